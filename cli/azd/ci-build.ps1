@@ -1,6 +1,7 @@
 param(
     [string] $Version = (Get-Content "$PSScriptRoot/../version.txt"),
-    [string] $SourceVersion = (git rev-parse HEAD)
+    [string] $SourceVersion = (git rev-parse HEAD),
+    [string] $ReleaseSigningKey = $env:AZD_RELEASE_SIGNING_KEY
 )
 
 # On Windows, use the goversioninfo tool to embed the version information into the executable.
@@ -40,7 +41,7 @@ if ($IsWindows) {
 }
 
 Write-Host "go build"
-go build -ldflags="-X 'github.com/azure/azure-dev/cli/azd/internal.Version=$Version (commit $SourceVersion)'"
+go build -ldflags="-X 'github.com/azure/azure-dev/cli/azd/internal.Version=$Version (commit $SourceVersion)' -X 'github.com/azure/azure-dev/cli/azd/pkg/update.ReleaseSigningKey=$ReleaseSigningKey'"
 
 if ($LASTEXITCODE) {
     Write-Host "Error running go build"
@@ -214,6 +214,10 @@ func (la *loginAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}
 
 	if !la.flags.onlyCheckStatus {
+		if err := la.authManager.EnsureLoginPersisted(); err != nil {
+			return nil, err
+		}
+
 		if err := la.accountSubManager.ClearSubscriptions(ctx); err != nil {
 			log.Printf("failed clearing subscriptions: %v", err)
 		}
@@ -38,14 +38,23 @@ func newAuthTokenFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions
 
 func newAuthTokenCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:    "token --output json",
-		Hidden: true,
+		Use:   "token",
+		Short: "Get an access token for the signed in account.",
+		Long: "Get an access token for the signed in account, ex) for hooks that call Azure Resource Manager or " +
+			"data plane APIs. The token is printed to the standard output, or as JSON with its expiration when " +
+			"--output json is specified.",
 	}
 }
 
 func (f *authTokenFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	f.global = global
-	local.StringArrayVar(&f.scopes, "scope", nil, "The scope to use when requesting an access token")
+	local.StringArrayVar(
+		&f.scopes,
+		"scope",
+		nil,
+		"The scope to use when requesting an access token, ex) https://storage.azure.com/.default. "+
+			"Defaults to the Azure Resource Manager scope.",
+	)
 	local.StringVar(&f.tenantID, "tenant-id", "", "The tenant id to use when requesting an access token.")
 }
 
@@ -165,6 +174,12 @@ func (a *authTokenAction) Run(ctx context.Context) (*actions.ActionResult, error
 		return nil, fmt.Errorf("fetching token: %w", err)
 	}
 
+	// Prints the bare token, so it can be captured by scripts, ex) TOKEN=$(azd auth token --scope <scope>)
+	if a.formatter.Kind() == output.NoneFormat {
+		fmt.Fprintln(a.writer, token.Token)
+		return nil, nil
+	}
+
 	res := contracts.AuthTokenResult{
 		Token:     token.Token,
 		ExpiresOn: contracts.RFC3339Time(token.ExpiresOn),
@@ -296,6 +296,30 @@ func TestAuthTokenCustomScopes(t *testing.T) {
 	require.True(t, wasCalled, "GetToken was not called on the credential")
 }
 
+func TestAuthTokenNoneFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	token := authTokenFn(func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+		return azcore.AccessToken{
+			Token:     "ABC123",
+			ExpiresOn: time.Unix(1669153000, 0).UTC(),
+		}, nil
+	})
+
+	a := newAuthTokenAction(
+		credentialProviderForTokenFn(token),
+		&output.NoneFormatter{},
+		buf,
+		&authTokenFlags{},
+		func() (*environment.Environment, error) { return nil, fmt.Errorf("not an azd env directory") },
+		&mockSubscriptionTenantResolver{},
+	)
+
+	_, err := a.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "ABC123\n", buf.String())
+}
+
 func TestAuthTokenFailure(t *testing.T) {
 	token := authTokenFn(func(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
 		return azcore.AccessToken{}, errors.New("could not fetch token")
@@ -11,7 +11,9 @@ import (
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/spf13/cobra"
 )
 
@@ -139,6 +141,32 @@ $ azd config set defaults.location eastus`,
 		ActionResolver: newConfigResetAction,
 	})
 
+	group.Add("set-service", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "set-service <service> <path> <value>",
+			Short: "Sets a service configuration in " + azdcontext.ProjectFileName,
+			Long: "Sets a configuration of a service in " + azdcontext.ProjectFileName +
+				", preserving the comments and formatting of the file.\n" +
+				"Values are parsed as YAML, ex) 3, true, [a, b] or {key: value}.",
+			Args: cobra.ExactArgs(3),
+			Example: `$ azd config set-service api host containerapp
+$ azd config set-service api docker.path ./Dockerfile.prod`,
+		},
+		ActionResolver: newConfigSetServiceAction,
+	})
+
+	group.Add("unset-service", &actions.ActionDescriptorOptions{
+		Command: &cobra.Command{
+			Use:   "unset-service <service> <path>",
+			Short: "Unsets a service configuration in " + azdcontext.ProjectFileName,
+			Long: "Removes a configuration of a service in " + azdcontext.ProjectFileName +
+				", preserving the comments and formatting of the file.",
+			Example: `$ azd config unset-service api docker.platform`,
+			Args:    cobra.ExactArgs(2),
+		},
+		ActionResolver: newConfigUnsetServiceAction,
+	})
+
 	return group
 }
 
@@ -309,6 +337,79 @@ func (a *configResetAction) Run(ctx context.Context) (*actions.ActionResult, err
 	return nil, a.configManager.Save(emptyConfig)
 }
 
+// azd config set-service <service> <path> <value>
+
+type configSetServiceAction struct {
+	azdCtx *azdcontext.AzdContext
+	args   []string
+}
+
+func newConfigSetServiceAction(azdCtx *azdcontext.AzdContext, args []string) actions.Action {
+	return &configSetServiceAction{
+		azdCtx: azdCtx,
+		args:   args,
+	}
+}
+
+// Executes the `azd config set-service <service> <path> <value>` action
+func (a *configSetServiceAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	editor, err := loadServiceEditor(a.azdCtx, a.args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("services.%s.%s", a.args[0], a.args[1])
+	value := a.args[2]
+
+	if err := editor.SetNode(path, project.ParseValueNode(value)); err != nil {
+		return nil, fmt.Errorf("failed setting service configuration value '%s' to '%s'. %w", a.args[1], value, err)
+	}
+
+	return nil, editor.Save(ctx, a.azdCtx.ProjectPath())
+}
+
+// azd config unset-service <service> <path>
+
+type configUnsetServiceAction struct {
+	azdCtx *azdcontext.AzdContext
+	args   []string
+}
+
+func newConfigUnsetServiceAction(azdCtx *azdcontext.AzdContext, args []string) actions.Action {
+	return &configUnsetServiceAction{
+		azdCtx: azdCtx,
+		args:   args,
+	}
+}
+
+// Executes the `azd config unset-service <service> <path>` action
+func (a *configUnsetServiceAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	editor, err := loadServiceEditor(a.azdCtx, a.args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !editor.Unset(fmt.Sprintf("services.%s.%s", a.args[0], a.args[1])) {
+		return nil, fmt.Errorf("no value stored at path '%s' for service '%s'", a.args[1], a.args[0])
+	}
+
+	return nil, editor.Save(ctx, a.azdCtx.ProjectPath())
+}
+
+// Loads the azure.yaml file of the project for editing, ensuring the service exists
+func loadServiceEditor(azdCtx *azdcontext.AzdContext, serviceName string) (*project.ProjectEditor, error) {
+	editor, err := project.LoadProjectEditor(azdCtx.ProjectPath())
+	if err != nil {
+		return nil, err
+	}
+
+	if !editor.Has(fmt.Sprintf("services.%s", serviceName)) {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", serviceName)
+	}
+
+	return editor, nil
+}
+
 func getCmdConfigHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(
 		"Manage the Azure Developer CLI user configuration, which includes your default Azure subscription and location.",
@@ -333,5 +434,7 @@ func getCmdConfigHelpFooter(c *cobra.Command) string {
 		"Set the default Azure deployment location.": fmt.Sprintf("%s %s",
 			output.WithHighLightFormat("azd config set defaults.location"),
 			output.WithWarningFormat("<location>")),
+		"Opt out of the telemetry of the account and project attributes.": output.WithHighLightFormat(
+			"azd config set telemetry.disabledCategories account,project"),
 	})
 }
@@ -25,17 +25,35 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/templates"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bundler"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cargo"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/composer"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/conda"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/dotnet"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/github"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/golang"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/gradle"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kiota"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/maven"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/oras"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/python"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/ssh"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/swa"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/terraform"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
+	"github.com/azure/azure-dev/cli/azd/pkg/validate"
 	"github.com/benbjohnson/clock"
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
@@ -87,6 +105,7 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.RegisterSingleton(func(
 		rootOptions *internal.GlobalCommandOptions,
 		formatter output.Formatter,
+		httpClient httputil.HttpClient,
 		cmd *cobra.Command) input.Console {
 		writer := cmd.OutOrStdout()
 		// When using JSON formatting, we want to ensure we always write messages from the console to stderr.
@@ -102,19 +121,37 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 			cmd.InOrStdin() == os.Stdin && isatty.IsTerminal(os.Stdin.Fd()) &&
 			isatty.IsTerminal(os.Stdout.Fd())
 
+		// Embedders, ex) the VS Code extension, can handle the prompts and progress of the console
+		var backend input.ConsoleBackend
+		if endpoint := os.Getenv(input.ConsoleEndpointEnvVarName); endpoint != "" {
+			backend = input.NewExternalConsoleBackend(endpoint, os.Getenv(input.ConsoleKeyEnvVarName), httpClient)
+		}
+
 		return input.NewConsole(rootOptions.NoPrompt, isTerminal, writer, input.ConsoleHandles{
 			Stdin:  cmd.InOrStdin(),
 			Stdout: cmd.OutOrStdout(),
 			Stderr: cmd.ErrOrStderr(),
-		}, formatter)
+		}, formatter, backend)
 	})
 
-	container.RegisterSingleton(func(console input.Console) exec.CommandRunner {
-		return exec.NewCommandRunner(
+	container.RegisterSingleton(func(console input.Console) (exec.CommandRunner, error) {
+		commandRunner := exec.NewCommandRunner(
 			console.Handles().Stdin,
 			console.Handles().Stdout,
 			console.Handles().Stderr,
 		)
+
+		// The command policy is managed by the organization, users can't change it from their configuration
+		policy, err := exec.LoadCommandPolicy(exec.CommandPolicyFilePath())
+		if err != nil {
+			return nil, err
+		}
+
+		if policy == nil || (len(policy.AllowedCommands) == 0 && policy.AuditLogPath == "") {
+			return commandRunner, nil
+		}
+
+		return exec.NewPolicyCommandRunner(commandRunner, *policy), nil
 	})
 	container.RegisterSingleton(input.NewConsoleMessaging)
 
@@ -253,10 +290,43 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.RegisterSingleton(project.NewResourceManager)
 	container.RegisterSingleton(project.NewProjectManager)
 	container.RegisterSingleton(project.NewServiceManager)
+	container.RegisterSingleton(project.NewPowerPlatformSolution)
+	container.RegisterSingleton(project.NewDeployNotifier)
+	container.RegisterSingleton(project.NewDeployAnnotator)
+	container.RegisterSingleton(project.NewDeployGitTracker)
+	container.RegisterSingleton(project.NewCustomDomainVerifier)
+	container.RegisterSingleton(project.NewServiceScheduler)
+	container.RegisterSingleton(project.NewOpenApiClientGenerator)
+	container.RegisterSingleton(project.NewBuildOrchestrator)
+	container.RegisterSingleton(project.NewDeployOrchestrator)
+	container.RegisterSingleton(project.NewImagePushCoordinator)
+	container.RegisterSingleton(project.NewImagePruner)
+	container.RegisterSingleton(project.NewProvenanceGenerator)
+	container.RegisterSingleton(project.NewChangeTracker)
+
+	// Lazy loads the change tracker of the environment when the environment becomes available
+	container.RegisterSingleton(
+		func(lazyEnv *lazy.Lazy[*environment.Environment]) *lazy.Lazy[*project.ChangeTracker] {
+			return lazy.NewLazy(func() (*project.ChangeTracker, error) {
+				_, err := lazyEnv.GetValue()
+				if err != nil {
+					return nil, err
+				}
+
+				var changeTracker *project.ChangeTracker
+				err = container.Resolve(&changeTracker)
+
+				return changeTracker, err
+			})
+		},
+	)
+	container.RegisterSingleton(project.NewServiceMetricsReader)
+	container.RegisterSingleton(validate.NewValidator)
 	container.RegisterSingleton(repository.NewInitializer)
 	container.RegisterSingleton(config.NewUserConfigManager)
 	container.RegisterSingleton(config.NewManager)
 	container.RegisterSingleton(templates.NewTemplateManager)
+	container.RegisterSingleton(update.NewManager)
 	container.RegisterSingleton(auth.NewManager)
 	container.RegisterSingleton(azcli.NewUserProfileService)
 	container.RegisterSingleton(account.NewSubscriptionsService)
@@ -265,6 +335,10 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 	container.RegisterSingleton(account.NewSubscriptionCredentialProvider)
 	container.RegisterSingleton(azcli.NewManagedClustersService)
 	container.RegisterSingleton(azcli.NewContainerRegistryService)
+	container.RegisterSingleton(azcli.NewBatchService)
+	container.RegisterSingleton(azcli.NewIotHubService)
+	container.RegisterSingleton(azcli.NewDataverseService)
+	container.RegisterSingleton(azcli.NewDataCopyService)
 	container.RegisterSingleton(func() ioc.ServiceLocator {
 		return ioc.NewServiceLocator(container)
 	})
@@ -285,17 +359,43 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		})
 	})
 	container.RegisterSingleton(bicep.NewBicepCli)
-	container.RegisterSingleton(docker.NewDocker)
+	container.RegisterSingleton(cosign.NewCosignCli)
+	container.RegisterSingleton(func(
+		commandRunner exec.CommandRunner,
+		lazyProjectConfig *lazy.Lazy[*project.ProjectConfig],
+	) docker.Docker {
+		// The docker context of the project, ex) a remote builder, overrides the docker context of the environment
+		if projectConfig, err := lazyProjectConfig.GetValue(); err == nil && projectConfig.Docker != nil {
+			return docker.NewDockerWithContext(commandRunner, projectConfig.Docker.Context)
+		}
+
+		return docker.NewDocker(commandRunner)
+	})
 	container.RegisterSingleton(dotnet.NewDotNetCli)
 	container.RegisterSingleton(git.NewGitCli)
 	container.RegisterSingleton(github.NewGitHubCli)
+	container.RegisterSingleton(grype.NewGrypeCli)
+	container.RegisterSingleton(hadolint.NewHadolintCli)
+	container.RegisterSingleton(golang.NewGoCli)
+	container.RegisterSingleton(cargo.NewCargoCli)
+	container.RegisterSingleton(composer.NewComposerCli)
+	container.RegisterSingleton(bundler.NewBundlerCli)
+	container.RegisterSingleton(conda.NewCondaCli)
 	container.RegisterSingleton(javac.NewCli)
+	container.RegisterSingleton(kiota.NewKiotaCli)
 	container.RegisterSingleton(kubectl.NewKubectl)
 	container.RegisterSingleton(maven.NewMavenCli)
+	container.RegisterSingleton(gradle.NewGradleCli)
+	container.RegisterSingleton(notation.NewNotationCli)
 	container.RegisterSingleton(npm.NewNpmCli)
+	container.RegisterSingleton(oras.NewOrasCli)
 	container.RegisterSingleton(python.NewPythonCli)
+	container.RegisterSingleton(ssh.NewSshCli)
 	container.RegisterSingleton(swa.NewSwaCli)
+	container.RegisterSingleton(syft.NewSyftCli)
+	container.RegisterSingleton(pack.NewPackCli)
 	container.RegisterSingleton(terraform.NewTerraformCli)
+	container.RegisterSingleton(trivy.NewTrivyCli)
 
 	// Other
 	container.RegisterSingleton(clock.New)
@@ -308,6 +408,10 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		project.ContainerAppTarget:  project.NewContainerAppTarget,
 		project.StaticWebAppTarget:  project.NewStaticWebAppTarget,
 		project.AksTarget:           project.NewAksTarget,
+		project.VmTarget:            project.NewVmTarget,
+		project.BatchJobTarget:      project.NewBatchJobTarget,
+		project.IotEdgeTarget:       project.NewIotEdgeTarget,
+		project.LogicAppTarget:      project.NewLogicAppTarget,
 	}
 
 	for target, constructor := range serviceTargetMap {
@@ -326,7 +430,16 @@ func registerCommonDependencies(container *ioc.NestedContainer) {
 		project.ServiceLanguageJavaScript: project.NewNpmProject,
 		project.ServiceLanguageTypeScript: project.NewNpmProject,
 		project.ServiceLanguageJava:       project.NewMavenProject,
+		project.ServiceLanguageGo:         project.NewGoProject,
+		project.ServiceLanguageRust:       project.NewRustProject,
+		project.ServiceLanguagePhp:        project.NewPhpProject,
+		project.ServiceLanguageRuby:       project.NewRubyProject,
+		project.ServiceLanguageCustom:     project.NewCustomProject,
 		project.ServiceLanguageDocker:     project.NewDockerProject,
+		project.ServiceLanguageWorkflow:   project.NewWorkflowProject,
+		project.ServiceLanguageGradle:     project.NewGradleProject,
+		project.ServiceLanguageConda:      project.NewCondaProject,
+		project.ServiceLanguageCompose:    project.NewComposeProject,
 	}
 
 	for language, constructor := range frameworkServiceMap {
@@ -5,13 +5,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
@@ -23,10 +27,15 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
 )
 
 type deployFlags struct {
 	serviceName string
+	group       string
+	noCache     bool
+	concurrency int
+	targets     string
 	global      *internal.GlobalCommandOptions
 	*envFlag
 }
@@ -34,6 +43,12 @@ type deployFlags struct {
 func (d *deployFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	d.bindNonCommon(local, global)
 	d.bindCommon(local, global)
+	local.StringVar(
+		&d.targets,
+		"targets",
+		"",
+		"Deploys the project to each target of a targets file, in the environment of the target.",
+	)
 }
 
 func (d *deployFlags) bindNonCommon(
@@ -48,6 +63,24 @@ func (d *deployFlags) bindNonCommon(
 	)
 	//deprecate:flag hide --service
 	_ = local.MarkHidden("service")
+	local.StringVar(
+		&d.group,
+		"group",
+		"",
+		"Deploys the services of a group (the services with the matching 'group' in "+azdcontext.ProjectFileName+").",
+	)
+	local.BoolVar(
+		&d.noCache,
+		"no-cache",
+		false,
+		"Rebuilds the services even when their sources are unchanged, and their container images without the layer cache.",
+	)
+	local.IntVar(
+		&d.concurrency,
+		"concurrency",
+		0,
+		"The maximum number of services built concurrently (defaults to the number of CPUs).",
+	)
 	d.global = global
 }
 
@@ -86,12 +119,21 @@ type deployAction struct {
 	projectManager  project.ProjectManager
 	serviceManager  project.ServiceManager
 	resourceManager project.ResourceManager
+	builds          *project.BuildOrchestrator
+	deploys         *project.DeployOrchestrator
+	provenance      *project.ProvenanceGenerator
+	changeTracker   *project.ChangeTracker
+	annotator       *project.DeployAnnotator
+	gitTracker      *project.DeployGitTracker
 	accountManager  account.Manager
+	subResolver     account.SubscriptionTenantResolver
 	azCli           azcli.AzCli
 	formatter       output.Formatter
 	writer          io.Writer
 	console         input.Console
 	commandRunner   exec.CommandRunner
+	// The services already deployed by the failed run of azd up being retried, which aren't deployed again
+	deployedServices []string
 }
 
 func newDeployAction(
@@ -101,9 +143,16 @@ func newDeployAction(
 	projectManager project.ProjectManager,
 	serviceManager project.ServiceManager,
 	resourceManager project.ResourceManager,
+	builds *project.BuildOrchestrator,
+	deploys *project.DeployOrchestrator,
+	provenance *project.ProvenanceGenerator,
+	changeTracker *project.ChangeTracker,
+	annotator *project.DeployAnnotator,
+	gitTracker *project.DeployGitTracker,
 	azdCtx *azdcontext.AzdContext,
 	environment *environment.Environment,
 	accountManager account.Manager,
+	subResolver account.SubscriptionTenantResolver,
 	azCli azcli.AzCli,
 	commandRunner exec.CommandRunner,
 	console input.Console,
@@ -119,7 +168,14 @@ func newDeployAction(
 		projectManager:  projectManager,
 		serviceManager:  serviceManager,
 		resourceManager: resourceManager,
+		builds:          builds,
+		deploys:         deploys,
+		provenance:      provenance,
+		changeTracker:   changeTracker,
+		annotator:       annotator,
+		gitTracker:      gitTracker,
 		accountManager:  accountManager,
+		subResolver:     subResolver,
 		azCli:           azCli,
 		formatter:       formatter,
 		writer:          writer,
@@ -131,6 +187,8 @@ func newDeployAction(
 type DeploymentResult struct {
 	Timestamp time.Time                      `json:"timestamp"`
 	Services  []*project.ServiceDeployResult `json:"services"`
+	// The git working tree the services were deployed from, nil when the project isn't in a git repository
+	Git *project.GitMetadata `json:"git,omitempty"`
 }
 
 func (d *deployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
@@ -146,8 +204,45 @@ func (d *deployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		targetServiceName = d.args[0]
 	}
 
-	if targetServiceName != "" && !d.projectConfig.HasService(targetServiceName) {
-		return nil, fmt.Errorf("service name '%s' doesn't exist", targetServiceName)
+	if d.flags.targets != "" {
+		return d.deployTargets(ctx, targetServiceName)
+	}
+
+	servicesToDeploy, err := d.projectConfig.SelectServices(targetServiceName, d.flags.group)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d.deployedServices) > 0 {
+		remaining := []*project.ServiceConfig{}
+		for _, svc := range servicesToDeploy {
+			if slices.Contains(d.deployedServices, svc.Name) {
+				log.Printf("skipping service %s, it was already deployed", svc.Name)
+				continue
+			}
+
+			remaining = append(remaining, svc)
+		}
+
+		if len(remaining) == 0 {
+			return &actions.ActionResult{
+				Message: &actions.ResultMessage{Header: "All the services were already deployed."},
+			}, nil
+		}
+
+		servicesToDeploy = remaining
+	}
+
+	// The working tree is checked before the services are built, which may generate files in their sources
+	gitMetadata, err := d.gitTracker.Check(ctx, d.projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.flags.noCache {
+		for _, svc := range servicesToDeploy {
+			svc.Docker.NoCache = true
+		}
 	}
 
 	if err := d.projectManager.Initialize(ctx, d.projectConfig); err != nil {
@@ -155,17 +250,15 @@ func (d *deployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}
 
 	// Collect all the tools we will need to do the deployment and validate that
-	// the are installed. When a single project is being deployed, we need just
-	// the tools for that project, otherwise we need the tools from all project.
+	// the are installed. When a single project or group is being deployed, we need just
+	// the tools for those projects, otherwise we need the tools from all project.
 	var allTools []tools.ExternalTool
-	for _, svc := range d.projectConfig.Services {
-		if targetServiceName == "" || targetServiceName == svc.Name {
-			serviceTools, err := d.serviceManager.GetRequiredTools(ctx, svc)
-			if err != nil {
-				return nil, fmt.Errorf("failed getting required tools for service %s: %w", svc.Name, err)
-			}
-			allTools = append(allTools, serviceTools...)
+	for _, svc := range servicesToDeploy {
+		serviceTools, err := d.serviceManager.GetRequiredTools(ctx, svc)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting required tools for service %s: %w", svc.Name, err)
 		}
+		allTools = append(allTools, serviceTools...)
 	}
 
 	if err := tools.EnsureInstalled(ctx, tools.Unique(allTools)...); err != nil {
@@ -177,58 +270,130 @@ func (d *deployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 		Title: "Deploying services (azd deploy)",
 	})
 
-	var svcDeploymentResult *project.ServiceDeployResult
-	var deploymentResults []*project.ServiceDeployResult
+	// Each service is deployed by the service manager, which pauses the deployment once the service is packaged. All
+	// services are packaged before any of them is published, so the container images of packaged services are pushed
+	// concurrently in the background while the remaining services are being packaged.
+	// Services are packaged concurrently, their progress is interleaved in the spinner.
+	unlockBuild, err := lockTargetsBuild(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, svc := range d.projectConfig.Services {
-		// Skip this service if both cases are true:
-		// 1. The user specified a service name
-		// 2. This service is not the one the user specified
-		if targetServiceName != "" && svc.Name != targetServiceName {
+	deployments := &serviceDeployments{deployments: map[string]*serviceDeployment{}}
+	packagedCount := 0
+	packagingMessage := "Packaging services"
+	d.console.ShowSpinner(ctx, packagingMessage, input.Step)
+
+	d.builds.SetMaxConcurrency(d.flags.concurrency)
+	packageTask := d.builds.Package(ctx, servicesToDeploy,
+		func(
+			_ context.Context,
+			svc *project.ServiceConfig,
+		) *async.TaskWithProgress[*project.ServiceDeployResult, project.ServiceProgress] {
+			// The deployment outlives the packaging of the services, so it runs with the context of the command
+			return d.startDeployment(ctx, deployments, svc)
+		})
+	for progress := range packageTask.Progress() {
+		if !progress.Done {
+			updatedMessage := fmt.Sprintf("Packaging service %s (%s)", progress.Service.Name, progress.Message)
+			d.console.ShowSpinner(ctx, updatedMessage, input.Step)
 			continue
 		}
 
-		stepMessage := fmt.Sprintf("Deploying service %s", svc.Name)
-		d.console.ShowSpinner(ctx, stepMessage, input.Step)
+		stepMessage := fmt.Sprintf("Packaging service %s", progress.Service.Name)
+		d.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(progress.Error))
+		if progress.Error == nil {
+			if archive, ok := progress.Result.Package.Details.(*project.ArchivePackageResult); ok {
+				d.console.MessageUxItem(ctx, &ux.PackageArchive{Summary: archive.Summary()})
+			}
 
-		deployTask := d.serviceManager.Deploy(ctx, svc)
+			packagedCount++
+		}
 
-		go func() {
-			for progress := range deployTask.Progress() {
-				updatedMessage := fmt.Sprintf("Deploying service %s (%s)", svc.Name, progress.Message)
-				d.console.ShowSpinner(ctx, updatedMessage, input.Step)
-			}
-		}()
+		if packagedCount < len(servicesToDeploy) {
+			d.console.ShowSpinner(ctx, packagingMessage, input.Step)
+		}
+	}
 
-		result, err := deployTask.Await()
+	_, err = packageTask.Await()
+	unlockBuild()
+	if err != nil {
+		deployments.cancel()
+		d.console.StopSpinner(ctx, "", input.StepDone)
+		return nil, fmt.Errorf("deploying service: %w", err)
+	}
 
-		d.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
-		if err != nil {
-			return nil, fmt.Errorf("deploying service: %w", err)
+	// Services are deployed with the strategy of the environment, ex) all at once in dev and rolling in prod. Services
+	// deployed concurrently have their progress interleaved in the spinner.
+	var deploymentResults []*project.ServiceDeployResult
+	deployingMessage := "Deploying services"
+	d.console.ShowSpinner(ctx, deployingMessage, input.Step)
+
+	strategy := d.projectConfig.DeployStrategy(d.env.GetEnvName())
+	publishTask := d.deploys.Publish(ctx, servicesToDeploy, strategy,
+		func(
+			_ context.Context,
+			svc *project.ServiceConfig,
+		) *async.TaskWithProgress[*project.ServiceDeployResult, project.ServiceProgress] {
+			return deployments.get(svc.Name).publish()
+		})
+	for progress := range publishTask.Progress() {
+		if !progress.Done {
+			updatedMessage := fmt.Sprintf("Deploying service %s (%s)", progress.Service.Name, progress.Message)
+			d.console.ShowSpinner(ctx, updatedMessage, input.Step)
+			continue
 		}
 
-		svcDeploymentResult = result
-		deploymentResults = append(deploymentResults, svcDeploymentResult)
+		stepMessage := fmt.Sprintf("Deploying service %s", progress.Service.Name)
+		d.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(progress.Error))
+		if progress.Error == nil {
+			svcDeploymentResult := progress.Result
+			deploymentResults = append(deploymentResults, svcDeploymentResult)
+
+			// report endpoint
+			for _, endpoint := range svcDeploymentResult.Publish.Endpoints {
+				d.console.MessageUxItem(ctx, &ux.Endpoint{Endpoint: endpoint})
+			}
+
+			if attestation := svcDeploymentResult.Provenance; attestation != nil {
+				d.console.MessageUxItem(ctx, &ux.Provenance{Subject: attestation.Subject, Referrer: attestation.Referrer})
+			}
+
+			if signature := svcDeploymentResult.Signature; signature != nil {
+				d.console.MessageUxItem(ctx, &ux.ImageSignature{Image: signature.Image, Digest: signature.Digest})
+			}
+		}
 
-		// report endpoint
-		for _, endpoint := range svcDeploymentResult.Publish.Endpoints {
-			d.console.MessageUxItem(ctx, &ux.Endpoint{Endpoint: endpoint})
+		if len(deploymentResults) < len(servicesToDeploy) {
+			d.console.ShowSpinner(ctx, deployingMessage, input.Step)
 		}
 	}
 
+	if _, err := publishTask.Await(); err != nil {
+		deployments.cancel()
+		d.console.StopSpinner(ctx, "", input.StepDone)
+		return nil, fmt.Errorf("deploying service: %w", err)
+	}
+
 	if targetServiceName != "" && len(deploymentResults) == 0 {
 		return nil, fmt.Errorf("no services were deployed. Check the specified service name and try again.")
 	}
 
+	d.annotator.Annotate(ctx, d.projectConfig, servicesToDeploy)
+	d.gitTracker.Tag(ctx, d.projectConfig, gitMetadata)
+
 	if d.formatter.Kind() == output.JsonFormat {
 		aggregateDeploymentResult := DeploymentResult{
 			Timestamp: time.Now(),
 			Services:  deploymentResults,
+			Git:       gitMetadata,
 		}
 
 		if fmtErr := d.formatter.Format(aggregateDeploymentResult, d.writer, nil); fmtErr != nil {
 			return nil, fmt.Errorf("deployment result could not be displayed: %w", fmtErr)
 		}
+	} else if gitMetadata != nil {
+		d.console.Message(ctx, output.WithGrayFormat("Deployed from %s", gitMetadata.Summary()))
 	}
 
 	return &actions.ActionResult{
@@ -239,6 +404,177 @@ func (d *deployAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}, nil
 }
 
+// A deployment of a service by ServiceManager.Deploy, paused once the service is packaged until it's published
+type serviceDeployment struct {
+	task *async.TaskWithProgress[*project.ServiceDeployResult, project.ServiceProgress]
+	// Receives the result of the deployment once the service is packaged
+	packaged chan *project.ServiceDeployResult
+	// Resumes the paused deployment, which publishes the service when the error is nil
+	resume chan error
+	// Closed once the deployment completed
+	done chan struct{}
+
+	mutex sync.Mutex
+	// Reports the progress of the deployment, nil while the deployment is paused
+	report func(project.ServiceProgress)
+}
+
+// Reports the progress of the deployment to the task of its current step, if any
+func (s *serviceDeployment) setProgress(progress project.ServiceProgress) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.report != nil {
+		s.report(progress)
+	}
+}
+
+// Sets the function reporting the progress of the deployment
+func (s *serviceDeployment) reportTo(report func(project.ServiceProgress)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.report = report
+}
+
+// Resumes the paused deployment to publish the service, returning a task that completes with the deployment
+func (s *serviceDeployment) publish() *async.TaskWithProgress[*project.ServiceDeployResult, project.ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*project.ServiceDeployResult, project.ServiceProgress]) {
+			s.reportTo(task.SetProgress)
+			s.resume <- nil
+			<-s.done
+
+			result, err := s.task.Await()
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(result)
+		},
+	)
+}
+
+// The deployments of the services by name
+type serviceDeployments struct {
+	mutex       sync.Mutex
+	deployments map[string]*serviceDeployment
+}
+
+func (s *serviceDeployments) add(serviceName string, deployment *serviceDeployment) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.deployments[serviceName] = deployment
+}
+
+func (s *serviceDeployments) get(serviceName string) *serviceDeployment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.deployments[serviceName]
+}
+
+// Fails the deployments still paused once their service is packaged, ex) when another service failed, then waits for all
+// the deployments to complete, which cancels the images of the failed deployments being pushed in the background
+func (s *serviceDeployments) cancel() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, deployment := range s.deployments {
+		select {
+		case deployment.resume <- errors.New("the deployment was canceled, another service failed"):
+		default:
+		}
+
+		<-deployment.done
+	}
+}
+
+// Starts deploying the service with the service manager, returning a task that completes once the service is packaged.
+// The deployment is then paused until the service is published.
+func (d *deployAction) startDeployment(
+	ctx context.Context,
+	deployments *serviceDeployments,
+	svc *project.ServiceConfig,
+) *async.TaskWithProgress[*project.ServiceDeployResult, project.ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*project.ServiceDeployResult, project.ServiceProgress]) {
+			deployment := &serviceDeployment{
+				packaged: make(chan *project.ServiceDeployResult, 1),
+				resume:   make(chan error, 1),
+				done:     make(chan struct{}),
+				report:   task.SetProgress,
+			}
+
+			startedOn := time.Now()
+			deployment.task = d.serviceManager.Deploy(ctx, svc, project.ServiceDeployOptions{
+				Packaged: func(ctx context.Context, result *project.ServiceDeployResult) error {
+					deployment.packaged <- result
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case err := <-deployment.resume:
+						return err
+					}
+				},
+				Published: func(ctx context.Context, result *project.ServiceDeployResult) error {
+					return d.servicePublished(ctx, svc, result, startedOn, deployment.setProgress)
+				},
+			})
+			deployments.add(svc.Name, deployment)
+
+			go func() {
+				defer close(deployment.done)
+				for progress := range deployment.task.Progress() {
+					deployment.setProgress(progress)
+				}
+			}()
+
+			select {
+			case result := <-deployment.packaged:
+				deployment.reportTo(nil)
+				task.SetResult(result)
+			case <-deployment.done:
+				result, err := deployment.task.Await()
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				task.SetResult(result)
+			}
+		},
+	)
+}
+
+// Generates the provenance of the deployed artifact when enabled and records the deployment of the published service
+func (d *deployAction) servicePublished(
+	ctx context.Context,
+	svc *project.ServiceConfig,
+	deployResult *project.ServiceDeployResult,
+	startedOn time.Time,
+	progress func(project.ServiceProgress),
+) error {
+	if svc.Provenance.Enabled {
+		progress(project.NewServiceProgress("Generating provenance"))
+		attestation, err := d.provenance.Attest(ctx, svc, deployResult, startedOn)
+		if err != nil {
+			return fmt.Errorf("generating provenance: %w", err)
+		}
+
+		deployResult.Provenance = attestation
+	}
+
+	// Failing to record the deployment doesn't fail it, it only affects the changes reported by `azd show`
+	if err := d.changeTracker.RecordDeploy(ctx, svc, deployResult); err != nil {
+		log.Printf("failed recording the deployment of service '%s': %v", svc.Name, err)
+	}
+
+	return nil
+}
+
 func getCmdDeployHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription("Deploy application to Azure.", []string{
 		formatHelpNote(fmt.Sprintf("When %s is not set, all services in the 'azure.yaml'"+
@@ -254,5 +590,7 @@ func getCmdDeployHelpFooter(*cobra.Command) string {
 			"azd deploy"),
 		"Deploy all application API services to Azure.": output.WithHighLightFormat("azd deploy api"),
 		"Deploy all application web services to Azure.": output.WithHighLightFormat("azd deploy web"),
+		"Deploy the application to the environment of each customer listed in targets.yaml.": output.WithHighLightFormat(
+			"azd deploy --targets targets.yaml"),
 	})
 }
@@ -0,0 +1,197 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/deploytargets"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/gofrs/flock"
+)
+
+// The environment variable set for the deployments of the targets with the path of the lock serializing the builds of
+// the targets, since the targets are built from the same sources
+const targetsBuildLockEnvVarName = "AZD_TARGETS_BUILD_LOCK"
+
+// Deploys the project to each target of the targets file, in the environment of the target. Each target is deployed by
+// azd deploy in a separate process, since the services of a command are bound to a single environment. The targets are
+// packaged one at a time and published in parallel, so the builds of the targets don't overwrite the outputs of each
+// other in the sources of the services.
+func (d *deployAction) deployTargets(ctx context.Context, targetServiceName string) (*actions.ActionResult, error) {
+	targetsFile, err := deploytargets.Load(d.flags.targets)
+	if err != nil {
+		return nil, err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("finding azd executable: %w", err)
+	}
+
+	args := []string{"deploy"}
+	if targetServiceName != "" {
+		args = append(args, targetServiceName)
+	}
+
+	if d.flags.group != "" {
+		args = append(args, "--group", d.flags.group)
+	}
+
+	if d.flags.noCache {
+		args = append(args, "--no-cache")
+	}
+
+	if d.flags.global.EnableDebugLogging {
+		args = append(args, "--debug")
+	}
+
+	d.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Deploying services to targets (azd deploy --targets)",
+		TitleNote: fmt.Sprintf(
+			"%d targets, up to %d deployed at the same time", len(targetsFile.Targets), targetsFile.Parallelism),
+	})
+
+	deployingMessage := "Deploying targets"
+	d.console.ShowSpinner(ctx, deployingMessage, input.Step)
+
+	buildLockPath := filepath.Join(d.azdCtx.EnvironmentDirectory(), "targets-build.lock")
+	completed := 0
+	report := targetsFile.Deploy(ctx, func(ctx context.Context, target deploytargets.Target) error {
+		if err := d.prepareTarget(ctx, targetsFile, target); err != nil {
+			return err
+		}
+
+		targetArgs := append(append([]string{}, args...), "--environment", target.Name, "--no-prompt")
+		runArgs := exec.NewRunArgs(executable, targetArgs...).
+			WithCwd(d.azdCtx.ProjectDirectory()).
+			WithEnv([]string{fmt.Sprintf("%s=%s", targetsBuildLockEnvVarName, buildLockPath)})
+		res, err := d.commandRunner.Run(ctx, runArgs)
+		log.Printf("deployment of target %s:\n%s", target.Name, res.String())
+		if err != nil {
+			return errors.New(lastLine(res.Stderr, err.Error()))
+		}
+
+		return nil
+	}, func(result *deploytargets.Result) {
+		completed++
+
+		var err error
+		if result.Status == deploytargets.StatusFailed {
+			err = errors.New(result.Error)
+		}
+
+		stepMessage := fmt.Sprintf("Deploying target %s (%s)", result.Target, result.Duration)
+		d.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			d.console.Message(ctx, output.WithErrorFormat("  %s", result.Error))
+		}
+
+		if completed < len(targetsFile.Targets) {
+			d.console.ShowSpinner(ctx, deployingMessage, input.Step)
+		}
+	})
+
+	if d.formatter.Kind() == output.JsonFormat {
+		if fmtErr := d.formatter.Format(report, d.writer, nil); fmtErr != nil {
+			return nil, fmt.Errorf("deployment report could not be displayed: %w", fmtErr)
+		}
+	}
+
+	if failed := report.Failed(); len(failed) > 0 {
+		return nil, fmt.Errorf(
+			"%d of %d targets failed to deploy: %s", len(failed), len(report.Targets), strings.Join(failed, ", "))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Your Azure app has been deployed to %d targets!", len(report.Targets)),
+		},
+	}, nil
+}
+
+// Creates or updates the environment of the target with the subscription, the location and the values of the target,
+// after checking the subscription belongs to the tenant of the target
+func (d *deployAction) prepareTarget(
+	ctx context.Context,
+	targetsFile *deploytargets.File,
+	target deploytargets.Target,
+) error {
+	if target.TenantId != "" {
+		tenantId, err := d.subResolver.LookupTenant(ctx, target.SubscriptionId)
+		if err != nil {
+			return fmt.Errorf("finding the tenant of subscription '%s': %w", target.SubscriptionId, err)
+		}
+
+		if !strings.EqualFold(tenantId, target.TenantId) {
+			return fmt.Errorf(
+				"subscription '%s' belongs to tenant '%s', not to tenant '%s' of the target",
+				target.SubscriptionId,
+				tenantId,
+				target.TenantId,
+			)
+		}
+	}
+
+	env, err := environment.GetEnvironment(d.azdCtx, target.Name)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("creating environment %s of target", target.Name)
+		env = environment.EmptyWithRoot(d.azdCtx.EnvironmentRoot(target.Name))
+	} else if err != nil {
+		return fmt.Errorf("loading environment '%s': %w", target.Name, err)
+	}
+
+	targetsFile.Apply(target, env)
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("saving environment '%s': %w", target.Name, err)
+	}
+
+	return nil
+}
+
+// Waits for the deployments of the other targets to package the services, when the project is deployed to the targets
+// of a targets file. Returns the function releasing the lock once the services are packaged.
+func lockTargetsBuild(ctx context.Context) (func(), error) {
+	lockPath := os.Getenv(targetsBuildLockEnvVarName)
+	if lockPath == "" {
+		return func() {}, nil
+	}
+
+	fileLock := flock.New(lockPath)
+	if _, err := fileLock.TryLockContext(ctx, 100*time.Millisecond); err != nil {
+		return nil, fmt.Errorf("waiting for the other targets to be packaged: %w", err)
+	}
+
+	return func() {
+		if err := fileLock.Unlock(); err != nil {
+			log.Printf("failed releasing lock %s: %v", lockPath, err)
+		}
+	}, nil
+}
+
+// Gets the last line of the output, or the fallback when the output is empty
+func lastLine(output string, fallback string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+
+	return fallback
+}
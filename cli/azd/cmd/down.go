@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
@@ -87,6 +88,10 @@ func newDownAction(
 }
 
 func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if err := a.confirmProtectedEnvironment(ctx); err != nil {
+		return nil, err
+	}
+
 	infraManager, err := provisioning.NewManager(
 		ctx,
 		a.env,
@@ -127,6 +132,35 @@ func (a *downAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	return nil, nil
 }
 
+// Requires typing the name of a protected environment before deleting its resources, even with --force, so the resources
+// of protected environments, ex) production environments, aren't deleted by accident
+func (a *downAction) confirmProtectedEnvironment(ctx context.Context) error {
+	if !a.env.IsProtected() {
+		return nil
+	}
+
+	envName := a.env.GetEnvName()
+	a.console.Message(ctx, output.WithWarningFormat("Environment %s is protected.", envName))
+
+	typed, err := a.console.Prompt(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf("Type the name of the environment (%s) to delete its resources:", envName),
+	})
+	if err != nil {
+		return fmt.Errorf("confirming deletion of protected environment %s: %w", envName, err)
+	}
+
+	if strings.TrimSpace(typed) != envName {
+		return fmt.Errorf(
+			"the name doesn't match the protected environment %s, its resources weren't deleted. Run %s to remove its"+
+				" protection",
+			envName,
+			output.WithHighLightFormat("azd env set-protection --disable"),
+		)
+	}
+
+	return nil
+}
+
 func getCmdDownHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(fmt.Sprintf(
 		"Delete Azure resources for an application. Running %s will not delete application"+
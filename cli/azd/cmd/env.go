@@ -5,10 +5,15 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"os"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/auth"
@@ -18,7 +23,9 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
@@ -56,6 +63,15 @@ func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		ActionResolver: newEnvNewAction,
 	})
 
+	group.Add("clone", &actions.ActionDescriptorOptions{
+		Command:        newEnvCloneCmd(),
+		FlagsResolver:  newEnvCloneFlags,
+		ActionResolver: newEnvCloneAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdEnvCloneHelpDescription,
+		},
+	})
+
 	group.Add("list", &actions.ActionDescriptorOptions{
 		Command:        newEnvListCmd(),
 		ActionResolver: newEnvListAction,
@@ -69,7 +85,7 @@ func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		ActionResolver: newEnvRefreshAction,
 		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
 		DefaultFormat:  output.NoneFormat,
-	})
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	group.Add("get-values", &actions.ActionDescriptorOptions{
 		Command:        newEnvGetValuesCmd(),
@@ -79,6 +95,23 @@ func envActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 		DefaultFormat:  output.EnvVarsFormat,
 	})
 
+	group.Add("prune-images", &actions.ActionDescriptorOptions{
+		Command:        newEnvPruneImagesCmd(),
+		FlagsResolver:  newEnvPruneImagesFlags,
+		ActionResolver: newEnvPruneImagesAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	group.Add("set-protection", &actions.ActionDescriptorOptions{
+		Command:        newEnvSetProtectionCmd(),
+		FlagsResolver:  newEnvSetProtectionFlags,
+		ActionResolver: newEnvSetProtectionAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdEnvSetProtectionHelpDescription,
+		},
+	})
+
 	return group
 }
 
@@ -144,6 +177,95 @@ func (e *envSetAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	return nil, nil
 }
 
+type envSetProtectionFlags struct {
+	disable bool
+	global  *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (f *envSetProtectionFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(&f.disable, "disable", false, "Removes the protection of the environment.")
+	f.envFlag.Bind(local, global)
+	f.global = global
+}
+
+func newEnvSetProtectionFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *envSetProtectionFlags {
+	flags := &envSetProtectionFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newEnvSetProtectionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-protection",
+		Short: "Protect the environment from the deletion of its resources.",
+		Args:  cobra.NoArgs,
+	}
+}
+
+type envSetProtectionAction struct {
+	env     *environment.Environment
+	console input.Console
+	flags   *envSetProtectionFlags
+}
+
+func newEnvSetProtectionAction(
+	env *environment.Environment,
+	console input.Console,
+	flags *envSetProtectionFlags,
+) actions.Action {
+	return &envSetProtectionAction{
+		env:     env,
+		console: console,
+		flags:   flags,
+	}
+}
+
+func (a *envSetProtectionAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if err := a.env.SetProtected(!a.flags.disable); err != nil {
+		return nil, fmt.Errorf("setting protection of environment: %w", err)
+	}
+
+	if err := a.env.Save(); err != nil {
+		return nil, fmt.Errorf("saving environment: %w", err)
+	}
+
+	header := fmt.Sprintf("Environment %s is protected.", a.env.GetEnvName())
+	followUp := fmt.Sprintf(
+		"Deleting its resources with %s requires typing the name of the environment.",
+		output.WithHighLightFormat("azd down"),
+	)
+	if a.flags.disable {
+		header = fmt.Sprintf("Environment %s is no longer protected.", a.env.GetEnvName())
+		followUp = ""
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header:   header,
+			FollowUp: followUp,
+		},
+	}, nil
+}
+
+func getCmdEnvSetProtectionHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Protect the environment from the deletion of its resources, ex) production environments.",
+		[]string{
+			formatHelpNote(fmt.Sprintf(
+				"Deleting the resources of a protected environment with %s requires typing the name of the environment,"+
+					" even with %s.",
+				output.WithHighLightFormat("azd down"),
+				output.WithHighLightFormat("--force"),
+			)),
+			formatHelpNote(fmt.Sprintf(
+				"Run %s to remove the protection.",
+				output.WithHighLightFormat("azd env set-protection --disable"),
+			)),
+		})
+}
+
 func newEnvSelectCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "select <environment>",
@@ -315,6 +437,244 @@ func (en *envNewAction) Run(ctx context.Context) (*actions.ActionResult, error)
 	return nil, nil
 }
 
+type envCloneFlags struct {
+	withData bool
+	global   *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (f *envCloneFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.withData,
+		"with-data",
+		false,
+		"Copies the blob containers and Cosmos DB containers of the source environment to the new environment.",
+	)
+	// The environment flag selects the new environment for the provisioning and the deployment of the clone
+	f.envFlag.Bind(local, global)
+	_ = local.MarkHidden(environmentNameFlag)
+	f.global = global
+}
+
+func newEnvCloneFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *envCloneFlags {
+	flags := &envCloneFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newEnvCloneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clone <source> <destination>",
+		Short: "Create a new environment from an existing environment, and provision and deploy it.",
+		Args:  cobra.ExactArgs(2),
+	}
+}
+
+type envCloneAction struct {
+	azdCtx                     *azdcontext.AzdContext
+	lazyProjectConfig          *lazy.Lazy[*project.ProjectConfig]
+	containerRegistryService   azcli.ContainerRegistryService
+	dataCopyService            azcli.DataCopyService
+	provisionActionInitializer actions.ActionInitializer[*provisionAction]
+	deployActionInitializer    actions.ActionInitializer[*deployAction]
+	runner                     middleware.MiddlewareContext
+	flags                      *envCloneFlags
+	args                       []string
+	console                    input.Console
+}
+
+func newEnvCloneAction(
+	azdCtx *azdcontext.AzdContext,
+	lazyProjectConfig *lazy.Lazy[*project.ProjectConfig],
+	containerRegistryService azcli.ContainerRegistryService,
+	dataCopyService azcli.DataCopyService,
+	provisionActionInitializer actions.ActionInitializer[*provisionAction],
+	deployActionInitializer actions.ActionInitializer[*deployAction],
+	runner middleware.MiddlewareContext,
+	flags *envCloneFlags,
+	args []string,
+	console input.Console,
+) actions.Action {
+	return &envCloneAction{
+		azdCtx:                     azdCtx,
+		lazyProjectConfig:          lazyProjectConfig,
+		containerRegistryService:   containerRegistryService,
+		dataCopyService:            dataCopyService,
+		provisionActionInitializer: provisionActionInitializer,
+		deployActionInitializer:    deployActionInitializer,
+		runner:                     runner,
+		flags:                      flags,
+		args:                       args,
+		console:                    console,
+	}
+}
+
+func (ec *envCloneAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	sourceName, targetName := ec.args[0], ec.args[1]
+	if !environment.IsValidEnvironmentName(targetName) {
+		return nil, fmt.Errorf(
+			"invalid environment name '%s', the name can only contain alphanumeric characters, '-', '(', ')', '_' and '.'",
+			targetName,
+		)
+	}
+
+	source, err := environment.GetEnvironment(ec.azdCtx, sourceName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("environment '%s' doesn't exist", sourceName)
+	} else if err != nil {
+		return nil, fmt.Errorf("loading environment '%s': %w", sourceName, err)
+	}
+
+	targetRoot := ec.azdCtx.EnvironmentRoot(targetName)
+	if _, err := os.Stat(targetRoot); err == nil {
+		return nil, fmt.Errorf("environment '%s' already exists", targetName)
+	}
+
+	target := environment.EmptyWithRoot(targetRoot)
+	if err := provisioning.CopyInputs(source, target); err != nil {
+		return nil, err
+	}
+
+	target.SetEnvName(targetName)
+	if err := target.Save(); err != nil {
+		return nil, fmt.Errorf("saving environment '%s': %w", targetName, err)
+	}
+
+	ec.console.Message(ctx, fmt.Sprintf("Created environment %s from %s.\n", targetName, sourceName))
+
+	// The provisioning and the deployment run against the new environment, without changing the default environment
+	ec.flags.environmentName = targetName
+
+	provision, err := ec.provisionActionInitializer()
+	if err != nil {
+		return nil, err
+	}
+
+	provision.flags = &provisionFlags{global: ec.flags.global}
+	provision.flags.setCommon(&ec.flags.envFlag)
+	provisionOptions := &middleware.Options{CommandPath: "provision", EnvironmentName: targetName}
+	if _, err := ec.runner.RunChildAction(ctx, provisionOptions, provision); err != nil {
+		return nil, err
+	}
+
+	if err := target.Reload(); err != nil {
+		return nil, fmt.Errorf("loading environment '%s': %w", targetName, err)
+	}
+
+	if ec.flags.withData {
+		if err := ec.copyData(ctx, source, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ec.pinImages(ctx, source); err != nil {
+		return nil, err
+	}
+
+	// Print an additional newline to separate provision from deploy
+	ec.console.Message(ctx, "")
+
+	deploy, err := ec.deployActionInitializer()
+	if err != nil {
+		return nil, err
+	}
+
+	deploy.flags = &deployFlags{global: ec.flags.global}
+	deploy.flags.setCommon(&ec.flags.envFlag)
+	deployOptions := &middleware.Options{CommandPath: "deploy", EnvironmentName: targetName}
+	if _, err := ec.runner.RunChildAction(ctx, deployOptions, deploy); err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Environment %s is a clone of %s.", targetName, sourceName),
+			FollowUp: fmt.Sprintf(
+				"Run %s to delete its resources when you're done.",
+				output.WithHighLightFormat("azd down -e %s", targetName),
+			),
+		},
+	}, nil
+}
+
+// Deploys the container images deployed to the source environment instead of building the services from source, so the
+// new environment runs the same versions as the source environment. The images are pulled from the registry of the source
+// environment and pushed to the registry of the new environment.
+func (ec *envCloneAction) pinImages(ctx context.Context, source *environment.Environment) error {
+	projectConfig, err := ec.lazyProjectConfig.GetValue()
+	if err != nil {
+		return err
+	}
+
+	pinned := false
+	for _, serviceConfig := range projectConfig.Services {
+		image := source.GetServiceProperty(serviceConfig.Name, "IMAGE_NAME")
+		if !serviceConfig.Host.RequiresContainer() || image == "" {
+			continue
+		}
+
+		log.Printf("deploying image %s of environment %s for service %s", image, source.GetEnvName(), serviceConfig.Name)
+		serviceConfig.Docker.Image = project.NewExpandableString(image)
+		pinned = true
+	}
+
+	loginServer := source.Values[environment.ContainerRegistryEndpointEnvVarName]
+	if !pinned || loginServer == "" {
+		return nil
+	}
+
+	if err := ec.containerRegistryService.LoginAcr(ctx, source.GetSubscriptionId(), loginServer); err != nil {
+		return fmt.Errorf("logging into registry '%s' of environment '%s': %w", loginServer, source.GetEnvName(), err)
+	}
+
+	return nil
+}
+
+// Copies the content of the data stores of the source environment to the matching data stores of the target environment
+func (ec *envCloneAction) copyData(
+	ctx context.Context,
+	source *environment.Environment,
+	target *environment.Environment,
+) error {
+	pairs := azcli.FindDataStorePairs(provisioning.OutputNames(target), source.Values, target.Values)
+	if len(pairs) == 0 {
+		ec.console.MessageUxItem(ctx, &ux.WarningMessage{
+			Description: "No data was copied, the infrastructure doesn't output the endpoints of blob or Cosmos DB" +
+				" accounts.",
+		})
+		return nil
+	}
+
+	for _, pair := range pairs {
+		stepMessage := fmt.Sprintf("Copying %s data (%s)", pair.Kind, output.WithHighLightFormat(pair.Name))
+		ec.console.ShowSpinner(ctx, stepMessage, input.Step)
+
+		var result *azcli.DataCopyResult
+		var err error
+		switch pair.Kind {
+		case azcli.DataStoreKindBlob:
+			result, err = ec.dataCopyService.CopyBlobContainers(
+				ctx, target.GetSubscriptionId(), pair.SourceEndpoint, pair.TargetEndpoint)
+		case azcli.DataStoreKindCosmos:
+			result, err = ec.dataCopyService.CopyCosmosContainers(
+				ctx, target.GetSubscriptionId(), pair.SourceEndpoint, pair.TargetEndpoint)
+		}
+
+		ec.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return fmt.Errorf("copying data from '%s' to '%s': %w", pair.SourceEndpoint, pair.TargetEndpoint, err)
+		}
+
+		ec.console.Message(ctx, fmt.Sprintf("  Copied %d item(s) from %s", result.Copied, pair.SourceEndpoint))
+		for _, skipped := range result.Skipped {
+			ec.console.Message(ctx, output.WithWarningFormat("  Skipped %s", skipped))
+		}
+	}
+
+	return nil
+}
+
 type envRefreshFlags struct {
 	global *internal.GlobalCommandOptions
 	envFlag
@@ -428,6 +788,7 @@ func (ef *envRefreshAction) Run(ctx context.Context) (*actions.ActionResult, err
 			Args: map[string]any{
 				"bicepOutput": getStateResult.State.Outputs,
 			},
+			Outputs: getStateResult.State.Outputs,
 		}
 
 		if err := svc.RaiseEvent(ctx, project.ServiceEventEnvUpdated, eventArgs); err != nil {
@@ -498,6 +859,167 @@ func (eg *envGetValuesAction) Run(ctx context.Context) (*actions.ActionResult, e
 	return nil, nil
 }
 
+type envPruneImagesFlags struct {
+	keep   int
+	dryRun bool
+	force  bool
+	global *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (f *envPruneImagesFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.IntVar(
+		&f.keep,
+		"keep",
+		project.DefaultImagePruneKeep,
+		"The number of the most recent deployments whose images are kept.",
+	)
+	local.BoolVar(&f.dryRun, "dry-run", false, "Lists the images that would be deleted without deleting them.")
+	local.BoolVar(&f.force, "force", false, "Does not require confirmation before it deletes the images.")
+	f.envFlag.Bind(local, global)
+	f.global = global
+}
+
+func newEnvPruneImagesFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *envPruneImagesFlags {
+	flags := &envPruneImagesFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newEnvPruneImagesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune-images [<service>]",
+		Short: "Delete the container images of old deployments from the container registry.",
+		Args:  cobra.MaximumNArgs(1),
+	}
+}
+
+type envPruneImagesAction struct {
+	args          []string
+	projectConfig *project.ProjectConfig
+	pruner        *project.ImagePruner
+	flags         *envPruneImagesFlags
+	console       input.Console
+	formatter     output.Formatter
+	writer        io.Writer
+}
+
+func newEnvPruneImagesAction(
+	args []string,
+	projectConfig *project.ProjectConfig,
+	pruner *project.ImagePruner,
+	flags *envPruneImagesFlags,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &envPruneImagesAction{
+		args:          args,
+		projectConfig: projectConfig,
+		pruner:        pruner,
+		flags:         flags,
+		console:       console,
+		formatter:     formatter,
+		writer:        writer,
+	}
+}
+
+func (a *envPruneImagesAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	serviceName := ""
+	if len(a.args) == 1 {
+		serviceName = a.args[0]
+	}
+
+	services, err := a.projectConfig.SelectServices(serviceName, "")
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := []*project.PrunedImage{}
+	for _, svc := range services {
+		// Only the services deployed as containers have images, unless a service is selected explicitly
+		if serviceName == "" && !svc.Host.RequiresContainer() {
+			continue
+		}
+
+		stepMessage := fmt.Sprintf("Finding images to prune of service %s", output.WithHighLightFormat(svc.Name))
+		a.console.ShowSpinner(ctx, stepMessage, input.Step)
+		images, err := a.pruner.Find(ctx, svc, a.flags.keep)
+		if errors.Is(err, project.ErrImagePruneNotSupported) {
+			a.console.StopSpinner(ctx, stepMessage, input.StepWarning)
+			a.console.Message(ctx, fmt.Sprintf("  %s", err.Error()))
+			continue
+		}
+
+		a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, image := range images {
+			a.console.Message(ctx, fmt.Sprintf("  %s@%s (%s)", image.Repository, image.Digest, strings.Join(image.Tags, ", ")))
+		}
+
+		pruned = append(pruned, images...)
+	}
+
+	if !a.flags.dryRun && len(pruned) > 0 {
+		if !a.flags.force {
+			confirm, err := a.console.Confirm(ctx, input.ConsoleOptions{
+				Message:      fmt.Sprintf("Delete %d image(s) from the container registry?", len(pruned)),
+				DefaultValue: false,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if !confirm {
+				return nil, errors.New("the images weren't deleted, run with --force to delete them without confirmation")
+			}
+		}
+
+		stepMessage := fmt.Sprintf("Deleting %d image(s)", len(pruned))
+		a.console.ShowSpinner(ctx, stepMessage, input.Step)
+		err := a.pruner.Delete(ctx, pruned)
+		a.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if a.formatter.Kind() == output.JsonFormat {
+		if err := a.formatter.Format(pruned, a.writer, nil); err != nil {
+			return nil, fmt.Errorf("writing pruned images in JSON format: %w", err)
+		}
+	}
+
+	header := fmt.Sprintf("Deleted %d image(s) of old deployments.", len(pruned))
+	if a.flags.dryRun {
+		header = fmt.Sprintf("%d image(s) of old deployments would be deleted.", len(pruned))
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{Header: header},
+	}, nil
+}
+
+func getCmdEnvCloneHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Create a new environment from an existing environment, to reproduce the issues of an environment in a"+
+			" sandbox. The new environment is provisioned, then deployed with the same container images as the existing"+
+			" environment.",
+		[]string{
+			formatHelpNote("The location, subscription, infrastructure parameters and values of the existing environment" +
+				" are copied. The outputs of its infrastructure aren't copied, so the new environment never uses its" +
+				" resources."),
+			formatHelpNote("Services which aren't deployed as containers are deployed from the current source code."),
+			formatHelpNote(fmt.Sprintf("With %s, the blob containers and Cosmos DB containers whose endpoints are outputs"+
+				" of the infrastructure are copied, which requires data-plane roles on both environments.",
+				output.WithHighLightFormat("--with-data"))),
+		})
+}
+
 func getCmdEnvHelpDescription(*cobra.Command) string {
 	return generateCmdHelpDescription(
 		"Manage your application environments. With this command group, you can create a new environment or get, set,"+
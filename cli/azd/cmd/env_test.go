@@ -0,0 +1,198 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const envCloneTestProject = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+`
+
+func Test_EnvCloneAction(t *testing.T) {
+	t.Run("ProvisionsAndDeploysTarget", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+		saveCloneSourceEnvironment(t, azdCtx)
+
+		projectConfig, err := project.Parse(*mockContext.Context, envCloneTestProject)
+		require.NoError(t, err)
+
+		registryService := &fakeCloneRegistryService{}
+		runner := &fakeCloneRunner{t: t, azdCtx: azdCtx}
+		action := newCloneTestAction(mockContext, azdCtx, projectConfig, registryService, runner, "prod", "sandbox")
+
+		_, err = action.Run(*mockContext.Context)
+		require.NoError(t, err)
+
+		// The inputs of the source are copied, its outputs and the values set by its deployments aren't
+		target, err := environment.GetEnvironment(azdCtx, "sandbox")
+		require.NoError(t, err)
+		require.Equal(t, "sandbox", target.GetEnvName())
+		require.Equal(t, "westus2", target.GetLocation())
+		require.Equal(t, "SUBSCRIPTION_ID", target.GetSubscriptionId())
+		require.Equal(t, "value", target.Values["CUSTOM_SETTING"])
+		require.NotContains(t, target.Values, "API_BASE_URL")
+		require.NotContains(t, target.Values, "SERVICE_API_IMAGE_NAME")
+
+		sku, has := target.Config.Get("infra.parameters.sku")
+		require.True(t, has)
+		require.Equal(t, "P1", sku)
+
+		// The provisioning and the deployment run against the target, the default environment is unchanged
+		require.Equal(t, []string{"provision", "deploy"}, runner.commands)
+		require.Equal(t, []string{"sandbox", "sandbox"}, runner.environments)
+		defaultEnv, err := azdCtx.GetDefaultEnvironmentName()
+		require.NoError(t, err)
+		require.Equal(t, "prod", defaultEnv)
+
+		// The services are deployed with the images of the source, pulled from the registry of the source
+		require.Equal(t, "crprod.azurecr.io/app/api:azd-deploy-1", projectConfig.Services["api"].Docker.Image.MustEnvsubst(
+			func(string) string { return "" }))
+		require.Equal(t, []string{"crprod.azurecr.io"}, registryService.loginServers)
+	})
+
+	t.Run("ExistingTarget", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+		saveCloneSourceEnvironment(t, azdCtx)
+
+		existing := environment.EmptyWithRoot(azdCtx.EnvironmentRoot("sandbox"))
+		existing.SetEnvName("sandbox")
+		existing.SetLocation("eastus")
+		require.NoError(t, existing.Save())
+
+		projectConfig, err := project.Parse(*mockContext.Context, envCloneTestProject)
+		require.NoError(t, err)
+
+		runner := &fakeCloneRunner{t: t, azdCtx: azdCtx}
+		action := newCloneTestAction(
+			mockContext, azdCtx, projectConfig, &fakeCloneRegistryService{}, runner, "prod", "sandbox")
+
+		_, err = action.Run(*mockContext.Context)
+		require.ErrorContains(t, err, "environment 'sandbox' already exists")
+		require.Empty(t, runner.commands)
+
+		// The existing environment isn't overwritten with the inputs of the source
+		target, err := environment.GetEnvironment(azdCtx, "sandbox")
+		require.NoError(t, err)
+		require.Equal(t, "eastus", target.GetLocation())
+		require.NotContains(t, target.Values, "CUSTOM_SETTING")
+	})
+
+	t.Run("MissingSource", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdCtx := azdcontext.NewAzdContextWithDirectory(t.TempDir())
+
+		runner := &fakeCloneRunner{t: t, azdCtx: azdCtx}
+		action := newCloneTestAction(
+			mockContext, azdCtx, &project.ProjectConfig{}, &fakeCloneRegistryService{}, runner, "prod", "sandbox")
+
+		_, err := action.Run(*mockContext.Context)
+		require.ErrorContains(t, err, "environment 'prod' doesn't exist")
+		require.Empty(t, runner.commands)
+	})
+}
+
+// Saves the prod environment cloned by the tests, with inputs, outputs and the values set by a deployment
+func saveCloneSourceEnvironment(t *testing.T, azdCtx *azdcontext.AzdContext) {
+	source := environment.EmptyWithRoot(azdCtx.EnvironmentRoot("prod"))
+	source.SetEnvName("prod")
+	source.SetLocation("westus2")
+	source.SetSubscriptionId("SUBSCRIPTION_ID")
+	source.Values["CUSTOM_SETTING"] = "value"
+	source.Values[environment.ContainerRegistryEndpointEnvVarName] = "crprod.azurecr.io"
+	source.SetServiceProperty("api", "IMAGE_NAME", "crprod.azurecr.io/app/api:azd-deploy-1")
+	require.NoError(t, source.Config.Set("infra.parameters.sku", "P1"))
+	require.NoError(t, provisioning.UpdateEnvironment(source, map[string]provisioning.OutputParameter{
+		"API_BASE_URL": {Type: provisioning.ParameterTypeString, Value: "https://api.contoso.com"},
+	}))
+	require.NoError(t, source.Save())
+	require.NoError(t, azdCtx.SetDefaultEnvironmentName("prod"))
+}
+
+func newCloneTestAction(
+	mockContext *mocks.MockContext,
+	azdCtx *azdcontext.AzdContext,
+	projectConfig *project.ProjectConfig,
+	registryService azcli.ContainerRegistryService,
+	runner middleware.MiddlewareContext,
+	sourceName string,
+	targetName string,
+) actions.Action {
+	return newEnvCloneAction(
+		azdCtx,
+		lazy.NewLazy(func() (*project.ProjectConfig, error) { return projectConfig, nil }),
+		registryService,
+		nil,
+		func() (*provisionAction, error) { return &provisionAction{}, nil },
+		func() (*deployAction, error) { return &deployAction{}, nil },
+		runner,
+		&envCloneFlags{global: &internal.GlobalCommandOptions{}},
+		[]string{sourceName, targetName},
+		mockContext.Console,
+	)
+}
+
+// Records the child actions run by the clone instead of running them
+type fakeCloneRunner struct {
+	t            *testing.T
+	azdCtx       *azdcontext.AzdContext
+	commands     []string
+	environments []string
+}
+
+func (r *fakeCloneRunner) RunChildAction(
+	ctx context.Context,
+	runOptions *middleware.Options,
+	action actions.Action,
+) (*actions.ActionResult, error) {
+	var envName string
+	switch child := action.(type) {
+	case *provisionAction:
+		envName = child.flags.environmentName
+	case *deployAction:
+		envName = child.flags.environmentName
+	}
+
+	// The flags of the child select the same environment as the options of the middleware
+	require.Equal(r.t, runOptions.EnvironmentName, envName)
+
+	// The target environment is saved before the child actions run
+	_, err := environment.GetEnvironment(r.azdCtx, envName)
+	require.NoError(r.t, err)
+
+	r.commands = append(r.commands, runOptions.CommandPath)
+	r.environments = append(r.environments, envName)
+	return nil, nil
+}
+
+type fakeCloneRegistryService struct {
+	azcli.ContainerRegistryService
+	loginServers []string
+}
+
+func (s *fakeCloneRegistryService) LoginAcr(ctx context.Context, subscriptionId string, loginServer string) error {
+	s.loginServers = append(s.loginServers, loginServer)
+	return nil
+}
@@ -27,7 +27,8 @@ func infraActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 			OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
 			DefaultFormat:  output.NoneFormat,
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	group.
 		Add("delete", &actions.ActionDescriptorOptions{
@@ -37,7 +38,18 @@ func infraActions(root *actions.ActionDescriptor) *actions.ActionDescriptor {
 			OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
 			DefaultFormat:  output.NoneFormat,
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	group.
+		Add("snapshot", &actions.ActionDescriptorOptions{
+			Command:        newInfraSnapshotCmd(),
+			FlagsResolver:  newInfraSnapshotFlags,
+			ActionResolver: newInfraSnapshotAction,
+			OutputFormats:  []output.Format{output.NoneFormat},
+			DefaultFormat:  output.NoneFormat,
+		}).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	return group
 }
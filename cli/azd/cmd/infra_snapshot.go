@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// The changed values of snapshots listed when the snapshot doesn't match the compiled templates
+const maxSnapshotDiffs = 20
+
+type infraSnapshotFlags struct {
+	update bool
+	global *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (f *infraSnapshotFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.update,
+		"update",
+		false,
+		"Updates the snapshot with the compiled templates instead of comparing them.",
+	)
+	f.envFlag.Bind(local, global)
+	f.global = global
+}
+
+func newInfraSnapshotFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *infraSnapshotFlags {
+	flags := &infraSnapshotFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newInfraSnapshotCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot",
+		Short: "Compare the compiled infrastructure templates to their snapshot.",
+		Long: `Compare the compiled infrastructure templates to their snapshot.
+
+The Bicep or Terraform templates are compiled to canonical JSON and compared to the snapshot stored in the .snapshots
+directory of the infra path. The command fails when the compiled templates don't match the snapshot, so unexpected
+changes of the templates are caught by CI. Run the command with --update to create or update the snapshot.`,
+	}
+}
+
+type infraSnapshotAction struct {
+	flags          *infraSnapshotFlags
+	projectConfig  *project.ProjectConfig
+	env            *environment.Environment
+	azCli          azcli.AzCli
+	accountManager account.Manager
+	console        input.Console
+	commandRunner  exec.CommandRunner
+}
+
+func newInfraSnapshotAction(
+	flags *infraSnapshotFlags,
+	projectConfig *project.ProjectConfig,
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	accountManager account.Manager,
+	console input.Console,
+	commandRunner exec.CommandRunner,
+) actions.Action {
+	return &infraSnapshotAction{
+		flags:          flags,
+		projectConfig:  projectConfig,
+		env:            env,
+		azCli:          azCli,
+		accountManager: accountManager,
+		console:        console,
+		commandRunner:  commandRunner,
+	}
+}
+
+func (a *infraSnapshotAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Comparing the infrastructure to its snapshot (azd infra snapshot)",
+	})
+
+	infraManager, err := provisioning.NewManager(
+		ctx,
+		a.env,
+		a.projectConfig.Path,
+		a.projectConfig.Infra,
+		a.console.IsUnformatted(),
+		a.azCli,
+		a.console,
+		a.commandRunner,
+		a.accountManager,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating provisioning manager: %w", err)
+	}
+
+	a.console.ShowSpinner(ctx, "Compiling templates", input.Step)
+	snapshot, err := infraManager.Snapshot(ctx)
+	a.console.StopSpinner(ctx, "Compiling templates", input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, fmt.Errorf("compiling snapshot: %w", err)
+	}
+
+	snapshotPath := provisioning.SnapshotPath(a.projectConfig.Path, a.projectConfig.Infra)
+	relativePath, err := filepath.Rel(a.projectConfig.Path, snapshotPath)
+	if err != nil {
+		relativePath = snapshotPath
+	}
+
+	expected, err := os.ReadFile(snapshotPath)
+	if errors.Is(err, os.ErrNotExist) && !a.flags.update {
+		return nil, fmt.Errorf(
+			"the infrastructure has no snapshot at '%s', run 'azd infra snapshot --update' to create it",
+			relativePath,
+		)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	if a.flags.update {
+		if bytes.Equal(expected, snapshot) {
+			return &actions.ActionResult{
+				Message: &actions.ResultMessage{Header: fmt.Sprintf("The snapshot %s is up to date.", relativePath)},
+			}, nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(snapshotPath), osutil.PermissionDirectory); err != nil {
+			return nil, fmt.Errorf("creating snapshots directory: %w", err)
+		}
+
+		if err := os.WriteFile(snapshotPath, snapshot, osutil.PermissionFile); err != nil {
+			return nil, fmt.Errorf("writing snapshot: %w", err)
+		}
+
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{Header: fmt.Sprintf("Updated the snapshot %s.", relativePath)},
+		}, nil
+	}
+
+	diff, err := provisioning.SnapshotDiff(expected, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(diff) == 0 {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("The infrastructure matches the snapshot %s.", relativePath),
+			},
+		}, nil
+	}
+
+	changes := diff
+	if len(changes) > maxSnapshotDiffs {
+		changes = append(changes[:maxSnapshotDiffs:maxSnapshotDiffs], fmt.Sprintf("... %d more", len(diff)-maxSnapshotDiffs))
+	}
+
+	a.console.Message(ctx, output.WithWarningFormat("Changed values:"))
+	for _, change := range changes {
+		a.console.Message(ctx, fmt.Sprintf("  - %s", change))
+	}
+
+	return nil, fmt.Errorf(
+		"the compiled infrastructure doesn't match the snapshot %s, %d values changed. If the changes are expected, "+
+			"run 'azd infra snapshot --update' and commit the snapshot",
+		relativePath,
+		len(diff),
+	)
+}
@@ -178,6 +178,12 @@ func (i *initAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 			if err != nil {
 				return nil, fmt.Errorf("init empty repository: %w", err)
 			}
+		} else if err == nil {
+			// Existing projects get the files of the services added since they were initialized, ex) Dockerfiles
+			err = i.repoInitializer.WriteServiceFiles(ctx, azdCtx)
+			if err != nil {
+				return nil, fmt.Errorf("writing service files: %w", err)
+			}
 		}
 	}
 
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/accesspolicy"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The deprecated commands running the actions of other commands, which are restricted as the commands they alias
+var accessPolicyOperationAliases = map[string]string{
+	"infra create": "provision",
+	"infra delete": "down",
+}
+
+// AccessPolicyMiddleware rejects the commands the signed-in identity isn't allowed to run on the selected environment
+// by the access policy of the project, ex) deploying to the production environment from the CLI of a developer.
+// Child actions are checked as well, so 'azd up' requires the identity to be allowed to provision and deploy.
+type AccessPolicyMiddleware struct {
+	lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext]
+	serviceLocator ioc.ServiceLocator
+	options        *Options
+}
+
+// Creates a new instance of the Access Policy middleware
+func NewAccessPolicyMiddleware(
+	lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext],
+	serviceLocator ioc.ServiceLocator,
+	options *Options,
+) Middleware {
+	return &AccessPolicyMiddleware{
+		lazyAzdContext: lazyAzdContext,
+		serviceLocator: serviceLocator,
+		options:        options,
+	}
+}
+
+// Runs the Access Policy middleware
+func (m *AccessPolicyMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	// Commands running outside of a project, ex) azd init, aren't restricted by access policies
+	azdCtx, err := m.lazyAzdContext.GetValue()
+	if err != nil {
+		return next(ctx)
+	}
+
+	policy, err := accesspolicy.Load(azdCtx.ProjectDirectory())
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == nil {
+		return next(ctx)
+	}
+
+	operation := accessPolicyOperation(m.options.CommandPath)
+	envName := environmentName(m.options, azdCtx)
+	if len(policy.MatchingRules(operation, envName)) == 0 {
+		return next(ctx)
+	}
+
+	log.Printf("checking access policy for '%s' on environment '%s'\n", operation, envName)
+
+	err = policy.Authorize(ctx, operation, envName, func(ctx context.Context, groupIds []string) ([]string, error) {
+		return m.memberGroups(ctx, azdCtx, envName, groupIds)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+// Gets the operation of the command matched against the access policy rules, ex) 'infra create' is matched as provision
+func accessPolicyOperation(commandPath string) string {
+	operation := strings.TrimSpace(strings.TrimPrefix(commandPath, "azd"))
+	if alias, has := accessPolicyOperationAliases[operation]; has {
+		return alias
+	}
+
+	return operation
+}
+
+// Gets the groups the signed-in identity is a member of, in the tenant of the subscription of the environment
+func (m *AccessPolicyMiddleware) memberGroups(
+	ctx context.Context,
+	azdCtx *azdcontext.AzdContext,
+	envName string,
+	groupIds []string,
+) ([]string, error) {
+	var userProfileService *azcli.UserProfileService
+	if err := m.serviceLocator.Resolve(&userProfileService); err != nil {
+		return nil, err
+	}
+
+	var subResolver account.SubscriptionTenantResolver
+	if err := m.serviceLocator.Resolve(&subResolver); err != nil {
+		return nil, err
+	}
+
+	// The home tenant of the identity is used when the environment doesn't have a subscription yet
+	tenantId := ""
+	if env, err := environment.GetEnvironment(azdCtx, envName); err == nil && env.GetSubscriptionId() != "" {
+		tenantId, err = subResolver.LookupTenant(ctx, env.GetSubscriptionId())
+		if err != nil {
+			return nil, fmt.Errorf("getting tenant id for subscription %s: %w", env.GetSubscriptionId(), err)
+		}
+	}
+
+	principalId, err := azureutil.GetCurrentPrincipalId(ctx, userProfileService, tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("fetching current user information: %w", err)
+	}
+
+	return userProfileService.CheckMemberGroups(ctx, tenantId, *principalId, groupIds)
+}
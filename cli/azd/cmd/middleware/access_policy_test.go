@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AccessPolicyOperation(t *testing.T) {
+	tests := map[string]string{
+		"azd deploy":       "deploy",
+		"azd env clone":    "env clone",
+		"azd infra create": "provision",
+		"azd infra delete": "down",
+	}
+
+	for commandPath, expected := range tests {
+		t.Run(commandPath, func(t *testing.T) {
+			require.Equal(t, expected, accessPolicyOperation(commandPath))
+		})
+	}
+}
@@ -48,6 +48,14 @@ func NewHooksMiddleware(
 func (m *HooksMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
 	ctx, _ = getServiceHooksRegistered(ctx)
 
+	// Deployments to the targets of a targets file run the hooks in the deployment of each target
+	if m.options.Flags != nil {
+		if flag := m.options.Flags.Lookup("targets"); flag != nil && flag.Value.String() != "" {
+			log.Println("deploying to targets, skipping all hook registrations.")
+			return next(ctx)
+		}
+	}
+
 	env, err := m.lazyEnv.GetValue()
 	if err != nil {
 		log.Println("azd environment is not available, skipping all hook registrations.")
@@ -144,6 +152,15 @@ func (m *HooksMiddleware) registerServiceHooks(
 			service.Hooks,
 			env,
 		)
+		if scope := service.EnvScope(); scope != nil {
+			serviceHooksRunner.WithEnvScope(*scope)
+		}
+
+		secretEnvVars, err := service.SecretEnviron(env)
+		if err != nil {
+			return fmt.Errorf("getting secrets of service '%s': %w", serviceName, err)
+		}
+		serviceHooksRunner.WithEnvVars(secretEnvVars...)
 
 		for hookName, hookConfig := range service.Hooks {
 			hookType, eventName, err := inferHookType(hookName, hookConfig)
@@ -30,12 +30,15 @@ type MiddlewareContext interface {
 
 // Middleware Run options
 type Options struct {
-	CommandPath   string
-	Name          string
-	Aliases       []string
-	Flags         *pflag.FlagSet
-	Args          []string
-	isChildAction bool
+	CommandPath string
+	Name        string
+	Aliases     []string
+	Flags       *pflag.FlagSet
+	Args        []string
+	// The name of the environment of child actions running against another environment than the environment selected
+	// by the flags of the command, ex) the new environment of azd env clone
+	EnvironmentName string
+	isChildAction   bool
 }
 
 func (o *Options) IsChildAction() bool {
@@ -81,8 +84,12 @@ func (r *MiddlewareRunner) RunAction(
 
 	var nextFn NextFn
 
+	// Child actions share the request cache of the command that runs them
+	ctx, requestCache := withRequestCache(ctx)
+
 	actionContainer := ioc.NewNestedContainer(r.container)
 	ioc.RegisterInstance(actionContainer, runOptions)
+	ioc.RegisterInstance(actionContainer, requestCache)
 
 	// This recursive function executes the middleware chain in the order that
 	// the middlewares were registered. nextFn is passed into the middleware run
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -176,6 +177,81 @@ func Test_Middleware_RunChildAction(t *testing.T) {
 	require.True(t, *actionRan)
 }
 
+func Test_Middleware_RequestCache(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	middlewareRunner := NewMiddlewareRunner(ioc.NewNestedContainer(nil))
+
+	var middlewareCache *RequestCache
+	_ = middlewareRunner.Use("cache", func(requestCache *RequestCache) Middleware {
+		middlewareCache = requestCache
+		return middlewareFunc(func(ctx context.Context, nextFn NextFn) (*actions.ActionResult, error) {
+			requestCache.Set("subscription", "SUBSCRIPTION_ID")
+			return nextFn(ctx)
+		})
+	})
+
+	childCaches := []*RequestCache{}
+	childAction := actionFunc(func(ctx context.Context) (*actions.ActionResult, error) {
+		cache, ok := RequestCacheFromContext(ctx)
+		require.True(t, ok)
+		childCaches = append(childCaches, cache)
+
+		return nil, nil
+	})
+
+	action := actionFunc(func(ctx context.Context) (*actions.ActionResult, error) {
+		cache, ok := RequestCacheFromContext(ctx)
+		require.True(t, ok)
+
+		value, has := cache.Get("subscription")
+		require.True(t, has)
+		require.Equal(t, "SUBSCRIPTION_ID", value)
+
+		// Child actions share the cache of the parent action
+		_, err := middlewareRunner.RunChildAction(ctx, &Options{Name: "child"}, childAction)
+		require.NoError(t, err)
+
+		return nil, nil
+	})
+
+	_, err := middlewareRunner.RunAction(*mockContext.Context, &Options{Name: "test"}, action)
+	require.NoError(t, err)
+	require.Len(t, childCaches, 1)
+	require.Same(t, middlewareCache, childCaches[0])
+
+	// A new command invocation gets a new cache
+	_, err = middlewareRunner.RunAction(*mockContext.Context, &Options{Name: "test"}, childAction)
+	require.NoError(t, err)
+	require.Len(t, childCaches, 2)
+	require.NotSame(t, childCaches[0], childCaches[1])
+}
+
+func Test_RequestCache_GetOrSet(t *testing.T) {
+	cache := newRequestCache()
+	calls := 0
+	valueFn := func() (any, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("transient error")
+		}
+
+		return calls, nil
+	}
+
+	_, err := cache.GetOrSet("key", valueFn)
+	require.Error(t, err)
+
+	// Errors aren't cached
+	value, err := cache.GetOrSet("key", valueFn)
+	require.NoError(t, err)
+	require.Equal(t, 2, value)
+
+	value, err = cache.GetOrSet("key", valueFn)
+	require.NoError(t, err)
+	require.Equal(t, 2, value)
+	require.Equal(t, 2, calls)
+}
+
 func createAction(runLog *[]string) (actions.Action, *bool) {
 	actionRan := false
 
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+)
+
+// The name of the flag used to select the azd environment
+const environmentNameFlag = "environment"
+
+// PolicyHooksMiddleware runs the machine level hooks configured by an organization around any azd command.
+// Unlike project hooks, policy hooks don't require a project and apply to all the projects of the machine.
+type PolicyHooksMiddleware struct {
+	lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext]
+	commandRunner  exec.CommandRunner
+	console        input.Console
+	options        *Options
+	// The path of the machine level policy hooks file
+	policyFilePath string
+}
+
+// Creates a new instance of the Policy Hooks middleware
+func NewPolicyHooksMiddleware(
+	lazyAzdContext *lazy.Lazy[*azdcontext.AzdContext],
+	commandRunner exec.CommandRunner,
+	console input.Console,
+	options *Options,
+) Middleware {
+	return &PolicyHooksMiddleware{
+		lazyAzdContext: lazyAzdContext,
+		commandRunner:  commandRunner,
+		console:        console,
+		options:        options,
+		policyFilePath: ext.PolicyHooksFilePath(),
+	}
+}
+
+// Runs the Policy Hooks middleware
+func (m *PolicyHooksMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	filePath := m.policyFilePath
+	policyHooks, err := ext.LoadPolicyHooks(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading policy hooks, %w", err)
+	}
+
+	if policyHooks == nil || len(policyHooks.Hooks) == 0 {
+		return next(ctx)
+	}
+
+	log.Printf("running policy hooks from '%s'\n", filePath)
+
+	// Policy hooks run from the project directory when a project is available, otherwise from the current directory
+	cwd := ""
+	azdCtx, err := m.lazyAzdContext.GetValue()
+	if err == nil {
+		cwd = azdCtx.ProjectDirectory()
+	}
+
+	hooksRunner := ext.NewHooksRunner(
+		ext.NewHooksManager(cwd),
+		m.commandRunner,
+		m.console,
+		cwd,
+		policyHooks.Hooks,
+		m.loadEnvironment(azdCtx),
+	).WithEnvVars(fmt.Sprintf("AZD_COMMAND=%s", m.options.CommandPath))
+
+	var actionResult *actions.ActionResult
+
+	commandNames := []string{m.options.CommandPath}
+	commandNames = append(commandNames, m.options.Aliases...)
+
+	// Wildcard hooks run once around the command invoked by the user, not around each of its child actions
+	if !m.options.IsChildAction() {
+		commandNames = append(commandNames, ext.HookCommandWildcard)
+	}
+
+	err = hooksRunner.Invoke(ctx, commandNames, func() error {
+		result, err := next(ctx)
+		if err != nil {
+			return err
+		}
+
+		actionResult = result
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return actionResult, nil
+}
+
+// Loads the environment selected for the command when available, so its values are exposed to the policy hooks.
+// Policy hooks also run for commands that don't have an environment, in which case an ephemeral environment is used.
+func (m *PolicyHooksMiddleware) loadEnvironment(azdCtx *azdcontext.AzdContext) *environment.Environment {
+	if azdCtx == nil {
+		return environment.Ephemeral()
+	}
+
+	envName := environmentName(m.options, azdCtx)
+	if envName == "" {
+		return environment.Ephemeral()
+	}
+
+	env, err := environment.GetEnvironment(azdCtx, envName)
+	if err != nil {
+		log.Printf("environment '%s' is not available to policy hooks: %v\n", envName, err)
+		return environment.Ephemeral()
+	}
+
+	return env
+}
+
+// Gets the name of the environment selected for the command, from the options of child actions, the environment flag of
+// the command or the default environment of the project. Returns an empty string when no environment is selected.
+func environmentName(options *Options, azdCtx *azdcontext.AzdContext) string {
+	if options.EnvironmentName != "" {
+		return options.EnvironmentName
+	}
+
+	if options.Flags != nil {
+		if flag := options.Flags.Lookup(environmentNameFlag); flag != nil && flag.Value.String() != "" {
+			return flag.Value.String()
+		}
+	}
+
+	defaultEnvName, err := azdCtx.GetDefaultEnvironmentName()
+	if err != nil {
+		return ""
+	}
+
+	return defaultEnvName
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicyHooks = `
+hooks:
+  pre*:
+    shell: sh
+    run: scripts/validate.sh
+  postdeploy:
+    shell: sh
+    run: echo 'registering deployment'
+`
+
+func Test_PolicyHooks_Middleware(t *testing.T) {
+	t.Run("RunsMatchingHooks", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdContext := createAzdContext(t)
+		policyDir := setupPolicyHooks(t, testPolicyHooks)
+		policyFilePath := filepath.Join(policyDir, "hooks.yaml")
+
+		err := ensureAzdValid(*mockContext.Context, azdContext, "test", &project.ProjectConfig{Name: "test"})
+		require.NoError(t, err)
+
+		scripts := []string{}
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return true
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			scripts = append(scripts, args.Args[0])
+			require.Equal(t, azdContext.ProjectDirectory(), args.Cwd)
+			require.Contains(t, args.Env, "AZD_COMMAND=azd deploy")
+			require.Contains(t, args.Env, "AZURE_ENV_NAME=test")
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		flags := pflag.NewFlagSet("deploy", pflag.ContinueOnError)
+		flags.String(environmentNameFlag, "test", "")
+
+		nextFn, actionRan := createNextFn()
+		runOptions := &Options{CommandPath: "azd deploy", Flags: flags}
+		result, err := runPolicyHooksMiddleware(mockContext, azdContext, policyFilePath, runOptions, nextFn)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, *actionRan)
+
+		require.Len(t, scripts, 2)
+		require.Equal(t, filepath.Join(policyDir, "scripts", "validate.sh"), scripts[0])
+		require.Contains(t, scripts[1], "azd-postdeploy")
+	})
+
+	t.Run("NoPolicyFile", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdContext := createAzdContext(t)
+		policyFilePath := filepath.Join(t.TempDir(), "hooks.yaml")
+
+		hookRan := setupHookMock(mockContext, 0)
+		nextFn, actionRan := createNextFn()
+		runOptions := &Options{CommandPath: "azd deploy"}
+		result, err := runPolicyHooksMiddleware(mockContext, azdContext, policyFilePath, runOptions, nextFn)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, *actionRan)
+		require.False(t, *hookRan)
+	})
+
+	t.Run("PreHookFailureBlocksCommand", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdContext := createAzdContext(t)
+		policyFilePath := filepath.Join(setupPolicyHooks(t, testPolicyHooks), "hooks.yaml")
+
+		hookRan := setupHookMock(mockContext, 1)
+		nextFn, actionRan := createNextFn()
+		runOptions := &Options{CommandPath: "azd up"}
+		result, err := runPolicyHooksMiddleware(mockContext, azdContext, policyFilePath, runOptions, nextFn)
+		require.Error(t, err)
+		require.Nil(t, result)
+		require.True(t, *hookRan)
+		require.False(t, *actionRan)
+	})
+
+	t.Run("WildcardSkippedForChildActions", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azdContext := createAzdContext(t)
+		policyFilePath := filepath.Join(setupPolicyHooks(t, testPolicyHooks), "hooks.yaml")
+
+		hookRan := setupHookMock(mockContext, 0)
+		nextFn, actionRan := createNextFn()
+		runOptions := &Options{CommandPath: "azd provision", isChildAction: true}
+		result, err := runPolicyHooksMiddleware(mockContext, azdContext, policyFilePath, runOptions, nextFn)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, *actionRan)
+		require.False(t, *hookRan)
+	})
+}
+
+// Writes the policy hooks file and its scripts to a temp directory, and returns the directory
+func setupPolicyHooks(t *testing.T, contents string) string {
+	policyDir := t.TempDir()
+	scriptsDir := filepath.Join(policyDir, "scripts")
+	require.NoError(t, os.MkdirAll(scriptsDir, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(scriptsDir, "validate.sh"), []byte("exit 0"), osutil.PermissionExecutableFile))
+
+	filePath := filepath.Join(policyDir, "hooks.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(strings.TrimSpace(contents)), osutil.PermissionFile))
+
+	return policyDir
+}
+
+func runPolicyHooksMiddleware(
+	mockContext *mocks.MockContext,
+	azdContext *azdcontext.AzdContext,
+	policyFilePath string,
+	runOptions *Options,
+	nextFn NextFn,
+) (*actions.ActionResult, error) {
+	lazyAzdContext := lazy.NewLazy(func() (*azdcontext.AzdContext, error) {
+		return azdContext, nil
+	})
+
+	middleware := &PolicyHooksMiddleware{
+		lazyAzdContext: lazyAzdContext,
+		commandRunner:  mockContext.CommandRunner,
+		console:        mockContext.Console,
+		options:        runOptions,
+		policyFilePath: policyFilePath,
+	}
+
+	return middleware.Run(*mockContext.Context, nextFn)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+)
+
+var (
+	registryMu    sync.Mutex
+	registrations []*actions.MiddlewareRegistration
+)
+
+// Register adds a middleware component that runs around every action, in addition to the built-in middleware.
+// Extensions and applications embedding azd call Register before the root command is created, ex) from an init function,
+// to add request-scoped caching, metrics or additional validation to all commands.
+//
+// The resolver is a go function that returns a [Middleware] and may take any registered dependency as a parameter,
+// ex) *Options, input.Console or *RequestCache. The optional predicate controls which actions the middleware runs for.
+// Registered middleware runs in the order it was registered, after the built-in middleware and before the action.
+func Register(name string, resolver any, predicate actions.UseMiddlewareWhenPredicate) error {
+	if name == "" {
+		return errors.New("middleware name is required")
+	}
+
+	if resolver == nil {
+		return fmt.Errorf("middleware '%s' requires a resolver", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, registration := range registrations {
+		if registration.Name == name {
+			return fmt.Errorf("middleware '%s' is already registered", name)
+		}
+	}
+
+	registrations = append(registrations, &actions.MiddlewareRegistration{
+		Name:      name,
+		Resolver:  resolver,
+		Predicate: predicate,
+	})
+
+	return nil
+}
+
+// Registrations gets the middleware added with Register, in the order it was registered
+func Registrations() []*actions.MiddlewareRegistration {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	return append([]*actions.MiddlewareRegistration{}, registrations...)
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Register(t *testing.T) {
+	original := registrations
+	t.Cleanup(func() {
+		registrations = original
+	})
+	registrations = nil
+
+	resolver := func(options *Options) Middleware {
+		return middlewareFunc(func(ctx context.Context, nextFn NextFn) (*actions.ActionResult, error) {
+			return nextFn(ctx)
+		})
+	}
+
+	require.NoError(t, Register("metrics", resolver, nil))
+	require.NoError(t, Register("validation", resolver, func(descriptor *actions.ActionDescriptor) bool {
+		return descriptor.Name == "deploy"
+	}))
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		require.Error(t, Register("metrics", resolver, nil))
+	})
+
+	t.Run("MissingResolver", func(t *testing.T) {
+		require.Error(t, Register("cache", nil, nil))
+	})
+
+	registered := Registrations()
+	require.Len(t, registered, 2)
+	require.Equal(t, "metrics", registered[0].Name)
+	require.Nil(t, registered[0].Predicate)
+	require.Equal(t, "validation", registered[1].Name)
+	require.True(t, registered[1].Predicate(actions.NewActionDescriptor("deploy", nil)))
+
+	// Changes to the returned registrations don't change the registry
+	registered[0] = nil
+	require.NotNil(t, Registrations()[0])
+}
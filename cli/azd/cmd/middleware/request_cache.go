@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+)
+
+type requestCacheKey struct{}
+
+// RequestCache stores values for the duration of a single command invocation. The cache is shared by the middleware
+// and the child actions of the command, ex) the provision and deploy actions run by 'azd up', so values such as
+// access tokens or resource lookups are computed once per command instead of once per action.
+type RequestCache struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func newRequestCache() *RequestCache {
+	return &RequestCache{
+		values: map[string]any{},
+	}
+}
+
+// Gets the value stored for the key
+func (c *RequestCache) Get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	value, has := c.values[key]
+	return value, has
+}
+
+// Stores the value for the key, replacing any existing value
+func (c *RequestCache) Set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] = value
+}
+
+// Gets the value stored for the key, or computes and stores the value with valueFn when the key isn't cached.
+// Errors returned from valueFn aren't cached.
+func (c *RequestCache) GetOrSet(key string, valueFn func() (any, error)) (any, error) {
+	if value, has := c.Get(key); has {
+		return value, nil
+	}
+
+	value, err := valueFn()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set(key, value)
+	return value, nil
+}
+
+// RequestCacheFromContext gets the request cache of the command running with the context
+func RequestCacheFromContext(ctx context.Context) (*RequestCache, bool) {
+	cache, ok := ctx.Value(requestCacheKey{}).(*RequestCache)
+	return cache, ok
+}
+
+// Gets the request cache of the context, or creates a new request cache for a new command invocation
+func withRequestCache(ctx context.Context) (context.Context, *RequestCache) {
+	if cache, ok := RequestCacheFromContext(ctx); ok {
+		return ctx, cache
+	}
+
+	cache := newRequestCache()
+	return context.WithValue(ctx, requestCacheKey{}, cache), cache
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+)
+
+// ServiceEnvironmentMiddleware applies the service overrides declared for the selected environment, ex) the host of a
+// service in the environment, to the project before the action runs. The middleware is only used by the actions that
+// require an environment, since resolving the environment prompts for one when it isn't initialized.
+type ServiceEnvironmentMiddleware struct {
+	env           *environment.Environment
+	projectConfig *project.ProjectConfig
+	options       *Options
+}
+
+// Creates a new instance of the Service Environment middleware
+func NewServiceEnvironmentMiddleware(
+	env *environment.Environment,
+	projectConfig *project.ProjectConfig,
+	options *Options,
+) Middleware {
+	return &ServiceEnvironmentMiddleware{
+		env:           env,
+		projectConfig: projectConfig,
+		options:       options,
+	}
+}
+
+// Runs the Service Environment middleware
+func (m *ServiceEnvironmentMiddleware) Run(ctx context.Context, next NextFn) (*actions.ActionResult, error) {
+	// The overrides are applied once by the command running the child action
+	if !m.options.IsChildAction() {
+		// Exposes the overrides of the environment, ex) SERVICE_API_HOST, to the infrastructure
+		if err := m.projectConfig.ApplyEnvironment(m.env); err != nil {
+			return nil, err
+		}
+	}
+
+	return next(ctx)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceEnvironment_Middleware(t *testing.T) {
+	projectConfig, err := project.Parse(context.Background(), `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+    environments:
+      prod:
+        host: aks
+`)
+	require.NoError(t, err)
+	projectConfig.Path = t.TempDir()
+
+	t.Run("AppliesOverrides", func(t *testing.T) {
+		env := environment.EphemeralWithValues("prod", nil)
+		middleware := NewServiceEnvironmentMiddleware(env, projectConfig, &Options{CommandPath: "azd provision"})
+
+		nextFn, actionRan := createNextFn()
+		_, err := middleware.Run(context.Background(), nextFn)
+		require.NoError(t, err)
+		require.True(t, *actionRan)
+		require.Equal(t, project.AksTarget, projectConfig.Services["api"].Host)
+		require.Equal(t, "aks", env.GetServiceProperty("api", "HOST"))
+	})
+
+	t.Run("SkipsChildActions", func(t *testing.T) {
+		env := environment.EphemeralWithValues("dev", nil)
+		options := &Options{CommandPath: "azd provision"}
+		options.isChildAction = true
+		middleware := NewServiceEnvironmentMiddleware(env, projectConfig, options)
+
+		nextFn, actionRan := createNextFn()
+		_, err := middleware.Run(context.Background(), nextFn)
+		require.NoError(t, err)
+		require.True(t, *actionRan)
+		require.Equal(t, "", env.GetServiceProperty("api", "HOST"))
+	})
+}
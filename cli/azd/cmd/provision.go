@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
@@ -78,6 +79,7 @@ type provisionAction struct {
 	accountManager  account.Manager
 	projectManager  project.ProjectManager
 	resourceManager project.ResourceManager
+	changeTracker   *project.ChangeTracker
 	azdCtx          *azdcontext.AzdContext
 	azCli           azcli.AzCli
 	env             *environment.Environment
@@ -93,6 +95,7 @@ func newProvisionAction(
 	accountManager account.Manager,
 	projectManager project.ProjectManager,
 	resourceManager project.ResourceManager,
+	changeTracker *project.ChangeTracker,
 	azdCtx *azdcontext.AzdContext,
 	projectConfig *project.ProjectConfig,
 	azCli azcli.AzCli,
@@ -107,6 +110,7 @@ func newProvisionAction(
 		accountManager:  accountManager,
 		projectManager:  projectManager,
 		resourceManager: resourceManager,
+		changeTracker:   changeTracker,
 		azdCtx:          azdCtx,
 		azCli:           azCli,
 		env:             env,
@@ -183,6 +187,11 @@ func (p *provisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 		return nil, fmt.Errorf("deployment failed: %w", err)
 	}
 
+	// Failing to record the provisioning doesn't fail it, it only affects the changes reported by `azd show`
+	if err := p.changeTracker.RecordProvision(p.projectConfig); err != nil {
+		log.Printf("failed recording the provisioned infrastructure: %v", err)
+	}
+
 	for _, svc := range p.projectConfig.Services {
 		eventArgs := project.ServiceLifecycleEventArgs{
 			Project: p.projectConfig,
@@ -190,6 +199,7 @@ func (p *provisionAction) Run(ctx context.Context) (*actions.ActionResult, error
 			Args: map[string]any{
 				"bicepOutput": deployResult.Deployment.Outputs,
 			},
+			Outputs: deployResult.Deployment.Outputs,
 		}
 
 		if err := svc.RaiseEvent(ctx, project.ServiceEventEnvUpdated, eventArgs); err != nil {
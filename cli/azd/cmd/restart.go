@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type restartFlags struct {
+	global *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (r *restartFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	r.envFlag.Bind(local, global)
+	r.global = global
+}
+
+func newRestartFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *restartFlags {
+	flags := &restartFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newRestartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restart <service>",
+		Short: "Restart the Azure resource hosting a service.",
+		Args:  cobra.ExactArgs(1),
+	}
+}
+
+type restartAction struct {
+	flags          *restartFlags
+	args           []string
+	projectConfig  *project.ProjectConfig
+	serviceManager project.ServiceManager
+	console        input.Console
+}
+
+func newRestartAction(
+	flags *restartFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	serviceManager project.ServiceManager,
+	console input.Console,
+) actions.Action {
+	return &restartAction{
+		flags:          flags,
+		args:           args,
+		projectConfig:  projectConfig,
+		serviceManager: serviceManager,
+		console:        console,
+	}
+}
+
+func (r *restartAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	// The instances of the service are restarted too
+	servicesToRestart, err := r.projectConfig.SelectServices(r.args[0], "")
+	if err != nil {
+		return nil, err
+	}
+
+	r.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Restarting services (azd restart)",
+	})
+
+	for _, svc := range servicesToRestart {
+		stepMessage := fmt.Sprintf("Restarting service %s", svc.Name)
+		r.console.ShowSpinner(ctx, stepMessage, input.Step)
+		if err := r.serviceManager.Restart(ctx, svc); err != nil {
+			r.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+			return nil, err
+		}
+
+		r.console.StopSpinner(ctx, stepMessage, input.StepDone)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Your service %s was restarted.", r.args[0]),
+		},
+	}, nil
+}
+
+func getCmdRestartHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription("Restart the Azure resource hosting a service.", []string{
+		formatHelpNote("Container apps restart their latest revision, app services and function apps restart their" +
+			" app, and services hosted on AKS restart the pods of their deployment with a rollout."),
+		formatHelpNote(fmt.Sprintf("The resource of the service is looked up in the environment, run %s first"+
+			" when the service isn't provisioned yet.", output.WithHighLightFormat("azd provision"))),
+	})
+}
+
+func getCmdRestartHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Restart the api service.": output.WithHighLightFormat("azd restart api"),
+		"Restart the api service of the prod environment.": output.WithHighLightFormat(
+			"azd restart api --environment prod"),
+	})
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/pkg/project"
-	"github.com/azure/azure-dev/cli/azd/pkg/spin"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
@@ -24,8 +23,11 @@ import (
 )
 
 type restoreFlags struct {
-	global      *internal.GlobalCommandOptions
-	serviceName string
+	global          *internal.GlobalCommandOptions
+	serviceName     string
+	group           string
+	concurrency     int
+	generateClients bool
 	envFlag
 }
 
@@ -39,6 +41,24 @@ func (r *restoreFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommand
 	)
 	//deprecate:flag hide --service
 	_ = local.MarkHidden("service")
+	local.StringVar(
+		&r.group,
+		"group",
+		"",
+		"Restores the services of a group (the services with the matching 'group' in "+azdcontext.ProjectFileName+").",
+	)
+	local.IntVar(
+		&r.concurrency,
+		"concurrency",
+		0,
+		"The maximum number of services restored concurrently (defaults to the number of CPUs).",
+	)
+	local.BoolVar(
+		&r.generateClients,
+		"generate-clients",
+		false,
+		"Generates the clients of the OpenAPI descriptions of the services into the services consuming them before restoring.",
+	)
 }
 
 func newRestoreFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *restoreFlags {
@@ -66,6 +86,8 @@ type restoreAction struct {
 	env            *environment.Environment
 	projectConfig  *project.ProjectConfig
 	serviceManager project.ServiceManager
+	builds         *project.BuildOrchestrator
+	clients        *project.OpenApiClientGenerator
 	commandRunner  exec.CommandRunner
 }
 
@@ -78,6 +100,8 @@ func newRestoreAction(
 	env *environment.Environment,
 	projectConfig *project.ProjectConfig,
 	serviceManager project.ServiceManager,
+	builds *project.BuildOrchestrator,
+	clients *project.OpenApiClientGenerator,
 	commandRunner exec.CommandRunner,
 ) actions.Action {
 	return &restoreAction{
@@ -87,6 +111,8 @@ func newRestoreAction(
 		azdCtx:         azdCtx,
 		projectConfig:  projectConfig,
 		serviceManager: serviceManager,
+		builds:         builds,
+		clients:        clients,
 		azCli:          azCli,
 		env:            env,
 		commandRunner:  commandRunner,
@@ -106,59 +132,71 @@ func (r *restoreAction) Run(ctx context.Context) (*actions.ActionResult, error)
 		targetServiceName = r.args[0]
 	}
 
-	if targetServiceName != "" && !r.projectConfig.HasService(targetServiceName) {
-		return nil, fmt.Errorf("service name '%s' doesn't exist", targetServiceName)
+	servicesToRestore, err := r.projectConfig.SelectServices(targetServiceName, r.flags.group)
+	if err != nil {
+		return nil, err
 	}
 
-	count := 0
-
 	// Collect all the tools we will need to do the restore and validate that
-	// the are installed. When a single project is being deployed, we need just
-	// the tools for that project, otherwise we need the tools from all project.
+	// the are installed. When a single project or group is being restored, we need just
+	// the tools for those projects, otherwise we need the tools from all project.
 	allTools := []tools.ExternalTool{}
-	for _, svc := range r.projectConfig.Services {
-		if targetServiceName == "" || targetServiceName == svc.Name {
-			requiredTools, err := r.serviceManager.GetRequiredTools(ctx, svc)
-			if err != nil {
-				return nil, fmt.Errorf("failed getting required tools, %w", err)
-			}
-
-			allTools = append(allTools, requiredTools...)
+	for _, svc := range servicesToRestore {
+		requiredTools, err := r.serviceManager.GetRequiredTools(ctx, svc)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting required tools, %w", err)
 		}
+
+		allTools = append(allTools, requiredTools...)
 	}
 
 	if err := tools.EnsureInstalled(ctx, tools.Unique(allTools)...); err != nil {
 		return nil, err
 	}
 
-	for _, svc := range r.projectConfig.Services {
-		if targetServiceName != "" && svc.Name != targetServiceName {
-			continue
-		}
+	if r.flags.generateClients {
+		for _, svc := range servicesToRestore {
+			if !r.clients.HasClients(svc) {
+				continue
+			}
 
-		installMsg := fmt.Sprintf("Installing dependencies for %s service...", svc.Name)
-		spinner := spin.NewSpinner(r.console.Handles().Stdout, installMsg)
-		if err := spinner.Run(func() error {
-			restoreTask := r.serviceManager.Restore(ctx, svc)
-			go func() {
-				for progress := range restoreTask.Progress() {
-					log.Printf("Restore progress: %s\n", progress.Message)
-				}
-			}()
-			_, err := restoreTask.Await()
+			stepMessage := fmt.Sprintf("Generating API clients for %s service", svc.Name)
+			r.console.ShowSpinner(ctx, stepMessage, input.Step)
+			err := r.clients.Generate(ctx, svc)
+			r.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
 			if err != nil {
-				return err
+				return nil, err
 			}
-			return nil
-		}); err != nil {
-			return nil, err
 		}
+	}
+
+	// Services are restored concurrently, their progress is interleaved in the spinner
+	restoringMessage := "Installing dependencies"
+	r.console.ShowSpinner(ctx, restoringMessage, input.Step)
+
+	r.builds.SetMaxConcurrency(r.flags.concurrency)
+	restoreTask := r.builds.Restore(ctx, servicesToRestore, r.serviceManager.Restore)
+	restored := 0
+	for progress := range restoreTask.Progress() {
+		if !progress.Done {
+			log.Printf("Restore progress of %s: %s\n", progress.Service.Name, progress.Message)
+			updatedMessage := fmt.Sprintf("Installing dependencies for %s service (%s)", progress.Service.Name, progress.Message)
+			r.console.ShowSpinner(ctx, updatedMessage, input.Step)
+			continue
+		}
+
+		stepMessage := fmt.Sprintf("Installing dependencies for %s service", progress.Service.Name)
+		r.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(progress.Error))
 
-		count++
+		restored++
+		if restored < len(servicesToRestore) {
+			r.console.ShowSpinner(ctx, restoringMessage, input.Step)
+		}
 	}
 
-	if targetServiceName != "" && count == 0 {
-		return nil, fmt.Errorf("Dependencies were not restored (%s service was not found)", targetServiceName)
+	if _, err := restoreTask.Await(); err != nil {
+		r.console.StopSpinner(ctx, "", input.StepDone)
+		return nil, err
 	}
 
 	return nil, nil
@@ -184,5 +222,7 @@ func getCmdRestoreHelpFooter(*cobra.Command) string {
 			"dependency, Individual services are listed in your azure.yaml file.": fmt.Sprintf("%s %s",
 			output.WithHighLightFormat("azd restore <service>"),
 			output.WithWarningFormat("[Service name]")),
+		"Generates the clients of the OpenAPI descriptions of the services and downloads and installs all " +
+			"application dependencies.": output.WithHighLightFormat("azd restore --generate-clients"),
 	})
 }
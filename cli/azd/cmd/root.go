@@ -124,13 +124,24 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		},
 	})
 
+	root.Add("upgrade", &actions.ActionDescriptorOptions{
+		Command:        newUpgradeCmd(),
+		ActionResolver: newUpgradeAction,
+		FlagsResolver:  newUpgradeFlags,
+		OutputFormats:  []output.Format{output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupAbout,
+		},
+	})
+
 	root.Add("show", &actions.ActionDescriptorOptions{
 		Command:        newShowCmd(),
 		FlagsResolver:  newShowFlags,
 		ActionResolver: newShowAction,
 		OutputFormats:  []output.Format{output.JsonFormat},
 		DefaultFormat:  output.NoneFormat,
-	})
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	root.
 		Add("restore", &actions.ActionDescriptorOptions{
@@ -145,7 +156,23 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 				RootLevelHelp: actions.CmdGroupConfig,
 			},
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	root.Add("validate", &actions.ActionDescriptorOptions{
+		Command:        newValidateCmd(),
+		FlagsResolver:  newValidateFlags,
+		ActionResolver: newValidateAction,
+		OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+		DefaultFormat:  output.NoneFormat,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdValidateHelpDescription,
+			Footer:      getCmdValidateHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupConfig,
+		},
+	})
 
 	//deprecate:cmd hide login
 	login := newLoginCmd("")
@@ -194,7 +221,8 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 				RootLevelHelp: actions.CmdGroupManage,
 			},
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	root.
 		Add("deploy", &actions.ActionDescriptorOptions{
@@ -211,7 +239,8 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 				RootLevelHelp: actions.CmdGroupManage,
 			},
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	root.
 		Add("up", &actions.ActionDescriptorOptions{
@@ -227,7 +256,47 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 				RootLevelHelp: actions.CmdGroupManage,
 			},
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	root.Add("restart", &actions.ActionDescriptorOptions{
+		Command:        newRestartCmd(),
+		FlagsResolver:  newRestartFlags,
+		ActionResolver: newRestartAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdRestartHelpDescription,
+			Footer:      getCmdRestartHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupManage,
+		},
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	root.Add("scale", &actions.ActionDescriptorOptions{
+		Command:        newScaleCmd(),
+		FlagsResolver:  newScaleFlags,
+		ActionResolver: newScaleAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdScaleHelpDescription,
+			Footer:      getCmdScaleHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupManage,
+		},
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	root.Add("watch", &actions.ActionDescriptorOptions{
+		Command:        newWatchCmd(),
+		FlagsResolver:  newWatchFlags,
+		ActionResolver: newWatchAction,
+		HelpOptions: actions.ActionHelpOptions{
+			Description: getCmdWatchHelpDescription,
+			Footer:      getCmdWatchHelpFooter,
+		},
+		GroupingOptions: actions.CommandGroupOptions{
+			RootLevelHelp: actions.CmdGroupManage,
+		},
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	root.Add("monitor", &actions.ActionDescriptorOptions{
 		Command:        newMonitorCmd(),
@@ -240,7 +309,25 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 		GroupingOptions: actions.CommandGroupOptions{
 			RootLevelHelp: actions.CmdGroupMonitor,
 		},
-	})
+	}).UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
+
+	root.
+		Add("test", &actions.ActionDescriptorOptions{
+			Command:        newTestCmd(),
+			FlagsResolver:  newTestCmdFlags,
+			ActionResolver: newTestCmdAction,
+			OutputFormats:  []output.Format{output.JsonFormat, output.NoneFormat},
+			DefaultFormat:  output.NoneFormat,
+			HelpOptions: actions.ActionHelpOptions{
+				Description: getCmdTestHelpDescription,
+				Footer:      getCmdTestHelpFooter,
+			},
+			GroupingOptions: actions.CommandGroupOptions{
+				RootLevelHelp: actions.CmdGroupMonitor,
+			},
+		}).
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	root.
 		Add("down", &actions.ActionDescriptorOptions{
@@ -257,7 +344,8 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 				RootLevelHelp: actions.CmdGroupManage,
 			},
 		}).
-		UseMiddleware("hooks", middleware.NewHooksMiddleware)
+		UseMiddleware("hooks", middleware.NewHooksMiddleware).
+		UseMiddleware("serviceenvironment", middleware.NewServiceEnvironmentMiddleware)
 
 	// Register any global middleware defined by the caller
 	if len(middlewareChain) > 0 {
@@ -269,10 +357,17 @@ func NewRootCmd(staticHelp bool, middlewareChain []*actions.MiddlewareRegistrati
 	// Global middleware registration
 	root.
 		UseMiddleware("debug", middleware.NewDebugMiddleware).
+		UseMiddleware("policyhooks", middleware.NewPolicyHooksMiddleware).
+		UseMiddleware("accesspolicy", middleware.NewAccessPolicyMiddleware).
 		UseMiddlewareWhen("telemetry", middleware.NewTelemetryMiddleware, func(descriptor *actions.ActionDescriptor) bool {
 			return !descriptor.Options.DisableTelemetry
 		})
 
+	// Register middleware added by extensions and embedders with middleware.Register
+	for _, registration := range middleware.Registrations() {
+		root.UseMiddlewareWhen(registration.Name, registration.Resolver, registration.Predicate)
+	}
+
 	registerCommonDependencies(ioc.Global)
 	cobraBuilder := NewCobraBuilder(ioc.Global)
 
@@ -0,0 +1,168 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type scaleFlags struct {
+	global   *internal.GlobalCommandOptions
+	replicas int
+	force    bool
+	envFlag
+}
+
+func (s *scaleFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.IntVar(
+		&s.replicas,
+		"replicas",
+		-1,
+		"The number of replicas the service is scaled to (the instances of the app service plan for app services).",
+	)
+	local.BoolVar(
+		&s.force,
+		"force",
+		false,
+		"Does not require confirmation before it scales app service plans hosting other apps.",
+	)
+	s.envFlag.Bind(local, global)
+	s.global = global
+}
+
+func newScaleFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *scaleFlags {
+	flags := &scaleFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newScaleCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scale <service>",
+		Short: "Scale the Azure resource hosting a service to a number of replicas.",
+		Args:  cobra.ExactArgs(1),
+	}
+}
+
+type scaleAction struct {
+	flags          *scaleFlags
+	args           []string
+	projectConfig  *project.ProjectConfig
+	serviceManager project.ServiceManager
+	console        input.Console
+}
+
+func newScaleAction(
+	flags *scaleFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	serviceManager project.ServiceManager,
+	console input.Console,
+) actions.Action {
+	return &scaleAction{
+		flags:          flags,
+		args:           args,
+		projectConfig:  projectConfig,
+		serviceManager: serviceManager,
+		console:        console,
+	}
+}
+
+func (s *scaleAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	if s.flags.replicas < 0 {
+		return nil, errors.New("--replicas is required and can't be negative")
+	}
+
+	// The instances of the service are scaled too
+	servicesToScale, err := s.projectConfig.SelectServices(s.args[0], "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Scaling services (azd scale)",
+	})
+
+	for _, svc := range servicesToScale {
+		stepMessage := fmt.Sprintf("Scaling service %s to %d replica(s)", svc.Name, s.flags.replicas)
+		s.console.ShowSpinner(ctx, stepMessage, input.Step)
+		if err := s.scale(ctx, svc); err != nil {
+			s.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+			return nil, err
+		}
+
+		s.console.StopSpinner(ctx, stepMessage, input.StepDone)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Your service %s was scaled to %d replica(s).", s.args[0], s.flags.replicas),
+		},
+	}, nil
+}
+
+// Scales the service, confirming before scaling an app service plan hosting other apps unless --force is set
+func (s *scaleAction) scale(ctx context.Context, svc *project.ServiceConfig) error {
+	options := project.ScaleOptions{Replicas: s.flags.replicas, Force: s.flags.force}
+	err := s.serviceManager.Scale(ctx, svc, options)
+
+	var sharedPlanErr *azcli.SharedAppServicePlanError
+	if !errors.As(err, &sharedPlanErr) {
+		return err
+	}
+
+	confirm, promptErr := s.console.Confirm(ctx, input.ConsoleOptions{
+		Message: fmt.Sprintf(
+			"The app service plan '%s' of service %s hosts %d other app(s), which will be scaled as well. Continue?",
+			sharedPlanErr.PlanName,
+			svc.Name,
+			sharedPlanErr.Apps-1,
+		),
+		DefaultValue: false,
+	})
+	if promptErr != nil {
+		return promptErr
+	}
+
+	if !confirm {
+		return fmt.Errorf("%w, run with --force to scale it anyway", err)
+	}
+
+	options.Force = true
+	return s.serviceManager.Scale(ctx, svc, options)
+}
+
+func getCmdScaleHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription("Scale the Azure resource hosting a service to a number of replicas.", []string{
+		formatHelpNote("Container apps are scaled to a fixed number of replicas, their minimum and maximum replicas" +
+			" are both set to the number of replicas."),
+		formatHelpNote("App services and function apps scale the instances of their app service plan, the other apps" +
+			" of the plan are scaled as well after confirmation. Consumption plans are scaled automatically and can't" +
+			" be scaled."),
+		formatHelpNote("Services hosted on AKS scale the replicas of their deployment, which may be scaled to 0."),
+		formatHelpNote(fmt.Sprintf("The next %s may scale the service back to the scale set by its infrastructure.",
+			output.WithHighLightFormat("azd provision"))),
+	})
+}
+
+func getCmdScaleHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Scale the api service to 3 replicas.": output.WithHighLightFormat("azd scale api --replicas 3"),
+		"Scale the worker service of the dev environment to 0 replicas.": output.WithHighLightFormat(
+			"azd scale worker --replicas 0 --environment dev"),
+	})
+}
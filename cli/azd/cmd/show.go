@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/internal"
@@ -21,14 +22,39 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
 )
 
+// The interval of the refreshes of the metrics of azd show --watch
+const showWatchInterval = 30 * time.Second
+
 type showFlags struct {
-	global *internal.GlobalCommandOptions
+	changes bool
+	metrics bool
+	watch   bool
+	global  *internal.GlobalCommandOptions
 	envFlag
 }
 
 func (s *showFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&s.changes,
+		"changes",
+		false,
+		"Compares the source, configuration and infrastructure of the project against what was last deployed.",
+	)
+	local.BoolVar(
+		&s.metrics,
+		"metrics",
+		false,
+		"Includes the recent requests, errors, CPU, memory and replicas of the services from Azure Monitor.",
+	)
+	local.BoolVar(
+		&s.watch,
+		"watch",
+		false,
+		fmt.Sprintf("Refreshes the metrics of the services every %s until canceled.", showWatchInterval),
+	)
 	s.envFlag.Bind(local, global)
 	s.global = global
 }
@@ -53,6 +79,8 @@ func newShowCmd() *cobra.Command {
 type showAction struct {
 	projectConfig   *project.ProjectConfig
 	resourceManager project.ResourceManager
+	changeTracker   *project.ChangeTracker
+	metricsReader   *project.ServiceMetricsReader
 	console         input.Console
 	formatter       output.Formatter
 	writer          io.Writer
@@ -69,6 +97,8 @@ func newShowAction(
 	azCli azcli.AzCli,
 	projectConfig *project.ProjectConfig,
 	resourceManager project.ResourceManager,
+	changeTracker *project.ChangeTracker,
+	metricsReader *project.ServiceMetricsReader,
 	azdCtx *azdcontext.AzdContext,
 	env *environment.Environment,
 	flags *showFlags,
@@ -76,6 +106,8 @@ func newShowAction(
 	return &showAction{
 		projectConfig:   projectConfig,
 		resourceManager: resourceManager,
+		changeTracker:   changeTracker,
+		metricsReader:   metricsReader,
 		console:         console,
 		formatter:       formatter,
 		writer:          writer,
@@ -113,6 +145,7 @@ func (s *showAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	resourceManager := infra.NewAzureResourceManager(s.azCli)
 	subId := s.env.GetSubscriptionId()
 	envName := s.env.GetEnvName()
+	serviceResources := map[string][]azcli.AzCliResource{}
 
 	if rgName, err := resourceManager.FindResourceGroupForEnvironment(ctx, subId, envName); err == nil {
 		for svcName, serviceConfig := range s.projectConfig.Services {
@@ -122,6 +155,7 @@ func (s *showAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 					resourceIds[idx] = res.Id
 				}
 
+				serviceResources[svcName] = resources
 				resSvc := res.Services[svcName]
 				resSvc.Target = &contracts.ShowTargetArm{
 					ResourceIds: resourceIds,
@@ -137,7 +171,251 @@ func (s *showAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 			err)
 	}
 
-	return nil, s.formatter.Format(res, s.writer, nil)
+	if s.flags.changes {
+		if err := s.addChanges(ctx, &res); err != nil {
+			return nil, err
+		}
+	}
+
+	withMetrics := s.flags.metrics || s.flags.watch
+	if withMetrics {
+		s.addMetrics(ctx, &res, subId, serviceResources)
+	}
+
+	// The changes and the metrics are readable without a structured output format, unlike the rest of the result
+	readable := s.formatter.Kind() == output.NoneFormat && (s.flags.changes || withMetrics)
+	if !readable {
+		if err := s.formatter.Format(res, s.writer, nil); err != nil {
+			return nil, err
+		}
+	} else {
+		if s.flags.changes {
+			s.displayChanges(ctx, res)
+		}
+
+		if withMetrics {
+			s.displayMetrics(ctx, res)
+		}
+	}
+
+	for s.flags.watch {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case <-time.After(showWatchInterval):
+		}
+
+		s.addMetrics(ctx, &res, subId, serviceResources)
+		if readable {
+			s.console.Message(ctx, "")
+			s.displayMetrics(ctx, res)
+		} else if err := s.formatter.Format(res, s.writer, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Adds the recent health metrics of the resources of each service
+func (s *showAction) addMetrics(
+	ctx context.Context,
+	res *contracts.ShowResult,
+	subscriptionId string,
+	serviceResources map[string][]azcli.AzCliResource,
+) {
+	for name := range s.projectConfig.Services {
+		showSvc := res.Services[name]
+		showSvc.Metrics = nil
+		for _, metric := range s.metricsReader.Read(ctx, subscriptionId, serviceResources[name]) {
+			showSvc.Metrics = append(showSvc.Metrics, contracts.ShowMetric{
+				Kind:       string(metric.Kind),
+				ResourceId: metric.ResourceId,
+				Name:       metric.Name,
+				Value:      metric.Value,
+				Unit:       metric.Unit,
+			})
+		}
+
+		res.Services[name] = showSvc
+	}
+}
+
+func (s *showAction) displayMetrics(ctx context.Context, res contracts.ShowResult) {
+	s.console.Message(ctx, output.WithGrayFormat(
+		"Metrics of the last %s, at %s", project.ServiceMetricsWindow, time.Now().Format(time.TimeOnly)))
+
+	names := make([]string, 0, len(res.Services))
+	for name := range res.Services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		title := output.WithHighLightFormat("Service %s", name)
+		metrics := res.Services[name].Metrics
+		if len(metrics) == 0 {
+			s.console.Message(ctx, fmt.Sprintf("%s: no metrics, the service isn't deployed or its host has no metrics", title))
+			continue
+		}
+
+		s.console.Message(ctx, fmt.Sprintf("%s:", title))
+		for _, metric := range metrics {
+			s.console.Message(ctx, fmt.Sprintf("  %-9s %s", metric.Kind, formatMetricValue(metric.Value, metric.Unit)))
+		}
+	}
+}
+
+// Formats the value of a metric in the unit of the metric, ex) 512.0 MiB for 536870912 Bytes
+func formatMetricValue(value float64, unit string) string {
+	switch unit {
+	case "Count":
+		return fmt.Sprintf("%.0f", value)
+	case "Bytes":
+		return fmt.Sprintf("%.1f MiB", value/(1024*1024))
+	case "NanoCores":
+		return fmt.Sprintf("%.2f cores", value/1e9)
+	case "Percent":
+		return fmt.Sprintf("%.1f%%", value)
+	case "Seconds":
+		return fmt.Sprintf("%.1fs", value)
+	default:
+		return fmt.Sprintf("%g %s", value, unit)
+	}
+}
+
+// Adds the changes of each service since it was last deployed and of the infrastructure since it was last provisioned
+func (s *showAction) addChanges(ctx context.Context, res *contracts.ShowResult) error {
+	for name, svc := range s.projectConfig.Services {
+		changes, err := s.changeTracker.ServiceChanges(ctx, svc)
+		if err != nil {
+			return fmt.Errorf("determining changes of service %s: %w", name, err)
+		}
+
+		showSvc := res.Services[name]
+		showSvc.Changes = &contracts.ShowServiceChanges{
+			LastDeployed:  changes.DeployedAt,
+			Commit:        changes.Commit,
+			CurrentCommit: changes.CurrentCommit,
+			SourceTracked: changes.SourceTracked,
+			Files:         showFileChanges(changes.Files),
+		}
+
+		for _, change := range changes.Config {
+			showSvc.Changes.Config = append(showSvc.Changes.Config, contracts.ShowConfigChange{
+				Key:      change.Key,
+				Previous: change.Previous,
+				Current:  change.Current,
+			})
+		}
+
+		res.Services[name] = showSvc
+	}
+
+	infraChanges, err := s.changeTracker.InfraChanges(s.projectConfig)
+	if err != nil {
+		return fmt.Errorf("determining changes of infrastructure: %w", err)
+	}
+
+	res.Infra = &contracts.ShowInfraChanges{
+		LastProvisioned: infraChanges.ProvisionedAt,
+		Files:           showFileChanges(infraChanges.Files),
+	}
+
+	return nil
+}
+
+func (s *showAction) displayChanges(ctx context.Context, res contracts.ShowResult) {
+	names := make([]string, 0, len(res.Services))
+	for name := range res.Services {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		changes := res.Services[name].Changes
+		title := output.WithHighLightFormat("Service %s", name)
+
+		switch {
+		case changes.LastDeployed == nil:
+			s.console.Message(ctx, fmt.Sprintf("%s: never deployed to this environment", title))
+			continue
+		case !changes.SourceTracked:
+			s.console.Message(ctx, fmt.Sprintf(
+				"%s: source changes since the deployment on %s can't be determined without git",
+				title,
+				changes.LastDeployed.Local().Format(time.RFC1123),
+			))
+		case len(changes.Files) == 0 && len(changes.Config) == 0:
+			s.console.Message(ctx, fmt.Sprintf("%s: no changes since the deployment on %s",
+				title, changes.LastDeployed.Local().Format(time.RFC1123)))
+			continue
+		default:
+			s.console.Message(ctx, fmt.Sprintf(
+				"%s: %d file(s) and %d configuration value(s) changed since the deployment of commit %s on %s",
+				title,
+				len(changes.Files),
+				len(changes.Config),
+				shortCommit(changes.Commit),
+				changes.LastDeployed.Local().Format(time.RFC1123),
+			))
+		}
+
+		displayFileChanges(ctx, s.console, changes.Files)
+		for _, change := range changes.Config {
+			s.console.Message(ctx, fmt.Sprintf(
+				"  %-9s %s: %s -> %s",
+				"config",
+				change.Key,
+				configValueOrUnset(change.Previous),
+				configValueOrUnset(change.Current),
+			))
+		}
+	}
+
+	title := output.WithHighLightFormat("Infrastructure")
+	switch {
+	case res.Infra.LastProvisioned == nil:
+		s.console.Message(ctx, fmt.Sprintf("%s: never provisioned to this environment", title))
+	case len(res.Infra.Files) == 0:
+		s.console.Message(ctx, fmt.Sprintf("%s: no changes since the provisioning on %s",
+			title, res.Infra.LastProvisioned.Local().Format(time.RFC1123)))
+	default:
+		s.console.Message(ctx, fmt.Sprintf("%s: %d file(s) changed since the provisioning on %s",
+			title, len(res.Infra.Files), res.Infra.LastProvisioned.Local().Format(time.RFC1123)))
+		displayFileChanges(ctx, s.console, res.Infra.Files)
+	}
+}
+
+func displayFileChanges(ctx context.Context, console input.Console, changes []contracts.ShowFileChange) {
+	for _, change := range changes {
+		console.Message(ctx, fmt.Sprintf("  %-9s %s", change.Status, change.Path))
+	}
+}
+
+func showFileChanges(changes []project.FileChange) []contracts.ShowFileChange {
+	var result []contracts.ShowFileChange
+	for _, change := range changes {
+		result = append(result, contracts.ShowFileChange{Status: string(change.Status), Path: change.Path})
+	}
+
+	return result
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+
+	return commit
+}
+
+func configValueOrUnset(value string) string {
+	if value == "" {
+		return output.WithGrayFormat("(unset)")
+	}
+
+	return value
 }
 
 func showTypeFromLanguage(language project.ServiceLanguageKind) contracts.ShowType {
@@ -150,8 +428,19 @@ func showTypeFromLanguage(language project.ServiceLanguageKind) contracts.ShowTy
 		return contracts.ShowTypeNode
 	case project.ServiceLanguageJava:
 		return contracts.ShowTypeJava
+	case project.ServiceLanguageGo:
+		return contracts.ShowTypeGo
+	case project.ServiceLanguageRust:
+		return contracts.ShowTypeRust
+	case project.ServiceLanguagePhp:
+		return contracts.ShowTypePhp
+	case project.ServiceLanguageRuby:
+		return contracts.ShowTypeRuby
+	case project.ServiceLanguageCompose:
+		return contracts.ShowTypeCompose
 	default:
-		panic(fmt.Sprintf("unknown language %s", language))
+		// The languages of framework plugins, as the custom language, are built by commands azd doesn't know about
+		return contracts.ShowTypeCustom
 	}
 }
 
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ShowTypeFromLanguage(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: py
+    host: containerapp
+  stack:
+    project: src/stack
+    language: compose
+    host: containerapp
+`
+	projectConfig, err := project.Parse(context.Background(), testProj)
+	require.NoError(t, err)
+
+	require.Equal(t, contracts.ShowTypePython, showTypeFromLanguage(projectConfig.Services["api"].Language))
+	require.Equal(t, contracts.ShowTypeCompose, showTypeFromLanguage(projectConfig.Services["stack"].Language))
+}
+
+func Test_ShowTypeFromLanguage_FrameworkPlugin(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: bazel
+    host: appservice
+`
+	configDir := t.TempDir()
+	t.Setenv("AZD_CONFIG_DIR", configDir)
+
+	pluginName := project.FrameworkPluginPrefix + "bazel"
+	if runtime.GOOS == "windows" {
+		pluginName += ".exe"
+	}
+
+	pluginPath := filepath.Join(configDir, "plugins", "frameworks", pluginName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(pluginPath), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(pluginPath, []byte{}, osutil.PermissionExecutableFile))
+
+	projectConfig, err := project.Parse(context.Background(), testProj)
+	require.NoError(t, err)
+
+	require.Equal(t, contracts.ShowTypeCustom, showTypeFromLanguage(projectConfig.Services["api"].Language))
+}
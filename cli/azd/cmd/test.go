@@ -0,0 +1,220 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type testCmdFlags struct {
+	global *internal.GlobalCommandOptions
+	group  string
+	envFlag
+}
+
+func (t *testCmdFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&t.group,
+		"group",
+		"",
+		"Tests the services of a group (the services with the matching 'group' in "+azdcontext.ProjectFileName+").",
+	)
+	t.envFlag.Bind(local, global)
+	t.global = global
+}
+
+func newTestCmdFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *testCmdFlags {
+	flags := &testCmdFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test <service>",
+		Short: "Run the tests of the application's services.",
+	}
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	return cmd
+}
+
+type testCmdAction struct {
+	flags          *testCmdFlags
+	args           []string
+	projectConfig  *project.ProjectConfig
+	projectManager project.ProjectManager
+	serviceManager project.ServiceManager
+	formatter      output.Formatter
+	writer         io.Writer
+	console        input.Console
+}
+
+func newTestCmdAction(
+	flags *testCmdFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	projectManager project.ProjectManager,
+	serviceManager project.ServiceManager,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &testCmdAction{
+		flags:          flags,
+		args:           args,
+		projectConfig:  projectConfig,
+		projectManager: projectManager,
+		serviceManager: serviceManager,
+		formatter:      formatter,
+		writer:         writer,
+		console:        console,
+	}
+}
+
+type TestResult struct {
+	Timestamp time.Time `json:"timestamp"`
+	// The results of the tests of the services, keyed by service name
+	Services map[string]*project.ServiceTestResult `json:"services"`
+}
+
+func (t *testCmdAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	targetServiceName := ""
+	if len(t.args) == 1 {
+		targetServiceName = t.args[0]
+	}
+
+	servicesToTest, err := t.projectConfig.SelectServices(targetServiceName, t.flags.group)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.projectManager.Initialize(ctx, t.projectConfig); err != nil {
+		return nil, err
+	}
+
+	// Collect all the tools we will need to run the tests and validate that they are installed. The tools of the
+	// services opting out of tests aren't required.
+	allTools := []tools.ExternalTool{}
+	for _, svc := range servicesToTest {
+		if svc.Test.Skip {
+			continue
+		}
+
+		requiredTools, err := t.serviceManager.GetRequiredTools(ctx, svc)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting required tools for service %s: %w", svc.Name, err)
+		}
+
+		allTools = append(allTools, requiredTools...)
+	}
+
+	if err := tools.EnsureInstalled(ctx, tools.Unique(allTools)...); err != nil {
+		return nil, err
+	}
+
+	// Command title
+	t.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Testing services (azd test)",
+	})
+
+	testResults := map[string]*project.ServiceTestResult{}
+	for _, svc := range servicesToTest {
+		stepMessage := fmt.Sprintf("Testing service %s", svc.Name)
+		t.console.ShowSpinner(ctx, stepMessage, input.Step)
+
+		testResult, err := t.testService(ctx, svc, stepMessage)
+		if err != nil {
+			t.console.StopSpinner(ctx, stepMessage, input.StepFailed)
+			return nil, err
+		}
+
+		if testResult.Skipped {
+			t.console.StopSpinner(ctx, fmt.Sprintf("%s (skipped)", stepMessage), input.StepWarning)
+		} else {
+			t.console.StopSpinner(ctx, stepMessage, input.StepDone)
+		}
+
+		testResults[svc.Name] = testResult
+	}
+
+	if t.formatter.Kind() == output.JsonFormat {
+		result := TestResult{
+			Timestamp: time.Now(),
+			Services:  testResults,
+		}
+
+		if fmtErr := t.formatter.Format(result, t.writer, nil); fmtErr != nil {
+			return nil, fmt.Errorf("test result could not be displayed: %w", fmtErr)
+		}
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: "The tests of your services passed.",
+		},
+	}, nil
+}
+
+// Restores the dependencies of the service, then runs its tests. Services opting out of tests aren't restored.
+func (t *testCmdAction) testService(
+	ctx context.Context,
+	svc *project.ServiceConfig,
+	stepMessage string,
+) (*project.ServiceTestResult, error) {
+	showProgress := func(progress <-chan project.ServiceProgress) {
+		for progress := range progress {
+			t.console.ShowSpinner(ctx, fmt.Sprintf("%s (%s)", stepMessage, progress.Message), input.Step)
+		}
+	}
+
+	if !svc.Test.Skip {
+		restoreTask := t.serviceManager.Restore(ctx, svc)
+		go showProgress(restoreTask.Progress())
+		if _, err := restoreTask.Await(); err != nil {
+			return nil, err
+		}
+	}
+
+	testTask := t.serviceManager.Test(ctx, svc)
+	go showProgress(testTask.Progress())
+	return testTask.Await()
+}
+
+func getCmdTestHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription("Run the tests of the application's services.", []string{
+		formatHelpNote(fmt.Sprintf("When %s is not set, the tests of all services in the 'azure.yaml'"+
+			" file (found in the root of your project) are run.", output.WithHighLightFormat("<service>"))),
+		formatHelpNote("The dependencies of each service are restored, then its tests are run with the test" +
+			" tool of its language, ex) npm test, dotnet test, pytest or mvn test."),
+		formatHelpNote(fmt.Sprintf("Set %s on a service in 'azure.yaml' to opt out of its tests, or %s to run"+
+			" its tests before it is deployed.",
+			output.WithHighLightFormat("test.skip"),
+			output.WithHighLightFormat("test.beforeDeploy"))),
+	})
+}
+
+func getCmdTestHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Run the tests of all services in your azure.yaml file.": output.WithHighLightFormat("azd test"),
+		"Run the tests of the api service.":                      output.WithHighLightFormat("azd test api"),
+		"Run the tests of the services of the backend group.": output.WithHighLightFormat(
+			"azd test --group backend"),
+	})
+}
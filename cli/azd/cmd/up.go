@@ -3,12 +3,18 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/cmd/actions"
 	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
 	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -16,13 +22,20 @@ import (
 type upFlags struct {
 	provisionFlags
 	deployFlags
-	global *internal.GlobalCommandOptions
+	retryFailed bool
+	global      *internal.GlobalCommandOptions
 	envFlag
 }
 
 func (u *upFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
 	u.envFlag.Bind(local, global)
 	u.global = global
+	local.BoolVar(
+		&u.retryFailed,
+		"retry-failed",
+		false,
+		"Retries the last failed run of azd up, without provisioning again or redeploying the services that were deployed.",
+	)
 
 	u.provisionFlags.bindNonCommon(local, global)
 	u.provisionFlags.setCommon(&u.envFlag)
@@ -48,14 +61,22 @@ type upAction struct {
 	flags                      *upFlags
 	provisionActionInitializer actions.ActionInitializer[*provisionAction]
 	deployActionInitializer    actions.ActionInitializer[*deployAction]
+	lazyEnv                    *lazy.Lazy[*environment.Environment]
+	lazyProjectConfig          *lazy.Lazy[*project.ProjectConfig]
+	lazyChangeTracker          *lazy.Lazy[*project.ChangeTracker]
 	console                    input.Console
 	runner                     middleware.MiddlewareContext
+	changeTracker              *project.ChangeTracker
+	env                        *environment.Environment
 }
 
 func newUpAction(
 	flags *upFlags,
 	provisionActionInitializer actions.ActionInitializer[*provisionAction],
 	deployActionInitializer actions.ActionInitializer[*deployAction],
+	lazyEnv *lazy.Lazy[*environment.Environment],
+	lazyProjectConfig *lazy.Lazy[*project.ProjectConfig],
+	lazyChangeTracker *lazy.Lazy[*project.ChangeTracker],
 	console input.Console,
 	runner middleware.MiddlewareContext,
 ) actions.Action {
@@ -63,6 +84,9 @@ func newUpAction(
 		flags:                      flags,
 		provisionActionInitializer: provisionActionInitializer,
 		deployActionInitializer:    deployActionInitializer,
+		lazyEnv:                    lazyEnv,
+		lazyProjectConfig:          lazyProjectConfig,
+		lazyChangeTracker:          lazyChangeTracker,
 		console:                    console,
 		runner:                     runner,
 	}
@@ -83,17 +107,47 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 			output.WithWarningFormat("The --service flag is deprecated and will be removed in the future."))
 	}
 
-	provision, err := u.provisionActionInitializer()
+	// The environment is only loaded by the run, so its errors, ex) when there's no project, are reported like the
+	// errors of provision and deploy
+	env, err := u.lazyEnv.GetValue()
 	if err != nil {
 		return nil, err
 	}
+	u.env = env
 
-	provision.flags = &u.flags.provisionFlags
-	provisionOptions := &middleware.Options{CommandPath: "provision"}
-	_, err = u.runner.RunChildAction(ctx, provisionOptions, provision)
+	changeTracker, err := u.lazyChangeTracker.GetValue()
 	if err != nil {
 		return nil, err
 	}
+	u.changeTracker = changeTracker
+
+	checkpoint, err := u.checkpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint.Provisioned {
+		u.console.Message(ctx, output.WithGrayFormat(
+			"Skipping provisioning, the infrastructure was provisioned by the failed run of azd up started at %s.",
+			checkpoint.StartedAt.Local().Format(time.RFC1123)))
+	} else {
+		provision, err := u.provisionActionInitializer()
+		if err != nil {
+			return nil, err
+		}
+
+		provision.flags = &u.flags.provisionFlags
+		provisionOptions := &middleware.Options{CommandPath: "provision", EnvironmentName: u.flags.environmentName}
+		_, err = u.runner.RunChildAction(ctx, provisionOptions, provision)
+		if err != nil {
+			return nil, err
+		}
+
+		// Failing to record the checkpoint only means a retry of the run provisions again
+		if err := u.changeTracker.RecordUpProvisioned(); err != nil {
+			log.Printf("failed recording the provisioning of azd up: %v", err)
+		}
+	}
 
 	// Print an additional newline to separate provision from deploy
 	u.console.Message(ctx, "")
@@ -104,23 +158,66 @@ func (u *upAction) Run(ctx context.Context) (*actions.ActionResult, error) {
 	}
 
 	deploy.flags = &u.flags.deployFlags
+	deploy.deployedServices = checkpoint.Deployed
+	if len(checkpoint.Deployed) > 0 {
+		u.console.Message(ctx, output.WithGrayFormat(
+			"Skipping services deployed by the failed run of azd up: %s", strings.Join(checkpoint.Deployed, ", ")))
+	}
+
 	// move flag to args to avoid extra deprecation flag warning
 	if deploy.flags.serviceName != "" {
 		deploy.args = []string{deploy.flags.serviceName}
 		deploy.flags.serviceName = ""
 	}
-	deployOptions := &middleware.Options{CommandPath: "deploy"}
+	deployOptions := &middleware.Options{CommandPath: "deploy", EnvironmentName: u.flags.environmentName}
 	deployResult, err := u.runner.RunChildAction(ctx, deployOptions, deploy)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := u.changeTracker.CompleteUp(); err != nil {
+		log.Printf("failed recording the completion of azd up: %v", err)
+	}
+
 	return deployResult, nil
 }
 
+// Gets the checkpoint of the run, the checkpoint of the last failed run when retrying it, or a new checkpoint.
+// Retries only skip the infrastructure and the services that are unchanged since the failed run.
+func (u *upAction) checkpoint(ctx context.Context) (*project.UpCheckpoint, error) {
+	if !u.flags.retryFailed {
+		// Failing to record the checkpoint only means the run can't be retried
+		if err := u.changeTracker.StartUp(); err != nil {
+			log.Printf("failed recording the start of azd up: %v", err)
+		}
+
+		return &project.UpCheckpoint{}, nil
+	}
+
+	checkpoint, err := u.changeTracker.UpCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint == nil || checkpoint.Completed {
+		return nil, fmt.Errorf(
+			"there's no failed run of azd up to retry in environment '%s', run azd up without --retry-failed", u.env.GetEnvName())
+	}
+
+	projectConfig, err := u.lazyProjectConfig.GetValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return u.changeTracker.VerifyUpCheckpoint(ctx, projectConfig, checkpoint)
+}
+
 func getCmdUpHelpDescription(c *cobra.Command) string {
 	return generateCmdHelpDescription(
 		fmt.Sprintf("Executes the %s and %s commands in a single step.",
 			output.WithHighLightFormat("azd provision"),
-			output.WithHighLightFormat("azd deploy")), nil)
+			output.WithHighLightFormat("azd deploy")), []string{
+			formatHelpNote(fmt.Sprintf("When a run fails, %s retries it without provisioning again or redeploying the"+
+				" services that were deployed.", output.WithHighLightFormat("azd up --retry-failed"))),
+		})
 }
@@ -0,0 +1,149 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/update"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type upgradeFlags struct {
+	channel string
+	check   bool
+	global  *internal.GlobalCommandOptions
+}
+
+func (u *upgradeFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&u.channel,
+		"channel",
+		"",
+		"The release channel to upgrade from: stable, beta or daily. Defaults to the upgrade.channel configuration, "+
+			"or stable.",
+	)
+	local.BoolVar(&u.check, "check", false, "Checks for a newer version without upgrading.")
+	u.global = global
+}
+
+func newUpgradeFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *upgradeFlags {
+	flags := &upgradeFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newUpgradeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade azd to the latest version of a release channel.",
+	}
+}
+
+type upgradeAction struct {
+	flags         *upgradeFlags
+	updateManager *update.Manager
+	configManager config.UserConfigManager
+	console       input.Console
+}
+
+func newUpgradeAction(
+	flags *upgradeFlags,
+	updateManager *update.Manager,
+	configManager config.UserConfigManager,
+	console input.Console,
+) actions.Action {
+	return &upgradeAction{
+		flags:         flags,
+		updateManager: updateManager,
+		configManager: configManager,
+		console:       console,
+	}
+}
+
+func (u *upgradeAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	azdConfig, err := u.configManager.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	upgradeConfig, err := update.LoadConfig(azdConfig, update.PolicyFilePath())
+	if err != nil {
+		return nil, err
+	}
+
+	if u.flags.channel != "" {
+		channel, err := update.ParseChannel(u.flags.channel)
+		if err != nil {
+			return nil, err
+		}
+
+		upgradeConfig.Channel = channel
+	}
+
+	if internal.IsDevVersion() && !u.flags.check {
+		return nil, errors.New("development builds of azd can't be upgraded, install a release of azd instead")
+	}
+
+	stepMessage := fmt.Sprintf("Checking for a newer version in the %s channel", upgradeConfig.Channel)
+	u.console.ShowSpinner(ctx, stepMessage, input.Step)
+	release, err := u.updateManager.CheckForUpdate(ctx, upgradeConfig)
+	u.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	if release == nil {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf("azd %s is up to date.", internal.GetVersionNumber()),
+			},
+		}, nil
+	}
+
+	if u.flags.check {
+		return &actions.ActionResult{
+			Message: &actions.ResultMessage{
+				Header: fmt.Sprintf(
+					"azd %s is available, you have %s.", release.Version, internal.GetVersionNumber()),
+				FollowUp: fmt.Sprintf(
+					"Run %s to upgrade.", output.WithHighLightFormat("azd upgrade --channel %s", release.Channel)),
+			},
+		}, nil
+	}
+
+	executablePath, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("finding azd executable: %w", err)
+	}
+
+	if executablePath, err = filepath.EvalSymlinks(executablePath); err != nil {
+		return nil, fmt.Errorf("finding azd executable: %w", err)
+	}
+
+	stepMessage = fmt.Sprintf("Installing azd %s", release.Version)
+	u.console.ShowSpinner(ctx, stepMessage, input.Step)
+	err = u.updateManager.Install(ctx, release, executablePath)
+	u.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return nil, err
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: fmt.Sprintf("Upgraded azd from %s to %s.", internal.GetVersionNumber(), release.Version),
+		},
+	}, nil
+}
@@ -0,0 +1,219 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/lazy"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/validate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/slices"
+)
+
+type validateFlags struct {
+	ci     bool
+	sarif  string
+	junit  string
+	global *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (f *validateFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.BoolVar(
+		&f.ci,
+		"ci",
+		false,
+		"Fails the validation on warnings, for pre-commit hooks and pull request checks.",
+	)
+	local.StringVar(&f.sarif, "sarif", "", "Writes the issues found to a SARIF file, ex) for code scanning alerts.")
+	local.StringVar(&f.junit, "junit", "", "Writes the results of the checks to a JUnit XML file, ex) for CI test reports.")
+	f.envFlag.Bind(local, global)
+	f.global = global
+}
+
+func newValidateFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *validateFlags {
+	flags := &validateFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the project without provisioning, building or deploying it.",
+	}
+}
+
+type validateAction struct {
+	flags     *validateFlags
+	azdCtx    *azdcontext.AzdContext
+	lazyEnv   *lazy.Lazy[*environment.Environment]
+	validator *validate.Validator
+	console   input.Console
+	formatter output.Formatter
+	writer    io.Writer
+}
+
+func newValidateAction(
+	flags *validateFlags,
+	azdCtx *azdcontext.AzdContext,
+	lazyEnv *lazy.Lazy[*environment.Environment],
+	validator *validate.Validator,
+	console input.Console,
+	formatter output.Formatter,
+	writer io.Writer,
+) actions.Action {
+	return &validateAction{
+		flags:     flags,
+		azdCtx:    azdCtx,
+		lazyEnv:   lazyEnv,
+		validator: validator,
+		console:   console,
+		formatter: formatter,
+		writer:    writer,
+	}
+}
+
+func (a *validateAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	a.console.MessageUxItem(ctx, &ux.MessageTitle{
+		Title: "Validating the project (azd validate)",
+	})
+
+	// The references of the infrastructure parameters are only checked when the project has an environment, the
+	// validation never prompts for one
+	env, err := a.lazyEnv.GetValue()
+	if err != nil {
+		env = nil
+	}
+
+	report, err := a.validator.Run(ctx, validate.Options{
+		ProjectFilePath: a.azdCtx.ProjectPath(),
+		Env:             env,
+		Strict:          a.flags.ci,
+		CheckStarted: func(title string) {
+			a.console.ShowSpinner(ctx, title, input.Step)
+		},
+		CheckDone: func(result *validate.CheckResult) {
+			a.showCheck(ctx, result)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if a.formatter.Kind() == output.JsonFormat {
+		if err := a.formatter.Format(report, a.writer, nil); err != nil {
+			return nil, fmt.Errorf("validation report could not be displayed: %w", err)
+		}
+	}
+
+	if a.flags.sarif != "" {
+		if err := writeValidationReport(a.flags.sarif, func(w io.Writer) error {
+			return validate.WriteSarif(w, report, internal.GetVersionNumber())
+		}); err != nil {
+			return nil, fmt.Errorf("writing SARIF report: %w", err)
+		}
+	}
+
+	if a.flags.junit != "" {
+		if err := writeValidationReport(a.flags.junit, func(w io.Writer) error {
+			return validate.WriteJUnit(w, report)
+		}); err != nil {
+			return nil, fmt.Errorf("writing JUnit report: %w", err)
+		}
+	}
+
+	if failures := report.Failures(); failures > 0 {
+		return nil, fmt.Errorf("the validation of the project failed with %d issues", failures)
+	}
+
+	return &actions.ActionResult{
+		Message: &actions.ResultMessage{
+			Header: "The project is valid.",
+		},
+	}, nil
+}
+
+// Shows the result of the check with its issues
+func (a *validateAction) showCheck(ctx context.Context, check *validate.CheckResult) {
+	strict := a.flags.ci
+	switch {
+	case check.Skipped != "":
+		a.console.StopSpinner(ctx, fmt.Sprintf("%s (skipped, %s)", check.Title, check.Skipped), input.StepWarning)
+	case check.Failed(strict):
+		a.console.StopSpinner(ctx, check.Title, input.StepFailed)
+	case slices.IndexFunc(check.Issues, func(issue validate.Issue) bool {
+		return issue.Severity == validate.SeverityWarning
+	}) >= 0:
+		a.console.StopSpinner(ctx, check.Title, input.StepWarning)
+	default:
+		a.console.StopSpinner(ctx, check.Title, input.StepDone)
+	}
+
+	for _, issue := range check.Issues {
+		severity := output.WithGrayFormat(string(issue.Severity))
+		if issue.Fails(strict) {
+			severity = output.WithErrorFormat(string(validate.SeverityError))
+		} else if issue.Severity == validate.SeverityWarning {
+			severity = output.WithWarningFormat(string(issue.Severity))
+		}
+
+		location := ""
+		if issue.File != "" {
+			location = output.WithHighLightFormat(issue.Location()) + " "
+		}
+
+		a.console.Message(ctx, fmt.Sprintf("  %s %s%s %s",
+			severity, location, issue.Message, output.WithGrayFormat("(%s/%s)", check.Name, issue.Rule)))
+	}
+}
+
+func writeValidationReport(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+	return write(file)
+}
+
+func getCmdValidateHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Validate the project without side effects, for pre-commit hooks and pull request checks.",
+		[]string{
+			formatHelpNote("The 'azure.yaml' file is checked against its schema, the infrastructure is compiled" +
+				" and its parameters file checked, the Dockerfiles of the services are linted with hadolint and the" +
+				" syntax of the scripts of the hooks is checked."),
+			formatHelpNote("Nothing is provisioned, built or deployed and no hook runs."),
+			formatHelpNote(fmt.Sprintf("Errors fail the validation. With %s, warnings fail the validation too.",
+				output.WithHighLightFormat("--ci"))),
+			formatHelpNote(fmt.Sprintf("Use %s and %s to write the results for code scanning and CI test reports.",
+				output.WithHighLightFormat("--sarif"),
+				output.WithHighLightFormat("--junit"))),
+		})
+}
+
+func getCmdValidateHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Validate the project.": output.WithHighLightFormat("azd validate"),
+		"Validate the project in a pull request check, failing on warnings.": output.WithHighLightFormat(
+			"azd validate --ci"),
+		"Validate the project and write the issues found to a SARIF file.": output.WithHighLightFormat(
+			"azd validate --ci --sarif azd.sarif"),
+	})
+}
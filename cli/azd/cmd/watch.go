@@ -0,0 +1,228 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/cmd/actions"
+	"github.com/azure/azure-dev/cli/azd/cmd/middleware"
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type watchFlags struct {
+	group     string
+	buildOnly bool
+	interval  time.Duration
+	global    *internal.GlobalCommandOptions
+	envFlag
+}
+
+func (w *watchFlags) Bind(local *pflag.FlagSet, global *internal.GlobalCommandOptions) {
+	local.StringVar(
+		&w.group,
+		"group",
+		"",
+		"Watches the services of a group (the services with the matching 'group' in "+azdcontext.ProjectFileName+").",
+	)
+	local.BoolVar(&w.buildOnly, "build-only", false, "Rebuilds the services that changed without deploying them.")
+	local.DurationVar(
+		&w.interval,
+		"interval",
+		project.DefaultWatchInterval,
+		"The interval the sources of the services are checked for changes (defaults to 1s).",
+	)
+	w.envFlag.Bind(local, global)
+	w.global = global
+}
+
+func newWatchFlags(cmd *cobra.Command, global *internal.GlobalCommandOptions) *watchFlags {
+	flags := &watchFlags{}
+	flags.Bind(cmd.Flags(), global)
+
+	return flags
+}
+
+func newWatchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch <service>",
+		Short: "Rebuild and redeploy the services when their sources change.",
+		Args:  cobra.MaximumNArgs(1),
+	}
+}
+
+type watchAction struct {
+	flags                   *watchFlags
+	args                    []string
+	projectConfig           *project.ProjectConfig
+	projectManager          project.ProjectManager
+	serviceManager          project.ServiceManager
+	deployActionInitializer actions.ActionInitializer[*deployAction]
+	runner                  middleware.MiddlewareContext
+	console                 input.Console
+}
+
+func newWatchAction(
+	flags *watchFlags,
+	args []string,
+	projectConfig *project.ProjectConfig,
+	projectManager project.ProjectManager,
+	serviceManager project.ServiceManager,
+	deployActionInitializer actions.ActionInitializer[*deployAction],
+	runner middleware.MiddlewareContext,
+	console input.Console,
+) actions.Action {
+	return &watchAction{
+		flags:                   flags,
+		args:                    args,
+		projectConfig:           projectConfig,
+		projectManager:          projectManager,
+		serviceManager:          serviceManager,
+		deployActionInitializer: deployActionInitializer,
+		runner:                  runner,
+		console:                 console,
+	}
+}
+
+func (w *watchAction) Run(ctx context.Context) (*actions.ActionResult, error) {
+	targetServiceName := ""
+	if len(w.args) == 1 {
+		targetServiceName = w.args[0]
+	}
+
+	servicesToWatch, err := w.projectConfig.SelectServices(targetServiceName, w.flags.group)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.flags.buildOnly {
+		if err := w.projectManager.Initialize(ctx, w.projectConfig); err != nil {
+			return nil, err
+		}
+
+		allTools := []tools.ExternalTool{}
+		for _, svc := range servicesToWatch {
+			requiredTools, err := w.serviceManager.GetRequiredTools(ctx, svc)
+			if err != nil {
+				return nil, fmt.Errorf("failed getting required tools for service %s: %w", svc.Name, err)
+			}
+
+			allTools = append(allTools, requiredTools...)
+		}
+
+		if err := tools.EnsureInstalled(ctx, tools.Unique(allTools)...); err != nil {
+			return nil, err
+		}
+	}
+
+	watcher, err := project.NewSourceWatcher(servicesToWatch, w.flags.interval)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		w.console.Message(ctx, fmt.Sprintf(
+			"\nWatching %d service(s) for changes. Press Ctrl+C to stop.", len(servicesToWatch)))
+
+		changes, err := watcher.Wait(ctx)
+		if errors.Is(err, context.Canceled) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, change := range changes {
+			w.console.Message(ctx, fmt.Sprintf(
+				"Service %s changed: %s", change.Service.Name, output.WithGrayFormat(summarizeFiles(change.Files))))
+
+			// Failures are reported and the services keep being watched, so the next change can fix them
+			if err := w.update(ctx, change.Service); err != nil {
+				w.console.Message(ctx, output.WithErrorFormat("ERROR: %s", err.Error()))
+			}
+		}
+
+		// The files generated by the builds of the services aren't changes
+		if err := watcher.Reset(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Rebuilds the service, and redeploys it unless only builds are enabled
+func (w *watchAction) update(ctx context.Context, serviceConfig *project.ServiceConfig) error {
+	if !w.flags.buildOnly {
+		deploy, err := w.deployActionInitializer()
+		if err != nil {
+			return err
+		}
+
+		deploy.flags = &deployFlags{global: w.flags.global}
+		deploy.flags.setCommon(&w.flags.envFlag)
+		deploy.args = []string{serviceConfig.Name}
+		deployOptions := &middleware.Options{CommandPath: "deploy", EnvironmentName: w.flags.environmentName}
+		_, err = w.runner.RunChildAction(ctx, deployOptions, deploy)
+		return err
+	}
+
+	stepMessage := fmt.Sprintf("Building service %s", serviceConfig.Name)
+	w.console.ShowSpinner(ctx, stepMessage, input.Step)
+
+	buildTask := w.serviceManager.Build(ctx, serviceConfig, nil)
+	go func() {
+		for progress := range buildTask.Progress() {
+			updatedMessage := fmt.Sprintf("Building service %s (%s)", serviceConfig.Name, progress.Message)
+			w.console.ShowSpinner(ctx, updatedMessage, input.Step)
+		}
+	}()
+
+	_, err := buildTask.Await()
+	w.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	return err
+}
+
+// Summarizes the changed files, listing the first few
+func summarizeFiles(files []string) string {
+	const listed = 3
+	if len(files) <= listed {
+		return strings.Join(files, ", ")
+	}
+
+	return fmt.Sprintf("%s and %d more", strings.Join(files[:listed], ", "), len(files)-listed)
+}
+
+func getCmdWatchHelpDescription(*cobra.Command) string {
+	return generateCmdHelpDescription(
+		"Rebuild and redeploy the services when their sources change, until stopped with Ctrl+C.",
+		[]string{
+			formatHelpNote("The source directories of the services are checked for changes at an interval, the files" +
+				" excluded by their .gitignore file and the directories of dependencies and build outputs aren't watched."),
+			formatHelpNote(fmt.Sprintf(
+				"The services that changed are deployed as with %s, their builds are skipped when their sources are"+
+					" unchanged since their last build.",
+				output.WithHighLightFormat("azd deploy <service>"),
+			)),
+		})
+}
+
+func getCmdWatchHelpFooter(*cobra.Command) string {
+	return generateCmdHelpSamplesBlock(map[string]string{
+		"Redeploy all services when their sources change.": output.WithHighLightFormat("azd watch"),
+		"Redeploy a specific service when its sources change.": fmt.Sprintf("%s %s",
+			output.WithHighLightFormat("azd watch <service>"),
+			output.WithWarningFormat("[Service name]")),
+		"Rebuild the services when their sources change, without deploying them.": output.WithHighLightFormat(
+			"azd watch --build-only"),
+	})
+}
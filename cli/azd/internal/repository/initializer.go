@@ -99,6 +99,11 @@ func (i *Initializer) Initialize(
 		return err
 	}
 
+	err = i.WriteServiceFiles(ctx, azdCtx)
+	if err != nil {
+		return err
+	}
+
 	err = i.gitInitialize(ctx, target, filesWithExecPerms, isEmpty)
 	if err != nil {
 		return err
@@ -386,6 +391,46 @@ func (i *Initializer) writeAzdAssets(ctx context.Context, azdCtx *azdcontext.Azd
 	return nil
 }
 
+// WriteServiceFiles writes the ignore files of the services of the project that don't include them, so the files of the
+// services that aren't meant to be deployed, ex) node_modules, are excluded from their packages and docker build
+// contexts. The Dockerfiles of the container services without one are generated from the language of the services, so
+// they can be reviewed before the services are deployed.
+func (i *Initializer) WriteServiceFiles(ctx context.Context, azdCtx *azdcontext.AzdContext) error {
+	projectConfig, err := project.Load(ctx, azdCtx.ProjectPath())
+	if err != nil {
+		// The project of the template is validated by the commands using it, not by init
+		log.Printf("skipping service files, loading project: %v", err)
+		return nil
+	}
+
+	for _, svc := range projectConfig.Services {
+		written, err := project.WriteIgnoreFiles(svc)
+		if err != nil {
+			return fmt.Errorf("writing ignore files for service '%s': %w", svc.Name, err)
+		}
+
+		dockerfilePath, err := project.WriteDockerfile(svc)
+		if err != nil {
+			return fmt.Errorf("writing Dockerfile for service '%s': %w", svc.Name, err)
+		}
+
+		if dockerfilePath != "" {
+			written = append(written, dockerfilePath)
+		}
+
+		for _, ignoreFilePath := range written {
+			relativePath, err := filepath.Rel(azdCtx.ProjectDirectory(), ignoreFilePath)
+			if err != nil {
+				relativePath = ignoreFilePath
+			}
+
+			i.console.MessageUxItem(ctx, &ux.DoneMessage{Message: fmt.Sprintf("Created %s", relativePath)})
+		}
+	}
+
+	return nil
+}
+
 // Returns files that are both present in source and target.
 // The files returned are expressed in their relative paths to source/target.
 func determineDuplicates(source string, target string) ([]string, error) {
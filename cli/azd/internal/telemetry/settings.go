@@ -0,0 +1,215 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// The user configuration keys of the telemetry settings, ex) azd config set telemetry.enabled false
+const (
+	enabledConfigKey            = "telemetry.enabled"
+	disabledCategoriesConfigKey = "telemetry.disabledCategories"
+	connectionStringConfigKey   = "telemetry.connectionString"
+)
+
+// Category is a category of the telemetry collected by azd, which can be opted out of
+type Category string
+
+const (
+	// The events of the commands run, ex) cmd.deploy
+	CategoryCommands Category = "commands"
+	// The events of the tools installed by azd, ex) tools.bicep.install
+	CategoryTools Category = "tools"
+	// The events of the account and the hashed account attributes of all events, ex) the tenant and subscription
+	CategoryAccount Category = "account"
+	// The hashed project and environment attributes of all events, ex) the project template and service hosts
+	CategoryProject Category = "project"
+)
+
+// The supported categories of telemetry
+var Categories = []Category{CategoryCommands, CategoryTools, CategoryAccount, CategoryProject}
+
+// The prefixes of the names of the events of the categories
+var categoryEventPrefixes = map[Category][]string{
+	CategoryCommands: {"cmd."},
+	CategoryTools:    {"tools."},
+	CategoryAccount:  {"account."},
+}
+
+// The prefixes of the attributes of the categories, which are removed from all events when the category is disabled
+var categoryAttributePrefixes = map[Category][]string{
+	CategoryAccount: {"ad.", string(fields.ObjectIdKey)},
+	CategoryProject: {"project.", "env."},
+}
+
+// Settings are the telemetry settings of azd, from the user configuration and the machine level policy
+type Settings struct {
+	// When false, no telemetry is collected
+	Enabled bool
+	// The categories of telemetry not collected
+	DisabledCategories []Category
+	// The Application Insights connection string telemetry is sent to, ex) the resource of an organization collecting
+	// azd usage internally. Empty for the default resource of azd.
+	ConnectionString string
+}
+
+// PolicyConfig is the machine level telemetry configuration managed by an organization, ex) /etc/azd/telemetry.yaml.
+// The policy overrides the telemetry settings of users: telemetry disabled by the policy can't be enabled, categories
+// disabled by the policy can't be collected and telemetry is always sent to the connection string of the policy.
+type PolicyConfig struct {
+	Enabled            *bool      `yaml:"enabled"`
+	DisabledCategories []Category `yaml:"disabledCategories"`
+	ConnectionString   string     `yaml:"connectionString"`
+}
+
+// Gets the path of the machine level telemetry policy file. The file is stored in a machine wide location that is
+// typically only writable by administrators, ex) /etc/azd/telemetry.yaml. The location can't be overridden by users.
+func PolicyFilePath() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+
+		return filepath.Join(programData, "azd", "telemetry.yaml")
+	}
+
+	return filepath.Join("/etc", "azd", "telemetry.yaml")
+}
+
+// Loads the telemetry policy from the specified file. Returns nil when the file doesn't exist.
+func LoadPolicy(filePath string) (*PolicyConfig, error) {
+	contents, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading telemetry policy file: %w", err)
+	}
+
+	var policy PolicyConfig
+	if err := yaml.Unmarshal(contents, &policy); err != nil {
+		return nil, fmt.Errorf("parsing telemetry policy file '%s': %w", filePath, err)
+	}
+
+	return &policy, nil
+}
+
+// Gets the telemetry settings from the AZURE_DEV_COLLECT_TELEMETRY environment variable, the user configuration and the
+// machine level policy. Telemetry is disabled when the policy can't be read, so a broken policy never leaks telemetry.
+func LoadSettings() Settings {
+	return loadSettings(PolicyFilePath())
+}
+
+func loadSettings(policyFilePath string) Settings {
+	settings := Settings{Enabled: os.Getenv(collectTelemetryEnvVar) != "no"}
+
+	if userConfig, err := config.NewUserConfigManager().Load(); err != nil {
+		log.Printf("failed loading user config for telemetry settings: %v", err)
+	} else {
+		applyUserConfig(&settings, userConfig)
+	}
+
+	policy, err := LoadPolicy(policyFilePath)
+	if err != nil {
+		log.Printf("telemetry is disabled, %v", err)
+		settings.Enabled = false
+	} else if policy != nil {
+		applyPolicy(&settings, policy)
+	}
+
+	return settings
+}
+
+func applyUserConfig(settings *Settings, userConfig config.Config) {
+	if value, has := userConfig.Get(enabledConfigKey); has && strings.EqualFold(fmt.Sprint(value), "false") {
+		settings.Enabled = false
+	}
+
+	if value, has := userConfig.Get(disabledCategoriesConfigKey); has {
+		for _, category := range strings.Split(fmt.Sprint(value), ",") {
+			settings.disableCategory(Category(strings.TrimSpace(category)))
+		}
+	}
+
+	if value, has := userConfig.Get(connectionStringConfigKey); has {
+		settings.ConnectionString = fmt.Sprint(value)
+	}
+}
+
+func applyPolicy(settings *Settings, policy *PolicyConfig) {
+	if policy.Enabled != nil && !*policy.Enabled {
+		settings.Enabled = false
+	}
+
+	for _, category := range policy.DisabledCategories {
+		settings.disableCategory(category)
+	}
+
+	if policy.ConnectionString != "" {
+		settings.ConnectionString = policy.ConnectionString
+	}
+}
+
+func (s *Settings) disableCategory(category Category) {
+	if category == "" {
+		return
+	}
+
+	if !slices.Contains(Categories, category) {
+		log.Printf("ignoring unknown telemetry category '%s'", category)
+		return
+	}
+
+	if !slices.Contains(s.DisabledCategories, category) {
+		s.DisabledCategories = append(s.DisabledCategories, category)
+	}
+}
+
+// Gets whether the event is in a disabled category
+func (s *Settings) isEventDisabled(name string) bool {
+	for _, category := range s.DisabledCategories {
+		for _, prefix := range categoryEventPrefixes[category] {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Removes the properties and context tags of the disabled categories from the envelope
+func (s *Settings) filterEnvelope(envelope *contracts.Envelope) {
+	removeDisabled := func(values map[string]string) {
+		for key := range values {
+			for _, category := range s.DisabledCategories {
+				for _, prefix := range categoryAttributePrefixes[category] {
+					if strings.HasPrefix(key, prefix) {
+						delete(values, key)
+					}
+				}
+			}
+		}
+	}
+
+	removeDisabled(envelope.Tags)
+	if data, ok := envelope.Data.(*contracts.Data); ok {
+		if requestData, ok := data.BaseData.(*contracts.RequestData); ok {
+			removeDisabled(requestData.Properties)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	appinsightsexporter "github.com/azure/azure-dev/cli/azd/internal/telemetry/appinsights-exporter"
+	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/microsoft/ApplicationInsights-Go/appinsights/contracts"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Sets up the user configuration & policy and returns the path of the policy file
+func setupSettings(t *testing.T, userConfig string, policy string) string {
+	configDir := t.TempDir()
+	ostest.Setenv(t, "AZD_CONFIG_DIR", configDir)
+	ostest.Unsetenv(t, collectTelemetryEnvVar)
+	if userConfig != "" {
+		err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(userConfig), osutil.PermissionFile)
+		require.NoError(t, err)
+	}
+
+	policyPath := filepath.Join(t.TempDir(), "telemetry.yaml")
+	if policy != "" {
+		require.NoError(t, os.WriteFile(policyPath, []byte(policy), osutil.PermissionFile))
+	}
+
+	return policyPath
+}
+
+func TestLoadSettings(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		policyPath := setupSettings(t, "", "")
+		require.Equal(t, Settings{Enabled: true}, loadSettings(policyPath))
+	})
+
+	t.Run("UserConfig", func(t *testing.T) {
+		policyPath := setupSettings(t, `{"telemetry": {"disabledCategories": "account, project,unknown",
+			"connectionString": "InstrumentationKey=user"}}`, "")
+		require.Equal(t, Settings{
+			Enabled:            true,
+			DisabledCategories: []Category{CategoryAccount, CategoryProject},
+			ConnectionString:   "InstrumentationKey=user",
+		}, loadSettings(policyPath))
+	})
+
+	t.Run("UserDisabled", func(t *testing.T) {
+		policyPath := setupSettings(t, `{"telemetry": {"enabled": "false"}}`, "")
+		require.False(t, loadSettings(policyPath).Enabled)
+	})
+
+	t.Run("PolicyOverridesUserConfig", func(t *testing.T) {
+		policyPath := setupSettings(
+			t,
+			`{"telemetry": {"disabledCategories": "account", "connectionString": "InstrumentationKey=user"}}`,
+			"disabledCategories: [tools]\nconnectionString: InstrumentationKey=contoso\n",
+		)
+		require.Equal(t, Settings{
+			Enabled:            true,
+			DisabledCategories: []Category{CategoryAccount, CategoryTools},
+			ConnectionString:   "InstrumentationKey=contoso",
+		}, loadSettings(policyPath))
+	})
+
+	t.Run("PolicyDisabled", func(t *testing.T) {
+		policyPath := setupSettings(t, `{"telemetry": {"enabled": "true"}}`, "enabled: false\n")
+		require.False(t, loadSettings(policyPath).Enabled)
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		policyPath := setupSettings(t, "", "enabled: [\n")
+		require.False(t, loadSettings(policyPath).Enabled)
+	})
+}
+
+func TestExportSpans_DisabledCategories(t *testing.T) {
+	queue := InMemoryQueue{[][]byte{}}
+	exporter := NewExporter(&queue, "iKey")
+	exporter.settings = Settings{Enabled: true, DisabledCategories: []Category{CategoryTools, CategoryAccount}}
+
+	toolsSpan := GetSpanStub()
+	toolsSpan.Name = "tools.bicep.install"
+
+	commandSpan := GetSpanStub()
+	commandSpan.Name = "cmd.deploy"
+	commandSpan.Attributes = append(commandSpan.Attributes,
+		fields.SubscriptionIdKey.String("subscription"),
+		fields.ObjectIdKey.String("user"),
+		fields.ProjectNameKey.String("project"),
+	)
+
+	err := exporter.ExportSpans(
+		context.Background(), []tracesdk.ReadOnlySpan{toolsSpan.Snapshot(), commandSpan.Snapshot()})
+	require.NoError(t, err)
+	require.Len(t, queue.queue, 1)
+
+	var items appinsightsexporter.TelemetryItems
+	items.Deserialize(queue.queue[0])
+	require.Len(t, items, 1)
+
+	envelope := items[0]
+	require.Equal(t, "cmd.deploy", envelope.Tags[contracts.OperationName])
+	require.NotContains(t, envelope.Tags, string(fields.ObjectIdKey))
+
+	properties := envelope.Data.(map[string]any)["baseData"].(map[string]any)["properties"].(map[string]any)
+	require.NotContains(t, properties, string(fields.SubscriptionIdKey))
+	require.Equal(t, "project", properties[string(fields.ProjectNameKey)])
+}
+
+// Ensures the attributes of the categories match the keys of their fields
+func TestCategoryAttributePrefixes(t *testing.T) {
+	keys := map[Category][]attribute.Key{
+		CategoryAccount: {fields.TenantIdKey, fields.AccountTypeKey, fields.SubscriptionIdKey, fields.ObjectIdKey},
+		CategoryProject: {fields.ProjectTemplateIdKey, fields.ProjectNameKey, fields.EnvNameKey},
+	}
+
+	for category, categoryKeys := range keys {
+		settings := Settings{DisabledCategories: []Category{category}}
+		for _, key := range categoryKeys {
+			envelope := contracts.NewEnvelope()
+			envelope.Tags = map[string]string{string(key): "value"}
+			settings.filterEnvelope(envelope)
+			require.Empty(t, envelope.Tags, "%s isn't removed with category %s", key, category)
+		}
+	}
+}
@@ -19,6 +19,8 @@ type Exporter struct {
 
 	anyExported        *atomic.Bool
 	instrumentationKey string
+	// The events and attributes of the disabled categories of the settings aren't exported
+	settings Settings
 }
 
 func NewExporter(queue simpleQueue, instrumentationKey string) *Exporter {
@@ -37,8 +39,13 @@ func (e *Exporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan)
 		case <-ctx.Done():
 			return ctx.Err()
 		default:
+			if e.settings.isEventDisabled(span.Name()) {
+				continue
+			}
+
 			envelope := appinsightsexporter.SpanToEnvelope(span)
 			envelope.IKey = e.instrumentationKey
+			e.settings.filterEnvelope(envelope)
 
 			items = append(items, *envelope)
 		}
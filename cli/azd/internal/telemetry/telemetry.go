@@ -64,8 +64,10 @@ func getTelemetryDirectory() (string, error) {
 	return telemetryDir, nil
 }
 
+// Gets whether telemetry is enabled, from the AZURE_DEV_COLLECT_TELEMETRY environment variable, the telemetry.enabled
+// user configuration and the machine level telemetry policy
 func IsTelemetryEnabled() bool {
-	return os.Getenv(collectTelemetryEnvVar) != "no"
+	return LoadSettings().Enabled
 }
 
 // Returns the singleton TelemetrySystem instance.
@@ -87,8 +89,9 @@ func GetTelemetrySystem() *TelemetrySystem {
 const cDefaultCollectorHTTPPort uint16 = 4318
 
 func initialize() (*TelemetrySystem, error) {
-	if !IsTelemetryEnabled() {
-		log.Println("telemetry is disabled by user and will not be initialized.")
+	settings := LoadSettings()
+	if !settings.Enabled {
+		log.Println("telemetry is disabled by user or policy and will not be initialized.")
 		return nil, nil
 	}
 
@@ -106,10 +109,11 @@ func initialize() (*TelemetrySystem, error) {
 		return nil, fmt.Errorf("failed to initialize storage queue: %w", err)
 	}
 
-	var connectionString string
-	if internal.IsNonProdVersion() {
+	// Organizations may redirect telemetry to their own Application Insights resource
+	connectionString := settings.ConnectionString
+	if connectionString == "" && internal.IsNonProdVersion() {
 		connectionString = devConnectionString
-	} else {
+	} else if connectionString == "" {
 		connectionString = prodConnectionString
 	}
 	config, err := appinsightsexporter.NewEndpointConfig(connectionString)
@@ -118,6 +122,7 @@ func initialize() (*TelemetrySystem, error) {
 	}
 
 	exporter := NewExporter(storageQueue, config.InstrumentationKey)
+	exporter.settings = settings
 
 	options := []trace.TracerProviderOption{
 		trace.WithBatcher(exporter),
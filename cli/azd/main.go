@@ -17,7 +17,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +25,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/cmd"
 	"github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/blang/semver/v4"
@@ -49,6 +49,8 @@ func main() {
 		log.SetOutput(io.Discard)
 	}
 
+	applyTheme()
+
 	ts := telemetry.GetTelemetrySystem()
 
 	latest := make(chan semver.Version)
@@ -81,14 +83,7 @@ func main() {
 			fmt.Fprintln(os.Stderr)
 			fmt.Fprintln(os.Stderr, output.WithWarningFormat(`To update to the latest version, run:`))
 
-			if runtime.GOOS == "windows" {
-				fmt.Fprintln(
-					os.Stderr,
-					output.WithWarningFormat(
-						`powershell -ex AllSigned -c "Invoke-RestMethod 'https://aka.ms/install-azd.ps1' | Invoke-Expression"`))
-			} else {
-				fmt.Fprintln(os.Stderr, output.WithWarningFormat(`curl -fsSL https://aka.ms/install-azd.sh | bash`))
-			}
+			fmt.Fprintln(os.Stderr, output.WithWarningFormat(`azd upgrade`))
 		}
 	}
 
@@ -305,6 +300,24 @@ func isJsonOutput() bool {
 	return output == "json"
 }
 
+// Applies the theme of the output configured with `azd config set theme <name>`, unless NO_COLOR is set
+func applyTheme() {
+	configured := ""
+	if azdConfig, err := config.NewUserConfigManager().Load(); err != nil {
+		log.Printf("failed to load user config, using the default theme: %v", err)
+	} else if value, has := azdConfig.Get(output.ThemeConfigKey); has {
+		configured = fmt.Sprint(value)
+	}
+
+	theme, err := output.ResolveThemeName(configured)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, output.WithWarningFormat("warning: %s, using the %s theme", err, output.ThemeDark))
+		theme = output.ThemeDark
+	}
+
+	output.SetTheme(theme)
+}
+
 func readToEndAndClose(r io.ReadCloser) (string, error) {
 	defer r.Close()
 	var buf strings.Builder
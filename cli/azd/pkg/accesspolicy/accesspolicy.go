@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package accesspolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// The name of the access policy file of a project, stored next to azure.yaml
+const FileName = "azure.policy.yaml"
+
+// The operation or environment pattern matching any operation or environment
+const Wildcard = "*"
+
+// Policy restricts the azd operations of a project to the members of groups of Microsoft Entra ID, per environment,
+// ex) only the members of the release group can deploy to the production environment. The policy is checked by azd
+// against the signed-in identity before the operation runs, in addition to the Azure RBAC roles of the identity.
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule allows an operation on environments only to the members of groups
+type Rule struct {
+	// The operations restricted by the rule, named after the azd commands without 'azd', ex) deploy, down or env clone
+	Operations []string `yaml:"operations"`
+	// The names of the environments the rule applies to, which may include wildcards, ex) prod-*.
+	// The rule applies to all the environments when empty.
+	Environments []string `yaml:"environments"`
+	// The object ids of the groups allowed to run the operations
+	Groups []string `yaml:"groups"`
+}
+
+// MemberGroupsFn gets the groups of the specified groups which the signed-in identity is a member of
+type MemberGroupsFn func(ctx context.Context, groupIds []string) ([]string, error)
+
+// UnauthorizedError is returned when the signed-in identity isn't allowed to run an operation by the access policy
+type UnauthorizedError struct {
+	Operation   string
+	Environment string
+	Groups      []string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return fmt.Sprintf(
+		"the access policy of the project doesn't allow '%s' on environment '%s', "+
+			"which is restricted to the members of the groups %s",
+		e.Operation,
+		e.Environment,
+		strings.Join(e.Groups, ", "),
+	)
+}
+
+// Loads the access policy of the project in the directory. Returns nil when the project doesn't have an access policy.
+func Load(projectDir string) (*Policy, error) {
+	filePath := filepath.Join(projectDir, FileName)
+	contents, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading access policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(contents, &policy); err != nil {
+		return nil, fmt.Errorf("parsing access policy file '%s': %w", filePath, err)
+	}
+
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid access policy file '%s': %w", filePath, err)
+	}
+
+	return &policy, nil
+}
+
+func (p *Policy) validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.Operations) == 0 {
+			return fmt.Errorf("rule %d doesn't have operations", i+1)
+		}
+
+		if len(rule.Groups) == 0 {
+			return fmt.Errorf("rule %d doesn't have groups", i+1)
+		}
+
+		for _, pattern := range rule.Environments {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d has an invalid environment pattern '%s': %w", i+1, pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Gets the rules applying to the operation on the environment
+func (p *Policy) MatchingRules(operation string, envName string) []Rule {
+	rules := []Rule{}
+	for _, rule := range p.Rules {
+		if rule.matches(operation, envName) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// Checks the signed-in identity is allowed to run the operation on the environment. Every rule applying to the operation
+// requires the identity to be a member of one of the groups of the rule. Returns an *UnauthorizedError otherwise.
+func (p *Policy) Authorize(ctx context.Context, operation string, envName string, memberGroups MemberGroupsFn) error {
+	rules := p.MatchingRules(operation, envName)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	groupIds := []string{}
+	for _, rule := range rules {
+		for _, groupId := range rule.Groups {
+			if !slices.Contains(groupIds, groupId) {
+				groupIds = append(groupIds, groupId)
+			}
+		}
+	}
+
+	// The membership of all the groups is checked at once
+	memberOf, err := memberGroups(ctx, groupIds)
+	if err != nil {
+		return fmt.Errorf("checking access policy: %w", err)
+	}
+
+	for _, rule := range rules {
+		isMember := slices.IndexFunc(rule.Groups, func(groupId string) bool {
+			return slices.IndexFunc(memberOf, func(id string) bool { return strings.EqualFold(id, groupId) }) >= 0
+		}) >= 0
+
+		if !isMember {
+			return &UnauthorizedError{Operation: operation, Environment: envName, Groups: rule.Groups}
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) matches(operation string, envName string) bool {
+	if !slices.Contains(r.Operations, operation) && !slices.Contains(r.Operations, Wildcard) {
+		return false
+	}
+
+	if len(r.Environments) == 0 {
+		return true
+	}
+
+	for _, pattern := range r.Environments {
+		if matched, _ := path.Match(pattern, envName); matched {
+			return true
+		}
+	}
+
+	return false
+}
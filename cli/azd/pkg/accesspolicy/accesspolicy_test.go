@@ -0,0 +1,104 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package accesspolicy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+  - operations: [deploy, down]
+    environments: [prod, prod-*]
+    groups: [release-group]
+  - operations: [down]
+    groups: [admin-group, owner-group]
+`
+
+func Test_Load(t *testing.T) {
+	t.Run("NoPolicy", func(t *testing.T) {
+		policy, err := Load(t.TempDir())
+		require.NoError(t, err)
+		require.Nil(t, policy)
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, FileName), []byte(testPolicy), osutil.PermissionFile)
+		require.NoError(t, err)
+
+		policy, err := Load(dir)
+		require.NoError(t, err)
+		require.Len(t, policy.Rules, 2)
+		require.Equal(t, []string{"prod", "prod-*"}, policy.Rules[0].Environments)
+	})
+
+	t.Run("RuleWithoutGroups", func(t *testing.T) {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, FileName), []byte("rules:\n  - operations: [deploy]\n"), osutil.PermissionFile)
+		require.NoError(t, err)
+
+		_, err = Load(dir)
+		require.Error(t, err)
+	})
+}
+
+func Test_Authorize(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, FileName), []byte(testPolicy), osutil.PermissionFile)
+	require.NoError(t, err)
+
+	policy, err := Load(dir)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		operation    string
+		envName      string
+		memberOf     []string
+		unauthorized bool
+	}{
+		{name: "UnrestrictedOperation", operation: "provision", envName: "prod"},
+		{name: "UnrestrictedEnvironment", operation: "deploy", envName: "dev"},
+		{name: "Member", operation: "deploy", envName: "prod-eastus", memberOf: []string{"release-group"}},
+		{name: "NotMember", operation: "deploy", envName: "prod", memberOf: []string{"admin-group"}, unauthorized: true},
+		{name: "MemberOfAllRules", operation: "down", envName: "prod", memberOf: []string{"release-group", "owner-group"}},
+		{name: "MemberOfSomeRules", operation: "down", envName: "prod", memberOf: []string{"release-group"}, unauthorized: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(
+				context.Background(),
+				tt.operation,
+				tt.envName,
+				func(ctx context.Context, groupIds []string) ([]string, error) {
+					memberOf := []string{}
+					for _, groupId := range groupIds {
+						for _, id := range tt.memberOf {
+							if id == groupId {
+								memberOf = append(memberOf, id)
+							}
+						}
+					}
+
+					return memberOf, nil
+				},
+			)
+
+			var unauthorizedErr *UnauthorizedError
+			require.Equal(t, tt.unauthorized, errors.As(err, &unauthorizedErr))
+			if !tt.unauthorized {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -42,3 +42,22 @@ type Cache interface {
 	Read(key string) ([]byte, error)
 	Set(key string, value []byte) error
 }
+
+// Creates the MSAL token cache, kept in memory and backed by the durable cache of the token store when not nil
+func newCache(inner Cache) cache.ExportReplace {
+	return &msalCacheAdapter{
+		cache: &memoryCache{
+			cache: make(map[string][]byte),
+			inner: inner,
+		},
+	}
+}
+
+// Creates the cache of service principal credentials, kept in memory and backed by the durable cache of the token
+// store when not nil
+func newCredentialCache(inner Cache) Cache {
+	return &memoryCache{
+		cache: make(map[string][]byte),
+		inner: inner,
+	}
+}
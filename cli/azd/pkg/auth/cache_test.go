@@ -4,15 +4,17 @@
 package auth
 
 import (
+	"os"
 	"testing"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCache(t *testing.T) {
 	root := t.TempDir()
 
-	c := newCache(root)
+	c := newCache(newFileCache(root, "cache"))
 
 	d1 := fixedMarshaller{
 		val: []byte("some data"),
@@ -39,7 +41,7 @@ func TestCache(t *testing.T) {
 	require.Equal(t, d2.val, r2.val)
 
 	// the data should be shared across instances.
-	c = newCache(root)
+	c = newCache(newFileCache(root, "cache"))
 
 	c.Replace(&r1, "d1")
 	c.Replace(&r2, "d2")
@@ -53,7 +55,7 @@ func TestCache(t *testing.T) {
 func TestCredentialCache(t *testing.T) {
 	root := t.TempDir()
 
-	c := newCredentialCache(root)
+	c := newCredentialCache(newFileCache(root, "cred"))
 
 	d1 := []byte("some data")
 
@@ -76,7 +78,7 @@ func TestCredentialCache(t *testing.T) {
 	require.Equal(t, d2, r2)
 
 	// the data should be shared across instances.
-	c = newCredentialCache(root)
+	c = newCredentialCache(newFileCache(root, "cred"))
 
 	r1, err = c.Read("d1")
 	require.NoError(t, err)
@@ -89,3 +91,88 @@ func TestCredentialCache(t *testing.T) {
 	require.Equal(t, d1, r1)
 	require.Equal(t, d2, r2)
 }
+
+func TestPassphraseCache(t *testing.T) {
+	root := t.TempDir()
+	inner := &fileCache{prefix: "cred", root: root, ext: "enc"}
+	c := &passphraseCache{passphrase: "passphrase", inner: inner}
+
+	d1 := []byte("some data")
+	require.NoError(t, c.Set("d1", d1))
+
+	// the data is encrypted at rest.
+	encrypted, err := inner.Read("d1")
+	require.NoError(t, err)
+	require.NotContains(t, string(encrypted), string(d1))
+
+	r1, err := c.Read("d1")
+	require.NoError(t, err)
+	require.Equal(t, d1, r1)
+
+	// the data can't be read with a different passphrase.
+	c = &passphraseCache{passphrase: "other passphrase", inner: inner}
+	_, err = c.Read("d1")
+	require.Error(t, err)
+}
+
+func TestTokenStoreFromConfig(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		store, err := tokenStoreFromConfig(config.NewConfig(nil))
+		require.NoError(t, err)
+		require.Equal(t, TokenStoreFile, store)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		cfg := config.NewConfig(nil)
+		require.NoError(t, cfg.Set(cTokenStoreKey, "keychain"))
+
+		store, err := tokenStoreFromConfig(cfg)
+		require.NoError(t, err)
+		require.Equal(t, TokenStoreKeychain, store)
+	})
+
+	t.Run("EnvironmentOverridesConfig", func(t *testing.T) {
+		t.Setenv(TokenStoreEnvVarName, "memory")
+		cfg := config.NewConfig(nil)
+		require.NoError(t, cfg.Set(cTokenStoreKey, "keychain"))
+
+		store, err := tokenStoreFromConfig(cfg)
+		require.NoError(t, err)
+		require.Equal(t, TokenStoreMemory, store)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		t.Setenv(TokenStoreEnvVarName, "vault")
+
+		_, err := tokenStoreFromConfig(config.NewConfig(nil))
+		require.ErrorContains(t, err, "unsupported token store 'vault'")
+	})
+}
+
+func TestTokenStoreCache(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		root := t.TempDir()
+		inner, err := newTokenStoreCache(TokenStoreMemory, root, "cred", nil)
+		require.NoError(t, err)
+
+		c := newCredentialCache(inner)
+		require.NoError(t, c.Set("d1", []byte("some data")))
+
+		// nothing is persisted.
+		entries, err := os.ReadDir(root)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		// a new instance starts empty.
+		value, err := newCredentialCache(inner).Read("d1")
+		require.NoError(t, err)
+		require.Nil(t, value)
+	})
+
+	t.Run("EncryptedFileRequiresPassphrase", func(t *testing.T) {
+		t.Setenv(TokenStorePassphraseEnvVarName, "")
+
+		_, err := newTokenStoreCache(TokenStoreEncryptedFile, t.TempDir(), "cred", nil)
+		require.ErrorContains(t, err, TokenStorePassphraseEnvVarName)
+	})
+}
@@ -7,29 +7,22 @@
 package auth
 
 import (
-	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/cache"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 )
 
-func newCache(root string) cache.ExportReplace {
-	return &msalCacheAdapter{
-		cache: &memoryCache{
-			cache: make(map[string][]byte),
-			inner: &fileCache{
-				prefix: "cache",
-				root:   root,
-				ext:    "json",
-			},
-		},
+// Creates the file backed Cache. Files are stored in [root] and are named [prefix][key].json.
+func newFileCache(root string, prefix string) Cache {
+	return &fileCache{
+		prefix: prefix,
+		root:   root,
+		ext:    "json",
 	}
 }
 
-func newCredentialCache(root string) Cache {
-	return &memoryCache{
-		cache: make(map[string][]byte),
-		inner: &fileCache{
-			prefix: "cred",
-			root:   root,
-			ext:    "json",
-		},
+// Creates the Cache backed by the keychain of the operating system
+func newKeychainCache(root string, prefix string, commandRunner exec.CommandRunner) Cache {
+	return &keychainCache{
+		prefix:        prefix,
+		commandRunner: commandRunner,
 	}
 }
@@ -10,36 +10,26 @@ import (
 	"fmt"
 	"unsafe"
 
-	"github.com/AzureAD/microsoft-authentication-library-for-go/apps/cache"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"golang.org/x/sys/windows"
 )
 
-func newCache(root string) cache.ExportReplace {
-	return &msalCacheAdapter{
-		cache: &memoryCache{
-			cache: make(map[string][]byte),
-			inner: &encryptedCache{
-				inner: &fileCache{
-					prefix: "cache",
-					root:   root,
-					ext:    "bin",
-				},
-			},
+// Creates the file backed Cache. Files are stored in [root], are named [prefix][key].bin and are encrypted with
+// CryptProtectData.
+func newFileCache(root string, prefix string) Cache {
+	return &encryptedCache{
+		inner: &fileCache{
+			prefix: prefix,
+			root:   root,
+			ext:    "bin",
 		},
 	}
 }
 
-func newCredentialCache(root string) Cache {
-	return &memoryCache{
-		cache: make(map[string][]byte),
-		inner: &encryptedCache{
-			inner: &fileCache{
-				prefix: "cred",
-				root:   root,
-				ext:    "bin",
-			},
-		},
-	}
+// Creates the Cache backed by the keychain of the operating system. On Windows, the data protection API is the
+// keychain, so the cache is the same as the file backed cache.
+func newKeychainCache(root string, prefix string, commandRunner exec.CommandRunner) Cache {
+	return newFileCache(root, prefix)
 }
 
 // encryptedCache is a Cache that wraps an existing Cache, encrypting and decrypting the cached value with CryptProtectData
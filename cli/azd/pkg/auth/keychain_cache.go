@@ -0,0 +1,80 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build unix
+// +build unix
+
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+)
+
+// The service name under which azd stores its items in the keychain
+const cKeychainService = "azd"
+
+// keychainCache implements Cache by storing the data in the keychain of the operating system: the macOS keychain
+// through the `security` tool, or the Secret Service (ex. GNOME Keyring) on Linux through the `secret-tool` tool.
+// Values are base64 encoded, since keychain items hold text. Items are named [prefix][key].
+type keychainCache struct {
+	prefix        string
+	commandRunner exec.CommandRunner
+}
+
+func (c *keychainCache) Read(key string) ([]byte, error) {
+	var runArgs exec.RunArgs
+	if runtime.GOOS == "darwin" {
+		runArgs = exec.NewRunArgs(
+			"security", "find-generic-password", "-s", cKeychainService, "-a", c.account(key), "-w")
+	} else {
+		runArgs = exec.NewRunArgs("secret-tool", "lookup", "service", cKeychainService, "account", c.account(key))
+	}
+
+	res, err := c.commandRunner.Run(context.Background(), runArgs)
+	value := strings.TrimSpace(res.Stdout)
+	if err != nil || value == "" {
+		// Both tools fail when the item doesn't exist, which matches reading a file that doesn't exist
+		return nil, fmt.Errorf("reading keychain item '%s': %w", c.account(key), os.ErrNotExist)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding keychain item '%s': %w", c.account(key), err)
+	}
+
+	return decoded, nil
+}
+
+func (c *keychainCache) Set(key string, value []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(value)
+
+	// The value is written to the standard input of the tools so it is not visible in the process list
+	var runArgs exec.RunArgs
+	if runtime.GOOS == "darwin" {
+		command := fmt.Sprintf(
+			"add-generic-password -U -s %s -a %s -w %s\n", cKeychainService, c.account(key), encoded)
+		runArgs = exec.NewRunArgs("security", "-i").WithStdIn(strings.NewReader(command))
+	} else {
+		runArgs = exec.NewRunArgs(
+			"secret-tool", "store", "--label", fmt.Sprintf("%s %s", cKeychainService, c.account(key)),
+			"service", cKeychainService, "account", c.account(key),
+		).WithStdIn(strings.NewReader(encoded))
+	}
+
+	if _, err := c.commandRunner.Run(context.Background(), runArgs); err != nil {
+		return fmt.Errorf("writing keychain item '%s': %w", c.account(key), err)
+	}
+
+	return nil
+}
+
+func (c *keychainCache) account(key string) string {
+	return c.prefix + key
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/github"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 )
@@ -54,6 +55,8 @@ var cLoginScopes = []string{azure.ManagementScope}
 // as a user (either interactively or via a device code flow), we provide a durable cache to MSAL which is used to cache
 // information to allow silent logins across process runs. This cache is stored inside the user's home directory, ACL'd such
 // that it can only be read by the current user.  In addition, on Windows, this cache is encrypted, using CryptProtectData.
+// The cache may instead be stored in the keychain of the operating system, in files encrypted with a passphrase or only in
+// memory, by selecting a [TokenStore] in the [cTokenStoreKey] config or the [TokenStoreEnvVarName] environment variable.
 // The home account id of the signed in user is stored as a property under [cCurrentUserKey]. This behavior matches the
 // AZ CLI.
 //
@@ -70,9 +73,10 @@ type Manager struct {
 	configManager       config.UserConfigManager
 	credentialCache     Cache
 	ghClient            *github.FederatedTokenClient
+	tokenStore          TokenStore
 }
 
-func NewManager(configManager config.UserConfigManager) (*Manager, error) {
+func NewManager(configManager config.UserConfigManager, commandRunner exec.CommandRunner) (*Manager, error) {
 	cfgRoot, err := config.GetUserConfigDir()
 	if err != nil {
 		return nil, fmt.Errorf("getting config dir: %w", err)
@@ -88,8 +92,28 @@ func NewManager(configManager config.UserConfigManager) (*Manager, error) {
 		return nil, fmt.Errorf("creating msal cache root: %w", err)
 	}
 
+	cfg, err := configManager.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	tokenStore, err := tokenStoreFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenCache, err := newTokenStoreCache(tokenStore, cacheRoot, "cache", commandRunner)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialCache, err := newTokenStoreCache(tokenStore, authRoot, "cred", commandRunner)
+	if err != nil {
+		return nil, err
+	}
+
 	options := []public.Option{
-		public.WithCache(newCache(cacheRoot)),
+		public.WithCache(newCache(tokenCache)),
 		public.WithAuthority(cDefaultAuthority),
 	}
 
@@ -104,13 +128,33 @@ func NewManager(configManager config.UserConfigManager) (*Manager, error) {
 		publicClient:        &msalPublicClientAdapter{client: &publicClientApp},
 		publicClientOptions: options,
 		configManager:       configManager,
-		credentialCache:     newCredentialCache(authRoot),
+		credentialCache:     newCredentialCache(credentialCache),
 		ghClient:            ghClient,
+		tokenStore:          tokenStore,
 	}, nil
 }
 
 var ErrNoCurrentUser = errors.New("not logged in, run `azd auth login` to login")
 
+// ErrLoginNotPersisted is returned when logging in with a token store that doesn't persist logins across processes
+var ErrLoginNotPersisted = fmt.Errorf(
+	"the %s token store doesn't persist logins, set %s to true to use the login of the Azure CLI, "+
+		"or select another token store with %s",
+	TokenStoreMemory,
+	cUseAzCliAuthKey,
+	cTokenStoreKey,
+)
+
+// EnsureLoginPersisted returns [ErrLoginNotPersisted] when the selected token store doesn't persist logins, so logging
+// in would have no effect on the commands that follow.
+func (m *Manager) EnsureLoginPersisted() error {
+	if m.tokenStore == TokenStoreMemory {
+		return ErrLoginNotPersisted
+	}
+
+	return nil
+}
+
 // EnsureLoggedInCredential uses the credential's GetToken method to ensure an access token can be fetched. If this fails,
 // nil, ErrNoCurrentUser is returned. On success, the token we fetched is returned.
 func EnsureLoggedInCredential(ctx context.Context, credential azcore.TokenCredential) (*azcore.AccessToken, error) {
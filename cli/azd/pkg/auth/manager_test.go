@@ -339,3 +339,8 @@ func (m *mockDeviceCode) AuthenticationResult(ctx context.Context) (public.AuthR
 		},
 	}, nil
 }
+
+func TestEnsureLoginPersisted(t *testing.T) {
+	require.NoError(t, (&Manager{tokenStore: TokenStoreFile}).EnsureLoginPersisted())
+	require.ErrorIs(t, (&Manager{tokenStore: TokenStoreMemory}).EnsureLoginPersisted(), ErrLoginNotPersisted)
+}
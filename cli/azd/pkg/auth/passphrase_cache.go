@@ -0,0 +1,100 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	cPassphraseSaltSize = 16
+	cPassphraseKeySize  = 32
+)
+
+// passphraseCache is a Cache that wraps an existing Cache, encrypting and decrypting the cached value with AES-GCM using
+// a key derived from a passphrase with scrypt. A random salt is generated for each value and stored with it.
+type passphraseCache struct {
+	passphrase string
+	inner      Cache
+}
+
+func (c *passphraseCache) Read(key string) ([]byte, error) {
+	val, err := c.inner.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(val) == 0 {
+		return val, nil
+	}
+
+	if len(val) < cPassphraseSaltSize {
+		return nil, errors.New("failed to decrypt data: invalid data")
+	}
+
+	salt, encrypted := val[:cPassphraseSaltSize], val[cPassphraseSaltSize:]
+	gcm, err := c.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.New("failed to decrypt data: invalid data")
+	}
+
+	nonce, ciphertext := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data, the passphrase may be incorrect: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func (c *passphraseCache) Set(key string, val []byte) error {
+	if len(val) == 0 {
+		return c.inner.Set(key, val)
+	}
+
+	salt := make([]byte, cPassphraseSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := c.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	encrypted := append(salt, nonce...)
+	encrypted = gcm.Seal(encrypted, nonce, val, nil)
+
+	return c.inner.Set(key, encrypted)
+}
+
+func (c *passphraseCache) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(c.passphrase), salt, 1<<15, 8, 1, cPassphraseKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+)
+
+// cTokenStoreKey is the key we use in config to select where azd stores its authentication data.
+const cTokenStoreKey = "auth.tokenStore"
+
+// TokenStoreEnvVarName is the environment variable that selects the token store, overriding the value in config.
+const TokenStoreEnvVarName = "AZD_AUTH_TOKEN_STORE"
+
+// TokenStorePassphraseEnvVarName is the environment variable holding the passphrase of the encrypted file token store.
+const TokenStorePassphraseEnvVarName = "AZD_AUTH_TOKEN_STORE_PASSPHRASE"
+
+// TokenStore is the backend used to store the token cache and the credentials of the signed in account.
+type TokenStore string
+
+const (
+	// TokenStoreFile stores the data in files in the user's home directory, ACL'd such that they can only be read by the
+	// current user. On Windows, the files are encrypted with CryptProtectData. This is the default.
+	TokenStoreFile TokenStore = "file"
+	// TokenStoreKeychain stores the data in the keychain of the operating system: the macOS keychain, the Secret
+	// Service on Linux, and files encrypted with CryptProtectData on Windows.
+	TokenStoreKeychain TokenStore = "keychain"
+	// TokenStoreEncryptedFile stores the data in files encrypted with a key derived from the passphrase set in
+	// [TokenStorePassphraseEnvVarName].
+	TokenStoreEncryptedFile TokenStore = "encrypted-file"
+	// TokenStoreMemory keeps the data in memory for the lifetime of the process, which avoids persisting credentials
+	// on shared machines such as CI agents. Since logins don't outlive the process, `azd auth login` is refused and
+	// authentication is delegated to the Azure CLI with the [cUseAzCliAuthKey] config instead.
+	TokenStoreMemory TokenStore = "memory"
+)
+
+// TokenStores are the supported token stores.
+var TokenStores = []TokenStore{TokenStoreFile, TokenStoreKeychain, TokenStoreEncryptedFile, TokenStoreMemory}
+
+// Gets the token store selected by the [TokenStoreEnvVarName] environment variable or the [cTokenStoreKey] config,
+// defaulting to [TokenStoreFile].
+func tokenStoreFromConfig(cfg config.Config) (TokenStore, error) {
+	value := os.Getenv(TokenStoreEnvVarName)
+	if value == "" {
+		if configValue, has := cfg.Get(cTokenStoreKey); has {
+			stringValue, ok := configValue.(string)
+			if !ok {
+				return "", fmt.Errorf("invalid value for %s, expected a string", cTokenStoreKey)
+			}
+
+			value = stringValue
+		}
+	}
+
+	if value == "" {
+		return TokenStoreFile, nil
+	}
+
+	for _, store := range TokenStores {
+		if strings.EqualFold(value, string(store)) {
+			return store, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported token store '%s', supported token stores are: %s", value, tokenStoreNames())
+}
+
+// Creates the durable Cache for the token store. Objects are stored in [root] with names beginning with [prefix].
+// The memory token store has no durable cache and returns nil.
+func newTokenStoreCache(store TokenStore, root string, prefix string, commandRunner exec.CommandRunner) (Cache, error) {
+	switch store {
+	case TokenStoreFile:
+		return newFileCache(root, prefix), nil
+	case TokenStoreKeychain:
+		return newKeychainCache(root, prefix, commandRunner), nil
+	case TokenStoreEncryptedFile:
+		passphrase := os.Getenv(TokenStorePassphraseEnvVarName)
+		if passphrase == "" {
+			return nil, fmt.Errorf(
+				"the %s token store requires a passphrase, set it in the %s environment variable",
+				TokenStoreEncryptedFile,
+				TokenStorePassphraseEnvVarName,
+			)
+		}
+
+		return &passphraseCache{
+			passphrase: passphrase,
+			inner: &fileCache{
+				prefix: prefix,
+				root:   root,
+				ext:    "enc",
+			},
+		}, nil
+	case TokenStoreMemory:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported token store '%s', supported token stores are: %s", store, tokenStoreNames())
+	}
+}
+
+func tokenStoreNames() string {
+	names := make([]string, len(TokenStores))
+	for i, store := range TokenStores {
+		names[i] = string(store)
+	}
+
+	return strings.Join(names, ", ")
+}
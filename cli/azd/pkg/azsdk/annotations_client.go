@@ -0,0 +1,75 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const annotationsApiVersion = "2015-05-01"
+
+// Annotation is a release annotation of an Application Insights component, shown on the charts of the component
+type Annotation struct {
+	Id        string    `json:"Id"`
+	Name      string    `json:"AnnotationName"`
+	Category  string    `json:"Category"`
+	EventTime time.Time `json:"EventTime"`
+	// The properties of the annotation serialized as a JSON object, ex) {"ReleaseName":"api"}
+	Properties string `json:"Properties"`
+}
+
+// AnnotationsClient wraps usage of the Application Insights annotations REST API used to create release annotations
+// More info can be found at https://learn.microsoft.com/azure/azure-monitor/app/release-and-work-item-insights
+type AnnotationsClient struct {
+	pipeline runtime.Pipeline
+}
+
+// Creates a new AnnotationsClient instance
+func NewAnnotationsClient(credential azcore.TokenCredential, options *arm.ClientOptions) (*AnnotationsClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("annotations", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	return &AnnotationsClient{
+		pipeline: pipeline,
+	}, nil
+}
+
+// Creates the annotation on the Application Insights component
+func (c *AnnotationsClient) Create(ctx context.Context, componentId string, annotation Annotation) error {
+	endpointUrl := fmt.Sprintf("%s/%s/Annotations", resourceManagerOrigin, strings.TrimPrefix(componentId, "/"))
+	req, err := runtime.NewRequest(ctx, http.MethodPut, endpointUrl)
+	if err != nil {
+		return fmt.Errorf("creating annotation request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = url.Values{"api-version": []string{annotationsApiVersion}}.Encode()
+	if err := runtime.MarshalAsJSON(req, annotation); err != nil {
+		return err
+	}
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusCreated) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
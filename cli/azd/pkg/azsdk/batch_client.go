@@ -0,0 +1,250 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	batchApiVersion = "2022-10-01.16.0"
+	batchScope      = "https://batch.core.windows.net//.default"
+)
+
+// BatchTaskState is the state of an Azure Batch task
+type BatchTaskState string
+
+const (
+	BatchTaskStateActive    BatchTaskState = "active"
+	BatchTaskStatePreparing BatchTaskState = "preparing"
+	BatchTaskStateRunning   BatchTaskState = "running"
+	BatchTaskStateCompleted BatchTaskState = "completed"
+)
+
+// BatchJob is the definition of an Azure Batch job
+type BatchJob struct {
+	Id       string           `json:"id"`
+	PoolInfo BatchPoolInfo    `json:"poolInfo"`
+	Metadata []BatchNameValue `json:"metadata,omitempty"`
+}
+
+// BatchPoolInfo specifies the pool where the tasks of a job are scheduled
+type BatchPoolInfo struct {
+	PoolId string `json:"poolId"`
+}
+
+// BatchNameValue is a generic name/value pair used for metadata & environment settings
+type BatchNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// BatchTask is the definition of an Azure Batch task.
+// Only the properties used by azd are modeled.
+type BatchTask struct {
+	Id                  string                  `json:"id"`
+	DisplayName         string                  `json:"displayName,omitempty"`
+	CommandLine         string                  `json:"commandLine"`
+	ContainerSettings   *BatchContainerSettings `json:"containerSettings,omitempty"`
+	EnvironmentSettings []BatchNameValue        `json:"environmentSettings,omitempty"`
+	State               BatchTaskState          `json:"state,omitempty"`
+	ExecutionInfo       *BatchTaskExecutionInfo `json:"executionInfo,omitempty"`
+}
+
+// BatchContainerSettings are the container settings for a task
+type BatchContainerSettings struct {
+	ImageName           string                  `json:"imageName"`
+	ContainerRunOptions string                  `json:"containerRunOptions,omitempty"`
+	Registry            *BatchContainerRegistry `json:"registry,omitempty"`
+	WorkingDirectory    string                  `json:"workingDirectory,omitempty"`
+}
+
+// BatchContainerRegistry is the private registry used to pull the task container image
+type BatchContainerRegistry struct {
+	RegistryServer    string                  `json:"registryServer"`
+	Username          string                  `json:"username,omitempty"`
+	Password          string                  `json:"password,omitempty"`
+	IdentityReference *BatchIdentityReference `json:"identityReference,omitempty"`
+}
+
+// BatchIdentityReference references a user assigned identity associated with the Batch pool
+type BatchIdentityReference struct {
+	ResourceId string `json:"resourceId"`
+}
+
+// BatchTaskExecutionInfo contains information about the execution of a task
+type BatchTaskExecutionInfo struct {
+	ExitCode    *int                  `json:"exitCode,omitempty"`
+	Result      string                `json:"result,omitempty"`
+	FailureInfo *BatchTaskFailureInfo `json:"failureInfo,omitempty"`
+}
+
+// BatchTaskFailureInfo contains information about a task failure
+type BatchTaskFailureInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchClient wraps usage of the Azure Batch data plane REST API used to submit jobs & tasks
+// More info can be found at https://learn.microsoft.com/rest/api/batchservice/
+type BatchClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// Creates a new BatchClient instance for the specified batch account endpoint,
+// ex) https://{account}.{region}.batch.azure.com
+func NewBatchClient(
+	accountEndpoint string,
+	credential azcore.TokenCredential,
+	options *azcore.ClientOptions,
+) *BatchClient {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	authPolicy := runtime.NewBearerTokenPolicy(credential, []string{batchScope}, nil)
+	pipelineOptions := runtime.PipelineOptions{
+		PerRetry: []policy.Policy{authPolicy},
+	}
+
+	return &BatchClient{
+		endpoint: strings.TrimSuffix(accountEndpoint, "/"),
+		pipeline: runtime.NewPipeline("batch", "1.0.0", pipelineOptions, options),
+	}
+}
+
+// Creates the job when it does not already exist. Existing jobs are left untouched.
+func (c *BatchClient) EnsureJob(ctx context.Context, job *BatchJob) error {
+	req, err := c.createRequest(ctx, http.MethodPost, "jobs")
+	if err != nil {
+		return err
+	}
+
+	if err := graphsdk.SetHttpRequestBody(req, job); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusCreated, http.StatusConflict) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// Adds the task to the specified job
+func (c *BatchClient) AddTask(ctx context.Context, jobId string, task *BatchTask) error {
+	req, err := c.createRequest(ctx, http.MethodPost, fmt.Sprintf("jobs/%s/tasks", url.PathEscape(jobId)))
+	if err != nil {
+		return err
+	}
+
+	if err := graphsdk.SetHttpRequestBody(req, task); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusCreated) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// Gets the current state of the specified task
+func (c *BatchClient) GetTask(ctx context.Context, jobId string, taskId string) (*BatchTask, error) {
+	req, err := c.createRequest(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("jobs/%s/tasks/%s", url.PathEscape(jobId), url.PathEscape(taskId)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return httputil.ReadRawResponse[BatchTask](res)
+}
+
+// Gets the content of a file written by the specified task, ex) stdout.txt, starting at the byte offset.
+// Returns no content when the file has not been created yet or no data is available past the offset.
+func (c *BatchClient) GetTaskFile(
+	ctx context.Context,
+	jobId string,
+	taskId string,
+	filePath string,
+	offset int64,
+) ([]byte, error) {
+	req, err := c.createRequest(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("jobs/%s/tasks/%s/files/%s", url.PathEscape(jobId), url.PathEscape(taskId), url.PathEscape(filePath)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Header.Set("Accept", "application/octet-stream")
+	rawRequest.Header.Set("ocp-range", fmt.Sprintf("bytes=%d-", offset))
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+	defer res.Body.Close()
+
+	if runtime.HasStatusCode(res, http.StatusNotFound, http.StatusRequestedRangeNotSatisfiable) {
+		return nil, nil
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK, http.StatusPartialContent) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+func (c *BatchClient) createRequest(ctx context.Context, method string, path string) (*policy.Request, error) {
+	req, err := runtime.NewRequest(ctx, method, fmt.Sprintf("%s/%s", c.endpoint, path))
+	if err != nil {
+		return nil, fmt.Errorf("creating batch request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	query := rawRequest.URL.Query()
+	query.Set("api-version", batchApiVersion)
+	rawRequest.URL.RawQuery = query.Encode()
+	rawRequest.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
@@ -0,0 +1,237 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	// The version of the Blob service REST API, supporting the authorization of the source of blob copies
+	blobApiVersion = "2021-08-06"
+
+	// The scope of the tokens used to access Azure Storage
+	StorageScope = "https://storage.azure.com/.default"
+
+	// The type of blobs made of blocks, the only type of blob copied from URLs
+	BlockBlobType = "BlockBlob"
+)
+
+// BlobContainer is a container of a storage account
+type BlobContainer struct {
+	Name string `xml:"Name"`
+}
+
+// BlobProperties are the properties of a blob
+type BlobProperties struct {
+	BlobType      string `xml:"BlobType"`
+	ContentLength int64  `xml:"Content-Length"`
+}
+
+// Blob is a blob of a container
+type Blob struct {
+	Name       string         `xml:"Name"`
+	Properties BlobProperties `xml:"Properties"`
+}
+
+type listContainersResult struct {
+	Containers []BlobContainer `xml:"Containers>Container"`
+	NextMarker string          `xml:"NextMarker"`
+}
+
+type listBlobsResult struct {
+	Blobs      []Blob `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// BlobClient wraps usage of the Azure Blob Storage REST API used to copy the content of storage accounts
+// More info can be found at https://learn.microsoft.com/rest/api/storageservices/blob-service-rest-api
+type BlobClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// Creates a new BlobClient instance for the blob endpoint of the storage account,
+// ex) https://{account}.blob.core.windows.net
+func NewBlobClient(endpoint string, credential azcore.TokenCredential, options *azcore.ClientOptions) *BlobClient {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	authPolicy := runtime.NewBearerTokenPolicy(credential, []string{StorageScope}, nil)
+	pipelineOptions := runtime.PipelineOptions{
+		PerRetry: []policy.Policy{authPolicy},
+	}
+
+	return &BlobClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		pipeline: runtime.NewPipeline("blob", "1.0.0", pipelineOptions, options),
+	}
+}
+
+// Gets the containers of the storage account
+func (c *BlobClient) ListContainers(ctx context.Context) ([]BlobContainer, error) {
+	containers := []BlobContainer{}
+	marker := ""
+
+	for {
+		query := url.Values{"comp": []string{"list"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		result, err := listPage[listContainersResult](ctx, c, c.endpoint, query)
+		if err != nil {
+			return nil, err
+		}
+
+		containers = append(containers, result.Containers...)
+		if result.NextMarker == "" {
+			return containers, nil
+		}
+
+		marker = result.NextMarker
+	}
+}
+
+// Creates the container in the storage account, unless it already exists
+func (c *BlobClient) CreateContainer(ctx context.Context, containerName string) error {
+	req, err := c.createRequest(ctx, http.MethodPut, c.containerUrl(containerName))
+	if err != nil {
+		return err
+	}
+
+	req.Raw().URL.RawQuery = url.Values{"restype": []string{"container"}}.Encode()
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusCreated, http.StatusConflict) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// Gets the blobs of the container
+func (c *BlobClient) ListBlobs(ctx context.Context, containerName string) ([]Blob, error) {
+	blobs := []Blob{}
+	marker := ""
+
+	for {
+		query := url.Values{"restype": []string{"container"}, "comp": []string{"list"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		result, err := listPage[listBlobsResult](ctx, c, c.containerUrl(containerName), query)
+		if err != nil {
+			return nil, err
+		}
+
+		blobs = append(blobs, result.Blobs...)
+		if result.NextMarker == "" {
+			return blobs, nil
+		}
+
+		marker = result.NextMarker
+	}
+}
+
+// Gets the URL of the blob of the container
+func (c *BlobClient) BlobUrl(containerName string, blobName string) string {
+	segments := strings.Split(blobName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return fmt.Sprintf("%s/%s", c.containerUrl(containerName), strings.Join(segments, "/"))
+}
+
+// Copies the block blob at the source URL to the blob of the container, replacing the blob when it exists. The copy is
+// performed by the storage service, authorized by the token of the source, so the content isn't downloaded.
+func (c *BlobClient) CopyBlobFromUrl(
+	ctx context.Context,
+	containerName string,
+	blobName string,
+	sourceUrl string,
+	sourceToken string,
+) error {
+	req, err := c.createRequest(ctx, http.MethodPut, c.BlobUrl(containerName, blobName))
+	if err != nil {
+		return err
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Header.Set("x-ms-blob-type", BlockBlobType)
+	rawRequest.Header.Set("x-ms-copy-source", sourceUrl)
+	rawRequest.Header.Set("x-ms-copy-source-authorization", fmt.Sprintf("Bearer %s", sourceToken))
+	rawRequest.Header.Set("Content-Length", "0")
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusCreated) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+func (c *BlobClient) containerUrl(containerName string) string {
+	return fmt.Sprintf("%s/%s", c.endpoint, url.PathEscape(containerName))
+}
+
+func (c *BlobClient) createRequest(ctx context.Context, method string, requestUrl string) (*policy.Request, error) {
+	req, err := runtime.NewRequest(ctx, method, requestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating blob request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Header.Set("x-ms-version", blobApiVersion)
+	rawRequest.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+
+	return req, nil
+}
+
+// Gets a page of the XML listing at the URL
+func listPage[T any](ctx context.Context, c *BlobClient, requestUrl string, query url.Values) (*T, error) {
+	req, err := c.createRequest(ctx, http.MethodGet, requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Raw().URL.RawQuery = query.Encode()
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	defer res.Body.Close()
+
+	var result T
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding blob listing: %w", err)
+	}
+
+	return &result, nil
+}
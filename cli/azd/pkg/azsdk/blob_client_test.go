@@ -0,0 +1,165 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobClient(t *testing.T) {
+	t.Run("ListContainers", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		markers := []string{}
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && request.URL.Query().Get("comp") == "list"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			marker := request.URL.Query().Get("marker")
+			markers = append(markers, marker)
+
+			if marker == "" {
+				return createXmlResponse(request, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Containers><Container><Name>images</Name></Container></Containers>
+<NextMarker>page2</NextMarker></EnumerationResults>`)
+			}
+
+			return createXmlResponse(request, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Containers><Container><Name>reports</Name></Container></Containers>
+<NextMarker /></EnumerationResults>`)
+		})
+
+		client := newTestBlobClient(mockContext)
+
+		containers, err := client.ListContainers(*mockContext.Context)
+		require.NoError(t, err)
+		require.Equal(t, []BlobContainer{{Name: "images"}, {Name: "reports"}}, containers)
+		require.Equal(t, []string{"", "page2"}, markers)
+	})
+
+	t.Run("ListBlobs", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		markers := []string{}
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			query := request.URL.Query()
+			return request.Method == http.MethodGet &&
+				request.URL.Path == "/images" &&
+				query.Get("restype") == "container" &&
+				query.Get("comp") == "list"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			marker := request.URL.Query().Get("marker")
+			markers = append(markers, marker)
+
+			if marker == "" {
+				return createXmlResponse(request, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Blobs><Blob><Name>logo.png</Name>
+<Properties><BlobType>BlockBlob</BlobType><Content-Length>1024</Content-Length></Properties></Blob></Blobs>
+<NextMarker>page2</NextMarker></EnumerationResults>`)
+			}
+
+			return createXmlResponse(request, `<?xml version="1.0" encoding="utf-8"?>
+<EnumerationResults><Blobs><Blob><Name>logs/app.log</Name>
+<Properties><BlobType>AppendBlob</BlobType><Content-Length>42</Content-Length></Properties></Blob></Blobs>
+<NextMarker /></EnumerationResults>`)
+		})
+
+		client := newTestBlobClient(mockContext)
+
+		blobs, err := client.ListBlobs(*mockContext.Context, "images")
+		require.NoError(t, err)
+		require.Equal(t, []Blob{
+			{Name: "logo.png", Properties: BlobProperties{BlobType: BlockBlobType, ContentLength: 1024}},
+			{Name: "logs/app.log", Properties: BlobProperties{BlobType: "AppendBlob", ContentLength: 42}},
+		}, blobs)
+		require.Equal(t, []string{"", "page2"}, markers)
+	})
+
+	t.Run("CreateContainer", func(t *testing.T) {
+		for _, statusCode := range []int{http.StatusCreated, http.StatusConflict} {
+			t.Run(fmt.Sprint(statusCode), func(t *testing.T) {
+				mockContext := mocks.NewMockContext(context.Background())
+				mockContext.HttpClient.When(func(request *http.Request) bool {
+					return request.Method == http.MethodPut &&
+						request.URL.Path == "/images" &&
+						request.URL.Query().Get("restype") == "container"
+				}).RespondFn(func(request *http.Request) (*http.Response, error) {
+					return mocks.CreateEmptyHttpResponse(request, statusCode)
+				})
+
+				client := newTestBlobClient(mockContext)
+
+				// A container that already exists is reused
+				err := client.CreateContainer(*mockContext.Context, "images")
+				require.NoError(t, err)
+			})
+		}
+	})
+
+	t.Run("CopyBlobFromUrl", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		var copied *http.Request
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			copied = request
+			return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+		})
+
+		client := newTestBlobClient(mockContext)
+		sourceUrl := "https://source.blob.core.windows.net/images/logs/app%201.log"
+
+		err := client.CopyBlobFromUrl(*mockContext.Context, "images", "logs/app 1.log", sourceUrl, "SOURCE_TOKEN")
+		require.NoError(t, err)
+
+		require.NotNil(t, copied)
+		require.Equal(t, "/images/logs/app 1.log", copied.URL.Path)
+		require.Equal(t, "https://target.blob.core.windows.net/images/logs/app%201.log", copied.URL.String())
+		require.Equal(t, BlockBlobType, copied.Header.Get("x-ms-blob-type"))
+		require.Equal(t, sourceUrl, copied.Header.Get("x-ms-copy-source"))
+		require.Equal(t, "Bearer SOURCE_TOKEN", copied.Header.Get("x-ms-copy-source-authorization"))
+		require.Equal(t, blobApiVersion, copied.Header.Get("x-ms-version"))
+		require.True(t, strings.HasPrefix(copied.Header.Get("Authorization"), "Bearer "))
+	})
+
+	t.Run("CopyBlobFromUrlError", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusForbidden)
+		})
+
+		client := newTestBlobClient(mockContext)
+
+		err := client.CopyBlobFromUrl(
+			*mockContext.Context,
+			"images",
+			"logo.png",
+			"https://source.blob.core.windows.net/images/logo.png",
+			"SOURCE_TOKEN",
+		)
+		require.ErrorContains(t, err, "403")
+	})
+}
+
+func newTestBlobClient(mockContext *mocks.MockContext) *BlobClient {
+	options := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		BuildCoreClientOptions()
+
+	return NewBlobClient("https://target.blob.core.windows.net", &mocks.MockCredentials{}, options)
+}
+
+func createXmlResponse(request *http.Request, body string) (*http.Response, error) {
+	return &http.Response{
+		Request:    request,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/xml"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}, nil
+}
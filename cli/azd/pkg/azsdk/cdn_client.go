@@ -0,0 +1,114 @@
+package azsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	cdnApiVersion         = "2023-05-01"
+	resourceManagerOrigin = "https://management.azure.com"
+)
+
+// CdnClient wraps usage of the Azure CDN & Azure Front Door management REST API used to purge cached content
+// More info can be found at https://learn.microsoft.com/rest/api/cdn/
+type CdnClient struct {
+	subscriptionId string
+	pipeline       runtime.Pipeline
+}
+
+// Creates a new CdnClient instance
+func NewCdnClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*CdnClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("cdn", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	return &CdnClient{
+		subscriptionId: subscriptionId,
+		pipeline:       pipeline,
+	}, nil
+}
+
+// Purges the content paths cached by the endpoint of the profile, ex) /images/*, and waits for the purge to complete.
+// The endpoint is either an Azure Front Door endpoint or an Azure CDN endpoint.
+func (c *CdnClient) Purge(
+	ctx context.Context,
+	resourceGroupName string,
+	profileName string,
+	endpointName string,
+	contentPaths []string,
+) error {
+	// Front Door and CDN endpoints are both endpoints of Microsoft.Cdn profiles, under different resource types
+	err := c.purge(ctx, resourceGroupName, profileName, "afdEndpoints", endpointName, contentPaths)
+
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+		return c.purge(ctx, resourceGroupName, profileName, "endpoints", endpointName, contentPaths)
+	}
+
+	return err
+}
+
+func (c *CdnClient) purge(
+	ctx context.Context,
+	resourceGroupName string,
+	profileName string,
+	endpointType string,
+	endpointName string,
+	contentPaths []string,
+) error {
+	endpointUrl := fmt.Sprintf(
+		"%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Cdn/profiles/%s/%s/%s/purge",
+		resourceManagerOrigin,
+		url.PathEscape(c.subscriptionId),
+		url.PathEscape(resourceGroupName),
+		url.PathEscape(profileName),
+		endpointType,
+		url.PathEscape(endpointName),
+	)
+
+	req, err := runtime.NewRequest(ctx, http.MethodPost, endpointUrl)
+	if err != nil {
+		return fmt.Errorf("creating purge request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = url.Values{"api-version": []string{cdnApiVersion}}.Encode()
+	if err := runtime.MarshalAsJSON(req, map[string][]string{"contentPaths": contentPaths}); err != nil {
+		return err
+	}
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusAccepted) {
+		return runtime.NewResponseError(response)
+	}
+
+	poller, err := runtime.NewPoller[struct{}](response, c.pipeline, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
@@ -0,0 +1,363 @@
+package azsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	cosmosApiVersion = "2018-12-31"
+
+	// The maximum number of documents read from the change feed at once
+	cosmosChangeFeedPageSize = 100
+)
+
+// The system properties of Cosmos DB documents, generated by the service when a document is written
+var cosmosSystemProperties = []string{"_rid", "_self", "_etag", "_attachments", "_ts", "_lsn"}
+
+// CosmosDatabase is a database of a Cosmos DB account
+type CosmosDatabase struct {
+	Id string `json:"id"`
+}
+
+// CosmosPartitionKey is the definition of the partition key of a Cosmos DB container
+type CosmosPartitionKey struct {
+	Paths []string `json:"paths"`
+	Kind  string   `json:"kind"`
+}
+
+// CosmosContainer is a container of a Cosmos DB database
+type CosmosContainer struct {
+	Id           string             `json:"id"`
+	PartitionKey CosmosPartitionKey `json:"partitionKey"`
+}
+
+// CosmosDocument is a document of a Cosmos DB container
+type CosmosDocument map[string]any
+
+type cosmosPartitionKeyRange struct {
+	Id string `json:"id"`
+}
+
+// CosmosDataClient wraps usage of the Azure Cosmos DB for NoSQL REST API used to copy the documents of containers
+// More info can be found at https://learn.microsoft.com/rest/api/cosmos-db/
+type CosmosDataClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// Creates a new CosmosDataClient instance for the endpoint of the account, ex) https://{account}.documents.azure.com:443/
+func NewCosmosDataClient(
+	endpoint string,
+	credential azcore.TokenCredential,
+	options *azcore.ClientOptions,
+) (*CosmosDataClient, error) {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Cosmos DB endpoint '%s': %w", endpoint, err)
+	}
+
+	authPolicy := &cosmosAuthPolicy{
+		credential: credential,
+		scopes:     []string{fmt.Sprintf("https://%s/.default", endpointUrl.Hostname())},
+	}
+	pipelineOptions := runtime.PipelineOptions{
+		PerRetry: []policy.Policy{authPolicy},
+	}
+
+	return &CosmosDataClient{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		pipeline: runtime.NewPipeline("cosmos", "1.0.0", pipelineOptions, options),
+	}, nil
+}
+
+// Gets the databases of the account
+func (c *CosmosDataClient) ListDatabases(ctx context.Context) ([]CosmosDatabase, error) {
+	res, err := c.get(ctx, "dbs")
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := httputil.ReadRawResponse[struct {
+		Databases []CosmosDatabase `json:"Databases"`
+	}](res)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Databases, nil
+}
+
+// Gets the containers of the database
+func (c *CosmosDataClient) ListContainers(ctx context.Context, databaseId string) ([]CosmosContainer, error) {
+	res, err := c.get(ctx, fmt.Sprintf("dbs/%s/colls", url.PathEscape(databaseId)))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := httputil.ReadRawResponse[struct {
+		DocumentCollections []CosmosContainer `json:"DocumentCollections"`
+	}](res)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.DocumentCollections, nil
+}
+
+// Gets the container of the database. Returns an azcore.ResponseError with status 404 when the container doesn't exist.
+func (c *CosmosDataClient) GetContainer(
+	ctx context.Context,
+	databaseId string,
+	containerId string,
+) (*CosmosContainer, error) {
+	res, err := c.get(ctx, containerPath(databaseId, containerId))
+	if err != nil {
+		return nil, err
+	}
+
+	return httputil.ReadRawResponse[CosmosContainer](res)
+}
+
+// Reads the current version of all the documents of the container from its change feed, calling the handler with each
+// page of documents, in order
+func (c *CosmosDataClient) ReadChangeFeed(
+	ctx context.Context,
+	databaseId string,
+	containerId string,
+	handler func(documents []CosmosDocument) error,
+) error {
+	res, err := c.get(ctx, fmt.Sprintf("%s/pkranges", containerPath(databaseId, containerId)))
+	if err != nil {
+		return err
+	}
+
+	ranges, err := httputil.ReadRawResponse[struct {
+		PartitionKeyRanges []cosmosPartitionKeyRange `json:"PartitionKeyRanges"`
+	}](res)
+	if err != nil {
+		return err
+	}
+
+	// The change feed is read per partition key range, from the beginning, until the range doesn't have more changes
+	for _, partitionKeyRange := range ranges.PartitionKeyRanges {
+		continuation := ""
+
+		for {
+			req, err := c.createRequest(ctx, http.MethodGet, fmt.Sprintf("%s/docs", containerPath(databaseId, containerId)))
+			if err != nil {
+				return err
+			}
+
+			rawRequest := req.Raw()
+			rawRequest.Header.Set("A-IM", "Incremental feed")
+			rawRequest.Header.Set("x-ms-documentdb-partitionkeyrangeid", partitionKeyRange.Id)
+			rawRequest.Header.Set("x-ms-max-item-count", fmt.Sprint(cosmosChangeFeedPageSize))
+			if continuation != "" {
+				rawRequest.Header.Set("If-None-Match", continuation)
+			}
+
+			res, err := c.pipeline.Do(req)
+			if err != nil {
+				return httputil.HandleRequestError(res, err)
+			}
+
+			if runtime.HasStatusCode(res, http.StatusNotModified) {
+				res.Body.Close()
+				break
+			}
+
+			if !runtime.HasStatusCode(res, http.StatusOK) {
+				return runtime.NewResponseError(res)
+			}
+
+			documents, err := readDocuments(res)
+			if err != nil {
+				return err
+			}
+
+			if len(documents) == 0 {
+				break
+			}
+
+			if err := handler(documents); err != nil {
+				return err
+			}
+
+			continuation = res.Header.Get("etag")
+			if continuation == "" {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// Creates the document in the container, or replaces the document with the same id and partition key
+func (c *CosmosDataClient) UpsertDocument(
+	ctx context.Context,
+	databaseId string,
+	container *CosmosContainer,
+	document CosmosDocument,
+) error {
+	partitionKey, err := json.Marshal(PartitionKeyValue(container.PartitionKey, document))
+	if err != nil {
+		return fmt.Errorf("marshalling partition key: %w", err)
+	}
+
+	body := CosmosDocument{}
+	for key, value := range document {
+		body[key] = value
+	}
+
+	for _, property := range cosmosSystemProperties {
+		delete(body, property)
+	}
+
+	req, err := c.createRequest(ctx, http.MethodPost, fmt.Sprintf("%s/docs", containerPath(databaseId, container.Id)))
+	if err != nil {
+		return err
+	}
+
+	req.Raw().Header.Set("x-ms-documentdb-is-upsert", "True")
+	req.Raw().Header.Set("x-ms-documentdb-partitionkey", string(partitionKey))
+
+	if err := graphsdk.SetHttpRequestBody(req, body); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK, http.StatusCreated) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// PartitionKeyValue gets the value of the partition key of the document, as sent to Cosmos DB. Documents without a value
+// for a path of the partition key are stored with an undefined value, represented by an empty object.
+func PartitionKeyValue(partitionKey CosmosPartitionKey, document CosmosDocument) []any {
+	values := []any{}
+
+	for _, path := range partitionKey.Paths {
+		var value any = map[string]any(document)
+		for _, segment := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+			node, ok := value.(map[string]any)
+			if !ok {
+				value = nil
+				break
+			}
+
+			if value, ok = node[segment]; !ok {
+				value = nil
+				break
+			}
+		}
+
+		if value == nil {
+			value = map[string]any{}
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func (c *CosmosDataClient) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := c.createRequest(ctx, http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return res, nil
+}
+
+func (c *CosmosDataClient) createRequest(ctx context.Context, method string, path string) (*policy.Request, error) {
+	req, err := runtime.NewRequest(ctx, method, fmt.Sprintf("%s/%s", c.endpoint, path))
+	if err != nil {
+		return nil, fmt.Errorf("creating cosmos request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Header.Set("Accept", "application/json")
+	rawRequest.Header.Set("x-ms-version", cosmosApiVersion)
+	rawRequest.Header.Set("x-ms-date", strings.ToLower(time.Now().UTC().Format(http.TimeFormat)))
+
+	return req, nil
+}
+
+func containerPath(databaseId string, containerId string) string {
+	return fmt.Sprintf("dbs/%s/colls/%s", url.PathEscape(databaseId), url.PathEscape(containerId))
+}
+
+// Reads the documents of a change feed response, preserving the precision of their numbers
+func readDocuments(res *http.Response) ([]CosmosDocument, error) {
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Documents []CosmosDocument `json:"Documents"`
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed unmarshalling JSON from response: %w", err)
+	}
+
+	return result.Documents, nil
+}
+
+// cosmosAuthPolicy authorizes the requests to Cosmos DB with Microsoft Entra ID tokens, which Cosmos DB expects in its
+// own format of the authorization header instead of bearer tokens
+type cosmosAuthPolicy struct {
+	credential azcore.TokenCredential
+	scopes     []string
+}
+
+func (p *cosmosAuthPolicy) Do(req *policy.Request) (*http.Response, error) {
+	token, err := p.credential.GetToken(req.Raw().Context(), policy.TokenRequestOptions{Scopes: p.scopes})
+	if err != nil {
+		return nil, fmt.Errorf("getting Cosmos DB token: %w", err)
+	}
+
+	req.Raw().Header.Set("Authorization", url.QueryEscape(fmt.Sprintf("type=aad&ver=1.0&sig=%s", token.Token)))
+
+	return req.Next()
+}
@@ -0,0 +1,202 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCosmosDataClient(t *testing.T) {
+	t.Run("ReadChangeFeed", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerChangeFeedMocks(mockContext)
+
+		client := newTestCosmosDataClient(t, mockContext)
+
+		pages := [][]string{}
+		err := client.ReadChangeFeed(*mockContext.Context, "app", "orders", func(documents []CosmosDocument) error {
+			ids := []string{}
+			for _, document := range documents {
+				ids = append(ids, document["id"].(string))
+			}
+
+			pages = append(pages, ids)
+			return nil
+		})
+		require.NoError(t, err)
+
+		// The pages of each partition key range are read until the range has no more changes, with the etag of the
+		// previous page as the continuation
+		require.Equal(t, [][]string{{"a", "b"}, {"c"}, {"d"}}, pages)
+	})
+
+	t.Run("ReadChangeFeedError", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		registerChangeFeedMocks(mockContext)
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/docs")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusForbidden)
+		})
+
+		client := newTestCosmosDataClient(t, mockContext)
+
+		err := client.ReadChangeFeed(*mockContext.Context, "app", "orders", func(documents []CosmosDocument) error {
+			return nil
+		})
+		require.ErrorContains(t, err, "403")
+	})
+
+	t.Run("UpsertDocument", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+
+		var upserted *http.Request
+		var body map[string]any
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && request.URL.Path == "/dbs/app/colls/orders/docs"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			upserted = request
+
+			content, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+
+			decoder := json.NewDecoder(strings.NewReader(string(content)))
+			decoder.UseNumber()
+			require.NoError(t, decoder.Decode(&body))
+
+			return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+		})
+
+		client := newTestCosmosDataClient(t, mockContext)
+		container := &CosmosContainer{
+			Id:           "orders",
+			PartitionKey: CosmosPartitionKey{Paths: []string{"/customer/id"}, Kind: "Hash"},
+		}
+		document := CosmosDocument{
+			"id":       "a",
+			"customer": map[string]any{"id": "contoso"},
+			"total":    json.Number("12345678901234567890"),
+			"_rid":     "rid",
+			"_etag":    "\"etag\"",
+			"_ts":      json.Number("1700000000"),
+		}
+
+		err := client.UpsertDocument(*mockContext.Context, "app", container, document)
+		require.NoError(t, err)
+
+		require.NotNil(t, upserted)
+		require.Equal(t, "True", upserted.Header.Get("x-ms-documentdb-is-upsert"))
+		require.Equal(t, `["contoso"]`, upserted.Header.Get("x-ms-documentdb-partitionkey"))
+		require.Equal(t, cosmosApiVersion, upserted.Header.Get("x-ms-version"))
+		require.True(t, strings.HasPrefix(upserted.Header.Get("Authorization"), "type%3Daad%26ver%3D1.0%26sig%3D"))
+
+		// The system properties are generated again by the target, the numbers keep their precision
+		require.Equal(t, map[string]any{
+			"id":       "a",
+			"customer": map[string]any{"id": "contoso"},
+			"total":    json.Number("12345678901234567890"),
+		}, body)
+
+		// The document of the source isn't changed
+		require.Contains(t, document, "_rid")
+	})
+
+	t.Run("UpsertDocumentError", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusBadRequest)
+		})
+
+		client := newTestCosmosDataClient(t, mockContext)
+		container := &CosmosContainer{Id: "orders", PartitionKey: CosmosPartitionKey{Paths: []string{"/id"}}}
+
+		err := client.UpsertDocument(*mockContext.Context, "app", container, CosmosDocument{"id": "a"})
+		require.ErrorContains(t, err, "400")
+	})
+}
+
+func TestPartitionKeyValue(t *testing.T) {
+	partitionKey := CosmosPartitionKey{Paths: []string{"/tenant", "/customer/id"}, Kind: "MultiHash"}
+
+	values := PartitionKeyValue(partitionKey, CosmosDocument{
+		"tenant":   "contoso",
+		"customer": map[string]any{"id": json.Number("42")},
+	})
+	require.Equal(t, []any{"contoso", json.Number("42")}, values)
+
+	// Documents without a value of a path are stored with an undefined value
+	values = PartitionKeyValue(partitionKey, CosmosDocument{"tenant": "contoso"})
+	require.Equal(t, []any{"contoso", map[string]any{}}, values)
+}
+
+func newTestCosmosDataClient(t *testing.T, mockContext *mocks.MockContext) *CosmosDataClient {
+	options := NewClientOptionsBuilder().
+		WithTransport(mockContext.HttpClient).
+		BuildCoreClientOptions()
+
+	client, err := NewCosmosDataClient("https://contoso.documents.azure.com:443/", &mocks.MockCredentials{}, options)
+	require.NoError(t, err)
+
+	return client
+}
+
+// Registers the change feed of a container with two partition key ranges. The first range has two pages of changes and
+// reports the end of its changes with a 304, the second range has one page of changes and reports the end of its
+// changes with an empty page.
+func registerChangeFeedMocks(mockContext *mocks.MockContext) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/dbs/app/colls/orders/pkranges"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"PartitionKeyRanges": []map[string]any{{"id": "0"}, {"id": "1"}},
+		})
+	})
+
+	type changeFeedPage struct {
+		documents []string
+		etag      string
+	}
+
+	// The pages of the change feed by partition key range and continuation
+	pages := map[string]*changeFeedPage{
+		"0/":       {documents: []string{"a", "b"}, etag: `"10"`},
+		"0/\"10\"": {documents: []string{"c"}, etag: `"20"`},
+		"1/":       {documents: []string{"d"}, etag: `"5"`},
+		"1/\"5\"":  {documents: []string{}, etag: `"5"`},
+	}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && request.URL.Path == "/dbs/app/colls/orders/docs"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if request.Header.Get("A-IM") != "Incremental feed" {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusBadRequest)
+		}
+
+		key := request.Header.Get("x-ms-documentdb-partitionkeyrangeid") + "/" + request.Header.Get("If-None-Match")
+		page, has := pages[key]
+		if !has {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotModified)
+		}
+
+		documents := []map[string]any{}
+		for _, id := range page.documents {
+			documents = append(documents, map[string]any{"id": id, "_rid": "rid-" + id})
+		}
+
+		response, err := mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"Documents": documents})
+		if err != nil {
+			return nil, err
+		}
+
+		response.Header.Set("etag", page.etag)
+		return response, nil
+	})
+}
@@ -0,0 +1,146 @@
+package azsdk
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	dataverseApiPath = "api/data/v9.2"
+
+	// The async operation state code when the operation has completed
+	AsyncOperationStateCompleted = 3
+	// The async operation status code when the operation has succeeded
+	AsyncOperationStatusSucceeded = 30
+)
+
+// ImportSolutionRequest is the request body of the Dataverse ImportSolutionAsync action
+type ImportSolutionRequest struct {
+	OverwriteUnmanagedCustomizations bool   `json:"OverwriteUnmanagedCustomizations"`
+	PublishWorkflows                 bool   `json:"PublishWorkflows"`
+	CustomizationFile                string `json:"CustomizationFile"`
+}
+
+// ImportSolutionResponse is the response of the Dataverse ImportSolutionAsync action
+type ImportSolutionResponse struct {
+	AsyncOperationId string `json:"AsyncOperationId"`
+	ImportJobKey     string `json:"ImportJobKey"`
+}
+
+// AsyncOperation is the state of a Dataverse system job
+type AsyncOperation struct {
+	StateCode       int    `json:"statecode"`
+	StatusCode      int    `json:"statuscode"`
+	Message         string `json:"message"`
+	FriendlyMessage string `json:"friendlymessage"`
+}
+
+// DataverseClient wraps usage of the Dataverse Web API used to import Power Platform solutions
+// More info can be found at https://learn.microsoft.com/power-apps/developer/data-platform/webapi/overview
+type DataverseClient struct {
+	environmentUrl string
+	pipeline       runtime.Pipeline
+}
+
+// Creates a new DataverseClient instance for the specified environment url, ex) https://{org}.crm.dynamics.com
+func NewDataverseClient(
+	environmentUrl string,
+	credential azcore.TokenCredential,
+	options *azcore.ClientOptions,
+) *DataverseClient {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	environmentUrl = strings.TrimSuffix(environmentUrl, "/")
+	authPolicy := runtime.NewBearerTokenPolicy(credential, []string{fmt.Sprintf("%s/.default", environmentUrl)}, nil)
+	pipelineOptions := runtime.PipelineOptions{
+		PerRetry: []policy.Policy{authPolicy},
+	}
+
+	return &DataverseClient{
+		environmentUrl: environmentUrl,
+		pipeline:       runtime.NewPipeline("dataverse", "1.0.0", pipelineOptions, options),
+	}
+}
+
+// Starts the asynchronous import of the solution zip into the environment
+func (c *DataverseClient) ImportSolutionAsync(
+	ctx context.Context,
+	solutionZip []byte,
+	overwriteUnmanagedCustomizations bool,
+	publishWorkflows bool,
+) (*ImportSolutionResponse, error) {
+	req, err := c.createRequest(ctx, http.MethodPost, "ImportSolutionAsync")
+	if err != nil {
+		return nil, err
+	}
+
+	body := ImportSolutionRequest{
+		OverwriteUnmanagedCustomizations: overwriteUnmanagedCustomizations,
+		PublishWorkflows:                 publishWorkflows,
+		CustomizationFile:                base64.StdEncoding.EncodeToString(solutionZip),
+	}
+
+	if err := graphsdk.SetHttpRequestBody(req, body); err != nil {
+		return nil, err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return httputil.ReadRawResponse[ImportSolutionResponse](res)
+}
+
+// Gets the current state of the specified async operation
+func (c *DataverseClient) GetAsyncOperation(ctx context.Context, asyncOperationId string) (*AsyncOperation, error) {
+	req, err := c.createRequest(ctx, http.MethodGet, fmt.Sprintf("asyncoperations(%s)", asyncOperationId))
+	if err != nil {
+		return nil, err
+	}
+
+	rawRequest := req.Raw()
+	query := rawRequest.URL.Query()
+	query.Set("$select", "statecode,statuscode,message,friendlymessage")
+	rawRequest.URL.RawQuery = query.Encode()
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	return httputil.ReadRawResponse[AsyncOperation](res)
+}
+
+func (c *DataverseClient) createRequest(ctx context.Context, method string, path string) (*policy.Request, error) {
+	req, err := runtime.NewRequest(ctx, method, fmt.Sprintf("%s/%s/%s", c.environmentUrl, dataverseApiPath, path))
+	if err != nil {
+		return nil, fmt.Errorf("creating dataverse request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Header.Set("Accept", "application/json")
+	rawRequest.Header.Set("OData-MaxVersion", "4.0")
+	rawRequest.Header.Set("OData-Version", "4.0")
+
+	return req, nil
+}
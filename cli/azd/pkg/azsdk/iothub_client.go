@@ -0,0 +1,133 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/graphsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	iotHubApiVersion = "2021-04-12"
+	iotHubScope      = "https://iothubs.azure.net/.default"
+)
+
+// IotHubConfigurationContent is the modules content of an IoT Edge deployment manifest
+type IotHubConfigurationContent struct {
+	ModulesContent map[string]map[string]any `json:"modulesContent"`
+}
+
+// IotHubConfiguration is an automatic (or layered) IoT Edge deployment targeting a set of devices
+type IotHubConfiguration struct {
+	Id              string                     `json:"id"`
+	SchemaVersion   string                     `json:"schemaVersion"`
+	Labels          map[string]string          `json:"labels,omitempty"`
+	Content         IotHubConfigurationContent `json:"content"`
+	TargetCondition string                     `json:"targetCondition"`
+	Priority        int                        `json:"priority"`
+}
+
+// IotHubClient wraps usage of the IoT Hub data plane REST API used to apply IoT Edge deployments
+// More info can be found at https://learn.microsoft.com/rest/api/iothub/service/configuration
+type IotHubClient struct {
+	hostName string
+	pipeline runtime.Pipeline
+}
+
+// Creates a new IotHubClient instance for the specified IoT Hub host name, ex) {hub}.azure-devices.net
+func NewIotHubClient(
+	hostName string,
+	credential azcore.TokenCredential,
+	options *azcore.ClientOptions,
+) *IotHubClient {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	authPolicy := runtime.NewBearerTokenPolicy(credential, []string{iotHubScope}, nil)
+	pipelineOptions := runtime.PipelineOptions{
+		PerRetry: []policy.Policy{authPolicy},
+	}
+
+	return &IotHubClient{
+		hostName: hostName,
+		pipeline: runtime.NewPipeline("iothub", "1.0.0", pipelineOptions, options),
+	}
+}
+
+// Applies the deployment manifest content directly to a single IoT Edge device
+func (c *IotHubClient) ApplyConfigurationContent(
+	ctx context.Context,
+	deviceId string,
+	content *IotHubConfigurationContent,
+) error {
+	req, err := c.createRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("devices/%s/applyConfigurationContent", url.PathEscape(deviceId)),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := graphsdk.SetHttpRequestBody(req, content); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK, http.StatusNoContent) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+// Creates the automatic deployment. Deployment content is immutable once created.
+func (c *IotHubClient) CreateConfiguration(ctx context.Context, configuration *IotHubConfiguration) error {
+	req, err := c.createRequest(ctx, http.MethodPut, fmt.Sprintf("configurations/%s", url.PathEscape(configuration.Id)))
+	if err != nil {
+		return err
+	}
+
+	if err := graphsdk.SetHttpRequestBody(req, configuration); err != nil {
+		return err
+	}
+
+	res, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(res, err)
+	}
+	defer res.Body.Close()
+
+	if !runtime.HasStatusCode(res, http.StatusOK, http.StatusCreated) {
+		return runtime.NewResponseError(res)
+	}
+
+	return nil
+}
+
+func (c *IotHubClient) createRequest(ctx context.Context, method string, path string) (*policy.Request, error) {
+	req, err := runtime.NewRequest(ctx, method, fmt.Sprintf("https://%s/%s", c.hostName, path))
+	if err != nil {
+		return nil, fmt.Errorf("creating iot hub request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	query := rawRequest.URL.Query()
+	query.Set("api-version", iotHubApiVersion)
+	rawRequest.URL.RawQuery = query.Encode()
+	rawRequest.Header.Set("Accept", "application/json")
+
+	return req, nil
+}
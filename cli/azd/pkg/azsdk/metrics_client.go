@@ -0,0 +1,127 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const metricsApiVersion = "2023-10-01"
+
+// MetricsQuery is a query of the values of a metric of a resource over a time range
+type MetricsQuery struct {
+	// The name of the metric, ex) Requests
+	Name string
+	// The aggregation of the values of each interval, ex) Total, Average or Maximum
+	Aggregation string
+	// The optional filter of the dimensions of the metric, ex) statusCodeCategory eq '5xx'
+	Filter string
+	// The time range of the values, ending now
+	Timespan time.Duration
+	// The interval of the values, ex) 1m
+	Interval time.Duration
+}
+
+// MetricValue is the aggregated value of a metric over an interval, nil when the metric has no value for the interval
+type MetricValue struct {
+	TimeStamp time.Time `json:"timeStamp"`
+	Total     *float64  `json:"total"`
+	Average   *float64  `json:"average"`
+	Maximum   *float64  `json:"maximum"`
+	Minimum   *float64  `json:"minimum"`
+	Count     *float64  `json:"count"`
+}
+
+// Metric is the values of a metric of a resource, ex) the requests of a container app
+type Metric struct {
+	Name struct {
+		Value string `json:"value"`
+	} `json:"name"`
+	// The unit of the values, ex) Count, Bytes or Percent
+	Unit       string `json:"unit"`
+	Timeseries []struct {
+		Data []MetricValue `json:"data"`
+	} `json:"timeseries"`
+}
+
+// MetricsClient wraps usage of the Azure Monitor metrics REST API used to read the recent metrics of resources
+// More info can be found at https://learn.microsoft.com/rest/api/monitor/metrics
+type MetricsClient struct {
+	pipeline runtime.Pipeline
+}
+
+// Creates a new MetricsClient instance
+func NewMetricsClient(credential azcore.TokenCredential, options *arm.ClientOptions) (*MetricsClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("metrics", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	return &MetricsClient{
+		pipeline: pipeline,
+	}, nil
+}
+
+// Gets the values of the metric of the resource over the time range of the query. The values of all the dimensions
+// of the metric are aggregated.
+func (c *MetricsClient) GetMetric(ctx context.Context, resourceId string, query MetricsQuery) (*Metric, error) {
+	end := time.Now().UTC()
+	start := end.Add(-query.Timespan)
+
+	values := url.Values{
+		"api-version": []string{metricsApiVersion},
+		"metricnames": []string{query.Name},
+		"aggregation": []string{query.Aggregation},
+		"timespan":    []string{fmt.Sprintf("%s/%s", start.Format(time.RFC3339), end.Format(time.RFC3339))},
+		"interval":    []string{fmt.Sprintf("PT%dM", int(query.Interval.Minutes()))},
+	}
+
+	if query.Filter != "" {
+		values.Set("$filter", query.Filter)
+	}
+
+	endpointUrl := fmt.Sprintf(
+		"%s/%s/providers/Microsoft.Insights/metrics", resourceManagerOrigin, strings.TrimPrefix(resourceId, "/"))
+	req, err := runtime.NewRequest(ctx, http.MethodGet, endpointUrl)
+	if err != nil {
+		return nil, fmt.Errorf("creating metrics request: %w", err)
+	}
+
+	req.Raw().URL.RawQuery = values.Encode()
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	var result struct {
+		Value []*Metric `json:"value"`
+	}
+
+	if err := runtime.UnmarshalAsJSON(response, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Value) == 0 {
+		return nil, fmt.Errorf("metric '%s' not found", query.Name)
+	}
+
+	return result.Value[0], nil
+}
@@ -0,0 +1,337 @@
+package azsdk
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+const (
+	OciImageManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	OciImageIndexMediaType    = "application/vnd.oci.image.index.v1+json"
+	// The media type of the empty config blob used by OCI artifacts
+	OciEmptyConfigMediaType = "application/vnd.oci.empty.v1+json"
+
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// OciDescriptor describes the content referenced by an OCI manifest
+type OciDescriptor struct {
+	MediaType    string `json:"mediaType"`
+	Digest       string `json:"digest"`
+	Size         int64  `json:"size"`
+	ArtifactType string `json:"artifactType,omitempty"`
+}
+
+// OciManifest is an OCI image manifest, used to store artifacts that refer to an image through the subject
+// More info can be found at https://github.com/opencontainers/image-spec/blob/main/manifest.md
+type OciManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        OciDescriptor     `json:"config"`
+	Layers        []OciDescriptor   `json:"layers"`
+	Subject       *OciDescriptor    `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// RegistryClient wraps usage of the OCI distribution API of a container registry
+// More info can be found at https://github.com/opencontainers/distribution-spec/blob/main/spec.md
+type RegistryClient struct {
+	loginServer string
+	pipeline    runtime.Pipeline
+}
+
+// Creates a new RegistryClient instance for the specified login server, ex) {registry}.azurecr.io
+// Requests are authenticated with the specified registry credentials.
+func NewRegistryClient(
+	loginServer string,
+	username string,
+	password string,
+	options *azcore.ClientOptions,
+) *RegistryClient {
+	if options == nil {
+		options = &azcore.ClientOptions{}
+	}
+
+	pipelineOptions := runtime.PipelineOptions{
+		PerRetry: []policy.Policy{&basicAuthPolicy{username: username, password: password}},
+	}
+
+	return &RegistryClient{
+		loginServer: loginServer,
+		pipeline:    runtime.NewPipeline("registry", "1.0.0", pipelineOptions, options),
+	}
+}
+
+// Gets the descriptor of the manifest for the specified tag or digest
+func (c *RegistryClient) GetManifestDescriptor(
+	ctx context.Context,
+	repository string,
+	reference string,
+) (*OciDescriptor, error) {
+	request, err := runtime.NewRequest(ctx, http.MethodHead, c.url("v2", repository, "manifests", reference))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	request.Raw().Header.Set("Accept", fmt.Sprintf(
+		"%s, %s, %s, %s",
+		OciImageManifestMediaType,
+		OciImageIndexMediaType,
+		dockerManifestMediaType,
+		dockerManifestListMediaType,
+	))
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	// The size of the manifest is the length of the content that would be returned by a GET request
+	size := response.ContentLength
+	if contentLength := response.Header.Get("Content-Length"); contentLength != "" {
+		parsed, err := strconv.ParseInt(contentLength, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing manifest size: %w", err)
+		}
+		size = parsed
+	}
+
+	return &OciDescriptor{
+		MediaType: response.Header.Get("Content-Type"),
+		Digest:    response.Header.Get("Docker-Content-Digest"),
+		Size:      size,
+	}, nil
+}
+
+// Uploads the blob to the repository using a monolithic upload
+func (c *RegistryClient) UploadBlob(
+	ctx context.Context,
+	repository string,
+	mediaType string,
+	content []byte,
+) (*OciDescriptor, error) {
+	descriptor := &OciDescriptor{
+		MediaType: mediaType,
+		Digest:    Sha256Digest(content),
+		Size:      int64(len(content)),
+	}
+
+	request, err := runtime.NewRequest(ctx, http.MethodPost, c.url("v2", repository, "blobs", "uploads")+"/")
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusAccepted) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	// The upload location may be relative to the registry
+	location, err := request.Raw().URL.Parse(response.Header.Get("Location"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing upload location: %w", err)
+	}
+
+	query := location.Query()
+	query.Set("digest", descriptor.Digest)
+	location.RawQuery = query.Encode()
+
+	request, err = runtime.NewRequest(ctx, http.MethodPut, location.String())
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	err = request.SetBody(streaming.NopCloser(bytes.NewReader(content)), "application/octet-stream")
+	if err != nil {
+		return nil, fmt.Errorf("setting request body: %w", err)
+	}
+
+	response, err = c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusCreated) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return descriptor, nil
+}
+
+// Pushes the manifest to the repository, referenced by its digest
+func (c *RegistryClient) PutManifest(
+	ctx context.Context,
+	repository string,
+	manifest *OciManifest,
+) (*OciDescriptor, error) {
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling manifest: %w", err)
+	}
+
+	descriptor := &OciDescriptor{
+		MediaType:    manifest.MediaType,
+		Digest:       Sha256Digest(manifestBytes),
+		Size:         int64(len(manifestBytes)),
+		ArtifactType: manifest.ArtifactType,
+	}
+
+	request, err := runtime.NewRequest(ctx, http.MethodPut, c.url("v2", repository, "manifests", descriptor.Digest))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	err = request.SetBody(streaming.NopCloser(bytes.NewReader(manifestBytes)), manifest.MediaType)
+	if err != nil {
+		return nil, fmt.Errorf("setting request body: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusCreated) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return descriptor, nil
+}
+
+// Lists the tags of the repository, following the pages of the tags linked by the Link header
+func (c *RegistryClient) ListTags(ctx context.Context, repository string) ([]string, error) {
+	tags := []string{}
+	nextUrl := c.url("v2", repository, "tags", "list")
+
+	for nextUrl != "" {
+		request, err := runtime.NewRequest(ctx, http.MethodGet, nextUrl)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		response, err := c.pipeline.Do(request)
+		if err != nil {
+			return nil, httputil.HandleRequestError(response, err)
+		}
+
+		// Repositories without images don't exist
+		if runtime.HasStatusCode(response, http.StatusNotFound) {
+			response.Body.Close()
+			return tags, nil
+		}
+
+		if !runtime.HasStatusCode(response, http.StatusOK) {
+			response.Body.Close()
+			return nil, runtime.NewResponseError(response)
+		}
+
+		page, err := httputil.ReadRawResponse[struct {
+			Tags []string `json:"tags"`
+		}](response)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, page.Tags...)
+
+		nextUrl, err = nextPageUrl(request.Raw().URL, response.Header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tags, nil
+}
+
+// Deletes the manifest with the digest from the repository, along with all the tags referencing the manifest
+func (c *RegistryClient) DeleteManifest(ctx context.Context, repository string, digest string) error {
+	request, err := runtime.NewRequest(ctx, http.MethodDelete, c.url("v2", repository, "manifests", digest))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(request)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusAccepted, http.StatusOK, http.StatusNotFound) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+// Gets the URL of the next page from the Link header of a page, ex) </v2/api/tags/list?last=v2&n=100>; rel="next", or an
+// empty string on the last page. Relative links are relative to the URL of the page.
+func nextPageUrl(pageUrl *url.URL, link string) (string, error) {
+	target, params, has := strings.Cut(link, ";")
+	if !has || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+		return "", nil
+	}
+
+	next, err := pageUrl.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+	if err != nil {
+		return "", fmt.Errorf("parsing next page link: %w", err)
+	}
+
+	return next.String(), nil
+}
+
+func (c *RegistryClient) url(segments ...string) string {
+	path, _ := url.JoinPath(fmt.Sprintf("https://%s", c.loginServer), segments...)
+	return path
+}
+
+// Sha256Digest computes the OCI digest of the content, ex) sha256:{hex}
+func Sha256Digest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// basicAuthPolicy authenticates requests with basic authentication
+type basicAuthPolicy struct {
+	username string
+	password string
+}
+
+func (p *basicAuthPolicy) Do(request *policy.Request) (*http.Response, error) {
+	credentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", p.username, p.password)))
+	request.Raw().Header.Set("Authorization", fmt.Sprintf("Basic %s", credentials))
+
+	return request.Next()
+}
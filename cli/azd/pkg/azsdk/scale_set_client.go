@@ -0,0 +1,174 @@
+package azsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// The version of the network API of scale sets, served by the Microsoft.Compute provider
+const scaleSetNetworkApiVersion = "2018-10-01"
+
+// ScaleSetClient wraps usage of the Azure Compute management REST API used to get the network addresses of the
+// instances of a virtual machine scale set.
+// More info can be found at https://learn.microsoft.com/rest/api/virtualnetwork/network-interface-in-vm-ss
+type ScaleSetClient struct {
+	subscriptionId string
+	pipeline       runtime.Pipeline
+}
+
+// ScaleSetInstanceAddress are the network addresses of the primary network interface of an instance of a scale set
+type ScaleSetInstanceAddress struct {
+	// The resource ID of the instance
+	InstanceId       string
+	PrivateIpAddress string
+	// The public IP address of the instance, empty when the instance doesn't have one
+	PublicIpAddress string
+}
+
+type scaleSetNetworkInterface struct {
+	Properties struct {
+		Primary        bool `json:"primary"`
+		VirtualMachine struct {
+			Id string `json:"id"`
+		} `json:"virtualMachine"`
+		IpConfigurations []struct {
+			Id         string `json:"id"`
+			Properties struct {
+				Primary          bool   `json:"primary"`
+				PrivateIpAddress string `json:"privateIPAddress"`
+			} `json:"properties"`
+		} `json:"ipConfigurations"`
+	} `json:"properties"`
+}
+
+type scaleSetPublicIpAddress struct {
+	Properties struct {
+		IpAddress       string `json:"ipAddress"`
+		IpConfiguration struct {
+			Id string `json:"id"`
+		} `json:"ipConfiguration"`
+	} `json:"properties"`
+}
+
+// Creates a new ScaleSetClient instance
+func NewScaleSetClient(
+	subscriptionId string,
+	credential azcore.TokenCredential,
+	options *arm.ClientOptions,
+) (*ScaleSetClient, error) {
+	if options == nil {
+		options = &arm.ClientOptions{}
+	}
+
+	pipeline, err := armruntime.NewPipeline("scale-set", "1.0.0", credential, runtime.PipelineOptions{}, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating HTTP pipeline: %w", err)
+	}
+
+	return &ScaleSetClient{
+		subscriptionId: subscriptionId,
+		pipeline:       pipeline,
+	}, nil
+}
+
+// Lists the network addresses of the instances of the scale set, in the order of the network interfaces of the instances
+func (c *ScaleSetClient) ListInstanceAddresses(
+	ctx context.Context,
+	resourceGroupName string,
+	scaleSetName string,
+) ([]ScaleSetInstanceAddress, error) {
+	scaleSetUrl := fmt.Sprintf(
+		"%s/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/virtualMachineScaleSets/%s",
+		resourceManagerOrigin,
+		url.PathEscape(c.subscriptionId),
+		url.PathEscape(resourceGroupName),
+		url.PathEscape(scaleSetName),
+	)
+
+	networkInterfaces, err := listScaleSetPages[scaleSetNetworkInterface](ctx, c.pipeline, scaleSetUrl+"/networkInterfaces")
+	if err != nil {
+		return nil, fmt.Errorf("listing network interfaces: %w", err)
+	}
+
+	publicIpAddresses, err := listScaleSetPages[scaleSetPublicIpAddress](ctx, c.pipeline, scaleSetUrl+"/publicIPAddresses")
+	if err != nil {
+		return nil, fmt.Errorf("listing public IP addresses: %w", err)
+	}
+
+	// Public IP addresses reference the IP configuration of the network interface they're assigned to
+	publicIps := map[string]string{}
+	for _, publicIpAddress := range publicIpAddresses {
+		configId := strings.ToLower(publicIpAddress.Properties.IpConfiguration.Id)
+		if configId != "" && publicIpAddress.Properties.IpAddress != "" {
+			publicIps[configId] = publicIpAddress.Properties.IpAddress
+		}
+	}
+
+	addresses := []ScaleSetInstanceAddress{}
+	for _, networkInterface := range networkInterfaces {
+		// Instances with multiple network interfaces are reached through their primary network interface
+		if !networkInterface.Properties.Primary || len(networkInterface.Properties.IpConfigurations) == 0 {
+			continue
+		}
+
+		ipConfiguration := networkInterface.Properties.IpConfigurations[0]
+		for _, candidate := range networkInterface.Properties.IpConfigurations {
+			if candidate.Properties.Primary {
+				ipConfiguration = candidate
+				break
+			}
+		}
+
+		addresses = append(addresses, ScaleSetInstanceAddress{
+			InstanceId:       networkInterface.Properties.VirtualMachine.Id,
+			PrivateIpAddress: ipConfiguration.Properties.PrivateIpAddress,
+			PublicIpAddress:  publicIps[strings.ToLower(ipConfiguration.Id)],
+		})
+	}
+
+	return addresses, nil
+}
+
+// Gets all the resources of a list operation of a scale set, following the next links of the pages
+func listScaleSetPages[T any](ctx context.Context, pipeline runtime.Pipeline, listUrl string) ([]T, error) {
+	values := []T{}
+	nextUrl := listUrl + "?" + url.Values{"api-version": []string{scaleSetNetworkApiVersion}}.Encode()
+
+	for nextUrl != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, nextUrl)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		response, err := pipeline.Do(req)
+		if err != nil {
+			return nil, httputil.HandleRequestError(response, err)
+		}
+
+		if !runtime.HasStatusCode(response, http.StatusOK) {
+			return nil, runtime.NewResponseError(response)
+		}
+
+		var page struct {
+			Value    []T    `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := runtime.UnmarshalAsJSON(response, &page); err != nil {
+			return nil, err
+		}
+
+		values = append(values, page.Value...)
+		nextUrl = page.NextLink
+	}
+
+	return values, nil
+}
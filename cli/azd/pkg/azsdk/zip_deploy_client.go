@@ -1,6 +1,7 @@
 package azsdk
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
 )
 
@@ -131,14 +133,140 @@ func (c *ZipDeployClient) Deploy(ctx context.Context, appName string, zipFile io
 	return response, nil
 }
 
+// Gets the latest deployment of the app. Returns nil when the app has not been deployed yet.
+func (c *ZipDeployClient) GetLatestDeployment(ctx context.Context, appName string) (*DeployStatus, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, c.scmUrl(appName, "api/deployments/latest"))
+	if err != nil {
+		return nil, fmt.Errorf("creating deployments request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+	defer response.Body.Close()
+
+	if runtime.HasStatusCode(response, http.StatusNotFound) {
+		return nil, nil
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return httputil.ReadRawResponse[DeployStatus](response)
+}
+
+// Gets the contents of a file relative to the app home directory using the Kudu VFS API.
+// Returns nil when the file does not exist.
+func (c *ZipDeployClient) GetFile(ctx context.Context, appName string, path string) ([]byte, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, c.scmUrl(appName, "api/vfs/"+path))
+	if err != nil {
+		return nil, fmt.Errorf("creating file request: %w", err)
+	}
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(response, err)
+	}
+
+	defer response.Body.Close()
+
+	if runtime.HasStatusCode(response, http.StatusNotFound) {
+		return nil, nil
+	}
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return nil, runtime.NewResponseError(response)
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+// Creates or overwrites a file relative to the app home directory using the Kudu VFS API
+func (c *ZipDeployClient) PutFile(ctx context.Context, appName string, path string, contents []byte) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPut, c.scmUrl(appName, "api/vfs/"+path))
+	if err != nil {
+		return fmt.Errorf("creating file request: %w", err)
+	}
+
+	rawRequest := req.Raw()
+	rawRequest.Header.Set("If-Match", "*")
+	if err := req.SetBody(streaming.NopCloser(bytes.NewReader(contents)), "application/octet-stream"); err != nil {
+		return err
+	}
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusCreated, http.StatusNoContent) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+// Deletes a file relative to the app home directory using the Kudu VFS API. Missing files are ignored.
+func (c *ZipDeployClient) DeleteFile(ctx context.Context, appName string, path string) error {
+	req, err := runtime.NewRequest(ctx, http.MethodDelete, c.scmUrl(appName, "api/vfs/"+path))
+	if err != nil {
+		return fmt.Errorf("creating file request: %w", err)
+	}
+
+	req.Raw().Header.Set("If-Match", "*")
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK, http.StatusNoContent, http.StatusNotFound) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+// Extracts the zip archive into a directory relative to the app home directory using the Kudu zip API.
+// Unlike zip deploy, existing files that are not part of the archive are kept and no build is run.
+func (c *ZipDeployClient) ExtractZip(ctx context.Context, appName string, path string, zipFile io.ReadSeekCloser) error {
+	req, err := runtime.NewRequest(ctx, http.MethodPut, c.scmUrl(appName, "api/zip/"+strings.TrimSuffix(path, "/")+"/"))
+	if err != nil {
+		return fmt.Errorf("creating zip request: %w", err)
+	}
+
+	if err := req.SetBody(zipFile, "application/zip"); err != nil {
+		return err
+	}
+
+	response, err := c.pipeline.Do(req)
+	if err != nil {
+		return httputil.HandleRequestError(response, err)
+	}
+	defer response.Body.Close()
+
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return runtime.NewResponseError(response)
+	}
+
+	return nil
+}
+
+func (c *ZipDeployClient) scmUrl(appName string, path string) string {
+	return fmt.Sprintf("https://%s.scm.azurewebsites.net/%s", appName, path)
+}
+
 // Creates the HTTP request for the zip deployment operation
 func (c *ZipDeployClient) createDeployRequest(
 	ctx context.Context,
 	appName string,
 	zipFile io.Reader,
 ) (*policy.Request, error) {
-	endpoint := fmt.Sprintf("https://%s.scm.azurewebsites.net/api/zipdeploy", appName)
-	req, err := runtime.NewRequest(ctx, http.MethodPost, endpoint)
+	req, err := runtime.NewRequest(ctx, http.MethodPost, c.scmUrl(appName, "api/zipdeploy"))
 	if err != nil {
 		return nil, fmt.Errorf("creating deploy request: %w", err)
 	}
@@ -91,6 +91,16 @@ func StaticWebAppRID(subscriptionId, resourceGroupName, staticSiteName string) s
 	return returnValue
 }
 
+// Creates the resource ID for a top level resource of the specified type, ex) Microsoft.Compute/virtualMachines
+func ResourceRID(subscriptionId, resourceGroupName, resourceType, resourceName string) string {
+	return fmt.Sprintf(
+		"%s/providers/%s/%s",
+		ResourceGroupRID(subscriptionId, resourceGroupName),
+		resourceType,
+		resourceName,
+	)
+}
+
 var resourceIdRegex = regexp.MustCompile("/.+/(?i)resourceGroups/(.+?)/.+")
 
 // Find the resource group name from the resource id
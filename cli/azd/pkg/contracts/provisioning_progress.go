@@ -0,0 +1,36 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package contracts
+
+import "time"
+
+// ProvisioningProgress is the heartbeat of the provisioning of the infrastructure, emitted periodically in JSON output
+// mode while the deployment runs, so orchestrators can follow the deployment without polling Azure Resource Manager.
+type ProvisioningProgress struct {
+	// The name of the Azure deployment
+	DeploymentName string `json:"deploymentName"`
+	// The time elapsed since the deployment started, in seconds
+	ElapsedSeconds int64 `json:"elapsedSeconds"`
+	// The estimated time remaining until the deployment completes, in seconds, based on the duration of the previous
+	// provisioning of the environment. Not set when the environment wasn't provisioned before.
+	EstimatedRemainingSeconds *int64 `json:"estimatedRemainingSeconds,omitempty"`
+	// The number of operations of the deployment by provisioning state, ex) Succeeded: 4, Running: 2
+	OperationCounts map[string]int `json:"operationCounts"`
+	// The operations of the deployment on resources
+	Operations []ProvisioningOperation `json:"operations"`
+}
+
+// ProvisioningOperation is the operation of a deployment on a resource
+type ProvisioningOperation struct {
+	// The type of the resource, ex) Microsoft.Web/sites
+	ResourceType string `json:"resourceType"`
+	// The name of the resource
+	ResourceName string `json:"resourceName"`
+	// The provisioning state of the operation, ex) Running, Succeeded or Failed
+	ProvisioningState string `json:"provisioningState"`
+	// The duration of the operation, as an ISO 8601 duration, ex) PT1M30S
+	Duration string `json:"duration,omitempty"`
+	// The time the state of the operation last changed
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
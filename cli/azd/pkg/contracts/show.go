@@ -2,20 +2,31 @@
 // Licensed under the MIT License.
 package contracts
 
+import "time"
+
 // ShowType are the values for the language property of a ShowServiceProject
 type ShowType string
 
 const (
-	ShowTypeDotNet ShowType = "dotnet"
-	ShowTypePython ShowType = "python"
-	ShowTypeNode   ShowType = "node"
-	ShowTypeJava   ShowType = "java"
+	ShowTypeDotNet  ShowType = "dotnet"
+	ShowTypePython  ShowType = "python"
+	ShowTypeNode    ShowType = "node"
+	ShowTypeJava    ShowType = "java"
+	ShowTypeGo      ShowType = "go"
+	ShowTypeRust    ShowType = "rust"
+	ShowTypePhp     ShowType = "php"
+	ShowTypeRuby    ShowType = "ruby"
+	ShowTypeCompose ShowType = "compose"
+	ShowTypeCustom  ShowType = "custom"
 )
 
 // ShowResult is the contract for the output of `azd show`
 type ShowResult struct {
 	Name     string                 `json:"name"`
 	Services map[string]ShowService `json:"services"`
+	// Infra contains the changes of the infrastructure since the environment was last provisioned.
+	// Only set when `--changes` is specified.
+	Infra *ShowInfraChanges `json:"infra,omitempty"`
 }
 
 // ShowService is the contract for a service returned by `azd show`
@@ -25,6 +36,12 @@ type ShowService struct {
 	// Target contains information about the resource that the service is deployed
 	// to.
 	Target *ShowTargetArm `json:"target,omitempty"`
+	// Changes contains the changes of the service since it was last deployed.
+	// Only set when `--changes` is specified.
+	Changes *ShowServiceChanges `json:"changes,omitempty"`
+	// Metrics contains the recent health metrics of the resources of the service from Azure Monitor.
+	// Only set when `--metrics` or `--watch` is specified.
+	Metrics []ShowMetric `json:"metrics,omitempty"`
 }
 
 // ShowServiceProject is the contract for a service's project as returned by `azd show`
@@ -41,3 +58,56 @@ type ShowServiceProject struct {
 type ShowTargetArm struct {
 	ResourceIds []string `json:"resourceIds"`
 }
+
+// ShowMetric is the contract for a health metric of a resource of a service, as returned by `azd show --metrics`.
+// Requests and errors are totals over the window of the metrics, other metrics are the most recent values.
+type ShowMetric struct {
+	// Kind is one of 'requests', 'errors', 'cpu', 'memory' or 'replicas'.
+	Kind       string `json:"kind"`
+	ResourceId string `json:"resourceId"`
+	// Name is the name of the metric of the resource in Azure Monitor.
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// ShowServiceChanges is the contract for the changes of a service since it was last deployed, as returned by
+// `azd show --changes`
+type ShowServiceChanges struct {
+	// LastDeployed is the time the service was last deployed, nil when it was never deployed to the environment.
+	LastDeployed *time.Time `json:"lastDeployed,omitempty"`
+	// Commit is the git commit the service was last deployed from.
+	Commit string `json:"commit,omitempty"`
+	// CurrentCommit is the git commit currently checked out.
+	CurrentCommit string `json:"currentCommit,omitempty"`
+	// SourceTracked is false when the source changes can't be determined, i.e. the service isn't in a git repository.
+	SourceTracked bool `json:"sourceTracked"`
+	// Files contains the source files of the service that changed since the last deployment.
+	Files []ShowFileChange `json:"files,omitempty"`
+	// Config contains the keys of the service configuration in azure.yaml that changed since the last deployment.
+	Config []ShowConfigChange `json:"config,omitempty"`
+}
+
+// ShowInfraChanges is the contract for the changes of the infrastructure since the environment was last
+// provisioned, as returned by `azd show --changes`
+type ShowInfraChanges struct {
+	// LastProvisioned is the time the environment was last provisioned, nil when it was never provisioned.
+	LastProvisioned *time.Time `json:"lastProvisioned,omitempty"`
+	// Files contains the infrastructure files that changed since the last provisioning.
+	Files []ShowFileChange `json:"files,omitempty"`
+}
+
+// ShowFileChange is the contract for a file that changed, as returned by `azd show --changes`
+type ShowFileChange struct {
+	// Status is one of 'added', 'modified' or 'deleted'.
+	Status string `json:"status"`
+	Path   string `json:"path"`
+}
+
+// ShowConfigChange is the contract for a configuration key that changed, as returned by `azd show --changes`.
+// An empty value means the key isn't set.
+type ShowConfigChange struct {
+	Key      string `json:"key"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
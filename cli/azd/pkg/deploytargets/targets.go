@@ -0,0 +1,233 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package deploytargets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"gopkg.in/yaml.v3"
+)
+
+// The number of targets deployed at the same time, when the targets file doesn't configure a parallelism
+const DefaultParallelism = 1
+
+// File is a list of targets a project is deployed to, each in its own environment, ex) the instances of the customers of
+// an ISV in their own subscriptions and tenants
+type File struct {
+	// The maximum number of targets deployed at the same time
+	Parallelism int `yaml:"parallelism"`
+	// The values set in the environments of all the targets, overridden by the values of each target
+	Env map[string]string `yaml:"env"`
+	// The targets of the deployment, deployed in order
+	Targets []Target `yaml:"targets"`
+}
+
+// Target is an instance of the project deployed to its own environment
+type Target struct {
+	// The name of the environment of the target, created when it doesn't exist
+	Name string `yaml:"name"`
+	// The subscription of the resources of the target
+	SubscriptionId string `yaml:"subscription"`
+	// The optional tenant of the subscription, checked before the target is deployed
+	TenantId string `yaml:"tenant"`
+	// The optional location of the resources of the target
+	Location string `yaml:"location"`
+	// The values set in the environment of the target before it's deployed, ex) the parameters of the infrastructure
+	// or the settings of the services read from the environment
+	Env map[string]string `yaml:"env"`
+}
+
+// Status is the status of the deployment of a target
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	// The target wasn't deployed, since the deployment was canceled
+	StatusSkipped Status = "skipped"
+)
+
+// Result is the result of the deployment of a target
+type Result struct {
+	Target         string        `json:"target"`
+	SubscriptionId string        `json:"subscriptionId"`
+	TenantId       string        `json:"tenantId,omitempty"`
+	Status         Status        `json:"status"`
+	Duration       time.Duration `json:"duration"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// Report is the aggregated result of the deployment of all the targets
+type Report struct {
+	Timestamp time.Time `json:"timestamp"`
+	Targets   []*Result `json:"targets"`
+}
+
+// Failed gets the names of the targets which failed to deploy
+func (r *Report) Failed() []string {
+	failed := []string{}
+	for _, result := range r.Targets {
+		if result.Status == StatusFailed {
+			failed = append(failed, result.Target)
+		}
+	}
+
+	return failed
+}
+
+// Load reads and validates the targets file
+func Load(path string) (*File, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading targets file: %w", err)
+	}
+
+	var file File
+	if err := yaml.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("parsing targets file '%s': %w", path, err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("invalid targets file '%s': %w", path, err)
+	}
+
+	if file.Parallelism == 0 {
+		file.Parallelism = DefaultParallelism
+	}
+
+	return &file, nil
+}
+
+func (f *File) validate() error {
+	if len(f.Targets) == 0 {
+		return errors.New("no targets")
+	}
+
+	if f.Parallelism < 0 {
+		return fmt.Errorf("invalid parallelism %d, expected a positive number", f.Parallelism)
+	}
+
+	names := map[string]bool{}
+	for i, target := range f.Targets {
+		if target.Name == "" {
+			return fmt.Errorf("target %d: missing name", i)
+		}
+
+		if !environment.IsValidEnvironmentName(target.Name) {
+			return fmt.Errorf(
+				"target %d: invalid name '%s', the name can only contain alphanumeric characters, "+
+					"'-', '(', ')', '_' and '.'",
+				i,
+				target.Name,
+			)
+		}
+
+		if names[target.Name] {
+			return fmt.Errorf("target %d: duplicate name '%s'", i, target.Name)
+		}
+
+		names[target.Name] = true
+
+		if target.SubscriptionId == "" {
+			return fmt.Errorf("target '%s': missing subscription", target.Name)
+		}
+	}
+
+	return nil
+}
+
+// Apply sets the subscription, the location and the values of the target in its environment. The values of the
+// target override the values shared by all the targets.
+func (f *File) Apply(target Target, env *environment.Environment) {
+	env.SetEnvName(target.Name)
+	env.SetSubscriptionId(target.SubscriptionId)
+	if target.TenantId != "" {
+		env.Values[environment.TenantIdEnvVarName] = target.TenantId
+	}
+
+	if target.Location != "" {
+		env.SetLocation(target.Location)
+	}
+
+	for key, value := range f.Env {
+		env.Values[key] = value
+	}
+
+	for key, value := range target.Env {
+		env.Values[key] = value
+	}
+}
+
+// DeployFn deploys a target
+type DeployFn func(ctx context.Context, target Target) error
+
+// Deploy deploys the targets with at most the parallelism of the file deployed at the same time, and reports the result
+// of each target to the progress function as soon as it's deployed. The remaining targets are skipped when the context
+// is canceled. The results of the report are in the order of the targets.
+func (f *File) Deploy(ctx context.Context, deploy DeployFn, progress func(result *Result)) *Report {
+	report := &Report{
+		Timestamp: time.Now(),
+		Targets:   make([]*Result, len(f.Targets)),
+	}
+
+	parallelism := f.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	var progressMutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, parallelism)
+
+	for i, target := range f.Targets {
+		target := target
+		result := &Result{
+			Target:         target.Name,
+			SubscriptionId: target.SubscriptionId,
+			TenantId:       target.TenantId,
+			Status:         StatusSkipped,
+		}
+		report.Targets[i] = result
+
+		if ctx.Err() != nil {
+			continue
+		}
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			start := time.Now()
+			err := deploy(ctx, target)
+			result.Duration = time.Since(start).Round(time.Second)
+
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			} else {
+				result.Status = StatusSucceeded
+			}
+
+			progressMutex.Lock()
+			defer progressMutex.Unlock()
+			progress(result)
+		}()
+	}
+
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package deploytargets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+const testTargets = `
+parallelism: 2
+env:
+  SKU: standard
+targets:
+  - name: contoso
+    subscription: 00000000-0000-0000-0000-000000000001
+    tenant: 10000000-0000-0000-0000-000000000000
+    location: westus2
+    env:
+      SKU: premium
+  - name: fabrikam
+    subscription: 00000000-0000-0000-0000-000000000002
+  - name: tailspin
+    subscription: 00000000-0000-0000-0000-000000000003
+`
+
+func writeTargets(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "targets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	return path
+}
+
+func Test_Load(t *testing.T) {
+	file, err := Load(writeTargets(t, testTargets))
+	require.NoError(t, err)
+	require.Equal(t, 2, file.Parallelism)
+	require.Len(t, file.Targets, 3)
+	require.Equal(t, "10000000-0000-0000-0000-000000000000", file.Targets[0].TenantId)
+
+	file, err = Load(writeTargets(t, "targets:\n  - name: contoso\n    subscription: sub\n"))
+	require.NoError(t, err)
+	require.Equal(t, DefaultParallelism, file.Parallelism)
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := map[string]string{
+			"no targets":           "parallelism: 2\n",
+			"missing name":         "targets:\n  - subscription: sub\n",
+			"invalid name":         "targets:\n  - name: contoso west\n    subscription: sub\n",
+			"missing sub":          "targets:\n  - name: contoso\n",
+			"negative parallelism": "parallelism: -1\ntargets:\n  - name: contoso\n    subscription: sub\n",
+			"duplicate name": "targets:\n  - name: contoso\n    subscription: sub\n" +
+				"  - name: contoso\n    subscription: sub2\n",
+		}
+
+		for name, contents := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := Load(writeTargets(t, contents))
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func Test_Apply(t *testing.T) {
+	file, err := Load(writeTargets(t, testTargets))
+	require.NoError(t, err)
+
+	env := environment.EphemeralWithValues("contoso", map[string]string{"SKU": "basic", "OTHER": "value"})
+	file.Apply(file.Targets[0], env)
+	require.Equal(t, "00000000-0000-0000-0000-000000000001", env.GetSubscriptionId())
+	require.Equal(t, "10000000-0000-0000-0000-000000000000", env.GetTenantId())
+	require.Equal(t, "westus2", env.GetLocation())
+	require.Equal(t, "premium", env.Values["SKU"])
+	require.Equal(t, "value", env.Values["OTHER"])
+
+	env = environment.EphemeralWithValues("fabrikam", map[string]string{"SKU": "basic"})
+	file.Apply(file.Targets[1], env)
+	require.Equal(t, "standard", env.Values["SKU"])
+	require.Equal(t, "", env.GetLocation())
+}
+
+func Test_Deploy(t *testing.T) {
+	file, err := Load(writeTargets(t, testTargets))
+	require.NoError(t, err)
+
+	var running, maxRunning int32
+	var mutex sync.Mutex
+	progress := []string{}
+
+	report := file.Deploy(context.Background(), func(ctx context.Context, target Target) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		mutex.Lock()
+		if current > maxRunning {
+			maxRunning = current
+		}
+		mutex.Unlock()
+
+		if target.Name == "fabrikam" {
+			return errors.New("deployment failed")
+		}
+
+		return nil
+	}, func(result *Result) {
+		progress = append(progress, result.Target)
+	})
+
+	require.LessOrEqual(t, maxRunning, int32(2))
+	require.ElementsMatch(t, []string{"contoso", "fabrikam", "tailspin"}, progress)
+	require.Len(t, report.Targets, 3)
+	require.Equal(t, StatusSucceeded, report.Targets[0].Status)
+	require.Equal(t, StatusFailed, report.Targets[1].Status)
+	require.Equal(t, "deployment failed", report.Targets[1].Error)
+	require.Equal(t, StatusSucceeded, report.Targets[2].Status)
+	require.Equal(t, []string{"fabrikam"}, report.Failed())
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		report := file.Deploy(ctx, func(ctx context.Context, target Target) error {
+			require.Fail(t, "canceled deployments don't deploy targets")
+			return nil
+		}, func(result *Result) {})
+
+		for _, result := range report.Targets {
+			require.Equal(t, StatusSkipped, result.Status)
+		}
+	})
+}
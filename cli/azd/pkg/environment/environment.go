@@ -4,18 +4,24 @@
 package environment
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry"
 	"github.com/azure/azure-dev/cli/azd/internal/telemetry/fields"
 	"github.com/azure/azure-dev/cli/azd/pkg/config"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/gofrs/flock"
 	"github.com/joho/godotenv"
 )
 
@@ -38,14 +44,33 @@ const TenantIdEnvVarName = "AZURE_TENANT_ID"
 // to.
 const ContainerRegistryEndpointEnvVarName = "AZURE_CONTAINER_REGISTRY_ENDPOINT"
 
+// ContainerRegistryConnectedEndpointsEnvVarName is the name of the key used to store the endpoints of the connected
+// registries of the container registry, as a JSON object of location to endpoint, ex) {"westeurope": "registry.contoso.com"}
+const ContainerRegistryConnectedEndpointsEnvVarName = "AZURE_CONTAINER_REGISTRY_CONNECTED_ENDPOINTS"
+
+// ContainerRegistryConnectedUsernameEnvVarName is the name of the key used to store the username of the client token the
+// deploy targets pull images from the connected registries with
+const ContainerRegistryConnectedUsernameEnvVarName = "AZURE_CONTAINER_REGISTRY_CONNECTED_USERNAME"
+
+// ContainerRegistryConnectedPasswordEnvVarName is the name of the key used to store the password of the client token the
+// deploy targets pull images from the connected registries with
+const ContainerRegistryConnectedPasswordEnvVarName = "AZURE_CONTAINER_REGISTRY_CONNECTED_PASSWORD"
+
 // AksClusterEnvVarName is the name of they key used to store the endpoint of the AKS cluster to push to.
 const AksClusterEnvVarName = "AZURE_AKS_CLUSTER_NAME"
 
 // ResourceGroupEnvVarName is the name of the azure resource group that should be used for deployments
 const ResourceGroupEnvVarName = "AZURE_RESOURCE_GROUP"
 
+// ErrConflict is returned when saving an environment whose values were changed by another process since it was loaded
+var ErrConflict = errors.New("the environment was modified by another process")
+
+// The time to wait for another process to release the lock of the environment before giving up
+const lockTimeout = 30 * time.Second
+
 type Environment struct {
-	// Values is a map of setting names to values.
+	// Values is a map of setting names to values. Code running while services are deployed concurrently reads and writes
+	// the values with the methods of the environment, which are safe for concurrent use.
 	Values map[string]string
 
 	// Config is environment specific config
@@ -55,6 +80,13 @@ type Environment struct {
 	// will not be persisted when `Save` is called. This allows the zero value to be used
 	// for testing.
 	Root string
+
+	// The values as of the last time the environment was loaded from or saved to the .env file, used to detect the
+	// values changed by other processes. Nil when the environment hasn't been loaded.
+	loadedValues map[string]string
+
+	// Synchronizes the access to the values and the configuration by the methods of the environment
+	mu sync.RWMutex
 }
 
 type EnvironmentResolver func() (*Environment, error)
@@ -125,25 +157,45 @@ func EphemeralWithValues(name string, values map[string]string) *Environment {
 
 // Getenv fetches a key from e.Values, falling back to os.Getenv if it is not present.
 func (e *Environment) Getenv(key string) string {
-	if v, has := e.Values[key]; has {
+	if v, has := e.LookupEnv(key); has {
 		return v
 	}
 
 	return os.Getenv(key)
 }
 
+// LookupEnv fetches a key from e.Values, reporting whether the key is present
+func (e *Environment) LookupEnv(key string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	value, has := e.Values[key]
+	return value, has
+}
+
+// Dotenv returns a copy of the values of the environment
+func (e *Environment) Dotenv() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return cloneValues(e.Values)
+}
+
 // Reloads environment variables and configuration
 func (e *Environment) Reload() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	// Reload env values
 	envPath := filepath.Join(e.Root, azdcontext.DotEnvFileName)
-	if envMap, err := godotenv.Read(envPath); errors.Is(err, os.ErrNotExist) {
-		e.Values = make(map[string]string)
-	} else if err != nil {
-		return fmt.Errorf("loading .env: %w", err)
-	} else {
-		e.Values = envMap
+	envMap, err := readValues(envPath)
+	if err != nil {
+		return err
 	}
 
+	e.Values = envMap
+	e.loadedValues = cloneValues(envMap)
+
 	// Reload env config
 	cfgPath := filepath.Join(e.Root, azdcontext.ConfigFileName)
 	cfgMgr := config.NewManager()
@@ -155,8 +207,8 @@ func (e *Environment) Reload() error {
 		e.Config = cfg
 	}
 
-	if e.GetEnvName() != "" {
-		telemetry.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, e.GetEnvName()))
+	if envName := e.Values[EnvNameEnvVarName]; envName != "" {
+		telemetry.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, envName))
 	}
 
 	return nil
@@ -164,76 +216,265 @@ func (e *Environment) Reload() error {
 
 // If `Root` is set, Save writes the current contents of the environment to
 // the given directory, creating it and any intermediate directories as needed.
+//
+// The environment is locked while it is saved, so concurrent azd processes don't corrupt it. The values changed since
+// the environment was loaded are merged with the values saved by other processes in the meantime, and ErrConflict is
+// returned when another process changed the same values.
 func (e *Environment) Save() error {
 	if e.Root == "" {
 		return nil
 	}
 
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err := os.MkdirAll(e.Root, osutil.PermissionDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to create a directory: %w", err)
+	}
+
+	fileLock, err := e.lock()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := fileLock.Unlock(); err != nil {
+			log.Printf("failed to release environment lock: %v", err)
+		}
+	}()
+
 	// Update configuration
 	cfgMgr := config.NewManager()
 	if err := cfgMgr.Save(e.Config, filepath.Join(e.Root, azdcontext.ConfigFileName)); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
 
-	// Cache current values & reload to get any new env vars
-	currentValues := e.Values
-	if err := e.Reload(); err != nil {
+	// Read the current values to get any new env vars
+	envPath := filepath.Join(e.Root, azdcontext.DotEnvFileName)
+	savedValues, err := readValues(envPath)
+	if err != nil {
 		return fmt.Errorf("failed reloading env vars, %w", err)
 	}
 
-	// Overlay current values before saving
-	for key, value := range currentValues {
-		e.Values[key] = value
+	values, err := e.mergeValues(savedValues)
+	if err != nil {
+		return err
 	}
 
-	err := os.MkdirAll(e.Root, osutil.PermissionDirectory)
+	if err := writeValues(envPath, values); err != nil {
+		return fmt.Errorf("saving .env: %w", err)
+	}
+
+	e.Values = values
+	e.loadedValues = cloneValues(values)
+
+	telemetry.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, e.Values[EnvNameEnvVarName]))
+	return nil
+}
+
+// Locks the environment for writing, waiting for other processes to release it
+func (e *Environment) lock() (*flock.Flock, error) {
+	lockPath := filepath.Join(e.Root, fmt.Sprintf("%s.lock", azdcontext.DotEnvFileName))
+	fileLock := flock.New(lockPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+
+	locked, err := fileLock.TryLockContext(ctx, 100*time.Millisecond)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("locking file %s: %w", lockPath, err)
+	}
+
+	if !locked {
+		return nil, fmt.Errorf(
+			"environment '%s' is in use by another azd process, wait for it to complete and try again",
+			e.Values[EnvNameEnvVarName],
+		)
+	}
+
+	return fileLock, nil
+}
+
+// Merges the values changed since the environment was loaded into the saved values. Returns ErrConflict when a changed
+// value was also changed to a different value by another process.
+func (e *Environment) mergeValues(savedValues map[string]string) (map[string]string, error) {
+	values := cloneValues(savedValues)
+	conflicts := []string{}
+
+	for key, value := range e.Values {
+		loadedValue, wasLoaded := e.loadedValues[key]
+		if wasLoaded && loadedValue == value {
+			// Unchanged, keeps the value saved by other processes
+			continue
+		}
+
+		savedValue, isSaved := savedValues[key]
+		changedBySave := isSaved && (!wasLoaded || savedValue != loadedValue)
+		if e.loadedValues != nil && changedBySave && savedValue != value {
+			conflicts = append(conflicts, key)
+			continue
+		}
+
+		values[key] = value
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf(
+			"saving environment '%s': %w, conflicting values: %s. Run the command again to use the latest values",
+			e.Values[EnvNameEnvVarName],
+			ErrConflict,
+			strings.Join(conflicts, ", "),
+		)
+	}
+
+	return values, nil
+}
+
+// Reads the values of a .env file, returning empty values when the file doesn't exist
+func readValues(envPath string) (map[string]string, error) {
+	envMap, err := godotenv.Read(envPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("loading .env: %w", err)
+	}
+
+	return envMap, nil
+}
+
+// Writes the values to a .env file atomically, by writing a temporary file renamed over the .env file, so concurrent
+// readers never observe a partially written file
+func writeValues(envPath string, values map[string]string) error {
+	contents, err := godotenv.Marshal(values)
 	if err != nil {
-		return fmt.Errorf("failed to create a directory: %w", err)
+		return err
 	}
 
-	err = godotenv.Write(e.Values, filepath.Join(e.Root, azdcontext.DotEnvFileName))
+	tempFile, err := os.CreateTemp(filepath.Dir(envPath), fmt.Sprintf("%s.*.tmp", filepath.Base(envPath)))
 	if err != nil {
-		return fmt.Errorf("saving .env: %w", err)
+		return err
+	}
+	defer func() {
+		// Removes the temporary file when it wasn't renamed
+		_ = os.Remove(tempFile.Name())
+	}()
+
+	if _, err := tempFile.WriteString(contents + "\n"); err != nil {
+		_ = tempFile.Close()
+		return err
 	}
 
-	telemetry.SetUsageAttributes(fields.StringHashed(fields.EnvNameKey, e.GetEnvName()))
-	return nil
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tempFile.Name(), osutil.PermissionFile); err != nil {
+		return err
+	}
+
+	return osutil.Rename(context.Background(), tempFile.Name(), envPath)
+}
+
+func cloneValues(values map[string]string) map[string]string {
+	clone := make(map[string]string, len(values))
+	for key, value := range values {
+		clone[key] = value
+	}
+
+	return clone
+}
+
+// Gets a value of the environment, empty when the value isn't set
+func (e *Environment) get(key string) string {
+	value, _ := e.LookupEnv(key)
+	return value
+}
+
+// Sets a value of the environment
+func (e *Environment) set(key string, value string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.Values[key] = value
 }
 
 func (e *Environment) GetEnvName() string {
-	return e.Values[EnvNameEnvVarName]
+	return e.get(EnvNameEnvVarName)
 }
 
 func (e *Environment) SetEnvName(envname string) {
-	e.Values[EnvNameEnvVarName] = envname
+	e.set(EnvNameEnvVarName, envname)
 }
 
 func (e *Environment) GetSubscriptionId() string {
-	return e.Values[SubscriptionIdEnvVarName]
+	return e.get(SubscriptionIdEnvVarName)
 }
 
 func (e *Environment) GetTenantId() string {
-	return e.Values[TenantIdEnvVarName]
+	return e.get(TenantIdEnvVarName)
 }
 
 func (e *Environment) SetSubscriptionId(id string) {
-	e.Values[SubscriptionIdEnvVarName] = id
+	e.set(SubscriptionIdEnvVarName, id)
 }
 
 func (e *Environment) GetLocation() string {
-	return e.Values[LocationEnvVarName]
+	return e.get(LocationEnvVarName)
 }
 
 func (e *Environment) SetLocation(location string) {
-	e.Values[LocationEnvVarName] = location
+	e.set(LocationEnvVarName, location)
 }
 
 func (e *Environment) SetPrincipalId(principalID string) {
-	e.Values[PrincipalIdEnvVarName] = principalID
+	e.set(PrincipalIdEnvVarName, principalID)
 }
 
 func (e *Environment) GetPrincipalId() string {
-	return e.Values[PrincipalIdEnvVarName]
+	return e.get(PrincipalIdEnvVarName)
+}
+
+// The path of the protection of the environment, in the configuration of the environment
+const protectedConfigPath = "protected"
+
+// IsProtected checks whether the environment is protected, in which case deleting its resources requires typing the name
+// of the environment, ex) for production environments
+func (e *Environment) IsProtected() bool {
+	value, has := e.Config.Get(protectedConfigPath)
+	protected, ok := value.(bool)
+	return has && ok && protected
+}
+
+// SetProtected sets whether the environment is protected
+func (e *Environment) SetProtected(protected bool) error {
+	if !protected {
+		return e.Config.Unset(protectedConfigPath)
+	}
+
+	return e.Config.Set(protectedConfigPath, true)
+}
+
+// The path of the secrets declared by the services of the project, in the configuration of the environment. The secrets
+// aren't environment values, so they're only exposed to the services they're shared with.
+const serviceSecretsConfigPath = "secrets"
+
+// GetServiceSecret gets the value of a secret declared by the service, ex) an API key generated for the service
+func (e *Environment) GetServiceSecret(serviceName string, secretName string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	value, has := e.Config.Get(fmt.Sprintf("%s.%s.%s", serviceSecretsConfigPath, normalize(serviceName), secretName))
+	secret, ok := value.(string)
+	return secret, has && ok
+}
+
+// SetServiceSecret sets the value of a secret declared by the service
+func (e *Environment) SetServiceSecret(serviceName string, secretName string, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.Config.Set(fmt.Sprintf("%s.%s.%s", serviceSecretsConfigPath, normalize(serviceName), secretName), value)
 }
 
 func normalize(key string) string {
@@ -242,17 +483,20 @@ func normalize(key string) string {
 
 // Returns the value of a service-namespaced property in the environment.
 func (e *Environment) GetServiceProperty(serviceName string, propertyName string) string {
-	return e.Values[fmt.Sprintf("SERVICE_%s_%s", normalize(serviceName), propertyName)]
+	return e.get(fmt.Sprintf("SERVICE_%s_%s", normalize(serviceName), propertyName))
 }
 
 // Sets the value of a service-namespaced property in the environment.
 func (e *Environment) SetServiceProperty(serviceName string, propertyName string, value string) {
-	e.Values[fmt.Sprintf("SERVICE_%s_%s", normalize(serviceName), propertyName)] = value
+	e.set(fmt.Sprintf("SERVICE_%s_%s", normalize(serviceName), propertyName), value)
 }
 
 // Creates a slice of key value pairs like `KEY=VALUE` that
 // can be used to pass into command runner or similar constructs
 func (e *Environment) Environ() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
 	envVars := []string{}
 	for k, v := range e.Values {
 		envVars = append(envVars, fmt.Sprintf("%s=%s", k, v))
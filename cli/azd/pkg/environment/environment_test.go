@@ -4,8 +4,10 @@
 package environment
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
@@ -147,3 +149,102 @@ func Test_SaveAndReload(t *testing.T) {
 	require.Equal(t, "SUBSCRIPTION_ID", env.GetSubscriptionId())
 	require.Equal(t, "eastus2", env.GetLocation())
 }
+
+func Test_SaveConcurrentChanges(t *testing.T) {
+	t.Run("KeepsValuesChangedByOtherProcesses", func(t *testing.T) {
+		tempDir := t.TempDir()
+		env, err := FromRoot(tempDir)
+		require.NoError(t, err)
+
+		env.SetLocation("eastus2")
+		require.NoError(t, env.Save())
+
+		// Another process loads the environment and changes the location
+		other, err := FromRoot(tempDir)
+		require.NoError(t, err)
+		other.SetLocation("westus3")
+		require.NoError(t, other.Save())
+
+		// The stale location of the first environment doesn't overwrite the new location
+		env.SetSubscriptionId("SUBSCRIPTION_ID")
+		require.NoError(t, env.Save())
+		require.Equal(t, "westus3", env.GetLocation())
+		require.Equal(t, "SUBSCRIPTION_ID", env.GetSubscriptionId())
+
+		// The .env file is replaced atomically, no temporary files are left behind
+		entries, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		for _, entry := range entries {
+			require.NotContains(t, entry.Name(), ".tmp")
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		tempDir := t.TempDir()
+		env, err := FromRoot(tempDir)
+		require.NoError(t, err)
+
+		env.SetLocation("eastus2")
+		require.NoError(t, env.Save())
+
+		other, err := FromRoot(tempDir)
+		require.NoError(t, err)
+		other.SetLocation("westus3")
+		require.NoError(t, other.Save())
+
+		env.SetLocation("centralus")
+		err = env.Save()
+		require.ErrorIs(t, err, ErrConflict)
+		require.ErrorContains(t, err, LocationEnvVarName)
+
+		// The values saved by the other process are preserved
+		saved, err := FromRoot(tempDir)
+		require.NoError(t, err)
+		require.Equal(t, "westus3", saved.GetLocation())
+	})
+}
+
+func Test_ConcurrentServiceProperties(t *testing.T) {
+	env, err := FromRoot(t.TempDir())
+	require.NoError(t, err)
+
+	// Services deployed concurrently set their properties and save the environment at the same time
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		serviceName := fmt.Sprintf("svc%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			env.SetServiceProperty(serviceName, "IMAGE_NAME", serviceName+":latest")
+			assert.NoError(t, env.Save())
+			assert.NotEmpty(t, env.Dotenv())
+			assert.Equal(t, serviceName+":latest", env.GetServiceProperty(serviceName, "IMAGE_NAME"))
+		}()
+	}
+	wg.Wait()
+
+	saved, err := FromRoot(env.Root)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		serviceName := fmt.Sprintf("svc%d", i)
+		require.Equal(t, serviceName+":latest", saved.GetServiceProperty(serviceName, "IMAGE_NAME"))
+	}
+}
+
+func Test_Protection(t *testing.T) {
+	root := t.TempDir()
+
+	e, err := FromRoot(root)
+	require.NoError(t, err)
+	require.False(t, e.IsProtected())
+
+	require.NoError(t, e.SetProtected(true))
+	require.NoError(t, e.Save())
+
+	e, err = FromRoot(root)
+	require.NoError(t, err)
+	require.True(t, e.IsProtected())
+
+	require.NoError(t, e.SetProtected(false))
+	require.False(t, e.IsProtected())
+}
@@ -7,6 +7,7 @@
 package exec
 
 import (
+	"os"
 	"os/exec"
 	"syscall"
 )
@@ -34,6 +35,33 @@ func (o *CmdTree) Start() error {
 	return o.Cmd.Start()
 }
 
+// StartPty starts the command under a new pseudo terminal, returning the controlling side of the terminal the output
+// of the command is read from. The command runs in a new session, with the terminal as its controlling terminal, so
+// Kill still terminates the command and its child processes.
+func (o *CmdTree) StartPty() (*os.File, error) {
+	ptmx, tty, err := openPty()
+	if err != nil {
+		return nil, err
+	}
+	// The command has its own handle to the terminal once started
+	defer tty.Close()
+
+	o.Cmd.Stdin = tty
+	o.Cmd.Stdout = tty
+	o.Cmd.Stderr = tty
+	o.Cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid:  true,
+		Setctty: true,
+	}
+
+	if err := o.Cmd.Start(); err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+
+	return ptmx, nil
+}
+
 func (o *CmdTree) Kill() {
 	_ = syscall.Kill(-o.Cmd.Process.Pid, syscall.SIGKILL)
 }
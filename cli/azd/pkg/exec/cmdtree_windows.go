@@ -9,6 +9,7 @@ package exec
 import (
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"syscall"
 	"unsafe"
@@ -73,6 +74,11 @@ func (o *CmdTree) Start() error {
 	return nil
 }
 
+// StartPty isn't supported on Windows, commands are run with pipes instead
+func (o *CmdTree) StartPty() (*os.File, error) {
+	return nil, ErrPtyUnsupported
+}
+
 func (o *CmdTree) Kill() {
 	err := windows.TerminateJobObject(windows.Handle(o.jobObject), 0)
 	if err != nil {
@@ -0,0 +1,307 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrCommandNotAllowed is returned when a command isn't in the allowed commands of the command policy
+var ErrCommandNotAllowed = errors.New("command is not allowed")
+
+// CommandPolicy restricts the external commands run by azd, including the commands run by hooks, and audits the
+// commands that are run. The policy is managed by an organization in a machine level file, ex) /etc/azd/exec.yaml
+type CommandPolicy struct {
+	// The commands allowed to run, by name, ex) docker, or by path, ex) /usr/bin/docker.
+	// All commands are allowed when empty.
+	AllowedCommands []string `yaml:"allowedCommands"`
+	// The path of the file each command is appended to as a JSON line, with sensitive arguments redacted.
+	// Commands aren't audited when empty.
+	AuditLogPath string `yaml:"auditLog"`
+}
+
+// Gets the path of the machine level command policy file. The file is stored in a machine wide location that is
+// typically only writable by administrators, ex) /etc/azd/exec.yaml. The location can't be overridden by users.
+func CommandPolicyFilePath() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+
+		return filepath.Join(programData, "azd", "exec.yaml")
+	}
+
+	return filepath.Join("/etc", "azd", "exec.yaml")
+}
+
+// Loads the command policy from the specified file. Returns nil when the file doesn't exist.
+func LoadCommandPolicy(filePath string) (*CommandPolicy, error) {
+	contents, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading command policy file: %w", err)
+	}
+
+	var policy CommandPolicy
+	if err := yaml.Unmarshal(contents, &policy); err != nil {
+		return nil, fmt.Errorf("parsing command policy file '%s': %w", filePath, err)
+	}
+
+	return &policy, nil
+}
+
+// Shell builtins used within command lists which don't run external commands, ex) . venv/bin/activate
+var shellBuiltins = map[string]struct{}{
+	".":      {},
+	"source": {},
+	"cd":     {},
+	"set":    {},
+	"export": {},
+}
+
+// NewPolicyCommandRunner creates a CommandRunner enforcing the policy for the commands run by the inner command runner.
+// Commands that aren't allowed fail with [ErrCommandNotAllowed] without being started.
+func NewPolicyCommandRunner(inner CommandRunner, policy CommandPolicy) CommandRunner {
+	return &policyCommandRunner{
+		inner:  inner,
+		policy: policy,
+	}
+}
+
+type policyCommandRunner struct {
+	inner  CommandRunner
+	policy CommandPolicy
+	// Synchronizes writes to the audit log
+	auditMu sync.Mutex
+}
+
+// A line of the command audit log
+type commandAuditEntry struct {
+	Time        time.Time `json:"time"`
+	Command     string    `json:"command"`
+	CommandLine string    `json:"commandLine"`
+	Cwd         string    `json:"cwd,omitempty"`
+	Allowed     bool      `json:"allowed"`
+}
+
+func (r *policyCommandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error) {
+	commandLine := strings.TrimSpace(fmt.Sprintf("%s %s", args.Cmd, strings.Join(args.Args, " ")))
+	if err := r.enforce(args.Cmd, commandLine, args.Cwd); err != nil {
+		return NewRunResult(-1, "", ""), err
+	}
+
+	return r.inner.Run(ctx, args)
+}
+
+func (r *policyCommandRunner) RunList(ctx context.Context, commands []string, args RunArgs) (RunResult, error) {
+	for _, commandLine := range commands {
+		simpleCommands, err := splitCommandLine(commandLine)
+		if err != nil {
+			if len(r.policy.AllowedCommands) > 0 {
+				r.audit(commandAuditEntry{
+					Time:        time.Now().UTC(),
+					CommandLine: redactSensitiveData(commandLine),
+					Cwd:         args.Cwd,
+				})
+
+				return NewRunResult(-1, "", ""), fmt.Errorf("%w: %w", ErrCommandNotAllowed, err)
+			}
+
+			simpleCommands = []string{commandLine}
+		}
+
+		for _, command := range simpleCommands {
+			name := commandLineName(command)
+			if name == "" {
+				continue
+			}
+
+			if _, builtin := shellBuiltins[name]; builtin {
+				continue
+			}
+
+			if err := r.enforce(name, command, args.Cwd); err != nil {
+				return NewRunResult(-1, "", ""), err
+			}
+		}
+	}
+
+	return r.inner.RunList(ctx, commands, args)
+}
+
+// Splits a shell command line into the simple commands it runs, ex) 'npm ci && npm run build | tee log' runs npm, npm
+// and tee. Command lines that run commands which can't be determined without running the shell, ex) $(curl ...) or
+// subshells, return an error.
+func splitCommandLine(commandLine string) ([]string, error) {
+	commands := []string{}
+	current := strings.Builder{}
+	var quote rune
+
+	flush := func() {
+		if command := strings.TrimSpace(current.String()); command != "" {
+			commands = append(commands, command)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(commandLine)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\' && i+1 < len(runes):
+			current.WriteRune(c)
+			i++
+			c = runes[i]
+		case c == '`' || (c == '$' && i+1 < len(runes) && runes[i+1] == '('):
+			return nil, fmt.Errorf("command substitutions aren't supported in '%s'", commandLine)
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == ')':
+			return nil, fmt.Errorf("subshells aren't supported in '%s'", commandLine)
+		case c == '&' && isRedirection(runes, i):
+		case c == '&' || c == '|' || c == ';' || c == '\n':
+			flush()
+			continue
+		}
+
+		current.WriteRune(c)
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in '%s'", commandLine)
+	}
+
+	flush()
+	return commands, nil
+}
+
+// Checks whether the & at the index is part of a redirection, ex) 2>&1 or &> log, rather than a command separator
+func isRedirection(runes []rune, i int) bool {
+	return (i > 0 && (runes[i-1] == '>' || runes[i-1] == '<')) || (i+1 < len(runes) && runes[i+1] == '>')
+}
+
+// Gets the name of the command run by a simple command line, skipping the variable assignments before the command,
+// ex) 'DEBUG=1 npm start' runs npm
+func commandLineName(command string) string {
+	for _, field := range strings.Fields(command) {
+		if name, _, isAssignment := strings.Cut(field, "="); isAssignment && name != "" &&
+			!strings.ContainsAny(name, `/\"'`) {
+			continue
+		}
+
+		return strings.Trim(field, `"'`)
+	}
+
+	return ""
+}
+
+// Audits the command and checks whether the command is allowed
+func (r *policyCommandRunner) enforce(command string, commandLine string, cwd string) error {
+	allowed := r.allowed(command)
+	r.audit(commandAuditEntry{
+		Time:        time.Now().UTC(),
+		Command:     command,
+		CommandLine: redactSensitiveData(commandLine),
+		Cwd:         cwd,
+		Allowed:     allowed,
+	})
+
+	if !allowed {
+		return fmt.Errorf(
+			"%w: '%s' isn't one of the allowed commands (%s)",
+			ErrCommandNotAllowed,
+			command,
+			strings.Join(r.policy.AllowedCommands, ", "),
+		)
+	}
+
+	return nil
+}
+
+// Checks whether the command is in the allowed commands. Commands match allowed commands by path when the allowed
+// command is a path, and by name otherwise, ignoring the extension of executables on Windows, ex) docker.exe
+func (r *policyCommandRunner) allowed(command string) bool {
+	if len(r.policy.AllowedCommands) == 0 {
+		return true
+	}
+
+	name := commandName(command)
+
+	for _, allowedCommand := range r.policy.AllowedCommands {
+		if strings.ContainsAny(allowedCommand, `/\`) {
+			allowedPath, commandPath := filepath.Clean(allowedCommand), filepath.Clean(command)
+			if allowedPath == commandPath || (runtime.GOOS == "windows" && strings.EqualFold(allowedPath, commandPath)) {
+				return true
+			}
+
+			continue
+		}
+
+		if commandName(allowedCommand) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Gets the name of the command, without the extension of executables on Windows
+func commandName(command string) string {
+	name := filepath.Base(command)
+	if runtime.GOOS == "windows" {
+		name = strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+
+	return name
+}
+
+// Appends the entry to the audit log. Failures to audit are logged, they don't fail the command.
+func (r *policyCommandRunner) audit(entry commandAuditEntry) {
+	if r.policy.AuditLogPath == "" {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("failed auditing command '%s': %v", entry.Command, err)
+		return
+	}
+
+	r.auditMu.Lock()
+	defer r.auditMu.Unlock()
+
+	file, err := os.OpenFile(r.policy.AuditLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("failed opening command audit log: %v", err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		log.Printf("failed auditing command '%s': %v", entry.Command, err)
+	}
+}
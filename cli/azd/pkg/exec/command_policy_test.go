@@ -0,0 +1,135 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PolicyCommandRunner(t *testing.T) {
+	auditLogPath := filepath.Join(t.TempDir(), "audit.log")
+	inner := &recordingCommandRunner{}
+	runner := NewPolicyCommandRunner(inner, CommandPolicy{
+		AllowedCommands: []string{"docker", "/usr/bin/az"},
+		AuditLogPath:    auditLogPath,
+	})
+
+	_, err := runner.Run(context.Background(), NewRunArgs("docker", "login", "--password", "SECRET", "myregistry"))
+	require.NoError(t, err)
+
+	_, err = runner.Run(context.Background(), NewRunArgs("/usr/bin/az", "version"))
+	require.NoError(t, err)
+
+	// Commands are matched by path when the allowed command is a path
+	_, err = runner.Run(context.Background(), NewRunArgs("/tmp/az", "version"))
+	require.ErrorIs(t, err, ErrCommandNotAllowed)
+
+	_, err = runner.Run(context.Background(), NewRunArgs("curl", "https://example.com"))
+	require.ErrorIs(t, err, ErrCommandNotAllowed)
+
+	// Shell builtins within command lists aren't commands
+	_, err = runner.RunList(context.Background(), []string{". .venv/bin/activate", "docker version"}, RunArgs{})
+	require.NoError(t, err)
+
+	_, err = runner.RunList(context.Background(), []string{"docker version", "python3 -m pip install"}, RunArgs{})
+	require.ErrorIs(t, err, ErrCommandNotAllowed)
+
+	// Commands that aren't allowed aren't started
+	require.Equal(t, []string{"docker", "/usr/bin/az", "list"}, inner.commands)
+
+	file, err := os.Open(auditLogPath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	entries := []commandAuditEntry{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry commandAuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	require.Len(t, entries, 7)
+	require.Equal(t, "docker login --password <redacted> myregistry", entries[0].CommandLine)
+	require.True(t, entries[0].Allowed)
+	require.False(t, entries[2].Allowed)
+	require.Equal(t, "python3", entries[6].Command)
+	require.False(t, entries[6].Allowed)
+}
+
+func Test_PolicyCommandRunner_CompoundCommands(t *testing.T) {
+	inner := &recordingCommandRunner{}
+	runner := NewPolicyCommandRunner(inner, CommandPolicy{AllowedCommands: []string{"npm", "tee"}})
+
+	_, err := runner.RunList(context.Background(), []string{"DEBUG=1 npm ci 2>&1 | tee 'a && b.log'; npm test"}, RunArgs{})
+	require.NoError(t, err)
+
+	for _, commandLine := range []string{
+		"npm ci && curl https://example.com",
+		"npm ci; curl https://example.com",
+		"npm ci | sh",
+		"npm ci & curl https://example.com",
+		"npm ci\ncurl https://example.com",
+		"npm run $(curl https://example.com)",
+		"npm run `curl https://example.com`",
+		"(curl https://example.com)",
+	} {
+		_, err := runner.RunList(context.Background(), []string{commandLine}, RunArgs{})
+		require.ErrorIs(t, err, ErrCommandNotAllowed, commandLine)
+	}
+
+	require.Equal(t, []string{"list"}, inner.commands)
+}
+
+func Test_LoadCommandPolicy(t *testing.T) {
+	policy, err := LoadCommandPolicy(filepath.Join(t.TempDir(), "exec.yaml"))
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	policyFilePath := filepath.Join(t.TempDir(), "exec.yaml")
+	contents := "allowedCommands:\n  - docker\n  - /usr/bin/az\nauditLog: /var/log/azd/audit.log\n"
+	require.NoError(t, os.WriteFile(policyFilePath, []byte(contents), 0600))
+
+	policy, err = LoadCommandPolicy(policyFilePath)
+	require.NoError(t, err)
+	require.Equal(t, &CommandPolicy{
+		AllowedCommands: []string{"docker", "/usr/bin/az"},
+		AuditLogPath:    "/var/log/azd/audit.log",
+	}, policy)
+
+	require.NoError(t, os.WriteFile(policyFilePath, []byte("allowedCommands: [\n"), 0600))
+	_, err = LoadCommandPolicy(policyFilePath)
+	require.Error(t, err)
+}
+
+func Test_PolicyCommandRunner_AllowsAllCommands(t *testing.T) {
+	inner := &recordingCommandRunner{}
+	runner := NewPolicyCommandRunner(inner, CommandPolicy{})
+
+	_, err := runner.Run(context.Background(), NewRunArgs("curl", "https://example.com"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"curl"}, inner.commands)
+}
+
+// recordingCommandRunner is a CommandRunner recording the commands that are run, without running them
+type recordingCommandRunner struct {
+	commands []string
+}
+
+func (r *recordingCommandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error) {
+	r.commands = append(r.commands, args.Cmd)
+	return NewRunResult(0, "", ""), nil
+}
+
+func (r *recordingCommandRunner) RunList(ctx context.Context, commands []string, args RunArgs) (RunResult, error) {
+	r.commands = append(r.commands, "list")
+	return NewRunResult(0, "", ""), nil
+}
@@ -75,7 +75,7 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 
 	var stdout, stderr bytes.Buffer
 
-	cmd.Env = appendEnv(args.Env)
+	cmd.Env = commandEnv(ctx, args.Env)
 
 	if args.Interactive {
 		cmd.Stdin = r.stdin
@@ -86,6 +86,10 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
+		if args.Stdout != nil {
+			cmd.Stdout = io.MultiWriter(args.Stdout, &stdout)
+		}
+
 		if args.Stderr != nil {
 			cmd.Stderr = io.MultiWriter(args.Stderr, &stderr)
 		}
@@ -100,7 +104,14 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 		}
 	}
 
-	if err := cmd.Start(); err != nil {
+	var output *ptyOutput
+	if args.Pty && !args.Interactive {
+		output, err = startPty(&cmd)
+	} else {
+		err = cmd.Start()
+	}
+
+	if err != nil {
 		return RunResult{}, err
 	}
 
@@ -114,6 +125,10 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 
 	err = cmd.Wait()
 
+	if output != nil {
+		output.wait()
+	}
+
 	var result RunResult
 
 	if args.Interactive {
@@ -145,6 +160,23 @@ func (r *commandRunner) Run(ctx context.Context, args RunArgs) (RunResult, error
 	return result, err
 }
 
+// Starts the command under a pseudo terminal, copying the output written to the terminal to the stdout of the command.
+// Starts the command without a terminal when pseudo terminals aren't supported.
+func startPty(cmd *CmdTree) (*ptyOutput, error) {
+	output := cmd.Stdout
+	ptmx, err := cmd.StartPty()
+	if errors.Is(err, ErrPtyUnsupported) {
+		log.Printf("running '%s' without a terminal: %v", cmd.Path, err)
+		return nil, cmd.Start()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newPtyOutput(ptmx, output), nil
+}
+
 func (r *commandRunner) RunList(ctx context.Context, commands []string, args RunArgs) (RunResult, error) {
 	process, err := newCmdTree(ctx, "", commands, true, false)
 	if err != nil {
@@ -152,7 +184,7 @@ func (r *commandRunner) RunList(ctx context.Context, commands []string, args Run
 	}
 
 	process.Cmd.Dir = args.Cwd
-	process.Env = appendEnv(args.Env)
+	process.Env = commandEnv(ctx, args.Env)
 
 	var stdOutBuf bytes.Buffer
 	var stdErrBuf bytes.Buffer
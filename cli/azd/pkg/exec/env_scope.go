@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// The variables of the environment of azd that commands always inherit, as they're required by most tools to run,
+// ex) to find executables, the home directory of the user, temporary directories, proxies and certificates
+var essentialEnvVars = []string{
+	"PATH",
+	"PATHEXT",
+	"HOME",
+	"USER",
+	"USERNAME",
+	"USERPROFILE",
+	"HOMEDRIVE",
+	"HOMEPATH",
+	"SHELL",
+	"LANG",
+	"LC_*",
+	"TERM",
+	"TMP",
+	"TEMP",
+	"TMPDIR",
+	"XDG_*",
+	"SYSTEMROOT",
+	"SYSTEMDRIVE",
+	"WINDIR",
+	"COMSPEC",
+	"APPDATA",
+	"LOCALAPPDATA",
+	"PROGRAMDATA",
+	"PROGRAMFILES",
+	"PROGRAMFILES(X86)",
+	"DOCKER_HOST",
+	"DOCKER_CONFIG",
+	"DOCKER_CONTEXT",
+	"HTTP_PROXY",
+	"HTTPS_PROXY",
+	"NO_PROXY",
+	"http_proxy",
+	"https_proxy",
+	"no_proxy",
+	"SSL_CERT_FILE",
+	"SSL_CERT_DIR",
+	"REQUESTS_CA_BUNDLE",
+	"NODE_EXTRA_CA_CERTS",
+	"JAVA_HOME",
+}
+
+// EnvScope restricts the variables of the environment of azd inherited by commands, so the user scripts run for a
+// service, ex) hooks, don't depend on variables meant for other services or set in the shell running azd.
+// The variables set explicitly for a command with RunArgs.Env are always set.
+type EnvScope struct {
+	// The names of the variables inherited by commands, in addition to the variables required to run most tools,
+	// ex) PATH and HOME. Names ending with * match by prefix, ex) NPM_CONFIG_*.
+	Allow []string
+}
+
+// Allows checks whether the variable with the name is inherited by the commands of the scope.
+// Names are matched case-insensitively on Windows, where environment variables are case-insensitive.
+func (s EnvScope) Allows(name string) bool {
+	for _, patterns := range [][]string{essentialEnvVars, s.Allow} {
+		for _, pattern := range patterns {
+			if matchEnvName(pattern, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Filter gets the KEY=VALUE pairs of the variables allowed by the scope
+func (s EnvScope) Filter(env []string) []string {
+	filtered := []string{}
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if s.Allows(name) {
+			filtered = append(filtered, kv)
+		}
+	}
+
+	return filtered
+}
+
+func matchEnvName(pattern string, name string) bool {
+	if runtime.GOOS == "windows" {
+		pattern, name = strings.ToUpper(pattern), strings.ToUpper(name)
+	}
+
+	if prefix, isPrefix := strings.CutSuffix(pattern, "*"); isPrefix {
+		return strings.HasPrefix(name, prefix)
+	}
+
+	return pattern == name
+}
+
+type envScopeContextKey struct{}
+
+// WithEnvScope returns a context restricting the environment inherited by the commands run with it to the scope
+func WithEnvScope(ctx context.Context, scope EnvScope) context.Context {
+	return context.WithValue(ctx, envScopeContextKey{}, scope)
+}
+
+// EnvScopeFromContext gets the scope of the environment of the commands run with the context, if any
+func EnvScopeFromContext(ctx context.Context) (EnvScope, bool) {
+	scope, ok := ctx.Value(envScopeContextKey{}).(EnvScope)
+	return scope, ok
+}
+
+type envVarsContextKey struct{}
+
+// WithEnvVars returns a context setting the variables, in the form 'key=value', for the commands run with it, ex) the
+// credentials of the package feeds of restores. The variables set for a command with RunArgs.Env take precedence.
+func WithEnvVars(ctx context.Context, envVars ...string) context.Context {
+	existing, _ := ctx.Value(envVarsContextKey{}).([]string)
+	return context.WithValue(ctx, envVarsContextKey{}, append(append([]string{}, existing...), envVars...))
+}
+
+// Gets the environment of a command, the variables of the environment of azd allowed by the scope of the context
+// followed by the variables of the context and the variables set for the command. Returns nil to inherit the environment
+// of azd as-is.
+func commandEnv(ctx context.Context, env []string) []string {
+	if ctxEnv, _ := ctx.Value(envVarsContextKey{}).([]string); len(ctxEnv) > 0 {
+		env = append(append([]string{}, ctxEnv...), env...)
+	}
+
+	scope, has := EnvScopeFromContext(ctx)
+	if !has {
+		return appendEnv(env)
+	}
+
+	return append(scope.Filter(os.Environ()), env...)
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvScope(t *testing.T) {
+	scope := EnvScope{Allow: []string{"NPM_TOKEN", "AZURE_*"}}
+
+	require.True(t, scope.Allows("PATH"))
+	require.True(t, scope.Allows("HTTPS_PROXY"))
+	require.True(t, scope.Allows("https_proxy"))
+	require.True(t, scope.Allows("JAVA_HOME"))
+	require.True(t, scope.Allows("NPM_TOKEN"))
+	require.True(t, scope.Allows("AZURE_LOCATION"))
+	require.False(t, scope.Allows("NPM_TOKEN_OTHER"))
+	require.False(t, scope.Allows("SERVICE_WEB_IMAGE_NAME"))
+
+	require.Equal(t,
+		[]string{"PATH=/usr/bin", "AZURE_LOCATION=eastus", "NPM_TOKEN=token"},
+		scope.Filter([]string{"PATH=/usr/bin", "AZURE_LOCATION=eastus", "SERVICE_WEB_URL=url", "NPM_TOKEN=token"}),
+	)
+}
+
+func TestCommandEnv(t *testing.T) {
+	t.Setenv("AZD_TEST_ALLOWED", "allowed")
+	t.Setenv("AZD_TEST_DENIED", "denied")
+
+	// Without a scope the environment of azd is inherited as-is
+	require.Nil(t, commandEnv(context.Background(), nil))
+	require.Contains(t, commandEnv(context.Background(), []string{"KEY=VALUE"}), "AZD_TEST_DENIED=denied")
+
+	ctx := WithEnvScope(context.Background(), EnvScope{Allow: []string{"AZD_TEST_ALLOWED"}})
+	env := commandEnv(ctx, []string{"KEY=VALUE"})
+	require.Contains(t, env, "AZD_TEST_ALLOWED=allowed")
+	require.NotContains(t, env, "AZD_TEST_DENIED=denied")
+	require.Equal(t, "KEY=VALUE", env[len(env)-1])
+}
+
+func TestCommandEnvWithEnvVars(t *testing.T) {
+	t.Setenv("AZD_TEST_DENIED", "denied")
+
+	ctx := WithEnvVars(context.Background(), "NPM_CONFIG_USERCONFIG=/tmp/.npmrc")
+	env := commandEnv(ctx, []string{"KEY=VALUE"})
+	require.Contains(t, env, "AZD_TEST_DENIED=denied")
+	require.Equal(t, []string{"NPM_CONFIG_USERCONFIG=/tmp/.npmrc", "KEY=VALUE"}, env[len(env)-2:])
+
+	// The variables of the context aren't filtered by the scope of the context
+	ctx = WithEnvScope(ctx, EnvScope{Allow: []string{"AZD_TEST_ALLOWED"}})
+	env = commandEnv(ctx, nil)
+	require.Contains(t, env, "NPM_CONFIG_USERCONFIG=/tmp/.npmrc")
+	require.NotContains(t, env, "AZD_TEST_DENIED=denied")
+}
@@ -0,0 +1,63 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Matches the ANSI escape sequences written by tools running under a terminal, ex) colors and cursor movements
+var ansiEscapeRegexp = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07]*\x07`)
+
+// LineWriter is an io.Writer calling a function for each line of the output of a command, as the command writes the
+// output. It is used as the Stdout and Stderr of a command to report its output as progress, ex) per service.
+// Lines end with new lines or carriage returns, used by tools to redraw progress. ANSI escape sequences are removed and
+// empty lines are skipped.
+type LineWriter struct {
+	onLine  func(line string)
+	pending []byte
+	mu      sync.Mutex
+}
+
+// NewLineWriter creates a LineWriter calling onLine for each line written to it
+func NewLineWriter(onLine func(line string)) *LineWriter {
+	return &LineWriter{onLine: onLine}
+}
+
+// NewPrefixLineWriter creates a LineWriter calling onLine for each line written to it, prefixed with the prefix in
+// brackets, ex) [api] Step 1/5, to interleave the output of commands run for multiple services
+func NewPrefixLineWriter(prefix string, onLine func(line string)) *LineWriter {
+	return NewLineWriter(func(line string) {
+		onLine("[" + prefix + "] " + line)
+	})
+}
+
+// Write reports the complete lines written to the writer. The remainder of an incomplete line is reported once the
+// line is complete.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	// Stdout and stderr are written concurrently when both use the same writer
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+
+	for {
+		index := bytes.IndexAny(w.pending, "\r\n")
+		if index < 0 {
+			break
+		}
+
+		line := strings.TrimSpace(ansiEscapeRegexp.ReplaceAllString(string(w.pending[:index]), ""))
+		if line != "" {
+			w.onLine(line)
+		}
+
+		w.pending = w.pending[index+1:]
+	}
+
+	return len(p), nil
+}
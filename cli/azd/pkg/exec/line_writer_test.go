@@ -0,0 +1,40 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LineWriter(t *testing.T) {
+	lines := []string{}
+	writer := NewLineWriter(func(line string) {
+		lines = append(lines, line)
+	})
+
+	_, _ = writer.Write([]byte("#1 [internal] load build"))
+	_, _ = writer.Write([]byte(" definition\n\n#2 [1/3] FROM node\r#2 DONE 0.1s\n#3 [2/3] COPY"))
+	_, _ = writer.Write([]byte(" . .\n\x1b[32mApply complete!\x1b[0m\r\n"))
+
+	// The last line isn't reported until it's complete
+	require.Equal(t, []string{
+		"#1 [internal] load build definition",
+		"#2 [1/3] FROM node",
+		"#2 DONE 0.1s",
+		"#3 [2/3] COPY . .",
+		"Apply complete!",
+	}, lines)
+}
+
+func Test_PrefixLineWriter(t *testing.T) {
+	lines := []string{}
+	writer := NewPrefixLineWriter("api", func(line string) {
+		lines = append(lines, line)
+	})
+
+	_, _ = writer.Write([]byte("added 120 packages\n"))
+	require.Equal(t, []string{"[api] added 120 packages"}, lines)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exec
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrPtyUnsupported is returned when commands can't be run under a pseudo terminal on the current platform
+var ErrPtyUnsupported = errors.New("pseudo terminals are not supported on this platform")
+
+// The size of the pseudo terminals commands are run under. Tools wrap and truncate their output to the size of the
+// terminal, so the terminal is wide enough for most output to be written as-is.
+const (
+	ptyRows    = 40
+	ptyColumns = 160
+)
+
+// How long the output of a command is read from its terminal after the command exits. Child processes started in the
+// background keep the terminal open after the command exits, in which case the remaining output is discarded.
+const ptyDrainTimeout = 2 * time.Second
+
+// ptyOutput copies the output of a command from its pseudo terminal
+type ptyOutput struct {
+	ptmx *os.File
+	done chan struct{}
+}
+
+func newPtyOutput(ptmx *os.File, output io.Writer) *ptyOutput {
+	p := &ptyOutput{
+		ptmx: ptmx,
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(p.done)
+
+		// Reading the terminal fails once the command, and any child process using the terminal, exits
+		_, _ = io.Copy(output, ptmx)
+	}()
+
+	return p
+}
+
+// Waits for the remaining output of the command to be copied once the command exits, and closes the terminal
+func (p *ptyOutput) wait() {
+	select {
+	case <-p.done:
+	case <-time.After(ptyDrainTimeout):
+	}
+
+	_ = p.ptmx.Close()
+	<-p.done
+}
+
+// Runs the function with the file descriptor of the terminal, without putting the terminal in blocking mode
+func controlPty(ptmx *os.File, fn func(fd int) error) error {
+	rawConn, err := ptmx.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var fnErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd))
+	}); err != nil {
+		return err
+	}
+
+	return fnErr
+}
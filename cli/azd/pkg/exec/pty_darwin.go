@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build darwin
+// +build darwin
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Opens a new pseudo terminal, returning the controlling side and the terminal used by the command
+func openPty() (*os.File, *os.File, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The terminal is configured through the raw connection, since Fd would put the file in blocking mode and prevent
+	// pending reads from being interrupted when the file is closed
+	name := make([]byte, 128)
+	if err := controlPty(ptmx, func(fd int) error {
+		if err := unix.IoctlSetInt(fd, unix.TIOCPTYGRANT, 0); err != nil {
+			return fmt.Errorf("granting pseudo terminal: %w", err)
+		}
+
+		if err := unix.IoctlSetInt(fd, unix.TIOCPTYUNLK, 0); err != nil {
+			return fmt.Errorf("unlocking pseudo terminal: %w", err)
+		}
+
+		// TIOCPTYGNAME writes the name of the terminal to a buffer of 128 bytes
+		_, _, errno := syscall.Syscall(
+			syscall.SYS_IOCTL, uintptr(fd), uintptr(unix.TIOCPTYGNAME), uintptr(unsafe.Pointer(&name[0])))
+		if errno != 0 {
+			return fmt.Errorf("getting pseudo terminal name: %w", errno)
+		}
+
+		return nil
+	}); err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	if index := bytes.IndexByte(name, 0); index >= 0 {
+		name = name[:index]
+	}
+
+	tty, err := os.OpenFile(string(name), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	setPtySize(tty)
+	return ptmx, tty, nil
+}
+
+func setPtySize(tty *os.File) {
+	_ = unix.IoctlSetWinsize(int(tty.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: ptyRows, Col: ptyColumns})
+}
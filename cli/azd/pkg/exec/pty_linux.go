@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build linux
+// +build linux
+
+package exec
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Opens a new pseudo terminal, returning the controlling side and the terminal used by the command
+func openPty() (*os.File, *os.File, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The terminal is configured through the raw connection, since Fd would put the file in blocking mode and prevent
+	// pending reads from being interrupted when the file is closed
+	var index uint32
+	if err := controlPty(ptmx, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return fmt.Errorf("unlocking pseudo terminal: %w", err)
+		}
+
+		index, err = unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+		if err != nil {
+			return fmt.Errorf("getting pseudo terminal name: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	tty, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", index), os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	setPtySize(tty)
+	return ptmx, tty, nil
+}
+
+func setPtySize(tty *os.File) {
+	_ = unix.IoctlSetWinsize(int(tty.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: ptyRows, Col: ptyColumns})
+}
@@ -0,0 +1,16 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package exec
+
+import (
+	"os"
+)
+
+// Pseudo terminals are only supported on Linux and macOS, commands are run with pipes on other platforms
+func openPty() (*os.File, *os.File, error) {
+	return nil, nil, ErrPtyUnsupported
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCommandPty(t *testing.T) {
+	runner := NewCommandRunner(os.Stdin, os.Stdout, os.Stderr)
+	output := &bytes.Buffer{}
+
+	res, err := runner.Run(context.Background(), NewRunArgs(
+		"sh", "-c", "test -t 1 && echo 'stdout is a terminal'; echo 'to stderr' 1>&2",
+	).WithPty(true).WithStdOut(output))
+	require.NoError(t, err)
+	require.Equal(t, 0, res.ExitCode)
+
+	// Terminals translate new lines to carriage return and new line
+	stdout := strings.ReplaceAll(res.Stdout, "\r\n", "\n")
+	require.Equal(t, "stdout is a terminal\nto stderr\n", stdout)
+	require.Equal(t, res.Stdout, output.String())
+	require.Empty(t, res.Stderr)
+}
+
+func TestRunCommandPtyExitCode(t *testing.T) {
+	runner := NewCommandRunner(os.Stdin, os.Stdout, os.Stderr)
+
+	res, err := runner.Run(context.Background(), NewRunArgs("sh", "-c", "echo failed; exit 3").WithPty(true))
+	require.Error(t, err)
+	require.Equal(t, 3, res.ExitCode)
+	require.Contains(t, res.Stdout, "failed")
+}
@@ -11,6 +11,11 @@ type RunArgs struct {
 	Cwd  string
 	Env  []string
 
+	// Stdout will receive a copy of the text written to Stdout by
+	// the command.
+	// NOTE: RunResult.Stdout will still contain stdout output.
+	Stdout io.Writer
+
 	// Stderr will receive a copy of the text written to Stderr by
 	// the command.
 	// NOTE: RunResult.Stderr will still contain stderr output.
@@ -30,6 +35,12 @@ type RunArgs struct {
 	// When set will attach commands to std input/output
 	Interactive bool
 
+	// When set will run the command under a pseudo terminal, so tools that buffer their output or hide their progress
+	// when not writing to a terminal write their output as it happens. The stdout and stderr of the command are
+	// interleaved as they would be on a terminal, and are returned as RunResult.Stdout and copied to Stdout.
+	// Falls back to running the command without a terminal on platforms without pseudo terminals, ex) Windows.
+	Pty bool
+
 	// When set will call the command with the specified StdIn
 	StdIn io.Reader
 }
@@ -67,6 +78,12 @@ func (b RunArgs) WithInteractive(interactive bool) RunArgs {
 	return b
 }
 
+// Updates whether or not this will be run under a pseudo terminal
+func (b RunArgs) WithPty(pty bool) RunArgs {
+	b.Pty = pty
+	return b
+}
+
 // Updates whether or not this will be run in a shell
 func (b RunArgs) WithShell(useShell bool) RunArgs {
 	b.UseShell = useShell
@@ -85,6 +102,18 @@ func (b RunArgs) WithDebug(debug bool) RunArgs {
 	return b
 }
 
+// Updates the writer receiving a copy of the stdout of the command
+func (b RunArgs) WithStdOut(stdOut io.Writer) RunArgs {
+	b.Stdout = stdOut
+	return b
+}
+
+// Updates the writer receiving a copy of the stderr of the command
+func (b RunArgs) WithStdErr(stdErr io.Writer) RunArgs {
+	b.Stderr = stdErr
+	return b
+}
+
 // Updates the stdin reader that will be used while invoking the command
 func (b RunArgs) WithStdIn(stdIn io.Reader) RunArgs {
 	b.StdIn = stdIn
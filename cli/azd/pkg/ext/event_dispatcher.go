@@ -5,21 +5,44 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 )
 
+// Event is the name of an event raised by an EventDispatcher. Events of actions are raised with the 'pre' and 'post'
+// prefixes, ex) prepackage and postpackage for the package action.
 type Event string
 
+// EventHandlerFn handles an event with its arguments
 type EventHandlerFn[T any] func(ctx context.Context, args T) error
 
 var (
 	ErrInvalidEvent = errors.New("invalid event name for the current type")
+	// ErrHandlerPanic is returned when an event handler panics
+	ErrHandlerPanic = errors.New("event handler panicked")
 )
 
+// EventDispatcher dispatches the events of a type to the handlers subscribed to them. It's the extension point of the
+// project and service lifecycles: framework integrations subscribe to the events of a service, ex) prerestore, to run
+// before or after the operations of the service.
+//
+// The dispatcher guarantees that:
+//   - The handlers of an event run sequentially, in the order they were added.
+//   - All the handlers of an event run, even when a previous handler fails. The errors of the handlers are joined in
+//     the error of the event, which unwraps to the error of each handler.
+//   - A handler that panics fails with ErrHandlerPanic instead of crashing the process.
+//   - Handlers added or removed while an event is raised take effect from the next event.
+//   - With Invoke, the action only runs when the handlers of the pre event succeed, and the handlers of the post event
+//     only run when the action succeeds.
+//
+// Handlers may be added and events raised concurrently.
 type EventDispatcher[T any] struct {
+	mutex      sync.RWMutex
 	handlers   map[Event][]EventHandlerFn[T]
 	eventNames map[Event]struct{}
 }
 
+// NewEventDispatcher creates a dispatcher of the events of the actions, and of their pre and post events. A dispatcher
+// without event names accepts any event name.
 func NewEventDispatcher[T any](validEventNames ...Event) *EventDispatcher[T] {
 	eventNames := map[Event]struct{}{}
 	for _, name := range validEventNames {
@@ -40,6 +63,9 @@ func (ed *EventDispatcher[T]) AddHandler(name Event, handler EventHandlerFn[T])
 		return err
 	}
 
+	ed.mutex.Lock()
+	defer ed.mutex.Unlock()
+
 	newHandler := fmt.Sprintf("%v", handler)
 	events := ed.handlers[name]
 
@@ -51,8 +77,8 @@ func (ed *EventDispatcher[T]) AddHandler(name Event, handler EventHandlerFn[T])
 		}
 	}
 
-	events = append(events, handler)
-	ed.handlers[name] = events
+	// Copies the handlers, so the handlers of events being raised aren't modified
+	ed.handlers[name] = append(events[:len(events):len(events)], handler)
 
 	return nil
 }
@@ -63,13 +89,17 @@ func (ed *EventDispatcher[T]) RemoveHandler(name Event, handler EventHandlerFn[T
 		return err
 	}
 
+	ed.mutex.Lock()
+	defer ed.mutex.Unlock()
+
 	newHandler := fmt.Sprintf("%v", handler)
 	events := ed.handlers[name]
 	for i, ref := range events {
 		existingHandler := fmt.Sprintf("%v", ref)
 
 		if newHandler == existingHandler {
-			ed.handlers[name] = append(events[:i], events[i+1:]...)
+			remaining := make([]EventHandlerFn[T], 0, len(events)-1)
+			ed.handlers[name] = append(append(remaining, events[:i]...), events[i+1:]...)
 			return nil
 		}
 	}
@@ -83,25 +113,19 @@ func (ed *EventDispatcher[T]) RaiseEvent(ctx context.Context, name Event, eventA
 		return err
 	}
 
-	handlerErrors := []error{}
+	ed.mutex.RLock()
 	handlers := ed.handlers[name]
+	ed.mutex.RUnlock()
 
-	// TODO: Opportunity to dispatch these event handlers in parallel if needed
+	handlerErrors := handlerErrors{}
 	for _, handler := range handlers {
-		err := handler(ctx, eventArgs)
-		if err != nil {
+		if err := runHandler(ctx, handler, eventArgs); err != nil {
 			handlerErrors = append(handlerErrors, err)
 		}
 	}
 
-	// Build final error string if their are any failures
 	if len(handlerErrors) > 0 {
-		lines := make([]string, len(handlerErrors))
-		for i, err := range handlerErrors {
-			lines[i] = err.Error()
-		}
-
-		return errors.New(strings.Join(lines, ","))
+		return handlerErrors
 	}
 
 	return nil
@@ -109,6 +133,19 @@ func (ed *EventDispatcher[T]) RaiseEvent(ctx context.Context, name Event, eventA
 
 // Invokes an action and raises an event before and after the action
 func (ed *EventDispatcher[T]) Invoke(ctx context.Context, name Event, eventArgs T, action InvokeFn) error {
+	return ed.InvokeWithResult(ctx, name, eventArgs, func(eventArgs T) (T, error) {
+		return eventArgs, action()
+	})
+}
+
+// Invokes an action and raises an event before and after the action. The action gets the arguments of the pre event and
+// returns the arguments of the post event, ex) the arguments with the result of the action.
+func (ed *EventDispatcher[T]) InvokeWithResult(
+	ctx context.Context,
+	name Event,
+	eventArgs T,
+	action func(eventArgs T) (T, error),
+) error {
 	if err := ed.validateEvent(name); err != nil {
 		return err
 	}
@@ -120,11 +157,12 @@ func (ed *EventDispatcher[T]) Invoke(ctx context.Context, name Event, eventArgs
 		return fmt.Errorf("failed invoking event handlers for 'pre%s', %w", name, err)
 	}
 
-	if err := action(); err != nil {
+	postEventArgs, err := action(eventArgs)
+	if err != nil {
 		return fmt.Errorf("failing invoking action '%s', %w", name, err)
 	}
 
-	if err := ed.RaiseEvent(ctx, postEventName, eventArgs); err != nil {
+	if err := ed.RaiseEvent(ctx, postEventName, postEventArgs); err != nil {
 		return fmt.Errorf("failed invoking event handlers for 'post%s', %w", name, err)
 	}
 
@@ -143,3 +181,30 @@ func (ed *EventDispatcher[T]) validateEvent(name Event) error {
 
 	return nil
 }
+
+// Runs the handler, converting panics to errors
+func runHandler[T any](ctx context.Context, handler EventHandlerFn[T], eventArgs T) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, recovered)
+		}
+	}()
+
+	return handler(ctx, eventArgs)
+}
+
+// The errors of the handlers of an event
+type handlerErrors []error
+
+func (e handlerErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+
+	return strings.Join(lines, ",")
+}
+
+func (e handlerErrors) Unwrap() []error {
+	return e
+}
@@ -2,6 +2,7 @@ package ext
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -56,6 +57,95 @@ func Test_Invalid_Event_Names(t *testing.T) {
 	}
 }
 
-type testEventArgs struct{}
+func Test_RaiseEvent_Ordering_And_Errors(t *testing.T) {
+	errFirst := errors.New("first failed")
+	calls := []string{}
+
+	ed := NewEventDispatcher[testEventArgs](testEvent)
+	require.NoError(t, ed.AddHandler("pretest", func(ctx context.Context, args testEventArgs) error {
+		calls = append(calls, "first")
+		return errFirst
+	}))
+	require.NoError(t, ed.AddHandler("pretest", func(ctx context.Context, args testEventArgs) error {
+		calls = append(calls, "second")
+		panic("boom")
+	}))
+	require.NoError(t, ed.AddHandler("pretest", func(ctx context.Context, args testEventArgs) error {
+		calls = append(calls, "third")
+		return nil
+	}))
+
+	err := ed.RaiseEvent(context.Background(), "pretest", testEventArgs{})
+	require.Equal(t, []string{"first", "second", "third"}, calls)
+	require.ErrorIs(t, err, errFirst)
+	require.ErrorIs(t, err, ErrHandlerPanic)
+}
+
+func Test_InvokeWithResult(t *testing.T) {
+	t.Run("PostEventGetsResult", func(t *testing.T) {
+		var postArgs testEventArgs
+		ed := NewEventDispatcher[testEventArgs](testEvent)
+		require.NoError(t, ed.AddHandler("posttest", func(ctx context.Context, args testEventArgs) error {
+			postArgs = args
+			return nil
+		}))
+
+		err := ed.InvokeWithResult(
+			context.Background(),
+			testEvent,
+			testEventArgs{},
+			func(args testEventArgs) (testEventArgs, error) {
+				args.result = "done"
+				return args, nil
+			},
+		)
+		require.NoError(t, err)
+		require.Equal(t, "done", postArgs.result)
+	})
+
+	t.Run("PreEventFailureSkipsAction", func(t *testing.T) {
+		errPre := errors.New("pre failed")
+		actionCalled := false
+		postCalled := false
+
+		ed := NewEventDispatcher[testEventArgs](testEvent)
+		require.NoError(t, ed.AddHandler("pretest", func(ctx context.Context, args testEventArgs) error {
+			return errPre
+		}))
+		require.NoError(t, ed.AddHandler("posttest", func(ctx context.Context, args testEventArgs) error {
+			postCalled = true
+			return nil
+		}))
+
+		err := ed.Invoke(context.Background(), testEvent, testEventArgs{}, func() error {
+			actionCalled = true
+			return nil
+		})
+		require.ErrorIs(t, err, errPre)
+		require.False(t, actionCalled)
+		require.False(t, postCalled)
+	})
+
+	t.Run("ActionFailureSkipsPostEvent", func(t *testing.T) {
+		errAction := errors.New("action failed")
+		postCalled := false
+
+		ed := NewEventDispatcher[testEventArgs](testEvent)
+		require.NoError(t, ed.AddHandler("posttest", func(ctx context.Context, args testEventArgs) error {
+			postCalled = true
+			return nil
+		}))
+
+		err := ed.Invoke(context.Background(), testEvent, testEventArgs{}, func() error {
+			return errAction
+		})
+		require.ErrorIs(t, err, errAction)
+		require.False(t, postCalled)
+	})
+}
+
+type testEventArgs struct {
+	result string
+}
 
 const testEvent Event = "test"
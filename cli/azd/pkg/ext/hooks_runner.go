@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -25,6 +26,8 @@ type HooksRunner struct {
 	cwd           string
 	hooks         map[string]*HookConfig
 	env           *environment.Environment
+	envVars       []string
+	envScope      *exec.EnvScope
 }
 
 // NewHooks creates a new instance of CommandHooks
@@ -56,6 +59,19 @@ func NewHooksRunner(
 	}
 }
 
+// WithEnvVars sets additional environment variables, in the form 'key=value', that are exposed to the hook scripts
+func (h *HooksRunner) WithEnvVars(envVars ...string) *HooksRunner {
+	h.envVars = append(h.envVars, envVars...)
+	return h
+}
+
+// WithEnvScope restricts the environment values and the variables of the environment of azd exposed to the hook
+// scripts to the scope. The additional environment variables set with WithEnvVars are always exposed.
+func (h *HooksRunner) WithEnvScope(scope exec.EnvScope) *HooksRunner {
+	h.envScope = &scope
+	return h
+}
+
 // Invokes an action run runs any registered pre or post script hooks for the specified command.
 func (h *HooksRunner) Invoke(ctx context.Context, commands []string, actionFn InvokeFn) error {
 	err := h.RunHooks(ctx, HookTypePre, commands...)
@@ -105,11 +121,26 @@ func (h *HooksRunner) GetScript(hookConfig *HookConfig) (tools.Script, error) {
 		return nil, err
 	}
 
+	envVars := h.env.Environ()
+	if h.envScope != nil {
+		envVars = h.envScope.Filter(envVars)
+	}
+	envVars = append(envVars, h.envVars...)
+
+	// The working directory of the hook is relative to the project or service
+	cwd := h.cwd
+	if hookConfig.Cwd != "" {
+		cwd = hookConfig.Cwd
+		if !filepath.IsAbs(cwd) {
+			cwd = filepath.Join(h.cwd, cwd)
+		}
+	}
+
 	switch hookConfig.Shell {
 	case ShellTypeBash:
-		return bash.NewBashScript(h.commandRunner, h.cwd, h.env.Environ()), nil
+		return bash.NewBashScript(h.commandRunner, cwd, envVars), nil
 	case ShellTypePowershell:
-		return powershell.NewPowershellScript(h.commandRunner, h.cwd, h.env.Environ()), nil
+		return powershell.NewPowershellScript(h.commandRunner, cwd, envVars), nil
 	default:
 		return nil, fmt.Errorf(
 			"shell type '%s' is not a valid option. Only 'sh' and 'pwsh' are supported",
@@ -142,8 +173,21 @@ func (h *HooksRunner) execHook(ctx context.Context, hookConfig *HookConfig) erro
 		)
 	}
 
+	if h.envScope != nil {
+		ctx = exec.WithEnvScope(ctx, *h.envScope)
+	}
+
 	log.Printf("Executing script '%s'\n", hookConfig.path)
-	res, err := script.Execute(ctx, hookConfig.path, scriptInteractive)
+	options := tools.ExecOptions{Interactive: scriptInteractive}
+
+	// Non interactive hooks run under a pseudo terminal in an interactive terminal, so their output is written to the
+	// console as it happens, with the colors and progress of the tools they run
+	if consoleInteractive && !scriptInteractive {
+		options.Pty = true
+		options.StdOut = h.console.Handles().Stdout
+	}
+
+	res, err := script.Execute(ctx, hookConfig.path, options)
 	if err != nil {
 		execErr := fmt.Errorf(
 			"'%s' hook failed with exit code: '%d', Path: '%s'. : %w",
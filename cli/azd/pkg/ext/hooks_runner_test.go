@@ -3,6 +3,7 @@ package ext
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -62,6 +63,8 @@ func Test_Hooks_Execute(t *testing.T) {
 			require.Equal(t, cwd, args.Cwd)
 			require.Equal(t, env.Environ(), args.Env)
 			require.Equal(t, false, args.Interactive)
+			require.True(t, args.Pty)
+			require.Equal(t, mockContext.Console.Handles().Stdout, args.Stdout)
 
 			return exec.NewRunResult(0, "", ""), nil
 		})
@@ -114,6 +117,7 @@ func Test_Hooks_Execute(t *testing.T) {
 			require.Equal(t, cwd, args.Cwd)
 			require.Equal(t, env.Environ(), args.Env)
 			require.Equal(t, true, args.Interactive)
+			require.False(t, args.Pty)
 
 			return exec.NewRunResult(0, "", ""), nil
 		})
@@ -149,6 +153,45 @@ func Test_Hooks_Execute(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("CwdAndEnvScope", func(t *testing.T) {
+		// The environment values are reloaded from the .env file of the working directory before hooks run
+		envPath := filepath.Join(cwd, ".env")
+		err := os.WriteFile(envPath, []byte("a=apple\nb=banana\n"), osutil.PermissionFile)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.Remove(envPath) })
+
+		ran := false
+		scopedHooks := map[string]*HookConfig{
+			"prescoped": {
+				Shell: ShellTypeBash,
+				Run:   "scripts/precommand.sh",
+				Cwd:   "scripts",
+			},
+		}
+
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "precommand.sh")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			ran = true
+			// The script path stays relative to the project when running in another directory
+			require.Equal(t, filepath.ToSlash(filepath.Join(cwd, "scripts", "precommand.sh")), args.Args[0])
+			require.Equal(t, filepath.Join(cwd, "scripts"), args.Cwd)
+			require.Equal(t, []string{"a=apple", "EXTRA=value"}, args.Env)
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		hooksManager := NewHooksManager(cwd)
+		runner := NewHooksRunner(hooksManager, mockContext.CommandRunner, mockContext.Console, cwd, scopedHooks, env).
+			WithEnvVars("EXTRA=value").
+			WithEnvScope(exec.EnvScope{Allow: []string{"a"}})
+		err = runner.RunHooks(*mockContext.Context, HookTypePre, "scoped")
+
+		require.True(t, ran)
+		require.NoError(t, err)
+	})
+
 	t.Run("InvokeAction", func(t *testing.T) {
 		ranPreHook := false
 		ranPostHook := false
@@ -63,6 +63,9 @@ type HookConfig struct {
 	ContinueOnError bool `yaml:"continueOnError,omitempty"`
 	// When set to true will bind the stdin, stdout & stderr to the running console
 	Interactive bool `yaml:"interactive,omitempty"`
+	// The working directory of the script, relative to the project or service. Defaults to the project or service
+	// directory. Script paths are always relative to the project or service.
+	Cwd string `yaml:"cwd,omitempty"`
 	// When running on windows use this override config
 	Windows *HookConfig `yaml:"windows,omitempty"`
 	// When running on linux/macos use this override config
@@ -82,7 +85,7 @@ func (hc *HookConfig) validate() error {
 	hc.Run = strings.ReplaceAll(hc.Run, "/", string(os.PathSeparator))
 
 	scriptPath := hc.Run
-	if hc.cwd != "" {
+	if hc.cwd != "" && !filepath.IsAbs(hc.Run) {
 		scriptPath = filepath.Join(hc.cwd, hc.Run)
 	}
 
@@ -90,6 +93,10 @@ func (hc *HookConfig) validate() error {
 	if err == nil && !stats.IsDir() {
 		hc.location = ScriptLocationPath
 		hc.path = hc.Run
+		// The script runs in another directory, so the path must not be relative to the working directory
+		if hc.Cwd != "" {
+			hc.path = scriptPath
+		}
 	} else {
 		hc.location = ScriptLocationInline
 		hc.script = hc.Run
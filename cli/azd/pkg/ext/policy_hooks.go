@@ -0,0 +1,75 @@
+package ext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The command name matching any azd command, ex) the 'pre*' policy hook runs before every command
+const HookCommandWildcard = "*"
+
+// PolicyHooksConfig is the machine level hooks configuration managed by an organization.
+// Policy hooks run around azd commands in addition to the hooks of the project, which lets platform teams enforce
+// guardrails without changing templates.
+type PolicyHooksConfig struct {
+	Hooks map[string]*HookConfig `yaml:"hooks"`
+}
+
+// Gets the path of the machine level policy hooks file. The file is stored in a machine wide location that is
+// typically only writable by administrators, ex) /etc/azd/hooks.yaml. The location can't be overridden by users.
+func PolicyHooksFilePath() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+
+		return filepath.Join(programData, "azd", "hooks.yaml")
+	}
+
+	return filepath.Join("/etc", "azd", "hooks.yaml")
+}
+
+// Loads the policy hooks from the specified file. Returns nil when the file doesn't exist.
+// Hook scripts referenced by relative paths are resolved from the directory of the file.
+func LoadPolicyHooks(filePath string) (*PolicyHooksConfig, error) {
+	contents, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading policy hooks file: %w", err)
+	}
+
+	var config PolicyHooksConfig
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("parsing policy hooks file '%s': %w", filePath, err)
+	}
+
+	for _, hookConfig := range config.Hooks {
+		resolvePolicyScriptPath(filepath.Dir(filePath), hookConfig)
+	}
+
+	return &config, nil
+}
+
+// Policy hooks run from the project directory, so scripts next to the policy file are referenced by absolute path
+func resolvePolicyScriptPath(dir string, hookConfig *HookConfig) {
+	if hookConfig == nil {
+		return
+	}
+
+	if hookConfig.Run != "" && !filepath.IsAbs(hookConfig.Run) {
+		scriptPath := filepath.Join(dir, hookConfig.Run)
+		if stats, err := os.Stat(scriptPath); err == nil && !stats.IsDir() {
+			hookConfig.Run = scriptPath
+		}
+	}
+
+	resolvePolicyScriptPath(dir, hookConfig.Windows)
+	resolvePolicyScriptPath(dir, hookConfig.Posix)
+}
@@ -0,0 +1,11 @@
+package graphsdk
+
+// The request to check the membership of a directory object in groups
+type CheckMemberGroupsRequest struct {
+	GroupIds []string `json:"groupIds"`
+}
+
+// The ids of the groups the directory object is a member of
+type CheckMemberGroupsResponse struct {
+	Value []string `json:"value"`
+}
@@ -0,0 +1,55 @@
+package graphsdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+type DirectoryObjectItemRequestBuilder struct {
+	*EntityItemRequestBuilder[DirectoryObjectItemRequestBuilder]
+}
+
+func NewDirectoryObjectItemRequestBuilder(client *GraphClient, id string) *DirectoryObjectItemRequestBuilder {
+	builder := &DirectoryObjectItemRequestBuilder{}
+	builder.EntityItemRequestBuilder = newEntityItemRequestBuilder(builder, client, id)
+
+	return builder
+}
+
+// Gets the groups of the specified groups which the directory object, ex) a user or a service principal, is a member of,
+// directly or transitively
+func (b *DirectoryObjectItemRequestBuilder) CheckMemberGroups(ctx context.Context, groupIds []string) ([]string, error) {
+	req, err := b.createRequest(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/directoryObjects/%s/checkMemberGroups", b.client.host, b.id),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating request: %w", err)
+	}
+
+	err = SetHttpRequestBody(req, CheckMemberGroupsRequest{GroupIds: groupIds})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := b.client.pipeline.Do(req)
+	if err != nil {
+		return nil, httputil.HandleRequestError(res, err)
+	}
+
+	if !runtime.HasStatusCode(res, http.StatusOK) {
+		return nil, runtime.NewResponseError(res)
+	}
+
+	response, err := httputil.ReadRawResponse[CheckMemberGroupsResponse](res)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Value, nil
+}
@@ -0,0 +1,38 @@
+package graphsdk_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockgraphsdk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMemberGroups(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockgraphsdk.RegisterCheckMemberGroupsMock(mockContext, http.StatusOK, "user1", []string{"group1"})
+
+		client, err := mockgraphsdk.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		actual, err := client.DirectoryObjectById("user1").CheckMemberGroups(
+			*mockContext.Context, []string{"group1", "group2"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"group1"}, actual)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockgraphsdk.RegisterCheckMemberGroupsMock(mockContext, http.StatusNotFound, "user1", nil)
+
+		client, err := mockgraphsdk.CreateGraphClient(mockContext)
+		require.NoError(t, err)
+
+		actual, err := client.DirectoryObjectById("user1").CheckMemberGroups(*mockContext.Context, []string{"group1"})
+		require.Error(t, err)
+		require.Nil(t, actual)
+	})
+}
@@ -51,3 +51,9 @@ func (c *GraphClient) ServicePrincipals() *ServicePrincipalListRequestBuilder {
 func (c *GraphClient) ServicePrincipalById(id string) *ServicePrincipalItemRequestBuilder {
 	return NewServicePrincipalItemRequestBuilder(c, id)
 }
+
+// DirectoryObjects
+
+func (c *GraphClient) DirectoryObjectById(id string) *DirectoryObjectItemRequestBuilder {
+	return NewDirectoryObjectItemRequestBuilder(c, id)
+}
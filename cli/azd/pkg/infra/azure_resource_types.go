@@ -32,6 +32,10 @@ const (
 	AzureResourceTypeContainerRegistry       AzureResourceType = "Microsoft.ContainerRegistry/registries"
 	AzureResourceTypeManagedCluster          AzureResourceType = "Microsoft.ContainerService/managedClusters"
 	AzureResourceTypeAgentPool               AzureResourceType = "Microsoft.ContainerService/managedClusters/agentPools"
+	AzureResourceTypeVirtualMachine          AzureResourceType = "Microsoft.Compute/virtualMachines"
+	AzureResourceTypeVirtualMachineScaleSet  AzureResourceType = "Microsoft.Compute/virtualMachineScaleSets"
+	AzureResourceTypeBatchAccount            AzureResourceType = "Microsoft.Batch/batchAccounts"
+	AzureResourceTypeIotHub                  AzureResourceType = "Microsoft.Devices/IotHubs"
 )
 
 const resourceLevelSeparator = "/"
@@ -87,6 +91,14 @@ func GetResourceTypeDisplayName(resourceType AzureResourceType) string {
 		return "AKS Managed Cluster"
 	case AzureResourceTypeAgentPool:
 		return "AKS Agent Pool"
+	case AzureResourceTypeVirtualMachine:
+		return "Virtual machine"
+	case AzureResourceTypeVirtualMachineScaleSet:
+		return "Virtual machine scale set"
+	case AzureResourceTypeBatchAccount:
+		return "Batch account"
+	case AzureResourceTypeIotHub:
+		return "IoT Hub"
 	}
 
 	return ""
@@ -117,6 +117,17 @@ func (p *BicepProvider) State(
 		})
 }
 
+// Compiles the Bicep module to a canonical JSON snapshot of the ARM template. The metadata of the generator of the
+// template, the version of Bicep and the hash of the template, is removed so snapshots don't change with Bicep.
+func (p *BicepProvider) Snapshot(ctx context.Context) ([]byte, error) {
+	rawTemplate, _, err := p.compileBicep(ctx, p.modulePath())
+	if err != nil {
+		return nil, err
+	}
+
+	return CanonicalJson(rawTemplate, "metadata._generator")
+}
+
 // Plans the infrastructure provisioning
 func (p *BicepProvider) Plan(
 	ctx context.Context,
@@ -186,7 +197,8 @@ func (p *BicepProvider) Deploy(
 			// Report incremental progress
 			go func() {
 				resourceManager := infra.NewAzureResourceManager(p.azCli)
-				progressDisplay := NewProvisioningProgressDisplay(resourceManager, p.console, scope)
+				progressDisplay := NewProvisioningProgressDisplay(
+					resourceManager, p.console, scope, LastDeploymentDuration(p.env))
 				// Make initial delay shorter to be more responsive in displaying initial progress
 				initialDelay := 3 * time.Second
 				regularDelay := 10 * time.Second
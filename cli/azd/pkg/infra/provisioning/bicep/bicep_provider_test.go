@@ -77,6 +77,25 @@ func TestBicepPlan(t *testing.T) {
 	)
 }
 
+func TestBicepSnapshot(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	preparePlanningMocks(mockContext)
+	infraProvider := createBicepProvider(t, mockContext)
+
+	snapshot, err := infraProvider.Snapshot(*mockContext.Context)
+	require.NoError(t, err)
+
+	var template map[string]any
+	require.NoError(t, json.Unmarshal(snapshot, &template))
+	require.Contains(t, template, "outputs")
+	require.NotContains(t, string(snapshot), "_generator")
+
+	// Snapshots of the same template are equal
+	again, err := infraProvider.Snapshot(*mockContext.Context)
+	require.NoError(t, err)
+	require.Equal(t, snapshot, again)
+}
+
 const paramsArmJson = `{
 	"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
 	"contentVersion": "1.0.0.0",
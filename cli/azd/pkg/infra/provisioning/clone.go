@@ -0,0 +1,103 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"golang.org/x/exp/slices"
+)
+
+// The path of the names of the outputs written to the environment by provisioning, in the configuration of the environment
+const outputNamesConfigPath = "provision.outputs"
+
+// The values written to the environment by provisioning, even when the environment doesn't record its outputs, ex) when
+// it was provisioned by an earlier version of azd
+var wellKnownOutputNames = []string{
+	environment.ContainerRegistryEndpointEnvVarName,
+	environment.ContainerRegistryConnectedEndpointsEnvVarName,
+	environment.AksClusterEnvVarName,
+	environment.ResourceGroupEnvVarName,
+}
+
+// Records the names of the outputs written to the environment, in addition to the names recorded previously, ex) by the
+// provisioning of the infrastructure of another service
+func recordOutputNames(env *environment.Environment, outputs map[string]OutputParameter) error {
+	names := OutputNames(env)
+	for key := range outputs {
+		if !slices.Contains(names, key) {
+			names = append(names, key)
+		}
+	}
+
+	slices.Sort(names)
+	if err := env.Config.Set(outputNamesConfigPath, names); err != nil {
+		return fmt.Errorf("recording provisioning outputs: %w", err)
+	}
+
+	return nil
+}
+
+// OutputNames gets the names of the values written to the environment by provisioning
+func OutputNames(env *environment.Environment) []string {
+	names := []string{}
+
+	value, has := env.Config.Get(outputNamesConfigPath)
+	if !has {
+		return names
+	}
+
+	switch recorded := value.(type) {
+	case []string:
+		names = append(names, recorded...)
+	case []any:
+		// Lists are loaded from the configuration file as lists of any
+		for _, name := range recorded {
+			if name, ok := name.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// CopyInputs copies the inputs of the provisioning of the source environment to the target environment, ex) its location,
+// subscription, infrastructure parameters and the values set by the user. The outputs of provisioning and the values set
+// by deployments, ex) SERVICE_API_IMAGE_NAME, aren't copied, so the target environment never references the resources of
+// the source environment.
+func CopyInputs(source *environment.Environment, target *environment.Environment) error {
+	excluded := append(OutputNames(source), wellKnownOutputNames...)
+	excluded = append(excluded, environment.EnvNameEnvVarName)
+
+	for key, value := range source.Values {
+		if slices.Contains(excluded, key) || strings.HasPrefix(key, "SERVICE_") {
+			continue
+		}
+
+		target.Values[key] = value
+	}
+
+	// The configuration holds the parameters of the infrastructure prompted to the user, copied without the outputs
+	configJson, err := json.Marshal(source.Config.Raw())
+	if err != nil {
+		return fmt.Errorf("copying environment configuration: %w", err)
+	}
+
+	targetConfig, err := config.Parse(configJson)
+	if err != nil {
+		return fmt.Errorf("copying environment configuration: %w", err)
+	}
+
+	if err := targetConfig.Unset(outputNamesConfigPath); err != nil {
+		return fmt.Errorf("copying environment configuration: %w", err)
+	}
+
+	target.Config = targetConfig
+	return nil
+}
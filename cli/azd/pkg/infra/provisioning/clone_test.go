@@ -0,0 +1,44 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CopyInputs(t *testing.T) {
+	source := environment.EphemeralWithValues("prod", map[string]string{
+		environment.LocationEnvVarName:                  "westus2",
+		environment.SubscriptionIdEnvVarName:            "SUBSCRIPTION_ID",
+		environment.ContainerRegistryEndpointEnvVarName: "crprod.azurecr.io",
+		"SERVICE_API_IMAGE_NAME":                        "crprod.azurecr.io/app/api:azd-deploy-1",
+		"CUSTOM_SETTING":                                "value",
+	})
+	require.NoError(t, source.Config.Set("infra.parameters.sku", "P1"))
+
+	err := UpdateEnvironment(source, map[string]OutputParameter{
+		"API_BASE_URL": {Type: ParameterTypeString, Value: "https://api.contoso.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"API_BASE_URL"}, OutputNames(source))
+
+	target := environment.EphemeralWithValues("sandbox", nil)
+	err = CopyInputs(source, target)
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{
+		environment.EnvNameEnvVarName:        "sandbox",
+		environment.LocationEnvVarName:       "westus2",
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		"CUSTOM_SETTING":                     "value",
+	}, target.Values)
+
+	sku, has := target.Config.Get("infra.parameters.sku")
+	require.True(t, has)
+	require.Equal(t, "P1", sku)
+	require.Empty(t, OutputNames(target))
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
 	"github.com/azure/azure-dev/cli/azd/pkg/azureutil"
@@ -89,15 +90,27 @@ func (m *Manager) Deploy(ctx context.Context, plan *DeploymentPlan, scope infra.
 	}
 
 	// Apply the infrastructure deployment
+	startTime := time.Now()
 	deployResult, err := m.deploy(ctx, location, plan, scope)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := recordDeploymentDuration(m.env, time.Since(startTime)); err != nil {
+		return nil, err
+	}
+
 	if err := UpdateEnvironment(m.env, deployResult.Deployment.Outputs); err != nil {
 		return nil, fmt.Errorf("updating environment with deployment outputs: %w", err)
 	}
 
+	// The environment is saved with the outputs of the deployment, if any
+	if len(deployResult.Deployment.Outputs) == 0 {
+		if err := m.env.Save(); err != nil {
+			return nil, fmt.Errorf("saving environment: %w", err)
+		}
+	}
+
 	return deployResult, nil
 }
 
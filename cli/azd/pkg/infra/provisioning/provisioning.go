@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
@@ -31,6 +32,10 @@ func UpdateEnvironment(env *environment.Environment, outputs map[string]OutputPa
 			}
 		}
 
+		if err := recordOutputNames(env, outputs); err != nil {
+			return err
+		}
+
 		if err := env.Save(); err != nil {
 			return fmt.Errorf("writing environment: %w", err)
 		}
@@ -39,6 +44,37 @@ func UpdateEnvironment(env *environment.Environment, outputs map[string]OutputPa
 	return nil
 }
 
+// The path of the duration of the last provisioning of the environment in seconds, in the configuration of the environment
+const lastDurationConfigPath = "provision.lastDuration"
+
+// Records the duration of the provisioning of the environment, which estimates the duration of the next provisioning
+func recordDeploymentDuration(env *environment.Environment, duration time.Duration) error {
+	if err := env.Config.Set(lastDurationConfigPath, int64(duration.Seconds())); err != nil {
+		return fmt.Errorf("recording provisioning duration: %w", err)
+	}
+
+	return nil
+}
+
+// LastDeploymentDuration gets the duration of the last provisioning of the environment, or zero when the environment
+// wasn't provisioned before
+func LastDeploymentDuration(env *environment.Environment) time.Duration {
+	value, has := env.Config.Get(lastDurationConfigPath)
+	if !has {
+		return 0
+	}
+
+	// Numbers are loaded from the configuration file as floats
+	switch seconds := value.(type) {
+	case int64:
+		return time.Duration(seconds) * time.Second
+	case float64:
+		return time.Duration(seconds * float64(time.Second))
+	default:
+		return 0
+	}
+}
+
 // Copies the an input parameters file templateFilePath to inputFilePath after replacing environment variable references in
 // the contents```
 func CreateInputParametersFile(templateFilePath string, inputFilePath string, envValues map[string]string) error {
@@ -5,6 +5,7 @@ package provisioning
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/url"
@@ -13,6 +14,8 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/input"
 	"github.com/azure/azure-dev/cli/azd/pkg/output"
@@ -32,18 +35,27 @@ type ProvisioningProgressDisplay struct {
 	resourceManager  infra.ResourceManager
 	console          input.Console
 	scope            infra.Scope
+	// The time the deployment started
+	startTime time.Time
+	// The estimated duration of the deployment, zero when unknown
+	estimatedDuration time.Duration
 }
 
+// NewProvisioningProgressDisplay creates the progress display of a deployment. The estimated duration of the deployment,
+// ex) the duration of the previous provisioning, is reported by the heartbeats of JSON output mode. Zero when unknown.
 func NewProvisioningProgressDisplay(
 	rm infra.ResourceManager,
 	console input.Console,
 	scope infra.Scope,
+	estimatedDuration time.Duration,
 ) ProvisioningProgressDisplay {
 	return ProvisioningProgressDisplay{
-		createdResources: map[string]bool{},
-		scope:            scope,
-		resourceManager:  rm,
-		console:          console,
+		createdResources:  map[string]bool{},
+		scope:             scope,
+		resourceManager:   rm,
+		console:           console,
+		startTime:         time.Now(),
+		estimatedDuration: estimatedDuration,
 	}
 }
 
@@ -61,6 +73,7 @@ func (display *ProvisioningProgressDisplay) ReportProgress(
 		if err != nil {
 			// Return default progress
 			log.Printf("error while reporting progress: %s", err.Error())
+			display.reportHeartbeat(ctx, nil)
 			return &progress, nil
 		}
 
@@ -115,9 +128,75 @@ func (display *ProvisioningProgressDisplay) ReportProgress(
 	})
 
 	display.logNewlyCreatedResources(ctx, newlyDeployedResources, runningDeployments)
+	display.reportHeartbeat(ctx, operations)
 	return &progress, nil
 }
 
+// Writes the heartbeat of the deployment with the state of its operations in JSON output mode, so orchestrators know the
+// deployment is running
+func (display *ProvisioningProgressDisplay) reportHeartbeat(
+	ctx context.Context,
+	operations []*armresources.DeploymentOperation,
+) {
+	formatter := display.console.GetFormatter()
+	if formatter == nil || formatter.Kind() != output.JsonFormat {
+		return
+	}
+
+	event, err := json.Marshal(contracts.EventEnvelope{
+		Type:      contracts.ProvisioningProgressEventDataType,
+		Timestamp: time.Now(),
+		Data:      display.heartbeat(time.Since(display.startTime), operations),
+	})
+	if err != nil {
+		log.Printf("failed marshaling provisioning progress: %v", err)
+		return
+	}
+
+	fmt.Fprintln(display.console.GetWriter(), string(event))
+}
+
+// Creates the heartbeat of the deployment from its operations and the time elapsed since it started
+func (display *ProvisioningProgressDisplay) heartbeat(
+	elapsed time.Duration,
+	operations []*armresources.DeploymentOperation,
+) contracts.ProvisioningProgress {
+	progress := contracts.ProvisioningProgress{
+		DeploymentName:  display.scope.Name(),
+		ElapsedSeconds:  int64(elapsed.Seconds()),
+		OperationCounts: map[string]int{},
+		Operations:      []contracts.ProvisioningOperation{},
+	}
+
+	if display.estimatedDuration > 0 {
+		remaining := int64((display.estimatedDuration - elapsed).Seconds())
+		if remaining < 0 {
+			// The deployment takes longer than the previous one, its completion can't be estimated any further
+			remaining = 0
+		}
+
+		progress.EstimatedRemainingSeconds = &remaining
+	}
+
+	for _, operation := range operations {
+		properties := operation.Properties
+		if properties == nil || properties.TargetResource == nil || properties.ProvisioningState == nil {
+			continue
+		}
+
+		progress.OperationCounts[*properties.ProvisioningState]++
+		progress.Operations = append(progress.Operations, contracts.ProvisioningOperation{
+			ResourceType:      convert.ToValueWithDefault(properties.TargetResource.ResourceType, ""),
+			ResourceName:      convert.ToValueWithDefault(properties.TargetResource.ResourceName, ""),
+			ProvisioningState: *properties.ProvisioningState,
+			Duration:          convert.ToValueWithDefault(properties.Duration, ""),
+			Timestamp:         properties.Timestamp,
+		})
+	}
+
+	return progress
+}
+
 func (display *ProvisioningProgressDisplay) logNewlyCreatedResources(
 	ctx context.Context,
 	resources []*armresources.DeploymentOperation,
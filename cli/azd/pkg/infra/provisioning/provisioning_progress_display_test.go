@@ -16,7 +16,11 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/contracts"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/stretchr/testify/assert"
@@ -117,7 +121,7 @@ func TestReportProgress(t *testing.T) {
 	startTime := time.Now()
 	outputLength := 0
 	mockResourceManager := mockResourceManager{}
-	progressDisplay := NewProvisioningProgressDisplay(&mockResourceManager, mockContext.Console, scope)
+	progressDisplay := NewProvisioningProgressDisplay(&mockResourceManager, mockContext.Console, scope, 0)
 	progressReport, _ := progressDisplay.ReportProgress(*mockContext.Context, &startTime)
 	outputLength++
 	assert.Len(t, mockContext.Console.Output(), outputLength)
@@ -129,3 +133,56 @@ func TestReportProgress(t *testing.T) {
 	assert.Len(t, mockContext.Console.Output(), outputLength)
 	assert.Equal(t, "Provisioning Azure resources", progressReport.Message)
 }
+
+func TestReportProgress_JsonHeartbeat(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+
+	scope := infra.NewSubscriptionScope(azCli, "eastus2", "SUBSCRIPTION_ID", "DEPLOYMENT_NAME")
+	mockAzDeploymentShow(t, *mockContext)
+
+	var buf bytes.Buffer
+	console := input.NewConsole(
+		true, false, &buf, input.ConsoleHandles{Stdout: &buf}, &output.JsonFormatter{}, nil)
+
+	startTime := time.Now()
+	mockResourceManager := mockResourceManager{}
+	mockResourceManager.AddInProgressOperation()
+	mockResourceManager.AddInProgressOperation()
+	mockResourceManager.MarkComplete(0)
+
+	progressDisplay := NewProvisioningProgressDisplay(&mockResourceManager, console, scope, 10*time.Minute)
+	_, err := progressDisplay.ReportProgress(*mockContext.Context, &startTime)
+	require.NoError(t, err)
+
+	var heartbeat *contracts.ProvisioningProgress
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event struct {
+			Type contracts.EventDataType         `json:"type"`
+			Data *contracts.ProvisioningProgress `json:"data"`
+		}
+		if json.Unmarshal([]byte(line), &event) == nil && event.Type == contracts.ProvisioningProgressEventDataType {
+			heartbeat = event.Data
+		}
+	}
+
+	require.NotNil(t, heartbeat)
+	require.Equal(t, "DEPLOYMENT_NAME", heartbeat.DeploymentName)
+	require.Equal(t, map[string]int{succeededProvisioningState: 1, "In Progress": 1}, heartbeat.OperationCounts)
+	require.Len(t, heartbeat.Operations, 2)
+	require.Equal(t, "website-resource-name-0", heartbeat.Operations[0].ResourceName)
+	require.NotNil(t, heartbeat.EstimatedRemainingSeconds)
+	require.InDelta(t, 600, *heartbeat.EstimatedRemainingSeconds, 5)
+}
+
+func TestLastDeploymentDuration(t *testing.T) {
+	env := environment.Ephemeral()
+	require.Equal(t, time.Duration(0), LastDeploymentDuration(env))
+
+	require.NoError(t, recordDeploymentDuration(env, 90*time.Second))
+	require.Equal(t, 90*time.Second, LastDeploymentDuration(env))
+
+	// Values loaded from the configuration file are floats
+	require.NoError(t, env.Config.Set(lastDurationConfigPath, float64(120)))
+	require.Equal(t, 2*time.Minute, LastDeploymentDuration(env))
+}
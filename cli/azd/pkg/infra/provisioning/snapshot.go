@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The directory of the snapshots of the modules, relative to the infra path
+const SnapshotsDirectory = ".snapshots"
+
+// Snapshotter is implemented by the providers compiling their templates to a canonical JSON snapshot. Snapshots are stored
+// next to the templates and compared on each change, so unexpected changes of the compiled templates are caught before
+// the templates are provisioned.
+type Snapshotter interface {
+	// Snapshot compiles the templates of the module to canonical JSON
+	Snapshot(ctx context.Context) ([]byte, error)
+}
+
+// Snapshot compiles the templates of the infrastructure to a canonical JSON snapshot
+func (m *Manager) Snapshot(ctx context.Context) ([]byte, error) {
+	snapshotter, ok := m.provider.(Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("snapshots aren't supported by the %s provider", m.provider.Name())
+	}
+
+	return snapshotter.Snapshot(ctx)
+}
+
+// Gets the path of the snapshot of the module, ex) infra/.snapshots/main.json
+func SnapshotPath(projectPath string, options Options) string {
+	infraPath := options.Path
+	if strings.TrimSpace(infraPath) == "" {
+		infraPath = "infra"
+	}
+
+	module := options.Module
+	if strings.TrimSpace(module) == "" {
+		module = "main"
+	}
+
+	return filepath.Join(projectPath, infraPath, SnapshotsDirectory, module+".json")
+}
+
+// CanonicalJson formats the JSON with sorted keys and a two space indentation, so equal documents have equal snapshots.
+// The volatile properties are removed at any depth, ex) metadata._generator removes the _generator property of any
+// metadata object.
+func CanonicalJson(raw []byte, volatileProperties ...string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	// Numbers are kept as-is, large integers would lose precision as floats
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+
+	for _, property := range volatileProperties {
+		removeProperty(value, strings.Split(property, "."))
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Removes the property at the path from the objects of the value, at any depth
+func removeProperty(value any, path []string) {
+	switch value := value.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			delete(value, path[0])
+		} else if child, has := value[path[0]]; has {
+			removeProperty(child, path[1:])
+		}
+
+		for _, child := range value {
+			removeProperty(child, path)
+		}
+	case []any:
+		for _, child := range value {
+			removeProperty(child, path)
+		}
+	}
+}
+
+// SnapshotDiff compares the expected snapshot to the actual snapshot, returning the JSON paths of the values added,
+// removed or changed, ex) $.resources[0].properties.sku
+func SnapshotDiff(expected []byte, actual []byte) ([]string, error) {
+	var expectedValue, actualValue any
+	if err := json.Unmarshal(expected, &expectedValue); err != nil {
+		return nil, fmt.Errorf("parsing expected snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(actual, &actualValue); err != nil {
+		return nil, fmt.Errorf("parsing actual snapshot: %w", err)
+	}
+
+	diff := []string{}
+	diffValues("$", expectedValue, actualValue, &diff)
+	return diff, nil
+}
+
+func diffValues(path string, expected any, actual any, diff *[]string) {
+	switch expected := expected.(type) {
+	case map[string]any:
+		actual, ok := actual.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := maps.Keys(expected)
+		for key := range actual {
+			if _, has := expected[key]; !has {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			expectedChild, hasExpected := expected[key]
+			actualChild, hasActual := actual[key]
+			childPath := fmt.Sprintf("%s.%s", path, key)
+			if !hasExpected || !hasActual {
+				*diff = append(*diff, childPath)
+				continue
+			}
+
+			diffValues(childPath, expectedChild, actualChild, diff)
+		}
+		return
+	case []any:
+		actual, ok := actual.([]any)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(expected) || i < len(actual); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			if i >= len(expected) || i >= len(actual) {
+				*diff = append(*diff, childPath)
+				continue
+			}
+
+			diffValues(childPath, expected[i], actual[i], diff)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		*diff = append(*diff, path)
+	}
+}
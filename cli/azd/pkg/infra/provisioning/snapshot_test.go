@@ -0,0 +1,63 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package provisioning_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJson(t *testing.T) {
+	raw := `{
+		"resources": [{"name": "web", "metadata": {"_generator": {"version": "0.18.4"}, "description": "web"}}],
+		"metadata": {"_generator": {"templateHash": "123"}},
+		"$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+		"size": 12345678901234567890
+	}`
+
+	canonical, err := CanonicalJson([]byte(raw), "metadata._generator")
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+  "metadata": {},
+  "resources": [
+    {
+      "metadata": {
+        "description": "web"
+      },
+      "name": "web"
+    }
+  ],
+  "size": 12345678901234567890
+}
+`, string(canonical))
+
+	_, err = CanonicalJson([]byte("{"))
+	require.Error(t, err)
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	expected := `{"resources": [{"name": "web", "sku": "B1"}, {"name": "api"}], "outputs": {"URL": {}}}`
+	actual := `{"resources": [{"name": "web", "sku": "P1v3"}], "outputs": {"URL": {}, "NAME": {}}}`
+
+	diff, err := SnapshotDiff([]byte(expected), []byte(actual))
+	require.NoError(t, err)
+	require.Equal(t, []string{"$.outputs.NAME", "$.resources[0].sku", "$.resources[1]"}, diff)
+
+	diff, err = SnapshotDiff([]byte(expected), []byte(expected))
+	require.NoError(t, err)
+	require.Empty(t, diff)
+}
+
+func TestSnapshotPath(t *testing.T) {
+	require.Equal(t, filepath.Join("app", "infra", ".snapshots", "main.json"), SnapshotPath("app", Options{}))
+	require.Equal(
+		t,
+		filepath.Join("app", "deploy", ".snapshots", "resources.json"),
+		SnapshotPath("app", Options{Path: "deploy", Module: "resources"}),
+	)
+}
@@ -162,6 +162,51 @@ func (t *TerraformProvider) Plan(
 		})
 }
 
+// Compiles the Terraform module to a canonical JSON snapshot of its configuration. The configuration is read from a plan
+// of the module, without refreshing the state, and doesn't include the values of the variables of the environment.
+func (t *TerraformProvider) Snapshot(ctx context.Context) ([]byte, error) {
+	isRemoteBackendConfig, err := t.isRemoteBackendConfig()
+	if err != nil {
+		return nil, fmt.Errorf("reading backend config: %w", err)
+	}
+
+	modulePath := t.modulePath()
+	initRes, err := t.init(ctx, isRemoteBackendConfig)
+	if err != nil {
+		return nil, fmt.Errorf("terraform init failed: %s , err: %w", initRes, err)
+	}
+
+	if err := t.ensureParametersFile(); err != nil {
+		return nil, err
+	}
+
+	planFilePath := t.snapshotPlanFilePath()
+	planArgs := append(t.createPlanArgs(isRemoteBackendConfig), "-refresh=false", "-input=false")
+	runResult, err := t.cli.Plan(ctx, modulePath, planFilePath, planArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("terraform plan failed:%s err %w", runResult, err)
+	}
+	defer os.Remove(planFilePath)
+
+	showResult, err := t.cli.Show(ctx, modulePath, planFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("showing plan failed: %s, err:%w", showResult, err)
+	}
+
+	var plan struct {
+		Configuration json.RawMessage `json:"configuration"`
+	}
+	if err := json.Unmarshal([]byte(showResult), &plan); err != nil {
+		return nil, fmt.Errorf("parsing plan: %w", err)
+	}
+
+	if len(plan.Configuration) == 0 {
+		return nil, fmt.Errorf("the plan of module '%s' has no configuration", t.options.Module)
+	}
+
+	return CanonicalJson(plan.Configuration)
+}
+
 // Deploy the infrastructure within the specified template through terraform apply
 func (t *TerraformProvider) Deploy(
 	ctx context.Context,
@@ -619,6 +664,12 @@ func (t *TerraformProvider) planFilePath() string {
 	return filepath.Join(t.projectPath, ".azure", t.env.GetEnvName(), t.options.Path, planFilename)
 }
 
+// Gets the path to the staging .azure terraform plan file of snapshots
+func (t *TerraformProvider) snapshotPlanFilePath() string {
+	planFilename := fmt.Sprintf("%s.snapshot.tfplan", t.options.Module)
+	return filepath.Join(t.projectPath, ".azure", t.env.GetEnvName(), t.options.Path, planFilename)
+}
+
 // Gets the path to the staging .azure terraform local state file path
 func (t *TerraformProvider) localStateFilePath() string {
 	return filepath.Join(t.projectPath, ".azure", t.env.GetEnvName(), t.options.Path, "terraform.tfstate")
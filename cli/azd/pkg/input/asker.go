@@ -12,6 +12,8 @@ import (
 	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
 )
 
 type Asker func(p survey.Prompt, response interface{}) error
@@ -100,9 +102,11 @@ func askOnePrompt(p survey.Prompt, response interface{}, isTerminal bool, stdout
 			opts = append(opts, withShowCursor)
 		}
 
-		// use blue question mark for all questions
+		// style the question mark of all questions with the current theme
+		theme := output.CurrentTheme()
+		core.DisableColor = theme.Name == output.ThemeNoColor
 		opts = append(opts, survey.WithIcons(func(icons *survey.IconSet) {
-			icons.Question.Format = "blue"
+			icons.Question.Format = theme.PromptFormat
 		}))
 
 		return survey.AskOne(p, response, opts...)
@@ -69,8 +69,9 @@ type Console interface {
 }
 
 type AskerConsole struct {
-	asker   Asker
-	handles ConsoleHandles
+	asker    Asker
+	noPrompt bool
+	handles  ConsoleHandles
 	// the writer the console was constructed with, and what we reset to when SetWriter(nil) is called.
 	defaultWriter io.Writer
 	// the writer which output is written to.
@@ -78,6 +79,9 @@ type AskerConsole struct {
 	formatter     output.Formatter
 	spinner       *yacspin.Spinner
 	currentIndent string
+	// the backend handling the prompts and progress, when they're not displayed on the terminal
+	backend         ConsoleBackend
+	backendProgress bool
 }
 
 type ConsoleOptions struct {
@@ -155,6 +159,12 @@ func (c *AskerConsole) ShowSpinner(ctx context.Context, title string, format Spi
 		return
 	}
 
+	if c.backend != nil {
+		c.backend.ShowProgress(ctx, title, format)
+		c.backendProgress = true
+		return
+	}
+
 	// mutating an existing spinner brings issues on how the messages are formatted
 	// so, instead of mutating, we stop any current spinner and replaced it for a new one
 	if c.spinner != nil {
@@ -222,6 +232,14 @@ func (c *AskerConsole) StopSpinner(ctx context.Context, lastMessage string, form
 		return
 	}
 
+	if c.backend != nil {
+		if c.backendProgress {
+			c.backend.StopProgress(ctx, lastMessage, format)
+			c.backendProgress = false
+		}
+		return
+	}
+
 	// calling stop for non existing spinner
 	if c.spinner == nil {
 		return
@@ -243,16 +261,18 @@ func (c *AskerConsole) StopSpinner(ctx context.Context, lastMessage string, form
 }
 
 func (c *AskerConsole) IsSpinnerRunning(ctx context.Context) bool {
+	if c.backend != nil {
+		return c.backendProgress
+	}
+
 	return c.spinner != nil && c.spinner.Status() != yacspin.SpinnerStopped
 }
 
-var donePrefix string = output.WithSuccessFormat("(✓) Done:")
-
 func (c *AskerConsole) getStopChar(format SpinnerUxType) string {
 	var stopChar string
 	switch format {
 	case StepDone:
-		stopChar = donePrefix
+		stopChar = output.WithSuccessFormat("(✓) Done:")
 	case StepFailed:
 		stopChar = output.WithErrorFormat("(x) Failed:")
 	case StepWarning:
@@ -263,6 +283,10 @@ func (c *AskerConsole) getStopChar(format SpinnerUxType) string {
 
 // Prompts the user for a single value
 func (c *AskerConsole) Prompt(ctx context.Context, options ConsoleOptions) (string, error) {
+	if c.useBackend() {
+		return c.backend.Prompt(ctx, options)
+	}
+
 	var defaultValue string
 	if value, ok := options.DefaultValue.(string); ok {
 		defaultValue = value
@@ -288,6 +312,10 @@ func (c *AskerConsole) Prompt(ctx context.Context, options ConsoleOptions) (stri
 
 // Prompts the user to select from a set of values
 func (c *AskerConsole) Select(ctx context.Context, options ConsoleOptions) (int, error) {
+	if c.useBackend() {
+		return c.backend.Select(ctx, options)
+	}
+
 	survey := &survey.Select{
 		Message: options.Message,
 		Options: options.Options,
@@ -309,6 +337,10 @@ func (c *AskerConsole) Select(ctx context.Context, options ConsoleOptions) (int,
 
 // Prompts the user to confirm an operation
 func (c *AskerConsole) Confirm(ctx context.Context, options ConsoleOptions) (bool, error) {
+	if c.useBackend() {
+		return c.backend.Confirm(ctx, options)
+	}
+
 	var defaultValue bool
 	if value, ok := options.DefaultValue.(bool); ok {
 		defaultValue = value
@@ -341,16 +373,31 @@ func (c *AskerConsole) Handles() ConsoleHandles {
 	return c.handles
 }
 
+// Checks whether prompts are handled by the backend. When prompting is disabled, the default values are used instead.
+func (c *AskerConsole) useBackend() bool {
+	return c.backend != nil && !c.noPrompt
+}
+
 // Creates a new console with the specified writer, handles and formatter.
-func NewConsole(noPrompt bool, isTerminal bool, w io.Writer, handles ConsoleHandles, formatter output.Formatter) Console {
+// When a backend is specified, the prompts and progress are handled by the backend instead of the terminal.
+func NewConsole(
+	noPrompt bool,
+	isTerminal bool,
+	w io.Writer,
+	handles ConsoleHandles,
+	formatter output.Formatter,
+	backend ConsoleBackend,
+) Console {
 	asker := NewAsker(noPrompt, isTerminal, handles.Stdout, handles.Stdin)
 
 	return &AskerConsole{
 		asker:         asker,
+		noPrompt:      noPrompt,
 		handles:       handles,
 		defaultWriter: w,
 		writer:        w,
 		formatter:     formatter,
+		backend:       backend,
 	}
 }
 
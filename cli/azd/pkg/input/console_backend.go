@@ -0,0 +1,223 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// ConsoleEndpointEnvVarName is the environment variable with the endpoint of the external console backend.
+// When set, the prompts and progress of the console are sent to the endpoint instead of the terminal.
+const ConsoleEndpointEnvVarName = "AZD_UI_ENDPOINT"
+
+// ConsoleKeyEnvVarName is the environment variable with the key sent as a bearer token to the external console backend.
+const ConsoleKeyEnvVarName = "AZD_UI_KEY"
+
+// ErrPromptCancelled is returned when a prompt is cancelled by the console backend
+var ErrPromptCancelled = errors.New("prompt was cancelled")
+
+// ConsoleBackend handles the interactions of the console with the user. By default, the console prompts and displays
+// progress on the terminal. Embedders, ex) the VS Code extension or a server, supply a backend to handle the prompts and
+// progress programmatically.
+type ConsoleBackend interface {
+	// Prompts for a single value
+	Prompt(ctx context.Context, options ConsoleOptions) (string, error)
+	// Prompts to select from a set of values, returning the index of the selected value
+	Select(ctx context.Context, options ConsoleOptions) (int, error)
+	// Prompts to confirm an operation
+	Confirm(ctx context.Context, options ConsoleOptions) (bool, error)
+	// Displays the progress of the current step. Replaces the progress of a step that is already displayed
+	ShowProgress(ctx context.Context, title string, format SpinnerUxType)
+	// Completes the current step with the last message, or clears the progress when the last message is empty
+	StopProgress(ctx context.Context, lastMessage string, format SpinnerUxType)
+}
+
+// externalConsoleBackend is a ConsoleBackend that sends the prompts and progress of the console as JSON requests to an
+// HTTP endpoint, ex) a server hosted by the VS Code extension:
+//
+//	POST {endpoint}/prompt   {"type": "string|select|confirm", "options": {...}}
+//	POST {endpoint}/progress {"title": "...", "state": "step|done|failed|warning|cleared"}
+//
+// Prompts are answered with {"status": "success|cancelled|error", "value": ..., "message": "..."}.
+type externalConsoleBackend struct {
+	endpoint   string
+	key        string
+	httpClient httputil.HttpClient
+}
+
+// NewExternalConsoleBackend creates a ConsoleBackend that sends the prompts and progress of the console to the endpoint.
+// The key, when set, is sent as a bearer token.
+func NewExternalConsoleBackend(endpoint string, key string, httpClient httputil.HttpClient) ConsoleBackend {
+	return &externalConsoleBackend{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		key:        key,
+		httpClient: httpClient,
+	}
+}
+
+type externalPromptOptions struct {
+	Message      string   `json:"message"`
+	Help         string   `json:"help,omitempty"`
+	Choices      []string `json:"choices,omitempty"`
+	DefaultValue any      `json:"defaultValue,omitempty"`
+}
+
+type externalPromptRequest struct {
+	Type    string                `json:"type"`
+	Options externalPromptOptions `json:"options"`
+}
+
+type externalPromptResponse struct {
+	Status  string          `json:"status"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+type externalProgressRequest struct {
+	Title string `json:"title"`
+	State string `json:"state"`
+}
+
+func (b *externalConsoleBackend) Prompt(ctx context.Context, options ConsoleOptions) (string, error) {
+	var value string
+	if err := b.prompt(ctx, "string", options, &value); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+func (b *externalConsoleBackend) Select(ctx context.Context, options ConsoleOptions) (int, error) {
+	var value string
+	if err := b.prompt(ctx, "select", options, &value); err != nil {
+		return -1, err
+	}
+
+	for index, option := range options.Options {
+		if option == value {
+			return index, nil
+		}
+	}
+
+	return -1, fmt.Errorf("selected value '%s' is not one of the options for prompt '%s'", value, options.Message)
+}
+
+func (b *externalConsoleBackend) Confirm(ctx context.Context, options ConsoleOptions) (bool, error) {
+	var value bool
+	if err := b.prompt(ctx, "confirm", options, &value); err != nil {
+		return false, err
+	}
+
+	return value, nil
+}
+
+func (b *externalConsoleBackend) ShowProgress(ctx context.Context, title string, format SpinnerUxType) {
+	b.progress(ctx, title, "step")
+}
+
+func (b *externalConsoleBackend) StopProgress(ctx context.Context, lastMessage string, format SpinnerUxType) {
+	state := "cleared"
+	if lastMessage != "" {
+		switch format {
+		case StepFailed:
+			state = "failed"
+		case StepWarning:
+			state = "warning"
+		default:
+			state = "done"
+		}
+	}
+
+	b.progress(ctx, lastMessage, state)
+}
+
+// Sends the prompt to the endpoint and unmarshals the value of the response into value
+func (b *externalConsoleBackend) prompt(ctx context.Context, promptType string, options ConsoleOptions, value any) error {
+	res, err := b.send(ctx, "prompt", externalPromptRequest{
+		Type: promptType,
+		Options: externalPromptOptions{
+			Message:      options.Message,
+			Help:         options.Help,
+			Choices:      options.Options,
+			DefaultValue: options.DefaultValue,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("prompting '%s': %w", options.Message, err)
+	}
+	defer res.Body.Close()
+
+	var response externalPromptResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return fmt.Errorf("prompting '%s', reading response: %w", options.Message, err)
+	}
+
+	switch response.Status {
+	case "success":
+		if err := json.Unmarshal(response.Value, value); err != nil {
+			return fmt.Errorf("prompting '%s', unexpected value: %w", options.Message, err)
+		}
+
+		return nil
+	case "cancelled":
+		return ErrPromptCancelled
+	default:
+		return fmt.Errorf("prompting '%s': %s", options.Message, response.Message)
+	}
+}
+
+// Sends the progress to the endpoint. Progress is informational, so failures are logged and ignored
+func (b *externalConsoleBackend) progress(ctx context.Context, title string, state string) {
+	res, err := b.send(ctx, "progress", externalProgressRequest{
+		Title: title,
+		State: state,
+	})
+	if err != nil {
+		log.Printf("failed sending progress '%s' to console backend: %v", title, err)
+		return
+	}
+
+	_ = res.Body.Close()
+}
+
+func (b *externalConsoleBackend) send(ctx context.Context, path string, body any) (*http.Response, error) {
+	content, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, fmt.Sprintf("%s/%s", b.endpoint, path), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if b.key != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", b.key))
+	}
+
+	res, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		message, _ := io.ReadAll(res.Body)
+		return nil, fmt.Errorf("unexpected status code %d: %s", res.StatusCode, string(message))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExternalConsoleBackend(t *testing.T) {
+	respond := func(request *http.Request, body string) (*http.Response, error) {
+		return &http.Response{
+			Request:    request,
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+
+	t.Run("Prompts", func(t *testing.T) {
+		httpClient := mockhttp.NewMockHttpUtil()
+		requests := []externalPromptRequest{}
+		httpClient.When(func(request *http.Request) bool {
+			return request.URL.String() == "http://localhost:8080/prompt"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			require.Equal(t, "Bearer KEY", request.Header.Get("Authorization"))
+
+			var promptRequest externalPromptRequest
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&promptRequest))
+			requests = append(requests, promptRequest)
+
+			switch promptRequest.Type {
+			case "select":
+				return respond(request, `{"status": "success", "value": "westus"}`)
+			case "confirm":
+				return respond(request, `{"status": "success", "value": true}`)
+			default:
+				return respond(request, `{"status": "success", "value": "dev"}`)
+			}
+		})
+
+		console := NewConsole(false, false, &bytes.Buffer{}, ConsoleHandles{}, nil,
+			NewExternalConsoleBackend("http://localhost:8080/", "KEY", httpClient))
+
+		value, err := console.Prompt(context.Background(), ConsoleOptions{Message: "Environment name"})
+		require.NoError(t, err)
+		require.Equal(t, "dev", value)
+
+		index, err := console.Select(context.Background(), ConsoleOptions{
+			Message: "Location",
+			Options: []string{"eastus", "westus"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, index)
+
+		confirmed, err := console.Confirm(context.Background(), ConsoleOptions{Message: "Continue?"})
+		require.NoError(t, err)
+		require.True(t, confirmed)
+
+		require.Len(t, requests, 3)
+		require.Equal(t, []string{"eastus", "westus"}, requests[1].Options.Choices)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		httpClient := mockhttp.NewMockHttpUtil()
+		httpClient.When(func(request *http.Request) bool {
+			return strings.HasSuffix(request.URL.Path, "/prompt")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			return respond(request, `{"status": "cancelled"}`)
+		})
+
+		console := NewConsole(false, false, &bytes.Buffer{}, ConsoleHandles{}, nil,
+			NewExternalConsoleBackend("http://localhost:8080", "", httpClient))
+
+		_, err := console.Confirm(context.Background(), ConsoleOptions{Message: "Continue?"})
+		require.ErrorIs(t, err, ErrPromptCancelled)
+	})
+
+	t.Run("NoPromptUsesDefaults", func(t *testing.T) {
+		console := NewConsole(true, false, &bytes.Buffer{}, ConsoleHandles{}, nil,
+			NewExternalConsoleBackend("http://localhost:8080", "", mockhttp.NewMockHttpUtil()))
+
+		value, err := console.Prompt(context.Background(), ConsoleOptions{Message: "Name", DefaultValue: "dev"})
+		require.NoError(t, err)
+		require.Equal(t, "dev", value)
+	})
+
+	t.Run("Progress", func(t *testing.T) {
+		httpClient := mockhttp.NewMockHttpUtil()
+		progress := []externalProgressRequest{}
+		httpClient.When(func(request *http.Request) bool {
+			return strings.HasSuffix(request.URL.Path, "/progress")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			var progressRequest externalProgressRequest
+			require.NoError(t, json.NewDecoder(request.Body).Decode(&progressRequest))
+			progress = append(progress, progressRequest)
+
+			return respond(request, "")
+		})
+
+		console := NewConsole(false, false, &bytes.Buffer{}, ConsoleHandles{}, nil,
+			NewExternalConsoleBackend("http://localhost:8080", "", httpClient))
+
+		console.ShowSpinner(context.Background(), "Creating resources", Step)
+		require.True(t, console.IsSpinnerRunning(context.Background()))
+
+		console.StopSpinner(context.Background(), "Created resources", StepDone)
+		require.False(t, console.IsSpinnerRunning(context.Background()))
+
+		// Stopping the progress again is a no-op
+		console.StopSpinner(context.Background(), "", StepDone)
+
+		require.Equal(t, []externalProgressRequest{
+			{Title: "Creating resources", State: "step"},
+			{Title: "Created resources", State: "done"},
+		}, progress)
+	})
+}
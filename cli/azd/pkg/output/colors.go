@@ -1,46 +1,52 @@
 package output
 
-import (
-	"github.com/fatih/color"
-)
+import "github.com/fatih/color"
 
 // withLinkFormat creates string with hyperlink-looking color
 func WithLinkFormat(link string, a ...interface{}) string {
-	return color.HiCyanString(link, a...)
+	return colorString(CurrentTheme().Link, link, a...)
 }
 
 // withHighLightFormat creates string with highlight-looking color
 func WithHighLightFormat(text string, a ...interface{}) string {
-	return color.CyanString(text, a...)
+	return colorString(CurrentTheme().HighLight, text, a...)
 }
 
 func WithErrorFormat(text string, a ...interface{}) string {
-	return color.RedString(text, a...)
+	return colorString(CurrentTheme().Error, text, a...)
 }
 
 func WithWarningFormat(text string, a ...interface{}) string {
-	return color.YellowString(text, a...)
+	return colorString(CurrentTheme().Warning, text, a...)
 }
 
 func WithSuccessFormat(text string, a ...interface{}) string {
-	return color.GreenString(text, a...)
+	return colorString(CurrentTheme().Success, text, a...)
 }
 
 func WithGrayFormat(text string, a ...interface{}) string {
-	return color.HiBlackString(text, a...)
+	return colorString(CurrentTheme().Gray, text, a...)
 }
 
 func WithBold(text string, a ...interface{}) string {
-	format := color.New(color.Bold)
-	return format.Sprintf(text, a...)
+	return CurrentTheme().Bold.Sprintf(text, a...)
 }
 
 func WithUnderline(text string, a ...interface{}) string {
-	format := color.New(color.Underline)
-	return format.Sprintf(text, a...)
+	return CurrentTheme().Underline.Sprintf(text, a...)
 }
 
 // WithBackticks wraps text with the backtick (`) character.
 func WithBackticks(text string) string {
 	return "`" + text + "`"
 }
+
+// Formats the text with the color like the color functions of the color package, which only format the text when they have
+// arguments, so texts without arguments may contain verbs, ex) %HOME
+func colorString(c *color.Color, text string, a ...interface{}) string {
+	if len(a) == 0 {
+		return c.SprintFunc()(text)
+	}
+
+	return c.SprintfFunc()(text, a...)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+)
+
+// ThemeName is the name of a theme of the output of azd, set with `azd config set theme <name>`
+type ThemeName string
+
+const (
+	// The default theme, for terminals with a dark background
+	ThemeDark ThemeName = "dark"
+	// A theme for terminals with a light background
+	ThemeLight ThemeName = "light"
+	// A theme using bold and bright colors only
+	ThemeHighContrast ThemeName = "high-contrast"
+	// A theme without colors or styles, used when NO_COLOR is set
+	ThemeNoColor ThemeName = "no-color"
+)
+
+// The key of the theme in the user configuration
+const ThemeConfigKey = "theme"
+
+// Theme is the set of styles applied to the output of azd, ex) to links, warnings and errors
+type Theme struct {
+	Name      ThemeName
+	Link      *color.Color
+	HighLight *color.Color
+	Error     *color.Color
+	Warning   *color.Color
+	Success   *color.Color
+	Gray      *color.Color
+	Bold      *color.Color
+	Underline *color.Color
+	// The survey format of the icon of the prompts, ex) blue
+	PromptFormat string
+}
+
+var themes = map[ThemeName]*Theme{
+	ThemeDark: {
+		Name:         ThemeDark,
+		Link:         color.New(color.FgHiCyan),
+		HighLight:    color.New(color.FgCyan),
+		Error:        color.New(color.FgRed),
+		Warning:      color.New(color.FgYellow),
+		Success:      color.New(color.FgGreen),
+		Gray:         color.New(color.FgHiBlack),
+		Bold:         color.New(color.Bold),
+		Underline:    color.New(color.Underline),
+		PromptFormat: "blue",
+	},
+	ThemeLight: {
+		Name:         ThemeLight,
+		Link:         color.New(color.FgBlue, color.Underline),
+		HighLight:    color.New(color.FgBlue),
+		Error:        color.New(color.FgRed),
+		Warning:      color.New(color.FgMagenta),
+		Success:      color.New(color.FgGreen),
+		Gray:         color.New(color.FgBlack),
+		Bold:         color.New(color.Bold),
+		Underline:    color.New(color.Underline),
+		PromptFormat: "blue",
+	},
+	ThemeHighContrast: {
+		Name:      ThemeHighContrast,
+		Link:      color.New(color.FgHiCyan, color.Underline),
+		HighLight: color.New(color.Bold),
+		Error:     color.New(color.FgHiRed, color.Bold),
+		Warning:   color.New(color.FgHiYellow, color.Bold),
+		Success:   color.New(color.FgHiGreen, color.Bold),
+		// Gray text is written in the default color of the terminal
+		Gray:         noColor(),
+		Bold:         color.New(color.Bold),
+		Underline:    color.New(color.Underline),
+		PromptFormat: "default+b",
+	},
+	ThemeNoColor: {
+		Name:      ThemeNoColor,
+		Link:      noColor(),
+		HighLight: noColor(),
+		Error:     noColor(),
+		Warning:   noColor(),
+		Success:   noColor(),
+		Gray:      noColor(),
+		Bold:      noColor(),
+		Underline: noColor(),
+	},
+}
+
+var currentTheme atomic.Pointer[Theme]
+
+func init() {
+	currentTheme.Store(themes[ThemeDark])
+
+	// NO_COLOR is honored before the user configuration is loaded, ex) for errors parsing the command line
+	if noColorRequested() {
+		SetTheme(ThemeNoColor)
+	}
+}
+
+// Themes gets the names of the available themes
+func Themes() []ThemeName {
+	return []ThemeName{ThemeDark, ThemeLight, ThemeHighContrast, ThemeNoColor}
+}
+
+// ParseThemeName parses the name of a theme, ex) light
+func ParseThemeName(value string) (ThemeName, error) {
+	for _, name := range Themes() {
+		if strings.EqualFold(value, string(name)) {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"invalid theme '%s', expected one of %s, %s, %s or %s",
+		value,
+		ThemeDark,
+		ThemeLight,
+		ThemeHighContrast,
+		ThemeNoColor,
+	)
+}
+
+// ResolveThemeName gets the theme to use for the configured theme. NO_COLOR (https://no-color.org) takes precedence over
+// the configured theme. Without a configured theme, the dark theme is used.
+func ResolveThemeName(configured string) (ThemeName, error) {
+	if noColorRequested() {
+		return ThemeNoColor, nil
+	}
+
+	if configured == "" {
+		return ThemeDark, nil
+	}
+
+	return ParseThemeName(configured)
+}
+
+// SetTheme sets the theme applied to the output of azd
+func SetTheme(name ThemeName) {
+	theme, has := themes[name]
+	if !has {
+		theme = themes[ThemeDark]
+	}
+
+	currentTheme.Store(theme)
+}
+
+// CurrentTheme gets the theme applied to the output of azd
+func CurrentTheme() *Theme {
+	return currentTheme.Load()
+}
+
+// Gets whether the NO_COLOR environment variable is set to a non-empty value
+func noColorRequested() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Creates a color which never writes escape sequences
+func noColor() *color.Color {
+	c := color.New()
+	c.DisableColor()
+	return c
+}
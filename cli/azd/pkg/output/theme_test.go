@@ -0,0 +1,66 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package output
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveThemeName(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+
+		name, err := ResolveThemeName("")
+		require.NoError(t, err)
+		require.Equal(t, ThemeDark, name)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+
+		name, err := ResolveThemeName("High-Contrast")
+		require.NoError(t, err)
+		require.Equal(t, ThemeHighContrast, name)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+
+		_, err := ResolveThemeName("solarized")
+		require.Error(t, err)
+	})
+
+	t.Run("NoColor", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+
+		name, err := ResolveThemeName("light")
+		require.NoError(t, err)
+		require.Equal(t, ThemeNoColor, name)
+	})
+}
+
+func Test_SetTheme(t *testing.T) {
+	// Colors are disabled when the output isn't a terminal, ex) while testing
+	noColor := color.NoColor
+	color.NoColor = false
+	previous := CurrentTheme()
+	t.Cleanup(func() {
+		color.NoColor = noColor
+		currentTheme.Store(previous)
+	})
+
+	SetTheme(ThemeDark)
+	require.Equal(t, "\x1b[33mwarning\x1b[0m", WithWarningFormat("warning"))
+
+	SetTheme(ThemeLight)
+	require.Equal(t, "\x1b[35mwarning\x1b[0m", WithWarningFormat("warning"))
+
+	SetTheme(ThemeNoColor)
+	require.Equal(t, "warning", WithWarningFormat("warning"))
+	require.Equal(t, "bold", WithBold("bold"))
+	require.Equal(t, "https://aka.ms/azd", WithLinkFormat("https://aka.ms/azd"))
+}
@@ -15,11 +15,11 @@ type CreatedRepoSecret struct {
 }
 
 func (cr *CreatedRepoSecret) ToString(currentIndentation string) string {
-	return fmt.Sprintf("%s%s Setting %s repo secret", currentIndentation, donePrefix, cr.Name)
+	return fmt.Sprintf("%s%s Setting %s repo secret", currentIndentation, donePrefix(), cr.Name)
 }
 
 func (cr *CreatedRepoSecret) MarshalJSON() ([]byte, error) {
 	// reusing the same envelope from console messages
 	return json.Marshal(output.EventForMessage(
-		fmt.Sprintf("%s Setting %s repo secret", donePrefix, cr.Name)))
+		fmt.Sprintf("%s Setting %s repo secret", donePrefix(), cr.Name)))
 }
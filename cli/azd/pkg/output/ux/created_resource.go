@@ -16,11 +16,11 @@ type CreatedResource struct {
 }
 
 func (cr *CreatedResource) ToString(currentIndentation string) string {
-	return fmt.Sprintf("%s%s %s: %s", currentIndentation, donePrefix, cr.Type, cr.Name)
+	return fmt.Sprintf("%s%s %s: %s", currentIndentation, donePrefix(), cr.Type, cr.Name)
 }
 
 func (cr *CreatedResource) MarshalJSON() ([]byte, error) {
 	// reusing the same envelope from console messages
 	return json.Marshal(output.EventForMessage(
-		fmt.Sprintf("%s Creating %s: %s", donePrefix, cr.Type, cr.Name)))
+		fmt.Sprintf("%s Creating %s: %s", donePrefix(), cr.Type, cr.Name)))
 }
@@ -18,11 +18,11 @@ func (d *DoneMessage) ToString(currentIndentation string) string {
 	if currentIndentation == "" {
 		currentIndentation = "  "
 	}
-	return fmt.Sprintf("%s%s %s", currentIndentation, donePrefix, d.Message)
+	return fmt.Sprintf("%s%s %s", currentIndentation, donePrefix(), d.Message)
 }
 
 func (d *DoneMessage) MarshalJSON() ([]byte, error) {
 	// reusing the same envelope from console messages
 	return json.Marshal(output.EventForMessage(
-		fmt.Sprintf("%s %s", donePrefix, d.Message)))
+		fmt.Sprintf("%s %s", donePrefix(), d.Message)))
 }
@@ -0,0 +1,30 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ux
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+type ImageSignature struct {
+	// The signed image, ex) {registry}/{repository}@sha256:{digest}
+	Image string
+	// The digest of the signature stored in the registry of the image
+	Digest string
+}
+
+func (s *ImageSignature) ToString(currentIndentation string) string {
+	return fmt.Sprintf("%s- Signature: %s", currentIndentation, output.WithGrayFormat("%s", s.message()))
+}
+
+func (s *ImageSignature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(output.EventForMessage(fmt.Sprintf("- Signature: %s", s.message())))
+}
+
+func (s *ImageSignature) message() string {
+	return fmt.Sprintf("%s (signature %s)", s.Image, s.Digest)
+}
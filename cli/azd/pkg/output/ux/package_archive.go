@@ -0,0 +1,24 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ux
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+type PackageArchive struct {
+	// A short description of the archive, ex) zip (level 6): 12.4 MB -> 3.1 MB in 1.2s
+	Summary string
+}
+
+func (p *PackageArchive) ToString(currentIndentation string) string {
+	return fmt.Sprintf("%s- Package: %s", currentIndentation, output.WithGrayFormat("%s", p.Summary))
+}
+
+func (p *PackageArchive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(output.EventForMessage(fmt.Sprintf("- Package: %s", p.Summary)))
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ux
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/output"
+)
+
+type Provenance struct {
+	// The artifact the provenance was generated for, ex) {registry}/{repository}@sha256:{digest}
+	Subject string
+	// The reference of the OCI referrer the provenance was stored in, if any
+	Referrer string
+}
+
+func (p *Provenance) ToString(currentIndentation string) string {
+	return fmt.Sprintf("%s- Provenance: %s", currentIndentation, output.WithGrayFormat("%s", p.message()))
+}
+
+func (p *Provenance) MarshalJSON() ([]byte, error) {
+	return json.Marshal(output.EventForMessage(fmt.Sprintf("- Provenance: %s", p.message())))
+}
+
+func (p *Provenance) message() string {
+	if p.Referrer == "" {
+		return p.Subject
+	}
+
+	return fmt.Sprintf("%s (stored at %s)", p.Subject, p.Referrer)
+}
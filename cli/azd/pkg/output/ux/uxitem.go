@@ -16,4 +16,7 @@ type UxItem interface {
 	json.Marshaler
 }
 
-var donePrefix string = output.WithSuccessFormat("(✓) Done:")
+// Gets the prefix of completed steps, styled with the current theme
+func donePrefix() string {
+	return output.WithSuccessFormat("(✓) Done:")
+}
@@ -0,0 +1,322 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The name of the file in the environment directory recording the digests of the inputs of the last builds of the services
+const BuildCacheFileName = "build-cache.json"
+
+// BuildCacher is implemented by framework services whose builds are skipped when their inputs haven't changed since the
+// last build of the service, in which case the result of the last build is reused.
+type BuildCacher interface {
+	// Computes the digest of the inputs of the build of the service, ex) its source files and build options.
+	// Returns an empty digest when the build of the service can't be reused.
+	BuildDigest(ctx context.Context, serviceConfig *ServiceConfig) (string, error)
+
+	// Checks whether the output of a previous build of the service is still available, ex) its build directory
+	BuildOutputExists(ctx context.Context, serviceConfig *ServiceConfig, buildOutput *ServiceBuildResult) bool
+}
+
+// BuildCacheEntry records the last build of a service
+type BuildCacheEntry struct {
+	BuiltAt time.Time `json:"builtAt"`
+	// The digest of the inputs of the build
+	Digest string `json:"digest"`
+	// The output of the build, ex) the build directory or the id of the image
+	BuildOutputPath string `json:"buildOutputPath"`
+	// The path of the output of the build relative to the directory of the service, for the builds of the shared build
+	// cache whose output is copied to the cache
+	Artifact string `json:"artifact,omitempty"`
+	// The SHA-256 digest of the contents of the output copied to the shared build cache, verified before the output is
+	// restored
+	ContentHash string `json:"contentHash,omitempty"`
+}
+
+// buildCache records the last builds of the services in the environment directory. The builds of services run
+// concurrently, so updates of the file are serialized. Builds are also stored in the shared build cache, which is used when
+// the environment hasn't built the service with the same inputs yet.
+type buildCache struct {
+	env    *environment.Environment
+	shared *sharedBuildCache
+	mu     sync.Mutex
+}
+
+func newBuildCache(env *environment.Environment) *buildCache {
+	return &buildCache{env: env, shared: newSharedBuildCache()}
+}
+
+// Gets the result of the last build of the service when the digest of its inputs hasn't changed and its output is still
+// available, or the build of another environment with the same digest from the shared build cache, or nil when the service
+// must be built. Only the builds of framework services implementing BuildCacher are reused, and never when the service is
+// built without cache, ex) azd deploy --no-cache.
+func (c *buildCache) lookup(
+	ctx context.Context,
+	frameworkService FrameworkService,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) (result *ServiceBuildResult, digest string) {
+	cacher, ok := frameworkService.(BuildCacher)
+	if !ok || serviceConfig.Docker.NoCache || len(serviceConfig.Matrix) > 0 {
+		return nil, ""
+	}
+
+	sourceDigest, err := cacher.BuildDigest(ctx, serviceConfig)
+	if err != nil {
+		log.Printf("failed computing build digest of service %s: %v", serviceConfig.Name, err)
+		return nil, ""
+	}
+
+	if sourceDigest == "" {
+		return nil, ""
+	}
+
+	// The digest also covers the configuration of the service, so builds with different options aren't reused
+	config, err := serviceConfigSnapshot(serviceConfig)
+	if err != nil {
+		log.Printf("failed computing build digest of service %s: %v", serviceConfig.Name, err)
+		return nil, ""
+	}
+
+	// Builds are only reused on the same platform, with the same version of the toolchain
+	toolchainVersion := ""
+	if toolchain, ok := frameworkService.(BuildToolchain); ok {
+		toolchainVersion, err = toolchain.ToolchainVersion(ctx, serviceConfig)
+		if err != nil {
+			log.Printf("failed getting toolchain version of service %s: %v", serviceConfig.Name, err)
+			return nil, ""
+		}
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "source\x00%s\n", sourceDigest)
+	fmt.Fprintf(hash, "platform\x00%s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(hash, "toolchain\x00%s\n", toolchainVersion)
+	keys := maps.Keys(config)
+	slices.Sort(keys)
+	for _, key := range keys {
+		fmt.Fprintf(hash, "config\x00%s\x00%s\n", key, config[key])
+	}
+	digest = fmt.Sprintf("%x", hash.Sum(nil))
+
+	entries, err := c.load()
+	if err != nil {
+		log.Printf("failed reading build cache: %v", err)
+		return nil, digest
+	}
+
+	// Outputs removed since the last build of the environment, ex) by a clean, are rebuilt
+	if entry, has := entries[serviceConfig.Name]; has && entry.Digest == digest {
+		buildOutput := &ServiceBuildResult{
+			Restore:         restoreOutput,
+			BuildOutputPath: entry.BuildOutputPath,
+		}
+
+		if !cacher.BuildOutputExists(ctx, serviceConfig, buildOutput) {
+			log.Printf(
+				"not reusing build of service %s, its output %s no longer exists", serviceConfig.Name, entry.BuildOutputPath)
+			return nil, digest
+		}
+
+		return buildOutput, digest
+	}
+
+	buildOutput := c.shared.lookup(ctx, cacher, serviceConfig, digest, restoreOutput)
+	if buildOutput == nil {
+		return nil, digest
+	}
+
+	log.Printf("reusing shared build of service %s with digest %s", serviceConfig.Name, digest)
+	if err := c.recordEnv(serviceConfig, digest, buildOutput); err != nil {
+		log.Printf("failed recording build of service %s: %v", serviceConfig.Name, err)
+	}
+
+	return buildOutput, digest
+}
+
+// Records the build of the service with the digest of its inputs, in the environment and in the shared build cache. Builds
+// with details specific to the framework service, ex) deferred docker builds, aren't recorded since they can't be restored.
+func (c *buildCache) record(serviceConfig *ServiceConfig, digest string, buildOutput *ServiceBuildResult) error {
+	if digest == "" || buildOutput == nil || buildOutput.Details != nil || len(buildOutput.Variants) > 0 {
+		return nil
+	}
+
+	if err := c.recordEnv(serviceConfig, digest, buildOutput); err != nil {
+		return err
+	}
+
+	if err := c.shared.store(serviceConfig, digest, buildOutput); err != nil {
+		return fmt.Errorf("storing build in shared build cache: %w", err)
+	}
+
+	return nil
+}
+
+// Records the build of the service in the build cache file of the environment
+func (c *buildCache) recordEnv(serviceConfig *ServiceConfig, digest string, buildOutput *ServiceBuildResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := c.load()
+	if err != nil {
+		return err
+	}
+
+	entries[serviceConfig.Name] = &BuildCacheEntry{
+		BuiltAt:         time.Now().UTC(),
+		Digest:          digest,
+		BuildOutputPath: buildOutput.BuildOutputPath,
+	}
+
+	return c.save(entries)
+}
+
+func (c *buildCache) load() (map[string]*BuildCacheEntry, error) {
+	entries := map[string]*BuildCacheEntry{}
+	if c.env.Root == "" {
+		return entries, nil
+	}
+
+	contents, err := os.ReadFile(filepath.Join(c.env.Root, BuildCacheFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading build cache: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, &entries); err != nil {
+		return nil, fmt.Errorf("parsing build cache: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (c *buildCache) save(entries map[string]*BuildCacheEntry) error {
+	// Ephemeral environments are not persisted
+	if c.env.Root == "" {
+		return nil
+	}
+
+	contents, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling build cache: %w", err)
+	}
+
+	if err := os.MkdirAll(c.env.Root, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating environment directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(c.env.Root, BuildCacheFileName), contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing build cache: %w", err)
+	}
+
+	return nil
+}
+
+// Computes the digest of the source files of the service and of the inputs, ex) the options of the build. Files excluded
+// by the .gitignore file of the service and the files under the excluded directories, relative to the service, aren't
+// part of the digest, since they are usually outputs of builds, ex) bin or node_modules.
+func sourceDigest(serviceConfig *ServiceConfig, excludedDirs []string, inputs ...string) (string, error) {
+	root := serviceConfig.Path()
+	rules, err := readIgnoreFile(filepath.Join(root, ".gitignore"), true)
+	if err != nil {
+		return "", fmt.Errorf("reading .gitignore: %w", err)
+	}
+
+	excluded := map[string]bool{".git": true}
+	for _, dir := range excludedDirs {
+		if dir != "" {
+			excluded[filepath.ToSlash(filepath.Clean(dir))] = true
+		}
+	}
+
+	files := []string{}
+	fileHashes := map[string]string{}
+	err = filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+
+		if excluded[filepath.ToSlash(relativePath)] || rules.excludes(relativePath, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		fileHash, err := fileSha256(filePath)
+		if err != nil {
+			return err
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		files = append(files, relativePath)
+		fileHashes[relativePath] = fileHash
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("hashing source of service %s: %w", serviceConfig.Name, err)
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "inputs\x00%s\n", strings.Join(inputs, "\x00"))
+
+	// Files are hashed in the same order on all platforms
+	slices.Sort(files)
+	for _, file := range files {
+		fmt.Fprintf(hash, "file\x00%s\x00%s\n", file, fileHashes[file])
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// Checks whether the build output path of the build exists, for the framework services whose builds output files
+func buildOutputPathExists(buildOutput *ServiceBuildResult) bool {
+	if buildOutput.BuildOutputPath == "" {
+		return false
+	}
+
+	_, err := os.Stat(buildOutput.BuildOutputPath)
+	return err == nil
+}
+
+// Gets the environment values exposed to the service as sorted KEY=value inputs of a digest
+func envDigestInputs(serviceConfig *ServiceConfig, env *environment.Environment) ([]string, error) {
+	envs, err := serviceConfig.Environ(env)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(envs)
+	return envs, nil
+}
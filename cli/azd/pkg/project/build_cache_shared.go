@@ -0,0 +1,323 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/otiai10/copy"
+)
+
+// BuildCacheDirEnvVarName is the environment variable with the directory of the build cache shared by the environments of
+// the projects. When unset, the shared build cache is the cache/builds directory of the azd configuration directory. The
+// directory may be a network share or a directory restored by CI runs, so machines and runs reuse the builds of each other.
+const BuildCacheDirEnvVarName = "AZD_BUILD_CACHE_DIR"
+
+// The builds of the shared build cache are removed when they haven't been stored or reused for this long
+const sharedBuildCacheMaxAge = 14 * 24 * time.Hour
+
+const (
+	// The name of the file recording the build within the directory of the build in the shared build cache
+	sharedBuildEntryFileName = "build.json"
+	// The name of the copy of the output of the build within the directory of the build in the shared build cache
+	sharedBuildOutputName = "output"
+	// The suffix of the directories builds are written to before they are moved to their directory
+	sharedBuildTempSuffix = ".tmp"
+)
+
+// BuildToolchain is implemented by framework services whose build outputs depend on the version of the toolchain building
+// them, ex) the version of Node.js or of the go toolchain. Builds are only reused when built with the same version.
+type BuildToolchain interface {
+	// Gets the version of the toolchain building the service, ex) go1.22.1
+	ToolchainVersion(ctx context.Context, serviceConfig *ServiceConfig) (string, error)
+}
+
+// sharedBuildCache stores the builds of the services by the digest of their inputs, so the environments of the project,
+// ex) dev and staging, reuse the builds of each other instead of rebuilding unchanged services. The outputs of the builds
+// within the directories of the services are copied to the cache with the digest of their contents, which is verified
+// before they are restored. Outputs on the file system that aren't copied, ex) the directory of the service, aren't
+// shared. Each build is written to a temporary directory first and then moved to the directory of its digest, so builds
+// are never read while they are written.
+type sharedBuildCache struct {
+	root string
+}
+
+func newSharedBuildCache() *sharedBuildCache {
+	root := strings.TrimSpace(os.Getenv(BuildCacheDirEnvVarName))
+	if root == "" {
+		configDir, err := config.GetUserConfigDir()
+		if err != nil {
+			// The cache is an optimization, builds just aren't shared
+			log.Printf("failed getting user config directory, builds aren't shared by environments: %v", err)
+			return &sharedBuildCache{}
+		}
+
+		root = filepath.Join(configDir, "cache", "builds")
+	}
+
+	return &sharedBuildCache{root: root}
+}
+
+// Gets the build of the service with the digest when another environment built the service with the same inputs, or nil.
+// The output of the build is copied back to the path of the output of the build within the directory of the service.
+func (c *sharedBuildCache) lookup(
+	ctx context.Context,
+	cacher BuildCacher,
+	serviceConfig *ServiceConfig,
+	digest string,
+	restoreOutput *ServiceRestoreResult,
+) *ServiceBuildResult {
+	if c.root == "" || digest == "" {
+		return nil
+	}
+
+	buildDir := filepath.Join(c.root, digest)
+	contents, err := os.ReadFile(filepath.Join(buildDir, sharedBuildEntryFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		log.Printf("failed reading shared build of service %s: %v", serviceConfig.Name, err)
+		return nil
+	}
+
+	var entry BuildCacheEntry
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		log.Printf("failed parsing shared build of service %s: %v", serviceConfig.Name, err)
+		return nil
+	}
+
+	buildOutput := &ServiceBuildResult{
+		Restore:         restoreOutput,
+		BuildOutputPath: entry.BuildOutputPath,
+	}
+
+	// Only the outputs copied to the cache are restored, paths on the file system aren't reused from the cache
+	if entry.Artifact == "" && filepath.IsAbs(entry.BuildOutputPath) {
+		log.Printf("not reusing shared build of service %s, its output isn't in the cache", serviceConfig.Name)
+		return nil
+	}
+
+	if entry.Artifact != "" {
+		artifactPath := filepath.FromSlash(entry.Artifact)
+		if !filepath.IsLocal(artifactPath) {
+			log.Printf("not reusing shared build of service %s, invalid artifact '%s'", serviceConfig.Name, entry.Artifact)
+			return nil
+		}
+
+		outputPath := filepath.Join(buildDir, sharedBuildOutputName)
+		contentHash, err := hashBuildOutput(outputPath)
+		if err != nil {
+			log.Printf("failed hashing shared build of service %s: %v", serviceConfig.Name, err)
+		}
+
+		if err != nil || entry.ContentHash == "" || contentHash != entry.ContentHash {
+			// The build can't be trusted, it's removed so the service is built and stored again
+			log.Printf("removing shared build of service %s, its contents don't match the build", serviceConfig.Name)
+			if err := os.RemoveAll(buildDir); err != nil {
+				log.Printf("failed removing shared build of service %s: %v", serviceConfig.Name, err)
+			}
+
+			return nil
+		}
+
+		buildOutput.BuildOutputPath = filepath.Join(serviceConfig.Path(), artifactPath)
+		if err := os.RemoveAll(buildOutput.BuildOutputPath); err != nil {
+			log.Printf("failed removing build output of service %s: %v", serviceConfig.Name, err)
+			return nil
+		}
+
+		if err := copy.Copy(outputPath, buildOutput.BuildOutputPath); err != nil {
+			log.Printf("failed restoring shared build of service %s: %v", serviceConfig.Name, err)
+			return nil
+		}
+	}
+
+	if !cacher.BuildOutputExists(ctx, serviceConfig, buildOutput) {
+		log.Printf("not reusing shared build of service %s, its output %s no longer exists",
+			serviceConfig.Name, buildOutput.BuildOutputPath)
+		return nil
+	}
+
+	// Builds are kept in the cache while they are reused
+	now := time.Now()
+	if err := os.Chtimes(filepath.Join(buildDir, sharedBuildEntryFileName), now, now); err != nil {
+		log.Printf("failed touching shared build of service %s: %v", serviceConfig.Name, err)
+	}
+
+	return buildOutput
+}
+
+// Stores the build of the service with the digest of its inputs. Outputs within the directory of the service are copied to
+// the cache, outputs that aren't on the file system are recorded as they are and reused while they exist, ex) the images
+// of docker builds. Other outputs on the file system aren't stored, ex) the directory of the service.
+func (c *sharedBuildCache) store(serviceConfig *ServiceConfig, digest string, buildOutput *ServiceBuildResult) error {
+	if c.root == "" {
+		return nil
+	}
+
+	artifact, isArtifact := buildArtifactPath(serviceConfig, buildOutput)
+	if !isArtifact && filepath.IsAbs(buildOutput.BuildOutputPath) {
+		log.Printf("not sharing build of service %s, its output %s isn't copied to the cache",
+			serviceConfig.Name, buildOutput.BuildOutputPath)
+		return nil
+	}
+
+	buildDir := filepath.Join(c.root, digest)
+	if _, err := os.Stat(filepath.Join(buildDir, sharedBuildEntryFileName)); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.root, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating build cache directory: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp(c.root, digest+"-*"+sharedBuildTempSuffix)
+	if err != nil {
+		return fmt.Errorf("creating build cache directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	entry := &BuildCacheEntry{
+		BuiltAt:         time.Now().UTC(),
+		Digest:          digest,
+		BuildOutputPath: buildOutput.BuildOutputPath,
+	}
+
+	if isArtifact {
+		outputPath := filepath.Join(tempDir, sharedBuildOutputName)
+		if err := copy.Copy(buildOutput.BuildOutputPath, outputPath); err != nil {
+			return fmt.Errorf("copying build output: %w", err)
+		}
+
+		contentHash, err := hashBuildOutput(outputPath)
+		if err != nil {
+			return fmt.Errorf("hashing build output: %w", err)
+		}
+
+		entry.BuildOutputPath = ""
+		entry.Artifact = artifact
+		entry.ContentHash = contentHash
+	}
+
+	contents, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling build cache entry: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, sharedBuildEntryFileName), contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing build cache entry: %w", err)
+	}
+
+	// Another azd process may have stored the same build in the meantime, in which case its build is kept
+	if err := os.Rename(tempDir, buildDir); err != nil {
+		log.Printf("not storing shared build of service %s, the build is already stored: %v", serviceConfig.Name, err)
+	}
+
+	c.prune()
+	return nil
+}
+
+// Removes the builds which haven't been stored or reused for the maximum age of the builds of the cache, and the temporary
+// directories of builds which were never moved to their directory, ex) when azd was interrupted
+func (c *sharedBuildCache) prune() {
+	dirs, err := os.ReadDir(c.root)
+	if err != nil {
+		log.Printf("failed pruning build cache: %v", err)
+		return
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(c.root, dir.Name(), sharedBuildEntryFileName))
+		if strings.HasSuffix(dir.Name(), sharedBuildTempSuffix) {
+			info, err = dir.Info()
+		}
+
+		if err != nil || time.Since(info.ModTime()) < sharedBuildCacheMaxAge {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(c.root, dir.Name())); err != nil {
+			log.Printf("failed removing build %s from build cache: %v", dir.Name(), err)
+		}
+	}
+}
+
+// Computes the SHA-256 digest of the contents of a build output copied to the shared build cache, a file or a directory.
+// The digest covers the paths, the types and the contents of the files of the output, walked in lexical order.
+func hashBuildOutput(outputPath string) (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(outputPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(outputPath, path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hash, "%s\x00%s\x00", filepath.ToSlash(relativePath), entry.Type().String())
+		switch {
+		case entry.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(hash, "%s\x00", target)
+		case entry.Type().IsRegular():
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			if _, err := io.Copy(hash, file); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Gets the path of the output of the build relative to the directory of the service, when the output is a file or a
+// directory within the directory of the service. Services whose output is the directory of the service have no output of
+// their own to copy.
+func buildArtifactPath(serviceConfig *ServiceConfig, buildOutput *ServiceBuildResult) (string, bool) {
+	if !buildOutputPathExists(buildOutput) || !filepath.IsAbs(buildOutput.BuildOutputPath) {
+		return "", false
+	}
+
+	relativePath, err := filepath.Rel(serviceConfig.Path(), buildOutput.BuildOutputPath)
+	if err != nil || relativePath == "." || relativePath == ".." ||
+		strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(relativePath), true
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const buildCacheTestProject = `
+name: test-app
+services:
+  web:
+    project: src/web
+    language: ts
+    host: appservice
+    dist: dist
+`
+
+func Test_ServiceManager_BuildWithCache(t *testing.T) {
+	t.Setenv(BuildCacheDirEnvVarName, t.TempDir())
+	mockContext := mocks.NewMockContext(context.Background())
+
+	projectPath := t.TempDir()
+	servicePath := filepath.Join(projectPath, "src", "web")
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	writeFile(filepath.Join(projectPath, "azure.yaml"), buildCacheTestProject)
+	writeFile(filepath.Join(servicePath, "package.json"), `{"scripts": {"build": "tsc"}}`)
+	writeFile(filepath.Join(servicePath, ".gitignore"), "*.log\n")
+	writeFile(filepath.Join(servicePath, "index.ts"), "console.log('hello')")
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "node --version")
+	}).Respond(exec.NewRunResult(0, "v20.11.1", ""))
+
+	builds := 0
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "npm run build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		builds++
+		writeFile(filepath.Join(servicePath, "dist", "index.js"), "console.log('hello')")
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	projectConfig, err := Load(*mockContext.Context, filepath.Join(projectPath, "azure.yaml"))
+	require.NoError(t, err)
+	serviceConfig := projectConfig.Services["web"]
+
+	env := environment.EmptyWithRoot(t.TempDir())
+	env.Values["API_URL"] = "https://api.contoso.com"
+	sm := &serviceManager{env: env, buildCache: newBuildCache(env)}
+	npmProject := NewNpmProject(npm.NewNpmCli(mockContext.CommandRunner), env)
+
+	build := func() *ServiceBuildResult {
+		buildTask := sm.buildWithCache(*mockContext.Context, npmProject, serviceConfig, nil)
+		logProgress(buildTask)
+		buildResult, err := buildTask.Await()
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(servicePath, "dist"), buildResult.BuildOutputPath)
+		return buildResult
+	}
+
+	build()
+	require.Equal(t, 1, builds)
+	require.FileExists(t, filepath.Join(env.Root, BuildCacheFileName))
+
+	// Outputs of the build and files excluded by .gitignore aren't inputs of the build
+	writeFile(filepath.Join(servicePath, "debug.log"), "built")
+	build()
+	require.Equal(t, 1, builds)
+
+	writeFile(filepath.Join(servicePath, "index.ts"), "console.log('hello world')")
+	build()
+	require.Equal(t, 2, builds)
+
+	// The build script runs with the values of the environment
+	env.Values["API_URL"] = "https://api.fabrikam.com"
+	build()
+	require.Equal(t, 3, builds)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(servicePath, "dist")))
+	build()
+	require.Equal(t, 4, builds)
+
+	serviceConfig.Docker.NoCache = true
+	build()
+	require.Equal(t, 5, builds)
+}
+
+func Test_ServiceManager_BuildWithSharedCache(t *testing.T) {
+	t.Setenv(BuildCacheDirEnvVarName, t.TempDir())
+	mockContext := mocks.NewMockContext(context.Background())
+
+	projectPath := t.TempDir()
+	servicePath := filepath.Join(projectPath, "src", "web")
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	writeFile(filepath.Join(projectPath, "azure.yaml"), buildCacheTestProject)
+	writeFile(filepath.Join(servicePath, "package.json"), `{"scripts": {"build": "tsc"}}`)
+	writeFile(filepath.Join(servicePath, "index.ts"), "console.log('hello')")
+
+	nodeVersion := "v20.11.1"
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "node --version")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, nodeVersion, ""), nil
+	})
+
+	builds := 0
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "npm run build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		builds++
+		writeFile(filepath.Join(servicePath, "dist", "index.js"), "console.log('hello')")
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	projectConfig, err := Load(*mockContext.Context, filepath.Join(projectPath, "azure.yaml"))
+	require.NoError(t, err)
+	serviceConfig := projectConfig.Services["web"]
+
+	// Builds the service in a new environment of the project
+	build := func(envName string) {
+		env := environment.EmptyWithRoot(filepath.Join(projectPath, ".azure", envName))
+		sm := &serviceManager{env: env, buildCache: newBuildCache(env)}
+		npmProject := NewNpmProject(npm.NewNpmCli(mockContext.CommandRunner), env)
+
+		buildTask := sm.buildWithCache(*mockContext.Context, npmProject, serviceConfig, nil)
+		logProgress(buildTask)
+		buildResult, err := buildTask.Await()
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(servicePath, "dist"), buildResult.BuildOutputPath)
+		require.FileExists(t, filepath.Join(servicePath, "dist", "index.js"))
+	}
+
+	build("dev")
+	require.Equal(t, 1, builds)
+
+	// The output of the build is restored from the shared build cache
+	require.NoError(t, os.RemoveAll(filepath.Join(servicePath, "dist")))
+	build("staging")
+	require.Equal(t, 1, builds)
+	require.FileExists(t, filepath.Join(projectPath, ".azure", "staging", BuildCacheFileName))
+
+	// Builds whose contents were changed in the cache aren't restored
+	cachedFiles, err := filepath.Glob(filepath.Join(os.Getenv(BuildCacheDirEnvVarName), "*", "*", "index.js"))
+	require.NoError(t, err)
+	require.Len(t, cachedFiles, 1)
+	writeFile(cachedFiles[0], "console.log('tampered')")
+	require.NoError(t, os.RemoveAll(filepath.Join(servicePath, "dist")))
+	build("qa")
+	require.Equal(t, 2, builds)
+	contents, err := os.ReadFile(filepath.Join(servicePath, "dist", "index.js"))
+	require.NoError(t, err)
+	require.Equal(t, "console.log('hello')", string(contents))
+
+	// Builds aren't reused with another version of the toolchain
+	nodeVersion = "v22.1.0"
+	build("prod")
+	require.Equal(t, 3, builds)
+}
+
+func Test_BuildArtifactPath(t *testing.T) {
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageTypeScript)
+	distPath := filepath.Join(serviceConfig.Path(), "dist")
+	require.NoError(t, os.MkdirAll(distPath, osutil.PermissionDirectory))
+
+	artifact, ok := buildArtifactPath(serviceConfig, &ServiceBuildResult{BuildOutputPath: distPath})
+	require.True(t, ok)
+	require.Equal(t, "dist", artifact)
+
+	// Services deployed from their directory and images have no output to copy
+	_, ok = buildArtifactPath(serviceConfig, &ServiceBuildResult{BuildOutputPath: serviceConfig.Path()})
+	require.False(t, ok)
+
+	_, ok = buildArtifactPath(serviceConfig, &ServiceBuildResult{BuildOutputPath: "sha256:2c26b46b68ffc68ff99b453c1d3041"})
+	require.False(t, ok)
+}
+
+func Test_SharedBuildCache_StoreOnlyArtifacts(t *testing.T) {
+	cache := &sharedBuildCache{root: t.TempDir()}
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageTypeScript)
+
+	// Outputs on the file system that aren't copied to the cache aren't shared
+	outsidePath := t.TempDir()
+	require.NoError(t, cache.store(serviceConfig, "outside", &ServiceBuildResult{BuildOutputPath: outsidePath}))
+	require.NoDirExists(t, filepath.Join(cache.root, "outside"))
+
+	require.NoError(t, cache.store(serviceConfig, "service", &ServiceBuildResult{BuildOutputPath: serviceConfig.Path()}))
+	require.NoDirExists(t, filepath.Join(cache.root, "service"))
+
+	// Images are shared and reused while they exist
+	image := "sha256:2c26b46b68ffc68ff99b453c1d3041"
+	require.NoError(t, cache.store(serviceConfig, "image", &ServiceBuildResult{BuildOutputPath: image}))
+	require.FileExists(t, filepath.Join(cache.root, "image", sharedBuildEntryFileName))
+}
@@ -0,0 +1,299 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ServicePackageFunc restores, builds and packages a service
+type ServicePackageFunc func(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress]
+
+// ServiceRestoreFunc restores the dependencies of a service
+type ServiceRestoreFunc func(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress]
+
+// BuildProgress is the progress of a service processed by the BuildOrchestrator. The progress of the services is
+// interleaved, each service reports its progress messages then a final progress when its processing completes.
+type BuildProgress struct {
+	Service *ServiceConfig
+	Message string
+	// Set on the final progress of the service
+	Done bool
+	// The time the processing of the service started, set on the final progress
+	StartedAt time.Time
+	// The result of the service, set on the final progress of services packaged successfully
+	Result *ServiceDeployResult
+	// The error of the service, set on the final progress of services that failed
+	Error error
+}
+
+// BuildResults are the packaged services by name
+type BuildResults struct {
+	Services map[string]*ServiceDeployResult
+}
+
+// RestoreResults are the restored services by name
+type RestoreResults struct {
+	Services map[string]*ServiceRestoreResult
+}
+
+// BuildOrchestrator restores and packages the services of a project concurrently, up to a configurable limit. Services
+// are started in order once the services they depend on completed, and when a service fails, the services that aren't
+// started yet are skipped.
+type BuildOrchestrator struct {
+	maxConcurrency int
+}
+
+// Creates a new instance of the BuildOrchestrator, processing as many services concurrently as there are CPUs.
+// Can be overridden with the AZD_BUILD_MAX_CONCURRENCY environment variable.
+func NewBuildOrchestrator() *BuildOrchestrator {
+	maxConcurrency := runtime.NumCPU()
+	if value := os.Getenv("AZD_BUILD_MAX_CONCURRENCY"); value != "" {
+		if val, err := strconv.ParseInt(value, 10, 0); err == nil && val > 0 {
+			maxConcurrency = int(val)
+		}
+	}
+
+	return &BuildOrchestrator{maxConcurrency: maxConcurrency}
+}
+
+// SetMaxConcurrency sets the maximum number of services processed concurrently, ex) from the --concurrency flag.
+// Values lower than 1 keep the default.
+func (o *BuildOrchestrator) SetMaxConcurrency(maxConcurrency int) {
+	if maxConcurrency > 0 {
+		o.maxConcurrency = maxConcurrency
+	}
+}
+
+// Package packages the services with the package function, reporting the progress of all services through the task.
+// The task fails with the errors of the failed services.
+func (o *BuildOrchestrator) Package(
+	ctx context.Context,
+	services []*ServiceConfig,
+	packageFn ServicePackageFunc,
+) *async.TaskWithProgress[*BuildResults, BuildProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*BuildResults, BuildProgress]) {
+			results, err := runServices(ctx, o.maxConcurrency, services, "packaging", packageFn,
+				func(progress BuildProgress, result *ServiceDeployResult) {
+					progress.Result = result
+					task.SetProgress(progress)
+				})
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&BuildResults{Services: results})
+		},
+	)
+}
+
+// Restore restores the dependencies of the services with the restore function, reporting the progress of all services
+// through the task. The task fails with the errors of the failed services.
+func (o *BuildOrchestrator) Restore(
+	ctx context.Context,
+	services []*ServiceConfig,
+	restoreFn ServiceRestoreFunc,
+) *async.TaskWithProgress[*RestoreResults, BuildProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*RestoreResults, BuildProgress]) {
+			results, err := runServices(ctx, o.maxConcurrency, services, "restoring", restoreFn,
+				func(progress BuildProgress, _ *ServiceRestoreResult) {
+					task.SetProgress(progress)
+				})
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&RestoreResults{Services: results})
+		},
+	)
+}
+
+// The completion of a service run by runServices
+type serviceCompletion[T comparable] struct {
+	index  int
+	result T
+	err    error
+}
+
+// Runs the function for the services concurrently, up to the maximum concurrency, reporting their progress. Services
+// are started in order once the services they depend on, among the services, completed successfully. When a service
+// fails, the services that aren't started yet are skipped. Returns the results of the services by name.
+func runServices[T comparable](
+	ctx context.Context,
+	maxConcurrency int,
+	services []*ServiceConfig,
+	operation string,
+	runFn func(ctx context.Context, serviceConfig *ServiceConfig) *async.TaskWithProgress[T, ServiceProgress],
+	report func(progress BuildProgress, result T),
+) (map[string]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	selected := map[string]bool{}
+	for _, serviceConfig := range services {
+		selected[serviceConfig.Name] = true
+	}
+
+	results := map[string]T{}
+	completed := map[string]bool{}
+	errs := make([]error, len(services))
+	completions := make(chan serviceCompletion[T], len(services))
+	pending := slices.Clone(services)
+	running := 0
+
+	// Dependencies outside of the services, ex) when a single service is deployed, aren't waited for
+	ready := func(serviceConfig *ServiceConfig) bool {
+		for _, dependency := range serviceConfig.DependsOn {
+			if selected[dependency] && !completed[dependency] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	complete := func(completion serviceCompletion[T]) {
+		running--
+		serviceConfig := services[completion.index]
+		if completion.err != nil {
+			errs[completion.index] = fmt.Errorf("%s service %s: %w", operation, serviceConfig.Name, completion.err)
+			cancel()
+			return
+		}
+
+		completed[serviceConfig.Name] = true
+		results[serviceConfig.Name] = completion.result
+	}
+
+	for len(pending) > 0 && ctx.Err() == nil {
+		next := slices.IndexFunc(pending, ready)
+		if next < 0 && running == 0 {
+			// Dependency cycles are rejected when the project is loaded
+			errs = append(errs, fmt.Errorf("%s services: the dependencies of the remaining services can't complete", operation))
+			break
+		}
+
+		if next < 0 || running >= maxConcurrency {
+			select {
+			case <-ctx.Done():
+			case completion := <-completions:
+				complete(completion)
+			}
+
+			continue
+		}
+
+		serviceConfig := pending[next]
+		index := slices.Index(services, serviceConfig)
+		pending = slices.Delete(pending, next, next+1)
+		running++
+
+		go func() {
+			startedAt := time.Now()
+			runTask := runFn(ctx, serviceConfig)
+			for progress := range runTask.Progress() {
+				report(BuildProgress{Service: serviceConfig, Message: progress.Message}, *new(T))
+			}
+
+			result, err := runTask.Await()
+			report(BuildProgress{
+				Service:   serviceConfig,
+				Done:      true,
+				StartedAt: startedAt,
+				Error:     err,
+			}, result)
+
+			completions <- serviceCompletion[T]{index: index, result: result, err: err}
+		}()
+	}
+
+	// A service failed, the services already started complete
+	for running > 0 {
+		complete(<-completions)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil && len(results) < len(services) {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Checks that the services depend on services of the project, without cycles
+func validateDependsOn(projectConfig *ProjectConfig) error {
+	names := maps.Keys(projectConfig.Services)
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, dependency := range projectConfig.Services[name].DependsOn {
+			if dependency == name {
+				return fmt.Errorf("parsing service %s: the service depends on itself", name)
+			}
+
+			if _, has := projectConfig.Services[dependency]; !has {
+				return fmt.Errorf("parsing service %s: depends on service '%s', which isn't in the project", name, dependency)
+			}
+		}
+	}
+
+	// Services being visited are on the path of the current service, visiting them again means a cycle
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	states := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch states[name] {
+		case visiting:
+			return fmt.Errorf("services have circular dependencies: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+
+		states[name] = visiting
+		for _, dependency := range projectConfig.Services[name].DependsOn {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+
+		states[name] = visited
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+func Test_BuildOrchestrator_Package(t *testing.T) {
+	services := []*ServiceConfig{}
+	for i := 0; i < 5; i++ {
+		services = append(services, &ServiceConfig{Name: fmt.Sprintf("svc%d", i)})
+	}
+
+	t.Run("BoundedConcurrency", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+		orchestrator := &BuildOrchestrator{maxConcurrency: 2}
+
+		task := orchestrator.Package(context.Background(), services, func(
+			ctx context.Context,
+			serviceConfig *ServiceConfig,
+		) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+			return async.RunTaskWithProgress(
+				func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+					current := running.Add(1)
+					defer running.Add(-1)
+					for {
+						highest := maxRunning.Load()
+						if current <= highest || maxRunning.CompareAndSwap(highest, current) {
+							break
+						}
+					}
+
+					task.SetProgress(NewServiceProgress("Building"))
+					time.Sleep(10 * time.Millisecond)
+					task.SetResult(&ServiceDeployResult{Package: &ServicePackageResult{PackagePath: serviceConfig.Name}})
+				},
+			)
+		})
+
+		messages := map[string][]string{}
+		for progress := range task.Progress() {
+			if progress.Done {
+				require.NoError(t, progress.Error)
+				require.False(t, progress.StartedAt.IsZero())
+				messages[progress.Service.Name] = append(messages[progress.Service.Name], "Done")
+			} else {
+				messages[progress.Service.Name] = append(messages[progress.Service.Name], progress.Message)
+			}
+		}
+
+		results, err := task.Await()
+		require.NoError(t, err)
+		require.Len(t, results.Services, len(services))
+		require.Equal(t, "svc3", results.Services["svc3"].Package.PackagePath)
+		require.Equal(t, []string{"Building", "Done"}, messages["svc4"])
+		require.LessOrEqual(t, maxRunning.Load(), int32(2))
+	})
+
+	t.Run("SkipsRemainingServicesOnFailure", func(t *testing.T) {
+		var mutex sync.Mutex
+		started := []string{}
+		orchestrator := &BuildOrchestrator{maxConcurrency: 1}
+
+		task := orchestrator.Package(context.Background(), services, func(
+			ctx context.Context,
+			serviceConfig *ServiceConfig,
+		) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+			mutex.Lock()
+			started = append(started, serviceConfig.Name)
+			mutex.Unlock()
+
+			return async.RunTaskWithProgress(
+				func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+					if serviceConfig.Name == "svc1" {
+						task.SetError(errors.New("build failed"))
+						return
+					}
+
+					task.SetResult(&ServiceDeployResult{})
+				},
+			)
+		})
+
+		for range task.Progress() {
+		}
+
+		_, err := task.Await()
+		require.ErrorContains(t, err, "packaging service svc1: build failed")
+		require.Equal(t, []string{"svc0", "svc1"}, started)
+	})
+	t.Run("WaitsForDependencies", func(t *testing.T) {
+		var mutex sync.Mutex
+		events := []string{}
+		orchestrator := &BuildOrchestrator{maxConcurrency: 3}
+
+		dependent := []*ServiceConfig{
+			{Name: "web", DependsOn: []string{"api"}},
+			{Name: "api"},
+			// Dependencies outside of the services aren't waited for
+			{Name: "worker", DependsOn: []string{"jobs"}},
+		}
+
+		task := orchestrator.Package(context.Background(), dependent, func(
+			ctx context.Context,
+			serviceConfig *ServiceConfig,
+		) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+			return async.RunTaskWithProgress(
+				func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+					mutex.Lock()
+					events = append(events, "start "+serviceConfig.Name)
+					mutex.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+
+					mutex.Lock()
+					events = append(events, "done "+serviceConfig.Name)
+					mutex.Unlock()
+					task.SetResult(&ServiceDeployResult{})
+				},
+			)
+		})
+
+		for range task.Progress() {
+		}
+
+		results, err := task.Await()
+		require.NoError(t, err)
+		require.Len(t, results.Services, 3)
+		require.Less(t, slices.Index(events, "done api"), slices.Index(events, "start web"))
+		require.Less(t, slices.Index(events, "start worker"), slices.Index(events, "done api"))
+	})
+}
+
+func Test_BuildOrchestrator_Restore(t *testing.T) {
+	services := []*ServiceConfig{{Name: "api"}, {Name: "web"}}
+	orchestrator := &BuildOrchestrator{maxConcurrency: 2}
+
+	task := orchestrator.Restore(context.Background(), services, func(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+	) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+		return async.RunTaskWithProgress(
+			func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+				if serviceConfig.Name == "web" {
+					task.SetError(errors.New("npm install failed"))
+					return
+				}
+
+				task.SetResult(&ServiceRestoreResult{})
+			},
+		)
+	})
+
+	done := 0
+	for progress := range task.Progress() {
+		if progress.Done {
+			done++
+		}
+	}
+
+	_, err := task.Await()
+	require.ErrorContains(t, err, "restoring service web: npm install failed")
+	require.Equal(t, 2, done)
+}
+
+const dependsOnTestProject = `
+name: test-app
+services:
+  api:
+    project: src/api
+    host: appservice
+    dependsOn: %s
+  web:
+    project: src/web
+    host: appservice
+    dependsOn: %s
+`
+
+func Test_ValidateDependsOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiDeps string
+		webDeps string
+		wantErr string
+	}{
+		{
+			name:    "Valid",
+			apiDeps: "[]",
+			webDeps: "[api]",
+		},
+		{
+			name:    "UnknownService",
+			apiDeps: "[]",
+			webDeps: "[jobs]",
+			wantErr: "depends on service 'jobs', which isn't in the project",
+		},
+		{
+			name:    "Cycle",
+			apiDeps: "[web]",
+			webDeps: "[api]",
+			wantErr: "services have circular dependencies: api -> web -> api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(context.Background(), fmt.Sprintf(dependsOnTestProject, tt.apiDeps, tt.webDeps))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
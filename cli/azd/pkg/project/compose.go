@@ -0,0 +1,315 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// The compose files looked up in the service directory when the compose file isn't set, in order
+var defaultComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// ComposeOptions are the compose file of a service of language compose. Each compose service of the compose file is
+// deployed as a service of the project, to its own container app.
+type ComposeOptions struct {
+	// The path of the compose file relative to the service path. Defaults to compose.yaml, compose.yml,
+	// docker-compose.yaml or docker-compose.yml, the first found.
+	File string `yaml:"file"`
+	// The compose services deployed, ex) [web, api]. Defaults to all compose services.
+	Services []string `yaml:"services"`
+}
+
+// The subset of a compose file used to map compose services to services of the project
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image    string        `yaml:"image"`
+	Build    *composeBuild `yaml:"build"`
+	Platform string        `yaml:"platform"`
+	Ports    []composePort `yaml:"ports"`
+	Expose   []string      `yaml:"expose"`
+}
+
+// The build of a compose service, either the path of the build context or the build options
+type composeBuild struct {
+	Context    string          `yaml:"context"`
+	Dockerfile string          `yaml:"dockerfile"`
+	Target     string          `yaml:"target"`
+	Args       composeMappings `yaml:"args"`
+}
+
+func (b *composeBuild) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		b.Context = value.Value
+		return nil
+	}
+
+	type build composeBuild
+	return value.Decode((*build)(b))
+}
+
+// Mappings of compose files, either a map or a list of KEY=VALUE entries
+type composeMappings map[string]string
+
+func (m *composeMappings) UnmarshalYAML(value *yaml.Node) error {
+	mappings := map[string]string{}
+	if value.Kind == yaml.SequenceNode {
+		entries := []string{}
+		if err := value.Decode(&entries); err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			key, val, _ := strings.Cut(entry, "=")
+			mappings[key] = val
+		}
+	} else if err := value.Decode(&mappings); err != nil {
+		return err
+	}
+
+	*m = mappings
+	return nil
+}
+
+// A port published by a compose service, either in the short syntax, ex) 8080:80/tcp, or the long syntax
+type composePort struct {
+	Target   int
+	Protocol string
+}
+
+func (p *composePort) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var port struct {
+			Target   int    `yaml:"target"`
+			Protocol string `yaml:"protocol"`
+		}
+		if err := value.Decode(&port); err != nil {
+			return err
+		}
+
+		p.Target, p.Protocol = port.Target, port.Protocol
+		return nil
+	}
+
+	// The container port is the last part of the short syntax, ex) 127.0.0.1:8080:80/tcp
+	ports, protocol, _ := strings.Cut(value.Value, "/")
+	ports = ports[strings.LastIndex(ports, ":")+1:]
+	// Ranges of ports, ex) 8000-8010, publish the first port of the range to the ingress
+	ports, _, _ = strings.Cut(ports, "-")
+
+	target, err := strconv.Atoi(ports)
+	if err != nil {
+		return fmt.Errorf("invalid port '%s'", value.Value)
+	}
+
+	p.Target, p.Protocol = target, protocol
+	return nil
+}
+
+// Gets the port of the container of the compose service the ingress routes requests to, the first TCP port published or
+// exposed by the compose service, or 0 when the compose service doesn't publish a port
+func (s composeService) targetPort() int {
+	for _, port := range s.Ports {
+		if port.Protocol == "" || port.Protocol == "tcp" {
+			return port.Target
+		}
+	}
+
+	for _, expose := range s.Expose {
+		port, protocol, _ := strings.Cut(expose, "/")
+		if target, err := strconv.Atoi(port); err == nil && (protocol == "" || protocol == "tcp") {
+			return target
+		}
+	}
+
+	return 0
+}
+
+// Validates the compose options of the service
+func validateCompose(serviceConfig *ServiceConfig) error {
+	if serviceConfig.Language != ServiceLanguageCompose {
+		if serviceConfig.Compose.File != "" || len(serviceConfig.Compose.Services) > 0 {
+			return errors.New("'compose' is only supported for services of language 'compose'")
+		}
+
+		return nil
+	}
+
+	if serviceConfig.Host != ContainerAppTarget {
+		return fmt.Errorf(
+			"compose services are deployed to container apps, host '%s' isn't supported for language 'compose'",
+			serviceConfig.Host,
+		)
+	}
+
+	return nil
+}
+
+// Replaces the services of language compose of the project with a service for each compose service of their compose
+// file. The services of the compose services are built from the build of their compose service, or deploy the image of
+// their compose service when they aren't built.
+func expandComposeServices(projectConfig *ProjectConfig) error {
+	names := maps.Keys(projectConfig.Services)
+	slices.Sort(names)
+
+	for _, name := range names {
+		serviceConfig := projectConfig.Services[name]
+		if serviceConfig.Language != ServiceLanguageCompose || serviceConfig.composeService != "" {
+			continue
+		}
+
+		composePath, err := findComposeFile(serviceConfig)
+		if err != nil {
+			return fmt.Errorf("loading compose file of service %s: %w", name, err)
+		}
+
+		compose, err := readComposeFile(composePath)
+		if err != nil {
+			return fmt.Errorf("loading compose file of service %s: %w", name, err)
+		}
+
+		composeServices := serviceConfig.Compose.Services
+		if len(composeServices) == 0 {
+			composeServices = maps.Keys(compose.Services)
+			slices.Sort(composeServices)
+		}
+
+		delete(projectConfig.Services, name)
+		for _, composeName := range composeServices {
+			composeService, has := compose.Services[composeName]
+			if !has {
+				return fmt.Errorf("service %s deploys compose service '%s', which isn't in %s", name, composeName, composePath)
+			}
+
+			if _, has := projectConfig.Services[composeName]; has {
+				return fmt.Errorf(
+					"compose service '%s' of service %s has the name of another service of the project",
+					composeName,
+					name,
+				)
+			}
+
+			expanded, err := newComposeServiceConfig(serviceConfig, composePath, composeName, composeService)
+			if err != nil {
+				return fmt.Errorf("mapping compose service '%s' of service %s: %w", composeName, name, err)
+			}
+
+			log.Printf("deploying compose service %s of %s as service %s", composeName, composePath, expanded.Name)
+			projectConfig.Services[composeName] = expanded
+		}
+	}
+
+	return nil
+}
+
+// Finds the compose file of the service, the configured compose file or the first default compose file found
+func findComposeFile(serviceConfig *ServiceConfig) (string, error) {
+	if serviceConfig.Compose.File != "" {
+		return filepath.Join(serviceConfig.Path(), serviceConfig.Compose.File), nil
+	}
+
+	for _, name := range defaultComposeFiles {
+		path := filepath.Join(serviceConfig.Path(), name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf(
+		"no compose file found in '%s', set 'compose.file' or add one of: %s",
+		serviceConfig.Path(),
+		strings.Join(defaultComposeFiles, ", "),
+	)
+}
+
+func readComposeFile(path string) (*composeFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading compose file: %w", err)
+	}
+
+	compose := &composeFile{}
+	if err := yaml.Unmarshal(content, compose); err != nil {
+		return nil, fmt.Errorf("parsing compose file '%s': %w", path, err)
+	}
+
+	return compose, nil
+}
+
+// Creates the service of a compose service from the service of language compose declaring the compose file. The
+// service inherits the host, docker and container app options of the service declaring the compose file.
+func newComposeServiceConfig(
+	serviceConfig *ServiceConfig,
+	composePath string,
+	name string,
+	composeService composeService,
+) (*ServiceConfig, error) {
+	composeDir, err := filepath.Rel(serviceConfig.Project.Path, filepath.Dir(composePath))
+	if err != nil {
+		return nil, err
+	}
+
+	expanded := &ServiceConfig{
+		Project:         serviceConfig.Project,
+		Name:            name,
+		RelativePath:    composeDir,
+		Host:            serviceConfig.Host,
+		Language:        ServiceLanguageCompose,
+		Module:          name,
+		Docker:          serviceConfig.Docker,
+		ContainerApp:    serviceConfig.ContainerApp,
+		Infra:           serviceConfig.Infra,
+		EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
+		composeService:  name,
+	}
+
+	if composeService.Platform != "" {
+		expanded.Docker.Platform = composeService.Platform
+	}
+
+	if expanded.ContainerApp.Ingress.TargetPort == 0 {
+		expanded.ContainerApp.Ingress.TargetPort = composeService.targetPort()
+	}
+
+	build := composeService.Build
+	if build == nil {
+		if composeService.Image == "" {
+			return nil, errors.New("the compose service has no build and no image")
+		}
+
+		expanded.Docker.Image = NewExpandableString(composeService.Image)
+		return expanded, nil
+	}
+
+	// Paths of the build are relative to the directory of the compose file
+	if build.Context != "" {
+		expanded.RelativePath = filepath.Join(composeDir, build.Context)
+	}
+
+	expanded.Docker.Path = build.Dockerfile
+	expanded.Docker.Context = "."
+	expanded.Docker.Target = build.Target
+	expanded.Docker.Image = NewExpandableString("")
+	if len(build.Args) > 0 {
+		expanded.Docker.BuildArgs = map[string]ExpandableString{}
+		for key, value := range build.Args {
+			expanded.Docker.BuildArgs[key] = NewExpandableString(value)
+		}
+	}
+
+	return expanded, nil
+}
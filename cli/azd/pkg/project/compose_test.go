@@ -0,0 +1,110 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testComposeFile = `
+services:
+  web:
+    build: ./web
+    ports:
+      - "8080:3000"
+  api:
+    build:
+      context: ./api
+      dockerfile: Dockerfile.prod
+      target: runtime
+      args:
+        - VERSION=1.0
+    expose:
+      - "5000"
+  cache:
+    image: redis:7
+    ports:
+      - target: 6379
+        published: 6379
+`
+
+func Test_Load_Compose(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  app:
+    project: ./src
+    language: compose
+    host: containerapp
+    docker:
+      remoteBuild: true
+`
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, "src"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "src", "docker-compose.yml"), []byte(testComposeFile), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "azure.yaml"), []byte(testProj), 0600))
+
+	projectConfig, err := Load(context.Background(), filepath.Join(projectDir, "azure.yaml"))
+	require.NoError(t, err)
+	require.Len(t, projectConfig.Services, 3)
+	require.NotContains(t, projectConfig.Services, "app")
+
+	web := projectConfig.Services["web"]
+	require.Equal(t, filepath.Join(projectDir, "src", "web"), web.Path())
+	require.Equal(t, ContainerAppTarget, web.Host)
+	require.Equal(t, ServiceLanguageCompose, web.Language)
+	require.Equal(t, "web", web.Module)
+	require.Equal(t, 3000, web.ContainerApp.Ingress.TargetPort)
+	// The docker options of the service of the compose file are inherited
+	require.True(t, web.Docker.RemoteBuild)
+
+	api := projectConfig.Services["api"]
+	require.Equal(t, filepath.Join(projectDir, "src", "api"), api.Path())
+	require.Equal(t, "Dockerfile.prod", api.Docker.Path)
+	require.Equal(t, "runtime", api.Docker.Target)
+	require.Equal(t, "1.0", api.Docker.BuildArgs["VERSION"].MustEnvsubst(func(string) string { return "" }))
+	require.Equal(t, 5000, api.ContainerApp.Ingress.TargetPort)
+
+	cache := projectConfig.Services["cache"]
+	require.Equal(t, "redis:7", cache.Docker.Image.MustEnvsubst(func(string) string { return "" }))
+	require.Equal(t, 6379, cache.ContainerApp.Ingress.TargetPort)
+
+	t.Run("Subset", func(t *testing.T) {
+		subsetProj := testProj + `    compose:
+      file: docker-compose.yml
+      services: [web]
+`
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "azure.yaml"), []byte(subsetProj), 0600))
+
+		projectConfig, err := Load(context.Background(), filepath.Join(projectDir, "azure.yaml"))
+		require.NoError(t, err)
+		require.Len(t, projectConfig.Services, 1)
+		require.Contains(t, projectConfig.Services, "web")
+	})
+
+	t.Run("UnknownComposeService", func(t *testing.T) {
+		unknownProj := testProj + `    compose:
+      services: [worker]
+`
+		require.NoError(t, os.WriteFile(filepath.Join(projectDir, "azure.yaml"), []byte(unknownProj), 0600))
+
+		_, err := Load(context.Background(), filepath.Join(projectDir, "azure.yaml"))
+		require.ErrorContains(t, err, "service app deploys compose service 'worker'")
+	})
+}
+
+func Test_Parse_Compose(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  app:
+    project: ./src
+    language: compose
+    host: appservice
+`
+	_, err := Parse(context.Background(), testProj)
+	require.ErrorContains(t, err, "host 'appservice' isn't supported for language 'compose'")
+}
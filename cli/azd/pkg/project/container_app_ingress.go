@@ -0,0 +1,278 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// The service property exposing the target port of the ingress of a container app to the infrastructure,
+	// ex) SERVICE_API_TARGET_PORT
+	serviceTargetPortProperty = "TARGET_PORT"
+	// The service property exposing the transport of the ingress of a container app to the infrastructure,
+	// ex) SERVICE_API_TRANSPORT
+	serviceTransportProperty = "TRANSPORT"
+
+	// The default port of gRPC services, served over HTTP/2
+	grpcDefaultPort = 50051
+)
+
+// The transports of the ingress of container apps
+var containerAppTransports = []string{"auto", "http", "http2", "tcp"}
+
+// The ports the frameworks of each language listen on by default, used when the image doesn't declare the port
+var frameworkDefaultPorts = map[ServiceLanguageKind]int{
+	ServiceLanguageJavaScript: 3000,
+	ServiceLanguageTypeScript: 3000,
+	ServiceLanguagePython:     8000,
+	ServiceLanguageDotNet:     8080,
+	ServiceLanguageCsharp:     8080,
+	ServiceLanguageFsharp:     8080,
+	ServiceLanguageJava:       8080,
+	ServiceLanguageGo:         8080,
+	ServiceLanguageRust:       8080,
+	ServiceLanguagePhp:        8080,
+	ServiceLanguageRuby:       3000,
+}
+
+// The environment variables of images that set the port the app listens on, in order of precedence
+var portEnvVars = []string{"PORT", "ASPNETCORE_HTTP_PORTS", "ASPNETCORE_URLS"}
+
+// ContainerAppIngressOptions are the target port and transport of the ingress of the container app
+type ContainerAppIngressOptions struct {
+	// The port of the container the ingress routes requests to, ex) 8080
+	TargetPort int `yaml:"targetPort"`
+	// The transport of the ingress, auto, http, http2 or tcp
+	Transport string `yaml:"transport"`
+}
+
+// The ingress of the container app of a service, declared in azure.yaml or detected from the image of the service
+type containerAppIngress struct {
+	TargetPort int
+	Transport  string
+	// Where the target port comes from, ex) EXPOSE 8080/tcp
+	Source string
+}
+
+// A port exposed by an image, ex) 8080/tcp
+type exposedPort struct {
+	port     int
+	protocol string
+}
+
+func (p exposedPort) String() string {
+	return fmt.Sprintf("%d/%s", p.port, p.protocol)
+}
+
+// Parses the ports exposed by the image, ignoring malformed ports
+func parseExposedPorts(imageConfig *docker.ImageConfig) []exposedPort {
+	ports := []exposedPort{}
+	if imageConfig == nil {
+		return ports
+	}
+
+	for _, value := range imageConfig.ExposedPorts {
+		portValue, protocol, has := strings.Cut(value, "/")
+		if !has {
+			protocol = "tcp"
+		}
+
+		port, err := strconv.Atoi(portValue)
+		if err != nil {
+			continue
+		}
+
+		ports = append(ports, exposedPort{port: port, protocol: protocol})
+	}
+
+	slices.SortFunc(ports, func(a, b exposedPort) bool { return a.port < b.port })
+	return ports
+}
+
+// Gets the port the app of the image listens on from the environment variables of the image, ex) PORT=8080 or
+// ASPNETCORE_URLS=http://+:8080. Returns the port and the variable setting it, or 0 when no variable sets the port.
+func envPort(imageConfig *docker.ImageConfig) (int, string) {
+	if imageConfig == nil {
+		return 0, ""
+	}
+
+	values := map[string]string{}
+	for _, env := range imageConfig.Env {
+		if key, value, has := strings.Cut(env, "="); has {
+			values[key] = value
+		}
+	}
+
+	for _, key := range portEnvVars {
+		value, has := values[key]
+		if !has {
+			continue
+		}
+
+		// Lists of ports and URLs are separated by ; ex) http://+:8080;http://+:8081
+		value, _, _ = strings.Cut(value, ";")
+		if index := strings.LastIndex(value, ":"); index >= 0 {
+			value = value[index+1:]
+		}
+
+		if port, err := strconv.Atoi(strings.TrimSuffix(value, "/")); err == nil && port > 0 {
+			return port, key
+		}
+	}
+
+	return 0, ""
+}
+
+// Resolves the ingress of the container app of the service. The ingress declared in azure.yaml is validated against the
+// ports exposed by the image. When the target port isn't declared, it's detected from the ports exposed by the image,
+// from the environment variables of the image and from the default port of the framework of the service, in order.
+// Returns no ingress when the target port isn't declared and can't be detected, and the warnings to show, if any.
+func resolveContainerAppIngress(
+	serviceConfig *ServiceConfig,
+	imageConfig *docker.ImageConfig,
+) (*containerAppIngress, []string, error) {
+	options := serviceConfig.ContainerApp.Ingress
+	if options.Transport != "" && !slices.Contains(containerAppTransports, options.Transport) {
+		return nil, nil, fmt.Errorf(
+			"unsupported ingress transport '%s' for service '%s', supported transports: %s",
+			options.Transport,
+			serviceConfig.Name,
+			strings.Join(containerAppTransports, ", "),
+		)
+	}
+
+	exposed := parseExposedPorts(imageConfig)
+	tcpPorts := []int{}
+	for _, port := range exposed {
+		if port.protocol == "tcp" {
+			tcpPorts = append(tcpPorts, port.port)
+		}
+	}
+
+	warnings := []string{}
+	if options.TargetPort > 0 {
+		if len(exposed) > 0 && !slices.Contains(tcpPorts, options.TargetPort) {
+			warnings = append(warnings, fmt.Sprintf(
+				"Service '%s' declares target port %d, but its image exposes %s. Requests to the container app time "+
+					"out when the target port isn't the port the app listens on",
+				serviceConfig.Name,
+				options.TargetPort,
+				formatExposedPorts(exposed),
+			))
+		}
+
+		return &containerAppIngress{
+			TargetPort: options.TargetPort,
+			Transport:  ingressTransport(options.Transport, options.TargetPort, imageConfig),
+			Source:     "azure.yaml",
+		}, warnings, nil
+	}
+
+	if len(exposed) > 0 && len(tcpPorts) == 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"The image of service '%s' only exposes %s, container apps only accept TCP traffic",
+			serviceConfig.Name,
+			formatExposedPorts(exposed),
+		))
+	}
+
+	// The port of images that can't be inspected is unknown, ex) images referenced from their registry
+	if imageConfig == nil {
+		return nil, warnings, nil
+	}
+
+	ingress := &containerAppIngress{}
+	port, envVar := envPort(imageConfig)
+	switch {
+	case len(tcpPorts) == 1:
+		ingress.TargetPort = tcpPorts[0]
+		ingress.Source = fmt.Sprintf("EXPOSE %d/tcp", tcpPorts[0])
+	case len(tcpPorts) > 1 && slices.Contains(tcpPorts, port):
+		ingress.TargetPort = port
+		ingress.Source = fmt.Sprintf("EXPOSE %d/tcp and %s", port, envVar)
+	case len(tcpPorts) > 1:
+		ingress.TargetPort = tcpPorts[0]
+		ingress.Source = fmt.Sprintf("EXPOSE %d/tcp", tcpPorts[0])
+		warnings = append(warnings, fmt.Sprintf(
+			"The image of service '%s' exposes %s, using target port %d. Set containerApp.ingress.targetPort in "+
+				"azure.yaml to select the port the app listens on",
+			serviceConfig.Name,
+			formatExposedPorts(exposed),
+			tcpPorts[0],
+		))
+	case port > 0:
+		ingress.TargetPort = port
+		ingress.Source = envVar
+	case frameworkDefaultPorts[serviceConfig.Language] > 0:
+		ingress.TargetPort = frameworkDefaultPorts[serviceConfig.Language]
+		ingress.Source = fmt.Sprintf("default port of %s apps", serviceConfig.Language)
+	default:
+		return nil, warnings, nil
+	}
+
+	ingress.Transport = ingressTransport(options.Transport, ingress.TargetPort, imageConfig)
+	return ingress, warnings, nil
+}
+
+// Gets the transport of the ingress, the declared transport or http2 for gRPC services, otherwise auto
+func ingressTransport(declared string, targetPort int, imageConfig *docker.ImageConfig) string {
+	if declared != "" {
+		return declared
+	}
+
+	if targetPort == grpcDefaultPort {
+		return "http2"
+	}
+
+	if imageConfig != nil {
+		for _, env := range imageConfig.Env {
+			key, value, _ := strings.Cut(env, "=")
+			if key == "ASPNETCORE_Kestrel__EndpointDefaults__Protocols" && strings.EqualFold(value, "Http2") {
+				return "http2"
+			}
+		}
+	}
+
+	return "auto"
+}
+
+// Checks the target port of the deployed container app is the target port of the ingress of the service, since the
+// infrastructure may not use the SERVICE_<NAME>_TARGET_PORT value. Returns the warning to show, if any.
+func validateDeployedIngress(
+	serviceConfig *ServiceConfig,
+	ingress *containerAppIngress,
+	deployedTargetPort int,
+) string {
+	if ingress == nil || deployedTargetPort == 0 || deployedTargetPort == ingress.TargetPort {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"The container app of service '%s' routes requests to port %d, but the app listens on port %d (%s). "+
+			"Requests time out with 'stream timeout' errors until the target port of the ingress is set to %d, ex) with "+
+			"the SERVICE_%s_%s environment value",
+		serviceConfig.Name,
+		deployedTargetPort,
+		ingress.TargetPort,
+		ingress.Source,
+		ingress.TargetPort,
+		strings.ToUpper(strings.ReplaceAll(serviceConfig.Name, "-", "_")),
+		serviceTargetPortProperty,
+	)
+}
+
+func formatExposedPorts(ports []exposedPort) string {
+	values := make([]string, len(ports))
+	for i, port := range ports {
+		values[i] = port.String()
+	}
+
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,102 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveContainerAppIngress(t *testing.T) {
+	tests := map[string]struct {
+		language    ServiceLanguageKind
+		options     ContainerAppIngressOptions
+		imageConfig *docker.ImageConfig
+		expected    *containerAppIngress
+		warnings    int
+	}{
+		"ExposedPort": {
+			language:    ServiceLanguageJavaScript,
+			imageConfig: &docker.ImageConfig{ExposedPorts: []string{"8080/tcp"}},
+			expected:    &containerAppIngress{TargetPort: 8080, Transport: "auto", Source: "EXPOSE 8080/tcp"},
+		},
+		"ExposedPortsSelectedByEnv": {
+			language: ServiceLanguageDotNet,
+			imageConfig: &docker.ImageConfig{
+				ExposedPorts: []string{"443/tcp", "8080/tcp"},
+				Env:          []string{"ASPNETCORE_URLS=http://+:8080"},
+			},
+			expected: &containerAppIngress{
+				TargetPort: 8080,
+				Transport:  "auto",
+				Source:     "EXPOSE 8080/tcp and ASPNETCORE_URLS",
+			},
+		},
+		"AmbiguousExposedPorts": {
+			language:    ServiceLanguageJavaScript,
+			imageConfig: &docker.ImageConfig{ExposedPorts: []string{"8081/tcp", "3000/tcp"}},
+			expected:    &containerAppIngress{TargetPort: 3000, Transport: "auto", Source: "EXPOSE 3000/tcp"},
+			warnings:    1,
+		},
+		"EnvPort": {
+			language:    ServiceLanguagePython,
+			imageConfig: &docker.ImageConfig{Env: []string{"PORT=5000"}},
+			expected:    &containerAppIngress{TargetPort: 5000, Transport: "auto", Source: "PORT"},
+		},
+		"FrameworkDefault": {
+			language:    ServiceLanguageJavaScript,
+			imageConfig: &docker.ImageConfig{},
+			expected:    &containerAppIngress{TargetPort: 3000, Transport: "auto", Source: "default port of js apps"},
+		},
+		"Grpc": {
+			language:    ServiceLanguageDocker,
+			imageConfig: &docker.ImageConfig{ExposedPorts: []string{"50051/tcp"}},
+			expected:    &containerAppIngress{TargetPort: 50051, Transport: "http2", Source: "EXPOSE 50051/tcp"},
+		},
+		"Declared": {
+			language:    ServiceLanguageJavaScript,
+			options:     ContainerAppIngressOptions{TargetPort: 8080, Transport: "http"},
+			imageConfig: &docker.ImageConfig{ExposedPorts: []string{"8080/tcp"}},
+			expected:    &containerAppIngress{TargetPort: 8080, Transport: "http", Source: "azure.yaml"},
+		},
+		"DeclaredPortNotExposed": {
+			language:    ServiceLanguageJavaScript,
+			options:     ContainerAppIngressOptions{TargetPort: 80},
+			imageConfig: &docker.ImageConfig{ExposedPorts: []string{"3000/tcp"}},
+			expected:    &containerAppIngress{TargetPort: 80, Transport: "auto", Source: "azure.yaml"},
+			warnings:    1,
+		},
+		"ImageNotInspected": {
+			language: ServiceLanguageJavaScript,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, test.language)
+			serviceConfig.ContainerApp.Ingress = test.options
+
+			ingress, warnings, err := resolveContainerAppIngress(serviceConfig, test.imageConfig)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, ingress)
+			require.Len(t, warnings, test.warnings)
+		})
+	}
+
+	t.Run("UnsupportedTransport", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageJavaScript)
+		serviceConfig.ContainerApp.Ingress.Transport = "udp"
+
+		_, _, err := resolveContainerAppIngress(serviceConfig, nil)
+		require.ErrorContains(t, err, "unsupported ingress transport 'udp'")
+	})
+}
+
+func Test_ValidateDeployedIngress(t *testing.T) {
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageJavaScript)
+	ingress := &containerAppIngress{TargetPort: 3000, Transport: "auto", Source: "EXPOSE 3000/tcp"}
+
+	require.Empty(t, validateDeployedIngress(serviceConfig, ingress, 3000))
+	require.Empty(t, validateDeployedIngress(serviceConfig, nil, 80))
+	require.Contains(t, validateDeployedIngress(serviceConfig, ingress, 80), "SERVICE_API_TARGET_PORT")
+}
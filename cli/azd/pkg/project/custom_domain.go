@@ -0,0 +1,243 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/benbjohnson/clock"
+)
+
+// The interval used when polling the custom domains of a service
+var customDomainPollInterval = 10 * time.Second
+
+// The maximum duration waited for the custom domains of a service when the timeout isn't configured
+const defaultCustomDomainTimeout = 15 * time.Minute
+
+// CustomDomainOptions are the custom domains of the service, configured by the infrastructure of the service. When
+// enabled, the deployment of the service waits for the domains to be live, so the steps following the deployment, ex)
+// smoke tests, don't run against domains whose DNS records, certificates or bindings aren't ready yet.
+type CustomDomainOptions struct {
+	// The hostnames of the custom domains, ex) api.contoso.com or ${API_HOSTNAME}. Empty hostnames are ignored.
+	Hostnames []ExpandableString `yaml:"hostnames"`
+	// When true, the deployment waits for the custom domains to be live after the service is deployed
+	Wait bool `yaml:"wait"`
+	// The maximum duration waited for all the custom domains, ex) 10m. Defaults to 15m.
+	Timeout string `yaml:"timeout"`
+	// The path requested to verify the binding of the domain is active, ex) /health. Defaults to /.
+	Path string `yaml:"path"`
+}
+
+// The stages a custom domain goes through until it's live
+type customDomainStage string
+
+const (
+	customDomainStageDns         customDomainStage = "DNS propagation"
+	customDomainStageCertificate customDomainStage = "certificate issuance"
+	customDomainStageBinding     customDomainStage = "binding activation"
+)
+
+// Validates the custom domains of the service
+func validateCustomDomains(serviceConfig *ServiceConfig) error {
+	options := serviceConfig.CustomDomains
+	if options.Timeout != "" {
+		if timeout, err := time.ParseDuration(options.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid 'customDomains.timeout' '%s', expected a duration, ex) 10m", options.Timeout)
+		}
+	}
+
+	if options.Path != "" && !strings.HasPrefix(options.Path, "/") {
+		return fmt.Errorf("invalid 'customDomains.path' '%s', the path must start with /", options.Path)
+	}
+
+	if options.Wait && len(options.Hostnames) == 0 {
+		return errors.New("'customDomains.wait' requires 'customDomains.hostnames'")
+	}
+
+	return nil
+}
+
+// CustomDomainVerifier is an optional deployment step that waits for the custom domains of a service to be live after
+// the service is deployed. A domain is live when its hostname resolves, it serves a valid certificate for the hostname
+// and its binding is active, ex) the path doesn't respond with 404 Not Found or a server error.
+type CustomDomainVerifier struct {
+	env        *environment.Environment
+	httpClient httputil.HttpClient
+	console    input.Console
+	clock      clock.Clock
+	// Resolves the addresses of a hostname, replaced while testing
+	lookupHost func(ctx context.Context, hostname string) ([]string, error)
+	// Verifies the hostname serves a valid certificate, replaced while testing
+	verifyCertificate func(ctx context.Context, hostname string) error
+}
+
+// Creates a new instance of the custom domain verifier
+func NewCustomDomainVerifier(
+	env *environment.Environment,
+	httpClient httputil.HttpClient,
+	console input.Console,
+	clock clock.Clock,
+) *CustomDomainVerifier {
+	return &CustomDomainVerifier{
+		env:               env,
+		httpClient:        httpClient,
+		console:           console,
+		clock:             clock,
+		lookupHost:        net.DefaultResolver.LookupHost,
+		verifyCertificate: verifyCertificate,
+	}
+}
+
+// Initializes the custom domain verifier for the service by subscribing to the postdeploy event
+func (v *CustomDomainVerifier) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	handler := func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		return v.Wait(ctx, args.Service)
+	}
+
+	return serviceConfig.AddHandler(ext.Event("post"+ServiceEventDeploy), handler)
+}
+
+// Waits for the custom domains of the service to be live, in order, reporting the stage each domain is waiting for.
+// Fails when the domains aren't live before the timeout of the custom domains.
+func (v *CustomDomainVerifier) Wait(ctx context.Context, serviceConfig *ServiceConfig) error {
+	options := serviceConfig.CustomDomains
+
+	timeout := defaultCustomDomainTimeout
+	if options.Timeout != "" {
+		parsed, err := time.ParseDuration(options.Timeout)
+		if err != nil {
+			return fmt.Errorf("parsing custom domains timeout: %w", err)
+		}
+
+		timeout = parsed
+	}
+
+	path := options.Path
+	if path == "" {
+		path = "/"
+	}
+
+	deadline := v.clock.Now().Add(timeout)
+	for _, hostnameTemplate := range options.Hostnames {
+		hostname, err := hostnameTemplate.Envsubst(v.env.Getenv)
+		if err != nil {
+			return fmt.Errorf("evaluating custom domain hostname: %w", err)
+		}
+
+		hostname = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(hostname), "https://"), "/")
+		if hostname == "" {
+			continue
+		}
+
+		if err := v.waitForDomain(ctx, serviceConfig, hostname, path, deadline, timeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Polls the custom domain until it's live or the deadline is reached
+func (v *CustomDomainVerifier) waitForDomain(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	hostname string,
+	path string,
+	deadline time.Time,
+	timeout time.Duration,
+) error {
+	var lastStage customDomainStage
+
+	for {
+		stage, err := v.check(ctx, hostname, path)
+		if err == nil {
+			v.console.StopSpinner(ctx, fmt.Sprintf("Custom domain %s is live", hostname), input.StepDone)
+			return nil
+		}
+
+		log.Printf("custom domain %s of service %s is waiting for %s: %v", hostname, serviceConfig.Name, stage, err)
+		if stage != lastStage {
+			lastStage = stage
+			v.console.ShowSpinner(ctx, fmt.Sprintf("Waiting for custom domain %s (%s)", hostname, stage), input.Step)
+		}
+
+		if !v.clock.Now().Before(deadline) {
+			v.console.StopSpinner(ctx, fmt.Sprintf("Waiting for custom domain %s", hostname), input.StepFailed)
+			return fmt.Errorf(
+				"custom domain '%s' of service '%s' isn't live after %s, waiting for %s: %w",
+				hostname,
+				serviceConfig.Name,
+				timeout,
+				stage,
+				err,
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-v.clock.After(customDomainPollInterval):
+		}
+	}
+}
+
+// Checks whether the custom domain is live. Returns the stage the domain is waiting for when it isn't.
+func (v *CustomDomainVerifier) check(ctx context.Context, hostname string, path string) (customDomainStage, error) {
+	if _, err := v.lookupHost(ctx, hostname); err != nil {
+		return customDomainStageDns, err
+	}
+
+	if err := v.verifyCertificate(ctx, hostname); err != nil {
+		return customDomainStageCertificate, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s%s", hostname, path), nil)
+	if err != nil {
+		return customDomainStageBinding, err
+	}
+
+	response, err := v.httpClient.Do(request)
+	if err != nil {
+		return customDomainStageBinding, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	// Hosts respond to hostnames without an active binding with 404 Not Found, or a server error from their gateway
+	if response.StatusCode == http.StatusNotFound || response.StatusCode >= http.StatusInternalServerError {
+		return customDomainStageBinding, fmt.Errorf("%s responded with status %d", path, response.StatusCode)
+	}
+
+	return "", nil
+}
+
+// Verifies the hostname serves a certificate valid for the hostname, issued by a trusted authority
+func verifyCertificate(ctx context.Context, hostname string) error {
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			ServerName: hostname,
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(hostname, "443"))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
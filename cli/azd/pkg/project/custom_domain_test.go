@@ -0,0 +1,130 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CustomDomainVerifier_Wait(t *testing.T) {
+	interval := customDomainPollInterval
+	customDomainPollInterval = time.Millisecond
+	t.Cleanup(func() { customDomainPollInterval = interval })
+
+	setup := func(t *testing.T, dnsFailures int, certificateFailures int, bindingFailures int) *CustomDomainVerifier {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.WhenPath(http.MethodGet, "/health").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				require.Equal(t, "api.contoso.com", request.URL.Host)
+
+				if bindingFailures > 0 {
+					bindingFailures--
+					return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+				}
+
+				return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+			})
+
+		verifier := NewCustomDomainVerifier(
+			environment.EphemeralWithValues("test", map[string]string{"API_HOSTNAME": "api.contoso.com"}),
+			mockContext.HttpClient,
+			mockContext.Console,
+			clock.New(),
+		)
+		verifier.lookupHost = func(ctx context.Context, hostname string) ([]string, error) {
+			if dnsFailures > 0 {
+				dnsFailures--
+				return nil, errors.New("no such host")
+			}
+
+			return []string{"10.0.0.1"}, nil
+		}
+		verifier.verifyCertificate = func(ctx context.Context, hostname string) error {
+			if certificateFailures > 0 {
+				certificateFailures--
+				return errors.New("certificate is valid for *.azurewebsites.net, not api.contoso.com")
+			}
+
+			return nil
+		}
+
+		return verifier
+	}
+
+	createServiceConfig := func(timeout string) *ServiceConfig {
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+		serviceConfig.CustomDomains = CustomDomainOptions{
+			Hostnames: []ExpandableString{NewExpandableString("${API_HOSTNAME}"), NewExpandableString("${WEB_HOSTNAME}")},
+			Wait:      true,
+			Timeout:   timeout,
+			Path:      "/health",
+		}
+
+		return serviceConfig
+	}
+
+	t.Run("Live", func(t *testing.T) {
+		verifier := setup(t, 2, 1, 1)
+
+		err := verifier.Wait(context.Background(), createServiceConfig(""))
+		require.NoError(t, err)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		verifier := setup(t, 0, 1000, 0)
+
+		err := verifier.Wait(context.Background(), createServiceConfig("20ms"))
+		require.ErrorContains(t, err, "custom domain 'api.contoso.com' of service 'api' isn't live")
+		require.ErrorContains(t, err, string(customDomainStageCertificate))
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		verifier := setup(t, 1000, 0, 0)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := verifier.Wait(ctx, createServiceConfig(""))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func Test_ValidateCustomDomains(t *testing.T) {
+	hostnames := []ExpandableString{NewExpandableString("api.contoso.com")}
+
+	tests := []struct {
+		name      string
+		options   CustomDomainOptions
+		expectErr bool
+	}{
+		{name: "Empty", options: CustomDomainOptions{}},
+		{name: "Valid", options: CustomDomainOptions{Hostnames: hostnames, Wait: true, Timeout: "5m", Path: "/health"}},
+		{name: "InvalidTimeout", options: CustomDomainOptions{Hostnames: hostnames, Timeout: "soon"}, expectErr: true},
+		{name: "InvalidPath", options: CustomDomainOptions{Hostnames: hostnames, Path: "health"}, expectErr: true},
+		{name: "WaitWithoutHostnames", options: CustomDomainOptions{Wait: true}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+			serviceConfig.CustomDomains = tt.options
+
+			err := validateCustomDomains(serviceConfig)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/benbjohnson/clock"
+	"github.com/google/uuid"
+)
+
+// The environment variable of the Grafana service account token when the Grafana annotations don't configure one
+const defaultGrafanaTokenEnv = "GRAFANA_TOKEN"
+
+// The environment variables of the user who started the deployment in CI, ex) the actor of a GitHub Actions workflow
+var initiatorEnvVarNames = []string{"GITHUB_ACTOR", "BUILD_REQUESTEDFOR", "GITLAB_USER_LOGIN"}
+
+// AnnotationOptions are the release annotations created after the services of the project are deployed, so dashboards
+// correlate regressions with deployments. At least one of AppInsights and Grafana is set.
+type AnnotationOptions struct {
+	// The Application Insights component annotated
+	AppInsights *AppInsightsAnnotationOptions `yaml:"appInsights"`
+	// The Grafana instance annotated
+	Grafana *GrafanaAnnotationOptions `yaml:"grafana"`
+}
+
+// AppInsightsAnnotationOptions are the Application Insights component the release annotations are created on
+type AppInsightsAnnotationOptions struct {
+	// The resource group of the component. Defaults to the resource group of the project.
+	ResourceGroup ExpandableString `yaml:"resourceGroup"`
+	// The name of the component. Defaults to the Application Insights component of the resource group, which must be
+	// the only one.
+	Name ExpandableString `yaml:"name"`
+}
+
+// GrafanaAnnotationOptions are the Grafana instance the annotations are created on, with the annotations HTTP API
+type GrafanaAnnotationOptions struct {
+	// The URL of the Grafana instance, ex) https://grafana.contoso.com
+	Url ExpandableString `yaml:"url"`
+	// The environment variable of the service account token of Grafana. Defaults to GRAFANA_TOKEN.
+	TokenEnv string `yaml:"tokenEnv"`
+	// The dashboard annotated. Defaults to an organization-wide annotation.
+	DashboardUid ExpandableString `yaml:"dashboardUid"`
+	// The tags of the annotations, in addition to azd, deployment and the name of the environment
+	Tags []string `yaml:"tags"`
+}
+
+// Validates the release annotations of the project
+func validateAnnotations(projectConfig *ProjectConfig) error {
+	options := projectConfig.Annotations
+	if options == nil {
+		return nil
+	}
+
+	if options.AppInsights == nil && options.Grafana == nil {
+		return errors.New("'annotations' must set at least one of 'appInsights' or 'grafana'")
+	}
+
+	if options.Grafana != nil && options.Grafana.Url == (ExpandableString{}) {
+		return errors.New("'annotations.grafana.url' is required")
+	}
+
+	return nil
+}
+
+// DeploymentAnnotation describes a deployment of the services of a project to an environment
+type DeploymentAnnotation struct {
+	Environment string   `json:"environment"`
+	Services    []string `json:"services"`
+	// The commit of the project deployed, empty when the project isn't in a git repository
+	Commit string `json:"commit,omitempty"`
+	// The user who started the deployment
+	Initiator string `json:"initiator,omitempty"`
+}
+
+// Gets the title of the annotation, ex) Deployed api, web to dev
+func (a DeploymentAnnotation) Title() string {
+	return fmt.Sprintf("Deployed %s to %s", strings.Join(a.Services, ", "), a.Environment)
+}
+
+// DeployAnnotator is an optional deployment step that creates release annotations on Application Insights and Grafana
+// after the services of the project have been deployed
+type DeployAnnotator struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager ResourceManager
+	gitCli          git.GitCli
+	httpClient      httputil.HttpClient
+	console         input.Console
+	clock           clock.Clock
+	// Gets the value of an environment variable of the process, replaced while testing
+	getenv func(key string) string
+}
+
+// Creates a new instance of the deploy annotator
+func NewDeployAnnotator(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager ResourceManager,
+	gitCli git.GitCli,
+	httpClient httputil.HttpClient,
+	console input.Console,
+	clock clock.Clock,
+) *DeployAnnotator {
+	return &DeployAnnotator{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+		gitCli:          gitCli,
+		httpClient:      httpClient,
+		console:         console,
+		clock:           clock,
+		getenv:          os.Getenv,
+	}
+}
+
+// Creates the release annotations of the deployment of the services. Annotations don't fail the deployment, failures
+// are shown as warnings.
+func (a *DeployAnnotator) Annotate(ctx context.Context, projectConfig *ProjectConfig, services []*ServiceConfig) {
+	options := projectConfig.Annotations
+	if options == nil || len(services) == 0 {
+		return
+	}
+
+	annotation := a.annotation(ctx, projectConfig, services)
+
+	if options.AppInsights != nil {
+		if err := a.annotateAppInsights(ctx, projectConfig, options.AppInsights, annotation); err != nil {
+			a.warn(ctx, "Application Insights", err)
+		}
+	}
+
+	if options.Grafana != nil {
+		if err := a.annotateGrafana(ctx, options.Grafana, annotation); err != nil {
+			a.warn(ctx, "Grafana", err)
+		}
+	}
+}
+
+func (a *DeployAnnotator) warn(ctx context.Context, target string, err error) {
+	a.console.MessageUxItem(ctx, &ux.WarningMessage{
+		Description: fmt.Sprintf("Creating the deployment annotation on %s failed: %v", target, err),
+	})
+}
+
+// Describes the deployment of the services
+func (a *DeployAnnotator) annotation(
+	ctx context.Context,
+	projectConfig *ProjectConfig,
+	services []*ServiceConfig,
+) DeploymentAnnotation {
+	annotation := DeploymentAnnotation{
+		Environment: a.env.GetEnvName(),
+		Initiator:   a.initiator(),
+	}
+
+	for _, svc := range services {
+		annotation.Services = append(annotation.Services, svc.Name)
+	}
+
+	commit, err := a.gitCli.GetCurrentCommit(ctx, projectConfig.Path)
+	if err != nil && !errors.Is(err, git.ErrNotRepository) {
+		log.Printf("failed getting current commit of the project: %v", err)
+	}
+
+	annotation.Commit = commit
+	return annotation
+}
+
+// Gets the user who started the deployment, the actor of the CI pipeline or the user running azd
+func (a *DeployAnnotator) initiator() string {
+	for _, name := range initiatorEnvVarNames {
+		if value := a.getenv(name); value != "" {
+			return value
+		}
+	}
+
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	return ""
+}
+
+// Creates a release annotation on the Application Insights component
+func (a *DeployAnnotator) annotateAppInsights(
+	ctx context.Context,
+	projectConfig *ProjectConfig,
+	options *AppInsightsAnnotationOptions,
+	annotation DeploymentAnnotation,
+) error {
+	subscriptionId := a.env.GetSubscriptionId()
+	componentId, err := a.appInsightsComponent(ctx, subscriptionId, projectConfig, options)
+	if err != nil {
+		return err
+	}
+
+	properties, err := json.Marshal(map[string]string{
+		"ReleaseName":     annotation.Title(),
+		"Environment":     annotation.Environment,
+		"Services":        strings.Join(annotation.Services, ","),
+		"CommitId":        annotation.Commit,
+		"TriggerBy":       annotation.Initiator,
+		"ReleaseProvider": "azd",
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("creating deployment annotation on application insights component %s", componentId)
+	return a.azCli.CreateAppInsightsAnnotation(ctx, subscriptionId, componentId, azsdk.Annotation{
+		Id:         uuid.NewString(),
+		Name:       annotation.Title(),
+		Category:   "Deployment",
+		EventTime:  a.clock.Now().UTC(),
+		Properties: string(properties),
+	})
+}
+
+// Gets the id of the annotated Application Insights component, the configured component or the only component of the
+// resource group
+func (a *DeployAnnotator) appInsightsComponent(
+	ctx context.Context,
+	subscriptionId string,
+	projectConfig *ProjectConfig,
+	options *AppInsightsAnnotationOptions,
+) (string, error) {
+	resourceGroup, err := options.ResourceGroup.Envsubst(a.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("evaluating resource group: %w", err)
+	}
+
+	if resourceGroup == "" {
+		resourceGroup, err = a.resourceManager.GetResourceGroupName(ctx, subscriptionId, projectConfig)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name, err := options.Name.Envsubst(a.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("evaluating component name: %w", err)
+	}
+
+	resources, err := a.azCli.ListResourceGroupResources(ctx, subscriptionId, resourceGroup, nil)
+	if err != nil {
+		return "", fmt.Errorf("listing resources of resource group '%s': %w", resourceGroup, err)
+	}
+
+	components := []azcli.AzCliResource{}
+	for _, resource := range resources {
+		if strings.EqualFold(resource.Type, string(infra.AzureResourceTypeAppInsightComponent)) &&
+			(name == "" || strings.EqualFold(resource.Name, name)) {
+			components = append(components, resource)
+		}
+	}
+
+	switch {
+	case len(components) == 1:
+		return components[0].Id, nil
+	case name != "":
+		return "", fmt.Errorf("application insights component '%s' not found in resource group '%s'", name, resourceGroup)
+	case len(components) == 0:
+		return "", fmt.Errorf("resource group '%s' has no application insights component", resourceGroup)
+	default:
+		return "", fmt.Errorf(
+			"resource group '%s' has %d application insights components, set 'annotations.appInsights.name'",
+			resourceGroup,
+			len(components),
+		)
+	}
+}
+
+// Creates an annotation with the annotations HTTP API of Grafana
+func (a *DeployAnnotator) annotateGrafana(
+	ctx context.Context,
+	options *GrafanaAnnotationOptions,
+	annotation DeploymentAnnotation,
+) error {
+	grafanaUrl, err := options.Url.Envsubst(a.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("evaluating url: %w", err)
+	}
+
+	dashboardUid, err := options.DashboardUid.Envsubst(a.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("evaluating dashboard uid: %w", err)
+	}
+
+	tokenEnv := options.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = defaultGrafanaTokenEnv
+	}
+
+	token := a.env.Getenv(tokenEnv)
+	if token == "" {
+		return fmt.Errorf("the service account token of Grafana isn't set, set the %s environment variable", tokenEnv)
+	}
+
+	text := annotation.Title()
+	if annotation.Commit != "" {
+		text += fmt.Sprintf(" (commit %s)", annotation.Commit)
+	}
+
+	if annotation.Initiator != "" {
+		text += fmt.Sprintf(" by %s", annotation.Initiator)
+	}
+
+	body := map[string]any{
+		"time": a.clock.Now().UnixMilli(),
+		"tags": append([]string{"azd", "deployment", annotation.Environment}, options.Tags...),
+		"text": text,
+	}
+
+	if dashboardUid != "" {
+		body["dashboardUID"] = dashboardUid
+	}
+
+	content, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(grafanaUrl, "/") + "/api/annotations"
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("creating annotation request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	log.Printf("creating deployment annotation on grafana %s", grafanaUrl)
+	response, err := a.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("creating annotation on %s failed with status %s", grafanaUrl, response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockarmresources"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_Annotations(t *testing.T) {
+	tests := map[string]struct {
+		annotations string
+		err         string
+	}{
+		"Valid": {
+			annotations: `
+  appInsights: {}
+  grafana:
+    url: https://grafana.contoso.com`,
+		},
+		"Empty": {
+			annotations: ` {}`,
+			err:         "'annotations' must set at least one of 'appInsights' or 'grafana'",
+		},
+		"GrafanaWithoutUrl": {
+			annotations: `
+  grafana:
+    dashboardUid: abc`,
+			err: "'annotations.grafana.url' is required",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(context.Background(), "name: test-proj\nannotations:"+test.annotations+"\n")
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_DeployAnnotator_Annotate(t *testing.T) {
+	const testProj = `
+name: test-proj
+resourceGroup: rg-test
+annotations:
+  appInsights: {}
+  grafana:
+    url: https://grafana.contoso.com/
+    dashboardUid: ${DASHBOARD_UID}
+    tags: [team-a]
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+  web:
+    project: src/web
+    language: js
+    host: appservice
+`
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(0, "abc1234\n", ""))
+
+	componentId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg-test/providers/Microsoft.Insights/components/appi-test"
+	mockarmresources.AddAzResourceListMock(
+		mockContext.HttpClient,
+		convert.RefOf("rg-test"),
+		[]*armresources.GenericResourceExpanded{
+			{
+				ID:       convert.RefOf(componentId),
+				Name:     convert.RefOf("appi-test"),
+				Type:     convert.RefOf(string(infra.AzureResourceTypeAppInsightComponent)),
+				Location: convert.RefOf("eastus2"),
+			},
+		})
+
+	var appInsightsAnnotation map[string]any
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Path == componentId+"/Annotations"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(request.Body)
+		require.NoError(t, json.Unmarshal(body, &appInsightsAnnotation))
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, []any{appInsightsAnnotation})
+	})
+
+	var grafanaAnnotation map[string]any
+	var grafanaAuthorization string
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.String() == "https://grafana.contoso.com/api/annotations"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		grafanaAuthorization = request.Header.Get("Authorization")
+		body, _ := io.ReadAll(request.Body)
+		require.NoError(t, json.Unmarshal(body, &grafanaAnnotation))
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"id": 1})
+	})
+
+	env := environment.EphemeralWithValues("prod", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		"DASHBOARD_UID":                      "dash-1",
+		"GRAFANA_TOKEN":                      "glsa_token",
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	annotator := NewDeployAnnotator(
+		env,
+		azCli,
+		NewResourceManager(env, azCli),
+		git.NewGitCli(mockContext.CommandRunner),
+		mockContext.HttpClient,
+		mockContext.Console,
+		mockClock,
+	)
+	annotator.getenv = func(key string) string {
+		if key == "GITHUB_ACTOR" {
+			return "octocat"
+		}
+		return ""
+	}
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	services, err := projectConfig.SelectServices("", "")
+	require.NoError(t, err)
+
+	annotator.Annotate(*mockContext.Context, projectConfig, services)
+	require.Empty(t, mockContext.Console.Output())
+
+	require.Equal(t, "Deployed api, web to prod", appInsightsAnnotation["AnnotationName"])
+	require.Equal(t, "Deployment", appInsightsAnnotation["Category"])
+	require.Equal(t, "2024-01-02T03:04:05Z", appInsightsAnnotation["EventTime"])
+
+	var properties map[string]string
+	require.NoError(t, json.Unmarshal([]byte(appInsightsAnnotation["Properties"].(string)), &properties))
+	require.Equal(t, "prod", properties["Environment"])
+	require.Equal(t, "api,web", properties["Services"])
+	require.Equal(t, "abc1234", properties["CommitId"])
+	require.Equal(t, "octocat", properties["TriggerBy"])
+
+	require.Equal(t, "Bearer glsa_token", grafanaAuthorization)
+	require.Equal(t, map[string]any{
+		"time":         float64(mockClock.Now().UnixMilli()),
+		"tags":         []any{"azd", "deployment", "prod", "team-a"},
+		"text":         "Deployed api, web to prod (commit abc1234) by octocat",
+		"dashboardUID": "dash-1",
+	}, grafanaAnnotation)
+}
+
+func Test_DeployAnnotator_Annotate_Failures(t *testing.T) {
+	const testProj = `
+name: test-proj
+resourceGroup: rg-test
+annotations:
+  appInsights:
+    name: appi-missing
+  grafana:
+    url: https://grafana.contoso.com
+    tokenEnv: MISSING_GRAFANA_TOKEN
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+`
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(128, "", "fatal: not a git repository"))
+	mockarmresources.AddAzResourceListMock(mockContext.HttpClient, convert.RefOf("rg-test"), nil)
+
+	env := environment.EphemeralWithValues("prod", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	annotator := NewDeployAnnotator(
+		env,
+		azCli,
+		NewResourceManager(env, azCli),
+		git.NewGitCli(mockContext.CommandRunner),
+		mockContext.HttpClient,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+
+	// Failures are warnings, the deployment succeeds without annotations
+	annotator.Annotate(*mockContext.Context, projectConfig, []*ServiceConfig{projectConfig.Services["api"]})
+	require.Len(t, mockContext.Console.Output(), 2)
+	require.Contains(t, mockContext.Console.Output()[0], "application insights component 'appi-missing' not found")
+	require.Contains(t, mockContext.Console.Output()[1], "set the MISSING_GRAFANA_TOKEN environment variable")
+}
@@ -0,0 +1,583 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"gopkg.in/yaml.v3"
+)
+
+// The name of the file in the environment directory recording what was last deployed to the environment
+const DeployStateFileName = "deploy-state.json"
+
+// The number of the container images of the successful deployments recorded for each service
+const maxDeployedImages = 100
+
+// DeployState records what was last deployed and provisioned to an environment
+type DeployState struct {
+	Services map[string]*ServiceDeployState `json:"services,omitempty"`
+	Infra    *InfraDeployState              `json:"infra,omitempty"`
+	// The checkpoint of the last run of azd up
+	Up *UpCheckpoint `json:"up,omitempty"`
+}
+
+// ServiceDeployState records the source and configuration of the last deployment of a service
+type ServiceDeployState struct {
+	DeployedAt time.Time `json:"deployedAt"`
+	// The git commit checked out when the service was deployed, empty when the service is not in a git repository
+	Commit string `json:"commit,omitempty"`
+	// The sha256 of the files that had uncommitted changes when the service was deployed, keyed by their path
+	// relative to the service. Files that were deleted have an empty hash.
+	Uncommitted map[string]string `json:"uncommitted,omitempty"`
+	// The configuration of the service in azure.yaml, flattened by key path, ex) docker.path
+	Config map[string]string `json:"config,omitempty"`
+	// The container images of the successful deployments of the service, most recent first
+	Images []string `json:"images,omitempty"`
+}
+
+// InfraDeployState records the infrastructure files of the last provisioning of the environment
+type InfraDeployState struct {
+	ProvisionedAt time.Time `json:"provisionedAt"`
+	// The sha256 of the infrastructure files, keyed by their path relative to the infrastructure directory
+	Files map[string]string `json:"files"`
+}
+
+type FileChangeStatus string
+
+const (
+	FileAdded    FileChangeStatus = "added"
+	FileModified FileChangeStatus = "modified"
+	FileDeleted  FileChangeStatus = "deleted"
+)
+
+// FileChange is a file that changed since the last deployment
+type FileChange struct {
+	Status FileChangeStatus `json:"status"`
+	// The path of the file, relative to the service or infrastructure directory
+	Path string `json:"path"`
+}
+
+// ConfigChange is a key of the service configuration that changed since the last deployment.
+// An empty value means the key is not set.
+type ConfigChange struct {
+	Key      string `json:"key"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// ServiceChanges are the changes of a service since it was last deployed
+type ServiceChanges struct {
+	// The time of the last deployment, nil when the service was never deployed to the environment
+	DeployedAt *time.Time `json:"deployedAt,omitempty"`
+	// The commit of the last deployment
+	Commit string `json:"commit,omitempty"`
+	// The commit currently checked out
+	CurrentCommit string `json:"currentCommit,omitempty"`
+	// Whether source changes could be determined, which requires the service to be tracked by git
+	SourceTracked bool           `json:"sourceTracked"`
+	Files         []FileChange   `json:"files,omitempty"`
+	Config        []ConfigChange `json:"config,omitempty"`
+}
+
+// HasChanges returns true when deploying the service would deploy something different than the last deployment
+func (c *ServiceChanges) HasChanges() bool {
+	return c.DeployedAt == nil || !c.SourceTracked || len(c.Files) > 0 || len(c.Config) > 0
+}
+
+// InfraChanges are the changes of the infrastructure files since the environment was last provisioned
+type InfraChanges struct {
+	// The time of the last provisioning, nil when the environment was never provisioned
+	ProvisionedAt *time.Time   `json:"provisionedAt,omitempty"`
+	Files         []FileChange `json:"files,omitempty"`
+}
+
+// HasChanges returns true when provisioning would use different infrastructure files than the last provisioning
+func (c *InfraChanges) HasChanges() bool {
+	return c.ProvisionedAt == nil || len(c.Files) > 0
+}
+
+// ChangeTracker records what is deployed to an environment and compares it against the current state of the project
+type ChangeTracker struct {
+	env    *environment.Environment
+	gitCli git.GitCli
+
+	// Serializes the updates of the deploy state, services are deployed concurrently by some deploy strategies
+	deployMutex sync.Mutex
+}
+
+// Creates a new instance of the ChangeTracker
+func NewChangeTracker(env *environment.Environment, gitCli git.GitCli) *ChangeTracker {
+	return &ChangeTracker{
+		env:    env,
+		gitCli: gitCli,
+	}
+}
+
+// RecordDeploy records the current source and configuration of the service as deployed, along with the container image
+// of the deployment, if any
+func (t *ChangeTracker) RecordDeploy(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	deployResult *ServiceDeployResult,
+) error {
+	config, err := serviceConfigSnapshot(serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	serviceState := &ServiceDeployState{
+		DeployedAt: time.Now().UTC(),
+		Config:     config,
+	}
+
+	commit, err := t.gitCli.GetCurrentCommit(ctx, serviceConfig.Path())
+	if err != nil {
+		log.Printf("source changes of service '%s' won't be tracked: %v", serviceConfig.Name, err)
+	} else {
+		uncommitted, err := t.workingTreeChanges(ctx, serviceConfig.Path(), commit)
+		if err != nil {
+			return err
+		}
+
+		serviceState.Commit = commit
+		serviceState.Uncommitted = map[string]string{}
+		for _, change := range uncommitted {
+			serviceState.Uncommitted[change.Path] = fileHashOrEmpty(serviceConfig.Path(), change.Path)
+		}
+	}
+
+	t.deployMutex.Lock()
+	defer t.deployMutex.Unlock()
+
+	state, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	if state.Services == nil {
+		state.Services = map[string]*ServiceDeployState{}
+	}
+
+	if previous, has := state.Services[serviceConfig.Name]; has {
+		serviceState.Images = previous.Images
+	}
+
+	if deployResult != nil && deployResult.Package != nil {
+		if packageDetails, ok := deployResult.Package.Details.(*dockerPackageResult); ok && packageDetails.ImageTag != "" {
+			images := []string{packageDetails.ImageTag}
+			for _, image := range serviceState.Images {
+				if image != packageDetails.ImageTag && len(images) < maxDeployedImages {
+					images = append(images, image)
+				}
+			}
+
+			serviceState.Images = images
+		}
+	}
+
+	state.Services[serviceConfig.Name] = serviceState
+	recordUpDeployed(state, serviceConfig.Name)
+
+	return t.save(state)
+}
+
+// DeployedImages gets the container images of the successful deployments of the service, most recent first
+func (t *ChangeTracker) DeployedImages(serviceName string) ([]string, error) {
+	t.deployMutex.Lock()
+	defer t.deployMutex.Unlock()
+
+	state, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if serviceState, has := state.Services[serviceName]; has {
+		return serviceState.Images, nil
+	}
+
+	return nil, nil
+}
+
+// RecordProvision records the current infrastructure files of the project as provisioned
+func (t *ChangeTracker) RecordProvision(projectConfig *ProjectConfig) error {
+	files, err := hashDirectory(infraPath(projectConfig))
+	if err != nil {
+		return fmt.Errorf("hashing infrastructure files: %w", err)
+	}
+
+	t.deployMutex.Lock()
+	defer t.deployMutex.Unlock()
+
+	state, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	state.Infra = &InfraDeployState{
+		ProvisionedAt: time.Now().UTC(),
+		Files:         files,
+	}
+
+	return t.save(state)
+}
+
+// ServiceChanges compares the current source and configuration of the service against its last deployment
+func (t *ChangeTracker) ServiceChanges(ctx context.Context, serviceConfig *ServiceConfig) (*ServiceChanges, error) {
+	state, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &ServiceChanges{}
+	currentCommit, err := t.gitCli.GetCurrentCommit(ctx, serviceConfig.Path())
+	if err != nil {
+		log.Printf("source changes of service '%s' can't be determined: %v", serviceConfig.Name, err)
+	} else {
+		changes.CurrentCommit = currentCommit
+	}
+
+	serviceState, has := state.Services[serviceConfig.Name]
+	if !has {
+		return changes, nil
+	}
+
+	changes.DeployedAt = &serviceState.DeployedAt
+	changes.Commit = serviceState.Commit
+
+	config, err := serviceConfigSnapshot(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Config = diffConfig(serviceState.Config, config)
+
+	if serviceState.Commit == "" || changes.CurrentCommit == "" {
+		return changes, nil
+	}
+
+	// The deployed commit may no longer exist locally, ex) after a rebase or a fresh clone
+	sourceChanges, err := t.workingTreeChanges(ctx, serviceConfig.Path(), serviceState.Commit)
+	if err != nil {
+		log.Printf("source changes of service '%s' can't be determined: %v", serviceConfig.Name, err)
+		return changes, nil
+	}
+
+	changes.SourceTracked = true
+	changes.Files = excludeDeployedChanges(serviceConfig.Path(), sourceChanges, serviceState.Uncommitted)
+
+	return changes, nil
+}
+
+// InfraChanges compares the current infrastructure files of the project against the last provisioning
+func (t *ChangeTracker) InfraChanges(projectConfig *ProjectConfig) (*InfraChanges, error) {
+	state, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &InfraChanges{}
+	if state.Infra == nil {
+		return changes, nil
+	}
+
+	files, err := hashDirectory(infraPath(projectConfig))
+	if err != nil {
+		return nil, fmt.Errorf("hashing infrastructure files: %w", err)
+	}
+
+	changes.ProvisionedAt = &state.Infra.ProvisionedAt
+	for path, previous := range state.Infra.Files {
+		if current, has := files[path]; !has {
+			changes.Files = append(changes.Files, FileChange{Status: FileDeleted, Path: path})
+		} else if current != previous {
+			changes.Files = append(changes.Files, FileChange{Status: FileModified, Path: path})
+		}
+	}
+
+	for path := range files {
+		if _, has := state.Infra.Files[path]; !has {
+			changes.Files = append(changes.Files, FileChange{Status: FileAdded, Path: path})
+		}
+	}
+
+	sortFileChanges(changes.Files)
+
+	return changes, nil
+}
+
+// Lists the files of the working tree under the path that changed since the commit, including untracked files
+func (t *ChangeTracker) workingTreeChanges(ctx context.Context, path string, commit string) ([]FileChange, error) {
+	diff, err := t.gitCli.DiffNameStatus(ctx, path, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := t.gitCli.ListUntrackedFiles(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FileChange{}
+	for _, line := range strings.Split(diff, "\n") {
+		status, filePath, has := strings.Cut(strings.TrimSpace(line), "\t")
+		if !has {
+			continue
+		}
+
+		change := FileChange{Status: FileModified, Path: filePath}
+		switch status {
+		case "A":
+			change.Status = FileAdded
+		case "D":
+			change.Status = FileDeleted
+		}
+
+		changes = append(changes, change)
+	}
+
+	for _, line := range strings.Split(untracked, "\n") {
+		if filePath := strings.TrimSpace(line); filePath != "" {
+			changes = append(changes, FileChange{Status: FileAdded, Path: filePath})
+		}
+	}
+
+	sortFileChanges(changes)
+
+	return changes, nil
+}
+
+func (t *ChangeTracker) load() (*DeployState, error) {
+	state := &DeployState{}
+	if t.env.Root == "" {
+		return state, nil
+	}
+
+	contents, err := os.ReadFile(filepath.Join(t.env.Root, DeployStateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading deploy state: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, state); err != nil {
+		return nil, fmt.Errorf("parsing deploy state: %w", err)
+	}
+
+	return state, nil
+}
+
+func (t *ChangeTracker) save(state *DeployState) error {
+	// Ephemeral environments are not persisted
+	if t.env.Root == "" {
+		return nil
+	}
+
+	contents, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling deploy state: %w", err)
+	}
+
+	if err := os.MkdirAll(t.env.Root, osutil.PermissionDirectory); err != nil {
+		return fmt.Errorf("creating environment directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(t.env.Root, DeployStateFileName), contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("writing deploy state: %w", err)
+	}
+
+	return nil
+}
+
+// Removes the changes of the files that had the same uncommitted changes when the service was deployed, and adds
+// the files with uncommitted changes at deployment that have since been reverted to their committed content
+func excludeDeployedChanges(servicePath string, changes []FileChange, uncommitted map[string]string) []FileChange {
+	result := []FileChange{}
+	changed := map[string]bool{}
+	for _, change := range changes {
+		changed[change.Path] = true
+		if deployedHash, has := uncommitted[change.Path]; has &&
+			deployedHash == fileHashOrEmpty(servicePath, change.Path) {
+			continue
+		}
+
+		result = append(result, change)
+	}
+
+	for path, deployedHash := range uncommitted {
+		if changed[path] {
+			continue
+		}
+
+		change := FileChange{Status: FileModified, Path: path}
+		if deployedHash == "" {
+			change.Status = FileAdded
+		} else if fileHashOrEmpty(servicePath, path) == "" {
+			change.Status = FileDeleted
+		}
+
+		result = append(result, change)
+	}
+
+	sortFileChanges(result)
+
+	return result
+}
+
+// Gets the configuration of the service as written in azure.yaml, flattened by key path
+func serviceConfigSnapshot(serviceConfig *ServiceConfig) (map[string]string, error) {
+	contents, err := os.ReadFile(filepath.Join(serviceConfig.Project.Path, azdcontext.ProjectFileName))
+	if err != nil {
+		return nil, fmt.Errorf("reading project file: %w", err)
+	}
+
+	var projectFile map[string]any
+	if err := yaml.Unmarshal(contents, &projectFile); err != nil {
+		return nil, fmt.Errorf("parsing project file: %w", err)
+	}
+
+	// The configuration services inherit from the defaults of the project is part of their configuration
+	if err := applyServiceDefaults(projectFile); err != nil {
+		return nil, fmt.Errorf("parsing project file: %w", err)
+	}
+
+	services, _ := projectFile["services"].(map[string]any)
+	config := map[string]string{}
+	if err := flattenConfig("", services[serviceConfig.Name], config); err != nil {
+		return nil, fmt.Errorf("reading configuration of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return config, nil
+}
+
+// Flattens the nested maps of the value into the result, keyed by their dotted path.
+// Lists are kept as a single JSON encoded value.
+func flattenConfig(prefix string, value any, result map[string]string) error {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		for key, child := range typed {
+			childKey := key
+			if prefix != "" {
+				childKey = fmt.Sprintf("%s.%s", prefix, key)
+			}
+
+			if err := flattenConfig(childKey, child, result); err != nil {
+				return err
+			}
+		}
+	case []any:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return err
+		}
+
+		result[prefix] = string(encoded)
+	default:
+		result[prefix] = fmt.Sprint(typed)
+	}
+
+	return nil
+}
+
+func diffConfig(previous map[string]string, current map[string]string) []ConfigChange {
+	keys := map[string]bool{}
+	for key := range previous {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+
+	changes := []ConfigChange{}
+	for key := range keys {
+		if previous[key] != current[key] {
+			changes = append(changes, ConfigChange{Key: key, Previous: previous[key], Current: current[key]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// Gets the sha256 of the files in the directory, keyed by their slash separated path relative to the directory
+func hashDirectory(root string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+
+		hash, err := fileSha256(filePath)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relativePath)] = hash
+		return nil
+	})
+
+	if errors.Is(err, os.ErrNotExist) {
+		return files, nil
+	}
+
+	return files, err
+}
+
+// Gets the sha256 of the file relative to the directory, or an empty string when the file doesn't exist
+func fileHashOrEmpty(root string, path string) string {
+	hash, err := fileSha256(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		return ""
+	}
+
+	return hash
+}
+
+func infraPath(projectConfig *ProjectConfig) string {
+	infraPath := projectConfig.Infra.Path
+	if infraPath == "" {
+		infraPath = azdcontext.InfraDirectoryName
+	}
+
+	if filepath.IsAbs(infraPath) {
+		return infraPath
+	}
+
+	return filepath.Join(projectConfig.Path, infraPath)
+}
+
+func sortFileChanges(changes []FileChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+}
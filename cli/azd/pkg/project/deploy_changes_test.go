@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const changesTestProject = `
+name: test-app
+services:
+  api:
+    project: src/api
+    language: python
+    host: containerapp
+    docker:
+      path: ./Dockerfile
+`
+
+func Test_ChangeTracker_ServiceChanges(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	diff := "M\tlocal.txt\n"
+	untracked := "new.txt\n"
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(0, "0123456789abcdef\n", ""))
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "diff --name-status")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Contains(t, args.Args, "0123456789abcdef")
+		return exec.NewRunResult(0, diff, ""), nil
+	})
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "ls-files --others")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, untracked, ""), nil
+	})
+
+	projectPath := t.TempDir()
+	servicePath := filepath.Join(projectPath, "src", "api")
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	writeFile(filepath.Join(projectPath, "azure.yaml"), changesTestProject)
+	writeFile(filepath.Join(servicePath, "local.txt"), "deployed")
+	writeFile(filepath.Join(servicePath, "new.txt"), "deployed")
+
+	projectConfig, err := Load(*mockContext.Context, filepath.Join(projectPath, "azure.yaml"))
+	require.NoError(t, err)
+	serviceConfig := projectConfig.Services["api"]
+
+	tracker := NewChangeTracker(environment.EmptyWithRoot(t.TempDir()), git.NewGitCli(mockContext.CommandRunner))
+
+	changes, err := tracker.ServiceChanges(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.Nil(t, changes.DeployedAt)
+	require.True(t, changes.HasChanges())
+
+	require.NoError(t, tracker.RecordDeploy(*mockContext.Context, serviceConfig, nil))
+
+	t.Run("Unchanged", func(t *testing.T) {
+		changes, err := tracker.ServiceChanges(*mockContext.Context, serviceConfig)
+		require.NoError(t, err)
+		require.NotNil(t, changes.DeployedAt)
+		require.True(t, changes.SourceTracked)
+		require.Equal(t, "0123456789abcdef", changes.Commit)
+		require.Empty(t, changes.Files)
+		require.Empty(t, changes.Config)
+		require.False(t, changes.HasChanges())
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		writeFile(filepath.Join(servicePath, "local.txt"), "updated")
+		require.NoError(t, os.Remove(filepath.Join(servicePath, "new.txt")))
+		diff = "M\tlocal.txt\nA\tsrc/added.py\nD\tremoved.py\n"
+		untracked = ""
+
+		updatedProject := strings.Replace(changesTestProject, "./Dockerfile", "./Dockerfile.prod", 1)
+		writeFile(filepath.Join(projectPath, "azure.yaml"), updatedProject)
+
+		changes, err := tracker.ServiceChanges(*mockContext.Context, serviceConfig)
+		require.NoError(t, err)
+		require.True(t, changes.HasChanges())
+		require.Equal(t, []FileChange{
+			{Status: FileModified, Path: "local.txt"},
+			{Status: FileDeleted, Path: "new.txt"},
+			{Status: FileDeleted, Path: "removed.py"},
+			{Status: FileAdded, Path: "src/added.py"},
+		}, changes.Files)
+		require.Equal(t, []ConfigChange{
+			{Key: "docker.path", Previous: "./Dockerfile", Current: "./Dockerfile.prod"},
+		}, changes.Config)
+	})
+
+	t.Run("DeployedImages", func(t *testing.T) {
+		deploy := func(imageTag string) {
+			require.NoError(t, tracker.RecordDeploy(*mockContext.Context, serviceConfig, &ServiceDeployResult{
+				Package: &ServicePackageResult{Details: &dockerPackageResult{ImageTag: imageTag}},
+			}))
+		}
+
+		deploy("contoso.azurecr.io/api:azd-deploy-1")
+		deploy("contoso.azurecr.io/api:azd-deploy-2")
+		require.NoError(t, tracker.RecordDeploy(*mockContext.Context, serviceConfig, nil))
+
+		images, err := tracker.DeployedImages("api")
+		require.NoError(t, err)
+		require.Equal(t, []string{"contoso.azurecr.io/api:azd-deploy-2", "contoso.azurecr.io/api:azd-deploy-1"}, images)
+	})
+}
+
+func Test_ChangeTracker_InfraChanges(t *testing.T) {
+	projectPath := t.TempDir()
+	infraPath := filepath.Join(projectPath, "infra")
+	writeFile := func(name string, contents string) {
+		require.NoError(t, os.MkdirAll(infraPath, osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(filepath.Join(infraPath, name), []byte(contents), osutil.PermissionFile))
+	}
+
+	writeFile("main.bicep", "targetScope = 'subscription'")
+	writeFile("main.parameters.json", "{}")
+	writeFile("resources.bicep", "param location string")
+
+	projectConfig := &ProjectConfig{Name: "test-app", Path: projectPath}
+	mockContext := mocks.NewMockContext(context.Background())
+	tracker := NewChangeTracker(environment.EmptyWithRoot(t.TempDir()), git.NewGitCli(mockContext.CommandRunner))
+
+	changes, err := tracker.InfraChanges(projectConfig)
+	require.NoError(t, err)
+	require.Nil(t, changes.ProvisionedAt)
+	require.True(t, changes.HasChanges())
+
+	require.NoError(t, tracker.RecordProvision(projectConfig))
+
+	changes, err = tracker.InfraChanges(projectConfig)
+	require.NoError(t, err)
+	require.NotNil(t, changes.ProvisionedAt)
+	require.False(t, changes.HasChanges())
+
+	writeFile("main.bicep", "targetScope = 'resourceGroup'")
+	writeFile("app.bicep", "param name string")
+	require.NoError(t, os.Remove(filepath.Join(infraPath, "resources.bicep")))
+
+	changes, err = tracker.InfraChanges(projectConfig)
+	require.NoError(t, err)
+	require.Equal(t, []FileChange{
+		{Status: FileAdded, Path: "app.bicep"},
+		{Status: FileModified, Path: "main.bicep"},
+		{Status: FileDeleted, Path: "resources.bicep"},
+	}, changes.Files)
+}
@@ -0,0 +1,288 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/benbjohnson/clock"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The first line of the files azd generates in the sources of the services, ex) the Dockerfile of a service without one
+const generatedFileHeader = "# Generated by azd"
+
+// GitOptions are the git integrations of the deployments of the project, ex) tagging the commits deployed to production
+type GitOptions struct {
+	// The environments the options apply to, as glob patterns, ex) prod-*. Defaults to all environments.
+	Environments []string `yaml:"environments"`
+	// When true, deployments from a working tree with uncommitted changes are refused
+	RequireClean bool `yaml:"requireClean"`
+	// The tag created on the deployed commit after successful deployments
+	Tag *GitTagOptions `yaml:"tag"`
+}
+
+// GitTagOptions are the tags created on the deployed commits
+type GitTagOptions struct {
+	// The name of the tag, ex) release/${AZURE_ENV_NAME}/v1. Defaults to deploy/<environment>/<timestamp>.
+	Name ExpandableString `yaml:"name"`
+	// The remote the tag is pushed to, ex) origin. The tag isn't pushed when empty.
+	Push string `yaml:"push"`
+}
+
+// Gets whether the options apply to the environment
+func (o *GitOptions) appliesTo(envName string) bool {
+	if len(o.Environments) == 0 {
+		return true
+	}
+
+	for _, pattern := range o.Environments {
+		if matched, _ := path.Match(pattern, envName); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GitMetadata is the state of the git working tree the project is deployed from
+type GitMetadata struct {
+	// The current branch, empty when HEAD is detached
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit"`
+	// Whether the working tree has uncommitted changes, which aren't in the commit
+	Dirty bool `json:"dirty"`
+	// The tag created on the commit after the deployment
+	Tag string `json:"tag,omitempty"`
+}
+
+// Gets the summary of the metadata, ex) commit 0123456 on branch main, with uncommitted changes
+func (m *GitMetadata) Summary() string {
+	commit := m.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+
+	summary := fmt.Sprintf("commit %s", commit)
+	if m.Branch != "" {
+		summary += fmt.Sprintf(" on branch %s", m.Branch)
+	}
+
+	if m.Dirty {
+		summary += ", with uncommitted changes"
+	}
+
+	if m.Tag != "" {
+		summary += fmt.Sprintf(", tagged %s", m.Tag)
+	}
+
+	return summary
+}
+
+// Reads the git metadata of the working tree of the path. The files azd generated in the sources of the services of the
+// project don't make the working tree dirty. Returns git.ErrNotRepository when the path isn't in a git repository.
+func readGitMetadata(
+	ctx context.Context,
+	gitCli git.GitCli,
+	repositoryPath string,
+	projectConfig *ProjectConfig,
+) (*GitMetadata, error) {
+	commit, err := gitCli.GetCurrentCommit(ctx, repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := gitCli.GetCurrentBranch(ctx, repositoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	generated, err := generatedFiles(ctx, gitCli, projectConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	excludePaths := []string{}
+	for _, generatedPath := range generated {
+		if relativePath, err := filepath.Rel(repositoryPath, generatedPath); err == nil {
+			excludePaths = append(excludePaths, filepath.ToSlash(relativePath))
+		}
+	}
+
+	dirty, err := gitCli.IsDirty(ctx, repositoryPath, excludePaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitMetadata{
+		Branch: branch,
+		Commit: commit,
+		Dirty:  dirty,
+	}, nil
+}
+
+// Gets the paths of the files azd generated in the sources of the services of the project. The ignore files and
+// Dockerfiles azd generates are only included while they're untracked and start with the header of generated files, so
+// the generated files committed afterwards are deployed as any other file.
+func generatedFiles(ctx context.Context, gitCli git.GitCli, projectConfig *ProjectConfig) ([]string, error) {
+	if projectConfig == nil || len(projectConfig.Services) == 0 {
+		return nil, nil
+	}
+
+	untrackedFiles, err := gitCli.ListUntrackedFiles(ctx, projectConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked := map[string]bool{}
+	for _, line := range strings.Split(untrackedFiles, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			untracked[filepath.Join(projectConfig.Path, filepath.FromSlash(line))] = true
+		}
+	}
+
+	names := maps.Keys(projectConfig.Services)
+	slices.Sort(names)
+
+	generated := []string{}
+	for _, name := range names {
+		serviceConfig := projectConfig.Services[name]
+		candidates := []string{
+			filepath.Join(serviceConfig.Path(), generatedDockerfileName),
+			filepath.Join(buildContextPath(serviceConfig), dockerIgnoreFileName),
+			filepath.Join(serviceConfig.Path(), funcIgnoreFileName),
+		}
+
+		for _, candidate := range candidates {
+			if untracked[candidate] && isGeneratedFile(candidate) {
+				generated = append(generated, candidate)
+			}
+		}
+	}
+
+	return generated, nil
+}
+
+// Checks whether the file starts with the header of the files generated by azd
+func isGeneratedFile(filePath string) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	return scanner.Scan() && strings.HasPrefix(scanner.Text(), generatedFileHeader)
+}
+
+// DeployGitTracker captures the git metadata of deployments, refuses deployments from dirty working trees and tags the
+// deployed commits, as configured by the git options of the project
+type DeployGitTracker struct {
+	env     *environment.Environment
+	gitCli  git.GitCli
+	console input.Console
+	clock   clock.Clock
+}
+
+// Creates a new instance of the deploy git tracker
+func NewDeployGitTracker(
+	env *environment.Environment,
+	gitCli git.GitCli,
+	console input.Console,
+	clock clock.Clock,
+) *DeployGitTracker {
+	return &DeployGitTracker{
+		env:     env,
+		gitCli:  gitCli,
+		console: console,
+		clock:   clock,
+	}
+}
+
+// Reads the git metadata of the project before it's deployed. Fails when the working tree has uncommitted changes and
+// the git options of the project require a clean working tree for the environment. Returns nil when the project isn't
+// in a git repository.
+func (t *DeployGitTracker) Check(ctx context.Context, projectConfig *ProjectConfig) (*GitMetadata, error) {
+	options := projectConfig.Git
+	requireClean := options != nil && options.RequireClean && options.appliesTo(t.env.GetEnvName())
+
+	metadata, err := readGitMetadata(ctx, t.gitCli, projectConfig.Path, projectConfig)
+	if errors.Is(err, git.ErrNotRepository) {
+		return nil, nil
+	} else if err != nil {
+		if requireClean {
+			return nil, fmt.Errorf("checking the git working tree of the project: %w", err)
+		}
+
+		log.Printf("failed reading the git metadata of the project: %v", err)
+		return nil, nil
+	}
+
+	if requireClean && metadata.Dirty {
+		return nil, fmt.Errorf(
+			"the working tree of the project has uncommitted changes, commit or stash them before deploying to "+
+				"environment '%s'",
+			t.env.GetEnvName(),
+		)
+	}
+
+	return metadata, nil
+}
+
+// Tags the deployed commit after the project is deployed, when configured for the environment. The tag is pushed to the
+// remote of the tag options. Failures don't fail the deployment, they're shown as warnings.
+func (t *DeployGitTracker) Tag(ctx context.Context, projectConfig *ProjectConfig, metadata *GitMetadata) {
+	options := projectConfig.Git
+	if options == nil || options.Tag == nil || metadata == nil || !options.appliesTo(t.env.GetEnvName()) {
+		return
+	}
+
+	if metadata.Dirty {
+		t.warn(ctx, fmt.Errorf("the working tree has uncommitted changes, which aren't in commit %s", metadata.Commit))
+		return
+	}
+
+	name, err := options.Tag.Name.Envsubst(t.env.Getenv)
+	if err != nil {
+		t.warn(ctx, fmt.Errorf("evaluating tag name: %w", err))
+		return
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("deploy/%s/%s", t.env.GetEnvName(), t.clock.Now().UTC().Format("20060102-150405"))
+	}
+
+	message := fmt.Sprintf("Deployed to %s", t.env.GetEnvName())
+	if err := t.gitCli.CreateTag(ctx, projectConfig.Path, name, metadata.Commit, message); err != nil {
+		t.warn(ctx, err)
+		return
+	}
+
+	if options.Tag.Push != "" {
+		if err := t.gitCli.PushTag(ctx, projectConfig.Path, options.Tag.Push, name); err != nil {
+			t.warn(ctx, err)
+		}
+	}
+
+	metadata.Tag = name
+}
+
+func (t *DeployGitTracker) warn(ctx context.Context, err error) {
+	t.console.MessageUxItem(ctx, &ux.WarningMessage{
+		Description: fmt.Sprintf("Tagging the deployed commit failed: %v", err),
+	})
+}
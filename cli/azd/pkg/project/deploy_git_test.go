@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+const testGitProj = `
+name: test-proj
+git:
+  environments: [prod, prod-*]
+  requireClean: true
+  tag:
+    push: origin
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+`
+
+func mockGitWorkingTree(mockContext *mocks.MockContext, status string) *[]exec.RunArgs {
+	commands := []exec.RunArgs{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == "git"
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, args)
+		command := strings.Join(args.Args, " ")
+		switch {
+		case strings.Contains(command, "rev-parse HEAD"):
+			return exec.NewRunResult(0, "0123456789abcdef\n", ""), nil
+		case strings.Contains(command, "branch --show-current"):
+			return exec.NewRunResult(0, "main\n", ""), nil
+		case strings.Contains(command, "status --porcelain"):
+			return exec.NewRunResult(0, status, ""), nil
+		default:
+			return exec.NewRunResult(0, "", ""), nil
+		}
+	})
+
+	return &commands
+}
+
+func Test_DeployGitTracker_Check(t *testing.T) {
+	projectConfig, err := Parse(context.Background(), testGitProj)
+	require.NoError(t, err)
+
+	t.Run("DirtyProd", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitWorkingTree(mockContext, "?? notes.txt\n")
+		tracker := NewDeployGitTracker(
+			environment.EphemeralWithValues("prod-eu", nil),
+			git.NewGitCli(mockContext.CommandRunner),
+			mockContext.Console,
+			clock.NewMock(),
+		)
+
+		_, err := tracker.Check(*mockContext.Context, projectConfig)
+		require.ErrorContains(t, err, "uncommitted changes, commit or stash them before deploying to environment 'prod-eu'")
+	})
+
+	t.Run("DirtyDev", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitWorkingTree(mockContext, " M src/api/index.js\n")
+		tracker := NewDeployGitTracker(
+			environment.EphemeralWithValues("dev", nil),
+			git.NewGitCli(mockContext.CommandRunner),
+			mockContext.Console,
+			clock.NewMock(),
+		)
+
+		metadata, err := tracker.Check(*mockContext.Context, projectConfig)
+		require.NoError(t, err)
+		require.Equal(t, &GitMetadata{Branch: "main", Commit: "0123456789abcdef", Dirty: true}, metadata)
+		require.Equal(t, "commit 0123456 on branch main, with uncommitted changes", metadata.Summary())
+	})
+
+	t.Run("GeneratedFiles", func(t *testing.T) {
+		generatedConfig, err := Parse(context.Background(), testGitProj)
+		require.NoError(t, err)
+		generatedConfig.Path = t.TempDir()
+		servicePath := filepath.Join(generatedConfig.Path, "src", "api")
+		require.NoError(t, os.MkdirAll(servicePath, osutil.PermissionDirectory))
+		dockerfile := generatedFileHeader + " for service api\nFROM node:20\n"
+		require.NoError(t, os.WriteFile(filepath.Join(servicePath, "Dockerfile"), []byte(dockerfile), osutil.PermissionFile))
+
+		// The untracked Dockerfile generated by azd is the only change of the working tree
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitWorkingTree(mockContext, "?? src/api/Dockerfile\n")
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "ls-files --others")
+		}).Respond(exec.NewRunResult(0, "src/api/Dockerfile\n", ""))
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, ":(exclude)src/api/Dockerfile")
+		}).Respond(exec.NewRunResult(0, "", ""))
+		tracker := NewDeployGitTracker(
+			environment.EphemeralWithValues("prod", nil),
+			git.NewGitCli(mockContext.CommandRunner),
+			mockContext.Console,
+			clock.NewMock(),
+		)
+
+		metadata, err := tracker.Check(*mockContext.Context, generatedConfig)
+		require.NoError(t, err)
+		require.False(t, metadata.Dirty)
+
+		// Files without the header of generated files are changes of the user
+		require.NoError(t, os.WriteFile(filepath.Join(servicePath, "Dockerfile"), []byte("FROM node:20\n"), osutil.PermissionFile))
+		_, err = tracker.Check(*mockContext.Context, generatedConfig)
+		require.ErrorContains(t, err, "uncommitted changes")
+	})
+
+	t.Run("NotRepository", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "git"
+		}).Respond(exec.NewRunResult(128, "", "fatal: not a git repository (or any of the parent directories): .git"))
+		tracker := NewDeployGitTracker(
+			environment.EphemeralWithValues("prod", nil),
+			git.NewGitCli(mockContext.CommandRunner),
+			mockContext.Console,
+			clock.NewMock(),
+		)
+
+		metadata, err := tracker.Check(*mockContext.Context, projectConfig)
+		require.NoError(t, err)
+		require.Nil(t, metadata)
+	})
+}
+
+func Test_DeployGitTracker_Tag(t *testing.T) {
+	projectConfig, err := Parse(context.Background(), testGitProj)
+	require.NoError(t, err)
+	projectConfig.Path = "/repo"
+
+	mockContext := mocks.NewMockContext(context.Background())
+	commands := mockGitWorkingTree(mockContext, "")
+	mockClock := clock.NewMock()
+	mockClock.Set(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	tracker := NewDeployGitTracker(
+		environment.EphemeralWithValues("prod", nil),
+		git.NewGitCli(mockContext.CommandRunner),
+		mockContext.Console,
+		mockClock,
+	)
+
+	metadata, err := tracker.Check(*mockContext.Context, projectConfig)
+	require.NoError(t, err)
+
+	*commands = nil
+	tracker.Tag(*mockContext.Context, projectConfig, metadata)
+	require.Empty(t, mockContext.Console.Output())
+	require.Equal(t, "deploy/prod/20240506-070809", metadata.Tag)
+	require.Len(t, *commands, 2)
+	require.Equal(t, []string{
+		"-C", "/repo", "tag", "--annotate", "deploy/prod/20240506-070809", "--message", "Deployed to prod",
+		"0123456789abcdef",
+	}, (*commands)[0].Args)
+	require.Equal(t, []string{
+		"-C", "/repo", "push", "--quiet", "origin", "refs/tags/deploy/prod/20240506-070809",
+	}, (*commands)[1].Args)
+
+	// Dirty working trees aren't tagged, since the changes deployed aren't in the commit
+	*commands = nil
+	tracker.Tag(*mockContext.Context, projectConfig, &GitMetadata{Commit: "0123456789abcdef", Dirty: true})
+	require.Empty(t, *commands)
+	require.Len(t, mockContext.Console.Output(), 1)
+	require.Contains(t, mockContext.Console.Output()[0], "uncommitted changes")
+}
@@ -0,0 +1,262 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/benbjohnson/clock"
+)
+
+// The named value of the API Management service updated to invalidate its cache when the named value isn't set
+const defaultApimCacheNamedValue = "cache-version"
+
+// OnDeployedAction is an action run after the service is deployed, ex) restarting a service that depends on the deployed
+// service. Exactly one of Restart, PurgeCdn and InvalidateApimCache is set.
+type OnDeployedAction struct {
+	// The name of the service restarted, ex) web. Container apps, app services and function apps can be restarted.
+	Restart string `yaml:"restart"`
+	// The Azure Front Door or Azure CDN endpoint purged
+	PurgeCdn *CdnPurgeOptions `yaml:"purgeCdn"`
+	// The API Management service the cache of is invalidated
+	InvalidateApimCache *ApimCacheOptions `yaml:"invalidateApimCache"`
+	// When true, a failure of the action is shown as a warning instead of failing the deployment
+	ContinueOnError bool `yaml:"continueOnError"`
+}
+
+// CdnPurgeOptions are the Azure Front Door or Azure CDN endpoint purged after the service is deployed
+type CdnPurgeOptions struct {
+	// The resource group of the profile. Defaults to the resource group of the project.
+	ResourceGroup ExpandableString `yaml:"resourceGroup"`
+	// The name of the Front Door or CDN profile
+	Profile ExpandableString `yaml:"profile"`
+	// The name of the endpoint of the profile
+	Endpoint ExpandableString `yaml:"endpoint"`
+	// The content paths purged, ex) /api/*. Defaults to /*.
+	Paths []string `yaml:"paths"`
+}
+
+// ApimCacheOptions are the API Management service the cache of is invalidated after the service is deployed.
+// API Management doesn't purge cached responses, so the cache is invalidated by setting a named value included in the
+// cache keys of the policies to a new value, ex) <cache-lookup-value key="@("orders-{{cache-version}}")" />.
+type ApimCacheOptions struct {
+	// The resource group of the service. Defaults to the resource group of the project.
+	ResourceGroup ExpandableString `yaml:"resourceGroup"`
+	// The name of the API Management service
+	Service ExpandableString `yaml:"service"`
+	// The id of the named value set to a new value, which must exist. Defaults to cache-version.
+	NamedValue string `yaml:"namedValue"`
+}
+
+// Validates the actions run after the service is deployed
+func validateOnDeployed(projectConfig *ProjectConfig, serviceConfig *ServiceConfig) error {
+	for i, action := range serviceConfig.OnDeployed {
+		actions := 0
+		for _, set := range []bool{action.Restart != "", action.PurgeCdn != nil, action.InvalidateApimCache != nil} {
+			if set {
+				actions++
+			}
+		}
+
+		if actions != 1 {
+			return fmt.Errorf(
+				"onDeployed action %d must set exactly one of 'restart', 'purgeCdn' or 'invalidateApimCache'",
+				i+1,
+			)
+		}
+
+		if action.Restart == "" {
+			continue
+		}
+
+		if action.Restart == serviceConfig.Name {
+			return fmt.Errorf("onDeployed action %d restarts the deployed service", i+1)
+		}
+
+		if _, has := projectConfig.Services[action.Restart]; !has {
+			return fmt.Errorf("onDeployed action %d restarts service '%s', which isn't in the project", i+1, action.Restart)
+		}
+	}
+
+	return nil
+}
+
+// DeployNotifier is an optional deployment step that runs the onDeployed actions of a service after the service has been
+// deployed, so the resources depending on the service pick up the new deployment.
+type DeployNotifier struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager ResourceManager
+	console         input.Console
+	clock           clock.Clock
+}
+
+// Creates a new instance of the deploy notifier
+func NewDeployNotifier(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager ResourceManager,
+	console input.Console,
+	clock clock.Clock,
+) *DeployNotifier {
+	return &DeployNotifier{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+		console:         console,
+		clock:           clock,
+	}
+}
+
+// Initializes the deploy notifier for the service by subscribing to the postdeploy event
+func (n *DeployNotifier) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	handler := func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		return n.Notify(ctx, args.Service)
+	}
+
+	return serviceConfig.AddHandler(ext.Event("post"+ServiceEventDeploy), handler)
+}
+
+// Runs the onDeployed actions of the service in order. Actions set to continue on error show their failures as
+// warnings, other failures stop the remaining actions.
+func (n *DeployNotifier) Notify(ctx context.Context, serviceConfig *ServiceConfig) error {
+	for i, action := range serviceConfig.OnDeployed {
+		err := n.run(ctx, serviceConfig, action)
+		if err == nil {
+			continue
+		}
+
+		if !action.ContinueOnError {
+			return fmt.Errorf("running onDeployed action %d of service '%s': %w", i+1, serviceConfig.Name, err)
+		}
+
+		n.console.MessageUxItem(ctx, &ux.WarningMessage{
+			Description: fmt.Sprintf("The onDeployed action %d of service '%s' failed: %v", i+1, serviceConfig.Name, err),
+		})
+	}
+
+	return nil
+}
+
+func (n *DeployNotifier) run(ctx context.Context, serviceConfig *ServiceConfig, action OnDeployedAction) error {
+	subscriptionId := n.env.GetSubscriptionId()
+
+	switch {
+	case action.Restart != "":
+		dependent, has := serviceConfig.Project.Services[action.Restart]
+		if !has {
+			return fmt.Errorf("service '%s' isn't in the project", action.Restart)
+		}
+
+		return n.restart(ctx, subscriptionId, dependent)
+	case action.PurgeCdn != nil:
+		resourceGroup, err := n.resourceGroup(ctx, subscriptionId, serviceConfig, action.PurgeCdn.ResourceGroup)
+		if err != nil {
+			return err
+		}
+
+		profile, err := action.PurgeCdn.Profile.Envsubst(n.env.Getenv)
+		if err != nil {
+			return fmt.Errorf("evaluating profile: %w", err)
+		}
+
+		endpoint, err := action.PurgeCdn.Endpoint.Envsubst(n.env.Getenv)
+		if err != nil {
+			return fmt.Errorf("evaluating endpoint: %w", err)
+		}
+
+		if profile == "" || endpoint == "" {
+			return errors.New("'purgeCdn.profile' and 'purgeCdn.endpoint' are required")
+		}
+
+		paths := action.PurgeCdn.Paths
+		if len(paths) == 0 {
+			paths = []string{"/*"}
+		}
+
+		log.Printf("purging %s of endpoint %s of profile %s", strings.Join(paths, ", "), endpoint, profile)
+		return n.azCli.PurgeCdnEndpoint(ctx, subscriptionId, resourceGroup, profile, endpoint, paths)
+	case action.InvalidateApimCache != nil:
+		options := action.InvalidateApimCache
+		resourceGroup, err := n.resourceGroup(ctx, subscriptionId, serviceConfig, options.ResourceGroup)
+		if err != nil {
+			return err
+		}
+
+		apimName, err := options.Service.Envsubst(n.env.Getenv)
+		if err != nil {
+			return fmt.Errorf("evaluating api management service: %w", err)
+		}
+
+		if apimName == "" {
+			return errors.New("'invalidateApimCache.service' is required")
+		}
+
+		namedValue := options.NamedValue
+		if namedValue == "" {
+			namedValue = defaultApimCacheNamedValue
+		}
+
+		value := n.clock.Now().UTC().Format("20060102150405")
+		log.Printf("setting named value %s of api management service %s to %s", namedValue, apimName, value)
+		return n.azCli.SetApimNamedValue(ctx, subscriptionId, resourceGroup, apimName, namedValue, value)
+	default:
+		return errors.New("no action is set")
+	}
+}
+
+// Restarts the resource hosting the service
+func (n *DeployNotifier) restart(ctx context.Context, subscriptionId string, serviceConfig *ServiceConfig) error {
+	targetResource, err := n.resourceManager.GetTargetResource(ctx, subscriptionId, serviceConfig)
+	if err != nil {
+		return fmt.Errorf("getting target resource of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	log.Printf("restarting %s of service %s", targetResource.ResourceName(), serviceConfig.Name)
+	switch {
+	case strings.EqualFold(targetResource.ResourceType(), string(infra.AzureResourceTypeContainerApp)):
+		return n.azCli.RestartContainerApp(
+			ctx, subscriptionId, targetResource.ResourceGroupName(), targetResource.ResourceName(),
+		)
+	case strings.EqualFold(targetResource.ResourceType(), string(infra.AzureResourceTypeWebSite)):
+		return n.azCli.RestartAppService(
+			ctx, subscriptionId, targetResource.ResourceGroupName(), targetResource.ResourceName(),
+		)
+	default:
+		return fmt.Errorf(
+			"restarting service '%s' isn't supported, resources of type '%s' can't be restarted",
+			serviceConfig.Name,
+			targetResource.ResourceType(),
+		)
+	}
+}
+
+// Gets the resource group of an action, the configured resource group or the resource group of the project
+func (n *DeployNotifier) resourceGroup(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *ServiceConfig,
+	configured ExpandableString,
+) (string, error) {
+	resourceGroup, err := configured.Envsubst(n.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("evaluating resource group: %w", err)
+	}
+
+	if resourceGroup != "" {
+		return resourceGroup, nil
+	}
+
+	return n.resourceManager.GetResourceGroupName(ctx, subscriptionId, serviceConfig.Project)
+}
@@ -0,0 +1,152 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockarmresources"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_OnDeployed(t *testing.T) {
+	tests := map[string]struct {
+		onDeployed string
+		err        string
+	}{
+		"Valid": {
+			onDeployed: `
+      - restart: web
+      - purgeCdn:
+          profile: afd-test
+          endpoint: web
+        continueOnError: true`,
+		},
+		"UnknownService": {
+			onDeployed: `
+      - restart: worker`,
+			err: "restarts service 'worker', which isn't in the project",
+		},
+		"RestartsItself": {
+			onDeployed: `
+      - restart: api`,
+			err: "onDeployed action 1 restarts the deployed service",
+		},
+		"MultipleActions": {
+			onDeployed: `
+      - restart: web
+        invalidateApimCache:
+          service: apim-test`,
+			err: "onDeployed action 1 must set exactly one of",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testProj := `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    onDeployed:` + test.onDeployed + `
+  web:
+    project: src/web
+    language: js
+    host: appservice
+`
+			projectConfig, err := Parse(context.Background(), testProj)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, projectConfig.Services["api"].OnDeployed, 2)
+			require.Equal(t, "web", projectConfig.Services["api"].OnDeployed[0].Restart)
+		})
+	}
+}
+
+func Test_DeployNotifier_Notify(t *testing.T) {
+	const testProj = `
+name: test-proj
+resourceGroup: rg-test
+services:
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    onDeployed:
+      - restart: docs
+        continueOnError: true
+      - restart: web
+  web:
+    project: src/web
+    language: js
+    host: appservice
+  docs:
+    project: src/docs
+    language: js
+    host: staticwebapp
+`
+	mockContext := mocks.NewMockContext(context.Background())
+	mockarmresources.AddAzResourceListMock(
+		mockContext.HttpClient,
+		convert.RefOf("rg-test"),
+		[]*armresources.GenericResourceExpanded{
+			{
+				ID:       convert.RefOf("app-web"),
+				Name:     convert.RefOf("app-web"),
+				Type:     convert.RefOf(string(infra.AzureResourceTypeWebSite)),
+				Location: convert.RefOf("eastus2"),
+				Tags:     map[string]*string{defaultServiceTag: convert.RefOf("web")},
+			},
+			{
+				ID:       convert.RefOf("stapp-docs"),
+				Name:     convert.RefOf("stapp-docs"),
+				Type:     convert.RefOf(string(infra.AzureResourceTypeStaticWebSite)),
+				Location: convert.RefOf("eastus2"),
+				Tags:     map[string]*string{defaultServiceTag: convert.RefOf("docs")},
+			},
+		})
+
+	restarted := false
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/sites/app-web/restart")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		restarted = true
+		return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+	})
+
+	env := environment.EphemeralWithValues("envA", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	notifier := NewDeployNotifier(env, azCli, NewResourceManager(env, azCli), mockContext.Console, clock.NewMock())
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+	serviceConfig := projectConfig.Services["api"]
+
+	// Static web apps can't be restarted, the failure is a warning since the action continues on error
+	err = notifier.Notify(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+	require.True(t, restarted)
+	require.Len(t, mockContext.Console.Output(), 1)
+	require.Contains(t, mockContext.Console.Output()[0], "The onDeployed action 1 of service 'api' failed")
+
+	serviceConfig.OnDeployed[0].ContinueOnError = false
+	err = notifier.Notify(*mockContext.Context, serviceConfig)
+	require.ErrorContains(t, err, "running onDeployed action 1 of service 'api'")
+	require.ErrorContains(t, err, "resources of type 'Microsoft.Web/staticSites' can't be restarted")
+}
@@ -0,0 +1,355 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/benbjohnson/clock"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// DeployStrategyKind is how the services of a project are deployed to an environment
+type DeployStrategyKind string
+
+const (
+	// Deploys all the services at the same time, the fastest strategy, ex) for dev environments
+	DeployStrategyAllAtOnce DeployStrategyKind = "all-at-once"
+	// Deploys up to maxParallel services at the same time. A service is deployed once a service deployed before it is
+	// healthy, so at most maxParallel services are being replaced at any time.
+	DeployStrategyRolling DeployStrategyKind = "rolling"
+	// Deploys the services one at a time, each service being deployed once the service deployed before it is healthy
+	DeployStrategySequential DeployStrategyKind = "sequential"
+)
+
+// The interval used when polling the health of the endpoints of a deployed service
+var deployHealthPollInterval = 5 * time.Second
+
+const (
+	// The maximum duration waited for a deployed service to be healthy when the timeout isn't configured
+	defaultDeployHealthTimeout = 5 * time.Minute
+	// The number of services deployed at the same time by the rolling strategy when maxParallel isn't configured
+	defaultMaxParallel = 1
+)
+
+// DeployStrategyOptions are how the services of the project are deployed, see DeployStrategyKind. When the strategy isn't
+// configured, the services are deployed one at a time without health gates.
+type DeployStrategyOptions struct {
+	// The strategy, all-at-once, rolling or sequential
+	Strategy DeployStrategyKind `yaml:"strategy"`
+	// The maximum number of services deployed at the same time by the rolling strategy. Defaults to 1.
+	MaxParallel int `yaml:"maxParallel"`
+	// The path of the endpoints of a deployed service requested by the health gates of the rolling and sequential
+	// strategies, ex) /health. A service is healthy when the path responds with a 2xx or 3xx status. Defaults to /.
+	HealthPath string `yaml:"healthPath"`
+	// The maximum duration waited for a deployed service to be healthy, ex) 10m. Defaults to 5m.
+	HealthTimeout string `yaml:"healthTimeout"`
+}
+
+// DeployOptions are the deploy strategy of the project, which can be overridden for environments, ex) all-at-once in dev
+// and rolling in prod
+type DeployOptions struct {
+	DeployStrategyOptions `yaml:",inline"`
+	// The deploy strategies overridden for environments, by environment name
+	Environments map[string]DeployStrategyOptions `yaml:"environments"`
+}
+
+// DeployStrategy gets the deploy strategy of the project for the environment, the settings overridden for the environment
+// taking precedence over the settings of the project
+func (p *ProjectConfig) DeployStrategy(envName string) DeployStrategyOptions {
+	if p.Deploy == nil {
+		return DeployStrategyOptions{}
+	}
+
+	strategy := p.Deploy.DeployStrategyOptions
+	if overrides, has := p.Deploy.Environments[envName]; has {
+		if overrides.Strategy != "" {
+			strategy.Strategy = overrides.Strategy
+		}
+
+		if overrides.MaxParallel != 0 {
+			strategy.MaxParallel = overrides.MaxParallel
+		}
+
+		if overrides.HealthPath != "" {
+			strategy.HealthPath = overrides.HealthPath
+		}
+
+		if overrides.HealthTimeout != "" {
+			strategy.HealthTimeout = overrides.HealthTimeout
+		}
+	}
+
+	return strategy
+}
+
+// Validates the deploy strategy of the project and of its environments
+func validateDeployStrategy(projectConfig *ProjectConfig) error {
+	if projectConfig.Deploy == nil {
+		return nil
+	}
+
+	if err := projectConfig.Deploy.DeployStrategyOptions.validate(); err != nil {
+		return fmt.Errorf("parsing deploy: %w", err)
+	}
+
+	envNames := maps.Keys(projectConfig.Deploy.Environments)
+	slices.Sort(envNames)
+	for _, envName := range envNames {
+		if err := projectConfig.Deploy.Environments[envName].validate(); err != nil {
+			return fmt.Errorf("parsing deploy for environment %s: %w", envName, err)
+		}
+	}
+
+	return nil
+}
+
+func (o DeployStrategyOptions) validate() error {
+	switch o.Strategy {
+	case "", DeployStrategyAllAtOnce, DeployStrategyRolling, DeployStrategySequential:
+	default:
+		return fmt.Errorf(
+			"unsupported strategy '%s', supported strategies are 'all-at-once', 'rolling' and 'sequential'", o.Strategy)
+	}
+
+	if o.MaxParallel < 0 {
+		return fmt.Errorf("invalid 'maxParallel' %d, expected a positive number", o.MaxParallel)
+	}
+
+	if o.HealthPath != "" && !strings.HasPrefix(o.HealthPath, "/") {
+		return fmt.Errorf("invalid 'healthPath' '%s', the path must start with /", o.HealthPath)
+	}
+
+	if o.HealthTimeout != "" {
+		if timeout, err := time.ParseDuration(o.HealthTimeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid 'healthTimeout' '%s', expected a duration, ex) 10m", o.HealthTimeout)
+		}
+	}
+
+	return nil
+}
+
+// Gets the maximum number of services deployed at the same time with the strategy
+func (o DeployStrategyOptions) maxConcurrency(serviceCount int) int {
+	switch o.Strategy {
+	case DeployStrategyAllAtOnce:
+		if serviceCount > 1 {
+			return serviceCount
+		}
+
+		return 1
+	case DeployStrategyRolling:
+		if o.MaxParallel > 0 {
+			return o.MaxParallel
+		}
+
+		return defaultMaxParallel
+	default:
+		return 1
+	}
+}
+
+// Checks whether the strategy waits for the deployed services to be healthy before deploying the next services
+func (o DeployStrategyOptions) hasHealthGates() bool {
+	return o.Strategy == DeployStrategyRolling || o.Strategy == DeployStrategySequential
+}
+
+// ServicePublishFunc publishes a packaged service, setting the publish result of the deploy result of the service
+type ServicePublishFunc func(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress]
+
+// DeployResults are the deployed services by name
+type DeployResults struct {
+	Services map[string]*ServiceDeployResult
+}
+
+// DeployOrchestrator deploys the packaged services of a project with the deploy strategy of the environment. Services are
+// deployed in order once the services they depend on are deployed, and when a service fails, the services that aren't
+// deployed yet are skipped.
+type DeployOrchestrator struct {
+	httpClient httputil.HttpClient
+	clock      clock.Clock
+}
+
+// Creates a new instance of the DeployOrchestrator
+func NewDeployOrchestrator(httpClient httputil.HttpClient, clock clock.Clock) *DeployOrchestrator {
+	return &DeployOrchestrator{
+		httpClient: httpClient,
+		clock:      clock,
+	}
+}
+
+// Publish deploys the services with the publish function and the strategy, reporting the progress of all services through
+// the task. With the rolling and sequential strategies, a deployed service completes once its endpoints are healthy.
+// The task fails with the errors of the failed services.
+func (o *DeployOrchestrator) Publish(
+	ctx context.Context,
+	services []*ServiceConfig,
+	strategy DeployStrategyOptions,
+	publishFn ServicePublishFunc,
+) *async.TaskWithProgress[*DeployResults, BuildProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*DeployResults, BuildProgress]) {
+			log.Printf(
+				"deploying %d services with strategy '%s', up to %d at the same time",
+				len(services), strategy.Strategy, strategy.maxConcurrency(len(services)))
+
+			gatedFn := func(
+				ctx context.Context,
+				serviceConfig *ServiceConfig,
+			) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+				return o.publishGated(ctx, serviceConfig, strategy, publishFn)
+			}
+
+			results, err := runServices(ctx, strategy.maxConcurrency(len(services)), services, "deploying", gatedFn,
+				func(progress BuildProgress, result *ServiceDeployResult) {
+					progress.Result = result
+					task.SetProgress(progress)
+				})
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&DeployResults{Services: results})
+		},
+	)
+}
+
+// Publishes the service, then waits for the service to be healthy when the strategy has health gates
+func (o *DeployOrchestrator) publishGated(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	strategy DeployStrategyOptions,
+	publishFn ServicePublishFunc,
+) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+			publishTask := publishFn(ctx, serviceConfig)
+			for progress := range publishTask.Progress() {
+				task.SetProgress(progress)
+			}
+
+			result, err := publishTask.Await()
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			if strategy.hasHealthGates() && result.Publish != nil {
+				task.SetProgress(NewServiceProgress("Waiting for the service to be healthy"))
+				if err := o.waitHealthy(ctx, serviceConfig, result.Publish.Endpoints, strategy); err != nil {
+					task.SetError(err)
+					return
+				}
+			}
+
+			task.SetResult(result)
+		},
+	)
+}
+
+// Polls the endpoints of the deployed service until they're all healthy. Services without endpoints reachable from azd,
+// ex) workers or services only exposed in their cluster, are considered healthy.
+func (o *DeployOrchestrator) waitHealthy(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	endpoints []string,
+	strategy DeployStrategyOptions,
+) error {
+	timeout := defaultDeployHealthTimeout
+	if strategy.HealthTimeout != "" {
+		parsed, err := time.ParseDuration(strategy.HealthTimeout)
+		if err != nil {
+			return fmt.Errorf("parsing health timeout: %w", err)
+		}
+
+		timeout = parsed
+	}
+
+	path := strategy.HealthPath
+	if path == "" {
+		path = "/"
+	}
+
+	deadline := o.clock.Now().Add(timeout)
+	for _, endpoint := range endpoints {
+		healthUrl, ok := deployHealthUrl(endpoint, path)
+		if !ok {
+			log.Printf("skipping health gate of endpoint '%s' of service %s, it isn't reachable", endpoint, serviceConfig.Name)
+			continue
+		}
+
+		for {
+			err := o.checkHealth(ctx, healthUrl)
+			if err == nil {
+				break
+			}
+
+			log.Printf("service %s isn't healthy yet: %v", serviceConfig.Name, err)
+			if !o.clock.Now().Before(deadline) {
+				return fmt.Errorf("service %s isn't healthy after %s: %w", serviceConfig.Name, timeout, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-o.clock.After(deployHealthPollInterval):
+			}
+		}
+	}
+
+	return nil
+}
+
+// Checks whether the health url responds with a 2xx or 3xx status
+func (o *DeployOrchestrator) checkHealth(ctx context.Context, healthUrl string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, healthUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := o.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s responded with status %d", healthUrl, response.StatusCode)
+	}
+
+	return nil
+}
+
+// Gets the url requested by the health gate of an endpoint of a service. Endpoints can be followed by a description,
+// ex) http://10.0.0.4:80, (Service, Type: ClusterIP). Endpoints that aren't http urls, or are only reachable in their
+// cluster, have no health gate.
+func deployHealthUrl(endpoint string, path string) (string, bool) {
+	address, description, _ := strings.Cut(endpoint, ",")
+	if strings.Contains(description, "ClusterIP") {
+		return "", false
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(address))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", false
+	}
+
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/") + path
+	return parsed.String(), true
+}
@@ -0,0 +1,174 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ProjectConfig_DeployStrategy(t *testing.T) {
+	projectConfig, err := Parse(context.Background(), `
+name: test-app
+deploy:
+  strategy: all-at-once
+  healthPath: /health
+  environments:
+    prod:
+      strategy: rolling
+      maxParallel: 2
+`)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		DeployStrategyOptions{Strategy: DeployStrategyAllAtOnce, HealthPath: "/health"},
+		projectConfig.DeployStrategy("dev"))
+	require.Equal(t,
+		DeployStrategyOptions{Strategy: DeployStrategyRolling, MaxParallel: 2, HealthPath: "/health"},
+		projectConfig.DeployStrategy("prod"))
+
+	projectConfig.Deploy = nil
+	require.Equal(t, DeployStrategyOptions{}, projectConfig.DeployStrategy("prod"))
+
+	_, err = Parse(context.Background(), "name: test-app\ndeploy:\n  environments:\n    prod:\n      strategy: canary\n")
+	require.ErrorContains(t, err, "parsing deploy for environment prod: unsupported strategy 'canary'")
+
+	_, err = Parse(context.Background(), "name: test-app\ndeploy:\n  healthTimeout: soon\n")
+	require.ErrorContains(t, err, "invalid 'healthTimeout' 'soon'")
+}
+
+func Test_DeployOrchestrator_Publish(t *testing.T) {
+	interval := deployHealthPollInterval
+	deployHealthPollInterval = time.Millisecond
+	t.Cleanup(func() { deployHealthPollInterval = interval })
+
+	services := func() []*ServiceConfig {
+		services := []*ServiceConfig{}
+		for _, name := range []string{"api", "web", "worker"} {
+			serviceConfig := createTestServiceConfig("./src/"+name, ContainerAppTarget, ServiceLanguagePython)
+			serviceConfig.Name = name
+			services = append(services, serviceConfig)
+		}
+
+		return services
+	}
+
+	// Publishes the services, recording the maximum number of services deployed at the same time
+	publish := func(
+		t *testing.T,
+		strategy DeployStrategyOptions,
+		unhealthyChecks int,
+	) (*DeployResults, int, error) {
+		var mutex sync.Mutex
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.HttpClient.WhenPath(http.MethodGet, "/health").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if unhealthyChecks > 0 {
+					unhealthyChecks--
+					return mocks.CreateEmptyHttpResponse(request, http.StatusServiceUnavailable)
+				}
+
+				return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+			})
+
+		running := 0
+		maxRunning := 0
+		publishFn := func(
+			ctx context.Context,
+			serviceConfig *ServiceConfig,
+		) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
+			return async.RunTaskWithProgress(
+				func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
+					mutex.Lock()
+					running++
+					if running > maxRunning {
+						maxRunning = running
+					}
+					mutex.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+
+					mutex.Lock()
+					running--
+					mutex.Unlock()
+
+					task.SetResult(&ServiceDeployResult{
+						Publish: &ServicePublishResult{
+							Endpoints: []string{"https://" + serviceConfig.Name + ".contoso.com/"},
+						},
+					})
+				})
+		}
+
+		orchestrator := NewDeployOrchestrator(mockContext.HttpClient, clock.New())
+		publishTask := orchestrator.Publish(*mockContext.Context, services(), strategy, publishFn)
+		go func() {
+			for range publishTask.Progress() {
+			}
+		}()
+
+		results, err := publishTask.Await()
+		return results, maxRunning, err
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		results, maxRunning, err := publish(t, DeployStrategyOptions{}, 0)
+		require.NoError(t, err)
+		require.Len(t, results.Services, 3)
+		require.Equal(t, 1, maxRunning)
+	})
+
+	t.Run("AllAtOnce", func(t *testing.T) {
+		results, maxRunning, err := publish(t, DeployStrategyOptions{Strategy: DeployStrategyAllAtOnce}, 0)
+		require.NoError(t, err)
+		require.Len(t, results.Services, 3)
+		require.Equal(t, 3, maxRunning)
+	})
+
+	t.Run("Rolling", func(t *testing.T) {
+		strategy := DeployStrategyOptions{Strategy: DeployStrategyRolling, MaxParallel: 2, HealthPath: "/health"}
+		results, maxRunning, err := publish(t, strategy, 2)
+		require.NoError(t, err)
+		require.Len(t, results.Services, 3)
+		require.LessOrEqual(t, maxRunning, 2)
+	})
+
+	t.Run("SequentialUnhealthy", func(t *testing.T) {
+		strategy := DeployStrategyOptions{
+			Strategy:      DeployStrategySequential,
+			HealthPath:    "/health",
+			HealthTimeout: "50ms",
+		}
+		_, _, err := publish(t, strategy, 1000)
+		require.ErrorContains(t, err, "deploying service api: service api isn't healthy after 50ms")
+		require.ErrorContains(t, err, "https://api.contoso.com/health responded with status 503")
+	})
+}
+
+func Test_DeployHealthUrl(t *testing.T) {
+	healthUrl, ok := deployHealthUrl("https://api.contoso.com/", "/health")
+	require.True(t, ok)
+	require.Equal(t, "https://api.contoso.com/health", healthUrl)
+
+	healthUrl, ok = deployHealthUrl("http://20.1.2.3, (Service, Type: LoadBalancer)", "/")
+	require.True(t, ok)
+	require.Equal(t, "http://20.1.2.3/", healthUrl)
+
+	_, ok = deployHealthUrl("http://10.0.0.4:80, (Service, Type: ClusterIP)", "/")
+	require.False(t, ok)
+
+	_, ok = deployHealthUrl("api.contoso.com", "/")
+	require.False(t, ok)
+}
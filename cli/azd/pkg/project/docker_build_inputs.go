@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/kballard/go-shellquote"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The labels added to the images built by azd, tracing images in the container registry back to the environment and
+// the commit that produced them
+const (
+	// The commit of the source the image was built from
+	ImageRevisionLabel = "org.opencontainers.image.revision"
+	// The name of the azd environment the image was built for
+	ImageEnvironmentLabel = "com.microsoft.azd.environment"
+	// The name of the azd project
+	ImageProjectLabel = "com.microsoft.azd.project"
+	// The name of the service of the azd project
+	ImageServiceLabel = "com.microsoft.azd.service"
+	// The id of the template the azd project was created from
+	ImageTemplateLabel = "com.microsoft.azd.template"
+	// The variant of the matrix of the service the image was built for
+	ImageVariantLabel = "com.microsoft.azd.variant"
+	// The git branch of the source the image was built from
+	ImageBranchLabel = "com.microsoft.azd.branch"
+	// Set to true when the image was built from a working tree with uncommitted changes, which aren't in the revision
+	ImageDirtyLabel = "com.microsoft.azd.dirty"
+	// The hash of the source the image was built from, see ReuseUnchanged
+	ImageSourceHashLabel = "com.microsoft.azd.source-hash"
+)
+
+// Expands the build args of the docker options with the environment values, as KEY=VALUE pairs sorted by key
+func (p *dockerProject) resolveBuildArgs(options DockerProjectOptions) ([]string, error) {
+	keys := maps.Keys(options.BuildArgs)
+	slices.Sort(keys)
+
+	buildArgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, err := options.BuildArgs[key].Envsubst(p.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("build arg '%s': %w", key, err)
+		}
+
+		buildArgs = append(buildArgs, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	return buildArgs, nil
+}
+
+// Splits the build options of the docker options into the arguments passed to docker build, ex) --ssh default to --ssh
+// and default. Quotes group arguments with spaces, like a shell does.
+func resolveBuildOptions(options DockerProjectOptions) ([]string, error) {
+	args := []string{}
+	for _, option := range options.BuildOptions {
+		optionArgs, err := shellquote.Split(option)
+		if err != nil {
+			return nil, fmt.Errorf("parsing build option '%s': %w", option, err)
+		}
+
+		args = append(args, optionArgs...)
+	}
+
+	return args, nil
+}
+
+// Gets the additional arguments passed to docker build, the build options after the arguments of the azd flags
+func dockerBuildExtraArgs(buildOptions []string, noCache bool) []string {
+	args := []string{}
+	if noCache {
+		args = append(args, "--no-cache")
+	}
+
+	return append(args, buildOptions...)
+}
+
+// Gets the labels of the image built for the service, as KEY=VALUE pairs sorted by key. The revision, branch and dirty
+// labels are only added when the service is in a git repository.
+func (p *dockerProject) imageLabels(ctx context.Context, serviceConfig *ServiceConfig) []string {
+	values := map[string]string{
+		ImageEnvironmentLabel: p.env.GetEnvName(),
+		ImageServiceLabel:     serviceConfig.Name,
+		ImageVariantLabel:     serviceConfig.Variant(),
+	}
+
+	if serviceConfig.Project != nil {
+		values[ImageProjectLabel] = serviceConfig.Project.Name
+		if serviceConfig.Project.Metadata != nil {
+			values[ImageTemplateLabel] = serviceConfig.Project.Metadata.Template
+		}
+	}
+
+	metadata, err := readGitMetadata(ctx, p.gitCli, serviceConfig.Path(), serviceConfig.Project)
+	if err == nil {
+		values[ImageRevisionLabel] = metadata.Commit
+		values[ImageBranchLabel] = metadata.Branch
+		if metadata.Dirty {
+			values[ImageDirtyLabel] = "true"
+		}
+	} else if !errors.Is(err, git.ErrNotRepository) {
+		log.Printf("failed reading git metadata for service %s: %v", serviceConfig.Name, err)
+	}
+
+	keys := maps.Keys(values)
+	slices.Sort(keys)
+
+	labels := []string{}
+	for _, key := range keys {
+		if values[key] != "" {
+			labels = append(labels, fmt.Sprintf("%s=%s", key, values[key]))
+		}
+	}
+
+	return labels
+}
+
+// Resolves the BuildKit cache specs of the cacheFrom and cacheTo docker options
+func (p *dockerProject) resolveBuildCache(options DockerProjectOptions) (docker.BuildCache, error) {
+	cache := docker.BuildCache{}
+
+	cacheFrom, err := p.resolveCacheSpec(options.CacheFrom, options.Registry, "")
+	if err != nil {
+		return cache, fmt.Errorf("cacheFrom: %w", err)
+	}
+	if cacheFrom != "" {
+		cache.From = append(cache.From, cacheFrom)
+	}
+
+	// Exports all layers, by default only the layers of the final image are exported
+	cacheTo, err := p.resolveCacheSpec(options.CacheTo, options.Registry, ",mode=max")
+	if err != nil {
+		return cache, fmt.Errorf("cacheTo: %w", err)
+	}
+	if cacheTo != "" {
+		cache.To = append(cache.To, cacheTo)
+	}
+
+	return cache, nil
+}
+
+// Resolves a cache option to a BuildKit cache spec. Cache repositories without a registry, ex) cache/api:buildcache,
+// are in the registry images are pushed to.
+func (p *dockerProject) resolveCacheSpec(
+	option ExpandableString,
+	registry DockerRegistryOptions,
+	registryAttributes string,
+) (string, error) {
+	value, err := option.Envsubst(p.env.Getenv)
+	if err != nil {
+		return "", err
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" || strings.Contains(value, "type=") {
+		return value, nil
+	}
+
+	// Like docker, the first part of a repository is a registry when it's a host name
+	if host, _, _ := strings.Cut(value, "/"); !strings.ContainsAny(host, ".:") && host != "localhost" {
+		loginServer, _, err := resolveRegistryEndpoint(p.env, registry)
+		if err != nil {
+			return "", err
+		}
+
+		if loginServer == "" {
+			return "", fmt.Errorf(
+				"could not determine container registry endpoint of cache '%s', ensure %s is set as an output of your "+
+					"infrastructure",
+				value,
+				environment.ContainerRegistryEndpointEnvVarName,
+			)
+		}
+
+		value = fmt.Sprintf("%s/%s", loginServer, value)
+	}
+
+	return fmt.Sprintf("type=registry,ref=%s%s", value, registryAttributes), nil
+}
+
+// Resolves the values of the build secrets of the docker options, sorted by id
+func (p *dockerProject) resolveSecrets(
+	serviceConfig *ServiceConfig,
+	options DockerProjectOptions,
+) ([]docker.BuildSecret, error) {
+	ids := maps.Keys(options.Secrets)
+	slices.Sort(ids)
+
+	var secrets []docker.BuildSecret
+	for _, id := range ids {
+		source := options.Secrets[id]
+		switch {
+		case source.Env != "" && source.File != "":
+			return nil, fmt.Errorf("secret '%s' must set either 'env' or 'file', not both", id)
+		case source.Env != "":
+			value := p.env.Getenv(source.Env)
+			if value == "" {
+				return nil, fmt.Errorf("secret '%s': environment value '%s' is not set", id, source.Env)
+			}
+
+			secrets = append(secrets, docker.BuildSecret{Id: id, Value: value})
+		case source.File != "":
+			file := source.File
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(serviceConfig.Path(), file)
+			}
+
+			// The build runs in the directory of the service, so the path of the file is passed as an absolute path
+			file, err := filepath.Abs(file)
+			if err != nil {
+				return nil, fmt.Errorf("secret '%s': %w", id, err)
+			}
+
+			if _, err := os.Stat(file); err != nil {
+				return nil, fmt.Errorf("secret '%s': %w", id, err)
+			}
+
+			secrets = append(secrets, docker.BuildSecret{Id: id, File: file})
+		default:
+			return nil, fmt.Errorf("secret '%s' must set 'env' or 'file'", id)
+		}
+	}
+
+	return secrets, nil
+}
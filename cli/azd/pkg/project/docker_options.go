@@ -0,0 +1,188 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+)
+
+// DockerBuildOutputEnvVarName is the environment variable enabling streaming of the docker build output. When set to a
+// truthy value, ex) true or 1, the output of docker build is reported as the progress of the build as the build runs,
+// instead of running a quiet build that only reports the outcome once the build completes.
+const DockerBuildOutputEnvVarName = "AZD_DOCKER_BUILD_OUTPUT"
+
+// The platform images are built for when the service doesn't configure a platform
+const DefaultDockerPlatform = "amd64"
+
+type DockerProjectOptions struct {
+	Path    string `json:"path"`
+	Context string `json:"context"`
+	// The platform of the image, ex) linux/arm64. Comma separated platforms build a multi-arch image with docker buildx,
+	// ex) linux/amd64,linux/arm64. Defaults to amd64, the platform supported by all hosts.
+	Platform string `json:"platform"`
+	Target   string `json:"target"`
+	// The tag of the image, ex) api:{semver-from-file}-{git-short-sha}. Supports the tokens {git-sha}, {git-short-sha},
+	// {env}, {timestamp} and {semver-from-file}, expanded after the environment variables.
+	Tag ExpandableString `json:"tag"`
+	// The build arguments passed to docker build, ex) VERSION: ${SERVICE_API_IMAGE_TAG}
+	BuildArgs map[string]ExpandableString `json:"buildArgs" yaml:"buildArgs"`
+	// When enabled, the image is built remotely with ACR Tasks in the container registry of the environment instead of
+	// the local docker daemon. Images are also built remotely when docker is unavailable.
+	RemoteBuild bool `json:"remoteBuild" yaml:"remoteBuild"`
+	// The secrets mounted into the build with BuildKit by id, ex) RUN --mount=type=secret,id=npm_token. Secrets are
+	// available to the build steps mounting them, without being stored in the layers of the image.
+	Secrets map[string]DockerSecretOptions `json:"secrets" yaml:"secrets"`
+	// The registry cache layers are imported from, ex) cache/api:buildcache. Repositories without a registry are in the
+	// registry the image is pushed to. BuildKit cache specs, ex) type=gha, are used as-is.
+	CacheFrom ExpandableString `json:"cacheFrom" yaml:"cacheFrom"`
+	// The registry cache layers are exported to, in the same format as CacheFrom. All layers of the build are exported,
+	// including the layers of intermediate stages.
+	CacheTo ExpandableString `json:"cacheTo" yaml:"cacheTo"`
+	// A pre-built image deployed instead of building the service, ex) mcr.microsoft.com/azuredocs/aci-helloworld:latest.
+	// The image is copied to the container registry of the environment, or referenced from its registry when it is
+	// already in the container registry of the environment or the environment has no container registry. The service is
+	// built when the image expands to an empty string, ex) ${API_IMAGE} when API_IMAGE isn't set.
+	Image ExpandableString `json:"image" yaml:"image"`
+	// The retries of pushes of the image failing with transient errors of the container registry
+	PushRetry DockerPushRetryOptions `json:"pushRetry" yaml:"pushRetry"`
+	// The software bill of materials generated for the image when the service is packaged
+	Sbom DockerSbomOptions `json:"sbom" yaml:"sbom"`
+	// The vulnerability scan of the image run when the service is packaged, before the image is pushed
+	Scan DockerScanOptions `json:"scan" yaml:"scan"`
+	// The signing of the image with a key stored in Azure Key Vault, once the image is pushed
+	Sign DockerSignOptions `json:"sign" yaml:"sign"`
+	// The registry the image is pushed to instead of the container registry of the environment, ex) ghcr.io/contoso
+	Registry DockerRegistryOptions `json:"registry" yaml:"registry"`
+	// Additional flags passed as-is to docker build, after the flags of the other options, ex) --network=host or
+	// --ssh default. Each option is split into arguments like a shell does.
+	BuildOptions []string `json:"buildOptions" yaml:"buildOptions"`
+	// When set, the image is built without the layer cache, ex) to debug stale layers. Set by the --no-cache flag of
+	// azd deploy, for the selected services.
+	NoCache bool `json:"-" yaml:"-"`
+	// The way the image is built, dockerfile or buildpacks. Defaults to dockerfile.
+	Builder DockerBuilderKind `json:"builder" yaml:"builder"`
+	// The options of the images built with buildpacks
+	Buildpacks DockerBuildpacksOptions `json:"buildpacks" yaml:"buildpacks"`
+	// The lint of the Dockerfile run before the image is built
+	Lint DockerLintOptions `json:"lint" yaml:"lint"`
+	// When enabled, the image is tagged with the hash of its source when it's pushed to the container registry of the
+	// environment, and the build and the push are skipped when the registry has an image for the hash of the source,
+	// ex) on CI runs deploying unchanged services. The hash covers the files of the build context not excluded by its
+	// .dockerignore file, the Dockerfile and the options of the build.
+	ReuseUnchanged bool `json:"reuseUnchanged" yaml:"reuseUnchanged"`
+}
+
+// DockerSecretOptions is the source of the value of a docker build secret. Exactly one of the sources is set.
+type DockerSecretOptions struct {
+	// The name of the environment value with the value of the secret, ex) NPM_TOKEN. Values of the azd environment
+	// are used before the environment variables of the process.
+	Env string `json:"env" yaml:"env"`
+	// The path of a file with the value of the secret, relative to the service, ex) ./nuget.config
+	File string `json:"file" yaml:"file"`
+}
+
+// Gets the platforms the image is built for
+func (o DockerProjectOptions) Platforms() []string {
+	platforms := []string{}
+	for _, platform := range strings.Split(o.Platform, ",") {
+		if platform = strings.TrimSpace(platform); platform != "" {
+			platforms = append(platforms, platform)
+		}
+	}
+
+	return platforms
+}
+
+// Validates the platforms the image of the service is built for, ex) linux/arm64, against the host of the service.
+// Container Apps run linux/amd64 images, and linux/arm64 images when the service enables ARM workload profiles.
+// Multi-platform images including linux/amd64 run on all container apps.
+func validateDockerPlatform(serviceConfig *ServiceConfig) error {
+	if !serviceConfig.Host.RequiresContainer() {
+		return nil
+	}
+
+	architectures := map[string]string{}
+	for _, platform := range serviceConfig.Docker.Platforms() {
+		platformOs, arch, err := parseDockerPlatform(platform)
+		if err != nil {
+			return err
+		}
+
+		if serviceConfig.Host != ContainerAppTarget {
+			continue
+		}
+
+		if platformOs != "linux" || (arch != "amd64" && arch != "arm64") {
+			return fmt.Errorf(
+				"docker platform '%s' isn't supported by Container Apps, expected linux/amd64 or linux/arm64", platform)
+		}
+
+		architectures[arch] = platform
+	}
+
+	if platform, has := architectures["arm64"]; has && architectures["amd64"] == "" &&
+		!serviceConfig.ContainerApp.ArmProfiles {
+		return fmt.Errorf(
+			"docker platform '%s' requires ARM workload profiles, enable them with 'containerApp.armProfiles'", platform)
+	}
+
+	return nil
+}
+
+// Parses a docker platform, ex) linux/arm64/v8, to its operating system and architecture. Platforms without an
+// operating system, ex) amd64, are linux platforms.
+func parseDockerPlatform(platform string) (string, string, error) {
+	parts := strings.Split(strings.ToLower(platform), "/")
+	if len(parts) == 1 {
+		parts = []string{"linux", parts[0]}
+	}
+
+	if len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid docker platform '%s', expected os/arch[/variant], ex) linux/amd64", platform)
+	}
+
+	if parts[0] != "linux" && parts[0] != "windows" {
+		return "", "", fmt.Errorf("invalid docker platform '%s', the os must be linux or windows", platform)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// Checks whether the image of the service is built remotely with ACR Tasks, either because remote builds are enabled
+// for the service or because no container engine is installed. Images pushed to a registry configured for the service
+// are always built locally.
+func usesRemoteBuild(serviceConfig *ServiceConfig) bool {
+	if serviceConfig.Docker.Registry.Endpoint.template != "" || usesBuildpacks(serviceConfig) {
+		return false
+	}
+
+	return serviceConfig.Docker.RemoteBuild || !docker.EngineInstalled()
+}
+
+// Checks whether the output of docker builds is streamed, see [DockerBuildOutputEnvVarName]
+func streamBuildOutput() bool {
+	stream, err := strconv.ParseBool(os.Getenv(DockerBuildOutputEnvVarName))
+	return err == nil && stream
+}
+
+func getDockerOptionsWithDefaults(options DockerProjectOptions) DockerProjectOptions {
+	if options.Path == "" {
+		options.Path = "./Dockerfile"
+	}
+
+	if options.Platform == "" {
+		options.Platform = DefaultDockerPlatform
+	}
+
+	if options.Context == "" {
+		options.Context = "."
+	}
+
+	return options
+}
@@ -0,0 +1,377 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// The Dockerfile generated for services without a Dockerfile, in the service directory
+const generatedDockerfileName = "Dockerfile"
+
+// The files of python services run as the entrypoint of the generated Dockerfile, in order
+var pythonEntrypoints = []string{"app.py", "main.py", "server.py"}
+
+// DockerfilePath gets the path of the Dockerfile the image of the service is built from, or an empty string when the image
+// of the service isn't built from a Dockerfile, ex) services hosted without containers, images built with buildpacks
+// and pre-built images. The Dockerfile may not exist yet, azd init generates it from the language of the service.
+func (sc *ServiceConfig) DockerfilePath() string {
+	if !sc.Host.RequiresContainer() ||
+		sc.Language == ServiceLanguageCompose ||
+		usesBuildpacks(sc) ||
+		sc.Docker.Image != (ExpandableString{}) {
+		return ""
+	}
+
+	return filepath.Join(sc.Path(), getDockerOptionsWithDefaults(sc.Docker).Path)
+}
+
+// WriteDockerfile writes a Dockerfile for the container service when the service doesn't have one, from the language of
+// the service. Services with a configured Dockerfile aren't generated a Dockerfile, neither are services of languages
+// without a template. Called when the project is initialized, so the Dockerfile can be reviewed before the service is
+// deployed, the sources of the service are never written when it's built.
+// Returns the path of the generated Dockerfile, or an empty string when the Dockerfile isn't generated.
+func WriteDockerfile(serviceConfig *ServiceConfig) (string, error) {
+	if serviceConfig.Docker.Path != "" || serviceConfig.DockerfilePath() == "" {
+		return "", nil
+	}
+
+	dockerfilePath := filepath.Join(serviceConfig.Path(), generatedDockerfileName)
+	if _, err := os.Stat(dockerfilePath); err == nil {
+		return "", nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	// The service may not be scaffolded yet
+	if info, err := os.Stat(serviceConfig.Path()); err != nil || !info.IsDir() {
+		return "", nil
+	}
+
+	contents, err := generateDockerfile(serviceConfig)
+	if err != nil || contents == "" {
+		return "", err
+	}
+
+	if err := os.WriteFile(dockerfilePath, []byte(contents), osutil.PermissionFile); err != nil {
+		return "", fmt.Errorf("writing Dockerfile: %w", err)
+	}
+
+	return dockerfilePath, nil
+}
+
+// Checks the Dockerfile of the service exists before the service is built. When the service has no Dockerfile and one can
+// be generated from the language of the service, the error explains how to generate it, other missing Dockerfiles are
+// reported by the build.
+func checkDockerfile(serviceConfig *ServiceConfig) error {
+	if serviceConfig.Docker.Path != "" {
+		return nil
+	}
+
+	dockerfilePath := filepath.Join(serviceConfig.Path(), generatedDockerfileName)
+	if _, err := os.Stat(dockerfilePath); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	// The build reports services that aren't scaffolded
+	if info, err := os.Stat(serviceConfig.Path()); err != nil || !info.IsDir() {
+		return nil
+	}
+
+	if contents, err := generateDockerfile(serviceConfig); err != nil || contents == "" {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"service %s has no Dockerfile at %s, run 'azd init' to generate one from the %s language of the service, "+
+			"or add one",
+		serviceConfig.Name,
+		dockerfilePath,
+		serviceConfig.Language,
+	)
+}
+
+// Generates the contents of the Dockerfile of the service from its language, or an empty string when there's no
+// template for the language of the service
+func generateDockerfile(serviceConfig *ServiceConfig) (string, error) {
+	port := serviceConfig.ContainerApp.Ingress.TargetPort
+	if port == 0 {
+		port = frameworkDefaultPorts[serviceConfig.Language]
+	}
+
+	var body string
+	var err error
+	switch serviceConfig.Language {
+	case ServiceLanguageJavaScript, ServiceLanguageTypeScript:
+		body = nodeDockerfile(port)
+	case ServiceLanguagePython:
+		body, err = pythonDockerfile(serviceConfig, port)
+	case ServiceLanguageDotNet, ServiceLanguageCsharp, ServiceLanguageFsharp:
+		body, err = dotNetDockerfile(serviceConfig, port)
+	case ServiceLanguageJava:
+		body, err = javaDockerfile(serviceConfig, port)
+	case ServiceLanguageGo:
+		body, err = goDockerfile(serviceConfig, port)
+	case ServiceLanguageRust:
+		body, err = rustDockerfile(serviceConfig, port)
+	case ServiceLanguagePhp:
+		body = phpDockerfile(serviceConfig, port)
+	case ServiceLanguageRuby:
+		body, err = rubyDockerfile(serviceConfig, port)
+	default:
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("generating Dockerfile for service %s: %w", serviceConfig.Name, err)
+	}
+
+	return fmt.Sprintf(
+		"%s for service %s, since the service has no Dockerfile. Edit it to customize the image.\n%s",
+		generatedFileHeader,
+		serviceConfig.Name,
+		body,
+	), nil
+}
+
+func nodeDockerfile(port int) string {
+	return fmt.Sprintf(`FROM node:18-alpine AS build
+WORKDIR /app
+COPY package*.json ./
+RUN npm ci
+COPY . .
+RUN npm run build --if-present && npm prune --omit=dev
+
+FROM node:18-alpine
+ENV NODE_ENV=production PORT=%[1]d
+WORKDIR /app
+COPY --from=build /app ./
+EXPOSE %[1]d
+CMD ["npm", "start"]
+`, port)
+}
+
+func pythonDockerfile(serviceConfig *ServiceConfig, port int) (string, error) {
+	image := "python:3.11-slim"
+	install := "COPY requirements.txt ./\nRUN pip install --no-cache-dir -r requirements.txt\nCOPY . ."
+	if condaFile := serviceConfig.CondaFile(); condaFile != "" {
+		// The packages of conda environments are installed into the base environment of micromamba, which is activated
+		// for the commands of the image
+		image = "mambaorg/micromamba:1.5-jammy"
+		install = fmt.Sprintf("COPY --chown=$MAMBA_USER:$MAMBA_USER %[1]s ./\n"+
+			"RUN micromamba install -y -n base -f %[1]s && micromamba clean --all --yes\n"+
+			"COPY --chown=$MAMBA_USER:$MAMBA_USER . .", filepath.ToSlash(condaFile))
+	} else if !fileExists(filepath.Join(serviceConfig.Path(), "requirements.txt")) {
+		if !fileExists(filepath.Join(serviceConfig.Path(), "pyproject.toml")) {
+			return "", errors.New("the service has no requirements.txt or pyproject.toml")
+		}
+
+		install = "COPY . .\nRUN pip install --no-cache-dir ."
+	}
+
+	entrypoint := ""
+	for _, name := range pythonEntrypoints {
+		if fileExists(filepath.Join(serviceConfig.Path(), name)) {
+			entrypoint = name
+			break
+		}
+	}
+
+	if entrypoint == "" {
+		return "", fmt.Errorf("the service has no entrypoint, add one of: %s", strings.Join(pythonEntrypoints, ", "))
+	}
+
+	return fmt.Sprintf(`FROM %[4]s
+ENV PYTHONDONTWRITEBYTECODE=1 PYTHONUNBUFFERED=1 PORT=%[1]d
+WORKDIR /app
+%[2]s
+EXPOSE %[1]d
+CMD ["python", "%[3]s"]
+`, port, install, entrypoint, image), nil
+}
+
+func dotNetDockerfile(serviceConfig *ServiceConfig, port int) (string, error) {
+	projectFiles, err := filepath.Glob(filepath.Join(serviceConfig.Path(), "*.?sproj"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(projectFiles) != 1 {
+		return "", fmt.Errorf("expected one project file in the service directory, found %d", len(projectFiles))
+	}
+
+	projectFile := filepath.Base(projectFiles[0])
+	assembly := strings.TrimSuffix(projectFile, filepath.Ext(projectFile))
+
+	return fmt.Sprintf(`FROM mcr.microsoft.com/dotnet/sdk:8.0 AS build
+WORKDIR /src
+COPY %[2]s ./
+RUN dotnet restore
+COPY . .
+RUN dotnet publish -c Release -o /app/publish --no-restore
+
+FROM mcr.microsoft.com/dotnet/aspnet:8.0
+ENV ASPNETCORE_HTTP_PORTS=%[1]d
+WORKDIR /app
+COPY --from=build /app/publish ./
+EXPOSE %[1]d
+ENTRYPOINT ["dotnet", "%[3]s.dll"]
+`, port, projectFile, assembly), nil
+}
+
+func javaDockerfile(serviceConfig *ServiceConfig, port int) (string, error) {
+	hasPom := fileExists(filepath.Join(serviceConfig.Path(), "pom.xml"))
+	hasGradleBuild := fileExists(filepath.Join(serviceConfig.Path(), "build.gradle")) ||
+		fileExists(filepath.Join(serviceConfig.Path(), "build.gradle.kts"))
+
+	var build string
+	switch buildTool := serviceConfig.JavaBuildTool(); {
+	case buildTool == JavaBuildToolMaven && hasPom:
+		build = `FROM maven:3-eclipse-temurin-17 AS build
+WORKDIR /src
+COPY pom.xml ./
+RUN mvn -q dependency:go-offline
+COPY . .
+RUN mvn -q package -DskipTests && cp "$(ls target/*.jar | grep -v original | head -n 1)" /app.jar`
+	case buildTool == JavaBuildToolGradle && hasGradleBuild:
+		build = `FROM gradle:8-jdk17 AS build
+WORKDIR /src
+COPY . .
+RUN gradle build -x test --no-daemon && cp "$(ls build/libs/*.jar | grep -v plain | head -n 1)" /app.jar`
+	default:
+		return "", errors.New("the service has no pom.xml or build.gradle")
+	}
+
+	return fmt.Sprintf(`%[2]s
+
+FROM eclipse-temurin:17-jre
+ENV SERVER_PORT=%[1]d
+WORKDIR /app
+COPY --from=build /app.jar ./app.jar
+EXPOSE %[1]d
+ENTRYPOINT ["java", "-jar", "app.jar"]
+`, port, build), nil
+}
+
+func goDockerfile(serviceConfig *ServiceConfig, port int) (string, error) {
+	if !fileExists(filepath.Join(serviceConfig.Path(), "go.mod")) {
+		return "", errors.New("the service has no go.mod")
+	}
+
+	pkg := serviceConfig.Go.Package
+	if pkg == "" {
+		pkg = "."
+	}
+
+	return fmt.Sprintf(`FROM golang:1.22-alpine AS build
+WORKDIR /src
+COPY go.mod go.sum* ./
+RUN go mod download
+COPY . .
+RUN CGO_ENABLED=0 go build -o /app %[2]s
+
+FROM gcr.io/distroless/static-debian12
+ENV PORT=%[1]d
+COPY --from=build /app /app
+EXPOSE %[1]d
+ENTRYPOINT ["/app"]
+`, port, pkg), nil
+}
+
+func rustDockerfile(serviceConfig *ServiceConfig, port int) (string, error) {
+	bin, err := rustBinName(serviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(`FROM rust:1-slim AS build
+WORKDIR /src
+COPY . .
+RUN cargo build --release --bin %[2]s && cp target/release/%[2]s /app
+
+FROM debian:bookworm-slim
+ENV PORT=%[1]d
+COPY --from=build /app /app
+EXPOSE %[1]d
+ENTRYPOINT ["/app"]
+`, port, bin), nil
+}
+
+func phpDockerfile(serviceConfig *ServiceConfig, port int) string {
+	// Frameworks like Laravel and Symfony serve the public directory of the project
+	documentRoot := "/var/www/html"
+	if fileExists(filepath.Join(serviceConfig.Path(), "public", "index.php")) {
+		documentRoot += "/public"
+	}
+
+	return fmt.Sprintf(`FROM composer:2 AS build
+WORKDIR /app
+COPY . .
+RUN composer install --no-interaction --prefer-dist --no-dev --optimize-autoloader
+
+FROM php:8.3-apache
+ENV PORT=%[1]d APACHE_DOCUMENT_ROOT=%[2]s
+RUN sed -ri -e 's/80/${PORT}/g' /etc/apache2/ports.conf /etc/apache2/sites-available/000-default.conf \
+    && sed -ri -e 's!/var/www/html!${APACHE_DOCUMENT_ROOT}!g' /etc/apache2/sites-available/*.conf \
+    && a2enmod rewrite
+COPY --from=build /app /var/www/html
+EXPOSE %[1]d
+`, port, documentRoot)
+}
+
+func rubyDockerfile(serviceConfig *ServiceConfig, port int) (string, error) {
+	if !fileExists(filepath.Join(serviceConfig.Path(), "Gemfile")) {
+		return "", errors.New("the service has no Gemfile")
+	}
+
+	var cmd string
+	switch {
+	case isRailsApp(serviceConfig):
+		cmd = `["bundle", "exec", "rails", "server", "-b", "0.0.0.0"]`
+	case fileExists(filepath.Join(serviceConfig.Path(), "config.ru")):
+		cmd = fmt.Sprintf(`["bundle", "exec", "rackup", "-o", "0.0.0.0", "-p", "%d"]`, port)
+	default:
+		return "", errors.New("the service has no config/application.rb or config.ru")
+	}
+
+	// Deployment installs require the lock file, the gems of the lock file are installed as-is
+	bundleEnv := "BUNDLE_PATH=/usr/local/bundle BUNDLE_WITHOUT=development:test"
+	if fileExists(filepath.Join(serviceConfig.Path(), "Gemfile.lock")) {
+		bundleEnv += " BUNDLE_DEPLOYMENT=1"
+	}
+
+	precompile := ""
+	if serviceConfig.PrecompilesAssets() {
+		precompile = "\nRUN SECRET_KEY_BASE_DUMMY=1 bundle exec rails assets:precompile"
+	}
+
+	return fmt.Sprintf(`FROM ruby:3.3-slim AS build
+RUN apt-get update -qq && apt-get install -y --no-install-recommends build-essential git libyaml-dev pkg-config \
+    && rm -rf /var/lib/apt/lists/*
+ENV RAILS_ENV=production %[2]s
+WORKDIR /app
+COPY Gemfile Gemfile.lock* ./
+RUN bundle install
+COPY . .%[3]s
+
+FROM ruby:3.3-slim
+ENV RAILS_ENV=production RAILS_LOG_TO_STDOUT=1 RAILS_SERVE_STATIC_FILES=1 PORT=%[1]d %[2]s
+WORKDIR /app
+COPY --from=build /usr/local/bundle /usr/local/bundle
+COPY --from=build /app ./
+EXPOSE %[1]d
+CMD %[4]s
+`, port, bundleEnv, precompile, cmd), nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
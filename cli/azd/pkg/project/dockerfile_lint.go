@@ -0,0 +1,165 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// The lowest level of the violations failing the build when the failure threshold of the lint isn't set
+	defaultLintFailureThreshold = "error"
+	// The failure threshold reporting violations without failing the build
+	lintFailureThresholdNone = "none"
+	// The maximum number of violations listed by a Dockerfile lint error
+	maxListedLintViolations = 20
+)
+
+// The levels of the violations of the Dockerfile lint, from the lowest
+var lintLevels = []string{"style", "info", "warning", "error"}
+
+// DockerLintOptions configures the lint of the Dockerfile of the service with hadolint. The Dockerfile is linted before
+// the image is built, so Dockerfiles violating rules at or above the failure threshold fail the build before it starts.
+type DockerLintOptions struct {
+	// When enabled, the Dockerfile is linted before the image is built
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// The lowest level of the violations failing the build, error, warning, info, style or none to only report the
+	// violations. Defaults to error.
+	FailureThreshold string `json:"failureThreshold" yaml:"failureThreshold"`
+	// The ids of the rules that aren't checked, ex) DL3008
+	Ignore []string `json:"ignore" yaml:"ignore"`
+}
+
+// DockerfileLintError is returned when the Dockerfile of a service violates rules at or above the failure threshold of
+// the lint
+type DockerfileLintError struct {
+	ServiceName string
+	Dockerfile  string
+	Threshold   string
+	Violations  []string
+}
+
+func (e *DockerfileLintError) Error() string {
+	lines := []string{
+		fmt.Sprintf(
+			"found %d violations with level %s or higher in the Dockerfile of service '%s' (%s):",
+			len(e.Violations),
+			e.Threshold,
+			e.ServiceName,
+			e.Dockerfile,
+		),
+	}
+
+	for i, violation := range e.Violations {
+		if i == maxListedLintViolations {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(e.Violations)-maxListedLintViolations))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s", violation))
+	}
+
+	lines = append(lines,
+		"Fix the Dockerfile, raise 'docker.lint.failureThreshold' or ignore the rules with 'docker.lint.ignore' in "+
+			"azure.yaml",
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+// Gets the failure threshold of the lint, validating the threshold is supported
+func (o DockerLintOptions) failureThreshold() (string, error) {
+	if o.FailureThreshold == "" {
+		return defaultLintFailureThreshold, nil
+	}
+
+	threshold := strings.ToLower(o.FailureThreshold)
+	if threshold != lintFailureThresholdNone && !slices.Contains(lintLevels, threshold) {
+		return "", fmt.Errorf(
+			"unsupported lint failure threshold '%s', supported thresholds: %s, %s",
+			o.FailureThreshold,
+			strings.Join(lintLevels, ", "),
+			lintFailureThresholdNone,
+		)
+	}
+
+	return threshold, nil
+}
+
+// Validates the lint of the Dockerfile of the service
+func validateDockerLint(serviceConfig *ServiceConfig) error {
+	options := serviceConfig.Docker.Lint
+	if !options.Enabled {
+		return nil
+	}
+
+	if usesBuildpacks(serviceConfig) {
+		return fmt.Errorf("'docker.lint' requires a Dockerfile, images built with buildpacks don't have one")
+	}
+
+	_, err := options.failureThreshold()
+	return err
+}
+
+// Lints the Dockerfile of the service with hadolint when enabled, reporting each violation as a progress message.
+// Returns a [DockerfileLintError] when the Dockerfile violates rules at or above the failure threshold.
+func (p *dockerProject) lintDockerfile(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	dockerfilePath string,
+) error {
+	options := serviceConfig.Docker.Lint
+	if !options.Enabled {
+		return nil
+	}
+
+	threshold, err := options.failureThreshold()
+	if err != nil {
+		return err
+	}
+
+	if err := tools.EnsureInstalled(ctx, p.hadolint); err != nil {
+		return err
+	}
+
+	task.SetProgress(NewServiceProgress("Linting Dockerfile"))
+	log.Printf("linting %s of service %s with hadolint", dockerfilePath, serviceConfig.Name)
+
+	violations, err := p.hadolint.Lint(ctx, serviceConfig.Path(), dockerfilePath, options.Ignore)
+	if err != nil {
+		return err
+	}
+
+	failing := []string{}
+	for _, violation := range violations {
+		description := fmt.Sprintf(
+			"%s:%d %s %s: %s", dockerfilePath, violation.Line, violation.Level, violation.Code, violation.Message)
+		task.SetProgress(NewServiceProgress(fmt.Sprintf("Dockerfile lint: %s", description)))
+
+		// Unknown levels never fail the lint
+		level := slices.Index(lintLevels, strings.ToLower(violation.Level))
+		if threshold != lintFailureThresholdNone && level >= 0 && level >= slices.Index(lintLevels, threshold) {
+			failing = append(failing, description)
+		}
+	}
+
+	if len(failing) > 0 {
+		return &DockerfileLintError{
+			ServiceName: serviceConfig.Name,
+			Dockerfile:  dockerfilePath,
+			Threshold:   threshold,
+			Violations:  failing,
+		}
+	}
+
+	return nil
+}
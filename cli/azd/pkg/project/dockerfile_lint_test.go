@@ -0,0 +1,97 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DockerProject_LintDockerfile(t *testing.T) {
+	p := &dockerProject{
+		hadolint: &fakeHadolintCli{
+			violations: []hadolint.Violation{
+				{Code: "DL3008", Level: "warning", Message: "Pin versions in apt get install", Line: 3},
+				{Code: "DL3020", Level: "error", Message: "Use COPY instead of ADD for files and folders", Line: 5},
+				{Code: "DL3059", Level: "info", Message: "Multiple consecutive RUN instructions", Line: 7},
+			},
+		},
+	}
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+
+	lint := func() (*ServiceBuildResult, error) {
+		task := async.RunTaskWithProgress(
+			func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+				if err := p.lintDockerfile(context.Background(), task, serviceConfig, "./Dockerfile"); err != nil {
+					task.SetError(err)
+					return
+				}
+
+				task.SetResult(&ServiceBuildResult{})
+			},
+		)
+
+		logProgress(task)
+
+		return task.Await()
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		serviceConfig.Docker.Lint = DockerLintOptions{}
+
+		_, err := lint()
+		require.NoError(t, err)
+	})
+
+	t.Run("Fails", func(t *testing.T) {
+		serviceConfig.Docker.Lint = DockerLintOptions{Enabled: true, FailureThreshold: "warning"}
+
+		_, err := lint()
+		lintErr := &DockerfileLintError{}
+		require.True(t, errors.As(err, &lintErr))
+		require.Equal(t, "warning", lintErr.Threshold)
+		require.Len(t, lintErr.Violations, 2)
+		require.Contains(t, err.Error(), "./Dockerfile:5 error DL3020: Use COPY instead of ADD")
+	})
+
+	t.Run("DefaultThreshold", func(t *testing.T) {
+		serviceConfig.Docker.Lint = DockerLintOptions{Enabled: true}
+
+		_, err := lint()
+		lintErr := &DockerfileLintError{}
+		require.True(t, errors.As(err, &lintErr))
+		require.Equal(t, []string{"./Dockerfile:5 error DL3020: Use COPY instead of ADD for files and folders"},
+			lintErr.Violations)
+	})
+
+	t.Run("ReportOnly", func(t *testing.T) {
+		serviceConfig.Docker.Lint = DockerLintOptions{Enabled: true, FailureThreshold: "none"}
+
+		_, err := lint()
+		require.NoError(t, err)
+	})
+
+	t.Run("UnsupportedThreshold", func(t *testing.T) {
+		serviceConfig.Docker.Lint = DockerLintOptions{Enabled: true, FailureThreshold: "fatal"}
+
+		_, err := lint()
+		require.ErrorContains(t, err, "unsupported lint failure threshold 'fatal'")
+	})
+}
+
+type fakeHadolintCli struct {
+	fakeTool
+	violations []hadolint.Violation
+}
+
+func (cli *fakeHadolintCli) Lint(
+	ctx context.Context,
+	cwd string,
+	dockerfilePath string,
+	ignore []string,
+) ([]hadolint.Violation, error) {
+	return cli.violations, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteDockerfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		language ServiceLanguageKind
+		files    []string
+		contains []string
+	}{
+		{"Node", ServiceLanguageTypeScript, []string{"package.json"}, []string{"FROM node:18-alpine", "EXPOSE 3000"}},
+		{"Python", ServiceLanguagePython, []string{"requirements.txt", "main.py"},
+			[]string{"pip install --no-cache-dir -r requirements.txt", `CMD ["python", "main.py"]`, "EXPOSE 8000"}},
+		{"Conda", ServiceLanguagePython, []string{"environment.yml", "requirements.txt", "main.py"},
+			[]string{"FROM mambaorg/micromamba", "micromamba install -y -n base -f environment.yml", `CMD ["python", "main.py"]`}},
+		{"DotNet", ServiceLanguageDotNet, []string{"Api.csproj"},
+			[]string{"COPY Api.csproj ./", `ENTRYPOINT ["dotnet", "Api.dll"]`, "ASPNETCORE_HTTP_PORTS=8080"}},
+		{"Maven", ServiceLanguageJava, []string{"pom.xml"}, []string{"FROM maven:3-eclipse-temurin-17", "EXPOSE 8080"}},
+		{"Gradle", ServiceLanguageJava, []string{"build.gradle"}, []string{"FROM gradle:8-jdk17", "build/libs"}},
+		{"Rack", ServiceLanguageRuby, []string{"Gemfile", "Gemfile.lock", "config.ru"},
+			[]string{"FROM ruby:3.3-slim", "BUNDLE_DEPLOYMENT=1", `"rackup", "-o", "0.0.0.0", "-p", "3000"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, tt.language)
+			for _, file := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), file), []byte{}, 0600))
+			}
+
+			dockerfilePath, err := WriteDockerfile(serviceConfig)
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(serviceConfig.Path(), "Dockerfile"), dockerfilePath)
+
+			contents, err := os.ReadFile(dockerfilePath)
+			require.NoError(t, err)
+			require.Contains(t, string(contents), "# Generated by azd for service api")
+			for _, expected := range tt.contains {
+				require.Contains(t, string(contents), expected)
+			}
+		})
+	}
+
+	t.Run("TargetPort", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+		serviceConfig.ContainerApp.Ingress.TargetPort = 4000
+
+		contents, err := generateDockerfile(serviceConfig)
+		require.NoError(t, err)
+		require.Contains(t, contents, "PORT=4000")
+		require.Contains(t, contents, "EXPOSE 4000")
+	})
+
+	t.Run("ExistingDockerfile", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+		dockerfilePath := filepath.Join(serviceConfig.Path(), "Dockerfile")
+		require.NoError(t, os.WriteFile(dockerfilePath, []byte("FROM scratch\n"), 0600))
+
+		written, err := WriteDockerfile(serviceConfig)
+		require.NoError(t, err)
+		require.Empty(t, written)
+
+		contents, err := os.ReadFile(dockerfilePath)
+		require.NoError(t, err)
+		require.Equal(t, "FROM scratch\n", string(contents))
+	})
+
+	t.Run("ConfiguredDockerfile", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+		serviceConfig.Docker.Path = "./docker/Dockerfile.prod"
+
+		written, err := WriteDockerfile(serviceConfig)
+		require.NoError(t, err)
+		require.Empty(t, written)
+	})
+
+	t.Run("UnsupportedLanguage", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageDocker)
+
+		written, err := WriteDockerfile(serviceConfig)
+		require.NoError(t, err)
+		require.Empty(t, written)
+	})
+
+	t.Run("PythonWithoutEntrypoint", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+		require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "requirements.txt"), []byte{}, 0600))
+
+		_, err := WriteDockerfile(serviceConfig)
+		require.ErrorContains(t, err, "the service has no entrypoint")
+	})
+}
+
+func Test_CheckDockerfile(t *testing.T) {
+	t.Run("Missing", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+
+		err := checkDockerfile(serviceConfig)
+		require.ErrorContains(t, err, "run 'azd init' to generate one")
+
+		_, err = os.Stat(filepath.Join(serviceConfig.Path(), "Dockerfile"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Existing", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+		require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "Dockerfile"), []byte("FROM scratch\n"), 0600))
+
+		require.NoError(t, checkDockerfile(serviceConfig))
+	})
+
+	t.Run("UnsupportedLanguage", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageDocker)
+
+		require.NoError(t, checkDockerfile(serviceConfig))
+	})
+}
@@ -6,6 +6,7 @@ package project
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
@@ -21,7 +22,16 @@ const (
 	ServiceLanguageTypeScript ServiceLanguageKind = "ts"
 	ServiceLanguagePython     ServiceLanguageKind = "python"
 	ServiceLanguageJava       ServiceLanguageKind = "java"
+	ServiceLanguageGo         ServiceLanguageKind = "go"
+	ServiceLanguageRust       ServiceLanguageKind = "rust"
+	ServiceLanguagePhp        ServiceLanguageKind = "php"
+	ServiceLanguageRuby       ServiceLanguageKind = "ruby"
+	ServiceLanguageCustom     ServiceLanguageKind = "custom"
 	ServiceLanguageDocker     ServiceLanguageKind = "docker"
+	ServiceLanguageWorkflow   ServiceLanguageKind = "workflow"
+	ServiceLanguageGradle     ServiceLanguageKind = "gradle"
+	ServiceLanguageConda      ServiceLanguageKind = "conda"
+	ServiceLanguageCompose    ServiceLanguageKind = "compose"
 )
 
 func parseServiceLanguage(kind ServiceLanguageKind) (ServiceLanguageKind, error) {
@@ -37,17 +47,58 @@ func parseServiceLanguage(kind ServiceLanguageKind) (ServiceLanguageKind, error)
 		ServiceLanguageJavaScript,
 		ServiceLanguageTypeScript,
 		ServiceLanguagePython,
-		ServiceLanguageJava:
-		// Excluding ServiceLanguageDocker since it is implicitly derived currently, and not an actual language
+		ServiceLanguageJava,
+		ServiceLanguageGo,
+		ServiceLanguageRust,
+		ServiceLanguagePhp,
+		ServiceLanguageRuby,
+		ServiceLanguageCustom,
+		ServiceLanguageCompose:
+		// Excluding ServiceLanguageDocker, ServiceLanguageWorkflow, ServiceLanguageGradle & ServiceLanguageConda since
+		// they are implicitly derived currently, and not actual languages
 		return kind, nil
 	}
 
-	return ServiceLanguageKind(""), fmt.Errorf("unsupported language '%s'", kind)
+	// Languages azd doesn't support may be handled by framework plugins
+	if _, has := FindFrameworkPlugin(kind); has {
+		return kind, nil
+	}
+
+	return ServiceLanguageKind(""), fmt.Errorf(
+		"unsupported language '%s', and no framework plugin %s%s was found", kind, FrameworkPluginPrefix, kind)
+}
+
+// Checks whether the language is supported by azd, including the languages derived from the service, rather than by a
+// framework plugin
+func isBuiltinServiceLanguage(kind ServiceLanguageKind) bool {
+	switch kind {
+	case "",
+		ServiceLanguageDotNet,
+		ServiceLanguageCsharp,
+		ServiceLanguageFsharp,
+		ServiceLanguageJavaScript,
+		ServiceLanguageTypeScript,
+		ServiceLanguagePython,
+		ServiceLanguageJava,
+		ServiceLanguageGo,
+		ServiceLanguageRust,
+		ServiceLanguagePhp,
+		ServiceLanguageRuby,
+		ServiceLanguageCustom,
+		ServiceLanguageCompose,
+		ServiceLanguageDocker,
+		ServiceLanguageWorkflow,
+		ServiceLanguageGradle,
+		ServiceLanguageConda:
+		return true
+	}
+
+	return false
 }
 
 // FrameworkService is an abstraction for a programming language or framework
 // that describe the required tools as well as implementations for
-// restore and build commands
+// restore, build and test commands
 type FrameworkService interface {
 	// Gets a list of the required external tools for the framework service
 	RequiredExternalTools(ctx context.Context) []tools.ExternalTool
@@ -69,6 +120,12 @@ type FrameworkService interface {
 		restoreOutput *ServiceRestoreResult,
 	) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress]
 
+	// Runs the tests of the source of the framework service, ex) npm test or dotnet test
+	Test(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+	) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress]
+
 	// Packages the source suitable for publishing
 	// This may optionally perform a rebuild internally depending on the language/framework requirements
 	Package(
@@ -86,3 +143,24 @@ type CompositeFrameworkService interface {
 	FrameworkService
 	SetSource(inner FrameworkService)
 }
+
+// TestOptions are the options of the tests of a service, run by azd test
+type TestOptions struct {
+	// When true, the tests of the service aren't run, ex) for services without tests
+	Skip bool `yaml:"skip"`
+	// When true, the tests of the service are run before the service is deployed, and failing tests fail the deployment
+	BeforeDeploy bool `yaml:"beforeDeploy"`
+}
+
+// Creates a task completing with a skipped test result, for services without tests
+func skipTests(
+	serviceConfig *ServiceConfig,
+	reason string,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			log.Printf("skipping tests of service %s, %s", serviceConfig.Name, reason)
+			task.SetResult(&ServiceTestResult{Skipped: true})
+		},
+	)
+}
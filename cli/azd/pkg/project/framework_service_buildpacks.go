@@ -0,0 +1,171 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// DockerBuilderKind is the way the image of a containerized service is built
+type DockerBuilderKind string
+
+const (
+	// The image is built with docker from the Dockerfile of the service
+	DockerBuilderDockerfile DockerBuilderKind = "dockerfile"
+	// The image is built from the source code of the service with Cloud Native Buildpacks, without a Dockerfile
+	DockerBuilderBuildpacks DockerBuilderKind = "buildpacks"
+)
+
+// The builder used for the languages without a default builder
+const DefaultBuildpacksBuilder = "paketobuildpacks/builder-jammy-base"
+
+// The builders used by default for each language, when the service doesn't configure a builder. The Paketo base builder
+// supports the languages of azd, except .NET Framework applications.
+var defaultBuildpacksBuilders = map[ServiceLanguageKind]string{
+	ServiceLanguageDotNet:     "paketobuildpacks/builder-jammy-base",
+	ServiceLanguageCsharp:     "paketobuildpacks/builder-jammy-base",
+	ServiceLanguageFsharp:     "paketobuildpacks/builder-jammy-base",
+	ServiceLanguageJavaScript: "paketobuildpacks/builder-jammy-base",
+	ServiceLanguageTypeScript: "paketobuildpacks/builder-jammy-base",
+	ServiceLanguagePython:     "paketobuildpacks/builder-jammy-base",
+	ServiceLanguageJava:       "paketobuildpacks/builder-jammy-base",
+	ServiceLanguageGo:         "paketobuildpacks/builder-jammy-base",
+}
+
+// The tag of the images built with buildpacks in the local image store. The tag doesn't change between builds, so the
+// caches of the previous builds of the image are reused.
+const buildpacksImageTag = "azd-buildpacks"
+
+// DockerBuildpacksOptions are the options of the images built with Cloud Native Buildpacks
+type DockerBuildpacksOptions struct {
+	// The builder image, ex) paketobuildpacks/builder-jammy-full. Defaults to the builder of the language of the service.
+	Builder string `json:"builder" yaml:"builder"`
+	// The buildpacks used instead of the buildpacks detected by the builder, ex) paketo-buildpacks/nodejs
+	Buildpacks []string `json:"buildpacks" yaml:"buildpacks"`
+	// The environment variables of the build, ex) BP_NODE_VERSION: "20"
+	Env map[string]ExpandableString `json:"env" yaml:"env"`
+}
+
+// Gets whether the image of the service is built with buildpacks
+func usesBuildpacks(serviceConfig *ServiceConfig) bool {
+	return serviceConfig.Docker.Builder == DockerBuilderBuildpacks
+}
+
+// Gets the builder image of the service, configured for the service or the default builder of its language
+func buildpacksBuilder(serviceConfig *ServiceConfig) string {
+	if builder := serviceConfig.Docker.Buildpacks.Builder; builder != "" {
+		return builder
+	}
+
+	if builder, has := defaultBuildpacksBuilders[serviceConfig.Language]; has {
+		return builder
+	}
+
+	return DefaultBuildpacksBuilder
+}
+
+// Validates the builder of the image of the service. Images built with buildpacks don't support the options specific
+// to docker builds.
+func validateDockerBuilder(serviceConfig *ServiceConfig) error {
+	options := serviceConfig.Docker
+	switch options.Builder {
+	case "", DockerBuilderDockerfile:
+		return nil
+	case DockerBuilderBuildpacks:
+	default:
+		return fmt.Errorf(
+			"invalid docker builder '%s', expected %s or %s", options.Builder, DockerBuilderDockerfile, DockerBuilderBuildpacks)
+	}
+
+	unsupported := []string{}
+	if options.RemoteBuild {
+		unsupported = append(unsupported, "remoteBuild")
+	}
+
+	if len(options.Platforms()) > 1 {
+		unsupported = append(unsupported, "multi-platform images")
+	}
+
+	if len(options.Secrets) > 0 {
+		unsupported = append(unsupported, "secrets")
+	}
+
+	if len(options.BuildOptions) > 0 {
+		unsupported = append(unsupported, "buildOptions")
+	}
+
+	if options.CacheFrom.template != "" || options.CacheTo.template != "" {
+		unsupported = append(unsupported, "cacheFrom and cacheTo")
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("images built with buildpacks don't support %s", strings.Join(unsupported, ", "))
+	}
+
+	return nil
+}
+
+// Builds the image of the service from its source code with buildpacks, into the local image store of the docker daemon
+func (p *dockerProject) buildWithBuildpacks(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) {
+	dockerOptions := getDockerOptionsWithDefaults(serviceConfig.Docker)
+
+	env := []string{}
+	keys := maps.Keys(dockerOptions.Buildpacks.Env)
+	slices.Sort(keys)
+	for _, key := range keys {
+		value, err := dockerOptions.Buildpacks.Env[key].Envsubst(p.env.Getenv)
+		if err != nil {
+			task.SetError(fmt.Errorf("evaluating buildpacks env '%s': %w", key, err))
+			return
+		}
+
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	image := fmt.Sprintf("%s:%s", defaultImageRepository(serviceConfig, p.env.GetEnvName()), buildpacksImageTag)
+	builder := buildpacksBuilder(serviceConfig)
+	log.Printf("building image %s for service %s with builder %s", image, serviceConfig.Name, builder)
+	task.SetProgress(NewServiceProgress("Building image with buildpacks"))
+
+	var buildProgress io.Writer
+	if streamBuildOutput() {
+		buildProgress = exec.NewPrefixLineWriter(serviceConfig.Name, func(line string) {
+			log.Printf("pack build %s", line)
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Building image with buildpacks: %s", line)))
+		})
+	}
+
+	err := p.pack.Build(ctx, serviceConfig.Path(), pack.BuildOptions{
+		Image:      image,
+		Builder:    builder,
+		Path:       dockerOptions.Context,
+		Buildpacks: dockerOptions.Buildpacks.Buildpacks,
+		Env:        env,
+		ClearCache: dockerOptions.NoCache,
+	}, buildProgress)
+	if err != nil {
+		task.SetError(fmt.Errorf("building container: %s at %s: %w", serviceConfig.Name, dockerOptions.Context, err))
+		return
+	}
+
+	task.SetResult(&ServiceBuildResult{
+		Restore:         restoreOutput,
+		BuildOutputPath: image,
+	})
+}
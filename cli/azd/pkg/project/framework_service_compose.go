@@ -0,0 +1,127 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/benbjohnson/clock"
+)
+
+// composeProject is the composite framework service of the services of compose files. The services of language compose
+// are replaced by a service for each of their compose services when the project is loaded, with the docker options of
+// the build of the compose service. The images of the compose services are built by their Dockerfile, so their source
+// isn't restored unless a source framework service is set.
+type composeProject struct {
+	docker CompositeFrameworkService
+	source FrameworkService
+}
+
+// Creates a new instance of the framework service of compose services
+func NewComposeProject(
+	env *environment.Environment,
+	docker docker.Docker,
+	gitCli git.GitCli,
+	syftCli syft.SyftCli,
+	trivyCli trivy.TrivyCli,
+	grypeCli grype.GrypeCli,
+	packCli pack.PackCli,
+	hadolintCli hadolint.HadolintCli,
+	containerRegistryService azcli.ContainerRegistryService,
+	console input.Console,
+	clock clock.Clock,
+) CompositeFrameworkService {
+	return &composeProject{
+		docker: NewDockerProject(
+			env,
+			docker,
+			gitCli,
+			syftCli,
+			trivyCli,
+			grypeCli,
+			packCli,
+			hadolintCli,
+			containerRegistryService,
+			console,
+			clock,
+		),
+	}
+}
+
+// Gets the required external tools for the project
+func (p *composeProject) RequiredExternalTools(ctx context.Context) []tools.ExternalTool {
+	return p.docker.RequiredExternalTools(ctx)
+}
+
+// Initializes the source framework service of the compose service, if any
+func (p *composeProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	if p.source == nil {
+		return nil
+	}
+
+	return p.source.Initialize(ctx, serviceConfig)
+}
+
+// Sets the framework service restoring the source of the compose services, ex) to run the services locally
+func (p *composeProject) SetSource(inner FrameworkService) {
+	p.source = inner
+}
+
+// Restores the source of the compose service with the source framework service, if any
+func (p *composeProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	if p.source != nil {
+		return p.source.Restore(ctx, serviceConfig)
+	}
+
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the image of the compose service from the build of the compose service
+func (p *composeProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return p.docker.Build(ctx, serviceConfig, restoreOutput)
+}
+
+// Tests the source of the compose service with the source framework service, if any
+func (p *composeProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	if p.source != nil {
+		return p.source.Test(ctx, serviceConfig)
+	}
+
+	return skipTests(serviceConfig, "the compose service has no source")
+}
+
+// Packages the image of the compose service, tagging the image for the container registry of the environment
+func (p *composeProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return p.docker.Package(ctx, serviceConfig, buildOutput)
+}
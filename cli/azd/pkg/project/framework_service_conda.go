@@ -0,0 +1,303 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/conda"
+)
+
+// CondaExportFormat is the format the conda environment of a service is exported to in the package of the service
+type CondaExportFormat string
+
+const (
+	// Exports the packages of the environment to the conda environment file, ex) for Azure Machine Learning or conda
+	// based container images
+	CondaExportEnvironment CondaExportFormat = "environment"
+	// Exports the pip packages of the environment to requirements.txt, ex) for App Service and Azure Functions which
+	// install the requirements of Python apps with pip
+	CondaExportRequirements CondaExportFormat = "requirements"
+	// Doesn't export the environment, the conda environment file of the service is packaged as-is
+	CondaExportNone CondaExportFormat = "none"
+)
+
+// PythonOptions are the options of the services of language python
+type PythonOptions struct {
+	// The conda environment file of the service, relative to the service. Defaults to environment.yml or environment.yaml
+	// when the service has one. The dependencies of services with a conda environment file are restored with conda rather
+	// than pip.
+	CondaFile string `yaml:"condaFile"`
+	// The format the conda environment of the service is exported to in the package of the service. Defaults to
+	// requirements for services hosted on App Service or Azure Functions, and to environment for other services.
+	CondaExport CondaExportFormat `yaml:"condaExport"`
+}
+
+// The conda environment files detected in the Python services, in order
+var condaEnvironmentFiles = []string{"environment.yml", "environment.yaml"}
+
+// The directory of the conda environment of the Python services, in the service directory
+const condaEnvDir = ".conda"
+
+// Gets the conda environment file of the Python service relative to the service, or an empty string when the dependencies
+// of the service are restored with pip
+func (sc *ServiceConfig) CondaFile() string {
+	if sc.Language != ServiceLanguagePython {
+		return ""
+	}
+
+	if sc.Python.CondaFile != "" {
+		return sc.Python.CondaFile
+	}
+
+	for _, name := range condaEnvironmentFiles {
+		if fileExists(filepath.Join(sc.Path(), name)) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// Gets the format the conda environment of the service is exported to
+func (sc *ServiceConfig) CondaExport() CondaExportFormat {
+	if sc.Python.CondaExport != "" {
+		return sc.Python.CondaExport
+	}
+
+	if sc.Host == AppServiceTarget || sc.Host == AzureFunctionTarget {
+		return CondaExportRequirements
+	}
+
+	return CondaExportEnvironment
+}
+
+// Validates the python options of the service
+func validatePython(serviceConfig *ServiceConfig) error {
+	switch serviceConfig.Python.CondaExport {
+	case "", CondaExportEnvironment, CondaExportRequirements, CondaExportNone:
+	default:
+		return fmt.Errorf(
+			"unsupported conda export '%s', supported exports are 'environment', 'requirements' and 'none'",
+			serviceConfig.Python.CondaExport,
+		)
+	}
+
+	if serviceConfig.Language != ServiceLanguagePython && serviceConfig.Python != (PythonOptions{}) {
+		return errors.New("'python' is only supported for services of language 'python'")
+	}
+
+	return nil
+}
+
+type condaProject struct {
+	env      *environment.Environment
+	condaCli conda.CondaCli
+}
+
+// NewCondaProject creates a new instance of a conda project, used for the Python services with a conda environment file
+func NewCondaProject(env *environment.Environment, condaCli conda.CondaCli) FrameworkService {
+	return &condaProject{
+		env:      env,
+		condaCli: condaCli,
+	}
+}
+
+// Gets the required external tools for the project
+func (cp *condaProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{cp.condaCli}
+}
+
+// Initializes the conda project
+func (cp *condaProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the dependencies of the project into its conda environment, creating the environment when it doesn't exist
+func (cp *condaProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			condaFile := serviceConfig.CondaFile()
+			if !fileExists(filepath.Join(serviceConfig.Path(), condaFile)) {
+				task.SetError(fmt.Errorf("conda environment file '%s' of service %s not found", condaFile, serviceConfig.Name))
+				return
+			}
+
+			if isCondaEnv(filepath.Join(serviceConfig.Path(), condaEnvDir)) {
+				task.SetProgress(NewServiceProgress("Updating conda environment"))
+			} else {
+				task.SetProgress(NewServiceProgress("Creating conda environment"))
+			}
+
+			if err := cp.condaCli.UpdateEnv(ctx, serviceConfig.Path(), condaEnvDir, condaFile); err != nil {
+				task.SetError(fmt.Errorf("restoring conda environment of service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Build for conda projects performs a no-op and returns the service path with an optional output path when specified
+func (cp *condaProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: customOutputPath(serviceConfig),
+			})
+		},
+	)
+}
+
+// Tests the project with pytest in its conda environment, when the project has tests
+func (cp *condaProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			if !hasPythonTests(serviceConfig.Path()) {
+				log.Printf("skipping tests of service %s, the project has no tests", serviceConfig.Name)
+				task.SetResult(&ServiceTestResult{Skipped: true})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Running pytest"))
+			res, err := cp.condaCli.Run(ctx, serviceConfig.Path(), condaEnvDir, "python", "-m", "pytest")
+			if err != nil {
+				task.SetError(fmt.Errorf("failed to run the tests of project '%s': %w (%s)", serviceConfig.Path(), err, res.String()))
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Packages the project without its conda environment, exporting the environment for the host of the service
+func (cp *condaProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			publishSource := customOutputPath(serviceConfig)
+			if buildOutput != nil && buildOutput.BuildOutputPath != "" {
+				publishSource = buildOutput.BuildOutputPath
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForZip(
+				publishSource,
+				publishRoot,
+				buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{
+						excludeCondaEnv,
+						excludeVirtualEnv,
+						excludePyCache,
+					},
+					ignoreFile: packageIgnoreFile(serviceConfig),
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			if err := cp.export(ctx, serviceConfig, publishRoot, task.SetProgress); err != nil {
+				task.SetError(fmt.Errorf("exporting conda environment of service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Exports the conda environment of the service to the package of the service, in the export format of the service
+func (cp *condaProject) export(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	publishRoot string,
+	setProgress func(ServiceProgress),
+) error {
+	switch serviceConfig.CondaExport() {
+	case CondaExportEnvironment:
+		setProgress(NewServiceProgress("Exporting conda environment"))
+		exported, err := cp.condaCli.ExportEnv(ctx, serviceConfig.Path(), condaEnvDir)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(publishRoot, filepath.Base(serviceConfig.CondaFile()))
+		return os.WriteFile(path, []byte(condaEnvironmentFile(serviceConfig.Name, exported)), osutil.PermissionFile)
+	case CondaExportRequirements:
+		setProgress(NewServiceProgress("Exporting pip requirements of conda environment"))
+		res, err := cp.condaCli.Run(
+			ctx, serviceConfig.Path(), condaEnvDir, "python", "-m", "pip", "list", "--format=freeze", "--exclude-editable")
+		if err != nil {
+			return fmt.Errorf("listing pip packages: %s: %w", res.String(), err)
+		}
+
+		path := filepath.Join(publishRoot, "requirements.txt")
+		return os.WriteFile(path, []byte(res.Stdout), osutil.PermissionFile)
+	default:
+		return nil
+	}
+}
+
+// Gets the environment file of an export of a conda environment, named after the service and without the prefix of the
+// environment, which is the path of the environment on the machine it was exported from
+func condaEnvironmentFile(serviceName string, exported string) string {
+	lines := []string{}
+	for _, line := range strings.Split(exported, "\n") {
+		switch {
+		case strings.HasPrefix(line, "prefix:"):
+			continue
+		case strings.HasPrefix(line, "name:"):
+			line = "name: " + serviceName
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Checks whether the directory is a conda environment, which has a conda-meta directory
+func isCondaEnv(path string) bool {
+	info, err := os.Stat(filepath.Join(path, "conda-meta"))
+	return err == nil && info.IsDir()
+}
+
+func excludeCondaEnv(path string, file os.FileInfo) bool {
+	return file.IsDir() && isCondaEnv(path)
+}
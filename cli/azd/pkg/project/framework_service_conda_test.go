@@ -0,0 +1,130 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/conda"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const condaExport = `name: /home/user/src/model/.conda
+channels:
+  - conda-forge
+dependencies:
+  - python=3.11
+  - scikit-learn=1.3.2
+prefix: /home/user/src/model/.conda
+`
+
+// Creates a Python service with a conda environment file and a conda environment
+func createCondaTestService(t *testing.T, host ServiceTargetKind) *ServiceConfig {
+	serviceConfig := createTestServiceConfig(t.TempDir(), host, ServiceLanguagePython)
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "environment.yml"), []byte{}, osutil.PermissionFile))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "score.py"), []byte{}, osutil.PermissionFile))
+	require.NoError(t, os.MkdirAll(
+		filepath.Join(serviceConfig.Path(), condaEnvDir, "conda-meta"), osutil.PermissionDirectory))
+	return serviceConfig
+}
+
+func Test_CondaFile(t *testing.T) {
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguagePython)
+	require.Empty(t, serviceConfig.CondaFile())
+
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "environment.yaml"), []byte{}, osutil.PermissionFile))
+	require.Equal(t, "environment.yaml", serviceConfig.CondaFile())
+	require.Equal(t, CondaExportRequirements, serviceConfig.CondaExport())
+
+	serviceConfig.Python.CondaFile = "conda/prod.yml"
+	require.Equal(t, "conda/prod.yml", serviceConfig.CondaFile())
+
+	serviceConfig.Host = ContainerAppTarget
+	require.Equal(t, CondaExportEnvironment, serviceConfig.CondaExport())
+
+	_, err := Parse(context.Background(), "name: test-app\nservices:\n  api:\n    project: src/api\n    language: js\n"+
+		"    host: appservice\n    python:\n      condaExport: environment\n")
+	require.ErrorContains(t, err, "'python' is only supported for services of language 'python'")
+}
+
+func Test_CondaProject_Restore(t *testing.T) {
+	serviceConfig := createCondaTestService(t, ContainerAppTarget)
+
+	var updateArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "conda env update")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		updateArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	condaProject := NewCondaProject(environment.Ephemeral(), conda.NewCondaCli(mockContext.CommandRunner))
+	restoreTask := condaProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+
+	_, err := restoreTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, serviceConfig.Path(), updateArgs.Cwd)
+	require.Equal(t,
+		[]string{"env", "update", "--prefix", condaEnvDir, "--file", "environment.yml", "--prune"}, updateArgs.Args)
+}
+
+func Test_CondaProject_Package(t *testing.T) {
+	t.Run("Environment", func(t *testing.T) {
+		serviceConfig := createCondaTestService(t, ContainerAppTarget)
+
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "conda env export")
+		}).Respond(exec.NewRunResult(0, condaExport, ""))
+
+		packagePath := packageCondaProject(t, mockContext, serviceConfig)
+		require.NoDirExists(t, filepath.Join(packagePath, condaEnvDir))
+		require.FileExists(t, filepath.Join(packagePath, "score.py"))
+
+		exported, err := os.ReadFile(filepath.Join(packagePath, "environment.yml"))
+		require.NoError(t, err)
+		require.Contains(t, string(exported), "name: api\n")
+		require.Contains(t, string(exported), "scikit-learn=1.3.2")
+		require.NotContains(t, string(exported), "prefix:")
+	})
+
+	t.Run("Requirements", func(t *testing.T) {
+		serviceConfig := createCondaTestService(t, AppServiceTarget)
+
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "pip list --format=freeze")
+		}).Respond(exec.NewRunResult(0, "scikit-learn==1.3.2\n", ""))
+
+		packagePath := packageCondaProject(t, mockContext, serviceConfig)
+		requirements, err := os.ReadFile(filepath.Join(packagePath, "requirements.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "scikit-learn==1.3.2\n", string(requirements))
+	})
+}
+
+func packageCondaProject(t *testing.T, mockContext *mocks.MockContext, serviceConfig *ServiceConfig) string {
+	condaProject := NewCondaProject(environment.Ephemeral(), conda.NewCondaCli(mockContext.CommandRunner))
+	packageTask := condaProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{BuildOutputPath: serviceConfig.Path()},
+	)
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(result.PackagePath) })
+	return result.PackagePath
+}
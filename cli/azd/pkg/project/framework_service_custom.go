@@ -0,0 +1,216 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// CustomOptions are the commands of the services of language custom, which restore, build, test and package the service
+// with any toolchain, ex) mix, deno or zig. The commands run in a shell in the service directory, with the values of the
+// environment as environment variables.
+type CustomOptions struct {
+	// The command restoring the dependencies of the service, ex) mix deps.get
+	Restore string `yaml:"restore"`
+	// The command building the service, ex) mix release
+	Build string `yaml:"build"`
+	// The command run before the output directory of the service is packaged, ex) zig build -Doptimize=ReleaseSafe
+	Package string `yaml:"package"`
+	// The command running the tests of the service, ex) mix test
+	Test string `yaml:"test"`
+}
+
+// Validates the commands of services of language custom
+func validateCustom(serviceConfig *ServiceConfig) error {
+	options := serviceConfig.Custom
+	if serviceConfig.Language != ServiceLanguageCustom {
+		if options != (CustomOptions{}) {
+			return errors.New("'custom' is only supported for services of language 'custom'")
+		}
+
+		return nil
+	}
+
+	if options == (CustomOptions{}) {
+		return errors.New("services of language 'custom' must set at least one of 'custom.restore', " +
+			"'custom.build' or 'custom.package'")
+	}
+
+	return nil
+}
+
+type customProject struct {
+	env           *environment.Environment
+	commandRunner exec.CommandRunner
+}
+
+// NewCustomProject creates a new instance of the custom project, which runs the commands of the service
+func NewCustomProject(commandRunner exec.CommandRunner, env *environment.Environment) FrameworkService {
+	return &customProject{
+		env:           env,
+		commandRunner: commandRunner,
+	}
+}
+
+// Gets the required external tools for the project. The tools of the commands of the service aren't known.
+func (cp *customProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+// Initializes the custom project
+func (cp *customProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the project dependencies with the restore command of the service
+func (cp *customProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			if err := cp.run(ctx, serviceConfig, "restore", serviceConfig.Custom.Restore, task.SetProgress); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the project with the build command of the service. The build output is the output directory of the service.
+func (cp *customProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			if err := cp.run(ctx, serviceConfig, "build", serviceConfig.Custom.Build, task.SetProgress); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: customOutputPath(serviceConfig),
+			})
+		},
+	)
+}
+
+// Tests the project with the test command of the service
+func (cp *customProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	if serviceConfig.Custom.Test == "" {
+		return skipTests(serviceConfig, "the service has no test command")
+	}
+
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			if err := cp.run(ctx, serviceConfig, "test", serviceConfig.Custom.Test, task.SetProgress); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Runs the package command of the service, then copies the output directory of the service into the package
+func (cp *customProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			if err := cp.run(ctx, serviceConfig, "package", serviceConfig.Custom.Package, task.SetProgress); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			outputPath := customOutputPath(serviceConfig)
+			if _, err := os.Stat(outputPath); err != nil {
+				task.SetError(fmt.Errorf("output directory of service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForZip(
+				outputPath,
+				publishRoot,
+				buildForZipOptions{
+					ignoreFile: packageIgnoreFile(serviceConfig),
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Runs a command of the service in a shell in the service directory. Commands which aren't set are skipped.
+func (cp *customProject) run(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	stage string,
+	command string,
+	setProgress func(ServiceProgress),
+) error {
+	if command == "" {
+		return nil
+	}
+
+	envs, err := serviceConfig.Environ(cp.env)
+	if err != nil {
+		return err
+	}
+
+	setProgress(NewServiceProgress(fmt.Sprintf("Running %s command", stage)))
+	runArgs := exec.NewRunArgs("", command).
+		WithCwd(serviceConfig.Path()).
+		WithEnv(envs).
+		WithShell(true)
+
+	res, err := cp.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("running %s command of service %s: %s: %w", stage, serviceConfig.Name, res.String(), err)
+	}
+
+	return nil
+}
+
+// Gets the directory packaged for the service, the dist path of the service or the service directory
+func customOutputPath(serviceConfig *ServiceConfig) string {
+	if serviceConfig.OutputPath == "" {
+		return serviceConfig.Path()
+	}
+
+	return filepath.Join(serviceConfig.Path(), serviceConfig.OutputPath)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_Custom(t *testing.T) {
+	tests := map[string]struct {
+		service string
+		err     string
+	}{
+		"Valid": {
+			service: `
+    language: custom
+    custom:
+      build: mix release`,
+		},
+		"NoCommands": {
+			service: `
+    language: custom`,
+			err: "services of language 'custom' must set at least one of",
+		},
+		"OtherLanguage": {
+			service: `
+    language: js
+    custom:
+      build: npm run build`,
+			err: "'custom' is only supported for services of language 'custom'",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(context.Background(), `
+name: test-proj
+services:
+  api:
+    project: src/api
+    host: appservice`+test.service+"\n")
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func Test_CustomProject_Lifecycle(t *testing.T) {
+	servicePath := t.TempDir()
+
+	commands := []exec.RunArgs{}
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.UseShell
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, args)
+		if args.Args[0] == "mix release" {
+			// The release is the output of the build
+			releasePath := filepath.Join(servicePath, "_build", "prod", "rel", "api", "bin")
+			require.NoError(t, os.MkdirAll(releasePath, osutil.PermissionDirectory))
+			require.NoError(t, os.WriteFile(filepath.Join(releasePath, "api"), []byte(""), osutil.PermissionFile))
+		}
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig(servicePath, AppServiceTarget, ServiceLanguageCustom)
+	serviceConfig.Project.Path = ""
+	serviceConfig.OutputPath = filepath.Join("_build", "prod", "rel", "api")
+	serviceConfig.Custom = CustomOptions{Restore: "mix deps.get", Build: "mix release", Test: "mix test"}
+
+	env := environment.EphemeralWithValues("test", map[string]string{"MIX_ENV": "prod"})
+	customProject := NewCustomProject(mockContext.CommandRunner, env)
+
+	restoreTask := customProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	restoreResult, err := restoreTask.Await()
+	require.NoError(t, err)
+
+	testTask := customProject.Test(*mockContext.Context, serviceConfig)
+	logProgress(testTask)
+	_, err = testTask.Await()
+	require.NoError(t, err)
+
+	buildTask := customProject.Build(*mockContext.Context, serviceConfig, restoreResult)
+	logProgress(buildTask)
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(servicePath, "_build", "prod", "rel", "api"), buildResult.BuildOutputPath)
+
+	// The package command isn't set, only the output directory is packaged
+	packageTask := customProject.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(packageResult.PackagePath) })
+	require.FileExists(t, filepath.Join(packageResult.PackagePath, "bin", "api"))
+
+	require.Len(t, commands, 3)
+	require.Equal(t, []string{"mix deps.get"}, commands[0].Args)
+	require.Equal(t, []string{"mix test"}, commands[1].Args)
+	require.Equal(t, []string{"mix release"}, commands[2].Args)
+	for _, command := range commands {
+		require.Equal(t, servicePath, command.Cwd)
+		require.Contains(t, command.Env, "MIX_ENV=prod")
+	}
+}
+
+func Test_CustomProject_CommandFails(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.UseShell
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(1, "", "zig: command not found"), errors.New("exit code: 1")
+	})
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageCustom)
+	serviceConfig.Project.Path = ""
+	serviceConfig.Custom = CustomOptions{Package: "zig build"}
+
+	customProject := NewCustomProject(mockContext.CommandRunner, environment.Ephemeral())
+	packageTask := customProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{})
+	logProgress(packageTask)
+	_, err := packageTask.Await()
+	require.ErrorContains(t, err, "running package command of service api")
+	require.ErrorContains(t, err, "zig: command not found")
+}
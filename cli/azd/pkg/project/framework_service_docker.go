@@ -5,35 +5,101 @@ package project
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/output/ux"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
 	"github.com/benbjohnson/clock"
 )
 
-type DockerProjectOptions struct {
-	Path     string           `json:"path"`
-	Context  string           `json:"context"`
-	Platform string           `json:"platform"`
-	Tag      ExpandableString `json:"tag"`
-}
-
 type dockerPackageResult struct {
 	ImageTag    string
 	LoginServer string
+	// The registry configured for the service the image is pushed to, nil when the image is pushed to the container
+	// registry of the environment
+	Registry *DockerRegistryOptions
+	// The build of an image built and pushed to the registry in a single step, ex) a multi-arch image or an image built
+	// remotely. Nil for images built locally, which are pushed from the local image store.
+	DeferredBuild *dockerDeferredBuild
+	// The SBOM of the image, when enabled for the service
+	Sbom *dockerSbom
+	// The result of the vulnerability scan of the image, when enabled for the service
+	Scan *dockerScanResult
+	// The tag of the image for the hash of its source, ex) {registry}/{repository}:azd-source-{hash}, pushed along with
+	// the image when the service reuses unchanged images
+	SourceTag string
+	// The signature of the image, set once the image is pushed and signed
+	Signature *ImageSignature
+}
+
+// Gets the tags the image is pushed with, the tag of the image followed by the tag for the hash of its source, if any
+func (r *dockerPackageResult) Tags() []string {
+	if r.SourceTag == "" {
+		return []string{r.ImageTag}
+	}
+
+	return []string{r.ImageTag, r.SourceTag}
+}
+
+// The options of an image build deferred until the image is pushed to the registry
+type dockerDeferredBuild struct {
+	// When set, the image is built remotely with ACR Tasks. Otherwise, the image is built with docker buildx
+	Remote         bool
+	DockerfilePath string
+	Platforms      []string
+	Target         string
+	Context        string
+	BuildArgs      []string
+	Labels         []string
+	Secrets        []docker.BuildSecret
+	BuildOptions   []string
+	NoCache        bool
+	Cache          docker.BuildCache
+	// The hash of the source of the image, when the service reuses unchanged images
+	SourceHash string
+}
+
+// The image of a service built in the local image store
+type dockerLocalBuild struct {
+	// The hash of the source of the image, when the service reuses unchanged images
+	SourceHash string
+}
+
+// The pre-built image of a service, deployed instead of building the service
+type dockerPrebuiltImage struct {
+	Image string
 }
 
 type dockerProject struct {
-	env       *environment.Environment
-	docker    docker.Docker
-	framework FrameworkService
-	clock     clock.Clock
+	env                      *environment.Environment
+	docker                   docker.Docker
+	gitCli                   git.GitCli
+	syft                     syft.SyftCli
+	trivy                    trivy.TrivyCli
+	grype                    grype.GrypeCli
+	pack                     pack.PackCli
+	hadolint                 hadolint.HadolintCli
+	containerRegistryService azcli.ContainerRegistryService
+	console                  input.Console
+	framework                FrameworkService
+	clock                    clock.Clock
 }
 
 // NewDockerProject creates a new instance of a Azd project that
@@ -41,12 +107,28 @@ type dockerProject struct {
 func NewDockerProject(
 	env *environment.Environment,
 	docker docker.Docker,
+	gitCli git.GitCli,
+	syftCli syft.SyftCli,
+	trivyCli trivy.TrivyCli,
+	grypeCli grype.GrypeCli,
+	packCli pack.PackCli,
+	hadolintCli hadolint.HadolintCli,
+	containerRegistryService azcli.ContainerRegistryService,
+	console input.Console,
 	clock clock.Clock,
 ) CompositeFrameworkService {
 	return &dockerProject{
-		env:    env,
-		docker: docker,
-		clock:  clock,
+		env:                      env,
+		docker:                   docker,
+		gitCli:                   gitCli,
+		syft:                     syftCli,
+		trivy:                    trivyCli,
+		grype:                    grypeCli,
+		pack:                     packCli,
+		hadolint:                 hadolintCli,
+		containerRegistryService: containerRegistryService,
+		console:                  console,
+		clock:                    clock,
 	}
 }
 
@@ -70,6 +152,21 @@ func (p *dockerProject) Restore(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	image, err := p.prebuiltImage(serviceConfig)
+	if err != nil || image != "" {
+		return async.RunTaskWithProgress(
+			func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				log.Printf("skipping restore of %s, the service deploys the pre-built image %s", serviceConfig.Name, image)
+				task.SetResult(&ServiceRestoreResult{})
+			},
+		)
+	}
+
 	// When the program runs the restore actions for the underlying project (containerapp),
 	// the dependencies are installed locally
 	return p.framework.Restore(ctx, serviceConfig)
@@ -83,39 +180,306 @@ func (p *dockerProject) Build(
 ) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			image, err := p.prebuiltImage(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			if image != "" {
+				log.Printf("skipping build of %s, the service deploys the pre-built image %s", serviceConfig.Name, image)
+				task.SetResult(&ServiceBuildResult{
+					Restore:         restoreOutput,
+					BuildOutputPath: image,
+					Details:         &dockerPrebuiltImage{Image: image},
+				})
+				return
+			}
+
+			if usesBuildpacks(serviceConfig) {
+				p.buildWithBuildpacks(ctx, task, serviceConfig, restoreOutput)
+				return
+			}
+
+			if err := checkDockerfile(serviceConfig); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			p.validateBuildContext(ctx, serviceConfig)
+
 			dockerOptions := getDockerOptionsWithDefaults(serviceConfig.Docker)
 
+			if err := p.lintDockerfile(ctx, task, serviceConfig, dockerOptions.Path); err != nil {
+				task.SetError(err)
+				return
+			}
+
 			log.Printf(
-				"building image for service %s, cwd: %s, path: %s, context: %s)",
+				"building image for service %s, cwd: %s, path: %s, context: %s, target: %s)",
 				serviceConfig.Name,
 				serviceConfig.Path(),
 				dockerOptions.Path,
 				dockerOptions.Context,
+				dockerOptions.Target,
 			)
 
+			buildArgs, err := p.resolveBuildArgs(dockerOptions)
+			if err != nil {
+				task.SetError(fmt.Errorf("resolving build args: %w", err))
+				return
+			}
+
+			secrets, err := p.resolveSecrets(serviceConfig, dockerOptions)
+			if err != nil {
+				task.SetError(fmt.Errorf("resolving build secrets: %w", err))
+				return
+			}
+
+			buildOptions, err := resolveBuildOptions(dockerOptions)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			cache, err := p.resolveBuildCache(dockerOptions)
+			if err != nil {
+				task.SetError(fmt.Errorf("resolving build cache: %w", err))
+				return
+			}
+
+			registry, isExternal, err := resolveRegistryEndpoint(p.env, dockerOptions.Registry)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			labels := p.imageLabels(ctx, serviceConfig)
+
+			var sourceHash string
+			if dockerOptions.ReuseUnchanged && !isExternal && registry != "" {
+				sourceHash, err = imageSourceHash(serviceConfig, dockerOptions, buildArgs, secrets, buildOptions)
+				if err != nil {
+					task.SetError(fmt.Errorf("hashing source of image: %w", err))
+					return
+				}
+
+				task.SetProgress(NewServiceProgress("Checking registry for unchanged image"))
+				if image := p.unchangedImage(ctx, serviceConfig, registry, sourceHash); image != "" {
+					log.Printf("skipping build of %s, the image %s was built from the same source", serviceConfig.Name, image)
+					task.SetResult(&ServiceBuildResult{
+						Restore:         restoreOutput,
+						BuildOutputPath: image,
+						Details:         &dockerPrebuiltImage{Image: image},
+					})
+					return
+				}
+
+				labels = append(labels, fmt.Sprintf("%s=%s", ImageSourceHashLabel, sourceHash))
+			} else if dockerOptions.ReuseUnchanged {
+				log.Printf("not reusing unchanged images of %s, the image isn't pushed to the environment registry",
+					serviceConfig.Name)
+			}
+
+			platforms := dockerOptions.Platforms()
+			deferredBuild := &dockerDeferredBuild{
+				DockerfilePath: dockerOptions.Path,
+				Platforms:      platforms,
+				Target:         dockerOptions.Target,
+				Context:        dockerOptions.Context,
+				BuildArgs:      buildArgs,
+				Labels:         labels,
+				Secrets:        secrets,
+				BuildOptions:   buildOptions,
+				NoCache:        dockerOptions.NoCache,
+				Cache:          cache,
+				SourceHash:     sourceHash,
+			}
+
+			if dockerOptions.RemoteBuild {
+				if isExternal {
+					task.SetError(fmt.Errorf(
+						"service '%s' pushes its image to registry '%s', which doesn't support remote builds",
+						serviceConfig.Name,
+						registry,
+					))
+					return
+				}
+
+				if len(platforms) > 1 {
+					task.SetError(fmt.Errorf(
+						"service '%s' builds a multi-platform image, which can't be built remotely", serviceConfig.Name))
+					return
+				}
+
+				if len(secrets) > 0 {
+					task.SetError(fmt.Errorf(
+						"service '%s' uses build secrets, which aren't supported for remote builds", serviceConfig.Name))
+					return
+				}
+
+				if len(buildOptions) > 0 {
+					task.SetError(fmt.Errorf(
+						"service '%s' uses build options, which aren't supported for remote builds", serviceConfig.Name))
+					return
+				}
+
+				if len(cache.From) > 0 || len(cache.To) > 0 {
+					log.Printf("ignoring build cache of %s, the cache isn't used by remote builds", serviceConfig.Name)
+				}
+
+				log.Printf("images of %s built remotely aren't labeled", serviceConfig.Name)
+
+				log.Printf("deferring remote build of %s", serviceConfig.Name)
+				deferredBuild.Remote = true
+				task.SetResult(&ServiceBuildResult{Restore: restoreOutput, Details: deferredBuild})
+				return
+			}
+
+			if len(platforms) > 1 {
+				// Multi-arch images can't be loaded into the local image store, they're built with buildx when pushed
+				log.Printf("deferring multi-platform build of %s for %s", serviceConfig.Name, strings.Join(platforms, ", "))
+				task.SetResult(&ServiceBuildResult{Restore: restoreOutput, Details: deferredBuild})
+				return
+			}
+
+			if len(cache.From) > 0 || len(cache.To) > 0 {
+				// The registry cache requires logging into the registry, images are built with buildx when pushed
+				log.Printf("deferring build of %s using a registry cache", serviceConfig.Name)
+				task.SetResult(&ServiceBuildResult{Restore: restoreOutput, Details: deferredBuild})
+				return
+			}
+
 			// Build the container
 			task.SetProgress(NewServiceProgress("Building docker image"))
+
+			var buildProgress io.Writer
+			if streamBuildOutput() {
+				buildProgress = exec.NewPrefixLineWriter(serviceConfig.Name, func(line string) {
+					log.Printf("docker build %s", line)
+					task.SetProgress(NewServiceProgress(fmt.Sprintf("Building docker image: %s", line)))
+				})
+			}
+
 			imageId, err := p.docker.Build(
 				ctx,
 				serviceConfig.Path(),
-				dockerOptions.Path,
-				dockerOptions.Platform,
-				dockerOptions.Context,
+				docker.BuildOptions{
+					DockerfilePath: dockerOptions.Path,
+					Platforms:      platforms,
+					Target:         dockerOptions.Target,
+					Context:        dockerOptions.Context,
+					BuildArgs:      buildArgs,
+					Labels:         labels,
+					Secrets:        secrets,
+					ExtraArgs:      dockerBuildExtraArgs(buildOptions, dockerOptions.NoCache),
+				},
+				buildProgress,
 			)
+			if errors.Is(err, docker.ErrDaemonUnavailable) && len(secrets) == 0 && len(buildOptions) == 0 && !isExternal {
+				// Falls back to building the image in the container registry, ex) on CI agents without docker
+				log.Printf("building image for %s remotely, %v", serviceConfig.Name, err)
+				task.SetProgress(NewServiceProgress("Docker is unavailable, building image remotely"))
+				deferredBuild.Remote = true
+				task.SetResult(&ServiceBuildResult{Restore: restoreOutput, Details: deferredBuild})
+				return
+			}
+
 			if err != nil {
 				task.SetError(fmt.Errorf("building container: %s at %s: %w", serviceConfig.Name, dockerOptions.Context, err))
 				return
 			}
 
 			log.Printf("built image %s for %s", imageId, serviceConfig.Name)
-			task.SetResult(&ServiceBuildResult{
+			result := &ServiceBuildResult{
 				Restore:         restoreOutput,
 				BuildOutputPath: imageId,
-			})
+			}
+			if sourceHash != "" {
+				result.Details = &dockerLocalBuild{SourceHash: sourceHash}
+			}
+
+			task.SetResult(result)
 		},
 	)
 }
 
+// Computes the digest of the source of the image, its build context and Dockerfile, and of the options and labels of its
+// build. Services deploying pre-built images or built with buildpacks aren't built from a Dockerfile and have no digest.
+func (p *dockerProject) BuildDigest(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	image, err := p.prebuiltImage(serviceConfig)
+	if err != nil || image != "" || usesBuildpacks(serviceConfig) {
+		return "", err
+	}
+
+	dockerOptions := getDockerOptionsWithDefaults(serviceConfig.Docker)
+	buildArgs, err := p.resolveBuildArgs(dockerOptions)
+	if err != nil {
+		return "", fmt.Errorf("resolving build args: %w", err)
+	}
+
+	secrets, err := p.resolveSecrets(serviceConfig, dockerOptions)
+	if err != nil {
+		return "", fmt.Errorf("resolving build secrets: %w", err)
+	}
+
+	buildOptions, err := resolveBuildOptions(dockerOptions)
+	if err != nil {
+		return "", err
+	}
+
+	sourceHash, err := imageSourceHash(serviceConfig, dockerOptions, buildArgs, secrets, buildOptions)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "source\x00%s\n", sourceHash)
+	for _, label := range p.imageLabels(ctx, serviceConfig) {
+		fmt.Fprintf(hash, "label\x00%s\n", label)
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// Checks whether the image of the last build is still in the local image store
+func (p *dockerProject) BuildOutputExists(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) bool {
+	if buildOutput.BuildOutputPath == "" {
+		return false
+	}
+
+	_, err := p.docker.InspectConfig(ctx, serviceConfig.Path(), buildOutput.BuildOutputPath)
+	return err == nil
+}
+
+// Tests the source of the docker project with the underlying framework service. Services deploying a pre-built image
+// have no source to test.
+func (p *dockerProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	image, err := p.prebuiltImage(serviceConfig)
+	if err != nil || image != "" {
+		return async.RunTaskWithProgress(
+			func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				log.Printf("skipping tests of %s, the service deploys the pre-built image %s", serviceConfig.Name, image)
+				task.SetResult(&ServiceTestResult{Skipped: true})
+			},
+		)
+	}
+
+	return p.framework.Test(ctx, serviceConfig)
+}
+
 func (p *dockerProject) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -123,22 +487,63 @@ func (p *dockerProject) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
-			loginServer, has := p.env.Values[environment.ContainerRegistryEndpointEnvVarName]
-			if !has {
+			loginServer, isExternal, err := resolveRegistryEndpoint(p.env, serviceConfig.Docker.Registry)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			prebuilt, isPrebuilt := buildOutput.Details.(*dockerPrebuiltImage)
+			if isPrebuilt && (loginServer == "" || strings.HasPrefix(prebuilt.Image, loginServer+"/")) {
+				// The image doesn't need to be pushed, it is pulled from its registry by the deploy target
+				log.Printf("referencing pre-built image %s for %s", prebuilt.Image, serviceConfig.Name)
+				sbom, err := p.packageSbom(ctx, task, serviceConfig, "registry:"+prebuilt.Image)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				scan, err := p.packageScan(ctx, task, serviceConfig, prebuilt.Image, true)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				task.SetResult(&ServicePackageResult{
+					Build:       buildOutput,
+					PackagePath: prebuilt.Image,
+					Details: &dockerPackageResult{
+						ImageTag: prebuilt.Image,
+						Sbom:     sbom,
+						Scan:     scan,
+					},
+				})
+				return
+			}
+
+			if loginServer == "" {
 				task.SetError(fmt.Errorf(
-					"could not determine container registry endpoint, ensure %s is set as an output of your infrastructure",
+					"could not determine container registry endpoint, ensure %s is set as an output of your infrastructure "+
+						"or set 'docker.registry.endpoint' for service '%s'",
 					environment.ContainerRegistryEndpointEnvVarName,
+					serviceConfig.Name,
 				))
 				return
 			}
 
+			var registry *DockerRegistryOptions
+			if isExternal {
+				registry = &serviceConfig.Docker.Registry
+			}
+
+			deferredBuild, isDeferred := buildOutput.Details.(*dockerDeferredBuild)
 			imageId := buildOutput.BuildOutputPath
-			if imageId == "" {
+			if imageId == "" && !isDeferred {
 				task.SetError(errors.New("missing container image id from build output"))
 				return
 			}
 
-			imageTag, err := p.generateImageTag(serviceConfig)
+			imageTag, err := p.generateImageTag(ctx, serviceConfig)
 			if err != nil {
 				task.SetError(fmt.Errorf("generating image tag: %w", err))
 				return
@@ -150,56 +555,148 @@ func (p *dockerProject) Package(
 				imageTag,
 			)
 
-			// Tag image.
-			log.Printf("tagging image %s as %s", imageId, fullTag)
-			task.SetProgress(NewServiceProgress("Tagging docker image"))
-			if err := p.docker.Tag(ctx, serviceConfig.Path(), imageId, fullTag); err != nil {
-				task.SetError(fmt.Errorf("tagging image: %w", err))
-				return
+			var sourceTag string
+			if sourceHash := buildSourceHash(buildOutput); sourceHash != "" {
+				repository, _ := splitImageTag(imageTag, "")
+				sourceTag = fmt.Sprintf("%s/%s:%s%s", loginServer, repository, sourceTagPrefix, sourceHash)
+			}
+
+			if isPrebuilt {
+				log.Printf("pulling pre-built image %s for %s", prebuilt.Image, serviceConfig.Name)
+				task.SetProgress(NewServiceProgress("Pulling docker image"))
+				if err := p.docker.Pull(ctx, serviceConfig.Path(), prebuilt.Image); err != nil {
+					task.SetError(fmt.Errorf("pulling image: %w", err))
+					return
+				}
+			}
+
+			// Tag image. Deferred builds are tagged when they're built and pushed.
+			if !isDeferred {
+				log.Printf("tagging image %s as %s", imageId, fullTag)
+				task.SetProgress(NewServiceProgress("Tagging docker image"))
+				if err := p.docker.Tag(ctx, serviceConfig.Path(), imageId, fullTag); err != nil {
+					task.SetError(fmt.Errorf("tagging image: %w", err))
+					return
+				}
+
+				if sourceTag != "" {
+					if err := p.docker.Tag(ctx, serviceConfig.Path(), imageId, sourceTag); err != nil {
+						task.SetError(fmt.Errorf("tagging image: %w", err))
+						return
+					}
+				}
+			}
+
+			// Deferred builds aren't in the local image store until they're pushed, so their SBOM can't be generated
+			// before they're pushed. Their SBOM is generated from and they're scanned in the registry once they're
+			// pushed, see ImagePushCoordinator.
+			var sbom *dockerSbom
+			var scan *dockerScanResult
+			if isDeferred {
+				if serviceConfig.Docker.Sbom.Enabled {
+					if _, err := serviceConfig.Docker.Sbom.format(); err != nil {
+						task.SetError(err)
+						return
+					}
+				}
+			} else {
+				sbom, err = p.packageSbom(ctx, task, serviceConfig, "docker:"+fullTag)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				scan, err = p.packageScan(ctx, task, serviceConfig, fullTag, false)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
 			}
 
 			task.SetResult(&ServicePackageResult{
 				Build:       buildOutput,
 				PackagePath: fullTag,
 				Details: &dockerPackageResult{
-					ImageTag:    fullTag,
-					LoginServer: loginServer,
+					ImageTag:      fullTag,
+					LoginServer:   loginServer,
+					Registry:      registry,
+					DeferredBuild: deferredBuild,
+					Sbom:          sbom,
+					Scan:          scan,
+					SourceTag:     sourceTag,
 				},
 			})
 		},
 	)
 }
 
-func (p *dockerProject) generateImageTag(serviceConfig *ServiceConfig) (string, error) {
-	configuredTag, err := serviceConfig.Docker.Tag.Envsubst(p.env.Getenv)
+// Gets the pre-built image deployed for the service, or an empty string when the image is built from the service
+func (p *dockerProject) prebuiltImage(serviceConfig *ServiceConfig) (string, error) {
+	image, err := serviceConfig.Docker.Image.Envsubst(p.env.Getenv)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("evaluating image of service '%s': %w", serviceConfig.Name, err)
 	}
 
-	if configuredTag != "" {
-		return configuredTag, nil
+	return strings.TrimSpace(image), nil
+}
+
+// Generates the SBOM of the packaged image when enabled for the service, reporting the progress to the task
+func (p *dockerProject) packageSbom(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	source string,
+) (*dockerSbom, error) {
+	if !serviceConfig.Docker.Sbom.Enabled {
+		return nil, nil
 	}
 
-	return fmt.Sprintf("%s/%s-%s:azd-deploy-%d",
-		strings.ToLower(serviceConfig.Project.Name),
-		strings.ToLower(serviceConfig.Name),
-		strings.ToLower(p.env.GetEnvName()),
-		p.clock.Now().Unix(),
-	), nil
+	task.SetProgress(NewServiceProgress("Generating SBOM"))
+	sbom, err := generateSbom(ctx, p.syft, p.env, serviceConfig, source)
+	if err != nil {
+		return nil, fmt.Errorf("generating sbom: %w", err)
+	}
+
+	return sbom, nil
 }
 
-func getDockerOptionsWithDefaults(options DockerProjectOptions) DockerProjectOptions {
-	if options.Path == "" {
-		options.Path = "./Dockerfile"
+// Scans the image of the service for vulnerabilities, when enabled for the service
+func (p *dockerProject) packageScan(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	image string,
+	remote bool,
+) (*dockerScanResult, error) {
+	if !serviceConfig.Docker.Scan.Enabled {
+		return nil, nil
 	}
 
-	if options.Platform == "" {
-		options.Platform = "amd64"
+	task.SetProgress(NewServiceProgress("Scanning image for vulnerabilities"))
+	scan, err := scanImage(ctx, p.trivy, p.grype, serviceConfig, image, remote)
+	if err != nil {
+		var scanErr *VulnerabilityScanError
+		if errors.As(err, &scanErr) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("scanning image: %w", err)
 	}
 
-	if options.Context == "" {
-		options.Context = "."
+	return scan, nil
+}
+
+// Warns about build contexts including directories the image likely doesn't require or exceeding the size threshold.
+// The .dockerignore file of the build context is only generated by azd init, the sources of the service aren't changed
+// by builds. Failures don't fail the build.
+func (p *dockerProject) validateBuildContext(ctx context.Context, serviceConfig *ServiceConfig) {
+	warnings, err := validateBuildContext(serviceConfig)
+	if err != nil {
+		log.Printf("failed validating build context of service %s: %v", serviceConfig.Name, err)
+		return
 	}
 
-	return options
+	for _, warning := range warnings {
+		p.console.MessageUxItem(ctx, &ux.WarningMessage{Description: warning})
+	}
 }
@@ -5,7 +5,10 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -14,8 +17,15 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockarmresources"
 	"github.com/benbjohnson/clock"
@@ -42,6 +52,7 @@ services:
 	env.SetSubscriptionId("sub")
 
 	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
 
 	mockarmresources.AddAzResourceListMock(
 		mockContext.HttpClient,
@@ -64,6 +75,11 @@ services:
 			"build", "-q",
 			"-f", "./Dockerfile",
 			"--platform", "amd64",
+			"--label", "com.microsoft.azd.environment=test-env",
+			"--label", "com.microsoft.azd.project=test-proj",
+			"--label", "com.microsoft.azd.service=web",
+			"--label", "com.microsoft.azd.template=test-proj-template",
+			"--label", "org.opencontainers.image.revision=COMMIT",
 			".",
 		}, args.Args)
 
@@ -86,7 +102,19 @@ services:
 	internalFramework := NewNpmProject(npmCli, env)
 	progressMessages := []string{}
 
-	framework := NewDockerProject(env, docker, clock.NewMock())
+	framework := NewDockerProject(
+		env,
+		docker,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
 	framework.SetSource(internalFramework)
 
 	buildTask := framework.Build(*mockContext.Context, service, nil)
@@ -127,6 +155,7 @@ services:
 	env := environment.EphemeralWithValues("test-env", nil)
 	env.SetSubscriptionId("sub")
 	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
 
 	mockarmresources.AddAzResourceListMock(
 		mockContext.HttpClient,
@@ -151,6 +180,11 @@ services:
 			"build", "-q",
 			"-f", "./Dockerfile.dev",
 			"--platform", "amd64",
+			"--label", "com.microsoft.azd.environment=test-env",
+			"--label", "com.microsoft.azd.project=test-proj",
+			"--label", "com.microsoft.azd.service=web",
+			"--label", "com.microsoft.azd.template=test-proj-template",
+			"--label", "org.opencontainers.image.revision=COMMIT",
 			"../",
 		}, args.Args)
 
@@ -174,7 +208,19 @@ services:
 	internalFramework := NewNpmProject(npmCli, env)
 	status := ""
 
-	framework := NewDockerProject(env, docker, clock.NewMock())
+	framework := NewDockerProject(
+		env,
+		docker,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
 	framework.SetSource(internalFramework)
 
 	buildTask := framework.Build(*mockContext.Context, service, nil)
@@ -224,18 +270,33 @@ func Test_generateImageTag(t *testing.T) {
 				Tag: NewExpandableString("contoso/contoso-image:latest"),
 			},
 			"contoso/contoso-image:latest"},
+		{
+			"ImageTagTokens",
+			DockerProjectOptions{
+				Tag: NewExpandableString("contoso/web:{env}-{git-short-sha}-{timestamp}"),
+			},
+			fmt.Sprintf("contoso/web:%s-0123456-%d", envName, mockClock.Now().Unix())},
+		{
+			"ImageTagCommit",
+			DockerProjectOptions{
+				Tag: NewExpandableString("contoso/web:{git-sha}"),
+			},
+			"contoso/web:0123456789abcdef"},
 	}
 
+	mockGitCommit(mockContext, "0123456789abcdef")
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dockerProject := &dockerProject{
 				env:    environment.EphemeralWithValues(envName, map[string]string{}),
 				docker: docker.NewDocker(mockContext.CommandRunner),
+				gitCli: git.NewGitCli(mockContext.CommandRunner),
 				clock:  mockClock,
 			}
 			serviceConfig.Docker = tt.dockerConfig
 
-			tag, err := dockerProject.generateImageTag(serviceConfig)
+			tag, err := dockerProject.generateImageTag(*mockContext.Context, serviceConfig)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.want, tag)
 		})
@@ -246,6 +307,7 @@ func Test_DockerProject_Build(t *testing.T) {
 	var runArgs exec.RunArgs
 
 	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
 	mockContext.CommandRunner.
 		When(func(args exec.RunArgs, command string) bool {
 			return strings.Contains(command, "docker build")
@@ -259,7 +321,19 @@ func Test_DockerProject_Build(t *testing.T) {
 	dockerCli := docker.NewDocker(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 
-	dockerProject := NewDockerProject(env, dockerCli, clock.NewMock())
+	dockerProject := NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
 	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
 	logProgress(buildTask)
 
@@ -270,11 +344,771 @@ func Test_DockerProject_Build(t *testing.T) {
 	require.Equal(t, "docker", runArgs.Cmd)
 	require.Equal(t, serviceConfig.RelativePath, runArgs.Cwd)
 	require.Equal(t,
-		[]string{"build", "-q", "-f", "./Dockerfile", "--platform", "amd64", "."},
+		[]string{
+			"build", "-q", "-f", "./Dockerfile", "--platform", "amd64",
+			"--label", "com.microsoft.azd.project=test-app",
+			"--label", "com.microsoft.azd.service=api",
+			"--label", "org.opencontainers.image.revision=COMMIT",
+			".",
+		},
+		runArgs.Args,
+	)
+}
+
+func Test_DockerProject_BuildStreamsOutput(t *testing.T) {
+	t.Setenv(DockerBuildOutputEnvVarName, "true")
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			require.NotContains(t, args.Args, "-q")
+			require.NotNil(t, args.Stderr)
+
+			iidFile := args.Args[len(args.Args)-2]
+			require.NoError(t, os.WriteFile(iidFile, []byte("sha256:IMAGE_ID"), osutil.PermissionFile))
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	env := environment.Ephemeral()
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+
+	dockerProject := NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	result, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, "sha256:IMAGE_ID", result.BuildOutputPath)
+}
+
+func Test_DockerProject_BuildArgs(t *testing.T) {
+	var runArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+		})
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"SERVICE_API_IMAGE_TAG": "1.2.3",
+	})
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.BuildArgs = map[string]ExpandableString{
+		"VERSION":  NewExpandableString("${SERVICE_API_IMAGE_TAG}"),
+		"NODE_ENV": NewExpandableString("production"),
+	}
+
+	dockerProject := NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	result, err := buildTask.Await()
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	require.Equal(t,
+		[]string{
+			"build", "-q", "-f", "./Dockerfile", "--platform", "amd64",
+			"--build-arg", "NODE_ENV=production",
+			"--build-arg", "VERSION=1.2.3",
+			"--label", "com.microsoft.azd.environment=test",
+			"--label", "com.microsoft.azd.project=test-app",
+			"--label", "com.microsoft.azd.service=api",
+			"--label", "org.opencontainers.image.revision=COMMIT",
+			".",
+		},
 		runArgs.Args,
 	)
 }
 
+func Test_DockerProject_BuildOptions(t *testing.T) {
+	var runArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+		})
+
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.BuildOptions = []string{"--network=host", "--ssh default", `--add-host "api.local:10.0.0.1"`}
+
+	dockerProject := NewDockerProject(
+		environment.EphemeralWithValues("test", nil),
+		docker.NewDocker(mockContext.CommandRunner),
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+
+	t.Run("Local", func(t *testing.T) {
+		buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+		logProgress(buildTask)
+
+		_, err := buildTask.Await()
+		require.NoError(t, err)
+		require.Equal(t,
+			[]string{
+				"build", "-q", "-f", "./Dockerfile", "--platform", "amd64",
+				"--label", "com.microsoft.azd.environment=test",
+				"--label", "com.microsoft.azd.project=test-app",
+				"--label", "com.microsoft.azd.service=api",
+				"--label", "org.opencontainers.image.revision=COMMIT",
+				"--network=host",
+				"--ssh", "default",
+				"--add-host", "api.local:10.0.0.1",
+				".",
+			},
+			runArgs.Args,
+		)
+	})
+
+	t.Run("RemoteBuild", func(t *testing.T) {
+		remoteConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		remoteConfig.Docker.BuildOptions = serviceConfig.Docker.BuildOptions
+		remoteConfig.Docker.RemoteBuild = true
+
+		buildTask := dockerProject.Build(*mockContext.Context, remoteConfig, nil)
+		logProgress(buildTask)
+
+		_, err := buildTask.Await()
+		require.ErrorContains(t, err, "build options")
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		invalidConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		invalidConfig.Docker.BuildOptions = []string{`--label "unterminated`}
+
+		buildTask := dockerProject.Build(*mockContext.Context, invalidConfig, nil)
+		logProgress(buildTask)
+
+		_, err := buildTask.Await()
+		require.Error(t, err)
+	})
+}
+
+func Test_DockerProject_Buildpacks(t *testing.T) {
+	var runArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "pack build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Builder = DockerBuilderBuildpacks
+	serviceConfig.Docker.NoCache = true
+	serviceConfig.Docker.Buildpacks.Env = map[string]ExpandableString{
+		"BP_NODE_VERSION": NewExpandableString("${NODE_VERSION}"),
+	}
+
+	dockerProject := NewDockerProject(
+		environment.EphemeralWithValues("test", map[string]string{"NODE_VERSION": "20"}),
+		docker.NewDocker(mockContext.CommandRunner),
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+
+	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	result, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, "test-app/api-test:azd-buildpacks", result.BuildOutputPath)
+	require.Equal(t,
+		[]string{
+			"build", "test-app/api-test:azd-buildpacks",
+			"--builder", DefaultBuildpacksBuilder,
+			"--path", ".",
+			"--env", "BP_NODE_VERSION=20",
+			"--clear-cache",
+		},
+		runArgs.Args,
+	)
+}
+
+func Test_ValidateDockerBuilder(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   DockerProjectOptions
+		expectErr bool
+	}{
+		{name: "Default", options: DockerProjectOptions{}},
+		{name: "Buildpacks", options: DockerProjectOptions{Builder: DockerBuilderBuildpacks}},
+		{name: "Invalid", options: DockerProjectOptions{Builder: "kaniko"}, expectErr: true},
+		{
+			name:      "BuildpacksRemoteBuild",
+			options:   DockerProjectOptions{Builder: DockerBuilderBuildpacks, RemoteBuild: true},
+			expectErr: true,
+		},
+		{
+			name:      "BuildpacksMultiPlatform",
+			options:   DockerProjectOptions{Builder: DockerBuilderBuildpacks, Platform: "linux/amd64,linux/arm64"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+			serviceConfig.Docker = tt.options
+
+			err := validateDockerBuilder(serviceConfig)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_DockerProject_Secrets(t *testing.T) {
+	nugetConfig := filepath.Join(t.TempDir(), "nuget.config")
+	require.NoError(t, os.WriteFile(nugetConfig, []byte("<configuration />"), osutil.PermissionFile))
+
+	build := func(env *environment.Environment, secrets map[string]DockerSecretOptions) (exec.RunArgs, error) {
+		var runArgs exec.RunArgs
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitCommit(mockContext, "COMMIT")
+		mockContext.CommandRunner.
+			When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "docker build")
+			}).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				runArgs = args
+				return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+			})
+
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		serviceConfig.Docker.Secrets = secrets
+
+		dockerProject := NewDockerProject(
+			env,
+			docker.NewDocker(mockContext.CommandRunner),
+			git.NewGitCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			pack.NewPackCli(mockContext.CommandRunner),
+			hadolint.NewHadolintCli(mockContext.CommandRunner),
+			nil,
+			mockContext.Console,
+			clock.NewMock(),
+		)
+		buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+		logProgress(buildTask)
+
+		_, err := buildTask.Await()
+		return runArgs, err
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		env := environment.EphemeralWithValues("test", map[string]string{
+			"NPM_TOKEN": "TOKEN",
+		})
+
+		runArgs, err := build(env, map[string]DockerSecretOptions{
+			"npm_token": {Env: "NPM_TOKEN"},
+			"nuget":     {File: nugetConfig},
+		})
+		require.NoError(t, err)
+		require.Equal(t,
+			[]string{
+				"build", "-q", "-f", "./Dockerfile", "--platform", "amd64",
+				"--label", "com.microsoft.azd.environment=test",
+				"--label", "com.microsoft.azd.project=test-app",
+				"--label", "com.microsoft.azd.service=api",
+				"--label", "org.opencontainers.image.revision=COMMIT",
+				"--secret", "id=npm_token,env=AZD_BUILD_SECRET_NPM_TOKEN",
+				"--secret", fmt.Sprintf("id=nuget,src=%s", nugetConfig),
+				".",
+			},
+			runArgs.Args,
+		)
+		require.Contains(t, runArgs.Env, "AZD_BUILD_SECRET_NPM_TOKEN=TOKEN")
+	})
+
+	t.Run("MissingValue", func(t *testing.T) {
+		_, err := build(environment.Ephemeral(), map[string]DockerSecretOptions{
+			"npm_token": {Env: "AZD_TEST_MISSING_NPM_TOKEN"},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("MultipleSources", func(t *testing.T) {
+		_, err := build(environment.Ephemeral(), map[string]DockerSecretOptions{
+			"nuget": {Env: "NUGET_TOKEN", File: nugetConfig},
+		})
+		require.Error(t, err)
+	})
+}
+
+func Test_DockerProject_ImageLabels(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(128, "", "fatal: not a git repository (or any of the parent directories): .git"))
+
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Project.Metadata = &ProjectMetadata{Template: "todo-nodejs-mongo@0.0.1-beta"}
+
+	project := &dockerProject{
+		env:    environment.EphemeralWithValues("dev", nil),
+		gitCli: git.NewGitCli(mockContext.CommandRunner),
+	}
+
+	// The revision is omitted outside of git repositories
+	require.Equal(t, []string{
+		"com.microsoft.azd.environment=dev",
+		"com.microsoft.azd.project=test-app",
+		"com.microsoft.azd.service=api",
+		"com.microsoft.azd.template=todo-nodejs-mongo@0.0.1-beta",
+	}, project.imageLabels(*mockContext.Context, serviceConfig))
+
+	mockGitCommit(mockContext, "COMMIT")
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "branch --show-current")
+	}).Respond(exec.NewRunResult(0, "main\n", ""))
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "status --porcelain")
+	}).Respond(exec.NewRunResult(0, " M src/index.ts\n", ""))
+
+	require.Equal(t, []string{
+		"com.microsoft.azd.branch=main",
+		"com.microsoft.azd.dirty=true",
+		"com.microsoft.azd.environment=dev",
+		"com.microsoft.azd.project=test-app",
+		"com.microsoft.azd.service=api",
+		"com.microsoft.azd.template=todo-nodejs-mongo@0.0.1-beta",
+		"org.opencontainers.image.revision=COMMIT",
+	}, project.imageLabels(*mockContext.Context, serviceConfig))
+}
+
+func Test_DockerProject_MultiPlatform(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker build") || strings.Contains(command, "docker tag")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			require.Fail(t, "multi-platform images are built when pushed")
+			return exec.NewRunResult(1, "", ""), nil
+		})
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "ACR_ENDPOINT",
+	})
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Platform = "linux/amd64, linux/arm64"
+	serviceConfig.Docker.Target = "production"
+
+	dockerProject := NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, &dockerDeferredBuild{
+		DockerfilePath: "./Dockerfile",
+		Platforms:      []string{"linux/amd64", "linux/arm64"},
+		Target:         "production",
+		Context:        ".",
+		BuildArgs:      []string{},
+		Labels: []string{
+			"com.microsoft.azd.environment=test",
+			"com.microsoft.azd.project=test-app",
+			"com.microsoft.azd.service=api",
+			"org.opencontainers.image.revision=COMMIT",
+		},
+		BuildOptions: []string{},
+	}, buildResult.Details)
+
+	packageTask := dockerProject.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+
+	packageDetails, ok := packageResult.Details.(*dockerPackageResult)
+	require.True(t, ok)
+	require.Equal(t, "ACR_ENDPOINT/test-app/api-test:azd-deploy-0", packageDetails.ImageTag)
+	require.Equal(t, buildResult.Details, packageDetails.DeferredBuild)
+}
+
+func Test_DockerProject_BuildCache(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockGitCommit(mockContext, "COMMIT")
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker build")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			require.Fail(t, "images using a registry cache are built when pushed")
+			return exec.NewRunResult(1, "", ""), nil
+		})
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "myregistry.azurecr.io",
+	})
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.CacheFrom = NewExpandableString("cache/api:${AZURE_ENV_NAME}")
+	serviceConfig.Docker.CacheTo = NewExpandableString("type=local,dest=/tmp/cache")
+
+	dockerProject := NewDockerProject(
+		env,
+		docker.NewDocker(mockContext.CommandRunner),
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+	buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+
+	deferredBuild, ok := buildResult.Details.(*dockerDeferredBuild)
+	require.True(t, ok)
+	require.False(t, deferredBuild.Remote)
+	require.Equal(t, docker.BuildCache{
+		From: []string{"type=registry,ref=myregistry.azurecr.io/cache/api:test"},
+		To:   []string{"type=local,dest=/tmp/cache"},
+	}, deferredBuild.Cache)
+}
+
+func Test_ResolveCacheSpec(t *testing.T) {
+	env := environment.EphemeralWithValues("test", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "myregistry.azurecr.io",
+	})
+	project := &dockerProject{env: env}
+
+	tests := map[string]string{
+		"cache/api:buildcache":                 "type=registry,ref=myregistry.azurecr.io/cache/api:buildcache,mode=max",
+		"other.azurecr.io/api:buildcache":      "type=registry,ref=other.azurecr.io/api:buildcache,mode=max",
+		"localhost:5000/api":                   "type=registry,ref=localhost:5000/api,mode=max",
+		"type=registry,ref=other.io/api:cache": "type=registry,ref=other.io/api:cache",
+		"":                                     "",
+	}
+
+	for option, expected := range tests {
+		spec, err := project.resolveCacheSpec(NewExpandableString(option), DockerRegistryOptions{}, ",mode=max")
+		require.NoError(t, err)
+		require.Equal(t, expected, spec)
+	}
+
+	registry := DockerRegistryOptions{Endpoint: NewExpandableString("ghcr.io/contoso")}
+	spec, err := project.resolveCacheSpec(NewExpandableString("cache/api"), registry, "")
+	require.NoError(t, err)
+	require.Equal(t, "type=registry,ref=ghcr.io/contoso/cache/api", spec)
+
+	_, err = (&dockerProject{env: environment.Ephemeral()}).
+		resolveCacheSpec(NewExpandableString("cache/api"), DockerRegistryOptions{}, "")
+	require.Error(t, err)
+}
+
+func Test_ValidateDockerPlatform(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        ServiceTargetKind
+		platform    string
+		armProfiles bool
+		expectErr   bool
+	}{
+		{name: "Default", host: ContainerAppTarget, platform: ""},
+		{name: "Amd64", host: ContainerAppTarget, platform: "amd64"},
+		{name: "LinuxAmd64", host: ContainerAppTarget, platform: "linux/amd64"},
+		{name: "Arm64", host: ContainerAppTarget, platform: "linux/arm64", expectErr: true},
+		{name: "Arm64Profiles", host: ContainerAppTarget, platform: "linux/arm64/v8", armProfiles: true},
+		{name: "MultiPlatform", host: ContainerAppTarget, platform: "linux/amd64,linux/arm64"},
+		{name: "Windows", host: ContainerAppTarget, platform: "windows/amd64", expectErr: true},
+		{name: "AksArm64", host: AksTarget, platform: "linux/arm64"},
+		{name: "AksWindows", host: AksTarget, platform: "windows/amd64"},
+		{name: "Invalid", host: AksTarget, platform: "darwin/arm64", expectErr: true},
+		{name: "NotContainer", host: AppServiceTarget, platform: "linux/arm64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := createTestServiceConfig("./src/api", tt.host, ServiceLanguageDocker)
+			serviceConfig.Docker.Platform = tt.platform
+			serviceConfig.ContainerApp.ArmProfiles = tt.armProfiles
+
+			err := validateDockerPlatform(serviceConfig)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_DockerProject_RemoteBuild(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitCommit(mockContext, "COMMIT")
+		mockContext.CommandRunner.
+			When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "docker build")
+			}).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				require.Fail(t, "remote builds don't use the local docker daemon")
+				return exec.NewRunResult(1, "", ""), nil
+			})
+
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		serviceConfig.Docker.RemoteBuild = true
+
+		dockerProject := NewDockerProject(
+			environment.Ephemeral(),
+			docker.NewDocker(mockContext.CommandRunner),
+			git.NewGitCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			pack.NewPackCli(mockContext.CommandRunner),
+			hadolint.NewHadolintCli(mockContext.CommandRunner),
+			nil,
+			mockContext.Console,
+			clock.NewMock(),
+		)
+		buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+		logProgress(buildTask)
+
+		result, err := buildTask.Await()
+		require.NoError(t, err)
+
+		deferredBuild, ok := result.Details.(*dockerDeferredBuild)
+		require.True(t, ok)
+		require.True(t, deferredBuild.Remote)
+		require.Equal(t, []string{"amd64"}, deferredBuild.Platforms)
+	})
+
+	t.Run("DockerUnavailable", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitCommit(mockContext, "COMMIT")
+		mockContext.CommandRunner.
+			When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "docker build")
+			}).
+			RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				return exec.NewRunResult(1, "", "error during connect"), errors.New("exit code: 1")
+			})
+
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+
+		dockerProject := NewDockerProject(
+			environment.Ephemeral(),
+			docker.NewDocker(mockContext.CommandRunner),
+			git.NewGitCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			pack.NewPackCli(mockContext.CommandRunner),
+			hadolint.NewHadolintCli(mockContext.CommandRunner),
+			nil,
+			mockContext.Console,
+			clock.NewMock(),
+		)
+		buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+		logProgress(buildTask)
+
+		result, err := buildTask.Await()
+		require.NoError(t, err)
+
+		deferredBuild, ok := result.Details.(*dockerDeferredBuild)
+		require.True(t, ok)
+		require.True(t, deferredBuild.Remote)
+	})
+
+	t.Run("MultiPlatformNotSupported", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		mockGitCommit(mockContext, "COMMIT")
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		serviceConfig.Docker.RemoteBuild = true
+		serviceConfig.Docker.Platform = "linux/amd64,linux/arm64"
+
+		dockerProject := NewDockerProject(
+			environment.Ephemeral(),
+			docker.NewDocker(mockContext.CommandRunner),
+			git.NewGitCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			pack.NewPackCli(mockContext.CommandRunner),
+			hadolint.NewHadolintCli(mockContext.CommandRunner),
+			nil,
+			mockContext.Console,
+			clock.NewMock(),
+		)
+		buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+		logProgress(buildTask)
+
+		_, err := buildTask.Await()
+		require.ErrorContains(t, err, "can't be built remotely")
+	})
+}
+
+func Test_DockerProject_PrebuiltImage(t *testing.T) {
+	const image = "mcr.microsoft.com/azuredocs/aci-helloworld:latest"
+
+	deploy := func(
+		t *testing.T,
+		env *environment.Environment,
+		mockContext *mocks.MockContext,
+	) *ServicePackageResult {
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+		serviceConfig.Docker.Image = NewExpandableString("${API_IMAGE}")
+
+		dockerProject := NewDockerProject(
+			env,
+			docker.NewDocker(mockContext.CommandRunner),
+			git.NewGitCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			pack.NewPackCli(mockContext.CommandRunner),
+			hadolint.NewHadolintCli(mockContext.CommandRunner),
+			nil,
+			mockContext.Console,
+			clock.NewMock(),
+		)
+
+		// The image isn't built, the source of the service isn't restored or built
+		restoreTask := dockerProject.Restore(*mockContext.Context, serviceConfig)
+		logProgress(restoreTask)
+		restoreResult, err := restoreTask.Await()
+		require.NoError(t, err)
+
+		buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, restoreResult)
+		logProgress(buildTask)
+		buildResult, err := buildTask.Await()
+		require.NoError(t, err)
+		require.Equal(t, image, buildResult.BuildOutputPath)
+
+		packageTask := dockerProject.Package(*mockContext.Context, serviceConfig, buildResult)
+		logProgress(packageTask)
+		packageResult, err := packageTask.Await()
+		require.NoError(t, err)
+
+		return packageResult
+	}
+
+	t.Run("CopiedToRegistry", func(t *testing.T) {
+		commands := []string{}
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "docker"
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			commands = append(commands, strings.Join(args.Args, " "))
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		env := environment.EphemeralWithValues("test", map[string]string{
+			"API_IMAGE": image,
+			environment.ContainerRegistryEndpointEnvVarName: "ACR_ENDPOINT",
+		})
+
+		packageResult := deploy(t, env, mockContext)
+		require.Equal(t, "ACR_ENDPOINT/test-app/api-test:azd-deploy-0", packageResult.PackagePath)
+		require.Equal(t, []string{
+			"pull " + image,
+			"tag " + image + " ACR_ENDPOINT/test-app/api-test:azd-deploy-0",
+		}, commands)
+	})
+
+	t.Run("Referenced", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		env := environment.EphemeralWithValues("test", map[string]string{
+			"API_IMAGE": image,
+		})
+
+		packageResult := deploy(t, env, mockContext)
+		require.Equal(t, image, packageResult.PackagePath)
+		require.Equal(t, &dockerPackageResult{ImageTag: image}, packageResult.Details)
+	})
+}
+
 func Test_DockerProject_Package(t *testing.T) {
 	var runArgs exec.RunArgs
 
@@ -294,7 +1128,19 @@ func Test_DockerProject_Package(t *testing.T) {
 	dockerCli := docker.NewDocker(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 
-	dockerProject := NewDockerProject(env, dockerCli, clock.NewMock())
+	dockerProject := NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
 	packageTask := dockerProject.Package(
 		*mockContext.Context,
 		serviceConfig,
@@ -336,7 +1182,19 @@ func Test_Docker_Package_No_Container_Registry(t *testing.T) {
 	dockerCli := docker.NewDocker(mockContext.CommandRunner)
 	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
 
-	dockerProject := NewDockerProject(env, dockerCli, clock.NewMock())
+	dockerProject := NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
 
 	packageTask := dockerProject.Package(
 		*mockContext.Context,
@@ -352,3 +1210,60 @@ func Test_Docker_Package_No_Container_Registry(t *testing.T) {
 	require.ErrorContains(t, err, "could not determine container registry endpoint")
 	require.Nil(t, packageResult)
 }
+
+func Test_DockerProject_Package_Registry(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker tag")
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	// The registry of the service is used instead of the container registry of the environment
+	env := environment.EphemeralWithValues("test", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "ACR_ENDPOINT",
+		"REGISTRY_NAMESPACE":                            "contoso",
+	})
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Registry.Endpoint = NewExpandableString("ghcr.io/${REGISTRY_NAMESPACE}/")
+
+	dockerProject := NewDockerProject(
+		env,
+		docker.NewDocker(mockContext.CommandRunner),
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+
+	packageTask := dockerProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{BuildOutputPath: "IMAGE_ID"},
+	)
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+
+	packageResult := result.Details.(*dockerPackageResult)
+	require.Equal(t, "ghcr.io/contoso", packageResult.LoginServer)
+	require.Equal(t, "ghcr.io/contoso/test-app/api-test:azd-deploy-0", packageResult.ImageTag)
+	require.Same(t, &serviceConfig.Docker.Registry, packageResult.Registry)
+}
+
+// Mocks the current commit of the git repository of the services
+func mockGitCommit(mockContext *mocks.MockContext, commit string) {
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(0, commit+"\n", ""))
+	// Detached HEAD, with a clean working tree
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "branch --show-current") ||
+			strings.Contains(command, "status --porcelain") ||
+			strings.Contains(command, "ls-files --others")
+	}).Respond(exec.NewRunResult(0, "", ""))
+}
@@ -5,6 +5,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -22,6 +23,56 @@ const (
 	defaultDotNetBuildConfiguration string = "Release"
 )
 
+// DotNetOptions are the options of the deployment of .NET services published with dotnet publish, so that the published
+// application matches the requirements of the production environment
+type DotNetOptions struct {
+	// The runtime identifier the service is published for, ex) linux-x64 or win-x64. Required for self-contained
+	// deployments.
+	Runtime string `yaml:"runtime"`
+	// Whether the .NET runtime is published with the service, so the service doesn't depend on the runtime of the host.
+	// Defaults to the setting of the project, and to self-contained when trimmed or compiled ahead of time.
+	SelfContained *bool `yaml:"selfContained"`
+	// Whether the unused code of the service and its dependencies is trimmed, with the PublishTrimmed property
+	Trimmed bool `yaml:"trimmed"`
+	// Whether the service is compiled ahead of time to native code, with the PublishAot property
+	Aot bool `yaml:"aot"`
+}
+
+// Gets the options of dotnet publish, validating that trimmed and native services are self-contained for a runtime
+func (o DotNetOptions) publishOptions() (dotnet.PublishOptions, error) {
+	options := dotnet.PublishOptions{
+		Runtime:       o.Runtime,
+		SelfContained: o.SelfContained,
+		Trimmed:       o.Trimmed,
+		Aot:           o.Aot,
+	}
+
+	if !o.Trimmed && !o.Aot {
+		if o.SelfContained != nil && *o.SelfContained && o.Runtime == "" {
+			return dotnet.PublishOptions{}, errors.New("dotnet.runtime is required for self-contained deployments")
+		}
+
+		return options, nil
+	}
+
+	feature := "trimmed"
+	if o.Aot {
+		feature = "compiled ahead of time"
+	}
+
+	if o.SelfContained != nil && !*o.SelfContained {
+		return dotnet.PublishOptions{}, fmt.Errorf("services %s must be self-contained, set dotnet.selfContained to true", feature)
+	}
+
+	if o.Runtime == "" {
+		return dotnet.PublishOptions{}, fmt.Errorf("dotnet.runtime is required for services %s, ex) linux-x64", feature)
+	}
+
+	selfContained := true
+	options.SelfContained = &selfContained
+	return options, nil
+}
+
 type dotnetProject struct {
 	env       *environment.Environment
 	dotnetCli dotnet.DotNetCli
@@ -116,6 +167,48 @@ func (dp *dotnetProject) Build(
 	)
 }
 
+// Computes the digest of the sources of the project, without the bin and obj directories of its builds
+func (dp *dotnetProject) BuildDigest(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	return sourceDigest(serviceConfig, []string{"bin", "obj"}, defaultDotNetBuildConfiguration)
+}
+
+// Checks whether the output directory of the last build still exists
+func (dp *dotnetProject) BuildOutputExists(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) bool {
+	return buildOutputPathExists(buildOutput)
+}
+
+// Gets the version of the .NET SDK building the project. The path of the service may be the project file of the service.
+func (dp *dotnetProject) ToolchainVersion(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	projectDir := serviceConfig.Path()
+	if !dirExists(projectDir) {
+		projectDir = filepath.Dir(projectDir)
+	}
+
+	return dp.dotnetCli.Version(ctx, projectDir)
+}
+
+// Tests the dotnet project using the dotnet CLI
+func (dp *dotnetProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running .NET tests"))
+			if err := dp.dotnetCli.Test(ctx, serviceConfig.Path(), defaultDotNetBuildConfiguration); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
 func (dp *dotnetProject) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -123,6 +216,12 @@ func (dp *dotnetProject) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishOptions, err := serviceConfig.DotNet.publishOptions()
+			if err != nil {
+				task.SetError(fmt.Errorf("publishing service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
 			publishRoot, err := os.MkdirTemp("", "azd")
 			if err != nil {
 				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
@@ -130,7 +229,14 @@ func (dp *dotnetProject) Package(
 			}
 
 			task.SetProgress(NewServiceProgress("Publishing .NET project"))
-			if err := dp.dotnetCli.Publish(ctx, serviceConfig.Path(), defaultDotNetBuildConfiguration, publishRoot); err != nil {
+			err = dp.dotnetCli.Publish(
+				ctx,
+				serviceConfig.Path(),
+				defaultDotNetBuildConfiguration,
+				publishRoot,
+				publishOptions,
+			)
+			if err != nil {
 				task.SetError(err)
 				return
 			}
@@ -152,22 +258,37 @@ func (dp *dotnetProject) setUserSecretsFromOutputs(
 	serviceConfig *ServiceConfig,
 	args ServiceLifecycleEventArgs,
 ) error {
-	bicepOutputArgs := args.Args["bicepOutput"]
-	if bicepOutputArgs == nil {
-		log.Println("no bicep outputs set as secrets to dotnet project, map args.Args doesn't contain key \"bicepOutput\"")
-		return nil
-	}
+	bicepOutput := args.Outputs
+	if bicepOutput == nil {
+		bicepOutputArgs := args.Args["bicepOutput"]
+		if bicepOutputArgs == nil {
+			log.Println("no bicep outputs set as secrets to dotnet project, the event has no outputs")
+			return nil
+		}
 
-	bicepOutput, ok := bicepOutputArgs.(map[string]provisioning.OutputParameter)
-	if !ok {
-		return fmt.Errorf("fail on interface conversion: no type in map")
+		outputs, ok := bicepOutputArgs.(map[string]provisioning.OutputParameter)
+		if !ok {
+			return fmt.Errorf("fail on interface conversion: no type in map")
+		}
+		bicepOutput = outputs
 	}
 
+	outputs := make(map[string]string, len(bicepOutput))
 	for key, val := range bicepOutput {
+		outputs[key] = fmt.Sprint(val.Value)
+	}
+
+	// The secrets are named after the env options of the service, ex) KeyVault__Name: AZURE_KEY_VAULT_NAME
+	secrets, _, err := serviceConfig.mapEnvValues(outputs)
+	if err != nil {
+		return err
+	}
+
+	for key, val := range secrets {
 		if err := dp.dotnetCli.SetSecret(
 			ctx,
 			normalizeDotNetSecret(key),
-			fmt.Sprint(val.Value),
+			val,
 			serviceConfig.Path(),
 		); err != nil {
 			return err
@@ -61,6 +61,39 @@ func TestBicepOutputsWithDoubleUnderscoresAreConverted(t *testing.T) {
 	require.Equal(t, "EXAMPLE_OUTPUT", keys[1])
 }
 
+func Test_DotNetProject_UserSecretsEnvOptions(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	secrets := map[string]string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "dotnet user-secrets set")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		secrets[args.Args[2]] = args.Args[3]
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageCsharp)
+	serviceConfig.Env = EnvOptions{
+		Casing: EnvCasingUpperSnake,
+		Map:    map[string]string{"CosmosDb__Endpoint": "cosmosEndpoint"},
+	}
+
+	dotNetCli := dotnet.NewDotNetCli(mockContext.CommandRunner)
+	dp := NewDotNetProject(dotNetCli, environment.Ephemeral()).(*dotnetProject)
+
+	err := dp.setUserSecretsFromOutputs(*mockContext.Context, serviceConfig, ServiceLifecycleEventArgs{
+		Outputs: map[string]provisioning.OutputParameter{
+			"cosmosEndpoint": {Type: "string", Value: "https://cosmos"},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"COSMOS_ENDPOINT":   "https://cosmos",
+		"CosmosDb:Endpoint": "https://cosmos",
+	}, secrets)
+}
+
 func Test_DotNetProject_Restore(t *testing.T) {
 	var runArgs exec.RunArgs
 
@@ -167,3 +200,87 @@ func Test_DotNetProject_Package(t *testing.T) {
 		runArgs.Args[:5],
 	)
 }
+
+func Test_DotNetProject_Package_PublishOptions(t *testing.T) {
+	var runArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "dotnet publish")
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	env := environment.Ephemeral()
+	dotNetCli := dotnet.NewDotNetCli(mockContext.CommandRunner)
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageCsharp)
+	serviceConfig.DotNet = DotNetOptions{
+		Runtime: "linux-x64",
+		Trimmed: true,
+		Aot:     true,
+	}
+
+	dotnetProject := NewDotNetProject(dotNetCli, env)
+	packageTask := dotnetProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{
+			BuildOutputPath: serviceConfig.Path(),
+		},
+	)
+	logProgress(packageTask)
+
+	_, err := packageTask.Await()
+	require.NoError(t, err)
+	require.Equal(t,
+		[]string{"--runtime", "linux-x64", "--self-contained", "true", "-p:PublishTrimmed=true", "-p:PublishAot=true"},
+		runArgs.Args[6:],
+	)
+}
+
+func Test_DotNetOptions_PublishOptions(t *testing.T) {
+	notSelfContained := false
+	selfContained := true
+
+	tests := []struct {
+		name    string
+		options DotNetOptions
+		wantErr string
+	}{
+		{name: "Default", options: DotNetOptions{}},
+		{name: "FrameworkDependent", options: DotNetOptions{Runtime: "linux-x64", SelfContained: &notSelfContained}},
+		{name: "SelfContained", options: DotNetOptions{Runtime: "win-x64", SelfContained: &selfContained}},
+		{
+			name:    "SelfContainedWithoutRuntime",
+			options: DotNetOptions{SelfContained: &selfContained},
+			wantErr: "dotnet.runtime is required for self-contained deployments",
+		},
+		{
+			name:    "TrimmedWithoutRuntime",
+			options: DotNetOptions{Trimmed: true},
+			wantErr: "dotnet.runtime is required for services trimmed",
+		},
+		{
+			name:    "AotNotSelfContained",
+			options: DotNetOptions{Runtime: "linux-x64", Aot: true, SelfContained: &notSelfContained},
+			wantErr: "services compiled ahead of time must be self-contained",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := tt.options.publishOptions()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.options.Runtime, options.Runtime)
+			require.Equal(t, tt.options.SelfContained, options.SelfContained)
+		})
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/golang"
+)
+
+// The platform of the binaries of Go services by default, the platform of Linux App Service and Functions plans
+const defaultGoPlatform = "linux/amd64"
+
+// The directory of the binaries of Go services in the service directory, when the service doesn't configure a dist path
+const defaultGoOutputPath = "bin"
+
+// GoOptions are the options of the services of language go
+type GoOptions struct {
+	// The package built, relative to the service path, ex) ./cmd/api. Defaults to the package of the service path.
+	Package string `yaml:"package"`
+	// The name of the binary, ex) handler. Defaults to the name of the service. Function apps run the binary from the
+	// defaultExecutablePath of the custom handler of host.json.
+	Binary string `yaml:"binary"`
+	// The platform of the binary, ex) linux/arm64. Defaults to linux/amd64.
+	Platform string `yaml:"platform"`
+	// The flags passed to the linker, ex) -s -w
+	Ldflags ExpandableString `yaml:"ldflags"`
+	// The package whose string variables commit, buildTime and environment are set to the metadata of the build with -X
+	// flags of the linker, ex) main or example.com/api/internal/version
+	BuildMetadata string `yaml:"buildMetadata"`
+}
+
+type goProject struct {
+	env    *environment.Environment
+	cli    golang.GoCli
+	gitCli git.GitCli
+}
+
+// NewGoProject creates a new instance of the Go project, which builds the service with go build
+func NewGoProject(cli golang.GoCli, gitCli git.GitCli, env *environment.Environment) FrameworkService {
+	return &goProject{
+		env:    env,
+		cli:    cli,
+		gitCli: gitCli,
+	}
+}
+
+// Gets the required external tools for the project
+func (gp *goProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{gp.cli}
+}
+
+// Initializes the Go project
+func (gp *goProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the project dependencies by downloading the modules of go.mod
+func (gp *goProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			if !fileExists(filepath.Join(serviceConfig.Path(), "go.mod")) {
+				log.Printf("skipping go mod download for service %s, the service has no go.mod", serviceConfig.Name)
+				task.SetResult(&ServiceRestoreResult{})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Downloading Go modules"))
+			if err := gp.cli.ModDownload(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(fmt.Errorf("restoring go modules for service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the binary of the service with go build. The build output is the path of the binary.
+func (gp *goProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			options := serviceConfig.Go
+			goos, goarch, err := parseGoPlatform(options.Platform)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			ldflags, err := gp.ldflags(ctx, serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			outputPath := serviceConfig.OutputPath
+			if outputPath == "" {
+				outputPath = defaultGoOutputPath
+			}
+
+			binary := goBinaryName(serviceConfig, goos)
+			output := filepath.Join(serviceConfig.Path(), outputPath, binary)
+
+			pkg := options.Package
+			if pkg == "" {
+				pkg = "."
+			}
+
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Building %s for %s/%s", binary, goos, goarch)))
+			err = gp.cli.Build(ctx, serviceConfig.Path(), golang.BuildOptions{
+				Package: pkg,
+				Output:  output,
+				Ldflags: ldflags,
+				// Binaries without cgo don't depend on the C libraries of the host
+				Env: []string{"GOOS=" + goos, "GOARCH=" + goarch, "CGO_ENABLED=0"},
+			})
+			if err != nil {
+				task.SetError(fmt.Errorf("building service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: output,
+			})
+		},
+	)
+}
+
+// Computes the digest of the sources of the module and of the linker flags of the build, which may stamp values of the
+// environment into the binary
+func (gp *goProject) BuildDigest(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	ldflags, err := gp.ldflags(ctx, serviceConfig)
+	if err != nil {
+		return "", err
+	}
+
+	outputPath := serviceConfig.OutputPath
+	if outputPath == "" {
+		outputPath = defaultGoOutputPath
+	}
+
+	return sourceDigest(serviceConfig, []string{outputPath}, ldflags)
+}
+
+// Checks whether the binary of the last build still exists
+func (gp *goProject) BuildOutputExists(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) bool {
+	return buildOutputPathExists(buildOutput)
+}
+
+// Gets the version of the go toolchain building the module
+func (gp *goProject) ToolchainVersion(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	return gp.cli.Version(ctx, serviceConfig.Path())
+}
+
+// Tests all the packages of the module with go test
+func (gp *goProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running Go tests"))
+			if err := gp.cli.Test(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Packages the binary with the files of the service which aren't Go sources, ex) the host.json and function.json files
+// of function apps, with the binary at the root of the package.
+func (gp *goProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForBinary(
+				serviceConfig.Path(),
+				publishRoot,
+				buildOutput.BuildOutputPath,
+				buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{excludeGoSources},
+					ignoreFile:        packageIgnoreFile(serviceConfig),
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Gets the flags of the linker of the build, with the -X flags of the build metadata when enabled
+func (gp *goProject) ldflags(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	options := serviceConfig.Go
+	ldflags, err := options.Ldflags.Envsubst(gp.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("evaluating go ldflags: %w", err)
+	}
+
+	if options.BuildMetadata == "" {
+		return strings.TrimSpace(ldflags), nil
+	}
+
+	commit, err := gp.gitCli.GetCurrentCommit(ctx, serviceConfig.Path())
+	if err != nil && !errors.Is(err, git.ErrNotRepository) {
+		log.Printf("failed getting current commit for service %s: %v", serviceConfig.Name, err)
+	}
+
+	metadata := []string{ldflags}
+	for _, variable := range []struct{ name, value string }{
+		{"commit", commit},
+		{"buildTime", time.Now().UTC().Format(time.RFC3339)},
+		{"environment", gp.env.GetEnvName()},
+	} {
+		if variable.value != "" {
+			metadata = append(metadata, fmt.Sprintf("-X %s.%s=%s", options.BuildMetadata, variable.name, variable.value))
+		}
+	}
+
+	return strings.TrimSpace(strings.Join(metadata, " ")), nil
+}
+
+// Gets the GOOS and GOARCH of the platform, ex) linux/amd64
+func parseGoPlatform(platform string) (string, string, error) {
+	if platform == "" {
+		platform = defaultGoPlatform
+	}
+
+	goos, goarch, has := strings.Cut(platform, "/")
+	if !has || goos == "" || goarch == "" {
+		return "", "", fmt.Errorf("invalid go platform '%s', expected <os>/<arch>, ex) %s", platform, defaultGoPlatform)
+	}
+
+	return goos, goarch, nil
+}
+
+// Gets the file name of the binary of the service
+func goBinaryName(serviceConfig *ServiceConfig, goos string) string {
+	binary := serviceConfig.Go.Binary
+	if binary == "" {
+		binary = serviceConfig.Name
+	}
+
+	if goos == "windows" && filepath.Ext(binary) != ".exe" {
+		binary += ".exe"
+	}
+
+	return binary
+}
+
+// Excludes the sources and module files of Go packages, which aren't required by the binary
+func excludeGoSources(path string, file os.FileInfo) bool {
+	if file.IsDir() {
+		return file.Name() == "vendor"
+	}
+
+	name := file.Name()
+	return filepath.Ext(name) == ".go" || name == "go.mod" || name == "go.sum" || name == "go.work" || name == "go.work.sum"
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/golang"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GoProject_Build(t *testing.T) {
+	var buildArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(0, "0123abc\n", ""))
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "go build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		buildArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	env := environment.EphemeralWithValues("dev", map[string]string{"VERSION": "1.2.3"})
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageGo)
+	serviceConfig.Go = GoOptions{
+		Package:       "./cmd/api",
+		Platform:      "linux/arm64",
+		Ldflags:       NewExpandableString("-s -w -X main.version=${VERSION}"),
+		BuildMetadata: "main",
+	}
+
+	goProject := NewGoProject(golang.NewGoCli(mockContext.CommandRunner), git.NewGitCli(mockContext.CommandRunner), env)
+	buildTask := goProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	result, err := buildTask.Await()
+	require.NoError(t, err)
+
+	output := filepath.Join(serviceConfig.Path(), "bin", "api")
+	require.Equal(t, output, result.BuildOutputPath)
+	require.Equal(t, serviceConfig.Path(), buildArgs.Cwd)
+	require.Equal(t, []string{"GOOS=linux", "GOARCH=arm64", "CGO_ENABLED=0"}, buildArgs.Env)
+	require.Equal(t, []string{"build", "-o", output, "-ldflags"}, buildArgs.Args[:4])
+	require.Equal(t, "./cmd/api", buildArgs.Args[5])
+
+	ldflags := buildArgs.Args[4]
+	require.True(t, strings.HasPrefix(ldflags, "-s -w -X main.version=1.2.3 -X main.commit=0123abc -X main.buildTime="))
+	require.True(t, strings.HasSuffix(ldflags, " -X main.environment=dev"))
+}
+
+func Test_GoProject_Package(t *testing.T) {
+	servicePath := t.TempDir()
+	for name, contents := range map[string]string{
+		"main.go":                   "package main",
+		"go.mod":                    "module example.com/handler",
+		"host.json":                 "{}",
+		"hello/function.json":       "{}",
+		"handlers/handlers.go":      "package handlers",
+		filepath.Join("bin", "api"): "binary",
+	} {
+		path := filepath.Join(servicePath, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	serviceConfig := createTestServiceConfig(servicePath, AzureFunctionTarget, ServiceLanguageGo)
+	serviceConfig.Project.Path = ""
+
+	goProject := NewGoProject(
+		golang.NewGoCli(mockContext.CommandRunner),
+		git.NewGitCli(mockContext.CommandRunner),
+		environment.Ephemeral(),
+	)
+	packageTask := goProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{
+		BuildOutputPath: filepath.Join(servicePath, "bin", "api"),
+	})
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(result.PackagePath) })
+
+	files := []string{}
+	err = filepath.Walk(result.PackagePath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(result.PackagePath, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"api", "host.json", "hello/function.json"}, files)
+}
+
+func Test_ParseGoPlatform(t *testing.T) {
+	goos, goarch, err := parseGoPlatform("")
+	require.NoError(t, err)
+	require.Equal(t, "linux", goos)
+	require.Equal(t, "amd64", goarch)
+
+	_, _, err = parseGoPlatform("linux")
+	require.Error(t, err)
+
+	serviceConfig := createTestServiceConfig("./src/api", AzureFunctionTarget, ServiceLanguageGo)
+	require.Equal(t, "api.exe", goBinaryName(serviceConfig, "windows"))
+	serviceConfig.Go.Binary = "handler"
+	require.Equal(t, "handler", goBinaryName(serviceConfig, "linux"))
+}
@@ -0,0 +1,155 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/gradle"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
+	"github.com/otiai10/copy"
+)
+
+type gradleProject struct {
+	env       *environment.Environment
+	gradleCli gradle.GradleCli
+	javacCli  javac.JavacCli
+}
+
+// NewGradleProject creates a new instance of a gradle project, used for the Java services built with Gradle
+func NewGradleProject(env *environment.Environment, gradleCli gradle.GradleCli, javaCli javac.JavacCli) FrameworkService {
+	return &gradleProject{
+		env:       env,
+		gradleCli: gradleCli,
+		javacCli:  javaCli,
+	}
+}
+
+// Gets the required external tools for the project
+func (g *gradleProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{
+		g.gradleCli,
+		g.javacCli,
+	}
+}
+
+// Initializes the gradle project
+func (g *gradleProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	g.gradleCli.SetPath(serviceConfig.Path(), serviceConfig.Project.Path)
+	return nil
+}
+
+// Restores dependencies using the Gradle CLI
+func (g *gradleProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Resolving gradle dependencies"))
+			if err := g.gradleCli.ResolveDependencies(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(fmt.Errorf("resolving gradle dependencies: %w", err))
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the gradle project
+func (g *gradleProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Compiling gradle project"))
+			if err := g.gradleCli.Compile(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: serviceConfig.Path(),
+			})
+		},
+	)
+}
+
+// Tests the project with the gradle test task
+func (g *gradleProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running Gradle tests"))
+			if err := g.gradleCli.Test(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Packages the runnable JAR file of the build/libs directory of the project. The plain JAR files built by the Spring Boot
+// plugin next to the runnable JAR files are ignored.
+func (g *gradleProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating staging directory: %w", err))
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Packaging gradle project"))
+			if err := g.gradleCli.Package(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			publishSource := serviceConfig.Path()
+
+			if serviceConfig.OutputPath != "" {
+				publishSource = filepath.Join(publishSource, serviceConfig.OutputPath)
+			} else {
+				publishSource = filepath.Join(publishSource, "build", "libs")
+			}
+
+			jarName, err := findJar(publishSource, func(name string) bool {
+				return strings.HasSuffix(name, "-plain.jar")
+			})
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			err = copy.Copy(filepath.Join(publishSource, jarName), filepath.Join(publishRoot, AppServiceJavaPackageName))
+			if err != nil {
+				task.SetError(fmt.Errorf("copying to staging directory failed: %w", err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
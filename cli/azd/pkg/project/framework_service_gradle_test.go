@@ -0,0 +1,110 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/gradle"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/javac"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JavaBuildTool(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []string
+		buildTool JavaBuildTool
+		want      JavaBuildTool
+	}{
+		{name: "Default", want: JavaBuildToolMaven},
+		{name: "Pom", files: []string{"pom.xml"}, want: JavaBuildToolMaven},
+		{name: "Gradle", files: []string{"build.gradle"}, want: JavaBuildToolGradle},
+		{name: "GradleKotlin", files: []string{"settings.gradle.kts", "build.gradle.kts"}, want: JavaBuildToolGradle},
+		{name: "PomFirst", files: []string{"pom.xml", "build.gradle"}, want: JavaBuildToolMaven},
+		{name: "Override", files: []string{"pom.xml"}, buildTool: JavaBuildToolGradle, want: JavaBuildToolGradle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ostest.Chdir(t, t.TempDir())
+			require.NoError(t, os.MkdirAll("./src/api", osutil.PermissionDirectory))
+			for _, file := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join("src", "api", file), nil, osutil.PermissionFile))
+			}
+
+			serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJava)
+			serviceConfig.Java.BuildTool = tt.buildTool
+			require.Equal(t, tt.want, serviceConfig.JavaBuildTool())
+		})
+	}
+}
+
+func Test_GradleProject_Package(t *testing.T) {
+	ostest.Chdir(t, t.TempDir())
+	require.NoError(t, os.MkdirAll("./src/api", osutil.PermissionDirectory))
+	f, err := os.OpenFile(filepath.Join(".", "src", "api", getGradlewCmd()), os.O_CREATE, osutil.PermissionExecutableFile)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	var runArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, getGradlewCmd())
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			runArgs = args
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	env := environment.Ephemeral()
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJava)
+	gradleCli := gradle.NewGradleCli(mockContext.CommandRunner)
+	javaCli := javac.NewCli(mockContext.CommandRunner)
+
+	// Simulate the output of the Spring Boot plugin, with a runnable and a plain jar file
+	buildOutputDir := filepath.Join(serviceConfig.Path(), "build", "libs")
+	require.NoError(t, os.MkdirAll(buildOutputDir, osutil.PermissionDirectory))
+	for _, name := range []string{"api-0.0.1.jar", "api-0.0.1-plain.jar"} {
+		err := os.WriteFile(filepath.Join(buildOutputDir, name), []byte(name), osutil.PermissionFile)
+		require.NoError(t, err)
+	}
+
+	gradleProject := NewGradleProject(env, gradleCli, javaCli)
+	require.NoError(t, gradleProject.Initialize(*mockContext.Context, serviceConfig))
+
+	packageTask := gradleProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{
+			BuildOutputPath: serviceConfig.Path(),
+		},
+	)
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	require.Contains(t, runArgs.Cmd, getGradlewCmd())
+	require.Equal(t, []string{"--console=plain", "assemble"}, runArgs.Args)
+
+	content, err := os.ReadFile(filepath.Join(result.PackagePath, AppServiceJavaPackageName))
+	require.NoError(t, err)
+	require.Equal(t, "api-0.0.1.jar", string(content))
+}
+
+func getGradlewCmd() string {
+	if runtime.GOOS == "windows" {
+		return "gradlew.bat"
+	}
+
+	return "gradlew"
+}
@@ -2,6 +2,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,6 +19,52 @@ import (
 // The default, conventional App Service Java package name
 const AppServiceJavaPackageName = "app.jar"
 
+// JavaBuildTool is the build tool of the services of language java
+type JavaBuildTool string
+
+const (
+	JavaBuildToolMaven  JavaBuildTool = "maven"
+	JavaBuildToolGradle JavaBuildTool = "gradle"
+)
+
+// JavaOptions are the options of the services of language java
+type JavaOptions struct {
+	// The build tool of the service, maven or gradle. Detected from the build files of the service by default, ex)
+	// build.gradle or build.gradle.kts for gradle.
+	BuildTool JavaBuildTool `yaml:"buildTool"`
+}
+
+// Gets the build tool of the Java service, the build tool of the java options of the service when set. Otherwise, Gradle
+// is used for services with a build.gradle or build.gradle.kts file and no pom.xml file, and Maven for other services.
+func (sc *ServiceConfig) JavaBuildTool() JavaBuildTool {
+	if sc.Java.BuildTool != "" {
+		return sc.Java.BuildTool
+	}
+
+	if !fileExists(filepath.Join(sc.Path(), "pom.xml")) &&
+		(fileExists(filepath.Join(sc.Path(), "build.gradle")) || fileExists(filepath.Join(sc.Path(), "build.gradle.kts"))) {
+		return JavaBuildToolGradle
+	}
+
+	return JavaBuildToolMaven
+}
+
+// Validates the java options of the service
+func validateJava(serviceConfig *ServiceConfig) error {
+	switch serviceConfig.Java.BuildTool {
+	case "", JavaBuildToolMaven, JavaBuildToolGradle:
+	default:
+		return fmt.Errorf("unsupported java build tool '%s', supported build tools are 'maven' and 'gradle'",
+			serviceConfig.Java.BuildTool)
+	}
+
+	if serviceConfig.Language != ServiceLanguageJava && serviceConfig.Java != (JavaOptions{}) {
+		return errors.New("'java' is only supported for services of language 'java'")
+	}
+
+	return nil
+}
+
 type mavenProject struct {
 	env      *environment.Environment
 	mavenCli maven.MavenCli
@@ -87,6 +134,24 @@ func (m *mavenProject) Build(
 	)
 }
 
+// Tests the project with the maven test phase
+func (m *mavenProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running Maven tests"))
+			if err := m.mavenCli.Test(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
 func (m *mavenProject) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -114,39 +179,14 @@ func (m *mavenProject) Package(
 				publishSource = filepath.Join(publishSource, "target")
 			}
 
-			entries, err := os.ReadDir(publishSource)
+			jarName, err := findJar(publishSource, nil)
 			if err != nil {
-				task.SetError(fmt.Errorf("discovering JAR files in %s: %w", publishSource, err))
-				return
-			}
-
-			matches := []string{}
-			for _, entry := range entries {
-				if entry.IsDir() {
-					continue
-				}
-
-				if name := entry.Name(); strings.HasSuffix(name, ".jar") {
-					matches = append(matches, name)
-				}
-			}
-
-			if len(matches) == 0 {
-				task.SetError(fmt.Errorf("no JAR files found in %s", publishSource))
-				return
-			}
-			if len(matches) > 1 {
-				names := strings.Join(matches, ", ")
-				task.SetError(fmt.Errorf(
-					"multiple JAR files found in %s: %s. Only a single runnable JAR file is expected",
-					publishSource,
-					names,
-				))
+				task.SetError(err)
 				return
 			}
 
 			task.SetProgress(NewServiceProgress("Copying deployment package"))
-			err = copy.Copy(filepath.Join(publishSource, matches[0]), filepath.Join(publishRoot, AppServiceJavaPackageName))
+			err = copy.Copy(filepath.Join(publishSource, jarName), filepath.Join(publishRoot, AppServiceJavaPackageName))
 			if err != nil {
 				task.SetError(fmt.Errorf("copying to staging directory failed: %w", err))
 				return
@@ -159,3 +199,36 @@ func (m *mavenProject) Package(
 		},
 	)
 }
+
+// Finds the single runnable JAR file of the directory, ignoring the JAR files matching ignore when specified
+func findJar(dir string, ignore func(name string) bool) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("discovering JAR files in %s: %w", dir, err)
+	}
+
+	matches := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if name := entry.Name(); strings.HasSuffix(name, ".jar") && (ignore == nil || !ignore(name)) {
+			matches = append(matches, name)
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no JAR files found in %s", dir)
+	}
+	if len(matches) > 1 {
+		names := strings.Join(matches, ", ")
+		return "", fmt.Errorf(
+			"multiple JAR files found in %s: %s. Only a single runnable JAR file is expected",
+			dir,
+			names,
+		)
+	}
+
+	return matches[0], nil
+}
@@ -5,26 +5,82 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment/azdcontext"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/npm"
 )
 
+// NodeOptions are the options of the version of Node.js of the services of language js or ts
+type NodeOptions struct {
+	// The version or range of versions of Node.js the service requires, ex) 18 or >=18 <21. Defaults to the version of the
+	// .nvmrc or .node-version file of the service, or to the node engine of its package.json.
+	Version string `yaml:"version"`
+	// Whether the required version is installed with fnm or nvm when the version of Node.js installed doesn't match it,
+	// instead of failing
+	Install bool `yaml:"install"`
+}
+
+// Gets the version of Node.js the service requires, nil when the service doesn't declare a version
+func (sc *ServiceConfig) NodeVersion() (*npm.NodeVersion, error) {
+	if sc.Node.Version != "" {
+		return npm.ParseNodeVersion(sc.Node.Version, azdcontext.ProjectFileName)
+	}
+
+	return npm.DetectNodeVersion(sc.Path(), sc.Project.Path)
+}
+
+// Validates the node options of the service
+func validateNode(serviceConfig *ServiceConfig) error {
+	if serviceConfig.Node == (NodeOptions{}) {
+		return nil
+	}
+
+	if serviceConfig.Language != ServiceLanguageJavaScript && serviceConfig.Language != ServiceLanguageTypeScript {
+		return errors.New("'node' is only supported for services of language 'js' or 'ts'")
+	}
+
+	if serviceConfig.Node.Version != "" {
+		if _, err := npm.ParseNodeVersion(serviceConfig.Node.Version, azdcontext.ProjectFileName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 type npmProject struct {
 	env *environment.Environment
 	cli npm.NpmCli
+
+	// Serializes the installs of the packages of workspaces, which update the same node_modules and lock file
+	workspaceMutex sync.Mutex
+	// The roots of the workspaces whose packages are all installed, keyed by root directory
+	installedWorkspaces map[string]bool
+
+	// Serializes the checks and installs of the versions of Node.js required by the services
+	nodeMutex sync.Mutex
+	// The directories of the node executables of the versions required by the services, keyed by version. Empty for the
+	// versions allowing the version of Node.js on the PATH.
+	nodeBinDirs map[string]string
 }
 
 // NewNpmProject creates a new instance of a NPM project
 func NewNpmProject(cli npm.NpmCli, env *environment.Environment) FrameworkService {
 	return &npmProject{
-		env: env,
-		cli: cli,
+		env:                 env,
+		cli:                 cli,
+		installedWorkspaces: map[string]bool{},
+		nodeBinDirs:         map[string]string{},
 	}
 }
 
@@ -45,8 +101,20 @@ func (np *npmProject) Restore(
 ) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
-			task.SetProgress(NewServiceProgress("Installing NPM dependencies"))
-			if err := np.cli.Install(ctx, serviceConfig.Path()); err != nil {
+			ctx, err := np.ensureNodeVersion(ctx, serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packageManager, err := detectPackageManager(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Installing %s dependencies", packageManager.Name)))
+			if err := np.install(ctx, serviceConfig, packageManager); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -64,10 +132,28 @@ func (np *npmProject) Build(
 ) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			ctx, err := np.ensureNodeVersion(ctx, serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			// Exec custom `build` script if available
 			// If `build`` script is not defined in the package.json the NPM script will NOT fail
-			task.SetProgress(NewServiceProgress("Running NPM build script"))
-			if err := np.cli.RunScript(ctx, serviceConfig.Path(), "build", np.env.Environ()); err != nil {
+			envs, err := serviceConfig.Environ(np.env)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packageManager, err := detectPackageManager(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Running %s build script", packageManager.Name)))
+			if err := np.runScript(ctx, serviceConfig, packageManager, "build", envs); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -86,6 +172,84 @@ func (np *npmProject) Build(
 	)
 }
 
+// Computes the digest of the sources of the service and of the environment values its build script runs with.
+// Dependencies and the output of the build aren't part of the digest.
+func (np *npmProject) BuildDigest(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	envs, err := envDigestInputs(serviceConfig, np.env)
+	if err != nil {
+		return "", err
+	}
+
+	return sourceDigest(serviceConfig, []string{"node_modules", serviceConfig.OutputPath}, envs...)
+}
+
+// Checks whether the output directory of the last build still exists
+func (np *npmProject) BuildOutputExists(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) bool {
+	return buildOutputPathExists(buildOutput)
+}
+
+// Gets the version of Node.js building the project, the version the service requires when it's installed by azd
+func (np *npmProject) ToolchainVersion(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	if _, err := np.ensureNodeVersion(ctx, serviceConfig); err != nil {
+		return "", err
+	}
+
+	binDir := ""
+	if required, _ := serviceConfig.NodeVersion(); required != nil {
+		np.nodeMutex.Lock()
+		binDir = np.nodeBinDirs[required.Constraint]
+		np.nodeMutex.Unlock()
+	}
+
+	version, err := np.cli.NodeVersion(ctx, binDir)
+	if err != nil {
+		return "", err
+	}
+
+	return "node " + version.String(), nil
+}
+
+// Tests the project executing the npm `test` script defined within the project package.json
+func (np *npmProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			ctx, err := np.ensureNodeVersion(ctx, serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			envs, err := serviceConfig.Environ(np.env)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packageManager, err := detectPackageManager(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			// If `test` script is not defined in the package.json the NPM script will NOT fail
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Running %s test script", packageManager.Name)))
+			if err := np.runScript(ctx, serviceConfig, packageManager, "test", envs); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
 func (np *npmProject) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -93,6 +257,12 @@ func (np *npmProject) Package(
 ) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			ctx, err := np.ensureNodeVersion(ctx, serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			publishRoot, err := os.MkdirTemp("", "azd")
 			if err != nil {
 				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
@@ -100,12 +270,23 @@ func (np *npmProject) Package(
 			}
 
 			// Run Build, injecting env.
-			envs := append(np.env.Environ(), "NODE_ENV=production")
+			envs, err := serviceConfig.Environ(np.env)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+			envs = append(envs, "NODE_ENV=production")
 
 			// Exec custom `package` script if available
 			// If `package` script is not defined in the package.json the NPM script will NOT fail
-			task.SetProgress(NewServiceProgress("Running NPM package script"))
-			if err := np.cli.RunScript(ctx, serviceConfig.Path(), "package", envs); err != nil {
+			packageManager, err := detectPackageManager(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Running %s package script", packageManager.Name)))
+			if err := np.runScript(ctx, serviceConfig, packageManager, "package", envs); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -126,6 +307,7 @@ func (np *npmProject) Package(
 					excludeConditions: []excludeDirEntryCondition{
 						excludeNodeModules,
 					},
+					ignoreFile: packageIgnoreFile(serviceConfig),
 				}); err != nil {
 				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
 				return
@@ -139,6 +321,143 @@ func (np *npmProject) Package(
 	)
 }
 
+// Checks the version of Node.js on the PATH is allowed by the version the service requires, if any. When it isn't and
+// node.install is set, the required version is installed and the context gets the PATH of the installed version, which is
+// used by the commands run with the context.
+func (np *npmProject) ensureNodeVersion(ctx context.Context, serviceConfig *ServiceConfig) (context.Context, error) {
+	required, err := serviceConfig.NodeVersion()
+	if err != nil {
+		return nil, fmt.Errorf("detecting node version of service %s: %w", serviceConfig.Name, err)
+	}
+
+	if required == nil {
+		return ctx, nil
+	}
+
+	np.nodeMutex.Lock()
+	defer np.nodeMutex.Unlock()
+
+	binDir, has := np.nodeBinDirs[required.Constraint]
+	if !has {
+		installed, err := np.cli.NodeVersion(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if !required.Allows(installed) {
+			if !serviceConfig.Node.Install {
+				return nil, fmt.Errorf(
+					"service %s requires Node.js %s, but Node.js %s is installed. Install the required version, "+
+						"select it with nvm or fnm, or set 'node.install' to true for the service in %s to install it",
+					serviceConfig.Name, required, installed, azdcontext.ProjectFileName,
+				)
+			}
+
+			log.Printf("installing Node.js %s for service %s, Node.js %s is installed", required, serviceConfig.Name, installed)
+			binDir, err = np.cli.InstallNode(ctx, serviceConfig.Path(), required)
+			if err != nil {
+				return nil, fmt.Errorf("installing Node.js %s for service %s: %w", required, serviceConfig.Name, err)
+			}
+
+			installed, err = np.cli.NodeVersion(ctx, binDir)
+			if err != nil {
+				return nil, err
+			}
+
+			if !required.Allows(installed) {
+				return nil, fmt.Errorf(
+					"service %s requires Node.js %s, but Node.js %s was installed", serviceConfig.Name, required, installed)
+			}
+		}
+
+		np.nodeBinDirs[required.Constraint] = binDir
+	}
+
+	if binDir == "" {
+		return ctx, nil
+	}
+
+	return exec.WithEnvVars(ctx, "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH")), nil
+}
+
+// Detects the package manager of the service, npm, pnpm or yarn, from the service directory up to the project directory
+func detectPackageManager(serviceConfig *ServiceConfig) (npm.PackageManagerInfo, error) {
+	packageManager, err := npm.DetectPackageManager(serviceConfig.Path(), serviceConfig.Project.Path)
+	if err != nil {
+		return packageManager, fmt.Errorf("detecting package manager of service %s: %w", serviceConfig.Name, err)
+	}
+
+	return packageManager, nil
+}
+
+// Detects the workspace of a monorepo the service is a package of, up to the project directory. Returns nil when the
+// service isn't a package of a workspace.
+func detectWorkspace(serviceConfig *ServiceConfig) (*npm.Workspace, error) {
+	workspace, err := npm.DetectWorkspace(serviceConfig.Path(), serviceConfig.Project.Path)
+	if err != nil {
+		return nil, fmt.Errorf("detecting workspace of service %s: %w", serviceConfig.Name, err)
+	}
+
+	return workspace, nil
+}
+
+// Installs the dependencies of the service. The dependencies of services that are packages of a workspace are installed
+// from the root of the workspace, instead of installing the whole dependency tree of the workspace for each service.
+func (np *npmProject) install(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageManager npm.PackageManagerInfo,
+) error {
+	workspace, err := detectWorkspace(serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	if workspace == nil {
+		return np.cli.Install(ctx, serviceConfig.Path(), packageManager)
+	}
+
+	np.workspaceMutex.Lock()
+	defer np.workspaceMutex.Unlock()
+
+	// yarn installs all the packages of the workspace, so the workspace is installed once for all its services
+	if packageManager.Name == npm.PackageManagerYarn && np.installedWorkspaces[workspace.Root] {
+		log.Printf("skipping install of %s, workspace %s is already installed", serviceConfig.Name, workspace.Root)
+		return nil
+	}
+
+	log.Printf("installing %s from the root of workspace %s", workspace.Package, workspace.Root)
+	if err := np.cli.InstallWorkspace(ctx, workspace, packageManager); err != nil {
+		return err
+	}
+
+	if packageManager.Name == npm.PackageManagerYarn {
+		np.installedWorkspaces[workspace.Root] = true
+	}
+
+	return nil
+}
+
+// Runs the script of the service, from the root of its workspace when the service is a package of a workspace
+func (np *npmProject) runScript(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageManager npm.PackageManagerInfo,
+	scriptName string,
+	env []string,
+) error {
+	workspace, err := detectWorkspace(serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	if workspace == nil {
+		return np.cli.RunScript(ctx, serviceConfig.Path(), packageManager, scriptName, env)
+	}
+
+	return np.cli.RunWorkspaceScript(ctx, workspace, packageManager, scriptName, env)
+}
+
 const cNodeModulesName = "node_modules"
 
 func excludeNodeModules(path string, file os.FileInfo) bool {
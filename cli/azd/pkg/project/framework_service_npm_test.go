@@ -3,6 +3,7 @@ package project
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -120,3 +121,55 @@ func Test_NpmProject_Package(t *testing.T) {
 		runArgs.Args,
 	)
 }
+
+func Test_NpmProject_NodeVersion(t *testing.T) {
+	restore := func(t *testing.T, nodeVersion string, serviceConfig *ServiceConfig) (bool, error) {
+		installed := false
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "node --version")
+		}).Respond(exec.NewRunResult(0, nodeVersion, ""))
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "npm install")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			installed = true
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		npmProject := NewNpmProject(npm.NewNpmCli(mockContext.CommandRunner), environment.Ephemeral())
+		restoreTask := npmProject.Restore(*mockContext.Context, serviceConfig)
+		logProgress(restoreTask)
+
+		_, err := restoreTask.Await()
+		return installed, err
+	}
+
+	createService := func(t *testing.T) *ServiceConfig {
+		serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageTypeScript)
+		require.NoError(t, os.WriteFile(
+			filepath.Join(serviceConfig.Path(), ".nvmrc"), []byte("18\n"), osutil.PermissionFile))
+		return serviceConfig
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		installed, err := restore(t, "v18.19.1", createService(t))
+		require.NoError(t, err)
+		require.True(t, installed)
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		installed, err := restore(t, "v20.11.0", createService(t))
+		require.ErrorContains(t, err, "service api requires Node.js 18 (from ")
+		require.ErrorContains(t, err, "but Node.js 20.11.0 is installed")
+		require.False(t, installed)
+	})
+
+	t.Run("VersionOfProject", func(t *testing.T) {
+		serviceConfig := createService(t)
+		serviceConfig.Node.Version = ">=20"
+
+		installed, err := restore(t, "v20.11.0", serviceConfig)
+		require.NoError(t, err)
+		require.True(t, installed)
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/composer"
+	"golang.org/x/exp/slices"
+)
+
+// The caches of PHP tools, which aren't deployed
+var phpCacheFiles = []string{".phpunit.result.cache", ".php-cs-fixer.cache", ".php_cs.cache"}
+
+type phpProject struct {
+	cli composer.ComposerCli
+}
+
+// NewPhpProject creates a new instance of the PHP project, which installs the dependencies of the service with composer
+func NewPhpProject(cli composer.ComposerCli) FrameworkService {
+	return &phpProject{
+		cli: cli,
+	}
+}
+
+// Gets the required external tools for the project
+func (pp *phpProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{pp.cli}
+}
+
+// Initializes the PHP project
+func (pp *phpProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the project dependencies, including the dev dependencies, with composer install
+func (pp *phpProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			if !fileExists(filepath.Join(serviceConfig.Path(), "composer.json")) {
+				log.Printf("skipping composer install for service %s, the service has no composer.json", serviceConfig.Name)
+				task.SetResult(&ServiceRestoreResult{})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Installing composer dependencies"))
+			if err := pp.cli.Install(ctx, serviceConfig.Path(), composer.InstallOptions{}); err != nil {
+				task.SetError(fmt.Errorf("restoring composer dependencies for service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// PHP projects aren't built, the build output is the service directory
+func (pp *phpProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: serviceConfig.Path(),
+			})
+		},
+	)
+}
+
+// Tests the project with the `test` script of the composer.json of the project, ex) phpunit
+func (pp *phpProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			hasTestScript, err := composerHasScript(filepath.Join(serviceConfig.Path(), "composer.json"), "test")
+			if err != nil {
+				task.SetError(fmt.Errorf("reading composer.json of service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			if !hasTestScript {
+				log.Printf("skipping tests of service %s, the composer.json has no test script", serviceConfig.Name)
+				task.SetResult(&ServiceTestResult{Skipped: true})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Running composer test script"))
+			if err := pp.cli.RunScript(ctx, serviceConfig.Path(), "test"); err != nil {
+				task.SetError(fmt.Errorf("testing service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Packages the service for zip deploy. The vendor directory and the caches of PHP tools aren't copied, the dependencies
+// are installed again in the package without the dev dependencies.
+func (pp *phpProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			publishSource := buildOutput.BuildOutputPath
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForZip(
+				publishSource,
+				publishRoot,
+				buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{excludePhpArtifacts(publishSource)},
+					ignoreFile:        packageIgnoreFile(serviceConfig),
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			if fileExists(filepath.Join(publishRoot, "composer.json")) {
+				task.SetProgress(NewServiceProgress("Installing production dependencies"))
+				if err := pp.cli.Install(ctx, publishRoot, composer.InstallOptions{NoDev: true}); err != nil {
+					task.SetError(fmt.Errorf("packaging composer dependencies for service %s: %w", serviceConfig.Name, err))
+					return
+				}
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Excludes the vendor directory of the project, with its dev dependencies, and the caches of PHP tools. The vendor
+// directories of public assets, ex) public/vendor, are deployed.
+func excludePhpArtifacts(servicePath string) excludeDirEntryCondition {
+	excludedDirs := []string{
+		filepath.Join(servicePath, "vendor"),
+		filepath.Join(servicePath, "var", "cache"),
+		filepath.Join(servicePath, ".phpunit.cache"),
+	}
+
+	return func(path string, file os.FileInfo) bool {
+		excluded := phpCacheFiles
+		if file.IsDir() {
+			path, excluded = filepath.Clean(path), excludedDirs
+		} else {
+			path = file.Name()
+		}
+
+		return slices.Contains(excluded, path)
+	}
+}
+
+// Gets whether the composer.json declares the script. Projects without a composer.json have no scripts.
+func composerHasScript(composerJsonPath string, script string) (bool, error) {
+	content, err := os.ReadFile(composerJsonPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	var composerJson struct {
+		Scripts map[string]any `json:"scripts"`
+	}
+	if err := json.Unmarshal(content, &composerJson); err != nil {
+		return false, err
+	}
+
+	_, has := composerJson.Scripts[script]
+	return has, nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/composer"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PhpProject_Restore(t *testing.T) {
+	var installArgs []exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "composer install")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		installArgs = append(installArgs, args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	servicePath := t.TempDir()
+	serviceConfig := createTestServiceConfig(servicePath, AppServiceTarget, ServiceLanguagePhp)
+	serviceConfig.Project.Path = ""
+	phpProject := NewPhpProject(composer.NewComposerCli(mockContext.CommandRunner))
+
+	// Services without composer.json have no dependencies
+	restoreTask := phpProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	_, err := restoreTask.Await()
+	require.NoError(t, err)
+	require.Empty(t, installArgs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(servicePath, "composer.json"), []byte("{}"), osutil.PermissionFile))
+	restoreTask = phpProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	_, err = restoreTask.Await()
+	require.NoError(t, err)
+	require.Len(t, installArgs, 1)
+	require.Equal(t, servicePath, installArgs[0].Cwd)
+	require.NotContains(t, installArgs[0].Args, "--no-dev")
+}
+
+func Test_PhpProject_Test(t *testing.T) {
+	var testArgs []exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "composer run-script")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		testArgs = append(testArgs, args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	servicePath := t.TempDir()
+	serviceConfig := createTestServiceConfig(servicePath, AppServiceTarget, ServiceLanguagePhp)
+	serviceConfig.Project.Path = ""
+	phpProject := NewPhpProject(composer.NewComposerCli(mockContext.CommandRunner))
+
+	// Projects without a test script have no tests
+	require.NoError(t, os.WriteFile(filepath.Join(servicePath, "composer.json"), []byte("{}"), osutil.PermissionFile))
+	testTask := phpProject.Test(*mockContext.Context, serviceConfig)
+	logProgress(testTask)
+	result, err := testTask.Await()
+	require.NoError(t, err)
+	require.True(t, result.Skipped)
+	require.Empty(t, testArgs)
+
+	composerJson := `{"scripts": {"test": "phpunit"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(servicePath, "composer.json"), []byte(composerJson), osutil.PermissionFile))
+	testTask = phpProject.Test(*mockContext.Context, serviceConfig)
+	logProgress(testTask)
+	result, err = testTask.Await()
+	require.NoError(t, err)
+	require.False(t, result.Skipped)
+	require.Len(t, testArgs, 1)
+	require.Equal(t, []string{"run-script", "--no-interaction", "test"}, testArgs[0].Args)
+}
+
+func Test_PhpProject_Package(t *testing.T) {
+	servicePath := t.TempDir()
+	for name, contents := range map[string]string{
+		"composer.json":                "{}",
+		"composer.lock":                "{}",
+		"public/index.php":             "<?php",
+		"public/vendor/app.js":         "",
+		"src/Kernel.php":               "<?php",
+		"vendor/autoload.php":          "<?php",
+		"vendor/phpunit/phpunit.php":   "<?php",
+		"var/cache/prod/container.php": "<?php",
+		"var/log/.gitkeep":             "",
+		".phpunit.result.cache":        "",
+	} {
+		path := filepath.Join(servicePath, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	var installArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "composer install")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		installArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig(servicePath, AppServiceTarget, ServiceLanguagePhp)
+	serviceConfig.Project.Path = ""
+
+	phpProject := NewPhpProject(composer.NewComposerCli(mockContext.CommandRunner))
+	packageTask := phpProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{
+		BuildOutputPath: servicePath,
+	})
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(result.PackagePath) })
+
+	require.Equal(t, result.PackagePath, installArgs.Cwd)
+	require.Contains(t, installArgs.Args, "--no-dev")
+
+	files := []string{}
+	err = filepath.Walk(result.PackagePath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(result.PackagePath, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		"composer.json",
+		"composer.lock",
+		"public/index.php",
+		"public/vendor/app.js",
+		"src/Kernel.php",
+		"var/log/.gitkeep",
+	}, files)
+}
@@ -0,0 +1,307 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// Framework plugins handle the services of languages azd doesn't support, ex) a bespoke build system. A plugin is an
+// executable named azd-framework-<language> in the plugins/frameworks directory of the user configuration of azd, ex)
+// ~/.azd/plugins/frameworks/azd-framework-bazel handles the services of language bazel.
+//
+// The plugin is run in the service directory for each phase, with the phase as its argument, ex) azd-framework-bazel
+// build, the environment values of the service as environment variables, and a frameworkPluginRequest as JSON on its
+// stdin. The plugin writes a frameworkPluginResponse as JSON on its stdout, and its progress as lines on its stderr.
+// A plugin fails a phase by exiting with a non-zero exit code.
+const (
+	// The prefix of the executables of framework plugins
+	FrameworkPluginPrefix = "azd-framework-"
+	// The version of the protocol between azd and framework plugins, sent to the plugins so they can reject requests
+	// of versions they don't support
+	FrameworkPluginProtocolVersion = "1.0"
+)
+
+// The directory of the framework plugins, in the user configuration directory of azd
+var frameworkPluginsDir = filepath.Join("plugins", "frameworks")
+
+// The names of the languages of plugins, so that languages can't name executables outside of the plugins directory
+var frameworkPluginLanguageRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// frameworkPluginRequest is the request written to the stdin of framework plugins
+type frameworkPluginRequest struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Phase           string                 `json:"phase"`
+	Service         frameworkPluginService `json:"service"`
+	// The output of the build of the service, for the package phase
+	BuildOutputPath string `json:"buildOutputPath,omitempty"`
+	// The options of the service for the plugin, from the plugin section of the service in azure.yaml
+	Options map[string]any `json:"options,omitempty"`
+}
+
+// frameworkPluginService is the service of the request of framework plugins
+type frameworkPluginService struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Language   string `json:"language"`
+	Host       string `json:"host"`
+	OutputPath string `json:"outputPath,omitempty"`
+}
+
+// frameworkPluginResponse is the response written to the stdout of framework plugins
+type frameworkPluginResponse struct {
+	// The output of the build of the service, for the build phase. Defaults to the dist path of the service, or the
+	// service directory.
+	BuildOutputPath string `json:"buildOutputPath"`
+	// The directory or file deployed, for the package phase. Defaults to the output of the build of the service.
+	PackagePath string `json:"packagePath"`
+	// Whether the tests of the service weren't run, for the test phase, ex) the service has no tests
+	Skipped bool `json:"skipped"`
+}
+
+// FindFrameworkPlugin finds the executable of the framework plugin handling the services of the language
+func FindFrameworkPlugin(language ServiceLanguageKind) (string, bool) {
+	if !frameworkPluginLanguageRegexp.MatchString(string(language)) {
+		return "", false
+	}
+
+	configDir, err := config.GetUserConfigDir()
+	if err != nil {
+		return "", false
+	}
+
+	name := FrameworkPluginPrefix + string(language)
+	candidates := []string{name}
+	if runtime.GOOS == "windows" {
+		candidates = []string{name + ".exe", name + ".cmd", name + ".bat"}
+	}
+
+	for _, candidate := range candidates {
+		pluginPath := filepath.Join(configDir, frameworkPluginsDir, candidate)
+		if fileExists(pluginPath) {
+			return pluginPath, true
+		}
+	}
+
+	return "", false
+}
+
+// Validates the plugin options are only set for services of languages handled by framework plugins
+func validateFrameworkPlugin(serviceConfig *ServiceConfig) error {
+	if len(serviceConfig.Plugin) == 0 {
+		return nil
+	}
+
+	if _, has := FindFrameworkPlugin(serviceConfig.Language); !has {
+		return errors.New("'plugin' is only supported for services of languages handled by framework plugins")
+	}
+
+	return nil
+}
+
+type pluginProject struct {
+	pluginPath    string
+	env           *environment.Environment
+	commandRunner exec.CommandRunner
+}
+
+// NewPluginProject creates a new instance of the project of a language handled by the framework plugin of the path
+func NewPluginProject(pluginPath string, commandRunner exec.CommandRunner, env *environment.Environment) FrameworkService {
+	return &pluginProject{
+		pluginPath:    pluginPath,
+		env:           env,
+		commandRunner: commandRunner,
+	}
+}
+
+// Gets the required external tools for the project. The plugin checks the tools it requires itself.
+func (pp *pluginProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+// Initializes the plugin project
+func (pp *pluginProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the project dependencies with the restore phase of the plugin
+func (pp *pluginProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			if _, err := pp.run(ctx, serviceConfig, "restore", "", task.SetProgress); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the project with the build phase of the plugin
+func (pp *pluginProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			response, err := pp.run(ctx, serviceConfig, "build", "", task.SetProgress)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			buildOutputPath := customOutputPath(serviceConfig)
+			if response.BuildOutputPath != "" {
+				buildOutputPath = pluginPath(serviceConfig, response.BuildOutputPath)
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: buildOutputPath,
+			})
+		},
+	)
+}
+
+// Tests the project with the test phase of the plugin
+func (pp *pluginProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			response, err := pp.run(ctx, serviceConfig, "test", "", task.SetProgress)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{Skipped: response.Skipped})
+		},
+	)
+}
+
+// Packages the project with the package phase of the plugin
+func (pp *pluginProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			buildOutputPath := customOutputPath(serviceConfig)
+			if buildOutput != nil && buildOutput.BuildOutputPath != "" {
+				buildOutputPath = buildOutput.BuildOutputPath
+			}
+
+			response, err := pp.run(ctx, serviceConfig, "package", buildOutputPath, task.SetProgress)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packagePath := buildOutputPath
+			if response.PackagePath != "" {
+				packagePath = pluginPath(serviceConfig, response.PackagePath)
+			}
+
+			if _, err := os.Stat(packagePath); err != nil {
+				task.SetError(fmt.Errorf("package of service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: packagePath,
+			})
+		},
+	)
+}
+
+// Runs the phase of the plugin for the service, reporting the lines the plugin writes to stderr as progress
+func (pp *pluginProject) run(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	phase string,
+	buildOutputPath string,
+	setProgress func(ServiceProgress),
+) (*frameworkPluginResponse, error) {
+	request, err := json.Marshal(frameworkPluginRequest{
+		ProtocolVersion: FrameworkPluginProtocolVersion,
+		Phase:           phase,
+		Service: frameworkPluginService{
+			Name:       serviceConfig.Name,
+			Path:       serviceConfig.Path(),
+			Language:   string(serviceConfig.Language),
+			Host:       string(serviceConfig.Host),
+			OutputPath: serviceConfig.OutputPath,
+		},
+		BuildOutputPath: buildOutputPath,
+		Options:         serviceConfig.Plugin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating %s request of plugin: %w", phase, err)
+	}
+
+	envs, err := serviceConfig.Environ(pp.env)
+	if err != nil {
+		return nil, err
+	}
+
+	setProgress(NewServiceProgress(fmt.Sprintf("Running %s of %s plugin", phase, serviceConfig.Language)))
+	runArgs := exec.NewRunArgs(pp.pluginPath, phase).
+		WithCwd(serviceConfig.Path()).
+		WithEnv(envs).
+		WithStdIn(bytes.NewReader(request)).
+		WithStdErr(exec.NewLineWriter(func(line string) {
+			setProgress(NewServiceProgress(line))
+		}))
+
+	res, err := pp.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"running %s of %s plugin for service %s: %s: %w", phase, serviceConfig.Language, serviceConfig.Name, res.String(), err)
+	}
+
+	response := &frameworkPluginResponse{}
+	if strings.TrimSpace(res.Stdout) == "" {
+		return response, nil
+	}
+
+	if err := json.Unmarshal([]byte(res.Stdout), response); err != nil {
+		return nil, fmt.Errorf(
+			"parsing %s response of %s plugin for service %s: %w", phase, serviceConfig.Language, serviceConfig.Name, err)
+	}
+
+	return response, nil
+}
+
+// Gets the path of a path of the response of a plugin, relative to the service directory unless absolute
+func pluginPath(serviceConfig *ServiceConfig, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(serviceConfig.Path(), path)
+}
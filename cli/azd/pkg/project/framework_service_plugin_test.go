@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const frameworkPluginTestProject = `
+name: test-app
+services:
+  api:
+    project: src/api
+    language: bazel
+    host: appservice
+    plugin:
+      target: //src/api:server
+`
+
+// Installs a framework plugin of the language in a temporary user configuration directory of azd
+func installFrameworkPlugin(t *testing.T, language string) string {
+	configDir := t.TempDir()
+	t.Setenv("AZD_CONFIG_DIR", configDir)
+
+	name := FrameworkPluginPrefix + language
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+
+	pluginPath := filepath.Join(configDir, frameworkPluginsDir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(pluginPath), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(pluginPath, []byte{}, osutil.PermissionExecutableFile))
+	return pluginPath
+}
+
+func Test_FrameworkPlugin_Parse(t *testing.T) {
+	t.Setenv("AZD_CONFIG_DIR", t.TempDir())
+	_, err := Parse(context.Background(), frameworkPluginTestProject)
+	require.ErrorContains(t, err, "unsupported language 'bazel', and no framework plugin azd-framework-bazel was found")
+
+	installFrameworkPlugin(t, "bazel")
+	projectConfig, err := Parse(context.Background(), frameworkPluginTestProject)
+	require.NoError(t, err)
+	require.Equal(t, ServiceLanguageKind("bazel"), projectConfig.Services["api"].Language)
+
+	_, err = Parse(context.Background(), strings.Replace(frameworkPluginTestProject, "bazel", "go", 1))
+	require.ErrorContains(t, err, "'plugin' is only supported for services of languages handled by framework plugins")
+}
+
+func Test_PluginProject_Build(t *testing.T) {
+	pluginPath := installFrameworkPlugin(t, "bazel")
+	projectConfig, err := Parse(context.Background(), frameworkPluginTestProject)
+	require.NoError(t, err)
+	projectConfig.Path = t.TempDir()
+	serviceConfig := projectConfig.Services["api"]
+
+	var request frameworkPluginRequest
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == pluginPath
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{"build"}, args.Args)
+		require.Equal(t, serviceConfig.Path(), args.Cwd)
+
+		stdin, err := io.ReadAll(args.StdIn)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(stdin, &request))
+
+		_, err = args.Stderr.Write([]byte("Analyzing target //src/api:server\n"))
+		require.NoError(t, err)
+		return exec.NewRunResult(0, `{"buildOutputPath": "bazel-bin/src/api"}`, ""), nil
+	})
+
+	pluginProject := NewPluginProject(pluginPath, mockContext.CommandRunner, environment.Ephemeral())
+	buildTask := pluginProject.Build(*mockContext.Context, serviceConfig, nil)
+
+	progress := []string{}
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		for p := range buildTask.Progress() {
+			progress = append(progress, p.Message)
+		}
+	}()
+
+	result, err := buildTask.Await()
+	<-progressDone
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(serviceConfig.Path(), "bazel-bin", "src", "api"), result.BuildOutputPath)
+	require.Contains(t, progress, "Analyzing target //src/api:server")
+
+	require.Equal(t, FrameworkPluginProtocolVersion, request.ProtocolVersion)
+	require.Equal(t, "build", request.Phase)
+	require.Equal(t, "api", request.Service.Name)
+	require.Equal(t, "appservice", request.Service.Host)
+	require.Equal(t, map[string]any{"target": "//src/api:server"}, request.Options)
+}
@@ -6,6 +6,7 @@ package project
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"path"
 	"path/filepath"
@@ -108,6 +109,30 @@ func (pp *pythonProject) Build(
 	)
 }
 
+// Tests the project with pytest, when the project has tests
+func (pp *pythonProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			if !hasPythonTests(serviceConfig.Path()) {
+				log.Printf("skipping tests of service %s, the project has no tests", serviceConfig.Name)
+				task.SetResult(&ServiceTestResult{Skipped: true})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Running pytest"))
+			if err := pp.cli.Pytest(ctx, serviceConfig.Path(), pp.getVenvName(serviceConfig)); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
 func (pp *pythonProject) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -132,6 +157,7 @@ func (pp *pythonProject) Package(
 						excludeVirtualEnv,
 						excludePyCache,
 					},
+					ignoreFile: packageIgnoreFile(serviceConfig),
 				}); err != nil {
 				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
 				return
@@ -171,3 +197,17 @@ func (pp *pythonProject) getVenvName(serviceConfig *ServiceConfig) string {
 	_, projectDir := filepath.Split(trimmedPath)
 	return projectDir + "_env"
 }
+
+// The files and directories of the tests of Python projects, which pytest collects
+var pythonTestMarkers = []string{"pytest.ini", "conftest.py", "tests", "test"}
+
+// Gets whether the project has tests, which are run with pytest
+func hasPythonTests(projectPath string) bool {
+	for _, marker := range pythonTestMarkers {
+		if _, err := os.Stat(filepath.Join(projectPath, marker)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
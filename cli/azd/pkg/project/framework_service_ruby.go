@@ -0,0 +1,227 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bundler"
+	"golang.org/x/exp/slices"
+)
+
+// RubyOptions are the options of the services of language ruby
+type RubyOptions struct {
+	// Whether the assets of the Rails app of the service are precompiled when the service is built, with
+	// rails assets:precompile. Defaults to true for Rails apps.
+	PrecompileAssets *bool `yaml:"precompileAssets"`
+}
+
+// The directories of Ruby projects restored or generated locally, which aren't deployed
+var rubyLocalDirs = []string{filepath.Join("vendor", "bundle"), ".bundle", "log", "tmp", "node_modules", "coverage"}
+
+// The environment of the Rails commands run to build the service. Rails 7.1 and later don't require the secrets of the app
+// to precompile its assets with SECRET_KEY_BASE_DUMMY.
+var railsBuildEnv = []string{"RAILS_ENV=production", "SECRET_KEY_BASE_DUMMY=1"}
+
+// Validates the ruby options of the service
+func validateRuby(serviceConfig *ServiceConfig) error {
+	if serviceConfig.Language != ServiceLanguageRuby && serviceConfig.Ruby != (RubyOptions{}) {
+		return errors.New("'ruby' is only supported for services of language 'ruby'")
+	}
+
+	return nil
+}
+
+// Checks whether the service is a Rails app
+func isRailsApp(serviceConfig *ServiceConfig) bool {
+	return fileExists(filepath.Join(serviceConfig.Path(), "config", "application.rb"))
+}
+
+// Checks whether the assets of the Rails app of the service are precompiled when the service is built
+func (sc *ServiceConfig) PrecompilesAssets() bool {
+	if sc.Ruby.PrecompileAssets != nil {
+		return *sc.Ruby.PrecompileAssets
+	}
+
+	return isRailsApp(sc)
+}
+
+type rubyProject struct {
+	env *environment.Environment
+	cli bundler.BundlerCli
+}
+
+// NewRubyProject creates a new instance of the Ruby project, which installs the gems of the service with bundler
+func NewRubyProject(env *environment.Environment, cli bundler.BundlerCli) FrameworkService {
+	return &rubyProject{
+		env: env,
+		cli: cli,
+	}
+}
+
+// Gets the required external tools for the project
+func (rp *rubyProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{rp.cli}
+}
+
+// Initializes the Ruby project
+func (rp *rubyProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the gems of the project with bundle install
+func (rp *rubyProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			if !fileExists(filepath.Join(serviceConfig.Path(), "Gemfile")) {
+				log.Printf("skipping bundle install for service %s, the service has no Gemfile", serviceConfig.Name)
+				task.SetResult(&ServiceRestoreResult{})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Installing gems"))
+			if err := rp.cli.Install(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(fmt.Errorf("restoring gems for service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the project, precompiling the assets of Rails apps with rails assets:precompile
+func (rp *rubyProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			if serviceConfig.PrecompilesAssets() {
+				envs, err := serviceConfig.Environ(rp.env)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				task.SetProgress(NewServiceProgress("Precompiling assets"))
+				_, err = rp.cli.Exec(
+					ctx, serviceConfig.Path(), append(envs, railsBuildEnv...), "rails", "assets:precompile")
+				if err != nil {
+					task.SetError(fmt.Errorf("precompiling assets of service %s: %w", serviceConfig.Name, err))
+					return
+				}
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: customOutputPath(serviceConfig),
+			})
+		},
+	)
+}
+
+// Tests the project with rspec when the project has a spec directory, otherwise with the test task of Rails or rake when
+// the project has a test directory
+func (rp *rubyProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			var args []string
+			switch {
+			case dirExists(filepath.Join(serviceConfig.Path(), "spec")):
+				args = []string{"rspec"}
+			case dirExists(filepath.Join(serviceConfig.Path(), "test")) && isRailsApp(serviceConfig):
+				args = []string{"rails", "test"}
+			case dirExists(filepath.Join(serviceConfig.Path(), "test")) &&
+				fileExists(filepath.Join(serviceConfig.Path(), "Rakefile")):
+				args = []string{"rake", "test"}
+			default:
+				log.Printf("skipping tests of service %s, the project has no spec or test directory", serviceConfig.Name)
+				task.SetResult(&ServiceTestResult{Skipped: true})
+				return
+			}
+
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Running %s", args[0])))
+			if _, err := rp.cli.Exec(ctx, serviceConfig.Path(), nil, args...); err != nil {
+				task.SetError(fmt.Errorf("testing service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Packages the service for zip deploy, with the precompiled assets of Rails apps. The installed gems, logs and temporary
+// files aren't copied, the gems are installed by the build of the host, ex) the remote build of App Service.
+func (rp *rubyProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			publishSource := customOutputPath(serviceConfig)
+			if buildOutput != nil && buildOutput.BuildOutputPath != "" {
+				publishSource = buildOutput.BuildOutputPath
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForZip(
+				publishSource,
+				publishRoot,
+				buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{excludeRubyLocalDirs(publishSource)},
+					ignoreFile:        packageIgnoreFile(serviceConfig),
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Excludes the directories of the project restored or generated locally, ex) the gems installed in vendor/bundle. The
+// other vendor directories, ex) vendor/javascript of Rails apps, are deployed.
+func excludeRubyLocalDirs(servicePath string) excludeDirEntryCondition {
+	excludedDirs := []string{}
+	for _, dir := range rubyLocalDirs {
+		excludedDirs = append(excludedDirs, filepath.Join(servicePath, dir))
+	}
+
+	return func(path string, file os.FileInfo) bool {
+		return file.IsDir() && slices.Contains(excludedDirs, filepath.Clean(path))
+	}
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
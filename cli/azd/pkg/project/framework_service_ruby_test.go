@@ -0,0 +1,147 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bundler"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// Creates a Rails service with installed gems and logs
+func createRailsTestService(t *testing.T) *ServiceConfig {
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageRuby)
+	for _, file := range []string{
+		"Gemfile",
+		"Gemfile.lock",
+		"config/application.rb",
+		"public/assets/application.css",
+		"vendor/bundle/ruby/3.3.0/gems/rails.rb",
+		"vendor/javascript/stimulus.js",
+		"log/development.log",
+	} {
+		path := filepath.Join(serviceConfig.Path(), filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte{}, osutil.PermissionFile))
+	}
+
+	return serviceConfig
+}
+
+func Test_RubyProject_Restore(t *testing.T) {
+	var installArgs []exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "bundle install")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		installArgs = append(installArgs, args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	rubyProject := NewRubyProject(environment.Ephemeral(), bundler.NewBundlerCli(mockContext.CommandRunner))
+
+	// Services without a Gemfile have no gems
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageRuby)
+	restoreTask := rubyProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	_, err := restoreTask.Await()
+	require.NoError(t, err)
+	require.Empty(t, installArgs)
+
+	serviceConfig = createRailsTestService(t)
+	restoreTask = rubyProject.Restore(*mockContext.Context, serviceConfig)
+	logProgress(restoreTask)
+	_, err = restoreTask.Await()
+	require.NoError(t, err)
+	require.Len(t, installArgs, 1)
+	require.Equal(t, serviceConfig.Path(), installArgs[0].Cwd)
+}
+
+func Test_RubyProject_Build(t *testing.T) {
+	build := func(t *testing.T, serviceConfig *ServiceConfig) []exec.RunArgs {
+		var execArgs []exec.RunArgs
+
+		mockContext := mocks.NewMockContext(context.Background())
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "bundle exec")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			execArgs = append(execArgs, args)
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		rubyProject := NewRubyProject(environment.Ephemeral(), bundler.NewBundlerCli(mockContext.CommandRunner))
+		buildTask := rubyProject.Build(*mockContext.Context, serviceConfig, nil)
+		logProgress(buildTask)
+
+		result, err := buildTask.Await()
+		require.NoError(t, err)
+		require.Equal(t, serviceConfig.Path(), result.BuildOutputPath)
+		return execArgs
+	}
+
+	t.Run("Rails", func(t *testing.T) {
+		execArgs := build(t, createRailsTestService(t))
+		require.Len(t, execArgs, 1)
+		require.Equal(t, []string{"exec", "rails", "assets:precompile"}, execArgs[0].Args)
+		require.Contains(t, execArgs[0].Env, "RAILS_ENV=production")
+		require.Contains(t, execArgs[0].Env, "SECRET_KEY_BASE_DUMMY=1")
+	})
+
+	t.Run("NoPrecompile", func(t *testing.T) {
+		serviceConfig := createRailsTestService(t)
+		precompile := false
+		serviceConfig.Ruby.PrecompileAssets = &precompile
+
+		require.Empty(t, build(t, serviceConfig))
+	})
+
+	t.Run("Rack", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguageRuby)
+		require.Empty(t, build(t, serviceConfig))
+	})
+}
+
+func Test_RubyProject_Package(t *testing.T) {
+	serviceConfig := createRailsTestService(t)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	rubyProject := NewRubyProject(environment.Ephemeral(), bundler.NewBundlerCli(mockContext.CommandRunner))
+	packageTask := rubyProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{BuildOutputPath: serviceConfig.Path()},
+	)
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(result.PackagePath) })
+
+	require.FileExists(t, filepath.Join(result.PackagePath, "Gemfile.lock"))
+	require.FileExists(t, filepath.Join(result.PackagePath, "public", "assets", "application.css"))
+	require.FileExists(t, filepath.Join(result.PackagePath, "vendor", "javascript", "stimulus.js"))
+	require.NoDirExists(t, filepath.Join(result.PackagePath, "vendor", "bundle"))
+	require.NoDirExists(t, filepath.Join(result.PackagePath, "log"))
+}
+
+func Test_RubyOptions_Validate(t *testing.T) {
+	_, err := Parse(context.Background(), "name: test-app\nservices:\n  api:\n    project: src/api\n    language: js\n"+
+		"    host: appservice\n    ruby:\n      precompileAssets: false\n")
+	require.ErrorContains(t, err, "'ruby' is only supported for services of language 'ruby'")
+
+	projectConfig, err := Parse(context.Background(), "name: test-app\nservices:\n  web:\n    project: src/web\n"+
+		"    language: ruby\n    host: appservice\n")
+	require.NoError(t, err)
+	require.Equal(t, ServiceLanguageRuby, projectConfig.Services["web"].Language)
+}
@@ -0,0 +1,252 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cargo"
+)
+
+// The directory of the artifacts of the builds of Rust services in the service directory, when the service doesn't
+// configure a dist path
+const defaultRustOutputPath = "target"
+
+// The name of the package of the Cargo.toml manifest, the default name of its binary
+var cargoPackageNameRegex = regexp.MustCompile(`(?ms)^\[package\][^\[]*?^name\s*=\s*["']([^"']+)["']`)
+
+// RustOptions are the options of the services of language rust
+type RustOptions struct {
+	// The binary of the package deployed, ex) api. Defaults to the name of the package of Cargo.toml. Function apps run
+	// the binary from the defaultExecutablePath of the custom handler of host.json.
+	Bin string `yaml:"bin"`
+	// The target triple of the build, ex) x86_64-unknown-linux-musl. Defaults to the platform of the host running azd,
+	// the target must match the platform of the Azure host of the service.
+	Target string `yaml:"target"`
+	// The features of the package enabled for the build
+	Features []string `yaml:"features"`
+}
+
+type rustProject struct {
+	env *environment.Environment
+	cli cargo.CargoCli
+}
+
+// NewRustProject creates a new instance of the Rust project, which builds the service with cargo
+func NewRustProject(cli cargo.CargoCli, env *environment.Environment) FrameworkService {
+	return &rustProject{
+		env: env,
+		cli: cli,
+	}
+}
+
+// Gets the required external tools for the project
+func (rp *rustProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{rp.cli}
+}
+
+// Initializes the Rust project
+func (rp *rustProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restores the project dependencies with cargo fetch
+func (rp *rustProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			if !fileExists(filepath.Join(serviceConfig.Path(), "Cargo.toml")) {
+				task.SetError(fmt.Errorf("restoring service %s: the service has no Cargo.toml", serviceConfig.Name))
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Fetching cargo dependencies"))
+			if err := rp.cli.Fetch(ctx, serviceConfig.Path()); err != nil {
+				task.SetError(fmt.Errorf("restoring cargo dependencies for service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Builds the binary of the service with cargo build --release. The build output is the path of the binary.
+func (rp *rustProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			options := serviceConfig.Rust
+			bin, err := rustBinName(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			targetDir := rustTargetDir(serviceConfig)
+
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Building %s", bin)))
+			err = rp.cli.Build(ctx, serviceConfig.Path(), cargo.BuildOptions{
+				Bin:       bin,
+				Target:    options.Target,
+				TargetDir: targetDir,
+				Features:  options.Features,
+			})
+			if err != nil {
+				task.SetError(fmt.Errorf("building service %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			// Binaries of targets are in a directory of the target, ex) target/x86_64-unknown-linux-musl/release
+			binaryPath := filepath.Join(targetDir, options.Target, "release", bin)
+			if strings.Contains(options.Target, "windows") || (options.Target == "" && runtime.GOOS == "windows") {
+				binaryPath += ".exe"
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: binaryPath,
+			})
+		},
+	)
+}
+
+// Computes the digest of the sources of the package, without the target directory of its builds
+func (rp *rustProject) BuildDigest(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	targetDir, err := filepath.Rel(serviceConfig.Path(), rustTargetDir(serviceConfig))
+	if err != nil {
+		return "", err
+	}
+
+	return sourceDigest(serviceConfig, []string{targetDir})
+}
+
+// Checks whether the binary of the last build still exists
+func (rp *rustProject) BuildOutputExists(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) bool {
+	return buildOutputPathExists(buildOutput)
+}
+
+// Gets the version of the Rust compiler building the package
+func (rp *rustProject) ToolchainVersion(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	return rp.cli.Version(ctx, serviceConfig.Path())
+}
+
+// Tests the package with cargo test, with the features of the build enabled
+func (rp *rustProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Running cargo tests"))
+			if err := rp.cli.Test(ctx, serviceConfig.Path(), serviceConfig.Rust.Features); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetResult(&ServiceTestResult{})
+		},
+	)
+}
+
+// Packages the binary with the files of the service which aren't Rust sources, ex) the host.json and function.json files
+// of function apps, with the binary at the root of the package.
+func (rp *rustProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			targetDir := filepath.Clean(rustTargetDir(serviceConfig))
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForBinary(
+				serviceConfig.Path(),
+				publishRoot,
+				buildOutput.BuildOutputPath,
+				buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{
+						excludeRustSources,
+						func(path string, file os.FileInfo) bool {
+							return file.IsDir() && filepath.Clean(path) == targetDir
+						},
+					},
+					ignoreFile: packageIgnoreFile(serviceConfig),
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Gets the directory of the artifacts of the builds of the service
+func rustTargetDir(serviceConfig *ServiceConfig) string {
+	outputPath := serviceConfig.OutputPath
+	if outputPath == "" {
+		outputPath = defaultRustOutputPath
+	}
+
+	return filepath.Join(serviceConfig.Path(), outputPath)
+}
+
+// Gets the binary of the service, configured for the service or the name of the package of Cargo.toml
+func rustBinName(serviceConfig *ServiceConfig) (string, error) {
+	if bin := serviceConfig.Rust.Bin; bin != "" {
+		return bin, nil
+	}
+
+	manifest, err := os.ReadFile(filepath.Join(serviceConfig.Path(), "Cargo.toml"))
+	if err != nil {
+		return "", fmt.Errorf("reading Cargo.toml: %w", err)
+	}
+
+	match := cargoPackageNameRegex.FindSubmatch(manifest)
+	if match == nil {
+		return "", errors.New("the Cargo.toml of the service has no package name, set the bin of the service")
+	}
+
+	return string(match[1]), nil
+}
+
+// Excludes the sources and manifests of Rust packages, which aren't required by the binary
+func excludeRustSources(path string, file os.FileInfo) bool {
+	if file.IsDir() {
+		return false
+	}
+
+	name := file.Name()
+	return filepath.Ext(name) == ".rs" || name == "Cargo.toml" || name == "Cargo.lock"
+}
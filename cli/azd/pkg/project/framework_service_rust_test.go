@@ -0,0 +1,125 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cargo"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testCargoManifest = `[package]
+name = "todo-api"
+version = "0.1.0"
+edition = "2021"
+
+[dependencies]
+name = "not-the-package"
+`
+
+func writeRustService(t *testing.T, files map[string]string) string {
+	servicePath := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(servicePath, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	return servicePath
+}
+
+func Test_RustProject_Build(t *testing.T) {
+	var buildArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "cargo build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		buildArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	servicePath := writeRustService(t, map[string]string{"Cargo.toml": testCargoManifest})
+	serviceConfig := createTestServiceConfig(servicePath, AppServiceTarget, ServiceLanguageRust)
+	serviceConfig.Project.Path = ""
+	serviceConfig.Rust.Target = "x86_64-unknown-linux-musl"
+
+	rustProject := NewRustProject(cargo.NewCargoCli(mockContext.CommandRunner), environment.Ephemeral())
+	buildTask := rustProject.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+
+	result, err := buildTask.Await()
+	require.NoError(t, err)
+
+	targetDir := filepath.Join(servicePath, "target")
+	require.Equal(t, filepath.Join(targetDir, "x86_64-unknown-linux-musl", "release", "todo-api"), result.BuildOutputPath)
+	require.Equal(t, servicePath, buildArgs.Cwd)
+	require.Equal(t, []string{
+		"build", "--release",
+		"--bin", "todo-api",
+		"--target", "x86_64-unknown-linux-musl",
+		"--target-dir", targetDir,
+	}, buildArgs.Args)
+}
+
+func Test_RustProject_Package(t *testing.T) {
+	servicePath := writeRustService(t, map[string]string{
+		"Cargo.toml":                     testCargoManifest,
+		"Cargo.lock":                     "",
+		"src/main.rs":                    "fn main() {}",
+		"host.json":                      "{}",
+		"hello/function.json":            "{}",
+		"target/release/todo-api":        "binary",
+		"target/release/deps/todo_api.d": "",
+		"target/debug/todo-api":          "debug binary",
+	})
+
+	mockContext := mocks.NewMockContext(context.Background())
+	serviceConfig := createTestServiceConfig(servicePath, AzureFunctionTarget, ServiceLanguageRust)
+	serviceConfig.Project.Path = ""
+
+	rustProject := NewRustProject(cargo.NewCargoCli(mockContext.CommandRunner), environment.Ephemeral())
+	packageTask := rustProject.Package(*mockContext.Context, serviceConfig, &ServiceBuildResult{
+		BuildOutputPath: filepath.Join(servicePath, "target", "release", "todo-api"),
+	})
+	logProgress(packageTask)
+
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(result.PackagePath) })
+
+	files := []string{}
+	err = filepath.Walk(result.PackagePath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(result.PackagePath, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"todo-api", "host.json", "hello/function.json"}, files)
+}
+
+func Test_RustBinName(t *testing.T) {
+	servicePath := writeRustService(t, map[string]string{"Cargo.toml": "[workspace]\nmembers = [\"api\"]\n"})
+	serviceConfig := createTestServiceConfig(servicePath, AppServiceTarget, ServiceLanguageRust)
+	serviceConfig.Project.Path = ""
+
+	_, err := rustBinName(serviceConfig)
+	require.ErrorContains(t, err, "no package name")
+
+	serviceConfig.Rust.Bin = "api"
+	bin, err := rustBinName(serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, "api", bin)
+}
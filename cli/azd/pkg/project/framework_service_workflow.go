@@ -0,0 +1,121 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+type workflowProject struct{}
+
+// NewWorkflowProject creates a new instance of the framework service used for Logic Apps Standard
+// workflow projects. Workflow projects are made of JSON definitions and do not require a build.
+func NewWorkflowProject() FrameworkService {
+	return &workflowProject{}
+}
+
+// Workflow projects do not require any external tools
+func (wp *workflowProject) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+// Initializes the workflow project
+func (wp *workflowProject) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Restore for workflow projects performs a no-op
+func (wp *workflowProject) Restore(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceRestoreResult, ServiceProgress]) {
+			task.SetResult(&ServiceRestoreResult{})
+		},
+	)
+}
+
+// Build for workflow projects performs a no-op and returns the service path with an optional output path
+func (wp *workflowProject) Build(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+			publishSource := serviceConfig.Path()
+
+			if serviceConfig.OutputPath != "" {
+				publishSource = filepath.Join(publishSource, serviceConfig.OutputPath)
+			}
+
+			task.SetResult(&ServiceBuildResult{
+				Restore:         restoreOutput,
+				BuildOutputPath: publishSource,
+			})
+		},
+	)
+}
+
+// Workflow projects don't have tests
+func (wp *workflowProject) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	return skipTests(serviceConfig, "workflow projects don't have tests")
+}
+
+// Copies the workflow definitions into a staging folder, excluding local development artifacts
+func (wp *workflowProject) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	buildOutput *ServiceBuildResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			publishRoot, err := os.MkdirTemp("", "azd")
+			if err != nil {
+				task.SetError(fmt.Errorf("creating package directory for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Copying deployment package"))
+			if err := buildForZip(
+				buildOutput.BuildOutputPath,
+				publishRoot,
+				buildForZipOptions{
+					excludeConditions: []excludeDirEntryCondition{
+						excludeWorkflowLocalArtifacts,
+					},
+				}); err != nil {
+				task.SetError(fmt.Errorf("publishing for %s: %w", serviceConfig.Name, err))
+				return
+			}
+
+			task.SetResult(&ServicePackageResult{
+				Build:       buildOutput,
+				PackagePath: publishRoot,
+			})
+		},
+	)
+}
+
+// Excludes the files only used while running workflows locally
+func excludeWorkflowLocalArtifacts(path string, file os.FileInfo) bool {
+	name := strings.ToLower(file.Name())
+
+	if file.IsDir() {
+		return name == ".vscode" || name == "workflow-designtime" || strings.HasPrefix(name, "__azurite")
+	}
+
+	return name == "local.settings.json" || strings.HasPrefix(name, "__azurite")
+}
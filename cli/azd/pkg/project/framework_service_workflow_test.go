@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WorkflowProject_Package(t *testing.T) {
+	tempDir := t.TempDir()
+	files := []string{
+		"host.json",
+		"connections.json",
+		"local.settings.json",
+		filepath.Join("orders", "workflow.json"),
+		filepath.Join("workflow-designtime", "host.json"),
+		filepath.Join(".vscode", "settings.json"),
+	}
+
+	for _, file := range files {
+		filePath := filepath.Join(tempDir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(filePath, []byte("{}"), osutil.PermissionFile))
+	}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	serviceConfig := createTestServiceConfig(tempDir, LogicAppTarget, ServiceLanguageWorkflow)
+	workflowProject := NewWorkflowProject()
+
+	buildTask := workflowProject.Build(*mockContext.Context, serviceConfig, &ServiceRestoreResult{})
+	logProgress(buildTask)
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+
+	packageTask := workflowProject.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+	defer os.RemoveAll(packageResult.PackagePath)
+
+	require.FileExists(t, filepath.Join(packageResult.PackagePath, "host.json"))
+	require.FileExists(t, filepath.Join(packageResult.PackagePath, "connections.json"))
+	require.FileExists(t, filepath.Join(packageResult.PackagePath, "orders", "workflow.json"))
+	require.NoFileExists(t, filepath.Join(packageResult.PackagePath, "local.settings.json"))
+	require.NoDirExists(t, filepath.Join(packageResult.PackagePath, "workflow-designtime"))
+	require.NoDirExists(t, filepath.Join(packageResult.PackagePath, ".vscode"))
+}
+
+func Test_WorkflowProject_Parse(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  orders:
+    project: src/orders
+    host: logicapp
+  functions:
+    project: src/functions
+    language: dotnet
+    host: logicapp
+`
+	mockContext := mocks.NewMockContext(context.Background())
+	_ = mockContext.Container.RegisterNamedSingleton(string(ServiceLanguageWorkflow), NewWorkflowProject)
+	_ = mockContext.Container.RegisterNamedSingleton(string(ServiceLanguageDotNet), newFakeFramework)
+
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+
+	// Logic Apps without a language are workflow projects, not .NET projects
+	orders := projectConfig.Services["orders"]
+	require.Empty(t, orders.Language)
+
+	sm := createServiceManager(mockContext, environment.Ephemeral())
+	framework, err := sm.GetFrameworkService(*mockContext.Context, orders)
+	require.NoError(t, err)
+	require.IsType(t, new(workflowProject), framework)
+
+	framework, err = sm.GetFrameworkService(*mockContext.Context, projectConfig.Services["functions"])
+	require.NoError(t, err)
+	require.IsType(t, new(fakeFramework), framework)
+}
@@ -0,0 +1,359 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+const (
+	// The file listing the files excluded from the docker build context, at the root of the build context
+	dockerIgnoreFileName = ".dockerignore"
+	// The file listing the files excluded from the packages of function apps, at the root of the service
+	funcIgnoreFileName = ".funcignore"
+
+	// The default size of a docker build context above which a warning is shown, in megabytes.
+	// Can be overridden with the AZD_BUILD_CONTEXT_WARNING_SIZE_MB environment variable.
+	defaultBuildContextWarningSizeMb = 100
+)
+
+// The files that are never meant to be deployed, regardless of the language of the service
+var commonIgnorePatterns = []string{
+	".git",
+	".gitignore",
+	".azure",
+	".vscode",
+	".idea",
+	".env",
+	"**/.DS_Store",
+	"**/*.log",
+}
+
+// The files generated or restored locally for each language, which are restored or built again when the service is
+// built, ex) in a stage of a multi-stage Dockerfile, or by the Azure service. The outputs of the builds, ex) target, build
+// or bin, aren't excluded since Dockerfiles commonly copy the artifacts built outside of docker.
+var languageIgnorePatterns = map[ServiceLanguageKind][]string{
+	ServiceLanguageJavaScript: {"**/node_modules", "**/coverage", "**/.npm"},
+	ServiceLanguageTypeScript: {"**/node_modules", "**/coverage", "**/.npm"},
+	ServiceLanguagePython:     {"**/__pycache__", "**/*.pyc", ".venv", "venv", "**/.pytest_cache", "**/.mypy_cache"},
+	ServiceLanguageDotNet:     {"**/obj"},
+	ServiceLanguageCsharp:     {"**/obj"},
+	ServiceLanguageFsharp:     {"**/obj"},
+	ServiceLanguageJava:       {"**/.gradle"},
+	ServiceLanguageGo:         {"**/*.test"},
+	ServiceLanguagePhp:        {"vendor", "var/cache", ".phpunit.cache", "**/.phpunit.result.cache"},
+	ServiceLanguageRuby:       {"vendor/bundle", ".bundle", "log", "tmp", "**/node_modules", "public/assets"},
+}
+
+// The files used to run function apps locally, which aren't meant to be deployed
+var funcIgnorePatterns = []string{
+	"local.settings.json",
+	"__azurite_db*__.json",
+	"__blobstorage__",
+	"__queuestorage__",
+	"test",
+	"tests",
+}
+
+// The directories that are almost never meant to be part of a docker build context, since they're large and restored
+// or generated again by the build
+var unintendedContextDirs = []string{".git", "node_modules", ".venv", "venv", "__pycache__"}
+
+// WriteIgnoreFiles writes the ignore files of the service that don't exist yet, with the files of the language of
+// the service that aren't meant to be deployed. A .dockerignore file is written at the root of the docker build context
+// of services deployed as container images, and a .funcignore file at the root of function apps.
+// Called when the project is initialized, the files of the services are never written when they're built or deployed.
+// Returns the paths of the written files.
+func WriteIgnoreFiles(serviceConfig *ServiceConfig) ([]string, error) {
+	written := []string{}
+	write := func(ignoreFilePath string, patterns []string) error {
+		if _, err := os.Stat(ignoreFilePath); err == nil {
+			return nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		// The root of the build context may not exist yet, ex) when the service isn't scaffolded
+		if _, err := os.Stat(filepath.Dir(ignoreFilePath)); err != nil {
+			return nil
+		}
+
+		contents := fmt.Sprintf(
+			"%s, lists the files that aren't deployed\n%s\n",
+			generatedFileHeader,
+			strings.Join(patterns, "\n"),
+		)
+		if err := os.WriteFile(ignoreFilePath, []byte(contents), osutil.PermissionFile); err != nil {
+			return fmt.Errorf("writing %s: %w", filepath.Base(ignoreFilePath), err)
+		}
+
+		written = append(written, ignoreFilePath)
+		return nil
+	}
+
+	patterns := append(append([]string{}, commonIgnorePatterns...), languageIgnorePatterns[serviceConfig.Language]...)
+
+	if serviceConfig.Language == ServiceLanguageDocker || serviceConfig.Host.RequiresContainer() {
+		contextPath := buildContextPath(serviceConfig)
+		if err := write(filepath.Join(contextPath, dockerIgnoreFileName), patterns); err != nil {
+			return written, err
+		}
+	}
+
+	if serviceConfig.Host == AzureFunctionTarget {
+		funcPatterns := append(append([]string{}, patterns...), funcIgnorePatterns...)
+		if err := write(filepath.Join(serviceConfig.Path(), funcIgnoreFileName), funcPatterns); err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// Gets the path of the docker build context of the service
+func buildContextPath(serviceConfig *ServiceConfig) string {
+	contextPath := getDockerOptionsWithDefaults(serviceConfig.Docker).Context
+	if filepath.IsAbs(contextPath) {
+		return contextPath
+	}
+
+	return filepath.Join(serviceConfig.Path(), contextPath)
+}
+
+// Gets the ignore file applied to the zip package of the service, or an empty string when the package doesn't exclude
+// the files of an ignore file
+func packageIgnoreFile(serviceConfig *ServiceConfig) string {
+	if serviceConfig.Host == AzureFunctionTarget {
+		return funcIgnoreFileName
+	}
+
+	return ""
+}
+
+// A pattern of an ignore file
+type ignoreRule struct {
+	pattern string
+	// Whether the pattern is negated with a leading !, which includes files excluded by previous patterns
+	negate bool
+	// Whether the pattern only matches directories, with a trailing /
+	dirOnly bool
+	// Whether the pattern matches files in any directory, ex) **/node_modules
+	anyDir bool
+}
+
+// The patterns of an ignore file, matched in order so the last matching pattern applies
+type ignoreRules []ignoreRule
+
+// Reads the patterns of the ignore file. Patterns without a leading **/ match paths relative to the root of the ignore
+// file, as in .dockerignore files. When matchBaseNames is set, patterns without a / also match files in any directory,
+// as in .gitignore and .funcignore files. Returns no rules when the file doesn't exist.
+func readIgnoreFile(ignoreFilePath string, matchBaseNames bool) (ignoreRules, error) {
+	file, err := os.Open(ignoreFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules := ignoreRules{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+		if negated, has := strings.CutPrefix(line, "!"); has {
+			rule.negate = true
+			line = negated
+		}
+
+		line = filepath.ToSlash(line)
+		if trimmed, has := strings.CutSuffix(line, "/"); has {
+			rule.dirOnly = true
+			line = trimmed
+		}
+
+		line = strings.TrimPrefix(strings.TrimPrefix(line, "./"), "/")
+		if trimmed, has := strings.CutPrefix(line, "**/"); has {
+			rule.anyDir = true
+			line = trimmed
+		} else if matchBaseNames && !strings.Contains(line, "/") {
+			rule.anyDir = true
+		}
+
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
+
+// Checks whether the path, relative to the root of the ignore file, is excluded by the patterns
+func (rules ignoreRules) excludes(relativePath string, isDir bool) bool {
+	relativePath = filepath.ToSlash(relativePath)
+	excluded := false
+	for _, rule := range rules {
+		if rule.matches(relativePath, isDir) {
+			excluded = !rule.negate
+		}
+	}
+
+	return excluded
+}
+
+// Checks whether the pattern matches the slash separated path, relative to the root of the ignore file
+func (rule ignoreRule) matches(relativePath string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+
+	name := relativePath
+	if rule.anyDir {
+		name = path.Base(relativePath)
+	}
+
+	matched, _ := path.Match(rule.pattern, name)
+	return matched
+}
+
+// Checks whether the path, relative to the root of the ignore file, or one of its parent directories is excluded by the
+// patterns, the way docker matches the files of a build context. The last pattern matching the path or a parent applies,
+// so a negated pattern can include a file of an excluded directory.
+func (rules ignoreRules) excludesPathOrParent(relativePath string, isDir bool) bool {
+	relativePath = filepath.ToSlash(relativePath)
+	excluded := false
+	for _, rule := range rules {
+		matchPath := relativePath
+		matchIsDir := isDir
+		for matchPath != "." && matchPath != "/" && matchPath != "" {
+			if rule.matches(matchPath, matchIsDir) {
+				excluded = !rule.negate
+				break
+			}
+
+			matchPath = path.Dir(matchPath)
+			matchIsDir = true
+		}
+	}
+
+	return excluded
+}
+
+// Whether any of the patterns is negated, in which case files of excluded directories may still be included
+func (rules ignoreRules) hasNegation() bool {
+	for _, rule := range rules {
+		if rule.negate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Creates the condition excluding the files under the root that are excluded by the patterns
+func (rules ignoreRules) excludeCondition(root string) excludeDirEntryCondition {
+	return func(filePath string, file os.FileInfo) bool {
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil || relativePath == "." {
+			return false
+		}
+
+		return rules.excludes(relativePath, file.IsDir())
+	}
+}
+
+// Checks the docker build context of the service for directories that aren't meant to be part of it and for its size,
+// since large build contexts slow down every build. Returns the warnings to show, if any.
+func validateBuildContext(serviceConfig *ServiceConfig) ([]string, error) {
+	contextPath := buildContextPath(serviceConfig)
+	rules, err := readIgnoreFile(filepath.Join(contextPath, dockerIgnoreFileName), false)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dockerIgnoreFileName, err)
+	}
+
+	var size int64
+	unintendedDirs := []string{}
+	err = filepath.WalkDir(contextPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(contextPath, filePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+
+		if rules.excludes(relativePath, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() {
+			for _, dir := range unintendedContextDirs {
+				if entry.Name() == dir {
+					unintendedDirs = append(unintendedDirs, filepath.ToSlash(relativePath))
+					return filepath.SkipDir
+				}
+			}
+
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking build context: %w", err)
+	}
+
+	warnings := []string{}
+	if len(unintendedDirs) > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"The build context of service '%s' includes '%s', add them to %s unless the image requires them",
+			serviceConfig.Name,
+			strings.Join(unintendedDirs, "', '"),
+			filepath.Join(contextPath, dockerIgnoreFileName),
+		))
+	}
+
+	warningSizeMb := int64(defaultBuildContextWarningSizeMb)
+	if value := os.Getenv("AZD_BUILD_CONTEXT_WARNING_SIZE_MB"); value != "" {
+		if val, err := strconv.ParseInt(value, 10, 64); err == nil && val > 0 {
+			warningSizeMb = val
+		}
+	}
+
+	if size > warningSizeMb*1024*1024 {
+		warnings = append(warnings, fmt.Sprintf(
+			"The build context of service '%s' is %d MB, exclude the files the image doesn't require with %s "+
+				"to speed up builds",
+			serviceConfig.Name,
+			size/(1024*1024),
+			filepath.Join(contextPath, dockerIgnoreFileName),
+		))
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteIgnoreFiles(t *testing.T) {
+	t.Run("Container", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+
+		written, err := WriteIgnoreFiles(serviceConfig)
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(serviceConfig.Path(), ".dockerignore")}, written)
+
+		contents, err := os.ReadFile(written[0])
+		require.NoError(t, err)
+		require.Contains(t, string(contents), "**/node_modules\n")
+		require.Contains(t, string(contents), ".git\n")
+
+		// Existing ignore files are kept as-is
+		require.NoError(t, os.WriteFile(written[0], []byte("custom\n"), 0600))
+		written, err = WriteIgnoreFiles(serviceConfig)
+		require.NoError(t, err)
+		require.Empty(t, written)
+
+		contents, err = os.ReadFile(filepath.Join(serviceConfig.Path(), ".dockerignore"))
+		require.NoError(t, err)
+		require.Equal(t, "custom\n", string(contents))
+	})
+
+	t.Run("FunctionApp", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), AzureFunctionTarget, ServiceLanguagePython)
+
+		written, err := WriteIgnoreFiles(serviceConfig)
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(serviceConfig.Path(), ".funcignore")}, written)
+
+		rules, err := readIgnoreFile(written[0], true)
+		require.NoError(t, err)
+		require.True(t, rules.excludes("local.settings.json", false))
+		require.True(t, rules.excludes("api/__pycache__", true))
+		require.False(t, rules.excludes("function_app.py", false))
+	})
+
+	t.Run("BuildOutputs", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJava)
+
+		written, err := WriteIgnoreFiles(serviceConfig)
+		require.NoError(t, err)
+		require.Len(t, written, 1)
+
+		// Dockerfiles commonly copy the artifacts built outside of docker
+		rules, err := readIgnoreFile(written[0], false)
+		require.NoError(t, err)
+		require.False(t, rules.excludes("target", true))
+		require.False(t, rules.excludes("build", true))
+		require.True(t, rules.excludes(".gradle", true))
+	})
+}
+
+func Test_IgnoreRules(t *testing.T) {
+	ignoreFilePath := filepath.Join(t.TempDir(), ".dockerignore")
+	require.NoError(t, os.WriteFile(ignoreFilePath, []byte(`
+# comment
+node_modules
+**/*.log
+build/
+*.md
+!README.md
+`), 0600))
+
+	rules, err := readIgnoreFile(ignoreFilePath, false)
+	require.NoError(t, err)
+
+	require.True(t, rules.excludes("node_modules", true))
+	require.True(t, rules.excludes("logs/app.log", false))
+	require.True(t, rules.excludes("build", true))
+	require.True(t, rules.excludes("CHANGELOG.md", false))
+
+	// Patterns of .dockerignore files are relative to the root of the build context
+	require.False(t, rules.excludes("web/node_modules", true))
+	require.False(t, rules.excludes("build", false))
+	require.False(t, rules.excludes("README.md", false))
+
+	rules, err = readIgnoreFile(filepath.Join(t.TempDir(), ".dockerignore"), false)
+	require.NoError(t, err)
+	require.False(t, rules.excludes("node_modules", true))
+}
+
+func Test_DockerIgnoreExclude(t *testing.T) {
+	contextPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextPath, ".dockerignore"), []byte(`
+node_modules
+docker
+.dockerignore
+secrets/
+!secrets/public.pem
+`), 0600))
+
+	exclude, err := dockerIgnoreExclude(contextPath, "docker/Dockerfile")
+	require.NoError(t, err)
+
+	// Files of excluded directories are excluded as well
+	require.True(t, exclude("node_modules/express/index.js", false))
+	require.True(t, exclude("secrets/private.pem", false))
+	require.False(t, exclude("secrets/public.pem", false))
+	require.False(t, exclude("src/index.js", false))
+
+	// The Dockerfile and the .dockerignore file are always part of the build context
+	require.False(t, exclude("docker/Dockerfile", false))
+	require.False(t, exclude(".dockerignore", false))
+
+	// Excluded directories aren't skipped as a whole when a negated pattern may include their files back
+	require.False(t, exclude("node_modules", true))
+
+	require.NoError(t, os.WriteFile(filepath.Join(contextPath, ".dockerignore"), []byte("node_modules\ndocker\n"), 0600))
+	exclude, err = dockerIgnoreExclude(contextPath, "docker/Dockerfile")
+	require.NoError(t, err)
+	require.True(t, exclude("node_modules", true))
+	require.False(t, exclude("docker", true))
+
+	exclude, err = dockerIgnoreExclude(t.TempDir(), "Dockerfile")
+	require.NoError(t, err)
+	require.Nil(t, exclude)
+}
+
+func Test_ValidateBuildContext(t *testing.T) {
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguageJavaScript)
+	require.NoError(t, os.MkdirAll(filepath.Join(serviceConfig.Path(), "node_modules", "express"), 0700))
+	require.NoError(t, os.MkdirAll(filepath.Join(serviceConfig.Path(), "dist"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceConfig.Path(), "dist", "bundle.js"), make([]byte, 2*1024*1024), 0600))
+
+	t.Setenv("AZD_BUILD_CONTEXT_WARNING_SIZE_MB", "1")
+	warnings, err := validateBuildContext(serviceConfig)
+	require.NoError(t, err)
+	require.Len(t, warnings, 2)
+	require.Contains(t, warnings[0], "includes 'node_modules'")
+	require.Contains(t, warnings[1], "is 2 MB")
+
+	// Excluded files aren't part of the build context
+	ignoreFile := filepath.Join(serviceConfig.Path(), ".dockerignore")
+	require.NoError(t, os.WriteFile(ignoreFile, []byte("node_modules\ndist\n"), 0600))
+	warnings, err = validateBuildContext(serviceConfig)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+}
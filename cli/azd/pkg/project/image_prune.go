@@ -0,0 +1,240 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"golang.org/x/exp/slices"
+)
+
+// The prefix of the tags of the images of deployments, when the tag of the image isn't configured
+const deployTagPrefix = "azd-deploy-"
+
+// DefaultImagePruneKeep is the number of the most recent deployments whose images are kept by default when images are
+// pruned
+const DefaultImagePruneKeep = 5
+
+// ErrImagePruneNotSupported is returned when the images of a service can't be pruned, ex) when its tag is configured
+var ErrImagePruneNotSupported = errors.New("pruning images isn't supported")
+
+// Matches the tags of deployments, ex) azd-deploy-1700000000 or azd-deploy-1700000000-debug for a variant
+var deployTagRegex = regexp.MustCompile("^" + deployTagPrefix + `(\d+)(-.+)?$`)
+
+// PrunedImage is an image of an old deployment deleted from the container registry, or that would be deleted in a dry
+// run. The image is deleted with all its tags.
+type PrunedImage struct {
+	Service    string   `json:"service"`
+	Registry   string   `json:"registry"`
+	Repository string   `json:"repository"`
+	Digest     string   `json:"digest"`
+	Tags       []string `json:"tags"`
+}
+
+// ImagePruner deletes the images of old deployments of the services from the container registry of the environment, so
+// the registry doesn't grow with every deployment.
+type ImagePruner struct {
+	env                      *environment.Environment
+	changeTracker            *ChangeTracker
+	containerRegistryService azcli.ContainerRegistryService
+}
+
+// Creates a new instance of the ImagePruner
+func NewImagePruner(
+	env *environment.Environment,
+	changeTracker *ChangeTracker,
+	containerRegistryService azcli.ContainerRegistryService,
+) *ImagePruner {
+	return &ImagePruner{
+		env:                      env,
+		changeTracker:            changeTracker,
+		containerRegistryService: containerRegistryService,
+	}
+}
+
+// Find gets the images of the service tagged by deployments older than the most recent successful deployments to keep.
+// The deployments are ordered by the timestamp of their tag, and the deployments of each variant of the service are kept
+// separately. Only the successful deployments recorded in the environment count as kept deployments, the images of
+// failed deployments are only found when they're older than the kept deployments. The image currently deployed, images
+// also tagged by a kept deployment and images tagged by other tags, ex) latest, are never found.
+func (p *ImagePruner) Find(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	keep int,
+) ([]*PrunedImage, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("the images of at least 1 deployment must be kept, got %d", keep)
+	}
+
+	if !serviceConfig.Host.RequiresContainer() {
+		return nil, fmt.Errorf("%w: service '%s' isn't deployed as a container", ErrImagePruneNotSupported, serviceConfig.Name)
+	}
+
+	if serviceConfig.Docker.Tag.template != "" {
+		return nil, fmt.Errorf(
+			"%w: service '%s' configures the tag of its image", ErrImagePruneNotSupported, serviceConfig.Name)
+	}
+
+	loginServer, isExternal, err := resolveRegistryEndpoint(p.env, serviceConfig.Docker.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	if isExternal {
+		return nil, fmt.Errorf(
+			"%w: service '%s' pushes its image to registry '%s'",
+			ErrImagePruneNotSupported,
+			serviceConfig.Name,
+			loginServer,
+		)
+	}
+
+	if loginServer == "" {
+		return nil, fmt.Errorf(
+			"could not determine container registry endpoint, ensure %s is set as an output of your infrastructure",
+			environment.ContainerRegistryEndpointEnvVarName,
+		)
+	}
+
+	subscriptionId := p.env.GetSubscriptionId()
+	repository := defaultImageRepository(serviceConfig, p.env.GetEnvName())
+	tags, err := p.containerRegistryService.ListTags(ctx, subscriptionId, loginServer, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	deployedImages, err := p.changeTracker.DeployedImages(serviceConfig.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The image currently deployed is set once the deployment succeeds, ex) by deployments before images were recorded
+	currentTag := imageReferenceTag(p.env.GetServiceProperty(serviceConfig.Name, "IMAGE_NAME"))
+	deployed := map[string]bool{}
+	if currentTag != "" {
+		deployed[currentTag] = true
+	}
+
+	for _, image := range deployedImages {
+		deployed[imageReferenceTag(image)] = true
+	}
+
+	pruned := pruneTags(tags, keep, deployed, currentTag)
+	images := []*PrunedImage{}
+	for _, tag := range tags {
+		if !pruned[tag.Name] {
+			continue
+		}
+
+		index := slices.IndexFunc(images, func(image *PrunedImage) bool { return image.Digest == tag.Digest })
+		if index == -1 {
+			images = append(images, &PrunedImage{
+				Service:    serviceConfig.Name,
+				Registry:   loginServer,
+				Repository: repository,
+				Digest:     tag.Digest,
+			})
+			index = len(images) - 1
+		}
+
+		images[index].Tags = append(images[index].Tags, tag.Name)
+	}
+
+	return images, nil
+}
+
+// Delete deletes the images found by Find from the container registry, with all their tags
+func (p *ImagePruner) Delete(ctx context.Context, images []*PrunedImage) error {
+	subscriptionId := p.env.GetSubscriptionId()
+	for _, image := range images {
+		log.Printf("deleting %s/%s@%s, tagged %v", image.Registry, image.Repository, image.Digest, image.Tags)
+		err := p.containerRegistryService.DeleteManifest(
+			ctx, subscriptionId, image.Registry, image.Repository, image.Digest)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Gets the tag of the image reference, ex) {registry}/app/api:azd-deploy-1700000000@sha256:... -> azd-deploy-1700000000.
+// Returns an empty string when the reference is empty.
+func imageReferenceTag(imageReference string) string {
+	if imageReference == "" {
+		return ""
+	}
+
+	imageReference, _, _ = strings.Cut(imageReference, "@")
+	_, tag := splitImageTag(imageReference, "")
+	return tag
+}
+
+// Selects the tags of the deployments older than the most recent successful deployments to keep, per variant. Only the
+// deployed tags count as kept deployments, the tags of other deployments, ex) failed deployments, are only selected when
+// they're older than the kept deployments, and nothing is selected for variants with fewer successful deployments than
+// the deployments to keep. The current tag and the tags referencing the same manifest as a tag that isn't pruned aren't
+// selected, since deleting the manifest deletes all its tags.
+func pruneTags(tags []*azcli.RegistryTag, keep int, deployed map[string]bool, currentTag string) map[string]bool {
+	type deployment struct {
+		tag       *azcli.RegistryTag
+		timestamp int64
+	}
+
+	variants := map[string][]deployment{}
+	for _, tag := range tags {
+		matches := deployTagRegex.FindStringSubmatch(tag.Name)
+		if matches == nil {
+			continue
+		}
+
+		timestamp, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		variants[matches[2]] = append(variants[matches[2]], deployment{tag: tag, timestamp: timestamp})
+	}
+
+	pruned := map[string]bool{}
+	for _, deployments := range variants {
+		sort.Slice(deployments, func(i, j int) bool {
+			return deployments[i].timestamp > deployments[j].timestamp
+		})
+
+		kept := 0
+		for _, deployment := range deployments {
+			if kept >= keep && deployment.tag.Name != currentTag {
+				pruned[deployment.tag.Name] = true
+			} else if deployed[deployment.tag.Name] {
+				kept++
+			}
+		}
+	}
+
+	kept := map[string]bool{}
+	for _, tag := range tags {
+		if !pruned[tag.Name] {
+			kept[tag.Digest] = true
+		}
+	}
+
+	for _, tag := range tags {
+		if pruned[tag.Name] && kept[tag.Digest] {
+			log.Printf("keeping tag %s, its image is also tagged by a kept tag", tag.Name)
+			delete(pruned, tag.Name)
+		}
+	}
+
+	return pruned
+}
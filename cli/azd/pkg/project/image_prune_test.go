@@ -0,0 +1,178 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImagePruner_Prune(t *testing.T) {
+	digests := map[string]string{
+		"azd-deploy-100":      "sha256:a",
+		"azd-deploy-200":      "sha256:b",
+		"azd-deploy-300":      "sha256:c",
+		"azd-deploy-400":      "sha256:d",
+		"latest":              "sha256:d",
+		"azd-deploy-150":      "sha256:c",
+		"azd-deploy-50-debug": "sha256:e",
+		"azd-deploy-60-debug": "sha256:f",
+	}
+
+	setup := func(t *testing.T) (*mocks.MockContext, *ImagePruner, *[]string) {
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocksForBatchJobTarget(mockContext)
+
+		mockContext.HttpClient.WhenPath(http.MethodGet, "/v2/test-app/api-test/tags/list").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				// The tags are listed in two pages
+				if request.URL.Query().Get("last") == "" {
+					response, err := mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+						"tags": []string{"azd-deploy-100", "azd-deploy-200", "azd-deploy-300", "azd-deploy-400"},
+					})
+					response.Header.Set("Link", `</v2/test-app/api-test/tags/list?last=azd-deploy-400&n=4>; rel="next"`)
+					return response, err
+				}
+
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+					"tags": []string{"latest", "azd-deploy-150", "azd-deploy-50-debug", "azd-deploy-60-debug"},
+				})
+			})
+
+		mockContext.HttpClient.WhenPath(http.MethodHead, "/v2/test-app/api-test/manifests/*").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				tag := request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:]
+				response, err := mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+				response.Header.Set("Docker-Content-Digest", digests[tag])
+				return response, err
+			})
+
+		deleted := []string{}
+		mockContext.HttpClient.WhenPath(http.MethodDelete, "/v2/test-app/api-test/manifests/*").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				deleted = append(deleted, request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:])
+				return mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+			})
+
+		credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+			func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+				return mockContext.Credentials, nil
+			})
+
+		env := environment.EphemeralWithValues("test", map[string]string{
+			environment.SubscriptionIdEnvVarName:            "SUBSCRIPTION_ID",
+			environment.ContainerRegistryEndpointEnvVarName: "REGISTRY.azurecr.io",
+		})
+		env.Root = t.TempDir()
+		changeTracker := NewChangeTracker(env, git.NewGitCli(mockContext.CommandRunner))
+		pruner := NewImagePruner(
+			env,
+			changeTracker,
+			azcli.NewContainerRegistryService(
+				credentialProvider,
+				mockContext.HttpClient,
+				docker.NewDocker(mockContext.CommandRunner),
+			),
+		)
+
+		return mockContext, pruner, &deleted
+	}
+
+	// Records the images of the successful deployments of the service
+	recordDeployed := func(t *testing.T, pruner *ImagePruner, tags ...string) {
+		images := []string{}
+		for _, tag := range tags {
+			images = append(images, "REGISTRY.azurecr.io/test-app/api-test:"+tag)
+		}
+
+		require.NoError(t, pruner.changeTracker.save(&DeployState{
+			Services: map[string]*ServiceDeployState{"api": {Images: images}},
+		}))
+	}
+
+	t.Run("Prune", func(t *testing.T) {
+		mockContext, pruner, deleted := setup(t)
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+		recordDeployed(t, pruner,
+			"azd-deploy-400", "azd-deploy-300", "azd-deploy-200", "azd-deploy-100", "azd-deploy-60-debug",
+			"azd-deploy-50-debug")
+
+		images, err := pruner.Find(*mockContext.Context, serviceConfig, 2)
+		require.NoError(t, err)
+
+		// The image of azd-deploy-150 is kept since it's also tagged azd-deploy-300, and the variants are kept separately
+		require.Equal(t, []*PrunedImage{
+			{
+				Service:    "api",
+				Registry:   "REGISTRY.azurecr.io",
+				Repository: "test-app/api-test",
+				Digest:     "sha256:a",
+				Tags:       []string{"azd-deploy-100"},
+			},
+			{
+				Service:    "api",
+				Registry:   "REGISTRY.azurecr.io",
+				Repository: "test-app/api-test",
+				Digest:     "sha256:b",
+				Tags:       []string{"azd-deploy-200"},
+			},
+		}, images)
+		require.Empty(t, *deleted)
+
+		require.NoError(t, pruner.Delete(*mockContext.Context, images))
+		require.Equal(t, []string{"sha256:a", "sha256:b"}, *deleted)
+	})
+
+	t.Run("FailedDeployments", func(t *testing.T) {
+		mockContext, pruner, _ := setup(t)
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+		recordDeployed(t, pruner, "azd-deploy-300", "azd-deploy-100")
+
+		// The failed deployment azd-deploy-400 isn't counted as a kept deployment
+		images, err := pruner.Find(*mockContext.Context, serviceConfig, 1)
+		require.NoError(t, err)
+		require.Len(t, images, 2)
+		require.Equal(t, []string{"azd-deploy-100"}, images[0].Tags)
+		require.Equal(t, []string{"azd-deploy-200"}, images[1].Tags)
+
+		// Nothing is pruned without the successful deployments to keep
+		recordDeployed(t, pruner)
+		images, err = pruner.Find(*mockContext.Context, serviceConfig, 1)
+		require.NoError(t, err)
+		require.Empty(t, images)
+	})
+
+	t.Run("CurrentImage", func(t *testing.T) {
+		mockContext, pruner, _ := setup(t)
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+		recordDeployed(t, pruner, "azd-deploy-400", "azd-deploy-300", "azd-deploy-200", "azd-deploy-100")
+		pruner.env.SetServiceProperty("api", "IMAGE_NAME", "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-100")
+
+		images, err := pruner.Find(*mockContext.Context, serviceConfig, 1)
+		require.NoError(t, err)
+		require.Len(t, images, 2)
+		require.Equal(t, []string{"azd-deploy-200"}, images[0].Tags)
+		require.Equal(t, []string{"azd-deploy-300", "azd-deploy-150"}, images[1].Tags)
+	})
+
+	t.Run("ConfiguredTag", func(t *testing.T) {
+		mockContext, pruner, _ := setup(t)
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+		serviceConfig.Docker.Tag = NewExpandableString("api:{git-short-sha}")
+
+		_, err := pruner.Find(*mockContext.Context, serviceConfig, 2)
+		require.ErrorIs(t, err, ErrImagePruneNotSupported)
+	})
+}
@@ -0,0 +1,727 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/oras"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"golang.org/x/exp/slices"
+)
+
+// The default number of container images pushed concurrently.
+// Can be overridden with the AZD_DOCKER_PUSH_MAX_CONCURRENCY environment variable.
+const defaultImagePushConcurrency = 3
+
+// ImagePushCoordinator pushes the container images of all services in a project.
+// Pushes run concurrently up to a configurable limit. When images share layers with an image that is already being
+// pushed to the same registry, the push waits for the other push to complete so the shared layers are uploaded once
+// and mounted from the registry afterwards.
+type ImagePushCoordinator struct {
+	env                      *environment.Environment
+	containerRegistryService azcli.ContainerRegistryService
+	azCli                    azcli.AzCli
+	docker                   docker.Docker
+	oras                     oras.OrasCli
+	notation                 notation.NotationCli
+	cosign                   cosign.CosignCli
+	trivy                    trivy.TrivyCli
+	grype                    grype.GrypeCli
+	syft                     syft.SyftCli
+
+	slots chan struct{}
+
+	mutex  sync.Mutex
+	pushes map[string]*imagePush
+	layers map[string]*imagePush
+	logins map[string]*imagePush
+
+	replicasMutex sync.Mutex
+	replicas      map[string][]string
+}
+
+// imagePush tracks the state of a single image push
+type imagePush struct {
+	serviceName string
+	imageTag    string
+	done        chan struct{}
+	err         error
+
+	mutex        sync.Mutex
+	status       string
+	listeners    map[int]func(string)
+	nextListener int
+}
+
+// Creates a new instance of the ImagePushCoordinator
+func NewImagePushCoordinator(
+	env *environment.Environment,
+	containerRegistryService azcli.ContainerRegistryService,
+	azCli azcli.AzCli,
+	docker docker.Docker,
+	orasCli oras.OrasCli,
+	notationCli notation.NotationCli,
+	cosignCli cosign.CosignCli,
+	trivyCli trivy.TrivyCli,
+	grypeCli grype.GrypeCli,
+	syftCli syft.SyftCli,
+) *ImagePushCoordinator {
+	maxConcurrency := defaultImagePushConcurrency
+	if value := os.Getenv("AZD_DOCKER_PUSH_MAX_CONCURRENCY"); value != "" {
+		if val, err := strconv.ParseInt(value, 10, 0); err == nil && val > 0 {
+			maxConcurrency = int(val)
+		}
+	}
+
+	return &ImagePushCoordinator{
+		env:                      env,
+		containerRegistryService: containerRegistryService,
+		azCli:                    azCli,
+		docker:                   docker,
+		oras:                     orasCli,
+		notation:                 notationCli,
+		cosign:                   cosignCli,
+		trivy:                    trivyCli,
+		grype:                    grypeCli,
+		syft:                     syftCli,
+		slots:                    make(chan struct{}, maxConcurrency),
+		pushes:                   map[string]*imagePush{},
+		layers:                   map[string]*imagePush{},
+		logins:                   map[string]*imagePush{},
+		replicas:                 map[string][]string{},
+	}
+}
+
+// Begins pushing the container image of the service package in the background, with the subscription the service target
+// pushes the image with. Returns a function waiting for the push to complete, which cancels the push first when cancel
+// is set, ex) when the deployment of the service fails before the service target uses the image.
+// Packages that don't produce a container image have nothing to push.
+func (c *ImagePushCoordinator) start(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) (wait func(cancel bool)) {
+	packageDetails, ok := packageOutput.Details.(*dockerPackageResult)
+	if !ok || packageDetails.LoginServer == "" {
+		return func(bool) {}
+	}
+
+	ctx, cancelPush := context.WithCancel(ctx)
+	push := c.schedule(ctx, subscriptionId, serviceConfig, packageDetails)
+	return func(cancel bool) {
+		if cancel {
+			cancelPush()
+		}
+
+		<-push.done
+		cancelPush()
+	}
+}
+
+// Push pushes the container image of the service to the container registry, reporting the state of the push
+// to the progress function. When a push of the same image was already started, waits for it to complete.
+func (c *ImagePushCoordinator) Push(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	progress func(string),
+) error {
+	push := c.schedule(ctx, subscriptionId, serviceConfig, packageDetails)
+	unwatch := push.watch(progress)
+	defer unwatch()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-push.done:
+		return push.err
+	}
+}
+
+// ImageReference gets the reference used by the deploy target to pull the image pushed for the service, and the
+// credentials the image is pulled with when they differ from the credentials of the registry the image was pushed to.
+//
+// When a connected registry serves the location of the environment, the image is pulled from the connected registry,
+// with the client token of the connected registry. Otherwise the image is pulled from the login server of the registry,
+// which routes the requests of a geo-replicated registry to the nearest replica.
+func (c *ImagePushCoordinator) ImageReference(
+	ctx context.Context,
+	subscriptionId string,
+	packageDetails *dockerPackageResult,
+) (string, *azcli.DockerCredentials, error) {
+	location := c.env.GetLocation()
+	if location == "" || packageDetails.LoginServer == "" || packageDetails.Registry != nil {
+		return packageDetails.ImageTag, nil, nil
+	}
+
+	endpoint, err := c.connectedEndpoint(location)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if endpoint != "" {
+		log.Printf("pulling image %s from connected registry %s", packageDetails.ImageTag, endpoint)
+		creds, err := c.connectedRegistryCredentials(endpoint)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return endpoint + strings.TrimPrefix(packageDetails.ImageTag, packageDetails.LoginServer), creds, nil
+	}
+
+	replicas := c.replicationLocations(ctx, subscriptionId, packageDetails.LoginServer)
+	if len(replicas) > 1 && slices.IndexFunc(replicas, func(replica string) bool {
+		return normalizeLocation(replica) == normalizeLocation(location)
+	}) == -1 {
+		log.Printf(
+			"registry %s has no replica in location '%s', images are pulled from another region",
+			packageDetails.LoginServer,
+			location,
+		)
+	}
+
+	return packageDetails.ImageTag, nil, nil
+}
+
+// Gets the endpoint of the connected registry serving the location, empty when no connected registry serves it
+func (c *ImagePushCoordinator) connectedEndpoint(location string) (string, error) {
+	value := c.env.Getenv(environment.ContainerRegistryConnectedEndpointsEnvVarName)
+	if value == "" {
+		return "", nil
+	}
+
+	endpoints := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &endpoints); err != nil {
+		return "", fmt.Errorf(
+			"parsing %s, expected a JSON object of location to endpoint: %w",
+			environment.ContainerRegistryConnectedEndpointsEnvVarName,
+			err,
+		)
+	}
+
+	for endpointLocation, endpoint := range endpoints {
+		if normalizeLocation(endpointLocation) == normalizeLocation(location) && endpoint != "" {
+			return strings.TrimSuffix(endpoint, "/"), nil
+		}
+	}
+
+	return "", nil
+}
+
+// Gets the client token the deploy targets pull images from the connected registry with. Connected registries only
+// serve clients authenticated with one of their client tokens, the identity of the deploy target can't pull from them.
+func (c *ImagePushCoordinator) connectedRegistryCredentials(endpoint string) (*azcli.DockerCredentials, error) {
+	username := c.env.Getenv(environment.ContainerRegistryConnectedUsernameEnvVarName)
+	password := c.env.Getenv(environment.ContainerRegistryConnectedPasswordEnvVarName)
+	if username == "" || password == "" {
+		return nil, fmt.Errorf(
+			"pulling images from connected registry %s requires a client token of the connected registry, set %s and %s",
+			endpoint,
+			environment.ContainerRegistryConnectedUsernameEnvVarName,
+			environment.ContainerRegistryConnectedPasswordEnvVarName,
+		)
+	}
+
+	host, _, _ := strings.Cut(endpoint, "/")
+	return &azcli.DockerCredentials{
+		Username:    username,
+		Password:    password,
+		LoginServer: host,
+	}, nil
+}
+
+// Gets the replica locations of a geo-replicated registry once per registry and subscription for the run of azd, the
+// deploy targets of all the services pulling from the registry share the lookup.
+// The replicas only inform the selection of the endpoint, so failures are logged and ignored.
+func (c *ImagePushCoordinator) replicationLocations(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) []string {
+	c.replicasMutex.Lock()
+	defer c.replicasMutex.Unlock()
+
+	key := subscriptionId + "/" + loginServer
+	if replicas, has := c.replicas[key]; has {
+		return replicas
+	}
+
+	replicas, err := c.containerRegistryService.GetReplicationLocations(ctx, subscriptionId, loginServer)
+	if err != nil {
+		log.Printf("failed getting replicas of registry %s: %v", loginServer, err)
+	}
+
+	c.replicas[key] = replicas
+	return replicas
+}
+
+// Normalizes an Azure location for comparison, ex) 'West Europe' and 'westeurope'
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// Gets the image push for the image tag, starting a new push when one doesn't exist yet
+func (c *ImagePushCoordinator) schedule(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+) *imagePush {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if push, has := c.pushes[packageDetails.ImageTag]; has {
+		return push
+	}
+
+	push := &imagePush{
+		serviceName: serviceConfig.Name,
+		imageTag:    packageDetails.ImageTag,
+		done:        make(chan struct{}),
+	}
+	c.pushes[packageDetails.ImageTag] = push
+
+	go func() {
+		defer close(push.done)
+		push.err = c.run(ctx, subscriptionId, serviceConfig, packageDetails, push)
+		if push.err != nil {
+			// Allows the image to be pushed again on a subsequent attempt
+			c.mutex.Lock()
+			delete(c.pushes, packageDetails.ImageTag)
+			c.mutex.Unlock()
+		}
+	}()
+
+	return push
+}
+
+func (c *ImagePushCoordinator) run(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) error {
+	// Pre-built images referenced from their registry are already available to the deploy target
+	if packageDetails.LoginServer == "" {
+		log.Printf("skipping push of %s, the image is referenced from its registry", packageDetails.ImageTag)
+		if serviceConfig.Docker.Sign.Enabled {
+			log.Printf("skipping signing of %s, the image isn't in the container registry", packageDetails.ImageTag)
+		}
+
+		// The SBOM is attached to the image in its registry, with the credentials of docker for the registry
+		if err := c.pushSbom(ctx, packageDetails, push); err != nil {
+			return fmt.Errorf(
+				"the credentials of docker must allow pushing to the registry of pre-built image %s: %w",
+				packageDetails.ImageTag,
+				err,
+			)
+		}
+
+		return nil
+	}
+
+	// Remote builds push the image from the registry, which doesn't require logging into the registry with docker
+	if build := packageDetails.DeferredBuild; build != nil && build.Remote {
+		if err := c.buildRemote(ctx, subscriptionId, serviceConfig, packageDetails, push); err != nil {
+			return err
+		}
+
+		// SBOM generators, scanners and signing tools use the credentials of docker to read the image from and store
+		// the SBOM and the signature in the registry
+		if serviceConfig.Docker.Sbom.Enabled || serviceConfig.Docker.Scan.Enabled || serviceConfig.Docker.Sign.Enabled {
+			if err := c.login(ctx, subscriptionId, packageDetails, push); err != nil {
+				return fmt.Errorf("logging into registry '%s': %w", packageDetails.LoginServer, err)
+			}
+		}
+
+		if err := c.sbomPushed(ctx, serviceConfig, packageDetails, push); err != nil {
+			return err
+		}
+
+		if err := c.scanPushed(ctx, serviceConfig, packageDetails, push); err != nil {
+			return err
+		}
+
+		return c.sign(ctx, serviceConfig, packageDetails, push)
+	}
+
+	if err := c.login(ctx, subscriptionId, packageDetails, push); err != nil {
+		return fmt.Errorf("logging into registry '%s': %w", packageDetails.LoginServer, err)
+	}
+
+	// Deferred builds aren't in the local image store, so their layers can't be shared with other pushes
+	if packageDetails.DeferredBuild == nil {
+		for _, owner := range c.claimLayers(ctx, serviceConfig, packageDetails, push) {
+			push.setStatus(fmt.Sprintf("Waiting for layers shared with service %s", owner.serviceName))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-owner.done:
+			}
+		}
+	}
+
+	push.setStatus("Waiting for push slot")
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case c.slots <- struct{}{}:
+	}
+	defer func() { <-c.slots }()
+
+	if build := packageDetails.DeferredBuild; build != nil {
+		log.Printf("building and pushing %s for %s", packageDetails.ImageTag, strings.Join(build.Platforms, ", "))
+		push.setStatus("Building and pushing multi-platform image")
+		err := pushWithRetry(ctx, serviceConfig, packageDetails.ImageTag, push, func() error {
+			return c.docker.BuildxPush(
+				ctx,
+				serviceConfig.Path(),
+				docker.BuildOptions{
+					DockerfilePath: build.DockerfilePath,
+					Platforms:      build.Platforms,
+					Target:         build.Target,
+					Context:        build.Context,
+					BuildArgs:      build.BuildArgs,
+					Labels:         build.Labels,
+					Secrets:        build.Secrets,
+					Cache:          build.Cache,
+					ExtraArgs:      dockerBuildExtraArgs(build.BuildOptions, build.NoCache),
+				},
+				packageDetails.Tags(),
+			)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := c.sbomPushed(ctx, serviceConfig, packageDetails, push); err != nil {
+			return err
+		}
+
+		if err := c.scanPushed(ctx, serviceConfig, packageDetails, push); err != nil {
+			return err
+		}
+
+		return c.sign(ctx, serviceConfig, packageDetails, push)
+	}
+
+	log.Printf("pushing %s to registry", packageDetails.ImageTag)
+	push.setStatus("Pushing image")
+	for _, tag := range packageDetails.Tags() {
+		err := pushWithRetry(ctx, serviceConfig, tag, push, func() error {
+			return c.docker.Push(ctx, serviceConfig.Path(), tag)
+		})
+		if err != nil {
+			return githubActionsPushError(packageDetails.LoginServer, err)
+		}
+	}
+
+	if err := c.pushSbom(ctx, packageDetails, push); err != nil {
+		return err
+	}
+
+	return c.sign(ctx, serviceConfig, packageDetails, push)
+}
+
+// Generates the SBOM of the image of a deferred build from the registry and attaches it to the image, when enabled for the
+// service. Deferred builds aren't in the local image store, so their SBOM can't be generated when they're packaged.
+func (c *ImagePushCoordinator) sbomPushed(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) error {
+	if !serviceConfig.Docker.Sbom.Enabled {
+		return nil
+	}
+
+	push.setStatus("Generating SBOM")
+	sbom, err := generateSbom(ctx, c.syft, c.env, serviceConfig, "registry:"+packageDetails.ImageTag)
+	if err != nil {
+		return fmt.Errorf("generating sbom: %w", err)
+	}
+
+	packageDetails.Sbom = sbom
+	return c.pushSbom(ctx, packageDetails, push)
+}
+
+// Scans the image of a deferred build for vulnerabilities in the registry, when enabled for the service. Deferred builds
+// aren't in the local image store, so they can't be scanned when they're packaged. The deploy targets wait for the push,
+// so an image failing the scan is never deployed nor signed.
+func (c *ImagePushCoordinator) scanPushed(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) error {
+	if !serviceConfig.Docker.Scan.Enabled {
+		return nil
+	}
+
+	push.setStatus("Scanning image for vulnerabilities")
+	scan, err := scanImage(ctx, c.trivy, c.grype, serviceConfig, packageDetails.ImageTag, true)
+	if err != nil {
+		var scanErr *VulnerabilityScanError
+		if errors.As(err, &scanErr) {
+			return err
+		}
+
+		return fmt.Errorf("scanning image: %w", err)
+	}
+
+	packageDetails.Scan = scan
+	return nil
+}
+
+// Attaches the SBOM of the image to the pushed image as an OCI artifact, when the SBOM is pushed
+func (c *ImagePushCoordinator) pushSbom(ctx context.Context, packageDetails *dockerPackageResult, push *imagePush) error {
+	sbom := packageDetails.Sbom
+	if sbom == nil || !sbom.Push {
+		return nil
+	}
+
+	if err := tools.EnsureInstalled(ctx, c.oras); err != nil {
+		return fmt.Errorf("pushing sbom: %w", err)
+	}
+
+	log.Printf("attaching sbom %s to %s", sbom.Path, packageDetails.ImageTag)
+	push.setStatus("Pushing SBOM")
+	if err := c.oras.Attach(ctx, packageDetails.ImageTag, syft.MediaTypes[sbom.Format], sbom.Path); err != nil {
+		return fmt.Errorf("pushing sbom: %w", err)
+	}
+
+	return nil
+}
+
+// Builds the image remotely with ACR Tasks, which pushes the image to the registry when the build completes
+func (c *ImagePushCoordinator) buildRemote(
+	ctx context.Context,
+	subscriptionId string,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) error {
+	build := packageDetails.DeferredBuild
+	contextPath := filepath.Join(serviceConfig.Path(), build.Context)
+	dockerfilePath, err := filepath.Rel(contextPath, filepath.Join(serviceConfig.Path(), build.DockerfilePath))
+	if err != nil {
+		return fmt.Errorf("resolving Dockerfile path: %w", err)
+	}
+
+	if strings.HasPrefix(dockerfilePath, "..") {
+		return fmt.Errorf(
+			"the Dockerfile '%s' must be within the build context '%s' to build the image remotely",
+			build.DockerfilePath,
+			build.Context,
+		)
+	}
+
+	exclude, err := dockerIgnoreExclude(contextPath, filepath.ToSlash(dockerfilePath))
+	if err != nil {
+		return err
+	}
+
+	platform := ""
+	if len(build.Platforms) > 0 {
+		platform = build.Platforms[0]
+	}
+
+	imageNames := []string{}
+	for _, tag := range packageDetails.Tags() {
+		imageNames = append(imageNames, strings.TrimPrefix(tag, packageDetails.LoginServer+"/"))
+	}
+
+	log.Printf("building %s remotely in registry %s", packageDetails.ImageTag, packageDetails.LoginServer)
+	push.setStatus("Building image remotely")
+	return c.containerRegistryService.BuildImage(ctx, subscriptionId, packageDetails.LoginServer, azcli.RemoteBuildOptions{
+		ContextPath:    contextPath,
+		DockerfilePath: filepath.ToSlash(dockerfilePath),
+		Platform:       platform,
+		Target:         build.Target,
+		BuildArgs:      build.BuildArgs,
+		NoCache:        build.NoCache,
+		ImageNames:     imageNames,
+		Exclude:        exclude,
+	})
+}
+
+// Creates the condition leaving the files excluded by the .dockerignore file of the build context out of the source
+// uploaded to the registry, as the docker CLI does. The Dockerfile and the .dockerignore file are always uploaded.
+func dockerIgnoreExclude(contextPath string, dockerfilePath string) (rzip.ExcludeFunc, error) {
+	rules, err := readIgnoreFile(filepath.Join(contextPath, dockerIgnoreFileName), false)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", dockerIgnoreFileName, err)
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	// Directories can only be skipped as a whole when no pattern includes files of excluded directories back
+	skipDirs := !rules.hasNegation()
+	return func(name string, isDir bool) bool {
+		if isDir {
+			return skipDirs && !strings.HasPrefix(dockerfilePath, name+"/") && rules.excludesPathOrParent(name, true)
+		}
+
+		if name == dockerfilePath || name == dockerIgnoreFileName {
+			return false
+		}
+
+		return rules.excludesPathOrParent(name, false)
+	}, nil
+}
+
+// Claims the layers of the image that are not already being pushed to the registry and returns the pushes
+// uploading the remaining shared layers. Layers are claimed in order, so a push only ever waits for pushes started
+// before it.
+func (c *ImagePushCoordinator) claimLayers(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) []*imagePush {
+	layers, err := c.docker.Layers(ctx, serviceConfig.Path(), packageDetails.ImageTag)
+	if err != nil {
+		// Layer de-duplication is an optimization, the push itself uploads any missing layers
+		log.Printf("failed getting layers for image %s: %v", packageDetails.ImageTag, err)
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	owners := []*imagePush{}
+	for _, layer := range layers {
+		key := fmt.Sprintf("%s/%s", packageDetails.LoginServer, layer)
+		owner, has := c.layers[key]
+		if !has {
+			c.layers[key] = push
+			continue
+		}
+
+		if owner == push || containsImagePush(owners, owner) {
+			continue
+		}
+
+		select {
+		case <-owner.done:
+			// Already pushed, the layer is mounted from the registry
+		default:
+			owners = append(owners, owner)
+		}
+	}
+
+	return owners
+}
+
+// Logs into the registry once, subsequent pushes to the same registry wait for the initial login. The container registry
+// of the environment is logged into with the credentials of azd, other registries with their configured credentials.
+func (c *ImagePushCoordinator) login(
+	ctx context.Context,
+	subscriptionId string,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) error {
+	loginServer := packageDetails.LoginServer
+	c.mutex.Lock()
+	login, has := c.logins[loginServer]
+	if !has {
+		login = &imagePush{done: make(chan struct{})}
+		c.logins[loginServer] = login
+	}
+	c.mutex.Unlock()
+
+	if !has {
+		log.Printf("logging into registry %s", loginServer)
+		push.setStatus("Logging into container registry")
+		if packageDetails.Registry != nil {
+			login.err = c.loginRegistry(ctx, subscriptionId, loginServer, packageDetails.Registry)
+		} else {
+			login.err = c.containerRegistryService.LoginAcr(ctx, subscriptionId, loginServer)
+		}
+		if login.err != nil {
+			c.mutex.Lock()
+			delete(c.logins, loginServer)
+			c.mutex.Unlock()
+		}
+
+		close(login.done)
+		return login.err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-login.done:
+		return login.err
+	}
+}
+
+// Registers a progress listener, which immediately receives the current status of the push.
+// Returns a function that removes the listener.
+func (p *imagePush) watch(listener func(string)) func() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.listeners == nil {
+		p.listeners = map[int]func(string){}
+	}
+
+	id := p.nextListener
+	p.nextListener++
+	p.listeners[id] = listener
+
+	if p.status != "" {
+		listener(p.status)
+	}
+
+	return func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		delete(p.listeners, id)
+	}
+}
+
+func (p *imagePush) setStatus(status string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.status = status
+	for _, listener := range p.listeners {
+		listener(status)
+	}
+}
+
+func containsImagePush(pushes []*imagePush, push *imagePush) bool {
+	for _, p := range pushes {
+		if p == push {
+			return true
+		}
+	}
+
+	return false
+}
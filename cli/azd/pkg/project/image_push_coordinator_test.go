@@ -0,0 +1,635 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/oras"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImagePushCoordinator_SharedLayers(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	layers := map[string]string{
+		"REGISTRY.azurecr.io/api:azd-deploy-0":    `["sha256:base","sha256:api"]`,
+		"REGISTRY.azurecr.io/web:azd-deploy-0":    `["sha256:base","sha256:web"]`,
+		"REGISTRY.azurecr.io/worker:azd-deploy-0": `["sha256:other"]`,
+	}
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, layers[args.Args[len(args.Args)-1]], ""), nil
+	})
+
+	var mutex sync.Mutex
+	logins := 0
+	pushed := []string{}
+	releaseApi := make(chan struct{})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker login")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		logins++
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker push")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		imageTag := args.Args[len(args.Args)-1]
+		if strings.Contains(imageTag, "/api:") {
+			<-releaseApi
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		pushed = append(pushed, imageTag)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	coordinator := createImagePushCoordinator(mockContext)
+	push := func(serviceName string) <-chan error {
+		result := make(chan error, 1)
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+		serviceConfig.Name = serviceName
+		packageDetails := &dockerPackageResult{
+			ImageTag:    "REGISTRY.azurecr.io/" + serviceName + ":azd-deploy-0",
+			LoginServer: "REGISTRY.azurecr.io",
+		}
+
+		// Ensures the push of the api is started first and claims the shared base layer
+		started := make(chan struct{})
+		var once sync.Once
+		go func() {
+			result <- coordinator.Push(
+				*mockContext.Context,
+				"SUBSCRIPTION_ID",
+				serviceConfig,
+				packageDetails,
+				func(status string) {
+					if status == "Pushing image" || strings.HasPrefix(status, "Waiting for layers") {
+						once.Do(func() { close(started) })
+					}
+				},
+			)
+		}()
+		<-started
+
+		return result
+	}
+
+	apiResult := push("api")
+	webResult := push("web")
+
+	// The worker doesn't share any layers and is pushed while the api push is still in progress
+	require.NoError(t, <-push("worker"))
+
+	close(releaseApi)
+	require.NoError(t, <-apiResult)
+	require.NoError(t, <-webResult)
+
+	require.Equal(t, 1, logins)
+	require.Equal(t, []string{
+		"REGISTRY.azurecr.io/worker:azd-deploy-0",
+		"REGISTRY.azurecr.io/api:azd-deploy-0",
+		"REGISTRY.azurecr.io/web:azd-deploy-0",
+	}, pushed)
+}
+
+func Test_ImagePushCoordinator_Start(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	pushes := 0
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker push")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		pushes++
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	coordinator := createImagePushCoordinator(mockContext)
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+	packageDetails := &dockerPackageResult{
+		ImageTag:    "REGISTRY.azurecr.io/api:azd-deploy-0",
+		LoginServer: "REGISTRY.azurecr.io",
+	}
+
+	// Packages without a container image are ignored
+	wait := coordinator.start(
+		*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, &ServicePackageResult{PackagePath: "app.zip"})
+	wait(true)
+
+	wait = coordinator.start(
+		*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, &ServicePackageResult{Details: packageDetails})
+
+	// Pushing the image waits for the push that was already started
+	err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+	require.NoError(t, err)
+	require.Equal(t, 1, pushes)
+	wait(false)
+
+	// Canceled pushes are awaited, and pushed again by the next attempt
+	otherDetails := &dockerPackageResult{
+		ImageTag:    "REGISTRY.azurecr.io/web:azd-deploy-0",
+		LoginServer: "REGISTRY.azurecr.io",
+	}
+	ctx, cancel := context.WithCancel(*mockContext.Context)
+	cancel()
+	wait = coordinator.start(ctx, "SUBSCRIPTION_ID", serviceConfig, &ServicePackageResult{Details: otherDetails})
+	wait(true)
+
+	err = coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, otherDetails, func(string) {})
+	require.NoError(t, err)
+	require.Equal(t, 2, pushes)
+}
+
+func Test_ImagePushCoordinator_Registry(t *testing.T) {
+	push := func(t *testing.T, credentials DockerRegistryCredentials) ([]string, []string) {
+		mockContext := mocks.NewMockContext(context.Background())
+		logins := []string{}
+		pushed := []string{}
+
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker login")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			logins = append(logins, strings.Join(args.Args, " "))
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			pushed = append(pushed, args.Args[len(args.Args)-1])
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker image inspect")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			return exec.NewRunResult(0, "[]", ""), nil
+		})
+
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.env.Values["GHCR_TOKEN"] = "TOKEN"
+
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+		serviceConfig.Docker.Registry = DockerRegistryOptions{
+			Endpoint:    NewExpandableString("ghcr.io/contoso"),
+			Credentials: credentials,
+		}
+
+		packageDetails := &dockerPackageResult{
+			ImageTag:    "ghcr.io/contoso/api:azd-deploy-0",
+			LoginServer: "ghcr.io/contoso",
+			Registry:    &serviceConfig.Docker.Registry,
+		}
+
+		err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+		require.NoError(t, err)
+
+		return logins, pushed
+	}
+
+	t.Run("PasswordEnv", func(t *testing.T) {
+		logins, pushed := push(t, DockerRegistryCredentials{
+			Username:    NewExpandableString("octocat"),
+			PasswordEnv: "GHCR_TOKEN",
+		})
+
+		require.Equal(t, []string{"login --username octocat --password TOKEN ghcr.io"}, logins)
+		require.Equal(t, []string{"ghcr.io/contoso/api:azd-deploy-0"}, pushed)
+	})
+
+	t.Run("DockerConfig", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		logins, pushed := push(t, DockerRegistryCredentials{})
+
+		require.Empty(t, logins)
+		require.Equal(t, []string{"ghcr.io/contoso/api:azd-deploy-0"}, pushed)
+	})
+
+	t.Run("GitHubActions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
+		t.Setenv("GITHUB_ACTOR", "octocat")
+		t.Setenv("GITHUB_TOKEN", "WORKFLOW_TOKEN")
+		logins, pushed := push(t, DockerRegistryCredentials{})
+
+		require.Equal(t, []string{"login --username octocat --password WORKFLOW_TOKEN ghcr.io"}, logins)
+		require.Equal(t, []string{"ghcr.io/contoso/api:azd-deploy-0"}, pushed)
+	})
+}
+
+func Test_ImagePushCoordinator_PullCredentials(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	coordinator := createImagePushCoordinator(mockContext)
+	coordinator.env.Values["GHCR_TOKEN"] = "TOKEN"
+
+	t.Run("PasswordEnv", func(t *testing.T) {
+		creds, err := coordinator.PullCredentials(*mockContext.Context, "SUBSCRIPTION_ID", &dockerPackageResult{
+			LoginServer: "ghcr.io/contoso",
+			Registry: &DockerRegistryOptions{
+				Credentials: DockerRegistryCredentials{
+					Username:    NewExpandableString("octocat"),
+					PasswordEnv: "GHCR_TOKEN",
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, &azcli.DockerCredentials{
+			Username:    "octocat",
+			Password:    "TOKEN",
+			LoginServer: "ghcr.io",
+		}, creds)
+	})
+
+	t.Run("NoCredentials", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
+		t.Setenv("GITHUB_ACTOR", "octocat")
+		t.Setenv("GITHUB_TOKEN", "WORKFLOW_TOKEN")
+		creds, err := coordinator.PullCredentials(*mockContext.Context, "SUBSCRIPTION_ID", &dockerPackageResult{
+			LoginServer: "ghcr.io/contoso",
+			Registry:    &DockerRegistryOptions{},
+		})
+		require.NoError(t, err)
+		require.Nil(t, creds)
+	})
+
+	t.Run("EnvironmentRegistry", func(t *testing.T) {
+		creds, err := coordinator.PullCredentials(*mockContext.Context, "SUBSCRIPTION_ID", &dockerPackageResult{
+			LoginServer: "contoso.azurecr.io",
+		})
+		require.NoError(t, err)
+		require.Nil(t, creds)
+	})
+}
+
+func Test_ResolveRegistryEndpoint_GitHubContainerRegistry(t *testing.T) {
+	env := environment.EphemeralWithValues("test", map[string]string{"GITHUB_REPOSITORY_OWNER": "Contoso"})
+
+	endpoint, isExternal, err := resolveRegistryEndpoint(env, DockerRegistryOptions{
+		Endpoint: NewExpandableString("ghcr.io/${GITHUB_REPOSITORY_OWNER}/"),
+	})
+	require.NoError(t, err)
+	require.True(t, isExternal)
+	require.Equal(t, "ghcr.io/contoso", endpoint)
+}
+
+func Test_ParseKeyVaultSecretId(t *testing.T) {
+	vaultUrl, name, err := parseKeyVaultSecretId("https://contoso.vault.azure.net/secrets/ghcr-token/0123")
+	require.NoError(t, err)
+	require.Equal(t, "https://contoso.vault.azure.net", vaultUrl)
+	require.Equal(t, "ghcr-token", name)
+
+	_, _, err = parseKeyVaultSecretId("https://contoso.vault.azure.net/keys/signing")
+	require.Error(t, err)
+}
+
+func Test_ImagePushCoordinator_RetryPush(t *testing.T) {
+	push := func(t *testing.T, stderr []string) (int, error) {
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocksForBatchJobTarget(mockContext)
+
+		pushes := 0
+		mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+			return strings.Contains(command, "docker push")
+		}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			pushes++
+			if pushes > len(stderr) {
+				return exec.NewRunResult(0, "", ""), nil
+			}
+
+			return exec.NewRunResult(1, "", stderr[pushes-1]), errors.New(stderr[pushes-1])
+		})
+
+		coordinator := createImagePushCoordinator(mockContext)
+		serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+		serviceConfig.Docker.PushRetry = DockerPushRetryOptions{MaxRetries: 2, Delay: time.Millisecond}
+		packageDetails := &dockerPackageResult{
+			ImageTag:    "REGISTRY.azurecr.io/api:azd-deploy-0",
+			LoginServer: "REGISTRY.azurecr.io",
+		}
+
+		err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+		return pushes, err
+	}
+
+	t.Run("TransientError", func(t *testing.T) {
+		pushes, err := push(t, []string{
+			"received unexpected HTTP status: 503 Service Unavailable",
+			"net/http: TLS handshake timeout",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, pushes)
+	})
+
+	t.Run("RetriesExhausted", func(t *testing.T) {
+		pushes, err := push(t, []string{"503 Service Unavailable", "502 Bad Gateway", "504 Gateway Timeout"})
+		require.Equal(t, 3, pushes)
+
+		var pushErr *ImagePushError
+		require.ErrorAs(t, err, &pushErr)
+		require.Len(t, pushErr.Attempts, 3)
+		require.ErrorContains(t, err, "failed after 3 attempts")
+		require.ErrorContains(t, err, "attempt 3: pushing image: ")
+	})
+
+	t.Run("PermanentError", func(t *testing.T) {
+		pushes, err := push(t, []string{"denied: requested access to the resource is denied"})
+		require.ErrorContains(t, err, "requested access to the resource is denied")
+		require.Equal(t, 1, pushes)
+	})
+}
+
+func Test_ImagePushCoordinator_MultiPlatform(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	var buildArgs []string
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker push") || strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Fail(t, "multi-platform images are pushed by buildx")
+		return exec.NewRunResult(1, "", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker buildx build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		buildArgs = args.Args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	coordinator := createImagePushCoordinator(mockContext)
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+	packageDetails := &dockerPackageResult{
+		ImageTag:    "REGISTRY.azurecr.io/api:azd-deploy-0",
+		LoginServer: "REGISTRY.azurecr.io",
+		DeferredBuild: &dockerDeferredBuild{
+			DockerfilePath: "./Dockerfile",
+			Platforms:      []string{"linux/amd64", "linux/arm64"},
+			Context:        ".",
+			Labels:         []string{"org.opencontainers.image.revision=COMMIT"},
+		},
+	}
+
+	err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"buildx", "build",
+		"-f", "./Dockerfile",
+		"--platform", "linux/amd64,linux/arm64",
+		"--label", "org.opencontainers.image.revision=COMMIT",
+		"-t", "REGISTRY.azurecr.io/api:azd-deploy-0",
+		"--push",
+		".",
+	}, buildArgs)
+}
+
+func Test_ImagePushCoordinator_ScanDeferredBuild(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker buildx build")
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+	serviceConfig.Docker.Scan = DockerScanOptions{Enabled: true, Severity: "HIGH"}
+	newPackageDetails := func() *dockerPackageResult {
+		return &dockerPackageResult{
+			ImageTag:    "REGISTRY.azurecr.io/api:azd-deploy-0",
+			LoginServer: "REGISTRY.azurecr.io",
+			DeferredBuild: &dockerDeferredBuild{
+				DockerfilePath: "./Dockerfile",
+				Platforms:      []string{"linux/amd64", "linux/arm64"},
+				Context:        ".",
+			},
+		}
+	}
+
+	t.Run("Fails", func(t *testing.T) {
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.trivy = &fakeTrivyCli{
+			vulnerabilities: []trivy.Vulnerability{{VulnerabilityID: "CVE-1", PkgName: "openssl", Severity: "HIGH"}},
+		}
+
+		err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, newPackageDetails(), func(string) {})
+		scanErr := &VulnerabilityScanError{}
+		require.True(t, errors.As(err, &scanErr))
+		require.Equal(t, "REGISTRY.azurecr.io/api:azd-deploy-0", scanErr.Image)
+	})
+
+	t.Run("Passes", func(t *testing.T) {
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.trivy = &fakeTrivyCli{}
+		packageDetails := newPackageDetails()
+
+		err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+		require.NoError(t, err)
+		require.NotNil(t, packageDetails.Scan)
+		require.Equal(t, "trivy", packageDetails.Scan.Scanner)
+	})
+}
+
+func Test_ImagePushCoordinator_SbomDeferredBuild(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker buildx build")
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	syftCli := &fakeSyftCli{}
+	orasCli := &fakeOrasCli{}
+	coordinator := createImagePushCoordinator(mockContext)
+	coordinator.env.Root = t.TempDir()
+	coordinator.syft = syftCli
+	coordinator.oras = orasCli
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+	serviceConfig.Docker.Sbom = DockerSbomOptions{Enabled: true, Push: true}
+	packageDetails := &dockerPackageResult{
+		ImageTag:    "REGISTRY.azurecr.io/api:azd-deploy-0",
+		LoginServer: "REGISTRY.azurecr.io",
+		DeferredBuild: &dockerDeferredBuild{
+			DockerfilePath: "./Dockerfile",
+			Platforms:      []string{"linux/amd64", "linux/arm64"},
+			Context:        ".",
+		},
+	}
+
+	err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+	require.NoError(t, err)
+
+	// The SBOM is generated from the pushed image and attached to it
+	require.NotNil(t, packageDetails.Sbom)
+	require.Equal(t, "registry:REGISTRY.azurecr.io/api:azd-deploy-0", syftCli.args[0])
+	require.Equal(t, []string{"REGISTRY.azurecr.io/api:azd-deploy-0"}, orasCli.attached)
+}
+
+func Test_ImagePushCoordinator_ReferencedImage(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == "docker"
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Fail(t, "images referenced from their registry aren't pushed")
+		return exec.NewRunResult(1, "", ""), nil
+	})
+
+	coordinator := createImagePushCoordinator(mockContext)
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+	packageDetails := &dockerPackageResult{
+		ImageTag: "mcr.microsoft.com/azuredocs/aci-helloworld:latest",
+	}
+
+	err := coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+	require.NoError(t, err)
+
+	// The SBOM is attached to the image in its registry
+	orasCli := &fakeOrasCli{}
+	coordinator = createImagePushCoordinator(mockContext)
+	coordinator.oras = orasCli
+	packageDetails = &dockerPackageResult{
+		ImageTag: "contoso.azurecr.io/api:1.0",
+		Sbom:     &dockerSbom{Path: "api.spdx.json", Format: syft.FormatSpdxJson, Push: true},
+	}
+
+	err = coordinator.Push(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig, packageDetails, func(string) {})
+	require.NoError(t, err)
+	require.Equal(t, []string{"contoso.azurecr.io/api:1.0"}, orasCli.attached)
+}
+
+func Test_ImagePushCoordinator_ImageReference(t *testing.T) {
+	packageDetails := &dockerPackageResult{
+		ImageTag:    "REGISTRY.azurecr.io/api:azd-deploy-0",
+		LoginServer: "REGISTRY.azurecr.io",
+	}
+
+	t.Run("ConnectedRegistry", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.env.SetLocation("westeurope")
+		coordinator.env.Values[environment.ContainerRegistryConnectedEndpointsEnvVarName] =
+			`{"West Europe": "registry.contoso.com", "eastus": "registry-us.contoso.com"}`
+		coordinator.env.Values[environment.ContainerRegistryConnectedUsernameEnvVarName] = "pull-token"
+		coordinator.env.Values[environment.ContainerRegistryConnectedPasswordEnvVarName] = "TOKEN_PASSWORD"
+
+		imageReference, creds, err := coordinator.ImageReference(*mockContext.Context, "SUBSCRIPTION_ID", packageDetails)
+		require.NoError(t, err)
+		require.Equal(t, "registry.contoso.com/api:azd-deploy-0", imageReference)
+		require.Equal(t, &azcli.DockerCredentials{
+			Username:    "pull-token",
+			Password:    "TOKEN_PASSWORD",
+			LoginServer: "registry.contoso.com",
+		}, creds)
+	})
+
+	t.Run("ConnectedRegistryWithoutToken", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.env.SetLocation("westeurope")
+		coordinator.env.Values[environment.ContainerRegistryConnectedEndpointsEnvVarName] =
+			`{"westeurope": "registry.contoso.com"}`
+
+		_, _, err := coordinator.ImageReference(*mockContext.Context, "SUBSCRIPTION_ID", packageDetails)
+		require.ErrorContains(t, err, environment.ContainerRegistryConnectedPasswordEnvVarName)
+	})
+
+	t.Run("GeoReplicatedRegistry", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		setupMocksForBatchJobTarget(mockContext)
+
+		replicationRequests := 0
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/replications")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			replicationRequests++
+			result := armcontainerregistry.ReplicationListResult{
+				Value: []*armcontainerregistry.Replication{
+					{Location: convert.RefOf("eastus2")},
+					{Location: convert.RefOf("westeurope")},
+				},
+			}
+
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, result)
+		})
+
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.env.SetLocation("westeurope")
+
+		for i := 0; i < 2; i++ {
+			imageReference, creds, err := coordinator.ImageReference(
+				*mockContext.Context, "SUBSCRIPTION_ID", packageDetails)
+			require.NoError(t, err)
+			require.Equal(t, packageDetails.ImageTag, imageReference)
+			require.Nil(t, creds)
+		}
+
+		// The replicas are listed once per registry
+		require.Equal(t, 1, replicationRequests)
+	})
+
+	t.Run("InvalidConnectedEndpoints", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		coordinator := createImagePushCoordinator(mockContext)
+		coordinator.env.SetLocation("westeurope")
+		coordinator.env.Values[environment.ContainerRegistryConnectedEndpointsEnvVarName] = "registry.contoso.com"
+
+		_, _, err := coordinator.ImageReference(*mockContext.Context, "SUBSCRIPTION_ID", packageDetails)
+		require.ErrorContains(t, err, environment.ContainerRegistryConnectedEndpointsEnvVarName)
+	})
+}
+
+func createImagePushCoordinator(mockContext *mocks.MockContext) *ImagePushCoordinator {
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+		func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		})
+
+	return NewImagePushCoordinator(
+		environment.EphemeralWithValues("test", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		}),
+		azcli.NewContainerRegistryService(credentialProvider, mockContext.HttpClient, dockerCli),
+		mockazcli.NewAzCliFromMockContext(mockContext),
+		dockerCli,
+		oras.NewOrasCli(mockContext.CommandRunner),
+		notation.NewNotationCli(mockContext.CommandRunner),
+		cosign.NewCosignCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+	)
+}
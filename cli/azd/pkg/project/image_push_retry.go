@@ -0,0 +1,153 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sethvargo/go-retry"
+)
+
+const (
+	// The default number of times a push failing with a transient error is retried
+	defaultPushMaxRetries = 3
+	// The default delay before the first retry of a push, doubled for each following retry
+	defaultPushRetryDelay = 2 * time.Second
+	// The default maximum delay between retries of a push
+	defaultPushMaxRetryDelay = 30 * time.Second
+	// The percentage of the delay between retries randomized, so concurrent pushes don't retry at the same time
+	pushRetryJitterPercent = 20
+)
+
+// DockerPushRetryOptions configures the retries of pushes of the image of the service failing with transient errors,
+// ex) 5xx responses or timeouts of the container registry. Pushes failing with other errors aren't retried.
+type DockerPushRetryOptions struct {
+	// The number of times a failed push is retried. Defaults to 3, pushes aren't retried when negative.
+	MaxRetries int `json:"maxRetries" yaml:"maxRetries"`
+	// The delay before the first retry, doubled for each following retry, ex) 2s. Defaults to 2s.
+	Delay time.Duration `json:"delay" yaml:"delay"`
+	// The maximum delay between retries, ex) 30s. Defaults to 30s.
+	MaxDelay time.Duration `json:"maxDelay" yaml:"maxDelay"`
+}
+
+// Creates the backoff between the retries of a push, an exponential backoff with jitter
+func (o DockerPushRetryOptions) backoff() retry.Backoff {
+	maxRetries := defaultPushMaxRetries
+	if o.MaxRetries < 0 {
+		maxRetries = 0
+	} else if o.MaxRetries > 0 {
+		maxRetries = o.MaxRetries
+	}
+
+	delay := o.Delay
+	if delay <= 0 {
+		delay = defaultPushRetryDelay
+	}
+
+	maxDelay := o.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultPushMaxRetryDelay
+	}
+
+	backoff := retry.NewExponential(delay)
+	backoff = retry.WithJitterPercent(pushRetryJitterPercent, backoff)
+	backoff = retry.WithCappedDuration(maxDelay, backoff)
+	return retry.WithMaxRetries(uint64(maxRetries), backoff)
+}
+
+// ImagePushError is returned when the push of an image failed after being retried, with the error of each attempt
+type ImagePushError struct {
+	ImageTag string
+	// The errors of the attempts to push the image, in order
+	Attempts []error
+}
+
+func (e *ImagePushError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("pushing image '%s' failed after %d attempts:", e.ImageTag, len(e.Attempts)))
+	for i, err := range e.Attempts {
+		builder.WriteString(fmt.Sprintf("\n  attempt %d: %s", i+1, err))
+	}
+
+	return builder.String()
+}
+
+func (e *ImagePushError) Unwrap() []error {
+	return e.Attempts
+}
+
+// The messages of the errors of the container registry and of the network that are expected to succeed on retry
+var transientPushErrors = []string{
+	"500 internal server error",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"received unexpected http status: 5",
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"tls handshake",
+	"toomanyrequests",
+	"429 too many requests",
+}
+
+// Checks whether the push failed with a transient error
+func isTransientPushError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, transientError := range transientPushErrors {
+		if strings.Contains(message, transientError) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Runs the push, retrying it with the retry options of the service while it fails with transient errors.
+// Returns an [ImagePushError] with the error of each attempt when the push fails after being retried.
+func pushWithRetry(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	imageTag string,
+	push *imagePush,
+	pushFn func() error,
+) error {
+	attempts := []error{}
+	err := retry.Do(ctx, serviceConfig.Docker.PushRetry.backoff(), func(ctx context.Context) error {
+		if len(attempts) > 0 {
+			push.setStatus(fmt.Sprintf("Retrying push (attempt %d)", len(attempts)+1))
+		}
+
+		err := pushFn()
+		if err == nil {
+			return nil
+		}
+
+		attempts = append(attempts, err)
+		if !isTransientPushError(err) {
+			return err
+		}
+
+		log.Printf("push of %s failed with a transient error, retrying: %v", imageTag, err)
+		return retry.RetryableError(err)
+	})
+
+	if err == nil {
+		return nil
+	}
+
+	// The push wasn't retried or was canceled
+	if len(attempts) <= 1 || ctx.Err() != nil {
+		return err
+	}
+
+	return &ImagePushError{ImageTag: imageTag, Attempts: attempts}
+}
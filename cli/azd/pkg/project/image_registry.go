@@ -0,0 +1,295 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// DockerRegistryOptions configures a container registry the image of the service is pushed to instead of the container
+// registry of the environment, ex) GitHub Container Registry, Docker Hub or an on-premises registry. Features of Azure
+// Container Registry, ex) remote builds and geo-replication, aren't available for these registries.
+type DockerRegistryOptions struct {
+	// The endpoint of the registry, with an optional namespace, ex) ghcr.io/contoso. Images are pushed to repositories
+	// under the endpoint, ex) ghcr.io/contoso/api:azd-deploy-1700000000.
+	Endpoint ExpandableString `json:"endpoint" yaml:"endpoint"`
+	// The credentials used to log into the registry. Without credentials, the token of the GitHub Actions workflow is
+	// used for GitHub Container Registry in workflows, and the credentials of the docker config otherwise, ex) from a
+	// previous docker login.
+	Credentials DockerRegistryCredentials `json:"credentials" yaml:"credentials"`
+}
+
+// DockerRegistryCredentials are the credentials of a container registry. The password is read from at most one source.
+type DockerRegistryCredentials struct {
+	// The username, ex) ${GITHUB_ACTOR}
+	Username ExpandableString `json:"username" yaml:"username"`
+	// The name of the environment value with the password or token, ex) GHCR_TOKEN. Values of the azd environment are
+	// used before the environment variables of the process.
+	PasswordEnv string `json:"passwordEnv" yaml:"passwordEnv"`
+	// The identifier of the Key Vault secret with the password or token,
+	// ex) https://contoso.vault.azure.net/secrets/ghcr-token
+	PasswordSecret ExpandableString `json:"passwordSecret" yaml:"passwordSecret"`
+}
+
+// The host of GitHub Container Registry
+const githubContainerRegistryHost = "ghcr.io"
+
+// Resolves the endpoint of the registry images are pushed to, and whether it's a registry configured for the service. The
+// registry configured for the service takes precedence over the container registry of the environment. Returns an empty
+// endpoint when no registry is available.
+func resolveRegistryEndpoint(env *environment.Environment, options DockerRegistryOptions) (string, bool, error) {
+	endpoint, err := options.Endpoint.Envsubst(env.Getenv)
+	if err != nil {
+		return "", false, fmt.Errorf("evaluating registry endpoint: %w", err)
+	}
+
+	if endpoint = strings.TrimSuffix(strings.TrimSpace(endpoint), "/"); endpoint != "" {
+		// The namespaces of GitHub Container Registry are the lower case names of the owners, while the names of the
+		// owners, ex) ${GITHUB_REPOSITORY_OWNER}, may be mixed case
+		if host, _, _ := strings.Cut(endpoint, "/"); strings.EqualFold(host, githubContainerRegistryHost) {
+			endpoint = strings.ToLower(endpoint)
+		}
+
+		return endpoint, true, nil
+	}
+
+	endpoint, _ = env.LookupEnv(environment.ContainerRegistryEndpointEnvVarName)
+	return endpoint, false, nil
+}
+
+// Logs into a registry configured for the service with docker, using the configured credentials. Without credentials,
+// docker uses the credentials of its config.
+func (c *ImagePushCoordinator) loginRegistry(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	options *DockerRegistryOptions,
+) error {
+	username, password, err := c.registryCredentials(ctx, subscriptionId, options)
+	if err != nil {
+		return err
+	}
+
+	if password == "" {
+		if actor, token, has := c.githubActionsCredentials(loginServer); has {
+			log.Printf("using the GITHUB_TOKEN of the GitHub Actions workflow for registry %s", loginServer)
+			if username == "" {
+				username = actor
+			}
+			password = token
+		} else {
+			log.Printf("using the credentials of the docker config for registry %s", loginServer)
+			return nil
+		}
+	}
+
+	// Docker logs into the host of the registry, the namespace of the endpoint is part of the repositories
+	host, _, _ := strings.Cut(loginServer, "/")
+	return c.docker.Login(ctx, host, username, password)
+}
+
+// PullCredentials gets the credentials the deploy target pulls the image with, for images pushed to a registry
+// configured for the service. Returns nil for images pushed to the container registry of the environment, which the
+// deploy targets pull with their identity, and for registries without configured credentials, ex) public registries or
+// registries pushed to with the token of a GitHub Actions workflow, which expires with the workflow.
+func (c *ImagePushCoordinator) PullCredentials(
+	ctx context.Context,
+	subscriptionId string,
+	packageDetails *dockerPackageResult,
+) (*azcli.DockerCredentials, error) {
+	if packageDetails.Registry == nil {
+		return nil, nil
+	}
+
+	username, password, err := c.registryCredentials(ctx, subscriptionId, packageDetails.Registry)
+	if err != nil {
+		return nil, err
+	}
+
+	if password == "" {
+		log.Printf("no credentials configured for registry %s, the image is pulled anonymously", packageDetails.LoginServer)
+		return nil, nil
+	}
+
+	host, _, _ := strings.Cut(packageDetails.LoginServer, "/")
+	return &azcli.DockerCredentials{
+		Username:    username,
+		Password:    password,
+		LoginServer: host,
+	}, nil
+}
+
+// Reads the username and the password configured for the registry. Returns an empty password when the registry has no
+// configured password.
+func (c *ImagePushCoordinator) registryCredentials(
+	ctx context.Context,
+	subscriptionId string,
+	options *DockerRegistryOptions,
+) (string, string, error) {
+	credentials := options.Credentials
+	username, err := credentials.Username.Envsubst(c.env.Getenv)
+	if err != nil {
+		return "", "", fmt.Errorf("evaluating registry username: %w", err)
+	}
+
+	secretId, err := credentials.PasswordSecret.Envsubst(c.env.Getenv)
+	if err != nil {
+		return "", "", fmt.Errorf("evaluating registry password secret: %w", err)
+	}
+
+	var password string
+	switch {
+	case credentials.PasswordEnv != "" && secretId != "":
+		return "", "", errors.New("registry credentials must set either 'passwordEnv' or 'passwordSecret', not both")
+	case credentials.PasswordEnv != "":
+		if password = c.env.Getenv(credentials.PasswordEnv); password == "" {
+			return "", "", fmt.Errorf(
+				"environment value '%s' with the registry password is not set", credentials.PasswordEnv)
+		}
+	case secretId != "":
+		vaultUrl, secretName, err := parseKeyVaultSecretId(secretId)
+		if err != nil {
+			return "", "", err
+		}
+
+		secret, err := c.azCli.GetKeyVaultSecret(ctx, subscriptionId, vaultUrl, secretName)
+		if err != nil {
+			return "", "", fmt.Errorf("reading registry password from secret '%s': %w", secretId, err)
+		}
+
+		if secret == nil {
+			return "", "", fmt.Errorf(
+				"reading registry password from secret '%s': the vault couldn't be accessed", secretId)
+		}
+
+		password = secret.Value
+	default:
+		return username, "", nil
+	}
+
+	if username == "" {
+		return "", "", errors.New("registry credentials with a password require 'username'")
+	}
+
+	return username, password, nil
+}
+
+// Sets the registry configured for the service as properties of the service in the environment, so the infrastructure
+// of the service can configure the registry its image is pulled from, ex) the registries of a container app:
+// SERVICE_<NAME>_REGISTRY_SERVER, SERVICE_<NAME>_REGISTRY_USERNAME, and SERVICE_<NAME>_REGISTRY_PASSWORD_SECRET for
+// passwords stored in Key Vault. Passwords read from environment values are referenced by the parameters of the
+// infrastructure directly, ex) ${GHCR_TOKEN}, they're never stored in the environment.
+//
+// Images pulled from a connected registry with pullCreds set the connected registry and the username of its client token,
+// the infrastructure references the password with ${AZURE_CONTAINER_REGISTRY_CONNECTED_PASSWORD}.
+func setRegistryProperties(
+	env *environment.Environment,
+	serviceName string,
+	packageDetails *dockerPackageResult,
+	pullCreds *azcli.DockerCredentials,
+) error {
+	if pullCreds != nil {
+		env.SetServiceProperty(serviceName, "REGISTRY_SERVER", pullCreds.LoginServer)
+		env.SetServiceProperty(serviceName, "REGISTRY_USERNAME", pullCreds.Username)
+		env.SetServiceProperty(serviceName, "REGISTRY_PASSWORD_SECRET", "")
+		return nil
+	}
+
+	if packageDetails.Registry == nil {
+		return nil
+	}
+
+	username, err := packageDetails.Registry.Credentials.Username.Envsubst(env.Getenv)
+	if err != nil {
+		return fmt.Errorf("evaluating registry username: %w", err)
+	}
+
+	secretId, err := packageDetails.Registry.Credentials.PasswordSecret.Envsubst(env.Getenv)
+	if err != nil {
+		return fmt.Errorf("evaluating registry password secret: %w", err)
+	}
+
+	host, _, _ := strings.Cut(packageDetails.LoginServer, "/")
+	env.SetServiceProperty(serviceName, "REGISTRY_SERVER", host)
+	env.SetServiceProperty(serviceName, "REGISTRY_USERNAME", username)
+	env.SetServiceProperty(serviceName, "REGISTRY_PASSWORD_SECRET", secretId)
+	return nil
+}
+
+// Gets the credentials of the GitHub Actions workflow running azd for GitHub Container Registry, so pushing images from
+// workflows doesn't require configuring credentials. The workflow must expose its token to azd as GITHUB_TOKEN, ex)
+// env: GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }}, and grant the token the packages: write permission.
+func (c *ImagePushCoordinator) githubActionsCredentials(loginServer string) (string, string, bool) {
+	host, _, _ := strings.Cut(loginServer, "/")
+	if !strings.EqualFold(host, githubContainerRegistryHost) || os.Getenv("GITHUB_ACTIONS") != "true" {
+		return "", "", false
+	}
+
+	token := c.env.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		log.Printf(
+			"GITHUB_TOKEN is not set, set it in the env of the workflow step to push to %s with the token of the workflow",
+			loginServer,
+		)
+		return "", "", false
+	}
+
+	// The registry accepts the token of the workflow with any username, the actor identifies who pushed the images
+	actor := c.env.Getenv("GITHUB_ACTOR")
+	if actor == "" {
+		actor = "github-actions"
+	}
+
+	return actor, token, true
+}
+
+// Explains how to grant the token of the GitHub Actions workflow running azd the permission to push to GitHub Container
+// Registry, when the registry denied a push from a workflow
+func githubActionsPushError(loginServer string, err error) error {
+	host, _, _ := strings.Cut(loginServer, "/")
+	if !strings.EqualFold(host, githubContainerRegistryHost) ||
+		os.Getenv("GITHUB_ACTIONS") != "true" ||
+		!strings.Contains(strings.ToLower(err.Error()), "denied") {
+		return err
+	}
+
+	return fmt.Errorf(
+		"%w\n\nThe registry denied the push of the workflow, grant its token the permission to push packages with "+
+			"'permissions: packages: write' in the workflow, and set GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }} in the env of "+
+			"the step running azd",
+		err,
+	)
+}
+
+// Parses the identifier of a Key Vault secret, ex) https://contoso.vault.azure.net/secrets/ghcr-token, to the URL of the
+// vault and the name of the secret. The latest version of the secret is used.
+func parseKeyVaultSecretId(secretId string) (string, string, error) {
+	invalidErr := fmt.Errorf(
+		"invalid secret '%s', expected a Key Vault secret, ex) https://contoso.vault.azure.net/secrets/name", secretId)
+
+	parsed, err := url.Parse(secretId)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", "", invalidErr
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 2 || len(segments) > 3 || !strings.EqualFold(segments[0], "secrets") || segments[1] == "" {
+		return "", "", invalidErr
+	}
+
+	if len(segments) == 3 {
+		log.Printf("ignoring version of secret %s, the latest version is used", secretId)
+	}
+
+	return fmt.Sprintf("https://%s", parsed.Host), segments[1], nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"golang.org/x/exp/slices"
+)
+
+// The prefix of the tags of the images for the hash of their source, ex) azd-source-{hash}
+const sourceTagPrefix = "azd-source-"
+
+// Computes the hash of the source of the image of the service, from the files of the build context not excluded by its
+// .dockerignore file, the Dockerfile and the options of the build. Images built from the same source are expected to be
+// the same, so the hash identifies the image in the registry. The values of the build secrets aren't part of the hash.
+func imageSourceHash(
+	serviceConfig *ServiceConfig,
+	options DockerProjectOptions,
+	buildArgs []string,
+	secrets []docker.BuildSecret,
+	buildOptions []string,
+) (string, error) {
+	hash := sha256.New()
+	write := func(name string, values ...string) {
+		fmt.Fprintf(hash, "%s\x00%s\n", name, strings.Join(values, "\x00"))
+	}
+
+	write("platform", options.Platforms()...)
+	write("target", options.Target)
+	write("variant", serviceConfig.Variant())
+	write("buildArgs", buildArgs...)
+	write("buildOptions", buildOptions...)
+	for _, secret := range secrets {
+		write("secret", secret.Id)
+	}
+
+	dockerfilePath := options.Path
+	if !filepath.IsAbs(dockerfilePath) {
+		dockerfilePath = filepath.Join(serviceConfig.Path(), dockerfilePath)
+	}
+
+	dockerfileHash, err := fileSha256(dockerfilePath)
+	if err != nil {
+		return "", fmt.Errorf("hashing Dockerfile: %w", err)
+	}
+	write("dockerfile", dockerfileHash)
+
+	contextPath := buildContextPath(serviceConfig)
+	rules, err := readIgnoreFile(filepath.Join(contextPath, dockerIgnoreFileName), false)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", dockerIgnoreFileName, err)
+	}
+
+	files := []string{}
+	fileHashes := map[string]string{}
+	err = filepath.WalkDir(contextPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(contextPath, filePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+
+		if rules.excludes(relativePath, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		fileHash, err := fileSha256(filePath)
+		if err != nil {
+			return err
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		files = append(files, relativePath)
+		fileHashes[relativePath] = fileHash
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("hashing build context: %w", err)
+	}
+
+	// Files are hashed in the same order on all platforms
+	slices.Sort(files)
+	for _, file := range files {
+		write("file", file, fileHashes[file])
+	}
+
+	// The first 32 characters of the hex encoded hash keep the tags short, while collisions remain unlikely
+	return fmt.Sprintf("%x", hash.Sum(nil))[:32], nil
+}
+
+// Gets the repository of the image of the service in the registry, ex) {project}/{service}-{env}
+func (p *dockerProject) imageRepository(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	imageTag, err := p.generateImageTag(ctx, serviceConfig)
+	if err != nil {
+		return "", fmt.Errorf("generating image tag: %w", err)
+	}
+
+	repository, _ := splitImageTag(imageTag, "")
+	return repository, nil
+}
+
+// Gets the image of the container registry built from the source with the hash, or an empty string when the registry
+// has no image for the hash. Failures looking up the image are logged, and the image is built.
+func (p *dockerProject) unchangedImage(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	loginServer string,
+	sourceHash string,
+) string {
+	repository, err := p.imageRepository(ctx, serviceConfig)
+	if err != nil {
+		log.Printf("failed looking up unchanged image of %s: %v", serviceConfig.Name, err)
+		return ""
+	}
+
+	sourceTag := sourceTagPrefix + sourceHash
+	_, err = p.containerRegistryService.GetManifest(
+		ctx, p.env.GetSubscriptionId(), loginServer, repository, sourceTag)
+
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+		log.Printf("registry %s has no image of %s for source %s", loginServer, serviceConfig.Name, sourceHash)
+		return ""
+	} else if err != nil {
+		log.Printf("failed looking up unchanged image of %s: %v", serviceConfig.Name, err)
+		return ""
+	}
+
+	return fmt.Sprintf("%s/%s:%s", loginServer, repository, sourceTag)
+}
+
+// Gets the hash of the source of the image of the build, or an empty string when the service doesn't reuse unchanged
+// images
+func buildSourceHash(buildOutput *ServiceBuildResult) string {
+	switch details := buildOutput.Details.(type) {
+	case *dockerLocalBuild:
+		return details.SourceHash
+	case *dockerDeferredBuild:
+		return details.SourceHash
+	default:
+		return ""
+	}
+}
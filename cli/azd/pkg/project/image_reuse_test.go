@@ -0,0 +1,177 @@
+package project
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImageSourceHash(t *testing.T) {
+	ostest.Chdir(t, t.TempDir())
+	writeSourceFiles(t, map[string]string{
+		"src/api/Dockerfile":    "FROM node:18",
+		"src/api/.dockerignore": "node_modules\n",
+		"src/api/index.js":      "console.log('hello')",
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageJavaScript)
+	options := getDockerOptionsWithDefaults(serviceConfig.Docker)
+	hash := func(buildArgs ...string) string {
+		sourceHash, err := imageSourceHash(serviceConfig, options, buildArgs, nil, nil)
+		require.NoError(t, err)
+		return sourceHash
+	}
+
+	original := hash()
+	require.Len(t, original, 32)
+
+	// Files excluded from the build context don't change the source
+	writeSourceFiles(t, map[string]string{"src/api/node_modules/lib/index.js": "module.exports = {}"})
+	require.Equal(t, original, hash())
+
+	require.NotEqual(t, original, hash("VERSION=1.0"))
+
+	writeSourceFiles(t, map[string]string{"src/api/index.js": "console.log('hello world')"})
+	require.NotEqual(t, original, hash())
+}
+
+func Test_DockerProject_ReuseUnchanged(t *testing.T) {
+	ostest.Chdir(t, t.TempDir())
+	writeSourceFiles(t, map[string]string{
+		"src/api/Dockerfile": "FROM node:18",
+		"src/api/index.js":   "console.log('hello')",
+	})
+
+	for _, exists := range []bool{true, false} {
+		name := "Unchanged"
+		if !exists {
+			name = "Changed"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			mockGitCommit(mockContext, "COMMIT")
+			setupMocksForBatchJobTarget(mockContext)
+
+			built := false
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "docker build")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				built = true
+				return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+			})
+
+			tags := []string{}
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "docker tag")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				tags = append(tags, args.Args[2])
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodHead &&
+					strings.HasPrefix(request.URL.Path, "/v2/test-app/api-test/manifests/"+sourceTagPrefix)
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				if !exists {
+					return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+				}
+
+				response, _ := mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+				response.Header.Set("Content-Type", azsdk.OciImageManifestMediaType)
+				response.Header.Set("Docker-Content-Digest", "sha256:IMAGE_DIGEST")
+				return response, nil
+			})
+
+			env := environment.EphemeralWithValues("test", map[string]string{
+				environment.SubscriptionIdEnvVarName:            "SUBSCRIPTION_ID",
+				environment.ContainerRegistryEndpointEnvVarName: "REGISTRY.azurecr.io",
+			})
+			serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageJavaScript)
+			serviceConfig.Docker.ReuseUnchanged = true
+
+			dockerProject := createReuseDockerProject(mockContext, env)
+			buildTask := dockerProject.Build(*mockContext.Context, serviceConfig, nil)
+			logProgress(buildTask)
+			buildResult, err := buildTask.Await()
+			require.NoError(t, err)
+			require.Equal(t, !exists, built)
+
+			packageTask := dockerProject.Package(*mockContext.Context, serviceConfig, buildResult)
+			logProgress(packageTask)
+			packageResult, err := packageTask.Await()
+			require.NoError(t, err)
+
+			packageDetails, ok := packageResult.Details.(*dockerPackageResult)
+			require.True(t, ok)
+
+			if exists {
+				// The unchanged image is deployed from the registry, without being pushed again
+				require.True(t, strings.HasPrefix(
+					packageDetails.ImageTag, "REGISTRY.azurecr.io/test-app/api-test:"+sourceTagPrefix))
+				require.Empty(t, packageDetails.LoginServer)
+				require.Empty(t, tags)
+				return
+			}
+
+			// The image is pushed with the tag for the hash of its source
+			require.Equal(t, "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0", packageDetails.ImageTag)
+			require.True(t, strings.HasPrefix(
+				packageDetails.SourceTag, "REGISTRY.azurecr.io/test-app/api-test:"+sourceTagPrefix))
+			require.Equal(t, []string{packageDetails.ImageTag, packageDetails.SourceTag}, tags)
+			require.Equal(t, []string{packageDetails.ImageTag, packageDetails.SourceTag}, packageDetails.Tags())
+		})
+	}
+}
+
+func writeSourceFiles(t *testing.T, files map[string]string) {
+	for path, content := range files {
+		path = filepath.FromSlash(path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(content), osutil.PermissionFile))
+	}
+}
+
+func createReuseDockerProject(mockContext *mocks.MockContext, env *environment.Environment) CompositeFrameworkService {
+	credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+		func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		})
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+
+	return NewDockerProject(
+		env,
+		dockerCli,
+		git.NewGitCli(mockContext.CommandRunner),
+		syft.NewSyftCli(mockContext.CommandRunner),
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		azcli.NewContainerRegistryService(credentialProvider, mockContext.HttpClient, dockerCli),
+		mockContext.Console,
+		clock.NewMock(),
+	)
+}
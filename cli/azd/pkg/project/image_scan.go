@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	// The scanner used when the scanner of the vulnerability scan isn't set
+	defaultImageScanner = "trivy"
+	// The severity threshold used when the severity of the vulnerability scan isn't set
+	defaultScanSeverity = "CRITICAL"
+	// The maximum number of vulnerabilities listed by a vulnerability scan error
+	maxListedVulnerabilities = 20
+)
+
+// The severities of vulnerabilities that can be used as the threshold of the vulnerability scan, from the lowest
+var scanSeverities = []string{"LOW", "MEDIUM", "HIGH", "CRITICAL"}
+
+// DockerScanOptions configures the vulnerability scan of the image of the service. The image is scanned when the service
+// is packaged, so images with vulnerabilities at or above the severity threshold fail the deployment before they're
+// pushed. Images built when they're pushed, ex) multi-platform or remote builds, are scanned in the registry once they're
+// pushed, before the deploy target is updated.
+type DockerScanOptions struct {
+	// When enabled, the image is scanned for vulnerabilities when the service is packaged
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// The scanner, trivy or grype. Defaults to trivy.
+	Scanner string `json:"scanner" yaml:"scanner"`
+	// The lowest severity of the vulnerabilities failing the scan, LOW, MEDIUM, HIGH or CRITICAL. Defaults to CRITICAL.
+	Severity string `json:"severity" yaml:"severity"`
+	// When enabled, vulnerabilities without a fixed version don't fail the scan
+	IgnoreUnfixed bool `json:"ignoreUnfixed" yaml:"ignoreUnfixed"`
+	// The ids of the vulnerabilities that don't fail the scan, ex) CVE-2023-12345
+	Ignore []string `json:"ignore" yaml:"ignore"`
+}
+
+// ImageVulnerability is a vulnerability of a package of an image found by the vulnerability scan
+type ImageVulnerability struct {
+	Id               string
+	Package          string
+	InstalledVersion string
+	// The version of the package fixing the vulnerability, empty when the vulnerability isn't fixed
+	FixedVersion string
+	// The severity of the vulnerability, ex) CRITICAL
+	Severity string
+}
+
+func (v ImageVulnerability) String() string {
+	fixed := "no fix available"
+	if v.FixedVersion != "" {
+		fixed = fmt.Sprintf("fixed in %s", v.FixedVersion)
+	}
+
+	return fmt.Sprintf("%s %s: %s %s, %s", v.Severity, v.Id, v.Package, v.InstalledVersion, fixed)
+}
+
+// The result of the vulnerability scan of the image of a service
+type dockerScanResult struct {
+	// The scanner of the image, ex) trivy
+	Scanner string
+	// The lowest severity of the vulnerabilities failing the scan
+	Severity string
+	// The number of vulnerabilities found by severity, ex) HIGH: 2. Vulnerabilities below the severity threshold or
+	// ignored don't fail the scan.
+	Counts map[string]int
+}
+
+// VulnerabilityScanError is returned when the image of a service has vulnerabilities at or above the severity threshold
+// of the vulnerability scan
+type VulnerabilityScanError struct {
+	ServiceName     string
+	Image           string
+	Severity        string
+	Vulnerabilities []ImageVulnerability
+}
+
+func (e *VulnerabilityScanError) Error() string {
+	lines := []string{
+		fmt.Sprintf(
+			"found %d vulnerabilities with severity %s or higher in the image of service '%s' (%s):",
+			len(e.Vulnerabilities),
+			e.Severity,
+			e.ServiceName,
+			e.Image,
+		),
+	}
+
+	for i, vulnerability := range e.Vulnerabilities {
+		if i == maxListedVulnerabilities {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(e.Vulnerabilities)-maxListedVulnerabilities))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s", vulnerability))
+	}
+
+	lines = append(lines,
+		"Update the affected packages, raise 'docker.scan.severity' or ignore the vulnerabilities with "+
+			"'docker.scan.ignore' in azure.yaml",
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+// Gets the severity threshold of the scan, validating the severity is supported
+func (o DockerScanOptions) severity() (string, error) {
+	if o.Severity == "" {
+		return defaultScanSeverity, nil
+	}
+
+	severity := strings.ToUpper(o.Severity)
+	if !slices.Contains(scanSeverities, severity) {
+		return "", fmt.Errorf(
+			"unsupported scan severity '%s', supported severities: %s",
+			o.Severity,
+			strings.Join(scanSeverities, ", "),
+		)
+	}
+
+	return severity, nil
+}
+
+// Scans the image of the service for vulnerabilities with the scanner of the service. Images are read from the local
+// image store, or from their registry when remote is set.
+// Returns a [VulnerabilityScanError] when the image has vulnerabilities at or above the severity threshold.
+func scanImage(
+	ctx context.Context,
+	trivyCli trivy.TrivyCli,
+	grypeCli grype.GrypeCli,
+	serviceConfig *ServiceConfig,
+	image string,
+	remote bool,
+) (*dockerScanResult, error) {
+	options := serviceConfig.Docker.Scan
+	severity, err := options.severity()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := options.Scanner
+	if scanner == "" {
+		scanner = defaultImageScanner
+	}
+
+	var vulnerabilities []ImageVulnerability
+	switch scanner {
+	case "trivy":
+		if err := tools.EnsureInstalled(ctx, trivyCli); err != nil {
+			return nil, err
+		}
+
+		log.Printf("scanning %s for vulnerabilities with trivy", image)
+		results, err := trivyCli.ScanImage(ctx, serviceConfig.Path(), image, remote)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range results {
+			vulnerabilities = append(vulnerabilities, ImageVulnerability{
+				Id:               result.VulnerabilityID,
+				Package:          result.PkgName,
+				InstalledVersion: result.InstalledVersion,
+				FixedVersion:     result.FixedVersion,
+				Severity:         strings.ToUpper(result.Severity),
+			})
+		}
+	case "grype":
+		if err := tools.EnsureInstalled(ctx, grypeCli); err != nil {
+			return nil, err
+		}
+
+		log.Printf("scanning %s for vulnerabilities with grype", image)
+		matches, err := grypeCli.ScanImage(ctx, serviceConfig.Path(), image, remote)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			vulnerabilities = append(vulnerabilities, ImageVulnerability{
+				Id:               match.Vulnerability.Id,
+				Package:          match.Artifact.Name,
+				InstalledVersion: match.Artifact.Version,
+				FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+				Severity:         strings.ToUpper(match.Vulnerability.Severity),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported image scanner '%s', supported scanners: trivy, grype", scanner)
+	}
+
+	result := &dockerScanResult{
+		Scanner:  scanner,
+		Severity: severity,
+		Counts:   map[string]int{},
+	}
+
+	failing := []ImageVulnerability{}
+	threshold := slices.Index(scanSeverities, severity)
+	for _, vulnerability := range vulnerabilities {
+		result.Counts[vulnerability.Severity]++
+
+		// Unknown and negligible severities never fail the scan
+		if slices.Index(scanSeverities, vulnerability.Severity) < threshold ||
+			slices.Contains(options.Ignore, vulnerability.Id) ||
+			(options.IgnoreUnfixed && vulnerability.FixedVersion == "") {
+			continue
+		}
+
+		failing = append(failing, vulnerability)
+	}
+
+	if len(failing) > 0 {
+		// The most severe vulnerabilities are listed first
+		slices.SortStableFunc(failing, func(a, b ImageVulnerability) bool {
+			return slices.Index(scanSeverities, a.Severity) > slices.Index(scanSeverities, b.Severity)
+		})
+
+		return nil, &VulnerabilityScanError{
+			ServiceName:     serviceConfig.Name,
+			Image:           image,
+			Severity:        severity,
+			Vulnerabilities: failing,
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ScanImage(t *testing.T) {
+	trivyCli := &fakeTrivyCli{
+		vulnerabilities: []trivy.Vulnerability{
+			{VulnerabilityID: "CVE-1", PkgName: "openssl", InstalledVersion: "3.0.1", Severity: "HIGH"},
+			{VulnerabilityID: "CVE-2", PkgName: "zlib", InstalledVersion: "1.2", FixedVersion: "1.3", Severity: "CRITICAL"},
+			{VulnerabilityID: "CVE-3", PkgName: "curl", InstalledVersion: "7.0", Severity: "LOW"},
+			{VulnerabilityID: "CVE-4", PkgName: "bash", InstalledVersion: "5.0", Severity: "UNKNOWN"},
+		},
+	}
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+
+	t.Run("Fails", func(t *testing.T) {
+		serviceConfig.Docker.Scan = DockerScanOptions{Enabled: true, Severity: "high"}
+
+		_, err := scanImage(context.Background(), trivyCli, nil, serviceConfig, "api:latest", false)
+		scanErr := &VulnerabilityScanError{}
+		require.True(t, errors.As(err, &scanErr))
+		require.Equal(t, "HIGH", scanErr.Severity)
+		require.Len(t, scanErr.Vulnerabilities, 2)
+		// The most severe vulnerabilities are listed first
+		require.Equal(t, "CVE-2", scanErr.Vulnerabilities[0].Id)
+		require.Contains(t, err.Error(), "CRITICAL CVE-2: zlib 1.2, fixed in 1.3")
+	})
+
+	t.Run("Passes", func(t *testing.T) {
+		serviceConfig.Docker.Scan = DockerScanOptions{Enabled: true, IgnoreUnfixed: true}
+
+		// Vulnerabilities with a fix still fail the scan when unfixed vulnerabilities are ignored
+		result, err := scanImage(context.Background(), trivyCli, nil, serviceConfig, "api:latest", false)
+		require.ErrorContains(t, err, "CVE-2")
+		require.Nil(t, result)
+
+		serviceConfig.Docker.Scan.Ignore = []string{"CVE-2"}
+		result, err = scanImage(context.Background(), trivyCli, nil, serviceConfig, "api:latest", false)
+		require.NoError(t, err)
+		require.Equal(t, &dockerScanResult{
+			Scanner:  "trivy",
+			Severity: "CRITICAL",
+			Counts:   map[string]int{"CRITICAL": 1, "HIGH": 1, "LOW": 1, "UNKNOWN": 1},
+		}, result)
+	})
+
+	t.Run("Grype", func(t *testing.T) {
+		match := grype.Match{}
+		match.Vulnerability.Id = "CVE-5"
+		match.Vulnerability.Severity = "Medium"
+		match.Artifact.Name = "libxml2"
+		grypeCli := &fakeGrypeCli{matches: []grype.Match{match}}
+		serviceConfig.Docker.Scan = DockerScanOptions{Enabled: true, Scanner: "grype", Severity: "MEDIUM"}
+
+		_, err := scanImage(context.Background(), nil, grypeCli, serviceConfig, "api:latest", true)
+		require.ErrorContains(t, err, "MEDIUM CVE-5: libxml2")
+	})
+
+	t.Run("UnsupportedSeverity", func(t *testing.T) {
+		serviceConfig.Docker.Scan = DockerScanOptions{Enabled: true, Severity: "urgent"}
+
+		_, err := scanImage(context.Background(), trivyCli, nil, serviceConfig, "api:latest", false)
+		require.ErrorContains(t, err, "unsupported scan severity 'urgent'")
+	})
+}
+
+type fakeTrivyCli struct {
+	fakeTool
+	vulnerabilities []trivy.Vulnerability
+}
+
+func (cli *fakeTrivyCli) ScanImage(
+	ctx context.Context,
+	cwd string,
+	image string,
+	remote bool,
+) ([]trivy.Vulnerability, error) {
+	return cli.vulnerabilities, nil
+}
+
+type fakeGrypeCli struct {
+	fakeTool
+	matches []grype.Match
+}
+
+func (cli *fakeGrypeCli) ScanImage(ctx context.Context, cwd string, image string, remote bool) ([]grype.Match, error) {
+	return cli.matches, nil
+}
@@ -0,0 +1,226 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// The signing tool used when the tool of the image signing isn't set
+const defaultImageSigner = "notation"
+
+// DockerSignOptions configures the signing of the image of the service with a key stored in Azure Key Vault. The image is
+// signed once it's pushed to the container registry, and the signature is stored next to the image in the registry so
+// policy engines can verify the image before it's run.
+type DockerSignOptions struct {
+	// When enabled, the image is signed once it's pushed to the container registry
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// The signing tool, notation or cosign. Defaults to notation.
+	Tool string `json:"tool" yaml:"tool"`
+	// The identifier of the Key Vault key, ex) https://contoso.vault.azure.net/keys/signing/{version}. Notation signs with
+	// the certificate of the key and requires the version of the key. Cosign signs with the version of the key when set,
+	// and with the latest version of the key otherwise.
+	KeyId ExpandableString `json:"keyId" yaml:"keyId"`
+}
+
+// ImageSignature is the signature of the image pushed for a service
+type ImageSignature struct {
+	// The signing tool, ex) notation
+	Tool string `json:"tool"`
+	// The signed image, ex) {registry}/{repository}@sha256:{digest}
+	Image string `json:"image"`
+	// The digest of the signature stored in the registry of the image
+	Digest string `json:"digest"`
+	// The identifier of the Key Vault key the image was signed with
+	KeyId string `json:"keyId"`
+}
+
+// ImageSignature gets the signature of the image of the package, or nil when the image isn't signed
+func (r *ServicePackageResult) ImageSignature() *ImageSignature {
+	if r == nil {
+		return nil
+	}
+
+	if details, ok := r.Details.(*dockerPackageResult); ok {
+		return details.Signature
+	}
+
+	return nil
+}
+
+// Signs the pushed image of the service when signing is enabled, saving the signature to the package details
+func (c *ImagePushCoordinator) sign(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	push *imagePush,
+) error {
+	options := serviceConfig.Docker.Sign
+	if !options.Enabled {
+		return nil
+	}
+
+	keyId, err := options.KeyId.Envsubst(c.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("evaluating signing key: %w", err)
+	}
+
+	if keyId == "" {
+		return errors.New("signing the image requires 'docker.sign.keyId', the identifier of the Key Vault key")
+	}
+
+	tool := options.Tool
+	if tool == "" {
+		tool = defaultImageSigner
+	}
+
+	if tool != "notation" && tool != "cosign" {
+		return fmt.Errorf("unsupported signing tool '%s', supported tools: notation, cosign", tool)
+	}
+
+	if err := tools.EnsureInstalled(ctx, c.oras); err != nil {
+		return err
+	}
+
+	// The image is signed by the digest it was pushed with, the tag may be moved to another image before it's signed
+	imageDigest, err := c.oras.Resolve(ctx, packageDetails.ImageTag)
+	if err != nil {
+		return fmt.Errorf("resolving digest of image '%s': %w", packageDetails.ImageTag, err)
+	}
+
+	repository, _ := splitImageTag(packageDetails.ImageTag, "")
+	image := fmt.Sprintf("%s@%s", repository, imageDigest)
+
+	log.Printf("signing %s with %s", image, tool)
+	push.setStatus("Signing image")
+
+	var signature *ImageSignature
+	if tool == "notation" {
+		signature, err = c.signWithNotation(ctx, serviceConfig, image, keyId)
+	} else {
+		signature, err = c.signWithCosign(ctx, serviceConfig, image, keyId)
+	}
+
+	if err != nil {
+		return fmt.Errorf("signing image: %w", err)
+	}
+
+	signature.Tool = tool
+	signature.KeyId = keyId
+	packageDetails.Signature = signature
+	return nil
+}
+
+func (c *ImagePushCoordinator) signWithNotation(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	image string,
+	keyId string,
+) (*ImageSignature, error) {
+	if err := tools.EnsureInstalled(ctx, c.notation); err != nil {
+		return nil, err
+	}
+
+	signedImage, err := c.notation.Sign(ctx, serviceConfig.Path(), image, keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := c.notation.Signatures(ctx, serviceConfig.Path(), signedImage)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signatures) == 0 {
+		return nil, fmt.Errorf("the signature of image '%s' isn't in its registry", signedImage)
+	}
+
+	// Images signed before have several signatures, the latest signature is the signature just created
+	latest := signatures[0]
+	for _, signature := range signatures[1:] {
+		if signature.SignedAttributes.SigningTime.After(latest.SignedAttributes.SigningTime) {
+			latest = signature
+		}
+	}
+
+	return &ImageSignature{Image: signedImage, Digest: latest.Digest}, nil
+}
+
+func (c *ImagePushCoordinator) signWithCosign(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	image string,
+	keyId string,
+) (*ImageSignature, error) {
+	if err := tools.EnsureInstalled(ctx, c.cosign); err != nil {
+		return nil, err
+	}
+
+	key, err := cosignKey(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cosign.Sign(ctx, serviceConfig.Path(), image, key); err != nil {
+		return nil, err
+	}
+
+	// The signature is stored with the tag sha256-{digest}.sig, ex) contoso.azurecr.io/api:sha256-{digest}.sig
+	signatureRef, err := c.cosign.Triangulate(ctx, serviceConfig.Path(), image)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, tag, has := cutLast(signatureRef, ":"); !has || !strings.HasPrefix(tag, "sha256-") {
+		return nil, fmt.Errorf("unexpected signature reference '%s'", signatureRef)
+	}
+
+	digest, err := c.oras.Resolve(ctx, signatureRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageSignature{Image: image, Digest: digest}, nil
+}
+
+// Gets the cosign reference of the Key Vault key, ex) azurekms://contoso.vault.azure.net/signing/{version} for the key
+// https://contoso.vault.azure.net/keys/signing/{version}. The version is kept, so images keep being signed with the
+// same version after the key is rotated. Cosign references of keys are used as-is.
+func cosignKey(keyId string) (string, error) {
+	if strings.HasPrefix(keyId, "azurekms://") {
+		return keyId, nil
+	}
+
+	keyUrl, err := url.Parse(keyId)
+	if err != nil {
+		return "", fmt.Errorf("parsing key identifier '%s': %w", keyId, err)
+	}
+
+	segments := strings.Split(strings.Trim(keyUrl.Path, "/"), "/")
+	if keyUrl.Host == "" || len(segments) < 2 || len(segments) > 3 || segments[0] != "keys" || segments[1] == "" {
+		return "", fmt.Errorf(
+			"invalid key identifier '%s', expected https://{vault}.vault.azure.net/keys/{name}/{version}",
+			keyId,
+		)
+	}
+
+	return fmt.Sprintf("azurekms://%s/%s", keyUrl.Host, strings.Join(segments[1:], "/")), nil
+}
+
+// Slices the value around the last instance of the separator
+func cutLast(value string, separator string) (string, string, bool) {
+	index := strings.LastIndex(value, separator)
+	if index < 0 {
+		return value, "", false
+	}
+
+	return value[:index], value[index+len(separator):], true
+}
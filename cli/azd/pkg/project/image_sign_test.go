@@ -0,0 +1,163 @@
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ImagePushCoordinator_Sign(t *testing.T) {
+	const keyId = "https://contoso.vault.azure.net/keys/signing/0123"
+	const imageDigest = "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b"
+	env := environment.EphemeralWithValues("test", map[string]string{"SIGNING_KEY_ID": keyId})
+
+	t.Run("Notation", func(t *testing.T) {
+		signatures := []notation.Signature{{Digest: "sha256:old"}, {Digest: "sha256:new"}}
+		signatures[0].SignedAttributes.SigningTime = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		signatures[1].SignedAttributes.SigningTime = time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+		notationCli := &fakeNotationCli{signedImage: "contoso.azurecr.io/api@" + imageDigest, signatures: signatures}
+		orasCli := &fakeOrasCli{digest: imageDigest}
+		coordinator := &ImagePushCoordinator{env: env, notation: notationCli, oras: orasCli}
+
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+		serviceConfig.Docker.Sign = DockerSignOptions{Enabled: true, KeyId: NewExpandableString("${SIGNING_KEY_ID}")}
+		packageDetails := &dockerPackageResult{ImageTag: "contoso.azurecr.io/api:azd-deploy-1", LoginServer: "contoso.azurecr.io"}
+
+		err := coordinator.sign(context.Background(), serviceConfig, packageDetails, &imagePush{})
+		require.NoError(t, err)
+		require.Equal(t, keyId, notationCli.keyId)
+		// The image is signed by digest, not by its tag
+		require.Equal(t, "contoso.azurecr.io/api@"+imageDigest, notationCli.image)
+		// The latest signature is the signature of the push
+		require.Equal(t, &ImageSignature{
+			Tool:   "notation",
+			Image:  "contoso.azurecr.io/api@" + imageDigest,
+			Digest: "sha256:new",
+			KeyId:  keyId,
+		}, packageDetails.Signature)
+		require.Equal(t, packageDetails.Signature, (&ServicePackageResult{Details: packageDetails}).ImageSignature())
+	})
+
+	t.Run("Cosign", func(t *testing.T) {
+		cosignCli := &fakeCosignCli{
+			signatureRef: "contoso.azurecr.io/api:sha256-6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b.sig",
+		}
+		orasCli := &fakeOrasCli{digests: map[string]string{
+			"contoso.azurecr.io/api:azd-deploy-1": imageDigest,
+			cosignCli.signatureRef:                "sha256:signature",
+		}}
+		coordinator := &ImagePushCoordinator{env: env, cosign: cosignCli, oras: orasCli}
+
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+		serviceConfig.Docker.Sign = DockerSignOptions{Enabled: true, Tool: "cosign", KeyId: NewExpandableString(keyId)}
+		packageDetails := &dockerPackageResult{ImageTag: "contoso.azurecr.io/api:azd-deploy-1", LoginServer: "contoso.azurecr.io"}
+
+		err := coordinator.sign(context.Background(), serviceConfig, packageDetails, &imagePush{})
+		require.NoError(t, err)
+		require.Equal(t, "azurekms://contoso.vault.azure.net/signing/0123", cosignCli.key)
+		require.Equal(t, "contoso.azurecr.io/api@"+imageDigest, cosignCli.image)
+		require.Equal(t, &ImageSignature{
+			Tool:   "cosign",
+			Image:  "contoso.azurecr.io/api@" + imageDigest,
+			Digest: "sha256:signature",
+			KeyId:  keyId,
+		}, packageDetails.Signature)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		coordinator := &ImagePushCoordinator{env: env}
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+		serviceConfig.Docker.Sign = DockerSignOptions{Enabled: true}
+
+		err := coordinator.sign(context.Background(), serviceConfig, &dockerPackageResult{}, &imagePush{})
+		require.ErrorContains(t, err, "'docker.sign.keyId'")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		coordinator := &ImagePushCoordinator{env: env}
+		serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+		packageDetails := &dockerPackageResult{ImageTag: "contoso.azurecr.io/api:azd-deploy-1"}
+
+		err := coordinator.sign(context.Background(), serviceConfig, packageDetails, &imagePush{})
+		require.NoError(t, err)
+		require.Nil(t, packageDetails.Signature)
+	})
+}
+
+func Test_CosignKey(t *testing.T) {
+	key, err := cosignKey("https://contoso.vault.azure.net/keys/signing")
+	require.NoError(t, err)
+	require.Equal(t, "azurekms://contoso.vault.azure.net/signing", key)
+
+	// The version of the key is kept, so rotating the key doesn't change the key images are signed with
+	key, err = cosignKey("https://contoso.vault.azure.net/keys/signing/0123")
+	require.NoError(t, err)
+	require.Equal(t, "azurekms://contoso.vault.azure.net/signing/0123", key)
+
+	key, err = cosignKey("azurekms://contoso.vault.azure.net/signing")
+	require.NoError(t, err)
+	require.Equal(t, "azurekms://contoso.vault.azure.net/signing", key)
+
+	_, err = cosignKey("https://contoso.vault.azure.net/secrets/signing")
+	require.ErrorContains(t, err, "invalid key identifier")
+}
+
+type fakeNotationCli struct {
+	fakeTool
+	signedImage string
+	signatures  []notation.Signature
+	image       string
+	keyId       string
+}
+
+func (cli *fakeNotationCli) Sign(ctx context.Context, cwd string, image string, keyId string) (string, error) {
+	cli.image = image
+	cli.keyId = keyId
+	return cli.signedImage, nil
+}
+
+func (cli *fakeNotationCli) Signatures(ctx context.Context, cwd string, image string) ([]notation.Signature, error) {
+	return cli.signatures, nil
+}
+
+type fakeCosignCli struct {
+	fakeTool
+	signatureRef string
+	image        string
+	key          string
+}
+
+func (cli *fakeCosignCli) Sign(ctx context.Context, cwd string, image string, key string) error {
+	cli.image = image
+	cli.key = key
+	return nil
+}
+
+func (cli *fakeCosignCli) Triangulate(ctx context.Context, cwd string, image string) (string, error) {
+	return cli.signatureRef, nil
+}
+
+type fakeOrasCli struct {
+	fakeTool
+	digest string
+	// The digests of the references resolved to a digest other than digest
+	digests  map[string]string
+	attached []string
+}
+
+func (cli *fakeOrasCli) Attach(ctx context.Context, image string, artifactType string, filePath string) error {
+	cli.attached = append(cli.attached, image)
+	return nil
+}
+
+func (cli *fakeOrasCli) Resolve(ctx context.Context, reference string) (string, error) {
+	if digest, has := cli.digests[reference]; has {
+		return digest, nil
+	}
+
+	return cli.digest, nil
+}
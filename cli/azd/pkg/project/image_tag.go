@@ -0,0 +1,195 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/blang/semver/v4"
+)
+
+// The tokens of image tags, ex) {git-sha} or {semver-from-file:./VERSION}
+var imageTagTokenRegex = regexp.MustCompile(`\{([a-z-]+)(?::([^{}]+))?\}`)
+
+// The files the version of the service is read from for {semver-from-file}, in order
+var defaultVersionFiles = []string{"VERSION", "package.json", "pyproject.toml"}
+
+var (
+	pyprojectVersionRegex = regexp.MustCompile(`(?m)^version\s*=\s*["']([^"']+)["']`)
+	csprojVersionRegex    = regexp.MustCompile(`<Version>\s*([^<\s]+)\s*</Version>`)
+)
+
+// Expands the tokens of the configured image tag of the service. The tokens are:
+//   - {git-sha}: the commit of the source of the service
+//   - {git-short-sha}: the first 7 characters of the commit of the source of the service
+//   - {env}: the name of the environment
+//   - {timestamp}: the time of the package in seconds since the Unix epoch, as in the default tag
+//   - {semver-from-file}: the version of the service, read from the VERSION, package.json, pyproject.toml or project file
+//     of the service, or from the file of the token, ex) {semver-from-file:./version.txt}
+func (p *dockerProject) expandImageTag(ctx context.Context, serviceConfig *ServiceConfig, tag string) (string, error) {
+	var expandErr error
+	var commit string
+
+	expanded := imageTagTokenRegex.ReplaceAllStringFunc(tag, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+
+		groups := imageTagTokenRegex.FindStringSubmatch(match)
+		token, arg := groups[1], groups[2]
+
+		switch token {
+		case "git-sha", "git-short-sha":
+			if commit == "" {
+				commit, expandErr = p.gitCli.GetCurrentCommit(ctx, serviceConfig.Path())
+				if errors.Is(expandErr, git.ErrNotRepository) {
+					expandErr = fmt.Errorf("'{%s}' requires the service to be in a git repository", token)
+				}
+				if expandErr != nil {
+					return match
+				}
+			}
+
+			if token == "git-short-sha" && len(commit) > 7 {
+				return commit[:7]
+			}
+
+			return commit
+		case "env":
+			return p.env.GetEnvName()
+		case "timestamp":
+			return strconv.FormatInt(p.clock.Now().Unix(), 10)
+		case "semver-from-file":
+			var version string
+			version, expandErr = readServiceVersion(serviceConfig, arg)
+			return version
+		default:
+			expandErr = fmt.Errorf(
+				"unknown token '%s', supported tokens: {git-sha}, {git-short-sha}, {env}, {timestamp}, {semver-from-file}",
+				match,
+			)
+			return match
+		}
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+
+	return expanded, nil
+}
+
+// Reads the semantic version of the service from the file, or from the first version file found in the service directory
+func readServiceVersion(serviceConfig *ServiceConfig, file string) (string, error) {
+	files := defaultVersionFiles
+	if file != "" {
+		files = []string{file}
+	} else if projectFiles, err := filepath.Glob(filepath.Join(serviceConfig.Path(), "*.??proj")); err == nil {
+		for _, projectFile := range projectFiles {
+			files = append(files, filepath.Base(projectFile))
+		}
+	}
+
+	for _, name := range files {
+		path := filepath.Join(serviceConfig.Path(), name)
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && file == "" {
+			continue
+		} else if err != nil {
+			return "", fmt.Errorf("reading version file: %w", err)
+		}
+
+		version, err := parseVersionFile(name, content)
+		if err != nil {
+			return "", fmt.Errorf("reading version from '%s': %w", path, err)
+		}
+
+		// Versions such as v1.2 are normalized to 1.2.0
+		parsed, err := semver.ParseTolerant(version)
+		if err != nil {
+			return "", fmt.Errorf("'%s' in '%s' isn't a semantic version: %w", version, path, err)
+		}
+
+		return parsed.String(), nil
+	}
+
+	return "", fmt.Errorf(
+		"no version file found in '%s', add one of: %s, or set the file, ex) {semver-from-file:./version.txt}",
+		serviceConfig.Path(),
+		strings.Join(defaultVersionFiles, ", "),
+	)
+}
+
+// Gets the version in the content of the version file, by the kind of the file
+func parseVersionFile(name string, content []byte) (string, error) {
+	var version string
+	switch ext := filepath.Ext(name); {
+	case filepath.Base(name) == "package.json":
+		var packageJson struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(content, &packageJson); err != nil {
+			return "", err
+		}
+		version = packageJson.Version
+	case filepath.Base(name) == "pyproject.toml":
+		if match := pyprojectVersionRegex.FindSubmatch(content); match != nil {
+			version = string(match[1])
+		}
+	case ext == ".csproj" || ext == ".fsproj" || ext == ".vbproj":
+		if match := csprojVersionRegex.FindSubmatch(content); match != nil {
+			version = string(match[1])
+		}
+	default:
+		version = strings.TrimSpace(string(content))
+	}
+
+	if version == "" {
+		return "", errors.New("the file has no version")
+	}
+
+	return version, nil
+}
+
+// Generates the tag of the image of the service, the configured tag with its tokens expanded, ex) api:{git-short-sha}, or
+// {project}/{service}-{env}:azd-deploy-{timestamp} when the tag isn't configured
+func (p *dockerProject) generateImageTag(ctx context.Context, serviceConfig *ServiceConfig) (string, error) {
+	configuredTag, err := serviceConfig.Docker.Tag.Envsubst(p.env.Getenv)
+	if err != nil {
+		return "", err
+	}
+
+	if configuredTag != "" {
+		configuredTag, err = p.expandImageTag(ctx, serviceConfig, configuredTag)
+		if err != nil {
+			return "", err
+		}
+
+		return variantImageTag(configuredTag, serviceConfig.Variant()), nil
+	}
+
+	return variantImageTag(fmt.Sprintf("%s:%s%d",
+		defaultImageRepository(serviceConfig, p.env.GetEnvName()),
+		deployTagPrefix,
+		p.clock.Now().Unix(),
+	), serviceConfig.Variant()), nil
+}
+
+// Gets the repository of the images of the service when the tag of the image isn't configured, ex) {project}/{service}-{env}
+func defaultImageRepository(serviceConfig *ServiceConfig, envName string) string {
+	return fmt.Sprintf("%s/%s-%s",
+		strings.ToLower(serviceConfig.Project.Name),
+		strings.ToLower(serviceConfig.Name),
+		strings.ToLower(envName),
+	)
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExpandImageTag_SemverFromFile(t *testing.T) {
+	dockerProject := &dockerProject{
+		env:   environment.EphemeralWithValues("dev", map[string]string{}),
+		clock: clock.NewMock(),
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		tag     string
+		want    string
+	}{
+		{"VersionFile", "VERSION", "v1.2\n", "api:{semver-from-file}", "api:1.2.0"},
+		{"PackageJson", "package.json", `{"name": "api", "version": "2.0.1-beta.1"}`,
+			"api:{semver-from-file}", "api:2.0.1-beta.1"},
+		{"Pyproject", "pyproject.toml", "[project]\nname = \"api\"\nversion = \"0.3.0\"\n",
+			"api:{semver-from-file}", "api:0.3.0"},
+		{"Csproj", "Api.csproj", "<Project><PropertyGroup><Version>4.1.0</Version></PropertyGroup></Project>",
+			"api:{semver-from-file}", "api:4.1.0"},
+		{"File", "version.txt", "3.0.0", "api:{semver-from-file:version.txt}-{env}", "api:3.0.0-dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceConfig := createTestServiceConfig(".", ContainerAppTarget, ServiceLanguageDocker)
+			serviceConfig.Project.Path = t.TempDir()
+			err := os.WriteFile(filepath.Join(serviceConfig.Path(), tt.file), []byte(tt.content), osutil.PermissionFile)
+			require.NoError(t, err)
+
+			tag, err := dockerProject.expandImageTag(context.Background(), serviceConfig, tt.tag)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tag)
+		})
+	}
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(".", ContainerAppTarget, ServiceLanguageDocker)
+		serviceConfig.Project.Path = t.TempDir()
+		err := os.WriteFile(filepath.Join(serviceConfig.Path(), "VERSION"), []byte("latest"), osutil.PermissionFile)
+		require.NoError(t, err)
+
+		_, err = dockerProject.expandImageTag(context.Background(), serviceConfig, "api:{semver-from-file}")
+		require.ErrorContains(t, err, "isn't a semantic version")
+	})
+
+	t.Run("MissingVersionFile", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(".", ContainerAppTarget, ServiceLanguageDocker)
+		serviceConfig.Project.Path = t.TempDir()
+
+		_, err := dockerProject.expandImageTag(context.Background(), serviceConfig, "api:{semver-from-file}")
+		require.ErrorContains(t, err, "no version file found")
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(".", ContainerAppTarget, ServiceLanguageDocker)
+
+		_, err := dockerProject.expandImageTag(context.Background(), serviceConfig, "api:{branch}")
+		require.ErrorContains(t, err, "unknown token '{branch}'")
+	})
+}
@@ -0,0 +1,245 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kiota"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The languages of the clients generated from OpenAPI descriptions, and their kiota languages
+var openApiClientLanguages = map[string]string{
+	"typescript": kiota.LanguageTypeScript,
+	"csharp":     kiota.LanguageCSharp,
+	"python":     kiota.LanguagePython,
+}
+
+// The languages of the clients generated for the services of each language, when the language of the client isn't set
+var defaultOpenApiClientLanguages = map[ServiceLanguageKind]string{
+	ServiceLanguageJavaScript: "typescript",
+	ServiceLanguageTypeScript: "typescript",
+	ServiceLanguageDotNet:     "csharp",
+	ServiceLanguageCsharp:     "csharp",
+	ServiceLanguagePython:     "python",
+}
+
+// OpenApiOptions are the OpenAPI description of the HTTP API of the service and the clients of the API generated into the
+// source of the services consuming the API, so the contracts between the services stay in sync
+type OpenApiOptions struct {
+	// The path of the OpenAPI description of the API relative to the service path, ex) ./openapi.yaml
+	Spec string `yaml:"spec"`
+	// The clients of the API generated for the services consuming the API
+	Clients []OpenApiClientOptions `yaml:"clients"`
+}
+
+// OpenApiClientOptions is a typed client of the API of the service, generated with kiota by azd restore --generate-clients
+type OpenApiClientOptions struct {
+	// The name of the service consuming the API, ex) web
+	Service string `yaml:"service"`
+	// The language of the client, typescript, csharp or python. Defaults to the language of the consuming service.
+	Language string `yaml:"language"`
+	// The directory the client is generated into, relative to the consuming service. Defaults to ./clients/{api},
+	// ex) ./clients/orders-api
+	Output string `yaml:"output"`
+	// The name of the class of the client. Defaults to {Api}Client, ex) OrdersApiClient.
+	ClassName string `yaml:"className"`
+	// The namespace of the client. Defaults to {Api}Sdk, ex) OrdersApiSdk.
+	Namespace string `yaml:"namespace"`
+}
+
+// Validates the clients generated from the OpenAPI description of the service
+func validateOpenApi(projectConfig *ProjectConfig, serviceConfig *ServiceConfig) error {
+	if len(serviceConfig.OpenApi.Clients) > 0 && serviceConfig.OpenApi.Spec == "" {
+		return errors.New("'openApi.spec' is required to generate the clients of the service")
+	}
+
+	for i, client := range serviceConfig.OpenApi.Clients {
+		if client.Service == serviceConfig.Name {
+			return fmt.Errorf("openApi client %d is generated for the service of the API", i+1)
+		}
+
+		consumer, has := projectConfig.Services[client.Service]
+		if !has {
+			return fmt.Errorf("openApi client %d is generated for service '%s', which isn't in the project", i+1, client.Service)
+		}
+
+		if _, err := openApiClientLanguage(client, consumer); err != nil {
+			return fmt.Errorf("openApi client %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Gets the kiota language of the client, the language of the client or the default language of the consuming service
+func openApiClientLanguage(client OpenApiClientOptions, consumer *ServiceConfig) (string, error) {
+	language := client.Language
+	if language == "" {
+		language = defaultOpenApiClientLanguages[consumer.Language]
+	}
+
+	if language == "" {
+		return "", fmt.Errorf(
+			"clients of services of language '%s' can't be generated, set 'language' to one of: %s",
+			consumer.Language,
+			strings.Join(supportedOpenApiClientLanguages(), ", "),
+		)
+	}
+
+	kiotaLanguage, has := openApiClientLanguages[strings.ToLower(language)]
+	if !has {
+		return "", fmt.Errorf(
+			"unsupported client language '%s', supported languages: %s",
+			language,
+			strings.Join(supportedOpenApiClientLanguages(), ", "),
+		)
+	}
+
+	return kiotaLanguage, nil
+}
+
+func supportedOpenApiClientLanguages() []string {
+	languages := maps.Keys(openApiClientLanguages)
+	slices.Sort(languages)
+	return languages
+}
+
+// OpenApiClientGenerator generates the clients of the APIs of the services of the project into the source of the
+// services consuming the APIs. Clients are only generated when requested, ex) with azd restore --generate-clients, so
+// the sources of the services aren't changed by the commands restoring, building or deploying them.
+type OpenApiClientGenerator struct {
+	kiota kiota.KiotaCli
+}
+
+// Creates a new instance of the OpenAPI client generator
+func NewOpenApiClientGenerator(kiotaCli kiota.KiotaCli) *OpenApiClientGenerator {
+	return &OpenApiClientGenerator{
+		kiota: kiotaCli,
+	}
+}
+
+// Checks whether the service consumes APIs of the project that clients are generated for
+func (g *OpenApiClientGenerator) HasClients(serviceConfig *ServiceConfig) bool {
+	return len(openApiClientsOf(serviceConfig)) > 0
+}
+
+// Generates the clients of the APIs consumed by the service
+func (g *OpenApiClientGenerator) Generate(ctx context.Context, serviceConfig *ServiceConfig) error {
+	apis := openApiClientsOf(serviceConfig)
+	if len(apis) == 0 {
+		return nil
+	}
+
+	if err := tools.EnsureInstalled(ctx, g.kiota); err != nil {
+		return err
+	}
+
+	for _, api := range apis {
+		if err := g.generate(ctx, api.service, api.client, serviceConfig); err != nil {
+			return fmt.Errorf(
+				"generating the client of service '%s' for service '%s': %w",
+				api.service.Name,
+				serviceConfig.Name,
+				err,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (g *OpenApiClientGenerator) generate(
+	ctx context.Context,
+	apiConfig *ServiceConfig,
+	client OpenApiClientOptions,
+	serviceConfig *ServiceConfig,
+) error {
+	language, err := openApiClientLanguage(client, serviceConfig)
+	if err != nil {
+		return err
+	}
+
+	specPath := filepath.Join(apiConfig.Path(), apiConfig.OpenApi.Spec)
+	if _, err := os.Stat(specPath); err != nil {
+		return fmt.Errorf("reading the OpenAPI description of the service: %w", err)
+	}
+
+	output := openApiClientOutput(apiConfig, client)
+	name := pascalCase(apiConfig.Name)
+	options := kiota.GenerateOptions{
+		Language:    language,
+		Description: specPath,
+		Output:      output,
+		ClassName:   client.ClassName,
+		Namespace:   client.Namespace,
+	}
+	if options.ClassName == "" {
+		options.ClassName = name + "Client"
+	}
+	if options.Namespace == "" {
+		options.Namespace = name + "Sdk"
+	}
+
+	log.Printf("generating %s client of service %s into %s", language, apiConfig.Name, output)
+	return g.kiota.Generate(ctx, serviceConfig.Path(), options)
+}
+
+// Gets the directory the client of the API is generated into, relative to the consuming service
+func openApiClientOutput(apiConfig *ServiceConfig, client OpenApiClientOptions) string {
+	if client.Output != "" {
+		return client.Output
+	}
+
+	return filepath.Join("clients", apiConfig.Name)
+}
+
+// An API consumed by a service and the client generated for the service
+type openApiClient struct {
+	service *ServiceConfig
+	client  OpenApiClientOptions
+}
+
+// Gets the APIs consumed by the service, in order of the names of the services of the APIs
+func openApiClientsOf(serviceConfig *ServiceConfig) []openApiClient {
+	clients := []openApiClient{}
+	if serviceConfig.Project == nil {
+		return clients
+	}
+
+	names := maps.Keys(serviceConfig.Project.Services)
+	slices.Sort(names)
+	for _, name := range names {
+		apiConfig := serviceConfig.Project.Services[name]
+		for _, client := range apiConfig.OpenApi.Clients {
+			if client.Service == serviceConfig.Name {
+				clients = append(clients, openApiClient{service: apiConfig, client: client})
+			}
+		}
+	}
+
+	return clients
+}
+
+// Converts a service name to pascal case, ex) orders-api to OrdersApi
+func pascalCase(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, "")
+}
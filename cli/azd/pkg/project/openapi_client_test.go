@@ -0,0 +1,158 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/kiota"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Parse_OpenApi(t *testing.T) {
+	tests := map[string]struct {
+		openApi string
+		err     string
+	}{
+		"Valid": {
+			openApi: `
+      spec: ./openapi.yaml
+      clients:
+        - service: web
+        - service: worker
+          language: typescript`,
+		},
+		"MissingSpec": {
+			openApi: `
+      clients:
+        - service: web`,
+			err: "'openApi.spec' is required",
+		},
+		"UnknownService": {
+			openApi: `
+      spec: ./openapi.yaml
+      clients:
+        - service: mobile`,
+			err: "openApi client 1 is generated for service 'mobile', which isn't in the project",
+		},
+		"UnsupportedLanguage": {
+			openApi: `
+      spec: ./openapi.yaml
+      clients:
+        - service: worker`,
+			err: "clients of services of language 'java' can't be generated",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testProj := `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: dotnet
+    host: containerapp
+    openApi:` + test.openApi + `
+  web:
+    project: src/web
+    language: ts
+    host: staticwebapp
+  worker:
+    project: src/worker
+    language: java
+    host: containerapp
+`
+			projectConfig, err := Parse(context.Background(), testProj)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, openApiClientsOf(projectConfig.Services["web"]), 1)
+			require.Empty(t, openApiClientsOf(projectConfig.Services["api"]))
+		})
+	}
+}
+
+func Test_OpenApiClientGenerator_Generate(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  orders-api:
+    project: src/orders
+    language: dotnet
+    host: containerapp
+    openApi:
+      spec: openapi.yaml
+      clients:
+        - service: web
+        - service: admin
+          output: ./Clients/Orders
+          namespace: Contoso.Admin.Clients
+  web:
+    project: src/web
+    language: js
+    host: staticwebapp
+  admin:
+    project: src/admin
+    language: csharp
+    host: appservice
+`
+	projectConfig, err := Parse(context.Background(), testProj)
+	require.NoError(t, err)
+	projectConfig.Path = t.TempDir()
+
+	apiPath := projectConfig.Services["orders-api"].Path()
+	require.NoError(t, os.MkdirAll(apiPath, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(apiPath, "openapi.yaml"), []byte("openapi: 3.0.1"), os.ModePerm))
+
+	kiotaCli := &fakeKiotaCli{}
+	generator := NewOpenApiClientGenerator(kiotaCli)
+	require.True(t, generator.HasClients(projectConfig.Services["web"]))
+	require.False(t, generator.HasClients(projectConfig.Services["orders-api"]))
+
+	err = generator.Generate(context.Background(), projectConfig.Services["web"])
+	require.NoError(t, err)
+	err = generator.Generate(context.Background(), projectConfig.Services["admin"])
+	require.NoError(t, err)
+
+	require.Equal(t, []kiota.GenerateOptions{
+		{
+			Language:    kiota.LanguageTypeScript,
+			Description: filepath.Join(apiPath, "openapi.yaml"),
+			Output:      filepath.Join("clients", "orders-api"),
+			ClassName:   "OrdersApiClient",
+			Namespace:   "OrdersApiSdk",
+		},
+		{
+			Language:    kiota.LanguageCSharp,
+			Description: filepath.Join(apiPath, "openapi.yaml"),
+			Output:      "./Clients/Orders",
+			ClassName:   "OrdersApiClient",
+			Namespace:   "Contoso.Admin.Clients",
+		},
+	}, kiotaCli.generated)
+	require.Equal(t, projectConfig.Services["admin"].Path(), kiotaCli.cwd)
+
+	t.Run("MissingSpec", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(apiPath, "openapi.yaml")))
+
+		err := generator.Generate(context.Background(), projectConfig.Services["web"])
+		require.ErrorContains(t, err, "generating the client of service 'orders-api' for service 'web'")
+	})
+}
+
+type fakeKiotaCli struct {
+	fakeTool
+	generated []kiota.GenerateOptions
+	cwd       string
+}
+
+func (cli *fakeKiotaCli) Generate(ctx context.Context, cwd string, options kiota.GenerateOptions) error {
+	cli.generated = append(cli.generated, options)
+	cli.cwd = cwd
+	return nil
+}
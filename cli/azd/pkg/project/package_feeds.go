@@ -0,0 +1,245 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/azure/azure-dev/cli/azd/pkg/auth"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// PackageFeedOptions is a private package feed the dependencies of the service are restored from, ex) an Azure Artifacts
+// feed. azd provides the credentials of the feeds to the restore of the service, so developers don't have to add them to
+// their .npmrc, nuget.config or pip.conf.
+type PackageFeedOptions struct {
+	// The URL of the feed, ex) https://pkgs.dev.azure.com/contoso/_packaging/internal/npm/registry/
+	Url ExpandableString `yaml:"url"`
+	// The name of the package source of the feed in the nuget.config of services of language dotnet, csharp or fsharp
+	Name string `yaml:"name"`
+	// The environment value of a personal access token of the feed. Defaults to a token of the azd credential for Azure
+	// DevOps.
+	TokenEnv string `yaml:"tokenEnv"`
+}
+
+// The scope of the tokens of the azd credential for Azure DevOps, which authenticate to Azure Artifacts feeds
+const azureDevOpsScope = "499b84ac-1321-427f-aa17-267ca6975798/.default"
+
+// The user name of the feed credentials, ignored by Azure Artifacts
+const packageFeedUserName = "azd"
+
+// The names of NuGet package sources are part of the names of the environment variables of their credentials
+var packageFeedNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// packageFeed is a package feed of a service with its credentials
+type packageFeed struct {
+	url   *url.URL
+	name  string
+	token string
+}
+
+// Gets the feeds of the service with their credentials
+func (sm *serviceManager) packageFeeds(ctx context.Context, serviceConfig *ServiceConfig) ([]packageFeed, error) {
+	feeds := make([]packageFeed, 0, len(serviceConfig.Feeds))
+	azdToken := ""
+	for i, options := range serviceConfig.Feeds {
+		rawUrl, err := options.Url.Envsubst(sm.env.Getenv)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating url of feed %d: %w", i, err)
+		}
+
+		feedUrl, err := url.Parse(rawUrl)
+		if err != nil || feedUrl.Host == "" {
+			return nil, fmt.Errorf("invalid url '%s' of feed %d", rawUrl, i)
+		}
+
+		if feedUrl.Scheme != "https" {
+			return nil, fmt.Errorf("url '%s' of feed %d must use https, so the credentials of the feed aren't leaked", rawUrl, i)
+		}
+
+		if options.Name != "" && !packageFeedNameRegexp.MatchString(options.Name) {
+			return nil, fmt.Errorf("invalid name '%s' of feed %d", options.Name, i)
+		}
+
+		feed := packageFeed{url: feedUrl, name: options.Name}
+		if options.TokenEnv != "" {
+			feed.token = sm.env.Getenv(options.TokenEnv)
+			if feed.token == "" {
+				return nil, fmt.Errorf("the token of feed '%s' isn't set, set it with azd env set %s", rawUrl, options.TokenEnv)
+			}
+		} else {
+			if azdToken == "" {
+				if azdToken, err = sm.azureDevOpsToken(ctx); err != nil {
+					return nil, fmt.Errorf("getting token of feed '%s': %w", rawUrl, err)
+				}
+			}
+
+			feed.token = azdToken
+		}
+
+		feeds = append(feeds, feed)
+	}
+
+	return feeds, nil
+}
+
+// Gets a token of the azd credential for Azure DevOps
+func (sm *serviceManager) azureDevOpsToken(ctx context.Context) (string, error) {
+	var credentialProvider auth.MultiTenantCredentialProvider
+	if err := sm.serviceLocator.Resolve(&credentialProvider); err != nil {
+		return "", err
+	}
+
+	credential, err := credentialProvider.GetTokenCredential(ctx, sm.env.GetTenantId())
+	if err != nil {
+		return "", err
+	}
+
+	token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azureDevOpsScope}})
+	if err != nil {
+		return "", err
+	}
+
+	return token.Token, nil
+}
+
+// Gets the context of the restore of the service, setting the credentials of the feeds of the service for the package
+// manager of the language of the service. The returned function removes the files created for the credentials.
+func (sm *serviceManager) withPackageFeeds(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) (context.Context, func(), error) {
+	if len(serviceConfig.Feeds) == 0 {
+		return ctx, func() {}, nil
+	}
+
+	feeds, err := sm.packageFeeds(ctx, serviceConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting feeds of service %s: %w", serviceConfig.Name, err)
+	}
+
+	envVars, cleanup, err := packageFeedEnviron(serviceConfig.Language, feeds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("setting credentials of feeds of service %s: %w", serviceConfig.Name, err)
+	}
+
+	return exec.WithEnvVars(ctx, envVars...), cleanup, nil
+}
+
+// Gets the environment variables setting the credentials of the feeds for the package manager of the language
+func packageFeedEnviron(language ServiceLanguageKind, feeds []packageFeed) ([]string, func(), error) {
+	switch language {
+	case ServiceLanguageJavaScript, ServiceLanguageTypeScript:
+		return npmFeedEnviron(feeds)
+	case ServiceLanguageDotNet, ServiceLanguageCsharp, ServiceLanguageFsharp:
+		envVars, err := nugetFeedEnviron(feeds)
+		return envVars, func() {}, err
+	case ServiceLanguagePython:
+		indexUrls := pipFeedIndexUrls(feeds)
+		if existing := os.Getenv("PIP_EXTRA_INDEX_URL"); existing != "" {
+			indexUrls = existing + " " + indexUrls
+		}
+
+		return []string{"PIP_EXTRA_INDEX_URL=" + indexUrls}, func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("feeds aren't supported for services of language '%s'", language)
+	}
+}
+
+// Writes a copy of the npm user configuration with the tokens of the feeds added, since npm only reads the tokens of
+// registries from its configuration files
+func npmFeedEnviron(feeds []packageFeed) ([]string, func(), error) {
+	userConfig := os.Getenv("NPM_CONFIG_USERCONFIG")
+	if userConfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		userConfig = filepath.Join(home, ".npmrc")
+	}
+
+	contents, err := os.ReadFile(userConfig)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("reading npm user configuration: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.Write(contents)
+	if len(contents) > 0 && !strings.HasSuffix(string(contents), "\n") {
+		sb.WriteString("\n")
+	}
+
+	for _, feed := range feeds {
+		registry := "//" + feed.url.Host + strings.TrimSuffix(feed.url.Path, "/") + "/"
+		sb.WriteString(fmt.Sprintf("%s:_authToken=%s\n", registry, feed.token))
+	}
+
+	dir, err := os.MkdirTemp("", "azd-npm")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	path := filepath.Join(dir, ".npmrc")
+	if err := os.WriteFile(path, []byte(sb.String()), osutil.PermissionFileOwnerOnly); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("writing npm user configuration: %w", err)
+	}
+
+	return []string{"NPM_CONFIG_USERCONFIG=" + path}, cleanup, nil
+}
+
+// Gets the credentials of the feeds for NuGet, for the package sources of the names of the feeds and for the Azure
+// Artifacts credential provider
+func nugetFeedEnviron(feeds []packageFeed) ([]string, error) {
+	type endpointCredential struct {
+		Endpoint string `json:"endpoint"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	envVars := []string{}
+	credentials := []endpointCredential{}
+	for _, feed := range feeds {
+		if feed.name != "" {
+			envVars = append(envVars, fmt.Sprintf(
+				"NuGetPackageSourceCredentials_%s=Username=%s;Password=%s", feed.name, packageFeedUserName, feed.token))
+		}
+
+		credentials = append(credentials, endpointCredential{
+			Endpoint: feed.url.String(),
+			Username: packageFeedUserName,
+			Password: feed.token,
+		})
+	}
+
+	endpoints, err := json.Marshal(map[string]any{"endpointCredentials": credentials})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(envVars, "VSS_NUGET_EXTERNAL_FEED_ENDPOINTS="+string(endpoints)), nil
+}
+
+// Gets the extra index URLs of pip for the feeds, with the credentials of the feeds
+func pipFeedIndexUrls(feeds []packageFeed) string {
+	indexUrls := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		indexUrl := *feed.url
+		indexUrl.User = url.UserPassword(packageFeedUserName, feed.token)
+		indexUrls = append(indexUrls, indexUrl.String())
+	}
+
+	return strings.Join(indexUrls, " ")
+}
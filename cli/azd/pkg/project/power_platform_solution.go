@@ -0,0 +1,189 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/benbjohnson/clock"
+)
+
+// The interval used when polling the state of a solution import
+var solutionImportPollInterval = 10 * time.Second
+
+// The Power Platform solution deployment options
+type PowerPlatformOptions struct {
+	// The path to the solution relative to the service path.
+	// Either a solution zip or an unpacked solution folder that is compressed during package
+	Solution string `yaml:"solution"`
+	// The URL of the Dataverse environment the solution is imported into, ex) https://{org}.crm.dynamics.com
+	EnvironmentUrl ExpandableString `yaml:"environmentUrl"`
+	// When true unmanaged customizations in the environment are overwritten by the solution
+	OverwriteUnmanagedCustomizations bool `yaml:"overwriteUnmanagedCustomizations"`
+	// When true workflows & flows included in the solution are activated after import
+	PublishWorkflows bool `yaml:"publishWorkflows"`
+}
+
+// PowerPlatformSolution is an optional deployment step that packages a Power Platform solution alongside
+// the service and imports it into a Dataverse environment after the service has been published.
+type PowerPlatformSolution struct {
+	env              *environment.Environment
+	dataverseService azcli.DataverseService
+	clock            clock.Clock
+
+	// Solution zip files created during package, keyed by service name
+	packages     map[string]string
+	packagesLock sync.Mutex
+}
+
+// Creates a new instance of the Power Platform solution deployment step
+func NewPowerPlatformSolution(
+	env *environment.Environment,
+	dataverseService azcli.DataverseService,
+	clock clock.Clock,
+) *PowerPlatformSolution {
+	return &PowerPlatformSolution{
+		env:              env,
+		dataverseService: dataverseService,
+		clock:            clock,
+		packages:         map[string]string{},
+	}
+}
+
+// Initializes the solution step for the service by subscribing to the package & publish events
+func (s *PowerPlatformSolution) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	packageHandler := func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		return s.Package(ctx, args.Service)
+	}
+	if err := serviceConfig.AddHandler(ext.Event("post"+ServiceEventPackage), packageHandler); err != nil {
+		return err
+	}
+
+	importHandler := func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		return s.Import(ctx, args.Service)
+	}
+	if err := serviceConfig.AddHandler(ext.Event("post"+ServiceEventPublish), importHandler); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Packages the solution folder into a solution zip. Solution zip files are used as-is.
+func (s *PowerPlatformSolution) Package(ctx context.Context, serviceConfig *ServiceConfig) error {
+	solutionPath := s.solutionPath(serviceConfig)
+
+	info, err := os.Stat(solutionPath)
+	if err != nil {
+		return fmt.Errorf("reading power platform solution: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	log.Printf("packaging power platform solution %s", solutionPath)
+	zipFilePath, err := createDeployableZip(serviceConfig.Name, solutionPath)
+	if err != nil {
+		return fmt.Errorf("packaging power platform solution: %w", err)
+	}
+
+	s.packagesLock.Lock()
+	defer s.packagesLock.Unlock()
+	s.packages[serviceConfig.Name] = zipFilePath
+
+	return nil
+}
+
+// Imports the packaged solution into the Dataverse environment and waits for the import to complete
+func (s *PowerPlatformSolution) Import(ctx context.Context, serviceConfig *ServiceConfig) error {
+	environmentUrl, err := serviceConfig.PowerPlatform.EnvironmentUrl.Envsubst(s.env.Getenv)
+	if err != nil {
+		return fmt.Errorf("evaluating power platform environment url: %w", err)
+	}
+
+	if environmentUrl == "" {
+		return fmt.Errorf("'powerPlatform.environmentUrl' is required for service '%s'", serviceConfig.Name)
+	}
+
+	s.packagesLock.Lock()
+	solutionZipPath, packaged := s.packages[serviceConfig.Name]
+	delete(s.packages, serviceConfig.Name)
+	s.packagesLock.Unlock()
+
+	if packaged {
+		defer os.Remove(solutionZipPath)
+	} else {
+		solutionZipPath = s.solutionPath(serviceConfig)
+	}
+
+	solutionZip, err := os.ReadFile(solutionZipPath)
+	if err != nil {
+		return fmt.Errorf("reading power platform solution: %w", err)
+	}
+
+	log.Printf("importing power platform solution into %s", environmentUrl)
+	asyncOperationId, err := s.dataverseService.ImportSolution(
+		ctx,
+		s.env.GetSubscriptionId(),
+		environmentUrl,
+		solutionZip,
+		serviceConfig.PowerPlatform.OverwriteUnmanagedCustomizations,
+		serviceConfig.PowerPlatform.PublishWorkflows,
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		operation, err := s.dataverseService.GetAsyncOperation(
+			ctx,
+			s.env.GetSubscriptionId(),
+			environmentUrl,
+			asyncOperationId,
+		)
+		if err != nil {
+			return err
+		}
+
+		if operation.StateCode == azsdk.AsyncOperationStateCompleted {
+			if operation.StatusCode != azsdk.AsyncOperationStatusSucceeded {
+				message := operation.FriendlyMessage
+				if message == "" {
+					message = operation.Message
+				}
+
+				return fmt.Errorf("importing power platform solution for service '%s': %s", serviceConfig.Name, message)
+			}
+
+			log.Printf("imported power platform solution into %s", environmentUrl)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(solutionImportPollInterval):
+		}
+	}
+}
+
+func (s *PowerPlatformSolution) solutionPath(serviceConfig *ServiceConfig) string {
+	solutionPath := serviceConfig.PowerPlatform.Solution
+	if filepath.IsAbs(solutionPath) {
+		return solutionPath
+	}
+
+	return filepath.Join(serviceConfig.Path(), solutionPath)
+}
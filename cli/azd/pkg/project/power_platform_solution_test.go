@@ -0,0 +1,168 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PowerPlatformSolution_PackageAndImport(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	var importRequest azsdk.ImportSolutionRequest
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			request.URL.Host == "contoso.crm.dynamics.com" &&
+			request.URL.Path == "/api/data/v9.2/ImportSolutionAsync"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &importRequest))
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.ImportSolutionResponse{
+			AsyncOperationId: "OPERATION_ID",
+		})
+	})
+
+	polls := 0
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/asyncoperations(OPERATION_ID)")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		polls++
+		operation := azsdk.AsyncOperation{StateCode: 1}
+		if polls > 1 {
+			operation = azsdk.AsyncOperation{
+				StateCode:  azsdk.AsyncOperationStateCompleted,
+				StatusCode: azsdk.AsyncOperationStatusSucceeded,
+			}
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, operation)
+	})
+
+	tempDir := t.TempDir()
+	solutionDir := filepath.Join(tempDir, "solution", "Other")
+	require.NoError(t, os.MkdirAll(solutionDir, osutil.PermissionDirectory))
+	err := os.WriteFile(filepath.Join(solutionDir, "Solution.xml"), []byte("<ImportExportXml />"), osutil.PermissionFile)
+	require.NoError(t, err)
+
+	serviceConfig := createTestServiceConfig(tempDir, AppServiceTarget, ServiceLanguagePython)
+	serviceConfig.PowerPlatform = PowerPlatformOptions{
+		Solution:         "solution",
+		EnvironmentUrl:   NewExpandableString("${DATAVERSE_URL}"),
+		PublishWorkflows: true,
+	}
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"DATAVERSE_URL": "https://contoso.crm.dynamics.com/",
+	})
+	solution := createPowerPlatformSolution(mockContext, env, clock.New())
+
+	require.NoError(t, solution.Package(*mockContext.Context, serviceConfig))
+	require.Contains(t, solution.packages, "api")
+	zipFilePath := solution.packages["api"]
+
+	pollInterval := solutionImportPollInterval
+	solutionImportPollInterval = 0
+	defer func() { solutionImportPollInterval = pollInterval }()
+
+	err = solution.Import(*mockContext.Context, serviceConfig)
+	require.NoError(t, err)
+
+	require.Equal(t, 2, polls)
+	require.True(t, importRequest.PublishWorkflows)
+	require.False(t, importRequest.OverwriteUnmanagedCustomizations)
+	require.NotEmpty(t, importRequest.CustomizationFile)
+	require.NotContains(t, solution.packages, "api")
+	require.NoFileExists(t, zipFilePath)
+}
+
+func Test_PowerPlatformSolution_Import_Failed(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/ImportSolutionAsync")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.ImportSolutionResponse{
+			AsyncOperationId: "OPERATION_ID",
+		})
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/asyncoperations(")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.AsyncOperation{
+			StateCode:       azsdk.AsyncOperationStateCompleted,
+			StatusCode:      31,
+			FriendlyMessage: "Missing dependencies",
+		})
+	})
+
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "solution.zip"), []byte("zip"), osutil.PermissionFile)
+	require.NoError(t, err)
+
+	serviceConfig := createTestServiceConfig(tempDir, AppServiceTarget, ServiceLanguagePython)
+	serviceConfig.PowerPlatform = PowerPlatformOptions{
+		Solution:       "solution.zip",
+		EnvironmentUrl: NewExpandableString("https://contoso.crm.dynamics.com"),
+	}
+
+	solution := createPowerPlatformSolution(mockContext, environment.EphemeralWithValues("test", nil), clock.NewMock())
+
+	// Solution zip files are used as-is
+	require.NoError(t, solution.Package(*mockContext.Context, serviceConfig))
+	require.Empty(t, solution.packages)
+
+	err = solution.Import(*mockContext.Context, serviceConfig)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "Missing dependencies")
+	require.FileExists(t, filepath.Join(tempDir, "solution.zip"))
+}
+
+func Test_PowerPlatformSolution_Import_MissingEnvironmentUrl(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	serviceConfig := createTestServiceConfig(t.TempDir(), AppServiceTarget, ServiceLanguagePython)
+	serviceConfig.PowerPlatform = PowerPlatformOptions{
+		Solution:       "solution.zip",
+		EnvironmentUrl: NewExpandableString("${DATAVERSE_URL}"),
+	}
+
+	solution := createPowerPlatformSolution(mockContext, environment.EphemeralWithValues("test", nil), clock.NewMock())
+
+	err := solution.Import(*mockContext.Context, serviceConfig)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "powerPlatform.environmentUrl")
+}
+
+func createPowerPlatformSolution(
+	mockContext *mocks.MockContext,
+	env *environment.Environment,
+	clock clock.Clock,
+) *PowerPlatformSolution {
+	credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+		func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		})
+
+	return NewPowerPlatformSolution(env, azcli.NewDataverseService(credentialProvider, mockContext.HttpClient), clock)
+}
@@ -38,6 +38,11 @@ func New(ctx context.Context, projectFilePath string, projectName string) (*Proj
 func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 	var projectConfig ProjectConfig
 
+	yamlContent, err := withServiceDefaults(yamlContent)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := yaml.Unmarshal([]byte(yamlContent), &projectConfig); err != nil {
 		return nil, fmt.Errorf(
 			"unable to parse azure.yaml file. Please check the format of the file, "+
@@ -48,6 +53,14 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 
 	projectConfig.EventDispatcher = ext.NewEventDispatcher[ProjectLifecycleEventArgs]()
 
+	if err := validateAnnotations(&projectConfig); err != nil {
+		return nil, err
+	}
+
+	if err := expandInstances(&projectConfig); err != nil {
+		return nil, err
+	}
+
 	for key, svc := range projectConfig.Services {
 		svc.Name = key
 		svc.Project = &projectConfig
@@ -59,20 +72,120 @@ func Parse(ctx context.Context, yamlContent string) (*ProjectConfig, error) {
 			svc.Module = key
 		}
 
-		if svc.Language == "" {
+		// Logic Apps without a language are workflow projects, see GetFrameworkService
+		if svc.Language == "" && svc.Host != LogicAppTarget {
 			svc.Language = "dotnet"
 		}
 
 		var err error
-		svc.Language, err = parseServiceLanguage(svc.Language)
-		if err != nil {
-			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		if svc.Language != "" {
+			svc.Language, err = parseServiceLanguage(svc.Language)
+			if err != nil {
+				return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+			}
 		}
 
 		svc.Host, err = parseServiceHost(svc.Host)
 		if err != nil {
 			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
 		}
+
+		if err := validateOnDeployed(&projectConfig, svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateOpenApi(&projectConfig, svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateCompose(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateJava(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validatePython(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateNode(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateRuby(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateCustom(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateFrameworkPlugin(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateDockerPlatform(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateDockerBuilder(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateDockerLint(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateEnvOptions(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateCompression(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateCustomDomains(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		if err := validateSchedules(svc); err != nil {
+			return nil, fmt.Errorf("parsing service %s: %w", svc.Name, err)
+		}
+
+		for envName, overrides := range svc.Environments {
+			if overrides.Variant != "" {
+				if _, has := svc.Matrix[overrides.Variant]; !has {
+					return nil, fmt.Errorf(
+						"parsing service %s for environment %s: variant '%s' isn't in the matrix of the service",
+						svc.Name,
+						envName,
+						overrides.Variant,
+					)
+				}
+			}
+
+			if overrides.Host == "" {
+				continue
+			}
+
+			if _, err := parseServiceHost(overrides.Host); err != nil {
+				return nil, fmt.Errorf("parsing service %s for environment %s: %w", svc.Name, envName, err)
+			}
+		}
+	}
+
+	if err := validateDependsOn(&projectConfig); err != nil {
+		return nil, err
+	}
+
+	if err := validateServiceSecrets(&projectConfig); err != nil {
+		return nil, err
+	}
+
+	if err := validateDeployStrategy(&projectConfig); err != nil {
+		return nil, err
 	}
 
 	return &projectConfig, nil
@@ -120,6 +233,11 @@ func Load(ctx context.Context, projectFilePath string) (*ProjectConfig, error) {
 	}
 
 	projectConfig.Path = filepath.Dir(projectFilePath)
+
+	if err := expandComposeServices(projectConfig); err != nil {
+		return nil, fmt.Errorf("parsing project file: %w", err)
+	}
+
 	return projectConfig, nil
 }
 
@@ -2,9 +2,11 @@ package project
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
 	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+	"golang.org/x/exp/slices"
 )
 
 // ProjectConfig is the top level object serialized into an azure.yaml file.
@@ -19,6 +21,10 @@ type ProjectConfig struct {
 	Infra             provisioning.Options       `yaml:"infra"`
 	Pipeline          PipelineOptions            `yaml:"pipeline"`
 	Hooks             map[string]*ext.HookConfig `yaml:"hooks,omitempty"`
+	Annotations       *AnnotationOptions         `yaml:"annotations,omitempty"`
+	Git               *GitOptions                `yaml:"git,omitempty"`
+	Docker            *ProjectDockerOptions      `yaml:"docker,omitempty"`
+	Deploy            *DeployOptions             `yaml:"deploy,omitempty"`
 
 	*ext.EventDispatcher[ProjectLifecycleEventArgs] `yaml:",omitempty"`
 }
@@ -28,6 +34,13 @@ type PipelineOptions struct {
 	Provider string `yaml:"provider"`
 }
 
+// The docker options of the project, shared by the services of the project
+type ProjectDockerOptions struct {
+	// The docker context the images of the services are built and pushed with, ex) a remote builder connected with
+	// ssh://user@builder. Overrides the DOCKER_HOST and DOCKER_CONTEXT variables of the environment azd runs in.
+	Context string `yaml:"context"`
+}
+
 // Project lifecycle event arguments
 type ProjectLifecycleEventArgs struct {
 	Project *ProjectConfig
@@ -54,3 +67,37 @@ func (p *ProjectConfig) HasService(name string) bool {
 
 	return false
 }
+
+// SelectServices gets the services targeted by a command, sorted by name. The services are either the named service,
+// the instances of the named service when it declares instances, the services of the group, or all the services of the
+// project when neither a service nor a group is specified.
+func (p *ProjectConfig) SelectServices(serviceName string, group string) ([]*ServiceConfig, error) {
+	if serviceName != "" && group != "" {
+		return nil, fmt.Errorf("a service name and a group can't be specified together")
+	}
+
+	if serviceName != "" && !p.HasService(serviceName) && len(p.serviceInstances(serviceName)) == 0 {
+		return nil, fmt.Errorf("service name '%s' doesn't exist", serviceName)
+	}
+
+	services := []*ServiceConfig{}
+	for name, svc := range p.Services {
+		if svc == nil ||
+			(serviceName != "" && name != serviceName && svc.instanceOf != serviceName) ||
+			(group != "" && svc.Group != group) {
+			continue
+		}
+
+		services = append(services, svc)
+	}
+
+	if group != "" && len(services) == 0 {
+		return nil, fmt.Errorf("no services belong to group '%s'", group)
+	}
+
+	slices.SortFunc(services, func(a, b *ServiceConfig) bool {
+		return a.Name < b.Name
+	})
+
+	return services, nil
+}
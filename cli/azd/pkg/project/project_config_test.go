@@ -112,6 +112,61 @@ services:
 	require.False(t, projectConfig.HasService("foobar"))
 }
 
+func TestProjectConfigSelectServices(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  web:
+    project: src/web
+    language: js
+    host: appservice
+    group: frontend
+  api:
+    project: src/api
+    language: js
+    host: appservice
+    group: backend
+  worker:
+    project: src/worker
+    language: js
+    host: containerapp
+    group: backend
+`
+
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig, err := Parse(*mockContext.Context, testProj)
+	require.NoError(t, err)
+
+	serviceNames := func(services []*ServiceConfig) []string {
+		names := []string{}
+		for _, svc := range services {
+			names = append(names, svc.Name)
+		}
+		return names
+	}
+
+	services, err := projectConfig.SelectServices("", "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "web", "worker"}, serviceNames(services))
+
+	services, err = projectConfig.SelectServices("web", "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"web"}, serviceNames(services))
+
+	services, err = projectConfig.SelectServices("", "backend")
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "worker"}, serviceNames(services))
+
+	_, err = projectConfig.SelectServices("foobar", "")
+	require.ErrorContains(t, err, "service name 'foobar' doesn't exist")
+
+	_, err = projectConfig.SelectServices("", "foobar")
+	require.ErrorContains(t, err, "no services belong to group 'foobar'")
+
+	_, err = projectConfig.SelectServices("web", "backend")
+	require.Error(t, err)
+}
+
 func TestProjectWithCustomDockerOptions(t *testing.T) {
 	const testProj = `
 name: test-proj
@@ -126,6 +181,9 @@ services:
     docker:
       path: ./Dockerfile.dev
       context: ../
+      target: production
+      buildArgs:
+        VERSION: ${SERVICE_WEB_IMAGE_TAG}
 `
 
 	mockContext := mocks.NewMockContext(context.Background())
@@ -138,6 +196,8 @@ services:
 
 	require.Equal(t, "./Dockerfile.dev", service.Docker.Path)
 	require.Equal(t, "../", service.Docker.Context)
+	require.Equal(t, "production", service.Docker.Target)
+	require.Equal(t, NewExpandableString("${SERVICE_WEB_IMAGE_TAG}"), service.Docker.BuildArgs["VERSION"])
 }
 
 func TestProjectWithCustomModule(t *testing.T) {
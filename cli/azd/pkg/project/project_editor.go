@@ -0,0 +1,552 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// The indentation used when the indentation of the azure.yaml file can't be detected
+const defaultProjectIndent = 2
+
+// ProjectEditor edits the azure.yaml file programmatically. Edits are applied to the YAML nodes of the file, and only
+// the lines of the edited entries are replaced in the file, so the comments, blank lines, key order and formatting of
+// the parts of the file that aren't edited are preserved, unlike loading the file into a ProjectConfig and saving it
+// back.
+//
+// Paths are dot separated keys from the root of the file, ex) services.api.docker.path
+type ProjectEditor struct {
+	contents []byte
+	document *yaml.Node
+	indent   int
+}
+
+// An entry of a mapping of the file, the key at the index of the content of the mapping followed by its value
+type mappingEntry struct {
+	mapping *yaml.Node
+	index   int
+}
+
+// A replacement of lines of the file, the lines are rendered once the nodes are edited
+type lineSplice struct {
+	// The range of the replaced lines, 0 based and end exclusive
+	start int
+	end   int
+	// Renders the lines replacing the range
+	render func() (string, error)
+}
+
+// Loads the azure.yaml file at the specified path for editing
+func LoadProjectEditor(projectFilePath string) (*ProjectEditor, error) {
+	contents, err := os.ReadFile(projectFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading project file: %w", err)
+	}
+
+	return ParseProjectEditor(contents)
+}
+
+// Parses the contents of an azure.yaml file for editing
+func ParseProjectEditor(contents []byte) (*ProjectEditor, error) {
+	document, err := parseProjectDocument(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProjectEditor{
+		contents: contents,
+		document: document,
+		indent:   detectIndent(contents),
+	}, nil
+}
+
+func parseProjectDocument(contents []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, fmt.Errorf("parsing project file: %w", err)
+	}
+
+	if document.Kind == 0 {
+		document = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+
+	if len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("parsing project file: the root of the project file must be a mapping")
+	}
+
+	return &document, nil
+}
+
+// Get decodes the value at the path. Returns false when the path doesn't exist.
+func (e *ProjectEditor) Get(path string) (any, bool, error) {
+	node := e.find(splitPath(path))
+	if node == nil {
+		return nil, false, nil
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, true, fmt.Errorf("decoding value at '%s': %w", path, err)
+	}
+
+	return value, true, nil
+}
+
+// Has returns true when a value exists at the path
+func (e *ProjectEditor) Has(path string) bool {
+	return e.find(splitPath(path)) != nil
+}
+
+// Set sets the value at the path, creating the intermediate mappings that don't exist.
+// The value is encoded to YAML, ex) a string, a number or a map[string]any.
+func (e *ProjectEditor) Set(path string, value any) error {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("encoding value for '%s': %w", path, err)
+	}
+
+	return e.SetNode(path, &node)
+}
+
+// SetNode sets the YAML node at the path, creating the intermediate mappings that don't exist.
+// The comments of a replaced value are kept.
+func (e *ProjectEditor) SetNode(path string, node *yaml.Node) error {
+	keys := splitPath(path)
+	if len(keys) == 0 {
+		return errors.New("path is required")
+	}
+
+	// The replaced lines are found before the nodes are edited, the new nodes don't have positions in the file
+	splice := e.setSplice(e.entries(keys), len(keys))
+
+	mapping := e.document.Content[0]
+	for i, key := range keys[:len(keys)-1] {
+		_, value := findKey(mapping, key)
+		if value == nil {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			appendKey(mapping, key, value)
+		} else if value.Kind != yaml.MappingNode {
+			// An empty key, ex) 'docker:' is parsed as null and can be replaced by a mapping
+			if value.Tag != "!!null" {
+				return fmt.Errorf("'%s' is not a mapping", strings.Join(keys[:i+1], "."))
+			}
+
+			value.Kind = yaml.MappingNode
+			value.Tag = "!!map"
+			value.Value = ""
+		}
+
+		mapping = value
+	}
+
+	key := keys[len(keys)-1]
+	if _, existing := findKey(mapping, key); existing != nil {
+		node.HeadComment = existing.HeadComment
+		node.LineComment = existing.LineComment
+		node.FootComment = existing.FootComment
+		*existing = *node
+	} else {
+		appendKey(mapping, key, node)
+	}
+
+	return e.apply(splice)
+}
+
+// Unset removes the value at the path. Returns false when the path doesn't exist.
+func (e *ProjectEditor) Unset(path string) bool {
+	keys := splitPath(path)
+	if len(keys) == 0 {
+		return false
+	}
+
+	chain := e.entries(keys)
+	if len(chain) != len(keys) {
+		return false
+	}
+
+	splice := e.unsetSplice(chain)
+	entry := chain[len(chain)-1]
+	entry.mapping.Content = append(entry.mapping.Content[:entry.index], entry.mapping.Content[entry.index+2:]...)
+
+	// Removing an entry keeps the file valid, the whole file is encoded again if it doesn't
+	if err := e.apply(splice); err != nil {
+		log.Printf("encoding the project file again after removing '%s': %v", path, err)
+		_ = e.apply(e.documentSplice())
+	}
+
+	return true
+}
+
+// Bytes gets the contents of the edited azure.yaml file
+func (e *ProjectEditor) Bytes() ([]byte, error) {
+	return e.contents, nil
+}
+
+// Save validates the edited azure.yaml file and writes it to the specified path
+func (e *ProjectEditor) Save(ctx context.Context, projectFilePath string) error {
+	contents, err := e.Bytes()
+	if err != nil {
+		return err
+	}
+
+	// Ensures the edits result in a valid project before overwriting the file
+	if _, err := Parse(ctx, string(contents)); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(projectFilePath, contents, osutil.PermissionFile); err != nil {
+		return fmt.Errorf("saving project file: %w", err)
+	}
+
+	return nil
+}
+
+// ParseValueNode parses a value specified on the command line as YAML, so typed values such as numbers, booleans,
+// lists and maps can be set, ex) 3, true, [a, b] or {key: value}. Other values are kept as strings.
+func ParseValueNode(value string) *yaml.Node {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &document); err == nil && len(document.Content) == 1 {
+		node := document.Content[0]
+		node.HeadComment, node.LineComment, node.FootComment = "", "", ""
+		return node
+	}
+
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// Gets the entries of the keys of the path that exist, from the root of the file
+func (e *ProjectEditor) entries(keys []string) []mappingEntry {
+	chain := []mappingEntry{}
+	node := e.document.Content[0]
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+
+		index := -1
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 {
+			break
+		}
+
+		chain = append(chain, mappingEntry{mapping: node, index: index})
+		node = node.Content[index+1]
+	}
+
+	return chain
+}
+
+// Gets the lines replaced by setting the value of the path, given the entries of the path that exist
+func (e *ProjectEditor) setSplice(chain []mappingEntry, keyCount int) *lineSplice {
+	// The value of an existing key is replaced
+	if len(chain) == keyCount {
+		return e.entrySplice(chain, len(chain)-1)
+	}
+
+	mapping := e.document.Content[0]
+	if len(chain) == 0 && mapping.Style&yaml.FlowStyle != 0 {
+		return e.documentSplice()
+	} else if len(chain) > 0 {
+		mapping = chain[len(chain)-1].value()
+
+		// Empty keys and empty mappings, ex) 'docker:' or 'docker: {}', are replaced with the new mapping
+		if mapping.Kind != yaml.MappingNode || len(mapping.Content) == 0 || mapping.Style&yaml.FlowStyle != 0 {
+			return e.entrySplice(chain, len(chain)-1)
+		}
+	}
+
+	if flowLevel := flowMappingLevel(chain); flowLevel >= 0 {
+		return e.entrySplice(chain, flowLevel-1)
+	}
+
+	// The first key of a new mapping of the path is added after the last entry of the deepest existing mapping
+	var start, column int
+	if len(mapping.Content) == 0 {
+		start = len(e.lines())
+	} else {
+		last := append(slices.Clone(chain), mappingEntry{mapping: mapping, index: len(mapping.Content) - 2})
+		_, start = e.entryLines(last)
+		column = mapping.Content[0].Column - 1
+	}
+
+	index := len(mapping.Content)
+	return &lineSplice{
+		start: start,
+		end:   start,
+		render: func() (string, error) {
+			return e.renderEntry(mappingEntry{mapping: mapping, index: index}, column)
+		},
+	}
+}
+
+// Gets the lines removed by removing the last entry of the chain, including the comment lines right above the entry
+func (e *ProjectEditor) unsetSplice(chain []mappingEntry) *lineSplice {
+	if flowLevel := flowMappingLevel(chain); flowLevel >= 0 {
+		return e.entrySplice(chain, flowLevel-1)
+	}
+
+	lines := e.lines()
+	start, end := e.entryLines(chain)
+	for start > 0 && isCommentLine(lines[start-1]) {
+		start--
+	}
+
+	return &lineSplice{
+		start:  start,
+		end:    end,
+		render: func() (string, error) { return "", nil },
+	}
+}
+
+// Gets the lines replaced by encoding the entry of the chain at the level again. Entries in flow mappings are
+// encoded with the whole flow mapping, and entries at a level below the root with the whole file.
+func (e *ProjectEditor) entrySplice(chain []mappingEntry, level int) *lineSplice {
+	if flowLevel := flowMappingLevel(chain[:level+1]); flowLevel >= 0 {
+		level = flowLevel - 1
+	}
+
+	if level < 0 {
+		return e.documentSplice()
+	}
+
+	start, end := e.entryLines(chain[:level+1])
+	entry := chain[level]
+	column := entry.key().Column - 1
+
+	return &lineSplice{
+		start: start,
+		end:   end,
+		render: func() (string, error) {
+			// The comments above the key and after the entry are outside of the replaced lines
+			entry.key().HeadComment = ""
+			clearTrailingComments(entry.key(), entry.value())
+			return e.renderEntry(entry, column)
+		},
+	}
+}
+
+// Gets the splice encoding the whole file again
+func (e *ProjectEditor) documentSplice() *lineSplice {
+	return &lineSplice{
+		start: 0,
+		end:   len(e.lines()),
+		render: func() (string, error) {
+			return e.encode(e.document)
+		},
+	}
+}
+
+// Gets the range of the lines of the last entry of the chain, from its key to its last line that isn't blank or a
+// comment, 0 based and end exclusive
+func (e *ProjectEditor) entryLines(chain []mappingEntry) (int, int) {
+	lines := e.lines()
+	start := chain[len(chain)-1].key().Line - 1
+
+	// The entry ends before the next key of its mapping, or the next key of the mappings it's nested in
+	end := len(lines)
+	for level := len(chain) - 1; level >= 0; level-- {
+		if next := chain[level].index + 2; next < len(chain[level].mapping.Content) {
+			end = chain[level].mapping.Content[next].Line - 1
+			break
+		}
+	}
+
+	for end > start+1 && (strings.TrimSpace(lines[end-1]) == "" || isCommentLine(lines[end-1])) {
+		end--
+	}
+
+	return start, end
+}
+
+// Applies the splice to the lines of the file, and parses the edited file again so the positions of the nodes match
+// the edited file
+func (e *ProjectEditor) apply(splice *lineSplice) error {
+	lines := e.lines()
+	rendered, err := splice.render()
+	if err != nil {
+		return err
+	}
+
+	buffer := &bytes.Buffer{}
+	for _, line := range lines[:splice.start] {
+		buffer.WriteString(line)
+	}
+	buffer.WriteString(rendered)
+	for _, line := range lines[splice.end:] {
+		buffer.WriteString(line)
+	}
+
+	document, err := parseProjectDocument(buffer.Bytes())
+	if err != nil {
+		return fmt.Errorf("editing project file: %w", err)
+	}
+
+	e.contents = buffer.Bytes()
+	e.document = document
+	return nil
+}
+
+// Encodes the entry as a mapping with the entry only, indented at the column
+func (e *ProjectEditor) renderEntry(entry mappingEntry, column int) (string, error) {
+	encoded, err := e.encode(&yaml.Node{
+		Kind:    yaml.MappingNode,
+		Tag:     "!!map",
+		Content: []*yaml.Node{entry.key(), entry.value()},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	indent := strings.Repeat(" ", column)
+	lines := strings.SplitAfter(encoded, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = indent + line
+		}
+	}
+
+	return strings.Join(lines, ""), nil
+}
+
+// Encodes the node using the indentation of the original file
+func (e *ProjectEditor) encode(node *yaml.Node) (string, error) {
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(e.indent)
+
+	if err := encoder.Encode(node); err != nil {
+		return "", fmt.Errorf("encoding project file: %w", err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("encoding project file: %w", err)
+	}
+
+	return buffer.String(), nil
+}
+
+// Splits the contents of the file into lines that each end with a line break
+func (e *ProjectEditor) lines() []string {
+	contents := string(e.contents)
+	if contents == "" {
+		return []string{}
+	}
+
+	if !strings.HasSuffix(contents, "\n") {
+		contents += "\n"
+	}
+
+	lines := strings.SplitAfter(contents, "\n")
+	return lines[:len(lines)-1]
+}
+
+func (m mappingEntry) key() *yaml.Node {
+	return m.mapping.Content[m.index]
+}
+
+func (m mappingEntry) value() *yaml.Node {
+	return m.mapping.Content[m.index+1]
+}
+
+// Gets the level of the outermost flow mapping of the chain, -1 when the mappings of the chain are block mappings
+func flowMappingLevel(chain []mappingEntry) int {
+	return slices.IndexFunc(chain, func(entry mappingEntry) bool {
+		return entry.mapping.Style&yaml.FlowStyle != 0
+	})
+}
+
+// Clears the foot comments of the last nodes of the entry, which are after the last line of the entry
+func clearTrailingComments(key *yaml.Node, value *yaml.Node) {
+	key.FootComment = ""
+	for node := value; node != nil; {
+		node.FootComment = ""
+		if (node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode) || len(node.Content) == 0 {
+			return
+		}
+
+		if node.Kind == yaml.MappingNode {
+			node.Content[len(node.Content)-2].FootComment = ""
+		}
+		node = node.Content[len(node.Content)-1]
+	}
+}
+
+func isCommentLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+func (e *ProjectEditor) find(keys []string) *yaml.Node {
+	node := e.document.Content[0]
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		_, node = findKey(node, key)
+		if node == nil {
+			return nil
+		}
+	}
+
+	return node
+}
+
+func findKey(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+
+	return nil, nil
+}
+
+func appendKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, ".")
+}
+
+// Detects the indentation of the YAML content as the smallest indentation of its nested keys
+func detectIndent(contents []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(contents), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+
+		if lineIndent := len(line) - len(trimmed); lineIndent > 0 && (indent == 0 || lineIndent < indent) {
+			indent = lineIndent
+		}
+	}
+
+	if indent < 2 {
+		return defaultProjectIndent
+	}
+
+	return indent
+}
@@ -0,0 +1,249 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+const editorTestProject = `# yaml-language-server: $schema=azure.yaml.json
+
+name: test-app
+services:
+  # The API of the app
+  api:
+    project: src/api # relative to the project
+    language: python
+    host: appservice
+  web:
+    project: src/web
+    language: js
+    host: staticwebapp
+`
+
+func Test_ProjectEditor_Set(t *testing.T) {
+	editor, err := ParseProjectEditor([]byte(editorTestProject))
+	require.NoError(t, err)
+
+	require.NoError(t, editor.Set("services.api.project", "src/api-v2"))
+	require.NoError(t, editor.SetNode("services.api.docker.platform", ParseValueNode("linux/arm64")))
+	require.NoError(t, editor.SetNode("services.api.k8s.replicas", ParseValueNode("3")))
+
+	contents, err := editor.Bytes()
+	require.NoError(t, err)
+
+	expected := `# yaml-language-server: $schema=azure.yaml.json
+
+name: test-app
+services:
+  # The API of the app
+  api:
+    project: src/api-v2 # relative to the project
+    language: python
+    host: appservice
+    docker:
+      platform: linux/arm64
+    k8s:
+      replicas: 3
+  web:
+    project: src/web
+    language: js
+    host: staticwebapp
+`
+	require.Equal(t, expected, string(contents))
+
+	value, has, err := editor.Get("services.api.k8s.replicas")
+	require.NoError(t, err)
+	require.True(t, has)
+	require.Equal(t, 3, value)
+
+	err = editor.Set("services.api.host.name", "value")
+	require.ErrorContains(t, err, "'services.api.host' is not a mapping")
+}
+
+// A project with blank lines, comments and styles that encoding the file again would change
+const editorFormattedTestProject = `# yaml-language-server: $schema=azure.yaml.json
+
+name:   test-app
+metadata:
+  template: 'todo-python@0.0.1'
+
+services:
+
+  # The API of the app
+  api:
+    project: src/api   # relative to the project
+    language: python
+    host: appservice
+    docker:
+
+    # Runs after the API is deployed
+    hooks:
+      postdeploy:
+        run: |
+          echo "deployed"
+
+          ./smoke-test.sh
+
+
+  web:
+    project: "src/web"
+    language: js
+    host: staticwebapp
+    docker: {path: ./Dockerfile, context: .}
+
+# Hooks of the project
+hooks:
+  preprovision:
+    run: ./scripts/setup.sh
+`
+
+func Test_ProjectEditor_PreservesFormatting(t *testing.T) {
+	edit := func(t *testing.T, edit func(editor *ProjectEditor)) string {
+		editor, err := ParseProjectEditor([]byte(editorFormattedTestProject))
+		require.NoError(t, err)
+
+		edit(editor)
+
+		contents, err := editor.Bytes()
+		require.NoError(t, err)
+
+		_, err = Parse(context.Background(), string(contents))
+		require.NoError(t, err)
+
+		return string(contents)
+	}
+
+	t.Run("ReplaceValue", func(t *testing.T) {
+		contents := edit(t, func(editor *ProjectEditor) {
+			require.NoError(t, editor.Set("services.api.host", "containerapp"))
+		})
+
+		expected := strings.Replace(editorFormattedTestProject, "host: appservice", "host: containerapp", 1)
+		require.Equal(t, expected, contents)
+	})
+
+	t.Run("AddKey", func(t *testing.T) {
+		contents := edit(t, func(editor *ProjectEditor) {
+			require.NoError(t, editor.SetNode("services.web.k8s.replicas", ParseValueNode("3")))
+		})
+
+		expected := strings.Replace(
+			editorFormattedTestProject,
+			"    docker: {path: ./Dockerfile, context: .}\n",
+			"    docker: {path: ./Dockerfile, context: .}\n    k8s:\n      replicas: 3\n",
+			1,
+		)
+		require.Equal(t, expected, contents)
+	})
+
+	t.Run("EmptyKey", func(t *testing.T) {
+		contents := edit(t, func(editor *ProjectEditor) {
+			require.NoError(t, editor.Set("services.api.docker.path", "./Dockerfile"))
+		})
+
+		expected := strings.Replace(
+			editorFormattedTestProject,
+			"    docker:\n",
+			"    docker:\n      path: ./Dockerfile\n",
+			1,
+		)
+		require.Equal(t, expected, contents)
+	})
+
+	t.Run("FlowMapping", func(t *testing.T) {
+		contents := edit(t, func(editor *ProjectEditor) {
+			require.NoError(t, editor.Set("services.web.docker.path", "./Dockerfile.prod"))
+		})
+
+		expected := strings.Replace(
+			editorFormattedTestProject,
+			"docker: {path: ./Dockerfile, context: .}",
+			"docker: {path: ./Dockerfile.prod, context: .}",
+			1,
+		)
+		require.Equal(t, expected, contents)
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		contents := edit(t, func(editor *ProjectEditor) {
+			require.True(t, editor.Unset("services.api.hooks"))
+		})
+
+		expected := strings.Replace(
+			editorFormattedTestProject,
+			"    # Runs after the API is deployed\n"+
+				"    hooks:\n"+
+				"      postdeploy:\n"+
+				"        run: |\n"+
+				"          echo \"deployed\"\n"+
+				"\n"+
+				"          ./smoke-test.sh\n",
+			"",
+			1,
+		)
+		require.Equal(t, expected, contents)
+	})
+}
+
+func Test_ProjectEditor_Unset(t *testing.T) {
+	editor, err := ParseProjectEditor([]byte(editorTestProject))
+	require.NoError(t, err)
+
+	require.True(t, editor.Unset("services.web"))
+	require.False(t, editor.Unset("services.web"))
+	require.False(t, editor.Unset("services.api.docker.path"))
+	require.False(t, editor.Has("services.web"))
+	require.True(t, editor.Has("services.api"))
+}
+
+func Test_ProjectEditor_Save(t *testing.T) {
+	ctx := context.Background()
+	projectFilePath := filepath.Join(t.TempDir(), "azure.yaml")
+	require.NoError(t, os.WriteFile(projectFilePath, []byte(editorTestProject), osutil.PermissionFile))
+
+	editor, err := LoadProjectEditor(projectFilePath)
+	require.NoError(t, err)
+
+	require.NoError(t, editor.Set("services.api.host", "containerapp"))
+	require.NoError(t, editor.Save(ctx, projectFilePath))
+
+	projectConfig, err := Load(ctx, projectFilePath)
+	require.NoError(t, err)
+	require.Equal(t, ContainerAppTarget, projectConfig.Services["api"].Host)
+
+	// Invalid edits are not saved
+	require.NoError(t, editor.Set("services.api.host", "unknown"))
+	require.Error(t, editor.Save(ctx, projectFilePath))
+
+	projectConfig, err = Load(ctx, projectFilePath)
+	require.NoError(t, err)
+	require.Equal(t, ContainerAppTarget, projectConfig.Services["api"].Host)
+}
+
+func Test_ParseValueNode(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		expected any
+	}{
+		"String":  {value: "./Dockerfile", expected: "./Dockerfile"},
+		"Empty":   {value: "", expected: ""},
+		"Integer": {value: "3", expected: 3},
+		"Boolean": {value: "true", expected: true},
+		"List":    {value: "[a, b]", expected: []any{"a", "b"}},
+		"Map":     {value: "{key: value}", expected: map[string]any{"key": "value"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var value any
+			require.NoError(t, ParseValueNode(test.value).Decode(&value))
+			require.Equal(t, test.expected, value)
+		})
+	}
+}
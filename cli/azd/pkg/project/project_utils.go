@@ -4,13 +4,265 @@
 package project
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/otiai10/copy"
 )
 
+type ArchiveFormat string
+
+const (
+	ArchiveFormatZip   ArchiveFormat = "zip"
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+)
+
+// The archive formats accepted by the hosts deploying services as an archive. App Service, Azure Functions and Logic Apps
+// are deployed with zip deploy, which only accepts zip archives, including for the apps built by Oryx on the host.
+var archiveFormats = map[ServiceTargetKind][]ArchiveFormat{
+	AppServiceTarget:    {ArchiveFormatZip},
+	AzureFunctionTarget: {ArchiveFormatZip},
+	LogicAppTarget:      {ArchiveFormatZip},
+	VmTarget:            {ArchiveFormatZip, ArchiveFormatTarGz},
+}
+
+// The compression options used when packaging service artifacts into an archive
+type CompressionOptions struct {
+	// The archive format, zip or tar.gz. Defaults to zip.
+	// Only virtual machines accept tar.gz archives
+	Format ArchiveFormat `yaml:"format"`
+	// The compression level from 0 (store files without compression) to 9 (best compression).
+	// Lower levels use less CPU at the cost of a larger upload. Defaults to the deflate default level
+	Level *int `yaml:"level"`
+}
+
+// ArchivePackageResult describes the archive created for a service package
+type ArchivePackageResult struct {
+	Format     ArchiveFormat `json:"format"`
+	Level      int           `json:"level"`
+	SourceSize int64         `json:"sourceSize"`
+	Size       int64         `json:"size"`
+	Duration   time.Duration `json:"duration"`
+	// The hex encoded SHA-256 digest of the archive
+	Sha256 string `json:"sha256"`
+}
+
+// Summary returns a short description of the archive, ex) zip (level 6): 12.4 MB -> 3.1 MB in 1.2s
+func (r *ArchivePackageResult) Summary() string {
+	level := fmt.Sprintf("level %d", r.Level)
+	if r.Level == rzip.NoCompression {
+		level = "stored"
+	} else if r.Level == rzip.DefaultCompression {
+		level = "default level"
+	}
+
+	return fmt.Sprintf(
+		"%s (%s): %s -> %s in %s",
+		r.Format,
+		level,
+		formatByteSize(r.SourceSize),
+		formatByteSize(r.Size),
+		r.Duration.Round(10*time.Millisecond),
+	)
+}
+
+// createDeployableArchive creates an archive of a folder, recursively, using the compression options of the service.
+// Returns an error when the configured format is not one of the supported formats of the service host.
+func createDeployableArchive(
+	serviceConfig *ServiceConfig,
+	path string,
+	supportedFormats ...ArchiveFormat,
+) (*ServicePackageResult, error) {
+	format, level, err := serviceConfig.Compression.resolve(serviceConfig.Name, supportedFormats)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceSize, err := directorySize(path)
+	if err != nil {
+		return nil, fmt.Errorf("measuring package contents for %s: %w", serviceConfig.Name, err)
+	}
+
+	archiveFile, err := os.CreateTemp("", fmt.Sprintf("azddeploy*.%s", format))
+	if err != nil {
+		return nil, fmt.Errorf("failed when creating %s package to deploy %s: %w", format, serviceConfig.Name, err)
+	}
+
+	start := time.Now()
+	if format == ArchiveFormatTarGz {
+		err = rzip.CreateTarGzFromDirectory(path, archiveFile, level, nil)
+	} else {
+		err = rzip.CreateFromDirectoryWithLevel(path, archiveFile, level)
+	}
+	if err != nil {
+		archiveFile.Close()
+		os.Remove(archiveFile.Name())
+		return nil, err
+	}
+
+	if err := archiveFile.Close(); err != nil {
+		os.Remove(archiveFile.Name())
+		return nil, err
+	}
+	duration := time.Since(start)
+
+	archiveInfo, err := os.Stat(archiveFile.Name())
+	if err != nil {
+		os.Remove(archiveFile.Name())
+		return nil, err
+	}
+
+	digest, err := fileSha256(archiveFile.Name())
+	if err != nil {
+		os.Remove(archiveFile.Name())
+		return nil, err
+	}
+
+	return &ServicePackageResult{
+		PackagePath: archiveFile.Name(),
+		Details: &ArchivePackageResult{
+			Format:     format,
+			Level:      level,
+			SourceSize: sourceSize,
+			Size:       archiveInfo.Size(),
+			Duration:   duration,
+			Sha256:     digest,
+		},
+	}, nil
+}
+
+// Validates the compression options of the service against the archive formats of its host. Hosts that don't deploy the
+// service as an archive, ex) container apps, don't support the compression options.
+func validateCompression(serviceConfig *ServiceConfig) error {
+	if serviceConfig.Compression == (CompressionOptions{}) {
+		return nil
+	}
+
+	supportedFormats, has := archiveFormats[serviceConfig.Host]
+	if !has {
+		return fmt.Errorf(
+			"'compression' is only supported for services of host '%s', '%s', '%s' or '%s'",
+			AppServiceTarget,
+			AzureFunctionTarget,
+			LogicAppTarget,
+			VmTarget,
+		)
+	}
+
+	_, _, err := serviceConfig.Compression.resolve(serviceConfig.Name, supportedFormats)
+	return err
+}
+
+// Validates the compression options, returning the archive format and compression level to use
+func (o CompressionOptions) resolve(
+	serviceName string,
+	supportedFormats []ArchiveFormat,
+) (ArchiveFormat, int, error) {
+	format := o.Format
+	if format == "" {
+		format = ArchiveFormatZip
+	}
+
+	supported := false
+	formatNames := make([]string, len(supportedFormats))
+	for i, supportedFormat := range supportedFormats {
+		formatNames[i] = string(supportedFormat)
+		if supportedFormat == format {
+			supported = true
+		}
+	}
+
+	if !supported {
+		return "", 0, fmt.Errorf(
+			"compression format '%s' is not supported for service '%s', supported formats: %s",
+			format,
+			serviceName,
+			strings.Join(formatNames, ", "),
+		)
+	}
+
+	level := rzip.DefaultCompression
+	if o.Level != nil {
+		level = *o.Level
+		if level < rzip.NoCompression || level > rzip.BestCompression {
+			return "", 0, fmt.Errorf(
+				"compression level %d is not valid for service '%s', the level must be between %d and %d",
+				level,
+				serviceName,
+				rzip.NoCompression,
+				rzip.BestCompression,
+			)
+		}
+	}
+
+	return format, level, nil
+}
+
+// Computes the hex encoded SHA-256 digest of the file contents
+func fileSha256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Gets the total size in bytes of the files in the directory, recursively
+func directorySize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+		return nil
+	})
+
+	return size, err
+}
+
+// Formats a size in bytes in a human readable form, ex) 1.5 MB
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
 // CreateDeployableZip creates a zip file of a folder, recursively.
 // Returns the path to the created zip file or an error if it fails.
 func createDeployableZip(appName string, path string) (string, error) {
@@ -43,6 +295,8 @@ type excludeDirEntryCondition func(path string, file os.FileInfo) bool
 // buildForZipOptions provides a set of options for doing build for zip
 type buildForZipOptions struct {
 	excludeConditions []excludeDirEntryCondition
+	// The name of the ignore file at the root of the source whose patterns are excluded, ex) .funcignore
+	ignoreFile string
 }
 
 // buildForZip is use by projects which build strategy is to only copy the source code into a folder which is later
@@ -53,6 +307,15 @@ func buildForZip(src, dst string, options buildForZipOptions) error {
 	// these exclude conditions applies to all projects
 	options.excludeConditions = append(options.excludeConditions, globalExcludeAzdFolder)
 
+	if options.ignoreFile != "" {
+		rules, err := readIgnoreFile(filepath.Join(src, options.ignoreFile), true)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", options.ignoreFile, err)
+		}
+
+		options.excludeConditions = append(options.excludeConditions, rules.excludeCondition(src))
+	}
+
 	return copy.Copy(src, dst, copy.Options{
 		Skip: func(srcInfo os.FileInfo, src, dest string) (bool, error) {
 			for _, checkExclude := range options.excludeConditions {
@@ -65,6 +328,31 @@ func buildForZip(src, dst string, options buildForZipOptions) error {
 	})
 }
 
+// buildForBinary is used by projects compiled to a single binary, ex) Go or Rust. The files of the source which aren't
+// excluded, ex) the host.json file of function apps, are copied with the binary at the root of the destination. The
+// directory of the binary in the source is excluded.
+func buildForBinary(src, dst, binaryPath string, options buildForZipOptions) error {
+	outputDir := filepath.Clean(filepath.Dir(binaryPath))
+	options.excludeConditions = append(options.excludeConditions, func(path string, file os.FileInfo) bool {
+		return file.IsDir() && filepath.Clean(path) == outputDir
+	})
+
+	if err := buildForZip(src, dst, options); err != nil {
+		return err
+	}
+
+	binary, err := os.ReadFile(binaryPath)
+	if err != nil {
+		return fmt.Errorf("reading binary: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dst, filepath.Base(binaryPath)), binary, osutil.PermissionExecutableFile); err != nil {
+		return fmt.Errorf("copying binary: %w", err)
+	}
+
+	return nil
+}
+
 func globalExcludeAzdFolder(path string, file os.FileInfo) bool {
 	return file.IsDir() && file.Name() == ".azure"
 }
@@ -0,0 +1,132 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateDeployableArchive(t *testing.T) {
+	sourcePath := t.TempDir()
+	contents := strings.Repeat("console.log('hello world');\n", 1000)
+	require.NoError(t, os.MkdirAll(filepath.Join(sourcePath, "lib"), osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(filepath.Join(sourcePath, "index.js"), []byte(contents), osutil.PermissionFile))
+	require.NoError(t, os.WriteFile(filepath.Join(sourcePath, "lib", "util.js"), []byte(contents), osutil.PermissionFile))
+
+	t.Run("ZipStore", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(sourcePath, AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Compression = CompressionOptions{Level: convert.RefOf(0)}
+
+		result, err := createDeployableArchive(serviceConfig, sourcePath, ArchiveFormatZip)
+		require.NoError(t, err)
+		defer os.Remove(result.PackagePath)
+
+		archive, ok := result.Details.(*ArchivePackageResult)
+		require.True(t, ok)
+		require.Equal(t, ArchiveFormatZip, archive.Format)
+		require.Equal(t, 0, archive.Level)
+		require.Equal(t, int64(2*len(contents)), archive.SourceSize)
+		require.Greater(t, archive.Size, archive.SourceSize)
+
+		reader, err := zip.OpenReader(result.PackagePath)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		require.Len(t, reader.File, 2)
+		for _, file := range reader.File {
+			require.Equal(t, zip.Store, file.Method)
+		}
+	})
+
+	t.Run("TarGz", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(sourcePath, VmTarget, ServiceLanguageJavaScript)
+		serviceConfig.Compression = CompressionOptions{Format: ArchiveFormatTarGz, Level: convert.RefOf(9)}
+
+		result, err := createDeployableArchive(serviceConfig, sourcePath, ArchiveFormatZip, ArchiveFormatTarGz)
+		require.NoError(t, err)
+		defer os.Remove(result.PackagePath)
+		require.True(t, strings.HasSuffix(result.PackagePath, ".tar.gz"))
+
+		archive, ok := result.Details.(*ArchivePackageResult)
+		require.True(t, ok)
+		require.Equal(t, ArchiveFormatTarGz, archive.Format)
+		require.Less(t, archive.Size, archive.SourceSize)
+		require.Contains(t, archive.Summary(), "tar.gz (level 9)")
+
+		file, err := os.Open(result.PackagePath)
+		require.NoError(t, err)
+		defer file.Close()
+
+		gzipReader, err := gzip.NewReader(file)
+		require.NoError(t, err)
+
+		names := []string{}
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			names = append(names, header.Name)
+		}
+
+		require.ElementsMatch(t, []string{"index.js", "lib/util.js"}, names)
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(sourcePath, AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Compression = CompressionOptions{Format: ArchiveFormatTarGz}
+
+		result, err := createDeployableArchive(serviceConfig, sourcePath, ArchiveFormatZip)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "supported formats: zip")
+		require.Nil(t, result)
+	})
+
+	t.Run("InvalidLevel", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(sourcePath, AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Compression = CompressionOptions{Level: convert.RefOf(12)}
+
+		result, err := createDeployableArchive(serviceConfig, sourcePath, ArchiveFormatZip)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "compression level 12")
+		require.Nil(t, result)
+	})
+}
+
+func Test_ValidateCompression(t *testing.T) {
+	serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguagePython)
+	require.NoError(t, validateCompression(serviceConfig))
+
+	serviceConfig.Compression = CompressionOptions{Level: convert.RefOf(1)}
+	require.NoError(t, validateCompression(serviceConfig))
+
+	// Oryx builds on App Service are deployed with zip deploy, which only accepts zip archives
+	serviceConfig.Compression = CompressionOptions{Format: ArchiveFormatTarGz}
+	require.ErrorContains(t, validateCompression(serviceConfig), "supported formats: zip")
+
+	serviceConfig.Host = VmTarget
+	require.NoError(t, validateCompression(serviceConfig))
+
+	serviceConfig.Host = ContainerAppTarget
+	require.ErrorContains(t, validateCompression(serviceConfig), "'compression' is only supported")
+}
+
+func Test_FormatByteSize(t *testing.T) {
+	require.Equal(t, "512 B", formatByteSize(512))
+	require.Equal(t, "1.5 KB", formatByteSize(1536))
+	require.Equal(t, "3.0 MB", formatByteSize(3*1024*1024))
+}
@@ -0,0 +1,396 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/google/uuid"
+)
+
+const (
+	InTotoStatementType         = "https://in-toto.io/Statement/v1"
+	SlsaProvenancePredicateType = "https://slsa.dev/provenance/v1"
+	// The media type of the provenance attestations attached to container images
+	ProvenanceArtifactType = "application/vnd.in-toto+json"
+
+	provenanceBuildType = "https://github.com/Azure/azure-dev/provenance/v1"
+	provenanceBuilderId = "https://github.com/Azure/azure-dev"
+	// The license reported when the license of the project cannot be determined
+	noAssertionLicense = "NOASSERTION"
+)
+
+// The provenance attestation options of a service
+type ProvenanceOptions struct {
+	// When enabled, a SLSA provenance attestation is generated for the deployed artifact
+	// and included in the deployment result
+	Enabled bool `yaml:"enabled"`
+	// When enabled, the attestation of container images is also pushed to the container registry as an OCI referrer
+	Registry bool `yaml:"registry"`
+	// The SPDX license expression of the artifact, ex) MIT. Defaults to the license detected from the LICENSE file
+	License string `yaml:"license"`
+}
+
+// ProvenanceStatement is an in-toto statement with a SLSA provenance predicate
+// More info can be found at https://slsa.dev/spec/v1.0/provenance
+type ProvenanceStatement struct {
+	Type          string                         `json:"_type"`
+	Subject       []ProvenanceResourceDescriptor `json:"subject"`
+	PredicateType string                         `json:"predicateType"`
+	Predicate     ProvenancePredicate            `json:"predicate"`
+}
+
+type ProvenanceResourceDescriptor struct {
+	Name        string            `json:"name,omitempty"`
+	Uri         string            `json:"uri,omitempty"`
+	Digest      map[string]string `json:"digest,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ProvenancePredicate struct {
+	BuildDefinition ProvenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      ProvenanceRunDetails      `json:"runDetails"`
+}
+
+type ProvenanceBuildDefinition struct {
+	BuildType            string                         `json:"buildType"`
+	ExternalParameters   map[string]any                 `json:"externalParameters"`
+	ResolvedDependencies []ProvenanceResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type ProvenanceRunDetails struct {
+	Builder  ProvenanceBuilder  `json:"builder"`
+	Metadata ProvenanceMetadata `json:"metadata"`
+}
+
+type ProvenanceBuilder struct {
+	Id      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+type ProvenanceMetadata struct {
+	InvocationId string    `json:"invocationId"`
+	StartedOn    time.Time `json:"startedOn"`
+	FinishedOn   time.Time `json:"finishedOn"`
+}
+
+// ProvenanceAttestation is the provenance generated for a deployed service artifact
+type ProvenanceAttestation struct {
+	// The artifact the provenance was generated for, ex) {registry}/{repository}@sha256:{digest}
+	Subject   string               `json:"subject"`
+	Statement *ProvenanceStatement `json:"statement"`
+	// The reference of the OCI referrer the statement was pushed to, when stored in the container registry
+	Referrer string `json:"referrer,omitempty"`
+}
+
+// ProvenanceGenerator generates SLSA provenance attestations for the artifacts deployed by azd
+type ProvenanceGenerator struct {
+	env                      *environment.Environment
+	gitCli                   git.GitCli
+	containerRegistryService azcli.ContainerRegistryService
+}
+
+// Creates a new instance of the ProvenanceGenerator
+func NewProvenanceGenerator(
+	env *environment.Environment,
+	gitCli git.GitCli,
+	containerRegistryService azcli.ContainerRegistryService,
+) *ProvenanceGenerator {
+	return &ProvenanceGenerator{
+		env:                      env,
+		gitCli:                   gitCli,
+		containerRegistryService: containerRegistryService,
+	}
+}
+
+// Attest generates the provenance of the artifact deployed for the service, and pushes it to the container registry
+// when configured. Returns nil when the service package doesn't produce an artifact with a digest.
+func (g *ProvenanceGenerator) Attest(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	deployResult *ServiceDeployResult,
+	startedOn time.Time,
+) (*ProvenanceAttestation, error) {
+	subject, imageDetails, err := g.subject(ctx, serviceConfig, deployResult.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	if subject == nil {
+		log.Printf("skipping provenance for service %s, the package has no digest", serviceConfig.Name)
+		return nil, nil
+	}
+
+	license := serviceConfig.Provenance.License
+	if license == "" {
+		license = detectLicense(serviceConfig)
+	}
+	subject.Annotations = map[string]string{"license": license}
+
+	statement := &ProvenanceStatement{
+		Type:          InTotoStatementType,
+		Subject:       []ProvenanceResourceDescriptor{*subject},
+		PredicateType: SlsaProvenancePredicateType,
+		Predicate: ProvenancePredicate{
+			BuildDefinition: ProvenanceBuildDefinition{
+				BuildType:            provenanceBuildType,
+				ExternalParameters:   g.externalParameters(serviceConfig),
+				ResolvedDependencies: g.resolvedDependencies(ctx, serviceConfig),
+			},
+			RunDetails: ProvenanceRunDetails{
+				Builder: ProvenanceBuilder{
+					Id:      provenanceBuilderId,
+					Version: map[string]string{"azd": internal.GetVersionNumber()},
+				},
+				Metadata: ProvenanceMetadata{
+					InvocationId: uuid.NewString(),
+					StartedOn:    startedOn.UTC(),
+					FinishedOn:   time.Now().UTC(),
+				},
+			},
+		},
+	}
+
+	attestation := &ProvenanceAttestation{
+		Subject:   subject.String(),
+		Statement: statement,
+	}
+	if !serviceConfig.Provenance.Registry {
+		return attestation, nil
+	}
+
+	if imageDetails == nil {
+		return nil, fmt.Errorf(
+			"storing provenance in a container registry is not supported for host '%s' of service '%s'",
+			serviceConfig.Host,
+			serviceConfig.Name,
+		)
+	}
+
+	content, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling provenance: %w", err)
+	}
+
+	repository, _ := splitImageTag(imageDetails.ImageTag, imageDetails.LoginServer)
+	referrer, err := g.containerRegistryService.AttachArtifact(
+		ctx,
+		g.env.GetSubscriptionId(),
+		imageDetails.LoginServer,
+		repository,
+		imageDetails.manifest,
+		ProvenanceArtifactType,
+		content,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("attaching provenance to image '%s': %w", imageDetails.ImageTag, err)
+	}
+
+	attestation.Referrer = fmt.Sprintf("%s/%s@%s", imageDetails.LoginServer, repository, referrer.Digest)
+	return attestation, nil
+}
+
+// Gets the subject of the provenance from the package result. Container images are identified by the digest of
+// the image manifest pushed to the registry, archives by the digest of the archive.
+func (g *ProvenanceGenerator) subject(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageResult *ServicePackageResult,
+) (*ProvenanceResourceDescriptor, *provenanceImage, error) {
+	switch details := packageResult.Details.(type) {
+	case *dockerPackageResult:
+		// Pre-built images referenced from their registry aren't pushed by azd, so they aren't attested
+		if details.LoginServer == "" {
+			return nil, nil, nil
+		}
+
+		repository, tag := splitImageTag(details.ImageTag, details.LoginServer)
+		manifest, err := g.containerRegistryService.GetManifest(
+			ctx,
+			g.env.GetSubscriptionId(),
+			details.LoginServer,
+			repository,
+			tag,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		algorithm, digest, has := strings.Cut(manifest.Digest, ":")
+		if !has {
+			return nil, nil, fmt.Errorf("invalid manifest digest '%s'", manifest.Digest)
+		}
+
+		return &ProvenanceResourceDescriptor{
+				Name:   fmt.Sprintf("%s/%s", details.LoginServer, repository),
+				Digest: map[string]string{algorithm: digest},
+			},
+			&provenanceImage{dockerPackageResult: details, manifest: manifest},
+			nil
+	case *ArchivePackageResult:
+		return &ProvenanceResourceDescriptor{
+			Name:   fmt.Sprintf("%s.%s", serviceConfig.Name, details.Format),
+			Digest: map[string]string{"sha256": details.Sha256},
+		}, nil, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+// The parameters of the build that are under the control of the user
+func (g *ProvenanceGenerator) externalParameters(serviceConfig *ServiceConfig) map[string]any {
+	parameters := map[string]any{
+		"service":     serviceConfig.Name,
+		"host":        serviceConfig.Host,
+		"language":    serviceConfig.Language,
+		"environment": g.env.GetEnvName(),
+	}
+
+	if serviceConfig.Project != nil {
+		if relativePath, err := filepath.Rel(serviceConfig.Project.Path, serviceConfig.Path()); err == nil {
+			parameters["path"] = filepath.ToSlash(relativePath)
+		}
+	}
+
+	if serviceConfig.OutputPath != "" {
+		parameters["dist"] = serviceConfig.OutputPath
+	}
+
+	if !reflect.DeepEqual(serviceConfig.Docker, DockerProjectOptions{}) {
+		dockerOptions := getDockerOptionsWithDefaults(serviceConfig.Docker)
+		docker := map[string]string{
+			"path":     dockerOptions.Path,
+			"context":  dockerOptions.Context,
+			"platform": dockerOptions.Platform,
+		}
+
+		if dockerOptions.Target != "" {
+			docker["target"] = dockerOptions.Target
+		}
+
+		parameters["docker"] = docker
+	}
+
+	return parameters
+}
+
+// The source repository and commit the artifact was built from, when the service is in a git repository
+func (g *ProvenanceGenerator) resolvedDependencies(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) []ProvenanceResourceDescriptor {
+	commit, err := g.gitCli.GetCurrentCommit(ctx, serviceConfig.Path())
+	if err != nil {
+		if !errors.Is(err, git.ErrNotRepository) {
+			log.Printf("failed getting current commit for service %s: %v", serviceConfig.Name, err)
+		}
+
+		return nil
+	}
+
+	uri := "git+file://" + filepath.ToSlash(serviceConfig.Path())
+	if remoteUrl, err := g.gitCli.GetRemoteUrl(ctx, serviceConfig.Path(), "origin"); err == nil {
+		uri = "git+" + remoteUrl
+	}
+
+	return []ProvenanceResourceDescriptor{
+		{
+			Uri:    uri,
+			Digest: map[string]string{"gitCommit": commit},
+		},
+	}
+}
+
+// Formats the descriptor as {name}@{algorithm}:{digest}
+func (d ProvenanceResourceDescriptor) String() string {
+	for algorithm, digest := range d.Digest {
+		return fmt.Sprintf("%s@%s:%s", d.Name, algorithm, digest)
+	}
+
+	return d.Name
+}
+
+// The container image of the provenance subject
+type provenanceImage struct {
+	*dockerPackageResult
+	manifest *azsdk.OciDescriptor
+}
+
+// Splits the image tag into the repository and tag, ex) {registry}.azurecr.io/app/api:v1 -> app/api, v1
+func splitImageTag(imageTag string, loginServer string) (string, string) {
+	image := strings.TrimPrefix(imageTag, loginServer+"/")
+	lastSlash := strings.LastIndex(image, "/")
+	if lastColon := strings.LastIndex(image, ":"); lastColon > lastSlash {
+		return image[:lastColon], image[lastColon+1:]
+	}
+
+	return image, "latest"
+}
+
+var licenseFileNames = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// Detects the SPDX identifier of the license of the service, looking for a license file in the service directory
+// and then in the project directory
+func detectLicense(serviceConfig *ServiceConfig) string {
+	dirs := []string{serviceConfig.Path()}
+	if serviceConfig.Project != nil {
+		dirs = append(dirs, serviceConfig.Project.Path)
+	}
+
+	for _, dir := range dirs {
+		for _, fileName := range licenseFileNames {
+			contents, err := os.ReadFile(filepath.Join(dir, fileName))
+			if err == nil {
+				return licenseFromText(string(contents))
+			}
+		}
+	}
+
+	return noAssertionLicense
+}
+
+// Gets the SPDX identifier of the most common licenses from the license text
+func licenseFromText(text string) string {
+	switch {
+	case strings.Contains(text, "Permission is hereby granted, free of charge"):
+		return "MIT"
+	case strings.Contains(text, "Apache License") && strings.Contains(text, "Version 2.0"):
+		return "Apache-2.0"
+	case strings.Contains(text, "Mozilla Public License Version 2.0"):
+		return "MPL-2.0"
+	case strings.Contains(text, "GNU LESSER GENERAL PUBLIC LICENSE"):
+		if strings.Contains(text, "Version 2.1") {
+			return "LGPL-2.1"
+		}
+		return "LGPL-3.0"
+	case strings.Contains(text, "GNU GENERAL PUBLIC LICENSE"):
+		if strings.Contains(text, "Version 2,") {
+			return "GPL-2.0"
+		}
+		return "GPL-3.0"
+	case strings.Contains(text, "Redistribution and use in source and binary forms"):
+		if strings.Contains(text, "Neither the name") {
+			return "BSD-3-Clause"
+		}
+		return "BSD-2-Clause"
+	case strings.Contains(text, "This is free and unencumbered software released into the public domain"):
+		return "Unlicense"
+	default:
+		return noAssertionLicense
+	}
+}
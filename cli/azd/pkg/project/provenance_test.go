@@ -0,0 +1,182 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ProvenanceGenerator_Archive(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(0, "0123456789abcdef\n", ""))
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "remote get-url origin")
+	}).Respond(exec.NewRunResult(0, "https://github.com/contoso/app\n", ""))
+
+	projectPath := t.TempDir()
+	license := "MIT License\n\nPermission is hereby granted, free of charge, to any person obtaining a copy"
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "LICENSE"), []byte(license), osutil.PermissionFile))
+
+	serviceConfig := createTestServiceConfig("src/api", AppServiceTarget, ServiceLanguagePython)
+	serviceConfig.Project.Path = projectPath
+	serviceConfig.Provenance = ProvenanceOptions{Enabled: true}
+
+	deployResult := &ServiceDeployResult{
+		Package: &ServicePackageResult{
+			Details: &ArchivePackageResult{Format: ArchiveFormatZip, Sha256: "ARCHIVE_DIGEST"},
+		},
+	}
+
+	generator := createProvenanceGenerator(mockContext)
+	startedOn := time.Now().Add(-time.Minute)
+	attestation, err := generator.Attest(*mockContext.Context, serviceConfig, deployResult, startedOn)
+	require.NoError(t, err)
+	require.Equal(t, "api.zip@sha256:ARCHIVE_DIGEST", attestation.Subject)
+	require.Empty(t, attestation.Referrer)
+
+	statement := attestation.Statement
+	require.Equal(t, InTotoStatementType, statement.Type)
+	require.Equal(t, SlsaProvenancePredicateType, statement.PredicateType)
+	require.Equal(t, "MIT", statement.Subject[0].Annotations["license"])
+
+	buildDefinition := statement.Predicate.BuildDefinition
+	require.Equal(t, "api", buildDefinition.ExternalParameters["service"])
+	require.Equal(t, "src/api", buildDefinition.ExternalParameters["path"])
+	require.Equal(t, []ProvenanceResourceDescriptor{
+		{
+			Uri:    "git+https://github.com/contoso/app",
+			Digest: map[string]string{"gitCommit": "0123456789abcdef"},
+		},
+	}, buildDefinition.ResolvedDependencies)
+	require.Equal(t, startedOn.UTC(), statement.Predicate.RunDetails.Metadata.StartedOn)
+}
+
+func Test_ProvenanceGenerator_ImageReferrer(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(128, "", "fatal: not a git repository"), errors.New("exit code: 128")
+	})
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodHead && request.URL.Path == "/v2/app/api/manifests/azd-deploy-0"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		response, _ := mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+		response.Header.Set("Content-Type", azsdk.OciImageManifestMediaType)
+		response.Header.Set("Docker-Content-Digest", "sha256:IMAGE_DIGEST")
+		response.Header.Set("Content-Length", "1234")
+
+		return response, nil
+	})
+
+	uploads := []string{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/v2/app/api/blobs/uploads/"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		response, _ := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+		response.Header.Set("Location", "/v2/app/api/blobs/uploads/UPLOAD_ID?_state=STATE")
+
+		return response, nil
+	})
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && request.URL.Path == "/v2/app/api/blobs/uploads/UPLOAD_ID"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		require.Equal(t, "STATE", request.URL.Query().Get("_state"))
+		uploads = append(uploads, request.URL.Query().Get("digest"))
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	var manifest azsdk.OciManifest
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPut && strings.HasPrefix(request.URL.Path, "/v2/app/api/manifests/sha256:")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &manifest))
+		require.Equal(t, azsdk.Sha256Digest(body), strings.TrimPrefix(request.URL.Path, "/v2/app/api/manifests/"))
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), ContainerAppTarget, ServiceLanguagePython)
+	serviceConfig.Provenance = ProvenanceOptions{Enabled: true, Registry: true, License: "Apache-2.0"}
+
+	deployResult := &ServiceDeployResult{
+		Package: &ServicePackageResult{
+			Details: &dockerPackageResult{
+				ImageTag:    "REGISTRY.azurecr.io/app/api:azd-deploy-0",
+				LoginServer: "REGISTRY.azurecr.io",
+			},
+		},
+	}
+
+	generator := createProvenanceGenerator(mockContext)
+	attestation, err := generator.Attest(*mockContext.Context, serviceConfig, deployResult, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "REGISTRY.azurecr.io/app/api@sha256:IMAGE_DIGEST", attestation.Subject)
+	require.True(t, strings.HasPrefix(attestation.Referrer, "REGISTRY.azurecr.io/app/api@sha256:"))
+	require.Empty(t, attestation.Statement.Predicate.BuildDefinition.ResolvedDependencies)
+	require.Equal(t, "Apache-2.0", attestation.Statement.Subject[0].Annotations["license"])
+
+	require.Len(t, uploads, 2)
+	require.Equal(t, ProvenanceArtifactType, manifest.ArtifactType)
+	require.Equal(t, "sha256:IMAGE_DIGEST", manifest.Subject.Digest)
+	require.Equal(t, int64(1234), manifest.Subject.Size)
+	require.Equal(t, uploads[1], manifest.Layers[0].Digest)
+}
+
+func Test_SplitImageTag(t *testing.T) {
+	repository, tag := splitImageTag("REGISTRY.azurecr.io/app/api:azd-deploy-0", "REGISTRY.azurecr.io")
+	require.Equal(t, "app/api", repository)
+	require.Equal(t, "azd-deploy-0", tag)
+
+	repository, tag = splitImageTag("localhost:5000/api", "localhost:5000")
+	require.Equal(t, "api", repository)
+	require.Equal(t, "latest", tag)
+}
+
+func createProvenanceGenerator(mockContext *mocks.MockContext) *ProvenanceGenerator {
+	credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+		func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		})
+
+	return NewProvenanceGenerator(
+		environment.EphemeralWithValues("test", map[string]string{
+			environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+		}),
+		git.NewGitCli(mockContext.CommandRunner),
+		azcli.NewContainerRegistryService(
+			credentialProvider,
+			mockContext.HttpClient,
+			docker.NewDocker(mockContext.CommandRunner),
+		),
+	)
+}
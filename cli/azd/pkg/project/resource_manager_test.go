@@ -0,0 +1,97 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockarmresources"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+// A compiled template deploying a resource group with a container registry and a container app for the api service
+//
+//go:embed testdata/simulated-deployment.json
+var simulatedTemplate []byte
+
+// A template deploying the container registry and the AKS cluster of the test environment, see createEnv
+//
+//go:embed testdata/simulated-target-resources.json
+var simulatedTargetResourcesTemplate []byte
+
+// Registers an ARM simulator serving the resources the service targets use in the test environment, the container
+// registry REGISTRY and the AKS cluster AKS_CLUSTER in the resource group RESOURCE_GROUP
+func registerSimulatedTargetResources(mockContext *mocks.MockContext) (*mockarmresources.DeploymentSimulator, error) {
+	simulator := mockarmresources.NewDeploymentSimulator("SUBSCRIPTION_ID", "eastus2")
+	simulator.Register(mockContext.HttpClient)
+
+	var template map[string]any
+	if err := json.Unmarshal(simulatedTargetResourcesTemplate, &template); err != nil {
+		return nil, err
+	}
+
+	if _, err := simulator.Deploy("target-resources", "RESOURCE_GROUP", template, nil); err != nil {
+		return nil, err
+	}
+
+	return simulator, nil
+}
+
+func Test_ResourceManager_SimulatedDeployment(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	simulator := mockarmresources.NewDeploymentSimulator("SUBSCRIPTION_ID", "eastus2")
+	simulator.Register(mockContext.HttpClient)
+
+	azCli := mockazcli.NewAzCliFromMockContext(mockContext)
+	result, err := azCli.DeployToSubscription(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"test-env",
+		simulatedTemplate,
+		azure.ArmParameters{
+			"environmentName": {Value: "test-env"},
+			"location":        {Value: "eastus2"},
+		},
+		"eastus2",
+	)
+	require.NoError(t, err)
+
+	outputs := result.Properties.Outputs
+	require.Regexp(t, `^cr[a-z0-9]{13}\.azurecr\.io$`, outputs["AZURE_CONTAINER_REGISTRY_ENDPOINT"].Value)
+	require.Regexp(
+		t,
+		`^https://ca-api-[a-z0-9]{13}\.simulated\.eastus2\.azurecontainerapps\.io$`,
+		outputs["API_URI"].Value,
+	)
+	// Outputs that can't be evaluated get a placeholder
+	require.Equal(t, "simulated-azure_key_vault_endpoint", outputs["AZURE_KEY_VAULT_ENDPOINT"].Value)
+
+	var containerApp *mockarmresources.SimulatedResource
+	for _, resource := range simulator.Resources() {
+		if resource.Type == string(infra.AzureResourceTypeContainerApp) {
+			containerApp = resource
+		}
+	}
+	require.NotNil(t, containerApp)
+	require.Equal(t, "rg-test-env", containerApp.ResourceGroup)
+
+	// The resource group and the service resource are discovered from their tags
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	env := environment.EphemeralWithValues("test-env", nil)
+	resourceManager := NewResourceManager(env, azCli)
+
+	targetResource, err := resourceManager.GetTargetResource(*mockContext.Context, "SUBSCRIPTION_ID", serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, "rg-test-env", targetResource.ResourceGroupName())
+	require.Equal(t, containerApp.Name, targetResource.ResourceName())
+	require.Equal(t, string(infra.AzureResourceTypeContainerApp), targetResource.ResourceType())
+}
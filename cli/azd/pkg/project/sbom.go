@@ -0,0 +1,110 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+)
+
+// The directory of the environment the SBOMs of the images of the services are written to
+const sbomDirectoryName = "sbom"
+
+// DockerSbomOptions configures the software bill of materials (SBOM) generated for the image of the service when the
+// service is packaged. SBOMs are generated with syft.
+type DockerSbomOptions struct {
+	// When enabled, an SBOM of the image is generated when the service is packaged
+	Enabled bool `json:"enabled" yaml:"enabled"`
+	// The format of the SBOM, spdx-json, cyclonedx-json or syft-json. Defaults to spdx-json.
+	Format string `json:"format" yaml:"format"`
+	// When enabled, the SBOM is attached to the image in the container registry as an OCI artifact referring to the
+	// image, once the image is pushed. Requires the ORAS CLI.
+	Push bool `json:"push" yaml:"push"`
+}
+
+// The SBOM generated for the image of a service
+type dockerSbom struct {
+	// The path of the SBOM file
+	Path string
+	// The format of the SBOM, ex) spdx-json
+	Format string
+	// Whether the SBOM is attached to the image when the image is pushed
+	Push bool
+}
+
+// Gets the format of the SBOM, validating the format is supported
+func (o DockerSbomOptions) format() (string, error) {
+	if o.Format == "" {
+		return syft.FormatSpdxJson, nil
+	}
+
+	if _, has := syft.MediaTypes[o.Format]; !has {
+		return "", fmt.Errorf(
+			"unsupported sbom format '%s', supported formats: %s, %s, %s",
+			o.Format,
+			syft.FormatSpdxJson,
+			syft.FormatCycloneDxJson,
+			syft.FormatSyftJson,
+		)
+	}
+
+	return o.Format, nil
+}
+
+// Generates the SBOM of the image of the service from the syft source of the image, ex) docker:api:tag, or
+// registry:contoso.azurecr.io/api:tag for images that are only in a registry.
+// The SBOM is written to the sbom directory of the environment, replacing the SBOM of the previous package.
+func generateSbom(
+	ctx context.Context,
+	syftCli syft.SyftCli,
+	env *environment.Environment,
+	serviceConfig *ServiceConfig,
+	source string,
+) (*dockerSbom, error) {
+	options := serviceConfig.Docker.Sbom
+	format, err := options.format()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tools.EnsureInstalled(ctx, syftCli); err != nil {
+		return nil, err
+	}
+
+	sbomDir := filepath.Join(os.TempDir(), "azd-sbom")
+	if env.Root != "" {
+		sbomDir = filepath.Join(env.Root, sbomDirectoryName)
+	}
+
+	if err := os.MkdirAll(sbomDir, osutil.PermissionDirectory); err != nil {
+		return nil, fmt.Errorf("creating sbom directory: %w", err)
+	}
+
+	name := serviceConfig.Name
+	if variant := serviceConfig.Variant(); variant != "" {
+		name = fmt.Sprintf("%s-%s", name, variant)
+	}
+
+	sbomPath := filepath.Join(sbomDir, fmt.Sprintf("%s.%s.json", name, strings.TrimSuffix(format, "-json")))
+	log.Printf("generating %s sbom of %s to %s", format, source, sbomPath)
+
+	if err := syftCli.GenerateSbom(ctx, serviceConfig.Path(), source, format, sbomPath); err != nil {
+		return nil, err
+	}
+
+	return &dockerSbom{
+		Path:   sbomPath,
+		Format: format,
+		Push:   options.Push,
+	}, nil
+}
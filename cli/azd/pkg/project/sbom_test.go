@@ -0,0 +1,103 @@
+package project
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DockerProject_PackageSbom(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker tag")
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		environment.ContainerRegistryEndpointEnvVarName: "ACR_ENDPOINT",
+	})
+	env.Root = t.TempDir()
+
+	syftCli := &fakeSyftCli{}
+	dockerProject := NewDockerProject(
+		env,
+		docker.NewDocker(mockContext.CommandRunner),
+		git.NewGitCli(mockContext.CommandRunner),
+		syftCli,
+		trivy.NewTrivyCli(mockContext.CommandRunner),
+		grype.NewGrypeCli(mockContext.CommandRunner),
+		pack.NewPackCli(mockContext.CommandRunner),
+		hadolint.NewHadolintCli(mockContext.CommandRunner),
+		nil,
+		mockContext.Console,
+		clock.NewMock(),
+	)
+
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageTypeScript)
+	serviceConfig.Docker.Sbom = DockerSbomOptions{
+		Enabled: true,
+		Format:  syft.FormatCycloneDxJson,
+		Push:    true,
+	}
+
+	packageTask := dockerProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{BuildOutputPath: "IMAGE_ID"},
+	)
+	logProgress(packageTask)
+	result, err := packageTask.Await()
+	require.NoError(t, err)
+
+	sbomPath := filepath.Join(env.Root, "sbom", "api.cyclonedx.json")
+	require.Equal(t, &dockerSbom{
+		Path:   sbomPath,
+		Format: syft.FormatCycloneDxJson,
+		Push:   true,
+	}, result.Details.(*dockerPackageResult).Sbom)
+	require.Equal(t, []string{
+		"docker:ACR_ENDPOINT/test-app/api-test:azd-deploy-0",
+		syft.FormatCycloneDxJson,
+		sbomPath,
+	}, syftCli.args)
+
+	// Unsupported formats fail the package
+	serviceConfig.Docker.Sbom.Format = "xml"
+	packageTask = dockerProject.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServiceBuildResult{BuildOutputPath: "IMAGE_ID"},
+	)
+	logProgress(packageTask)
+	_, err = packageTask.Await()
+	require.ErrorContains(t, err, "unsupported sbom format 'xml'")
+}
+
+type fakeSyftCli struct {
+	fakeTool
+	args []string
+}
+
+func (cli *fakeSyftCli) GenerateSbom(
+	ctx context.Context,
+	cwd string,
+	source string,
+	format string,
+	outputPath string,
+) error {
+	cli.args = []string{source, format, outputPath}
+	return nil
+}
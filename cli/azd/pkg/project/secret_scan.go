@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/secretscan"
+)
+
+// The options of the secret scan run before the service package is created
+type SecretScanOptions struct {
+	// When enabled, packaging fails when potential credentials are found in the packaged files
+	Enabled bool `yaml:"enabled"`
+	// Glob patterns of the files excluded from the scan, relative to the scanned directory, ex) tests/**
+	Exclude []string `yaml:"exclude"`
+}
+
+// SecretScanError is returned when potential credentials are found in the files of a service package
+type SecretScanError struct {
+	ServiceName string
+	Path        string
+	Findings    []secretscan.Finding
+}
+
+func (e *SecretScanError) Error() string {
+	lines := []string{
+		fmt.Sprintf(
+			"found %d potential secret(s) in the files packaged for service '%s' (%s):",
+			len(e.Findings),
+			e.ServiceName,
+			e.Path,
+		),
+	}
+
+	for _, finding := range e.Findings {
+		lines = append(lines, fmt.Sprintf("  %s", finding))
+	}
+
+	lines = append(lines, fmt.Sprintf(
+		"Remove the secrets, exclude the files with 'secretScan.exclude' in azure.yaml, "+
+			"or add a '%s' comment to lines that are false positives",
+		secretscan.IgnoreMarker,
+	))
+
+	return strings.Join(lines, "\n")
+}
+
+// Scans the files packaged for the service for committed credentials.
+// Container images are scanned using the docker build context since the image is built from its files.
+func scanForSecrets(serviceConfig *ServiceConfig, packageOutput *ServicePackageResult) error {
+	scanPath := secretScanPath(serviceConfig, packageOutput)
+	log.Printf("scanning %s for secrets", scanPath)
+
+	findings, err := secretscan.ScanDirectory(scanPath, secretscan.Options{
+		Exclude: serviceConfig.SecretScan.Exclude,
+	})
+	if err != nil {
+		return fmt.Errorf("scanning for secrets: %w", err)
+	}
+
+	if len(findings) > 0 {
+		return &SecretScanError{
+			ServiceName: serviceConfig.Name,
+			Path:        scanPath,
+			Findings:    findings,
+		}
+	}
+
+	return nil
+}
+
+func secretScanPath(serviceConfig *ServiceConfig, packageOutput *ServicePackageResult) string {
+	if info, err := os.Stat(packageOutput.PackagePath); err == nil && info.IsDir() {
+		return packageOutput.PackagePath
+	}
+
+	if _, ok := packageOutput.Details.(*dockerPackageResult); ok {
+		dockerOptions := getDockerOptionsWithDefaults(serviceConfig.Docker)
+		if filepath.IsAbs(dockerOptions.Context) {
+			return dockerOptions.Context
+		}
+
+		return filepath.Join(serviceConfig.Path(), dockerOptions.Context)
+	}
+
+	return serviceConfig.Path()
+}
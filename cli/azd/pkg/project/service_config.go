@@ -24,10 +24,74 @@ type ServiceConfig struct {
 	OutputPath string `yaml:"dist"`
 	// The infrastructure module path relative to the root infra folder to use for this project
 	Module string `yaml:"module"`
+	// The optional group of the service, used to target related services in a single command, ex) backend
+	Group string `yaml:"group"`
+	// The optional services restored and built before the service, ex) a service bundling the output of another service
+	DependsOn []string `yaml:"dependsOn"`
+	// The optional settings overridden for specific environments, keyed by environment name
+	Environments map[string]ServiceEnvironmentOptions `yaml:"environments"`
+	// The optional variants the service is built in, keyed by variant name, ex) debug and release
+	Matrix map[string]ServiceVariantOptions `yaml:"matrix"`
+	// The optional instances the service is deployed as, keyed by instance name, ex) eastus and westus
+	Instances map[string]ServiceInstanceOptions `yaml:"instances"`
+	// The optional actions run after the service is deployed, ex) restarting a service that depends on it
+	OnDeployed []OnDeployedAction `yaml:"onDeployed"`
+	// The optional invocations of endpoints of the service on a schedule, ex) a nightly cleanup
+	Schedules []ServiceSchedule `yaml:"schedules"`
+	// The custom domains of the service, optionally verified to be live after the service is deployed
+	CustomDomains CustomDomainOptions `yaml:"customDomains"`
+	// The optional OpenAPI description of the API of the service and the clients generated for the consuming services
+	OpenApi OpenApiOptions `yaml:"openApi"`
+	// The optional options of the tests of the service, run by azd test
+	Test TestOptions `yaml:"test"`
+	// The optional private package feeds the dependencies of the service are restored from, ex) Azure Artifacts feeds
+	Feeds []PackageFeedOptions `yaml:"feeds"`
 	// The optional docker options
 	Docker DockerProjectOptions `yaml:"docker"`
+	// The optional build options of services of language java
+	Java JavaOptions `yaml:"java"`
+	// The optional conda options of services of language python
+	Python PythonOptions `yaml:"python"`
+	// The optional Node.js version options of services of language js or ts
+	Node NodeOptions `yaml:"node"`
+	// The optional Rails asset options of services of language ruby
+	Ruby RubyOptions `yaml:"ruby"`
+	// The optional dotnet publish options of services of language dotnet, csharp or fsharp
+	DotNet DotNetOptions `yaml:"dotnet"`
+	// The optional go build options of services of language go
+	Go GoOptions `yaml:"go"`
+	// The optional cargo build options of services of language rust
+	Rust RustOptions `yaml:"rust"`
+	// The commands of services of language custom
+	Custom CustomOptions `yaml:"custom"`
+	// The optional options of the framework plugin of services of languages handled by framework plugins
+	Plugin map[string]any `yaml:"plugin"`
+	// The optional compose file of services of language compose
+	Compose ComposeOptions `yaml:"compose"`
+	// The optional names under which environment values are exposed to the service
+	Env EnvOptions `yaml:"env"`
+	// The optional secrets of the service, keyed by the name they're exposed as, shared with the services consuming them
+	Secrets map[string]ServiceSecret `yaml:"secrets"`
+	// The optional secret scan run before the service is packaged
+	SecretScan SecretScanOptions `yaml:"secretScan"`
+	// The optional provenance attestation options for deployed artifacts
+	Provenance ProvenanceOptions `yaml:"provenance"`
+	// The optional compression options for package artifacts
+	Compression CompressionOptions `yaml:"compression"`
 	// The optional K8S / AKS options
 	K8s AksOptions `yaml:"k8s"`
+	// The optional Container Apps options
+	ContainerApp ContainerAppOptions `yaml:"containerApp"`
+	// The optional App Service options
+	AppService AppServiceOptions `yaml:"appService"`
+	// The optional virtual machine options
+	Vm VmOptions `yaml:"vm"`
+	// The optional Azure Batch job options
+	Batch BatchOptions `yaml:"batch"`
+	// The optional IoT Edge deployment options
+	IotEdge IotEdgeOptions `yaml:"iotEdge"`
+	// The optional Power Platform solution imported as part of the deployment
+	PowerPlatform PowerPlatformOptions `yaml:"powerPlatform"`
 	// The infrastructure provisioning configuration
 	Infra provisioning.Options `yaml:"infra"`
 	// Hook configuration for service
@@ -36,6 +100,18 @@ type ServiceConfig struct {
 	*ext.EventDispatcher[ServiceLifecycleEventArgs] `yaml:",omitempty"`
 
 	initialized bool
+	// The host declared in azure.yaml, before the overrides of the environment are applied
+	baseHost ServiceTargetKind
+	// The name of the variant, when the configuration is the configuration of a variant built by the matrix
+	variant string
+	// The values exposed to the builds of the variant
+	variantEnv map[string]string
+	// The name of the service declaring the instances, when the configuration is the configuration of an instance
+	instanceOf string
+	// The values of the instance
+	instanceEnv map[string]string
+	// The name of the compose service, when the service is a compose service of a service of language compose
+	composeService string
 }
 
 // Path returns the fully qualified path to the project
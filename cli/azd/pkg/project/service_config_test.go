@@ -224,6 +224,12 @@ func TestServiceConfigRaiseEventWithArgs(t *testing.T) {
 }
 
 func createTestServiceConfig(path string, host ServiceTargetKind, language ServiceLanguageKind) *ServiceConfig {
+	projectPath := "."
+	// Joining absolute paths, ex) temporary directories, to the current directory would make them relative
+	if filepath.IsAbs(path) {
+		projectPath = ""
+	}
+
 	return &ServiceConfig{
 		Name:         "api",
 		Host:         host,
@@ -231,7 +237,7 @@ func createTestServiceConfig(path string, host ServiceTargetKind, language Servi
 		RelativePath: filepath.Join(path),
 		Project: &ProjectConfig{
 			Name: "test-app",
-			Path: ".",
+			Path: projectPath,
 		},
 		EventDispatcher: ext.NewEventDispatcher[ServiceLifecycleEventArgs](),
 	}
@@ -0,0 +1,88 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The key of the section of azure.yaml with the configuration inherited by all the services of the project
+const serviceDefaultsKey = "defaults"
+
+// Applies the defaults section of the project file to the services of the project file, when the project file has one.
+// Returns the content unchanged otherwise, so errors parsing the project file are reported when parsing the project.
+func withServiceDefaults(yamlContent string) (string, error) {
+	var projectFile map[string]any
+	if err := yaml.Unmarshal([]byte(yamlContent), &projectFile); err != nil || projectFile[serviceDefaultsKey] == nil {
+		return yamlContent, nil
+	}
+
+	if err := applyServiceDefaults(projectFile); err != nil {
+		return "", err
+	}
+
+	content, err := yaml.Marshal(projectFile)
+	if err != nil {
+		return "", fmt.Errorf("applying defaults of services: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// Applies the defaults section of the project file to its services. Services inherit the configuration of the defaults
+// and override it with their own: maps are merged key by key, ex) docker or hooks, while other values, including lists,
+// are replaced by the value of the service.
+func applyServiceDefaults(projectFile map[string]any) error {
+	value, has := projectFile[serviceDefaultsKey]
+	if !has || value == nil {
+		return nil
+	}
+
+	defaults, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("parsing %s: must be a map of service configuration", serviceDefaultsKey)
+	}
+
+	for _, key := range []string{"project", "resourceName", "module", "secrets"} {
+		if _, has := defaults[key]; has {
+			return fmt.Errorf("parsing %s: '%s' is specific to each service and can't have a default", serviceDefaultsKey, key)
+		}
+	}
+
+	services, _ := projectFile["services"].(map[string]any)
+	for name, value := range services {
+		service, ok := value.(map[string]any)
+		if !ok && value != nil {
+			return fmt.Errorf("parsing service %s: must be a map of service configuration", name)
+		}
+
+		services[name] = mergeConfig(defaults, service)
+	}
+
+	return nil
+}
+
+// Merges the override configuration into the base configuration, recursively for the maps of both, without modifying
+// either of them
+func mergeConfig(base map[string]any, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range override {
+		baseMap, baseIsMap := merged[key].(map[string]any)
+		overrideMap, overrideIsMap := value.(map[string]any)
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeConfig(baseMap, overrideMap)
+			continue
+		}
+
+		merged[key] = value
+	}
+
+	return merged
+}
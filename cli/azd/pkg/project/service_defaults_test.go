@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const serviceDefaultsTestProject = `
+name: test-app
+defaults:
+  host: containerapp
+  language: js
+  docker:
+    path: ./Dockerfile.prod
+    target: runtime
+  env:
+    isolated: true
+    allow: [APP_INSIGHTS_CONNECTION_STRING]
+  hooks:
+    prebuild:
+      shell: sh
+      run: ./scripts/lint.sh
+services:
+  api:
+    project: src/api
+  web:
+    project: src/web
+    host: appservice
+    docker:
+      target: web
+    env:
+      allow: [API_URL]
+    hooks:
+      prebuild:
+        run: ./scripts/web.sh
+`
+
+func Test_ServiceDefaults(t *testing.T) {
+	projectConfig, err := Parse(context.Background(), serviceDefaultsTestProject)
+	require.NoError(t, err)
+
+	api := projectConfig.Services["api"]
+	require.Equal(t, ContainerAppTarget, api.Host)
+	require.Equal(t, ServiceLanguageJavaScript, api.Language)
+	require.Equal(t, "./Dockerfile.prod", api.Docker.Path)
+	require.Equal(t, "runtime", api.Docker.Target)
+	require.Equal(t, []string{"APP_INSIGHTS_CONNECTION_STRING"}, api.Env.Allow)
+	require.Equal(t, "./scripts/lint.sh", api.Hooks["prebuild"].Run)
+
+	// Services override the defaults, maps are merged key by key while lists are replaced
+	web := projectConfig.Services["web"]
+	require.Equal(t, AppServiceTarget, web.Host)
+	require.Equal(t, "./Dockerfile.prod", web.Docker.Path)
+	require.Equal(t, "web", web.Docker.Target)
+	require.True(t, web.Env.Isolated)
+	require.Equal(t, []string{"API_URL"}, web.Env.Allow)
+	require.Equal(t, "./scripts/web.sh", web.Hooks["prebuild"].Run)
+	require.EqualValues(t, "sh", web.Hooks["prebuild"].Shell)
+}
+
+func Test_ServiceDefaults_ServiceSpecific(t *testing.T) {
+	_, err := Parse(context.Background(),
+		"name: test-app\ndefaults:\n  project: src\nservices:\n  api:\n    project: src/api\n")
+	require.ErrorContains(t, err, "'project' is specific to each service and can't have a default")
+}
@@ -0,0 +1,292 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"golang.org/x/exp/slices"
+)
+
+type EnvCasing string
+
+const (
+	// Environment values are exposed using their original names
+	EnvCasingNone EnvCasing = ""
+	// Environment values are exposed in upper snake case, ex) cosmosEndpoint -> COSMOS_ENDPOINT
+	EnvCasingUpperSnake EnvCasing = "upper-snake"
+)
+
+// EnvOptions controls the names under which environment values, including infrastructure outputs,
+// are exposed to the service when building, packaging and deploying it.
+type EnvOptions struct {
+	// The casing applied to the names of all environment values
+	Casing EnvCasing `yaml:"casing"`
+	// Additional names for environment values, from the name exposed to the service to the name of the
+	// environment value or infrastructure output, ex) COSMOS_ENDPOINT: cosmosEndpoint.
+	// The environment value name is matched case-insensitively
+	Map map[string]string `yaml:"map"`
+	// When enabled, the builds and hooks of the service only get the environment values of the service, ex)
+	// SERVICE_API_IMAGE_NAME, the values in Map and the values in Allow, instead of all the environment values.
+	// The hooks of the service also only inherit the variables of the environment azd runs in that are in Allow.
+	// Prevents services from depending on the values of other services.
+	Isolated bool `yaml:"isolated"`
+	// The names of the environment values, and of the variables of the environment azd runs in inherited by hooks,
+	// available to the service when isolated, in addition to the variables required by most tools, ex) PATH and HOME.
+	// Names ending with * match by prefix, ex) AZURE_*
+	Allow []string `yaml:"allow"`
+}
+
+// EnvScope gets the scope of the environment of the hooks of the service, or nil when the service isn't
+// isolated. The environment values of the service, prefixed with SERVICE_{NAME}_, are always in scope.
+func (sc *ServiceConfig) EnvScope() *exec.EnvScope {
+	if !sc.Env.Isolated {
+		return nil
+	}
+
+	allow := []string{fmt.Sprintf("SERVICE_%s_*", strings.ReplaceAll(strings.ToUpper(sc.Name), "-", "_"))}
+	return &exec.EnvScope{Allow: append(allow, sc.Env.Allow...)}
+}
+
+// Environ creates a slice of key value pairs like `KEY=VALUE` from the environment values, applying the
+// env options of the service. Returns an error when different environment values are exposed under the same name.
+// Names are compared case-insensitively since environment variables are case-insensitive on Windows.
+func (sc *ServiceConfig) Environ(env *environment.Environment) ([]string, error) {
+	values, err := sc.EnvValues(env)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars := make([]string, 0, len(values))
+	for key, value := range values {
+		envVars = append(envVars, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	return envVars, nil
+}
+
+// EnvValues gets the environment values exposed to the service, keyed by the name exposed to the service.
+func (sc *ServiceConfig) EnvValues(env *environment.Environment) (map[string]string, error) {
+	values, sources, err := sc.mapEnvValues(env.Dotenv())
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := sc.SecretValues(env)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range secrets {
+		if existing, has := sources[strings.ToUpper(name)]; has {
+			return nil, fmt.Errorf(
+				"environment value '%s' and secret '%s' are both exposed to service '%s' as '%s'",
+				existing,
+				name,
+				sc.Name,
+				name,
+			)
+		}
+
+		values[name] = value
+	}
+
+	// The values of the instance and of the variant take precedence, as they're declared for the instance and the
+	// variant specifically
+	for name, value := range sc.instanceEnv {
+		values[name] = value
+	}
+
+	for name, value := range sc.variantEnv {
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// Applies the casing and the map of the env options of the service to the values, ex) the environment values or the
+// outputs of the infrastructure. Returns the values keyed by the name exposed to the service, and the names of the
+// values, keyed by the upper case name exposed to the service.
+func (sc *ServiceConfig) mapEnvValues(envValues map[string]string) (map[string]string, map[string]string, error) {
+	casing := sc.Env.Casing
+	if casing != EnvCasingNone && casing != EnvCasingUpperSnake {
+		return nil, nil, fmt.Errorf(
+			"invalid env casing '%s' for service '%s', supported values: %s",
+			casing,
+			sc.Name,
+			EnvCasingUpperSnake,
+		)
+	}
+
+	// Sorted so collisions are always reported for the same pair of names
+	keys := make([]string, 0, len(envValues))
+	for key := range envValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Values outside of the scope of an isolated service are only exposed when mapped explicitly
+	exposed := keys
+	if scope := sc.EnvScope(); scope != nil {
+		exposed = []string{}
+		for _, key := range keys {
+			if scope.Allows(key) {
+				exposed = append(exposed, key)
+			}
+		}
+	}
+
+	values := map[string]string{}
+	// Tracks the source of each exposed name, keyed by the upper case exposed name
+	sources := map[string]string{}
+	expose := func(name string, source string, origin string) error {
+		key := strings.ToUpper(name)
+		if existing, has := sources[key]; has && existing != source {
+			return fmt.Errorf(
+				"environment values '%s' and '%s' are both exposed to service '%s' as '%s'%s",
+				existing,
+				source,
+				sc.Name,
+				name,
+				origin,
+			)
+		}
+
+		sources[key] = source
+		values[name] = envValues[source]
+		return nil
+	}
+
+	for _, key := range exposed {
+		name := key
+		if casing == EnvCasingUpperSnake {
+			name = toUpperSnakeCase(key)
+		}
+
+		if err := expose(name, key, ""); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	names := make([]string, 0, len(sc.Env.Map))
+	for name := range sc.Env.Map {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		source, has := findEnvKey(keys, sc.Env.Map[name])
+		if !has {
+			// The value may not be available yet, ex) when packaging before the infrastructure is provisioned
+			log.Printf(
+				"environment value '%s' mapped to '%s' for service '%s' was not found",
+				sc.Env.Map[name],
+				name,
+				sc.Name,
+			)
+			continue
+		}
+
+		if err := expose(name, source, fmt.Sprintf(", check 'env.map.%s' in azure.yaml", name)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return values, sources, nil
+}
+
+// The languages whose framework services export the environment values to the builds of the service, ex) npm scripts,
+// and the user secrets of .NET projects
+var envExportLanguages = []ServiceLanguageKind{
+	ServiceLanguageJavaScript,
+	ServiceLanguageTypeScript,
+	ServiceLanguageRuby,
+	ServiceLanguageCustom,
+	ServiceLanguageDotNet,
+	ServiceLanguageCsharp,
+	ServiceLanguageFsharp,
+}
+
+// Validates the casing and the map of the env options are applied to the values exported to the service. The values
+// are exported to the builds of some languages, and to the deployments of AKS and virtual machines. Other hosts read the
+// infrastructure outputs from the settings declared by the infrastructure, ex) the app settings of App Service or the
+// env of a container app, which reference the outputs by their names.
+func validateEnvOptions(serviceConfig *ServiceConfig) error {
+	if serviceConfig.Env.Casing == EnvCasingNone && len(serviceConfig.Env.Map) == 0 {
+		return nil
+	}
+
+	if serviceConfig.Env.Casing != EnvCasingUpperSnake && serviceConfig.Env.Casing != EnvCasingNone {
+		return fmt.Errorf("invalid env casing '%s', supported values: %s", serviceConfig.Env.Casing, EnvCasingUpperSnake)
+	}
+
+	if serviceConfig.Host == AksTarget || serviceConfig.Host == VmTarget {
+		return nil
+	}
+
+	_, isPlugin := FindFrameworkPlugin(serviceConfig.Language)
+	if !serviceConfig.Host.RequiresContainer() &&
+		(isPlugin || slices.Contains(envExportLanguages, serviceConfig.Language)) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"'env.casing' and 'env.map' aren't applied to services of language '%s' on host '%s', the values are only "+
+			"exported to the builds of js, ts, ruby, custom, .NET and framework plugin services, and to the deployments "+
+			"of '%s' and '%s' services. Reference the infrastructure outputs from the settings of the service in the "+
+			"infrastructure instead",
+		serviceConfig.Language,
+		serviceConfig.Host,
+		AksTarget,
+		VmTarget,
+	)
+}
+
+// Finds the environment value name matching the specified name case-insensitively, preferring an exact match
+func findEnvKey(keys []string, name string) (string, bool) {
+	match := ""
+	for _, key := range keys {
+		if key == name {
+			return key, true
+		}
+
+		if match == "" && strings.EqualFold(key, name) {
+			match = key
+		}
+	}
+
+	return match, match != ""
+}
+
+// Converts a name to upper snake case, ex) cosmosEndpoint -> COSMOS_ENDPOINT, apiURLPath -> API_URL_PATH.
+// Characters other than letters and digits are replaced with underscores.
+func toUpperSnakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			builder.WriteRune('_')
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				builder.WriteRune('_')
+			}
+		}
+
+		builder.WriteRune(unicode.ToUpper(r))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceConfig_EnvValues(t *testing.T) {
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"cosmosEndpoint":        "https://cosmos",
+		"AZURE_STORAGE_URL":     "https://storage",
+		"apiURLPath":            "/api",
+		"AZURE_KEY_VAULT_NAME":  "vault",
+		"AZURE_RESOURCE_GROUP":  "rg",
+		"SERVICE_API_ENDPOINTS": "[]",
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+
+		values, err := serviceConfig.EnvValues(env)
+		require.NoError(t, err)
+		require.Equal(t, env.Values, values)
+	})
+
+	t.Run("UpperSnakeCasing", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Env = EnvOptions{Casing: EnvCasingUpperSnake}
+
+		values, err := serviceConfig.EnvValues(env)
+		require.NoError(t, err)
+		require.Equal(t, "https://cosmos", values["COSMOS_ENDPOINT"])
+		require.Equal(t, "/api", values["API_URL_PATH"])
+		require.Equal(t, "https://storage", values["AZURE_STORAGE_URL"])
+		require.NotContains(t, values, "cosmosEndpoint")
+	})
+
+	t.Run("Map", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Env = EnvOptions{
+			Map: map[string]string{
+				"COSMOS_ENDPOINT": "COSMOSENDPOINT",
+				"KeyVault__Name":  "AZURE_KEY_VAULT_NAME",
+				"MISSING":         "notProvisionedYet",
+			},
+		}
+
+		values, err := serviceConfig.EnvValues(env)
+		require.NoError(t, err)
+		require.Equal(t, "https://cosmos", values["COSMOS_ENDPOINT"])
+		require.Equal(t, "vault", values["KeyVault__Name"])
+		require.Equal(t, "vault", values["AZURE_KEY_VAULT_NAME"])
+		require.NotContains(t, values, "MISSING")
+	})
+
+	t.Run("CasingCollision", func(t *testing.T) {
+		collidingEnv := environment.EphemeralWithValues("test", map[string]string{
+			"cosmosEndpoint":  "https://cosmos",
+			"COSMOS_ENDPOINT": "https://other",
+		})
+
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Env = EnvOptions{Casing: EnvCasingUpperSnake}
+
+		values, err := serviceConfig.EnvValues(collidingEnv)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "'COSMOS_ENDPOINT' and 'cosmosEndpoint' are both exposed")
+		require.Nil(t, values)
+	})
+
+	t.Run("MapCollision", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Env = EnvOptions{
+			Map: map[string]string{
+				"azure_resource_group": "cosmosEndpoint",
+			},
+		}
+
+		values, err := serviceConfig.EnvValues(env)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "env.map.azure_resource_group")
+		require.Nil(t, values)
+	})
+
+	t.Run("Isolated", func(t *testing.T) {
+		isolatedEnv := environment.EphemeralWithValues("test", map[string]string{
+			"AZURE_RESOURCE_GROUP":    "rg",
+			"AZURE_KEY_VAULT_NAME":    "vault",
+			"cosmosEndpoint":          "https://cosmos",
+			"SERVICE_API_ENDPOINTS":   "[]",
+			"SERVICE_WEB_IMAGE_NAME":  "web:latest",
+			"SERVICE_API_IMAGE_NAME":  "api:latest",
+			"SERVICE_API2_IMAGE_NAME": "api2:latest",
+		})
+
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Env = EnvOptions{
+			Isolated: true,
+			Allow:    []string{"AZURE_KEY_*"},
+			Map:      map[string]string{"COSMOS_ENDPOINT": "cosmosEndpoint"},
+		}
+
+		values, err := serviceConfig.EnvValues(isolatedEnv)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"AZURE_KEY_VAULT_NAME":   "vault",
+			"COSMOS_ENDPOINT":        "https://cosmos",
+			"SERVICE_API_ENDPOINTS":  "[]",
+			"SERVICE_API_IMAGE_NAME": "api:latest",
+		}, values)
+	})
+
+	t.Run("InvalidCasing", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig("./src/api", AppServiceTarget, ServiceLanguageJavaScript)
+		serviceConfig.Env = EnvOptions{Casing: "kebab"}
+
+		_, err := serviceConfig.EnvValues(env)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "invalid env casing")
+	})
+}
+
+func Test_ValidateEnvOptions(t *testing.T) {
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguagePython)
+	require.NoError(t, validateEnvOptions(serviceConfig))
+
+	serviceConfig.Env = EnvOptions{Isolated: true, Allow: []string{"AZURE_*"}}
+	require.NoError(t, validateEnvOptions(serviceConfig))
+
+	// Container apps read the outputs from the env declared by the infrastructure
+	serviceConfig.Env = EnvOptions{Map: map[string]string{"COSMOS_ENDPOINT": "cosmosEndpoint"}}
+	require.ErrorContains(t, validateEnvOptions(serviceConfig), "aren't applied to services of language 'python'")
+
+	serviceConfig.Host = AksTarget
+	require.NoError(t, validateEnvOptions(serviceConfig))
+
+	// Python builds don't get the environment values
+	serviceConfig.Host = AppServiceTarget
+	require.Error(t, validateEnvOptions(serviceConfig))
+
+	serviceConfig.Language = ServiceLanguageTypeScript
+	require.NoError(t, validateEnvOptions(serviceConfig))
+
+	serviceConfig.Env = EnvOptions{Casing: "camel"}
+	require.ErrorContains(t, validateEnvOptions(serviceConfig), "invalid env casing 'camel'")
+}
+
+func Test_ToUpperSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"cosmosEndpoint":    "COSMOS_ENDPOINT",
+		"COSMOS_ENDPOINT":   "COSMOS_ENDPOINT",
+		"apiURLPath":        "API_URL_PATH",
+		"storage2Account":   "STORAGE2_ACCOUNT",
+		"service-api.name":  "SERVICE_API_NAME",
+		"AZURE_OPENAI_KEY1": "AZURE_OPENAI_KEY1",
+	}
+
+	for input, expected := range tests {
+		require.Equal(t, expected, toUpperSnakeCase(input), input)
+	}
+}
@@ -0,0 +1,175 @@
+package project
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+const (
+	// The service property exposing the effective host of a service to the infrastructure, ex) SERVICE_API_HOST
+	serviceHostProperty = "HOST"
+	// The service property exposing the effective SKU tier of a service to the infrastructure, ex) SERVICE_API_SKU
+	serviceSkuProperty = "SKU"
+)
+
+// The settings of a service overridden for an environment, ex) a containerapp host in dev and an aks host in prod
+type ServiceEnvironmentOptions struct {
+	// The azure hosting model used in the environment
+	Host ServiceTargetKind `yaml:"host"`
+	// The SKU tier used in the environment, ex) B1 or P1v3
+	Sku string `yaml:"sku"`
+	// The variant of the matrix of the service deployed to the environment, ex) release
+	Variant string `yaml:"variant"`
+}
+
+// ApplyEnvironment applies the service overrides declared for the environment to the services of the project.
+//
+// The effective host and SKU tier of the services that declare overrides are also set as the SERVICE_<NAME>_HOST and
+// SERVICE_<NAME>_SKU environment values, so the infrastructure provisions the matching resources. A service whose host
+// or SKU tier changes between environments requires its infrastructure parameters to reference these values.
+func (p *ProjectConfig) ApplyEnvironment(env *environment.Environment) error {
+	for _, svc := range p.Services {
+		// The values of instances are exposed to the infrastructure, ex) SERVICE_WORKER_EASTUS_QUEUE_NAME
+		for name, value := range svc.instanceEnv {
+			env.SetServiceProperty(svc.Name, name, value)
+		}
+
+		if len(svc.Environments) == 0 {
+			continue
+		}
+
+		if err := validateServiceEnvironments(p, svc); err != nil {
+			return err
+		}
+
+		// Overrides are applied from the host declared by the service, so the project can be applied more than once
+		if svc.baseHost == "" {
+			svc.baseHost = svc.Host
+		}
+
+		svc.Host = svc.baseHost
+		envName := env.GetEnvName()
+		if overrides, has := svc.Environments[envName]; has {
+			if overrides.Host != "" {
+				log.Printf("using host '%s' for service '%s' in environment '%s'\n", overrides.Host, svc.Name, envName)
+				svc.Host = overrides.Host
+			}
+
+			if overrides.Sku != "" {
+				env.SetServiceProperty(svc.Name, serviceSkuProperty, overrides.Sku)
+			}
+		}
+
+		// The env options are exported by the host of the environment
+		if err := validateEnvOptions(svc); err != nil {
+			return fmt.Errorf("service '%s' in environment '%s': %w", svc.Name, envName, err)
+		}
+
+		env.SetServiceProperty(svc.Name, serviceHostProperty, string(svc.Host))
+	}
+
+	return nil
+}
+
+// Validates the infrastructure of the project supports the hosts and SKU tiers used by the environments of the service
+func validateServiceEnvironments(projectConfig *ProjectConfig, svc *ServiceConfig) error {
+	baseHost := svc.baseHost
+	if baseHost == "" {
+		baseHost = svc.Host
+	}
+
+	hosts := map[ServiceTargetKind]struct{}{baseHost: {}}
+	hasSku := false
+	for _, overrides := range svc.Environments {
+		if overrides.Host != "" {
+			hosts[overrides.Host] = struct{}{}
+		}
+
+		if overrides.Sku != "" {
+			hasSku = true
+		}
+	}
+
+	if len(hosts) == 1 && !hasSku {
+		return nil
+	}
+
+	parametersPath, contents, err := readInfraParameters(projectConfig)
+	if err != nil {
+		return err
+	}
+
+	if parametersPath == "" {
+		log.Printf("skipping infrastructure validation of service '%s', parameters file not found\n", svc.Name)
+		return nil
+	}
+
+	if len(hosts) > 1 {
+		hostNames := []string{}
+		for host := range hosts {
+			hostNames = append(hostNames, string(host))
+		}
+		sort.Strings(hostNames)
+
+		if err := ensureInfraParameter(parametersPath, contents, svc, serviceHostProperty); err != nil {
+			return fmt.Errorf("service '%s' uses hosts '%s': %w", svc.Name, strings.Join(hostNames, "', '"), err)
+		}
+	}
+
+	if hasSku {
+		if err := ensureInfraParameter(parametersPath, contents, svc, serviceSkuProperty); err != nil {
+			return fmt.Errorf("service '%s' overrides its SKU tier: %w", svc.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func ensureInfraParameter(parametersPath string, contents string, svc *ServiceConfig, propertyName string) error {
+	envName := fmt.Sprintf("SERVICE_%s_%s", strings.ReplaceAll(strings.ToUpper(svc.Name), "-", "_"), propertyName)
+	if !strings.Contains(contents, fmt.Sprintf("${%s}", envName)) {
+		return fmt.Errorf(
+			"the infrastructure doesn't support it, '%s' must reference '${%s}'",
+			parametersPath,
+			envName,
+		)
+	}
+
+	return nil
+}
+
+// Reads the parameters file of the infrastructure module of the project.
+// Returns an empty path when the provider doesn't use a parameters file or the file doesn't exist.
+func readInfraParameters(projectConfig *ProjectConfig) (string, string, error) {
+	module := projectConfig.Infra.Module
+	if module == "" {
+		module = "main"
+	}
+
+	var parametersFileName string
+	switch projectConfig.Infra.Provider {
+	case "", provisioning.Bicep:
+		parametersFileName = fmt.Sprintf("%s.parameters.json", module)
+	case provisioning.Terraform:
+		parametersFileName = fmt.Sprintf("%s.tfvars.json", module)
+	default:
+		return "", "", nil
+	}
+
+	parametersPath := filepath.Join(infraPath(projectConfig), parametersFileName)
+	contents, err := os.ReadFile(parametersPath)
+	if os.IsNotExist(err) {
+		return "", "", nil
+	} else if err != nil {
+		return "", "", fmt.Errorf("reading infrastructure parameters: %w", err)
+	}
+
+	return parametersPath, string(contents), nil
+}
@@ -0,0 +1,107 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testEnvironmentsProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+    environments:
+      prod:
+        host: aks
+        sku: P1v3
+  web:
+    project: src/web
+    language: js
+    host: appservice
+`
+
+const testEnvironmentsParameters = `{
+  "parameters": {
+    "apiHost": { "value": "${SERVICE_API_HOST}" },
+    "apiSku": { "value": "${SERVICE_API_SKU}" }
+  }
+}`
+
+func Test_ProjectConfig_ApplyEnvironment(t *testing.T) {
+	t.Run("AppliesOverrides", func(t *testing.T) {
+		projectConfig := parseEnvironmentsProject(t, testEnvironmentsParameters)
+
+		env := environment.EphemeralWithValues("prod", nil)
+		require.NoError(t, projectConfig.ApplyEnvironment(env))
+		require.Equal(t, AksTarget, projectConfig.Services["api"].Host)
+		require.Equal(t, "aks", env.GetServiceProperty("api", "HOST"))
+		require.Equal(t, "P1v3", env.GetServiceProperty("api", "SKU"))
+
+		// Services without overrides are left unchanged
+		require.Equal(t, AppServiceTarget, projectConfig.Services["web"].Host)
+		require.Equal(t, "", env.GetServiceProperty("web", "HOST"))
+
+		// Applying another environment restores the host declared by the service
+		env = environment.EphemeralWithValues("dev", nil)
+		require.NoError(t, projectConfig.ApplyEnvironment(env))
+		require.Equal(t, ContainerAppTarget, projectConfig.Services["api"].Host)
+		require.Equal(t, "containerapp", env.GetServiceProperty("api", "HOST"))
+		require.Equal(t, "", env.GetServiceProperty("api", "SKU"))
+	})
+
+	t.Run("InfraMissingHostParameter", func(t *testing.T) {
+		projectConfig := parseEnvironmentsProject(t, `{"parameters": {"apiSku": {"value": "${SERVICE_API_SKU}"}}}`)
+
+		err := projectConfig.ApplyEnvironment(environment.EphemeralWithValues("prod", nil))
+		require.ErrorContains(t, err, "service 'api' uses hosts 'aks', 'containerapp'")
+		require.ErrorContains(t, err, "${SERVICE_API_HOST}")
+	})
+
+	t.Run("InfraMissingSkuParameter", func(t *testing.T) {
+		projectConfig := parseEnvironmentsProject(t, `{"parameters": {"apiHost": {"value": "${SERVICE_API_HOST}"}}}`)
+
+		err := projectConfig.ApplyEnvironment(environment.EphemeralWithValues("dev", nil))
+		require.ErrorContains(t, err, "${SERVICE_API_SKU}")
+	})
+}
+
+func Test_ProjectConfig_InvalidEnvironmentHost(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+    environments:
+      prod:
+        host: unknown
+`
+
+	mockContext := mocks.NewMockContext(context.Background())
+	_, err := Parse(*mockContext.Context, testProj)
+	require.ErrorContains(t, err, "unsupported host 'unknown'")
+}
+
+func parseEnvironmentsProject(t *testing.T, parameters string) *ProjectConfig {
+	mockContext := mocks.NewMockContext(context.Background())
+	projectConfig, err := Parse(*mockContext.Context, testEnvironmentsProj)
+	require.NoError(t, err)
+
+	projectConfig.Path = t.TempDir()
+	infraDir := filepath.Join(projectConfig.Path, "infra")
+	require.NoError(t, os.MkdirAll(infraDir, osutil.PermissionDirectory))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(infraDir, "main.parameters.json"), []byte(parameters), osutil.PermissionFile))
+
+	return projectConfig
+}
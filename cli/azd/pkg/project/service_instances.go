@@ -0,0 +1,100 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ServiceInstanceOptions are the settings of an instance of a service, ex) a worker deployed per region or per queue.
+// Each instance is a service of the project named <service>-<instance>, with the settings of the service and the settings
+// of the instance applied, so it is deployed to its own resources, tagged azd-service-name: <service>-<instance>.
+type ServiceInstanceOptions struct {
+	// The name of the Azure resource of the instance. Required when the service overrides the name of its resource, so
+	// instances don't deploy to the same resource.
+	ResourceName ExpandableString `yaml:"resourceName"`
+	// The values of the instance, exposed to its builds, hooks and deployments, and to the infrastructure as
+	// SERVICE_<SERVICE>_<INSTANCE>_<NAME> environment values, ex) QUEUE_NAME: orders-eastus
+	Env map[string]string `yaml:"env"`
+}
+
+// InstanceOf gets the name of the service declaring the instances, when the service is an instance of a service
+func (sc *ServiceConfig) InstanceOf() string {
+	return sc.instanceOf
+}
+
+// Replaces the services of the project declaring instances with their instances
+func expandInstances(projectConfig *ProjectConfig) error {
+	names := maps.Keys(projectConfig.Services)
+	slices.Sort(names)
+
+	for _, name := range names {
+		svc := projectConfig.Services[name]
+		if svc == nil || svc.Instances == nil {
+			continue
+		}
+
+		if len(svc.Instances) == 0 {
+			return fmt.Errorf("parsing service %s: 'instances' must declare at least one instance", name)
+		}
+
+		delete(projectConfig.Services, name)
+
+		instanceNames := maps.Keys(svc.Instances)
+		slices.Sort(instanceNames)
+		for _, instanceName := range instanceNames {
+			instance := svc.Instances[instanceName]
+			config := *svc
+			config.Instances = nil
+			config.instanceOf = name
+			config.instanceEnv = instance.Env
+
+			// The instances share the infrastructure module of the service
+			if config.Module == "" {
+				config.Module = name
+			}
+
+			if strings.TrimSpace(instance.ResourceName.template) != "" {
+				config.ResourceName = instance.ResourceName
+			} else if strings.TrimSpace(svc.ResourceName.template) != "" {
+				return fmt.Errorf(
+					"parsing service %s: instance '%s' must set 'resourceName', since the service overrides the name "+
+						"of its resource",
+					name,
+					instanceName,
+				)
+			}
+
+			instanceKey := fmt.Sprintf("%s-%s", name, instanceName)
+			if _, has := projectConfig.Services[instanceKey]; has {
+				return fmt.Errorf(
+					"parsing service %s: instance '%s' conflicts with service '%s'", name, instanceName, instanceKey)
+			}
+
+			projectConfig.Services[instanceKey] = &config
+		}
+	}
+
+	return nil
+}
+
+// Gets the instances of the service declaring instances, sorted by name
+func (p *ProjectConfig) serviceInstances(name string) []*ServiceConfig {
+	instances := []*ServiceConfig{}
+	for _, svc := range p.Services {
+		if svc != nil && svc.instanceOf == name {
+			instances = append(instances, svc)
+		}
+	}
+
+	slices.SortFunc(instances, func(a, b *ServiceConfig) bool {
+		return a.Name < b.Name
+	})
+
+	return instances
+}
@@ -0,0 +1,110 @@
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+const instancesProject = `name: test-proj
+services:
+  web:
+    project: src/web
+    language: js
+    host: appservice
+  worker:
+    project: src/worker
+    language: js
+    host: aks
+    env:
+      map:
+        COSMOS_ENDPOINT: cosmosEndpoint
+    instances:
+      eastus:
+        env:
+          QUEUE_NAME: orders-eastus
+      westus:
+        resourceName: worker-${AZURE_ENV_NAME}-westus
+        env:
+          QUEUE_NAME: orders-westus
+`
+
+func Test_ExpandInstances(t *testing.T) {
+	projectConfig, err := Parse(context.Background(), instancesProject)
+	require.NoError(t, err)
+
+	require.False(t, projectConfig.HasService("worker"))
+	require.True(t, projectConfig.HasService("worker-eastus"))
+	require.True(t, projectConfig.HasService("worker-westus"))
+
+	eastus := projectConfig.Services["worker-eastus"]
+	require.Equal(t, "worker-eastus", eastus.Name)
+	require.Equal(t, "worker", eastus.InstanceOf())
+	require.Equal(t, "worker", eastus.Module)
+	require.Equal(t, AksTarget, eastus.Host)
+	require.Equal(t, "cosmosEndpoint", eastus.Env.Map["COSMOS_ENDPOINT"])
+
+	westus := projectConfig.Services["worker-westus"]
+	resourceName, err := westus.ResourceName.Envsubst(func(string) string { return "dev" })
+	require.NoError(t, err)
+	require.Equal(t, "worker-dev-westus", resourceName)
+
+	// Targeting the service targets its instances
+	services, err := projectConfig.SelectServices("worker", "")
+	require.NoError(t, err)
+	require.Len(t, services, 2)
+	require.Equal(t, "worker-eastus", services[0].Name)
+	require.Equal(t, "worker-westus", services[1].Name)
+
+	services, err = projectConfig.SelectServices("worker-westus", "")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+
+	// The values of the instances are exposed to the instances and to the infrastructure
+	env := environment.EphemeralWithValues("dev", map[string]string{"cosmosEndpoint": "https://cosmos"})
+	require.NoError(t, projectConfig.ApplyEnvironment(env))
+	require.Equal(t, "orders-eastus", env.Values["SERVICE_WORKER_EASTUS_QUEUE_NAME"])
+	require.Equal(t, "orders-westus", env.Values["SERVICE_WORKER_WESTUS_QUEUE_NAME"])
+
+	values, err := eastus.EnvValues(env)
+	require.NoError(t, err)
+	require.Equal(t, "orders-eastus", values["QUEUE_NAME"])
+	require.Equal(t, "https://cosmos", values["COSMOS_ENDPOINT"])
+}
+
+func Test_ExpandInstances_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "NoInstances",
+			yaml: "name: test\nservices:\n  worker:\n    project: src/worker\n    language: js\n    host: containerapp\n" +
+				"    instances: {}\n",
+			wantErr: "'instances' must declare at least one instance",
+		},
+		{
+			name: "SharedResourceName",
+			yaml: "name: test\nservices:\n  worker:\n    project: src/worker\n    language: js\n    host: containerapp\n" +
+				"    resourceName: worker\n    instances:\n      eastus: {}\n",
+			wantErr: "instance 'eastus' must set 'resourceName'",
+		},
+		{
+			name: "Conflict",
+			yaml: "name: test\nservices:\n  worker:\n    project: src/worker\n    language: js\n    host: containerapp\n" +
+				"    instances:\n      eastus: {}\n  worker-eastus:\n    project: src/other\n    language: js\n" +
+				"    host: containerapp\n",
+			wantErr: "instance 'eastus' conflicts with service 'worker-eastus'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(context.Background(), tt.yaml)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
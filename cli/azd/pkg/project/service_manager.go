@@ -9,9 +9,14 @@ import (
 
 	"github.com/azure/azure-dev/cli/azd/pkg/async"
 	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
 	"github.com/azure/azure-dev/cli/azd/pkg/ioc"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/pack"
 )
 
 const (
@@ -20,16 +25,29 @@ const (
 	ServiceEventEnvUpdated ext.Event = "environment updated"
 	ServiceEventRestore    ext.Event = "restore"
 	ServiceEventBuild      ext.Event = "build"
+	ServiceEventTest       ext.Event = "test"
 	ServiceEventPackage    ext.Event = "package"
 	ServiceEventPublish    ext.Event = "publish"
 	ServiceEventDeploy     ext.Event = "deploy"
 )
 
 var (
+	// ServiceEvents are the events of the lifecycle of services. Handlers subscribe to the pre and post events of the
+	// operations of a service with its EventDispatcher, ex) prerestore and postpackage. For a deployed service, the
+	// events are raised in order: predeploy, prerestore, postrestore, prebuild, postbuild, prepackage, postpackage,
+	// prepublish, postpublish and postdeploy. The pretest and posttest events are raised when the tests of the service
+	// are run, after the restore. The environment updated event is raised for each service after the infrastructure is
+	// provisioned or the environment is refreshed.
+	//
+	// The operation fails when a handler of its pre event fails, and post events are only raised when the operation
+	// succeeds, see ext.EventDispatcher.
 	ServiceEvents []ext.Event = []ext.Event{
 		ServiceEventEnvUpdated,
 		ServiceEventRestore,
+		ServiceEventBuild,
+		ServiceEventTest,
 		ServiceEventPackage,
+		ServiceEventPublish,
 		ServiceEventDeploy,
 	}
 )
@@ -46,7 +64,21 @@ type ServiceDeploymentChannelResponse struct {
 type ServiceLifecycleEventArgs struct {
 	Project *ProjectConfig
 	Service *ServiceConfig
-	Args    map[string]any
+	// Deprecated: use the typed fields of the event instead, ex) Outputs
+	Args map[string]any
+
+	// The result of the restore of the service, set for the postrestore event
+	Restore *ServiceRestoreResult
+	// The result of the build of the service, set for the postbuild event
+	Build *ServiceBuildResult
+	// The result of the tests of the service, set for the posttest event
+	Test *ServiceTestResult
+	// The result of the package of the service, set for the postpackage and postdeploy events
+	Package *ServicePackageResult
+	// The result of the publish of the service, set for the postpublish and postdeploy events
+	Publish *ServicePublishResult
+	// The outputs of the provisioned infrastructure, set for the environment updated event
+	Outputs map[string]provisioning.OutputParameter
 }
 
 // ServiceProgress represents an incremental progress message
@@ -75,6 +107,15 @@ type ServiceBuildResult struct {
 	Restore         *ServiceRestoreResult `json:"restore"`
 	BuildOutputPath string                `json:"buildOutputPath"`
 	Details         interface{}           `json:"details"`
+	// The results of the builds of the variants of the service, keyed by variant name, when the service declares a matrix
+	Variants map[string]*ServiceBuildResult `json:"variants,omitempty"`
+}
+
+// ServiceTestResult is the result of a successful Test operation
+type ServiceTestResult struct {
+	// Whether the tests weren't run, ex) the service opted out of tests or has no tests
+	Skipped bool        `json:"skipped"`
+	Details interface{} `json:"details"`
 }
 
 // ServicePackageResult is the result of a successful Package operation
@@ -82,6 +123,9 @@ type ServicePackageResult struct {
 	Build       *ServiceBuildResult `json:"package"`
 	Details     interface{}         `json:"details"`
 	PackagePath string              `json:"packagePath"`
+	// The packages of the variants of the service, keyed by variant name, when the service declares a matrix.
+	// The package of the variant selected by the environment is published.
+	Variants map[string]*ServicePackageResult `json:"variants,omitempty"`
 }
 
 // ServicePublishResult is the result of a successful Publish operation
@@ -98,9 +142,27 @@ type ServicePublishResult struct {
 type ServiceDeployResult struct {
 	Restore *ServiceRestoreResult `json:"restore"`
 	Build   *ServiceBuildResult   `json:"build"`
+	// The result of the tests of the service, when the tests run before the service is deployed
+	Test    *ServiceTestResult    `json:"test,omitempty"`
 	Package *ServicePackageResult `json:"package"`
 	Publish *ServicePublishResult `json:"publish"`
 	Details interface{}           `json:"details"`
+	// The provenance attestation of the deployed artifact, when enabled for the service
+	Provenance *ProvenanceAttestation `json:"provenance,omitempty"`
+	// The signature of the deployed image, when signing is enabled for the service
+	Signature *ImageSignature `json:"signature,omitempty"`
+}
+
+// ServiceDeployOptions are the hooks of a deployment by ServiceManager.Deploy, which split the packaging of the service
+// from its publishing, ex) so the services of a project are all packaged before any of them is published
+type ServiceDeployOptions struct {
+	// Invoked once the service is packaged, while its container image is pushed in the background. Blocks until the
+	// service can be published, ex) until its turn in the deploy strategy. An error fails the deployment before the
+	// service is published.
+	Packaged func(ctx context.Context, result *ServiceDeployResult) error
+	// Invoked once the service is published, before the postdeploy event, ex) to attest the deployed artifact.
+	// An error fails the deployment.
+	Published func(ctx context.Context, result *ServiceDeployResult) error
 }
 
 // ServiceManager provides a management layer for performing operations against an azd service within a project
@@ -131,6 +193,12 @@ type ServiceManager interface {
 		restoreOutput *ServiceRestoreResult,
 	) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress]
 
+	// Runs the tests of the code for the specified service config, unless the service opts out of tests
+	Test(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+	) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress]
+
 	// Packages the code for the specified service config
 	// Depending on the service configuration this will generate an artifact
 	// that can be consumed by the hosting Azure service.
@@ -153,10 +221,11 @@ type ServiceManager interface {
 	) *async.TaskWithProgress[*ServicePublishResult, ServiceProgress]
 
 	// Deploy is a composite command that will perform the following operations in sequence.
-	// Restore, build, package & publish
+	// Restore, build, package & publish, invoking the hooks of the options between packaging and publishing
 	Deploy(
 		ctx context.Context,
 		serviceConfig *ServiceConfig,
+		options ServiceDeployOptions,
 	) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress]
 
 	// Gets the framework service for the specified service config
@@ -167,12 +236,21 @@ type ServiceManager interface {
 	// The service target is responsible for packaging & publishing the service app code
 	// to the destination Azure resource
 	GetServiceTarget(ctx context.Context, serviceConfig *ServiceConfig) (ServiceTarget, error)
+
+	// Restarts the resource hosting the service, for the service targets implementing ServiceOperator
+	Restart(ctx context.Context, serviceConfig *ServiceConfig) error
+
+	// Scales the resource hosting the service to a number of replicas, for the service targets implementing
+	// ServiceOperator
+	Scale(ctx context.Context, serviceConfig *ServiceConfig, options ScaleOptions) error
 }
 
 type serviceManager struct {
 	env             *environment.Environment
 	resourceManager ResourceManager
 	serviceLocator  ioc.ServiceLocator
+	imagePusher     *ImagePushCoordinator
+	buildCache      *buildCache
 }
 
 // NewServiceManager creates a new instance of the ServiceManager component
@@ -180,11 +258,14 @@ func NewServiceManager(
 	env *environment.Environment,
 	resourceManager ResourceManager,
 	serviceLocator ioc.ServiceLocator,
+	imagePusher *ImagePushCoordinator,
 ) ServiceManager {
 	return &serviceManager{
 		env:             env,
 		resourceManager: resourceManager,
 		serviceLocator:  serviceLocator,
+		imagePusher:     imagePusher,
+		buildCache:      newBuildCache(env),
 	}
 }
 
@@ -204,6 +285,38 @@ func (sm *serviceManager) GetRequiredTools(ctx context.Context, serviceConfig *S
 	requiredTools = append(requiredTools, frameworkService.RequiredExternalTools(ctx)...)
 	requiredTools = append(requiredTools, serviceTarget.RequiredExternalTools(ctx)...)
 
+	// Images built with buildpacks require the pack CLI, in addition to docker
+	if usesBuildpacks(serviceConfig) {
+		var packCli pack.PackCli
+		if err := sm.serviceLocator.Resolve(&packCli); err != nil {
+			return nil, fmt.Errorf("resolving pack CLI: %w", err)
+		}
+
+		requiredTools = append(requiredTools, packCli)
+	}
+
+	// Dockerfiles are linted with hadolint before the image is built
+	if serviceConfig.Docker.Lint.Enabled {
+		var hadolintCli hadolint.HadolintCli
+		if err := sm.serviceLocator.Resolve(&hadolintCli); err != nil {
+			return nil, fmt.Errorf("resolving hadolint CLI: %w", err)
+		}
+
+		requiredTools = append(requiredTools, hadolintCli)
+	}
+
+	// Images built remotely with ACR Tasks don't require docker
+	if usesRemoteBuild(serviceConfig) {
+		localTools := []tools.ExternalTool{}
+		for _, tool := range requiredTools {
+			if _, isDocker := tool.(docker.Docker); !isDocker {
+				localTools = append(localTools, tool)
+			}
+		}
+
+		requiredTools = localTools
+	}
+
 	return tools.Unique(requiredTools), nil
 }
 
@@ -233,6 +346,50 @@ func (sm *serviceManager) Initialize(ctx context.Context, serviceConfig *Service
 		return err
 	}
 
+	if serviceConfig.PowerPlatform.Solution != "" {
+		var solution *PowerPlatformSolution
+		if err := sm.serviceLocator.Resolve(&solution); err != nil {
+			return fmt.Errorf("resolving power platform solution: %w", err)
+		}
+
+		if err := solution.Initialize(ctx, serviceConfig); err != nil {
+			return err
+		}
+	}
+
+	if len(serviceConfig.OnDeployed) > 0 {
+		var notifier *DeployNotifier
+		if err := sm.serviceLocator.Resolve(&notifier); err != nil {
+			return fmt.Errorf("resolving deploy notifier: %w", err)
+		}
+
+		if err := notifier.Initialize(ctx, serviceConfig); err != nil {
+			return err
+		}
+	}
+
+	if serviceConfig.CustomDomains.Wait {
+		var verifier *CustomDomainVerifier
+		if err := sm.serviceLocator.Resolve(&verifier); err != nil {
+			return fmt.Errorf("resolving custom domain verifier: %w", err)
+		}
+
+		if err := verifier.Initialize(ctx, serviceConfig); err != nil {
+			return err
+		}
+	}
+
+	if len(serviceConfig.Schedules) > 0 {
+		var scheduler *ServiceScheduler
+		if err := sm.serviceLocator.Resolve(&scheduler); err != nil {
+			return fmt.Errorf("resolving service scheduler: %w", err)
+		}
+
+		if err := scheduler.Initialize(ctx, serviceConfig); err != nil {
+			return err
+		}
+	}
+
 	serviceConfig.initialized = true
 
 	return nil
@@ -250,13 +407,20 @@ func (sm *serviceManager) Restore(
 			return
 		}
 
+		restoreCtx, cleanup, err := sm.withPackageFeeds(ctx, serviceConfig)
+		if err != nil {
+			task.SetError(err)
+			return
+		}
+		defer cleanup()
+
 		restoreResult, err := runCommand(
 			ctx,
 			task,
 			ServiceEventRestore,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServiceRestoreResult, ServiceProgress] {
-				return frameworkService.Restore(ctx, serviceConfig)
+				return frameworkService.Restore(restoreCtx, serviceConfig)
 			},
 		)
 
@@ -289,7 +453,11 @@ func (sm *serviceManager) Build(
 			ServiceEventBuild,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
-				return frameworkService.Build(ctx, serviceConfig, restoreOutput)
+				if len(serviceConfig.Matrix) > 0 {
+					return sm.buildVariants(ctx, frameworkService, serviceConfig, restoreOutput)
+				}
+
+				return sm.buildWithCache(ctx, frameworkService, serviceConfig, restoreOutput)
 			},
 		)
 
@@ -302,6 +470,41 @@ func (sm *serviceManager) Build(
 	})
 }
 
+// Runs the tests of the code for the specified service config, unless the service opts out of tests
+func (sm *serviceManager) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	if serviceConfig.Test.Skip {
+		return skipTests(serviceConfig, "the service opts out of tests")
+	}
+
+	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+		frameworkService, err := sm.GetFrameworkService(ctx, serviceConfig)
+		if err != nil {
+			task.SetError(fmt.Errorf("getting framework services: %w", err))
+			return
+		}
+
+		testResult, err := runCommand(
+			ctx,
+			task,
+			ServiceEventTest,
+			serviceConfig,
+			func() *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+				return frameworkService.Test(ctx, serviceConfig)
+			},
+		)
+
+		if err != nil {
+			task.SetError(fmt.Errorf("failed testing service '%s': %w", serviceConfig.Name, err))
+			return
+		}
+
+		task.SetResult(testResult)
+	})
+}
+
 // Packages the code for the specified service config
 // Depending on the service configuration this will generate an artifact that can be consumed by the hosting Azure service.
 // Common examples could be a zip archive for app service or docker images for container apps and AKS
@@ -328,31 +531,79 @@ func (sm *serviceManager) Package(
 			Service: serviceConfig,
 		}
 
-		err = serviceConfig.Invoke(ctx, ServiceEventPackage, eventArgs, func() error {
+		packageService := func(
+			serviceConfig *ServiceConfig,
+			buildOutput *ServiceBuildResult,
+		) (*ServicePackageResult, error) {
 			frameworkPackageTask := frameworkService.Package(ctx, serviceConfig, buildOutput)
 			syncProgress(task, frameworkPackageTask.Progress())
 
 			frameworkPackageResult, err := frameworkPackageTask.Await()
 			if err != nil {
-				return err
+				return nil, err
+			}
+
+			if serviceConfig.SecretScan.Enabled {
+				task.SetProgress(NewServiceProgress("Scanning for secrets"))
+				if err := scanForSecrets(serviceConfig, frameworkPackageResult); err != nil {
+					return nil, err
+				}
 			}
 
 			serviceTargetPackageTask := serviceTarget.Package(ctx, serviceConfig, frameworkPackageResult)
 			syncProgress(task, serviceTargetPackageTask.Progress())
 
-			serviceTargetPackageResult, err := serviceTargetPackageTask.Await()
-			if err != nil {
-				return err
-			}
+			return serviceTargetPackageTask.Await()
+		}
 
-			task.SetResult(serviceTargetPackageResult)
-			return nil
-		})
+		var packageResult *ServicePackageResult
+		err = serviceConfig.InvokeWithResult(
+			ctx,
+			ServiceEventPackage,
+			eventArgs,
+			func(eventArgs ServiceLifecycleEventArgs) (ServiceLifecycleEventArgs, error) {
+				if len(serviceConfig.Matrix) == 0 {
+					packageResult, err = packageService(serviceConfig, buildOutput)
+					if err != nil {
+						return eventArgs, err
+					}
+
+					eventArgs.Package = packageResult
+					return eventArgs, nil
+				}
+
+				packageResult = &ServicePackageResult{
+					Build:    buildOutput,
+					Variants: map[string]*ServicePackageResult{},
+				}
+
+				for _, variant := range serviceConfig.Variants() {
+					var variantBuildOutput *ServiceBuildResult
+					if buildOutput != nil {
+						variantBuildOutput = buildOutput.Variants[variant]
+					}
+
+					task.SetProgress(NewServiceProgress(fmt.Sprintf("Packaging variant %s", variant)))
+					variantPackageResult, err := packageService(serviceConfig.variantConfig(variant), variantBuildOutput)
+					if err != nil {
+						return eventArgs, fmt.Errorf("variant '%s': %w", variant, err)
+					}
+
+					packageResult.Variants[variant] = variantPackageResult
+				}
+
+				eventArgs.Package = packageResult
+				return eventArgs, nil
+			},
+		)
 
+		// The result is set once the postpackage event succeeds, since a task can't have both a result and an error
 		if err != nil {
 			task.SetError(fmt.Errorf("failed packaging service '%s': %w", serviceConfig.Name, err))
 			return
 		}
+
+		task.SetResult(packageResult)
 	})
 }
 
@@ -377,13 +628,33 @@ func (sm *serviceManager) Publish(
 			return
 		}
 
+		// Services built in variants publish the package of the variant deployed to the environment
+		publishConfig := serviceConfig
+		if len(serviceConfig.Matrix) > 0 {
+			variant, err := serviceConfig.DeployedVariant(sm.env.GetEnvName())
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			variantPackageResult, has := packageResult.Variants[variant]
+			if !has {
+				task.SetError(fmt.Errorf("variant '%s' of service '%s' wasn't packaged", variant, serviceConfig.Name))
+				return
+			}
+
+			log.Printf("publishing variant '%s' of service '%s'\n", variant, serviceConfig.Name)
+			publishConfig = serviceConfig.variantConfig(variant)
+			packageResult = variantPackageResult
+		}
+
 		publishResult, err := runCommand(
 			ctx,
 			task,
 			ServiceEventPublish,
 			serviceConfig,
 			func() *async.TaskWithProgress[*ServicePublishResult, ServiceProgress] {
-				return serviceTarget.Publish(ctx, serviceConfig, packageResult, targetResource)
+				return serviceTarget.Publish(ctx, publishConfig, packageResult, targetResource)
 			},
 		)
 
@@ -404,10 +675,11 @@ func (sm *serviceManager) Publish(
 }
 
 // Deploy is a composite command that will perform the following operations in sequence.
-// Restore, build, package & publish
+// Restore, test when the tests run before the deployment, build, package & publish
 func (sm *serviceManager) Deploy(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
+	options ServiceDeployOptions,
 ) *async.TaskWithProgress[*ServiceDeployResult, ServiceProgress] {
 	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress]) {
 		var result *ServiceDeployResult
@@ -417,44 +689,62 @@ func (sm *serviceManager) Deploy(
 			Service: serviceConfig,
 		}
 
-		err := serviceConfig.Invoke(ctx, ServiceEventDeploy, serviceEventArgs, func() error {
-			restoreTask := sm.Restore(ctx, serviceConfig)
-			go syncProgress(task, restoreTask.Progress())
-			restoreResult, err := restoreTask.Await()
-			if err != nil {
-				return err
-			}
-
-			buildTask := sm.Build(ctx, serviceConfig, restoreResult)
-			go syncProgress(task, buildTask.Progress())
-			buildResult, err := buildTask.Await()
-			if err != nil {
-				return err
-			}
-
-			packageTask := sm.Package(ctx, serviceConfig, buildResult)
-			go syncProgress(task, packageTask.Progress())
-			packageResult, err := packageTask.Await()
-			if err != nil {
-				return err
-			}
-
-			publishTask := sm.Publish(ctx, serviceConfig, packageResult)
-			go syncProgress(task, publishTask.Progress())
-			publishResult, err := publishTask.Await()
-			if err != nil {
-				return err
-			}
-
-			result = &ServiceDeployResult{
-				Restore: restoreResult,
-				Build:   buildResult,
-				Package: packageResult,
-				Publish: publishResult,
-			}
-
-			return nil
-		})
+		err := serviceConfig.InvokeWithResult(
+			ctx,
+			ServiceEventDeploy,
+			serviceEventArgs,
+			func(eventArgs ServiceLifecycleEventArgs) (ServiceLifecycleEventArgs, error) {
+				restoreTask := sm.Restore(ctx, serviceConfig)
+				go syncProgress(task, restoreTask.Progress())
+				restoreResult, err := restoreTask.Await()
+				if err != nil {
+					return eventArgs, err
+				}
+
+				var testResult *ServiceTestResult
+				if serviceConfig.Test.BeforeDeploy {
+					testTask := sm.Test(ctx, serviceConfig)
+					go syncProgress(task, testTask.Progress())
+					testResult, err = testTask.Await()
+					if err != nil {
+						return eventArgs, err
+					}
+				}
+
+				buildTask := sm.Build(ctx, serviceConfig, restoreResult)
+				go syncProgress(task, buildTask.Progress())
+				buildResult, err := buildTask.Await()
+				if err != nil {
+					return eventArgs, err
+				}
+
+				packageTask := sm.Package(ctx, serviceConfig, buildResult)
+				go syncProgress(task, packageTask.Progress())
+				packageResult, err := packageTask.Await()
+				if err != nil {
+					return eventArgs, err
+				}
+
+				result = &ServiceDeployResult{
+					Restore: restoreResult,
+					Build:   buildResult,
+					Test:    testResult,
+					Package: packageResult,
+				}
+
+				// The image is pushed with the subscription of the target resource the service is published to. The push
+				// is awaited before the deployment completes, and canceled when the deployment fails.
+				waitPush := sm.imagePusher.start(ctx, sm.env.GetSubscriptionId(), serviceConfig, packageResult)
+				err = sm.publishPackaged(ctx, task, serviceConfig, result, options)
+				waitPush(err != nil)
+				if err != nil {
+					return eventArgs, err
+				}
+
+				eventArgs.Package = result.Package
+				eventArgs.Publish = result.Publish
+				return eventArgs, nil
+			})
 
 		if err != nil {
 			task.SetError(fmt.Errorf("failed deploying service '%s': %w", serviceConfig.Name, err))
@@ -466,6 +756,96 @@ func (sm *serviceManager) Deploy(
 	})
 }
 
+// Publishes the packaged service once the Packaged hook allows it, then invokes the Published hook
+func (sm *serviceManager) publishPackaged(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServiceDeployResult, ServiceProgress],
+	serviceConfig *ServiceConfig,
+	result *ServiceDeployResult,
+	options ServiceDeployOptions,
+) error {
+	if options.Packaged != nil {
+		if err := options.Packaged(ctx, result); err != nil {
+			return err
+		}
+	}
+
+	publishTask := sm.Publish(ctx, serviceConfig, result.Package)
+	go syncProgress(task, publishTask.Progress())
+	publishResult, err := publishTask.Await()
+	if err != nil {
+		return err
+	}
+
+	// Services built in variants deploy the package of the variant selected by the environment
+	if len(result.Package.Variants) > 0 && publishResult.Package != nil {
+		result.Package = publishResult.Package
+	}
+
+	result.Publish = publishResult
+	result.Signature = result.Package.ImageSignature()
+
+	if options.Published != nil {
+		return options.Published(ctx, result)
+	}
+
+	return nil
+}
+
+// Restarts the resource hosting the service
+func (sm *serviceManager) Restart(ctx context.Context, serviceConfig *ServiceConfig) error {
+	operator, targetResource, err := sm.getServiceOperator(ctx, serviceConfig, "restarted")
+	if err != nil {
+		return err
+	}
+
+	if err := operator.Restart(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("failed restarting service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return nil
+}
+
+// Scales the resource hosting the service to a number of replicas
+func (sm *serviceManager) Scale(ctx context.Context, serviceConfig *ServiceConfig, options ScaleOptions) error {
+	operator, targetResource, err := sm.getServiceOperator(ctx, serviceConfig, "scaled")
+	if err != nil {
+		return err
+	}
+
+	if err := operator.Scale(ctx, serviceConfig, targetResource, options); err != nil {
+		return fmt.Errorf("failed scaling service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return nil
+}
+
+// Gets the service target of the service operating on its resource, with the resource. Returns an error when the
+// resources of the host of the service can't be operated on, ex) static web apps, which can't be restarted.
+func (sm *serviceManager) getServiceOperator(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	operation string,
+) (ServiceOperator, *environment.TargetResource, error) {
+	serviceTarget, err := sm.GetServiceTarget(ctx, serviceConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting service target: %w", err)
+	}
+
+	operator, ok := serviceTarget.(ServiceOperator)
+	if !ok {
+		return nil, nil, fmt.Errorf(
+			"service '%s' can't be %s, services hosted on '%s' aren't supported", serviceConfig.Name, operation, serviceConfig.Host)
+	}
+
+	targetResource, err := sm.resourceManager.GetTargetResource(ctx, sm.env.GetSubscriptionId(), serviceConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting target resource: %w", err)
+	}
+
+	return operator, targetResource, nil
+}
+
 // GetServiceTarget constructs a ServiceTarget from the underlying service configuration
 func (sm *serviceManager) GetServiceTarget(ctx context.Context, serviceConfig *ServiceConfig) (ServiceTarget, error) {
 	var target ServiceTarget
@@ -486,7 +866,30 @@ func (sm *serviceManager) GetServiceTarget(ctx context.Context, serviceConfig *S
 func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig *ServiceConfig) (FrameworkService, error) {
 	var frameworkService FrameworkService
 
-	if err := sm.serviceLocator.ResolveNamed(string(serviceConfig.Language), &frameworkService); err != nil {
+	language := serviceConfig.Language
+	// Logic Apps without a language are plain workflow projects that don't require a build
+	if serviceConfig.Host == LogicAppTarget && language == "" {
+		language = ServiceLanguageWorkflow
+	}
+
+	// Java services built with Gradle use the Gradle framework service
+	if language == ServiceLanguageJava && serviceConfig.JavaBuildTool() == JavaBuildToolGradle {
+		language = ServiceLanguageGradle
+	}
+
+	// Python services with a conda environment file use the conda framework service
+	if language == ServiceLanguagePython && serviceConfig.CondaFile() != "" {
+		language = ServiceLanguageConda
+	}
+
+	if pluginPath, has := sm.frameworkPlugin(language); has {
+		var commandRunner exec.CommandRunner
+		if err := sm.serviceLocator.Resolve(&commandRunner); err != nil {
+			return nil, fmt.Errorf("resolving command runner of framework plugin: %w", err)
+		}
+
+		frameworkService = NewPluginProject(pluginPath, commandRunner, sm.env)
+	} else if err := sm.serviceLocator.ResolveNamed(string(language), &frameworkService); err != nil {
 		panic(fmt.Errorf(
 			"failed to resolve language '%s' for service '%s', %w",
 			serviceConfig.Language,
@@ -495,8 +898,9 @@ func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig
 		))
 	}
 
-	// For containerized applications we use a composite framework service
-	if serviceConfig.Host == ContainerAppTarget || serviceConfig.Host == AksTarget {
+	// For containerized applications we use a composite framework service.
+	// Compose services are built by the compose framework service, which is already a composite framework service.
+	if serviceConfig.Host.RequiresContainer() && language != ServiceLanguageCompose {
 		var compositeFramework CompositeFrameworkService
 		if err := sm.serviceLocator.ResolveNamed(string(ServiceLanguageDocker), &compositeFramework); err != nil {
 			panic(fmt.Errorf(
@@ -515,6 +919,15 @@ func (sm *serviceManager) GetFrameworkService(ctx context.Context, serviceConfig
 	return frameworkService, nil
 }
 
+// Finds the framework plugin handling the services of the language, when azd doesn't support the language
+func (sm *serviceManager) frameworkPlugin(language ServiceLanguageKind) (string, bool) {
+	if isBuiltinServiceLanguage(language) {
+		return "", false
+	}
+
+	return FindFrameworkPlugin(language)
+}
+
 func (sm *serviceManager) getOverriddenEndpoints(ctx context.Context, serviceConfig *ServiceConfig) []string {
 	overriddenEndpoints := sm.env.GetServiceProperty(serviceConfig.Name, "ENDPOINTS")
 	if overriddenEndpoints != "" {
@@ -535,6 +948,72 @@ func (sm *serviceManager) getOverriddenEndpoints(ctx context.Context, serviceCon
 	return nil
 }
 
+// Builds the service, unless the inputs of its build haven't changed since its last build, in which case the result of
+// its last build is reused
+func (sm *serviceManager) buildWithCache(
+	ctx context.Context,
+	frameworkService FrameworkService,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+		cachedBuild, digest := sm.buildCache.lookup(ctx, frameworkService, serviceConfig, restoreOutput)
+		if cachedBuild != nil {
+			log.Printf("skipping build of service %s, its inputs haven't changed since its last build", serviceConfig.Name)
+			task.SetProgress(NewServiceProgress("Reusing build of unchanged sources"))
+			task.SetResult(cachedBuild)
+			return
+		}
+
+		buildTask := frameworkService.Build(ctx, serviceConfig, restoreOutput)
+		syncProgress(task, buildTask.Progress())
+
+		buildResult, err := buildTask.Await()
+		if err != nil {
+			task.SetError(err)
+			return
+		}
+
+		if err := sm.buildCache.record(serviceConfig, digest, buildResult); err != nil {
+			log.Printf("failed recording build of service %s: %v", serviceConfig.Name, err)
+		}
+
+		task.SetResult(buildResult)
+	})
+}
+
+// Builds each variant of the matrix of the service, in the order of their names
+func (sm *serviceManager) buildVariants(
+	ctx context.Context,
+	frameworkService FrameworkService,
+	serviceConfig *ServiceConfig,
+	restoreOutput *ServiceRestoreResult,
+) *async.TaskWithProgress[*ServiceBuildResult, ServiceProgress] {
+	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServiceBuildResult, ServiceProgress]) {
+		buildResult := &ServiceBuildResult{
+			Restore:  restoreOutput,
+			Variants: map[string]*ServiceBuildResult{},
+		}
+
+		for _, variant := range serviceConfig.Variants() {
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Building variant %s", variant)))
+
+			variantBuildTask := frameworkService.Build(ctx, serviceConfig.variantConfig(variant), restoreOutput)
+			syncProgress(task, variantBuildTask.Progress())
+
+			variantBuildResult, err := variantBuildTask.Await()
+			if err != nil {
+				task.SetError(fmt.Errorf("variant '%s': %w", variant, err))
+				return
+			}
+
+			buildResult.Variants[variant] = variantBuildResult
+		}
+
+		task.SetResult(buildResult)
+	})
+}
+
 func runCommand[T comparable, P comparable](
 	ctx context.Context,
 	task *async.TaskContextWithProgress[T, P],
@@ -549,18 +1028,32 @@ func runCommand[T comparable, P comparable](
 
 	var result T
 
-	err := serviceConfig.Invoke(ctx, eventName, eventArgs, func() error {
-		serviceTask := taskFunc()
-		go syncProgress(task, serviceTask.Progress())
-
-		taskResult, err := serviceTask.Await()
-		if err != nil {
-			return err
-		}
+	err := serviceConfig.InvokeWithResult(
+		ctx,
+		eventName,
+		eventArgs,
+		func(eventArgs ServiceLifecycleEventArgs) (ServiceLifecycleEventArgs, error) {
+			serviceTask := taskFunc()
+
+			// The progress of the service task is forwarded until its channel is closed, which happens once the task
+			// completes, and must be forwarded before the task of the command completes and closes its own channel
+			progressSynced := make(chan struct{})
+			go func() {
+				defer close(progressSynced)
+				syncProgress(task, serviceTask.Progress())
+			}()
+
+			taskResult, err := serviceTask.Await()
+			<-progressSynced
+			if err != nil {
+				return eventArgs, err
+			}
 
-		result = taskResult
-		return nil
-	})
+			result = taskResult
+			eventArgs.setResult(taskResult)
+			return eventArgs, nil
+		},
+	)
 
 	if err != nil {
 		return result, err
@@ -569,6 +1062,22 @@ func runCommand[T comparable, P comparable](
 	return result, nil
 }
 
+// Sets the typed result of the operation of the event
+func (args *ServiceLifecycleEventArgs) setResult(result any) {
+	switch result := result.(type) {
+	case *ServiceRestoreResult:
+		args.Restore = result
+	case *ServiceBuildResult:
+		args.Build = result
+	case *ServiceTestResult:
+		args.Test = result
+	case *ServicePackageResult:
+		args.Package = result
+	case *ServicePublishResult:
+		args.Publish = result
+	}
+}
+
 func syncProgress[T comparable, P comparable](task *async.TaskContextWithProgress[T, P], progressChannel <-chan P) {
 	for progress := range progressChannel {
 		task.SetProgress(progress)
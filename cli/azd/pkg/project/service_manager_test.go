@@ -2,6 +2,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -27,6 +28,7 @@ const (
 
 	frameworkRestoreCalled     contextKey = "frameworkRestoreCalled"
 	frameworkBuildCalled       contextKey = "frameworkBuildCalled"
+	frameworkTestCalled        contextKey = "frameworkTestCalled"
 	frameworkPackageCalled     contextKey = "frameworkPackageCalled"
 	serviceTargetPackageCalled contextKey = "serviceTargetPackageCalled"
 	serviceTargetPublishCalled contextKey = "serviceTargetPublishCalled"
@@ -37,7 +39,9 @@ func createServiceManager(mockContext *mocks.MockContext, env *environment.Envir
 	resourceManager := NewResourceManager(env, azCli)
 	serviceLocator := ioc.NewServiceLocator(mockContext.Container)
 
-	return NewServiceManager(env, resourceManager, serviceLocator)
+	imagePusher := NewImagePushCoordinator(env, nil, azCli, nil, nil, nil, nil, nil, nil, nil)
+
+	return NewServiceManager(env, resourceManager, serviceLocator, imagePusher)
 }
 
 func Test_GetRequiredTools(t *testing.T) {
@@ -113,8 +117,10 @@ func Test_Build(t *testing.T) {
 		return nil
 	})
 
+	var postBuildResult *ServiceBuildResult
 	_ = serviceConfig.AddHandler("postbuild", func(ctx context.Context, args ServiceLifecycleEventArgs) error {
 		raisedPostBuildEvent = true
+		postBuildResult = args.Build
 		return nil
 	})
 
@@ -130,6 +136,53 @@ func Test_Build(t *testing.T) {
 	require.True(t, *buildCalled)
 	require.True(t, raisedPreBuildEvent)
 	require.True(t, raisedPostBuildEvent)
+	require.Same(t, result, postBuildResult)
+}
+
+func Test_Test(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.Ephemeral()
+	sm := createServiceManager(mockContext, env)
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+
+	raisedPreTestEvent := false
+	var postTestResult *ServiceTestResult
+
+	_ = serviceConfig.AddHandler("pretest", func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		raisedPreTestEvent = true
+		return nil
+	})
+
+	_ = serviceConfig.AddHandler("posttest", func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		postTestResult = args.Test
+		return nil
+	})
+
+	testCalled := convert.RefOf(false)
+	ctx := context.WithValue(*mockContext.Context, frameworkTestCalled, testCalled)
+
+	testTask := sm.Test(ctx, serviceConfig)
+	logProgress(testTask)
+
+	result, err := testTask.Await()
+	require.NoError(t, err)
+	require.False(t, result.Skipped)
+	require.True(t, *testCalled)
+	require.True(t, raisedPreTestEvent)
+	require.Same(t, result, postTestResult)
+
+	// Services opting out of tests aren't tested
+	*testCalled = false
+	serviceConfig.Test.Skip = true
+
+	testTask = sm.Test(ctx, serviceConfig)
+	logProgress(testTask)
+
+	result, err = testTask.Await()
+	require.NoError(t, err)
+	require.True(t, result.Skipped)
+	require.False(t, *testCalled)
 }
 
 func Test_Package(t *testing.T) {
@@ -238,12 +291,28 @@ func Test_Deploy(t *testing.T) {
 	ctx = context.WithValue(ctx, serviceTargetPackageCalled, fakeServiceTargetPackageCalled)
 	ctx = context.WithValue(ctx, serviceTargetPublishCalled, publishCalled)
 
-	deployTask := sm.Deploy(ctx, serviceConfig)
+	hooks := []string{}
+	deployTask := sm.Deploy(ctx, serviceConfig, ServiceDeployOptions{
+		Packaged: func(ctx context.Context, result *ServiceDeployResult) error {
+			require.NotNil(t, result.Package)
+			require.False(t, *publishCalled)
+			hooks = append(hooks, "packaged")
+			return nil
+		},
+		Published: func(ctx context.Context, result *ServiceDeployResult) error {
+			require.NotNil(t, result.Publish)
+			require.False(t, raisedPostDeployEvent)
+			hooks = append(hooks, "published")
+			return nil
+		},
+	})
 	logProgress(deployTask)
 
 	result, err := deployTask.Await()
 	require.NoError(t, err)
 	require.NotNil(t, result)
+	require.Equal(t, []string{"packaged", "published"}, hooks)
+	require.True(t, *publishCalled)
 	require.True(t, *restoreCalled)
 	require.True(t, *buildCalled)
 	require.True(t, *fakeFrameworkPackageCalled)
@@ -252,6 +321,36 @@ func Test_Deploy(t *testing.T) {
 	require.True(t, raisedPostDeployEvent)
 }
 
+func Test_DeployService_PackagedHookFails(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.Ephemeral()
+	sm := createServiceManager(mockContext, env)
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+
+	raisedPostDeployEvent := false
+	_ = serviceConfig.AddHandler("postdeploy", func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		raisedPostDeployEvent = true
+		return nil
+	})
+
+	publishCalled := convert.RefOf(false)
+	ctx := context.WithValue(*mockContext.Context, serviceTargetPublishCalled, publishCalled)
+
+	deployTask := sm.Deploy(ctx, serviceConfig, ServiceDeployOptions{
+		Packaged: func(ctx context.Context, result *ServiceDeployResult) error {
+			return errors.New("another service failed")
+		},
+	})
+	logProgress(deployTask)
+
+	_, err := deployTask.Await()
+	require.ErrorContains(t, err, "failed deploying service 'api'")
+	require.ErrorContains(t, err, "another service failed")
+	require.False(t, *publishCalled)
+	require.False(t, raisedPostDeployEvent)
+}
+
 func Test_GetFrameworkService(t *testing.T) {
 	mockContext := mocks.NewMockContext(context.Background())
 	setupMocksForServiceManager(mockContext)
@@ -278,6 +377,20 @@ func Test_GetServiceTarget(t *testing.T) {
 	require.IsType(t, new(fakeServiceTarget), serviceTarget)
 }
 
+func Test_Restart_NotSupported(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.Ephemeral()
+	sm := createServiceManager(mockContext, env)
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+
+	err := sm.Restart(*mockContext.Context, serviceConfig)
+	require.ErrorContains(t, err, "can't be restarted, services hosted on 'fake-service-target' aren't supported")
+
+	err = sm.Scale(*mockContext.Context, serviceConfig, ScaleOptions{Replicas: 2})
+	require.ErrorContains(t, err, "can't be scaled, services hosted on 'fake-service-target' aren't supported")
+}
+
 func setupMocksForServiceManager(mockContext *mocks.MockContext) {
 	_ = mockContext.Container.RegisterNamedSingleton(string(ServiceLanguageFake), newFakeFramework)
 	_ = mockContext.Container.RegisterNamedSingleton(string(ServiceTargetFake), newFakeServiceTarget)
@@ -294,6 +407,12 @@ func setupMocksForServiceManager(mockContext *mocks.MockContext) {
 		return exec.NewRunResult(0, "", ""), nil
 	})
 
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "fake-framework test")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
 	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
 		return strings.Contains(command, "fake-framework package")
 	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
@@ -405,6 +524,29 @@ func (f *fakeFramework) Build(
 	})
 }
 
+func (f *fakeFramework) Test(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+) *async.TaskWithProgress[*ServiceTestResult, ServiceProgress] {
+	testCalled, ok := ctx.Value(frameworkTestCalled).(*bool)
+	if ok {
+		*testCalled = true
+	}
+
+	return async.RunTaskWithProgress(func(task *async.TaskContextWithProgress[*ServiceTestResult, ServiceProgress]) {
+		runArgs := exec.NewRunArgs("fake-framework", "test")
+		result, err := f.commandRunner.Run(ctx, runArgs)
+		if err != nil {
+			task.SetError(err)
+			return
+		}
+
+		task.SetResult(&ServiceTestResult{
+			Details: result,
+		})
+	})
+}
+
 func (f *fakeFramework) Package(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
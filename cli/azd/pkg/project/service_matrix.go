@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ServiceVariantOptions are the settings of a variant of a service built by the matrix of the service, ex) a debug and
+// a release image. Each variant is built and packaged from the settings of the service, with the settings of the
+// variant applied, and environments select the variant deployed to them.
+type ServiceVariantOptions struct {
+	// The docker build arguments of the variant, merged with the build arguments of the service,
+	// ex) CONFIGURATION: Debug
+	BuildArgs map[string]ExpandableString `yaml:"buildArgs"`
+	// The Dockerfile stage built for the variant, ex) debug. Defaults to the target of the service.
+	Target string `yaml:"target"`
+	// The path to the Dockerfile of the variant, relative to the service. Defaults to the Dockerfile of the service.
+	Path string `yaml:"path"`
+	// The values exposed to the builds of the variant in addition to the environment values, ex) NODE_ENV: development
+	Env map[string]string `yaml:"env"`
+}
+
+// Variants gets the names of the variants built by the matrix of the service, sorted by name.
+// Returns an empty slice when the service doesn't declare a matrix.
+func (sc *ServiceConfig) Variants() []string {
+	variants := maps.Keys(sc.Matrix)
+	slices.Sort(variants)
+	return variants
+}
+
+// Variant gets the name of the variant of the service, when the configuration is the configuration of a variant
+func (sc *ServiceConfig) Variant() string {
+	return sc.variant
+}
+
+// DeployedVariant gets the name of the variant of the service deployed to the environment. The variant is selected
+// with the variant of the environment, or is the only variant of the service.
+func (sc *ServiceConfig) DeployedVariant(envName string) (string, error) {
+	if overrides, has := sc.Environments[envName]; has && overrides.Variant != "" {
+		if _, has := sc.Matrix[overrides.Variant]; !has {
+			return "", fmt.Errorf(
+				"environment '%s' deploys variant '%s' of service '%s', which isn't in the matrix of the service",
+				envName,
+				overrides.Variant,
+				sc.Name,
+			)
+		}
+
+		return overrides.Variant, nil
+	}
+
+	variants := sc.Variants()
+	if len(variants) == 1 {
+		return variants[0], nil
+	}
+
+	return "", fmt.Errorf(
+		"service '%s' is built in variants '%s', set 'environments.%s.variant' in azure.yaml to select the variant "+
+			"deployed to environment '%s'",
+		sc.Name,
+		strings.Join(variants, "', '"),
+		envName,
+		envName,
+	)
+}
+
+// Creates the configuration of the variant of the service, the configuration of the service with the settings of the
+// variant applied. Hooks and event handlers are shared with the service.
+func (sc *ServiceConfig) variantConfig(name string) *ServiceConfig {
+	variant := sc.Matrix[name]
+	config := *sc
+	config.variant = name
+	config.variantEnv = variant.Env
+
+	if len(variant.BuildArgs) > 0 {
+		config.Docker.BuildArgs = map[string]ExpandableString{}
+		for key, value := range sc.Docker.BuildArgs {
+			config.Docker.BuildArgs[key] = value
+		}
+		for key, value := range variant.BuildArgs {
+			config.Docker.BuildArgs[key] = value
+		}
+	}
+
+	if variant.Target != "" {
+		config.Docker.Target = variant.Target
+	}
+
+	if variant.Path != "" {
+		config.Docker.Path = variant.Path
+	}
+
+	return &config
+}
+
+// Appends the name of the variant to the tag of the image, so the images of the variants don't overwrite each other,
+// ex) api:1.0 -> api:1.0-debug. Images without a tag are tagged with the name of the variant, ex) api -> api:debug.
+func variantImageTag(image string, variant string) string {
+	if variant == "" {
+		return image
+	}
+
+	// A colon before the last slash separates the port of the registry, ex) localhost:5000/api
+	if strings.LastIndex(image, ":") > strings.LastIndex(image, "/") {
+		return fmt.Sprintf("%s-%s", image, variant)
+	}
+
+	return fmt.Sprintf("%s:%s", image, variant)
+}
@@ -0,0 +1,136 @@
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceConfig_VariantConfig(t *testing.T) {
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+	serviceConfig.Docker = DockerProjectOptions{
+		Target:    "final",
+		BuildArgs: map[string]ExpandableString{"VERSION": NewExpandableString("1.0")},
+	}
+	serviceConfig.Matrix = map[string]ServiceVariantOptions{
+		"release": {},
+		"debug": {
+			Target:    "debug",
+			BuildArgs: map[string]ExpandableString{"CONFIGURATION": NewExpandableString("Debug")},
+			Env:       map[string]string{"NODE_ENV": "development"},
+		},
+	}
+
+	require.Equal(t, []string{"debug", "release"}, serviceConfig.Variants())
+
+	debug := serviceConfig.variantConfig("debug")
+	require.Equal(t, "debug", debug.Variant())
+	require.Equal(t, "debug", debug.Docker.Target)
+	require.Equal(t, map[string]ExpandableString{
+		"VERSION":       NewExpandableString("1.0"),
+		"CONFIGURATION": NewExpandableString("Debug"),
+	}, debug.Docker.BuildArgs)
+
+	values, err := debug.EnvValues(environment.EphemeralWithValues("dev", map[string]string{"KEY": "value"}))
+	require.NoError(t, err)
+	require.Equal(t, "development", values["NODE_ENV"])
+	require.Equal(t, "value", values["KEY"])
+
+	// Variants without settings use the settings of the service
+	release := serviceConfig.variantConfig("release")
+	require.Equal(t, "final", release.Docker.Target)
+
+	// The settings of the service aren't changed by its variants
+	require.Equal(t, "", serviceConfig.Variant())
+	require.Equal(t, "final", serviceConfig.Docker.Target)
+	require.Len(t, serviceConfig.Docker.BuildArgs, 1)
+}
+
+func Test_ServiceConfig_DeployedVariant(t *testing.T) {
+	serviceConfig := createTestServiceConfig("./src/api", ContainerAppTarget, ServiceLanguageDocker)
+	serviceConfig.Matrix = map[string]ServiceVariantOptions{"debug": {}, "release": {}}
+	serviceConfig.Environments = map[string]ServiceEnvironmentOptions{"prod": {Variant: "release"}}
+
+	variant, err := serviceConfig.DeployedVariant("prod")
+	require.NoError(t, err)
+	require.Equal(t, "release", variant)
+
+	_, err = serviceConfig.DeployedVariant("dev")
+	require.ErrorContains(t, err, "set 'environments.dev.variant'")
+
+	// The only variant of a service is deployed to all environments
+	serviceConfig.Matrix = map[string]ServiceVariantOptions{"release": {}}
+	variant, err = serviceConfig.DeployedVariant("dev")
+	require.NoError(t, err)
+	require.Equal(t, "release", variant)
+}
+
+func Test_VariantImageTag(t *testing.T) {
+	require.Equal(t, "api:1.0", variantImageTag("api:1.0", ""))
+	require.Equal(t, "api:1.0-debug", variantImageTag("api:1.0", "debug"))
+	require.Equal(t, "api:debug", variantImageTag("api", "debug"))
+	require.Equal(t, "localhost:5000/api:debug", variantImageTag("localhost:5000/api", "debug"))
+}
+
+func Test_ProjectConfig_UnknownEnvironmentVariant(t *testing.T) {
+	const testProj = `
+name: test-proj
+services:
+  api:
+    project: src/api
+    language: js
+    host: containerapp
+    docker:
+      path: ./Dockerfile
+    matrix:
+      debug:
+        target: debug
+    environments:
+      prod:
+        variant: release
+`
+
+	_, err := Parse(context.Background(), testProj)
+	require.ErrorContains(t, err, "variant 'release' isn't in the matrix of the service")
+}
+
+func Test_ServiceManager_Matrix(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForServiceManager(mockContext)
+	env := environment.EphemeralWithValues("test", map[string]string{
+		environment.SubscriptionIdEnvVarName: "SUBSCRIPTION_ID",
+	})
+	sm := createServiceManager(mockContext, env)
+	serviceConfig := createTestServiceConfig("./src/api", ServiceTargetFake, ServiceLanguageFake)
+	serviceConfig.Matrix = map[string]ServiceVariantOptions{"debug": {}, "release": {}}
+	serviceConfig.Environments = map[string]ServiceEnvironmentOptions{"test": {Variant: "release"}}
+
+	buildEvents := 0
+	_ = serviceConfig.AddHandler("postbuild", func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		buildEvents++
+		return nil
+	})
+
+	buildTask := sm.Build(*mockContext.Context, serviceConfig, nil)
+	logProgress(buildTask)
+	buildResult, err := buildTask.Await()
+	require.NoError(t, err)
+	require.Len(t, buildResult.Variants, 2)
+	require.Equal(t, 1, buildEvents)
+
+	packageTask := sm.Package(*mockContext.Context, serviceConfig, buildResult)
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+	require.Len(t, packageResult.Variants, 2)
+	require.Same(t, buildResult.Variants["debug"], packageResult.Variants["debug"].Build)
+
+	publishTask := sm.Publish(*mockContext.Context, serviceConfig, packageResult)
+	logProgress(publishTask)
+	publishResult, err := publishTask.Await()
+	require.NoError(t, err)
+	require.Same(t, packageResult.Variants["release"], publishResult.Package)
+}
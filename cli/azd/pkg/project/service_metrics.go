@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// ServiceMetricKind is the kind of a health metric of a service, read from the metrics of the resource of the service
+type ServiceMetricKind string
+
+const (
+	// The number of requests served during the window of the metrics
+	ServiceMetricRequests ServiceMetricKind = "requests"
+	// The number of requests that failed with a server error during the window of the metrics
+	ServiceMetricErrors ServiceMetricKind = "errors"
+	// The most recent CPU usage
+	ServiceMetricCpu ServiceMetricKind = "cpu"
+	// The most recent memory usage
+	ServiceMetricMemory ServiceMetricKind = "memory"
+	// The most recent number of replicas
+	ServiceMetricReplicas ServiceMetricKind = "replicas"
+)
+
+// The time range of the metrics of services, ending now
+const ServiceMetricsWindow = 15 * time.Minute
+
+// The interval of the values of the metrics read in the window
+const serviceMetricsInterval = time.Minute
+
+// ServiceMetric is the value of a health metric of a resource of a service
+type ServiceMetric struct {
+	Kind       ServiceMetricKind
+	ResourceId string
+	// The name of the metric of the resource in Azure Monitor, ex) Requests
+	Name  string
+	Value float64
+	// The unit of the value, ex) Count, Bytes or Percent
+	Unit string
+}
+
+// The metric of a resource type read for a kind of health metric
+type serviceMetricDefinition struct {
+	kind        ServiceMetricKind
+	name        string
+	aggregation string
+	filter      string
+}
+
+// The metrics read for each type of resource hosting services. Requests and errors are summed over the window, while
+// the CPU, memory and replicas are the most recent values.
+var serviceMetricDefinitions = map[infra.AzureResourceType][]serviceMetricDefinition{
+	infra.AzureResourceTypeWebSite: {
+		{kind: ServiceMetricRequests, name: "Requests", aggregation: "Total"},
+		{kind: ServiceMetricErrors, name: "Http5xx", aggregation: "Total"},
+		{kind: ServiceMetricCpu, name: "CpuTime", aggregation: "Total"},
+		{kind: ServiceMetricMemory, name: "MemoryWorkingSet", aggregation: "Average"},
+	},
+	infra.AzureResourceTypeContainerApp: {
+		{kind: ServiceMetricRequests, name: "Requests", aggregation: "Total"},
+		{kind: ServiceMetricErrors, name: "Requests", aggregation: "Total", filter: "statusCodeCategory eq '5xx'"},
+		{kind: ServiceMetricCpu, name: "UsageNanoCores", aggregation: "Average"},
+		{kind: ServiceMetricMemory, name: "WorkingSetBytes", aggregation: "Average"},
+		{kind: ServiceMetricReplicas, name: "Replicas", aggregation: "Maximum"},
+	},
+	infra.AzureResourceTypeStaticWebSite: {
+		{kind: ServiceMetricRequests, name: "SiteHits", aggregation: "Total"},
+		{kind: ServiceMetricErrors, name: "SiteErrors", aggregation: "Total"},
+	},
+	infra.AzureResourceTypeManagedCluster: {
+		{kind: ServiceMetricCpu, name: "node_cpu_usage_percentage", aggregation: "Average"},
+		{kind: ServiceMetricMemory, name: "node_memory_working_set_percentage", aggregation: "Average"},
+	},
+}
+
+// ServiceMetricsReader reads the recent health metrics of the resources of services from Azure Monitor
+type ServiceMetricsReader struct {
+	azCli azcli.AzCli
+}
+
+// Creates a new instance of the ServiceMetricsReader
+func NewServiceMetricsReader(azCli azcli.AzCli) *ServiceMetricsReader {
+	return &ServiceMetricsReader{
+		azCli: azCli,
+	}
+}
+
+// Reads the health metrics of the resources of a service over the window of the metrics. Metrics which can't be read,
+// ex) metrics not emitted yet by a new resource, are skipped. Resources of other types than the hosts of services
+// don't have metrics.
+func (r *ServiceMetricsReader) Read(
+	ctx context.Context,
+	subscriptionId string,
+	resources []azcli.AzCliResource,
+) []ServiceMetric {
+	metrics := []ServiceMetric{}
+	for _, resource := range resources {
+		for _, definition := range serviceMetricDefinitions[resourceType(resource.Type)] {
+			metric, err := r.azCli.GetResourceMetric(ctx, subscriptionId, resource.Id, azsdk.MetricsQuery{
+				Name:        definition.name,
+				Aggregation: definition.aggregation,
+				Filter:      definition.filter,
+				Timespan:    ServiceMetricsWindow,
+				Interval:    serviceMetricsInterval,
+			})
+			if err != nil {
+				log.Printf("skipping metric %s of resource %s: %v", definition.name, resource.Id, err)
+				continue
+			}
+
+			value, has := aggregateMetric(definition, metric)
+			if !has {
+				continue
+			}
+
+			metrics = append(metrics, ServiceMetric{
+				Kind:       definition.kind,
+				ResourceId: resource.Id,
+				Name:       definition.name,
+				Value:      value,
+				Unit:       metric.Unit,
+			})
+		}
+	}
+
+	return metrics
+}
+
+// Gets the case-insensitive resource type, ex) Microsoft.Web/sites for microsoft.web/sites
+func resourceType(value string) infra.AzureResourceType {
+	for resourceType := range serviceMetricDefinitions {
+		if strings.EqualFold(string(resourceType), value) {
+			return resourceType
+		}
+	}
+
+	return infra.AzureResourceType(value)
+}
+
+// Aggregates the values of the metric over the window. Totals are summed, other aggregations are the most recent value.
+// Returns false when the metric has no value in the window.
+func aggregateMetric(definition serviceMetricDefinition, metric *azsdk.Metric) (float64, bool) {
+	total := 0.0
+	var latest *float64
+	for _, series := range metric.Timeseries {
+		for _, data := range series.Data {
+			var value *float64
+			switch definition.aggregation {
+			case "Total":
+				value = data.Total
+			case "Maximum":
+				value = data.Maximum
+			default:
+				value = data.Average
+			}
+
+			if value == nil {
+				continue
+			}
+
+			total += *value
+			latest = value
+		}
+	}
+
+	if definition.aggregation == "Total" {
+		// Totals without values, ex) no requests in the window, are zero
+		return total, true
+	}
+
+	if latest == nil {
+		return 0, false
+	}
+
+	return *latest, true
+}
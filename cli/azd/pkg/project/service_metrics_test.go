@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServiceMetricsReader_Read(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	queries := []string{}
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasSuffix(request.URL.Path, "/providers/Microsoft.Insights/metrics")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		query := request.URL.Query()
+		name := query.Get("metricnames")
+		queries = append(queries, name+" "+query.Get("aggregation")+" "+query.Get("$filter"))
+
+		var data []map[string]any
+		unit := "Count"
+		switch {
+		case name == "Requests" && query.Get("$filter") != "":
+			// No errors in the window
+			data = []map[string]any{{"timeStamp": "2024-01-01T00:00:00Z"}}
+		case name == "Requests":
+			data = []map[string]any{
+				{"timeStamp": "2024-01-01T00:00:00Z", "total": 10.0},
+				{"timeStamp": "2024-01-01T00:01:00Z", "total": 5.0},
+			}
+		case name == "WorkingSetBytes":
+			unit = "Bytes"
+			data = []map[string]any{
+				{"timeStamp": "2024-01-01T00:00:00Z", "average": 100.0},
+				{"timeStamp": "2024-01-01T00:01:00Z", "average": 200.0},
+				{"timeStamp": "2024-01-01T00:02:00Z"},
+			}
+		case name == "Replicas":
+			return mocks.CreateEmptyHttpResponse(request, http.StatusBadRequest)
+		default:
+			// Metrics without values, ex) a new container app
+			data = []map[string]any{{"timeStamp": "2024-01-01T00:00:00Z"}}
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"value": []map[string]any{
+				{
+					"name":       map[string]any{"value": name},
+					"unit":       unit,
+					"timeseries": []map[string]any{{"data": data}},
+				},
+			},
+		})
+	})
+
+	reader := NewServiceMetricsReader(mockazcli.NewAzCliFromMockContext(mockContext))
+	resourceId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg/providers/Microsoft.App/containerApps/ca-api"
+	metrics := reader.Read(*mockContext.Context, "SUBSCRIPTION_ID", []azcli.AzCliResource{
+		{Id: resourceId, Name: "ca-api", Type: "microsoft.app/containerapps"},
+		// Resources of other types don't have metrics
+		{
+			Id:   "/subscriptions/SUBSCRIPTION_ID/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv",
+			Type: "Microsoft.KeyVault/vaults",
+		},
+	})
+
+	require.Equal(t, []string{
+		"Requests Total ",
+		"Requests Total statusCodeCategory eq '5xx'",
+		"UsageNanoCores Average ",
+		"WorkingSetBytes Average ",
+		"Replicas Maximum ",
+	}, queries)
+	require.Equal(t, []ServiceMetric{
+		{Kind: ServiceMetricRequests, ResourceId: resourceId, Name: "Requests", Value: 15, Unit: "Count"},
+		{Kind: ServiceMetricErrors, ResourceId: resourceId, Name: "Requests", Value: 0, Unit: "Count"},
+		{Kind: ServiceMetricMemory, ResourceId: resourceId, Name: "WorkingSetBytes", Value: 200, Unit: "Bytes"},
+	}, metrics)
+}
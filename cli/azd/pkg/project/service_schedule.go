@@ -0,0 +1,465 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/input"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"golang.org/x/exp/slices"
+)
+
+// ScheduleAuthKind is the way a scheduled invocation authenticates to the endpoint of the service
+type ScheduleAuthKind string
+
+const (
+	// The endpoint is invoked anonymously, or with the headers of the schedule, ex) an API key
+	ScheduleAuthNone ScheduleAuthKind = "none"
+	// The endpoint is invoked with a token of the managed identity of the workflow, for the audience of the schedule
+	ScheduleAuthManagedIdentity ScheduleAuthKind = "managedIdentity"
+)
+
+// The start time of the workflows of schedules with a time zone, in the past, since Logic Apps only apply the time zone
+// of a recurrence when the recurrence has a start time
+const scheduleStartTime = "2024-01-01T00:00:00"
+
+// The schema of the definitions of Logic App workflows
+const logicAppWorkflowSchema = "https://schema.management.azure.com/providers/Microsoft.Logic/schemas/2016-06-01/" +
+	"workflowdefinition.json#"
+
+// The names of the schedules, used in the names of their workflows
+var scheduleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*$`)
+
+// ServiceSchedule is an invocation of an endpoint of the service on a schedule, ex) a cleanup endpoint called every
+// night. Each schedule is materialized as a Logic App workflow with a recurrence trigger calling the deployed endpoint,
+// created in the resource group of the service after the service is deployed.
+type ServiceSchedule struct {
+	// The name of the schedule, unique for the service, ex) nightly-cleanup
+	Name string `yaml:"name"`
+	// The path of the endpoint invoked, ex) /jobs/cleanup
+	Path string `yaml:"path"`
+	// The cron expression of the schedule, ex) 0 3 * * * runs every day at 03:00
+	Schedule string `yaml:"schedule"`
+	// The HTTP method of the invocation. Defaults to POST.
+	Method string `yaml:"method"`
+	// The Windows time zone of the schedule, ex) Pacific Standard Time. Defaults to UTC.
+	TimeZone string `yaml:"timeZone"`
+	// The authentication of the invocation. Defaults to none.
+	Auth ScheduleAuthKind `yaml:"auth"`
+	// The audience of the tokens of managed identity authentication, ex) api://my-api
+	Audience ExpandableString `yaml:"audience"`
+	// The headers of the invocation, ex) X-Api-Key: ${CLEANUP_API_KEY}. The values are stored as secure parameters.
+	Headers map[string]ExpandableString `yaml:"headers"`
+}
+
+// logicAppRecurrence is the recurrence of a Logic App recurrence trigger
+type logicAppRecurrence struct {
+	Frequency string                    `json:"frequency"`
+	Interval  int                       `json:"interval"`
+	Schedule  *logicAppRecurrenceWindow `json:"schedule,omitempty"`
+	StartTime string                    `json:"startTime,omitempty"`
+	TimeZone  string                    `json:"timeZone,omitempty"`
+}
+
+type logicAppRecurrenceWindow struct {
+	Minutes   []int    `json:"minutes,omitempty"`
+	Hours     []int    `json:"hours,omitempty"`
+	WeekDays  []string `json:"weekDays,omitempty"`
+	MonthDays []int    `json:"monthDays,omitempty"`
+}
+
+var cronWeekDays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// Validates the schedules of the service
+func validateSchedules(serviceConfig *ServiceConfig) error {
+	names := map[string]struct{}{}
+
+	for i, schedule := range serviceConfig.Schedules {
+		if !scheduleNameRegex.MatchString(schedule.Name) {
+			return fmt.Errorf(
+				"schedule %d has an invalid name '%s', expected letters, digits and dashes", i+1, schedule.Name)
+		}
+
+		if _, has := names[schedule.Name]; has {
+			return fmt.Errorf("schedule '%s' is declared more than once", schedule.Name)
+		}
+		names[schedule.Name] = struct{}{}
+
+		if !strings.HasPrefix(schedule.Path, "/") {
+			return fmt.Errorf("invalid path '%s' of schedule '%s', the path must start with /", schedule.Path, schedule.Name)
+		}
+
+		if _, err := cronRecurrence(schedule.Schedule); err != nil {
+			return fmt.Errorf("invalid schedule '%s': %w", schedule.Name, err)
+		}
+
+		switch strings.ToUpper(schedule.Method) {
+		case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			return fmt.Errorf("invalid method '%s' of schedule '%s'", schedule.Method, schedule.Name)
+		}
+
+		switch schedule.Auth {
+		case "", ScheduleAuthNone:
+		case ScheduleAuthManagedIdentity:
+			if schedule.Audience.template == "" {
+				return fmt.Errorf("schedule '%s' with managedIdentity auth requires an audience", schedule.Name)
+			}
+		default:
+			return fmt.Errorf(
+				"invalid auth '%s' of schedule '%s', expected %s or %s",
+				schedule.Auth,
+				schedule.Name,
+				ScheduleAuthNone,
+				ScheduleAuthManagedIdentity,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Converts the cron expression to the recurrence of a Logic App. Logic Apps don't support cron expressions, so only the
+// expressions matching a recurrence are supported: every N minutes (*/15 * * * *), at minutes of every N hours
+// (0 */2 * * *), and at times of every day (30 3 * * *), of days of the week (0 9 * * 1-5) or of days of the month
+// (0 0 1 * *).
+func cronRecurrence(expression string) (*logicAppRecurrence, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression '%s', expected 5 fields", expression)
+	}
+
+	minute, hour, monthDay, month, weekDay := fields[0], fields[1], fields[2], fields[3], fields[4]
+	unsupported := fmt.Errorf(
+		"cron expression '%s' isn't supported, the month must be * and only one of the days of the month or the "+
+			"days of the week can be set",
+		expression,
+	)
+
+	if month != "*" || (monthDay != "*" && weekDay != "*") {
+		return nil, unsupported
+	}
+
+	// Every N minutes
+	if interval, ok, err := cronInterval(minute, 59); err != nil {
+		return nil, err
+	} else if ok {
+		if hour != "*" || monthDay != "*" || weekDay != "*" {
+			return nil, unsupported
+		}
+
+		return &logicAppRecurrence{Frequency: "Minute", Interval: interval}, nil
+	}
+
+	minutes, err := cronValues(minute, 0, 59)
+	if err != nil {
+		return nil, err
+	}
+
+	// At minutes of every N hours
+	if interval, ok, err := cronInterval(hour, 23); err != nil {
+		return nil, err
+	} else if ok {
+		if monthDay != "*" || weekDay != "*" {
+			return nil, unsupported
+		}
+
+		return &logicAppRecurrence{
+			Frequency: "Hour",
+			Interval:  interval,
+			Schedule:  &logicAppRecurrenceWindow{Minutes: minutes},
+		}, nil
+	}
+
+	hours, err := cronValues(hour, 0, 23)
+	if err != nil {
+		return nil, err
+	}
+
+	recurrence := &logicAppRecurrence{
+		Frequency: "Day",
+		Interval:  1,
+		Schedule:  &logicAppRecurrenceWindow{Minutes: minutes, Hours: hours},
+	}
+
+	switch {
+	case weekDay != "*":
+		days, err := cronValues(weekDay, 0, 7)
+		if err != nil {
+			return nil, err
+		}
+
+		recurrence.Frequency = "Week"
+		for _, day := range days {
+			// Both 0 and 7 are Sunday
+			name := cronWeekDays[day%7]
+			if !slices.Contains(recurrence.Schedule.WeekDays, name) {
+				recurrence.Schedule.WeekDays = append(recurrence.Schedule.WeekDays, name)
+			}
+		}
+	case monthDay != "*":
+		days, err := cronValues(monthDay, 1, 31)
+		if err != nil {
+			return nil, err
+		}
+
+		recurrence.Frequency = "Month"
+		recurrence.Schedule.MonthDays = days
+	}
+
+	return recurrence, nil
+}
+
+// Parses a cron field repeating every N units, ex) * or */5. Returns false when the field isn't an interval.
+func cronInterval(field string, max int) (int, bool, error) {
+	if field == "*" {
+		return 1, true, nil
+	}
+
+	step, isStep := strings.CutPrefix(field, "*/")
+	if !isStep {
+		return 0, false, nil
+	}
+
+	interval, err := strconv.Atoi(step)
+	if err != nil || interval < 1 || interval > max {
+		return 0, false, fmt.Errorf("invalid cron interval '%s'", field)
+	}
+
+	return interval, true, nil
+}
+
+// Parses a cron field listing values or ranges of values, ex) 1,15 or 1-5
+func cronValues(field string, min int, max int) ([]int, error) {
+	values := []int{}
+
+	for _, part := range strings.Split(field, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron value '%s'", part)
+		}
+
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return nil, fmt.Errorf("invalid cron value '%s'", part)
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("invalid cron value '%s', expected values from %d to %d", part, min, max)
+		}
+
+		for value := start; value <= end; value++ {
+			if !slices.Contains(values, value) {
+				values = append(values, value)
+			}
+		}
+	}
+
+	slices.Sort(values)
+	return values, nil
+}
+
+// ServiceScheduler is an optional deployment step that creates or updates the Logic App workflows of the schedules of a
+// service after the service is deployed, so the workflows invoke the endpoint of the latest deployment
+type ServiceScheduler struct {
+	env             *environment.Environment
+	azCli           azcli.AzCli
+	resourceManager ResourceManager
+	console         input.Console
+}
+
+// Creates a new instance of the service scheduler
+func NewServiceScheduler(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+	resourceManager ResourceManager,
+	console input.Console,
+) *ServiceScheduler {
+	return &ServiceScheduler{
+		env:             env,
+		azCli:           azCli,
+		resourceManager: resourceManager,
+		console:         console,
+	}
+}
+
+// Initializes the service scheduler for the service by subscribing to the postdeploy event
+func (s *ServiceScheduler) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	handler := func(ctx context.Context, args ServiceLifecycleEventArgs) error {
+		if args.Publish == nil || len(args.Publish.Endpoints) == 0 {
+			return fmt.Errorf("the schedules of service '%s' require an endpoint, the service doesn't have one",
+				serviceConfig.Name)
+		}
+
+		return s.Schedule(ctx, args.Service, args.Publish.Endpoints[0])
+	}
+
+	return serviceConfig.AddHandler(ext.Event("post"+ServiceEventDeploy), handler)
+}
+
+// Creates or updates the workflows of the schedules of the service, invoking the endpoint, in a single deployment to the
+// resource group of the service. The workflows of the schedules removed from the service aren't deleted.
+func (s *ServiceScheduler) Schedule(ctx context.Context, serviceConfig *ServiceConfig, endpoint string) error {
+	subscriptionId := s.env.GetSubscriptionId()
+	resourceGroup, err := s.resourceManager.GetResourceGroupName(ctx, subscriptionId, serviceConfig.Project)
+	if err != nil {
+		return fmt.Errorf("getting resource group of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	template, parameters, err := s.template(serviceConfig, endpoint)
+	if err != nil {
+		return err
+	}
+
+	deploymentName := fmt.Sprintf("azd-schedules-%s-%s", serviceConfig.Name, s.env.GetEnvName())
+	if len(deploymentName) > 64 {
+		deploymentName = deploymentName[:64]
+	}
+
+	stepMessage := fmt.Sprintf("Scheduling invocations of service %s", serviceConfig.Name)
+	s.console.ShowSpinner(ctx, stepMessage, input.Step)
+	log.Printf("deploying %d schedules of service %s to resource group %s",
+		len(serviceConfig.Schedules), serviceConfig.Name, resourceGroup)
+
+	_, err = s.azCli.DeployToResourceGroup(ctx, subscriptionId, resourceGroup, deploymentName, template, parameters)
+	s.console.StopSpinner(ctx, stepMessage, input.GetStepResultFormat(err))
+	if err != nil {
+		return fmt.Errorf("deploying schedules of service '%s': %w", serviceConfig.Name, err)
+	}
+
+	return nil
+}
+
+// Gets the ARM template of the workflows of the schedules of the service, and its parameters with the headers of the
+// schedules, which are secure parameters so their values aren't visible in the deployment
+func (s *ServiceScheduler) template(
+	serviceConfig *ServiceConfig,
+	endpoint string,
+) (azure.RawArmTemplate, azure.ArmParameters, error) {
+	templateParameters := map[string]any{}
+	parameters := azure.ArmParameters{}
+	resources := []any{}
+
+	for i, schedule := range serviceConfig.Schedules {
+		recurrence, err := cronRecurrence(schedule.Schedule)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid schedule '%s': %w", schedule.Name, err)
+		}
+
+		if schedule.TimeZone != "" {
+			recurrence.StartTime = scheduleStartTime
+			recurrence.TimeZone = schedule.TimeZone
+		}
+
+		headers := map[string]string{}
+		for name, value := range schedule.Headers {
+			if headers[name], err = value.Envsubst(s.env.Getenv); err != nil {
+				return nil, nil, fmt.Errorf("evaluating header '%s' of schedule '%s': %w", name, schedule.Name, err)
+			}
+		}
+
+		headersParameter := fmt.Sprintf("headers%d", i)
+		templateParameters[headersParameter] = map[string]any{"type": "secureObject"}
+		parameters[headersParameter] = azure.ArmParameterValue{Value: headers}
+
+		method := strings.ToUpper(schedule.Method)
+		if method == "" {
+			method = http.MethodPost
+		}
+
+		inputs := map[string]any{
+			"method":  method,
+			"uri":     strings.TrimSuffix(endpoint, "/") + schedule.Path,
+			"headers": "@parameters('headers')",
+		}
+
+		resource := map[string]any{
+			"type":       "Microsoft.Logic/workflows",
+			"apiVersion": "2019-05-01",
+			"name":       s.workflowName(serviceConfig, schedule),
+			"location":   s.env.GetLocation(),
+			"tags": map[string]string{
+				"azd-env-name":      s.env.GetEnvName(),
+				defaultServiceTag:   serviceConfig.Name,
+				"azd-schedule-name": schedule.Name,
+			},
+		}
+
+		if schedule.Auth == ScheduleAuthManagedIdentity {
+			audience, err := schedule.Audience.Envsubst(s.env.Getenv)
+			if err != nil {
+				return nil, nil, fmt.Errorf("evaluating audience of schedule '%s': %w", schedule.Name, err)
+			}
+
+			if audience == "" {
+				return nil, nil, errors.New("'audience' is required with managedIdentity auth")
+			}
+
+			resource["identity"] = map[string]any{"type": "SystemAssigned"}
+			inputs["authentication"] = map[string]any{"type": "ManagedServiceIdentity", "audience": audience}
+		}
+
+		resource["properties"] = map[string]any{
+			"state": "Enabled",
+			"definition": map[string]any{
+				"$schema":        logicAppWorkflowSchema,
+				"contentVersion": "1.0.0.0",
+				"parameters": map[string]any{
+					"headers": map[string]any{"type": "SecureObject", "defaultValue": map[string]any{}},
+				},
+				"triggers": map[string]any{
+					"schedule": map[string]any{"type": "Recurrence", "recurrence": recurrence},
+				},
+				"actions": map[string]any{
+					"invoke": map[string]any{"type": "Http", "inputs": inputs},
+				},
+				"outputs": map[string]any{},
+			},
+			"parameters": map[string]any{
+				"headers": map[string]any{"value": fmt.Sprintf("[parameters('%s')]", headersParameter)},
+			},
+		}
+
+		resources = append(resources, resource)
+	}
+
+	template, err := json.Marshal(map[string]any{
+		"$schema":        "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		"contentVersion": "1.0.0.0",
+		"parameters":     templateParameters,
+		"resources":      resources,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshalling schedules template: %w", err)
+	}
+
+	return template, parameters, nil
+}
+
+// Gets the name of the workflow of the schedule, unique for the environment
+func (s *ServiceScheduler) workflowName(serviceConfig *ServiceConfig, schedule ServiceSchedule) string {
+	name := fmt.Sprintf("%s-%s-%s", serviceConfig.Name, schedule.Name, s.env.GetEnvName())
+
+	// The names of workflows are limited to 80 characters
+	if len(name) > 80 {
+		name = name[:80]
+	}
+
+	return name
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CronRecurrence(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   *logicAppRecurrence
+	}{
+		{
+			expression: "*/15 * * * *",
+			expected:   &logicAppRecurrence{Frequency: "Minute", Interval: 15},
+		},
+		{
+			expression: "5 */2 * * *",
+			expected: &logicAppRecurrence{
+				Frequency: "Hour",
+				Interval:  2,
+				Schedule:  &logicAppRecurrenceWindow{Minutes: []int{5}},
+			},
+		},
+		{
+			expression: "30 3,15 * * *",
+			expected: &logicAppRecurrence{
+				Frequency: "Day",
+				Interval:  1,
+				Schedule:  &logicAppRecurrenceWindow{Minutes: []int{30}, Hours: []int{3, 15}},
+			},
+		},
+		{
+			expression: "0 9 * * 1-5",
+			expected: &logicAppRecurrence{
+				Frequency: "Week",
+				Interval:  1,
+				Schedule: &logicAppRecurrenceWindow{
+					Minutes:  []int{0},
+					Hours:    []int{9},
+					WeekDays: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"},
+				},
+			},
+		},
+		{
+			expression: "0 0 1 * *",
+			expected: &logicAppRecurrence{
+				Frequency: "Month",
+				Interval:  1,
+				Schedule:  &logicAppRecurrenceWindow{Minutes: []int{0}, Hours: []int{0}, MonthDays: []int{1}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			recurrence, err := cronRecurrence(tt.expression)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, recurrence)
+		})
+	}
+
+	for _, expression := range []string{"0 3 * *", "0 3 * 1 *", "0 3 1 * 1", "*/5 3 * * *", "60 * * * *", "0 24 * * *"} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := cronRecurrence(expression)
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_ValidateSchedules(t *testing.T) {
+	valid := ServiceSchedule{Name: "cleanup", Path: "/jobs/cleanup", Schedule: "0 3 * * *"}
+
+	tests := []struct {
+		name      string
+		modify    func(schedule *ServiceSchedule)
+		expectErr bool
+	}{
+		{name: "Valid", modify: func(schedule *ServiceSchedule) {}},
+		{name: "InvalidName", modify: func(schedule *ServiceSchedule) { schedule.Name = "clean up" }, expectErr: true},
+		{name: "RelativePath", modify: func(schedule *ServiceSchedule) { schedule.Path = "jobs" }, expectErr: true},
+		{name: "InvalidSchedule", modify: func(schedule *ServiceSchedule) { schedule.Schedule = "nightly" }, expectErr: true},
+		{name: "InvalidMethod", modify: func(schedule *ServiceSchedule) { schedule.Method = "TRACE" }, expectErr: true},
+		{
+			name:      "ManagedIdentityWithoutAudience",
+			modify:    func(schedule *ServiceSchedule) { schedule.Auth = ScheduleAuthManagedIdentity },
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := valid
+			tt.modify(&schedule)
+
+			serviceConfig := &ServiceConfig{Name: "api", Schedules: []ServiceSchedule{schedule}}
+			err := validateSchedules(serviceConfig)
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("DuplicateName", func(t *testing.T) {
+		serviceConfig := &ServiceConfig{Name: "api", Schedules: []ServiceSchedule{valid, valid}}
+		require.Error(t, validateSchedules(serviceConfig))
+	})
+}
+
+func Test_ServiceScheduler_Template(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("dev", map[string]string{
+		environment.LocationEnvVarName: "eastus2",
+		"CLEANUP_API_KEY":              "secret",
+	})
+
+	scheduler := NewServiceScheduler(env, nil, nil, mockContext.Console)
+	serviceConfig := &ServiceConfig{
+		Name: "api",
+		Schedules: []ServiceSchedule{
+			{
+				Name:     "cleanup",
+				Path:     "/jobs/cleanup",
+				Schedule: "0 3 * * *",
+				TimeZone: "Pacific Standard Time",
+				Headers:  map[string]ExpandableString{"X-Api-Key": NewExpandableString("${CLEANUP_API_KEY}")},
+			},
+			{
+				Name:     "report",
+				Path:     "/jobs/report",
+				Schedule: "0 9 * * 1",
+				Method:   "GET",
+				Auth:     ScheduleAuthManagedIdentity,
+				Audience: NewExpandableString("api://my-api"),
+			},
+		},
+	}
+
+	rawTemplate, parameters, err := scheduler.template(serviceConfig, "https://api.contoso.com/")
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]string{"X-Api-Key": "secret"}, parameters["headers0"].Value)
+	require.Equal(t, map[string]string{}, parameters["headers1"].Value)
+
+	var template struct {
+		Resources []struct {
+			Name     string            `json:"name"`
+			Location string            `json:"location"`
+			Identity map[string]any    `json:"identity"`
+			Tags     map[string]string `json:"tags"`
+			Props    struct {
+				Definition struct {
+					Triggers struct {
+						Schedule struct {
+							Recurrence logicAppRecurrence `json:"recurrence"`
+						} `json:"schedule"`
+					} `json:"triggers"`
+					Actions struct {
+						Invoke struct {
+							Inputs map[string]any `json:"inputs"`
+						} `json:"invoke"`
+					} `json:"actions"`
+				} `json:"definition"`
+			} `json:"properties"`
+		} `json:"resources"`
+	}
+	require.NoError(t, json.Unmarshal(rawTemplate, &template))
+	require.Len(t, template.Resources, 2)
+
+	cleanup := template.Resources[0]
+	require.Equal(t, "api-cleanup-dev", cleanup.Name)
+	require.Equal(t, "eastus2", cleanup.Location)
+	require.Equal(t, "cleanup", cleanup.Tags["azd-schedule-name"])
+	require.Nil(t, cleanup.Identity)
+	require.Equal(t, "Pacific Standard Time", cleanup.Props.Definition.Triggers.Schedule.Recurrence.TimeZone)
+	require.Equal(t, "POST", cleanup.Props.Definition.Actions.Invoke.Inputs["method"])
+	require.Equal(t, "https://api.contoso.com/jobs/cleanup", cleanup.Props.Definition.Actions.Invoke.Inputs["uri"])
+
+	report := template.Resources[1]
+	require.Equal(t, "SystemAssigned", report.Identity["type"])
+	require.Equal(t, "GET", report.Props.Definition.Actions.Invoke.Inputs["method"])
+	require.Equal(t,
+		map[string]any{"type": "ManagedServiceIdentity", "audience": "api://my-api"},
+		report.Props.Definition.Actions.Invoke.Inputs["authentication"],
+	)
+}
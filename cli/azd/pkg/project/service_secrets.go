@@ -0,0 +1,229 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/password"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// ServiceSecret is a secret declared by a service, ex) an internal API key of the service, shared with the services
+// consuming it. Secrets aren't environment values: they're only exposed to the builds, hooks and deployments of the
+// service declaring them and of the services they're shared with, even when the services aren't isolated.
+type ServiceSecret struct {
+	// The value of the secret, ex) ${API_KEY} to share an output of the infrastructure of the service. When empty, a
+	// random value is generated once and kept in the configuration of the environment.
+	Value ExpandableString `yaml:"value"`
+	// The names of the services the secret is shared with
+	SharedWith []string `yaml:"sharedWith"`
+}
+
+// The names of secrets are exposed to the services as environment variables
+var serviceSecretNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Guards the generation of the secrets of the services, which are exposed to services built concurrently
+var serviceSecretsMu sync.Mutex
+
+// Gets the name the secrets declared by the service are kept under, the name of the service declaring the instances for
+// instances, so that all the instances of a service share its secrets
+func (sc *ServiceConfig) secretOwner() string {
+	if sc.instanceOf != "" {
+		return sc.instanceOf
+	}
+
+	return sc.Name
+}
+
+// Checks whether the service is the service of the name, or one of its instances
+func (sc *ServiceConfig) isService(name string) bool {
+	return sc.Name == name || sc.instanceOf == name
+}
+
+// Checks whether the secret is shared with the service, or with the service declaring the instances of the service
+func (s ServiceSecret) isSharedWith(sc *ServiceConfig) bool {
+	for _, consumer := range s.SharedWith {
+		if sc.isService(consumer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SecretValues gets the values of the secrets exposed to the service, the secrets it declares and the secrets shared with
+// it by other services, keyed by the name of the secret. The generated secrets missing from the environment are generated
+// and saved to the environment.
+func (sc *ServiceConfig) SecretValues(env *environment.Environment) (map[string]string, error) {
+	if sc.Project == nil {
+		return map[string]string{}, nil
+	}
+
+	serviceSecretsMu.Lock()
+	defer serviceSecretsMu.Unlock()
+
+	// Sorted so collisions are always reported for the same pair of services
+	names := maps.Keys(sc.Project.Services)
+	slices.Sort(names)
+
+	values := map[string]string{}
+	owners := map[string]string{}
+	generated := false
+	for _, name := range names {
+		owner := sc.Project.Services[name]
+		if owner == nil || len(owner.Secrets) == 0 {
+			continue
+		}
+
+		declared := owner.secretOwner() == sc.secretOwner()
+		secretNames := maps.Keys(owner.Secrets)
+		slices.Sort(secretNames)
+		for _, secretName := range secretNames {
+			secret := owner.Secrets[secretName]
+			if !declared && !secret.isSharedWith(sc) {
+				continue
+			}
+
+			// Instances of the same service expose the same secrets
+			if existing, has := owners[secretName]; has {
+				if existing == owner.secretOwner() {
+					continue
+				}
+
+				return nil, fmt.Errorf(
+					"secrets '%s' of services '%s' and '%s' are both shared with service '%s'",
+					secretName,
+					existing,
+					owner.secretOwner(),
+					sc.Name,
+				)
+			}
+
+			value, isGenerated, err := owner.secretValue(env, secretName)
+			if err != nil {
+				return nil, err
+			}
+
+			owners[secretName] = owner.secretOwner()
+			values[secretName] = value
+			generated = generated || isGenerated
+		}
+	}
+
+	if generated {
+		if err := env.Save(); err != nil {
+			return nil, fmt.Errorf("saving generated secrets: %w", err)
+		}
+	}
+
+	return values, nil
+}
+
+// Gets the value of the secret declared by the service, generating it when the secret has no value and wasn't generated
+// yet. Returns whether the value was generated.
+func (sc *ServiceConfig) secretValue(env *environment.Environment, secretName string) (string, bool, error) {
+	secret := sc.Secrets[secretName]
+	if strings.TrimSpace(secret.Value.template) != "" {
+		value, err := secret.Value.Envsubst(env.Getenv)
+		if err != nil {
+			return "", false, fmt.Errorf("evaluating secret '%s' of service '%s': %w", secretName, sc.secretOwner(), err)
+		}
+
+		if value == "" {
+			// The value may not be available yet, ex) when packaging before the infrastructure is provisioned
+			log.Printf("secret '%s' of service '%s' is empty", secretName, sc.secretOwner())
+		}
+
+		return value, false, nil
+	}
+
+	if value, has := env.GetServiceSecret(sc.secretOwner(), secretName); has {
+		return value, false, nil
+	}
+
+	value, err := password.Generate(password.PasswordComposition{NumLowercase: 12, NumUppercase: 12, NumDigits: 8})
+	if err != nil {
+		return "", false, fmt.Errorf("generating secret '%s' of service '%s': %w", secretName, sc.secretOwner(), err)
+	}
+
+	if err := env.SetServiceSecret(sc.secretOwner(), secretName, value); err != nil {
+		return "", false, fmt.Errorf("saving secret '%s' of service '%s': %w", secretName, sc.secretOwner(), err)
+	}
+
+	log.Printf("generated secret '%s' of service '%s'", secretName, sc.secretOwner())
+	return value, true, nil
+}
+
+// SecretEnviron creates a slice of key value pairs like `KEY=VALUE` from the values of the secrets exposed to the service
+func (sc *ServiceConfig) SecretEnviron(env *environment.Environment) ([]string, error) {
+	values, err := sc.SecretValues(env)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars := make([]string, 0, len(values))
+	for name, value := range values {
+		envVars = append(envVars, fmt.Sprintf("%s=%s", name, value))
+	}
+
+	return envVars, nil
+}
+
+// Validates the secrets of the services of the project are shared with services of the project
+func validateServiceSecrets(projectConfig *ProjectConfig) error {
+	names := maps.Keys(projectConfig.Services)
+	slices.Sort(names)
+
+	isService := func(name string) bool {
+		for _, svc := range projectConfig.Services {
+			if svc != nil && svc.isService(name) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, name := range names {
+		svc := projectConfig.Services[name]
+		if svc == nil {
+			continue
+		}
+
+		for secretName, secret := range svc.Secrets {
+			if !serviceSecretNameRegexp.MatchString(secretName) {
+				return fmt.Errorf(
+					"parsing service %s: invalid secret name '%s', names must be valid environment variable names, "+
+						"ex) INTERNAL_API_KEY",
+					name,
+					secretName,
+				)
+			}
+
+			for _, consumer := range secret.SharedWith {
+				if svc.isService(consumer) {
+					return fmt.Errorf("parsing service %s: secret '%s' is shared with the service itself", name, secretName)
+				}
+
+				if !isService(consumer) {
+					return fmt.Errorf(
+						"parsing service %s: secret '%s' is shared with service '%s', which isn't in the project",
+						name,
+						secretName,
+						consumer,
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
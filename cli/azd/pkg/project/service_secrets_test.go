@@ -0,0 +1,98 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/stretchr/testify/require"
+)
+
+const serviceSecretsTestProject = `
+name: test-app
+services:
+  api:
+    project: src/api
+    host: containerapp
+    secrets:
+      INTERNAL_API_KEY:
+        sharedWith: [web]
+      STORAGE_KEY:
+        value: ${AZURE_STORAGE_KEY}
+  web:
+    project: src/web
+    host: appservice
+    env:
+      isolated: true
+  worker:
+    project: src/worker
+    host: containerapp
+`
+
+func Test_ServiceSecrets(t *testing.T) {
+	projectConfig, err := Parse(context.Background(), serviceSecretsTestProject)
+	require.NoError(t, err)
+
+	env := environment.EmptyWithRoot(t.TempDir())
+	env.Values["AZURE_STORAGE_KEY"] = "storage-key"
+
+	apiValues, err := projectConfig.Services["api"].EnvValues(env)
+	require.NoError(t, err)
+	require.Equal(t, "storage-key", apiValues["STORAGE_KEY"])
+	require.NotEmpty(t, apiValues["INTERNAL_API_KEY"])
+
+	// The generated secret is kept in the environment, and shared with the services consuming it
+	reloaded, err := environment.FromRoot(env.Root)
+	require.NoError(t, err)
+	secret, has := reloaded.GetServiceSecret("api", "INTERNAL_API_KEY")
+	require.True(t, has)
+	require.Equal(t, apiValues["INTERNAL_API_KEY"], secret)
+
+	webValues, err := projectConfig.Services["web"].EnvValues(reloaded)
+	require.NoError(t, err)
+	require.Equal(t, secret, webValues["INTERNAL_API_KEY"])
+	require.NotContains(t, webValues, "STORAGE_KEY")
+
+	// Secrets aren't exposed to the services they aren't shared with, even when the services aren't isolated
+	workerValues, err := projectConfig.Services["worker"].EnvValues(reloaded)
+	require.NoError(t, err)
+	require.NotContains(t, workerValues, "INTERNAL_API_KEY")
+	require.NotContains(t, workerValues, "STORAGE_KEY")
+	require.Equal(t, "storage-key", workerValues["AZURE_STORAGE_KEY"])
+}
+
+func Test_ServiceSecrets_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		secrets string
+		wantErr string
+	}{
+		{
+			name:    "UnknownService",
+			secrets: "API_KEY:\n        sharedWith: [admin]",
+			wantErr: "secret 'API_KEY' is shared with service 'admin', which isn't in the project",
+		},
+		{
+			name:    "Itself",
+			secrets: "API_KEY:\n        sharedWith: [api]",
+			wantErr: "secret 'API_KEY' is shared with the service itself",
+		},
+		{
+			name:    "InvalidName",
+			secrets: "api-key:\n        sharedWith: [web]",
+			wantErr: "invalid secret name 'api-key'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := "name: test-app\nservices:\n  api:\n    project: src/api\n    host: containerapp\n    secrets:\n      " +
+				tt.secrets + "\n  web:\n    project: src/web\n    host: appservice\n"
+			_, err := Parse(context.Background(), yaml)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -23,6 +23,10 @@ const (
 	AzureFunctionTarget ServiceTargetKind = "function"
 	StaticWebAppTarget  ServiceTargetKind = "staticwebapp"
 	AksTarget           ServiceTargetKind = "aks"
+	VmTarget            ServiceTargetKind = "vm"
+	BatchJobTarget      ServiceTargetKind = "batch-job"
+	IotEdgeTarget       ServiceTargetKind = "iotedge"
+	LogicAppTarget      ServiceTargetKind = "logicapp"
 )
 
 func parseServiceHost(kind ServiceTargetKind) (ServiceTargetKind, error) {
@@ -31,7 +35,11 @@ func parseServiceHost(kind ServiceTargetKind) (ServiceTargetKind, error) {
 		ContainerAppTarget,
 		AzureFunctionTarget,
 		StaticWebAppTarget,
-		AksTarget:
+		AksTarget,
+		VmTarget,
+		BatchJobTarget,
+		IotEdgeTarget,
+		LogicAppTarget:
 		return kind, nil
 	}
 
@@ -70,6 +78,30 @@ type ServiceTarget interface {
 	) ([]string, error)
 }
 
+// ServiceOperator is implemented by the service targets whose resources are operated on once the service is deployed,
+// ex) with azd restart and azd scale
+type ServiceOperator interface {
+	// Restarts the resource hosting the service
+	Restart(ctx context.Context, serviceConfig *ServiceConfig, targetResource *environment.TargetResource) error
+
+	// Scales the resource hosting the service to a number of replicas, ex) the instances of an app service plan
+	Scale(
+		ctx context.Context,
+		serviceConfig *ServiceConfig,
+		targetResource *environment.TargetResource,
+		options ScaleOptions,
+	) error
+}
+
+// ScaleOptions are the options of scaling the resource hosting a service
+type ScaleOptions struct {
+	// The number of replicas the resource is scaled to
+	Replicas int
+	// When set, resources shared with other apps are scaled as well, ex) an app service plan hosting other apps.
+	// Otherwise scaling a shared resource fails with an azcli.SharedAppServicePlanError.
+	Force bool
+}
+
 // NewServicePublishResult is a helper function to create a new ServicePublishResult
 func NewServicePublishResult(
 	relatedResourceId string,
@@ -118,6 +150,12 @@ func (st ServiceTargetKind) SupportsDelayedProvisioning() bool {
 	return st == ContainerAppTarget || st == AksTarget
 }
 
+// RequiresContainer returns true if the service target kind deploys the service as a container image, which is built
+// from the service with docker.
+func (st ServiceTargetKind) RequiresContainer() bool {
+	return st == ContainerAppTarget || st == AksTarget || st == BatchJobTarget || st == IotEdgeTarget
+}
+
 func checkResourceType(resource *environment.TargetResource, expectedResourceType infra.AzureResourceType) error {
 	if !strings.EqualFold(resource.ResourceType(), string(expectedResourceType)) {
 		return resourceTypeMismatchError(
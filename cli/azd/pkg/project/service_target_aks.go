@@ -2,6 +2,8 @@ package project
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -21,6 +23,9 @@ import (
 
 const (
 	defaultDeploymentPath = "manifests"
+	// The image pull secret with the credentials of a registry configured for the service, used by the default
+	// service account of the namespace
+	registryPullSecretName = "azd-registry"
 )
 
 // The AKS configuration options
@@ -54,27 +59,27 @@ type AksServiceOptions struct {
 }
 
 type aksTarget struct {
-	env                      *environment.Environment
-	managedClustersService   azcli.ManagedClustersService
-	containerRegistryService azcli.ContainerRegistryService
-	docker                   docker.Docker
-	kubectl                  kubectl.KubectlCli
+	env                    *environment.Environment
+	managedClustersService azcli.ManagedClustersService
+	imagePushCoordinator   *ImagePushCoordinator
+	docker                 docker.Docker
+	kubectl                kubectl.KubectlCli
 }
 
 // Creates a new instance of the AKS service target
 func NewAksTarget(
 	env *environment.Environment,
 	managedClustersService azcli.ManagedClustersService,
-	containerRegistryService azcli.ContainerRegistryService,
+	imagePushCoordinator *ImagePushCoordinator,
 	kubectlCli kubectl.KubectlCli,
 	docker docker.Docker,
 ) ServiceTarget {
 	return &aksTarget{
-		env:                      env,
-		managedClustersService:   managedClustersService,
-		containerRegistryService: containerRegistryService,
-		docker:                   docker,
-		kubectl:                  kubectlCli,
+		env:                    env,
+		managedClustersService: managedClustersService,
+		imagePushCoordinator:   imagePushCoordinator,
+		docker:                 docker,
+		kubectl:                kubectlCli,
 	}
 }
 
@@ -123,60 +128,15 @@ func (t *aksTarget) Publish(
 				return
 			}
 
-			// Login to AKS cluster
-			clusterName, has := t.env.Values[environment.AksClusterEnvVarName]
-			if !has {
-				task.SetError(fmt.Errorf(
-					"could not determine AKS cluster, ensure %s is set as an output of your infrastructure",
-					environment.AksClusterEnvVarName,
-				))
-				return
-			}
-
-			log.Printf("getting AKS credentials for cluster '%s'\n", clusterName)
-			task.SetProgress(NewServiceProgress("Getting AKS credentials"))
-			clusterCreds, err := t.managedClustersService.GetAdminCredentials(
-				ctx,
-				targetResource.SubscriptionId(),
-				targetResource.ResourceGroupName(),
-				clusterName,
-			)
-			if err != nil {
-				task.SetError(fmt.Errorf(
-					"failed retrieving cluster admin credentials. Ensure your cluster has been configured to support admin credentials, %w",
-					err,
-				))
-				return
-			}
-
-			if len(clusterCreds.Kubeconfigs) == 0 {
-				task.SetError(fmt.Errorf(
-					"cluster credentials is empty. Ensure your cluster has been configured to support admin credentials. , %w",
-					err,
-				))
-				return
-			}
-
 			packageDetails, ok := packageOutput.Details.(*dockerPackageResult)
 			if !ok {
 				task.SetError(errors.New("failed retrieving package result details"))
 				return
 			}
 
-			log.Printf("logging into container registry '%s'\n", packageDetails.LoginServer)
-
-			task.SetProgress(NewServiceProgress("Logging into container registry"))
-			err = t.containerRegistryService.LoginAcr(ctx, targetResource.SubscriptionId(), packageDetails.LoginServer)
-			if err != nil {
-				task.SetError(fmt.Errorf("failed logging into registry '%s': %w", packageDetails.LoginServer, err))
-				return
-			}
-
-			// The kubeConfig that we care about will also be at position 0
-			// I don't know if there is a valid use case where this credential results would container multiple configs
-			task.SetProgress(NewServiceProgress("Configuring k8s config context"))
-			err = t.configureK8sContext(ctx, clusterName, clusterCreds.Kubeconfigs[0])
-			if err != nil {
+			// Login to AKS cluster
+			task.SetProgress(NewServiceProgress("Getting AKS credentials"))
+			if err := t.loginToCluster(ctx, targetResource); err != nil {
 				task.SetError(err)
 				return
 			}
@@ -203,11 +163,17 @@ func (t *aksTarget) Publish(
 				return
 			}
 
+			envs, err := serviceConfig.Environ(t.env)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
 			task.SetProgress(NewServiceProgress("Creating k8s secrets"))
 			secretResult, err := t.kubectl.CreateSecretGenericFromLiterals(
 				ctx,
 				"azd",
-				t.env.Environ(),
+				envs,
 				&kubectl.KubeCliFlags{
 					Namespace: namespace,
 					DryRun:    kubectl.DryRunTypeClient,
@@ -225,26 +191,46 @@ func (t *aksTarget) Publish(
 				return
 			}
 
-			log.Printf("pushing %s to registry", packageOutput.PackagePath)
-
 			// Push image.
 			task.SetProgress(NewServiceProgress("Pushing image"))
-			if err := t.docker.Push(ctx, serviceConfig.Path(), packageDetails.ImageTag); err != nil {
+			err = t.imagePushCoordinator.Push(
+				ctx,
+				targetResource.SubscriptionId(),
+				serviceConfig,
+				packageDetails,
+				func(status string) { task.SetProgress(NewServiceProgress(status)) },
+			)
+			if err != nil {
 				task.SetError(fmt.Errorf("failed pushing image: %w", err))
 				return
 			}
 
+			imageReference, pullCreds, err := t.imagePushCoordinator.ImageReference(
+				ctx,
+				targetResource.SubscriptionId(),
+				packageDetails,
+			)
+			if err != nil {
+				task.SetError(fmt.Errorf("selecting registry endpoint: %w", err))
+				return
+			}
+
 			// Save the name of the image we pushed into the environment with a well known key.
 			log.Printf("writing image name to environment")
-			t.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", packageDetails.ImageTag)
+			t.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", imageReference)
 
 			if err := t.env.Save(); err != nil {
 				task.SetError(fmt.Errorf("saving image name to environment: %w", err))
 				return
 			}
 
+			if err := t.applyRegistryPullSecret(ctx, targetResource, packageDetails, pullCreds, namespace); err != nil {
+				task.SetError(err)
+				return
+			}
+
 			task.SetProgress(NewServiceProgress("Applying k8s manifests"))
-			t.kubectl.SetEnv(t.env.Values)
+			t.kubectl.SetEnv(t.env.Dotenv())
 			deploymentPath := serviceConfig.K8s.DeploymentPath
 			if deploymentPath == "" {
 				deploymentPath = defaultDeploymentPath
@@ -260,10 +246,7 @@ func (t *aksTarget) Publish(
 				return
 			}
 
-			deploymentName := serviceConfig.K8s.Deployment.Name
-			if deploymentName == "" {
-				deploymentName = serviceConfig.Name
-			}
+			deploymentName := getK8sDeploymentName(serviceConfig)
 
 			// It is not a requirement for a AZD deploy to contain a deployment object
 			// If we don't find any deployment within the namespace we will continue
@@ -356,6 +339,42 @@ func (t *aksTarget) validateTargetResource(
 	return nil
 }
 
+// Gets the admin credentials of the AKS cluster of the environment and sets the k8s context of kubectl to the cluster
+func (t *aksTarget) loginToCluster(ctx context.Context, targetResource *environment.TargetResource) error {
+	clusterName, has := t.env.LookupEnv(environment.AksClusterEnvVarName)
+	if !has {
+		return fmt.Errorf(
+			"could not determine AKS cluster, ensure %s is set as an output of your infrastructure",
+			environment.AksClusterEnvVarName,
+		)
+	}
+
+	log.Printf("getting AKS credentials for cluster '%s'\n", clusterName)
+	clusterCreds, err := t.managedClustersService.GetAdminCredentials(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		clusterName,
+	)
+	if err != nil {
+		return fmt.Errorf(
+			"failed retrieving cluster admin credentials. Ensure your cluster has been configured to support admin credentials, %w",
+			err,
+		)
+	}
+
+	if len(clusterCreds.Kubeconfigs) == 0 {
+		return fmt.Errorf(
+			"cluster credentials is empty. Ensure your cluster has been configured to support admin credentials. , %w",
+			err,
+		)
+	}
+
+	// The kubeConfig that we care about will also be at position 0
+	// I don't know if there is a valid use case where this credential results would container multiple configs
+	return t.configureK8sContext(ctx, clusterName, clusterCreds.Kubeconfigs[0])
+}
+
 func (t *aksTarget) configureK8sContext(
 	ctx context.Context,
 	clusterName string,
@@ -557,6 +576,63 @@ func (t *aksTarget) getIngressEndpoints(
 	return endpoints, nil
 }
 
+// Restarts the pods of the deployment of the service with a rollout, and waits for the rollout to complete
+func (t *aksTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	return t.rollout(ctx, serviceConfig, targetResource, "rollout", "restart")
+}
+
+// Scales the deployment of the service to a number of replicas, and waits for the rollout to complete
+func (t *aksTarget) Scale(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ScaleOptions,
+) error {
+	return t.rollout(ctx, serviceConfig, targetResource, "scale", fmt.Sprintf("--replicas=%d", options.Replicas))
+}
+
+// Runs the kubectl command on the deployment of the service, ex) kubectl rollout restart deployment/api, then waits for
+// the rollout of the deployment to complete
+func (t *aksTarget) rollout(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	args ...string,
+) error {
+	if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if err := t.loginToCluster(ctx, targetResource); err != nil {
+		return err
+	}
+
+	deploymentName := getK8sDeploymentName(serviceConfig)
+	flags := &kubectl.KubeCliFlags{Namespace: t.getK8sNamespace(serviceConfig)}
+	if _, err := t.kubectl.Exec(ctx, flags, append(args, "deployment/"+deploymentName)...); err != nil {
+		return fmt.Errorf("running kubectl %s on deployment '%s': %w", args[0], deploymentName, err)
+	}
+
+	if _, err := t.kubectl.RolloutStatus(ctx, deploymentName, flags); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Gets the name of the k8s deployment of the service, which defaults to the name of the service
+func getK8sDeploymentName(serviceConfig *ServiceConfig) string {
+	if serviceConfig.K8s.Deployment.Name != "" {
+		return serviceConfig.K8s.Deployment.Name
+	}
+
+	return serviceConfig.Name
+}
+
 func (t *aksTarget) getK8sNamespace(serviceConfig *ServiceConfig) string {
 	namespace := serviceConfig.K8s.Namespace
 	if namespace == "" {
@@ -565,3 +641,74 @@ func (t *aksTarget) getK8sNamespace(serviceConfig *ServiceConfig) string {
 
 	return namespace
 }
+
+// Applies the credentials of a registry configured for the service, or of the connected registry the image is pulled
+// from, as an image pull secret of the namespace, and adds the secret to the default service account, so the pods of the
+// namespace can pull the image. Images pulled from the container registry of the environment are pulled by the cluster
+// with its identity.
+func (t *aksTarget) applyRegistryPullSecret(
+	ctx context.Context,
+	targetResource *environment.TargetResource,
+	packageDetails *dockerPackageResult,
+	registryCreds *azcli.DockerCredentials,
+	namespace string,
+) error {
+	if registryCreds == nil {
+		var err error
+		registryCreds, err = t.imagePushCoordinator.PullCredentials(ctx, targetResource.SubscriptionId(), packageDetails)
+		if err != nil {
+			return err
+		}
+	}
+
+	if registryCreds == nil {
+		return nil
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte(registryCreds.Username + ":" + registryCreds.Password))
+	dockerConfig, err := json.Marshal(map[string]any{
+		"auths": map[string]any{
+			registryCreds.LoginServer: map[string]string{
+				"username": registryCreds.Username,
+				"password": registryCreds.Password,
+				"auth":     auth,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The secret is applied from stdin, the password is never part of the arguments of kubectl
+	secret, err := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/dockerconfigjson",
+		"metadata": map[string]string{
+			"name":      registryPullSecretName,
+			"namespace": namespace,
+		},
+		"data": map[string]string{
+			".dockerconfigjson": base64.StdEncoding.EncodeToString(dockerConfig),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := t.kubectl.ApplyWithInput(ctx, string(secret), nil); err != nil {
+		return fmt.Errorf("failed applying registry pull secret: %w", err)
+	}
+
+	patch := fmt.Sprintf(`{"imagePullSecrets":[{"name":"%s"}]}`, registryPullSecretName)
+	_, err = t.kubectl.Exec(
+		ctx,
+		&kubectl.KubeCliFlags{Namespace: namespace},
+		"patch", "serviceaccount", "default", "--patch", patch,
+	)
+	if err != nil {
+		return fmt.Errorf("failed adding registry pull secret to the default service account: %w", err)
+	}
+
+	return nil
+}
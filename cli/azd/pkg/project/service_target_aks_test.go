@@ -21,10 +21,17 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/infra"
 	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/kubectl"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/oras"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
 	"github.com/azure/azure-dev/cli/azd/test/ostest"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
@@ -175,6 +182,66 @@ func Test_Publish_No_Admin_Credentials(t *testing.T) {
 	require.Nil(t, publishResult)
 }
 
+func Test_Package_Publish_SimulatedResources(t *testing.T) {
+	tempDir := t.TempDir()
+	ostest.Chdir(t, tempDir)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	err := setupMocksForAksTarget(mockContext)
+	require.NoError(t, err)
+
+	// The container registry, its credentials and the cluster credentials are served by the ARM simulator
+	_, err = registerSimulatedTargetResources(mockContext)
+	require.NoError(t, err)
+
+	dockerLogins := []string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker login")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		dockerLogins = append(dockerLogins, strings.Join(args.Args, " "))
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	serviceConfig := createTestServiceConfig(tempDir, AksTarget, ServiceLanguageTypeScript)
+	env := createEnv()
+
+	serviceTarget := createServiceTarget(mockContext, serviceConfig, env)
+	err = setupK8sManifests(t, serviceConfig)
+	require.NoError(t, err)
+
+	packageTask := serviceTarget.Package(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{
+			PackagePath: "IMAGE_ID",
+			Details: &dockerPackageResult{
+				ImageTag:    "IMAGE_TAG",
+				LoginServer: "REGISTRY.azurecr.io",
+			},
+		},
+	)
+	logProgress(packageTask)
+	packageResult, err := packageTask.Await()
+	require.NoError(t, err)
+
+	scope := environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "RESOURCE_GROUP", "AKS_CLUSTER", string(infra.AzureResourceTypeManagedCluster),
+	)
+	publishTask := serviceTarget.Publish(*mockContext.Context, serviceConfig, packageResult, scope)
+	logProgress(publishTask)
+	publishResult, err := publishTask.Await()
+
+	require.NoError(t, err)
+	require.Equal(t, AksTarget, publishResult.Kind)
+	require.IsType(t, new(kubectl.Deployment), publishResult.Details)
+	require.Equal(t, "IMAGE_TAG", env.Values["SERVICE_API_IMAGE_NAME"])
+
+	// The registry is logged into with the admin credentials of the simulated registry
+	require.NotEmpty(t, dockerLogins)
+	require.Contains(t, dockerLogins[0], "REGISTRY.azurecr.io")
+	require.Contains(t, dockerLogins[0], "--username REGISTRY")
+}
+
 func setupK8sManifests(t *testing.T, serviceConfig *ServiceConfig) error {
 	manifestsDir := filepath.Join(serviceConfig.RelativePath, defaultDeploymentPath)
 	err := os.MkdirAll(manifestsDir, osutil.PermissionDirectory)
@@ -317,6 +384,13 @@ func setupMocksForAksTarget(mockContext *mocks.MockContext) error {
 		return exec.NewRunResult(0, "", ""), nil
 	})
 
+	// Get image layers
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "[]", ""), nil
+	})
+
 	// Push Container Image
 	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
 		return strings.Contains(command, "docker push")
@@ -485,7 +559,18 @@ func createServiceTarget(
 	return NewAksTarget(
 		env,
 		managedClustersService,
-		containerRegistryService,
+		NewImagePushCoordinator(
+			env,
+			containerRegistryService,
+			mockazcli.NewAzCliFromMockContext(mockContext),
+			dockerCli,
+			oras.NewOrasCli(mockContext.CommandRunner),
+			notation.NewNotationCli(mockContext.CommandRunner),
+			cosign.NewCosignCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+		),
 		kubeCtl,
 		dockerCli,
 	)
@@ -5,6 +5,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -17,6 +18,13 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
 )
 
+// The App Service deployment options
+type AppServiceOptions struct {
+	// When true only the files that changed since the previous deployment are uploaded.
+	// Falls back to a full zip deployment when the app builds during deployment or runs from package.
+	DeltaDeploy bool `yaml:"deltaDeploy"`
+}
+
 type appServiceTarget struct {
 	env *environment.Environment
 	cli azcli.AzCli
@@ -53,16 +61,18 @@ func (st *appServiceTarget) Package(
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
 			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
-			zipFilePath, err := createDeployableZip(serviceConfig.Name, packageOutput.PackagePath)
+			packageResult, err := createDeployableArchive(
+				serviceConfig,
+				packageOutput.PackagePath,
+				archiveFormats[AppServiceTarget]...,
+			)
 			if err != nil {
 				task.SetError(err)
 				return
 			}
 
-			task.SetResult(&ServicePackageResult{
-				Build:       packageOutput.Build,
-				PackagePath: zipFilePath,
-			})
+			packageResult.Build = packageOutput.Build
+			task.SetResult(packageResult)
 		},
 	)
 }
@@ -81,23 +91,22 @@ func (st *appServiceTarget) Publish(
 				return
 			}
 
-			zipFile, err := os.Open(packageOutput.PackagePath)
-			if err != nil {
-				task.SetError(fmt.Errorf("failed reading deployment zip file: %w", err))
-				return
-			}
-
 			defer os.Remove(packageOutput.PackagePath)
-			defer zipFile.Close()
-
-			task.SetProgress(NewServiceProgress("Publishing deployment package"))
-			res, err := st.cli.DeployAppServiceZip(
-				ctx,
-				targetResource.SubscriptionId(),
-				targetResource.ResourceGroupName(),
-				targetResource.ResourceName(),
-				zipFile,
-			)
+
+			var res *string
+			var err error
+			if serviceConfig.AppService.DeltaDeploy {
+				task.SetProgress(NewServiceProgress("Publishing changed files"))
+				res, err = st.cli.DeployAppServiceZipDelta(
+					ctx,
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceName(),
+					packageOutput.PackagePath,
+				)
+			} else {
+				res, err = st.publishZip(ctx, task, packageOutput, targetResource)
+			}
 			if err != nil {
 				task.SetError(fmt.Errorf("deploying service %s: %w", serviceConfig.Name, err))
 				return
@@ -127,6 +136,30 @@ func (st *appServiceTarget) Publish(
 	)
 }
 
+// Publishes the complete zip archive using zip deploy
+func (st *appServiceTarget) publishZip(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServicePublishResult, ServiceProgress],
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) (*string, error) {
+	zipFile, err := os.Open(packageOutput.PackagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading deployment zip file: %w", err)
+	}
+
+	defer zipFile.Close()
+
+	task.SetProgress(NewServiceProgress("Publishing deployment package"))
+	return st.cli.DeployAppServiceZip(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		zipFile,
+	)
+}
+
 // Gets the exposed endpoints for the App Service
 func (st *appServiceTarget) Endpoints(
 	ctx context.Context,
@@ -151,6 +184,45 @@ func (st *appServiceTarget) Endpoints(
 	return endpoints, nil
 }
 
+// Restarts the app service
+func (st *appServiceTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := st.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	return st.cli.RestartAppService(
+		ctx, targetResource.SubscriptionId(), targetResource.ResourceGroupName(), targetResource.ResourceName())
+}
+
+// Scales the app service plan of the app service to a number of instances
+func (st *appServiceTarget) Scale(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ScaleOptions,
+) error {
+	if err := st.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if options.Replicas < 1 {
+		return errors.New("app service plans are scaled to at least 1 instance")
+	}
+
+	return st.cli.ScaleAppService(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		options.Replicas,
+		options.Force,
+	)
+}
+
 func (st *appServiceTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
@@ -0,0 +1,432 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/benbjohnson/clock"
+)
+
+// The interval used when polling the state of a submitted batch task
+var batchTaskPollInterval = 10 * time.Second
+
+// The files written by a batch task that are streamed while the task runs
+var batchTaskOutputFiles = []string{"stdout.txt", "stderr.txt"}
+
+// The Azure Batch job configuration options
+type BatchOptions struct {
+	// The URL of the batch account, ex) https://{account}.{region}.batch.azure.com
+	// Defaults to the SERVICE_<NAME>_BATCH_ENDPOINT environment value, typically set as an infrastructure output
+	Endpoint ExpandableString `yaml:"endpoint"`
+	// The id of the pool that runs the job.
+	// Defaults to the SERVICE_<NAME>_BATCH_POOL_ID environment value
+	PoolId ExpandableString `yaml:"poolId"`
+	// The id of the job that tasks are submitted to. Defaults to the service name
+	JobId string `yaml:"jobId"`
+	// The optional path to a JSON task template, relative to the service path.
+	// The template is an Azure Batch task definition, the task id and container image are set by azd
+	TaskTemplate string `yaml:"taskTemplate"`
+	// The command line run by the task. Overrides the command line from the task template
+	CommandLine string `yaml:"commandLine"`
+	// The optional resource id of the user assigned identity used by the pool to pull the container image
+	RegistryIdentity ExpandableString `yaml:"registryIdentity"`
+}
+
+type batchJobTarget struct {
+	env                  *environment.Environment
+	imagePushCoordinator *ImagePushCoordinator
+	batchService         azcli.BatchService
+	docker               docker.Docker
+	clock                clock.Clock
+}
+
+// NewBatchJobTarget creates a new instance of the Azure Batch job service target.
+//
+// The service container image is pushed to the container registry and a task referencing the image
+// is submitted to the configured batch job.
+func NewBatchJobTarget(
+	env *environment.Environment,
+	imagePushCoordinator *ImagePushCoordinator,
+	batchService azcli.BatchService,
+	docker docker.Docker,
+	clock clock.Clock,
+) ServiceTarget {
+	return &batchJobTarget{
+		env:                  env,
+		imagePushCoordinator: imagePushCoordinator,
+		batchService:         batchService,
+		docker:               docker,
+		clock:                clock,
+	}
+}
+
+// Gets the required external tools for the batch job target
+func (t *batchJobTarget) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{t.docker}
+}
+
+// Initializes the batch job target
+func (t *batchJobTarget) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// The container image produced by the docker framework is used as-is
+func (t *batchJobTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			task.SetResult(packageOutput)
+		},
+	)
+}
+
+// Pushes the container image, submits the batch task and waits for the task to complete
+func (t *batchJobTarget) Publish(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) *async.TaskWithProgress[*ServicePublishResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePublishResult, ServiceProgress]) {
+			if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+				task.SetError(fmt.Errorf("validating target resource: %w", err))
+				return
+			}
+
+			packageDetails, ok := packageOutput.Details.(*dockerPackageResult)
+			if !ok {
+				task.SetError(errors.New("failed retrieving package result details"))
+				return
+			}
+
+			endpoint, err := t.serviceValue(serviceConfig, serviceConfig.Batch.Endpoint, "endpoint", "BATCH_ENDPOINT")
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			poolId, err := t.serviceValue(serviceConfig, serviceConfig.Batch.PoolId, "poolId", "BATCH_POOL_ID")
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			// Tasks pull images from registries configured for the service with the credentials of the registry
+			registryCreds, err := t.imagePushCoordinator.PullCredentials(
+				ctx,
+				targetResource.SubscriptionId(),
+				packageDetails,
+			)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			batchTask, err := t.createTask(serviceConfig, packageDetails, registryCreds)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Pushing image"))
+			err = t.imagePushCoordinator.Push(
+				ctx,
+				targetResource.SubscriptionId(),
+				serviceConfig,
+				packageDetails,
+				func(status string) { task.SetProgress(NewServiceProgress(status)) },
+			)
+			if err != nil {
+				task.SetError(fmt.Errorf("pushing image: %w", err))
+				return
+			}
+
+			t.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", packageDetails.ImageTag)
+			if err := t.env.Save(); err != nil {
+				task.SetError(fmt.Errorf("saving image name to environment: %w", err))
+				return
+			}
+
+			job := &azsdk.BatchJob{
+				Id:       t.jobId(serviceConfig),
+				PoolInfo: azsdk.BatchPoolInfo{PoolId: poolId},
+			}
+
+			task.SetProgress(NewServiceProgress("Submitting batch task"))
+			err = t.batchService.SubmitTask(ctx, targetResource.SubscriptionId(), endpoint, job, batchTask)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			completedTask, err := t.waitForTask(ctx, task, targetResource.SubscriptionId(), endpoint, job.Id, batchTask.Id)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			sdr := NewServicePublishResult(
+				azure.ResourceRID(
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceType(),
+					targetResource.ResourceName(),
+				),
+				BatchJobTarget,
+				fmt.Sprintf("Task '%s' completed in job '%s'", completedTask.Id, job.Id),
+				[]string{},
+			)
+			sdr.Package = packageOutput
+			sdr.Details = completedTask
+
+			task.SetResult(sdr)
+		},
+	)
+}
+
+// Batch jobs do not expose any endpoints
+func (t *batchJobTarget) Endpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]string, error) {
+	return []string{}, nil
+}
+
+func (t *batchJobTarget) validateTargetResource(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	return checkResourceType(targetResource, infra.AzureResourceTypeBatchAccount)
+}
+
+// Builds the batch task from the optional task template and the service configuration
+func (t *batchJobTarget) createTask(
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+	registryCreds *azcli.DockerCredentials,
+) (*azsdk.BatchTask, error) {
+	batchTask := &azsdk.BatchTask{}
+
+	if serviceConfig.Batch.TaskTemplate != "" {
+		templatePath := serviceConfig.Batch.TaskTemplate
+		if !filepath.IsAbs(templatePath) {
+			templatePath = filepath.Join(serviceConfig.Path(), templatePath)
+		}
+
+		templateBytes, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("reading batch task template: %w", err)
+		}
+
+		if err := json.Unmarshal(templateBytes, batchTask); err != nil {
+			return nil, fmt.Errorf("parsing batch task template '%s': %w", templatePath, err)
+		}
+	}
+
+	if serviceConfig.Batch.CommandLine != "" {
+		batchTask.CommandLine = serviceConfig.Batch.CommandLine
+	}
+
+	if strings.TrimSpace(batchTask.CommandLine) == "" {
+		return nil, fmt.Errorf(
+			"a command line is required for service '%s', set 'batch.commandLine' or 'batch.taskTemplate'",
+			serviceConfig.Name,
+		)
+	}
+
+	batchTask.Id = fmt.Sprintf("%s-%d", strings.ToLower(serviceConfig.Name), t.clock.Now().Unix())
+
+	if batchTask.ContainerSettings == nil {
+		batchTask.ContainerSettings = &azsdk.BatchContainerSettings{}
+	}
+	batchTask.ContainerSettings.ImageName = packageDetails.ImageTag
+
+	registryIdentity, err := serviceConfig.Batch.RegistryIdentity.Envsubst(t.env.Getenv)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating batch registry identity: %w", err)
+	}
+
+	switch {
+	case registryCreds != nil:
+		batchTask.ContainerSettings.Registry = &azsdk.BatchContainerRegistry{
+			RegistryServer: registryCreds.LoginServer,
+			Username:       registryCreds.Username,
+			Password:       registryCreds.Password,
+		}
+	case registryIdentity != "":
+		batchTask.ContainerSettings.Registry = &azsdk.BatchContainerRegistry{
+			RegistryServer:    packageDetails.LoginServer,
+			IdentityReference: &azsdk.BatchIdentityReference{ResourceId: registryIdentity},
+		}
+	}
+
+	return batchTask, nil
+}
+
+// Polls the state of the task until completion, reporting each state change and each line written
+// by the task to stdout & stderr as progress
+func (t *batchJobTarget) waitForTask(
+	ctx context.Context,
+	task *async.TaskContextWithProgress[*ServicePublishResult, ServiceProgress],
+	subscriptionId string,
+	endpoint string,
+	jobId string,
+	taskId string,
+) (*azsdk.BatchTask, error) {
+	var lastState azsdk.BatchTaskState
+	outputs := make([]*batchTaskOutput, len(batchTaskOutputFiles))
+	for i, fileName := range batchTaskOutputFiles {
+		outputs[i] = &batchTaskOutput{fileName: fileName}
+	}
+
+	for {
+		batchTask, err := t.batchService.GetTask(ctx, subscriptionId, endpoint, jobId, taskId)
+		if err != nil {
+			return nil, err
+		}
+
+		if batchTask.State != lastState {
+			lastState = batchTask.State
+			task.SetProgress(NewServiceProgress(fmt.Sprintf("Batch task '%s' is %s", taskId, batchTask.State)))
+		}
+
+		completed := batchTask.State == azsdk.BatchTaskStateCompleted
+
+		if completed || batchTask.State == azsdk.BatchTaskStateRunning {
+			for _, output := range outputs {
+				content, err := t.batchService.GetTaskOutput(
+					ctx, subscriptionId, endpoint, jobId, taskId, output.fileName, output.offset,
+				)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, line := range output.append(content, completed) {
+					task.SetProgress(NewServiceProgress(line))
+				}
+			}
+		}
+
+		if completed {
+			return batchTask, taskExecutionError(batchTask)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-t.clock.After(batchTaskPollInterval):
+		}
+	}
+}
+
+// Tracks the portion of a task output file that has already been reported
+type batchTaskOutput struct {
+	fileName string
+	offset   int64
+	// The trailing line that has not been terminated yet
+	pending string
+}
+
+// Appends the newly fetched content and returns the complete lines.
+// The trailing unterminated line is only returned once the task has completed.
+func (o *batchTaskOutput) append(content []byte, completed bool) []string {
+	o.offset += int64(len(content))
+	lines := strings.Split(o.pending+string(content), "\n")
+
+	o.pending = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+
+	if completed && o.pending != "" {
+		lines = append(lines, o.pending)
+		o.pending = ""
+	}
+
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			result = append(result, line)
+		}
+	}
+
+	return result
+}
+
+// Returns an error when the completed batch task failed
+func taskExecutionError(batchTask *azsdk.BatchTask) error {
+	info := batchTask.ExecutionInfo
+	if info == nil {
+		return nil
+	}
+
+	if info.FailureInfo != nil {
+		return fmt.Errorf("batch task '%s' failed: %s", batchTask.Id, info.FailureInfo.Message)
+	}
+
+	if info.ExitCode != nil && *info.ExitCode != 0 {
+		return fmt.Errorf("batch task '%s' failed with exit code %d", batchTask.Id, *info.ExitCode)
+	}
+
+	return nil
+}
+
+// Evaluates the configured value, falling back to the SERVICE_<NAME>_<envSuffix> environment value
+func (t *batchJobTarget) serviceValue(
+	serviceConfig *ServiceConfig,
+	value ExpandableString,
+	configKey string,
+	envSuffix string,
+) (string, error) {
+	result, err := value.Envsubst(t.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("evaluating batch %s: %w", configKey, err)
+	}
+
+	if result == "" {
+		result = t.env.GetServiceProperty(serviceConfig.Name, envSuffix)
+	}
+
+	if result == "" {
+		return "", fmt.Errorf(
+			"could not determine the batch %s for service '%s', set 'batch.%s' in azure.yaml or "+
+				"ensure SERVICE_%s_%s is set as an output of your infrastructure",
+			configKey,
+			serviceConfig.Name,
+			configKey,
+			strings.ToUpper(strings.ReplaceAll(serviceConfig.Name, "-", "_")),
+			envSuffix,
+		)
+	}
+
+	return result, nil
+}
+
+func (t *batchJobTarget) jobId(serviceConfig *ServiceConfig) string {
+	if serviceConfig.Batch.JobId != "" {
+		return serviceConfig.Batch.JobId
+	}
+
+	return strings.ToLower(serviceConfig.Name)
+}
@@ -0,0 +1,392 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/oras"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+func TestNewBatchJobTargetTypeValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*serviceTargetValidationTest{
+		"ValidateTypeSuccess": {
+			targetResource: environment.NewTargetResource(
+				"SUBSCRIPTION_ID", "RESOURCE_GROUP", "res", string(infra.AzureResourceTypeBatchAccount),
+			),
+			expectError: false,
+		},
+		"ValidateTypeLowerCaseSuccess": {
+			targetResource: environment.NewTargetResource(
+				"SUBSCRIPTION_ID", "RESOURCE_GROUP", "res", strings.ToLower(string(infra.AzureResourceTypeBatchAccount)),
+			),
+			expectError: false,
+		},
+		"ValidateTypeFail": {
+			targetResource: environment.NewTargetResource("SUBSCRIPTION_ID", "RESOURCE_GROUP", "res", "BadType"),
+			expectError:    true,
+		},
+	}
+
+	for test, data := range tests {
+		t.Run(test, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			serviceTarget := &batchJobTarget{}
+			serviceConfig := &ServiceConfig{}
+
+			err := serviceTarget.validateTargetResource(*mockContext.Context, serviceConfig, data.targetResource)
+			if data.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_BatchJobTarget_Publish(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	var submittedTask azsdk.BatchTask
+	registerBatchTaskMocks(t, mockContext, &submittedTask, 0)
+
+	tempDir := t.TempDir()
+	serviceConfig := createTestServiceConfig(tempDir, BatchJobTarget, ServiceLanguagePython)
+	serviceConfig.Batch = BatchOptions{
+		TaskTemplate:     "task.json",
+		RegistryIdentity: NewExpandableString("IDENTITY_ID"),
+	}
+
+	template := `{"commandLine": "python main.py", "environmentSettings": [{"name": "MODE", "value": "batch"}]}`
+	err := os.WriteFile(filepath.Join(tempDir, "task.json"), []byte(template), osutil.PermissionFile)
+	require.NoError(t, err)
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"SERVICE_API_BATCH_ENDPOINT": "https://account.eastus.batch.azure.com",
+		"SERVICE_API_BATCH_POOL_ID":  "pool",
+	})
+
+	serviceTarget := createBatchJobServiceTarget(mockContext, env, advancingClock(t))
+	targetResource := environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "RESOURCE_GROUP", "account", string(infra.AzureResourceTypeBatchAccount),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{
+			PackagePath: "IMAGE_ID",
+			Details: &dockerPackageResult{
+				ImageTag:    "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0",
+				LoginServer: "REGISTRY.azurecr.io",
+			},
+		},
+		targetResource,
+	)
+
+	progress := []string{}
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		for value := range publishTask.Progress() {
+			progress = append(progress, value.Message)
+		}
+	}()
+
+	result, err := publishTask.Await()
+	<-progressDone
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Batch task 'api-0' is running",
+		"rendering frame 1",
+		"Batch task 'api-0' is completed",
+		"rendering frame 2",
+	}, progress[slices.Index(progress, "Submitting batch task")+1:])
+	require.Equal(t, BatchJobTarget, result.Kind)
+	require.IsType(t, new(azsdk.BatchTask), result.Details)
+	require.Equal(t, "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0", env.Values["SERVICE_API_IMAGE_NAME"])
+
+	require.Equal(t, "python main.py", submittedTask.CommandLine)
+	require.Equal(t, "api-0", submittedTask.Id)
+	require.Len(t, submittedTask.EnvironmentSettings, 1)
+	require.Equal(t, "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0", submittedTask.ContainerSettings.ImageName)
+	require.Equal(t, "REGISTRY.azurecr.io", submittedTask.ContainerSettings.Registry.RegistryServer)
+	require.Equal(t, "IDENTITY_ID", submittedTask.ContainerSettings.Registry.IdentityReference.ResourceId)
+}
+
+func Test_BatchJobTarget_Publish_TaskFailed(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	var submittedTask azsdk.BatchTask
+	registerBatchTaskMocks(t, mockContext, &submittedTask, 1)
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), BatchJobTarget, ServiceLanguagePython)
+	serviceConfig.Batch = BatchOptions{
+		Endpoint:    NewExpandableString("https://account.eastus.batch.azure.com"),
+		PoolId:      NewExpandableString("pool"),
+		CommandLine: "python main.py",
+	}
+
+	env := environment.EphemeralWithValues("test", nil)
+	serviceTarget := createBatchJobServiceTarget(mockContext, env, advancingClock(t))
+	targetResource := environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "RESOURCE_GROUP", "account", string(infra.AzureResourceTypeBatchAccount),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{
+			Details: &dockerPackageResult{
+				ImageTag:    "IMAGE_TAG",
+				LoginServer: "REGISTRY.azurecr.io",
+			},
+		},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "exit code 1")
+	require.Nil(t, result)
+}
+
+func Test_BatchJobTarget_Publish_MissingCommandLine(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), BatchJobTarget, ServiceLanguagePython)
+	serviceConfig.Batch = BatchOptions{
+		Endpoint: NewExpandableString("https://account.eastus.batch.azure.com"),
+		PoolId:   NewExpandableString("pool"),
+	}
+
+	env := environment.EphemeralWithValues("test", nil)
+	serviceTarget := createBatchJobServiceTarget(mockContext, env, clock.NewMock())
+	targetResource := environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "RESOURCE_GROUP", "account", string(infra.AzureResourceTypeBatchAccount),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{Details: &dockerPackageResult{ImageTag: "IMAGE_TAG"}},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "batch.commandLine")
+	require.Nil(t, result)
+}
+
+func Test_BatchJobTarget_CreateTask_RegistryCredentials(t *testing.T) {
+	serviceConfig := createTestServiceConfig(t.TempDir(), BatchJobTarget, ServiceLanguagePython)
+	serviceConfig.Batch = BatchOptions{
+		CommandLine:      "python main.py",
+		RegistryIdentity: NewExpandableString("IDENTITY_ID"),
+	}
+
+	target := &batchJobTarget{env: environment.EphemeralWithValues("test", nil), clock: clock.NewMock()}
+	batchTask, err := target.createTask(
+		serviceConfig,
+		&dockerPackageResult{ImageTag: "ghcr.io/contoso/api:azd-deploy-0", LoginServer: "ghcr.io/contoso"},
+		&azcli.DockerCredentials{Username: "octocat", Password: "TOKEN", LoginServer: "ghcr.io"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, &azsdk.BatchContainerRegistry{
+		RegistryServer: "ghcr.io",
+		Username:       "octocat",
+		Password:       "TOKEN",
+	}, batchTask.ContainerSettings.Registry)
+}
+
+func createBatchJobServiceTarget(
+	mockContext *mocks.MockContext,
+	env *environment.Environment,
+	clock clock.Clock,
+) ServiceTarget {
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+		func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		})
+
+	containerRegistryService := azcli.NewContainerRegistryService(credentialProvider, mockContext.HttpClient, dockerCli)
+
+	return NewBatchJobTarget(
+		env,
+		NewImagePushCoordinator(
+			env,
+			containerRegistryService,
+			mockazcli.NewAzCliFromMockContext(mockContext),
+			dockerCli,
+			oras.NewOrasCli(mockContext.CommandRunner),
+			notation.NewNotationCli(mockContext.CommandRunner),
+			cosign.NewCosignCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+		),
+		azcli.NewBatchService(credentialProvider, mockContext.HttpClient),
+		dockerCli,
+		clock,
+	)
+}
+
+// Creates a mock clock that keeps advancing past the batch task poll interval until the test completes
+func advancingClock(t *testing.T) *clock.Mock {
+	mockClock := clock.NewMock()
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				mockClock.Add(batchTaskPollInterval)
+			}
+		}
+	}()
+
+	return mockClock
+}
+
+func setupMocksForBatchJobTarget(mockContext *mocks.MockContext) {
+	// The container registry is served by the ARM simulator
+	if _, err := registerSimulatedTargetResources(mockContext); err != nil {
+		panic(err)
+	}
+
+	// Docker login & push
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker login") || strings.Contains(command, "docker push")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	// Image layers
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, "[]", ""), nil
+	})
+}
+
+func registerBatchTaskMocks(
+	t *testing.T,
+	mockContext *mocks.MockContext,
+	submittedTask *azsdk.BatchTask,
+	exitCode int,
+) {
+	// Create job
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/jobs"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	// Add task
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/jobs/api/tasks"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, submittedTask))
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+	})
+
+	// Get task, running on the first poll and completed afterwards
+	polls := 0
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet &&
+			strings.HasPrefix(request.URL.Path, "/jobs/api/tasks/") &&
+			!strings.Contains(request.URL.Path, "/files/")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		polls++
+		if polls == 1 {
+			return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.BatchTask{
+				Id:    submittedTask.Id,
+				State: azsdk.BatchTaskStateRunning,
+			})
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.BatchTask{
+			Id:    submittedTask.Id,
+			State: azsdk.BatchTaskStateCompleted,
+			ExecutionInfo: &azsdk.BatchTaskExecutionInfo{
+				ExitCode: convert.RefOf(exitCode),
+			},
+		})
+	})
+
+	// Task stdout, the second line is only written once the task completes
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/files/stdout.txt")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		content := "rendering frame 1\nrendering"
+		if polls > 1 {
+			content += " frame 2"
+		}
+
+		var offset int
+		_, err := fmt.Sscanf(request.Header.Get("ocp-range"), "bytes=%d-", &offset)
+		require.NoError(t, err)
+
+		if offset >= len(content) {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusRequestedRangeNotSatisfiable)
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusPartialContent,
+			Header:     http.Header{},
+			Request:    request,
+			Body:       io.NopCloser(strings.NewReader(content[offset:])),
+		}, nil
+	})
+
+	// Task stderr is never written
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/files/stderr.txt")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+	})
+}
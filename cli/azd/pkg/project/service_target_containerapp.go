@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
@@ -26,16 +27,25 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 )
 
+// ContainerAppOptions are the options of services hosted in Azure Container Apps
+type ContainerAppOptions struct {
+	// The ingress of the container app. The target port and transport are detected from the image when not declared,
+	// and exposed to the infrastructure as the SERVICE_<NAME>_TARGET_PORT and SERVICE_<NAME>_TRANSPORT values.
+	Ingress ContainerAppIngressOptions `yaml:"ingress"`
+	// Whether the container app runs on ARM workload profiles, so the image can be built for linux/arm64
+	ArmProfiles bool `yaml:"armProfiles"`
+}
+
 type containerAppTarget struct {
-	env                      *environment.Environment
-	cli                      azcli.AzCli
-	containerRegistryService azcli.ContainerRegistryService
-	docker                   docker.Docker
-	console                  input.Console
-	commandRunner            exec.CommandRunner
-	accountManager           account.Manager
-	serviceManager           ServiceManager
-	resourceManager          ResourceManager
+	env                  *environment.Environment
+	cli                  azcli.AzCli
+	imagePushCoordinator *ImagePushCoordinator
+	docker               docker.Docker
+	console              input.Console
+	commandRunner        exec.CommandRunner
+	accountManager       account.Manager
+	serviceManager       ServiceManager
+	resourceManager      ResourceManager
 }
 
 // NewContainerAppTarget creates the container app service target.
@@ -44,7 +54,7 @@ type containerAppTarget struct {
 // can be provisioned during deployment.
 func NewContainerAppTarget(
 	env *environment.Environment,
-	containerRegistryService azcli.ContainerRegistryService,
+	imagePushCoordinator *ImagePushCoordinator,
 	azCli azcli.AzCli,
 	docker docker.Docker,
 	console input.Console,
@@ -54,15 +64,15 @@ func NewContainerAppTarget(
 	resourceManager ResourceManager,
 ) ServiceTarget {
 	return &containerAppTarget{
-		env:                      env,
-		accountManager:           accountManager,
-		serviceManager:           serviceManager,
-		resourceManager:          resourceManager,
-		cli:                      azCli,
-		containerRegistryService: containerRegistryService,
-		docker:                   docker,
-		console:                  console,
-		commandRunner:            commandRunner,
+		env:                  env,
+		accountManager:       accountManager,
+		serviceManager:       serviceManager,
+		resourceManager:      resourceManager,
+		cli:                  azCli,
+		imagePushCoordinator: imagePushCoordinator,
+		docker:               docker,
+		console:              console,
+		commandRunner:        commandRunner,
 	}
 }
 
@@ -117,25 +127,52 @@ func (at *containerAppTarget) Publish(
 				return
 			}
 
-			log.Printf("logging into registry %s", packageDetails.LoginServer)
-			task.SetProgress(NewServiceProgress("Logging into container registry"))
-			err := at.containerRegistryService.LoginAcr(ctx, targetResource.SubscriptionId(), packageDetails.LoginServer)
+			task.SetProgress(NewServiceProgress("Pushing image"))
+			err := at.imagePushCoordinator.Push(
+				ctx,
+				targetResource.SubscriptionId(),
+				serviceConfig,
+				packageDetails,
+				func(status string) { task.SetProgress(NewServiceProgress(status)) },
+			)
 			if err != nil {
-				task.SetError(fmt.Errorf("logging into registry '%s': %w", packageDetails.LoginServer, err))
+				task.SetError(fmt.Errorf("pushing image: %w", err))
 				return
 			}
 
-			// Push image.
-			log.Printf("pushing %s to registry", packageDetails.ImageTag)
-			task.SetProgress(NewServiceProgress("Pushing image"))
-			if err := at.docker.Push(ctx, serviceConfig.Path(), packageDetails.ImageTag); err != nil {
-				task.SetError(fmt.Errorf("pushing image: %w", err))
+			imageReference, pullCreds, err := at.imagePushCoordinator.ImageReference(
+				ctx,
+				targetResource.SubscriptionId(),
+				packageDetails,
+			)
+			if err != nil {
+				task.SetError(fmt.Errorf("selecting registry endpoint: %w", err))
 				return
 			}
 
 			// Save the name of the image we pushed into the environment with a well known key.
 			log.Printf("writing image name to environment")
-			at.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", packageDetails.ImageTag)
+			at.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", imageReference)
+
+			// The container app pulls images from registries configured for the service, and from connected
+			// registries, with the registry credentials of its infrastructure
+			if err := setRegistryProperties(at.env, serviceConfig.Name, packageDetails, pullCreds); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Detecting container app ingress"))
+			ingress, err := at.resolveIngress(ctx, serviceConfig, packageDetails)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			if ingress != nil {
+				log.Printf("using target port %d (%s) and transport %s", ingress.TargetPort, ingress.Source, ingress.Transport)
+				at.env.SetServiceProperty(serviceConfig.Name, serviceTargetPortProperty, strconv.Itoa(ingress.TargetPort))
+				at.env.SetServiceProperty(serviceConfig.Name, serviceTransportProperty, ingress.Transport)
+			}
 
 			if err := at.env.Save(); err != nil {
 				task.SetError(fmt.Errorf("saving image name to environment: %w", err))
@@ -218,12 +255,23 @@ func (at *containerAppTarget) Publish(
 			}
 
 			task.SetProgress(NewServiceProgress("Fetching endpoints for container app service"))
-			endpoints, err := at.Endpoints(ctx, serviceConfig, targetResource)
+			containerAppProperties, err := at.cli.GetContainerAppProperties(
+				ctx,
+				targetResource.SubscriptionId(),
+				targetResource.ResourceGroupName(),
+				targetResource.ResourceName(),
+			)
 			if err != nil {
-				task.SetError(err)
+				task.SetError(fmt.Errorf("fetching service properties: %w", err))
 				return
 			}
 
+			if warning := validateDeployedIngress(
+				serviceConfig, ingress, containerAppProperties.TargetPort,
+			); warning != "" {
+				at.console.MessageUxItem(ctx, &ux.WarningMessage{Description: warning})
+			}
+
 			task.SetResult(&ServicePublishResult{
 				Package: packageOutput,
 				TargetResourceId: azure.ContainerAppRID(
@@ -233,7 +281,7 @@ func (at *containerAppTarget) Publish(
 				),
 				Kind:      ContainerAppTarget,
 				Details:   deployResult,
-				Endpoints: endpoints,
+				Endpoints: containerAppEndpoints(containerAppProperties),
 			})
 		},
 	)
@@ -253,13 +301,86 @@ func (at *containerAppTarget) Endpoints(
 	); err != nil {
 		return nil, fmt.Errorf("fetching service properties: %w", err)
 	} else {
-		endpoints := make([]string, len(containerAppProperties.HostNames))
-		for idx, hostName := range containerAppProperties.HostNames {
-			endpoints[idx] = fmt.Sprintf("https://%s/", hostName)
+		return containerAppEndpoints(containerAppProperties), nil
+	}
+}
+
+// Restarts the latest revision of the container app
+func (at *containerAppTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := at.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	return at.cli.RestartContainerApp(
+		ctx, targetResource.SubscriptionId(), targetResource.ResourceGroupName(), targetResource.ResourceName())
+}
+
+// Scales the container app to a fixed number of replicas, which replaces the scale of its template, ex) the scale of
+// the container app set by its infrastructure
+func (at *containerAppTarget) Scale(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ScaleOptions,
+) error {
+	if err := at.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	// Container apps only scale to zero replicas through their scale rules, while they have no traffic
+	if options.Replicas < 1 {
+		return errors.New("container apps are scaled to at least 1 replica")
+	}
+
+	return at.cli.ScaleContainerApp(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		options.Replicas,
+	)
+}
+
+func containerAppEndpoints(containerAppProperties *azcli.AzCliContainerAppProperties) []string {
+	endpoints := make([]string, len(containerAppProperties.HostNames))
+	for idx, hostName := range containerAppProperties.HostNames {
+		endpoints[idx] = fmt.Sprintf("https://%s/", hostName)
+	}
+
+	return endpoints
+}
+
+// Resolves the ingress of the container app from azure.yaml and from the image of the service. The image is only
+// inspected when it was built locally, and the ingress isn't detected when the image can't be inspected.
+func (at *containerAppTarget) resolveIngress(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageDetails *dockerPackageResult,
+) (*containerAppIngress, error) {
+	var imageConfig *docker.ImageConfig
+	if packageDetails.DeferredBuild == nil && packageDetails.LoginServer != "" {
+		config, err := at.docker.InspectConfig(ctx, serviceConfig.Path(), packageDetails.ImageTag)
+		if err != nil {
+			log.Printf("failed inspecting image %s, skipping ingress detection: %v", packageDetails.ImageTag, err)
+		} else {
+			imageConfig = config
 		}
+	}
+
+	ingress, warnings, err := resolveContainerAppIngress(serviceConfig, imageConfig)
+	if err != nil {
+		return nil, err
+	}
 
-		return endpoints, nil
+	for _, warning := range warnings {
+		at.console.MessageUxItem(ctx, &ux.WarningMessage{Description: warning})
 	}
+
+	return ingress, nil
 }
 
 func (at *containerAppTarget) validateTargetResource(
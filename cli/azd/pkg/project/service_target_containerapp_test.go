@@ -57,3 +57,13 @@ func TestNewContainerAppTargetTypeValidation(t *testing.T) {
 		})
 	}
 }
+
+func Test_ContainerAppTarget_Scale_ZeroReplicas(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	serviceTarget := &containerAppTarget{}
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "res", string(infra.AzureResourceTypeContainerApp))
+
+	err := serviceTarget.Scale(*mockContext.Context, &ServiceConfig{}, targetResource, ScaleOptions{Replicas: 0})
+	require.ErrorContains(t, err, "container apps are scaled to at least 1 replica")
+}
@@ -5,6 +5,7 @@ package project
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -54,16 +55,18 @@ func (f *functionAppTarget) Package(
 	return async.RunTaskWithProgress(
 		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
 			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
-			zipFilePath, err := createDeployableZip(serviceConfig.Name, packageOutput.PackagePath)
+			packageResult, err := createDeployableArchive(
+				serviceConfig,
+				packageOutput.PackagePath,
+				archiveFormats[AzureFunctionTarget]...,
+			)
 			if err != nil {
 				task.SetError(err)
 				return
 			}
 
-			task.SetResult(&ServicePackageResult{
-				Build:       packageOutput.Build,
-				PackagePath: zipFilePath,
-			})
+			packageResult.Build = packageOutput.Build
+			task.SetResult(packageResult)
 		},
 	)
 }
@@ -153,6 +156,45 @@ func (f *functionAppTarget) Endpoints(
 	}
 }
 
+// Restarts the function app
+func (f *functionAppTarget) Restart(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if err := f.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	return f.cli.RestartAppService(
+		ctx, targetResource.SubscriptionId(), targetResource.ResourceGroupName(), targetResource.ResourceName())
+}
+
+// Scales the app service plan of the function app to a number of instances
+func (f *functionAppTarget) Scale(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+	options ScaleOptions,
+) error {
+	if err := f.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+		return fmt.Errorf("validating target resource: %w", err)
+	}
+
+	if options.Replicas < 1 {
+		return errors.New("app service plans are scaled to at least 1 instance")
+	}
+
+	return f.cli.ScaleAppService(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+		options.Replicas,
+		options.Force,
+	)
+}
+
 func (f *functionAppTarget) validateTargetResource(
 	ctx context.Context,
 	serviceConfig *ServiceConfig,
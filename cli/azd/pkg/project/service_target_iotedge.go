@@ -0,0 +1,465 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/benbjohnson/clock"
+)
+
+const (
+	edgeAgentModule  = "$edgeAgent"
+	edgeHubModule    = "$edgeHub"
+	desiredKey       = "properties.desired"
+	edgeRuntimeImage = "mcr.microsoft.com/azureiotedge-agent:1.4"
+	edgeHubImage     = "mcr.microsoft.com/azureiotedge-hub:1.4"
+	//nolint:lll
+	edgeHubCreateOptions = `{"HostConfig":{"PortBindings":{"5671/tcp":[{"HostPort":"5671"}],"8883/tcp":[{"HostPort":"8883"}],"443/tcp":[{"HostPort":"443"}]}}}`
+)
+
+// The IoT Edge deployment configuration options
+type IotEdgeOptions struct {
+	// The optional path to a deployment manifest template, relative to the service path.
+	// When omitted a manifest containing the IoT Edge system modules is generated
+	Manifest string `yaml:"manifest"`
+	// The name of the module within the deployment manifest. Defaults to the service name
+	ModuleName string `yaml:"moduleName"`
+	// The optional container create options for the module
+	CreateOptions string `yaml:"createOptions"`
+	// The id of a single IoT Edge device the manifest is applied to
+	DeviceId ExpandableString `yaml:"deviceId"`
+	// The id of the automatic deployment, used when no device id is specified. Defaults to the service name
+	DeploymentId string `yaml:"deploymentId"`
+	// The target condition for the automatic deployment, ex) tags.environment='dev'
+	TargetCondition ExpandableString `yaml:"targetCondition"`
+	// The priority of the automatic deployment
+	Priority int `yaml:"priority"`
+	// When true the module is deployed as a layered deployment on top of an existing base deployment
+	Layered bool `yaml:"layered"`
+}
+
+type iotEdgeTarget struct {
+	env                      *environment.Environment
+	containerRegistryService azcli.ContainerRegistryService
+	imagePushCoordinator     *ImagePushCoordinator
+	iotHubService            azcli.IotHubService
+	docker                   docker.Docker
+	clock                    clock.Clock
+}
+
+// NewIotEdgeTarget creates a new instance of the IoT Edge service target.
+//
+// The module container image is pushed to the container registry and an IoT Edge deployment manifest
+// referencing the image is applied to a device or deployed to a set of devices of the IoT Hub.
+func NewIotEdgeTarget(
+	env *environment.Environment,
+	containerRegistryService azcli.ContainerRegistryService,
+	imagePushCoordinator *ImagePushCoordinator,
+	iotHubService azcli.IotHubService,
+	docker docker.Docker,
+	clock clock.Clock,
+) ServiceTarget {
+	return &iotEdgeTarget{
+		env:                      env,
+		containerRegistryService: containerRegistryService,
+		imagePushCoordinator:     imagePushCoordinator,
+		iotHubService:            iotHubService,
+		docker:                   docker,
+		clock:                    clock,
+	}
+}
+
+// Gets the required external tools for the IoT Edge target
+func (t *iotEdgeTarget) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{t.docker}
+}
+
+// Initializes the IoT Edge target
+func (t *iotEdgeTarget) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// The container image produced by the docker framework is used as-is
+func (t *iotEdgeTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			task.SetResult(packageOutput)
+		},
+	)
+}
+
+// Pushes the module image and applies the generated deployment manifest to the IoT Hub
+func (t *iotEdgeTarget) Publish(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) *async.TaskWithProgress[*ServicePublishResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePublishResult, ServiceProgress]) {
+			if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+				task.SetError(fmt.Errorf("validating target resource: %w", err))
+				return
+			}
+
+			packageDetails, ok := packageOutput.Details.(*dockerPackageResult)
+			if !ok {
+				task.SetError(errors.New("failed retrieving package result details"))
+				return
+			}
+
+			deviceId, err := serviceConfig.IotEdge.DeviceId.Envsubst(t.env.Getenv)
+			if err != nil {
+				task.SetError(fmt.Errorf("evaluating iot edge device id: %w", err))
+				return
+			}
+
+			targetCondition, err := serviceConfig.IotEdge.TargetCondition.Envsubst(t.env.Getenv)
+			if err != nil {
+				task.SetError(fmt.Errorf("evaluating iot edge target condition: %w", err))
+				return
+			}
+
+			if deviceId == "" && targetCondition == "" {
+				task.SetError(fmt.Errorf(
+					"service '%s' requires either 'iotEdge.deviceId' or 'iotEdge.targetCondition'",
+					serviceConfig.Name,
+				))
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Pushing image"))
+			err = t.imagePushCoordinator.Push(
+				ctx,
+				targetResource.SubscriptionId(),
+				serviceConfig,
+				packageDetails,
+				func(status string) { task.SetProgress(NewServiceProgress(status)) },
+			)
+			if err != nil {
+				task.SetError(fmt.Errorf("pushing image: %w", err))
+				return
+			}
+
+			t.env.SetServiceProperty(serviceConfig.Name, "IMAGE_NAME", packageDetails.ImageTag)
+			if err := t.env.Save(); err != nil {
+				task.SetError(fmt.Errorf("saving image name to environment: %w", err))
+				return
+			}
+
+			// The IoT Edge runtime pulls the module images using the registry credentials from the manifest
+			var registryCreds *azcli.DockerCredentials
+			if packageDetails.Registry != nil {
+				registryCreds, err = t.imagePushCoordinator.PullCredentials(
+					ctx,
+					targetResource.SubscriptionId(),
+					packageDetails,
+				)
+			} else {
+				registryCreds, err = t.containerRegistryService.Credentials(
+					ctx,
+					targetResource.SubscriptionId(),
+					packageDetails.LoginServer,
+				)
+			}
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Generating deployment manifest"))
+			content, err := t.createManifest(serviceConfig, packageDetails.ImageTag, registryCreds)
+			if err != nil {
+				task.SetError(fmt.Errorf("creating deployment manifest: %w", err))
+				return
+			}
+
+			hostName := fmt.Sprintf("%s.azure-devices.net", targetResource.ResourceName())
+			var deployment string
+
+			if deviceId != "" {
+				task.SetProgress(NewServiceProgress(fmt.Sprintf("Applying deployment manifest to device '%s'", deviceId)))
+				err := t.iotHubService.ApplyDeviceContent(ctx, targetResource.SubscriptionId(), hostName, deviceId, content)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				deployment = fmt.Sprintf("Applied deployment manifest to device '%s'", deviceId)
+			} else {
+				configuration := &azsdk.IotHubConfiguration{
+					Id:              t.deploymentId(serviceConfig),
+					SchemaVersion:   "1.0",
+					Content:         *content,
+					TargetCondition: targetCondition,
+					Priority:        serviceConfig.IotEdge.Priority,
+					Labels: map[string]string{
+						"azd-service-name": serviceConfig.Name,
+						"azd-env-name":     t.env.GetEnvName(),
+					},
+				}
+
+				task.SetProgress(NewServiceProgress(fmt.Sprintf("Creating deployment '%s'", configuration.Id)))
+				err := t.iotHubService.CreateDeployment(ctx, targetResource.SubscriptionId(), hostName, configuration)
+				if err != nil {
+					task.SetError(err)
+					return
+				}
+
+				deployment = fmt.Sprintf("Created deployment '%s'", configuration.Id)
+			}
+
+			sdr := NewServicePublishResult(
+				azure.ResourceRID(
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceType(),
+					targetResource.ResourceName(),
+				),
+				IotEdgeTarget,
+				deployment,
+				[]string{},
+			)
+			sdr.Package = packageOutput
+
+			task.SetResult(sdr)
+		},
+	)
+}
+
+// IoT Edge modules do not expose any endpoints
+func (t *iotEdgeTarget) Endpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]string, error) {
+	return []string{}, nil
+}
+
+func (t *iotEdgeTarget) validateTargetResource(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	return checkResourceType(targetResource, infra.AzureResourceTypeIotHub)
+}
+
+// Automatic deployments are immutable, a new deployment is created for every publish
+func (t *iotEdgeTarget) deploymentId(serviceConfig *ServiceConfig) string {
+	deploymentId := serviceConfig.IotEdge.DeploymentId
+	if deploymentId == "" {
+		deploymentId = serviceConfig.Name
+	}
+
+	return fmt.Sprintf("%s-%d", strings.ToLower(deploymentId), t.clock.Now().Unix())
+}
+
+func (t *iotEdgeTarget) moduleName(serviceConfig *ServiceConfig) string {
+	if serviceConfig.IotEdge.ModuleName != "" {
+		return serviceConfig.IotEdge.ModuleName
+	}
+
+	return serviceConfig.Name
+}
+
+// Creates the deployment manifest content for the service module.
+// Layered deployments only contain the module & registry credentials, while full manifests are based on
+// the configured manifest template or a generated manifest with the IoT Edge system modules.
+// Without registry credentials, the module image is pulled anonymously.
+func (t *iotEdgeTarget) createManifest(
+	serviceConfig *ServiceConfig,
+	imageTag string,
+	registryCreds *azcli.DockerCredentials,
+) (*azsdk.IotHubConfigurationContent, error) {
+	moduleName := t.moduleName(serviceConfig)
+	var registryName string
+	var registryCredential map[string]any
+	if registryCreds != nil {
+		registryName = strings.Split(registryCreds.LoginServer, ".")[0]
+		registryCredential = map[string]any{
+			"username": registryCreds.Username,
+			"password": registryCreds.Password,
+			"address":  registryCreds.LoginServer,
+		}
+	}
+
+	if serviceConfig.IotEdge.Layered {
+		module := map[string]any{
+			"version":       "1.0",
+			"type":          "docker",
+			"status":        "running",
+			"restartPolicy": "always",
+			"settings":      t.moduleSettings(serviceConfig, imageTag, nil),
+		}
+
+		moduleKey := fmt.Sprintf("%s.modules.%s", desiredKey, moduleName)
+		agent := map[string]any{
+			moduleKey: module,
+		}
+
+		if registryCredential != nil {
+			credentialKey := fmt.Sprintf("%s.runtime.settings.registryCredentials.%s", desiredKey, registryName)
+			agent[credentialKey] = registryCredential
+		}
+
+		return &azsdk.IotHubConfigurationContent{
+			ModulesContent: map[string]map[string]any{
+				edgeAgentModule: agent,
+			},
+		}, nil
+	}
+
+	content, err := t.loadManifest(serviceConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	agent, has := content.ModulesContent[edgeAgentModule][desiredKey].(map[string]any)
+	if !has {
+		return nil, fmt.Errorf("deployment manifest is missing '%s.%s'", edgeAgentModule, desiredKey)
+	}
+
+	if registryCredential != nil {
+		runtimeSettings := childMap(childMap(agent, "runtime"), "settings")
+		childMap(runtimeSettings, "registryCredentials")[registryName] = registryCredential
+	}
+
+	modules := childMap(agent, "modules")
+	module := childMap(modules, moduleName)
+	setDefault(module, "version", "1.0")
+	setDefault(module, "type", "docker")
+	setDefault(module, "status", "running")
+	setDefault(module, "restartPolicy", "always")
+	module["settings"] = t.moduleSettings(serviceConfig, imageTag, childMap(module, "settings"))
+
+	return content, nil
+}
+
+func (t *iotEdgeTarget) moduleSettings(
+	serviceConfig *ServiceConfig,
+	imageTag string,
+	settings map[string]any,
+) map[string]any {
+	if settings == nil {
+		settings = map[string]any{}
+	}
+
+	settings["image"] = imageTag
+	if serviceConfig.IotEdge.CreateOptions != "" {
+		settings["createOptions"] = serviceConfig.IotEdge.CreateOptions
+	}
+
+	return settings
+}
+
+// Loads the deployment manifest template or generates a manifest containing the IoT Edge system modules
+func (t *iotEdgeTarget) loadManifest(serviceConfig *ServiceConfig) (*azsdk.IotHubConfigurationContent, error) {
+	if serviceConfig.IotEdge.Manifest == "" {
+		return defaultEdgeManifest(), nil
+	}
+
+	manifestPath := serviceConfig.IotEdge.Manifest
+	if !filepath.IsAbs(manifestPath) {
+		manifestPath = filepath.Join(serviceConfig.Path(), manifestPath)
+	}
+
+	manifestBytes, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading deployment manifest: %w", err)
+	}
+
+	var content azsdk.IotHubConfigurationContent
+	if err := json.Unmarshal(manifestBytes, &content); err != nil {
+		return nil, fmt.Errorf("parsing deployment manifest '%s': %w", manifestPath, err)
+	}
+
+	if content.ModulesContent == nil {
+		return nil, fmt.Errorf("deployment manifest '%s' is missing 'modulesContent'", manifestPath)
+	}
+
+	return &content, nil
+}
+
+func defaultEdgeManifest() *azsdk.IotHubConfigurationContent {
+	return &azsdk.IotHubConfigurationContent{
+		ModulesContent: map[string]map[string]any{
+			edgeAgentModule: {
+				desiredKey: map[string]any{
+					"schemaVersion": "1.1",
+					"runtime": map[string]any{
+						"type": "docker",
+						"settings": map[string]any{
+							"minDockerVersion":    "v1.25",
+							"registryCredentials": map[string]any{},
+						},
+					},
+					"systemModules": map[string]any{
+						"edgeAgent": map[string]any{
+							"type":     "docker",
+							"settings": map[string]any{"image": edgeRuntimeImage},
+						},
+						"edgeHub": map[string]any{
+							"type":          "docker",
+							"status":        "running",
+							"restartPolicy": "always",
+							"settings": map[string]any{
+								"image":         edgeHubImage,
+								"createOptions": edgeHubCreateOptions,
+							},
+						},
+					},
+					"modules": map[string]any{},
+				},
+			},
+			edgeHubModule: {
+				desiredKey: map[string]any{
+					"schemaVersion": "1.1",
+					"routes": map[string]any{
+						"upstream": "FROM /messages/* INTO $upstream",
+					},
+					"storeAndForwardConfiguration": map[string]any{
+						"timeToLiveSecs": 7200,
+					},
+				},
+			},
+		},
+	}
+}
+
+// Gets the nested map for the specified key, creating it when missing
+func childMap(parent map[string]any, key string) map[string]any {
+	if child, has := parent[key].(map[string]any); has {
+		return child
+	}
+
+	child := map[string]any{}
+	parent[key] = child
+
+	return child
+}
+
+func setDefault(m map[string]any, key string, value any) {
+	if _, has := m[key]; !has {
+		m[key] = value
+	}
+}
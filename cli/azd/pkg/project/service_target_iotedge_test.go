@@ -0,0 +1,240 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/cosign"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/grype"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/notation"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/oras"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/syft"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/trivy"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockaccount"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIotEdgeTargetTypeValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*serviceTargetValidationTest{
+		"ValidateTypeSuccess": {
+			targetResource: environment.NewTargetResource("SUB_ID", "RG_ID", "res", string(infra.AzureResourceTypeIotHub)),
+			expectError:    false,
+		},
+		"ValidateTypeFail": {
+			targetResource: environment.NewTargetResource("SUB_ID", "RG_ID", "res", "BadType"),
+			expectError:    true,
+		},
+	}
+
+	for test, data := range tests {
+		t.Run(test, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			serviceTarget := &iotEdgeTarget{}
+			serviceConfig := &ServiceConfig{}
+
+			err := serviceTarget.validateTargetResource(*mockContext.Context, serviceConfig, data.targetResource)
+			if data.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_IotEdgeTarget_Publish_Device(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	setupMocksForBatchJobTarget(mockContext)
+
+	var appliedContent azsdk.IotHubConfigurationContent
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			request.URL.Host == "hub.azure-devices.net" &&
+			request.URL.Path == "/devices/device-1/applyConfigurationContent"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &appliedContent))
+
+		return mocks.CreateEmptyHttpResponse(request, http.StatusNoContent)
+	})
+
+	serviceConfig := createTestServiceConfig(t.TempDir(), IotEdgeTarget, ServiceLanguagePython)
+	serviceConfig.IotEdge = IotEdgeOptions{
+		DeviceId: NewExpandableString("${EDGE_DEVICE_ID}"),
+	}
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"EDGE_DEVICE_ID": "device-1",
+	})
+
+	serviceTarget := createIotEdgeServiceTarget(mockContext, env)
+	targetResource := environment.NewTargetResource(
+		"SUBSCRIPTION_ID", "RESOURCE_GROUP", "hub", string(infra.AzureResourceTypeIotHub),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{
+			Details: &dockerPackageResult{
+				ImageTag:    "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0",
+				LoginServer: "REGISTRY.azurecr.io",
+			},
+		},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, IotEdgeTarget, result.Kind)
+
+	agent := appliedContent.ModulesContent[edgeAgentModule][desiredKey].(map[string]any)
+	module := agent["modules"].(map[string]any)["api"].(map[string]any)
+	require.Equal(t, "REGISTRY.azurecr.io/test-app/api-test:azd-deploy-0", module["settings"].(map[string]any)["image"])
+
+	settings := agent["runtime"].(map[string]any)["settings"].(map[string]any)
+	credentials := settings["registryCredentials"].(map[string]any)["REGISTRY"].(map[string]any)
+	require.Equal(t, "REGISTRY", credentials["username"])
+	require.Equal(t, "simulated-password", credentials["password"])
+	require.Contains(t, appliedContent.ModulesContent, edgeHubModule)
+}
+
+func Test_IotEdgeTarget_Publish_MissingTarget(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	serviceConfig := createTestServiceConfig(t.TempDir(), IotEdgeTarget, ServiceLanguagePython)
+
+	serviceTarget := createIotEdgeServiceTarget(mockContext, environment.EphemeralWithValues("test", nil))
+	targetResource := environment.NewTargetResource("SUB_ID", "RG_ID", "hub", string(infra.AzureResourceTypeIotHub))
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{Details: &dockerPackageResult{ImageTag: "IMAGE_TAG"}},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "iotEdge.targetCondition")
+	require.Nil(t, result)
+}
+
+func Test_IotEdgeTarget_CreateManifest(t *testing.T) {
+	registryCreds := &azcli.DockerCredentials{
+		Username:    "admin",
+		Password:    "password",
+		LoginServer: "REGISTRY.azurecr.io",
+	}
+
+	t.Run("FromTemplate", func(t *testing.T) {
+		tempDir := t.TempDir()
+		serviceConfig := createTestServiceConfig(tempDir, IotEdgeTarget, ServiceLanguagePython)
+		serviceConfig.IotEdge = IotEdgeOptions{
+			Manifest:   "deployment.template.json",
+			ModuleName: "sensor",
+		}
+
+		template := `{
+			"modulesContent": {
+				"$edgeAgent": {
+					"properties.desired": {
+						"modules": {
+							"sensor": { "settings": { "image": "placeholder", "createOptions": "{}" } }
+						}
+					}
+				}
+			}
+		}`
+		err := os.WriteFile(filepath.Join(tempDir, "deployment.template.json"), []byte(template), osutil.PermissionFile)
+		require.NoError(t, err)
+
+		serviceTarget := &iotEdgeTarget{}
+		content, err := serviceTarget.createManifest(serviceConfig, "IMAGE_TAG", registryCreds)
+		require.NoError(t, err)
+
+		agent := content.ModulesContent[edgeAgentModule][desiredKey].(map[string]any)
+		module := agent["modules"].(map[string]any)["sensor"].(map[string]any)
+		require.Equal(t, "IMAGE_TAG", module["settings"].(map[string]any)["image"])
+		require.Equal(t, "{}", module["settings"].(map[string]any)["createOptions"])
+		require.Equal(t, "running", module["status"])
+	})
+
+	t.Run("Layered", func(t *testing.T) {
+		serviceConfig := createTestServiceConfig(t.TempDir(), IotEdgeTarget, ServiceLanguagePython)
+		serviceConfig.IotEdge = IotEdgeOptions{Layered: true}
+
+		serviceTarget := &iotEdgeTarget{}
+		content, err := serviceTarget.createManifest(serviceConfig, "IMAGE_TAG", registryCreds)
+		require.NoError(t, err)
+
+		agent := content.ModulesContent[edgeAgentModule]
+		require.Len(t, agent, 2)
+		require.Contains(t, agent, "properties.desired.modules.api")
+		require.Contains(t, agent, "properties.desired.runtime.settings.registryCredentials.REGISTRY")
+		require.NotContains(t, content.ModulesContent, edgeHubModule)
+	})
+}
+
+func Test_IotEdgeTarget_DeploymentId(t *testing.T) {
+	serviceConfig := createTestServiceConfig(t.TempDir(), IotEdgeTarget, ServiceLanguagePython)
+	serviceTarget := &iotEdgeTarget{clock: clock.NewMock()}
+
+	require.True(t, strings.HasPrefix(serviceTarget.deploymentId(serviceConfig), "api-"))
+
+	serviceConfig.IotEdge.DeploymentId = "Sensors"
+	require.Equal(t, "sensors-0", serviceTarget.deploymentId(serviceConfig))
+}
+
+func createIotEdgeServiceTarget(mockContext *mocks.MockContext, env *environment.Environment) ServiceTarget {
+	dockerCli := docker.NewDocker(mockContext.CommandRunner)
+	credentialProvider := mockaccount.SubscriptionCredentialProviderFunc(
+		func(_ context.Context, _ string) (azcore.TokenCredential, error) {
+			return mockContext.Credentials, nil
+		})
+
+	containerRegistryService := azcli.NewContainerRegistryService(credentialProvider, mockContext.HttpClient, dockerCli)
+
+	return NewIotEdgeTarget(
+		env,
+		containerRegistryService,
+		NewImagePushCoordinator(
+			env,
+			containerRegistryService,
+			mockazcli.NewAzCliFromMockContext(mockContext),
+			dockerCli,
+			oras.NewOrasCli(mockContext.CommandRunner),
+			notation.NewNotationCli(mockContext.CommandRunner),
+			cosign.NewCosignCli(mockContext.CommandRunner),
+			trivy.NewTrivyCli(mockContext.CommandRunner),
+			grype.NewGrypeCli(mockContext.CommandRunner),
+			syft.NewSyftCli(mockContext.CommandRunner),
+		),
+		azcli.NewIotHubService(credentialProvider, mockContext.HttpClient),
+		dockerCli,
+		clock.NewMock(),
+	)
+}
@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+)
+
+// The workflow files that support ${VAR} parameters resolved from the azd environment during packaging
+var logicAppParameterizedFiles = []string{"connections.json", "parameters.json"}
+
+// Only the braced form is resolved since workflow definitions commonly contain '$' characters
+var logicAppParameterRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// logicAppTarget specifies a Logic Apps Standard app to deploy to.
+// Implements `project.ServiceTarget`
+type logicAppTarget struct {
+	env *environment.Environment
+	cli azcli.AzCli
+}
+
+// NewLogicAppTarget creates a new instance of the Logic Apps Standard target.
+//
+// Logic Apps Standard runs on the Azure Functions runtime, so workflows are published using zip deploy.
+func NewLogicAppTarget(
+	env *environment.Environment,
+	azCli azcli.AzCli,
+) ServiceTarget {
+	return &logicAppTarget{
+		env: env,
+		cli: azCli,
+	}
+}
+
+// Gets the required external tools for the Logic App
+func (t *logicAppTarget) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{}
+}
+
+// Initializes the Logic App target
+func (t *logicAppTarget) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Resolves the connection parameters and prepares a zip archive from the specified build output
+func (t *logicAppTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Resolving workflow connections"))
+			if err := t.resolveParameters(packageOutput.PackagePath); err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
+			packageResult, err := createDeployableArchive(
+				serviceConfig,
+				packageOutput.PackagePath,
+				archiveFormats[LogicAppTarget]...,
+			)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packageResult.Build = packageOutput.Build
+			task.SetResult(packageResult)
+		},
+	)
+}
+
+// Publishes the prepared zip archive using Zip deploy to the Logic App
+func (t *logicAppTarget) Publish(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) *async.TaskWithProgress[*ServicePublishResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePublishResult, ServiceProgress]) {
+			if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+				task.SetError(fmt.Errorf("validating target resource: %w", err))
+				return
+			}
+
+			zipFile, err := os.Open(packageOutput.PackagePath)
+			if err != nil {
+				task.SetError(fmt.Errorf("failed reading deployment zip file: %w", err))
+				return
+			}
+
+			defer os.Remove(packageOutput.PackagePath)
+			defer zipFile.Close()
+
+			task.SetProgress(NewServiceProgress("Publishing workflows"))
+			res, err := t.cli.DeployFunctionAppUsingZipFile(
+				ctx,
+				targetResource.SubscriptionId(),
+				targetResource.ResourceGroupName(),
+				targetResource.ResourceName(),
+				zipFile,
+			)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			task.SetProgress(NewServiceProgress("Fetching endpoints for logic app"))
+			endpoints, err := t.Endpoints(ctx, serviceConfig, targetResource)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			sdr := NewServicePublishResult(
+				azure.WebsiteRID(
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceName(),
+				),
+				LogicAppTarget,
+				*res,
+				endpoints,
+			)
+			sdr.Package = packageOutput
+
+			task.SetResult(sdr)
+		},
+	)
+}
+
+// Gets the exposed endpoints for the Logic App
+func (t *logicAppTarget) Endpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]string, error) {
+	props, err := t.cli.GetFunctionAppProperties(
+		ctx,
+		targetResource.SubscriptionId(),
+		targetResource.ResourceGroupName(),
+		targetResource.ResourceName(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching service properties: %w", err)
+	}
+
+	endpoints := make([]string, len(props.HostNames))
+	for idx, hostName := range props.HostNames {
+		endpoints[idx] = fmt.Sprintf("https://%s/", hostName)
+	}
+
+	return endpoints, nil
+}
+
+func (t *logicAppTarget) validateTargetResource(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	return checkResourceType(targetResource, infra.AzureResourceTypeWebSite)
+}
+
+// Replaces ${VAR} parameters within the workflow connections & parameters with values from the azd environment,
+// typically set from infrastructure outputs. Unresolved parameters fail the packaging.
+func (t *logicAppTarget) resolveParameters(packagePath string) error {
+	missing := map[string]struct{}{}
+
+	for _, fileName := range logicAppParameterizedFiles {
+		filePath := filepath.Join(packagePath, fileName)
+
+		contents, err := os.ReadFile(filePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("reading %s: %w", fileName, err)
+		}
+
+		resolved := logicAppParameterRegex.ReplaceAllStringFunc(string(contents), func(match string) string {
+			name := logicAppParameterRegex.FindStringSubmatch(match)[1]
+			value, has := t.env.LookupEnv(name)
+			if !has {
+				missing[name] = struct{}{}
+				return match
+			}
+
+			// Values are placed within JSON string literals
+			escaped, _ := json.Marshal(value)
+			return string(escaped[1 : len(escaped)-1])
+		})
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filePath, []byte(resolved), info.Mode()); err != nil {
+			return fmt.Errorf("writing %s: %w", fileName, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return fmt.Errorf(
+			"unresolved workflow connection parameters: %s. Ensure they are set as outputs of your infrastructure",
+			strings.Join(names, ", "),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogicAppTargetTypeValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*serviceTargetValidationTest{
+		"ValidateTypeSuccess": {
+			targetResource: environment.NewTargetResource("SUB_ID", "RG_ID", "res", string(infra.AzureResourceTypeWebSite)),
+			expectError:    false,
+		},
+		"ValidateTypeFail": {
+			targetResource: environment.NewTargetResource("SUB_ID", "RG_ID", "res", "BadType"),
+			expectError:    true,
+		},
+	}
+
+	for test, data := range tests {
+		t.Run(test, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			serviceTarget := &logicAppTarget{}
+			serviceConfig := &ServiceConfig{}
+
+			err := serviceTarget.validateTargetResource(*mockContext.Context, serviceConfig, data.targetResource)
+			if data.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_LogicAppTarget_ResolveParameters(t *testing.T) {
+	connections := `{
+	"serviceProviderConnections": {
+		"serviceBus": {
+			"parameterValues": { "connectionString": "${SERVICE_BUS_CONNECTION}" }
+		}
+	},
+	"managedApiConnections": {
+		"office365": {
+			"api": { "id": "/subscriptions/${AZURE_SUBSCRIPTION_ID}/providers/Microsoft.Web/managedApis/office365" },
+			"authentication": { "type": "ManagedServiceIdentity" }
+		}
+	}
+}`
+
+	t.Run("Success", func(t *testing.T) {
+		tempDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tempDir, "connections.json"), []byte(connections), osutil.PermissionFile)
+		require.NoError(t, err)
+
+		env := environment.EphemeralWithValues("test", map[string]string{
+			"SERVICE_BUS_CONNECTION": `Endpoint=sb://bus/;SharedAccessKey="key"`,
+			"AZURE_SUBSCRIPTION_ID":  "SUB_ID",
+		})
+
+		serviceTarget := &logicAppTarget{env: env}
+		require.NoError(t, serviceTarget.resolveParameters(tempDir))
+
+		contents, err := os.ReadFile(filepath.Join(tempDir, "connections.json"))
+		require.NoError(t, err)
+		require.Contains(t, string(contents), `"connectionString": "Endpoint=sb://bus/;SharedAccessKey=\"key\""`)
+		require.Contains(t, string(contents), "/subscriptions/SUB_ID/providers")
+	})
+
+	t.Run("Unresolved", func(t *testing.T) {
+		tempDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tempDir, "connections.json"), []byte(connections), osutil.PermissionFile)
+		require.NoError(t, err)
+
+		serviceTarget := &logicAppTarget{env: environment.EphemeralWithValues("test", nil)}
+		err = serviceTarget.resolveParameters(tempDir)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "AZURE_SUBSCRIPTION_ID, SERVICE_BUS_CONNECTION")
+	})
+
+	t.Run("NoConnections", func(t *testing.T) {
+		serviceTarget := &logicAppTarget{env: environment.EphemeralWithValues("test", nil)}
+		require.NoError(t, serviceTarget.resolveParameters(t.TempDir()))
+	})
+}
@@ -0,0 +1,535 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/async"
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/azcli"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/ssh"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	defaultVmUser        = "azureuser"
+	defaultVmInstallRoot = "/opt"
+)
+
+// The virtual machine configuration options
+type VmOptions struct {
+	// The host name or IP address used to connect to the VM.
+	// Defaults to the SERVICE_<NAME>_VM_HOST environment value, typically set as an infrastructure output.
+	// Applications hosted in a scale set are deployed to every instance, the host only being used as their endpoint
+	Host ExpandableString `yaml:"host"`
+	// The user used to connect to the VM. Defaults to 'azureuser'
+	User string `yaml:"user"`
+	// The optional path to the SSH private key used to connect to the VM
+	SshKeyPath string `yaml:"sshKeyPath"`
+	// The optional SSH port. Defaults to 22
+	Port int `yaml:"port"`
+	// The absolute path of the folder on the VM where the application is installed, replaced on every deployment.
+	// Defaults to /opt/<service name>
+	InstallPath string `yaml:"installPath"`
+	// The command used to start the application, relative to the install path
+	StartCommand string `yaml:"startCommand"`
+	// The name of the systemd unit that runs the application. Defaults to the service name
+	Unit string `yaml:"unit"`
+	// The scheme of the endpoint of the application, http or https. Defaults to http
+	Scheme string `yaml:"scheme"`
+}
+
+// systemdUnit is the model used to render the systemd unit file for the service
+type systemdUnit struct {
+	Description      string
+	User             string
+	WorkingDirectory string
+	ExecStart        string
+	EnvironmentFile  string
+}
+
+var systemdUnitTemplate = template.Must(template.New("unit").Parse(`[Unit]
+Description={{ .Description }}
+After=network.target
+
+[Service]
+Type=simple
+User={{ .User }}
+WorkingDirectory={{ .WorkingDirectory }}
+EnvironmentFile=-{{ .EnvironmentFile }}
+ExecStart={{ .ExecStart }}
+Restart=always
+RestartSec=5
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+type vmTarget struct {
+	env   *environment.Environment
+	ssh   ssh.SshCli
+	azCli azcli.AzCli
+}
+
+// NewVmTarget creates a new instance of the virtual machine service target.
+//
+// Applications are packaged into a zip or tar.gz archive, copied to the VM over SSH and
+// run as a systemd unit. Applications hosted in a scale set are copied to every instance of the scale set.
+func NewVmTarget(
+	env *environment.Environment,
+	sshCli ssh.SshCli,
+	azCli azcli.AzCli,
+) ServiceTarget {
+	return &vmTarget{
+		env:   env,
+		ssh:   sshCli,
+		azCli: azCli,
+	}
+}
+
+// Gets the required external tools for the VM target
+func (t *vmTarget) RequiredExternalTools(context.Context) []tools.ExternalTool {
+	return []tools.ExternalTool{t.ssh}
+}
+
+// Initializes the VM target
+func (t *vmTarget) Initialize(ctx context.Context, serviceConfig *ServiceConfig) error {
+	return nil
+}
+
+// Prepares a zip or tar.gz archive from the specified build output
+func (t *vmTarget) Package(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+) *async.TaskWithProgress[*ServicePackageResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePackageResult, ServiceProgress]) {
+			task.SetProgress(NewServiceProgress("Compressing deployment artifacts"))
+			packageResult, err := createDeployableArchive(
+				serviceConfig,
+				packageOutput.PackagePath,
+				archiveFormats[VmTarget]...,
+			)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			packageResult.Build = packageOutput.Build
+			task.SetResult(packageResult)
+		},
+	)
+}
+
+// Copies the archive to the VM, or to every instance of the scale set, extracts it along with the environment values of
+// the service and (re)starts the systemd unit for the service
+func (t *vmTarget) Publish(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	packageOutput *ServicePackageResult,
+	targetResource *environment.TargetResource,
+) *async.TaskWithProgress[*ServicePublishResult, ServiceProgress] {
+	return async.RunTaskWithProgress(
+		func(task *async.TaskContextWithProgress[*ServicePublishResult, ServiceProgress]) {
+			if err := t.validateTargetResource(ctx, serviceConfig, targetResource); err != nil {
+				task.SetError(fmt.Errorf("validating target resource: %w", err))
+				return
+			}
+
+			defer os.Remove(packageOutput.PackagePath)
+
+			installPath, err := t.installPath(serviceConfig)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			sshTargets, err := t.sshTargets(ctx, serviceConfig, targetResource)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			unitFile, err := t.writeUnitFile(serviceConfig, sshTargets[0].User, installPath)
+			if err != nil {
+				task.SetError(fmt.Errorf("creating systemd unit: %w", err))
+				return
+			}
+			defer os.Remove(unitFile)
+
+			envFile, err := t.writeEnvFile(serviceConfig)
+			if err != nil {
+				task.SetError(fmt.Errorf("creating environment file: %w", err))
+				return
+			}
+			defer os.Remove(envFile)
+
+			format := ArchiveFormatZip
+			if archive, ok := packageOutput.Details.(*ArchivePackageResult); ok {
+				format = archive.Format
+			}
+
+			var res exec.RunResult
+			for _, sshTarget := range sshTargets {
+				// The instances of a scale set are reported by host
+				progress := func(message string) {
+					if len(sshTargets) > 1 {
+						message = fmt.Sprintf("%s (%s)", message, sshTarget.Host)
+					}
+
+					task.SetProgress(NewServiceProgress(message))
+				}
+
+				res, err = t.install(ctx, serviceConfig, sshTarget, installPath, format, packageOutput.PackagePath,
+					unitFile, envFile, progress)
+				if err != nil {
+					task.SetError(fmt.Errorf("deploying to host '%s': %w", sshTarget.Host, err))
+					return
+				}
+			}
+
+			task.SetProgress(NewServiceProgress("Fetching endpoints for virtual machine"))
+			endpoints, err := t.Endpoints(ctx, serviceConfig, targetResource)
+			if err != nil {
+				task.SetError(err)
+				return
+			}
+
+			sdr := NewServicePublishResult(
+				azure.ResourceRID(
+					targetResource.SubscriptionId(),
+					targetResource.ResourceGroupName(),
+					targetResource.ResourceType(),
+					targetResource.ResourceName(),
+				),
+				VmTarget,
+				res.Stdout,
+				endpoints,
+			)
+			sdr.Package = packageOutput
+
+			task.SetResult(sdr)
+		},
+	)
+}
+
+// Copies the archive, the systemd unit and the environment file to the host, installs the application in the install
+// path and (re)starts the systemd unit
+func (t *vmTarget) install(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	sshTarget ssh.Target,
+	installPath string,
+	format ArchiveFormat,
+	packagePath string,
+	unitFile string,
+	envFile string,
+	progress func(string),
+) (exec.RunResult, error) {
+	unitName := t.unitName(serviceConfig)
+	remotePackagePath := fmt.Sprintf("/tmp/%s.%s", unitName, format)
+	remoteUnitPath := fmt.Sprintf("/tmp/%s.service", unitName)
+	remoteEnvPath := fmt.Sprintf("/tmp/%s.env", unitName)
+
+	progress("Copying deployment package")
+	if err := t.ssh.Copy(ctx, sshTarget, packagePath, remotePackagePath); err != nil {
+		return exec.RunResult{}, err
+	}
+
+	if err := t.ssh.Copy(ctx, sshTarget, unitFile, remoteUnitPath); err != nil {
+		return exec.RunResult{}, err
+	}
+
+	if err := t.ssh.Copy(ctx, sshTarget, envFile, remoteEnvPath); err != nil {
+		return exec.RunResult{}, err
+	}
+
+	// The environment file holds the values of the environment, so only the user running the application can read it
+	progress("Installing application")
+	installEnvPath := path.Join(installPath, ".env")
+	installCommand := strings.Join([]string{
+		fmt.Sprintf("sudo rm -rf %s", shellQuote(installPath)),
+		fmt.Sprintf("sudo mkdir -p %s", shellQuote(installPath)),
+		extractCommand(format, remotePackagePath, installPath),
+		fmt.Sprintf("sudo mv %s %s", shellQuote(remoteEnvPath), shellQuote(installEnvPath)),
+		fmt.Sprintf("sudo chown -R %s %s", shellQuote(sshTarget.User), shellQuote(installPath)),
+		fmt.Sprintf("sudo chmod 600 %s", shellQuote(installEnvPath)),
+		fmt.Sprintf(
+			"sudo mv %s %s", shellQuote(remoteUnitPath), shellQuote(fmt.Sprintf("/etc/systemd/system/%s.service", unitName))),
+		fmt.Sprintf("rm -f %s", shellQuote(remotePackagePath)),
+	}, " && ")
+
+	if _, err := t.ssh.Run(ctx, sshTarget, installCommand); err != nil {
+		return exec.RunResult{}, err
+	}
+
+	progress("Restarting service")
+	restartCommand := strings.Join([]string{
+		"sudo systemctl daemon-reload",
+		fmt.Sprintf("sudo systemctl enable %s", shellQuote(unitName)),
+		fmt.Sprintf("sudo systemctl restart %s", shellQuote(unitName)),
+	}, " && ")
+
+	return t.ssh.Run(ctx, sshTarget, restartCommand)
+}
+
+// Gets the endpoints for the VM based on the configured host. Scale sets without a configured host, ex) the address of
+// their load balancer, have no endpoints.
+func (t *vmTarget) Endpoints(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]string, error) {
+	host, err := t.host(serviceConfig)
+	if err != nil {
+		if isScaleSet(targetResource) {
+			return []string{}, nil
+		}
+
+		return nil, err
+	}
+
+	scheme := serviceConfig.Vm.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("invalid 'vm.scheme' '%s' for service '%s', expected http or https", scheme, serviceConfig.Name)
+	}
+
+	return []string{fmt.Sprintf("%s://%s/", scheme, host)}, nil
+}
+
+func (t *vmTarget) validateTargetResource(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) error {
+	if !strings.EqualFold(targetResource.ResourceType(), string(infra.AzureResourceTypeVirtualMachine)) &&
+		!strings.EqualFold(targetResource.ResourceType(), string(infra.AzureResourceTypeVirtualMachineScaleSet)) {
+		return resourceTypeMismatchError(
+			targetResource.ResourceName(),
+			targetResource.ResourceType(),
+			infra.AzureResourceTypeVirtualMachine,
+		)
+	}
+
+	return nil
+}
+
+func (t *vmTarget) host(serviceConfig *ServiceConfig) (string, error) {
+	host, err := serviceConfig.Vm.Host.Envsubst(t.env.Getenv)
+	if err != nil {
+		return "", fmt.Errorf("evaluating vm host: %w", err)
+	}
+
+	if host == "" {
+		host = t.env.GetServiceProperty(serviceConfig.Name, "VM_HOST")
+	}
+
+	if host == "" {
+		return "", fmt.Errorf(
+			"could not determine the host for service '%s', set 'vm.host' in azure.yaml or "+
+				"ensure SERVICE_%s_VM_HOST is set as an output of your infrastructure",
+			serviceConfig.Name,
+			strings.ToUpper(strings.ReplaceAll(serviceConfig.Name, "-", "_")),
+		)
+	}
+
+	return host, nil
+}
+
+// Gets the SSH targets the application is deployed to, the configured host of a VM or every instance of a scale set
+func (t *vmTarget) sshTargets(
+	ctx context.Context,
+	serviceConfig *ServiceConfig,
+	targetResource *environment.TargetResource,
+) ([]ssh.Target, error) {
+	var hosts []string
+	if isScaleSet(targetResource) {
+		instanceHosts, err := t.azCli.GetScaleSetInstanceHosts(
+			ctx,
+			targetResource.SubscriptionId(),
+			targetResource.ResourceGroupName(),
+			targetResource.ResourceName(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(instanceHosts) == 0 {
+			return nil, fmt.Errorf("scale set '%s' has no instances to deploy to", targetResource.ResourceName())
+		}
+
+		hosts = instanceHosts
+	} else {
+		host, err := t.host(serviceConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		hosts = []string{host}
+	}
+
+	user := serviceConfig.Vm.User
+	if user == "" {
+		user = defaultVmUser
+	}
+
+	targets := []ssh.Target{}
+	for _, host := range hosts {
+		targets = append(targets, ssh.Target{
+			Host:    host,
+			User:    user,
+			KeyPath: serviceConfig.Vm.SshKeyPath,
+			Port:    serviceConfig.Vm.Port,
+		})
+	}
+
+	return targets, nil
+}
+
+// Whether the target resource is a scale set, the application being deployed to all its instances
+func isScaleSet(targetResource *environment.TargetResource) bool {
+	return strings.EqualFold(targetResource.ResourceType(), string(infra.AzureResourceTypeVirtualMachineScaleSet))
+}
+
+func (t *vmTarget) unitName(serviceConfig *ServiceConfig) string {
+	if serviceConfig.Vm.Unit != "" {
+		return serviceConfig.Vm.Unit
+	}
+
+	return strings.ToLower(serviceConfig.Name)
+}
+
+// Gets the install path of the application, which is removed on every deployment, so it must be an absolute path other
+// than the root directory
+func (t *vmTarget) installPath(serviceConfig *ServiceConfig) (string, error) {
+	if serviceConfig.Vm.InstallPath == "" {
+		return path.Join(defaultVmInstallRoot, t.unitName(serviceConfig)), nil
+	}
+
+	installPath := path.Clean(serviceConfig.Vm.InstallPath)
+	if !path.IsAbs(installPath) || installPath == "/" {
+		return "", fmt.Errorf(
+			"invalid 'vm.installPath' '%s' for service '%s', expected an absolute path other than /",
+			serviceConfig.Vm.InstallPath,
+			serviceConfig.Name,
+		)
+	}
+
+	return installPath, nil
+}
+
+// Renders the systemd unit for the service into a temporary file and returns the file path
+func (t *vmTarget) writeUnitFile(serviceConfig *ServiceConfig, user string, installPath string) (string, error) {
+	if strings.TrimSpace(serviceConfig.Vm.StartCommand) == "" {
+		return "", fmt.Errorf("'vm.startCommand' is required for service '%s'", serviceConfig.Name)
+	}
+
+	var buf bytes.Buffer
+	err := systemdUnitTemplate.Execute(&buf, systemdUnit{
+		Description:      fmt.Sprintf("%s (%s)", serviceConfig.Name, serviceConfig.Project.Name),
+		User:             user,
+		WorkingDirectory: installPath,
+		ExecStart:        t.execStart(serviceConfig.Vm.StartCommand, installPath),
+		EnvironmentFile:  path.Join(installPath, ".env"),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unitFile, err := os.CreateTemp("", "azd*.service")
+	if err != nil {
+		return "", err
+	}
+	defer unitFile.Close()
+
+	if _, err := unitFile.Write(buf.Bytes()); err != nil {
+		os.Remove(unitFile.Name())
+		return "", err
+	}
+
+	if err := unitFile.Chmod(osutil.PermissionFile); err != nil {
+		os.Remove(unitFile.Name())
+		return "", err
+	}
+
+	return unitFile.Name(), nil
+}
+
+// Writes the environment values of the service into a temporary file in the format of systemd environment files and
+// returns the file path
+func (t *vmTarget) writeEnvFile(serviceConfig *ServiceConfig) (string, error) {
+	values, err := serviceConfig.EnvValues(t.env)
+	if err != nil {
+		return "", err
+	}
+
+	keys := maps.Keys(values)
+	slices.Sort(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[key])
+		fmt.Fprintf(&buf, "%s=\"%s\"\n", key, value)
+	}
+
+	envFile, err := os.CreateTemp("", "azd*.env")
+	if err != nil {
+		return "", err
+	}
+	defer envFile.Close()
+
+	if err := envFile.Chmod(osutil.PermissionFileOwnerOnly); err != nil {
+		os.Remove(envFile.Name())
+		return "", err
+	}
+
+	if _, err := envFile.Write(buf.Bytes()); err != nil {
+		os.Remove(envFile.Name())
+		return "", err
+	}
+
+	return envFile.Name(), nil
+}
+
+// systemd requires an absolute path to the executable, relative commands are resolved from the install path
+func (t *vmTarget) execStart(startCommand string, installPath string) string {
+	startCommand = strings.TrimSpace(startCommand)
+	if strings.HasPrefix(startCommand, "/") {
+		return startCommand
+	}
+
+	return fmt.Sprintf("%s/%s", installPath, strings.TrimPrefix(startCommand, "./"))
+}
+
+// Gets the command extracting the package archive into the install path
+func extractCommand(format ArchiveFormat, packagePath string, installPath string) string {
+	if format == ArchiveFormatTarGz {
+		return fmt.Sprintf("sudo tar -xzf %s -C %s", shellQuote(packagePath), shellQuote(installPath))
+	}
+
+	return fmt.Sprintf("sudo unzip -o -q %s -d %s", shellQuote(packagePath), shellQuote(installPath))
+}
+
+// Quotes the value as a single argument of a POSIX shell command
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,302 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/ssh"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockazcli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVmTargetTypeValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*serviceTargetValidationTest{
+		"ValidateTypeSuccess": {
+			targetResource: environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "res", string(infra.AzureResourceTypeVirtualMachine),
+			),
+			expectError: false,
+		},
+		"ValidateScaleSetTypeSuccess": {
+			targetResource: environment.NewTargetResource(
+				"SUB_ID", "RG_ID", "res", string(infra.AzureResourceTypeVirtualMachineScaleSet),
+			),
+			expectError: false,
+		},
+		"ValidateTypeFail": {
+			targetResource: environment.NewTargetResource("SUB_ID", "RG_ID", "res", "BadType"),
+			expectError:    true,
+		},
+	}
+
+	for test, data := range tests {
+		t.Run(test, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			serviceTarget := &vmTarget{}
+			serviceConfig := &ServiceConfig{}
+
+			err := serviceTarget.validateTargetResource(*mockContext.Context, serviceConfig, data.targetResource)
+			if data.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_VmTarget_Publish(t *testing.T) {
+	commands := []exec.RunArgs{}
+	envFileContents := ""
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "ssh" || args.Cmd == "scp"
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			commands = append(commands, args)
+			if args.Cmd == "scp" && strings.HasSuffix(args.Args[len(args.Args)-1], ".env") {
+				contents, err := os.ReadFile(args.Args[len(args.Args)-2])
+				require.NoError(t, err)
+				envFileContents = string(contents)
+			}
+
+			return exec.NewRunResult(0, "", ""), nil
+		})
+
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"SERVICE_API_VM_HOST": "10.0.0.4",
+		"GREETING":            `say "hi"`,
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", VmTarget, ServiceLanguagePython)
+	serviceConfig.Vm = VmOptions{
+		SshKeyPath:   "~/.ssh/id_rsa",
+		StartCommand: "start.sh --port 80",
+		Scheme:       "https",
+	}
+
+	packageFile, err := os.CreateTemp(t.TempDir(), "*.zip")
+	require.NoError(t, err)
+	require.NoError(t, packageFile.Close())
+
+	serviceTarget := NewVmTarget(env, ssh.NewSshCli(mockContext.CommandRunner), mockazcli.NewAzCliFromMockContext(mockContext))
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "vm-api", string(infra.AzureResourceTypeVirtualMachine),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{PackagePath: packageFile.Name()},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, VmTarget, result.Kind)
+	require.Equal(t, []string{"https://10.0.0.4/"}, result.Endpoints)
+
+	require.Len(t, commands, 5)
+	require.Equal(t, "scp", commands[0].Cmd)
+	require.Contains(t, commands[0].Args, "azureuser@10.0.0.4:/tmp/api.zip")
+	require.Contains(t, commands[0].Args, "~/.ssh/id_rsa")
+	require.Equal(t, "scp", commands[1].Cmd)
+	require.Contains(t, commands[1].Args, "azureuser@10.0.0.4:/tmp/api.service")
+	require.Equal(t, "scp", commands[2].Cmd)
+	require.Contains(t, commands[2].Args, "azureuser@10.0.0.4:/tmp/api.env")
+	require.Contains(t, envFileContents, "GREETING=\"say \\\"hi\\\"\"\n")
+	require.Contains(t, envFileContents, "SERVICE_API_VM_HOST=\"10.0.0.4\"\n")
+
+	installCommand := commands[3].Args[len(commands[3].Args)-1]
+	require.Equal(t, "ssh", commands[3].Cmd)
+	require.Contains(t, installCommand, "unzip -o -q '/tmp/api.zip' -d '/opt/api'")
+	require.Contains(t, installCommand, "sudo mv '/tmp/api.env' '/opt/api/.env'")
+	require.Contains(t, installCommand, "sudo chmod 600 '/opt/api/.env'")
+	require.Equal(t, "ssh", commands[4].Cmd)
+	require.Contains(t, commands[4].Args[len(commands[4].Args)-1], "systemctl restart 'api'")
+}
+
+func Test_VmTarget_Publish_ScaleSet(t *testing.T) {
+	hosts := []string{}
+
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "ssh"
+		}).
+		RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+			hosts = append(hosts, args.Args[len(args.Args)-2])
+			return exec.NewRunResult(0, "", ""), nil
+		})
+	mockContext.CommandRunner.
+		When(func(args exec.RunArgs, command string) bool {
+			return args.Cmd == "scp"
+		}).
+		Respond(exec.NewRunResult(0, "", ""))
+
+	ipConfigurationId := func(instance string) string {
+		return "/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.Compute/virtualMachineScaleSets/vmss-api" +
+			"/virtualMachines/" + instance + "/networkInterfaces/nic/ipConfigurations/ipconfig"
+	}
+	networkInterface := func(instance string, privateIp string) map[string]any {
+		return map[string]any{
+			"properties": map[string]any{
+				"primary": true,
+				"virtualMachine": map[string]any{
+					"id": "/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.Compute/" +
+						"virtualMachineScaleSets/vmss-api/virtualMachines/" + instance,
+				},
+				"ipConfigurations": []map[string]any{{
+					"id": ipConfigurationId(instance),
+					"properties": map[string]any{
+						"primary":          true,
+						"privateIPAddress": privateIp,
+					},
+				}},
+			},
+		}
+	}
+
+	mockContext.HttpClient.
+		WhenPath(http.MethodGet, "/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.Compute/"+
+			"virtualMachineScaleSets/vmss-api/networkInterfaces").
+		RespondJson(http.StatusOK, map[string]any{
+			"value": []map[string]any{networkInterface("0", "10.0.0.4"), networkInterface("1", "10.0.0.5")},
+		})
+	mockContext.HttpClient.
+		WhenPath(http.MethodGet, "/subscriptions/SUB_ID/resourceGroups/RG_ID/providers/Microsoft.Compute/"+
+			"virtualMachineScaleSets/vmss-api/publicIPAddresses").
+		RespondJson(http.StatusOK, map[string]any{
+			"value": []map[string]any{{
+				"properties": map[string]any{
+					"ipAddress":       "20.1.2.3",
+					"ipConfiguration": map[string]any{"id": ipConfigurationId("1")},
+				},
+			}},
+		})
+
+	// The endpoint of a scale set is the address of its load balancer
+	env := environment.EphemeralWithValues("test", map[string]string{
+		"SERVICE_API_VM_HOST": "api.contoso.com",
+	})
+
+	serviceConfig := createTestServiceConfig("./src/api", VmTarget, ServiceLanguagePython)
+	serviceConfig.Vm = VmOptions{StartCommand: "start.sh"}
+
+	packageFile, err := os.CreateTemp(t.TempDir(), "*.zip")
+	require.NoError(t, err)
+	require.NoError(t, packageFile.Close())
+
+	serviceTarget := NewVmTarget(env, ssh.NewSshCli(mockContext.CommandRunner), mockazcli.NewAzCliFromMockContext(mockContext))
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "vmss-api", string(infra.AzureResourceTypeVirtualMachineScaleSet),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{PackagePath: packageFile.Name()},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://api.contoso.com/"}, result.Endpoints)
+
+	// Instances are reached through their public IP address when they have one
+	require.Equal(t, []string{
+		"azureuser@10.0.0.4", "azureuser@10.0.0.4",
+		"azureuser@20.1.2.3", "azureuser@20.1.2.3",
+	}, hosts)
+}
+
+func Test_VmTarget_InstallPath(t *testing.T) {
+	serviceTarget := &vmTarget{}
+	serviceConfig := createTestServiceConfig("./src/api", VmTarget, ServiceLanguagePython)
+
+	installPath, err := serviceTarget.installPath(serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, "/opt/api", installPath)
+
+	serviceConfig.Vm.InstallPath = "/srv/my app/"
+	installPath, err = serviceTarget.installPath(serviceConfig)
+	require.NoError(t, err)
+	require.Equal(t, "/srv/my app", installPath)
+
+	for _, invalid := range []string{"/", "//", "/srv/..", "srv/api", "~/api"} {
+		serviceConfig.Vm.InstallPath = invalid
+		_, err = serviceTarget.installPath(serviceConfig)
+		require.Error(t, err, invalid)
+	}
+}
+
+func Test_VmTarget_Publish_MissingHost(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("test", nil)
+
+	serviceConfig := createTestServiceConfig("./src/api", VmTarget, ServiceLanguagePython)
+	serviceConfig.Vm = VmOptions{StartCommand: "start.sh"}
+
+	serviceTarget := NewVmTarget(env, ssh.NewSshCli(mockContext.CommandRunner), mockazcli.NewAzCliFromMockContext(mockContext))
+	targetResource := environment.NewTargetResource(
+		"SUB_ID", "RG_ID", "vm-api", string(infra.AzureResourceTypeVirtualMachine),
+	)
+
+	publishTask := serviceTarget.Publish(
+		*mockContext.Context,
+		serviceConfig,
+		&ServicePackageResult{PackagePath: "package.zip"},
+		targetResource,
+	)
+	logProgress(publishTask)
+
+	result, err := publishTask.Await()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "SERVICE_API_VM_HOST")
+	require.Nil(t, result)
+}
+
+func Test_VmTarget_UnitFile(t *testing.T) {
+	serviceConfig := createTestServiceConfig("./src/api", VmTarget, ServiceLanguagePython)
+	serviceConfig.Vm = VmOptions{StartCommand: "./bin/server"}
+
+	serviceTarget := &vmTarget{}
+	unitFile, err := serviceTarget.writeUnitFile(serviceConfig, "azureuser", "/opt/api")
+	require.NoError(t, err)
+	defer os.Remove(unitFile)
+
+	contents, err := os.ReadFile(unitFile)
+	require.NoError(t, err)
+	require.Contains(t, string(contents), "ExecStart=/opt/api/bin/server")
+	require.Contains(t, string(contents), "WorkingDirectory=/opt/api")
+	require.Contains(t, string(contents), "User=azureuser")
+}
+
+func Test_VmTarget_ExtractCommand(t *testing.T) {
+	require.Equal(
+		t,
+		"sudo unzip -o -q '/tmp/api.zip' -d '/opt/api'",
+		extractCommand(ArchiveFormatZip, "/tmp/api.zip", "/opt/api"),
+	)
+	require.Equal(
+		t,
+		`sudo tar -xzf '/tmp/api.tar.gz' -C '/srv/it'\''s app'`,
+		extractCommand(ArchiveFormatTarGz, "/tmp/api.tar.gz", "/srv/it's app"),
+	)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// The default interval the sources of the services are polled for changes
+const DefaultWatchInterval = time.Second
+
+// The directories of dependencies in any directory of the services, which change when services are restored
+var watchDependencyDirs = []string{".git", ".azure", "node_modules", ".venv", "venv", "__pycache__"}
+
+// The directories of build outputs at the root of the services, which change when services are built
+var watchOutputDirs = []string{"bin", "obj", "target", "dist", "build"}
+
+// SourceChange is a change of the sources of a service
+type SourceChange struct {
+	Service *ServiceConfig
+	// The paths of the files that were added, modified or deleted, relative to the service
+	Files []string
+}
+
+// SourceWatcher watches the source directories of services for changes. The directories are polled, rather than
+// notified by the file system, so that directories mounted in containers and on network shares are watched too.
+type SourceWatcher struct {
+	services  []*ServiceConfig
+	interval  time.Duration
+	snapshots map[string]map[string]string
+}
+
+// NewSourceWatcher creates a watcher of the sources of the services, polling them at the interval. The changes are
+// relative to the current sources of the services.
+func NewSourceWatcher(services []*ServiceConfig, interval time.Duration) (*SourceWatcher, error) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	watcher := &SourceWatcher{
+		services: services,
+		interval: interval,
+	}
+
+	if err := watcher.Reset(); err != nil {
+		return nil, err
+	}
+
+	return watcher, nil
+}
+
+// Reset makes the next changes relative to the current sources of the services, ex) to ignore the files generated by
+// the builds of the services
+func (w *SourceWatcher) Reset() error {
+	snapshots := map[string]map[string]string{}
+	for _, serviceConfig := range w.services {
+		snapshot, err := sourceSnapshot(serviceConfig)
+		if err != nil {
+			return err
+		}
+
+		snapshots[serviceConfig.Name] = snapshot
+	}
+
+	w.snapshots = snapshots
+	return nil
+}
+
+// Wait polls the sources of the services until they change, and returns the changes of the services once the sources
+// settled, when no file changed during an interval, so that saving many files at once is a single change. Returns the
+// error of the context when it's cancelled.
+func (w *SourceWatcher) Wait(ctx context.Context) ([]SourceChange, error) {
+	changed := map[string]map[string]bool{}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		settled := true
+		for _, serviceConfig := range w.services {
+			snapshot, err := sourceSnapshot(serviceConfig)
+			if err != nil {
+				return nil, err
+			}
+
+			files := diffSnapshots(w.snapshots[serviceConfig.Name], snapshot)
+			if len(files) == 0 {
+				continue
+			}
+
+			settled = false
+			w.snapshots[serviceConfig.Name] = snapshot
+			if changed[serviceConfig.Name] == nil {
+				changed[serviceConfig.Name] = map[string]bool{}
+			}
+
+			for _, file := range files {
+				changed[serviceConfig.Name][file] = true
+			}
+		}
+
+		if !settled || len(changed) == 0 {
+			continue
+		}
+
+		changes := []SourceChange{}
+		for _, serviceConfig := range w.services {
+			if files, has := changed[serviceConfig.Name]; has {
+				change := SourceChange{Service: serviceConfig}
+				for file := range files {
+					change.Files = append(change.Files, file)
+				}
+
+				slices.Sort(change.Files)
+				changes = append(changes, change)
+			}
+		}
+
+		return changes, nil
+	}
+}
+
+// Gets the modification time and size of the source files of the service, keyed by their path relative to the service.
+// Files excluded by the .gitignore file of the service, and the directories of dependencies and build outputs aren't
+// sources.
+func sourceSnapshot(serviceConfig *ServiceConfig) (map[string]string, error) {
+	root := serviceConfig.Path()
+	rules, err := readIgnoreFile(filepath.Join(root, ".gitignore"), true)
+	if err != nil {
+		return nil, fmt.Errorf("reading .gitignore: %w", err)
+	}
+
+	outputDirs := slices.Clone(watchOutputDirs)
+	if serviceConfig.OutputPath != "" {
+		outputDirs = append(outputDirs, filepath.ToSlash(filepath.Clean(serviceConfig.OutputPath)))
+	}
+
+	snapshot := map[string]string{}
+	err = filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		if entry.IsDir() && (slices.Contains(outputDirs, relativePath) || slices.Contains(watchDependencyDirs, entry.Name())) {
+			return filepath.SkipDir
+		}
+
+		if rules.excludes(relativePath, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		snapshot[relativePath] = fmt.Sprintf("%d:%d", info.ModTime().UnixNano(), info.Size())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("watching sources of service %s: %w", serviceConfig.Name, err)
+	}
+
+	return snapshot, nil
+}
+
+// Gets the paths of the files added, modified or deleted between the snapshots, sorted
+func diffSnapshots(previous map[string]string, current map[string]string) []string {
+	files := []string{}
+	for path, state := range current {
+		if previous[path] != state {
+			files = append(files, path)
+		}
+	}
+
+	for path := range previous {
+		if _, has := current[path]; !has {
+			files = append(files, path)
+		}
+	}
+
+	slices.Sort(files)
+	return files
+}
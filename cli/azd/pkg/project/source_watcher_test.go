@@ -0,0 +1,61 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SourceWatcher(t *testing.T) {
+	projectPath := t.TempDir()
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+
+	serviceConfig := &ServiceConfig{
+		Name:         "api",
+		RelativePath: "src/api",
+		Project:      &ProjectConfig{Path: projectPath},
+	}
+	servicePath := serviceConfig.Path()
+	writeFile(filepath.Join(servicePath, ".gitignore"), "*.log\n")
+	writeFile(filepath.Join(servicePath, "index.js"), "console.log('hello')")
+
+	watcher, err := NewSourceWatcher([]*ServiceConfig{serviceConfig}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	wait := func() ([]SourceChange, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		return watcher.Wait(ctx)
+	}
+
+	// Dependencies, build outputs and files excluded by .gitignore aren't sources
+	writeFile(filepath.Join(servicePath, "node_modules", "express", "index.js"), "module.exports = {}")
+	writeFile(filepath.Join(servicePath, "dist", "index.js"), "console.log('hello')")
+	writeFile(filepath.Join(servicePath, "debug.log"), "started")
+	_, err = wait()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	writeFile(filepath.Join(servicePath, "index.js"), "console.log('hello world')")
+	writeFile(filepath.Join(servicePath, "routes", "users.js"), "module.exports = {}")
+	changes, err := wait()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Same(t, serviceConfig, changes[0].Service)
+	require.Equal(t, []string{"index.js", "routes/users.js"}, changes[0].Files)
+
+	require.NoError(t, os.Remove(filepath.Join(servicePath, "routes", "users.js")))
+	changes, err = wait()
+	require.NoError(t, err)
+	require.Equal(t, []string{"routes/users.js"}, changes[0].Files)
+}
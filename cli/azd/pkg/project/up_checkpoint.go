@@ -0,0 +1,147 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// UpCheckpoint records the progress of the last run of azd up in an environment, so that a failed run can be retried
+// without provisioning the infrastructure and deploying the services again, see azd up --retry-failed
+type UpCheckpoint struct {
+	StartedAt time.Time `json:"startedAt"`
+	// Whether the infrastructure was provisioned by the run
+	Provisioned bool `json:"provisioned"`
+	// The services deployed since the run started, in the order they were deployed
+	Deployed []string `json:"deployed,omitempty"`
+	// Whether the run completed, in which case there's nothing to retry
+	Completed bool `json:"completed"`
+}
+
+// IsDeployed checks whether the service was deployed since the run started
+func (c *UpCheckpoint) IsDeployed(serviceName string) bool {
+	return slices.Contains(c.Deployed, serviceName)
+}
+
+// StartUp records the start of a run of azd up, replacing the checkpoint of the previous run
+func (t *ChangeTracker) StartUp() error {
+	t.deployMutex.Lock()
+	defer t.deployMutex.Unlock()
+
+	state, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	state.Up = &UpCheckpoint{StartedAt: time.Now().UTC()}
+	return t.save(state)
+}
+
+// RecordUpProvisioned records the infrastructure was provisioned by the current run of azd up
+func (t *ChangeTracker) RecordUpProvisioned() error {
+	return t.updateUp(func(checkpoint *UpCheckpoint) {
+		checkpoint.Provisioned = true
+	})
+}
+
+// CompleteUp records the current run of azd up completed
+func (t *ChangeTracker) CompleteUp() error {
+	return t.updateUp(func(checkpoint *UpCheckpoint) {
+		checkpoint.Completed = true
+	})
+}
+
+// UpCheckpoint gets the checkpoint of the last run of azd up, nil when azd up never ran in the environment
+func (t *ChangeTracker) UpCheckpoint() (*UpCheckpoint, error) {
+	t.deployMutex.Lock()
+	defer t.deployMutex.Unlock()
+
+	state, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return state.Up, nil
+}
+
+// VerifyUpCheckpoint gets what a retry of the run of the checkpoint can skip, the infrastructure when it's unchanged
+// since the last provisioning and the services that are unchanged since their last deployment. The infrastructure and
+// the services may have changed since the run failed, or been deployed by azd deploy.
+func (t *ChangeTracker) VerifyUpCheckpoint(
+	ctx context.Context,
+	projectConfig *ProjectConfig,
+	checkpoint *UpCheckpoint,
+) (*UpCheckpoint, error) {
+	verified := &UpCheckpoint{
+		StartedAt: checkpoint.StartedAt,
+		Completed: checkpoint.Completed,
+	}
+
+	if checkpoint.Provisioned {
+		infraChanges, err := t.InfraChanges(projectConfig)
+		if err != nil {
+			return nil, fmt.Errorf("comparing the infrastructure against the last provisioning: %w", err)
+		}
+
+		verified.Provisioned = !infraChanges.HasChanges()
+		if !verified.Provisioned {
+			log.Printf("the infrastructure changed since it was provisioned by azd up, it's provisioned again")
+		}
+	}
+
+	for _, serviceName := range checkpoint.Deployed {
+		serviceConfig, has := projectConfig.Services[serviceName]
+		if !has {
+			continue
+		}
+
+		serviceChanges, err := t.ServiceChanges(ctx, serviceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("comparing service '%s' against its last deployment: %w", serviceName, err)
+		}
+
+		if serviceChanges.HasChanges() {
+			log.Printf("service '%s' changed since it was deployed by azd up, it's deployed again", serviceName)
+			continue
+		}
+
+		verified.Deployed = append(verified.Deployed, serviceName)
+	}
+
+	return verified, nil
+}
+
+// Updates the checkpoint of the current run of azd up, when a run is in progress
+func (t *ChangeTracker) updateUp(update func(checkpoint *UpCheckpoint)) error {
+	t.deployMutex.Lock()
+	defer t.deployMutex.Unlock()
+
+	state, err := t.load()
+	if err != nil {
+		return err
+	}
+
+	if state.Up == nil || state.Up.Completed {
+		return nil
+	}
+
+	update(state.Up)
+	return t.save(state)
+}
+
+// Records the deployment of the service in the checkpoint of the run of azd up in progress, if any. Services deployed
+// by azd deploy while the run is failed are recorded too, a retry of the run compares the services against their last
+// deployment before skipping them.
+func recordUpDeployed(state *DeployState, serviceName string) {
+	if state.Up == nil || state.Up.Completed || state.Up.IsDeployed(serviceName) {
+		return
+	}
+
+	state.Up.Deployed = append(state.Up.Deployed, serviceName)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/git"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChangeTracker_UpCheckpoint(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	tracker := NewChangeTracker(environment.EmptyWithRoot(t.TempDir()), git.NewGitCli(mockContext.CommandRunner))
+
+	checkpoint, err := tracker.UpCheckpoint()
+	require.NoError(t, err)
+	require.Nil(t, checkpoint)
+
+	// Deployments outside of runs of azd up aren't recorded
+	recordDeployed := func(serviceName string) {
+		state, err := tracker.load()
+		require.NoError(t, err)
+		recordUpDeployed(state, serviceName)
+		require.NoError(t, tracker.save(state))
+	}
+	recordDeployed("api")
+
+	require.NoError(t, tracker.StartUp())
+	require.NoError(t, tracker.RecordUpProvisioned())
+	recordDeployed("api")
+	recordDeployed("web")
+	recordDeployed("api")
+
+	checkpoint, err = tracker.UpCheckpoint()
+	require.NoError(t, err)
+	require.True(t, checkpoint.Provisioned)
+	require.False(t, checkpoint.Completed)
+	require.Equal(t, []string{"api", "web"}, checkpoint.Deployed)
+	require.True(t, checkpoint.IsDeployed("web"))
+	require.False(t, checkpoint.IsDeployed("worker"))
+
+	// Completed runs aren't updated anymore
+	require.NoError(t, tracker.CompleteUp())
+	recordDeployed("worker")
+
+	checkpoint, err = tracker.UpCheckpoint()
+	require.NoError(t, err)
+	require.True(t, checkpoint.Completed)
+	require.Equal(t, []string{"api", "web"}, checkpoint.Deployed)
+
+	// A new run replaces the checkpoint of the previous run
+	require.NoError(t, tracker.StartUp())
+	checkpoint, err = tracker.UpCheckpoint()
+	require.NoError(t, err)
+	require.False(t, checkpoint.Provisioned)
+	require.Empty(t, checkpoint.Deployed)
+}
+
+func Test_ChangeTracker_VerifyUpCheckpoint(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "rev-parse HEAD")
+	}).Respond(exec.NewRunResult(0, "0123456789abcdef\n", ""))
+	diff := ""
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "diff --name-status")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(0, diff, ""), nil
+	})
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "ls-files --others")
+	}).Respond(exec.NewRunResult(0, "", ""))
+
+	projectPath := t.TempDir()
+	infraPath := filepath.Join(projectPath, "infra")
+	require.NoError(t, os.MkdirAll(infraPath, osutil.PermissionDirectory))
+	writeFile := func(path string, contents string) {
+		require.NoError(t, os.WriteFile(path, []byte(contents), osutil.PermissionFile))
+	}
+	writeFile(filepath.Join(infraPath, "main.bicep"), "param name string")
+	writeFile(filepath.Join(projectPath, "azure.yaml"), changesTestProject)
+
+	projectConfig, err := Load(*mockContext.Context, filepath.Join(projectPath, "azure.yaml"))
+	require.NoError(t, err)
+
+	tracker := NewChangeTracker(environment.EmptyWithRoot(t.TempDir()), git.NewGitCli(mockContext.CommandRunner))
+	require.NoError(t, tracker.StartUp())
+	require.NoError(t, tracker.RecordProvision(projectConfig))
+	require.NoError(t, tracker.RecordUpProvisioned())
+	require.NoError(t, tracker.RecordDeploy(*mockContext.Context, projectConfig.Services["api"], nil))
+
+	checkpoint, err := tracker.UpCheckpoint()
+	require.NoError(t, err)
+
+	t.Run("Unchanged", func(t *testing.T) {
+		verified, err := tracker.VerifyUpCheckpoint(*mockContext.Context, projectConfig, checkpoint)
+		require.NoError(t, err)
+		require.True(t, verified.Provisioned)
+		require.Equal(t, []string{"api"}, verified.Deployed)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		diff = "M\tmain.py\n"
+		writeFile(filepath.Join(infraPath, "main.bicep"), "param location string")
+
+		verified, err := tracker.VerifyUpCheckpoint(*mockContext.Context, projectConfig, checkpoint)
+		require.NoError(t, err)
+		require.False(t, verified.Provisioned)
+		require.Empty(t, verified.Deployed)
+	})
+}
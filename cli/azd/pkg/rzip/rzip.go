@@ -4,7 +4,10 @@
 package rzip
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
 	"io"
 	"io/fs"
 	"os"
@@ -12,47 +15,138 @@ import (
 	"strings"
 )
 
+// Compression levels accepted by the archive functions, matching the levels of compress/flate
+const (
+	// Stores files without compression
+	NoCompression = flate.NoCompression
+	// Favors speed over archive size
+	BestSpeed = flate.BestSpeed
+	// Favors archive size over speed
+	BestCompression = flate.BestCompression
+	// The default trade-off between speed and archive size
+	DefaultCompression = flate.DefaultCompression
+)
+
+// ExcludeFunc checks whether the file or directory, at the slash separated path relative to the source directory, is
+// left out of an archive. The files of an excluded directory are all left out.
+type ExcludeFunc func(name string, isDir bool) bool
+
 func CreateFromDirectory(source string, buf *os.File) error {
+	return CreateFromDirectoryWithLevel(source, buf, DefaultCompression)
+}
+
+// CreateFromDirectoryWithLevel creates a zip archive of the source directory using the specified compression level.
+// Files are stored without compression when the level is NoCompression.
+func CreateFromDirectoryWithLevel(source string, buf *os.File, level int) error {
 	w := zip.NewWriter(buf)
-	err := filepath.WalkDir(source, func(path string, info fs.DirEntry, err error) error {
+	w.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, level)
+	})
+
+	method := zip.Deflate
+	if level == NoCompression {
+		method = zip.Store
+	}
+
+	err := walkFiles(source, nil, func(path string, name string, fileInfo fs.FileInfo) error {
+		header := &zip.FileHeader{
+			Name:     name,
+			Modified: fileInfo.ModTime(),
+			Method:   method,
+		}
+
+		f, err := w.CreateHeader(header)
 		if err != nil {
 			return err
 		}
 
-		if info.IsDir() {
-			return nil
-		}
-		fileInfo, err := info.Info()
+		return copyFile(f, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+// CreateTarGzFromDirectory creates a gzip compressed tar archive of the source directory using the specified
+// compression level. The files matching exclude, when set, are left out of the archive.
+func CreateTarGzFromDirectory(source string, buf *os.File, level int, exclude ExcludeFunc) error {
+	gw, err := gzip.NewWriterLevel(buf, level)
+	if err != nil {
+		return err
+	}
+
+	w := tar.NewWriter(gw)
+	err = walkFiles(source, exclude, func(path string, name string, fileInfo fs.FileInfo) error {
+		header, err := tar.FileInfoHeader(fileInfo, "")
 		if err != nil {
 			return err
 		}
+		header.Name = name
 
-		header := &zip.FileHeader{
-			Name: strings.Replace(
-				strings.TrimPrefix(
-					strings.TrimPrefix(path, source),
-					string(filepath.Separator)), "\\", "/", -1),
-			Modified: fileInfo.ModTime(),
-			Method:   zip.Deflate,
+		if err := w.WriteHeader(header); err != nil {
+			return err
 		}
 
-		f, err := w.CreateHeader(header)
+		return copyFile(w, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// walkFiles invokes the callback for each file in the source directory, recursively, along with the slash separated
+// path of the file relative to the source directory. The files and directories matching exclude, when set, are skipped.
+func walkFiles(
+	source string,
+	exclude ExcludeFunc,
+	callback func(path string, name string, fileInfo fs.FileInfo) error,
+) error {
+	return filepath.WalkDir(source, func(path string, info fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		in, err := os.Open(path)
-		if err != nil {
-			return err
+
+		name := strings.Replace(
+			strings.TrimPrefix(
+				strings.TrimPrefix(path, source),
+				string(filepath.Separator)), "\\", "/", -1)
+
+		if info.IsDir() {
+			if name != "" && exclude != nil && exclude(name, true) {
+				return filepath.SkipDir
+			}
+
+			return nil
 		}
-		_, err = io.Copy(f, in)
+
+		if exclude != nil && exclude(name, false) {
+			return nil
+		}
+
+		fileInfo, err := info.Info()
 		if err != nil {
 			return err
 		}
-		return nil
+
+		return callback(path, name, fileInfo)
 	})
+}
+
+func copyFile(w io.Writer, path string) error {
+	in, err := os.Open(path)
 	if err != nil {
 		return err
 	}
+	defer in.Close()
 
-	return w.Close()
+	_, err = io.Copy(w, in)
+	return err
 }
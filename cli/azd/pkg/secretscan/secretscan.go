@@ -0,0 +1,225 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package secretscan
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lines containing the marker are not reported, ex) a sample key used by unit tests
+const IgnoreMarker = "azd-secret-scan:ignore"
+
+// Files larger than the limit are not scanned
+const maxFileSize = 5 * 1024 * 1024
+
+// Directories that never contain deployable source, or that are not packaged by azd
+var skipDirs = map[string]struct{}{
+	".git":         {},
+	".azure":       {},
+	"node_modules": {},
+	".venv":        {},
+	"__pycache__":  {},
+}
+
+// Rule detects a kind of credential
+type Rule struct {
+	Id          string
+	Description string
+	Pattern     *regexp.Regexp
+}
+
+// DefaultRules are the credential detectors built into azd
+var DefaultRules = []Rule{
+	{
+		Id:          "azure-storage-account-key",
+		Description: "Azure Storage or Cosmos DB account key",
+		Pattern:     regexp.MustCompile(`(?i)AccountKey=[A-Za-z0-9+/]{86}==`),
+	},
+	{
+		Id:          "azure-shared-access-key",
+		Description: "Azure Service Bus, Event Hubs or IoT Hub shared access key",
+		Pattern:     regexp.MustCompile(`(?i)SharedAccessKey=[A-Za-z0-9+/]{43}=`),
+	},
+	{
+		Id:          "azure-ad-client-secret",
+		Description: "Microsoft Entra ID client secret",
+		Pattern:     regexp.MustCompile(`\b[A-Za-z0-9_~.-]{3}\dQ~[A-Za-z0-9_~.-]{31,34}\b`),
+	},
+	{
+		Id:          "connection-string-password",
+		Description: "Connection string with a password",
+		Pattern:     regexp.MustCompile(`(?i)(?:^|[;"'\s])(?:password|pwd)=[^;$<{%'"\s][^;'"\s]{7,}`),
+	},
+	{
+		Id:          "private-key",
+		Description: "Private key",
+		Pattern:     regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED )?PRIVATE KEY-----`),
+	},
+	{
+		Id:          "aws-access-key-id",
+		Description: "AWS access key id",
+		Pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Id:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	},
+}
+
+// Finding is a potential credential found in a file
+type Finding struct {
+	// The slash separated path of the file, relative to the scanned directory
+	Path string
+	// The 1-based line number
+	Line int
+	Rule Rule
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Rule.Description)
+}
+
+// Options configures a scan
+type Options struct {
+	// Glob patterns of the files excluded from the scan, matched against the slash separated path relative to the
+	// scanned directory. Patterns without a slash are also matched against the file name, and patterns matching a
+	// directory exclude all of the files within the directory
+	Exclude []string
+}
+
+// ScanDirectory scans the files of the directory, recursively, using the default rules.
+// Binary files and files larger than 5 MB are skipped.
+func ScanDirectory(root string, options Options) ([]Finding, error) {
+	for _, pattern := range options.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern '%s': %w", pattern, err)
+		}
+	}
+
+	findings := []Finding{}
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if _, skip := skipDirs[entry.Name()]; skip && filePath != root {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		if excluded(relativePath, options.Exclude) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Size() > maxFileSize {
+			return nil
+		}
+
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filePath, err)
+		}
+
+		findings = append(findings, Scan(relativePath, contents)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+
+		return findings[i].Line < findings[j].Line
+	})
+
+	return findings, nil
+}
+
+// Scan scans the contents of a single file using the default rules. Binary contents are not scanned.
+func Scan(relativePath string, contents []byte) []Finding {
+	if isBinary(contents) {
+		return nil
+	}
+
+	findings := []Finding{}
+	for i, line := range bytes.Split(contents, []byte("\n")) {
+		if bytes.Contains(line, []byte(IgnoreMarker)) {
+			continue
+		}
+
+		for _, rule := range DefaultRules {
+			if rule.Pattern.Match(line) {
+				findings = append(findings, Finding{Path: relativePath, Line: i + 1, Rule: rule})
+			}
+		}
+	}
+
+	return findings
+}
+
+// Files with a NUL byte in the first 8000 bytes are considered binary, the same heuristic used by git
+func isBinary(contents []byte) bool {
+	head := contents
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+func excluded(relativePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Patterns matching a parent directory exclude all of the files within the directory
+		for name := relativePath; name != "."; name = path.Dir(name) {
+			if matchPattern(pattern, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchPattern(pattern string, name string) bool {
+	if matched, _ := path.Match(pattern, name); matched {
+		return true
+	}
+
+	if strings.Contains(pattern, "/") {
+		return false
+	}
+
+	matched, _ := path.Match(pattern, path.Base(name))
+	return matched
+}
@@ -0,0 +1,33 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+// Creates a release annotation on the Application Insights component, ex) the deployment of the services of a project
+func (cli *azCli) CreateAppInsightsAnnotation(
+	ctx context.Context,
+	subscriptionId string,
+	componentId string,
+	annotation azsdk.Annotation,
+) error {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewAnnotationsClient(credential, options)
+	if err != nil {
+		return fmt.Errorf("creating annotations client: %w", err)
+	}
+
+	if err := client.Create(ctx, componentId, annotation); err != nil {
+		return fmt.Errorf("creating annotation on component '%s': %w", componentId, err)
+	}
+
+	return nil
+}
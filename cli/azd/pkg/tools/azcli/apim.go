@@ -56,6 +56,50 @@ func (cli *azCli) PurgeApim(ctx context.Context, subscriptionId string, apimName
 	return nil
 }
 
+// Sets the value of an existing named value of the API Management service, keeping its display name
+func (cli *azCli) SetApimNamedValue(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	apimName string,
+	namedValueId string,
+	value string,
+) error {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	namedValueClient, err := armapimanagement.NewNamedValueClient(subscriptionId, credential, options)
+	if err != nil {
+		return fmt.Errorf("creating NamedValue client: %w", err)
+	}
+
+	poller, err := namedValueClient.BeginUpdate(
+		ctx,
+		resourceGroupName,
+		apimName,
+		namedValueId,
+		"*",
+		armapimanagement.NamedValueUpdateParameters{
+			Properties: &armapimanagement.NamedValueUpdateParameterProperties{
+				Value: &value,
+			},
+		},
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("starting update of named value '%s': %w", namedValueId, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("updating named value '%s': %w", namedValueId, err)
+	}
+
+	return nil
+}
+
 // Creates a APIM soft-deleted service client for ARM control plane operations
 func (cli *azCli) createApimDeletedClient(
 	ctx context.Context,
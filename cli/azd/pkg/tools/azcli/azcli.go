@@ -71,6 +71,14 @@ type AzCli interface {
 	GetApim(
 		ctx context.Context, subscriptionId string, resourceGroupName string, apimName string) (*AzCliApim, error)
 	PurgeApim(ctx context.Context, subscriptionId string, apimName string, location string) error
+	SetApimNamedValue(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		apimName string,
+		namedValueId string,
+		value string,
+	) error
 	DeployAppServiceZip(
 		ctx context.Context,
 		subscriptionId string,
@@ -78,6 +86,13 @@ type AzCli interface {
 		appName string,
 		deployZipFile io.Reader,
 	) (*string, error)
+	DeployAppServiceZipDelta(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroup string,
+		appName string,
+		zipFilePath string,
+	) (*string, error)
 	DeployFunctionAppUsingZipFile(
 		ctx context.Context,
 		subscriptionID string,
@@ -151,6 +166,46 @@ type AzCli interface {
 		resourceGroupName string,
 		applicationName string,
 	) (*AzCliContainerAppProperties, error)
+	RestartContainerApp(ctx context.Context, subscriptionId string, resourceGroupName string, appName string) error
+	RestartAppService(ctx context.Context, subscriptionId string, resourceGroupName string, appName string) error
+	// Scales the container app to a fixed number of replicas
+	ScaleContainerApp(ctx context.Context, subscriptionId string, resourceGroupName string, appName string, replicas int) error
+	// Scales the app service plan of the app service or function app to a number of instances. Plans hosting other apps
+	// are only scaled when force is set, otherwise a SharedAppServicePlanError is returned.
+	ScaleAppService(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		appName string,
+		instances int,
+		force bool,
+	) error
+	GetScaleSetInstanceHosts(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		scaleSetName string,
+	) ([]string, error)
+	PurgeCdnEndpoint(
+		ctx context.Context,
+		subscriptionId string,
+		resourceGroupName string,
+		profileName string,
+		endpointName string,
+		contentPaths []string,
+	) error
+	GetResourceMetric(
+		ctx context.Context,
+		subscriptionId string,
+		resourceId string,
+		query azsdk.MetricsQuery,
+	) (*azsdk.Metric, error)
+	CreateAppInsightsAnnotation(
+		ctx context.Context,
+		subscriptionId string,
+		componentId string,
+		annotation azsdk.Annotation,
+	) error
 	GetStaticWebAppProperties(
 		ctx context.Context,
 		subscriptionID string,
@@ -0,0 +1,142 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// BatchService provides access to submit and monitor Azure Batch jobs & tasks
+type BatchService interface {
+	// Ensures the job exists within the batch account and adds the task to the job
+	SubmitTask(
+		ctx context.Context,
+		subscriptionId string,
+		accountEndpoint string,
+		job *azsdk.BatchJob,
+		task *azsdk.BatchTask,
+	) error
+	// Gets the current state of the specified task
+	GetTask(
+		ctx context.Context,
+		subscriptionId string,
+		accountEndpoint string,
+		jobId string,
+		taskId string,
+	) (*azsdk.BatchTask, error)
+	// Gets the content of a file written by the task, ex) stdout.txt, starting at the byte offset
+	GetTaskOutput(
+		ctx context.Context,
+		subscriptionId string,
+		accountEndpoint string,
+		jobId string,
+		taskId string,
+		fileName string,
+		offset int64,
+	) ([]byte, error)
+}
+
+type batchService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	userAgent          string
+}
+
+// Creates a new instance of the BatchService
+func NewBatchService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+) BatchService {
+	return &batchService{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		userAgent:          azdinternal.MakeUserAgentString(""),
+	}
+}
+
+// Ensures the job exists within the batch account and adds the task to the job
+func (bs *batchService) SubmitTask(
+	ctx context.Context,
+	subscriptionId string,
+	accountEndpoint string,
+	job *azsdk.BatchJob,
+	task *azsdk.BatchTask,
+) error {
+	client, err := bs.createBatchClient(ctx, subscriptionId, accountEndpoint)
+	if err != nil {
+		return err
+	}
+
+	if err := client.EnsureJob(ctx, job); err != nil {
+		return fmt.Errorf("creating batch job '%s': %w", job.Id, err)
+	}
+
+	if err := client.AddTask(ctx, job.Id, task); err != nil {
+		return fmt.Errorf("adding task '%s' to batch job '%s': %w", task.Id, job.Id, err)
+	}
+
+	return nil
+}
+
+// Gets the current state of the specified task
+func (bs *batchService) GetTask(
+	ctx context.Context,
+	subscriptionId string,
+	accountEndpoint string,
+	jobId string,
+	taskId string,
+) (*azsdk.BatchTask, error) {
+	client, err := bs.createBatchClient(ctx, subscriptionId, accountEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := client.GetTask(ctx, jobId, taskId)
+	if err != nil {
+		return nil, fmt.Errorf("getting batch task '%s': %w", taskId, err)
+	}
+
+	return task, nil
+}
+
+// Gets the content of a file written by the task, ex) stdout.txt, starting at the byte offset
+func (bs *batchService) GetTaskOutput(
+	ctx context.Context,
+	subscriptionId string,
+	accountEndpoint string,
+	jobId string,
+	taskId string,
+	fileName string,
+	offset int64,
+) ([]byte, error) {
+	client, err := bs.createBatchClient(ctx, subscriptionId, accountEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := client.GetTaskFile(ctx, jobId, taskId, fileName, offset)
+	if err != nil {
+		return nil, fmt.Errorf("getting output '%s' of batch task '%s': %w", fileName, taskId, err)
+	}
+
+	return content, nil
+}
+
+func (bs *batchService) createBatchClient(
+	ctx context.Context,
+	subscriptionId string,
+	accountEndpoint string,
+) (*azsdk.BatchClient, error) {
+	credential, err := bs.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(bs.httpClient, bs.userAgent).BuildCoreClientOptions()
+
+	return azsdk.NewBatchClient(accountEndpoint, credential, options), nil
+}
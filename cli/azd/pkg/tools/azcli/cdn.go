@@ -0,0 +1,35 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+// Purges the content paths cached by the Azure Front Door or Azure CDN endpoint of the profile, ex) /*
+func (cli *azCli) PurgeCdnEndpoint(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	profileName string,
+	endpointName string,
+	contentPaths []string,
+) error {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewCdnClient(subscriptionId, credential, options)
+	if err != nil {
+		return fmt.Errorf("creating CDN client: %w", err)
+	}
+
+	if err := client.Purge(ctx, resourceGroupName, profileName, endpointName, contentPaths); err != nil {
+		return fmt.Errorf("purging endpoint '%s' of profile '%s': %w", endpointName, profileName, err)
+	}
+
+	return nil
+}
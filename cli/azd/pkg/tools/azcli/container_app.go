@@ -5,10 +5,15 @@ import (
 	"fmt"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appcontainers/armappcontainers"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 )
 
 type AzCliContainerAppProperties struct {
 	HostNames []string
+	// The port of the container the ingress routes requests to, or 0 when the container app has no ingress
+	TargetPort int
+	// The transport of the ingress, ex) auto, http, http2 or tcp
+	Transport string
 }
 
 func (cli *azCli) GetContainerAppProperties(
@@ -25,19 +30,111 @@ func (cli *azCli) GetContainerAppProperties(
 		return nil, fmt.Errorf("failed retrieving container app properties: %w", err)
 	}
 
-	var hostNames []string
+	properties := &AzCliContainerAppProperties{
+		HostNames: []string{},
+	}
 	if containerApp.Properties != nil &&
 		containerApp.Properties.Configuration != nil &&
-		containerApp.Properties.Configuration.Ingress != nil &&
-		containerApp.Properties.Configuration.Ingress.Fqdn != nil {
-		hostNames = []string{*containerApp.Properties.Configuration.Ingress.Fqdn}
-	} else {
-		hostNames = []string{}
+		containerApp.Properties.Configuration.Ingress != nil {
+		ingress := containerApp.Properties.Configuration.Ingress
+		if ingress.Fqdn != nil {
+			properties.HostNames = []string{*ingress.Fqdn}
+		}
+
+		if ingress.TargetPort != nil {
+			properties.TargetPort = int(*ingress.TargetPort)
+		}
+
+		if ingress.Transport != nil {
+			properties.Transport = string(*ingress.Transport)
+		}
+	}
+
+	return properties, nil
+}
+
+// Restarts the latest revision of the container app
+func (cli *azCli) RestartContainerApp(
+	ctx context.Context,
+	subscriptionId, resourceGroup, appName string,
+) error {
+	client, err := cli.createContainerAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	containerApp, err := client.Get(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("failed retrieving container app properties: %w", err)
+	}
+
+	if containerApp.Properties == nil || containerApp.Properties.LatestRevisionName == nil {
+		return fmt.Errorf("container app '%s' has no revision", appName)
+	}
+
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	revisionsClient, err := armappcontainers.NewContainerAppsRevisionsClient(subscriptionId, credential, options)
+	if err != nil {
+		return fmt.Errorf("creating ContainerAppsRevisions client: %w", err)
+	}
+
+	revisionName := *containerApp.Properties.LatestRevisionName
+	if _, err := revisionsClient.RestartRevision(ctx, resourceGroup, appName, revisionName, nil); err != nil {
+		return fmt.Errorf("restarting revision '%s' of container app '%s': %w", revisionName, appName, err)
+	}
+
+	return nil
+}
+
+// Scales the container app to a fixed number of replicas, setting both the minimum and the maximum replicas of its scale
+func (cli *azCli) ScaleContainerApp(
+	ctx context.Context,
+	subscriptionId, resourceGroup, appName string,
+	replicas int,
+) error {
+	client, err := cli.createContainerAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	containerApp, err := client.Get(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("failed retrieving container app properties: %w", err)
+	}
+
+	if containerApp.Properties == nil || containerApp.Properties.Template == nil {
+		return fmt.Errorf("container app '%s' has no template", appName)
+	}
+
+	template := containerApp.Properties.Template
+	if template.Scale == nil {
+		template.Scale = &armappcontainers.Scale{}
+	}
+
+	template.Scale.MinReplicas = convert.RefOf(int32(replicas))
+	template.Scale.MaxReplicas = convert.RefOf(int32(replicas))
+
+	// Only the template is updated, the configuration of the container app, ex) its secrets, is left as is
+	poller, err := client.BeginUpdate(ctx, resourceGroup, appName, armappcontainers.ContainerApp{
+		Location: containerApp.Location,
+		Properties: &armappcontainers.ContainerAppProperties{
+			Template: template,
+		},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("starting scale of container app '%s': %w", appName, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("scaling container app '%s': %w", appName, err)
 	}
 
-	return &AzCliContainerAppProperties{
-		HostNames: hostNames,
-	}, nil
+	return nil
 }
 
 func (cli *azCli) createContainerAppsClient(
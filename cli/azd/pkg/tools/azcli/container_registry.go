@@ -3,23 +3,96 @@ package azcli
 import (
 	"context"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
 	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
 	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
 	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/rzip"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools/docker"
 	"golang.org/x/exp/slices"
 )
 
+// The interval between the checks of the status of a remote image build
+const remoteBuildPollInterval = 5 * time.Second
+
 // ContainerRegistryService provides access to query and login to Azure Container Registries (ACR)
 type ContainerRegistryService interface {
 	// Logs into the specified container registry
 	LoginAcr(ctx context.Context, subscriptionId string, loginServer string) error
+	// Gets the admin credentials for the specified container registry
+	Credentials(ctx context.Context, subscriptionId string, loginServer string) (*DockerCredentials, error)
 	// Gets a list of container registries for the specified subscription
 	GetContainerRegistries(ctx context.Context, subscriptionId string) ([]*armcontainerregistry.Registry, error)
+	// Gets the descriptor of the image manifest for the specified tag or digest
+	GetManifest(
+		ctx context.Context,
+		subscriptionId string,
+		loginServer string,
+		repository string,
+		reference string,
+	) (*azsdk.OciDescriptor, error)
+	// Attaches the content to the subject image as an OCI referrer artifact of the specified artifact type
+	AttachArtifact(
+		ctx context.Context,
+		subscriptionId string,
+		loginServer string,
+		repository string,
+		subject *azsdk.OciDescriptor,
+		artifactType string,
+		content []byte,
+	) (*azsdk.OciDescriptor, error)
+	// Lists the tags of the repository of the container registry, with the digests of the manifests they reference
+	ListTags(ctx context.Context, subscriptionId string, loginServer string, repository string) ([]*RegistryTag, error)
+	// Deletes the image manifest with the digest from the repository, along with all the tags referencing the manifest
+	DeleteManifest(ctx context.Context, subscriptionId string, loginServer string, repository string, digest string) error
+	// Gets the locations of the replicas of a geo-replicated container registry that serve requests
+	GetReplicationLocations(ctx context.Context, subscriptionId string, loginServer string) ([]string, error)
+	// Builds an image remotely with ACR Tasks and pushes it to the specified container registry
+	BuildImage(ctx context.Context, subscriptionId string, loginServer string, options RemoteBuildOptions) error
+}
+
+// RemoteBuildOptions are the options of an image built remotely with ACR Tasks
+type RemoteBuildOptions struct {
+	// The directory of the build context, uploaded as the source of the build
+	ContextPath string
+	// The slash separated path of the Dockerfile, relative to the build context
+	DockerfilePath string
+	// The platform of the image, ex) linux/amd64 or arm64. Defaults to linux/amd64
+	Platform string
+	// The target stage of a multi-stage Dockerfile
+	Target string
+	// The build arguments, as KEY=VALUE pairs
+	BuildArgs []string
+	// When set, the image is built without the layer cache of the registry
+	NoCache bool
+	// The names of the images pushed to the registry, relative to the login server, ex) app/api:azd-deploy-0
+	ImageNames []string
+	// Leaves files of the build context out of the uploaded source, ex) the files excluded by the .dockerignore file
+	Exclude rzip.ExcludeFunc
+}
+
+// RegistryTag is a tag of a repository of a container registry
+type RegistryTag struct {
+	Name string
+	// The digest of the manifest referenced by the tag, ex) sha256:{hex}
+	Digest string
+}
+
+// DockerCredentials are the username & password used to authenticate against a container registry
+type DockerCredentials struct {
+	Username    string
+	Password    string
+	LoginServer string
 }
 
 type containerRegistryService struct {
@@ -71,37 +144,447 @@ func (crs *containerRegistryService) GetContainerRegistries(
 // Logs into the specified container registry
 func (crs *containerRegistryService) LoginAcr(ctx context.Context, subscriptionId string, loginServer string,
 ) error {
-	client, err := crs.createRegistriesClient(ctx, subscriptionId)
+	dockerCreds, err := crs.Credentials(ctx, subscriptionId, loginServer)
 	if err != nil {
 		return err
 	}
 
+	username := dockerCreds.Username
+
+	// Login to docker with ACR credentials to allow push operations
+	err = crs.docker.Login(ctx, loginServer, username, dockerCreds.Password)
+	if err != nil {
+		return fmt.Errorf("failed logging into docker for username '%s' and server %s: %w", loginServer, username, err)
+	}
+
+	return nil
+}
+
+// Gets the admin credentials for the specified container registry
+func (crs *containerRegistryService) Credentials(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*DockerCredentials, error) {
+	client, err := crs.createRegistriesClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
 	parts := strings.Split(loginServer, ".")
 	registryName := parts[0]
 
 	// Find the registry and resource group
 	_, resourceGroup, err := crs.findContainerRegistryByName(ctx, subscriptionId, registryName)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Retrieve the registry credentials
 	credResponse, err := client.ListCredentials(ctx, resourceGroup, registryName, nil)
 	if err != nil {
-		return fmt.Errorf("getting container registry credentials: %w", err)
+		return nil, fmt.Errorf("getting container registry credentials: %w", err)
 	}
 
-	username := *credResponse.Username
+	return &DockerCredentials{
+		Username:    *credResponse.Username,
+		Password:    *credResponse.Passwords[0].Value,
+		LoginServer: loginServer,
+	}, nil
+}
 
-	// Login to docker with ACR credentials to allow push operations
-	err = crs.docker.Login(ctx, loginServer, username, *credResponse.Passwords[0].Value)
+// Gets the descriptor of the image manifest for the specified tag or digest
+func (crs *containerRegistryService) GetManifest(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	reference string,
+) (*azsdk.OciDescriptor, error) {
+	client, err := crs.createRegistryClient(ctx, subscriptionId, loginServer)
 	if err != nil {
-		return fmt.Errorf("failed logging into docker for username '%s' and server %s: %w", loginServer, username, err)
+		return nil, err
+	}
+
+	descriptor, err := client.GetManifestDescriptor(ctx, repository, reference)
+	if err != nil {
+		return nil, fmt.Errorf("getting manifest for '%s/%s:%s': %w", loginServer, repository, reference, err)
+	}
+
+	return descriptor, nil
+}
+
+// Attaches the content to the subject image as an OCI referrer artifact of the specified artifact type.
+// The content is stored as the single layer of an artifact manifest whose subject is the image manifest.
+func (crs *containerRegistryService) AttachArtifact(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	subject *azsdk.OciDescriptor,
+	artifactType string,
+	content []byte,
+) (*azsdk.OciDescriptor, error) {
+	client, err := crs.createRegistryClient(ctx, subscriptionId, loginServer)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := client.UploadBlob(ctx, repository, azsdk.OciEmptyConfigMediaType, []byte("{}"))
+	if err != nil {
+		return nil, fmt.Errorf("uploading artifact config: %w", err)
+	}
+
+	layer, err := client.UploadBlob(ctx, repository, artifactType, content)
+	if err != nil {
+		return nil, fmt.Errorf("uploading artifact: %w", err)
+	}
+
+	manifest := &azsdk.OciManifest{
+		SchemaVersion: 2,
+		MediaType:     azsdk.OciImageManifestMediaType,
+		ArtifactType:  artifactType,
+		Config:        *config,
+		Layers:        []azsdk.OciDescriptor{*layer},
+		Subject: &azsdk.OciDescriptor{
+			MediaType: subject.MediaType,
+			Digest:    subject.Digest,
+			Size:      subject.Size,
+		},
+	}
+
+	descriptor, err := client.PutManifest(ctx, repository, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("pushing artifact manifest: %w", err)
+	}
+
+	return descriptor, nil
+}
+
+// Lists the tags of the repository of the container registry, with the digests of the manifests they reference
+func (crs *containerRegistryService) ListTags(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+) ([]*RegistryTag, error) {
+	client, err := crs.createRegistryClient(ctx, subscriptionId, loginServer)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := client.ListTags(ctx, repository)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags of '%s/%s': %w", loginServer, repository, err)
+	}
+
+	tags := make([]*RegistryTag, 0, len(names))
+	for _, name := range names {
+		descriptor, err := client.GetManifestDescriptor(ctx, repository, name)
+		if err != nil {
+			return nil, fmt.Errorf("getting manifest for '%s/%s:%s': %w", loginServer, repository, name, err)
+		}
+
+		tags = append(tags, &RegistryTag{Name: name, Digest: descriptor.Digest})
+	}
+
+	return tags, nil
+}
+
+// Deletes the image manifest with the digest from the repository, along with all the tags referencing the manifest
+func (crs *containerRegistryService) DeleteManifest(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	repository string,
+	digest string,
+) error {
+	client, err := crs.createRegistryClient(ctx, subscriptionId, loginServer)
+	if err != nil {
+		return err
+	}
+
+	if err := client.DeleteManifest(ctx, repository, digest); err != nil {
+		return fmt.Errorf("deleting manifest '%s/%s@%s': %w", loginServer, repository, digest, err)
 	}
 
 	return nil
 }
 
+// Gets the locations of the replicas of a geo-replicated container registry that serve requests.
+// Replicas with a disabled regional endpoint are excluded since requests are not routed to them.
+func (crs *containerRegistryService) GetReplicationLocations(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) ([]string, error) {
+	registryName := strings.Split(loginServer, ".")[0]
+	_, resourceGroup, err := crs.findContainerRegistryByName(ctx, subscriptionId, registryName)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := crs.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(crs.httpClient, crs.userAgent).BuildArmClientOptions()
+	client, err := armcontainerregistry.NewReplicationsClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating replications client: %w", err)
+	}
+
+	locations := []string{}
+	pager := client.NewListPager(resourceGroup, registryName, nil)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting next page of replications: %w", err)
+		}
+
+		for _, replication := range page.ReplicationListResult.Value {
+			if replication.Location == nil {
+				continue
+			}
+
+			if replication.Properties != nil &&
+				replication.Properties.RegionEndpointEnabled != nil &&
+				!*replication.Properties.RegionEndpointEnabled {
+				continue
+			}
+
+			locations = append(locations, *replication.Location)
+		}
+	}
+
+	return locations, nil
+}
+
+// Builds an image remotely with ACR Tasks and pushes it to the specified container registry.
+// The build context is uploaded to the registry and the build runs on the agents of the registry, which doesn't require a
+// local docker daemon.
+func (crs *containerRegistryService) BuildImage(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+	options RemoteBuildOptions,
+) error {
+	registryName := strings.Split(loginServer, ".")[0]
+	_, resourceGroup, err := crs.findContainerRegistryByName(ctx, subscriptionId, registryName)
+	if err != nil {
+		return err
+	}
+
+	platform, err := parseRemoteBuildPlatform(options.Platform)
+	if err != nil {
+		return err
+	}
+
+	client, err := crs.createRegistriesClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	uploadResponse, err := client.GetBuildSourceUploadURL(ctx, resourceGroup, registryName, nil)
+	if err != nil {
+		return fmt.Errorf("getting build source upload url: %w", err)
+	}
+
+	if err := crs.uploadBuildSource(ctx, *uploadResponse.UploadURL, options.ContextPath, options.Exclude); err != nil {
+		return fmt.Errorf("uploading build source: %w", err)
+	}
+
+	request := &armcontainerregistry.DockerBuildRequest{
+		Type:           convert.RefOf("DockerBuildRequest"),
+		SourceLocation: uploadResponse.RelativePath,
+		DockerFilePath: convert.RefOf(options.DockerfilePath),
+		Platform:       platform,
+		IsPushEnabled:  convert.RefOf(true),
+		ImageNames:     []*string{},
+		Arguments:      []*armcontainerregistry.Argument{},
+	}
+
+	if options.Target != "" {
+		request.Target = convert.RefOf(options.Target)
+	}
+
+	if options.NoCache {
+		request.NoCache = convert.RefOf(true)
+	}
+
+	for _, imageName := range options.ImageNames {
+		request.ImageNames = append(request.ImageNames, convert.RefOf(imageName))
+	}
+
+	for _, buildArg := range options.BuildArgs {
+		name, value, _ := strings.Cut(buildArg, "=")
+		request.Arguments = append(request.Arguments, &armcontainerregistry.Argument{
+			Name:     convert.RefOf(name),
+			Value:    convert.RefOf(value),
+			IsSecret: convert.RefOf(false),
+		})
+	}
+
+	poller, err := client.BeginScheduleRun(ctx, resourceGroup, registryName, request, nil)
+	if err != nil {
+		return fmt.Errorf("scheduling remote build: %w", err)
+	}
+
+	scheduled, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("scheduling remote build: %w", err)
+	}
+
+	runId := *scheduled.Properties.RunID
+	log.Printf("scheduled remote build %s on registry %s", runId, loginServer)
+
+	return crs.waitForRun(ctx, subscriptionId, resourceGroup, registryName, runId)
+}
+
+// Waits for the ACR Tasks run to complete, returning an error with the link to the logs of the run when it fails
+func (crs *containerRegistryService) waitForRun(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	registryName string,
+	runId string,
+) error {
+	credential, err := crs.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	options := clientOptionsBuilder(crs.httpClient, crs.userAgent).BuildArmClientOptions()
+	client, err := armcontainerregistry.NewRunsClient(subscriptionId, credential, options)
+	if err != nil {
+		return fmt.Errorf("creating runs client: %w", err)
+	}
+
+	for {
+		run, err := client.Get(ctx, resourceGroup, registryName, runId, nil)
+		if err != nil {
+			return fmt.Errorf("getting status of remote build %s: %w", runId, err)
+		}
+
+		status := armcontainerregistry.RunStatusQueued
+		if run.Properties != nil && run.Properties.Status != nil {
+			status = *run.Properties.Status
+		}
+
+		switch status {
+		case armcontainerregistry.RunStatusSucceeded:
+			return nil
+		case armcontainerregistry.RunStatusFailed,
+			armcontainerregistry.RunStatusCanceled,
+			armcontainerregistry.RunStatusError,
+			armcontainerregistry.RunStatusTimeout:
+			logs := "unavailable"
+			if logResponse, err := client.GetLogSasURL(ctx, resourceGroup, registryName, runId, nil); err != nil {
+				log.Printf("failed getting logs of remote build %s: %v", runId, err)
+			} else if logResponse.LogLink != nil {
+				logs = *logResponse.LogLink
+			}
+
+			return fmt.Errorf("remote build %s completed with status '%s', logs: %s", runId, status, logs)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(remoteBuildPollInterval):
+		}
+	}
+}
+
+// Uploads the build context as a gzip compressed tar archive to the upload url of the registry
+func (crs *containerRegistryService) uploadBuildSource(
+	ctx context.Context,
+	uploadUrl string,
+	contextPath string,
+	exclude rzip.ExcludeFunc,
+) error {
+	archive, err := os.CreateTemp("", "azd-build-source-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = archive.Close()
+		_ = os.Remove(archive.Name())
+	}()
+
+	if err := rzip.CreateTarGzFromDirectory(contextPath, archive, rzip.DefaultCompression, exclude); err != nil {
+		return fmt.Errorf("archiving build context: %w", err)
+	}
+
+	size, err := archive.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadUrl, archive)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+
+	res, err := crs.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// Parses a docker platform, ex) linux/arm64/v8 or amd64, into the platform of an ACR Tasks run
+func parseRemoteBuildPlatform(platform string) (*armcontainerregistry.PlatformProperties, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(platform)), "/")
+	if len(parts) == 1 {
+		parts = append([]string{"linux"}, parts...)
+	}
+
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("unsupported platform '%s' for remote builds", platform)
+	}
+
+	var osType armcontainerregistry.OS
+	switch parts[0] {
+	case "linux":
+		osType = armcontainerregistry.OSLinux
+	case "windows":
+		osType = armcontainerregistry.OSWindows
+	default:
+		return nil, fmt.Errorf("unsupported platform '%s' for remote builds", platform)
+	}
+
+	architecture := armcontainerregistry.ArchitectureAmd64
+	if parts[1] != "" {
+		architecture = armcontainerregistry.Architecture(parts[1])
+	}
+
+	properties := &armcontainerregistry.PlatformProperties{
+		OS:           &osType,
+		Architecture: &architecture,
+	}
+
+	if len(parts) == 3 {
+		properties.Variant = convert.RefOf(armcontainerregistry.Variant(parts[2]))
+	}
+
+	return properties, nil
+}
+
 func (crs *containerRegistryService) findContainerRegistryByName(
 	ctx context.Context,
 	subscriptionId string,
@@ -150,3 +633,18 @@ func (crs *containerRegistryService) createRegistriesClient(
 
 	return client, nil
 }
+
+func (crs *containerRegistryService) createRegistryClient(
+	ctx context.Context,
+	subscriptionId string,
+	loginServer string,
+) (*azsdk.RegistryClient, error) {
+	dockerCreds, err := crs.Credentials(ctx, subscriptionId, loginServer)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(crs.httpClient, crs.userAgent).BuildCoreClientOptions()
+
+	return azsdk.NewRegistryClient(loginServer, dockerCreds.Username, dockerCreds.Password, options), nil
+}
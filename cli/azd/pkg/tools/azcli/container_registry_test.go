@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRemoteBuildPlatform(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		expected *armcontainerregistry.PlatformProperties
+	}{
+		{
+			name:     "Default",
+			platform: "",
+			expected: &armcontainerregistry.PlatformProperties{
+				OS:           convert.RefOf(armcontainerregistry.OSLinux),
+				Architecture: convert.RefOf(armcontainerregistry.ArchitectureAmd64),
+			},
+		},
+		{
+			name:     "ArchitectureOnly",
+			platform: "arm64",
+			expected: &armcontainerregistry.PlatformProperties{
+				OS:           convert.RefOf(armcontainerregistry.OSLinux),
+				Architecture: convert.RefOf(armcontainerregistry.ArchitectureArm64),
+			},
+		},
+		{
+			name:     "WithVariant",
+			platform: "linux/arm64/v8",
+			expected: &armcontainerregistry.PlatformProperties{
+				OS:           convert.RefOf(armcontainerregistry.OSLinux),
+				Architecture: convert.RefOf(armcontainerregistry.ArchitectureArm64),
+				Variant:      convert.RefOf(armcontainerregistry.VariantV8),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			platform, err := parseRemoteBuildPlatform(tt.platform)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, platform)
+		})
+	}
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := parseRemoteBuildPlatform("darwin/arm64")
+		require.ErrorContains(t, err, "unsupported platform 'darwin/arm64'")
+	})
+}
@@ -0,0 +1,271 @@
+package azcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"golang.org/x/exp/slices"
+)
+
+// DataStoreKind is the kind of a data store whose content can be copied
+type DataStoreKind string
+
+const (
+	DataStoreKindBlob   DataStoreKind = "Blob Storage"
+	DataStoreKindCosmos DataStoreKind = "Cosmos DB"
+)
+
+// DataStorePair is a data store of a source environment and the matching data store of a target environment, found from
+// the endpoints of the data stores written to the environments by the same output of the infrastructure
+type DataStorePair struct {
+	Kind DataStoreKind
+	// The name of the output of the infrastructure with the endpoints of the data stores
+	Name           string
+	SourceEndpoint string
+	TargetEndpoint string
+}
+
+// DataCopyResult is the result of copying the content of a data store
+type DataCopyResult struct {
+	// The number of blobs or documents copied
+	Copied int
+	// The blobs or containers which weren't copied, with the reason they were skipped
+	Skipped []string
+}
+
+// DataCopyService copies the data-plane content of data stores, ex) the blobs of storage accounts, using the identity of
+// the user. The user requires data-plane roles on both data stores, ex) Storage Blob Data Contributor.
+type DataCopyService interface {
+	// Copies the blob containers of the source storage account to the target storage account, creating the missing
+	// containers. Only block blobs are copied.
+	CopyBlobContainers(
+		ctx context.Context,
+		subscriptionId string,
+		sourceEndpoint string,
+		targetEndpoint string,
+	) (*DataCopyResult, error)
+	// Copies the documents of the containers of the source Cosmos DB account to the containers with the same names of the
+	// target account, from the change feed of the containers. The containers of the target account aren't created, since
+	// they are created by the infrastructure.
+	CopyCosmosContainers(
+		ctx context.Context,
+		subscriptionId string,
+		sourceEndpoint string,
+		targetEndpoint string,
+	) (*DataCopyResult, error)
+}
+
+type dataCopyService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	userAgent          string
+}
+
+// Creates a new instance of the DataCopyService
+func NewDataCopyService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+) DataCopyService {
+	return &dataCopyService{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		userAgent:          azdinternal.MakeUserAgentString(""),
+	}
+}
+
+// Copies the blob containers of the source storage account to the target storage account
+func (ds *dataCopyService) CopyBlobContainers(
+	ctx context.Context,
+	subscriptionId string,
+	sourceEndpoint string,
+	targetEndpoint string,
+) (*DataCopyResult, error) {
+	credential, err := ds.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(ds.httpClient, ds.userAgent).BuildCoreClientOptions()
+	source := azsdk.NewBlobClient(sourceEndpoint, credential, options)
+	target := azsdk.NewBlobClient(targetEndpoint, credential, options)
+
+	containers, err := source.ListContainers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing containers of '%s': %w", sourceEndpoint, err)
+	}
+
+	result := &DataCopyResult{}
+	for _, container := range containers {
+		if err := target.CreateContainer(ctx, container.Name); err != nil {
+			return nil, fmt.Errorf("creating container '%s' in '%s': %w", container.Name, targetEndpoint, err)
+		}
+
+		blobs, err := source.ListBlobs(ctx, container.Name)
+		if err != nil {
+			return nil, fmt.Errorf("listing blobs of container '%s': %w", container.Name, err)
+		}
+
+		for _, blob := range blobs {
+			if blob.Properties.BlobType != azsdk.BlockBlobType {
+				result.Skipped = append(
+					result.Skipped,
+					fmt.Sprintf("%s/%s (%s isn't supported)", container.Name, blob.Name, blob.Properties.BlobType),
+				)
+				continue
+			}
+
+			// The source is authorized per request, since the token may expire while large accounts are copied
+			token, err := credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{azsdk.StorageScope}})
+			if err != nil {
+				return nil, fmt.Errorf("getting storage token: %w", err)
+			}
+
+			sourceUrl := source.BlobUrl(container.Name, blob.Name)
+			if err := target.CopyBlobFromUrl(ctx, container.Name, blob.Name, sourceUrl, token.Token); err != nil {
+				return nil, fmt.Errorf("copying blob '%s' of container '%s': %w", blob.Name, container.Name, err)
+			}
+
+			result.Copied++
+		}
+	}
+
+	return result, nil
+}
+
+// Copies the documents of the containers of the source Cosmos DB account to the target account
+func (ds *dataCopyService) CopyCosmosContainers(
+	ctx context.Context,
+	subscriptionId string,
+	sourceEndpoint string,
+	targetEndpoint string,
+) (*DataCopyResult, error) {
+	credential, err := ds.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(ds.httpClient, ds.userAgent).BuildCoreClientOptions()
+	source, err := azsdk.NewCosmosDataClient(sourceEndpoint, credential, options)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := azsdk.NewCosmosDataClient(targetEndpoint, credential, options)
+	if err != nil {
+		return nil, err
+	}
+
+	databases, err := source.ListDatabases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing databases of '%s': %w", sourceEndpoint, err)
+	}
+
+	result := &DataCopyResult{}
+	for _, database := range databases {
+		containers, err := source.ListContainers(ctx, database.Id)
+		if err != nil {
+			return nil, fmt.Errorf("listing containers of database '%s': %w", database.Id, err)
+		}
+
+		for _, container := range containers {
+			targetContainer, err := target.GetContainer(ctx, database.Id, container.Id)
+			var responseErr *azcore.ResponseError
+			if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+				result.Skipped = append(
+					result.Skipped,
+					fmt.Sprintf("%s/%s (the container doesn't exist in '%s')", database.Id, container.Id, targetEndpoint),
+				)
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("getting container '%s' of database '%s': %w", container.Id, database.Id, err)
+			}
+
+			err = source.ReadChangeFeed(ctx, database.Id, container.Id, func(documents []azsdk.CosmosDocument) error {
+				for _, document := range documents {
+					if err := target.UpsertDocument(ctx, database.Id, targetContainer, document); err != nil {
+						return fmt.Errorf("copying document '%v': %w", document["id"], err)
+					}
+
+					result.Copied++
+				}
+
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("copying container '%s' of database '%s': %w", container.Id, database.Id, err)
+			}
+
+			log.Printf("copied container '%s' of database '%s' to '%s'", container.Id, database.Id, targetEndpoint)
+		}
+	}
+
+	return result, nil
+}
+
+// FindDataStorePairs finds the data stores of the source environment whose content can be copied to the target environment,
+// from the values of the outputs of the infrastructure written to both environments with the endpoints of data stores,
+// ex) AZURE_STORAGE_BLOB_ENDPOINT or AZURE_COSMOS_ENDPOINT. Outputs with the same endpoint in both environments, ex) a
+// shared data store, are ignored.
+func FindDataStorePairs(
+	outputNames []string,
+	sourceValues map[string]string,
+	targetValues map[string]string,
+) []DataStorePair {
+	pairs := []DataStorePair{}
+	names := slices.Clone(outputNames)
+	slices.Sort(names)
+
+	for _, name := range names {
+		sourceKind, sourceEndpoint := parseDataStoreEndpoint(sourceValues[name])
+		targetKind, targetEndpoint := parseDataStoreEndpoint(targetValues[name])
+		if sourceKind == "" || sourceKind != targetKind || sourceEndpoint == targetEndpoint {
+			continue
+		}
+
+		// The same data store may be exposed by several outputs
+		if slices.IndexFunc(pairs, func(pair DataStorePair) bool { return pair.SourceEndpoint == sourceEndpoint }) >= 0 {
+			continue
+		}
+
+		pairs = append(pairs, DataStorePair{
+			Kind:           sourceKind,
+			Name:           name,
+			SourceEndpoint: sourceEndpoint,
+			TargetEndpoint: targetEndpoint,
+		})
+	}
+
+	return pairs
+}
+
+// Gets the kind of the data store of the endpoint and the endpoint without its path,
+// ex) https://{account}.blob.core.windows.net
+func parseDataStoreEndpoint(value string) (DataStoreKind, string) {
+	endpointUrl, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || endpointUrl.Scheme != "https" {
+		return "", ""
+	}
+
+	endpoint := fmt.Sprintf("https://%s", endpointUrl.Host)
+	hostname := endpointUrl.Hostname()
+
+	switch {
+	case strings.Contains(hostname, ".blob.core."):
+		return DataStoreKindBlob, endpoint
+	case strings.Contains(hostname, ".documents.azure.") || strings.Contains(hostname, ".documents.microsoftazure."):
+		return DataStoreKindCosmos, endpoint
+	default:
+		return "", ""
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FindDataStorePairs(t *testing.T) {
+	outputNames := []string{
+		"AZURE_STORAGE_BLOB_ENDPOINT",
+		"AZURE_STORAGE_ACCOUNT_BLOB_URL",
+		"AZURE_COSMOS_ENDPOINT",
+		"AZURE_SHARED_BLOB_ENDPOINT",
+		"AZURE_KEY_VAULT_ENDPOINT",
+		"AZURE_REMOVED_COSMOS_ENDPOINT",
+	}
+	sourceValues := map[string]string{
+		"AZURE_STORAGE_BLOB_ENDPOINT":    "https://stprod.blob.core.windows.net/",
+		"AZURE_STORAGE_ACCOUNT_BLOB_URL": "https://stprod.blob.core.windows.net/uploads",
+		"AZURE_COSMOS_ENDPOINT":          "https://cosmos-prod.documents.azure.com:443/",
+		"AZURE_SHARED_BLOB_ENDPOINT":     "https://stshared.blob.core.windows.net/",
+		"AZURE_KEY_VAULT_ENDPOINT":       "https://kv-prod.vault.azure.net/",
+		"AZURE_REMOVED_COSMOS_ENDPOINT":  "https://cosmos-old.documents.azure.com:443/",
+	}
+	targetValues := map[string]string{
+		"AZURE_STORAGE_BLOB_ENDPOINT":    "https://stsandbox.blob.core.windows.net/",
+		"AZURE_STORAGE_ACCOUNT_BLOB_URL": "https://stsandbox.blob.core.windows.net/uploads",
+		"AZURE_COSMOS_ENDPOINT":          "https://cosmos-sandbox.documents.azure.com:443/",
+		"AZURE_SHARED_BLOB_ENDPOINT":     "https://stshared.blob.core.windows.net/",
+		"AZURE_KEY_VAULT_ENDPOINT":       "https://kv-sandbox.vault.azure.net/",
+	}
+
+	pairs := FindDataStorePairs(outputNames, sourceValues, targetValues)
+	require.Equal(t, []DataStorePair{
+		{
+			Kind:           DataStoreKindCosmos,
+			Name:           "AZURE_COSMOS_ENDPOINT",
+			SourceEndpoint: "https://cosmos-prod.documents.azure.com:443",
+			TargetEndpoint: "https://cosmos-sandbox.documents.azure.com:443",
+		},
+		{
+			Kind:           DataStoreKindBlob,
+			Name:           "AZURE_STORAGE_ACCOUNT_BLOB_URL",
+			SourceEndpoint: "https://stprod.blob.core.windows.net",
+			TargetEndpoint: "https://stsandbox.blob.core.windows.net",
+		},
+	}, pairs)
+}
@@ -0,0 +1,106 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// DataverseService provides access to import Power Platform solutions into Dataverse environments
+type DataverseService interface {
+	// Starts the asynchronous import of the solution and returns the id of the async operation
+	ImportSolution(
+		ctx context.Context,
+		subscriptionId string,
+		environmentUrl string,
+		solutionZip []byte,
+		overwriteUnmanagedCustomizations bool,
+		publishWorkflows bool,
+	) (string, error)
+	// Gets the current state of the specified async operation
+	GetAsyncOperation(
+		ctx context.Context,
+		subscriptionId string,
+		environmentUrl string,
+		asyncOperationId string,
+	) (*azsdk.AsyncOperation, error)
+}
+
+type dataverseService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	userAgent          string
+}
+
+// Creates a new instance of the DataverseService
+func NewDataverseService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+) DataverseService {
+	return &dataverseService{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		userAgent:          azdinternal.MakeUserAgentString(""),
+	}
+}
+
+// Starts the asynchronous import of the solution and returns the id of the async operation
+func (ds *dataverseService) ImportSolution(
+	ctx context.Context,
+	subscriptionId string,
+	environmentUrl string,
+	solutionZip []byte,
+	overwriteUnmanagedCustomizations bool,
+	publishWorkflows bool,
+) (string, error) {
+	client, err := ds.createDataverseClient(ctx, subscriptionId, environmentUrl)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.ImportSolutionAsync(ctx, solutionZip, overwriteUnmanagedCustomizations, publishWorkflows)
+	if err != nil {
+		return "", fmt.Errorf("importing solution into '%s': %w", environmentUrl, err)
+	}
+
+	return res.AsyncOperationId, nil
+}
+
+// Gets the current state of the specified async operation
+func (ds *dataverseService) GetAsyncOperation(
+	ctx context.Context,
+	subscriptionId string,
+	environmentUrl string,
+	asyncOperationId string,
+) (*azsdk.AsyncOperation, error) {
+	client, err := ds.createDataverseClient(ctx, subscriptionId, environmentUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := client.GetAsyncOperation(ctx, asyncOperationId)
+	if err != nil {
+		return nil, fmt.Errorf("getting solution import status: %w", err)
+	}
+
+	return operation, nil
+}
+
+func (ds *dataverseService) createDataverseClient(
+	ctx context.Context,
+	subscriptionId string,
+	environmentUrl string,
+) (*azsdk.DataverseClient, error) {
+	credential, err := ds.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(ds.httpClient, ds.userAgent).BuildCoreClientOptions()
+
+	return azsdk.NewDataverseClient(environmentUrl, credential, options), nil
+}
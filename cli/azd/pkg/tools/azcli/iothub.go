@@ -0,0 +1,102 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	azdinternal "github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/account"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+)
+
+// IotHubService provides access to apply IoT Edge deployments to an IoT Hub
+type IotHubService interface {
+	// Applies the deployment manifest content to a single IoT Edge device
+	ApplyDeviceContent(
+		ctx context.Context,
+		subscriptionId string,
+		hostName string,
+		deviceId string,
+		content *azsdk.IotHubConfigurationContent,
+	) error
+	// Creates an automatic or layered deployment targeting the devices matching the target condition
+	CreateDeployment(
+		ctx context.Context,
+		subscriptionId string,
+		hostName string,
+		configuration *azsdk.IotHubConfiguration,
+	) error
+}
+
+type iotHubService struct {
+	credentialProvider account.SubscriptionCredentialProvider
+	httpClient         httputil.HttpClient
+	userAgent          string
+}
+
+// Creates a new instance of the IotHubService
+func NewIotHubService(
+	credentialProvider account.SubscriptionCredentialProvider,
+	httpClient httputil.HttpClient,
+) IotHubService {
+	return &iotHubService{
+		credentialProvider: credentialProvider,
+		httpClient:         httpClient,
+		userAgent:          azdinternal.MakeUserAgentString(""),
+	}
+}
+
+// Applies the deployment manifest content to a single IoT Edge device
+func (s *iotHubService) ApplyDeviceContent(
+	ctx context.Context,
+	subscriptionId string,
+	hostName string,
+	deviceId string,
+	content *azsdk.IotHubConfigurationContent,
+) error {
+	client, err := s.createIotHubClient(ctx, subscriptionId, hostName)
+	if err != nil {
+		return err
+	}
+
+	if err := client.ApplyConfigurationContent(ctx, deviceId, content); err != nil {
+		return fmt.Errorf("applying deployment manifest to device '%s': %w", deviceId, err)
+	}
+
+	return nil
+}
+
+// Creates an automatic or layered deployment targeting the devices matching the target condition
+func (s *iotHubService) CreateDeployment(
+	ctx context.Context,
+	subscriptionId string,
+	hostName string,
+	configuration *azsdk.IotHubConfiguration,
+) error {
+	client, err := s.createIotHubClient(ctx, subscriptionId, hostName)
+	if err != nil {
+		return err
+	}
+
+	if err := client.CreateConfiguration(ctx, configuration); err != nil {
+		return fmt.Errorf("creating iot edge deployment '%s': %w", configuration.Id, err)
+	}
+
+	return nil
+}
+
+func (s *iotHubService) createIotHubClient(
+	ctx context.Context,
+	subscriptionId string,
+	hostName string,
+) (*azsdk.IotHubClient, error) {
+	credential, err := s.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := clientOptionsBuilder(s.httpClient, s.userAgent).BuildCoreClientOptions()
+
+	return azsdk.NewIotHubClient(hostName, credential, options), nil
+}
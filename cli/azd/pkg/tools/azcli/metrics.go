@@ -0,0 +1,34 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+// Gets the recent values of a metric of the resource from Azure Monitor, ex) the requests of a container app
+func (cli *azCli) GetResourceMetric(
+	ctx context.Context,
+	subscriptionId string,
+	resourceId string,
+	query azsdk.MetricsQuery,
+) (*azsdk.Metric, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewMetricsClient(credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating metrics client: %w", err)
+	}
+
+	metric, err := client.GetMetric(ctx, resourceId, query)
+	if err != nil {
+		return nil, fmt.Errorf("getting metric '%s' of resource '%s': %w", query.Name, resourceId, err)
+	}
+
+	return metric, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ListResourceGroupResources_Paging(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockhttp.NewArmScenario(mockContext.HttpClient).List(
+		"/subscriptions/*/resourceGroups/*/resources",
+		2,
+		testResource("app1"),
+		testResource("app2"),
+		testResource("app3"),
+	)
+
+	azCli := newAzCliFromMockContext(mockContext)
+	resources, err := azCli.ListResourceGroupResources(*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", nil)
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+	require.Equal(t, "app3", resources[2].Name)
+}
+
+func Test_ListResourceGroup_Error(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockhttp.NewArmScenario(mockContext.HttpClient).
+		Fail(http.MethodGet, "/subscriptions/*/resourcegroups", http.StatusForbidden, "AuthorizationFailed", "not allowed")
+
+	azCli := newAzCliFromMockContext(mockContext)
+	_, err := azCli.ListResourceGroup(*mockContext.Context, "SUBSCRIPTION_ID", nil)
+	require.ErrorContains(t, err, "AuthorizationFailed")
+}
+
+func Test_DeleteResourceGroup_LongRunning(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockhttp.NewArmScenario(mockContext.HttpClient).BeginDelete("/subscriptions/*/resourcegroups/*")
+
+	azCli := newAzCliFromMockContext(mockContext)
+	err := azCli.DeleteResourceGroup(*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP")
+	require.NoError(t, err)
+}
+
+func Test_DeployToResourceGroup_LongRunning(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	mockhttp.NewArmScenario(mockContext.HttpClient).BeginPut(
+		"/subscriptions/*/resourceGroups/*/providers/Microsoft.Resources/deployments/*",
+		map[string]any{
+			"id":   "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/deployments/DEPLOYMENT",
+			"name": "DEPLOYMENT",
+			"properties": map[string]any{
+				"provisioningState": "Succeeded",
+				"outputs": map[string]any{
+					"WEBSITE_URL": map[string]any{"type": "String", "value": "https://app.azurewebsites.net"},
+				},
+			},
+		},
+	)
+
+	azCli := newAzCliFromMockContext(mockContext)
+	result, err := azCli.DeployToResourceGroup(
+		*mockContext.Context,
+		"SUBSCRIPTION_ID",
+		"RESOURCE_GROUP",
+		"DEPLOYMENT",
+		azure.RawArmTemplate("{}"),
+		azure.ArmParameters{},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "https://app.azurewebsites.net", result.Properties.Outputs["WEBSITE_URL"].Value)
+}
+
+func testResource(name string) map[string]any {
+	return map[string]any{
+		"id":       "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Web/sites/" + name,
+		"name":     name,
+		"type":     "Microsoft.Web/sites",
+		"location": "eastus2",
+	}
+}
@@ -0,0 +1,44 @@
+package azcli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+)
+
+// Gets the addresses used to connect to the instances of the virtual machine scale set, the public IP address of the
+// instances that have one and the private IP address of the other instances
+func (cli *azCli) GetScaleSetInstanceHosts(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroupName string,
+	scaleSetName string,
+) ([]string, error) {
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	client, err := azsdk.NewScaleSetClient(subscriptionId, credential, options)
+	if err != nil {
+		return nil, fmt.Errorf("creating scale set client: %w", err)
+	}
+
+	addresses, err := client.ListInstanceAddresses(ctx, resourceGroupName, scaleSetName)
+	if err != nil {
+		return nil, fmt.Errorf("getting the instances of scale set '%s': %w", scaleSetName, err)
+	}
+
+	hosts := []string{}
+	for _, address := range addresses {
+		if address.PublicIpAddress != "" {
+			hosts = append(hosts, address.PublicIpAddress)
+		} else if address.PrivateIpAddress != "" {
+			hosts = append(hosts, address.PrivateIpAddress)
+		}
+	}
+
+	return hosts, nil
+}
@@ -58,6 +58,26 @@ func (user *UserProfileService) GetSignedInUserId(ctx context.Context, tenantId
 	return &userProfile.Id, nil
 }
 
+// Gets the groups of the specified groups which the principal is a member of, directly or transitively
+func (user *UserProfileService) CheckMemberGroups(
+	ctx context.Context,
+	tenantId string,
+	principalId string,
+	groupIds []string,
+) ([]string, error) {
+	client, err := user.createGraphClient(ctx, tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	memberGroups, err := client.DirectoryObjectById(principalId).CheckMemberGroups(ctx, groupIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed checking group membership of principal '%s': %w", principalId, err)
+	}
+
+	return memberGroups, nil
+}
+
 func (u *UserProfileService) GetAccessToken(ctx context.Context, tenantId string) (*AzCliAccessToken, error) {
 	cred, err := u.credentialProvider.GetTokenCredential(ctx, tenantId)
 	if err != nil {
@@ -1,10 +1,20 @@
 package azcli
 
 import (
+	"archive/zip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
 	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
 	"github.com/azure/azure-dev/cli/azd/pkg/convert"
@@ -55,6 +65,279 @@ func (cli *azCli) DeployAppServiceZip(
 	return convert.RefOf(response.StatusText), nil
 }
 
+// DeployAppServiceZipDelta deploys the zip archive by only uploading the files that changed since the previous
+// deployment, and removing the files that are no longer part of the archive.
+//
+// A manifest of the deployed files is stored alongside the app. A full zip deployment is used instead when the manifest
+// is missing or out of date with the latest deployment, or when the app builds during deployment or runs from package.
+func (cli *azCli) DeployAppServiceZipDelta(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	zipFilePath string,
+) (*string, error) {
+	client, err := cli.createZipDeployClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := hashZipEntries(zipFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	supported, err := cli.supportsDeltaDeploy(ctx, subscriptionId, resourceGroup, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !supported {
+		log.Printf("app '%s' doesn't support delta deployments, using zip deploy", appName)
+		return cli.deployAppServiceZipFile(ctx, client, appName, zipFilePath, nil)
+	}
+
+	manifest, err := getAppServiceDeployManifest(ctx, client, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := client.GetLatestDeployment(ctx, appName)
+	if err != nil {
+		return nil, fmt.Errorf("getting latest deployment: %w", err)
+	}
+
+	if manifest == nil || latest == nil || latest.Id != manifest.DeploymentId {
+		log.Printf("no deployment manifest matching the latest deployment of app '%s', using zip deploy", appName)
+		return cli.deployAppServiceZipFile(ctx, client, appName, zipFilePath, files)
+	}
+
+	changed, removed := diffAppServiceDeployManifest(manifest.Files, files)
+	if len(changed) == 0 && len(removed) == 0 {
+		return convert.RefOf("No changes detected"), nil
+	}
+
+	if len(changed) > 0 {
+		if err := extractChangedFiles(ctx, client, appName, zipFilePath, changed); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range removed {
+		if err := client.DeleteFile(ctx, appName, fmt.Sprintf("%s/%s", appServiceWwwRoot, path)); err != nil {
+			return nil, fmt.Errorf("removing '%s': %w", path, err)
+		}
+	}
+
+	manifest.Files = files
+	if err := putAppServiceDeployManifest(ctx, client, appName, manifest); err != nil {
+		return nil, err
+	}
+
+	// Files uploaded through the zip API don't restart the app
+	webAppsClient, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := webAppsClient.Restart(ctx, resourceGroup, appName, nil); err != nil {
+		return nil, fmt.Errorf("restarting app: %w", err)
+	}
+
+	return convert.RefOf(
+		fmt.Sprintf("Uploaded %d changed file(s), removed %d file(s)", len(changed), len(removed)),
+	), nil
+}
+
+// Delta deployments are not supported when the app runs directly from the deployed package or when the app is built
+// during deployment, since files extracted through the zip API bypass the build.
+func (cli *azCli) supportsDeltaDeploy(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) (bool, error) {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return false, err
+	}
+
+	settings, err := client.ListApplicationSettings(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed retrieving webapp settings: %w", err)
+	}
+
+	for name, value := range settings.Properties {
+		if value == nil {
+			continue
+		}
+
+		switch strings.ToUpper(name) {
+		case "WEBSITE_RUN_FROM_PACKAGE":
+			if *value != "0" {
+				return false, nil
+			}
+		case "SCM_DO_BUILD_DURING_DEPLOYMENT":
+			if enabled, _ := strconv.ParseBool(*value); enabled {
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// Runs a full zip deployment. When files are specified the deploy manifest is updated for subsequent delta deployments.
+func (cli *azCli) deployAppServiceZipFile(
+	ctx context.Context,
+	client *azsdk.ZipDeployClient,
+	appName string,
+	zipFilePath string,
+	files map[string]string,
+) (*string, error) {
+	zipFile, err := os.Open(zipFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading deployment zip file: %w", err)
+	}
+	defer zipFile.Close()
+
+	response, err := client.Deploy(ctx, appName, zipFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if files != nil {
+		manifest := &appServiceDeployManifest{
+			DeploymentId: response.Id,
+			Files:        files,
+		}
+
+		if err := putAppServiceDeployManifest(ctx, client, appName, manifest); err != nil {
+			return nil, err
+		}
+	}
+
+	return convert.RefOf(response.StatusText), nil
+}
+
+// Restarts the app service or function app
+func (cli *azCli) RestartAppService(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Restart(ctx, resourceGroup, appName, nil); err != nil {
+		return fmt.Errorf("restarting app '%s': %w", appName, err)
+	}
+
+	return nil
+}
+
+// SharedAppServicePlanError is returned when scaling an app service plan that hosts other apps, which run on the same
+// instances and are scaled as well
+type SharedAppServicePlanError struct {
+	PlanName string
+	// The number of apps hosted by the plan, including the app being scaled
+	Apps int
+}
+
+func (e *SharedAppServicePlanError) Error() string {
+	return fmt.Sprintf(
+		"app service plan '%s' hosts %d other app(s), which would be scaled as well",
+		e.PlanName,
+		e.Apps-1,
+	)
+}
+
+// Scales the app service plan of the app service or function app to a number of instances. The other apps of the plan
+// run on the same instances, so plans hosting other apps are only scaled when force is set. Plans with dynamic skus,
+// ex) Consumption and Flex Consumption, are scaled by the platform and can't be scaled to a number of instances.
+func (cli *azCli) ScaleAppService(
+	ctx context.Context,
+	subscriptionId string,
+	resourceGroup string,
+	appName string,
+	instances int,
+	force bool,
+) error {
+	client, err := cli.createWebAppsClient(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	app, err := client.Get(ctx, resourceGroup, appName, nil)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app properties: %w", err)
+	}
+
+	if app.Properties == nil || app.Properties.ServerFarmID == nil {
+		return fmt.Errorf("app '%s' has no app service plan", appName)
+	}
+
+	planId, err := arm.ParseResourceID(*app.Properties.ServerFarmID)
+	if err != nil {
+		return fmt.Errorf("parsing app service plan id of app '%s': %w", appName, err)
+	}
+
+	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	options := cli.createDefaultClientOptionsBuilder(ctx).BuildArmClientOptions()
+	plansClient, err := armappservice.NewPlansClient(planId.SubscriptionID, credential, options)
+	if err != nil {
+		return fmt.Errorf("creating Plans client: %w", err)
+	}
+
+	plan, err := plansClient.Get(ctx, planId.ResourceGroupName, planId.Name, nil)
+	if err != nil {
+		return fmt.Errorf("failed retrieving app service plan '%s': %w", planId.Name, err)
+	}
+
+	if plan.SKU == nil {
+		return fmt.Errorf("app service plan '%s' has no sku", planId.Name)
+	}
+
+	if tier := convert.ToValueWithDefault(plan.SKU.Tier, ""); isDynamicAppServiceTier(tier) {
+		return fmt.Errorf(
+			"app service plan '%s' uses the %s tier, which is scaled automatically and can't be scaled to a "+
+				"number of instances",
+			planId.Name,
+			tier,
+		)
+	}
+
+	if plan.Properties != nil && !force {
+		if apps := int(convert.ToValueWithDefault(plan.Properties.NumberOfSites, 0)); apps > 1 {
+			return &SharedAppServicePlanError{PlanName: planId.Name, Apps: apps}
+		}
+	}
+
+	plan.SKU.Capacity = convert.RefOf(int32(instances))
+	poller, err := plansClient.BeginCreateOrUpdate(ctx, planId.ResourceGroupName, planId.Name, plan.Plan, nil)
+	if err != nil {
+		return fmt.Errorf("starting scale of app service plan '%s': %w", planId.Name, err)
+	}
+
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("scaling app service plan '%s': %w", planId.Name, err)
+	}
+
+	return nil
+}
+
+// Gets whether the tier of an app service plan is scaled by the platform, ex) the Dynamic tier of Consumption plans
+func isDynamicAppServiceTier(tier string) bool {
+	return strings.EqualFold(tier, "Dynamic") || strings.EqualFold(tier, "FlexConsumption")
+}
+
 func (cli *azCli) createWebAppsClient(ctx context.Context, subscriptionId string) (*armappservice.WebAppsClient, error) {
 	credential, err := cli.credentialProvider.CredentialForSubscription(ctx, subscriptionId)
 	if err != nil {
@@ -84,3 +367,166 @@ func (cli *azCli) createZipDeployClient(ctx context.Context, subscriptionId stri
 
 	return client, nil
 }
+
+const (
+	// The directory the app is deployed to, relative to the app home directory
+	appServiceWwwRoot = "site/wwwroot"
+	// The manifest of the files deployed by azd, relative to the app home directory
+	appServiceDeployManifestPath = "site/deployments/azd/manifest.json"
+)
+
+// The files deployed to an app service, used to compute the changes for delta deployments
+type appServiceDeployManifest struct {
+	// The id of the zip deployment the files were deployed on top of
+	DeploymentId string `json:"deploymentId"`
+	// The SHA-256 hash of the deployed files, keyed by the path relative to the deployment root
+	Files map[string]string `json:"files"`
+}
+
+func getAppServiceDeployManifest(
+	ctx context.Context,
+	client *azsdk.ZipDeployClient,
+	appName string,
+) (*appServiceDeployManifest, error) {
+	contents, err := client.GetFile(ctx, appName, appServiceDeployManifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("getting deploy manifest: %w", err)
+	}
+
+	if contents == nil {
+		return nil, nil
+	}
+
+	var manifest *appServiceDeployManifest
+	if err := json.Unmarshal(contents, &manifest); err != nil {
+		// A corrupt manifest results in a full deployment that rewrites the manifest
+		log.Printf("failed parsing deploy manifest: %v", err)
+		return nil, nil
+	}
+
+	return manifest, nil
+}
+
+func putAppServiceDeployManifest(
+	ctx context.Context,
+	client *azsdk.ZipDeployClient,
+	appName string,
+	manifest *appServiceDeployManifest,
+) error {
+	contents, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	if err := client.PutFile(ctx, appName, appServiceDeployManifestPath, contents); err != nil {
+		return fmt.Errorf("saving deploy manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Computes the SHA-256 hash of all files within the zip archive
+func hashZipEntries(zipFilePath string) (map[string]string, error) {
+	reader, err := zip.OpenReader(zipFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading deployment zip file: %w", err)
+	}
+	defer reader.Close()
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		entry, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading '%s': %w", file.Name, err)
+		}
+
+		hash := sha256.New()
+		_, err = io.Copy(hash, entry)
+		entry.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading '%s': %w", file.Name, err)
+		}
+
+		files[file.Name] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return files, nil
+}
+
+// Gets the sorted paths of the files that were added or changed, and of the files that were removed
+func diffAppServiceDeployManifest(previous map[string]string, current map[string]string) ([]string, []string) {
+	changed := []string{}
+	for path, hash := range current {
+		if previous[path] != hash {
+			changed = append(changed, path)
+		}
+	}
+
+	removed := []string{}
+	for path := range previous {
+		if _, has := current[path]; !has {
+			removed = append(removed, path)
+		}
+	}
+
+	sort.Strings(changed)
+	sort.Strings(removed)
+
+	return changed, removed
+}
+
+// Copies the changed files into a new zip archive and extracts it into the app deployment root
+func extractChangedFiles(
+	ctx context.Context,
+	client *azsdk.ZipDeployClient,
+	appName string,
+	zipFilePath string,
+	changed []string,
+) error {
+	reader, err := zip.OpenReader(zipFilePath)
+	if err != nil {
+		return fmt.Errorf("reading deployment zip file: %w", err)
+	}
+	defer reader.Close()
+
+	deltaFile, err := os.CreateTemp("", "azd-delta-*.zip")
+	if err != nil {
+		return fmt.Errorf("creating delta zip file: %w", err)
+	}
+	defer os.Remove(deltaFile.Name())
+	defer deltaFile.Close()
+
+	include := map[string]struct{}{}
+	for _, path := range changed {
+		include[path] = struct{}{}
+	}
+
+	writer := zip.NewWriter(deltaFile)
+	for _, file := range reader.File {
+		if _, has := include[file.Name]; !has {
+			continue
+		}
+
+		if err := writer.Copy(file); err != nil {
+			return fmt.Errorf("creating delta zip file: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("creating delta zip file: %w", err)
+	}
+
+	if _, err := deltaFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err := client.ExtractZip(ctx, appName, appServiceWwwRoot, deltaFile); err != nil {
+		return fmt.Errorf("uploading changed files: %w", err)
+	}
+
+	return nil
+}
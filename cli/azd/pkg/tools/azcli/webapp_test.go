@@ -0,0 +1,309 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azcli
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/appservice/armappservice"
+	"github.com/azure/azure-dev/cli/azd/pkg/azsdk"
+	"github.com/azure/azure-dev/cli/azd/pkg/convert"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeployAppServiceZipDelta(t *testing.T) {
+	previous := createTestZip(t, map[string]string{
+		"index.js":   "console.log('v1')",
+		"package.js": "{}",
+		"old.js":     "removed",
+	})
+	previousFiles, err := hashZipEntries(previous)
+	require.NoError(t, err)
+
+	current := createTestZip(t, map[string]string{
+		"index.js":   "console.log('v2')",
+		"package.js": "{}",
+		"new.js":     "added",
+	})
+
+	t.Run("Delta", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		registerAppSettingsMock(mockContext, map[string]*string{})
+		registerLatestDeploymentMock(mockContext, "DEPLOYMENT_ID")
+		manifest := registerManifestMocks(t, mockContext, &appServiceDeployManifest{
+			DeploymentId: "DEPLOYMENT_ID",
+			Files:        previousFiles,
+		})
+
+		var extracted []string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPut && request.URL.Path == "/api/zip/site/wwwroot/"
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+
+			reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+			require.NoError(t, err)
+			for _, file := range reader.File {
+				extracted = append(extracted, file.Name)
+			}
+
+			return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+		})
+
+		var deleted []string
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodDelete && strings.HasPrefix(request.URL.Path, "/api/vfs/site/wwwroot/")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			deleted = append(deleted, strings.TrimPrefix(request.URL.Path, "/api/vfs/site/wwwroot/"))
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNoContent)
+		})
+
+		restarted := false
+		mockContext.HttpClient.When(func(request *http.Request) bool {
+			return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/sites/APP_NAME/restart")
+		}).RespondFn(func(request *http.Request) (*http.Response, error) {
+			restarted = true
+			return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+		})
+
+		res, err := azCli.DeployAppServiceZipDelta(
+			*mockContext.Context,
+			"SUBSCRIPTION_ID",
+			"RESOURCE_GROUP",
+			"APP_NAME",
+			current,
+		)
+		require.NoError(t, err)
+		require.Equal(t, "Uploaded 2 changed file(s), removed 1 file(s)", *res)
+		sort.Strings(extracted)
+		require.Equal(t, []string{"index.js", "new.js"}, extracted)
+		require.Equal(t, []string{"old.js"}, deleted)
+		require.True(t, restarted)
+
+		require.Equal(t, "DEPLOYMENT_ID", manifest.DeploymentId)
+		require.Contains(t, manifest.Files, "new.js")
+		require.NotContains(t, manifest.Files, "old.js")
+	})
+
+	t.Run("FullDeployWithoutManifest", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		registerAppSettingsMock(mockContext, map[string]*string{})
+		registerLatestDeploymentMock(mockContext, "DEPLOYMENT_ID")
+		registerZipDeployMock(mockContext)
+		manifest := registerManifestMocks(t, mockContext, nil)
+
+		res, err := azCli.DeployAppServiceZipDelta(
+			*mockContext.Context,
+			"SUBSCRIPTION_ID",
+			"RESOURCE_GROUP",
+			"APP_NAME",
+			current,
+		)
+		require.NoError(t, err)
+		require.Equal(t, "OK", *res)
+		require.Equal(t, "DEPLOYMENT_ID", manifest.DeploymentId)
+		require.Len(t, manifest.Files, 3)
+	})
+
+	t.Run("FullDeployWhenBuildDuringDeployment", func(t *testing.T) {
+		mockContext := mocks.NewMockContext(context.Background())
+		azCli := newAzCliFromMockContext(mockContext)
+
+		registerAppSettingsMock(mockContext, map[string]*string{
+			"SCM_DO_BUILD_DURING_DEPLOYMENT": convert.RefOf("true"),
+		})
+		registerLatestDeploymentMock(mockContext, "DEPLOYMENT_ID")
+		registerZipDeployMock(mockContext)
+		manifest := registerManifestMocks(t, mockContext, &appServiceDeployManifest{
+			DeploymentId: "DEPLOYMENT_ID",
+			Files:        previousFiles,
+		})
+
+		res, err := azCli.DeployAppServiceZipDelta(
+			*mockContext.Context,
+			"SUBSCRIPTION_ID",
+			"RESOURCE_GROUP",
+			"APP_NAME",
+			current,
+		)
+		require.NoError(t, err)
+		require.Equal(t, "OK", *res)
+		// The manifest is left untouched
+		require.Contains(t, manifest.Files, "old.js")
+	})
+}
+
+func createTestZip(t *testing.T, files map[string]string) string {
+	zipFilePath := filepath.Join(t.TempDir(), "app.zip")
+	zipFile, err := os.Create(zipFilePath)
+	require.NoError(t, err)
+	defer zipFile.Close()
+
+	writer := zip.NewWriter(zipFile)
+	for name, contents := range files {
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	return zipFilePath
+}
+
+func registerAppSettingsMock(mockContext *mocks.MockContext, settings map[string]*string) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/config/appsettings/list")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		response := armappservice.WebAppsClientListApplicationSettingsResponse{
+			StringDictionary: armappservice.StringDictionary{
+				Properties: settings,
+			},
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, response)
+	})
+}
+
+func registerLatestDeploymentMock(mockContext *mocks.MockContext, deploymentId string) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/deployments/latest")
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, azsdk.DeployStatus{
+			Id:         deploymentId,
+			Status:     http.StatusOK,
+			StatusText: "OK",
+			Complete:   true,
+		})
+	})
+}
+
+func registerZipDeployMock(mockContext *mocks.MockContext) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost && request.URL.Path == "/api/zipdeploy"
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		response, _ := mocks.CreateEmptyHttpResponse(request, http.StatusAccepted)
+		response.Header.Set("Location", "https://APP_NAME.scm.azurewebsites.net/api/deployments/latest")
+
+		return response, nil
+	})
+}
+
+// Registers the mocks to read & write the deploy manifest. The returned manifest reflects the latest saved manifest.
+func registerManifestMocks(
+	t *testing.T,
+	mockContext *mocks.MockContext,
+	existing *appServiceDeployManifest,
+) *appServiceDeployManifest {
+	manifest := &appServiceDeployManifest{}
+	if existing != nil {
+		*manifest = *existing
+	}
+
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.URL.Path == "/api/vfs/"+appServiceDeployManifestPath
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if request.Method == http.MethodPut {
+			body, err := io.ReadAll(request.Body)
+			require.NoError(t, err)
+
+			var updated appServiceDeployManifest
+			require.NoError(t, json.Unmarshal(body, &updated))
+			*manifest = updated
+
+			return mocks.CreateEmptyHttpResponse(request, http.StatusCreated)
+		}
+
+		if existing == nil {
+			return mocks.CreateEmptyHttpResponse(request, http.StatusNotFound)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, existing)
+	})
+
+	return manifest
+}
+
+func Test_ScaleAppService(t *testing.T) {
+	tests := []struct {
+		name        string
+		tier        string
+		apps        int32
+		force       bool
+		expectError string
+	}{
+		{name: "DedicatedPlan", tier: "Standard", apps: 1},
+		{name: "SharedPlan", tier: "Standard", apps: 3, expectError: "hosts 2 other app(s)"},
+		{name: "SharedPlanForced", tier: "Standard", apps: 3, force: true},
+		{name: "ConsumptionPlan", tier: "Dynamic", apps: 1, expectError: "uses the Dynamic tier"},
+		{name: "FlexConsumptionPlan", tier: "FlexConsumption", apps: 1, force: true, expectError: "can't be scaled"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			azCli := newAzCliFromMockContext(mockContext)
+			planId := "/subscriptions/SUBSCRIPTION_ID/resourceGroups/RESOURCE_GROUP/providers/Microsoft.Web/serverfarms/PLAN"
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/sites/APP_NAME")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, armappservice.WebAppsClientGetResponse{
+					Site: armappservice.Site{
+						Properties: &armappservice.SiteProperties{ServerFarmID: convert.RefOf(planId)},
+					},
+				})
+			})
+
+			plan := armappservice.Plan{
+				SKU:        &armappservice.SKUDescription{Tier: convert.RefOf(test.tier), Capacity: convert.RefOf(int32(1))},
+				Properties: &armappservice.PlanProperties{NumberOfSites: convert.RefOf(test.apps)},
+			}
+
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/serverfarms/PLAN")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, plan)
+			})
+
+			var scaledCapacity int32
+			mockContext.HttpClient.When(func(request *http.Request) bool {
+				return request.Method == http.MethodPut && strings.HasSuffix(request.URL.Path, "/serverfarms/PLAN")
+			}).RespondFn(func(request *http.Request) (*http.Response, error) {
+				var updated armappservice.Plan
+				require.NoError(t, json.NewDecoder(request.Body).Decode(&updated))
+				scaledCapacity = *updated.SKU.Capacity
+
+				return mocks.CreateHttpResponseWithBody(request, http.StatusOK, updated)
+			})
+
+			err := azCli.ScaleAppService(
+				*mockContext.Context, "SUBSCRIPTION_ID", "RESOURCE_GROUP", "APP_NAME", 3, test.force)
+			if test.expectError != "" {
+				require.ErrorContains(t, err, test.expectError)
+				require.Zero(t, scaledCapacity)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, int32(3), scaledCapacity)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -39,7 +40,7 @@ func Test_Bash_Execute(t *testing.T) {
 		})
 
 		bashScript := NewBashScript(mockContext.CommandRunner, workingDir, env)
-		runResult, err := bashScript.Execute(*mockContext.Context, scriptPath, true)
+		runResult, err := bashScript.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: true})
 
 		require.NotNil(t, runResult)
 		require.NoError(t, err)
@@ -55,7 +56,7 @@ func Test_Bash_Execute(t *testing.T) {
 		})
 
 		bashScript := NewBashScript(mockContext.CommandRunner, workingDir, env)
-		runResult, err := bashScript.Execute(*mockContext.Context, scriptPath, true)
+		runResult, err := bashScript.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: true})
 
 		require.Equal(t, 1, runResult.ExitCode)
 		require.Error(t, err)
@@ -81,7 +82,7 @@ func Test_Bash_Execute(t *testing.T) {
 			})
 
 			bashScript := NewBashScript(mockContext.CommandRunner, workingDir, env)
-			runResult, err := bashScript.Execute(*mockContext.Context, scriptPath, test.value)
+			runResult, err := bashScript.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: test.value})
 
 			require.NotNil(t, runResult)
 			require.NoError(t, err)
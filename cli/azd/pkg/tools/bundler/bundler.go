@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bundler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// BundlerCli installs the dependencies of Ruby projects with bundler, and runs the commands of their gems
+type BundlerCli interface {
+	tools.ExternalTool
+	// Installs the gems of the Gemfile and Gemfile.lock of the project
+	Install(ctx context.Context, cwd string) error
+	// Runs a command of the gems of the project with bundle exec, ex) rails assets:precompile
+	Exec(ctx context.Context, cwd string, env []string, args ...string) (exec.RunResult, error)
+}
+
+type bundlerCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the bundler CLI
+func NewBundlerCli(commandRunner exec.CommandRunner) BundlerCli {
+	return &bundlerCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *bundlerCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("bundle")
+}
+
+func (cli *bundlerCli) InstallUrl() string {
+	return "https://bundler.io/"
+}
+
+func (cli *bundlerCli) Name() string {
+	return "bundler"
+}
+
+func (cli *bundlerCli) Install(ctx context.Context, cwd string) error {
+	runArgs := exec.NewRunArgs("bundle", "install").
+		WithCwd(cwd).
+		WithEnv(tools.RestoreCacheEnv(tools.RestoreCacheBundler, "BUNDLE_USER_CACHE"))
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("installing gems: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *bundlerCli) Exec(ctx context.Context, cwd string, env []string, args ...string) (exec.RunResult, error) {
+	runArgs := exec.NewRunArgs("bundle", append([]string{"exec"}, args...)...).
+		WithCwd(cwd).
+		WithEnv(env)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return res, fmt.Errorf("running bundle exec %s: %s: %w", strings.Join(args, " "), res.String(), err)
+	}
+
+	return res, nil
+}
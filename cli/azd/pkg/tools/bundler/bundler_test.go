@@ -0,0 +1,54 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package bundler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BundlerInstall(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv(tools.RestoreCacheDirEnvVarName, cacheDir)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	bundlerCli := NewBundlerCli(mockContext.CommandRunner)
+
+	var runArgs exec.RunArgs
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "bundle install")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := bundlerCli.Install(*mockContext.Context, "./src/web")
+	require.NoError(t, err)
+	require.Equal(t, "./src/web", runArgs.Cwd)
+	require.Equal(t, []string{"install"}, runArgs.Args)
+	require.Len(t, runArgs.Env, 1)
+	require.True(t, strings.HasPrefix(runArgs.Env[0], "BUNDLE_USER_CACHE="))
+}
+
+func Test_BundlerExec(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	bundlerCli := NewBundlerCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "bundle exec rspec")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(1, "", "2 examples, 1 failure"), errors.New("exit code: 1")
+	})
+
+	_, err := bundlerCli.Exec(*mockContext.Context, "./src/web", nil, "rspec")
+	require.ErrorContains(t, err, "running bundle exec rspec")
+	require.ErrorContains(t, err, "2 examples, 1 failure")
+}
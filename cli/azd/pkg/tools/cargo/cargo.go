@@ -0,0 +1,120 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cargo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// BuildOptions are the options of a release build of a cargo package
+type BuildOptions struct {
+	// The binary built, ex) api. Builds all the binaries of the package when empty.
+	Bin string
+	// The target triple of the build, ex) x86_64-unknown-linux-musl. Builds for the host when empty.
+	Target string
+	// The directory of the artifacts of the build, ex) target
+	TargetDir string
+	// The features of the package enabled for the build
+	Features []string
+}
+
+// CargoCli builds Rust packages with cargo
+type CargoCli interface {
+	tools.ExternalTool
+	// Downloads the dependencies of the package
+	Fetch(ctx context.Context, cwd string) error
+	// Builds the package with the release profile
+	Build(ctx context.Context, cwd string, options BuildOptions) error
+	// Runs the tests of the package, with the features enabled
+	Test(ctx context.Context, cwd string, features []string) error
+	// Gets the version of the Rust compiler building the package, ex) rustc 1.77.0 (aedd173a2 2024-03-17), which honors
+	// the rust-toolchain file of the package
+	Version(ctx context.Context, cwd string) (string, error)
+}
+
+type cargoCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the cargo CLI
+func NewCargoCli(commandRunner exec.CommandRunner) CargoCli {
+	return &cargoCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *cargoCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("cargo")
+}
+
+func (cli *cargoCli) InstallUrl() string {
+	return "https://www.rust-lang.org/tools/install"
+}
+
+func (cli *cargoCli) Name() string {
+	return "cargo"
+}
+
+func (cli *cargoCli) Fetch(ctx context.Context, cwd string) error {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("cargo", "fetch").WithCwd(cwd))
+	if err != nil {
+		return fmt.Errorf("fetching cargo dependencies: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *cargoCli) Build(ctx context.Context, cwd string, options BuildOptions) error {
+	args := []string{"build", "--release"}
+	if options.Bin != "" {
+		args = append(args, "--bin", options.Bin)
+	}
+
+	if options.Target != "" {
+		args = append(args, "--target", options.Target)
+	}
+
+	if options.TargetDir != "" {
+		args = append(args, "--target-dir", options.TargetDir)
+	}
+
+	for _, feature := range options.Features {
+		args = append(args, "--features", feature)
+	}
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("cargo", args...).WithCwd(cwd))
+	if err != nil {
+		return fmt.Errorf("building cargo package: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *cargoCli) Test(ctx context.Context, cwd string, features []string) error {
+	args := []string{"test"}
+	for _, feature := range features {
+		args = append(args, "--features", feature)
+	}
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("cargo", args...).WithCwd(cwd))
+	if err != nil {
+		return fmt.Errorf("testing cargo package: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *cargoCli) Version(ctx context.Context, cwd string) (string, error) {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("rustc", "--version").WithCwd(cwd))
+	if err != nil {
+		return "", fmt.Errorf("checking rustc version: %s: %w", res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
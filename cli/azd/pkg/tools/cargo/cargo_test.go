@@ -0,0 +1,44 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cargo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CargoBuild(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	cargoCli := NewCargoCli(mockContext.CommandRunner)
+
+	ran := false
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "cargo build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+		require.Equal(t, "./src/api", args.Cwd)
+		require.Equal(t, []string{
+			"build", "--release",
+			"--bin", "api",
+			"--target", "x86_64-unknown-linux-musl",
+			"--target-dir", "target",
+			"--features", "azure",
+		}, args.Args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := cargoCli.Build(*mockContext.Context, "./src/api", BuildOptions{
+		Bin:       "api",
+		Target:    "x86_64-unknown-linux-musl",
+		TargetDir: "target",
+		Features:  []string{"azure"},
+	})
+	require.NoError(t, err)
+	require.True(t, ran)
+}
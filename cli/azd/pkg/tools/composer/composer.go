@@ -0,0 +1,74 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package composer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// InstallOptions are the options of the install of the dependencies of a PHP project
+type InstallOptions struct {
+	// When true, the dev dependencies aren't installed and the autoloader is optimized for production
+	NoDev bool
+}
+
+// ComposerCli installs the dependencies of PHP projects with composer
+type ComposerCli interface {
+	tools.ExternalTool
+	// Installs the dependencies of the composer.json and composer.lock of the project into its vendor directory
+	Install(ctx context.Context, cwd string, options InstallOptions) error
+	// Runs a script of the composer.json of the project, ex) test
+	RunScript(ctx context.Context, cwd string, script string) error
+}
+
+type composerCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the composer CLI
+func NewComposerCli(commandRunner exec.CommandRunner) ComposerCli {
+	return &composerCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *composerCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("composer")
+}
+
+func (cli *composerCli) InstallUrl() string {
+	return "https://getcomposer.org/download/"
+}
+
+func (cli *composerCli) Name() string {
+	return "composer"
+}
+
+func (cli *composerCli) Install(ctx context.Context, cwd string, options InstallOptions) error {
+	args := []string{"install", "--no-interaction", "--prefer-dist"}
+	if options.NoDev {
+		args = append(args, "--no-dev", "--optimize-autoloader")
+	}
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("composer", args...).WithCwd(cwd))
+	if err != nil {
+		return fmt.Errorf("installing composer dependencies: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *composerCli) RunScript(ctx context.Context, cwd string, script string) error {
+	runArgs := exec.NewRunArgs("composer", "run-script", "--no-interaction", script).WithCwd(cwd)
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("running composer script %s: %s: %w", script, res.String(), err)
+	}
+
+	return nil
+}
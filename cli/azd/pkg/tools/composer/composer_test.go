@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package composer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ComposerInstall(t *testing.T) {
+	tests := map[string]struct {
+		options InstallOptions
+		args    []string
+	}{
+		"Dev": {
+			args: []string{"install", "--no-interaction", "--prefer-dist"},
+		},
+		"NoDev": {
+			options: InstallOptions{NoDev: true},
+			args:    []string{"install", "--no-interaction", "--prefer-dist", "--no-dev", "--optimize-autoloader"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockContext := mocks.NewMockContext(context.Background())
+			composerCli := NewComposerCli(mockContext.CommandRunner)
+
+			ran := false
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "composer install")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				ran = true
+				require.Equal(t, "./src/web", args.Cwd)
+				require.Equal(t, test.args, args.Args)
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+			err := composerCli.Install(*mockContext.Context, "./src/web", test.options)
+			require.NoError(t, err)
+			require.True(t, ran)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package conda
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// CondaCli manages the conda environments of Python projects, ex) the environments of machine learning services with
+// packages pip can't install
+type CondaCli interface {
+	tools.ExternalTool
+	// Creates or updates the environment of the prefix from the environment file, removing the packages which aren't in
+	// the environment file
+	UpdateEnv(ctx context.Context, cwd string, prefix string, file string) error
+	// Exports the packages of the environment of the prefix, without their builds so the export resolves on other
+	// platforms, ex) the linux images of Azure Machine Learning
+	ExportEnv(ctx context.Context, cwd string, prefix string) (string, error)
+	// Runs the command in the environment of the prefix
+	Run(ctx context.Context, cwd string, prefix string, args ...string) (exec.RunResult, error)
+}
+
+type condaCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the conda CLI
+func NewCondaCli(commandRunner exec.CommandRunner) CondaCli {
+	return &condaCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *condaCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("conda")
+}
+
+func (cli *condaCli) InstallUrl() string {
+	return "https://docs.conda.io/en/latest/miniconda.html"
+}
+
+func (cli *condaCli) Name() string {
+	return "conda"
+}
+
+func (cli *condaCli) UpdateEnv(ctx context.Context, cwd string, prefix string, file string) error {
+	runArgs := exec.NewRunArgs("conda", "env", "update", "--prefix", prefix, "--file", file, "--prune").
+		WithCwd(cwd).
+		WithEnv(tools.RestoreCacheEnv(tools.RestoreCacheConda, "CONDA_PKGS_DIRS"))
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("updating conda environment: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *condaCli) ExportEnv(ctx context.Context, cwd string, prefix string) (string, error) {
+	runArgs := exec.NewRunArgs("conda", "env", "export", "--prefix", prefix, "--no-builds").WithCwd(cwd)
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return "", fmt.Errorf("exporting conda environment: %s: %w", res.String(), err)
+	}
+
+	return res.Stdout, nil
+}
+
+func (cli *condaCli) Run(ctx context.Context, cwd string, prefix string, args ...string) (exec.RunResult, error) {
+	runArgs := exec.NewRunArgs("conda", append([]string{"run", "--prefix", prefix}, args...)...).WithCwd(cwd)
+	return cli.commandRunner.Run(ctx, runArgs)
+}
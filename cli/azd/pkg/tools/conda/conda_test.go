@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package conda
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CondaUpdateEnv(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv(tools.RestoreCacheDirEnvVarName, cacheDir)
+
+	mockContext := mocks.NewMockContext(context.Background())
+	condaCli := NewCondaCli(mockContext.CommandRunner)
+
+	ran := false
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "conda env update")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+		require.Equal(t, "./src/model", args.Cwd)
+		require.Equal(t,
+			[]string{"env", "update", "--prefix", ".conda", "--file", "environment.yml", "--prune"},
+			args.Args,
+		)
+		require.Equal(t, []string{"CONDA_PKGS_DIRS=" + filepath.Join(cacheDir, tools.RestoreCacheConda)}, args.Env)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := condaCli.UpdateEnv(*mockContext.Context, "./src/model", ".conda", "environment.yml")
+	require.NoError(t, err)
+	require.True(t, ran)
+}
+
+func Test_CondaRun(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	condaCli := NewCondaCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "conda run")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{"run", "--prefix", ".conda", "python", "-m", "pytest"}, args.Args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	_, err := condaCli.Run(*mockContext.Context, "./src/model", ".conda", "python", "-m", "pytest")
+	require.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package cosign
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// CosignCli signs container images with the Sigstore cosign CLI. The credentials of the docker CLI are used to
+// authenticate with registries, ex) after docker login.
+type CosignCli interface {
+	tools.ExternalTool
+	// Signs the image with the key, ex) azurekms://contoso.vault.azure.net/signing for a Key Vault key, and stores the
+	// signature in the registry of the image
+	Sign(ctx context.Context, cwd string, image string, key string) error
+	// Gets the reference of the signature of the image in the registry of the image,
+	// ex) contoso.azurecr.io/api:sha256-{digest}.sig
+	Triangulate(ctx context.Context, cwd string, image string) (string, error)
+}
+
+type cosignCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the cosign CLI
+func NewCosignCli(commandRunner exec.CommandRunner) CosignCli {
+	return &cosignCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *cosignCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("cosign")
+}
+
+func (cli *cosignCli) InstallUrl() string {
+	return "https://docs.sigstore.dev/system_config/installation"
+}
+
+func (cli *cosignCli) Name() string {
+	return "cosign"
+}
+
+func (cli *cosignCli) Sign(ctx context.Context, cwd string, image string, key string) error {
+	// --yes skips the confirmation of uploading the signature to the transparency log
+	runArgs := exec.NewRunArgs("cosign", "sign", "--key", key, "--yes", image).WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("signing image '%s': %s: %w", image, res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *cosignCli) Triangulate(ctx context.Context, cwd string, image string) (string, error) {
+	runArgs := exec.NewRunArgs("cosign", "triangulate", "--type", "signature", image).WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return "", fmt.Errorf("getting signature of image '%s': %s: %w", image, res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
@@ -2,8 +2,13 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	osexec "os/exec"
 	"regexp"
 	"strconv"
 	"strings"
@@ -11,24 +16,106 @@ import (
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
+// ErrDaemonUnavailable is returned when docker isn't installed or can't connect to the docker daemon
+var ErrDaemonUnavailable = errors.New("docker daemon is unavailable")
+
 type Docker interface {
 	tools.ExternalTool
 	Login(ctx context.Context, loginServer string, username string, password string) error
-	Build(ctx context.Context, cwd string, dockerFilePath string, platform string, buildContext string) (string, error)
+	Build(ctx context.Context, cwd string, options BuildOptions, buildProgress io.Writer) (string, error)
+	BuildxPush(ctx context.Context, cwd string, options BuildOptions, tags []string) error
+	Pull(ctx context.Context, cwd string, imageName string) error
 	Tag(ctx context.Context, cwd string, imageName string, tag string) error
 	Push(ctx context.Context, cwd string, tag string) error
+	Layers(ctx context.Context, cwd string, imageName string) ([]string, error)
+	InspectConfig(ctx context.Context, cwd string, imageName string) (*ImageConfig, error)
+}
+
+// ImageConfig is the configuration of an image set by the instructions of its Dockerfile, ex) EXPOSE and ENV
+type ImageConfig struct {
+	// The ports exposed by the image, sorted, ex) 8080/tcp
+	ExposedPorts []string
+	// The environment variables of the image, ex) PORT=8080
+	Env []string
+}
+
+// BuildOptions are the options of an image build
+type BuildOptions struct {
+	// The path of the Dockerfile, relative to the working directory
+	DockerfilePath string
+	// The platforms of the image, ex) linux/amd64 and linux/arm64. Build builds a single platform, amd64 when empty, and
+	// BuildxPush builds a multi-platform image of all the platforms.
+	Platforms []string
+	// The stage of a multi-stage Dockerfile that is built, all the stages are built when empty
+	Target string
+	// The build context, relative to the working directory
+	Context string
+	// The build args, as KEY=VALUE pairs
+	BuildArgs []string
+	// The labels added to the image, as KEY=VALUE pairs
+	Labels []string
+	// The secrets mounted into the build with BuildKit
+	Secrets []BuildSecret
+	// The external caches of the build, only used by BuildxPush
+	Cache BuildCache
+	// The args passed to the build command as-is after the other options, ex) --network=host
+	ExtraArgs []string
+}
+
+// BuildSecret is a secret mounted into the build with BuildKit, ex) RUN --mount=type=secret,id=npm_token.
+// Secrets are only available to the build steps mounting them, and aren't stored in the layers of the image.
+type BuildSecret struct {
+	// The id of the secret referenced by the Dockerfile
+	Id string
+	// The value of the secret. Values are passed to the build through the environment of the build command.
+	Value string
+	// The path of a file with the value of the secret, used when the value isn't set
+	File string
+}
+
+// BuildCache is the external cache of a build, as BuildKit cache specs,
+// ex) type=registry,ref=myregistry.azurecr.io/app:cache.
+// Layers are imported from the caches in From, and exported to the caches in To.
+type BuildCache struct {
+	From []string
+	To   []string
 }
 
+// NewDocker creates the docker tool, running the commands with the container engine resolved from the environment.
+// See [ContainerEngineEnvVarName]. The commands connect to the daemon of the DOCKER_HOST and DOCKER_CONTEXT variables
+// of the environment, or of the current docker context.
 func NewDocker(commandRunner exec.CommandRunner) Docker {
+	return NewDockerWithContext(commandRunner, "")
+}
+
+// NewDockerWithContext creates the docker tool, running the commands against the daemon of the docker context, ex) a
+// remote builder connected with ssh://user@builder. The context overrides the DOCKER_HOST and DOCKER_CONTEXT variables
+// of the environment. With podman, the context is the name of a podman system connection.
+// When the context is empty, the tool is the tool created by [NewDocker].
+func NewDockerWithContext(commandRunner exec.CommandRunner, dockerContext string) Docker {
+	engine, binary := resolveContainerEngine()
+	if dockerContext != "" {
+		log.Printf("running %s commands with context '%s'", engine, dockerContext)
+	}
+
 	return &docker{
 		commandRunner: commandRunner,
+		engine:        engine,
+		binary:        binary,
+		context:       dockerContext,
 	}
 }
 
 type docker struct {
 	commandRunner exec.CommandRunner
+	engine        ContainerEngine
+	binary        string
+	// The docker context, or podman connection, the commands run against. Empty for the daemon of the environment.
+	context string
 }
 
 func (d *docker) Login(ctx context.Context, loginServer string, username string, password string) error {
@@ -44,29 +131,268 @@ func (d *docker) Login(ctx context.Context, loginServer string, username string,
 	return nil
 }
 
-// Runs a Docker build for a given Dockerfile. If the platform is not specified (empty),
-// it defaults to amd64. If the build
-// is successful, the function
-// returns the image id of the built image.
+// Runs a Docker build for a given Dockerfile, for a single platform which defaults to amd64. If the build is successful,
+// the function returns the image id of the built image.
+// When buildProgress is set, the output of the build is streamed to buildProgress as the build runs
+// instead of running a quiet build.
 func (d *docker) Build(
 	ctx context.Context,
 	cwd string,
-	dockerFilePath string,
-	platform string,
-	buildContext string,
+	options BuildOptions,
+	buildProgress io.Writer,
 ) (string, error) {
-	if strings.TrimSpace(platform) == "" {
-		platform = "amd64"
+	if len(options.Platforms) > 1 {
+		return "", fmt.Errorf(
+			"building image: multi-platform images are built with buildx, platforms: %s",
+			strings.Join(options.Platforms, ","),
+		)
 	}
 
-	res, err := d.executeCommand(ctx, cwd, "build", "-q", "-f", dockerFilePath, "--platform", platform, buildContext)
+	platform := "amd64"
+	if len(options.Platforms) == 1 && strings.TrimSpace(options.Platforms[0]) != "" {
+		platform = options.Platforms[0]
+	}
+
+	args := []string{"build"}
+	var imageIdFile string
+	if buildProgress == nil {
+		args = append(args, "-q")
+	} else {
+		// The build output is streamed, so the image id is written to a file instead of the only line of stdout
+		file, err := os.CreateTemp("", "azd-docker-iid")
+		if err != nil {
+			return "", fmt.Errorf("creating image id file: %w", err)
+		}
+		_ = file.Close()
+		defer os.Remove(file.Name())
+
+		imageIdFile = file.Name()
+	}
+
+	args = append(args, "-f", options.DockerfilePath)
+	if d.engine == EnginePodman {
+		args = append(args, "--platform", podmanPlatform(platform), "--format", "docker")
+	} else {
+		args = append(args, "--platform", platform)
+	}
+	optionArgs, env := d.buildOptions(options)
+	args = append(args, optionArgs...)
+	args = append(args, options.ExtraArgs...)
+	if imageIdFile != "" {
+		args = append(args, "--iidfile", imageIdFile)
+	}
+	args = append(args, options.Context)
+
+	runArgs := d.newRunArgs(args...).
+		WithCwd(cwd).
+		WithEnv(env).
+		WithEnrichError(true)
+	if buildProgress != nil {
+		// BuildKit writes the progress of the build to stderr, the legacy builder writes it to stdout
+		runArgs = runArgs.WithStdOut(buildProgress).WithStdErr(buildProgress)
+	}
+
+	res, err := d.commandRunner.Run(ctx, runArgs)
 	if err != nil {
+		if isDaemonUnavailable(res, err) {
+			return "", fmt.Errorf("building image: %w: %s: %w", ErrDaemonUnavailable, res.String(), err)
+		}
+
 		return "", fmt.Errorf("building image: %s: %w", res.String(), err)
 	}
 
+	if imageIdFile != "" {
+		imageId, err := os.ReadFile(imageIdFile)
+		if err != nil {
+			return "", fmt.Errorf("reading image id: %w", err)
+		}
+
+		return strings.TrimSpace(string(imageId)), nil
+	}
+
 	return strings.TrimSpace(res.Stdout), nil
 }
 
+// Runs a Docker buildx build of a given Dockerfile for the platforms of the options, ex) linux/amd64 and linux/arm64,
+// and pushes the resulting multi-arch image to the registry with the given tags. Multi-arch images can't be loaded into
+// the local image store, so the image is pushed as part of the build and the caller must be logged into the registry.
+// Layers are imported from and exported to the external caches of the build cache, which usually are in the registry.
+func (d *docker) BuildxPush(
+	ctx context.Context,
+	cwd string,
+	options BuildOptions,
+	tags []string,
+) error {
+	if d.engine == EnginePodman {
+		return d.podmanManifestPush(ctx, cwd, options, tags)
+	}
+
+	optionArgs, env := d.buildOptions(options)
+	args := []string{
+		"buildx", "build", "-f", options.DockerfilePath, "--platform", strings.Join(options.Platforms, ","),
+	}
+	args = append(args, optionArgs...)
+	args = append(args, options.ExtraArgs...)
+	for _, cacheFrom := range options.Cache.From {
+		args = append(args, "--cache-from", cacheFrom)
+	}
+	for _, cacheTo := range options.Cache.To {
+		args = append(args, "--cache-to", cacheTo)
+	}
+	for _, tag := range tags {
+		args = append(args, "-t", tag)
+	}
+	args = append(args, "--push", options.Context)
+
+	res, err := d.commandRunner.Run(ctx, d.newRunArgs(args...).
+		WithCwd(cwd).
+		WithEnv(env).
+		WithEnrichError(true))
+	if err != nil {
+		return fmt.Errorf("building multi-platform image: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+// Checks whether a docker command failed because docker isn't installed or the docker daemon isn't running
+func isDaemonUnavailable(res exec.RunResult, err error) bool {
+	return errors.Is(err, osexec.ErrNotFound) ||
+		strings.Contains(res.Stderr, "Cannot connect to the Docker daemon") ||
+		strings.Contains(res.Stderr, "Cannot connect to Podman") ||
+		strings.Contains(res.Stderr, "error during connect")
+}
+
+// Podman doesn't support buildx, multi-arch images are built as a manifest list which is then pushed to the registry
+func (d *docker) podmanManifestPush(
+	ctx context.Context,
+	cwd string,
+	options BuildOptions,
+	tags []string,
+) error {
+	podmanPlatforms := make([]string, len(options.Platforms))
+	for i, platform := range options.Platforms {
+		podmanPlatforms[i] = podmanPlatform(platform)
+	}
+
+	args := []string{
+		"build", "-f", options.DockerfilePath, "--platform", strings.Join(podmanPlatforms, ","), "--format", "docker",
+	}
+	optionArgs, env := d.buildOptions(options)
+	args = append(args, optionArgs...)
+	args = append(args, options.ExtraArgs...)
+
+	// Podman caches layers in a repository instead of BuildKit caches
+	for _, cacheFrom := range options.Cache.From {
+		if repository := cacheRepository(cacheFrom); repository != "" {
+			args = append(args, "--cache-from", repository)
+		}
+	}
+	for _, cacheTo := range options.Cache.To {
+		if repository := cacheRepository(cacheTo); repository != "" {
+			args = append(args, "--cache-to", repository)
+		}
+	}
+	args = append(args, "--manifest", tags[0], options.Context)
+
+	res, err := d.commandRunner.Run(ctx, d.newRunArgs(args...).
+		WithCwd(cwd).
+		WithEnv(env).
+		WithEnrichError(true))
+	if err != nil {
+		return fmt.Errorf("building multi-platform image: %s: %w", res.String(), err)
+	}
+
+	for _, tag := range tags {
+		res, err = d.executeCommand(ctx, cwd, "manifest", "push", "--all", tags[0], fmt.Sprintf("docker://%s", tag))
+		if err != nil {
+			return fmt.Errorf("pushing multi-platform image: %s: %w", res.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// Gets the repository of a registry BuildKit cache spec, ex) myregistry.azurecr.io/app for
+// type=registry,ref=myregistry.azurecr.io/app:cache. An empty repository is returned for other kinds of caches.
+func cacheRepository(spec string) string {
+	var cacheType, ref string
+	for _, attribute := range strings.Split(spec, ",") {
+		key, value, _ := strings.Cut(attribute, "=")
+		switch key {
+		case "type":
+			cacheType = value
+		case "ref":
+			ref = value
+		}
+	}
+
+	if cacheType != "registry" || ref == "" {
+		log.Printf("ignoring build cache '%s', only registry caches are supported by podman", spec)
+		return ""
+	}
+
+	// Strips the tag, the colon of a registry port is followed by a path
+	if index := strings.LastIndex(ref, ":"); index > strings.LastIndex(ref, "/") {
+		ref = ref[:index]
+	}
+
+	return ref
+}
+
+// Gets the arguments for the build target stage, build args, labels and secrets shared by the build commands, and the
+// environment of the build command with the values of the secrets. Secret values are passed through the environment so
+// they don't appear in the arguments of the command or in files on disk.
+func (d *docker) buildOptions(options BuildOptions) ([]string, []string) {
+	args := []string{}
+	env := []string{}
+	if options.Target != "" {
+		args = append(args, "--target", options.Target)
+	}
+
+	for _, buildArg := range options.BuildArgs {
+		args = append(args, "--build-arg", buildArg)
+	}
+
+	for _, label := range options.Labels {
+		args = append(args, "--label", label)
+	}
+
+	for _, secret := range options.Secrets {
+		if secret.File != "" && secret.Value == "" {
+			args = append(args, "--secret", fmt.Sprintf("id=%s,src=%s", secret.Id, secret.File))
+			continue
+		}
+
+		envName := secretEnvName(secret.Id)
+		args = append(args, "--secret", fmt.Sprintf("id=%s,env=%s", secret.Id, envName))
+		env = append(env, fmt.Sprintf("%s=%s", envName, secret.Value))
+	}
+
+	// Secrets require BuildKit, which isn't the default builder of older docker versions
+	if len(options.Secrets) > 0 && d.engine == EngineDocker {
+		env = append(env, "DOCKER_BUILDKIT=1")
+	}
+
+	return args, env
+}
+
+var secretEnvNameRegexp = regexp.MustCompile(`[^A-Z0-9_]`)
+
+// Gets the name of the environment variable passing the value of a secret to the build, ex) AZD_BUILD_SECRET_NPM_TOKEN
+func secretEnvName(id string) string {
+	return "AZD_BUILD_SECRET_" + secretEnvNameRegexp.ReplaceAllString(strings.ToUpper(id), "_")
+}
+
+func (d *docker) Pull(ctx context.Context, cwd string, imageName string) error {
+	res, err := d.executeCommand(ctx, cwd, "pull", imageName)
+	if err != nil {
+		return fmt.Errorf("pulling image: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
 func (d *docker) Tag(ctx context.Context, cwd string, imageName string, tag string) error {
 	res, err := d.executeCommand(ctx, cwd, "tag", imageName, tag)
 	if err != nil {
@@ -85,6 +411,45 @@ func (d *docker) Push(ctx context.Context, cwd string, tag string) error {
 	return nil
 }
 
+// Gets the digests of the layers that make up the specified local image, from the base layer up
+func (d *docker) Layers(ctx context.Context, cwd string, imageName string) ([]string, error) {
+	res, err := d.executeCommand(ctx, cwd, "image", "inspect", "--format", "{{json .RootFS.Layers}}", imageName)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting image: %s: %w", res.String(), err)
+	}
+
+	var layers []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(res.Stdout)), &layers); err != nil {
+		return nil, fmt.Errorf("parsing image layers: %w", err)
+	}
+
+	return layers, nil
+}
+
+// Gets the configuration of the specified local image, ex) the ports exposed by the image
+func (d *docker) InspectConfig(ctx context.Context, cwd string, imageName string) (*ImageConfig, error) {
+	res, err := d.executeCommand(ctx, cwd, "image", "inspect", "--format", "{{json .Config}}", imageName)
+	if err != nil {
+		return nil, fmt.Errorf("inspecting image: %s: %w", res.String(), err)
+	}
+
+	var config struct {
+		ExposedPorts map[string]struct{}
+		Env          []string
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(res.Stdout)), &config); err != nil {
+		return nil, fmt.Errorf("parsing image config: %w", err)
+	}
+
+	ports := maps.Keys(config.ExposedPorts)
+	slices.Sort(ports)
+
+	return &ImageConfig{
+		ExposedPorts: ports,
+		Env:          config.Env,
+	}, nil
+}
+
 func (d *docker) versionInfo() tools.VersionInfo {
 	return tools.VersionInfo{
 		MinimumVersion: semver.Version{
@@ -171,20 +536,26 @@ func isSupportedDockerVersion(cliOutput string) (bool, error) {
 	return false, fmt.Errorf("could not determine version from docker version string: %s", version)
 }
 func (d *docker) CheckInstalled(ctx context.Context) (bool, error) {
-	found, err := tools.ToolInPath("docker")
+	found, err := tools.ToolInPath(d.binary)
 	if !found {
 		return false, err
 	}
-	dockerRes, err := tools.ExecuteCommand(ctx, d.commandRunner, "docker", "--version")
+	dockerRes, err := tools.ExecuteCommand(ctx, d.commandRunner, d.binary, "--version")
 	if err != nil {
 		return false, fmt.Errorf("checking %s version: %w", d.Name(), err)
 	}
-	supported, err := isSupportedDockerVersion(dockerRes)
+
+	isSupportedVersion, versionInfo := isSupportedDockerVersion, d.versionInfo()
+	if d.engine == EnginePodman {
+		isSupportedVersion, versionInfo = isSupportedPodmanVersion, podmanVersionInfo()
+	}
+
+	supported, err := isSupportedVersion(dockerRes)
 	if err != nil {
 		return false, err
 	}
 	if !supported {
-		return false, &tools.ErrSemver{ToolName: d.Name(), VersionInfo: d.versionInfo()}
+		return false, &tools.ErrSemver{ToolName: d.Name(), VersionInfo: versionInfo}
 	}
 	return true, nil
 }
@@ -194,13 +565,31 @@ func (d *docker) InstallUrl() string {
 }
 
 func (d *docker) Name() string {
+	if d.engine == EnginePodman {
+		return "Podman"
+	}
+
 	return "Docker"
 }
 
 func (d *docker) executeCommand(ctx context.Context, cwd string, args ...string) (exec.RunResult, error) {
-	runArgs := exec.NewRunArgs("docker", args...).
+	runArgs := d.newRunArgs(args...).
 		WithCwd(cwd).
 		WithEnrichError(true)
 
 	return d.commandRunner.Run(ctx, runArgs)
 }
+
+// Creates the arguments running the command against the context of the tool, when set
+func (d *docker) newRunArgs(args ...string) exec.RunArgs {
+	if d.context == "" {
+		return exec.NewRunArgs(d.binary, args...)
+	}
+
+	contextFlag := "--context"
+	if d.engine == EnginePodman {
+		contextFlag = "--connection"
+	}
+
+	return exec.NewRunArgs(d.binary, append([]string{contextFlag, d.context}, args...)...)
+}
@@ -1,15 +1,19 @@
 package docker
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 )
 
 func Test_DockerBuild(t *testing.T) {
@@ -46,7 +50,11 @@ func Test_DockerBuild(t *testing.T) {
 			}, nil
 		})
 
-		result, err := docker.Build(context.Background(), cwd, dockerFile, platform, dockerContext)
+		result, err := docker.Build(context.Background(), cwd, BuildOptions{
+			DockerfilePath: dockerFile,
+			Platforms:      []string{platform},
+			Context:        dockerContext,
+		}, nil)
 
 		require.Equal(t, true, ran)
 		require.Nil(t, err)
@@ -82,7 +90,11 @@ func Test_DockerBuild(t *testing.T) {
 			}, errors.New(customErrorMessage)
 		})
 
-		result, err := docker.Build(context.Background(), cwd, dockerFile, platform, dockerContext)
+		result, err := docker.Build(context.Background(), cwd, BuildOptions{
+			DockerfilePath: dockerFile,
+			Platforms:      []string{platform},
+			Context:        dockerContext,
+		}, nil)
 
 		require.Equal(t, true, ran)
 		require.NotNil(t, err)
@@ -127,13 +139,256 @@ func Test_DockerBuildEmptyPlatform(t *testing.T) {
 		}, nil
 	})
 
-	result, err := docker.Build(context.Background(), cwd, dockerFile, "", dockerContext)
+	result, err := docker.Build(context.Background(), cwd, BuildOptions{
+		DockerfilePath: dockerFile,
+		Context:        dockerContext,
+	}, nil)
 
 	require.Equal(t, true, ran)
 	require.Nil(t, err)
 	require.Equal(t, "Docker build output", result)
 }
 
+func Test_DockerBuildWithTargetAndBuildArgs(t *testing.T) {
+	ran := false
+
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+
+		require.Equal(t, []string{
+			"build",
+			"-q",
+			"-f", "./Dockerfile",
+			"--platform", "amd64",
+			"--target", "production",
+			"--build-arg", "VERSION=1.0",
+			"--label", "org.opencontainers.image.revision=abc123",
+			"--network=host",
+			"--ssh", "default",
+			".",
+		}, args.Args)
+
+		return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+	})
+
+	result, err := docker.Build(
+		context.Background(),
+		".",
+		BuildOptions{
+			DockerfilePath: "./Dockerfile",
+			Platforms:      []string{"amd64"},
+			Target:         "production",
+			Context:        ".",
+			BuildArgs:      []string{"VERSION=1.0"},
+			Labels:         []string{"org.opencontainers.image.revision=abc123"},
+			ExtraArgs:      []string{"--network=host", "--ssh", "default"},
+		},
+		nil,
+	)
+
+	require.True(t, ran)
+	require.NoError(t, err)
+	require.Equal(t, "IMAGE_ID", result)
+}
+
+func Test_DockerBuildStreamsProgress(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.NotContains(t, args.Args, "-q")
+		require.Equal(t, []string{"-f", "./Dockerfile", "--platform", "amd64"}, args.Args[1:5])
+
+		iidIndex := slices.Index(args.Args, "--iidfile")
+		require.Greater(t, iidIndex, 0)
+		require.NoError(t, os.WriteFile(args.Args[iidIndex+1], []byte("sha256:IMAGE_ID\n"), osutil.PermissionFile))
+
+		_, _ = args.Stderr.Write([]byte("#1 [internal] load build definition from Dockerfile\n"))
+		return exec.NewRunResult(0, "", "#1 [internal] load build definition from Dockerfile\n"), nil
+	})
+
+	progress := &bytes.Buffer{}
+	result, err := docker.Build(context.Background(), ".", BuildOptions{
+		DockerfilePath: "./Dockerfile",
+		Platforms:      []string{"amd64"},
+		Context:        ".",
+	}, progress)
+	require.NoError(t, err)
+	require.Equal(t, "sha256:IMAGE_ID", result)
+	require.Equal(t, "#1 [internal] load build definition from Dockerfile\n", progress.String())
+}
+
+func Test_DockerBuildSecrets(t *testing.T) {
+	var runArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runArgs = args
+		return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+	})
+
+	_, err := docker.Build(context.Background(), ".", BuildOptions{
+		DockerfilePath: "./Dockerfile",
+		Platforms:      []string{"amd64"},
+		Context:        ".",
+		Secrets: []BuildSecret{
+			{Id: "npm-token", Value: "TOKEN"},
+			{Id: "nuget", File: "/home/user/.nuget/NuGet.Config"},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	// Secret values are passed through the environment, never the arguments of the command
+	require.Equal(t, []string{
+		"build", "-q", "-f", "./Dockerfile", "--platform", "amd64",
+		"--secret", "id=npm-token,env=AZD_BUILD_SECRET_NPM_TOKEN",
+		"--secret", "id=nuget,src=/home/user/.nuget/NuGet.Config",
+		".",
+	}, runArgs.Args)
+	require.Equal(t, []string{"AZD_BUILD_SECRET_NPM_TOKEN=TOKEN", "DOCKER_BUILDKIT=1"}, runArgs.Env)
+}
+
+func Test_DockerBuildDaemonUnavailable(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(
+			1,
+			"",
+			"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+		), errors.New("exit code: 1")
+	})
+
+	_, err := docker.Build(context.Background(), ".", BuildOptions{
+		DockerfilePath: "./Dockerfile",
+		Platforms:      []string{"amd64"},
+		Context:        ".",
+	}, nil)
+	require.ErrorIs(t, err, ErrDaemonUnavailable)
+}
+
+func Test_DockerBuildxPush(t *testing.T) {
+	ran := false
+
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker buildx build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+
+		require.Equal(t, []string{
+			"buildx", "build",
+			"-f", "./Dockerfile",
+			"--platform", "linux/amd64,linux/arm64",
+			"--target", "production",
+			"--build-arg", "VERSION=1.0",
+			"--label", "org.opencontainers.image.revision=abc123",
+			"--progress=plain",
+			"--cache-from", "type=registry,ref=registry.azurecr.io/app:buildcache",
+			"--cache-to", "type=registry,ref=registry.azurecr.io/app:buildcache,mode=max",
+			"-t", "registry.azurecr.io/app:tag",
+			"--push",
+			".",
+		}, args.Args)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := docker.BuildxPush(
+		context.Background(),
+		".",
+		BuildOptions{
+			DockerfilePath: "./Dockerfile",
+			Platforms:      []string{"linux/amd64", "linux/arm64"},
+			Target:         "production",
+			Context:        ".",
+			BuildArgs:      []string{"VERSION=1.0"},
+			Labels:         []string{"org.opencontainers.image.revision=abc123"},
+			ExtraArgs:      []string{"--progress=plain"},
+			Cache: BuildCache{
+				From: []string{"type=registry,ref=registry.azurecr.io/app:buildcache"},
+				To:   []string{"type=registry,ref=registry.azurecr.io/app:buildcache,mode=max"},
+			},
+		},
+		[]string{"registry.azurecr.io/app:tag"},
+	)
+
+	require.True(t, ran)
+	require.NoError(t, err)
+}
+
+func Test_CacheRepository(t *testing.T) {
+	require.Equal(t, "registry.azurecr.io/app", cacheRepository("type=registry,ref=registry.azurecr.io/app:buildcache"))
+	require.Equal(t, "localhost:5000/app", cacheRepository("type=registry,ref=localhost:5000/app,mode=max"))
+	require.Equal(t, "", cacheRepository("type=gha"))
+}
+
+func Test_DockerPull(t *testing.T) {
+	var runArgs exec.RunArgs
+
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker pull")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := docker.Pull(context.Background(), ".", "mcr.microsoft.com/azuredocs/containerapps-helloworld:latest")
+	require.NoError(t, err)
+	require.Equal(t, []string{"pull", "mcr.microsoft.com/azuredocs/containerapps-helloworld:latest"}, runArgs.Args)
+}
+
+func Test_DockerWithContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		want   []string
+	}{
+		{name: "Docker", engine: "docker", want: []string{"--context", "builder", "pull", "nginx"}},
+		{name: "Podman", engine: "podman", want: []string{"--connection", "builder", "pull", "nginx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ContainerEngineEnvVarName, tt.engine)
+
+			var runArgs exec.RunArgs
+			mockContext := mocks.NewMockContext(context.Background())
+			docker := NewDockerWithContext(mockContext.CommandRunner, "builder")
+
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return strings.Contains(command, "pull")
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				runArgs = args
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+			require.NoError(t, docker.Pull(context.Background(), ".", "nginx"))
+			require.Equal(t, tt.engine, runArgs.Cmd)
+			require.Equal(t, tt.want, runArgs.Args)
+		})
+	}
+}
+
 func Test_DockerTag(t *testing.T) {
 	cwd := "."
 	imageName := "image-name"
@@ -285,6 +540,67 @@ func Test_DockerPush(t *testing.T) {
 	})
 }
 
+func Test_DockerLayers(t *testing.T) {
+	cwd := "."
+	tag := "customTag"
+
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, "docker", args.Cmd)
+		require.Equal(t, cwd, args.Cwd)
+		require.Equal(t, []string{
+			"image", "inspect",
+			"--format", "{{json .RootFS.Layers}}",
+			tag,
+		}, args.Args)
+
+		return exec.RunResult{
+			Stdout:   "[\"sha256:base\",\"sha256:app\"]\n",
+			Stderr:   "",
+			ExitCode: 0,
+		}, nil
+	})
+
+	layers, err := docker.Layers(context.Background(), cwd, tag)
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"sha256:base", "sha256:app"}, layers)
+}
+
+func Test_DockerInspectConfig(t *testing.T) {
+	cwd := "."
+	tag := "customTag"
+
+	mockContext := mocks.NewMockContext(context.Background())
+	docker := NewDocker(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "docker image inspect")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{
+			"image", "inspect",
+			"--format", "{{json .Config}}",
+			tag,
+		}, args.Args)
+
+		return exec.RunResult{
+			Stdout: `{"ExposedPorts":{"8080/tcp":{},"443/tcp":{}},"Env":["PATH=/usr/bin","PORT=8080"]}` + "\n",
+		}, nil
+	})
+
+	config, err := docker.InspectConfig(context.Background(), cwd, tag)
+
+	require.NoError(t, err)
+	require.Equal(t, &ImageConfig{
+		ExposedPorts: []string{"443/tcp", "8080/tcp"},
+		Env:          []string{"PATH=/usr/bin", "PORT=8080"},
+	}, config)
+}
+
 func Test_DockerLogin(t *testing.T) {
 	cwd := "."
 
@@ -0,0 +1,88 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package docker
+
+import (
+	"log"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/blang/semver/v4"
+)
+
+// ContainerEngineEnvVarName is the environment variable selecting the container engine used to build and push images.
+// The value is the name of an engine, ex) podman, or the path to the binary of an engine compatible with the docker CLI.
+const ContainerEngineEnvVarName = "AZD_CONTAINER_ENGINE"
+
+// ContainerEngine is the kind of container engine running the docker commands
+type ContainerEngine string
+
+const (
+	// The docker CLI and docker daemon
+	EngineDocker ContainerEngine = "docker"
+	// The podman CLI, which is compatible with the docker CLI and runs without a daemon, in rootless mode by default
+	EnginePodman ContainerEngine = "podman"
+)
+
+// Resolves the container engine and the binary used to run its commands.
+// The engine set in [ContainerEngineEnvVarName] is used when set. Otherwise, docker is used when it is installed and
+// podman is used as a drop-in replacement when only podman is installed.
+func resolveContainerEngine() (ContainerEngine, string) {
+	if value := strings.TrimSpace(os.Getenv(ContainerEngineEnvVarName)); value != "" {
+		engine := EngineDocker
+		if strings.Contains(strings.ToLower(filepath.Base(value)), string(EnginePodman)) {
+			engine = EnginePodman
+		}
+
+		return engine, value
+	}
+
+	if _, err := osexec.LookPath(string(EngineDocker)); err != nil {
+		if _, err := osexec.LookPath(string(EnginePodman)); err == nil {
+			log.Printf("docker not found, using podman as the container engine")
+			return EnginePodman, string(EnginePodman)
+		}
+	}
+
+	return EngineDocker, string(EngineDocker)
+}
+
+// EngineInstalled checks whether the binary of the container engine resolved from the environment is installed
+func EngineInstalled() bool {
+	_, binary := resolveContainerEngine()
+	_, err := osexec.LookPath(binary)
+	return err == nil
+}
+
+// Podman requires the operating system in platforms, ex) linux/amd64 instead of amd64
+func podmanPlatform(platform string) string {
+	if platform != "" && !strings.Contains(platform, "/") {
+		return "linux/" + platform
+	}
+
+	return platform
+}
+
+func podmanVersionInfo() tools.VersionInfo {
+	return tools.VersionInfo{
+		MinimumVersion: semver.Version{
+			Major: 3,
+			Minor: 0,
+			Patch: 0},
+		UpdateCommand: "Visit https://podman.io/docs/installation to upgrade",
+	}
+}
+
+// Checks the output of "podman --version", ex) podman version 4.5.1
+func isSupportedPodmanVersion(cliOutput string) (bool, error) {
+	version, err := tools.ExtractVersion(cliOutput)
+	if err != nil {
+		return false, err
+	}
+
+	return version.GTE(podmanVersionInfo().MinimumVersion), nil
+}
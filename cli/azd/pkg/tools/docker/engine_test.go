@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package docker
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ResolveContainerEngine(t *testing.T) {
+	t.Run("Podman", func(t *testing.T) {
+		t.Setenv(ContainerEngineEnvVarName, "podman")
+
+		engine, binary := resolveContainerEngine()
+		require.Equal(t, EnginePodman, engine)
+		require.Equal(t, "podman", binary)
+	})
+
+	t.Run("CustomBinary", func(t *testing.T) {
+		t.Setenv(ContainerEngineEnvVarName, "/usr/local/bin/podman-remote")
+
+		engine, binary := resolveContainerEngine()
+		require.Equal(t, EnginePodman, engine)
+		require.Equal(t, "/usr/local/bin/podman-remote", binary)
+	})
+
+	t.Run("DockerCompatibleBinary", func(t *testing.T) {
+		t.Setenv(ContainerEngineEnvVarName, "nerdctl")
+
+		engine, binary := resolveContainerEngine()
+		require.Equal(t, EngineDocker, engine)
+		require.Equal(t, "nerdctl", binary)
+	})
+}
+
+func Test_PodmanBuild(t *testing.T) {
+	t.Setenv(ContainerEngineEnvVarName, "podman")
+
+	mockContext := mocks.NewMockContext(context.Background())
+	podman := NewDocker(mockContext.CommandRunner)
+	require.Equal(t, "Podman", podman.Name())
+
+	var buildArgs []string
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "podman build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		buildArgs = args.Args
+		return exec.NewRunResult(0, "IMAGE_ID", ""), nil
+	})
+
+	result, err := podman.Build(context.Background(), ".", BuildOptions{
+		DockerfilePath: "./Dockerfile",
+		Platforms:      []string{"amd64"},
+		Context:        ".",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, "IMAGE_ID", result)
+
+	// Podman requires the operating system in the platform, and builds images in the docker format so instructions
+	// such as HEALTHCHECK, which aren't part of the OCI image format, are preserved
+	require.Equal(t, []string{
+		"build", "-q", "-f", "./Dockerfile", "--platform", "linux/amd64", "--format", "docker", ".",
+	}, buildArgs)
+}
+
+func Test_PodmanManifestPush(t *testing.T) {
+	t.Setenv(ContainerEngineEnvVarName, "podman")
+
+	mockContext := mocks.NewMockContext(context.Background())
+	podman := NewDocker(mockContext.CommandRunner)
+
+	commands := [][]string{}
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == "podman"
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		commands = append(commands, args.Args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := podman.BuildxPush(
+		context.Background(),
+		".",
+		BuildOptions{
+			DockerfilePath: "./Dockerfile",
+			Platforms:      []string{"linux/amd64", "arm64"},
+			Context:        ".",
+		},
+		[]string{"registry.azurecr.io/app:tag"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{
+			"build", "-f", "./Dockerfile", "--platform", "linux/amd64,linux/arm64", "--format", "docker",
+			"--manifest", "registry.azurecr.io/app:tag", ".",
+		},
+		{"manifest", "push", "--all", "registry.azurecr.io/app:tag", "docker://registry.azurecr.io/app:tag"},
+	}, commands)
+}
+
+func Test_IsSupportedPodmanVersion(t *testing.T) {
+	supported, err := isSupportedPodmanVersion("podman version 4.5.1")
+	require.NoError(t, err)
+	require.True(t, supported)
+
+	supported, err = isSupportedPodmanVersion("podman version 2.2.1")
+	require.NoError(t, err)
+	require.False(t, supported)
+}
@@ -6,19 +6,36 @@ package dotnet
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
 )
 
+// PublishOptions are the options of the deployment published by dotnet publish
+type PublishOptions struct {
+	// The runtime identifier the application is published for, ex) linux-x64. Publishes for the runtime of the project
+	// when empty.
+	Runtime string
+	// Whether the .NET runtime is published with the application, uses the default of the project when nil
+	SelfContained *bool
+	// Whether the unused code of the application is trimmed, with the PublishTrimmed property
+	Trimmed bool
+	// Whether the application is compiled ahead of time to native code, with the PublishAot property
+	Aot bool
+}
+
 type DotNetCli interface {
 	tools.ExternalTool
 	Restore(ctx context.Context, project string) error
 	Build(ctx context.Context, project string, configuration string, output string) error
-	Publish(ctx context.Context, project string, configuration string, output string) error
+	Publish(ctx context.Context, project string, configuration string, output string, options PublishOptions) error
+	Test(ctx context.Context, project string, configuration string) error
 	InitializeSecret(ctx context.Context, project string) error
 	SetSecret(ctx context.Context, key string, value string, project string) error
+	// Gets the version of the .NET SDK building the projects of the directory, which honors their global.json file
+	Version(ctx context.Context, cwd string) (string, error)
 }
 
 type dotNetCli struct {
@@ -64,7 +81,7 @@ func (cli *dotNetCli) CheckInstalled(ctx context.Context) (bool, error) {
 }
 
 func (cli *dotNetCli) Restore(ctx context.Context, project string) error {
-	runArgs := exec.NewRunArgs("dotnet", "restore", project)
+	runArgs := exec.NewRunArgs("dotnet", "restore", project).WithEnv(nugetPackagesEnv())
 	res, err := cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("dotnet restore on project '%s' failed: %s: %w", project, res.String(), err)
@@ -73,7 +90,7 @@ func (cli *dotNetCli) Restore(ctx context.Context, project string) error {
 }
 
 func (cli *dotNetCli) Build(ctx context.Context, project string, configuration string, output string) error {
-	runArgs := exec.NewRunArgs("dotnet", "build", project)
+	runArgs := exec.NewRunArgs("dotnet", "build", project).WithEnv(nugetPackagesEnv())
 	if configuration != "" {
 		runArgs = runArgs.AppendParams("-c", configuration)
 	}
@@ -89,8 +106,14 @@ func (cli *dotNetCli) Build(ctx context.Context, project string, configuration s
 	return nil
 }
 
-func (cli *dotNetCli) Publish(ctx context.Context, project string, configuration string, output string) error {
-	runArgs := exec.NewRunArgs("dotnet", "publish", project)
+func (cli *dotNetCli) Publish(
+	ctx context.Context,
+	project string,
+	configuration string,
+	output string,
+	options PublishOptions,
+) error {
+	runArgs := exec.NewRunArgs("dotnet", "publish", project).WithEnv(nugetPackagesEnv())
 	if configuration != "" {
 		runArgs = runArgs.AppendParams("-c", configuration)
 	}
@@ -99,6 +122,22 @@ func (cli *dotNetCli) Publish(ctx context.Context, project string, configuration
 		runArgs = runArgs.AppendParams("--output", output)
 	}
 
+	if options.Runtime != "" {
+		runArgs = runArgs.AppendParams("--runtime", options.Runtime)
+	}
+
+	if options.SelfContained != nil {
+		runArgs = runArgs.AppendParams("--self-contained", fmt.Sprint(*options.SelfContained))
+	}
+
+	if options.Trimmed {
+		runArgs = runArgs.AppendParams("-p:PublishTrimmed=true")
+	}
+
+	if options.Aot {
+		runArgs = runArgs.AppendParams("-p:PublishAot=true")
+	}
+
 	res, err := cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("dotnet publish on project '%s' failed: %s: %w", project, res.String(), err)
@@ -106,6 +145,19 @@ func (cli *dotNetCli) Publish(ctx context.Context, project string, configuration
 	return nil
 }
 
+func (cli *dotNetCli) Test(ctx context.Context, project string, configuration string) error {
+	runArgs := exec.NewRunArgs("dotnet", "test", project).WithEnv(nugetPackagesEnv())
+	if configuration != "" {
+		runArgs = runArgs.AppendParams("-c", configuration)
+	}
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("dotnet test on project '%s' failed: %s: %w", project, res.String(), err)
+	}
+	return nil
+}
+
 func (cli *dotNetCli) InitializeSecret(ctx context.Context, project string) error {
 	runArgs := exec.NewRunArgs("dotnet", "user-secrets", "init", "--project", project)
 	res, err := cli.commandRunner.Run(ctx, runArgs)
@@ -124,6 +176,20 @@ func (cli *dotNetCli) SetSecret(ctx context.Context, key string, value string, p
 	return nil
 }
 
+func (cli *dotNetCli) Version(ctx context.Context, cwd string) (string, error) {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("dotnet", "--version").WithCwd(cwd))
+	if err != nil {
+		return "", fmt.Errorf("checking %s version: %s: %w", cli.Name(), res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// Builds and publishes restore packages implicitly, so all of them use the NuGet packages folder of the restore cache
+func nugetPackagesEnv() []string {
+	return tools.RestoreCacheEnv(tools.RestoreCacheNuGet, "NUGET_PACKAGES")
+}
+
 func NewDotNetCli(commandRunner exec.CommandRunner) DotNetCli {
 	return &dotNetCli{
 		commandRunner: commandRunner,
@@ -23,6 +23,12 @@ type GitCli interface {
 	AddRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error
 	UpdateRemote(ctx context.Context, repositoryPath string, remoteName string, remoteUrl string) error
 	GetCurrentBranch(ctx context.Context, repositoryPath string) (string, error)
+	GetCurrentCommit(ctx context.Context, repositoryPath string) (string, error)
+	DiffNameStatus(ctx context.Context, repositoryPath string, commit string) (string, error)
+	ListUntrackedFiles(ctx context.Context, repositoryPath string) (string, error)
+	IsDirty(ctx context.Context, repositoryPath string, excludePaths ...string) (bool, error)
+	CreateTag(ctx context.Context, repositoryPath string, name string, commit string, message string) error
+	PushTag(ctx context.Context, repositoryPath string, remoteName string, name string) error
 	AddFile(ctx context.Context, repositoryPath string, filespec string) error
 	Commit(ctx context.Context, repositoryPath string, message string) error
 	PushUpstream(ctx context.Context, repositoryPath string, origin string, branch string) error
@@ -131,6 +137,101 @@ func (cli *gitCli) GetCurrentBranch(ctx context.Context, repositoryPath string)
 	return strings.TrimSpace(res.Stdout), nil
 }
 
+func (cli *gitCli) GetCurrentCommit(ctx context.Context, repositoryPath string) (string, error) {
+	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "rev-parse", "HEAD")
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return "", ErrNotRepository
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get current commit: %s: %w", res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// Lists the files under the repository path that changed in the working tree since the specified commit,
+// one `{status}\t{path}` entry per line with paths relative to the repository path
+func (cli *gitCli) DiffNameStatus(ctx context.Context, repositoryPath string, commit string) (string, error) {
+	runArgs := exec.NewRunArgs(
+		"git", "-C", repositoryPath, "diff", "--name-status", "--relative", "--no-renames", commit, "--", ".",
+	)
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return "", ErrNotRepository
+	} else if err != nil {
+		return "", fmt.Errorf("failed to diff against commit %s: %s: %w", commit, res.String(), err)
+	}
+
+	return res.Stdout, nil
+}
+
+// Lists the files under the repository path that are neither tracked nor ignored, one path per line
+func (cli *gitCli) ListUntrackedFiles(ctx context.Context, repositoryPath string) (string, error) {
+	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "ls-files", "--others", "--exclude-standard")
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return "", ErrNotRepository
+	} else if err != nil {
+		return "", fmt.Errorf("failed to list untracked files: %s: %w", res.String(), err)
+	}
+
+	return res.Stdout, nil
+}
+
+// Checks whether the working tree of the repository has uncommitted changes, including untracked files. Changes to the
+// excluded paths, relative to the repository path, are ignored.
+func (cli *gitCli) IsDirty(ctx context.Context, repositoryPath string, excludePaths ...string) (bool, error) {
+	args := []string{"-C", repositoryPath, "status", "--porcelain"}
+	if len(excludePaths) > 0 {
+		// :/ matches the whole working tree, not only the repository path
+		args = append(args, "--", ":/")
+		for _, excludePath := range excludePaths {
+			args = append(args, ":(exclude)"+excludePath)
+		}
+	}
+
+	runArgs := exec.NewRunArgs("git", args...)
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if notGitRepositoryRegex.MatchString(res.Stderr) {
+		return false, ErrNotRepository
+	} else if err != nil {
+		return false, fmt.Errorf("failed to get status: %s: %w", res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout) != "", nil
+}
+
+// Creates an annotated tag on the commit
+func (cli *gitCli) CreateTag(
+	ctx context.Context,
+	repositoryPath string,
+	name string,
+	commit string,
+	message string,
+) error {
+	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "tag", "--annotate", name, "--message", message, commit)
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("failed to create tag %s: %s: %w", name, res.String(), err)
+	}
+
+	return nil
+}
+
+// Pushes the tag to the remote
+func (cli *gitCli) PushTag(ctx context.Context, repositoryPath string, remoteName string, name string) error {
+	runArgs := exec.
+		NewRunArgs("git", "-C", repositoryPath, "push", "--quiet", remoteName, "refs/tags/"+name).
+		WithInteractive(true)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("failed to push tag %s: %s: %w", name, res.String(), err)
+	}
+
+	return nil
+}
+
 func (cli *gitCli) InitRepo(ctx context.Context, repositoryPath string) error {
 	runArgs := exec.NewRunArgs("git", "-C", repositoryPath, "init")
 	res, err := cli.commandRunner.Run(ctx, runArgs)
@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package golang
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// BuildOptions are the options of a go build
+type BuildOptions struct {
+	// The package built, ex) ./cmd/api
+	Package string
+	// The path of the binary written by the build
+	Output string
+	// The flags passed to the linker, ex) -s -w -X main.version=1.0.0
+	Ldflags string
+	// The environment variables of the build, ex) GOOS=linux
+	Env []string
+}
+
+// GoCli builds Go modules with the go toolchain
+type GoCli interface {
+	tools.ExternalTool
+	// Downloads the dependencies of the module into the module cache
+	ModDownload(ctx context.Context, cwd string) error
+	// Builds the package into a binary
+	Build(ctx context.Context, cwd string, options BuildOptions) error
+	// Runs the tests of all the packages of the module
+	Test(ctx context.Context, cwd string) error
+	// Gets the version of the go toolchain building the module, ex) go1.22.1, which honors the toolchain directive of
+	// the go.mod file of the module
+	Version(ctx context.Context, cwd string) (string, error)
+}
+
+type goCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the go CLI
+func NewGoCli(commandRunner exec.CommandRunner) GoCli {
+	return &goCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *goCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("go")
+}
+
+func (cli *goCli) InstallUrl() string {
+	return "https://go.dev/doc/install"
+}
+
+func (cli *goCli) Name() string {
+	return "Go"
+}
+
+func (cli *goCli) ModDownload(ctx context.Context, cwd string) error {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("go", "mod", "download").WithCwd(cwd))
+	if err != nil {
+		return fmt.Errorf("downloading go modules: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *goCli) Build(ctx context.Context, cwd string, options BuildOptions) error {
+	args := []string{"build", "-o", options.Output}
+	if options.Ldflags != "" {
+		args = append(args, "-ldflags", options.Ldflags)
+	}
+
+	args = append(args, options.Package)
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("go", args...).WithCwd(cwd).WithEnv(options.Env))
+	if err != nil {
+		return fmt.Errorf("building go package '%s': %s: %w", options.Package, res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *goCli) Test(ctx context.Context, cwd string) error {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("go", "test", "./...").WithCwd(cwd))
+	if err != nil {
+		return fmt.Errorf("testing go module: %s: %w", res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *goCli) Version(ctx context.Context, cwd string) (string, error) {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("go", "env", "GOVERSION").WithCwd(cwd))
+	if err != nil {
+		return "", fmt.Errorf("checking go version: %s: %w", res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package golang
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GoBuild(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	goCli := NewGoCli(mockContext.CommandRunner)
+
+	ran := false
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "go build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+		require.Equal(t, "./src/api", args.Cwd)
+		require.Equal(t, []string{"build", "-o", "bin/api", "-ldflags", "-s -w", "./cmd/api"}, args.Args)
+		require.Equal(t, []string{"GOOS=linux"}, args.Env)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := goCli.Build(*mockContext.Context, "./src/api", BuildOptions{
+		Package: "./cmd/api",
+		Output:  "bin/api",
+		Ldflags: "-s -w",
+		Env:     []string{"GOOS=linux"},
+	})
+	require.NoError(t, err)
+	require.True(t, ran)
+}
+
+func Test_GoModDownload(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	goCli := NewGoCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "go mod download")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		return exec.NewRunResult(1, "", "go: missing go.sum entry"), errors.New("exit code: 1")
+	})
+
+	err := goCli.ModDownload(*mockContext.Context, "./src/api")
+	require.ErrorContains(t, err, "missing go.sum entry")
+}
+
+func Test_GoVersion(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	goCli := NewGoCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "go env GOVERSION")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, "./src/api", args.Cwd)
+		return exec.NewRunResult(0, "go1.22.1\n", ""), nil
+	})
+
+	version, err := goCli.Version(*mockContext.Context, "./src/api")
+	require.NoError(t, err)
+	require.Equal(t, "go1.22.1", version)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package gradle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	osexec "os/exec"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+type GradleCli interface {
+	tools.ExternalTool
+	SetPath(projectPath string, rootProjectPath string)
+	ResolveDependencies(ctx context.Context, projectPath string) error
+	Compile(ctx context.Context, projectPath string) error
+	Package(ctx context.Context, projectPath string) error
+	Test(ctx context.Context, projectPath string) error
+}
+
+type gradleCli struct {
+	commandRunner   exec.CommandRunner
+	projectPath     string
+	rootProjectPath string
+
+	// Lazily initialized. Access through gradleCmd.
+	gradleCmdStr  string
+	gradleCmdOnce sync.Once
+	gradleCmdErr  error
+}
+
+func (g *gradleCli) Name() string {
+	return "Gradle"
+}
+
+func (g *gradleCli) InstallUrl() string {
+	return "https://gradle.org/install"
+}
+
+func (g *gradleCli) CheckInstalled(ctx context.Context) (bool, error) {
+	_, err := g.gradleCmd()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (g *gradleCli) SetPath(projectPath string, rootProjectPath string) {
+	g.projectPath = projectPath
+	g.rootProjectPath = rootProjectPath
+}
+
+func (g *gradleCli) gradleCmd() (string, error) {
+	g.gradleCmdOnce.Do(func() {
+		gradleCmd, err := getGradlePath(g.projectPath, g.rootProjectPath)
+		if err != nil {
+			g.gradleCmdErr = err
+		} else {
+			g.gradleCmdStr = gradleCmd
+		}
+	})
+
+	if g.gradleCmdErr != nil {
+		return "", g.gradleCmdErr
+	}
+
+	return g.gradleCmdStr, nil
+}
+
+func getGradlePath(projectPath string, rootProjectPath string) (string, error) {
+	gradlew, err := getGradleWrapperPath(projectPath, rootProjectPath)
+	if gradlew != "" {
+		return gradlew, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed finding gradlew in repository path: %w", err)
+	}
+
+	gradle, err := osexec.LookPath("gradle")
+	if err == nil {
+		return gradle, nil
+	}
+
+	if !errors.Is(err, osexec.ErrNotFound) {
+		return "", fmt.Errorf("failed looking up gradle in PATH: %w", err)
+	}
+
+	return "", errors.New(
+		"gradle could not be found. Install either Gradle or Gradle Wrapper by " +
+			"visiting https://gradle.org/install/ or https://docs.gradle.org/current/userguide/gradle_wrapper.html",
+	)
+}
+
+// getGradleWrapperPath finds the path to gradlew in the project directory, up to the root project directory. Multi-project
+// builds typically have a single wrapper in the directory of the root project.
+//
+// An error is returned if an unexpected error occurred while finding.
+// If gradlew is not found, an empty string is returned with
+// no error.
+func getGradleWrapperPath(projectPath string, rootProjectPath string) (string, error) {
+	searchDir, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := filepath.Abs(rootProjectPath)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		gradlew, err := osexec.LookPath(filepath.Join(searchDir, "gradlew"))
+		if err == nil {
+			log.Printf("found gradlew as: %s\n", gradlew)
+			return gradlew, nil
+		}
+
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+
+		searchDir = filepath.Dir(searchDir)
+
+		// Past root, terminate search and return not found
+		if len(searchDir) < len(root) {
+			return "", nil
+		}
+	}
+}
+
+// Runs the tasks of the Gradle build of the project
+func (cli *gradleCli) run(ctx context.Context, projectPath string, tasks ...string) error {
+	gradleCmd, err := cli.gradleCmd()
+	if err != nil {
+		return err
+	}
+
+	// The plain console keeps the output of the build readable in logs, since the output isn't displayed in a terminal
+	args := append([]string{"--console=plain"}, tasks...)
+	runArgs := exec.NewRunArgs(gradleCmd, args...).
+		WithCwd(projectPath).
+		WithEnv(tools.RestoreCacheEnv(tools.RestoreCacheGradle, "GRADLE_USER_HOME"))
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("gradle %s on project '%s' failed: %s: %w", tasks[0], projectPath, res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *gradleCli) ResolveDependencies(ctx context.Context, projectPath string) error {
+	return cli.run(ctx, projectPath, "dependencies")
+}
+
+func (cli *gradleCli) Compile(ctx context.Context, projectPath string) error {
+	return cli.run(ctx, projectPath, "classes")
+}
+
+func (cli *gradleCli) Package(ctx context.Context, projectPath string) error {
+	// The assemble task doesn't run the tests, unlike the build task
+	return cli.run(ctx, projectPath, "assemble")
+}
+
+func (cli *gradleCli) Test(ctx context.Context, projectPath string) error {
+	return cli.run(ctx, projectPath, "test")
+}
+
+func NewGradleCli(commandRunner exec.CommandRunner) GradleCli {
+	return &gradleCli{
+		commandRunner: commandRunner,
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package gradle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/ostest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getGradlePath(t *testing.T) {
+	rootPath := t.TempDir()
+	sourcePath := filepath.Join(rootPath, "src")
+	projectPath := filepath.Join(sourcePath, "api")
+	pathDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(projectPath, 0755))
+	ostest.Unsetenv(t, "PATH")
+
+	tests := []struct {
+		name       string
+		gradlewDir []string
+		gradleDir  []string
+		envVar     map[string]string
+		want       string
+		wantErr    bool
+	}{
+		{name: "GradlewProjectPath", gradlewDir: []string{projectPath}, want: filepath.Join(projectPath, gradlewWithExt())},
+		{name: "GradlewRootPath", gradlewDir: []string{rootPath}, want: filepath.Join(rootPath, gradlewWithExt())},
+		{name: "GradlewFirst", gradlewDir: []string{rootPath}, want: filepath.Join(rootPath, gradlewWithExt()),
+			gradleDir: []string{pathDir}, envVar: map[string]string{"PATH": pathDir}},
+		{
+			name:      "Gradle",
+			gradleDir: []string{pathDir},
+			envVar:    map[string]string{"PATH": pathDir},
+			want:      filepath.Join(pathDir, gradleWithExt()),
+		},
+		{name: "NotFound", want: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placeExecutable(t, gradlewWithExt(), tt.gradlewDir...)
+			placeExecutable(t, gradleWithExt(), tt.gradleDir...)
+			ostest.Setenvs(t, tt.envVar)
+
+			actual, err := getGradlePath(projectPath, rootPath)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, actual)
+		})
+	}
+}
+
+func Test_GradleCli_Package(t *testing.T) {
+	projectPath := t.TempDir()
+	placeExecutable(t, gradlewWithExt(), projectPath)
+	cacheDir := t.TempDir()
+	t.Setenv(tools.RestoreCacheDirEnvVarName, cacheDir)
+
+	var runArgs exec.RunArgs
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return true
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		runArgs = args
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	cli := NewGradleCli(mockContext.CommandRunner)
+	cli.SetPath(projectPath, projectPath)
+	require.NoError(t, cli.Package(*mockContext.Context, projectPath))
+
+	require.Equal(t, filepath.Join(projectPath, gradlewWithExt()), runArgs.Cmd)
+	require.Equal(t, []string{"--console=plain", "assemble"}, runArgs.Args)
+	require.Equal(t, projectPath, runArgs.Cwd)
+	require.Equal(t, []string{"GRADLE_USER_HOME=" + filepath.Join(cacheDir, tools.RestoreCacheGradle)}, runArgs.Env)
+}
+
+func placeExecutable(t *testing.T, name string, dirs ...string) {
+	for _, createPath := range dirs {
+		toCreate := filepath.Join(createPath, name)
+		ostest.Create(t, toCreate)
+
+		err := os.Chmod(toCreate, 0755)
+		require.NoError(t, err)
+	}
+}
+
+func gradleWithExt() string {
+	if runtime.GOOS == "windows" {
+		// For Windows, we want to test EXT resolution behavior
+		return "gradle.bat"
+	}
+
+	return "gradle"
+}
+
+func gradlewWithExt() string {
+	if runtime.GOOS == "windows" {
+		return "gradlew.bat"
+	}
+
+	return "gradlew"
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package grype
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// Match is a vulnerability of a package of an image found by grype
+type Match struct {
+	Vulnerability struct {
+		Id string `json:"id"`
+		// The severity of the vulnerability, Critical, High, Medium, Low, Negligible or Unknown
+		Severity    string `json:"severity"`
+		Description string `json:"description"`
+		Fix         struct {
+			// The versions of the package fixing the vulnerability
+			Versions []string `json:"versions"`
+		} `json:"fix"`
+	} `json:"vulnerability"`
+	Artifact struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"artifact"`
+}
+
+// GrypeCli scans container images for vulnerabilities with grype
+type GrypeCli interface {
+	tools.ExternalTool
+	// Scans the image for vulnerabilities of its OS and language packages. Images are read from the local image store,
+	// or from their registry when remote is set.
+	ScanImage(ctx context.Context, cwd string, image string, remote bool) ([]Match, error)
+}
+
+type grypeCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the grype CLI
+func NewGrypeCli(commandRunner exec.CommandRunner) GrypeCli {
+	return &grypeCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *grypeCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("grype")
+}
+
+func (cli *grypeCli) InstallUrl() string {
+	return "https://github.com/anchore/grype#installation"
+}
+
+func (cli *grypeCli) Name() string {
+	return "grype"
+}
+
+func (cli *grypeCli) ScanImage(ctx context.Context, cwd string, image string, remote bool) ([]Match, error) {
+	source := "docker:" + image
+	if remote {
+		source = "registry:" + image
+	}
+
+	runArgs := exec.NewRunArgs("grype", source, "--output", "json", "--quiet").WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return nil, fmt.Errorf("scanning image '%s': %s: %w", image, res.String(), err)
+	}
+
+	var report struct {
+		Matches []Match `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &report); err != nil {
+		return nil, fmt.Errorf("parsing grype report: %w", err)
+	}
+
+	return report.Matches, nil
+}
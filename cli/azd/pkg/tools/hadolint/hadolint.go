@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package hadolint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// Violation is a violation of a rule of hadolint by a line of a Dockerfile
+type Violation struct {
+	// The id of the rule, ex) DL3008
+	Code string `json:"code"`
+	// The level of the violation, error, warning, info or style
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// HadolintCli lints Dockerfiles with hadolint
+type HadolintCli interface {
+	tools.ExternalTool
+	// Lints the Dockerfile, ignoring the rules with the specified ids. Returns the violations of all levels, the lint
+	// doesn't fail on violations.
+	Lint(ctx context.Context, cwd string, dockerfilePath string, ignore []string) ([]Violation, error)
+}
+
+type hadolintCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the hadolint CLI
+func NewHadolintCli(commandRunner exec.CommandRunner) HadolintCli {
+	return &hadolintCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *hadolintCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("hadolint")
+}
+
+func (cli *hadolintCli) InstallUrl() string {
+	return "https://github.com/hadolint/hadolint#install"
+}
+
+func (cli *hadolintCli) Name() string {
+	return "hadolint"
+}
+
+func (cli *hadolintCli) Lint(
+	ctx context.Context,
+	cwd string,
+	dockerfilePath string,
+	ignore []string,
+) ([]Violation, error) {
+	args := []string{"--format", "json", "--no-fail"}
+	for _, rule := range ignore {
+		args = append(args, "--ignore", rule)
+	}
+
+	args = append(args, dockerfilePath)
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("hadolint", args...).WithCwd(cwd))
+	if err != nil {
+		return nil, fmt.Errorf("linting Dockerfile '%s': %s: %w", dockerfilePath, res.String(), err)
+	}
+
+	violations := []Violation{}
+	if err := json.Unmarshal([]byte(res.Stdout), &violations); err != nil {
+		return nil, fmt.Errorf("parsing hadolint report: %w", err)
+	}
+
+	return violations, nil
+}
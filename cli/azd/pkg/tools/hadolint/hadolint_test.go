@@ -0,0 +1,43 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package hadolint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HadolintLint(t *testing.T) {
+	mockContext := mocks.NewMockContext(context.Background())
+	hadolintCli := NewHadolintCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "hadolint")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, "./src/api", args.Cwd)
+		require.Equal(t, []string{"--format", "json", "--no-fail", "--ignore", "DL3018", "./Dockerfile"}, args.Args)
+
+		report := `[{"code":"DL3008","column":1,"file":"./Dockerfile","level":"warning","line":3,` +
+			`"message":"Pin versions in apt get install"}]`
+		return exec.NewRunResult(0, report, ""), nil
+	})
+
+	violations, err := hadolintCli.Lint(*mockContext.Context, "./src/api", "./Dockerfile", []string{"DL3018"})
+	require.NoError(t, err)
+	require.Equal(t, []Violation{
+		{
+			Code:    "DL3008",
+			Level:   "warning",
+			Message: "Pin versions in apt get install",
+			File:    "./Dockerfile",
+			Line:    3,
+			Column:  1,
+		},
+	}, violations)
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package kiota
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// The languages of the clients generated by kiota
+const (
+	LanguageTypeScript = "TypeScript"
+	LanguageCSharp     = "CSharp"
+	LanguagePython     = "Python"
+)
+
+// GenerateOptions are the options of the generation of a client from an OpenAPI description
+type GenerateOptions struct {
+	// The language of the client, ex) TypeScript
+	Language string
+	// The path of the OpenAPI description
+	Description string
+	// The directory the client is generated into
+	Output string
+	// The name of the class of the client, ex) ApiClient
+	ClassName string
+	// The namespace of the client, ex) Contoso.Api.Client
+	Namespace string
+}
+
+// KiotaCli generates typed clients of HTTP APIs from their OpenAPI descriptions with kiota
+type KiotaCli interface {
+	tools.ExternalTool
+	// Generates the client of the OpenAPI description. Kiota records the hash of the description in the kiota-lock.json
+	// file of the output directory and skips the generation when the description didn't change.
+	Generate(ctx context.Context, cwd string, options GenerateOptions) error
+}
+
+type kiotaCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the kiota CLI
+func NewKiotaCli(commandRunner exec.CommandRunner) KiotaCli {
+	return &kiotaCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *kiotaCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("kiota")
+}
+
+func (cli *kiotaCli) InstallUrl() string {
+	return "https://learn.microsoft.com/openapi/kiota/install"
+}
+
+func (cli *kiotaCli) Name() string {
+	return "kiota"
+}
+
+func (cli *kiotaCli) Generate(ctx context.Context, cwd string, options GenerateOptions) error {
+	runArgs := exec.NewRunArgs(
+		"kiota", "generate",
+		"--language", options.Language,
+		"--openapi", options.Description,
+		"--output", options.Output,
+		"--class-name", options.ClassName,
+		"--namespace-name", options.Namespace,
+	).WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("generating client of '%s': %s: %w", options.Description, res.String(), err)
+	}
+
+	return nil
+}
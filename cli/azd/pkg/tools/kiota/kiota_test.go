@@ -0,0 +1,48 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package kiota
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KiotaGenerate(t *testing.T) {
+	ran := false
+	mockContext := mocks.NewMockContext(context.Background())
+	kiotaCli := NewKiotaCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "kiota generate")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+
+		require.Equal(t, "./src/web", args.Cwd)
+		require.Equal(t, []string{
+			"generate",
+			"--language", "TypeScript",
+			"--openapi", "../api/openapi.yaml",
+			"--output", "clients/api",
+			"--class-name", "ApiClient",
+			"--namespace-name", "ApiClient",
+		}, args.Args)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := kiotaCli.Generate(*mockContext.Context, "./src/web", GenerateOptions{
+		Language:    LanguageTypeScript,
+		Description: "../api/openapi.yaml",
+		Output:      "clients/api",
+		ClassName:   "ApiClient",
+		Namespace:   "ApiClient",
+	})
+	require.NoError(t, err)
+	require.True(t, ran)
+}
@@ -21,6 +21,7 @@ type MavenCli interface {
 	ResolveDependencies(ctx context.Context, projectPath string) error
 	Compile(ctx context.Context, projectPath string) error
 	Package(ctx context.Context, projectPath string) error
+	Test(ctx context.Context, projectPath string) error
 }
 
 type mavenCli struct {
@@ -144,7 +145,7 @@ func (cli *mavenCli) Compile(ctx context.Context, projectPath string) error {
 		return err
 	}
 
-	runArgs := exec.NewRunArgs(mvnCmd, "compile").WithCwd(projectPath)
+	runArgs := exec.NewRunArgs(mvnCmd, append(repoLocalArgs(), "compile")...).WithCwd(projectPath)
 	res, err := cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("mvn compile on project '%s' failed: %s: %w", projectPath, res.String(), err)
@@ -160,7 +161,7 @@ func (cli *mavenCli) Package(ctx context.Context, projectPath string) error {
 	}
 
 	// Maven's package phase includes tests by default. Skip it explicitly.
-	runArgs := exec.NewRunArgs(mvnCmd, "package", "-DskipTests").WithCwd(projectPath)
+	runArgs := exec.NewRunArgs(mvnCmd, append(repoLocalArgs(), "package", "-DskipTests")...).WithCwd(projectPath)
 	res, err := cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("mvn package on project '%s' failed: %s: %w", projectPath, res.String(), err)
@@ -169,12 +170,27 @@ func (cli *mavenCli) Package(ctx context.Context, projectPath string) error {
 	return nil
 }
 
+func (cli *mavenCli) Test(ctx context.Context, projectPath string) error {
+	mvnCmd, err := cli.mvnCmd()
+	if err != nil {
+		return err
+	}
+
+	runArgs := exec.NewRunArgs(mvnCmd, append(repoLocalArgs(), "test")...).WithCwd(projectPath)
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("mvn test on project '%s' failed: %s: %w", projectPath, res.String(), err)
+	}
+
+	return nil
+}
+
 func (cli *mavenCli) ResolveDependencies(ctx context.Context, projectPath string) error {
 	mvnCmd, err := cli.mvnCmd()
 	if err != nil {
 		return err
 	}
-	runArgs := exec.NewRunArgs(mvnCmd, "dependency:resolve").WithCwd(projectPath)
+	runArgs := exec.NewRunArgs(mvnCmd, append(repoLocalArgs(), "dependency:resolve")...).WithCwd(projectPath)
 	res, err := cli.commandRunner.Run(ctx, runArgs)
 	if err != nil {
 		return fmt.Errorf("mvn dependency:resolve on project '%s' failed: %s: %w", projectPath, res.String(), err)
@@ -183,6 +199,16 @@ func (cli *mavenCli) ResolveDependencies(ctx context.Context, projectPath string
 	return nil
 }
 
+// Gets the arguments setting the local repository to the Maven repository of the restore cache, when configured.
+// All phases resolve dependencies, so the arguments are used for all commands.
+func repoLocalArgs() []string {
+	if dir := tools.RestoreCacheDir(tools.RestoreCacheMaven); dir != "" {
+		return []string{fmt.Sprintf("-Dmaven.repo.local=%s", dir)}
+	}
+
+	return []string{}
+}
+
 func NewMavenCli(commandRunner exec.CommandRunner) MavenCli {
 	return &mavenCli{
 		commandRunner: commandRunner,
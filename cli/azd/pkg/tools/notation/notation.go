@@ -0,0 +1,97 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package notation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// The reference of the signed image printed by notation, ex) Successfully signed contoso.azurecr.io/api@sha256:...
+var signedRegex = regexp.MustCompile(`Successfully signed (\S+)`)
+
+// Signature is a signature of an image stored in the registry of the image
+type Signature struct {
+	// The digest of the signature manifest, ex) sha256:...
+	Digest           string `json:"digest"`
+	SignedAttributes struct {
+		SigningTime time.Time `json:"signingTime"`
+	} `json:"signedAttributes"`
+}
+
+// NotationCli signs container images with the Notary Project notation CLI and keys stored in Azure Key Vault, using the
+// azure-kv plugin. The credentials of the docker CLI are used to authenticate with registries, ex) after docker login.
+type NotationCli interface {
+	tools.ExternalTool
+	// Signs the image with the certificate of the Key Vault key, ex) https://contoso.vault.azure.net/keys/signing/{version}
+	// and stores the signature in the registry of the image. Returns the reference of the signed image by digest.
+	Sign(ctx context.Context, cwd string, image string, keyId string) (string, error)
+	// Lists the signatures of the image, ex) contoso.azurecr.io/api@sha256:...
+	Signatures(ctx context.Context, cwd string, image string) ([]Signature, error)
+}
+
+type notationCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the notation CLI
+func NewNotationCli(commandRunner exec.CommandRunner) NotationCli {
+	return &notationCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *notationCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("notation")
+}
+
+func (cli *notationCli) InstallUrl() string {
+	return "https://notaryproject.dev/docs/user-guides/installation/cli"
+}
+
+func (cli *notationCli) Name() string {
+	return "notation"
+}
+
+func (cli *notationCli) Sign(ctx context.Context, cwd string, image string, keyId string) (string, error) {
+	runArgs := exec.NewRunArgs(
+		"notation", "sign", "--signature-format", "cose", "--plugin", "azure-kv", "--id", keyId, image,
+	).WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return "", fmt.Errorf("signing image '%s': %s: %w", image, res.String(), err)
+	}
+
+	matches := signedRegex.FindStringSubmatch(res.Stdout)
+	if matches == nil {
+		return "", fmt.Errorf("signing image '%s': the signed image isn't in the output: %s", image, res.Stdout)
+	}
+
+	return matches[1], nil
+}
+
+func (cli *notationCli) Signatures(ctx context.Context, cwd string, image string) ([]Signature, error) {
+	runArgs := exec.NewRunArgs("notation", "inspect", "--output", "json", image).WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return nil, fmt.Errorf("listing signatures of image '%s': %s: %w", image, res.String(), err)
+	}
+
+	var inspection struct {
+		Signatures []Signature `json:"signatures"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &inspection); err != nil {
+		return nil, fmt.Errorf("parsing signatures of image '%s': %w", image, err)
+	}
+
+	return inspection.Signatures, nil
+}
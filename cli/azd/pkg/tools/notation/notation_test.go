@@ -0,0 +1,60 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package notation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NotationSign(t *testing.T) {
+	const signedImage = "contoso.azurecr.io/api@sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b"
+	mockContext := mocks.NewMockContext(context.Background())
+	notationCli := NewNotationCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "notation sign")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, []string{
+			"sign", "--signature-format", "cose",
+			"--plugin", "azure-kv",
+			"--id", "https://contoso.vault.azure.net/keys/signing/0123",
+			"contoso.azurecr.io/api:latest",
+		}, args.Args)
+
+		return exec.NewRunResult(0, "Successfully signed "+signedImage+"\n", ""), nil
+	})
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "notation inspect")
+	}).Respond(exec.NewRunResult(0, `{
+		"mediaType": "application/vnd.oci.image.manifest.v1+json",
+		"signatures": [
+			{
+				"digest": "sha256:e9e6f6f2f4f6a1b1c6c1f3b0a2f0e1d3c4b5a69788796a5b4c3d2e1f0a9b8c7d",
+				"signedAttributes": { "signingTime": "2023-06-01T10:00:00Z" }
+			}
+		]
+	}`, ""))
+
+	image, err := notationCli.Sign(
+		*mockContext.Context,
+		"./src/api",
+		"contoso.azurecr.io/api:latest",
+		"https://contoso.vault.azure.net/keys/signing/0123",
+	)
+	require.NoError(t, err)
+	require.Equal(t, signedImage, image)
+
+	signatures, err := notationCli.Signatures(*mockContext.Context, "./src/api", image)
+	require.NoError(t, err)
+	require.Len(t, signatures, 1)
+	require.Equal(t, "sha256:e9e6f6f2f4f6a1b1c6c1f3b0a2f0e1d3c4b5a69788796a5b4c3d2e1f0a9b8c7d", signatures[0].Digest)
+	require.Equal(t, 2023, signatures[0].SignedAttributes.SigningTime.Year())
+}
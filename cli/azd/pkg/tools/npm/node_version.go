@@ -0,0 +1,281 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// The files pinning the version of Node.js of a project, read by nvm and fnm, in the order they are detected
+var nodeVersionFiles = []string{".nvmrc", ".node-version"}
+
+// NodeVersion is the version of Node.js a project requires
+type NodeVersion struct {
+	// The version or range of versions, ex) 18, v18.17.0 or >=18 <21
+	Constraint string
+	// Where the version is declared, ex) .nvmrc or the engines field of package.json
+	Source string
+
+	// The versions allowed, nil for the aliases of nvm which can't be checked, ex) lts/*
+	versions semver.Range
+}
+
+// Allows checks whether the version of Node.js is allowed. The aliases of nvm, ex) lts/*, allow any version.
+func (v *NodeVersion) Allows(version semver.Version) bool {
+	return v.versions == nil || v.versions(version)
+}
+
+// String gets the constraint and its source, ex) 18 (from .nvmrc)
+func (v *NodeVersion) String() string {
+	return fmt.Sprintf("%s (from %s)", v.Constraint, v.Source)
+}
+
+// DetectNodeVersion detects the version of Node.js the project requires, from the project directory up to the root
+// directory. In each directory, the .nvmrc and .node-version files take precedence over the node engine of package.json.
+// Returns nil when the project doesn't declare a version.
+func DetectNodeVersion(projectPath string, rootPath string) (*NodeVersion, error) {
+	dir, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, name := range nodeVersionFiles {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+
+			// Only the first line is the version, nvm allows comments on the following lines
+			constraint, _, _ := strings.Cut(strings.TrimSpace(string(content)), "\n")
+			return ParseNodeVersion(strings.TrimSpace(constraint), filepath.Join(dir, name))
+		}
+
+		pkg, err := readPackageJson(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg != nil && strings.TrimSpace(pkg.Engines["node"]) != "" {
+			return ParseNodeVersion(strings.TrimSpace(pkg.Engines["node"]), filepath.Join(dir, "package.json"))
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || len(parent) < len(root) {
+			return nil, nil
+		}
+
+		dir = parent
+	}
+}
+
+// ParseNodeVersion parses a version or range of versions of Node.js, in the syntax of the ranges of npm, ex) ^18.17.0,
+// 18.x or >=18 <21 || 22
+func ParseNodeVersion(constraint string, source string) (*NodeVersion, error) {
+	version := &NodeVersion{Constraint: constraint, Source: source}
+	if isNodeVersionAlias(constraint) {
+		return version, nil
+	}
+
+	for _, alternative := range strings.Split(constraint, "||") {
+		comparators, err := nodeVersionComparators(alternative)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node version '%s' in %s: %w", constraint, source, err)
+		}
+
+		versions, err := semver.ParseRange(strings.Join(comparators, " "))
+		if err != nil {
+			return nil, fmt.Errorf("invalid node version '%s' in %s: %w", constraint, source, err)
+		}
+
+		if version.versions == nil {
+			version.versions = versions
+		} else {
+			version.versions = version.versions.OR(versions)
+		}
+	}
+
+	return version, nil
+}
+
+// Checks whether the version is an alias of nvm, ex) lts/*, lts/hydrogen, node or stable
+func isNodeVersionAlias(constraint string) bool {
+	lower := strings.ToLower(constraint)
+	return strings.HasPrefix(lower, "lts/") || lower == "node" || lower == "stable" || lower == "latest"
+}
+
+// Matches a comparator of a range of npm, ex) >=18.2 or ^18.17.0
+var nodeComparatorRegexp = regexp.MustCompile(`^(>=|<=|>|<|=|\^|~)?v?([0-9xX*]+)(?:\.([0-9xX*]+))?(?:\.([0-9xX*]+))?$`)
+
+// Converts the comparators of a range of npm to the comparators of semver, which requires complete versions and has no
+// caret, tilde, hyphen or partial ranges
+func nodeVersionComparators(alternative string) ([]string, error) {
+	fields := strings.Fields(alternative)
+
+	// Hyphen ranges, ex) 18 - 20
+	if len(fields) == 3 && fields[1] == "-" {
+		fields = []string{">=" + fields[0], "<=" + fields[2]}
+	}
+
+	if len(fields) == 0 {
+		return []string{">=0.0.0"}, nil
+	}
+
+	comparators := []string{}
+	for _, field := range fields {
+		match := nodeComparatorRegexp.FindStringSubmatch(field)
+		if match == nil {
+			return nil, fmt.Errorf("unsupported comparator '%s'", field)
+		}
+
+		operator := match[1]
+		parts := []int{}
+		for _, part := range match[2:] {
+			if part == "" || part == "x" || part == "X" || part == "*" {
+				break
+			}
+
+			number, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, err
+			}
+
+			parts = append(parts, number)
+		}
+
+		comparators = append(comparators, rangeComparators(operator, parts)...)
+	}
+
+	return comparators, nil
+}
+
+// Gets the comparators of semver of the operator of npm and the specified parts of the version, ex) [18] for 18.x
+func rangeComparators(operator string, parts []int) []string {
+	if len(parts) == 0 {
+		if operator == "<" || operator == ">" {
+			return []string{"<0.0.0"}
+		}
+
+		return []string{">=0.0.0"}
+	}
+
+	lower := completeVersion(parts)
+	// The first version after the versions matching the specified parts, ex) 18.3.0 for 18.2
+	next := nextVersion(parts)
+
+	switch operator {
+	case ">=":
+		return []string{">=" + lower}
+	case ">":
+		if len(parts) == 3 {
+			return []string{">" + lower}
+		}
+		return []string{">=" + next}
+	case "<":
+		return []string{"<" + lower}
+	case "<=":
+		if len(parts) == 3 {
+			return []string{"<=" + lower}
+		}
+		return []string{"<" + next}
+	case "^":
+		// The first non-zero part can't change, ex) ^18.2 allows 18.x from 18.2.0, ^0.2.1 allows 0.2.x from 0.2.1
+		for i, part := range parts {
+			if part != 0 || i == len(parts)-1 {
+				return []string{">=" + lower, "<" + nextVersion(parts[:i+1])}
+			}
+		}
+	case "~":
+		if len(parts) == 1 {
+			return []string{">=" + lower, "<" + next}
+		}
+		return []string{">=" + lower, "<" + nextVersion(parts[:2])}
+	}
+
+	// Exact or partial versions, ex) 18 allows 18.x
+	if len(parts) == 3 {
+		return []string{"=" + lower}
+	}
+
+	return []string{">=" + lower, "<" + next}
+}
+
+// Gets the version fnm or nvm install for the constraint, the most specific version whose latest release is allowed by
+// the constraint, ex) 18 for ^18.2.0 or >=18. Ranges with an upper bound or alternatives can't be installed.
+func (v *NodeVersion) installVersion() (string, bool) {
+	if isNodeVersionAlias(v.Constraint) {
+		return v.Constraint, true
+	}
+
+	fields := strings.Fields(v.Constraint)
+	if len(fields) != 1 {
+		return "", false
+	}
+
+	match := nodeComparatorRegexp.FindStringSubmatch(fields[0])
+	if match == nil {
+		return "", false
+	}
+
+	parts := []string{}
+	for _, part := range match[2:] {
+		if part == "" || part == "x" || part == "X" || part == "*" {
+			break
+		}
+
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return "", false
+	}
+
+	switch match[1] {
+	case "", "=":
+	case ">=":
+		parts = parts[:1]
+	case "^":
+		keep := 1
+		for keep < len(parts) && parts[keep-1] == "0" {
+			keep++
+		}
+		parts = parts[:keep]
+	case "~":
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+	default:
+		return "", false
+	}
+
+	return strings.Join(parts, "."), true
+}
+
+// Gets the version of the parts, the missing parts being 0, ex) 18.0.0 for 18
+func completeVersion(parts []int) string {
+	complete := append(append([]int{}, parts...), 0, 0, 0)[:3]
+	return fmt.Sprintf("%d.%d.%d", complete[0], complete[1], complete[2])
+}
+
+// Gets the version incrementing the last part, ex) 19.0.0 for 18 or 18.3.0 for 18.2
+func nextVersion(parts []int) string {
+	next := append([]int{}, parts...)
+	next[len(next)-1]++
+	return completeVersion(next)
+}
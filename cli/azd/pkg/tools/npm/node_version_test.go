@@ -0,0 +1,128 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseNodeVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allowed    []string
+		denied     []string
+	}{
+		{constraint: "18", allowed: []string{"18.0.0", "18.19.1"}, denied: []string{"17.9.0", "19.0.0"}},
+		{constraint: "v18.17.0", allowed: []string{"18.17.0"}, denied: []string{"18.17.1", "18.16.0"}},
+		{constraint: "18.x", allowed: []string{"18.2.0"}, denied: []string{"20.0.0"}},
+		{constraint: "^18.17.0", allowed: []string{"18.17.0", "18.20.0"}, denied: []string{"18.16.0", "19.0.0"}},
+		{constraint: "^0.2.1", allowed: []string{"0.2.5"}, denied: []string{"0.3.0"}},
+		{constraint: "~18.17", allowed: []string{"18.17.9"}, denied: []string{"18.18.0"}},
+		{constraint: ">=18 <21", allowed: []string{"18.0.0", "20.11.0"}, denied: []string{"16.20.0", "21.0.0"}},
+		{constraint: ">18", allowed: []string{"19.0.0"}, denied: []string{"18.19.0"}},
+		{constraint: "<=20", allowed: []string{"20.11.0"}, denied: []string{"21.0.0"}},
+		{constraint: "18 - 20", allowed: []string{"18.0.0", "20.11.0"}, denied: []string{"21.0.0"}},
+		{constraint: "18 || >=22", allowed: []string{"18.2.0", "22.1.0"}, denied: []string{"20.0.0"}},
+		{constraint: "*", allowed: []string{"14.0.0", "22.1.0"}},
+		{constraint: "lts/*", allowed: []string{"14.0.0", "22.1.0"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			version, err := ParseNodeVersion(test.constraint, ".nvmrc")
+			require.NoError(t, err)
+
+			for _, allowed := range test.allowed {
+				require.True(t, version.Allows(semver.MustParse(allowed)), allowed)
+			}
+
+			for _, denied := range test.denied {
+				require.False(t, version.Allows(semver.MustParse(denied)), denied)
+			}
+		})
+	}
+
+	_, err := ParseNodeVersion(">=18 !20", "package.json")
+	require.ErrorContains(t, err, "invalid node version '>=18 !20' in package.json")
+}
+
+func Test_DetectNodeVersion(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{"src/web/package.json": "{}"})
+
+		version, err := DetectNodeVersion(filepath.Join(root, "src", "web"), root)
+		require.NoError(t, err)
+		require.Nil(t, version)
+	})
+
+	t.Run("NvmrcBeforeEngines", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"src/web/package.json": `{"engines": {"node": ">=16"}}`,
+			"src/web/.nvmrc":       "20\n# the version of the pipelines\n",
+		})
+
+		version, err := DetectNodeVersion(filepath.Join(root, "src", "web"), root)
+		require.NoError(t, err)
+		require.Equal(t, "20", version.Constraint)
+		require.Equal(t, filepath.Join(root, "src", "web", ".nvmrc"), version.Source)
+	})
+
+	t.Run("EnginesBeforeMonorepo", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"src/web/package.json": `{"engines": {"node": "^18.17.0"}}`,
+			".node-version":        "20",
+		})
+
+		version, err := DetectNodeVersion(filepath.Join(root, "src", "web"), root)
+		require.NoError(t, err)
+		require.Equal(t, "^18.17.0", version.Constraint)
+	})
+
+	t.Run("Monorepo", func(t *testing.T) {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{"src/web/package.json": "{}", ".node-version": "v20.11.0"})
+
+		version, err := DetectNodeVersion(filepath.Join(root, "src", "web"), root)
+		require.NoError(t, err)
+		require.Equal(t, "v20.11.0", version.Constraint)
+		require.Equal(t, filepath.Join(root, ".node-version"), version.Source)
+	})
+}
+
+func Test_NodeVersion_InstallVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+		ok         bool
+	}{
+		{constraint: "v20.11.0", want: "20.11.0", ok: true},
+		{constraint: "18.x", want: "18", ok: true},
+		{constraint: ">=18", want: "18", ok: true},
+		{constraint: "^18.17.0", want: "18", ok: true},
+		{constraint: "^0.2.1", want: "0.2", ok: true},
+		{constraint: "~18.17.1", want: "18.17", ok: true},
+		{constraint: "lts/*", want: "lts/*", ok: true},
+		{constraint: ">=18 <21"},
+		{constraint: "<20"},
+		{constraint: "*"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			version, err := ParseNodeVersion(test.constraint, ".nvmrc")
+			require.NoError(t, err)
+
+			got, ok := version.installVersion()
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.want, got)
+		})
+	}
+}
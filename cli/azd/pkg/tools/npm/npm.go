@@ -6,17 +6,45 @@ package npm
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/blang/semver/v4"
+	"golang.org/x/exp/slices"
 )
 
+// NpmCli runs the package manager of Node.js projects, npm, pnpm or yarn. See [DetectPackageManager].
 type NpmCli interface {
 	tools.ExternalTool
-	Install(ctx context.Context, project string) error
-	RunScript(ctx context.Context, projectPath string, scriptName string, env []string) error
+	Install(ctx context.Context, project string, packageManager PackageManagerInfo) error
+	RunScript(
+		ctx context.Context,
+		projectPath string,
+		packageManager PackageManagerInfo,
+		scriptName string,
+		env []string,
+	) error
+	// Installs the dependencies of the package of the workspace from the root of the workspace
+	InstallWorkspace(ctx context.Context, workspace *Workspace, packageManager PackageManagerInfo) error
+	// Runs the script of the package of the workspace from the root of the workspace, when the script is defined
+	RunWorkspaceScript(
+		ctx context.Context,
+		workspace *Workspace,
+		packageManager PackageManagerInfo,
+		scriptName string,
+		env []string,
+	) error
 	Prune(ctx context.Context, projectPath string, production bool) error
+	// Gets the version of Node.js, of the node executable of the directory when not empty, ex) the directory of a version
+	// installed by InstallNode
+	NodeVersion(ctx context.Context, binDir string) (semver.Version, error)
+	// Installs the version of Node.js with fnm or nvm, whichever is installed, and gets the directory of its node executable
+	InstallNode(ctx context.Context, cwd string, version *NodeVersion) (string, error)
 }
 
 type npmCli struct {
@@ -70,9 +98,8 @@ func (cli *npmCli) Name() string {
 	return "npm CLI"
 }
 
-func (cli *npmCli) Install(ctx context.Context, project string) error {
-	runArgs := exec.
-		NewRunArgs("npm", "install").
+func (cli *npmCli) Install(ctx context.Context, project string, packageManager PackageManagerInfo) error {
+	runArgs := newPackageManagerArgs(packageManager, restoreCacheEnv(packageManager), "install").
 		WithCwd(project)
 
 	res, err := cli.commandRunner.Run(ctx, runArgs)
@@ -83,21 +110,152 @@ func (cli *npmCli) Install(ctx context.Context, project string) error {
 	return nil
 }
 
-func (cli *npmCli) RunScript(ctx context.Context, projectPath string, scriptName string, env []string) error {
-	runArgs := exec.
-		NewRunArgs("npm", "run", scriptName, "--if-present").
-		WithCwd(projectPath).
-		WithEnv(env)
+// Gets the environment variables setting the restore cache of the package manager
+func restoreCacheEnv(packageManager PackageManagerInfo) []string {
+	switch packageManager.Name {
+	case PackageManagerPnpm:
+		return tools.RestoreCacheEnv(tools.RestoreCachePnpm, "npm_config_store_dir")
+	case PackageManagerYarn:
+		return tools.RestoreCacheEnv(tools.RestoreCacheYarn, "YARN_CACHE_FOLDER")
+	default:
+		return tools.RestoreCacheEnv(tools.RestoreCacheNpm, "npm_config_cache")
+	}
+}
+
+// Installs the dependencies of the package of the workspace from the root of the workspace. pnpm and npm install the
+// dependencies of the package only, yarn installs the dependencies of all the packages of the workspace.
+func (cli *npmCli) InstallWorkspace(
+	ctx context.Context,
+	workspace *Workspace,
+	packageManager PackageManagerInfo,
+) error {
+	var args []string
+	switch packageManager.Name {
+	case PackageManagerPnpm:
+		// The trailing ... selects the dependencies of the package in the workspace as well
+		args = []string{"install", "--filter", workspace.Package + "..."}
+	case PackageManagerYarn:
+		args = []string{"install"}
+	default:
+		args = []string{"install", "--workspace", workspace.Package}
+	}
+
+	runArgs := newPackageManagerArgs(packageManager, restoreCacheEnv(packageManager), args...).
+		WithCwd(workspace.Root)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf(
+			"failed to install package %s of workspace %s, %s: %w", workspace.Package, workspace.Root, res.String(), err)
+	}
+
+	return nil
+}
+
+// Runs the script of the package of the workspace from the root of the workspace, when the script is defined. Scripts
+// of the tasks of Turborepo run with turbo, filtered to the package, so the scripts of the dependencies of the package
+// in the workspace run first.
+func (cli *npmCli) RunWorkspaceScript(
+	ctx context.Context,
+	workspace *Workspace,
+	packageManager PackageManagerInfo,
+	scriptName string,
+	env []string,
+) error {
+	pkg, err := readPackageJson(workspace.Path)
+	if err != nil {
+		return err
+	}
+
+	if pkg == nil || pkg.Scripts[scriptName] == "" {
+		log.Printf("skipping %s script %s of %s, the script isn't defined", packageManager.Name, scriptName, workspace.Package)
+		return nil
+	}
+
+	var args []string
+	if slices.Contains(workspace.TurboTasks, scriptName) {
+		turboArgs := []string{"turbo", "run", scriptName, "--filter=" + workspace.Package}
+		switch packageManager.Name {
+		case PackageManagerPnpm:
+			args = append([]string{"exec"}, turboArgs...)
+		case PackageManagerYarn:
+			args = turboArgs
+		default:
+			args = append([]string{"exec", "--"}, turboArgs...)
+		}
+	} else {
+		switch packageManager.Name {
+		case PackageManagerPnpm:
+			args = []string{"--filter", workspace.Package, "run", scriptName}
+		case PackageManagerYarn:
+			args = []string{"workspace", workspace.Package, "run", scriptName}
+		default:
+			args = []string{"run", scriptName, "--workspace", workspace.Package}
+		}
+	}
+
+	runArgs := newPackageManagerArgs(packageManager, env, args...).
+		WithCwd(workspace.Root)
+
+	if _, err := cli.commandRunner.Run(ctx, runArgs); err != nil {
+		return fmt.Errorf("failed to run %s script %s of %s, %w", packageManager.Name, scriptName, workspace.Package, err)
+	}
+
+	return nil
+}
+
+// Runs the script of package.json, when the script is defined
+func (cli *npmCli) RunScript(
+	ctx context.Context,
+	projectPath string,
+	packageManager PackageManagerInfo,
+	scriptName string,
+	env []string,
+) error {
+	var runArgs exec.RunArgs
+	if packageManager.Name == PackageManagerNpm {
+		runArgs = newPackageManagerArgs(packageManager, env, "run", scriptName, "--if-present")
+	} else {
+		// yarn fails running undefined scripts, the scripts are checked for all package managers other than npm
+		pkg, err := readPackageJson(projectPath)
+		if err != nil {
+			return err
+		}
+
+		if pkg == nil || pkg.Scripts[scriptName] == "" {
+			log.Printf("skipping %s script %s, the script isn't defined", packageManager.Name, scriptName)
+			return nil
+		}
+
+		runArgs = newPackageManagerArgs(packageManager, env, "run", scriptName)
+	}
+
+	runArgs = runArgs.WithCwd(projectPath)
 
 	_, err := cli.commandRunner.Run(ctx, runArgs)
 
 	if err != nil {
-		return fmt.Errorf("failed to run NPM script %s, %w", scriptName, err)
+		return fmt.Errorf("failed to run %s script %s, %w", packageManager.Name, scriptName, err)
 	}
 
 	return nil
 }
 
+// Creates the arguments running the package manager. Package managers declared by the packageManager field of
+// package.json run with corepack when corepack is installed, so the declared version runs, even when the package manager
+// isn't installed.
+func newPackageManagerArgs(packageManager PackageManagerInfo, env []string, args ...string) exec.RunArgs {
+	if packageManager.Declared {
+		if found, _ := tools.ToolInPath("corepack"); found {
+			// corepack prompts before downloading the declared version of the package manager
+			return exec.NewRunArgs("corepack", append([]string{string(packageManager.Name)}, args...)...).
+				WithEnv(append(env, "COREPACK_ENABLE_DOWNLOAD_PROMPT=0"))
+		}
+	}
+
+	return exec.NewRunArgs(string(packageManager.Name), args...).WithEnv(env)
+}
+
 func (cli *npmCli) Prune(ctx context.Context, projectPath string, production bool) error {
 	runArgs := exec.
 		NewRunArgs("npm", "prune").
@@ -114,3 +272,83 @@ func (cli *npmCli) Prune(ctx context.Context, projectPath string, production boo
 
 	return nil
 }
+
+func (cli *npmCli) NodeVersion(ctx context.Context, binDir string) (semver.Version, error) {
+	node := "node"
+	if binDir != "" {
+		node = filepath.Join(binDir, "node")
+	}
+
+	nodeRes, err := tools.ExecuteCommand(ctx, cli.commandRunner, node, "--version")
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("checking Node.js version: %w", err)
+	}
+
+	return tools.ExtractVersion(nodeRes)
+}
+
+// Installs the version of Node.js with fnm, or with nvm when fnm isn't installed. nvm is a shell function, it's loaded
+// from the nvm.sh script of its directory.
+func (cli *npmCli) InstallNode(ctx context.Context, cwd string, version *NodeVersion) (string, error) {
+	installVersion, ok := version.installVersion()
+	if !ok {
+		return "", fmt.Errorf(
+			"node version %s can't be installed, only versions and ranges with a lower bound only can, ex) 18 or ^18.17.0",
+			version)
+	}
+
+	var runArgs exec.RunArgs
+	if found, _ := tools.ToolInPath("fnm"); found {
+		// fnm prints the directory of the node executable of the version it runs
+		script := "require('path').dirname(process.execPath)"
+		if res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("fnm", "install", installVersion).WithCwd(cwd)); err != nil {
+			return "", fmt.Errorf("installing Node.js %s with fnm: %s: %w", installVersion, res.String(), err)
+		}
+
+		runArgs = exec.NewRunArgs("fnm", "exec", "--using="+installVersion, "node", "-p", script)
+	} else if nvmScript := nvmScriptPath(); nvmScript != "" {
+		script := `. "$0" && nvm install "$1" >&2 && dirname "$(nvm which "$1")"`
+		runArgs = exec.NewRunArgs("bash", "-c", script, nvmScript, installVersion)
+	} else {
+		return "", fmt.Errorf(
+			"installing Node.js %s requires fnm (https://github.com/Schniz/fnm) or nvm (https://github.com/nvm-sh/nvm)",
+			installVersion)
+	}
+
+	res, err := cli.commandRunner.Run(ctx, runArgs.WithCwd(cwd))
+	if err != nil {
+		return "", fmt.Errorf("installing Node.js %s: %s: %w", installVersion, res.String(), err)
+	}
+
+	binDir := strings.TrimSpace(res.Stdout)
+	if binDir == "" {
+		return "", fmt.Errorf("installing Node.js %s: the directory of the installed version wasn't found", installVersion)
+	}
+
+	return binDir, nil
+}
+
+// Gets the path of the script loading nvm, empty when nvm isn't installed. nvm doesn't support Windows, nvm-windows is a
+// different tool.
+func nvmScriptPath() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+
+	nvmDir := os.Getenv("NVM_DIR")
+	if nvmDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		nvmDir = filepath.Join(home, ".nvm")
+	}
+
+	script := filepath.Join(nvmDir, "nvm.sh")
+	if _, err := os.Stat(script); err != nil {
+		return ""
+	}
+
+	return script
+}
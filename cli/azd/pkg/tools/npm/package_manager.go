@@ -0,0 +1,124 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackageManager is the package manager of a Node.js project, ex) pnpm
+type PackageManager string
+
+const (
+	PackageManagerNpm  PackageManager = "npm"
+	PackageManagerPnpm PackageManager = "pnpm"
+	PackageManagerYarn PackageManager = "yarn"
+)
+
+// The lock files of the package managers, in the order they are detected
+var lockFiles = []struct {
+	name           string
+	packageManager PackageManager
+}{
+	{"pnpm-lock.yaml", PackageManagerPnpm},
+	{"yarn.lock", PackageManagerYarn},
+	{"package-lock.json", PackageManagerNpm},
+	{"npm-shrinkwrap.json", PackageManagerNpm},
+}
+
+// PackageManagerInfo is the package manager detected for a Node.js project
+type PackageManagerInfo struct {
+	// The package manager of the project
+	Name PackageManager
+	// Whether the package manager is declared by the packageManager field of a package.json file, ex) pnpm@8.15.1.
+	// Declared package managers are run with corepack, which runs the declared version of the package manager.
+	Declared bool
+}
+
+// The fields of package.json read by azd
+type packageJson struct {
+	Name           string            `json:"name"`
+	PackageManager string            `json:"packageManager"`
+	Scripts        map[string]string `json:"scripts"`
+	// The versions of the engines the package runs on, ex) { "node": ">=18" }
+	Engines map[string]string `json:"engines"`
+	// Either an array of the patterns of the packages of the workspace, or an object with the patterns of the packages,
+	// ex) { "packages": ["packages/*"] }
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// DetectPackageManager detects the package manager of the Node.js project, from the project directory up to the root
+// directory, since the package manager of the packages of a monorepo is usually declared at the root of the monorepo.
+// In each directory, the packageManager field of package.json takes precedence over the lock files, pnpm-lock.yaml for
+// pnpm, yarn.lock for yarn and package-lock.json for npm. Defaults to npm.
+func DetectPackageManager(projectPath string, rootPath string) (PackageManagerInfo, error) {
+	dir, err := filepath.Abs(projectPath)
+	if err != nil {
+		return PackageManagerInfo{}, err
+	}
+
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return PackageManagerInfo{}, err
+	}
+
+	for {
+		pkg, err := readPackageJson(dir)
+		if err != nil {
+			return PackageManagerInfo{}, err
+		}
+
+		if pkg != nil && pkg.PackageManager != "" {
+			// The field is the name and the version of the package manager, ex) yarn@3.6.1 or pnpm@8.15.1+sha256.abc
+			name, _, _ := strings.Cut(pkg.PackageManager, "@")
+			switch PackageManager(name) {
+			case PackageManagerNpm, PackageManagerPnpm, PackageManagerYarn:
+				return PackageManagerInfo{Name: PackageManager(name), Declared: true}, nil
+			default:
+				return PackageManagerInfo{}, fmt.Errorf(
+					"unsupported package manager '%s' in %s, supported package managers are npm, pnpm and yarn",
+					pkg.PackageManager,
+					filepath.Join(dir, "package.json"),
+				)
+			}
+		}
+
+		for _, lockFile := range lockFiles {
+			if _, err := os.Stat(filepath.Join(dir, lockFile.name)); err == nil {
+				log.Printf("detected %s from %s", lockFile.packageManager, filepath.Join(dir, lockFile.name))
+				return PackageManagerInfo{Name: lockFile.packageManager}, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || len(parent) < len(root) {
+			return PackageManagerInfo{Name: PackageManagerNpm}, nil
+		}
+
+		dir = parent
+	}
+}
+
+// Reads the package.json file of the directory. Returns nil when the directory has no package.json file.
+func readPackageJson(dir string) (*packageJson, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading package.json: %w", err)
+	}
+
+	var pkg packageJson
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, "package.json"), err)
+	}
+
+	return &pkg, nil
+}
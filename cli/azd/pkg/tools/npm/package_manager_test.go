@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(root, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(content), osutil.PermissionFile))
+	}
+}
+
+func Test_DetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    PackageManagerInfo
+		wantErr string
+	}{
+		{
+			name:  "Default",
+			files: map[string]string{"src/web/package.json": "{}"},
+			want:  PackageManagerInfo{Name: PackageManagerNpm},
+		},
+		{
+			name:  "PnpmLockFile",
+			files: map[string]string{"src/web/package.json": "{}", "src/web/pnpm-lock.yaml": ""},
+			want:  PackageManagerInfo{Name: PackageManagerPnpm},
+		},
+		{
+			name:  "YarnLockFileOfMonorepo",
+			files: map[string]string{"src/web/package.json": "{}", "package.json": "{}", "yarn.lock": ""},
+			want:  PackageManagerInfo{Name: PackageManagerYarn},
+		},
+		{
+			name: "ServiceLockFileFirst",
+			files: map[string]string{
+				"src/web/package.json": "{}", "src/web/package-lock.json": "{}", "pnpm-lock.yaml": "",
+			},
+			want: PackageManagerInfo{Name: PackageManagerNpm},
+		},
+		{
+			name: "ServiceLockFileBeforeMonorepo",
+			files: map[string]string{
+				"src/web/package.json": "{}",
+				"src/web/yarn.lock":    "",
+				"package.json":         `{"packageManager": "pnpm@8.15.1+sha256.abc"}`,
+			},
+			want: PackageManagerInfo{Name: PackageManagerYarn},
+		},
+		{
+			name: "DeclaredFirst",
+			files: map[string]string{
+				"src/web/package.json":      `{"packageManager": "yarn@3.6.1"}`,
+				"src/web/package-lock.json": "{}",
+			},
+			want: PackageManagerInfo{Name: PackageManagerYarn, Declared: true},
+		},
+		{
+			name:    "Unsupported",
+			files:   map[string]string{"src/web/package.json": `{"packageManager": "bun@1.0.0"}`},
+			wantErr: "unsupported package manager 'bun@1.0.0'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+
+			packageManager, err := DetectPackageManager(filepath.Join(root, "src", "web"), root)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, packageManager)
+		})
+	}
+}
+
+func Test_NpmCli_RunScript(t *testing.T) {
+	projectPath := t.TempDir()
+	writeFiles(t, projectPath, map[string]string{
+		"package.json": `{"scripts": {"build": "tsc"}}`,
+	})
+
+	ran := []exec.RunArgs{}
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return true
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = append(ran, args)
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	cli := NewNpmCli(mockContext.CommandRunner)
+	yarn := PackageManagerInfo{Name: PackageManagerYarn}
+	require.NoError(t, cli.RunScript(*mockContext.Context, projectPath, yarn, "build", []string{"KEY=value"}))
+	// Undefined scripts aren't run, since yarn fails running them
+	require.NoError(t, cli.RunScript(*mockContext.Context, projectPath, yarn, "package", nil))
+
+	require.Len(t, ran, 1)
+	require.Equal(t, "yarn", ran[0].Cmd)
+	require.Equal(t, []string{"run", "build"}, ran[0].Args)
+	require.Equal(t, []string{"KEY=value"}, ran[0].Env)
+	require.Equal(t, projectPath, ran[0].Cwd)
+}
@@ -0,0 +1,189 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Workspace is the workspace of a monorepo a Node.js project is a package of, declared by the workspaces field of the
+// package.json file at the root of the monorepo, or by the pnpm-workspace.yaml file of pnpm workspaces. The dependencies
+// of the packages of a workspace are installed once at the root of the workspace, and their scripts run from the root of
+// the workspace filtered to the package.
+type Workspace struct {
+	// The root directory of the workspace
+	Root string
+	// The directory of the package of the project
+	Path string
+	// The name of the package of the project, ex) @contoso/api
+	Package string
+	// The tasks of the turbo.json file at the root of the workspace, ex) build. The scripts of the tasks run with
+	// Turborepo, which runs the scripts of the dependencies of the package first.
+	TurboTasks []string
+}
+
+// DetectWorkspace detects the workspace the Node.js project is a package of, from the parent directory of the project up
+// to the root directory. Returns nil when the project isn't a package of a workspace or its package.json has no name,
+// since the packages of a workspace are filtered by name.
+func DetectWorkspace(projectPath string, rootPath string) (*Workspace, error) {
+	project, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := readPackageJson(project)
+	if err != nil || pkg == nil || pkg.Name == "" {
+		return nil, err
+	}
+
+	dir := project
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir || len(parent) < len(root) {
+			return nil, nil
+		}
+
+		dir = parent
+		patterns, err := readWorkspacePatterns(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if patterns == nil {
+			continue
+		}
+
+		relativePath, err := filepath.Rel(dir, project)
+		if err != nil {
+			return nil, err
+		}
+
+		// The closest workspace is the workspace of the project, packages excluded from it aren't in another workspace
+		if !matchesWorkspacePatterns(filepath.ToSlash(relativePath), patterns) {
+			return nil, nil
+		}
+
+		turboTasks, err := readTurboTasks(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Workspace{
+			Root:       dir,
+			Path:       project,
+			Package:    pkg.Name,
+			TurboTasks: turboTasks,
+		}, nil
+	}
+}
+
+// Reads the tasks of the turbo.json file of the directory, the keys of tasks, or of pipeline before Turborepo 2.0.
+// Returns no tasks when the directory has no turbo.json file.
+func readTurboTasks(dir string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "turbo.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading turbo.json: %w", err)
+	}
+
+	var turbo struct {
+		Tasks    map[string]json.RawMessage `json:"tasks"`
+		Pipeline map[string]json.RawMessage `json:"pipeline"`
+	}
+	if err := json.Unmarshal(content, &turbo); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, "turbo.json"), err)
+	}
+
+	tasks := []string{}
+	for _, definitions := range []map[string]json.RawMessage{turbo.Tasks, turbo.Pipeline} {
+		for task := range definitions {
+			tasks = append(tasks, task)
+		}
+	}
+
+	slices.Sort(tasks)
+	return tasks, nil
+}
+
+// Reads the patterns of the packages of the workspace declared in the directory, by pnpm-workspace.yaml or by the
+// workspaces field of package.json. Returns nil when the directory doesn't declare a workspace.
+func readWorkspacePatterns(dir string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml"))
+	if err == nil {
+		var pnpmWorkspace struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(content, &pnpmWorkspace); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, "pnpm-workspace.yaml"), err)
+		}
+
+		return append([]string{}, pnpmWorkspace.Packages...), nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading pnpm-workspace.yaml: %w", err)
+	}
+
+	pkg, err := readPackageJson(dir)
+	if err != nil || pkg == nil || len(pkg.Workspaces) == 0 {
+		return nil, err
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+		var workspaces struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &workspaces); err != nil {
+			return nil, fmt.Errorf("parsing workspaces of %s: %w", filepath.Join(dir, "package.json"), err)
+		}
+
+		patterns = workspaces.Packages
+	}
+
+	return append([]string{}, patterns...), nil
+}
+
+// Checks whether the slash separated path of the package, relative to the root of the workspace, matches the patterns of
+// the packages of the workspace, ex) packages/* or apps/**. Patterns with a leading ! exclude packages.
+func matchesWorkspacePatterns(packagePath string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		exclude := false
+		if trimmed, has := strings.CutPrefix(pattern, "!"); has {
+			exclude = true
+			pattern = trimmed
+		}
+
+		pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+		if matchesWorkspacePattern(packagePath, pattern) {
+			matched = !exclude
+		}
+	}
+
+	return matched
+}
+
+// Checks whether the path matches the pattern, where ** matches any number of directories
+func matchesWorkspacePattern(packagePath string, pattern string) bool {
+	if prefix, has := strings.CutSuffix(pattern, "/**"); has {
+		return strings.HasPrefix(packagePath, prefix+"/")
+	}
+
+	matched, _ := path.Match(pattern, packagePath)
+	return matched
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package npm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DetectWorkspace(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		want       bool
+		turboTasks []string
+	}{
+		{
+			name:  "NoWorkspace",
+			files: map[string]string{"package.json": "{}"},
+		},
+		{
+			name: "Pnpm",
+			files: map[string]string{
+				"pnpm-workspace.yaml": "packages:\n  - 'apps/*'\n  - 'packages/*'\n",
+			},
+			want: true,
+		},
+		{
+			name:  "NpmWorkspaces",
+			files: map[string]string{"package.json": `{"workspaces": ["apps/**"]}`},
+			want:  true,
+		},
+		{
+			name:  "YarnWorkspacesObject",
+			files: map[string]string{"package.json": `{"workspaces": {"packages": ["apps/*"]}}`},
+			want:  true,
+		},
+		{
+			name:  "Excluded",
+			files: map[string]string{"package.json": `{"workspaces": ["apps/*", "!apps/web"]}`},
+		},
+		{
+			name:  "NotAPackage",
+			files: map[string]string{"package.json": `{"workspaces": ["packages/*"]}`},
+		},
+		{
+			name: "Turbo",
+			files: map[string]string{
+				"package.json": `{"workspaces": ["apps/*"]}`,
+				"turbo.json":   `{"tasks": {"build": {"dependsOn": ["^build"]}, "lint": {}}}`,
+			},
+			want:       true,
+			turboTasks: []string{"build", "lint"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			tt.files["apps/web/package.json"] = `{"name": "@contoso/web"}`
+			writeFiles(t, root, tt.files)
+
+			workspace, err := DetectWorkspace(filepath.Join(root, "apps", "web"), root)
+			require.NoError(t, err)
+			if !tt.want {
+				require.Nil(t, workspace)
+				return
+			}
+
+			require.NotNil(t, workspace)
+			require.Equal(t, root, workspace.Root)
+			require.Equal(t, filepath.Join(root, "apps", "web"), workspace.Path)
+			require.Equal(t, "@contoso/web", workspace.Package)
+			if tt.turboTasks != nil {
+				require.Equal(t, tt.turboTasks, workspace.TurboTasks)
+			} else {
+				require.Empty(t, workspace.TurboTasks)
+			}
+		})
+	}
+}
+
+func Test_NpmCli_Workspace(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"apps/web/package.json": `{"name": "@contoso/web", "scripts": {"build": "tsc", "test": "jest"}}`,
+	})
+
+	workspace := &Workspace{
+		Root:       root,
+		Path:       filepath.Join(root, "apps", "web"),
+		Package:    "@contoso/web",
+		TurboTasks: []string{"build"},
+	}
+
+	tests := []struct {
+		name           string
+		packageManager PackageManager
+		install        []string
+		build          []string
+		test           []string
+	}{
+		{
+			name:           "Npm",
+			packageManager: PackageManagerNpm,
+			install:        []string{"install", "--workspace", "@contoso/web"},
+			build:          []string{"exec", "--", "turbo", "run", "build", "--filter=@contoso/web"},
+			test:           []string{"run", "test", "--workspace", "@contoso/web"},
+		},
+		{
+			name:           "Pnpm",
+			packageManager: PackageManagerPnpm,
+			install:        []string{"install", "--filter", "@contoso/web..."},
+			build:          []string{"exec", "turbo", "run", "build", "--filter=@contoso/web"},
+			test:           []string{"--filter", "@contoso/web", "run", "test"},
+		},
+		{
+			name:           "Yarn",
+			packageManager: PackageManagerYarn,
+			install:        []string{"install"},
+			build:          []string{"turbo", "run", "build", "--filter=@contoso/web"},
+			test:           []string{"workspace", "@contoso/web", "run", "test"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := []exec.RunArgs{}
+			mockContext := mocks.NewMockContext(context.Background())
+			mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+				return true
+			}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+				ran = append(ran, args)
+				return exec.NewRunResult(0, "", ""), nil
+			})
+
+			cli := NewNpmCli(mockContext.CommandRunner)
+			packageManager := PackageManagerInfo{Name: tt.packageManager}
+			require.NoError(t, cli.InstallWorkspace(*mockContext.Context, workspace, packageManager))
+			require.NoError(t, cli.RunWorkspaceScript(*mockContext.Context, workspace, packageManager, "build", nil))
+			require.NoError(t, cli.RunWorkspaceScript(*mockContext.Context, workspace, packageManager, "test", nil))
+			// Undefined scripts aren't run
+			require.NoError(t, cli.RunWorkspaceScript(*mockContext.Context, workspace, packageManager, "package", nil))
+
+			require.Len(t, ran, 3)
+			for i, args := range [][]string{tt.install, tt.build, tt.test} {
+				require.Equal(t, string(tt.packageManager), ran[i].Cmd)
+				require.Equal(t, args, ran[i].Args)
+				require.Equal(t, root, ran[i].Cwd)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package oras
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// OrasCli pushes OCI artifacts to container registries with the ORAS CLI. The credentials of the docker CLI are used to
+// authenticate with registries, ex) after docker login.
+type OrasCli interface {
+	tools.ExternalTool
+	// Attaches the file to the image in its registry as an OCI artifact of the artifact type, ex) application/spdx+json,
+	// referring to the image so the artifact can be discovered from the image
+	Attach(ctx context.Context, image string, artifactType string, filePath string) error
+	// Resolves the reference of an image or artifact, ex) contoso.azurecr.io/api:latest, to the digest of its manifest
+	Resolve(ctx context.Context, reference string) (string, error)
+}
+
+type orasCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the ORAS CLI
+func NewOrasCli(commandRunner exec.CommandRunner) OrasCli {
+	return &orasCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *orasCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("oras")
+}
+
+func (cli *orasCli) InstallUrl() string {
+	return "https://oras.land/docs/installation"
+}
+
+func (cli *orasCli) Name() string {
+	return "ORAS"
+}
+
+func (cli *orasCli) Attach(ctx context.Context, image string, artifactType string, filePath string) error {
+	// The file is attached by name, from its directory, so the path of the file isn't part of the artifact
+	runArgs := exec.NewRunArgs(
+		"oras", "attach", "--artifact-type", artifactType, image, filepath.Base(filePath),
+	).WithCwd(filepath.Dir(filePath))
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("attaching '%s' to '%s': %s: %w", filepath.Base(filePath), image, res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *orasCli) Resolve(ctx context.Context, reference string) (string, error) {
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("oras", "resolve", reference))
+	if err != nil {
+		return "", fmt.Errorf("resolving '%s': %s: %w", reference, res.String(), err)
+	}
+
+	return strings.TrimSpace(res.Stdout), nil
+}
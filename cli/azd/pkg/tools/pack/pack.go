@@ -0,0 +1,88 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pack
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// BuildOptions are the options of an image built with Cloud Native Buildpacks
+type BuildOptions struct {
+	// The name of the image built into the local image store of the docker daemon, ex) api:azd-buildpacks
+	Image string
+	// The builder image providing the buildpacks and the build and run images, ex) paketobuildpacks/builder-jammy-base
+	Builder string
+	// The directory of the source code of the application, relative to the working directory
+	Path string
+	// The buildpacks used instead of the buildpacks detected by the builder, ex) paketo-buildpacks/nodejs
+	Buildpacks []string
+	// The environment variables of the build, as KEY=VALUE pairs, ex) BP_NODE_VERSION=20
+	Env []string
+	// When set, the caches of the previous builds of the image aren't used
+	ClearCache bool
+}
+
+// PackCli builds container images from source code with the Cloud Native Buildpacks pack CLI, without a Dockerfile.
+// Images are built with the docker daemon.
+type PackCli interface {
+	tools.ExternalTool
+	// Builds the image into the local image store of the docker daemon. The output of the build is written to the
+	// progress writer when set.
+	Build(ctx context.Context, cwd string, options BuildOptions, buildProgress io.Writer) error
+}
+
+type packCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the pack CLI
+func NewPackCli(commandRunner exec.CommandRunner) PackCli {
+	return &packCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *packCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("pack")
+}
+
+func (cli *packCli) InstallUrl() string {
+	return "https://buildpacks.io/docs/for-platform-operators/how-to/integrate-ci/pack"
+}
+
+func (cli *packCli) Name() string {
+	return "pack"
+}
+
+func (cli *packCli) Build(ctx context.Context, cwd string, options BuildOptions, buildProgress io.Writer) error {
+	args := []string{"build", options.Image, "--builder", options.Builder, "--path", options.Path}
+	for _, buildpack := range options.Buildpacks {
+		args = append(args, "--buildpack", buildpack)
+	}
+
+	for _, env := range options.Env {
+		args = append(args, "--env", env)
+	}
+
+	if options.ClearCache {
+		args = append(args, "--clear-cache")
+	}
+
+	runArgs := exec.NewRunArgs("pack", args...).WithCwd(cwd)
+	if buildProgress != nil {
+		runArgs = runArgs.WithStdOut(buildProgress).WithStdErr(buildProgress)
+	}
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("building image '%s' with builder '%s': %s: %w", options.Image, options.Builder, res.String(), err)
+	}
+
+	return nil
+}
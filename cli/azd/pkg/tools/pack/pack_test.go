@@ -0,0 +1,49 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package pack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PackBuild(t *testing.T) {
+	ran := false
+	mockContext := mocks.NewMockContext(context.Background())
+	packCli := NewPackCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "pack build")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+
+		require.Equal(t, "./src/api", args.Cwd)
+		require.Equal(t, []string{
+			"build", "test-app-api:azd-buildpacks",
+			"--builder", "paketobuildpacks/builder-jammy-base",
+			"--path", ".",
+			"--buildpack", "paketo-buildpacks/nodejs",
+			"--env", "BP_NODE_VERSION=20",
+			"--clear-cache",
+		}, args.Args)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := packCli.Build(*mockContext.Context, "./src/api", BuildOptions{
+		Image:      "test-app-api:azd-buildpacks",
+		Builder:    "paketobuildpacks/builder-jammy-base",
+		Path:       ".",
+		Buildpacks: []string{"paketo-buildpacks/nodejs"},
+		Env:        []string{"BP_NODE_VERSION=20"},
+		ClearCache: true,
+	}, nil)
+	require.NoError(t, err)
+	require.True(t, ran)
+}
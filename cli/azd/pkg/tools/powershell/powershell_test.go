@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
 	"github.com/azure/azure-dev/cli/azd/test/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -33,7 +34,7 @@ func Test_Powershell_Execute(t *testing.T) {
 		})
 
 		PowershellScript := NewPowershellScript(mockContext.CommandRunner, workingDir, env)
-		runResult, err := PowershellScript.Execute(*mockContext.Context, scriptPath, true)
+		runResult, err := PowershellScript.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: true})
 
 		require.NotNil(t, runResult)
 		require.NoError(t, err)
@@ -49,7 +50,7 @@ func Test_Powershell_Execute(t *testing.T) {
 		})
 
 		PowershellScript := NewPowershellScript(mockContext.CommandRunner, workingDir, env)
-		runResult, err := PowershellScript.Execute(*mockContext.Context, scriptPath, true)
+		runResult, err := PowershellScript.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: true})
 
 		require.Equal(t, 1, runResult.ExitCode)
 		require.Error(t, err)
@@ -75,7 +76,7 @@ func Test_Powershell_Execute(t *testing.T) {
 			})
 
 			PowershellScript := NewPowershellScript(mockContext.CommandRunner, workingDir, env)
-			runResult, err := PowershellScript.Execute(*mockContext.Context, scriptPath, test.value)
+			runResult, err := PowershellScript.Execute(*mockContext.Context, scriptPath, tools.ExecOptions{Interactive: test.value})
 
 			require.NotNil(t, runResult)
 			require.NoError(t, err)
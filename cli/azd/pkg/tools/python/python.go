@@ -82,7 +82,7 @@ func (cli *PythonCli) InstallRequirements(ctx context.Context, workingDir, envir
 		runArgs := exec.
 			NewRunArgs(pythonExe(), "-m", "pip", "install", "-r", requirementFile).
 			WithCwd(workingDir).
-			WithEnv([]string{vEnvSetting})
+			WithEnv(append([]string{vEnvSetting}, tools.RestoreCacheEnv(tools.RestoreCachePip, "PIP_CACHE_DIR")...))
 
 		res, err = cli.commandRunner.Run(ctx, runArgs)
 	} else {
@@ -90,7 +90,9 @@ func (cli *PythonCli) InstallRequirements(ctx context.Context, workingDir, envir
 		installCmd := fmt.Sprintf("%s -m pip install -r %s", pythonExe(), requirementFile)
 		commands := []string{envActivation, installCmd}
 
-		runArgs := exec.NewRunArgs("").WithCwd(workingDir)
+		runArgs := exec.NewRunArgs("").
+			WithCwd(workingDir).
+			WithEnv(tools.RestoreCacheEnv(tools.RestoreCachePip, "PIP_CACHE_DIR"))
 		res, err = cli.commandRunner.RunList(ctx, commands, runArgs)
 	}
 
@@ -100,6 +102,39 @@ func (cli *PythonCli) InstallRequirements(ctx context.Context, workingDir, envir
 	return nil
 }
 
+// Runs the tests of the project with pytest, installed in the virtual environment of the project
+func (cli *PythonCli) Pytest(ctx context.Context, workingDir, environment string) error {
+	var res exec.RunResult
+	var err error
+
+	if runtime.GOOS == "windows" {
+		// The virtual environment is set the same way as for the install of the requirements
+		absWorkingDir, pathErr := filepath.Abs(workingDir)
+		if pathErr != nil {
+			return pathErr
+		}
+
+		vEnvSetting := fmt.Sprintf("VIRTUAL_ENV=%s", path.Join(absWorkingDir, environment))
+
+		runArgs := exec.
+			NewRunArgs(pythonExe(), "-m", "pytest").
+			WithCwd(workingDir).
+			WithEnv([]string{vEnvSetting})
+
+		res, err = cli.commandRunner.Run(ctx, runArgs)
+	} else {
+		envActivation := ". " + path.Join(environment, "bin", "activate")
+		testCmd := fmt.Sprintf("%s -m pytest", pythonExe())
+
+		res, err = cli.commandRunner.RunList(ctx, []string{envActivation, testCmd}, exec.NewRunArgs("").WithCwd(workingDir))
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to run the tests of project '%s': %w (%s)", workingDir, err, res.String())
+	}
+	return nil
+}
+
 func (cli *PythonCli) CreateVirtualEnv(ctx context.Context, workingDir, name string) error {
 	runArgs := exec.
 		NewRunArgs(pythonExe(), "-m", "venv", name).
@@ -0,0 +1,66 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package tools
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+)
+
+// RestoreCacheDirEnvVarName is the environment variable with the directory of the restore cache. When set, the package
+// managers run by azd download packages to a directory per package manager within the restore cache, ex) the npm cache,
+// NuGet packages, pip wheels and the Maven repository, so restores of multiple services and CI runs restoring the cache
+// reuse packages that were already downloaded.
+const RestoreCacheDirEnvVarName = "AZD_RESTORE_CACHE_DIR"
+
+// The package managers sharing the restore cache
+const (
+	RestoreCacheNpm     = "npm"
+	RestoreCachePnpm    = "pnpm"
+	RestoreCacheYarn    = "yarn"
+	RestoreCacheNuGet   = "nuget"
+	RestoreCachePip     = "pip"
+	RestoreCacheConda   = "conda"
+	RestoreCacheMaven   = "maven"
+	RestoreCacheGradle  = "gradle"
+	RestoreCacheBundler = "bundler"
+)
+
+// RestoreCacheDir gets the directory of the package manager within the restore cache, creating the directory when it
+// doesn't exist. An empty directory is returned when the restore cache isn't configured, see [RestoreCacheDirEnvVarName].
+func RestoreCacheDir(packageManager string) string {
+	root := strings.TrimSpace(os.Getenv(RestoreCacheDirEnvVarName))
+	if root == "" {
+		return ""
+	}
+
+	dir, err := filepath.Abs(filepath.Join(root, packageManager))
+	if err == nil {
+		err = os.MkdirAll(dir, osutil.PermissionDirectory)
+	}
+
+	if err != nil {
+		// The cache is an optimization, package managers fall back to their default locations
+		log.Printf("failed creating %s restore cache directory, using the default location: %v", packageManager, err)
+		return ""
+	}
+
+	return dir
+}
+
+// RestoreCacheEnv gets the environment variable setting the cache directory of the package manager to its directory within
+// the restore cache, ex) NUGET_PACKAGES=/cache/nuget. Nil is returned when the restore cache isn't configured.
+func RestoreCacheEnv(packageManager string, envVarName string) []string {
+	dir := RestoreCacheDir(packageManager)
+	if dir == "" {
+		return nil
+	}
+
+	return []string{fmt.Sprintf("%s=%s", envVarName, dir)}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RestoreCacheDir(t *testing.T) {
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Setenv(RestoreCacheDirEnvVarName, "")
+
+		require.Empty(t, RestoreCacheDir(RestoreCacheNpm))
+		require.Nil(t, RestoreCacheEnv(RestoreCacheNpm, "npm_config_cache"))
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		root := t.TempDir()
+		t.Setenv(RestoreCacheDirEnvVarName, root)
+
+		dir := RestoreCacheDir(RestoreCacheNuGet)
+		require.Equal(t, filepath.Join(root, "nuget"), dir)
+		require.DirExists(t, dir)
+
+		require.Equal(t,
+			[]string{"NUGET_PACKAGES=" + filepath.Join(root, "nuget")},
+			RestoreCacheEnv(RestoreCacheNuGet, "NUGET_PACKAGES"),
+		)
+	})
+}
@@ -2,11 +2,22 @@ package tools
 
 import (
 	"context"
+	"io"
 
 	"github.com/azure/azure-dev/cli/azd/pkg/exec"
 )
 
+// ExecOptions are the options used to execute a script
+type ExecOptions struct {
+	// When set will attach the script to stdin, stdout & stderr
+	Interactive bool
+	// When set will run the script under a pseudo terminal, so its output is written as it happens
+	Pty bool
+	// Receives a copy of the output of the script as it is written
+	StdOut io.Writer
+}
+
 // Utility to easily execute a bash script across platforms
 type Script interface {
-	Execute(ctx context.Context, scriptPath string, interactive bool) (exec.RunResult, error)
+	Execute(ctx context.Context, scriptPath string, options ExecOptions) (exec.RunResult, error)
 }
@@ -0,0 +1,115 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// Target describes the remote host used for SSH & SCP operations
+type Target struct {
+	// The host name or IP address of the remote machine
+	Host string
+	// The user used to connect to the remote machine
+	User string
+	// The optional path to the private key used for authentication
+	KeyPath string
+	// The optional SSH port, defaults to 22
+	Port int
+}
+
+// SshCli executes commands and copies files to remote hosts using OpenSSH
+type SshCli interface {
+	tools.ExternalTool
+	// Copies the local file to the specified path on the remote host
+	Copy(ctx context.Context, target Target, localPath string, remotePath string) error
+	// Runs the specified command on the remote host
+	Run(ctx context.Context, target Target, command string) (exec.RunResult, error)
+}
+
+type sshCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the SSH CLI
+func NewSshCli(commandRunner exec.CommandRunner) SshCli {
+	return &sshCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *sshCli) CheckInstalled(ctx context.Context) (bool, error) {
+	for _, toolName := range []string{"ssh", "scp"} {
+		found, err := tools.ToolInPath(toolName)
+		if !found {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (cli *sshCli) InstallUrl() string {
+	return "https://aka.ms/azure-dev/openssh-install"
+}
+
+func (cli *sshCli) Name() string {
+	return "OpenSSH"
+}
+
+func (cli *sshCli) Copy(ctx context.Context, target Target, localPath string, remotePath string) error {
+	args := commonArgs(target, "-P")
+	args = append(args, localPath, fmt.Sprintf("%s:%s", address(target), remotePath))
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("scp", args...))
+	if err != nil {
+		return fmt.Errorf("copying '%s' to '%s': %s: %w", localPath, target.Host, res.String(), err)
+	}
+
+	return nil
+}
+
+func (cli *sshCli) Run(ctx context.Context, target Target, command string) (exec.RunResult, error) {
+	args := commonArgs(target, "-p")
+	args = append(args, address(target), command)
+
+	res, err := cli.commandRunner.Run(ctx, exec.NewRunArgs("ssh", args...))
+	if err != nil {
+		return res, fmt.Errorf("running remote command on '%s': %s: %w", target.Host, res.String(), err)
+	}
+
+	return res, nil
+}
+
+// commonArgs builds the arguments shared between ssh & scp.
+// The port flag differs between the tools (ssh uses -p while scp uses -P)
+func commonArgs(target Target, portFlag string) []string {
+	args := []string{
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=accept-new",
+	}
+
+	if target.KeyPath != "" {
+		args = append(args, "-i", target.KeyPath)
+	}
+
+	if target.Port > 0 {
+		args = append(args, portFlag, strconv.Itoa(target.Port))
+	}
+
+	return args
+}
+
+func address(target Target) string {
+	if target.User == "" {
+		return target.Host
+	}
+
+	return fmt.Sprintf("%s@%s", target.User, target.Host)
+}
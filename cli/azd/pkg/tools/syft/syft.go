@@ -0,0 +1,76 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package syft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// The SBOM formats supported by syft and their media types
+const (
+	FormatSpdxJson      = "spdx-json"
+	FormatCycloneDxJson = "cyclonedx-json"
+	FormatSyftJson      = "syft-json"
+)
+
+// MediaTypes are the media types of the SBOM formats, used as the artifact type of SBOMs pushed to registries
+var MediaTypes = map[string]string{
+	FormatSpdxJson:      "application/spdx+json",
+	FormatCycloneDxJson: "application/vnd.cyclonedx+json",
+	FormatSyftJson:      "application/vnd.syft+json",
+}
+
+// SyftCli generates software bills of materials (SBOM) of container images with syft
+type SyftCli interface {
+	tools.ExternalTool
+	// Generates the SBOM of the image in the format, ex) spdx-json, and writes it to the output file.
+	// The source is a syft source, ex) docker:api:latest for an image of the local image store or
+	// registry:contoso.azurecr.io/api:latest for an image of a registry.
+	GenerateSbom(ctx context.Context, cwd string, source string, format string, outputPath string) error
+}
+
+type syftCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the syft CLI
+func NewSyftCli(commandRunner exec.CommandRunner) SyftCli {
+	return &syftCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *syftCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("syft")
+}
+
+func (cli *syftCli) InstallUrl() string {
+	return "https://github.com/anchore/syft#installation"
+}
+
+func (cli *syftCli) Name() string {
+	return "syft"
+}
+
+func (cli *syftCli) GenerateSbom(
+	ctx context.Context,
+	cwd string,
+	source string,
+	format string,
+	outputPath string,
+) error {
+	runArgs := exec.NewRunArgs("syft", "scan", source, "--output", fmt.Sprintf("%s=%s", format, outputPath), "--quiet").
+		WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return fmt.Errorf("generating sbom of '%s': %s: %w", source, res.String(), err)
+	}
+
+	return nil
+}
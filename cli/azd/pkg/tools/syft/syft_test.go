@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package syft
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SyftGenerateSbom(t *testing.T) {
+	ran := false
+	mockContext := mocks.NewMockContext(context.Background())
+	syftCli := NewSyftCli(mockContext.CommandRunner)
+
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return strings.Contains(command, "syft scan")
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		ran = true
+
+		require.Equal(t, "./src/api", args.Cwd)
+		require.Equal(t, []string{
+			"scan", "docker:api:latest",
+			"--output", "spdx-json=/sbom/api.spdx.json",
+			"--quiet",
+		}, args.Args)
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	err := syftCli.GenerateSbom(
+		*mockContext.Context,
+		"./src/api",
+		"docker:api:latest",
+		FormatSpdxJson,
+		"/sbom/api.spdx.json",
+	)
+	require.NoError(t, err)
+	require.True(t, ran)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package trivy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+)
+
+// Vulnerability is a vulnerability of a package of an image found by trivy
+type Vulnerability struct {
+	VulnerabilityID  string
+	PkgName          string
+	InstalledVersion string
+	// The version of the package fixing the vulnerability, empty when the vulnerability isn't fixed
+	FixedVersion string
+	// The severity of the vulnerability, CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN
+	Severity string
+	Title    string
+}
+
+// TrivyCli scans container images for vulnerabilities with trivy
+type TrivyCli interface {
+	tools.ExternalTool
+	// Scans the image for vulnerabilities of its OS and language packages. Images are read from the local image store,
+	// or from their registry when remote is set.
+	ScanImage(ctx context.Context, cwd string, image string, remote bool) ([]Vulnerability, error)
+}
+
+type trivyCli struct {
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the trivy CLI
+func NewTrivyCli(commandRunner exec.CommandRunner) TrivyCli {
+	return &trivyCli{
+		commandRunner: commandRunner,
+	}
+}
+
+func (cli *trivyCli) CheckInstalled(ctx context.Context) (bool, error) {
+	return tools.ToolInPath("trivy")
+}
+
+func (cli *trivyCli) InstallUrl() string {
+	return "https://aquasecurity.github.io/trivy/latest/getting-started/installation/"
+}
+
+func (cli *trivyCli) Name() string {
+	return "trivy"
+}
+
+func (cli *trivyCli) ScanImage(ctx context.Context, cwd string, image string, remote bool) ([]Vulnerability, error) {
+	imageSource := "docker"
+	if remote {
+		imageSource = "remote"
+	}
+
+	runArgs := exec.NewRunArgs(
+		"trivy", "image", "--format", "json", "--quiet", "--image-src", imageSource, image,
+	).WithCwd(cwd)
+
+	res, err := cli.commandRunner.Run(ctx, runArgs)
+	if err != nil {
+		return nil, fmt.Errorf("scanning image '%s': %s: %w", image, res.String(), err)
+	}
+
+	var report struct {
+		Results []struct {
+			Vulnerabilities []Vulnerability
+		}
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &report); err != nil {
+		return nil, fmt.Errorf("parsing trivy report: %w", err)
+	}
+
+	vulnerabilities := []Vulnerability{}
+	for _, result := range report.Results {
+		vulnerabilities = append(vulnerabilities, result.Vulnerabilities...)
+	}
+
+	return vulnerabilities, nil
+}
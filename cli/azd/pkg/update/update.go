@@ -0,0 +1,511 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package update provides functionality to upgrade azd to a release of a release channel.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/pkg/httputil"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/blang/semver/v4"
+	"gopkg.in/yaml.v3"
+)
+
+// Channel is a release channel of azd
+type Channel string
+
+const (
+	// The generally available releases
+	ChannelStable Channel = "stable"
+	// The releases previewing the features of the next stable release
+	ChannelBeta Channel = "beta"
+	// The builds of the main branch, published daily
+	ChannelDaily Channel = "daily"
+)
+
+// The base URL of the releases of azd, with a folder per channel
+const defaultReleaseBaseUrl = "https://azuresdkreleasepreview.blob.core.windows.net/azd/standalone/release"
+
+// ErrVersionNotApproved is returned when a release is newer than the version approved by the upgrade policy
+var ErrVersionNotApproved = errors.New("the version isn't approved")
+
+// ErrChecksumMismatch is returned when the checksum of a downloaded release doesn't match the checksum of its manifest
+var ErrChecksumMismatch = errors.New("the checksum of the download doesn't match the checksum of the release")
+
+// ErrInvalidSignature is returned when the manifest of a release isn't signed with the release signing key of azd
+var ErrInvalidSignature = errors.New("the release isn't signed with the release signing key of azd")
+
+// ErrManifestMismatch is returned when the signed manifest of a release is for another version or platform
+var ErrManifestMismatch = errors.New("the signed manifest doesn't match the release")
+
+// ErrNotNewer is returned when the signed version of a release isn't newer than the current version, ex) an older
+// release served as the latest release of a channel
+var ErrNotNewer = errors.New("the signed version of the release isn't newer than the current version")
+
+// ReleaseSigningKey is the base64 encoded Ed25519 public key verifying the signatures of the releases of azd. The key is
+// embedded in release builds with:
+//
+//	-ldflags="-X 'github.com/azure/azure-dev/cli/azd/pkg/update.ReleaseSigningKey={key}'"
+//
+// Builds without the key can't be upgraded.
+var ReleaseSigningKey = ""
+
+// Channels gets the release channels of azd
+func Channels() []Channel {
+	return []Channel{ChannelStable, ChannelBeta, ChannelDaily}
+}
+
+// ParseChannel parses the name of a release channel, ex) stable
+func ParseChannel(value string) (Channel, error) {
+	for _, channel := range Channels() {
+		if strings.EqualFold(value, string(channel)) {
+			return channel, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid channel '%s', expected one of stable, beta or daily", value)
+}
+
+// Config is the configuration of the upgrades of azd, from the user configuration and the machine level upgrade policy:
+//
+//	upgrade.channel: the release channel azd is upgraded from, defaults to stable
+//	maxVersion of the policy: the newest version azd may be upgraded to, ex) pinned by an organization
+type Config struct {
+	Channel    Channel
+	MaxVersion *semver.Version
+}
+
+// PolicyConfig is the machine level upgrade configuration managed by an organization, ex) /etc/azd/upgrade.yaml
+type PolicyConfig struct {
+	// The newest version azd may be upgraded to, ex) 1.5.0
+	MaxVersion string `yaml:"maxVersion"`
+}
+
+// Gets the path of the machine level upgrade policy file. The file is stored in a machine wide location that is
+// typically only writable by administrators, ex) /etc/azd/upgrade.yaml. The location can't be overridden by users.
+func PolicyFilePath() string {
+	if runtime.GOOS == "windows" {
+		programData := os.Getenv("ProgramData")
+		if programData == "" {
+			programData = `C:\ProgramData`
+		}
+
+		return filepath.Join(programData, "azd", "upgrade.yaml")
+	}
+
+	return filepath.Join("/etc", "azd", "upgrade.yaml")
+}
+
+// Loads the upgrade policy from the specified file. Returns nil when the file doesn't exist.
+func LoadPolicy(filePath string) (*PolicyConfig, error) {
+	contents, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading upgrade policy file: %w", err)
+	}
+
+	var policy PolicyConfig
+	if err := yaml.Unmarshal(contents, &policy); err != nil {
+		return nil, fmt.Errorf("parsing upgrade policy file '%s': %w", filePath, err)
+	}
+
+	return &policy, nil
+}
+
+// LoadConfig gets the configuration of the upgrades from the user configuration and the upgrade policy file. Upgrades
+// fail when the policy can't be read, so a broken policy never lets azd be upgraded past the pinned version.
+func LoadConfig(azdConfig config.Config, policyFilePath string) (*Config, error) {
+	upgradeConfig := &Config{Channel: ChannelStable}
+
+	if value, has := azdConfig.Get("upgrade.channel"); has {
+		channelName, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("upgrade.channel must be a channel name, found '%v'", value)
+		}
+
+		channel, err := ParseChannel(channelName)
+		if err != nil {
+			return nil, fmt.Errorf("upgrade.channel: %w", err)
+		}
+
+		upgradeConfig.Channel = channel
+	}
+
+	policy, err := LoadPolicy(policyFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy != nil && policy.MaxVersion != "" {
+		maxVersion, err := semver.Parse(strings.TrimPrefix(policy.MaxVersion, "v"))
+		if err != nil {
+			return nil, fmt.Errorf("maxVersion of upgrade policy file '%s': %w", policyFilePath, err)
+		}
+
+		upgradeConfig.MaxVersion = &maxVersion
+	}
+
+	return upgradeConfig, nil
+}
+
+// Release is the latest release of a channel for the current platform
+type Release struct {
+	Channel Channel
+	Version semver.Version
+	// The URL of the archive with the azd executable
+	ArtifactUrl string
+	// The URL of the manifest binding the version, the platform and the SHA-256 checksum of the archive
+	ManifestUrl string
+	// The URL of the base64 encoded Ed25519 signature of the manifest
+	SignatureUrl string
+}
+
+// releaseManifest is the manifest of the archive of a release, ex) azd-linux-amd64.tar.gz.manifest:
+//
+//	version: 1.3.0
+//	platform: linux/amd64
+//	sha256: {hash}
+//
+// The version of the channel is published unsigned in version.txt, so the manifest is signed rather than the archive.
+type releaseManifest struct {
+	Version  string `yaml:"version"`
+	Platform string `yaml:"platform"`
+	Sha256   string `yaml:"sha256"`
+}
+
+// Manager checks for and installs the releases of azd
+type Manager struct {
+	httpClient httputil.HttpClient
+	baseUrl    string
+	// The public key verifying the signatures of the releases, nil when the build doesn't embed it
+	signingKey ed25519.PublicKey
+}
+
+// Creates a new instance of the update Manager, verifying the releases with the ReleaseSigningKey embedded in azd
+func NewManager(httpClient httputil.HttpClient) *Manager {
+	var signingKey ed25519.PublicKey
+	if key, err := base64.StdEncoding.DecodeString(ReleaseSigningKey); err != nil || len(key) != ed25519.PublicKeySize {
+		log.Printf("the release signing key embedded in azd isn't a valid Ed25519 public key")
+	} else {
+		signingKey = key
+	}
+
+	return &Manager{
+		httpClient: httpClient,
+		baseUrl:    defaultReleaseBaseUrl,
+		signingKey: signingKey,
+	}
+}
+
+// CheckForUpdate gets the latest release of the channel for the current platform. The release is only returned when it's
+// newer than the current version. Releases newer than the max version of the configuration fail with
+// ErrVersionNotApproved.
+func (m *Manager) CheckForUpdate(ctx context.Context, upgradeConfig *Config) (*Release, error) {
+	release, err := m.LatestRelease(ctx, upgradeConfig.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	currentVersion, err := semver.Parse(internal.GetVersionNumber())
+	if err != nil {
+		return nil, fmt.Errorf("parsing current version: %w", err)
+	}
+
+	if !release.Version.GT(currentVersion) {
+		log.Printf("azd %s is up to date with the %s channel, latest version %s", currentVersion, release.Channel,
+			release.Version)
+		return nil, nil
+	}
+
+	if upgradeConfig.MaxVersion != nil && release.Version.GT(*upgradeConfig.MaxVersion) {
+		return release, fmt.Errorf(
+			"%w: version %s is newer than the approved version %s, set by the upgrade policy",
+			ErrVersionNotApproved,
+			release.Version,
+			upgradeConfig.MaxVersion,
+		)
+	}
+
+	return release, nil
+}
+
+// LatestRelease gets the latest release of the channel for the current platform
+func (m *Manager) LatestRelease(ctx context.Context, channel Channel) (*Release, error) {
+	versionUrl := fmt.Sprintf("%s/%s/version.txt", m.baseUrl, channel)
+	content, err := m.get(ctx, versionUrl)
+	if err != nil {
+		return nil, fmt.Errorf("getting latest version of channel %s: %w", channel, err)
+	}
+
+	version, err := semver.Parse(strings.TrimPrefix(strings.TrimSpace(string(content)), "v"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing latest version of channel %s: %w", channel, err)
+	}
+
+	artifactUrl := fmt.Sprintf("%s/%s/%s", m.baseUrl, channel, artifactName())
+	return &Release{
+		Channel:      channel,
+		Version:      version,
+		ArtifactUrl:  artifactUrl,
+		ManifestUrl:  artifactUrl + ".manifest",
+		SignatureUrl: artifactUrl + ".manifest.sig",
+	}, nil
+}
+
+// Install verifies the signed manifest of the release, downloads the release, verifies the checksum of the download and
+// replaces the executable with the executable of the release. Only releases newer than the current version are installed.
+// The previous executable is kept next to the executable with the .old extension until the next upgrade.
+func (m *Manager) Install(ctx context.Context, release *Release, executablePath string) error {
+	manifest, err := m.verifyManifest(ctx, release)
+	if err != nil {
+		return err
+	}
+
+	installDir := filepath.Dir(executablePath)
+	archive, err := os.CreateTemp(installDir, "azd-upgrade-*")
+	if err != nil {
+		return fmt.Errorf("creating download file: %w", err)
+	}
+	defer func() {
+		_ = archive.Close()
+		_ = os.Remove(archive.Name())
+	}()
+
+	log.Printf("downloading azd %s from %s", release.Version, release.ArtifactUrl)
+	hash := sha256.New()
+	if err := m.download(ctx, release.ArtifactUrl, io.MultiWriter(archive, hash)); err != nil {
+		return fmt.Errorf("downloading release: %w", err)
+	}
+
+	expectedChecksum := strings.ToLower(manifest.Sha256)
+	if actualChecksum := hex.EncodeToString(hash.Sum(nil)); actualChecksum != expectedChecksum {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expectedChecksum, actualChecksum)
+	}
+
+	newPath := executablePath + ".new"
+	if err := extractExecutable(archive.Name(), release.ArtifactUrl, newPath); err != nil {
+		return fmt.Errorf("extracting release: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(newPath)
+	}()
+
+	// The running executable can't be overwritten on all platforms, but it can be renamed
+	oldPath := executablePath + ".old"
+	if err := os.Remove(oldPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing previous executable: %w", err)
+	}
+
+	if err := osutil.Rename(ctx, executablePath, oldPath); err != nil {
+		return fmt.Errorf("moving current executable: %w", err)
+	}
+
+	if err := osutil.Rename(ctx, newPath, executablePath); err != nil {
+		if restoreErr := osutil.Rename(ctx, oldPath, executablePath); restoreErr != nil {
+			log.Printf("failed restoring executable %s: %v", executablePath, restoreErr)
+		}
+
+		return fmt.Errorf("installing executable: %w", err)
+	}
+
+	return nil
+}
+
+// Gets the manifest of the release, verifying it's signed with the release signing key and that it's the manifest of a
+// version of the current platform newer than the current version
+func (m *Manager) verifyManifest(ctx context.Context, release *Release) (*releaseManifest, error) {
+	if m.signingKey == nil {
+		return nil, errors.New(
+			"this build of azd doesn't embed the release signing key, install a release of azd instead")
+	}
+
+	content, err := m.get(ctx, release.ManifestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("getting manifest of release: %w", err)
+	}
+
+	signatureContent, err := m.get(ctx, release.SignatureUrl)
+	if err != nil {
+		return nil, fmt.Errorf("getting signature of release: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signatureContent)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: decoding signature: %v", ErrInvalidSignature, err)
+	}
+
+	// The manifest is published next to the release, only the signature proves it was published by azd
+	if !ed25519.Verify(m.signingKey, content, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var manifest releaseManifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest of release: %w", err)
+	}
+
+	version, err := semver.Parse(strings.TrimPrefix(manifest.Version, "v"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing version of manifest: %w", err)
+	}
+
+	// The version checked against the upgrade policy is the unsigned version of the channel
+	if !version.EQ(release.Version) {
+		return nil, fmt.Errorf("%w: the manifest is for version %s, expected %s",
+			ErrManifestMismatch, version, release.Version)
+	}
+
+	if platform := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH); manifest.Platform != platform {
+		return nil, fmt.Errorf("%w: the manifest is for platform %s, expected %s",
+			ErrManifestMismatch, manifest.Platform, platform)
+	}
+
+	currentVersion, err := semver.Parse(internal.GetVersionNumber())
+	if err != nil {
+		return nil, fmt.Errorf("parsing current version: %w", err)
+	}
+
+	if !version.GT(currentVersion) {
+		return nil, fmt.Errorf("%w: version %s, current version %s", ErrNotNewer, version, currentVersion)
+	}
+
+	return &manifest, nil
+}
+
+// Gets the content of the URL
+func (m *Manager) get(ctx context.Context, url string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := m.download(ctx, url, buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Downloads the content of the URL to the writer
+func (m *Manager) download(ctx context.Context, url string, w io.Writer) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("User-Agent", internal.MakeUserAgentString(""))
+
+	response, err := m.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("http error %d from %s", response.StatusCode, url)
+	}
+
+	_, err = io.Copy(w, response.Body)
+	return err
+}
+
+// Gets the name of the archive of the release for the current platform, ex) azd-linux-amd64.tar.gz
+func artifactName() string {
+	extension := "zip"
+	if runtime.GOOS == "linux" {
+		extension = "tar.gz"
+	}
+
+	return fmt.Sprintf("azd-%s-%s.%s", runtime.GOOS, runtime.GOARCH, extension)
+}
+
+// Gets whether the file of the archive is the azd executable, ex) azd-linux-amd64 or azd.exe
+func isExecutable(name string) bool {
+	name = strings.TrimSuffix(filepath.Base(name), ".exe")
+	return name == "azd" || name == fmt.Sprintf("azd-%s-%s", runtime.GOOS, runtime.GOARCH)
+}
+
+// Extracts the azd executable of the archive to the destination
+func extractExecutable(archivePath string, artifactUrl string, dst string) error {
+	if strings.HasSuffix(artifactUrl, ".zip") {
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		for _, file := range reader.File {
+			if file.FileInfo().IsDir() || !isExecutable(file.Name) {
+				continue
+			}
+
+			content, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer content.Close()
+
+			return writeExecutable(content, dst)
+		}
+
+		return errors.New("the archive doesn't contain the azd executable")
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return errors.New("the archive doesn't contain the azd executable")
+		}
+		if err != nil {
+			return err
+		}
+
+		// cspell: disable-next-line `Typeflag` is comming fron *tar.Header
+		if header.Typeflag == tar.TypeReg && isExecutable(header.Name) {
+			return writeExecutable(tarReader, dst)
+		}
+	}
+}
+
+func writeExecutable(content io.Reader, dst string) error {
+	file, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	/* #nosec G110 - decompression bomb false positive */
+	_, err = io.Copy(file, content)
+	return err
+}
@@ -0,0 +1,309 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/internal"
+	"github.com/azure/azure-dev/cli/azd/pkg/config"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadConfig(t *testing.T) {
+	writePolicy := func(t *testing.T, contents string) string {
+		policyFilePath := filepath.Join(t.TempDir(), "upgrade.yaml")
+		require.NoError(t, os.WriteFile(policyFilePath, []byte(contents), 0600))
+		return policyFilePath
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		upgradeConfig, err := LoadConfig(config.NewConfig(nil), filepath.Join(t.TempDir(), "upgrade.yaml"))
+		require.NoError(t, err)
+		require.Equal(t, ChannelStable, upgradeConfig.Channel)
+		require.Nil(t, upgradeConfig.MaxVersion)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		upgradeConfig, err := LoadConfig(config.NewConfig(map[string]any{
+			"upgrade": map[string]any{"channel": "Beta"},
+		}), writePolicy(t, "maxVersion: v1.5.0\n"))
+		require.NoError(t, err)
+		require.Equal(t, ChannelBeta, upgradeConfig.Channel)
+		require.Equal(t, semver.MustParse("1.5.0"), *upgradeConfig.MaxVersion)
+	})
+
+	t.Run("UserMaxVersionIgnored", func(t *testing.T) {
+		upgradeConfig, err := LoadConfig(config.NewConfig(map[string]any{
+			"upgrade": map[string]any{"maxVersion": "1.5.0"},
+		}), filepath.Join(t.TempDir(), "upgrade.yaml"))
+		require.NoError(t, err)
+		require.Nil(t, upgradeConfig.MaxVersion)
+	})
+
+	t.Run("InvalidPolicy", func(t *testing.T) {
+		_, err := LoadConfig(config.NewConfig(nil), writePolicy(t, "maxVersion: latest\n"))
+		require.Error(t, err)
+
+		_, err = LoadConfig(config.NewConfig(nil), writePolicy(t, "maxVersion: [\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidChannel", func(t *testing.T) {
+		_, err := LoadConfig(config.NewConfig(map[string]any{
+			"upgrade": map[string]any{"channel": "nightly"},
+		}), filepath.Join(t.TempDir(), "upgrade.yaml"))
+		require.Error(t, err)
+	})
+}
+
+func Test_Manager_CheckForUpdate(t *testing.T) {
+	setVersion(t, "1.2.0")
+
+	tests := []struct {
+		name          string
+		latest        string
+		maxVersion    string
+		expectRelease bool
+		expectErr     error
+	}{
+		{name: "UpToDate", latest: "1.2.0"},
+		{name: "Newer", latest: "1.3.0", expectRelease: true},
+		{name: "Approved", latest: "1.3.0", maxVersion: "1.3.0", expectRelease: true},
+		{name: "NotApproved", latest: "1.4.0", maxVersion: "1.3.0", expectRelease: true, expectErr: ErrVersionNotApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := mockhttp.NewMockHttpUtil()
+			httpClient.WhenPath(http.MethodGet, "/beta/version.txt").
+				RespondFn(func(request *http.Request) (*http.Response, error) {
+					return createResponse(request, []byte(tt.latest+"\n")), nil
+				})
+
+			upgradeConfig := &Config{Channel: ChannelBeta}
+			if tt.maxVersion != "" {
+				maxVersion := semver.MustParse(tt.maxVersion)
+				upgradeConfig.MaxVersion = &maxVersion
+			}
+
+			manager := &Manager{httpClient: httpClient, baseUrl: "https://releases.test"}
+			release, err := manager.CheckForUpdate(context.Background(), upgradeConfig)
+			if tt.expectErr != nil {
+				require.ErrorIs(t, err, tt.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if !tt.expectRelease {
+				require.Nil(t, release)
+				return
+			}
+
+			require.Equal(t, semver.MustParse(tt.latest), release.Version)
+			require.Equal(t, ChannelBeta, release.Channel)
+			require.Equal(t, "https://releases.test/beta/"+artifactName(), release.ArtifactUrl)
+			require.Equal(t, release.ArtifactUrl+".manifest", release.ManifestUrl)
+			require.Equal(t, release.ArtifactUrl+".manifest.sig", release.SignatureUrl)
+		})
+	}
+}
+
+func Test_Manager_Install(t *testing.T) {
+	setVersion(t, "1.2.0")
+
+	archive := createArchive(t, []byte("new azd"))
+	hash := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(hash[:])
+	platform := fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	setup := func(
+		t *testing.T, version string, manifest []byte, signingKey ed25519.PrivateKey,
+	) (*Manager, *Release, string) {
+		signature := base64.StdEncoding.EncodeToString(ed25519.Sign(signingKey, manifest))
+
+		httpClient := mockhttp.NewMockHttpUtil()
+		httpClient.WhenPath(http.MethodGet, "/stable/"+artifactName()).
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				return createResponse(request, archive), nil
+			})
+		httpClient.WhenPath(http.MethodGet, "/stable/"+artifactName()+".manifest").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				return createResponse(request, manifest), nil
+			})
+		httpClient.WhenPath(http.MethodGet, "/stable/"+artifactName()+".manifest.sig").
+			RespondFn(func(request *http.Request) (*http.Response, error) {
+				return createResponse(request, []byte(signature+"\n")), nil
+			})
+
+		executablePath := filepath.Join(t.TempDir(), "azd")
+		require.NoError(t, os.WriteFile(executablePath, []byte("old azd"), 0600))
+
+		artifactUrl := "https://releases.test/stable/" + artifactName()
+		release := &Release{
+			Channel:      ChannelStable,
+			Version:      semver.MustParse(version),
+			ArtifactUrl:  artifactUrl,
+			ManifestUrl:  artifactUrl + ".manifest",
+			SignatureUrl: artifactUrl + ".manifest.sig",
+		}
+
+		manager := &Manager{httpClient: httpClient, baseUrl: "https://releases.test", signingKey: publicKey}
+		return manager, release, executablePath
+	}
+
+	requireNotInstalled := func(t *testing.T, executablePath string) {
+		content, err := os.ReadFile(executablePath)
+		require.NoError(t, err)
+		require.Equal(t, "old azd", string(content))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		manifest := createManifest("1.3.0", platform, strings.ToUpper(checksum))
+		manager, release, executablePath := setup(t, "1.3.0", manifest, privateKey)
+
+		err := manager.Install(context.Background(), release, executablePath)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(executablePath)
+		require.NoError(t, err)
+		require.Equal(t, "new azd", string(content))
+
+		content, err = os.ReadFile(executablePath + ".old")
+		require.NoError(t, err)
+		require.Equal(t, "old azd", string(content))
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		manifest := createManifest("1.3.0", platform, strings.Repeat("0", 64))
+		manager, release, executablePath := setup(t, "1.3.0", manifest, privateKey)
+
+		err := manager.Install(context.Background(), release, executablePath)
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+		requireNotInstalled(t, executablePath)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		manifest := createManifest("1.3.0", platform, checksum)
+		manager, release, executablePath := setup(t, "1.3.0", manifest, otherKey)
+
+		err = manager.Install(context.Background(), release, executablePath)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+		requireNotInstalled(t, executablePath)
+	})
+
+	t.Run("VersionMismatch", func(t *testing.T) {
+		// An older signed release served with the version of a newer release
+		manifest := createManifest("1.1.0", platform, checksum)
+		manager, release, executablePath := setup(t, "1.3.0", manifest, privateKey)
+
+		err := manager.Install(context.Background(), release, executablePath)
+		require.ErrorIs(t, err, ErrManifestMismatch)
+		requireNotInstalled(t, executablePath)
+	})
+
+	t.Run("PlatformMismatch", func(t *testing.T) {
+		manifest := createManifest("1.3.0", "plan9/mips", checksum)
+		manager, release, executablePath := setup(t, "1.3.0", manifest, privateKey)
+
+		err := manager.Install(context.Background(), release, executablePath)
+		require.ErrorIs(t, err, ErrManifestMismatch)
+		requireNotInstalled(t, executablePath)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		for _, version := range []string{"1.1.0", "1.2.0"} {
+			manifest := createManifest(version, platform, checksum)
+			manager, release, executablePath := setup(t, version, manifest, privateKey)
+
+			err := manager.Install(context.Background(), release, executablePath)
+			require.ErrorIs(t, err, ErrNotNewer)
+			requireNotInstalled(t, executablePath)
+		}
+	})
+
+	t.Run("NoSigningKey", func(t *testing.T) {
+		manifest := createManifest("1.3.0", platform, checksum)
+		manager, release, executablePath := setup(t, "1.3.0", manifest, privateKey)
+		manager.signingKey = nil
+
+		err := manager.Install(context.Background(), release, executablePath)
+		require.Error(t, err)
+		requireNotInstalled(t, executablePath)
+	})
+}
+
+func setVersion(t *testing.T, version string) {
+	orig := internal.Version
+	t.Cleanup(func() { internal.Version = orig })
+	internal.Version = version + " (commit 13ec2b11aa755b11640fa16b8664cb8741d5d300)"
+}
+
+func createResponse(request *http.Request, body []byte) *http.Response {
+	return &http.Response{
+		Request:    request,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// Creates the manifest of a release, signed by the tests
+func createManifest(version string, platform string, checksum string) []byte {
+	return []byte(fmt.Sprintf("version: %s\nplatform: %s\nsha256: %s\n", version, platform, checksum))
+}
+
+// Creates the archive of a release for the current platform, with the executable
+func createArchive(t *testing.T, executable []byte) []byte {
+	buf := &bytes.Buffer{}
+
+	if strings.HasSuffix(artifactName(), ".zip") {
+		zipWriter := zip.NewWriter(buf)
+		file, err := zipWriter.Create("azd.exe")
+		require.NoError(t, err)
+		_, err = file.Write(executable)
+		require.NoError(t, err)
+		require.NoError(t, zipWriter.Close())
+
+		return buf.Bytes()
+	}
+
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+		Name:     "azd",
+		Mode:     0755,
+		Size:     int64(len(executable)),
+		Typeflag: tar.TypeReg,
+	}))
+	_, err := tarWriter.Write(executable)
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+
+	return buf.Bytes()
+}
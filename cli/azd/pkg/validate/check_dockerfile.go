@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Lints the Dockerfiles of the services with hadolint, whether or not the lint is enabled for the builds of the services.
+// The rules ignored by the lint of a service are ignored. Errors of hadolint are errors, warnings are warnings and the
+// other levels are notes.
+func (vr *Validator) checkDockerfiles(ctx context.Context, v *validation) ([]Issue, string, error) {
+	services := []*project.ServiceConfig{}
+	names := maps.Keys(v.projectConfig.Services)
+	slices.Sort(names)
+	for _, name := range names {
+		if svc := v.projectConfig.Services[name]; svc.DockerfilePath() != "" {
+			services = append(services, svc)
+		}
+	}
+
+	if len(services) == 0 {
+		return nil, "no service is built from a Dockerfile", nil
+	}
+
+	if installed, err := vr.hadolintCli.CheckInstalled(ctx); err != nil || !installed {
+		return nil, fmt.Sprintf("%s isn't installed, see %s", vr.hadolintCli.Name(), vr.hadolintCli.InstallUrl()), nil
+	}
+
+	issues := []Issue{}
+	for _, svc := range services {
+		dockerfilePath := svc.DockerfilePath()
+		if _, err := os.Stat(dockerfilePath); errors.Is(err, os.ErrNotExist) {
+			issues = append(issues, Issue{
+				Rule:     "dockerfile-not-found",
+				Severity: SeverityNote,
+				Message: fmt.Sprintf(
+					"service '%s' has no Dockerfile, run 'azd init' to generate one from its language", svc.Name),
+				File: v.relativePath(dockerfilePath),
+			})
+			continue
+		}
+
+		violations, err := vr.hadolintCli.Lint(ctx, svc.Path(), dockerfilePath, svc.Docker.Lint.Ignore)
+		if err != nil {
+			return issues, "", err
+		}
+
+		for _, violation := range violations {
+			issues = append(issues, Issue{
+				Rule:     violation.Code,
+				Severity: hadolintSeverity(violation.Level),
+				Message:  violation.Message,
+				File:     v.relativePath(dockerfilePath),
+				Line:     violation.Line,
+			})
+		}
+	}
+
+	return issues, "", nil
+}
+
+func hadolintSeverity(level string) Severity {
+	switch strings.ToLower(level) {
+	case "error":
+		return SeverityError
+	case "warning":
+		return SeverityWarning
+	default:
+		return SeverityNote
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"golang.org/x/exp/slices"
+)
+
+// Matches the references to environment variables without defaults, ex) ${AZURE_LOCATION}
+var envReferenceRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// The environment variables set by azd when the infrastructure is provisioned, which aren't in the environment before
+var provisionVariables = []string{environment.PrincipalIdEnvVarName}
+
+// Checks the parameters file of the infrastructure against the parameters of the compiled template and the environment.
+// Parameters the template doesn't declare are errors, since the deployment fails on them. Required parameters missing from
+// the file and references to variables unset in the environment are warnings, since azd prompts for them, which fails
+// non-interactive runs.
+func (vr *Validator) checkParameters(ctx context.Context, v *validation) ([]Issue, string, error) {
+	if !v.usesBicep() {
+		return nil, fmt.Sprintf("%s infrastructure isn't checked", v.projectConfig.Infra.Provider), nil
+	}
+
+	infraDir, module := v.infraModule()
+	parametersPath := filepath.Join(infraDir, module+".parameters.json")
+	content, err := os.ReadFile(parametersPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "the infrastructure has no parameters file", nil
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	parametersFile := v.relativePath(parametersPath)
+	parametersJson := string(content)
+
+	var parameters azure.ArmParameterFile
+	if err := json.Unmarshal(content, &parameters); err != nil {
+		issue := Issue{Rule: "invalid-json", Severity: SeverityError, Message: err.Error(), File: parametersFile}
+
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			issue.Line = strings.Count(parametersJson[:syntaxErr.Offset], "\n") + 1
+		}
+
+		return []Issue{issue}, "", nil
+	}
+
+	issues := []Issue{}
+
+	if v.template != nil {
+		for name := range parameters.Parameters {
+			if _, has := v.template.Parameters[name]; !has {
+				issues = append(issues, Issue{
+					Rule:     "undeclared-parameter",
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("parameter '%s' isn't declared by the infrastructure module '%s'", name, module),
+					File:     parametersFile,
+					Line:     lineOf(parametersJson, fmt.Sprintf("%q", name)),
+				})
+			}
+		}
+
+		for name, definition := range v.template.Parameters {
+			if _, has := parameters.Parameters[name]; has || definition.DefaultValue != nil {
+				continue
+			}
+
+			issues = append(issues, Issue{
+				Rule:     "missing-parameter",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("required parameter '%s' has no value, azd prompts for it when provisioning", name),
+				File:     parametersFile,
+			})
+		}
+	}
+
+	if v.options.Env != nil {
+		reported := map[string]bool{}
+		for _, matches := range envReferenceRegex.FindAllStringSubmatch(parametersJson, -1) {
+			name := matches[1]
+			if reported[name] || slices.Contains(provisionVariables, name) || v.options.Env.Getenv(name) != "" {
+				continue
+			}
+
+			reported[name] = true
+			issues = append(issues, Issue{
+				Rule:     "unset-variable",
+				Severity: SeverityWarning,
+				Message: fmt.Sprintf(
+					"variable '%s' isn't set in environment '%s'", name, v.options.Env.GetEnvName()),
+				File: parametersFile,
+				Line: lineOf(parametersJson, matches[0]),
+			})
+		}
+	}
+
+	slices.SortFunc(issues, func(a, b Issue) bool {
+		return a.Line < b.Line || (a.Line == b.Line && a.Message < b.Message)
+	})
+
+	return issues, "", nil
+}
@@ -0,0 +1,279 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/ext"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The environment variable the script checked by PowerShell is passed in
+const powershellScriptEnvVarName = "AZD_VALIDATE_SCRIPT"
+
+// Parses the script of the environment variable with the PowerShell parser, printing each error as <line>: <message>.
+// The script isn't run.
+var powershellParseCommand = fmt.Sprintf(
+	"$errors = $null; "+
+		"[System.Management.Automation.Language.Parser]::ParseInput($env:%s, [ref]$null, [ref]$errors) | Out-Null; "+
+		"$errors | ForEach-Object { '{0}: {1}' -f $_.Extent.StartLineNumber, $_.Message }",
+	powershellScriptEnvVarName,
+)
+
+// Matches the syntax errors of bash, ex) bash: -c: line 3: syntax error near unexpected token `fi'
+var bashErrorRegex = regexp.MustCompile(`(?m)line (\d+): (.*)$`)
+
+// Matches the syntax errors printed by the PowerShell parse command, ex) 3: Missing closing '}' in statement block
+var powershellErrorRegex = regexp.MustCompile(`(?m)^(\d+): (.*)$`)
+
+// A hook of the project or of a service
+type hookToCheck struct {
+	// The name of the hook, ex) preprovision or api/predeploy (windows)
+	name string
+	// The key of the hook in the azure.yaml file, ex) predeploy
+	key    string
+	config *ext.HookConfig
+	// Whether the configuration is the OS specific configuration of the hook
+	override bool
+	// The directory script paths are relative to
+	dir string
+}
+
+// Checks the syntax of the scripts of the hooks of the project and of its services, including the OS specific scripts of
+// the hooks. Scripts are parsed by their shell without running them. Hooks without scripts or shells are errors, since
+// they fail when they run.
+func (vr *Validator) checkHooks(ctx context.Context, v *validation) ([]Issue, string, error) {
+	hooks := collectHooks("", v.projectDir, v.projectConfig.Hooks)
+
+	serviceNames := maps.Keys(v.projectConfig.Services)
+	slices.Sort(serviceNames)
+	for _, name := range serviceNames {
+		svc := v.projectConfig.Services[name]
+		hooks = append(hooks, collectHooks(name+"/", svc.Path(), svc.Hooks)...)
+	}
+
+	if len(hooks) == 0 {
+		return nil, "the project has no hooks", nil
+	}
+
+	issues := []Issue{}
+	for _, hook := range hooks {
+		hookIssues, err := vr.checkHook(ctx, v, hook)
+		if err != nil {
+			return issues, "", err
+		}
+
+		issues = append(issues, hookIssues...)
+	}
+
+	return issues, "", nil
+}
+
+// Gets the hooks of the configurations, with their OS specific configurations, sorted by name
+func collectHooks(prefix string, dir string, configs map[string]*ext.HookConfig) []hookToCheck {
+	names := maps.Keys(configs)
+	slices.Sort(names)
+
+	hooks := []hookToCheck{}
+	for _, name := range names {
+		config := configs[name]
+		if config == nil {
+			continue
+		}
+
+		hooks = append(hooks, hookToCheck{name: prefix + name, key: name, config: config, dir: dir})
+		overrides := []struct {
+			os     string
+			config *ext.HookConfig
+		}{{"windows", config.Windows}, {"posix", config.Posix}}
+		for _, override := range overrides {
+			if override.config != nil {
+				hooks = append(hooks, hookToCheck{
+					name:     fmt.Sprintf("%s%s (%s)", prefix, name, override.os),
+					key:      name,
+					config:   override.config,
+					dir:      dir,
+					override: true,
+				})
+			}
+		}
+	}
+
+	return hooks
+}
+
+func (vr *Validator) checkHook(ctx context.Context, v *validation, hook hookToCheck) ([]Issue, error) {
+	hookLine := lineOfKey(v.projectYaml, hook.key)
+	issues := []Issue{}
+	if !hook.override &&
+		!strings.HasPrefix(hook.key, string(ext.HookTypePre)) &&
+		!strings.HasPrefix(hook.key, string(ext.HookTypePost)) {
+		issues = append(issues, Issue{
+			Rule:     "unknown-hook",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("hook '%s' never runs, hook names start with 'pre' or 'post'", hook.name),
+			File:     v.projectFile,
+			Line:     hookLine,
+		})
+	}
+
+	// OS specific configurations of hooks with scripts only override the scripts on their OS
+	if hook.config.Run == "" && (hook.config.Windows != nil || hook.config.Posix != nil) {
+		return issues, nil
+	}
+
+	if hook.config.Run == "" {
+		return append(issues, Issue{
+			Rule:     "missing-script",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("hook '%s' has no script, 'run' is required", hook.name),
+			File:     v.projectFile,
+			Line:     hookLine,
+		}), nil
+	}
+
+	shell := hook.config.Shell
+	script := hook.config.Run
+	file := v.projectFile
+	scriptPath := filepath.Join(hook.dir, filepath.FromSlash(hook.config.Run))
+	if filepath.IsAbs(hook.config.Run) {
+		scriptPath = hook.config.Run
+	}
+
+	if stats, err := os.Stat(scriptPath); err == nil && !stats.IsDir() {
+		content, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return issues, err
+		}
+
+		script = string(content)
+		file = v.relativePath(scriptPath)
+
+		if shell == ext.ScriptTypeUnknown {
+			switch filepath.Ext(scriptPath) {
+			case ".sh":
+				shell = ext.ShellTypeBash
+			case ".ps1":
+				shell = ext.ShellTypePowershell
+			default:
+				return append(issues, Issue{
+					Rule:     "unsupported-script",
+					Severity: SeverityError,
+					Message: fmt.Sprintf(
+						"script of hook '%s' isn't supported, only '.sh' and '.ps1' scripts are supported", hook.name),
+					File: file,
+				}), nil
+			}
+		}
+	} else if shell == ext.ScriptTypeUnknown {
+		return append(issues, Issue{
+			Rule:     "missing-shell",
+			Severity: SeverityError,
+			Message: fmt.Sprintf(
+				"inline script of hook '%s' has no shell, 'shell' is required, sh or pwsh", hook.name),
+			File: v.projectFile,
+			Line: hookLine,
+		}), nil
+	}
+
+	syntaxErrors, err := vr.parseScript(ctx, shell, script)
+	if err != nil {
+		return issues, err
+	}
+
+	for _, syntaxErr := range syntaxErrors {
+		issue := Issue{
+			Rule:     "syntax-error",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("hook '%s': %s", hook.name, syntaxErr.message),
+			File:     file,
+			Line:     syntaxErr.line,
+		}
+
+		// The lines of inline scripts are relative to the script, not to the azure.yaml file
+		if file == v.projectFile {
+			issue.Line = hookLine
+			if syntaxErr.line > 0 {
+				issue.Message = fmt.Sprintf("hook '%s', line %d of the script: %s", hook.name, syntaxErr.line, syntaxErr.message)
+			}
+		}
+
+		issues = append(issues, issue)
+	}
+
+	if syntaxErrors == nil {
+		issues = append(issues, Issue{
+			Rule:     "shell-not-installed",
+			Severity: SeverityNote,
+			Message:  fmt.Sprintf("script of hook '%s' isn't checked, %s isn't installed", hook.name, shellCommand(shell)),
+			File:     file,
+		})
+	}
+
+	return issues, nil
+}
+
+type scriptSyntaxError struct {
+	line    int
+	message string
+}
+
+func shellCommand(shell ext.ShellType) string {
+	if shell == ext.ShellTypePowershell {
+		return "pwsh"
+	}
+
+	return "bash"
+}
+
+// Parses the script with its shell without running it. Returns the syntax errors of the script, or nil when the shell
+// isn't installed.
+func (vr *Validator) parseScript(ctx context.Context, shell ext.ShellType, script string) ([]scriptSyntaxError, error) {
+	command := shellCommand(shell)
+	if found, err := tools.ToolInPath(command); err != nil || !found {
+		return nil, nil
+	}
+
+	var runArgs exec.RunArgs
+	errorRegex := bashErrorRegex
+	if shell == ext.ShellTypePowershell {
+		runArgs = exec.NewRunArgs(command, "-NoProfile", "-NonInteractive", "-Command", powershellParseCommand).
+			WithEnv([]string{fmt.Sprintf("%s=%s", powershellScriptEnvVarName, script)})
+		errorRegex = powershellErrorRegex
+	} else {
+		runArgs = exec.NewRunArgs(command, "-n", "-c", script)
+	}
+
+	res, err := vr.commandRunner.Run(ctx, runArgs)
+	if err != nil && res.ExitCode == 0 {
+		return nil, fmt.Errorf("parsing script with %s: %w", command, err)
+	}
+
+	syntaxErrors := []scriptSyntaxError{}
+	for _, matches := range errorRegex.FindAllStringSubmatch(res.Stdout+res.Stderr, -1) {
+		// bash prints the line of each syntax error after the error, ex) bash: -c: line 3: `fi'
+		if strings.HasPrefix(matches[2], "`") {
+			continue
+		}
+
+		line, _ := strconv.Atoi(matches[1])
+		syntaxErrors = append(syntaxErrors, scriptSyntaxError{line: line, message: strings.TrimSpace(matches[2])})
+	}
+
+	if len(syntaxErrors) == 0 && err != nil {
+		syntaxErrors = append(syntaxErrors, scriptSyntaxError{message: strings.TrimSpace(res.Stderr)})
+	}
+
+	return syntaxErrors, nil
+}
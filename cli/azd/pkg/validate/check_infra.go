@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/infra/provisioning"
+)
+
+// Matches the diagnostics of bicep, ex) /app/infra/main.bicep(12,5) : Error BCP037: The property "sku" is not allowed.
+var bicepDiagnosticRegex = regexp.MustCompile(`(?m)^(.+)\((\d+),(\d+)\)\s*:\s*(Error|Warning|Info)\s+([\w-]+):\s*(.*)$`)
+
+// Gets the path of the infrastructure directory and the name of the module of the project, with their defaults
+func (v *validation) infraModule() (string, string) {
+	infraPath := v.projectConfig.Infra.Path
+	if strings.TrimSpace(infraPath) == "" {
+		infraPath = "infra"
+	}
+
+	module := v.projectConfig.Infra.Module
+	if strings.TrimSpace(module) == "" {
+		module = "main"
+	}
+
+	return filepath.Join(v.projectDir, infraPath), module
+}
+
+// Gets whether the infrastructure of the project is provisioned with bicep
+func (v *validation) usesBicep() bool {
+	provider := v.projectConfig.Infra.Provider
+	return provider == "" || provider == provisioning.Bicep
+}
+
+// Compiles the bicep module of the infrastructure of the project. The diagnostics of bicep are reported at their lines.
+func (vr *Validator) checkInfra(ctx context.Context, v *validation) ([]Issue, string, error) {
+	if !v.usesBicep() {
+		return nil, fmt.Sprintf("%s infrastructure isn't compiled", v.projectConfig.Infra.Provider), nil
+	}
+
+	infraDir, module := v.infraModule()
+	if _, err := os.Stat(infraDir); errors.Is(err, os.ErrNotExist) {
+		return nil, "the project has no infrastructure", nil
+	}
+
+	modulePath := filepath.Join(infraDir, module+".bicep")
+	if _, err := os.Stat(modulePath); errors.Is(err, os.ErrNotExist) {
+		return []Issue{
+			{
+				Rule:     "module-not-found",
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("the infrastructure module '%s' doesn't exist", module),
+				File:     v.relativePath(modulePath),
+			},
+		}, "", nil
+	}
+
+	compiled, err := vr.bicepCli.Build(ctx, modulePath)
+	if err != nil {
+		// The diagnostics are printed to the standard error of bicep, one per line
+		output := err.Error()
+		if index := strings.Index(output, "stderr: "); index >= 0 {
+			output = output[index+len("stderr: "):]
+		}
+
+		issues := []Issue{}
+		for _, matches := range bicepDiagnosticRegex.FindAllStringSubmatch(output, -1) {
+			line, _ := strconv.Atoi(matches[2])
+			issues = append(issues, Issue{
+				Rule:     matches[5],
+				Severity: bicepSeverity(matches[4]),
+				Message:  strings.TrimSpace(matches[6]),
+				File:     v.relativePath(matches[1]),
+				Line:     line,
+			})
+		}
+
+		if len(issues) == 0 {
+			return nil, "", err
+		}
+
+		return issues, "", nil
+	}
+
+	var template azure.ArmTemplate
+	if err := json.Unmarshal([]byte(compiled), &template); err != nil {
+		return nil, "", fmt.Errorf("parsing compiled template: %w", err)
+	}
+
+	v.template = &template
+	return nil, "", nil
+}
+
+func bicepSeverity(level string) Severity {
+	switch level {
+	case "Error":
+		return SeverityError
+	case "Warning":
+		return SeverityWarning
+	default:
+		return SeverityNote
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"gopkg.in/yaml.v3"
+)
+
+// Matches the line of the errors of yaml, ex) line 12: field dockr not found in type project.ServiceConfig
+var yamlLineRegex = regexp.MustCompile(`line (\d+): (.*)`)
+
+// Checks azure.yaml against the schema of the project. Unknown properties are errors, since they're ignored by azd and
+// usually are typos, ex) dockr instead of docker. The project is then parsed, failing on invalid values, ex) unsupported
+// hosts.
+func (vr *Validator) checkSchema(ctx context.Context, v *validation) ([]Issue, string, error) {
+	issues := []Issue{}
+
+	decoder := yaml.NewDecoder(strings.NewReader(v.projectYaml))
+	decoder.KnownFields(true)
+
+	var strictConfig project.ProjectConfig
+	err := decoder.Decode(&strictConfig)
+
+	var typeErr *yaml.TypeError
+	switch {
+	case errors.As(err, &typeErr):
+		for _, message := range typeErr.Errors {
+			issues = append(issues, v.yamlIssue(message))
+		}
+	case err != nil:
+		// Syntax errors, the project can't be parsed
+		return append(issues, v.yamlIssue(err.Error())), "", nil
+	}
+
+	projectConfig, err := project.Parse(ctx, v.projectYaml)
+	if err != nil {
+		issues = append(issues, Issue{
+			Rule:     "invalid-configuration",
+			Severity: SeverityError,
+			Message:  err.Error(),
+			File:     v.projectFile,
+		})
+
+		return issues, "", nil
+	}
+
+	projectConfig.Path = v.projectDir
+	v.projectConfig = projectConfig
+
+	return issues, "", nil
+}
+
+// Converts an error of yaml to an issue of the azure.yaml file, at the line of the error
+func (v *validation) yamlIssue(message string) Issue {
+	issue := Issue{
+		Rule:     "invalid-configuration",
+		Severity: SeverityError,
+		Message:  strings.TrimPrefix(message, "yaml: "),
+		File:     v.projectFile,
+	}
+
+	if matches := yamlLineRegex.FindStringSubmatch(message); matches != nil {
+		issue.Line, _ = strconv.Atoi(matches[1])
+		issue.Message = matches[2]
+	}
+
+	if strings.Contains(issue.Message, "not found in type") {
+		issue.Rule = "unknown-property"
+	}
+
+	return issue
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as a JUnit XML document, ex) for the test reports of CI pipelines. Each check is a test
+// suite and each of its issues a test case, failed when the issue fails the validation. Checks without issues have a
+// single passing test case and skipped checks a single skipped test case.
+func WriteJUnit(writer io.Writer, report *Report) error {
+	suites := junitTestSuites{Name: "azd validate"}
+
+	for _, check := range report.Checks {
+		suite := junitTestSuite{
+			Name: check.Title,
+			Time: formatJUnitSeconds(check),
+		}
+
+		switch {
+		case check.Skipped != "":
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      check.Title,
+				ClassName: check.Name,
+				Skipped:   &junitMessage{Message: check.Skipped},
+			})
+			suite.Skipped++
+		case len(check.Issues) == 0:
+			suite.TestCases = append(suite.TestCases, junitTestCase{Name: check.Title, ClassName: check.Name})
+		}
+
+		for _, issue := range check.Issues {
+			name := issue.Rule
+			if location := issue.Location(); location != "" {
+				name = fmt.Sprintf("%s (%s)", issue.Rule, location)
+			}
+
+			testCase := junitTestCase{Name: name, ClassName: check.Name}
+			if issue.Fails(report.Strict) {
+				testCase.Failure = &junitMessage{
+					Message: issue.Message,
+					Type:    string(issue.Severity),
+					Text:    fmt.Sprintf("%s: %s", issue.Location(), issue.Message),
+				}
+				suite.Failures++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	var total float64
+	for _, check := range report.Checks {
+		total += check.Duration.Seconds()
+	}
+	suites.Time = fmt.Sprintf("%.3f", total)
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(writer, "\n")
+	return err
+}
+
+func formatJUnitSeconds(check *CheckResult) string {
+	return fmt.Sprintf("%.3f", check.Duration.Seconds())
+}
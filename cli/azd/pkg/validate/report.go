@@ -0,0 +1,92 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package validate implements the side-effect-free validation of projects run by azd validate, suitable for pre-commit
+// hooks and pull request checks. The results are reported as SARIF or JUnit documents for CI systems.
+package validate
+
+import (
+	"fmt"
+	"time"
+)
+
+type Severity string
+
+const (
+	// Errors fail the validation
+	SeverityError Severity = "error"
+	// Warnings fail the validation in strict mode, ex) azd validate --ci
+	SeverityWarning Severity = "warning"
+	// Notes never fail the validation, ex) a check skipped because its tool isn't installed
+	SeverityNote Severity = "note"
+)
+
+// Issue is a problem found by a check of the validation
+type Issue struct {
+	// The id of the rule of the issue, ex) unknown-property or DL3008
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// The path of the file of the issue relative to the project directory, with forward slashes, ex) infra/main.bicep
+	File string `json:"file,omitempty"`
+	// The line of the issue in the file, starting at 1. Zero when the line isn't known.
+	Line int `json:"line,omitempty"`
+}
+
+// Location gets the location of the issue, ex) azure.yaml:12
+func (i Issue) Location() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+
+	return i.File
+}
+
+// Fails gets whether the issue fails the validation
+func (i Issue) Fails(strict bool) bool {
+	return i.Severity == SeverityError || (strict && i.Severity == SeverityWarning)
+}
+
+// CheckResult is the result of a check of the validation
+type CheckResult struct {
+	// The name of the check, ex) schema
+	Name string `json:"name"`
+	// The description of the check, ex) azure.yaml schema
+	Title  string  `json:"title"`
+	Issues []Issue `json:"issues"`
+	// The reason the check didn't run, ex) the project has no infrastructure
+	Skipped  string        `json:"skipped,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Failed gets whether an issue of the check fails the validation
+func (r *CheckResult) Failed(strict bool) bool {
+	for _, issue := range r.Issues {
+		if issue.Fails(strict) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Report is the result of the checks of the validation
+type Report struct {
+	Checks []*CheckResult `json:"checks"`
+	// Whether warnings fail the validation
+	Strict bool `json:"strict"`
+}
+
+// Failures counts the issues failing the validation
+func (r *Report) Failures() int {
+	failures := 0
+	for _, check := range r.Checks {
+		for _, issue := range check.Issues {
+			if issue.Fails(r.Strict) {
+				failures++
+			}
+		}
+	}
+
+	return failures
+}
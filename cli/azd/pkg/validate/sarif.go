@@ -0,0 +1,135 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSarif writes the report as a SARIF 2.1.0 log, ex) for code scanning alerts of pull requests. The ids of the rules
+// are prefixed with the name of their check, ex) schema/unknown-property. In strict mode, warnings are reported as
+// errors. The version is the version of azd reported as the version of the tool.
+func WriteSarif(writer io.Writer, report *Report, version string) error {
+	rules := []sarifRule{}
+	results := []sarifResult{}
+
+	for _, check := range report.Checks {
+		for _, issue := range check.Issues {
+			ruleId := fmt.Sprintf("%s/%s", check.Name, issue.Rule)
+			if slices.IndexFunc(rules, func(rule sarifRule) bool { return rule.Id == ruleId }) < 0 {
+				rules = append(rules, sarifRule{Id: ruleId, ShortDescription: sarifMessage{Text: check.Title}})
+			}
+
+			level := string(issue.Severity)
+			if issue.Fails(report.Strict) {
+				level = string(SeverityError)
+			}
+
+			result := sarifResult{
+				RuleId:  ruleId,
+				Level:   level,
+				Message: sarifMessage{Text: issue.Message},
+			}
+
+			if issue.File != "" {
+				location := sarifLocation{
+					PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{Uri: issue.File}},
+				}
+				if issue.Line > 0 {
+					location.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line}
+				}
+
+				result.Locations = []sarifLocation{location}
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "azd",
+						InformationUri: "https://aka.ms/azd",
+						Version:        version,
+						Rules:          rules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/azure"
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/project"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/bicep"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+)
+
+// Options are the options of a validation
+type Options struct {
+	// The path of the azure.yaml file of the project
+	ProjectFilePath string
+	// The environment the references of the infrastructure parameters are checked against. When nil, the references
+	// aren't checked.
+	Env *environment.Environment
+	// When true, warnings fail the validation
+	Strict bool
+	// Called with the title of each check before it runs, ex) to show the progress of the validation
+	CheckStarted func(title string)
+	// Called with the result of each check after it runs
+	CheckDone func(result *CheckResult)
+}
+
+// Validator validates projects without side effects: nothing is provisioned, built or deployed and no file of the project
+// is written. The checks only read the files of the project and run the linters and compilers of the files.
+type Validator struct {
+	bicepCli      bicep.BicepCli
+	hadolintCli   hadolint.HadolintCli
+	commandRunner exec.CommandRunner
+}
+
+// Creates a new instance of the validator
+func NewValidator(
+	bicepCli bicep.BicepCli,
+	hadolintCli hadolint.HadolintCli,
+	commandRunner exec.CommandRunner,
+) *Validator {
+	return &Validator{
+		bicepCli:      bicepCli,
+		hadolintCli:   hadolintCli,
+		commandRunner: commandRunner,
+	}
+}
+
+// The state shared by the checks of a validation
+type validation struct {
+	options Options
+	// The directory of the project
+	projectDir string
+	// The name of the azure.yaml file, ex) azure.yaml
+	projectFile string
+	// The content of the azure.yaml file
+	projectYaml string
+	// The project, nil when the azure.yaml file can't be parsed
+	projectConfig *project.ProjectConfig
+	// The compiled infrastructure template, nil when the infrastructure isn't compiled
+	template *azure.ArmTemplate
+}
+
+// Gets the path of the file relative to the project directory, with forward slashes, ex) infra/main.bicep
+func (v *validation) relativePath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(v.projectDir, path)
+	}
+
+	relative, err := filepath.Rel(v.projectDir, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(relative)
+}
+
+// A check of the validation. Returns the issues found, or the reason the check was skipped.
+type check struct {
+	name  string
+	title string
+	run   func(ctx context.Context, v *validation) ([]Issue, string, error)
+}
+
+// Run runs the checks of the validation of the project. The checks that can't run are skipped, ex) the Dockerfile lint
+// when no service is built from a Dockerfile. Failures of the checks themselves are reported as errors of the checks, so
+// the report is complete.
+func (vr *Validator) Run(ctx context.Context, options Options) (*Report, error) {
+	projectYaml, err := os.ReadFile(options.ProjectFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading project file: %w", err)
+	}
+
+	v := &validation{
+		options:     options,
+		projectDir:  filepath.Dir(options.ProjectFilePath),
+		projectFile: filepath.Base(options.ProjectFilePath),
+		projectYaml: string(projectYaml),
+	}
+
+	checks := []check{
+		{name: "schema", title: "azure.yaml schema", run: vr.checkSchema},
+		{name: "infra", title: "Infrastructure compilation", run: vr.checkInfra},
+		{name: "env", title: "Infrastructure parameters", run: vr.checkParameters},
+		{name: "dockerfile", title: "Dockerfile lint", run: vr.checkDockerfiles},
+		{name: "hooks", title: "Hook scripts syntax", run: vr.checkHooks},
+	}
+
+	report := &Report{Strict: options.Strict}
+	for _, check := range checks {
+		if options.CheckStarted != nil {
+			options.CheckStarted(check.title)
+		}
+
+		result := &CheckResult{Name: check.name, Title: check.title, Issues: []Issue{}}
+		start := time.Now()
+
+		// The other checks need the project, they're skipped when the azure.yaml file can't be parsed
+		if check.name != "schema" && v.projectConfig == nil {
+			result.Skipped = "azure.yaml is invalid"
+		} else {
+			issues, skipped, err := check.run(ctx, v)
+			if err != nil {
+				log.Printf("validation check '%s' failed: %v", check.name, err)
+				issues = append(issues, Issue{Rule: "check-failed", Severity: SeverityError, Message: err.Error()})
+			}
+
+			result.Issues = append(result.Issues, issues...)
+			result.Skipped = skipped
+		}
+
+		result.Duration = time.Since(start)
+		report.Checks = append(report.Checks, result)
+
+		if options.CheckDone != nil {
+			options.CheckDone(result)
+		}
+	}
+
+	return report, nil
+}
+
+// Gets the line of the first occurrence of the text in the content, starting at 1. Zero when the content doesn't contain
+// the text.
+func lineOf(content string, text string) int {
+	return findLine(content, func(line string) bool { return strings.Contains(line, text) })
+}
+
+// Gets the line of the first yaml key in the content, starting at 1, ex) the line of 'predeploy:'. Zero when the content
+// doesn't contain the key.
+func lineOfKey(content string, key string) int {
+	return findLine(content, func(line string) bool { return strings.HasPrefix(strings.TrimSpace(line), key+":") })
+}
+
+func findLine(content string, predicate func(line string) bool) int {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	line := 0
+	for scanner.Scan() {
+		line++
+		if predicate(scanner.Text()) {
+			return line
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,291 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/azure/azure-dev/cli/azd/pkg/environment"
+	"github.com/azure/azure-dev/cli/azd/pkg/exec"
+	"github.com/azure/azure-dev/cli/azd/pkg/osutil"
+	"github.com/azure/azure-dev/cli/azd/pkg/tools/hadolint"
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBicepCli struct {
+	template string
+	err      error
+}
+
+func (cli *fakeBicepCli) Build(ctx context.Context, file string) (string, error) {
+	return cli.template, cli.err
+}
+
+const testTemplate = `{
+  "parameters": {
+    "environmentName": { "type": "string" },
+    "location": { "type": "string" },
+    "sku": { "type": "string", "defaultValue": "B1" }
+  }
+}`
+
+func writeProject(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), osutil.PermissionDirectory))
+		require.NoError(t, os.WriteFile(path, []byte(content), osutil.PermissionFile))
+	}
+
+	return filepath.Join(dir, "azure.yaml")
+}
+
+func newTestValidator(mockContext *mocks.MockContext, bicepCli *fakeBicepCli) *Validator {
+	return NewValidator(bicepCli, hadolint.NewHadolintCli(mockContext.CommandRunner), mockContext.CommandRunner)
+}
+
+func findCheck(t *testing.T, report *Report, name string) *CheckResult {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+
+	require.Fail(t, "check not found", name)
+	return nil
+}
+
+func Test_Validator_Schema(t *testing.T) {
+	t.Run("UnknownProperty", func(t *testing.T) {
+		projectFile := writeProject(t, map[string]string{
+			"azure.yaml": "name: test\nservices:\n  api:\n    project: src/api\n    language: js\n    host: appservice\n" +
+				"    dockr:\n      path: Dockerfile\n",
+		})
+		mockContext := mocks.NewMockContext(context.Background())
+
+		report, err := newTestValidator(mockContext, &fakeBicepCli{}).Run(*mockContext.Context, Options{
+			ProjectFilePath: projectFile,
+		})
+		require.NoError(t, err)
+
+		schema := findCheck(t, report, "schema")
+		require.Len(t, schema.Issues, 1)
+		require.Equal(t, "unknown-property", schema.Issues[0].Rule)
+		require.Equal(t, "azure.yaml:7", schema.Issues[0].Location())
+		require.Contains(t, schema.Issues[0].Message, "dockr")
+		require.Equal(t, 1, report.Failures())
+
+		// The other checks run, the project is valid apart from the unknown property
+		require.Equal(t, "the project has no infrastructure", findCheck(t, report, "infra").Skipped)
+	})
+
+	t.Run("InvalidHost", func(t *testing.T) {
+		projectFile := writeProject(t, map[string]string{
+			"azure.yaml": "name: test\nservices:\n  api:\n    project: src/api\n    language: js\n    host: mainframe\n",
+		})
+		mockContext := mocks.NewMockContext(context.Background())
+
+		report, err := newTestValidator(mockContext, &fakeBicepCli{}).Run(*mockContext.Context, Options{
+			ProjectFilePath: projectFile,
+		})
+		require.NoError(t, err)
+
+		schema := findCheck(t, report, "schema")
+		require.Len(t, schema.Issues, 1)
+		require.Equal(t, "invalid-configuration", schema.Issues[0].Rule)
+		require.Equal(t, "azure.yaml is invalid", findCheck(t, report, "hooks").Skipped)
+	})
+}
+
+func Test_Validator_Parameters(t *testing.T) {
+	projectFile := writeProject(t, map[string]string{
+		"azure.yaml":       "name: test\n",
+		"infra/main.bicep": "param environmentName string\n",
+		"infra/main.parameters.json": `{
+  "parameters": {
+    "environmentName": { "value": "${AZURE_ENV_NAME}" },
+    "principalId": { "value": "${AZURE_PRINCIPAL_ID}" },
+    "vmSize": { "value": "${VM_SIZE}" }
+  }
+}`,
+	})
+	mockContext := mocks.NewMockContext(context.Background())
+	env := environment.EphemeralWithValues("dev", nil)
+
+	report, err := newTestValidator(mockContext, &fakeBicepCli{template: testTemplate}).Run(*mockContext.Context, Options{
+		ProjectFilePath: projectFile,
+		Env:             env,
+	})
+	require.NoError(t, err)
+
+	issues := findCheck(t, report, "env").Issues
+	rules := []string{}
+	for _, issue := range issues {
+		rules = append(rules, issue.Rule)
+	}
+
+	require.ElementsMatch(t, []string{
+		"undeclared-parameter", "undeclared-parameter", "missing-parameter", "unset-variable",
+	}, rules)
+	require.Equal(t, 2, report.Failures())
+
+	// Warnings fail strict validations
+	report.Strict = true
+	require.Equal(t, 4, report.Failures())
+}
+
+func Test_Validator_Infra(t *testing.T) {
+	projectFile := writeProject(t, map[string]string{
+		"azure.yaml":       "name: test\n",
+		"infra/main.bicep": "param location string\n",
+	})
+	mockContext := mocks.NewMockContext(context.Background())
+	modulePath := filepath.Join(filepath.Dir(projectFile), "infra", "main.bicep")
+	bicepCli := &fakeBicepCli{
+		err: errors.New("failed running bicep build: exit code: 1, stdout: , stderr: " + modulePath +
+			"(3,5) : Error BCP037: The property \"sku\" is not allowed on objects of type \"Plan\"."),
+	}
+
+	report, err := newTestValidator(mockContext, bicepCli).Run(*mockContext.Context, Options{
+		ProjectFilePath: projectFile,
+	})
+	require.NoError(t, err)
+
+	infra := findCheck(t, report, "infra")
+	require.Len(t, infra.Issues, 1)
+	require.Equal(t, Issue{
+		Rule:     "BCP037",
+		Severity: SeverityError,
+		Message:  "The property \"sku\" is not allowed on objects of type \"Plan\".",
+		File:     "infra/main.bicep",
+		Line:     3,
+	}, infra.Issues[0])
+}
+
+func Test_Validator_Hooks(t *testing.T) {
+	if _, err := osexec.LookPath("bash"); err != nil {
+		t.Skip("bash isn't installed")
+	}
+
+	projectFile := writeProject(t, map[string]string{
+		"azure.yaml": `name: test
+hooks:
+  preprovision:
+    shell: sh
+    run: echo hello
+  postdeploy:
+    run: scripts/postdeploy.sh
+  deploy:
+    shell: sh
+    run: echo never
+  prerestore:
+    run: echo no shell
+`,
+		"scripts/postdeploy.sh": "if true; then\n  echo done\nfi fi\n",
+	})
+	mockContext := mocks.NewMockContext(context.Background())
+	mockContext.CommandRunner.When(func(args exec.RunArgs, command string) bool {
+		return args.Cmd == "bash"
+	}).RespondFn(func(args exec.RunArgs) (exec.RunResult, error) {
+		require.Equal(t, "-n", args.Args[0])
+		if strings.Contains(args.Args[2], "fi fi") {
+			return exec.NewRunResult(2, "", "bash: -c: line 3: syntax error near unexpected token `fi'\n"+
+				"bash: -c: line 3: `fi fi'\n"), nil
+		}
+
+		return exec.NewRunResult(0, "", ""), nil
+	})
+
+	report, err := newTestValidator(mockContext, &fakeBicepCli{}).Run(*mockContext.Context, Options{
+		ProjectFilePath: projectFile,
+	})
+	require.NoError(t, err)
+
+	issues := findCheck(t, report, "hooks").Issues
+	require.Len(t, issues, 3)
+	require.Equal(t, Issue{
+		Rule:     "unknown-hook",
+		Severity: SeverityWarning,
+		Message:  "hook 'deploy' never runs, hook names start with 'pre' or 'post'",
+		File:     "azure.yaml",
+		Line:     8,
+	}, issues[0])
+	require.Equal(t, Issue{
+		Rule:     "syntax-error",
+		Severity: SeverityError,
+		Message:  "hook 'postdeploy': syntax error near unexpected token `fi'",
+		File:     "scripts/postdeploy.sh",
+		Line:     3,
+	}, issues[1])
+	require.Equal(t, "missing-shell", issues[2].Rule)
+	require.Equal(t, "azure.yaml:11", issues[2].Location())
+}
+
+func testReport() *Report {
+	return &Report{
+		Checks: []*CheckResult{
+			{
+				Name:  "schema",
+				Title: "azure.yaml schema",
+				Issues: []Issue{
+					{Rule: "unknown-property", Severity: SeverityError, Message: "field dockr not found", File: "azure.yaml", Line: 7},
+					{Rule: "unknown-hook", Severity: SeverityWarning, Message: "hook 'deploy' never runs", File: "azure.yaml"},
+				},
+			},
+			{Name: "infra", Title: "Infrastructure compilation", Issues: []Issue{}, Skipped: "the project has no infrastructure"},
+			{Name: "hooks", Title: "Hook scripts syntax", Issues: []Issue{}},
+		},
+	}
+}
+
+func Test_WriteSarif(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteSarif(buf, testReport(), "1.0.0"))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	require.Equal(t, "1.0.0", log.Runs[0].Tool.Driver.Version)
+	require.Len(t, log.Runs[0].Tool.Driver.Rules, 2)
+
+	results := log.Runs[0].Results
+	require.Len(t, results, 2)
+	require.Equal(t, "schema/unknown-property", results[0].RuleId)
+	require.Equal(t, "error", results[0].Level)
+	require.Equal(t, "azure.yaml", results[0].Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	require.Equal(t, 7, results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	require.Equal(t, "warning", results[1].Level)
+	require.Nil(t, results[1].Locations[0].PhysicalLocation.Region)
+}
+
+func Test_WriteJUnit(t *testing.T) {
+	report := testReport()
+	report.Strict = true
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteJUnit(buf, report))
+	require.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	require.Equal(t, 4, suites.Tests)
+	require.Equal(t, 2, suites.Failures)
+	require.Equal(t, 1, suites.Skipped)
+	require.Len(t, suites.Suites, 3)
+	require.Equal(t, "unknown-property (azure.yaml:7)", suites.Suites[0].TestCases[0].Name)
+	require.NotNil(t, suites.Suites[0].TestCases[1].Failure)
+	require.NotNil(t, suites.Suites[1].TestCases[0].Skipped)
+	require.Nil(t, suites.Suites[2].TestCases[0].Failure)
+}
@@ -0,0 +1,1198 @@
+package mockarmresources
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/azure/azure-dev/cli/azd/test/mocks"
+	"github.com/azure/azure-dev/cli/azd/test/mocks/mockhttp"
+)
+
+var (
+	deploymentPathRegex = regexp.MustCompile(
+		`(?i)^(/subscriptions/[^/]+(?:/resourceGroups/[^/]+)?)/providers/Microsoft\.Resources/deployments/([^/]+)$`,
+	)
+	deploymentListPathRegex = regexp.MustCompile(
+		`(?i)^(/subscriptions/[^/]+(?:/resourceGroups/[^/]+)?)/providers/Microsoft\.Resources/deployments/?$`,
+	)
+	deploymentOperationsPathRegex = regexp.MustCompile(
+		`(?i)^(/subscriptions/[^/]+(?:/resourceGroups/[^/]+)?/providers/Microsoft\.Resources/deployments/[^/]+)/operations$`,
+	)
+	resourceListPathRegex      = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourceGroups/([^/]+)/resources$`)
+	resourceGroupListPathRegex = regexp.MustCompile(`(?i)^/subscriptions/[^/]+/resourcegroups$`)
+	resourceTypeListPathRegex  = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)/providers/([^/]+/[^/]+)$`)
+	resourceActionPathRegex    = regexp.MustCompile(
+		`(?i)^(/subscriptions/.+)/(listCredentials|listKeys|listClusterAdminCredential|listClusterUserCredential)$`,
+	)
+)
+
+// The type of the resources of resource groups and nested deployments
+const (
+	resourceGroupType = "Microsoft.Resources/resourceGroups"
+	deploymentType    = "Microsoft.Resources/deployments"
+)
+
+// The types of outputs of deployments, as returned by ARM
+var outputTypes = map[string]string{
+	"string":       "String",
+	"securestring": "SecureString",
+	"int":          "Int",
+	"bool":         "Bool",
+	"object":       "Object",
+	"secureobject": "SecureObject",
+	"array":        "Array",
+}
+
+// errNotDeployed is returned when a resource references a resource of the deployment that isn't deployed yet
+var errNotDeployed = errors.New("resource isn't deployed yet")
+
+// DeploymentSimulator is a fake ARM deployment engine. It interprets the compiled templates deployed with the mocked
+// HTTP client enough to produce plausible outputs and resources: the template expressions of names, tags, properties and
+// outputs are evaluated, nested deployments are deployed, and the resources get the properties Azure computes, ex) the
+// login server of container registries. The deployments, resources and resource groups are then served to the ARM
+// clients of azd, so tests can run realistic provision and deploy flows without mocking each request.
+//
+//	sim := mockarmresources.NewDeploymentSimulator("SUBSCRIPTION_ID", "eastus2")
+//	sim.Register(mockContext.HttpClient)
+//	_, err := azCli.DeployToSubscription(ctx, "SUBSCRIPTION_ID", "dev", template, parameters, "eastus2")
+//
+// Expressions that can't be evaluated fail the deployment, except for outputs which get a placeholder of their type.
+type DeploymentSimulator struct {
+	SubscriptionId string
+	TenantId       string
+	Location       string
+	// Gets the time of deployments, defaults to time.Now
+	Now func() time.Time
+
+	mu          sync.Mutex
+	deployments map[string]*simulatedDeployment
+	resources   map[string]*SimulatedResource
+}
+
+// SimulatedResource is a resource deployed by the simulator
+type SimulatedResource struct {
+	Id            string
+	Name          string
+	Type          string
+	Location      string
+	Kind          string
+	ResourceGroup string
+	Tags          map[string]string
+	Properties    map[string]any
+	Sku           any
+
+	// Set once the properties of the resource are evaluated
+	deployed bool
+}
+
+type simulatedDeployment struct {
+	id            string
+	name          string
+	resourceGroup string
+	location      string
+	tags          map[string]string
+	parameters    map[string]any
+	outputs       map[string]any
+	resourceIds   []string
+	timestamp     time.Time
+}
+
+// The scope the expressions of a template are evaluated in
+type deploymentScope struct {
+	sim            *DeploymentSimulator
+	deploymentName string
+	resourceGroup  string
+	location       string
+	parameterDefs  map[string]any
+	parameterArgs  map[string]any
+	parameters     map[string]any
+	variableDefs   map[string]any
+	variables      map[string]any
+	symbols        map[string]*SimulatedResource
+	resources      []*SimulatedResource
+	copyIndex      int
+}
+
+// NewDeploymentSimulator creates a simulator deploying templates to the subscription, in the location by default
+func NewDeploymentSimulator(subscriptionId string, location string) *DeploymentSimulator {
+	return &DeploymentSimulator{
+		SubscriptionId: subscriptionId,
+		TenantId:       "TENANT_ID",
+		Location:       location,
+		Now:            time.Now,
+		deployments:    map[string]*simulatedDeployment{},
+		resources:      map[string]*SimulatedResource{},
+	}
+}
+
+func (sim *DeploymentSimulator) now() time.Time {
+	if sim.Now == nil {
+		return time.Now().UTC()
+	}
+
+	return sim.Now().UTC()
+}
+
+// Register serves the requests of the ARM deployments, resources and resource groups of the simulator from the mocked
+// HTTP client. Mocks registered afterwards take precedence over the simulator.
+func (sim *DeploymentSimulator) Register(c *mockhttp.MockHttpClient) {
+	c.When(func(request *http.Request) bool {
+		return sim.route(request) != nil
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		return sim.route(request)(request)
+	})
+}
+
+// Resources gets the resources deployed by the simulator, sorted by id
+func (sim *DeploymentSimulator) Resources() []*SimulatedResource {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+
+	resources := make([]*SimulatedResource, 0, len(sim.resources))
+	for _, resource := range sim.resources {
+		resources = append(resources, resource)
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Id < resources[j].Id })
+	return resources
+}
+
+// Deploy deploys the template to the resource group, or to the subscription when the resource group is empty, returning
+// the outputs of the deployment by name
+func (sim *DeploymentSimulator) Deploy(
+	name string,
+	resourceGroup string,
+	template map[string]any,
+	parameters map[string]any,
+) (map[string]any, error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+
+	deployment, err := sim.deploy(name, resourceGroup, sim.Location, nil, template, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]any{}
+	for key, output := range deployment.outputs {
+		outputs[key] = output.(map[string]any)["value"]
+	}
+
+	return outputs, nil
+}
+
+func (sim *DeploymentSimulator) deploy(
+	name string,
+	resourceGroup string,
+	location string,
+	tags map[string]string,
+	template map[string]any,
+	parameters map[string]any,
+) (*simulatedDeployment, error) {
+	scope := &deploymentScope{
+		sim:            sim,
+		deploymentName: name,
+		resourceGroup:  resourceGroup,
+		location:       location,
+		parameterDefs:  objectProperty(template, "parameters"),
+		parameterArgs:  parameters,
+		parameters:     map[string]any{},
+		variableDefs:   objectProperty(template, "variables"),
+		variables:      map[string]any{},
+		symbols:        map[string]*SimulatedResource{},
+	}
+
+	definitions, err := resourceDefinitions(template)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resources are registered before their properties are evaluated, so the resources can reference each other
+	pending := []pendingResource{}
+	for _, definition := range definitions {
+		registered, err := scope.register(definition)
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s: %w", name, err)
+		}
+		pending = append(pending, registered...)
+	}
+
+	// The properties of the resources are evaluated once the resources they reference are deployed
+	for len(pending) > 0 {
+		remaining := []pendingResource{}
+		var lastErr error
+		for _, resource := range pending {
+			if err := scope.deployResource(resource); errors.Is(err, errNotDeployed) {
+				remaining = append(remaining, resource)
+				lastErr = err
+			} else if err != nil {
+				return nil, fmt.Errorf("deployment %s, resource %s: %w", name, resource.resource.Name, err)
+			}
+		}
+
+		if len(remaining) == len(pending) {
+			return nil, fmt.Errorf("deployment %s: %w", name, lastErr)
+		}
+		pending = remaining
+	}
+
+	outputs := map[string]any{}
+	for key, definition := range objectProperty(template, "outputs") {
+		output, _ := definition.(map[string]any)
+		if condition, has := output["condition"]; has {
+			if value, err := scope.evaluate(condition); err == nil && value != true {
+				continue
+			}
+		}
+
+		outputType := strings.ToLower(toString(output["type"]))
+		value, err := scope.evaluate(output["value"])
+		if err != nil {
+			log.Printf("simulating output %s of deployment %s: %v", key, name, err)
+			value = placeholderValue(outputType, key)
+		}
+
+		armType := outputTypes[outputType]
+		if armType == "" {
+			armType = toString(output["type"])
+		}
+		outputs[key] = map[string]any{"type": armType, "value": value}
+	}
+
+	deployment := &simulatedDeployment{
+		id:            deploymentId(sim.SubscriptionId, resourceGroup, name),
+		name:          name,
+		resourceGroup: resourceGroup,
+		location:      location,
+		tags:          tags,
+		parameters:    parameters,
+		outputs:       outputs,
+		timestamp:     sim.now(),
+	}
+	for _, resource := range scope.resources {
+		deployment.resourceIds = append(deployment.resourceIds, resource.Id)
+	}
+
+	sim.deployments[strings.ToLower(deployment.id)] = deployment
+	return deployment, nil
+}
+
+// A resource definition of a template, with its symbolic name for templates of language version 2.0
+type resourceDefinition struct {
+	symbol     string
+	definition map[string]any
+}
+
+// A registered resource whose properties aren't evaluated yet
+type pendingResource struct {
+	resource   *SimulatedResource
+	definition map[string]any
+	copyIndex  int
+}
+
+func resourceDefinitions(template map[string]any) ([]resourceDefinition, error) {
+	definitions := []resourceDefinition{}
+	switch resources := template["resources"].(type) {
+	case nil:
+	case []any:
+		for _, resource := range resources {
+			definition, ok := resource.(map[string]any)
+			if !ok {
+				return nil, errors.New("resources must be objects")
+			}
+			definitions = append(definitions, resourceDefinition{definition: definition})
+		}
+	case map[string]any:
+		symbols := make([]string, 0, len(resources))
+		for symbol := range resources {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		for _, symbol := range symbols {
+			definition, ok := resources[symbol].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("resource %s must be an object", symbol)
+			}
+			definitions = append(definitions, resourceDefinition{symbol: symbol, definition: definition})
+		}
+	default:
+		return nil, errors.New("resources must be an array or an object")
+	}
+
+	return definitions, nil
+}
+
+// Registers the instances of the resource definition, evaluating their names, locations and tags
+func (s *deploymentScope) register(definition resourceDefinition) ([]pendingResource, error) {
+	count := 1
+	if loop, has := definition.definition["copy"].(map[string]any); has {
+		value, err := s.evaluate(loop["count"])
+		if err != nil {
+			return nil, fmt.Errorf("evaluating copy count: %w", err)
+		}
+		count, _ = toInt(value)
+	}
+
+	registered := []pendingResource{}
+	for i := 0; i < count; i++ {
+		s.copyIndex = i
+		if condition, has := definition.definition["condition"]; has {
+			value, err := s.evaluate(condition)
+			if err != nil {
+				return nil, fmt.Errorf("evaluating condition: %w", err)
+			}
+			if value != true {
+				continue
+			}
+		}
+
+		resource, err := s.newResource(definition.definition)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing, has := s.sim.resources[strings.ToLower(resource.Id)]; has {
+			// Existing resources and resources deployed again keep their computed properties
+			existing.Tags, existing.Kind, existing.deployed = resource.Tags, resource.Kind, false
+			resource = existing
+		} else {
+			s.sim.resources[strings.ToLower(resource.Id)] = resource
+		}
+
+		if definition.symbol != "" && i == 0 {
+			s.symbols[definition.symbol] = resource
+		}
+
+		if definition.definition["existing"] == true {
+			resource.deployed = true
+			continue
+		}
+
+		s.resources = append(s.resources, resource)
+		registered = append(registered, pendingResource{resource: resource, definition: definition.definition, copyIndex: i})
+	}
+
+	return registered, nil
+}
+
+func (s *deploymentScope) newResource(definition map[string]any) (*SimulatedResource, error) {
+	resourceType := toString(definition["type"])
+	if resourceType == "" {
+		return nil, errors.New("resource has no type")
+	}
+
+	name, err := s.evaluate(definition["name"])
+	if err != nil {
+		return nil, fmt.Errorf("evaluating name of %s: %w", resourceType, err)
+	}
+
+	resource := &SimulatedResource{
+		Name:          toString(name),
+		Type:          resourceType,
+		Location:      s.location,
+		ResourceGroup: s.resourceGroup,
+		Tags:          map[string]string{},
+	}
+
+	for property, target := range map[string]*string{"location": &resource.Location, "kind": &resource.Kind} {
+		if value, has := definition[property]; has {
+			evaluated, err := s.evaluate(value)
+			if err != nil {
+				return nil, fmt.Errorf("evaluating %s of %s: %w", property, resource.Name, err)
+			}
+			*target = toString(evaluated)
+		}
+	}
+
+	if tags, has := definition["tags"]; has {
+		evaluated, err := s.evaluate(tags)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating tags of %s: %w", resource.Name, err)
+		}
+
+		for key, value := range asObject(evaluated) {
+			resource.Tags[key] = toString(value)
+		}
+	}
+
+	if resourceGroup, has := definition["resourceGroup"]; has && resourceType == deploymentType {
+		evaluated, err := s.evaluate(resourceGroup)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating resource group of %s: %w", resource.Name, err)
+		}
+		resource.ResourceGroup = toString(evaluated)
+	}
+
+	if resourceType == resourceGroupType {
+		resource.ResourceGroup = resource.Name
+		resource.Id = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", s.sim.SubscriptionId, resource.Name)
+	} else {
+		resource.Id = s.resourceId(s.sim.SubscriptionId, resource.ResourceGroup, resourceType, strings.Split(resource.Name, "/"))
+	}
+
+	resource.Properties = computedProperties(resource)
+	return resource, nil
+}
+
+// Evaluates the properties of the resource, deploying the template of nested deployments
+func (s *deploymentScope) deployResource(pending pendingResource) error {
+	s.copyIndex = pending.copyIndex
+	resource := pending.resource
+
+	if sku, has := pending.definition["sku"]; has {
+		evaluated, err := s.evaluate(sku)
+		if err != nil {
+			return err
+		}
+		resource.Sku = evaluated
+	}
+
+	properties := asObject(pending.definition["properties"])
+	if resource.Type != deploymentType {
+		evaluated, err := s.evaluate(properties)
+		if err != nil {
+			return err
+		}
+
+		// The declared properties are merged with the properties computed by Azure
+		mergeObject(resource.Properties, asObject(evaluated))
+		resource.deployed = true
+		return nil
+	}
+
+	// The parameters of nested deployments are evaluated in the scope of the parent deployment
+	parameters, err := s.evaluate(properties["parameters"])
+	if err != nil {
+		return err
+	}
+
+	template := asObject(properties["template"])
+	if template == nil {
+		return errors.New("nested deployments must have a template")
+	}
+
+	deployment, err := s.sim.deploy(
+		resource.Name,
+		resource.ResourceGroup,
+		resource.Location,
+		resource.Tags,
+		template,
+		asObject(parameters),
+	)
+	if err != nil {
+		return err
+	}
+
+	resource.Properties["outputs"] = deployment.outputs
+	resource.deployed = true
+	return nil
+}
+
+func (s *deploymentScope) parameter(name string) (any, error) {
+	for key, value := range s.parameters {
+		if strings.EqualFold(key, name) {
+			return value, nil
+		}
+	}
+
+	for key, arg := range s.parameterArgs {
+		if strings.EqualFold(key, name) {
+			value := asObject(arg)["value"]
+			s.parameters[key] = value
+			return value, nil
+		}
+	}
+
+	for key, definition := range s.parameterDefs {
+		if !strings.EqualFold(key, name) {
+			continue
+		}
+
+		defaultValue, has := asObject(definition)["defaultValue"]
+		if !has {
+			return nil, fmt.Errorf("missing value of parameter '%s'", name)
+		}
+
+		value, err := s.evaluate(defaultValue)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating default value of parameter '%s': %w", name, err)
+		}
+
+		s.parameters[key] = value
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("unknown parameter '%s'", name)
+}
+
+func (s *deploymentScope) variable(name string) (any, error) {
+	if value, has := s.variables[name]; has {
+		return value, nil
+	}
+
+	for key, definition := range s.variableDefs {
+		if strings.EqualFold(key, name) {
+			value, err := s.evaluate(definition)
+			if err != nil {
+				return nil, fmt.Errorf("evaluating variable '%s': %w", name, err)
+			}
+
+			s.variables[name] = value
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown variable '%s'", name)
+}
+
+// Gets the properties of the resource with the symbolic name or id, or the resource itself when full
+func (s *deploymentScope) reference(target string, full bool) (any, error) {
+	resource, has := s.symbols[target]
+	if !has {
+		resource, has = s.sim.resources[strings.ToLower(target)]
+	}
+
+	if !has {
+		// The name of a resource of the deployment, ex) reference('resources') for a nested deployment
+		for _, candidate := range s.resources {
+			if strings.EqualFold(candidate.Name, target) {
+				resource, has = candidate, true
+				break
+			}
+		}
+	}
+
+	if !has {
+		return nil, fmt.Errorf("reference to unknown resource '%s'", target)
+	}
+
+	if !resource.deployed {
+		return nil, fmt.Errorf("%w: %s", errNotDeployed, resource.Name)
+	}
+
+	if !full {
+		return resource.Properties, nil
+	}
+
+	return map[string]any{
+		"id":         resource.Id,
+		"name":       resource.Name,
+		"type":       resource.Type,
+		"location":   resource.Location,
+		"kind":       resource.Kind,
+		"tags":       stringMap(resource.Tags),
+		"properties": resource.Properties,
+	}, nil
+}
+
+// Evaluates resourceId and subscriptionResourceId, whose arguments are the optional subscription and resource group,
+// the type of the resource and the names of the resource
+func (s *deploymentScope) resourceIdFunction(args []any, subscriptionLevel bool) (any, error) {
+	typeIndex := -1
+	for i, arg := range args {
+		if strings.Contains(toString(arg), "/") {
+			typeIndex = i
+			break
+		}
+	}
+
+	if typeIndex < 0 {
+		return nil, errors.New("resourceId requires the type of the resource")
+	}
+
+	subscriptionId, resourceGroup := s.sim.SubscriptionId, s.resourceGroup
+	if subscriptionLevel {
+		resourceGroup = ""
+	}
+
+	switch typeIndex {
+	case 1:
+		if subscriptionLevel {
+			subscriptionId = toString(args[0])
+		} else {
+			resourceGroup = toString(args[0])
+		}
+	case 2:
+		subscriptionId, resourceGroup = toString(args[0]), toString(args[1])
+	}
+
+	names := []string{}
+	for _, arg := range args[typeIndex+1:] {
+		names = append(names, toString(arg))
+	}
+
+	return s.resourceId(subscriptionId, resourceGroup, toString(args[typeIndex]), names), nil
+}
+
+// Formats the id of a resource, ex) /subscriptions/{sub}/resourceGroups/{rg}/providers/Microsoft.Web/sites/{name}
+func (s *deploymentScope) resourceId(
+	subscriptionId string,
+	resourceGroup string,
+	resourceType string,
+	names []string,
+) string {
+	if strings.EqualFold(resourceType, resourceGroupType) && len(names) == 1 {
+		return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionId, names[0])
+	}
+
+	id := "/subscriptions/" + subscriptionId
+	if resourceGroup != "" {
+		id += "/resourceGroups/" + resourceGroup
+	}
+
+	// Types of child resources have a segment for each name, ex) Microsoft.Sql/servers/databases
+	segments := strings.Split(resourceType, "/")
+	id += "/providers/" + segments[0]
+	for i, segment := range segments[1:] {
+		id += "/" + segment
+		if i < len(names) {
+			id += "/" + names[i]
+		}
+	}
+
+	return id
+}
+
+// Gets the properties Azure computes for resources of common types, ex) the login server of container registries
+func computedProperties(resource *SimulatedResource) map[string]any {
+	name := strings.ToLower(resource.Name[strings.LastIndex(resource.Name, "/")+1:])
+	properties := map[string]any{"provisioningState": "Succeeded"}
+
+	switch strings.ToLower(resource.Type) {
+	case "microsoft.containerregistry/registries":
+		properties["loginServer"] = name + ".azurecr.io"
+	case "microsoft.app/containerapps":
+		properties["latestRevisionName"] = name + "--0000001"
+		properties["configuration"] = map[string]any{
+			"ingress": map[string]any{"fqdn": fmt.Sprintf("%s.simulated.%s.azurecontainerapps.io", name, resource.Location)},
+		}
+	case "microsoft.app/managedenvironments":
+		properties["defaultDomain"] = fmt.Sprintf("simulated.%s.azurecontainerapps.io", resource.Location)
+	case "microsoft.web/sites":
+		properties["defaultHostName"] = name + ".azurewebsites.net"
+	case "microsoft.web/staticsites":
+		properties["defaultHostname"] = name + ".azurestaticapps.net"
+	case "microsoft.containerservice/managedclusters":
+		properties["fqdn"] = fmt.Sprintf("%s.hcp.%s.azmk8s.io", name, resource.Location)
+	case "microsoft.keyvault/vaults":
+		properties["vaultUri"] = fmt.Sprintf("https://%s.vault.azure.net/", name)
+	case "microsoft.storage/storageaccounts":
+		properties["primaryEndpoints"] = map[string]any{
+			"blob":  fmt.Sprintf("https://%s.blob.core.windows.net/", name),
+			"queue": fmt.Sprintf("https://%s.queue.core.windows.net/", name),
+			"table": fmt.Sprintf("https://%s.table.core.windows.net/", name),
+		}
+	case "microsoft.insights/components":
+		properties["ConnectionString"] = "InstrumentationKey=00000000-0000-0000-0000-000000000000"
+		properties["InstrumentationKey"] = "00000000-0000-0000-0000-000000000000"
+	case "microsoft.managedidentity/userassignedidentities":
+		properties["clientId"] = deterministicGuid([]any{resource.Name, "clientId"})
+		properties["principalId"] = deterministicGuid([]any{resource.Name, "principalId"})
+	}
+
+	return properties
+}
+
+// Gets a placeholder of the type for outputs that can't be evaluated
+func placeholderValue(outputType string, name string) any {
+	switch outputType {
+	case "int":
+		return int64(0)
+	case "bool":
+		return false
+	case "object", "secureobject":
+		return map[string]any{}
+	case "array":
+		return []any{}
+	}
+
+	return "simulated-" + strings.ToLower(name)
+}
+
+// Gets the handler of the request, or nil when the request isn't served by the simulator
+func (sim *DeploymentSimulator) route(request *http.Request) func(*http.Request) (*http.Response, error) {
+	path := strings.TrimSuffix(request.URL.Path, "/")
+	switch request.Method {
+	case http.MethodPut:
+		if matches := deploymentPathRegex.FindStringSubmatch(path); matches != nil {
+			return sim.createDeployment
+		}
+	case http.MethodGet:
+		if deploymentPathRegex.MatchString(path) {
+			return sim.getDeployment
+		}
+		if deploymentListPathRegex.MatchString(request.URL.Path) {
+			return sim.listDeployments
+		}
+		if deploymentOperationsPathRegex.MatchString(path) {
+			return sim.listDeploymentOperations
+		}
+		if resourceListPathRegex.MatchString(path) {
+			return sim.listResources
+		}
+		if resourceGroupListPathRegex.MatchString(path) {
+			return sim.listResourceGroups
+		}
+		if matches := resourceTypeListPathRegex.FindStringSubmatch(path); matches != nil &&
+			strings.EqualFold(matches[1], sim.SubscriptionId) {
+			return sim.listResourcesOfType
+		}
+		if parent, _, has := cutLast(path, "/"); has && sim.resource(path) == nil && sim.resource(parent) != nil {
+			return sim.listChildResources
+		}
+	case http.MethodPost:
+		if matches := resourceActionPathRegex.FindStringSubmatch(path); matches != nil && sim.resource(matches[1]) != nil {
+			return sim.resourceAction
+		}
+	}
+
+	if sim.resource(path) == nil {
+		return nil
+	}
+
+	switch request.Method {
+	case http.MethodGet:
+		return sim.getResource
+	case http.MethodPut, http.MethodPatch:
+		return sim.updateResource
+	case http.MethodDelete:
+		return sim.deleteResource
+	}
+
+	return nil
+}
+
+func (sim *DeploymentSimulator) resource(id string) *SimulatedResource {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+
+	return sim.resources[strings.ToLower(strings.TrimSuffix(id, "/"))]
+}
+
+func (sim *DeploymentSimulator) createDeployment(request *http.Request) (*http.Response, error) {
+	matches := deploymentPathRegex.FindStringSubmatch(strings.TrimSuffix(request.URL.Path, "/"))
+	_, resourceGroup, _ := strings.Cut(strings.ToLower(matches[1]), "/resourcegroups/")
+	if resourceGroup != "" {
+		// Keeps the casing of the resource group of the request
+		resourceGroup = matches[1][len(matches[1])-len(resourceGroup):]
+	}
+
+	var body struct {
+		Location   string            `json:"location"`
+		Tags       map[string]string `json:"tags"`
+		Properties struct {
+			Template   map[string]any `json:"template"`
+			Parameters map[string]any `json:"parameters"`
+		} `json:"properties"`
+	}
+
+	content, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &body); err != nil {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusBadRequest, armError("InvalidRequestContent", err))
+	}
+
+	location := body.Location
+	if location == "" {
+		location = sim.Location
+	}
+
+	sim.mu.Lock()
+	deployment, err := sim.deploy(
+		matches[2],
+		resourceGroup,
+		location,
+		body.Tags,
+		body.Properties.Template,
+		body.Properties.Parameters,
+	)
+	sim.mu.Unlock()
+
+	if err != nil {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusBadRequest, armError("InvalidTemplate", err))
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, deployment.toJson())
+}
+
+func (sim *DeploymentSimulator) getDeployment(request *http.Request) (*http.Response, error) {
+	sim.mu.Lock()
+	deployment, has := sim.deployments[strings.ToLower(strings.TrimSuffix(request.URL.Path, "/"))]
+	sim.mu.Unlock()
+
+	if !has {
+		return mocks.CreateHttpResponseWithBody(
+			request,
+			http.StatusNotFound,
+			armError("DeploymentNotFound", errors.New("deployment not found")),
+		)
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, deployment.toJson())
+}
+
+func (sim *DeploymentSimulator) listDeployments(request *http.Request) (*http.Response, error) {
+	scope := strings.ToLower(deploymentListPathRegex.FindStringSubmatch(request.URL.Path)[1])
+
+	sim.mu.Lock()
+	deployments := []any{}
+	for id, deployment := range sim.deployments {
+		if strings.HasPrefix(id, scope+"/providers/") {
+			deployments = append(deployments, deployment.toJson())
+		}
+	}
+	sim.mu.Unlock()
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": deployments})
+}
+
+func (sim *DeploymentSimulator) listDeploymentOperations(request *http.Request) (*http.Response, error) {
+	deploymentId := deploymentOperationsPathRegex.FindStringSubmatch(strings.TrimSuffix(request.URL.Path, "/"))[1]
+
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+
+	deployment, has := sim.deployments[strings.ToLower(deploymentId)]
+	if !has {
+		return mocks.CreateHttpResponseWithBody(
+			request,
+			http.StatusNotFound,
+			armError("DeploymentNotFound", errors.New("deployment not found")),
+		)
+	}
+
+	operations := []any{}
+	for i, id := range deployment.resourceIds {
+		resource := sim.resources[strings.ToLower(id)]
+		if resource == nil {
+			continue
+		}
+
+		operationId := fmt.Sprintf("%016X", i+1)
+		operations = append(operations, map[string]any{
+			"id":          fmt.Sprintf("%s/operations/%s", deployment.id, operationId),
+			"operationId": operationId,
+			"properties": map[string]any{
+				"provisioningOperation": "Create",
+				"provisioningState":     "Succeeded",
+				"timestamp":             deployment.timestamp.Format(time.RFC3339),
+				"targetResource": map[string]any{
+					"id":           resource.Id,
+					"resourceName": resource.Name,
+					"resourceType": resource.Type,
+				},
+			},
+		})
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": operations})
+}
+
+func (sim *DeploymentSimulator) listResources(request *http.Request) (*http.Response, error) {
+	resourceGroup := resourceListPathRegex.FindStringSubmatch(strings.TrimSuffix(request.URL.Path, "/"))[1]
+
+	resources := []*SimulatedResource{}
+	for _, resource := range sim.Resources() {
+		if strings.EqualFold(resource.ResourceGroup, resourceGroup) && resource.Type != resourceGroupType &&
+			resource.Type != deploymentType {
+			resources = append(resources, resource)
+		}
+	}
+
+	return sim.respondWithList(request, resources)
+}
+
+func (sim *DeploymentSimulator) listResourceGroups(request *http.Request) (*http.Response, error) {
+	resourceGroups := []*SimulatedResource{}
+	for _, resource := range sim.Resources() {
+		if resource.Type == resourceGroupType {
+			resourceGroups = append(resourceGroups, resource)
+		}
+	}
+
+	return sim.respondWithList(request, resourceGroups)
+}
+
+// Responds with the resources of a type in the subscription with their properties, like the list operations of the
+// resource providers, ex) the container registries of the subscription
+func (sim *DeploymentSimulator) listResourcesOfType(request *http.Request) (*http.Response, error) {
+	resourceType := resourceTypeListPathRegex.FindStringSubmatch(strings.TrimSuffix(request.URL.Path, "/"))[2]
+
+	values := []any{}
+	for _, resource := range sim.Resources() {
+		if strings.EqualFold(resource.Type, resourceType) {
+			values = append(values, resource.toJson())
+		}
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": values})
+}
+
+// Responds with the child resources of a type of a resource, ex) the replications of a container registry
+func (sim *DeploymentSimulator) listChildResources(request *http.Request) (*http.Response, error) {
+	parentId, childType, _ := cutLast(strings.TrimSuffix(request.URL.Path, "/"), "/")
+	parent := sim.resource(parentId)
+
+	values := []any{}
+	for _, resource := range sim.Resources() {
+		if strings.EqualFold(resource.Type, parent.Type+"/"+childType) &&
+			strings.HasPrefix(strings.ToLower(resource.Id), strings.ToLower(parentId)+"/") {
+			values = append(values, resource.toJson())
+		}
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": values})
+}
+
+// Responds with the resources matching the tag or name filter of the request, if any
+func (sim *DeploymentSimulator) respondWithList(
+	request *http.Request,
+	resources []*SimulatedResource,
+) (*http.Response, error) {
+	tagName, tagValue, name := "", "", ""
+	if filter := request.URL.Query().Get("$filter"); filter != "" {
+		if matches := tagFilterExpression.FindStringSubmatch(filter); len(matches) >= 3 {
+			tagName, tagValue = matches[1], matches[2]
+		}
+		if matches := nameFilterExpression.FindStringSubmatch(filter); len(matches) >= 2 {
+			name = matches[1]
+		}
+	}
+
+	values := []any{}
+	for _, resource := range resources {
+		if tagName != "" && resource.Tags[tagName] != tagValue {
+			continue
+		}
+		if name != "" && resource.Name != name {
+			continue
+		}
+
+		values = append(values, map[string]any{
+			"id":       resource.Id,
+			"name":     resource.Name,
+			"type":     resource.Type,
+			"location": resource.Location,
+			"kind":     resource.Kind,
+			"tags":     stringMap(resource.Tags),
+		})
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{"value": values})
+}
+
+func (sim *DeploymentSimulator) getResource(request *http.Request) (*http.Response, error) {
+	resource := sim.resource(request.URL.Path)
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, resource.toJson())
+}
+
+// Updates the resource with the properties and tags of the request, ex) the new image of a container app
+func (sim *DeploymentSimulator) updateResource(request *http.Request) (*http.Response, error) {
+	var body struct {
+		Tags       map[string]string `json:"tags"`
+		Properties map[string]any    `json:"properties"`
+	}
+
+	content, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &body); err != nil {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusBadRequest, armError("InvalidRequestContent", err))
+	}
+
+	resource := sim.resource(request.URL.Path)
+	sim.mu.Lock()
+	for key, value := range body.Tags {
+		resource.Tags[key] = value
+	}
+	mergeObject(resource.Properties, body.Properties)
+	sim.mu.Unlock()
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, resource.toJson())
+}
+
+// Deletes the resource, and the resources of resource groups
+func (sim *DeploymentSimulator) deleteResource(request *http.Request) (*http.Response, error) {
+	resource := sim.resource(request.URL.Path)
+
+	sim.mu.Lock()
+	for id, candidate := range sim.resources {
+		if candidate == resource || (resource.Type == resourceGroupType &&
+			strings.EqualFold(candidate.ResourceGroup, resource.Name)) {
+			delete(sim.resources, id)
+		}
+	}
+	sim.mu.Unlock()
+
+	return mocks.CreateEmptyHttpResponse(request, http.StatusOK)
+}
+
+// Responds to the list credentials and keys actions of resources
+func (sim *DeploymentSimulator) resourceAction(request *http.Request) (*http.Response, error) {
+	matches := resourceActionPathRegex.FindStringSubmatch(strings.TrimSuffix(request.URL.Path, "/"))
+	resource := sim.resource(matches[1])
+
+	if strings.HasPrefix(strings.ToLower(matches[2]), "listcluster") {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"kubeconfigs": []any{
+				map[string]any{"name": "clusterAdmin", "value": []byte(resource.kubeConfig())},
+			},
+		})
+	}
+
+	if strings.EqualFold(matches[2], "listCredentials") {
+		return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+			"username": resource.Name,
+			"passwords": []any{
+				map[string]any{"name": "password", "value": "simulated-password"},
+				map[string]any{"name": "password2", "value": "simulated-password2"},
+			},
+		})
+	}
+
+	return mocks.CreateHttpResponseWithBody(request, http.StatusOK, map[string]any{
+		"keys": []any{map[string]any{"keyName": "key1", "value": "simulated-key", "permissions": "FULL"}},
+	})
+}
+
+func (d *simulatedDeployment) toJson() map[string]any {
+	outputResources := []any{}
+	for _, id := range d.resourceIds {
+		outputResources = append(outputResources, map[string]any{"id": id})
+	}
+
+	return map[string]any{
+		"id":       d.id,
+		"name":     d.name,
+		"type":     deploymentType,
+		"location": d.location,
+		"tags":     stringMap(d.tags),
+		"properties": map[string]any{
+			"provisioningState": "Succeeded",
+			"mode":              "Incremental",
+			"timestamp":         d.timestamp.Format(time.RFC3339),
+			"duration":          "PT1S",
+			"correlationId":     deterministicGuid([]any{d.id}),
+			"parameters":        d.parameters,
+			"outputs":           d.outputs,
+			"outputResources":   outputResources,
+		},
+	}
+}
+
+func (r *SimulatedResource) toJson() map[string]any {
+	resource := map[string]any{
+		"id":         r.Id,
+		"name":       r.Name[strings.LastIndex(r.Name, "/")+1:],
+		"type":       r.Type,
+		"location":   r.Location,
+		"kind":       r.Kind,
+		"tags":       stringMap(r.Tags),
+		"properties": r.Properties,
+	}
+
+	if r.Sku != nil {
+		resource["sku"] = r.Sku
+	}
+
+	return resource
+}
+
+// Gets a kube config connecting to the cluster with a token, like the credentials of AKS clusters
+func (r *SimulatedResource) kubeConfig() string {
+	name := r.Name[strings.LastIndex(r.Name, "/")+1:]
+	user := fmt.Sprintf("clusterAdmin_%s_%s", r.ResourceGroup, name)
+	fqdn, _ := r.Properties["fqdn"].(string)
+
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[3]s:443
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[2]s
+current-context: %[1]s
+users:
+- name: %[2]s
+  user:
+    token: simulated-token
+`, name, user, fqdn)
+}
+
+func deploymentId(subscriptionId string, resourceGroup string, name string) string {
+	if resourceGroup == "" {
+		return fmt.Sprintf("/subscriptions/%s/providers/%s/%s", subscriptionId, deploymentType, name)
+	}
+
+	return fmt.Sprintf(
+		"/subscriptions/%s/resourceGroups/%s/providers/%s/%s",
+		subscriptionId,
+		resourceGroup,
+		deploymentType,
+		name,
+	)
+}
+
+// Cuts the string around the last instance of the separator
+func cutLast(value string, separator string) (string, string, bool) {
+	if index := strings.LastIndex(value, separator); index >= 0 {
+		return value[:index], value[index+len(separator):], true
+	}
+
+	return value, "", false
+}
+
+func armError(code string, err error) map[string]any {
+	return map[string]any{"error": map[string]any{"code": code, "message": err.Error()}}
+}
+
+// Merges the properties into the object, recursively for nested objects
+func mergeObject(object map[string]any, properties map[string]any) {
+	for key, value := range properties {
+		if nested, ok := value.(map[string]any); ok {
+			if existing, ok := object[key].(map[string]any); ok {
+				mergeObject(existing, nested)
+				continue
+			}
+		}
+
+		object[key] = value
+	}
+}
+
+func objectProperty(object map[string]any, property string) map[string]any {
+	return asObject(object[property])
+}
+
+func asObject(value any) map[string]any {
+	object, _ := value.(map[string]any)
+	return object
+}
+
+func stringMap(values map[string]string) map[string]any {
+	object := map[string]any{}
+	for key, value := range values {
+		object[key] = value
+	}
+
+	return object
+}
@@ -0,0 +1,709 @@
+package mockarmresources
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A parsed template expression, ex) concat('rg-', parameters('environmentName'))
+type expression interface{}
+
+type literalExpression struct {
+	value any
+}
+
+type callExpression struct {
+	name string
+	args []expression
+}
+
+// A property or index accessor applied to the value of an expression, ex) reference(...).outputs or split(...)[0]
+type accessorExpression struct {
+	target   expression
+	property string
+	index    expression
+}
+
+// Parses the template expressions of ARM templates, the strings enclosed in brackets
+type expressionParser struct {
+	input string
+	pos   int
+}
+
+func parseExpression(input string) (expression, error) {
+	p := &expressionParser{input: input}
+	expr, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected '%s' at %d in expression '%s'", p.input[p.pos:], p.pos, input)
+	}
+
+	return expr, nil
+}
+
+func (p *expressionParser) parseExpression() (expression, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression '%s'", p.input)
+	}
+
+	var expr expression
+	var err error
+	switch c := p.input[p.pos]; {
+	case c == '\'':
+		expr, err = p.parseString()
+	case c == '-' || unicode.IsDigit(rune(c)):
+		expr, err = p.parseNumber()
+	case unicode.IsLetter(rune(c)):
+		expr, err = p.parseCall()
+	default:
+		return nil, fmt.Errorf("unexpected '%c' at %d in expression '%s'", c, p.pos, p.input)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseAccessors(expr)
+}
+
+func (p *expressionParser) parseString() (expression, error) {
+	builder := strings.Builder{}
+	// Skips the opening quote, quotes are escaped by doubling them
+	for p.pos++; p.pos < len(p.input); p.pos++ {
+		if p.input[p.pos] != '\'' {
+			builder.WriteByte(p.input[p.pos])
+			continue
+		}
+
+		if p.pos+1 < len(p.input) && p.input[p.pos+1] == '\'' {
+			builder.WriteByte('\'')
+			p.pos++
+			continue
+		}
+
+		p.pos++
+		return &literalExpression{value: builder.String()}, nil
+	}
+
+	return nil, fmt.Errorf("unterminated string in expression '%s'", p.input)
+}
+
+func (p *expressionParser) parseNumber() (expression, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.input) && unicode.IsDigit(rune(p.input[p.pos])); p.pos++ {
+	}
+
+	value, err := strconv.ParseInt(p.input[start:p.pos], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number in expression '%s': %w", p.input, err)
+	}
+
+	return &literalExpression{value: value}, nil
+}
+
+func (p *expressionParser) parseIdentifier() string {
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := rune(p.input[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '$' {
+			break
+		}
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+func (p *expressionParser) parseCall() (expression, error) {
+	name := p.parseIdentifier()
+	p.skipSpaces()
+	if !p.consume('(') {
+		return nil, fmt.Errorf("expected '(' after '%s' in expression '%s'", name, p.input)
+	}
+
+	call := &callExpression{name: strings.ToLower(name)}
+	p.skipSpaces()
+	if p.consume(')') {
+		return call, nil
+	}
+
+	for {
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+
+		p.skipSpaces()
+		if p.consume(')') {
+			return call, nil
+		}
+
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected ',' or ')' at %d in expression '%s'", p.pos, p.input)
+		}
+	}
+}
+
+func (p *expressionParser) parseAccessors(expr expression) (expression, error) {
+	for {
+		p.skipSpaces()
+		switch {
+		case p.consume('.'):
+			p.skipSpaces()
+			property := p.parseIdentifier()
+			if property == "" {
+				return nil, fmt.Errorf("expected property at %d in expression '%s'", p.pos, p.input)
+			}
+			expr = &accessorExpression{target: expr, property: property}
+		case p.consume('['):
+			index, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			p.skipSpaces()
+			if !p.consume(']') {
+				return nil, fmt.Errorf("expected ']' at %d in expression '%s'", p.pos, p.input)
+			}
+			expr = &accessorExpression{target: expr, index: index}
+		default:
+			return expr, nil
+		}
+	}
+}
+
+func (p *expressionParser) consume(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// Evaluates the parsed expression in the scope of a deployment
+func (s *deploymentScope) eval(expr expression) (any, error) {
+	switch expr := expr.(type) {
+	case *literalExpression:
+		return expr.value, nil
+	case *accessorExpression:
+		target, err := s.eval(expr.target)
+		if err != nil {
+			return nil, err
+		}
+
+		if expr.index == nil {
+			return accessProperty(target, expr.property)
+		}
+
+		index, err := s.eval(expr.index)
+		if err != nil {
+			return nil, err
+		}
+
+		if property, ok := index.(string); ok {
+			return accessProperty(target, property)
+		}
+
+		items, ok := target.([]any)
+		i, isInt := toInt(index)
+		if !ok || !isInt || i < 0 || i >= len(items) {
+			return nil, fmt.Errorf("index %v is out of range", index)
+		}
+
+		return items[i], nil
+	case *callExpression:
+		// The branches of if are evaluated lazily, the other branch may not be valid, ex) when a resource isn't deployed
+		if expr.name == "if" && len(expr.args) == 3 {
+			condition, err := s.eval(expr.args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if condition == true {
+				return s.eval(expr.args[1])
+			}
+
+			return s.eval(expr.args[2])
+		}
+
+		args := make([]any, len(expr.args))
+		for i, arg := range expr.args {
+			value, err := s.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+
+		return s.call(expr.name, args)
+	}
+
+	return nil, fmt.Errorf("unexpected expression %T", expr)
+}
+
+// Calls the template function with the evaluated arguments
+func (s *deploymentScope) call(name string, args []any) (any, error) {
+	switch name {
+	case "parameters":
+		return s.parameter(stringArg(args, 0))
+	case "variables":
+		return s.variable(stringArg(args, 0))
+	case "reference":
+		full := len(args) > 2 && strings.EqualFold(stringArg(args, 2), "full")
+		return s.reference(stringArg(args, 0), full)
+	case "resourceid":
+		return s.resourceIdFunction(args, false)
+	case "subscriptionresourceid":
+		return s.resourceIdFunction(args, true)
+	case "extensionresourceid":
+		if len(args) < 3 {
+			return nil, errors.New("extensionResourceId requires the parent id, the type and the names of the resource")
+		}
+
+		id := strings.TrimSuffix(toString(args[0]), "/")
+		segments := strings.Split(toString(args[1]), "/")
+		id += "/providers/" + segments[0]
+		for i, segment := range segments[1:] {
+			id += "/" + segment
+			if i+2 < len(args) {
+				id += "/" + toString(args[i+2])
+			}
+		}
+		return id, nil
+	case "resourcegroup":
+		return map[string]any{
+			"id":       fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", s.sim.SubscriptionId, s.resourceGroup),
+			"name":     s.resourceGroup,
+			"type":     "Microsoft.Resources/resourceGroups",
+			"location": s.location,
+			"tags":     map[string]any{},
+		}, nil
+	case "subscription":
+		return map[string]any{
+			"id":             "/subscriptions/" + s.sim.SubscriptionId,
+			"subscriptionId": s.sim.SubscriptionId,
+			"tenantId":       s.sim.TenantId,
+			"displayName":    "Simulated subscription",
+		}, nil
+	case "tenant":
+		return map[string]any{"tenantId": s.sim.TenantId}, nil
+	case "deployment":
+		return map[string]any{"name": s.deploymentName, "properties": map[string]any{}}, nil
+	case "environment":
+		return map[string]any{
+			"name":            "AzureCloud",
+			"resourceManager": "https://management.azure.com/",
+			"suffixes": map[string]any{
+				"storage":           "core.windows.net",
+				"keyvaultDns":       ".vault.azure.net",
+				"acrLoginServer":    ".azurecr.io",
+				"sqlServerHostname": ".database.windows.net",
+			},
+		}, nil
+	case "copyindex":
+		offset := 0
+		if len(args) > 0 {
+			offset, _ = toInt(args[len(args)-1])
+		}
+		return int64(s.copyIndex + offset), nil
+	case "listkeys":
+		return map[string]any{"keys": []any{map[string]any{"keyName": "key1", "value": "simulated-key"}}}, nil
+	case "concat":
+		if len(args) > 0 {
+			if _, ok := args[0].([]any); ok {
+				items := []any{}
+				for _, arg := range args {
+					array, _ := arg.([]any)
+					items = append(items, array...)
+				}
+				return items, nil
+			}
+		}
+
+		builder := strings.Builder{}
+		for _, arg := range args {
+			builder.WriteString(toString(arg))
+		}
+		return builder.String(), nil
+	case "format":
+		return formatString(stringArg(args, 0), args[1:]), nil
+	case "tolower":
+		return strings.ToLower(stringArg(args, 0)), nil
+	case "toupper":
+		return strings.ToUpper(stringArg(args, 0)), nil
+	case "trim":
+		return strings.TrimSpace(stringArg(args, 0)), nil
+	case "string":
+		if len(args) > 0 {
+			return toString(args[0]), nil
+		}
+	case "int":
+		if len(args) > 0 {
+			if i, ok := toInt(args[0]); ok {
+				return int64(i), nil
+			}
+			return strconv.ParseInt(toString(args[0]), 10, 64)
+		}
+	case "bool":
+		return strings.EqualFold(toString(args[0]), "true"), nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "uniquestring":
+		return uniqueString(args), nil
+	case "guid":
+		return deterministicGuid(args), nil
+	case "newguid":
+		return deterministicGuid([]any{s.deploymentName}), nil
+	case "utcnow":
+		return s.sim.now().Format("20060102T150405Z"), nil
+	case "replace":
+		return strings.ReplaceAll(stringArg(args, 0), stringArg(args, 1), stringArg(args, 2)), nil
+	case "split":
+		parts := strings.Split(stringArg(args, 0), stringArg(args, 1))
+		items := make([]any, len(parts))
+		for i, part := range parts {
+			items[i] = part
+		}
+		return items, nil
+	case "join":
+		items, _ := args[0].([]any)
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = toString(item)
+		}
+		return strings.Join(parts, stringArg(args, 1)), nil
+	case "startswith":
+		return strings.HasPrefix(strings.ToLower(stringArg(args, 0)), strings.ToLower(stringArg(args, 1))), nil
+	case "endswith":
+		return strings.HasSuffix(strings.ToLower(stringArg(args, 0)), strings.ToLower(stringArg(args, 1))), nil
+	case "take", "skip", "substring", "first", "last", "length", "empty", "contains":
+		return collectionFunction(name, args)
+	case "equals":
+		return len(args) == 2 && reflect.DeepEqual(normalize(args[0]), normalize(args[1])), nil
+	case "not":
+		return args[0] != true, nil
+	case "and":
+		for _, arg := range args {
+			if arg != true {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "or":
+		for _, arg := range args {
+			if arg == true {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "coalesce":
+		for _, arg := range args {
+			if arg != nil {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	case "createobject":
+		object := map[string]any{}
+		for i := 0; i+1 < len(args); i += 2 {
+			object[toString(args[i])] = args[i+1]
+		}
+		return object, nil
+	case "createarray", "array":
+		return append([]any{}, args...), nil
+	case "union":
+		object := map[string]any{}
+		for _, arg := range args {
+			if value, ok := arg.(map[string]any); ok {
+				for key, item := range value {
+					object[key] = item
+				}
+			}
+		}
+		return object, nil
+	case "json":
+		var value any
+		if err := json.Unmarshal([]byte(stringArg(args, 0)), &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("unsupported template function '%s'", name)
+}
+
+func collectionFunction(name string, args []any) (any, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s requires arguments", name)
+	}
+
+	switch value := args[0].(type) {
+	case string:
+		switch name {
+		case "length":
+			return int64(len(value)), nil
+		case "empty":
+			return value == "", nil
+		case "contains":
+			return strings.Contains(value, stringArg(args, 1)), nil
+		case "first", "last":
+			if value == "" {
+				return "", nil
+			}
+			if name == "first" {
+				return value[:1], nil
+			}
+			return value[len(value)-1:], nil
+		}
+
+		start, length := 0, len(value)
+		n, _ := toInt(argAt(args, 1))
+		switch name {
+		case "take":
+			length = minInt(n, len(value))
+		case "skip":
+			start = minInt(n, len(value))
+			length = len(value) - start
+		case "substring":
+			start = minInt(n, len(value))
+			length = len(value) - start
+			if l, ok := toInt(argAt(args, 2)); ok {
+				length = minInt(l, length)
+			}
+		}
+		return value[start : start+length], nil
+	case []any:
+		switch name {
+		case "length":
+			return int64(len(value)), nil
+		case "empty":
+			return len(value) == 0, nil
+		case "contains":
+			for _, item := range value {
+				if reflect.DeepEqual(normalize(item), normalize(argAt(args, 1))) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case "first":
+			if len(value) == 0 {
+				return nil, nil
+			}
+			return value[0], nil
+		case "last":
+			if len(value) == 0 {
+				return nil, nil
+			}
+			return value[len(value)-1], nil
+		}
+
+		n, _ := toInt(argAt(args, 1))
+		if name == "take" {
+			return value[:minInt(n, len(value))], nil
+		}
+		return value[minInt(n, len(value)):], nil
+	case map[string]any:
+		switch name {
+		case "length":
+			return int64(len(value)), nil
+		case "empty":
+			return len(value) == 0, nil
+		case "contains":
+			_, has := value[stringArg(args, 1)]
+			return has, nil
+		}
+	case nil:
+		if name == "empty" {
+			return true, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported arguments of %s", name)
+}
+
+// Evaluates the value of the template, evaluating the expressions of its strings, objects and arrays
+func (s *deploymentScope) evaluate(value any) (any, error) {
+	switch value := value.(type) {
+	case string:
+		if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+			return value, nil
+		}
+
+		// Strings starting with [[ are literals starting with [
+		if strings.HasPrefix(value, "[[") {
+			return value[1:], nil
+		}
+
+		expr, err := parseExpression(value[1 : len(value)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		return s.eval(expr)
+	case map[string]any:
+		object := make(map[string]any, len(value))
+		for key, item := range value {
+			evaluated, err := s.evaluate(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			object[key] = evaluated
+		}
+		return object, nil
+	case []any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			evaluated, err := s.evaluate(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = evaluated
+		}
+		return items, nil
+	}
+
+	return value, nil
+}
+
+func accessProperty(target any, property string) (any, error) {
+	object, ok := target.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("property '%s' of a value that isn't an object", property)
+	}
+
+	// Properties are case-insensitive
+	if value, has := object[property]; has {
+		return value, nil
+	}
+
+	for key, value := range object {
+		if strings.EqualFold(key, property) {
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w '%s'", errMissingProperty, property)
+}
+
+var errMissingProperty = errors.New("missing property")
+
+func argAt(args []any, i int) any {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return nil
+}
+
+func stringArg(args []any, i int) string {
+	return toString(argAt(args, i))
+}
+
+func toString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case map[string]any, []any:
+		content, _ := json.Marshal(value)
+		return string(content)
+	}
+
+	return fmt.Sprint(value)
+}
+
+func toInt(value any) (int, bool) {
+	switch value := value.(type) {
+	case int:
+		return value, true
+	case int64:
+		return int(value), true
+	case float64:
+		return int(value), true
+	case json.Number:
+		i, err := value.Int64()
+		return int(i), err == nil
+	}
+
+	return 0, false
+}
+
+// Normalizes the numbers of values for comparisons, numbers of templates are float64 and numbers of expressions int64
+func normalize(value any) any {
+	if i, ok := toInt(value); ok {
+		return int64(i)
+	}
+
+	return value
+}
+
+// Formats the string with the composite formatting of .NET, ex) {0}-{1}
+func formatString(format string, args []any) string {
+	for i, arg := range args {
+		format = strings.ReplaceAll(format, fmt.Sprintf("{%d}", i), toString(arg))
+	}
+
+	return format
+}
+
+// The alphabet of unique strings, lowercase letters and digits as in ARM
+const uniqueStringAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// Generates a deterministic 13 characters string from the arguments, as uniqueString of ARM
+func uniqueString(args []any) string {
+	hash := hashArgs(args)
+	builder := strings.Builder{}
+	for i := 0; i < 13; i++ {
+		builder.WriteByte(uniqueStringAlphabet[hash[i]%byte(len(uniqueStringAlphabet))])
+	}
+
+	return builder.String()
+}
+
+// Generates a deterministic GUID from the arguments, as guid of ARM
+func deterministicGuid(args []any) string {
+	hash := hashArgs(args)
+	return fmt.Sprintf("%x-%x-%x-%x-%x", hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:16])
+}
+
+func hashArgs(args []any) []byte {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = toString(arg)
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hash[:]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
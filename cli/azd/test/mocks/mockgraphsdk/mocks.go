@@ -339,3 +339,23 @@ func RegisterFederatedCredentialDeleteItemMock(
 		return mocks.CreateEmptyHttpResponse(request, statusCode)
 	})
 }
+
+func RegisterCheckMemberGroupsMock(
+	mockContext *mocks.MockContext,
+	statusCode int,
+	principalId string,
+	memberGroups []string,
+) {
+	mockContext.HttpClient.When(func(request *http.Request) bool {
+		return request.Method == http.MethodPost &&
+			strings.Contains(request.URL.Path, fmt.Sprintf("/directoryObjects/%s/checkMemberGroups", principalId))
+	}).RespondFn(func(request *http.Request) (*http.Response, error) {
+		if memberGroups == nil {
+			return mocks.CreateEmptyHttpResponse(request, statusCode)
+		}
+
+		return mocks.CreateHttpResponseWithBody(request, statusCode, graphsdk.CheckMemberGroupsResponse{
+			Value: memberGroups,
+		})
+	})
+}
@@ -0,0 +1,144 @@
+package mockhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+)
+
+// The path of the status and result endpoints of the mocked long-running operations
+const operationsPath = "/mockhttp/operations"
+
+// Ids of the mocked long-running operations, unique across scenarios sharing a client
+var operationIds atomic.Int64
+
+// ArmScenario composes the mocks of ARM requests following the conventions of ARM: resources are read with GET,
+// collections are listed in pages linked by nextLink, and long-running operations respond with an
+// Azure-AsyncOperation header polled until the operation completes. The resource paths are patterns, see MatchPath.
+//
+//	mockhttp.NewArmScenario(mockContext.HttpClient).
+//		List("/subscriptions/*/resourceGroups/*/resources", 2, resource1, resource2, resource3).
+//		BeginPut("/subscriptions/**/containerApps/*", containerApp)
+//
+// Like other mocks, the requests registered last take precedence.
+type ArmScenario struct {
+	http *MockHttpClient
+}
+
+// NewArmScenario creates a scenario registering its mocks with the client
+func NewArmScenario(c *MockHttpClient) *ArmScenario {
+	return &ArmScenario{http: c}
+}
+
+// Get responds to GET requests of the resource with the body
+func (s *ArmScenario) Get(pattern string, body any) *ArmScenario {
+	s.http.WhenPath(http.MethodGet, pattern).RespondJson(http.StatusOK, body)
+	return s
+}
+
+// Put responds to PUT and PATCH requests of the resource with the body, as a synchronous operation
+func (s *ArmScenario) Put(pattern string, body any) *ArmScenario {
+	s.http.WhenPath(http.MethodPut, pattern).RespondJson(http.StatusOK, body)
+	s.http.WhenPath(http.MethodPatch, pattern).RespondJson(http.StatusOK, body)
+	return s
+}
+
+// List responds to GET requests of the collection with the values, in pages of the page size linked by nextLink. A page
+// size of 0 responds with all the values in a single page.
+func (s *ArmScenario) List(pattern string, pageSize int, values ...any) *ArmScenario {
+	s.http.WhenPath(http.MethodGet, pattern).RespondFn(func(request *http.Request) (*http.Response, error) {
+		start := 0
+		if token := request.URL.Query().Get("$skipToken"); token != "" {
+			var err error
+			if start, err = strconv.Atoi(token); err != nil || start > len(values) {
+				return jsonResponse(request, http.StatusBadRequest, armError("InvalidSkipToken", token))
+			}
+		}
+
+		end := len(values)
+		if pageSize > 0 && start+pageSize < end {
+			end = start + pageSize
+		}
+
+		page := map[string]any{"value": append([]any{}, values[start:end]...)}
+		if end < len(values) {
+			nextLink := requestUrl(request, request.URL.Path)
+			query := request.URL.Query()
+			query.Set("$skipToken", strconv.Itoa(end))
+			nextLink.RawQuery = query.Encode()
+			page["nextLink"] = nextLink.String()
+		}
+
+		return jsonResponse(request, http.StatusOK, page)
+	})
+
+	return s
+}
+
+// BeginPut responds to PUT and PATCH requests of the resource with a long-running operation completed after a poll,
+// then responds to the final GET of the resource with the result
+func (s *ArmScenario) BeginPut(pattern string, result any) *ArmScenario {
+	s.longRunning(http.MethodPut, pattern, nil)
+	s.longRunning(http.MethodPatch, pattern, nil)
+	return s.Get(pattern, result)
+}
+
+// BeginPost responds to POST requests of the action with a long-running operation completed after a poll, whose result is
+// served from its Location header
+func (s *ArmScenario) BeginPost(pattern string, result any) *ArmScenario {
+	s.longRunning(http.MethodPost, pattern, result)
+	return s
+}
+
+// BeginDelete responds to DELETE requests of the resource with a long-running operation completed after a poll
+func (s *ArmScenario) BeginDelete(pattern string) *ArmScenario {
+	s.longRunning(http.MethodDelete, pattern, nil)
+	return s
+}
+
+// Fail responds to the requests of the method with an ARM error of the status code, ex) 404 and ResourceNotFound.
+// An empty method fails requests of any method.
+func (s *ArmScenario) Fail(method string, pattern string, statusCode int, code string, message string) *ArmScenario {
+	s.http.WhenPath(method, pattern).RespondJson(statusCode, armError(code, message))
+	return s
+}
+
+// Registers a long-running operation started by the requests of the method. The operation is in progress when started,
+// then succeeds on its first poll, so the pollers of the SDK complete without waiting between polls.
+func (s *ArmScenario) longRunning(method string, pattern string, result any) {
+	operationPath := fmt.Sprintf("%s/%d", operationsPath, operationIds.Add(1))
+	s.http.WhenPath(http.MethodGet, operationPath).RespondJson(http.StatusOK, map[string]any{"status": "Succeeded"})
+	s.http.WhenPath(http.MethodGet, operationPath+"/result").RespondJson(http.StatusOK, result)
+
+	statusCode := http.StatusAccepted
+	if method == http.MethodPut {
+		statusCode = http.StatusCreated
+	}
+
+	s.http.WhenPath(method, pattern).RespondFn(func(request *http.Request) (*http.Response, error) {
+		response, err := jsonResponse(request, statusCode, map[string]any{
+			"properties": map[string]any{"provisioningState": "InProgress"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		response.Header.Set("Azure-AsyncOperation", requestUrl(request, operationPath).String())
+		if method == http.MethodPost || method == http.MethodDelete {
+			response.Header.Set("Location", requestUrl(request, operationPath+"/result").String())
+		}
+
+		return response, nil
+	})
+}
+
+// Gets the URL of the path on the host of the request
+func requestUrl(request *http.Request, path string) *url.URL {
+	return &url.URL{Scheme: request.URL.Scheme, Host: request.URL.Host, Path: path}
+}
+
+func armError(code string, message string) map[string]any {
+	return map[string]any{"error": map[string]any{"code": code, "message": message}}
+}
@@ -0,0 +1,89 @@
+package mockhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// MatchPath matches the requests of the method whose URL path matches the pattern, case-insensitively. In the pattern,
+// '*' matches any single path segment or the rest of a segment, and '**' matches one or more segments, ex)
+// /subscriptions/*/resourceGroups/*/providers/Microsoft.App/containerApps/* or /subscriptions/**/deployments/*.
+// An empty method matches requests of any method.
+func MatchPath(method string, pattern string) RequestPredicate {
+	regex := pathPatternRegex(pattern)
+
+	return func(request *http.Request) bool {
+		if method != "" && !strings.EqualFold(request.Method, method) {
+			return false
+		}
+
+		return regex.MatchString(strings.TrimSuffix(request.URL.Path, "/"))
+	}
+}
+
+// WhenPath registers a mocked response for the requests of the method whose path matches the pattern, see MatchPath
+func (c *MockHttpClient) WhenPath(method string, pattern string) *HttpExpression {
+	return c.When(MatchPath(method, pattern))
+}
+
+// RespondJson responds to the matched requests with the status code and the body serialized as JSON
+func (e *HttpExpression) RespondJson(statusCode int, body any) *MockHttpClient {
+	return e.RespondFn(func(request *http.Request) (*http.Response, error) {
+		return jsonResponse(request, statusCode, body)
+	})
+}
+
+// Converts a path pattern to a regular expression matching the whole path
+func pathPatternRegex(pattern string) *regexp.Regexp {
+	segments := strings.Split(strings.TrimSuffix(pattern, "/"), "/")
+	expression := strings.Builder{}
+	expression.WriteString("(?i)^")
+
+	for i, segment := range segments {
+		if i > 0 {
+			expression.WriteString("/")
+		}
+
+		if segment == "**" {
+			expression.WriteString(".+")
+			continue
+		}
+
+		parts := strings.Split(segment, "*")
+		for j, part := range parts {
+			if j > 0 {
+				expression.WriteString("[^/]*")
+			}
+			expression.WriteString(regexp.QuoteMeta(part))
+		}
+	}
+
+	expression.WriteString("$")
+	return regexp.MustCompile(expression.String())
+}
+
+func jsonResponse(request *http.Request, statusCode int, body any) (*http.Response, error) {
+	response := &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{},
+		Request:    request,
+		Body:       http.NoBody,
+	}
+
+	if body == nil {
+		return response, nil
+	}
+
+	content, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Header.Set("Content-Type", "application/json")
+	response.Body = io.NopCloser(bytes.NewBuffer(content))
+	return response, nil
+}